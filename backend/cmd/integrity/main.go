@@ -0,0 +1,109 @@
+// Command integrity runs the tenant integrity checks from the command line and, with -repair,
+// fixes the issues in the categories that are safe to repair. Reports are written as JSON.
+//
+//	go run ./cmd/integrity -org <organization id> [-repair] [-categories a,b]
+//	go run ./cmd/integrity -all
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/controlwise/backend/internal/config"
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	orgFlag := flag.String("org", "", "organization to check")
+	all := flag.Bool("all", false, "check every organization")
+	repair := flag.Bool("repair", false, "repair the issues in the categories that are safe to repair")
+	categoriesFlag := flag.String("categories", "", "comma separated categories to repair; all safe ones by default")
+	flag.Parse()
+
+	if (*orgFlag == "") == !*all {
+		log.Fatal("Pass either -org or -all")
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("Warning: .env file not found")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Failed to load configuration: ", err)
+	}
+
+	db, err := database.NewPostgres(cfg.Database)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+
+	var orgIDs []uuid.UUID
+	if *all {
+		rows, err := db.Pool.Query(ctx, `SELECT id FROM organizations WHERE deleted_at IS NULL ORDER BY name`)
+		if err != nil {
+			log.Fatal("Failed to list organizations: ", err)
+		}
+		for rows.Next() {
+			var id uuid.UUID
+			if err := rows.Scan(&id); err != nil {
+				log.Fatal("Failed to scan organization: ", err)
+			}
+			orgIDs = append(orgIDs, id)
+		}
+		rows.Close()
+	} else {
+		id, err := uuid.Parse(*orgFlag)
+		if err != nil {
+			log.Fatal("Invalid organization ID: ", err)
+		}
+		orgIDs = append(orgIDs, id)
+	}
+
+	var categories []models.IntegrityCategory
+	for _, c := range strings.Split(*categoriesFlag, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			categories = append(categories, models.IntegrityCategory(c))
+		}
+	}
+
+	integrity := services.NewIntegrityService(db)
+	out := json.NewEncoder(os.Stdout)
+	out.SetIndent("", "  ")
+
+	failed := false
+	for _, orgID := range orgIDs {
+		report, err := integrity.Check(ctx, orgID)
+		if err != nil {
+			log.Printf("Failed to check organization %s: %v", orgID, err)
+			failed = true
+			continue
+		}
+		out.Encode(report)
+
+		if *repair && report.TotalIssues > 0 {
+			result, err := integrity.Repair(ctx, orgID, categories)
+			if err != nil {
+				log.Printf("Failed to repair organization %s: %v", orgID, err)
+				failed = true
+				continue
+			}
+			out.Encode(result)
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -11,8 +11,12 @@ import (
 
 	"github.com/controlwise/backend/internal/config"
 	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/events"
 	"github.com/controlwise/backend/internal/jobs"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
 	"github.com/controlwise/backend/internal/workflow"
+	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
 	"github.com/joho/godotenv"
 )
@@ -47,8 +51,21 @@ func main() {
 	client := asynq.NewClient(redisOpt)
 	defer client.Close()
 
+	// Redis backs the message rate limits shared by all workers
+	redisClient, err := database.NewRedis(cfg.Redis)
+	if err != nil {
+		log.Fatal("Failed to connect to Redis:", err)
+	}
+	defer redisClient.Close()
+
 	// Create workflow engine
 	engine := workflow.NewEngine(db, client)
+	// Rate limits spread out single messages and pace the fan-out of bulk runs
+	limiter := workflow.NewRateLimiter(redisClient.Client, db, cfg.RateLimit)
+	engine.GetExecutor().SetRateLimiter(limiter)
+	engine.GetScheduler().SetRateLimiter(limiter)
+	engine.SetEventPublisher(events.NewPublisher(redisClient.Client))
+	engine.SetFrontendURL(cfg.App.FrontendURL)
 
 	// Create Asynq server
 	srv := asynq.NewServer(
@@ -64,6 +81,10 @@ func main() {
 			},
 			// Retry configuration
 			RetryDelayFunc: func(n int, e error, t *asynq.Task) time.Duration {
+				// Workflow action retries follow the action's own backoff
+				if d, ok := workflow.RetryDelay(n, t); ok {
+					return d
+				}
 				return time.Duration(n) * time.Minute // Exponential backoff
 			},
 			// Error handler
@@ -75,12 +96,83 @@ func main() {
 
 	// Initialize handlers with workflow engine
 	handlers := jobs.NewHandlers(db, engine)
+	emailService := services.NewEmailService(cfg.Email)
+	storageService := services.NewStorageService(cfg.Storage)
+	handlers.SetAccountantService(services.NewAccountantService(db, storageService, emailService, cfg.App.FrontendURL))
+	handlers.SetFollowUpService(services.NewFollowUpService(db, emailService, cfg.App.FrontendURL))
+	handlers.SetLogRetentionService(services.NewLogRetentionService(db, storageService))
+	handlers.SetDataExportService(services.NewDataExportService(db, storageService, cfg.Storage.ExportRetention))
+	handlers.SetAdminSecurityService(services.NewAdminSecurityService(db, emailService, cfg.App.FrontendURL))
+	handlers.SetOrganizationDeletionService(services.NewOrganizationDeletionService(db, storageService, emailService, cfg.App.FrontendURL))
+
+	// Workflow emails go through each organization's email provider
+	engine.GetExecutor().SetEmailSender(services.NewEmailDeliveryService(db, cfg.Encryption.Key, emailService))
+
+	// transition_entity, create_project and create_task actions move related entities through the same services as the API
+	appServices := services.NewServices(db, redisClient, cfg)
+	chainService := services.NewWorkflowChainService(db,
+		appServices.Project, appServices.Task, appServices.SessionPayment, appServices.Workflow)
+	engine.GetExecutor().SetEntityTransitioner(chainService)
+	engine.GetExecutor().SetProjectCreator(chainService)
+	engine.GetExecutor().SetTaskCreator(chainService)
+
+	// {{payment_link}} variables are Stripe checkouts created when a message uses them
+	engine.GetExecutor().SetPaymentLinker(appServices.PaymentLink)
+	engine.GetExecutor().SetPaymentReferencer(appServices.PaymentReference)
+
+	// {{reschedule_link}} variables let patients move their session to another free slot
+	engine.GetExecutor().SetRescheduleLinker(appServices.Reschedule)
+
+	// offer_slot actions offer cancelled sessions' slots to the waiting list
+	engine.GetExecutor().SetSlotOfferer(appServices.WaitingList)
+
+	// send_chat actions and failed action alerts go to the team's Slack and Teams channels
+	engine.GetExecutor().SetChatSender(appServices.ChatWebhook)
+
+	// Synthetic traffic books and moves fake sessions in a staging organization, whose messages are not sent
+	if cfg.Synthetic.Enabled() {
+		orgID := uuid.MustParse(cfg.Synthetic.OrganizationID)
+		engine.GetExecutor().SetSyntheticOrganization(orgID)
+		handlers.SetSyntheticTrafficService(services.NewSyntheticTrafficService(db, appServices.Session, orgID,
+			cfg.Synthetic.SessionsPerMinute, cfg.Synthetic.TransitionsPerMinute))
+		log.Printf("Synthetic traffic enabled for organization %s", orgID)
+	}
+
+	// Deployment-specific action types run through their webhooks
+	for actionType, url := range cfg.Actions.Webhooks {
+		handler := workflow.NewWebhookActionHandler(url, cfg.Actions.WebhookSecret)
+		if err := engine.GetExecutor().RegisterAction(models.ActionType(actionType), handler); err != nil {
+			log.Fatal("Failed to register custom action:", err)
+		}
+	}
 
 	// Create mux for routing tasks to handlers
 	mux := asynq.NewServeMux()
 	mux.HandleFunc(jobs.TypeSendNotification, handlers.HandleSendNotification)
 	mux.HandleFunc(jobs.TypeExecuteTrigger, handlers.HandleExecuteTrigger)
 	mux.HandleFunc(jobs.TypeCheckTimeTriggers, handlers.HandleCheckTimeTriggers)
+	mux.HandleFunc(jobs.TypeCheckComplianceDeadlines, handlers.HandleCheckComplianceDeadlines)
+	mux.HandleFunc(jobs.TypeCheckTaskDeadlines, handlers.HandleCheckTaskDeadlines)
+	mux.HandleFunc(jobs.TypeCheckTreatmentPlanReviews, handlers.HandleCheckTreatmentPlanReviews)
+	mux.HandleFunc(jobs.TypeExpireBudgets, handlers.HandleExpireBudgets)
+	mux.HandleFunc(jobs.TypeMarkOverduePayments, handlers.HandleMarkOverduePayments)
+	mux.HandleFunc(jobs.TypeCheckStatusConsistency, handlers.HandleCheckStatusConsistency)
+	mux.HandleFunc(jobs.TypeExecuteBulkRun, handlers.HandleExecuteBulkRun)
+	mux.HandleFunc(jobs.TypeExecuteBulkRunItem, handlers.HandleExecuteBulkRunItem)
+	mux.HandleFunc(jobs.TypeSendMessage, handlers.HandleSendMessage)
+	mux.HandleFunc(jobs.TypeRetryAction, handlers.HandleRetryAction)
+	mux.HandleFunc(jobs.TypeProcessExportBundles, handlers.HandleProcessExportBundles)
+	mux.HandleFunc(jobs.TypeComputeOrganizationUsage, handlers.HandleComputeOrganizationUsage)
+	mux.HandleFunc(jobs.TypeComputeBenchmarks, handlers.HandleComputeBenchmarks)
+	mux.HandleFunc(jobs.TypeSendFollowUps, handlers.HandleSendFollowUps)
+	mux.HandleFunc(jobs.TypeScheduleLogArchives, handlers.HandleScheduleLogArchives)
+	mux.HandleFunc(jobs.TypeProcessLogArchives, handlers.HandleProcessLogArchives)
+	mux.HandleFunc(jobs.TypeProcessOrganizationMerges, handlers.HandleProcessOrganizationMerges)
+	mux.HandleFunc(jobs.TypeProcessDataExports, handlers.HandleProcessDataExports)
+	mux.HandleFunc(jobs.TypeCleanupDataExports, handlers.HandleCleanupDataExports)
+	mux.HandleFunc(jobs.TypeAnalyzeAdminActivity, handlers.HandleAnalyzeAdminActivity)
+	mux.HandleFunc(jobs.TypeProcessOrganizationDeletions, handlers.HandleProcessOrganizationDeletions)
+	mux.HandleFunc(jobs.TypeGenerateSyntheticTraffic, handlers.HandleGenerateSyntheticTraffic)
 
 	// Start scheduler for periodic tasks
 	scheduler := asynq.NewScheduler(redisOpt, nil)
@@ -91,6 +183,116 @@ func main() {
 		log.Fatal("Failed to register scheduled task: ", err)
 	}
 
+	// Check project compliance deadlines every hour
+	_, err = scheduler.Register("0 * * * *", asynq.NewTask(jobs.TypeCheckComplianceDeadlines, nil))
+	if err != nil {
+		log.Fatal("Failed to register scheduled task: ", err)
+	}
+
+	// Notify overdue tasks every hour
+	_, err = scheduler.Register("15 * * * *", asynq.NewTask(jobs.TypeCheckTaskDeadlines, nil))
+	if err != nil {
+		log.Fatal("Failed to register scheduled task: ", err)
+	}
+
+	// Flag treatment plans due for review every day
+	_, err = scheduler.Register("30 7 * * *", asynq.NewTask(jobs.TypeCheckTreatmentPlanReviews, nil))
+	if err != nil {
+		log.Fatal("Failed to register scheduled task: ", err)
+	}
+
+	// Expire unanswered budgets past their validity date every day
+	_, err = scheduler.Register("0 1 * * *", asynq.NewTask(jobs.TypeExpireBudgets, nil))
+	if err != nil {
+		log.Fatal("Failed to register scheduled task: ", err)
+	}
+
+	// Mark payments past their due date as overdue every day
+	_, err = scheduler.Register("15 1 * * *", asynq.NewTask(jobs.TypeMarkOverduePayments, nil))
+	if err != nil {
+		log.Fatal("Failed to register scheduled task: ", err)
+	}
+
+	// Check entity statuses against workflow states every day
+	_, err = scheduler.Register("30 2 * * *", asynq.NewTask(jobs.TypeCheckStatusConsistency, nil))
+	if err != nil {
+		log.Fatal("Failed to register scheduled task: ", err)
+	}
+
+	// Build requested accountant export bundles every minute
+	_, err = scheduler.Register("* * * * *", asynq.NewTask(jobs.TypeProcessExportBundles, nil))
+	if err != nil {
+		log.Fatal("Failed to register scheduled task: ", err)
+	}
+
+	// Snapshot organizations' data usage every night
+	_, err = scheduler.Register("0 3 * * *", asynq.NewTask(jobs.TypeComputeOrganizationUsage, nil))
+	if err != nil {
+		log.Fatal("Failed to register scheduled task: ", err)
+	}
+
+	// Recompute the metrics of organizations taking part in benchmarking every night
+	_, err = scheduler.Register("30 3 * * *", asynq.NewTask(jobs.TypeComputeBenchmarks, nil))
+	if err != nil {
+		log.Fatal("Failed to register scheduled task: ", err)
+	}
+
+	// Notify users of their due follow-ups every minute
+	_, err = scheduler.Register("* * * * *", asynq.NewTask(jobs.TypeSendFollowUps, nil))
+	if err != nil {
+		log.Fatal("Failed to register scheduled task: ", err)
+	}
+
+	// Archive execution log entries past each organization's retention every night
+	_, err = scheduler.Register("30 3 * * *", asynq.NewTask(jobs.TypeScheduleLogArchives, nil))
+	if err != nil {
+		log.Fatal("Failed to register scheduled task: ", err)
+	}
+
+	// Build queued execution log archives every minute
+	_, err = scheduler.Register("* * * * *", asynq.NewTask(jobs.TypeProcessLogArchives, nil))
+	if err != nil {
+		log.Fatal("Failed to register scheduled task: ", err)
+	}
+
+	// Run queued organization merges every minute
+	_, err = scheduler.Register("* * * * *", asynq.NewTask(jobs.TypeProcessOrganizationMerges, nil))
+	if err != nil {
+		log.Fatal("Failed to register scheduled task: ", err)
+	}
+
+	// Build requested data exports every minute
+	_, err = scheduler.Register("* * * * *", asynq.NewTask(jobs.TypeProcessDataExports, nil))
+	if err != nil {
+		log.Fatal("Failed to register scheduled task: ", err)
+	}
+
+	// Remove expired data export files every night
+	_, err = scheduler.Register("45 3 * * *", asynq.NewTask(jobs.TypeCleanupDataExports, nil))
+	if err != nil {
+		log.Fatal("Failed to register scheduled task: ", err)
+	}
+
+	// Look for unusual system admin activity every 15 minutes
+	_, err = scheduler.Register("*/15 * * * *", asynq.NewTask(jobs.TypeAnalyzeAdminActivity, nil))
+	if err != nil {
+		log.Fatal("Failed to register scheduled task: ", err)
+	}
+
+	// Remind of and run due organization deletions every 5 minutes
+	_, err = scheduler.Register("*/5 * * * *", asynq.NewTask(jobs.TypeProcessOrganizationDeletions, nil))
+	if err != nil {
+		log.Fatal("Failed to register scheduled task: ", err)
+	}
+
+	// Generate synthetic traffic every minute when enabled
+	if cfg.Synthetic.Enabled() {
+		_, err = scheduler.Register("* * * * *", asynq.NewTask(jobs.TypeGenerateSyntheticTraffic, nil))
+		if err != nil {
+			log.Fatal("Failed to register scheduled task: ", err)
+		}
+	}
+
 	// Start scheduler in goroutine
 	go func() {
 		if err := scheduler.Run(); err != nil {
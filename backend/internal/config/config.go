@@ -7,6 +7,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type Config struct {
@@ -18,6 +20,11 @@ type Config struct {
 	Email      EmailConfig
 	App        AppConfig
 	Encryption EncryptionConfig
+	RateLimit  RateLimitConfig
+	OCR        OCRConfig
+	Banking    BankingConfig
+	Actions    CustomActionsConfig
+	Synthetic  SyntheticTrafficConfig
 }
 
 type ServerConfig struct {
@@ -53,6 +60,7 @@ type StorageConfig struct {
 	S3Bucket           string
 	MaxUploadSize      int64
 	AllowedFileTypes   []string
+	ExportRetention    time.Duration // how long data export files are kept
 }
 
 type EmailConfig struct {
@@ -71,6 +79,55 @@ type EncryptionConfig struct {
 	Key string
 }
 
+// RateLimitConfig holds message rates (messages per second, 0 = unlimited).
+// Provider rates are shared by every organization, organization rates apply
+// to each organization unless overridden in its notification config.
+type RateLimitConfig struct {
+	TwilioPerSecond      float64
+	MetaPerSecond        float64
+	SMTPPerSecond        float64
+	OrgWhatsAppPerSecond float64
+	OrgEmailPerSecond    float64
+}
+
+// OCRConfig selects the provider that reads expense receipts. With no provider, receipts are
+// stored and the expense is filled in by hand. The "http" provider posts the receipt to Endpoint.
+type OCRConfig struct {
+	Provider string
+	Endpoint string
+	APIKey   string
+}
+
+// BankingConfig selects the PSD2 aggregator bank statements are synced from. Without one,
+// statements are only imported from files. The "http" provider reads transactions from Endpoint.
+type BankingConfig struct {
+	AggregatorProvider string
+	AggregatorEndpoint string
+	AggregatorAPIKey   string
+}
+
+// CustomActionsConfig maps custom workflow action types to the webhooks that run them, as
+// "type=url" pairs. Requests are signed with WebhookSecret when set.
+type CustomActionsConfig struct {
+	Webhooks      map[string]string
+	WebhookSecret string
+}
+
+// SyntheticTrafficConfig turns on the worker's synthetic traffic mode, for load testing scheduler
+// and queue changes against a staging organization before release. Fake sessions are booked and
+// moved through their states there, and its workflow messages are not sent. Off when
+// OrganizationID is empty; never allowed in production.
+type SyntheticTrafficConfig struct {
+	OrganizationID       string
+	SessionsPerMinute    int
+	TransitionsPerMinute int
+}
+
+// Enabled reports whether the worker generates synthetic traffic
+func (c SyntheticTrafficConfig) Enabled() bool {
+	return c.OrganizationID != ""
+}
+
 // Load loads and validates the configuration from environment variables
 func Load() (*Config, error) {
 	cfg := &Config{
@@ -103,6 +160,7 @@ func Load() (*Config, error) {
 			S3Bucket:           getEnv("S3_BUCKET", "controlwise-files"),
 			MaxUploadSize:      getEnvAsInt64("MAX_UPLOAD_SIZE", 10485760), // 10MB default
 			AllowedFileTypes:   getEnvAsStringSlice("ALLOWED_FILE_TYPES", "image/jpeg,image/png,image/webp,application/pdf"),
+			ExportRetention:    time.Duration(getEnvAsInt64("EXPORT_RETENTION_DAYS", 7)) * 24 * time.Hour,
 		},
 		Email: EmailConfig{
 			SMTPHost:     getEnv("SMTP_HOST", "smtp.gmail.com"),
@@ -117,6 +175,32 @@ func Load() (*Config, error) {
 		Encryption: EncryptionConfig{
 			Key: getEnv("ENCRYPTION_KEY", ""), // Required for storing Twilio credentials
 		},
+		RateLimit: RateLimitConfig{
+			TwilioPerSecond:      getEnvAsFloat64("RATE_LIMIT_TWILIO_PER_SECOND", 50),
+			MetaPerSecond:        getEnvAsFloat64("RATE_LIMIT_META_PER_SECOND", 80),
+			SMTPPerSecond:        getEnvAsFloat64("RATE_LIMIT_SMTP_PER_SECOND", 10),
+			OrgWhatsAppPerSecond: getEnvAsFloat64("RATE_LIMIT_ORG_WHATSAPP_PER_SECOND", 5),
+			OrgEmailPerSecond:    getEnvAsFloat64("RATE_LIMIT_ORG_EMAIL_PER_SECOND", 5),
+		},
+		OCR: OCRConfig{
+			Provider: getEnv("OCR_PROVIDER", ""),
+			Endpoint: getEnv("OCR_ENDPOINT", ""),
+			APIKey:   getEnv("OCR_API_KEY", ""),
+		},
+		Banking: BankingConfig{
+			AggregatorProvider: getEnv("BANK_AGGREGATOR_PROVIDER", ""),
+			AggregatorEndpoint: getEnv("BANK_AGGREGATOR_ENDPOINT", ""),
+			AggregatorAPIKey:   getEnv("BANK_AGGREGATOR_API_KEY", ""),
+		},
+		Actions: CustomActionsConfig{
+			Webhooks:      getEnvAsStringMap("CUSTOM_ACTION_WEBHOOKS"),
+			WebhookSecret: getEnv("CUSTOM_ACTION_WEBHOOK_SECRET", ""),
+		},
+		Synthetic: SyntheticTrafficConfig{
+			OrganizationID:       getEnv("SYNTHETIC_TRAFFIC_ORG_ID", ""),
+			SessionsPerMinute:    int(getEnvAsInt64("SYNTHETIC_SESSIONS_PER_MINUTE", 20)),
+			TransitionsPerMinute: int(getEnvAsInt64("SYNTHETIC_TRANSITIONS_PER_MINUTE", 40)),
+		},
 	}
 
 	if err := cfg.Validate(); err != nil {
@@ -147,6 +231,44 @@ func (c *Config) Validate() error {
 		return errors.New("DB_SSL_MODE must not be 'disable' in production")
 	}
 
+	switch c.OCR.Provider {
+	case "", "none":
+	case "http":
+		if c.OCR.Endpoint == "" {
+			return errors.New("OCR_ENDPOINT is required when OCR_PROVIDER is http")
+		}
+	default:
+		return fmt.Errorf("invalid OCR_PROVIDER value: %s (must be none or http)", c.OCR.Provider)
+	}
+
+	switch c.Banking.AggregatorProvider {
+	case "", "none":
+	case "http":
+		if c.Banking.AggregatorEndpoint == "" {
+			return errors.New("BANK_AGGREGATOR_ENDPOINT is required when BANK_AGGREGATOR_PROVIDER is http")
+		}
+	default:
+		return fmt.Errorf("invalid BANK_AGGREGATOR_PROVIDER value: %s (must be none or http)", c.Banking.AggregatorProvider)
+	}
+
+	for actionType, url := range c.Actions.Webhooks {
+		if actionType == "" || !strings.HasPrefix(url, "http") {
+			return fmt.Errorf("invalid CUSTOM_ACTION_WEBHOOKS entry: %s=%s", actionType, url)
+		}
+	}
+
+	if c.Synthetic.Enabled() {
+		if c.Server.Env == "production" {
+			return errors.New("SYNTHETIC_TRAFFIC_ORG_ID must not be set in production")
+		}
+		if _, err := uuid.Parse(c.Synthetic.OrganizationID); err != nil {
+			return fmt.Errorf("invalid SYNTHETIC_TRAFFIC_ORG_ID value: %s", c.Synthetic.OrganizationID)
+		}
+		if c.Synthetic.SessionsPerMinute < 0 || c.Synthetic.TransitionsPerMinute < 0 {
+			return errors.New("SYNTHETIC_SESSIONS_PER_MINUTE and SYNTHETIC_TRANSITIONS_PER_MINUTE must not be negative")
+		}
+	}
+
 	return nil
 }
 
@@ -176,6 +298,15 @@ func getEnvAsInt64(key string, defaultValue int64) int64 {
 	return defaultValue
 }
 
+func getEnvAsFloat64(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
 func getEnvAsStringSlice(key, defaultValue string) []string {
 	value := getEnv(key, defaultValue)
 	if value == "" {
@@ -183,3 +314,13 @@ func getEnvAsStringSlice(key, defaultValue string) []string {
 	}
 	return strings.Split(value, ",")
 }
+
+// getEnvAsStringMap parses a comma-separated list of key=value pairs
+func getEnvAsStringMap(key string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range getEnvAsStringSlice(key, "") {
+		k, v, _ := strings.Cut(pair, "=")
+		result[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return result
+}
@@ -0,0 +1,117 @@
+// Package events streams dashboard events between the API and worker processes.
+// Each organization has a capped Redis stream, so a dashboard that reconnects
+// resumes from the last event it received instead of missing what happened meanwhile.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// streamLength is roughly how many events are kept per organization for reconnecting dashboards
+	streamLength = 200
+	// streamTTL drops the streams of organizations with no recent events
+	streamTTL = 24 * time.Hour
+)
+
+// Publisher writes and reads the organizations' event streams. A nil Publisher discards events.
+type Publisher struct {
+	redis *redis.Client
+}
+
+func NewPublisher(client *redis.Client) *Publisher {
+	return &Publisher{redis: client}
+}
+
+func streamKey(orgID uuid.UUID) string {
+	return "dashboard:events:" + orgID.String()
+}
+
+// Publish appends an event to the organization's stream. Failures are logged, not returned:
+// the change the event describes has already been saved.
+func (p *Publisher) Publish(ctx context.Context, orgID uuid.UUID, event models.DashboardEvent) {
+	if p == nil || p.redis == nil {
+		return
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("[Events] Failed to encode %s event: %v", event.Type, err)
+		return
+	}
+
+	key := streamKey(orgID)
+	pipe := p.redis.TxPipeline()
+	pipe.XAdd(ctx, &redis.XAddArgs{
+		Stream: key,
+		MaxLen: streamLength,
+		Approx: true,
+		Values: map[string]interface{}{"event": data},
+	})
+	pipe.Expire(ctx, key, streamTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("[Events] Failed to publish %s event for organization %s: %v", event.Type, orgID, err)
+	}
+}
+
+// Read waits up to block for the organization's events after lastID and returns them.
+// No events within block is not an error.
+func (p *Publisher) Read(ctx context.Context, orgID uuid.UUID, lastID string, block time.Duration) ([]models.DashboardEvent, error) {
+	if p == nil || p.redis == nil {
+		return nil, errors.New("event stream is not available")
+	}
+	streams, err := p.redis.XRead(ctx, &redis.XReadArgs{
+		Streams: []string{streamKey(orgID), lastID},
+		Count:   100,
+		Block:   block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read events: %w", err)
+	}
+
+	var events []models.DashboardEvent
+	for _, stream := range streams {
+		for _, msg := range stream.Messages {
+			raw, _ := msg.Values["event"].(string)
+			var event models.DashboardEvent
+			if err := json.Unmarshal([]byte(raw), &event); err != nil {
+				log.Printf("[Events] Skipping malformed event %s: %v", msg.ID, err)
+				continue
+			}
+			event.ID = msg.ID
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// LatestID returns the id of the organization's last event, or "0" when there is none,
+// so a new dashboard can start reading from the current position
+func (p *Publisher) LatestID(ctx context.Context, orgID uuid.UUID) (string, error) {
+	if p == nil || p.redis == nil {
+		return "", errors.New("event stream is not available")
+	}
+	msgs, err := p.redis.XRevRangeN(ctx, streamKey(orgID), "+", "-", 1).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to read events: %w", err)
+	}
+	if len(msgs) == 0 {
+		return "0", nil
+	}
+	return msgs[0].ID, nil
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// AccountantHandler serves the read-only accountant workspace (admins and accountants)
+type AccountantHandler struct {
+	service *services.AccountantService
+}
+
+func NewAccountantHandler(service *services.AccountantService) *AccountantHandler {
+	return &AccountantHandler{service: service}
+}
+
+// accountantPeriod reads the period query parameter, defaulting to the previous month,
+// which is the one usually handed to the accountant
+func accountantPeriod(r *http.Request) (time.Time, error) {
+	period := r.URL.Query().Get("period")
+	if period == "" {
+		now := time.Now()
+		return time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.UTC), nil
+	}
+	return services.ParseFinancialPeriod(period)
+}
+
+// Summary returns a month's collected and spent totals and the items pending review
+func (h *AccountantHandler) Summary(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	if !canClosePeriods(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators and accountants can access the accountant workspace")
+		return
+	}
+
+	period, err := accountantPeriod(r)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	summary, err := h.service.Summary(r.Context(), orgID, period)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, summary)
+}
+
+// Ledger returns a month's payments received and posted expenses, optionally of one kind
+func (h *AccountantHandler) Ledger(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	if !canClosePeriods(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators and accountants can access the accountant workspace")
+		return
+	}
+
+	period, err := accountantPeriod(r)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	kind := models.AccountantEntryKind(r.URL.Query().Get("kind"))
+	switch kind {
+	case "", models.AccountantEntryPayment, models.AccountantEntrySessionPayment, models.AccountantEntryExpense:
+	default:
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid entry kind")
+		return
+	}
+
+	entries, err := h.service.Ledger(r.Context(), orgID, period, kind)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": entries,
+		"total": len(entries),
+	})
+}
+
+type RequestExportRequest struct {
+	Period    string `json:"period"` // YYYY-MM
+	SendEmail bool   `json:"send_email"`
+}
+
+// RequestExport queues a month's export bundle; it is built in the background
+func (h *AccountantHandler) RequestExport(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+	if !canClosePeriods(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators and accountants can access the accountant workspace")
+		return
+	}
+
+	var req RequestExportRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	period, err := services.ParseFinancialPeriod(req.Period)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	bundle, err := h.service.RequestExport(r.Context(), orgID, userID, period, req.SendEmail)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusAccepted, "Export requested successfully", bundle)
+}
+
+func (h *AccountantHandler) ListExports(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	if !canClosePeriods(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators and accountants can access the accountant workspace")
+		return
+	}
+
+	limit, offset := parsePage(r)
+	bundles, total, err := h.service.ListExports(r.Context(), orgID, limit, offset)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": bundles,
+		"total": total,
+	})
+}
+
+func (h *AccountantHandler) GetExport(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	if !canClosePeriods(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators and accountants can access the accountant workspace")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid export ID")
+		return
+	}
+
+	bundle, err := h.service.GetExport(r.Context(), id, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, bundle)
+}
+
+// DownloadExport returns a short-lived link to a ready bundle
+func (h *AccountantHandler) DownloadExport(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	if !canClosePeriods(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators and accountants can access the accountant workspace")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid export ID")
+		return
+	}
+
+	url, err := h.service.ExportDownloadURL(r.Context(), id, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]string{"url": url})
+}
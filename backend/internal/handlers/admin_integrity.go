@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+)
+
+type AdminIntegrityHandler struct {
+	integrityService *services.IntegrityService
+	auditService     *services.AdminAuditService
+}
+
+func NewAdminIntegrityHandler(integrityService *services.IntegrityService, auditService *services.AdminAuditService) *AdminIntegrityHandler {
+	return &AdminIntegrityHandler{
+		integrityService: integrityService,
+		auditService:     auditService,
+	}
+}
+
+// Check runs the integrity checks on an organization and returns the issues found
+func (h *AdminIntegrityHandler) Check(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid organization ID")
+		return
+	}
+
+	report, err := h.integrityService.Check(r.Context(), id)
+	if err != nil {
+		if err.Error() == "organization not found" {
+			utils.ErrorResponse(w, http.StatusNotFound, "Organization not found")
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, report)
+}
+
+type RepairIntegrityRequest struct {
+	// Categories to repair; every category that is safe to repair when empty
+	Categories []models.IntegrityCategory `json:"categories"`
+}
+
+// Repair fixes an organization's integrity issues in the categories that are safe to repair
+func (h *AdminIntegrityHandler) Repair(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetSystemAdminID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Admin not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid organization ID")
+		return
+	}
+
+	var req RepairIntegrityRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	result, err := h.integrityService.Repair(r.Context(), id, req.Categories)
+	if err != nil {
+		switch err.Error() {
+		case "organization not found":
+			utils.ErrorResponse(w, http.StatusNotFound, "Organization not found")
+		case "invalid integrity category", "this integrity category cannot be repaired automatically":
+			utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		default:
+			utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	h.auditService.Log(r.Context(), adminID, models.AuditActionIntegrityRepaired, models.AuditEntityOrganization, &id,
+		map[string]interface{}{"repaired": result.Repaired},
+		r.RemoteAddr, r.UserAgent())
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Integrity issues repaired successfully", result)
+}
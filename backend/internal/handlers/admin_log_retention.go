@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+)
+
+type AdminLogRetentionHandler struct {
+	logService   *services.LogRetentionService
+	auditService *services.AdminAuditService
+}
+
+func NewAdminLogRetentionHandler(logService *services.LogRetentionService, auditService *services.AdminAuditService) *AdminLogRetentionHandler {
+	return &AdminLogRetentionHandler{
+		logService:   logService,
+		auditService: auditService,
+	}
+}
+
+// GetPolicy returns an organization's execution log retention and how much of the log it covers
+func (h *AdminLogRetentionHandler) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid organization ID")
+		return
+	}
+
+	policy, err := h.logService.GetPolicy(r.Context(), id)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, policy)
+}
+
+type UpdateLogRetentionRequest struct {
+	RetentionDays *int `json:"retention_days"`
+}
+
+// UpdatePolicy sets an organization's execution log retention; null keeps the log forever
+func (h *AdminLogRetentionHandler) UpdatePolicy(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetSystemAdminID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Admin not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid organization ID")
+		return
+	}
+
+	var req UpdateLogRetentionRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.logService.SetPolicy(r.Context(), id, req.RetentionDays); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.auditService.Log(r.Context(), adminID, models.AuditActionUpdate, models.AuditEntityOrganization, &id,
+		map[string]interface{}{"execution_log_retention_days": req.RetentionDays},
+		r.RemoteAddr, r.UserAgent())
+
+	policy, err := h.logService.GetPolicy(r.Context(), id)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Retention policy updated successfully", policy)
+}
+
+// ListArchives returns an organization's execution log archives, newest first
+func (h *AdminLogRetentionHandler) ListArchives(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid organization ID")
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	archives, total, err := h.logService.ListArchives(r.Context(), id, limit, (page-1)*limit)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to list log archives")
+		return
+	}
+
+	utils.PaginatedResponse(w, http.StatusOK, archives, page, limit, total)
+}
+
+type RequestLogArchiveRequest struct {
+	Before *time.Time `json:"before"` // defaults to the start of the retention period
+}
+
+// RequestArchive queues an archive of an organization's old execution log entries, built by the worker
+func (h *AdminLogRetentionHandler) RequestArchive(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetSystemAdminID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Admin not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid organization ID")
+		return
+	}
+
+	var req RequestLogArchiveRequest
+	if r.ContentLength > 0 {
+		if err := utils.ParseJSON(r, &req); err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	archive, err := h.logService.RequestArchive(r.Context(), id, adminID, req.Before)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.auditService.Log(r.Context(), adminID, models.AuditActionCreate, models.AuditEntityOrganization, &id,
+		map[string]interface{}{"execution_log_archive": archive.ID, "cutoff": archive.Cutoff},
+		r.RemoteAddr, r.UserAgent())
+
+	utils.SuccessMessageResponse(w, http.StatusAccepted, "Log archive requested successfully", archive)
+}
+
+// DownloadArchive returns a short-lived link to a ready archive
+func (h *AdminLogRetentionHandler) DownloadArchive(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetSystemAdminID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Admin not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid organization ID")
+		return
+	}
+	archiveID, err := uuid.Parse(chi.URLParam(r, "archiveId"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid archive ID")
+		return
+	}
+
+	url, err := h.logService.ArchiveDownloadURL(r.Context(), archiveID, id)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.auditService.Log(r.Context(), adminID, models.AuditActionExport, models.AuditEntityOrganization, &id,
+		map[string]interface{}{"execution_log_archive": archiveID},
+		r.RemoteAddr, r.UserAgent())
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]string{"url": url})
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+)
+
+type AdminNotificationQueueHandler struct {
+	queueService *services.NotificationQueueService
+}
+
+func NewAdminNotificationQueueHandler(queueService *services.NotificationQueueService) *AdminNotificationQueueHandler {
+	return &AdminNotificationQueueHandler{
+		queueService: queueService,
+	}
+}
+
+// GetStats returns the depth of the worker queues and the organizations whose messages are
+// waiting on their rate limits
+func (h *AdminNotificationQueueHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.queueService.Stats(r.Context())
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, stats)
+}
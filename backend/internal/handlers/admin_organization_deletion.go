@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+)
+
+type AdminOrganizationDeletionHandler struct {
+	deletionService *services.OrganizationDeletionService
+	auditService    *services.AdminAuditService
+}
+
+func NewAdminOrganizationDeletionHandler(deletionService *services.OrganizationDeletionService, auditService *services.AdminAuditService) *AdminOrganizationDeletionHandler {
+	return &AdminOrganizationDeletionHandler{
+		deletionService: deletionService,
+		auditService:    auditService,
+	}
+}
+
+// List returns organization deletion requests, optionally of one organization or status
+func (h *AdminOrganizationDeletionHandler) List(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	filters := services.OrganizationDeletionFilters{
+		Status: r.URL.Query().Get("status"),
+		Page:   page,
+		Limit:  limit,
+	}
+	if orgIDStr := r.URL.Query().Get("organization_id"); orgIDStr != "" {
+		if id, err := uuid.Parse(orgIDStr); err == nil {
+			filters.OrganizationID = &id
+		}
+	}
+
+	requests, total, err := h.deletionService.List(r.Context(), filters)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to list deletion requests")
+		return
+	}
+
+	utils.PaginatedResponse(w, http.StatusOK, requests, page, limit, total)
+}
+
+// GetByID returns a deletion request with every step taken
+func (h *AdminOrganizationDeletionHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid deletion request ID")
+		return
+	}
+
+	req, err := h.deletionService.GetByID(r.Context(), id)
+	if err != nil {
+		h.deletionError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, req)
+}
+
+// Cancel stops a deletion request during its cooling-off period on the organization's behalf
+func (h *AdminOrganizationDeletionHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetSystemAdminID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Admin not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid deletion request ID")
+		return
+	}
+
+	if err := h.deletionService.Cancel(r.Context(), id, nil, nil, &adminID); err != nil {
+		h.deletionError(w, err)
+		return
+	}
+
+	h.auditService.Log(r.Context(), adminID, models.AuditActionDeletionCancelled, models.AuditEntityDeletionRequest, &id,
+		nil,
+		r.RemoteAddr, r.UserAgent())
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Organization deletion cancelled successfully", nil)
+}
+
+// Retry resumes a failed deletion request from the step that failed
+func (h *AdminOrganizationDeletionHandler) Retry(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetSystemAdminID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Admin not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid deletion request ID")
+		return
+	}
+
+	req, err := h.deletionService.Retry(r.Context(), id, adminID)
+	if err != nil {
+		h.deletionError(w, err)
+		return
+	}
+
+	h.auditService.Log(r.Context(), adminID, models.AuditActionDeletionRetried, models.AuditEntityDeletionRequest, &id,
+		map[string]interface{}{"organization_id": req.OrganizationID},
+		r.RemoteAddr, r.UserAgent())
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Organization deletion resumed successfully", req)
+}
+
+// DownloadExport returns a temporary link to the final export of an organization being deleted
+func (h *AdminOrganizationDeletionHandler) DownloadExport(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetSystemAdminID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Admin not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid deletion request ID")
+		return
+	}
+
+	url, err := h.deletionService.ExportDownloadURL(r.Context(), id)
+	if err != nil {
+		h.deletionError(w, err)
+		return
+	}
+
+	h.auditService.Log(r.Context(), adminID, models.AuditActionExport, models.AuditEntityDeletionRequest, &id,
+		map[string]interface{}{"organization_export": true},
+		r.RemoteAddr, r.UserAgent())
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]string{"url": url})
+}
+
+func (h *AdminOrganizationDeletionHandler) deletionError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case "deletion request not found", "deletion request not found or not failed":
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	default:
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	}
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+)
+
+type AdminOrganizationMergeHandler struct {
+	mergeService *services.OrganizationMergeService
+	auditService *services.AdminAuditService
+}
+
+func NewAdminOrganizationMergeHandler(mergeService *services.OrganizationMergeService, auditService *services.AdminAuditService) *AdminOrganizationMergeHandler {
+	return &AdminOrganizationMergeHandler{
+		mergeService: mergeService,
+		auditService: auditService,
+	}
+}
+
+// List returns the merges an organization took part in, newest first
+func (h *AdminOrganizationMergeHandler) List(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid organization ID")
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	merges, total, err := h.mergeService.ListMerges(r.Context(), id, limit, (page-1)*limit)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to list merges")
+		return
+	}
+
+	utils.PaginatedResponse(w, http.StatusOK, merges, page, limit, total)
+}
+
+type CreateOrganizationMergeRequest struct {
+	SourceOrganizationID uuid.UUID                     `json:"source_organization_id"`
+	DryRun               bool                          `json:"dry_run"`
+	Rules                models.OrganizationMergeRules `json:"rules"`
+}
+
+// Create queues a merge of the source organization into this one, run by the worker.
+// A dry run reports what would be moved and how conflicts would be resolved, without changes.
+func (h *AdminOrganizationMergeHandler) Create(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetSystemAdminID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Admin not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid organization ID")
+		return
+	}
+
+	var req CreateOrganizationMergeRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.SourceOrganizationID == uuid.Nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Source organization is required")
+		return
+	}
+
+	merge, err := h.mergeService.RequestMerge(r.Context(), id, req.SourceOrganizationID, adminID, req.Rules, req.DryRun)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !req.DryRun {
+		for _, orgID := range []uuid.UUID{id, req.SourceOrganizationID} {
+			h.auditService.Log(r.Context(), adminID, models.AuditActionUpdate, models.AuditEntityOrganization, &orgID,
+				map[string]interface{}{"organization_merge": merge.ID, "source": req.SourceOrganizationID, "target": id, "rules": merge.Rules},
+				r.RemoteAddr, r.UserAgent())
+		}
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusAccepted, "Organization merge requested successfully", merge)
+}
+
+// Get returns a merge with the report of its completed steps
+func (h *AdminOrganizationMergeHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid organization ID")
+		return
+	}
+	mergeID, err := uuid.Parse(chi.URLParam(r, "mergeId"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid merge ID")
+		return
+	}
+
+	merge, err := h.mergeService.GetMerge(r.Context(), mergeID, id)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, merge)
+}
+
+// Resume queues a failed merge again from the step that failed
+func (h *AdminOrganizationMergeHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetSystemAdminID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Admin not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid organization ID")
+		return
+	}
+	mergeID, err := uuid.Parse(chi.URLParam(r, "mergeId"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid merge ID")
+		return
+	}
+
+	merge, err := h.mergeService.ResumeMerge(r.Context(), mergeID, id)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.auditService.Log(r.Context(), adminID, models.AuditActionUpdate, models.AuditEntityOrganization, &id,
+		map[string]interface{}{"organization_merge": merge.ID, "resumed": true},
+		r.RemoteAddr, r.UserAgent())
+
+	utils.SuccessMessageResponse(w, http.StatusAccepted, "Organization merge resumed successfully", merge)
+}
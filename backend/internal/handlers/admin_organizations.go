@@ -354,3 +354,37 @@ func (h *AdminOrganizationsHandler) DisableModule(w http.ResponseWriter, r *http
 		"message": "Module disabled successfully",
 	})
 }
+
+// PublishModuleChangelog adds a release note to a module's what's-new feed
+func (h *AdminOrganizationsHandler) PublishModuleChangelog(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetSystemAdminID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Admin not found in context")
+		return
+	}
+
+	moduleName := chi.URLParam(r, "module")
+	if moduleName == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Module name is required")
+		return
+	}
+
+	var req services.PublishChangelogRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	entry, err := h.moduleService.PublishChangelog(r.Context(), models.ModuleName(moduleName), req)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Audit log
+	h.auditService.Log(r.Context(), adminID, models.AuditActionCreate, models.AuditEntityModule, &entry.ID,
+		map[string]interface{}{"action": "publish_changelog", "module": moduleName, "version": entry.Version},
+		r.RemoteAddr, r.UserAgent())
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Module changelog published successfully", entry)
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+)
+
+type AdminSecurityHandler struct {
+	securityService *services.AdminSecurityService
+	auditService    *services.AdminAuditService
+}
+
+func NewAdminSecurityHandler(securityService *services.AdminSecurityService, auditService *services.AdminAuditService) *AdminSecurityHandler {
+	return &AdminSecurityHandler{
+		securityService: securityService,
+		auditService:    auditService,
+	}
+}
+
+// List returns security alerts raised on admin activity
+func (h *AdminSecurityHandler) List(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	filters := services.AdminSecurityAlertFilters{
+		Status: r.URL.Query().Get("status"),
+		Rule:   r.URL.Query().Get("rule"),
+		Page:   page,
+		Limit:  limit,
+	}
+	if adminIDStr := r.URL.Query().Get("admin_id"); adminIDStr != "" {
+		if id, err := uuid.Parse(adminIDStr); err == nil {
+			filters.AdminID = &id
+		}
+	}
+
+	alerts, total, err := h.securityService.List(r.Context(), filters)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to list security alerts")
+		return
+	}
+
+	utils.PaginatedResponse(w, http.StatusOK, alerts, page, limit, total)
+}
+
+func (h *AdminSecurityHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid security alert ID")
+		return
+	}
+
+	alert, err := h.securityService.GetByID(r.Context(), id)
+	if err != nil {
+		if err.Error() == "security alert not found" {
+			utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, alert)
+}
+
+// JustifySecurityAlertRequest is the flagged admin's explanation of the activity
+type JustifySecurityAlertRequest struct {
+	Justification string `json:"justification"`
+}
+
+// Justify records the flagged admin's justification, which lifts the hold on sensitive actions
+// once no other alert is open
+func (h *AdminSecurityHandler) Justify(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetSystemAdminID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Admin not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid security alert ID")
+		return
+	}
+
+	var req JustifySecurityAlertRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	alert, err := h.securityService.Justify(r.Context(), id, adminID, req.Justification)
+	if err != nil {
+		h.alertError(w, err)
+		return
+	}
+
+	h.auditService.Log(r.Context(), adminID, models.AuditActionAlertJustified, models.AuditEntitySecurityAlert, &id,
+		map[string]interface{}{"rule": alert.Rule, "justification": req.Justification},
+		r.RemoteAddr, r.UserAgent())
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Security alert justified successfully", alert)
+}
+
+// Acknowledge marks another admin's alert as reviewed
+func (h *AdminSecurityHandler) Acknowledge(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, false)
+}
+
+// Dismiss closes another admin's open alert without a justification
+func (h *AdminSecurityHandler) Dismiss(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, true)
+}
+
+func (h *AdminSecurityHandler) review(w http.ResponseWriter, r *http.Request, dismiss bool) {
+	adminID, ok := middleware.GetSystemAdminID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Admin not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid security alert ID")
+		return
+	}
+
+	alert, err := h.securityService.Review(r.Context(), id, adminID, dismiss)
+	if err != nil {
+		h.alertError(w, err)
+		return
+	}
+
+	if dismiss {
+		h.auditService.Log(r.Context(), adminID, models.AuditActionAlertDismissed, models.AuditEntitySecurityAlert, &id,
+			map[string]interface{}{"rule": alert.Rule, "flagged_admin_id": alert.AdminID},
+			r.RemoteAddr, r.UserAgent())
+		utils.SuccessMessageResponse(w, http.StatusOK, "Security alert dismissed successfully", alert)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Security alert acknowledged successfully", alert)
+}
+
+func (h *AdminSecurityHandler) alertError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case "security alert not found":
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	case "only the flagged admin can justify this alert", "admins cannot review their own alerts":
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+	default:
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	}
+}
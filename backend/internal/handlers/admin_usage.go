@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+)
+
+type AdminUsageHandler struct {
+	usageService *services.UsageService
+	auditService *services.AdminAuditService
+}
+
+func NewAdminUsageHandler(usageService *services.UsageService, auditService *services.AdminAuditService) *AdminUsageHandler {
+	return &AdminUsageHandler{
+		usageService: usageService,
+		auditService: auditService,
+	}
+}
+
+// List returns the organizations' usage, largest storage first; ?warnings=true keeps only
+// organizations approaching or over a quota
+func (h *AdminUsageHandler) List(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	warningsOnly := r.URL.Query().Get("warnings") == "true"
+
+	usages, total, err := h.usageService.List(r.Context(), warningsOnly, limit, (page-1)*limit)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to list usage")
+		return
+	}
+
+	utils.PaginatedResponse(w, http.StatusOK, usages, page, limit, total)
+}
+
+// GetByOrganization returns an organization's usage; ?refresh=true recomputes it first
+func (h *AdminUsageHandler) GetByOrganization(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid organization ID")
+		return
+	}
+
+	if r.URL.Query().Get("refresh") == "true" {
+		if err := h.usageService.Refresh(r.Context(), id); err != nil {
+			utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	usage, err := h.usageService.Get(r.Context(), id)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "Organization not found")
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, usage)
+}
+
+type UpdateQuotasRequest struct {
+	StorageQuotaBytes   *int64 `json:"storage_quota_bytes"`
+	MonthlyMessageQuota *int   `json:"monthly_message_quota"`
+}
+
+// UpdateQuotas sets an organization's quotas; a null quota is unlimited
+func (h *AdminUsageHandler) UpdateQuotas(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetSystemAdminID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Admin not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid organization ID")
+		return
+	}
+
+	var req UpdateQuotasRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.usageService.SetQuotas(r.Context(), id, req.StorageQuotaBytes, req.MonthlyMessageQuota); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.auditService.Log(r.Context(), adminID, models.AuditActionUpdate, models.AuditEntityOrganization, &id,
+		map[string]interface{}{"quotas": req},
+		r.RemoteAddr, r.UserAgent())
+
+	usage, err := h.usageService.Get(r.Context(), id)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Quotas updated successfully", usage)
+}
@@ -0,0 +1,392 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type BankStatementHandler struct {
+	service *services.BankStatementService
+}
+
+func NewBankStatementHandler(service *services.BankStatementService) *BankStatementHandler {
+	return &BankStatementHandler{service: service}
+}
+
+// canReconcileBank reports whether the user may import statements and match their lines
+func canReconcileBank(r *http.Request) bool {
+	role, ok := middleware.GetUserRole(r.Context())
+	return ok && (role == string(models.RoleAdmin) || role == string(models.RoleManager) || role == string(models.RoleAccountant))
+}
+
+// parsePage reads the limit (default 50, max 100) and offset query parameters
+func parsePage(r *http.Request) (int, int) {
+	limit, offset := 50, 0
+	if parsed, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && parsed > 0 && parsed <= 100 {
+		limit = parsed
+	}
+	if parsed, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && parsed >= 0 {
+		offset = parsed
+	}
+	return limit, offset
+}
+
+func (h *BankStatementHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	if !canReconcileBank(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators, managers and accountants can reconcile bank statements")
+		return
+	}
+
+	limit, offset := parsePage(r)
+	statements, total, err := h.service.ListStatements(r.Context(), orgID, limit, offset)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": statements,
+		"total": total,
+	})
+}
+
+func (h *BankStatementHandler) Get(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	if !canReconcileBank(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators, managers and accountants can reconcile bank statements")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid statement ID")
+		return
+	}
+
+	statement, err := h.service.GetStatement(r.Context(), id, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, statement)
+}
+
+// Import reads a statement file. Multipart fields: file, and an optional format (csv or camt)
+// that is otherwise detected from the content.
+func (h *BankStatementHandler) Import(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+	if !canReconcileBank(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators, managers and accountants can reconcile bank statements")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, services.MaxStatementUploadSize+1<<20)
+	if err := r.ParseMultipartForm(services.MaxStatementUploadSize); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid upload or file too large")
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "File is required")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, services.MaxStatementUploadSize+1))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Failed to read file")
+		return
+	}
+	if int64(len(data)) > services.MaxStatementUploadSize {
+		utils.ErrorResponse(w, http.StatusBadRequest, "File is too large")
+		return
+	}
+
+	statement, err := h.service.ImportFile(r.Context(), orgID, userID, r.FormValue("format"), header.Filename, data)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Statement imported successfully", statement)
+}
+
+// Sync imports an account's transactions from the bank aggregator
+func (h *BankStatementHandler) Sync(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+	if !canReconcileBank(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators, managers and accountants can reconcile bank statements")
+		return
+	}
+
+	var req struct {
+		AccountID string `json:"account_id"`
+		From      string `json:"from"`
+		To        string `json:"to"`
+	}
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	from, err := time.Parse("2006-01-02", req.From)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid date format, expected YYYY-MM-DD")
+		return
+	}
+	to, err := time.Parse("2006-01-02", req.To)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid date format, expected YYYY-MM-DD")
+		return
+	}
+
+	statement, err := h.service.Sync(r.Context(), orgID, userID, req.AccountID, from, to)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Statement imported successfully", statement)
+}
+
+// AutoMatch matches the unmatched lines of a statement to their clear candidates
+func (h *BankStatementHandler) AutoMatch(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+	if !canReconcileBank(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators, managers and accountants can reconcile bank statements")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid statement ID")
+		return
+	}
+	if _, err := h.service.GetStatement(r.Context(), id, orgID); err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	result, err := h.service.AutoMatch(r.Context(), orgID, userID, &id)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, result)
+}
+
+// ListLines returns statement lines filtered by statement_id and status
+func (h *BankStatementHandler) ListLines(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	if !canReconcileBank(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators, managers and accountants can reconcile bank statements")
+		return
+	}
+
+	filters := services.BankLineFilters{Status: r.URL.Query().Get("status")}
+	filters.Limit, filters.Offset = parsePage(r)
+	if raw := chi.URLParam(r, "id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid statement ID")
+			return
+		}
+		filters.StatementID = &id
+	} else if raw := r.URL.Query().Get("statement_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid statement ID")
+			return
+		}
+		filters.StatementID = &id
+	}
+
+	lines, total, err := h.service.ListLines(r.Context(), orgID, filters)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": lines,
+		"total": total,
+	})
+}
+
+// Suggestions returns the open payments a line may settle, best match first
+func (h *BankStatementHandler) Suggestions(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	if !canReconcileBank(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators, managers and accountants can reconcile bank statements")
+		return
+	}
+
+	lineID, err := uuid.Parse(chi.URLParam(r, "lineId"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid statement line ID")
+		return
+	}
+
+	candidates, err := h.service.Suggestions(r.Context(), lineID, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, candidates)
+}
+
+// Match links a line to a payment ({"kind": "payment"|"session_payment", "id": ...}) and marks it paid
+func (h *BankStatementHandler) Match(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+	if !canReconcileBank(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators, managers and accountants can reconcile bank statements")
+		return
+	}
+
+	lineID, err := uuid.Parse(chi.URLParam(r, "lineId"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid statement line ID")
+		return
+	}
+
+	var req struct {
+		Kind models.BankMatchKind `json:"kind"`
+		ID   uuid.UUID            `json:"id"`
+	}
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	line, err := h.service.Match(r.Context(), lineID, orgID, userID, req.Kind, req.ID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Transaction matched successfully", line)
+}
+
+// Unmatch reopens a matched line and its payment, or restores an ignored line
+func (h *BankStatementHandler) Unmatch(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	if !canReconcileBank(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators, managers and accountants can reconcile bank statements")
+		return
+	}
+
+	lineID, err := uuid.Parse(chi.URLParam(r, "lineId"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid statement line ID")
+		return
+	}
+
+	line, err := h.service.Unmatch(r.Context(), lineID, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Transaction unmatched successfully", line)
+}
+
+// Ignore marks a line that needs no match, such as a bank fee
+func (h *BankStatementHandler) Ignore(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+	if !canReconcileBank(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators, managers and accountants can reconcile bank statements")
+		return
+	}
+
+	lineID, err := uuid.Parse(chi.URLParam(r, "lineId"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid statement line ID")
+		return
+	}
+
+	line, err := h.service.Ignore(r.Context(), lineID, orgID, userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Transaction ignored successfully", line)
+}
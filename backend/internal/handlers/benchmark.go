@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+)
+
+// BenchmarkHandler manages the organization's benchmarking opt-in and its comparison report
+type BenchmarkHandler struct {
+	service *services.BenchmarkService
+}
+
+func NewBenchmarkHandler(service *services.BenchmarkService) *BenchmarkHandler {
+	return &BenchmarkHandler{service: service}
+}
+
+// GetParticipation returns whether the organization shares its metrics for benchmarking
+func (h *BenchmarkHandler) GetParticipation(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	participation, err := h.service.GetParticipation(r.Context(), orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, participation)
+}
+
+// SetParticipation opts the organization in to ({"opted_in": true}) or out of benchmarking
+func (h *BenchmarkHandler) SetParticipation(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	role, _ := middleware.GetUserRole(r.Context())
+	if role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can change benchmarking participation")
+		return
+	}
+
+	var req struct {
+		OptedIn bool `json:"opted_in"`
+	}
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	participation, err := h.service.SetParticipation(r.Context(), orgID, userID, req.OptedIn)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Benchmarking participation updated successfully", participation)
+}
+
+// Report compares the organization's metrics to the anonymized percentile bands of the participants
+func (h *BenchmarkHandler) Report(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	role, _ := middleware.GetUserRole(r.Context())
+	if role != string(models.RoleAdmin) && role != string(models.RoleManager) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators and managers can view benchmarks")
+		return
+	}
+
+	report, err := h.service.Report(r.Context(), orgID)
+	if err != nil {
+		if err.Error() == "organization is not taking part in benchmarking" {
+			utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, report)
+}
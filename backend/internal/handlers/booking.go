@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// defaultSlotRangeDays is how many days of slots are returned when no end date is given
+const defaultSlotRangeDays = 7
+
+type BookingHandler struct {
+	service *services.BookingService
+}
+
+func NewBookingHandler(service *services.BookingService) *BookingHandler {
+	return &BookingHandler{service: service}
+}
+
+// BookableServiceRequest is the body for creating or updating a catalogue service
+type BookableServiceRequest struct {
+	Name            string      `json:"name"`
+	Description     *string     `json:"description"`
+	DurationMinutes int         `json:"duration_minutes"`
+	PriceCents      int         `json:"price_cents"`
+	SessionType     string      `json:"session_type"`
+	IsPublic        bool        `json:"is_public"`
+	IsActive        *bool       `json:"is_active"`
+	Position        int         `json:"position"`
+	TherapistIDs    []uuid.UUID `json:"therapist_ids"`
+}
+
+func (req BookableServiceRequest) toModel(orgID uuid.UUID) *models.BookableService {
+	bs := &models.BookableService{
+		OrganizationID:  orgID,
+		Name:            req.Name,
+		Description:     req.Description,
+		DurationMinutes: req.DurationMinutes,
+		PriceCents:      req.PriceCents,
+		SessionType:     models.SessionType(req.SessionType),
+		IsPublic:        req.IsPublic,
+		IsActive:        true,
+		Position:        req.Position,
+		TherapistIDs:    req.TherapistIDs,
+	}
+	if req.IsActive != nil {
+		bs.IsActive = *req.IsActive
+	}
+	return bs
+}
+
+// List returns the service catalogue
+func (h *BookingHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	activeOnly := r.URL.Query().Get("active") == "true"
+	items, err := h.service.List(r.Context(), orgID, activeOnly)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": items,
+		"total": len(items),
+	})
+}
+
+// Get returns a catalogue service
+func (h *BookingHandler) Get(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid service ID")
+		return
+	}
+
+	bs, err := h.service.GetByID(r.Context(), id, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, bs)
+}
+
+// Create adds a service to the catalogue (admin only)
+func (h *BookingHandler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only admins can manage the service catalogue")
+		return
+	}
+
+	var req BookableServiceRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	bs := req.toModel(orgID)
+	if err := h.service.Create(r.Context(), bs); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Service created successfully", bs)
+}
+
+// Update updates a catalogue service (admin only)
+func (h *BookingHandler) Update(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only admins can manage the service catalogue")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid service ID")
+		return
+	}
+
+	var req BookableServiceRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	bs := req.toModel(orgID)
+	bs.ID = id
+	if err := h.service.Update(r.Context(), bs); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Service updated successfully", bs)
+}
+
+// Delete removes a service from the catalogue (admin only)
+func (h *BookingHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only admins can manage the service catalogue")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid service ID")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id, orgID); err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Service deleted successfully", nil)
+}
+
+// ============ Public Booking Handlers ============
+
+// PublicServices lists the services an organization offers for online booking
+func (h *BookingHandler) PublicServices(w http.ResponseWriter, r *http.Request) {
+	orgID, err := uuid.Parse(chi.URLParam(r, "orgId"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid organization ID")
+		return
+	}
+
+	items, err := h.service.ListPublic(r.Context(), orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": items,
+		"total": len(items),
+	})
+}
+
+// PublicSlots returns the free booking slots of a public service.
+// Query params: from and to (YYYY-MM-DD, inclusive); defaults to the next 7 days.
+func (h *BookingHandler) PublicSlots(w http.ResponseWriter, r *http.Request) {
+	orgID, err := uuid.Parse(chi.URLParam(r, "orgId"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid organization ID")
+		return
+	}
+
+	serviceID, err := uuid.Parse(chi.URLParam(r, "serviceId"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid service ID")
+		return
+	}
+
+	from, to, ok := parseSlotRange(w, r)
+	if !ok {
+		return
+	}
+
+	slots, err := h.service.GetPublicSlots(r.Context(), orgID, serviceID, from, to)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": slots,
+		"total": len(slots),
+	})
+}
+
+// parseSlotRange reads the from and to query params (YYYY-MM-DD, inclusive), defaulting to the
+// next 7 days. It writes the error response and returns false when a date is invalid.
+func parseSlotRange(w http.ResponseWriter, r *http.Request) (time.Time, time.Time, bool) {
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if raw := r.URL.Query().Get("from"); raw != "" {
+		parsed, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid start date format")
+			return time.Time{}, time.Time{}, false
+		}
+		from = parsed
+	}
+
+	to := from.AddDate(0, 0, defaultSlotRangeDays)
+	if raw := r.URL.Query().Get("to"); raw != "" {
+		parsed, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid end date format")
+			return time.Time{}, time.Time{}, false
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+
+	return from, to, true
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// embedWidgetPath is where the booking widget script is served, relative to the API
+const embedWidgetPath = "/public/embed/widget.js"
+
+// ============ Embeddable Availability Handlers ============
+
+// EmbedAvailability returns an organization's public services and free slots for booking widgets
+// on customer websites. It is read-only and answers cross-origin GETs from the websites the
+// organization allowed (any website when none are listed). The request needs no custom headers,
+// so browsers send it without a preflight.
+// Query params: service_id (optional), from and to (YYYY-MM-DD, inclusive); defaults to the next 7 days.
+func (h *BookingHandler) EmbedAvailability(w http.ResponseWriter, r *http.Request) {
+	orgID, err := uuid.Parse(chi.URLParam(r, "orgId"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid organization ID")
+		return
+	}
+
+	allowed, err := h.service.EmbedAllowedOrigins(r.Context(), orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+	origin := r.Header.Get("Origin")
+	if !services.EmbedOriginAllowed(allowed, origin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Website not allowed to embed this organization")
+		return
+	}
+	if len(allowed) == 0 {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	} else {
+		if origin != "" {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		w.Header().Add("Vary", "Origin")
+	}
+
+	var serviceID *uuid.UUID
+	if raw := r.URL.Query().Get("service_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid service ID")
+			return
+		}
+		serviceID = &id
+	}
+
+	from, to, ok := parseSlotRange(w, r)
+	if !ok {
+		return
+	}
+
+	availability, err := h.service.GetEmbedAvailability(r.Context(), orgID, serviceID, from, to)
+	if err != nil {
+		switch err.Error() {
+		case "organization not found", "service not found":
+			utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		default:
+			utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	utils.SuccessResponse(w, http.StatusOK, availability)
+}
+
+// EmbedWidget serves the booking widget script customers include on their websites
+func (h *BookingHandler) EmbedWidget(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Header().Set("Cross-Origin-Resource-Policy", "cross-origin")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(embedWidgetScript))
+}
+
+// GetEmbedSettings returns the websites allowed to embed availability and the snippet to paste (admin only)
+func (h *BookingHandler) GetEmbedSettings(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only admins can manage the booking widget")
+		return
+	}
+
+	settings, err := h.service.GetEmbedSettings(r.Context(), orgID, embedScriptURL(r))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, settings)
+}
+
+// UpdateEmbedSettingsRequest lists the websites allowed to embed availability
+type UpdateEmbedSettingsRequest struct {
+	AllowedOrigins []string `json:"allowed_origins"`
+}
+
+// UpdateEmbedSettings replaces the websites allowed to embed availability (admin only)
+func (h *BookingHandler) UpdateEmbedSettings(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only admins can manage the booking widget")
+		return
+	}
+
+	var req UpdateEmbedSettingsRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	settings, err := h.service.UpdateEmbedSettings(r.Context(), orgID, req.AllowedOrigins, embedScriptURL(r))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Booking widget updated successfully", settings)
+}
+
+// embedScriptURL is the absolute address of the widget script as seen by the caller
+func embedScriptURL(r *http.Request) string {
+	scheme := "https"
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	} else if r.TLS == nil {
+		scheme = "http"
+	}
+	return scheme + "://" + r.Host + embedWidgetPath
+}
+
+// embedWidgetScript renders the availability of an organization into an element of the host page.
+// Contract: the script tag carries data-organization (required), data-target (element id,
+// default "controlwise-booking"), data-service and data-days (optional). Each slot links to the
+// public booking page, where the booking is made.
+const embedWidgetScript = `(function () {
+  var script = document.currentScript;
+  if (!script) return;
+  var orgId = script.getAttribute("data-organization");
+  var target = document.getElementById(script.getAttribute("data-target") || "controlwise-booking");
+  if (!orgId || !target) return;
+
+  var base = script.src.slice(0, script.src.indexOf("` + embedWidgetPath + `"));
+  var days = parseInt(script.getAttribute("data-days") || "7", 10);
+  var from = new Date();
+  var to = new Date(from.getTime() + (days - 1) * 86400000);
+  var day = function (d) { return d.toISOString().slice(0, 10); };
+  var url = base + "/public/embed/" + encodeURIComponent(orgId) + "/availability?from=" + day(from) + "&to=" + day(to);
+  var service = script.getAttribute("data-service");
+  if (service) url += "&service_id=" + encodeURIComponent(service);
+
+  var el = function (tag, cls, text) {
+    var node = document.createElement(tag);
+    if (cls) node.className = cls;
+    if (text) node.textContent = text;
+    return node;
+  };
+
+  fetch(url).then(function (res) { return res.json(); }).then(function (body) {
+    if (!body || !body.data) return;
+    target.textContent = "";
+    body.data.services.forEach(function (svc) {
+      var box = el("div", "cw-service");
+      box.appendChild(el("h3", "cw-service-name", svc.name));
+      var list = el("div", "cw-slots");
+      svc.slots.forEach(function (slot) {
+        var link = el("a", "cw-slot", new Date(slot.start).toLocaleString([], { dateStyle: "short", timeStyle: "short" }));
+        link.href = slot.booking_url;
+        link.target = "_blank";
+        link.rel = "noopener";
+        list.appendChild(link);
+      });
+      if (!svc.slots.length) {
+        var more = el("a", "cw-book", "Book now");
+        more.href = svc.booking_url;
+        more.target = "_blank";
+        more.rel = "noopener";
+        list.appendChild(more);
+      }
+      box.appendChild(list);
+      target.appendChild(box);
+    });
+  }).catch(function () {});
+})();
+`
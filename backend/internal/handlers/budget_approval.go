@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type BudgetApprovalHandler struct {
+	service *services.BudgetService
+}
+
+func NewBudgetApprovalHandler(service *services.BudgetService) *BudgetApprovalHandler {
+	return &BudgetApprovalHandler{service: service}
+}
+
+type BudgetApprovalRuleRequest struct {
+	Name         string          `json:"name"`
+	MinTotal     decimal.Decimal `json:"min_total"`
+	RequiredRole string          `json:"required_role"`
+	IsActive     *bool           `json:"is_active"`
+}
+
+type InternalApprovalDecisionRequest struct {
+	Comment *string `json:"comment"`
+}
+
+// ============ Rule Handlers ============
+
+// ListRules returns the internal approval rules
+func (h *BudgetApprovalHandler) ListRules(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	rules, err := h.service.ListApprovalRules(r.Context(), orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": rules,
+		"total": len(rules),
+	})
+}
+
+// CreateRule creates an internal approval rule
+func (h *BudgetApprovalHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can manage approval rules")
+		return
+	}
+
+	var req BudgetApprovalRuleRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	rule := &models.BudgetApprovalRule{
+		OrganizationID: orgID,
+		Name:           req.Name,
+		MinTotal:       req.MinTotal,
+		RequiredRole:   models.Role(req.RequiredRole),
+	}
+
+	if err := h.service.CreateApprovalRule(r.Context(), rule); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Approval rule created successfully", rule)
+}
+
+// UpdateRule updates an internal approval rule
+func (h *BudgetApprovalHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can manage approval rules")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid rule ID")
+		return
+	}
+
+	var req BudgetApprovalRuleRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	rule := &models.BudgetApprovalRule{
+		Name:         req.Name,
+		MinTotal:     req.MinTotal,
+		RequiredRole: models.Role(req.RequiredRole),
+		IsActive:     req.IsActive == nil || *req.IsActive,
+	}
+
+	if err := h.service.UpdateApprovalRule(r.Context(), id, orgID, rule); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Approval rule updated successfully", rule)
+}
+
+// DeleteRule deletes an internal approval rule
+func (h *BudgetApprovalHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can manage approval rules")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid rule ID")
+		return
+	}
+
+	if err := h.service.DeleteApprovalRule(r.Context(), id, orgID); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Approval rule deleted successfully", nil)
+}
+
+// ============ Budget Sign-off Handlers ============
+
+// ListApprovals returns the sign-off requests and audit trail of a budget
+func (h *BudgetApprovalHandler) ListApprovals(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid budget ID")
+		return
+	}
+
+	approvals, err := h.service.ListInternalApprovals(r.Context(), id, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	audit, err := h.service.ListApprovalAudit(r.Context(), id, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": approvals,
+		"total": len(approvals),
+		"audit": audit,
+	})
+}
+
+// Approve signs off a budget for the current user's role
+func (h *BudgetApprovalHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	orgID, userID, role, budgetID, ok := h.parseDecisionRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req InternalApprovalDecisionRequest
+	if r.ContentLength > 0 {
+		if err := utils.ParseJSON(r, &req); err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	status, err := h.service.ApproveInternal(r.Context(), budgetID, orgID, userID, role, req.Comment)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	message := "Budget signed off successfully"
+	if status == models.BudgetStatusSent {
+		message = "Budget fully approved and sent to the client"
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, message, map[string]interface{}{
+		"status": status,
+	})
+}
+
+// Reject rejects the sign-off for the current user's role and returns the budget to draft
+func (h *BudgetApprovalHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	orgID, userID, role, budgetID, ok := h.parseDecisionRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req InternalApprovalDecisionRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	comment := ""
+	if req.Comment != nil {
+		comment = *req.Comment
+	}
+
+	if err := h.service.RejectInternal(r.Context(), budgetID, orgID, userID, role, comment); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Budget returned to draft", map[string]interface{}{
+		"status": models.BudgetStatusDraft,
+	})
+}
+
+// parseDecisionRequest extracts the organization, user, role and budget ID for sign-off routes
+func (h *BudgetApprovalHandler) parseDecisionRequest(w http.ResponseWriter, r *http.Request) (orgID, userID uuid.UUID, role models.Role, budgetID uuid.UUID, ok bool) {
+	orgID, ok = middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	userID, ok = middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	roleStr, _ := middleware.GetUserRole(r.Context())
+	role = models.Role(roleStr)
+
+	var err error
+	budgetID, err = uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid budget ID")
+		return orgID, userID, role, budgetID, false
+	}
+
+	return orgID, userID, role, budgetID, true
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// GeneratePDF returns a short-lived link to the budget PDF, generating it when the budget
+// changed since the last one
+func (h *BudgetHandler) GeneratePDF(w http.ResponseWriter, r *http.Request) {
+	h.budgetPDF(w, r, false)
+}
+
+// RegeneratePDF rebuilds the budget PDF and returns a short-lived link to it
+func (h *BudgetHandler) RegeneratePDF(w http.ResponseWriter, r *http.Request) {
+	h.budgetPDF(w, r, true)
+}
+
+func (h *BudgetHandler) budgetPDF(w http.ResponseWriter, r *http.Request, regenerate bool) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid budget ID")
+		return
+	}
+
+	doc, err := h.service.GetPDF(r.Context(), id, orgID, regenerate)
+	if err != nil {
+		if err.Error() == "budget not found" {
+			utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, doc)
+}
+
+// GetPDFTemplate returns the organization's budget PDF branding
+func (h *BudgetHandler) GetPDFTemplate(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	template, err := h.service.GetPDFTemplate(r.Context(), orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, template)
+}
+
+// UpdatePDFTemplateRequest is the branding applied to budget PDFs
+type UpdatePDFTemplateRequest struct {
+	Layout        models.BudgetPDFLayout `json:"layout"`       // classic (default) or compact
+	AccentColor   string                 `json:"accent_color"` // #RRGGBB
+	Title         *string                `json:"title"`
+	HeaderText    *string                `json:"header_text"`
+	FooterText    *string                `json:"footer_text"`
+	Terms         *string                `json:"terms"`
+	ShowLogo      bool                   `json:"show_logo"`
+	ShowItemTax   bool                   `json:"show_item_tax"`
+	ShowSignature bool                   `json:"show_signature"`
+}
+
+// UpdatePDFTemplate replaces the organization's budget PDF branding (admin only)
+func (h *BudgetHandler) UpdatePDFTemplate(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only admins can manage the budget PDF template")
+		return
+	}
+
+	var req UpdatePDFTemplateRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	template, err := h.service.UpdatePDFTemplate(r.Context(), orgID, models.BudgetPDFTemplate{
+		Layout:        req.Layout,
+		AccentColor:   req.AccentColor,
+		Title:         req.Title,
+		HeaderText:    req.HeaderText,
+		FooterText:    req.FooterText,
+		Terms:         req.Terms,
+		ShowLogo:      req.ShowLogo,
+		ShowItemTax:   req.ShowItemTax,
+		ShowSignature: req.ShowSignature,
+	})
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Budget PDF template updated successfully", template)
+}
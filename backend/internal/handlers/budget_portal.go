@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// GetPortalLink returns the client's link to the budget and their activity on it
+func (h *BudgetHandler) GetPortalLink(w http.ResponseWriter, r *http.Request) {
+	h.portalLink(w, r, false)
+}
+
+// RotatePortalLink replaces the client's link to the budget, invalidating the previous one
+func (h *BudgetHandler) RotatePortalLink(w http.ResponseWriter, r *http.Request) {
+	h.portalLink(w, r, true)
+}
+
+func (h *BudgetHandler) portalLink(w http.ResponseWriter, r *http.Request, rotate bool) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid budget ID")
+		return
+	}
+
+	getLink := h.service.GetPortalLink
+	if rotate {
+		getLink = h.service.RotatePortalLink
+	}
+	link, err := getLink(r.Context(), id, orgID)
+	if err != nil {
+		if err.Error() == "budget not found" {
+			utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if rotate {
+		utils.SuccessMessageResponse(w, http.StatusOK, "Budget link rotated successfully", link)
+		return
+	}
+	utils.SuccessResponse(w, http.StatusOK, link)
+}
+
+// PortalView returns the budget behind a client portal link so the portal page can show it
+func (h *BudgetHandler) PortalView(w http.ResponseWriter, r *http.Request) {
+	view, err := h.service.GetPortalView(r.Context(), chi.URLParam(r, "token"), r.RemoteAddr, r.UserAgent())
+	if err != nil {
+		portalError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, view)
+}
+
+// PortalPDF redirects to a short-lived link to the PDF of the budget behind a client portal link
+func (h *BudgetHandler) PortalPDF(w http.ResponseWriter, r *http.Request) {
+	doc, err := h.service.PortalPDF(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		portalError(w, err)
+		return
+	}
+
+	http.Redirect(w, r, doc.DownloadURL, http.StatusFound)
+}
+
+// PortalResponseRequest is the client's optional note when answering a budget
+type PortalResponseRequest struct {
+	Comment *string `json:"comment"`
+}
+
+// PortalApprove records the client's approval of the budget behind a portal link
+func (h *BudgetHandler) PortalApprove(w http.ResponseWriter, r *http.Request) {
+	h.portalRespond(w, r, true)
+}
+
+// PortalReject records the client's rejection of the budget behind a portal link
+func (h *BudgetHandler) PortalReject(w http.ResponseWriter, r *http.Request) {
+	h.portalRespond(w, r, false)
+}
+
+func (h *BudgetHandler) portalRespond(w http.ResponseWriter, r *http.Request, approve bool) {
+	var req PortalResponseRequest
+	if r.ContentLength != 0 {
+		if err := utils.ParseJSON(r, &req); err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	view, err := h.service.RespondFromPortal(r.Context(), chi.URLParam(r, "token"), approve, req.Comment, r.RemoteAddr, r.UserAgent())
+	if err != nil {
+		portalError(w, err)
+		return
+	}
+
+	message := "Budget approved successfully"
+	if !approve {
+		message = "Budget rejected successfully"
+	}
+	utils.SuccessMessageResponse(w, http.StatusOK, message, view)
+}
+
+func portalError(w http.ResponseWriter, err error) {
+	if err.Error() == "budget not found" {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+	utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+}
+
+// ============ Line-item comments ============
+
+// BudgetCommentRequest is the body of a question or answer on a budget line
+type BudgetCommentRequest struct {
+	Body string `json:"body"`
+}
+
+func budgetCommentError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case "budget not found", "budget item not found":
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	case "budget is no longer awaiting a response":
+		utils.ErrorResponse(w, http.StatusConflict, err.Error())
+	default:
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// PortalComment records the client's question on a line of the budget behind a portal link
+func (h *BudgetHandler) PortalComment(w http.ResponseWriter, r *http.Request) {
+	itemID, err := uuid.Parse(chi.URLParam(r, "itemId"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid budget item ID")
+		return
+	}
+
+	var req BudgetCommentRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	comment, err := h.service.CommentFromPortal(r.Context(), chi.URLParam(r, "token"), itemID, req.Body, r.RemoteAddr)
+	if err != nil {
+		budgetCommentError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Comment sent successfully", comment)
+}
+
+// ListComments returns the line-item comment threads of a budget, only one line's with ?item_id=
+func (h *BudgetHandler) ListComments(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid budget ID")
+		return
+	}
+
+	var itemID *uuid.UUID
+	if v := r.URL.Query().Get("item_id"); v != "" {
+		parsed, err := uuid.Parse(v)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid budget item ID")
+			return
+		}
+		itemID = &parsed
+	}
+
+	comments, err := h.service.ListComments(r.Context(), id, orgID, itemID)
+	if err != nil {
+		budgetCommentError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": comments,
+		"total": len(comments),
+	})
+}
+
+// ReplyToItem answers the client on a line of the budget
+func (h *BudgetHandler) ReplyToItem(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid budget ID")
+		return
+	}
+	itemID, err := uuid.Parse(chi.URLParam(r, "itemId"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid budget item ID")
+		return
+	}
+
+	var req BudgetCommentRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	comment, err := h.service.ReplyToItem(r.Context(), id, orgID, itemID, userID, req.Body)
+	if err != nil {
+		budgetCommentError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Reply sent successfully", comment)
+}
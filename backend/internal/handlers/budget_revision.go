@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ListRevisions returns the revisions a budget was sent in, newest first
+func (h *BudgetHandler) ListRevisions(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid budget ID")
+		return
+	}
+
+	revisions, err := h.service.ListRevisions(r.Context(), id, orgID)
+	if err != nil {
+		if err.Error() == "budget not found" {
+			utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, revisions)
+}
+
+// DiffRevisions shows the line-item changes and total delta between the from and to revisions,
+// by default the latest revision and the one before it
+func (h *BudgetHandler) DiffRevisions(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid budget ID")
+		return
+	}
+
+	var revisions [2]int
+	for i, param := range []string{"from", "to"} {
+		if v := r.URL.Query().Get(param); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				utils.ErrorResponse(w, http.StatusBadRequest, "Invalid revision number")
+				return
+			}
+			revisions[i] = n
+		}
+	}
+
+	diff, err := h.service.DiffRevisions(r.Context(), id, orgID, revisions[0], revisions[1])
+	if err != nil {
+		if err.Error() == "budget not found" || err.Error() == "budget revision not found" {
+			utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, diff)
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type CashRegisterHandler struct {
+	service *services.CashRegisterService
+}
+
+func NewCashRegisterHandler(service *services.CashRegisterService) *CashRegisterHandler {
+	return &CashRegisterHandler{service: service}
+}
+
+// List returns the registers opened within an optional date range (from, to as YYYY-MM-DD)
+func (h *CashRegisterHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	var from, to *time.Time
+	if raw := r.URL.Query().Get("from"); raw != "" {
+		t, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid date format, expected YYYY-MM-DD")
+			return
+		}
+		from = &t
+	}
+	if raw := r.URL.Query().Get("to"); raw != "" {
+		t, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid date format, expected YYYY-MM-DD")
+			return
+		}
+		end := t.AddDate(0, 0, 1)
+		to = &end
+	}
+
+	registers, err := h.service.List(r.Context(), orgID, from, to)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, registers)
+}
+
+// Current returns the open register with its takings so far
+func (h *CashRegisterHandler) Current(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	register, err := h.service.GetOpen(r.Context(), orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+	report, err := h.service.Reconciliation(r.Context(), register.ID, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, report)
+}
+
+func (h *CashRegisterHandler) Open(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req struct {
+		OpeningFloatCents int     `json:"opening_float_cents"`
+		Notes             *string `json:"notes"`
+	}
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	register, err := h.service.Open(r.Context(), orgID, userID, req.OpeningFloatCents, req.Notes)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Cash register opened successfully", register)
+}
+
+// RecordTaking adds a cash, card or MB Way taking to an open register
+func (h *CashRegisterHandler) RecordTaking(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid cash register ID")
+		return
+	}
+
+	var req services.RecordTakingInput
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	entry, err := h.service.RecordTaking(r.Context(), id, orgID, userID, req)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Taking recorded successfully", entry)
+}
+
+// Close records the counted amounts and returns the end-of-day reconciliation
+func (h *CashRegisterHandler) Close(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid cash register ID")
+		return
+	}
+
+	var req services.CloseCashRegisterInput
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	report, err := h.service.Close(r.Context(), id, orgID, userID, req)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Cash register closed successfully", report)
+}
+
+// Reconciliation returns a register's takings compared with the counted amounts
+func (h *CashRegisterHandler) Reconciliation(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid cash register ID")
+		return
+	}
+
+	report, err := h.service.Reconciliation(r.Context(), id, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, report)
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type ChatWebhookHandler struct {
+	service *services.ChatWebhookService
+}
+
+func NewChatWebhookHandler(service *services.ChatWebhookService) *ChatWebhookHandler {
+	return &ChatWebhookHandler{service: service}
+}
+
+// chatWebhookAdmin returns the organization of an administrator's request
+func chatWebhookAdmin(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return uuid.Nil, false
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can manage chat webhooks")
+		return uuid.Nil, false
+	}
+	return orgID, true
+}
+
+// chatWebhookError answers a chat webhook request that failed
+func chatWebhookError(w http.ResponseWriter, err error) {
+	if err.Error() == "chat webhook not found" {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+	utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+}
+
+// List returns the organization's Slack and Teams webhooks
+func (h *ChatWebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	webhooks, err := h.service.List(r.Context(), orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, webhooks)
+}
+
+// Create adds a team channel's Slack or Teams webhook (admin only)
+func (h *ChatWebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := chatWebhookAdmin(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req services.ChatWebhookInput
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	webhook, err := h.service.Create(r.Context(), orgID, userID, req)
+	if err != nil {
+		chatWebhookError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Chat webhook created successfully", webhook)
+}
+
+// Update changes a chat webhook; its URL is kept when none is sent (admin only)
+func (h *ChatWebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := chatWebhookAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid chat webhook ID")
+		return
+	}
+
+	var req services.ChatWebhookInput
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	webhook, err := h.service.Update(r.Context(), id, orgID, req)
+	if err != nil {
+		chatWebhookError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Chat webhook updated successfully", webhook)
+}
+
+// Delete removes a chat webhook (admin only)
+func (h *ChatWebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := chatWebhookAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid chat webhook ID")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id, orgID); err != nil {
+		chatWebhookError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Chat webhook deleted successfully", nil)
+}
+
+// Test posts a test message to a chat webhook (admin only)
+func (h *ChatWebhookHandler) Test(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := chatWebhookAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid chat webhook ID")
+		return
+	}
+
+	if err := h.service.Test(r.Context(), id, orgID); err != nil {
+		if err.Error() == "chat webhook not found" {
+			utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ErrorResponse(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Test message sent successfully", nil)
+}
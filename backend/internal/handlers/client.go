@@ -27,6 +27,7 @@ type CreateClientRequest struct {
 	Address *string `json:"address"`
 	TaxID   *string `json:"tax_id"`
 	Notes   *string `json:"notes"`
+	Segment *string `json:"segment"`
 }
 
 type UpdateClientRequest struct {
@@ -36,6 +37,13 @@ type UpdateClientRequest struct {
 	Address *string `json:"address"`
 	TaxID   *string `json:"tax_id"`
 	Notes   *string `json:"notes"`
+	Segment *string `json:"segment"`
+}
+
+// ClientResponse is a created client along with existing clients that look like it
+type ClientResponse struct {
+	*models.Client
+	DuplicateWarnings []*services.ClientDuplicate `json:"duplicate_warnings"`
 }
 
 func (h *ClientHandler) List(w http.ResponseWriter, r *http.Request) {
@@ -60,22 +68,18 @@ func (h *ClientHandler) List(w http.ResponseWriter, r *http.Request) {
 	}
 	offset := (page - 1) * limit
 
-	// Check if search query
-	if search := r.URL.Query().Get("search"); search != "" {
-		clients, err := h.service.Search(r.Context(), orgID, search, limit)
-		if err != nil {
-			utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
-			return
+	filters := services.ClientFilters{
+		Search:  r.URL.Query().Get("search"),
+		Segment: r.URL.Query().Get("segment"),
+		Sort:    r.URL.Query().Get("sort"),
+	}
+	if raw := r.URL.Query().Get("has_active_projects"); raw != "" {
+		if active, err := strconv.ParseBool(raw); err == nil {
+			filters.HasActiveProjects = &active
 		}
-		utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
-			"clients": clients,
-			"total":   len(clients),
-		})
-		return
 	}
 
-	// Normal list
-	clients, total, err := h.service.List(r.Context(), orgID, limit, offset)
+	clients, total, err := h.service.List(r.Context(), orgID, filters, limit, offset)
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
@@ -112,6 +116,71 @@ func (h *ClientHandler) Get(w http.ResponseWriter, r *http.Request) {
 	utils.SuccessResponse(w, http.StatusOK, client)
 }
 
+// GetDetail returns the client with their worksheets, budgets, projects and billing totals
+func (h *ClientHandler) GetDetail(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid client ID")
+		return
+	}
+
+	detail, err := h.service.GetDetail(r.Context(), id, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, detail)
+}
+
+// CheckDuplicates lists existing clients matching the email, phone, tax_id or name query
+// parameters, so the form can warn before the client is saved. exclude_id skips the client
+// being edited.
+func (h *ClientHandler) CheckDuplicates(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	q := r.URL.Query()
+	candidate := &models.Client{
+		Name:  q.Get("name"),
+		Email: q.Get("email"),
+		Phone: q.Get("phone"),
+	}
+	if taxID := q.Get("tax_id"); taxID != "" {
+		candidate.TaxID = &taxID
+	}
+
+	excludeID := uuid.Nil
+	if raw := q.Get("exclude_id"); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid client ID")
+			return
+		}
+		excludeID = parsed
+	}
+
+	duplicates, err := h.service.FindDuplicates(r.Context(), orgID, candidate, excludeID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": duplicates,
+		"total": len(duplicates),
+	})
+}
+
 func (h *ClientHandler) Create(w http.ResponseWriter, r *http.Request) {
 	orgID, ok := middleware.GetOrganizationID(r.Context())
 	if !ok {
@@ -139,15 +208,20 @@ func (h *ClientHandler) Create(w http.ResponseWriter, r *http.Request) {
 		Address:        req.Address,
 		TaxID:          req.TaxID,
 		Notes:          req.Notes,
+		Segment:        req.Segment,
 		CreatedBy:      userID,
 	}
 
-	if err := h.service.Create(r.Context(), client); err != nil {
+	duplicates, err := h.service.Create(r.Context(), client)
+	if err != nil {
 		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	utils.SuccessMessageResponse(w, http.StatusCreated, "Client created successfully", client)
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Client created successfully", ClientResponse{
+		Client:            client,
+		DuplicateWarnings: duplicates,
+	})
 }
 
 func (h *ClientHandler) Update(w http.ResponseWriter, r *http.Request) {
@@ -177,6 +251,7 @@ func (h *ClientHandler) Update(w http.ResponseWriter, r *http.Request) {
 		Address: req.Address,
 		TaxID:   req.TaxID,
 		Notes:   req.Notes,
+		Segment: req.Segment,
 	}
 
 	if err := h.service.Update(r.Context(), id, orgID, client); err != nil {
@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type ComplianceHandler struct {
+	service *services.ComplianceService
+}
+
+func NewComplianceHandler(service *services.ComplianceService) *ComplianceHandler {
+	return &ComplianceHandler{service: service}
+}
+
+type ComplianceRequirementRequest struct {
+	Category        *string `json:"category"`
+	Name            string  `json:"name"`
+	Description     *string `json:"description"`
+	RequirementType string  `json:"requirement_type"`
+	DueOffsetDays   int     `json:"due_offset_days"`
+	IsActive        *bool   `json:"is_active"`
+}
+
+// ============ Requirement Handlers ============
+
+func (h *ComplianceHandler) ListRequirements(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	requirements, err := h.service.ListRequirements(r.Context(), orgID, r.URL.Query().Get("category"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": requirements,
+		"total": len(requirements),
+	})
+}
+
+func (h *ComplianceHandler) CreateRequirement(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	var req ComplianceRequirementRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	requirement := &models.ComplianceRequirement{
+		OrganizationID:  orgID,
+		Category:        req.Category,
+		Name:            req.Name,
+		Description:     req.Description,
+		RequirementType: models.ComplianceRequirementType(req.RequirementType),
+		DueOffsetDays:   req.DueOffsetDays,
+	}
+
+	if err := h.service.CreateRequirement(r.Context(), requirement); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Compliance requirement created successfully", requirement)
+}
+
+func (h *ComplianceHandler) UpdateRequirement(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid requirement ID")
+		return
+	}
+
+	var req ComplianceRequirementRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	requirement := &models.ComplianceRequirement{
+		Category:        req.Category,
+		Name:            req.Name,
+		Description:     req.Description,
+		RequirementType: models.ComplianceRequirementType(req.RequirementType),
+		DueOffsetDays:   req.DueOffsetDays,
+		IsActive:        req.IsActive == nil || *req.IsActive,
+	}
+
+	if err := h.service.UpdateRequirement(r.Context(), id, orgID, requirement); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Compliance requirement updated successfully", requirement)
+}
+
+func (h *ComplianceHandler) DeleteRequirement(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid requirement ID")
+		return
+	}
+
+	if err := h.service.DeleteRequirement(r.Context(), id, orgID); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Compliance requirement deleted successfully", nil)
+}
+
+// ============ Project Checklist Handlers ============
+
+// ListProjectItems returns the compliance checklist of a project
+func (h *ComplianceHandler) ListProjectItems(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	items, err := h.service.ListProjectItems(r.Context(), orgID, projectID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": items,
+		"total": len(items),
+	})
+}
+
+// ApplyRequirements adds checklist items for requirements matching the project's category
+func (h *ComplianceHandler) ApplyRequirements(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	items, err := h.service.ApplyToProject(r.Context(), orgID, projectID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Compliance requirements applied successfully", map[string]interface{}{
+		"items": items,
+		"total": len(items),
+	})
+}
+
+// CompleteItem marks a checklist item as completed
+func (h *ComplianceHandler) CompleteItem(w http.ResponseWriter, r *http.Request) {
+	orgID, projectID, itemID, userID, ok := h.parseItemRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		DocumentURL *string `json:"document_url"`
+	}
+	if r.ContentLength > 0 {
+		if err := utils.ParseJSON(r, &req); err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	if err := h.service.CompleteItem(r.Context(), orgID, projectID, itemID, userID, req.DocumentURL); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Compliance item completed successfully", nil)
+}
+
+// WaiveItem waives a checklist item with a mandatory reason
+func (h *ComplianceHandler) WaiveItem(w http.ResponseWriter, r *http.Request) {
+	orgID, projectID, itemID, userID, ok := h.parseItemRequest(w, r)
+	if !ok {
+		return
+	}
+
+	role, _ := middleware.GetUserRole(r.Context())
+	if role != string(models.RoleAdmin) && role != string(models.RoleManager) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators and managers can waive compliance items")
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.service.WaiveItem(r.Context(), orgID, projectID, itemID, userID, req.Reason); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Compliance item waived successfully", nil)
+}
+
+// ReopenItem sets a checklist item back to pending
+func (h *ComplianceHandler) ReopenItem(w http.ResponseWriter, r *http.Request) {
+	orgID, projectID, itemID, _, ok := h.parseItemRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.ReopenItem(r.Context(), orgID, projectID, itemID); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Compliance item reopened successfully", nil)
+}
+
+// parseItemRequest extracts the organization, project, item and user IDs for item routes
+func (h *ComplianceHandler) parseItemRequest(w http.ResponseWriter, r *http.Request) (orgID, projectID, itemID, userID uuid.UUID, ok bool) {
+	orgID, ok = middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	userID, ok = middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var err error
+	projectID, err = uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid project ID")
+		return orgID, projectID, itemID, userID, false
+	}
+
+	itemID, err = uuid.Parse(chi.URLParam(r, "itemId"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid compliance item ID")
+		return orgID, projectID, itemID, userID, false
+	}
+
+	return orgID, projectID, itemID, userID, true
+}
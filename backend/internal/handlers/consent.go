@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ConsentHandler manages whether contacts agreed to be messaged on each channel
+type ConsentHandler struct {
+	service *services.ConsentService
+}
+
+func NewConsentHandler(service *services.ConsentService) *ConsentHandler {
+	return &ConsentHandler{service: service}
+}
+
+// consentError answers a consent request that failed
+func consentError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case "consent not found", "client not found", "patient not found":
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	default:
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// List returns the organization's consent records; filter with ?channel=, ?status=, ?client_id=,
+// ?patient_id= and ?search= on the address
+func (h *ConsentHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	query := r.URL.Query()
+	page, _ := strconv.Atoi(query.Get("page"))
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	filters := services.ConsentFilters{
+		Channel: models.ConsentChannel(query.Get("channel")),
+		Status:  models.ConsentStatus(query.Get("status")),
+		Search:  query.Get("search"),
+	}
+	if v := query.Get("client_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid client ID")
+			return
+		}
+		filters.ClientID = &id
+	}
+	if v := query.Get("patient_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid patient ID")
+			return
+		}
+		filters.PatientID = &id
+	}
+
+	consents, total, err := h.service.List(r.Context(), orgID, filters, limit, (page-1)*limit)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.PaginatedResponse(w, http.StatusOK, consents, page, limit, total)
+}
+
+// Get returns a consent record with the history of its changes
+func (h *ConsentHandler) Get(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid consent ID")
+		return
+	}
+
+	consent, err := h.service.GetByID(r.Context(), id, orgID)
+	if err != nil {
+		consentError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, consent)
+}
+
+// Create records a contact's opt-in or opt-out on a channel, updating its record if it has one
+func (h *ConsentHandler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req services.ConsentInput
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	consent, err := h.service.Record(r.Context(), orgID, userID, req)
+	if err != nil {
+		consentError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Consent recorded successfully", consent)
+}
+
+// Update changes the status and note of a consent record
+func (h *ConsentHandler) Update(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid consent ID")
+		return
+	}
+
+	var req struct {
+		Status models.ConsentStatus `json:"status"`
+		Note   *string              `json:"note"`
+	}
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	consent, err := h.service.Update(r.Context(), id, orgID, userID, req.Status, req.Note)
+	if err != nil {
+		consentError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Consent updated successfully", consent)
+}
+
+// Delete removes a consent record and its history
+func (h *ConsentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	role, _ := middleware.GetUserRole(r.Context())
+	if role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can delete consent records")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid consent ID")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id, orgID); err != nil {
+		consentError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Consent deleted successfully", nil)
+}
@@ -0,0 +1,369 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type CrewHandler struct {
+	service *services.CrewService
+}
+
+func NewCrewHandler(service *services.CrewService) *CrewHandler {
+	return &CrewHandler{service: service}
+}
+
+// canManageCrew reports whether the user can change crew members, whose pay rates they set
+func canManageCrew(r *http.Request) bool {
+	role, _ := middleware.GetUserRole(r.Context())
+	return role == string(models.RoleAdmin) || role == string(models.RoleManager)
+}
+
+// crewError maps crew service errors to their status
+func crewError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case "crew member not found", "crew assignment not found", "project not found", "user not found":
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	case "crew member has recorded hours; deactivate them instead":
+		utils.ErrorResponse(w, http.StatusConflict, err.Error())
+	default:
+		// Double-booking errors list the days
+		if strings.HasPrefix(err.Error(), "crew member is already booked") {
+			utils.ErrorResponse(w, http.StatusConflict, err.Error())
+			return
+		}
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// crewDateParam reads an optional YYYY-MM-DD query parameter
+func crewDateParam(r *http.Request, name string) (*time.Time, bool) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return nil, true
+	}
+	t, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return nil, false
+	}
+	return &t, true
+}
+
+// crewUUIDParam reads an optional UUID query parameter
+func crewUUIDParam(r *http.Request, name string) (*uuid.UUID, bool) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return nil, true
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return nil, false
+	}
+	return &id, true
+}
+
+// ============ Crew members ============
+
+// ListMembers returns the active crew members, or all of them with ?all=true
+func (h *CrewHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	members, err := h.service.ListMembers(r.Context(), orgID, r.URL.Query().Get("all") == "true")
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": members,
+		"total": len(members),
+	})
+}
+
+func (h *CrewHandler) CreateMember(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	if !canManageCrew(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators and managers can manage the crew")
+		return
+	}
+
+	var req services.CrewMemberInput
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	member, err := h.service.CreateMember(r.Context(), orgID, req)
+	if err != nil {
+		crewError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Crew member created successfully", member)
+}
+
+func (h *CrewHandler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	if !canManageCrew(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators and managers can manage the crew")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid crew member ID")
+		return
+	}
+
+	var req services.CrewMemberInput
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	member, err := h.service.UpdateMember(r.Context(), id, orgID, req)
+	if err != nil {
+		crewError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Crew member updated successfully", member)
+}
+
+func (h *CrewHandler) DeleteMember(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	if !canManageCrew(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators and managers can manage the crew")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid crew member ID")
+		return
+	}
+
+	if err := h.service.DeleteMember(r.Context(), id, orgID); err != nil {
+		crewError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Crew member deleted successfully", nil)
+}
+
+// ============ Assignments ============
+
+// ListAssignments returns the assignments filtered by ?project_id=, ?crew_member_id=, ?from= and ?to=
+func (h *CrewHandler) ListAssignments(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	var filters services.CrewAssignmentFilters
+	if filters.ProjectID, ok = crewUUIDParam(r, "project_id"); !ok {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+	if filters.CrewMemberID, ok = crewUUIDParam(r, "crew_member_id"); !ok {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid crew member ID")
+		return
+	}
+	if filters.From, ok = crewDateParam(r, "from"); !ok {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid date format, expected YYYY-MM-DD")
+		return
+	}
+	if filters.To, ok = crewDateParam(r, "to"); !ok {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid date format, expected YYYY-MM-DD")
+		return
+	}
+
+	assignments, err := h.service.ListAssignments(r.Context(), orgID, filters)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": assignments,
+		"total": len(assignments),
+	})
+}
+
+// Assign schedules a crew member on a project for a day or a range of days
+func (h *CrewHandler) Assign(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req services.CrewAssignmentRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	assignments, err := h.service.Assign(r.Context(), orgID, userID, req)
+	if err != nil {
+		crewError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Crew scheduled successfully", map[string]interface{}{
+		"items": assignments,
+		"total": len(assignments),
+	})
+}
+
+// UpdateAssignment changes the planned hours of an assignment or records the hours worked
+func (h *CrewHandler) UpdateAssignment(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid assignment ID")
+		return
+	}
+
+	var req services.UpdateCrewAssignmentRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	assignment, err := h.service.UpdateAssignment(r.Context(), id, orgID, req)
+	if err != nil {
+		crewError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Crew assignment updated successfully", assignment)
+}
+
+func (h *CrewHandler) DeleteAssignment(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid assignment ID")
+		return
+	}
+
+	if err := h.service.DeleteAssignment(r.Context(), id, orgID); err != nil {
+		crewError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Crew assignment deleted successfully", nil)
+}
+
+// Calendar returns the crew's days between ?from= and ?to= (the next 7 days by default), optionally
+// for one ?crew_member_id= or the crew of one ?project_id=
+func (h *CrewHandler) Calendar(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	from, okFrom := crewDateParam(r, "from")
+	to, okTo := crewDateParam(r, "to")
+	if !okFrom || !okTo {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid date format, expected YYYY-MM-DD")
+		return
+	}
+	if from == nil {
+		// Today on the organization's wall clock, as a date
+		now := time.Now().In(h.service.Location(r.Context(), orgID))
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		from = &today
+	}
+	if to == nil {
+		end := from.AddDate(0, 0, 6)
+		to = &end
+	}
+	memberID, ok := crewUUIDParam(r, "crew_member_id")
+	if !ok {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid crew member ID")
+		return
+	}
+	projectID, ok := crewUUIDParam(r, "project_id")
+	if !ok {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	days, err := h.service.Calendar(r.Context(), orgID, *from, *to, memberID, projectID)
+	if err != nil {
+		crewError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"from":  from.Format("2006-01-02"),
+		"to":    to.Format("2006-01-02"),
+		"items": days,
+	})
+}
+
+// ProjectCosting returns a project's budgeted amount against its planned and actual labour and expenses
+func (h *CrewHandler) ProjectCosting(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	costing, err := h.service.ProjectCosting(r.Context(), id, orgID)
+	if err != nil {
+		crewError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, costing)
+}
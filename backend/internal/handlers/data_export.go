@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// DataExportHandler queues large CSV exports and serves their progress and download links (admins only)
+type DataExportHandler struct {
+	service      *services.DataExportService
+	auditService *services.AdminAuditService
+}
+
+func NewDataExportHandler(service *services.DataExportService, auditService *services.AdminAuditService) *DataExportHandler {
+	return &DataExportHandler{service: service, auditService: auditService}
+}
+
+type CreateDataExportRequest struct {
+	Entity models.DataExportEntity `json:"entity"`
+	From   string                  `json:"from"` // YYYY-MM-DD, optional
+	To     string                  `json:"to"`   // YYYY-MM-DD inclusive, optional
+}
+
+// Create queues an export; it is built in the background
+func (h *DataExportHandler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only admins can export data")
+		return
+	}
+
+	var req CreateDataExportRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	var filters models.DataExportFilters
+	if req.From != "" {
+		from, err := time.Parse("2006-01-02", req.From)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid start date format")
+			return
+		}
+		filters.From = &from
+	}
+	if req.To != "" {
+		to, err := time.Parse("2006-01-02", req.To)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid end date format")
+			return
+		}
+		to = to.AddDate(0, 0, 1)
+		filters.To = &to
+	}
+
+	export, err := h.service.RequestExport(r.Context(), orgID, userID, req.Entity, filters)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Exports made while impersonating count towards the admin's activity
+	if impersonatorID, ok := middleware.GetImpersonatorID(r.Context()); ok && middleware.IsImpersonation(r.Context()) {
+		h.auditService.Log(r.Context(), impersonatorID, models.AuditActionExport, models.AuditEntityOrganization, &orgID,
+			map[string]interface{}{"data_export": export.ID, "entity": req.Entity, "impersonated_user_id": userID},
+			r.RemoteAddr, r.UserAgent())
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusAccepted, "Export requested successfully", export)
+}
+
+func (h *DataExportHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only admins can export data")
+		return
+	}
+
+	limit, offset := parsePage(r)
+	exports, total, err := h.service.ListExports(r.Context(), orgID, limit, offset)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": exports,
+		"total": total,
+	})
+}
+
+// Get returns an export's progress and, once it is ready, a short-lived download link
+func (h *DataExportHandler) Get(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only admins can export data")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid export ID")
+		return
+	}
+
+	export, err := h.service.GetExport(r.Context(), id, orgID)
+	if err != nil {
+		if err.Error() == "data export not found" {
+			utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, export)
+}
@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type DelegationHandler struct {
+	service *services.DelegationService
+}
+
+func NewDelegationHandler(service *services.DelegationService) *DelegationHandler {
+	return &DelegationHandler{service: service}
+}
+
+type OutOfOfficeRequest struct {
+	UserID              *string   `json:"user_id"`
+	DelegateUserID      string    `json:"delegate_user_id"`
+	StartsAt            time.Time `json:"starts_at"`
+	EndsAt              time.Time `json:"ends_at"`
+	Reason              *string   `json:"reason"`
+	DelegateApprovals   *bool     `json:"delegate_approvals"`
+	DelegateTasks       *bool     `json:"delegate_tasks"`
+	DelegateEscalations *bool     `json:"delegate_escalations"`
+	IsActive            *bool     `json:"is_active"`
+}
+
+// canManageOthers returns true if the current user may manage out-of-office entries for other users
+func canManageOthers(r *http.Request) bool {
+	role, _ := middleware.GetUserRole(r.Context())
+	return role == string(models.RoleAdmin) || role == string(models.RoleManager)
+}
+
+// ListOutOfOffice returns out-of-office entries for the organization
+func (h *DelegationHandler) ListOutOfOffice(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	var userID *uuid.UUID
+	if userIDStr := r.URL.Query().Get("user_id"); userIDStr != "" {
+		id, err := uuid.Parse(userIDStr)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+			return
+		}
+		userID = &id
+	}
+	currentOnly := r.URL.Query().Get("current") == "true"
+
+	entries, err := h.service.ListOutOfOffice(r.Context(), orgID, userID, currentOnly)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": entries,
+		"total": len(entries),
+	})
+}
+
+// CreateOutOfOffice creates an out-of-office entry for the current user or, for managers, another user
+func (h *DelegationHandler) CreateOutOfOffice(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	currentUserID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req OutOfOfficeRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	userID := currentUserID
+	if req.UserID != nil && *req.UserID != "" {
+		id, err := uuid.Parse(*req.UserID)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+			return
+		}
+		userID = id
+	}
+	if userID != currentUserID && !canManageOthers(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators and managers can set out-of-office for other users")
+		return
+	}
+
+	delegateID, err := uuid.Parse(req.DelegateUserID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid delegate user ID")
+		return
+	}
+
+	entry := &models.OutOfOffice{
+		OrganizationID:      orgID,
+		UserID:              userID,
+		DelegateUserID:      delegateID,
+		StartsAt:            req.StartsAt,
+		EndsAt:              req.EndsAt,
+		Reason:              req.Reason,
+		DelegateApprovals:   req.DelegateApprovals == nil || *req.DelegateApprovals,
+		DelegateTasks:       req.DelegateTasks == nil || *req.DelegateTasks,
+		DelegateEscalations: req.DelegateEscalations == nil || *req.DelegateEscalations,
+		CreatedBy:           &currentUserID,
+	}
+
+	if err := h.service.CreateOutOfOffice(r.Context(), entry); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Out-of-office created successfully", entry)
+}
+
+// UpdateOutOfOffice updates an out-of-office entry
+func (h *DelegationHandler) UpdateOutOfOffice(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	currentUserID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid out-of-office ID")
+		return
+	}
+
+	existing, err := h.service.GetOutOfOffice(r.Context(), id, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if existing.UserID != currentUserID && !canManageOthers(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators and managers can change out-of-office for other users")
+		return
+	}
+
+	var req OutOfOfficeRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.DelegateUserID != "" {
+		delegateID, err := uuid.Parse(req.DelegateUserID)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid delegate user ID")
+			return
+		}
+		existing.DelegateUserID = delegateID
+	}
+	if !req.StartsAt.IsZero() {
+		existing.StartsAt = req.StartsAt
+	}
+	if !req.EndsAt.IsZero() {
+		existing.EndsAt = req.EndsAt
+	}
+	if req.Reason != nil {
+		existing.Reason = req.Reason
+	}
+	if req.DelegateApprovals != nil {
+		existing.DelegateApprovals = *req.DelegateApprovals
+	}
+	if req.DelegateTasks != nil {
+		existing.DelegateTasks = *req.DelegateTasks
+	}
+	if req.DelegateEscalations != nil {
+		existing.DelegateEscalations = *req.DelegateEscalations
+	}
+	if req.IsActive != nil {
+		existing.IsActive = *req.IsActive
+	}
+
+	if err := h.service.UpdateOutOfOffice(r.Context(), id, orgID, existing); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Out-of-office updated successfully", existing)
+}
+
+// DeleteOutOfOffice deletes an out-of-office entry
+func (h *DelegationHandler) DeleteOutOfOffice(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	currentUserID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid out-of-office ID")
+		return
+	}
+
+	existing, err := h.service.GetOutOfOffice(r.Context(), id, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if existing.UserID != currentUserID && !canManageOthers(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators and managers can remove out-of-office for other users")
+		return
+	}
+
+	if err := h.service.DeleteOutOfOffice(r.Context(), id, orgID); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Out-of-office deleted successfully", nil)
+}
+
+// ListDelegations returns the audit log of work rerouted to delegates
+func (h *DelegationHandler) ListDelegations(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	filters := services.DelegationAuditFilters{}
+	if userIDStr := r.URL.Query().Get("user_id"); userIDStr != "" {
+		id, err := uuid.Parse(userIDStr)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+			return
+		}
+		filters.UserID = &id
+	}
+	if page, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil {
+		filters.Page = page
+	}
+	if limit, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil {
+		filters.Limit = limit
+	}
+
+	records, total, err := h.service.ListDelegationAudit(r.Context(), orgID, filters)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": records,
+		"total": total,
+	})
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// EmailSuppressionHandler manages the addresses the organization's emails aren't sent to after
+// they hard bounced or complained
+type EmailSuppressionHandler struct {
+	service *services.EmailSuppressionService
+}
+
+func NewEmailSuppressionHandler(service *services.EmailSuppressionService) *EmailSuppressionHandler {
+	return &EmailSuppressionHandler{service: service}
+}
+
+// List returns the organization's suppressed email addresses; filter with ?search= on the address
+func (h *EmailSuppressionHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	query := r.URL.Query()
+	page, _ := strconv.Atoi(query.Get("page"))
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	suppressions, total, err := h.service.List(r.Context(), orgID, query.Get("search"), limit, (page-1)*limit)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.PaginatedResponse(w, http.StatusOK, suppressions, page, limit, total)
+}
+
+// Delete lifts a suppression, so the address may be emailed again
+func (h *EmailSuppressionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	role, _ := middleware.GetUserRole(r.Context())
+	if role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can remove email suppressions")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid email suppression ID")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id, orgID); err != nil {
+		if err.Error() == "email suppression not found" {
+			utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Email suppression removed successfully", nil)
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// LinkProjectRequest links a session to a project; a null project_id unlinks it
+type LinkProjectRequest struct {
+	ProjectID *uuid.UUID `json:"project_id"`
+}
+
+// LinkProject links a session to a construction project, e.g. an on-site consultancy
+func (h *SessionHandler) LinkProject(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	var req LinkProjectRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	session, err := h.service.LinkProject(r.Context(), id, orgID, req.ProjectID)
+	if err != nil {
+		if err.Error() == "session not found" || err.Error() == "project not found" {
+			utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Session linked successfully", session)
+}
+
+// LinkClientRequest is the client record a patient is linked to
+type LinkClientRequest struct {
+	ClientID uuid.UUID `json:"client_id"`
+}
+
+// LinkClient links a patient to an existing client record, so both modules share the customer
+func (h *PatientHandler) LinkClient(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid patient ID")
+		return
+	}
+
+	var req LinkClientRequest
+	if err := utils.ParseJSON(r, &req); err != nil || req.ClientID == uuid.Nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	patient, err := h.service.LinkClient(r.Context(), id, orgID, req.ClientID)
+	if err != nil {
+		switch err.Error() {
+		case "patient not found", "client not found":
+			utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		case "client is already linked to a patient":
+			utils.ErrorResponse(w, http.StatusConflict, err.Error())
+		default:
+			utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Patient linked successfully", patient)
+}
+
+// Timeline returns what happened for a client across modules, including its patient's sessions
+func (h *ClientHandler) Timeline(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid client ID")
+		return
+	}
+
+	entries, err := h.service.Timeline(r.Context(), id, orgID)
+	if err != nil {
+		if err.Error() == "client not found" {
+			utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, entries)
+}
+
+// Timeline returns what happened for a project, including the sessions linked to it
+func (h *ProjectHandler) Timeline(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	entries, err := h.service.Timeline(r.Context(), id, orgID)
+	if err != nil {
+		if err.Error() == "project not found" {
+			utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, entries)
+}
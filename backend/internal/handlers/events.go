@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/controlwise/backend/internal/events"
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/utils"
+)
+
+const (
+	// streamDuration ends each stream before the router's request timeout; the browser
+	// reconnects after streamRetry and resumes from the last event id it received
+	streamDuration = 50 * time.Second
+	streamRetry    = 2 * time.Second
+	// streamHeartbeat is the longest the stream stays silent, so proxies keep it open
+	streamHeartbeat = 15 * time.Second
+)
+
+var eventIDPattern = regexp.MustCompile(`^\d+-\d+$`)
+
+// EventsHandler streams dashboard events (new bookings, payments received, failed workflow
+// actions) as server-sent events
+type EventsHandler struct {
+	events *events.Publisher
+}
+
+func NewEventsHandler(publisher *events.Publisher) *EventsHandler {
+	return &EventsHandler{events: publisher}
+}
+
+// Stream sends the organization's events as they happen. A reconnecting client sends the
+// Last-Event-ID header (or ?last_event_id=) and receives the events it missed.
+func (h *EventsHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("last_event_id")
+	}
+	if lastID != "" && !eventIDPattern.MatchString(lastID) {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid last event ID")
+		return
+	}
+	if lastID == "" {
+		latest, err := h.events.LatestID(r.Context(), orgID)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusServiceUnavailable, "Event stream is not available")
+			return
+		}
+		lastID = latest
+	}
+
+	// The stream outlives the server's write timeout
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Now().Add(streamDuration + streamHeartbeat)); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Streaming is not supported")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetry.Milliseconds())
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	ctx := r.Context()
+	deadline := time.Now().Add(streamDuration)
+	for {
+		block := min(time.Until(deadline), streamHeartbeat)
+		if block <= 0 {
+			return
+		}
+
+		batch, err := h.events.Read(ctx, orgID, lastID, block)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Printf("[Events] Stream for organization %s stopped: %v", orgID, err)
+			return
+		}
+
+		if len(batch) == 0 {
+			fmt.Fprint(w, ": keepalive\n\n")
+		}
+		for _, event := range batch {
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+			lastID = event.ID
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type ExpenseHandler struct {
+	service *services.ExpenseService
+}
+
+func NewExpenseHandler(service *services.ExpenseService) *ExpenseHandler {
+	return &ExpenseHandler{service: service}
+}
+
+// List returns expenses filtered by scope, status, project_id and date range (from, to)
+func (h *ExpenseHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	q := r.URL.Query()
+	filters := services.ExpenseFilters{
+		Scope:  q.Get("scope"),
+		Status: q.Get("status"),
+		Limit:  50,
+	}
+	if raw := q.Get("project_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid project ID")
+			return
+		}
+		filters.ProjectID = &id
+	}
+	if raw := q.Get("from"); raw != "" {
+		t, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid date format, expected YYYY-MM-DD")
+			return
+		}
+		filters.From = &t
+	}
+	if raw := q.Get("to"); raw != "" {
+		t, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid date format, expected YYYY-MM-DD")
+			return
+		}
+		filters.To = &t
+	}
+	if l := q.Get("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+			filters.Limit = parsed
+		}
+	}
+	if o := q.Get("offset"); o != "" {
+		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
+			filters.Offset = parsed
+		}
+	}
+
+	expenses, total, err := h.service.List(r.Context(), orgID, filters)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": expenses,
+		"total": total,
+	})
+}
+
+func (h *ExpenseHandler) Get(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid expense ID")
+		return
+	}
+
+	expense, err := h.service.GetByID(r.Context(), id, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, expense)
+}
+
+// UploadReceipt creates a draft expense from a receipt. Multipart fields: receipt (JPEG, PNG,
+// WebP or PDF), scope (project or clinic) and an optional project_id.
+func (h *ExpenseHandler) UploadReceipt(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, services.MaxReceiptUploadSize+1<<20)
+	if err := r.ParseMultipartForm(services.MaxReceiptUploadSize); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid upload or file too large")
+		return
+	}
+
+	input := services.UploadReceiptInput{Scope: models.ExpenseScope(r.FormValue("scope"))}
+	if raw := r.FormValue("project_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid project ID")
+			return
+		}
+		input.ProjectID = &id
+	}
+
+	file, header, err := r.FormFile("receipt")
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "File is required")
+		return
+	}
+	defer file.Close()
+	input.FileName = header.Filename
+
+	data, err := io.ReadAll(io.LimitReader(file, services.MaxReceiptUploadSize+1))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Failed to read file")
+		return
+	}
+	if int64(len(data)) > services.MaxReceiptUploadSize {
+		utils.ErrorResponse(w, http.StatusBadRequest, "File is too large")
+		return
+	}
+
+	expense, err := h.service.UploadReceipt(r.Context(), orgID, userID, input, data)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Receipt uploaded successfully", expense)
+}
+
+// Update corrects a draft expense during review
+func (h *ExpenseHandler) Update(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid expense ID")
+		return
+	}
+
+	var req services.UpdateExpenseRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	expense, err := h.service.Update(r.Context(), id, orgID, req)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Expense updated successfully", expense)
+}
+
+// Confirm posts a reviewed draft expense
+func (h *ExpenseHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid expense ID")
+		return
+	}
+
+	expense, err := h.service.Confirm(r.Context(), id, orgID, userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Expense posted successfully", expense)
+}
+
+func (h *ExpenseHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid expense ID")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id, orgID); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Expense deleted successfully", nil)
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+type FinancialPeriodHandler struct {
+	service *services.FinancialPeriodService
+}
+
+func NewFinancialPeriodHandler(service *services.FinancialPeriodService) *FinancialPeriodHandler {
+	return &FinancialPeriodHandler{service: service}
+}
+
+// canClosePeriods reports whether the user may close and reopen months
+func canClosePeriods(r *http.Request) bool {
+	role, ok := middleware.GetUserRole(r.Context())
+	return ok && (role == string(models.RoleAdmin) || role == string(models.RoleAccountant))
+}
+
+// List returns the months that were closed at some point
+func (h *FinancialPeriodHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	periods, err := h.service.List(r.Context(), orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, periods)
+}
+
+// Get returns a month's figures (the locked snapshot when closed) and its close/reopen history
+func (h *FinancialPeriodHandler) Get(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	period, err := services.ParseFinancialPeriod(chi.URLParam(r, "period"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	p, err := h.service.Get(r.Context(), orgID, period)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	history, err := h.service.History(r.Context(), orgID, period)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"period":  p,
+		"history": history,
+	})
+}
+
+// Close locks a finished month (admins and accountants)
+func (h *FinancialPeriodHandler) Close(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+	if !canClosePeriods(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators and accountants can close financial periods")
+		return
+	}
+
+	period, err := services.ParseFinancialPeriod(chi.URLParam(r, "period"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	p, err := h.service.Close(r.Context(), orgID, userID, period)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Financial period closed successfully", p)
+}
+
+// Reopen unlocks a closed month, recording the reason (admins and accountants)
+func (h *FinancialPeriodHandler) Reopen(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+	if !canClosePeriods(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators and accountants can reopen financial periods")
+		return
+	}
+
+	period, err := services.ParseFinancialPeriod(chi.URLParam(r, "period"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	p, err := h.service.Reopen(r.Context(), orgID, userID, period, req.Reason)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Financial period reopened successfully", p)
+}
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// FollowUpHandler serves the user's personal follow-up reminders
+type FollowUpHandler struct {
+	service *services.FollowUpService
+}
+
+func NewFollowUpHandler(service *services.FollowUpService) *FollowUpHandler {
+	return &FollowUpHandler{service: service}
+}
+
+type CreateFollowUpRequest struct {
+	EntityType string  `json:"entity_type"` // budget, client, session, project or patient
+	EntityID   string  `json:"entity_id"`
+	RemindAt   string  `json:"remind_at"` // RFC3339 format
+	Note       *string `json:"note"`
+	SendEmail  bool    `json:"send_email"`
+}
+
+type RescheduleFollowUpRequest struct {
+	RemindAt  string  `json:"remind_at"` // RFC3339 format
+	Note      *string `json:"note"`
+	SendEmail bool    `json:"send_email"`
+}
+
+// List returns the user's follow-ups. Filters: ?status=, ?entity_type=, ?entity_id=
+func (h *FollowUpHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	filters := models.FollowUpFilters{
+		Status:     models.FollowUpStatus(r.URL.Query().Get("status")),
+		EntityType: models.FollowUpEntityType(r.URL.Query().Get("entity_type")),
+	}
+	if entityID := r.URL.Query().Get("entity_id"); entityID != "" {
+		id, err := uuid.Parse(entityID)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid entity ID")
+			return
+		}
+		filters.EntityID = &id
+	}
+
+	followUps, err := h.service.List(r.Context(), orgID, userID, filters)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": followUps,
+		"total": len(followUps),
+	})
+}
+
+// Create sets a follow-up on a record
+func (h *FollowUpHandler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req CreateFollowUpRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	entityType := models.FollowUpEntityType(req.EntityType)
+	if !entityType.IsValid() {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid entity type")
+		return
+	}
+
+	entityID, err := uuid.Parse(req.EntityID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid entity ID")
+		return
+	}
+
+	remindAt, err := time.Parse(time.RFC3339, req.RemindAt)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid remind time format")
+		return
+	}
+
+	followUp, err := h.service.Create(r.Context(), &models.FollowUp{
+		OrganizationID: orgID,
+		UserID:         userID,
+		EntityType:     entityType,
+		EntityID:       entityID,
+		RemindAt:       remindAt,
+		Note:           req.Note,
+		SendEmail:      req.SendEmail,
+	})
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Follow-up created successfully", followUp)
+}
+
+// Reschedule moves a follow-up to a new time
+func (h *FollowUpHandler) Reschedule(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid follow-up ID")
+		return
+	}
+
+	var req RescheduleFollowUpRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	remindAt, err := time.Parse(time.RFC3339, req.RemindAt)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid remind time format")
+		return
+	}
+
+	followUp, err := h.service.Reschedule(r.Context(), id, orgID, userID, remindAt, req.Note, req.SendEmail)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Follow-up updated successfully", followUp)
+}
+
+// Complete marks a follow-up as done
+func (h *FollowUpHandler) Complete(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid follow-up ID")
+		return
+	}
+
+	if err := h.service.Complete(r.Context(), id, orgID, userID); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Follow-up completed successfully", nil)
+}
+
+// Cancel drops a follow-up
+func (h *FollowUpHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid follow-up ID")
+		return
+	}
+
+	if err := h.service.Cancel(r.Context(), id, orgID, userID); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Follow-up cancelled successfully", nil)
+}
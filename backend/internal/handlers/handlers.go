@@ -1,12 +1,23 @@
 package handlers
 
 import (
+	"errors"
+	"io"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	apperrors "github.com/controlwise/backend/internal/errors"
 	"github.com/controlwise/backend/internal/middleware"
 	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/phone"
 	"github.com/controlwise/backend/internal/services"
 	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 // OrganizationHandler
@@ -35,11 +46,13 @@ func (h *OrganizationHandler) GetCurrent(w http.ResponseWriter, r *http.Request)
 }
 
 type UpdateOrganizationRequest struct {
-	Name    string `json:"name"`
-	Email   string `json:"email"`
-	Phone   string `json:"phone"`
-	Address string `json:"address"`
-	TaxID   string `json:"tax_id"`
+	Name           string `json:"name"`
+	Email          string `json:"email"`
+	Phone          string `json:"phone"`
+	Address        string `json:"address"`
+	TaxID          string `json:"tax_id"`
+	DefaultCountry string `json:"default_country"` // kept when empty
+	Timezone       string `json:"timezone"`        // IANA name, kept when empty
 }
 
 func (h *OrganizationHandler) Update(w http.ResponseWriter, r *http.Request) {
@@ -62,12 +75,30 @@ func (h *OrganizationHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.DefaultCountry != "" {
+		country, ok := phone.LookupCountry(req.DefaultCountry)
+		if !ok {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Unsupported country")
+			return
+		}
+		req.DefaultCountry = country.Code
+	}
+
+	if req.Timezone != "" {
+		if _, err := time.LoadLocation(req.Timezone); err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid timezone")
+			return
+		}
+	}
+
 	org := &models.Organization{
-		Name:    req.Name,
-		Email:   req.Email,
-		Phone:   req.Phone,
-		Address: req.Address,
-		TaxID:   req.TaxID,
+		Name:           req.Name,
+		Email:          req.Email,
+		Phone:          req.Phone,
+		Address:        req.Address,
+		TaxID:          req.TaxID,
+		DefaultCountry: req.DefaultCountry,
+		Timezone:       req.Timezone,
 	}
 
 	if err := h.service.Update(r.Context(), orgID, org); err != nil {
@@ -85,37 +116,51 @@ func (h *OrganizationHandler) Update(w http.ResponseWriter, r *http.Request) {
 	utils.SuccessResponse(w, http.StatusOK, updatedOrg)
 }
 
+// UploadLogo replaces the organization logo. Multipart field: logo (PNG, JPEG or GIF).
+// The image is resized to the standard logo dimensions before being stored.
 func (h *OrganizationHandler) UploadLogo(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Upload logo"})
-}
-
-// UserHandler
-type UserHandler struct {
-	service *services.UserService
-}
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found in token")
+		return
+	}
 
-func NewUserHandler(service *services.UserService) *UserHandler {
-	return &UserHandler{service: service}
-}
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || (role != string(models.RoleAdmin) && role != "owner") {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators and owners can update organization settings")
+		return
+	}
 
-func (h *UserHandler) List(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, []interface{}{})
-}
+	r.Body = http.MaxBytesReader(w, r.Body, services.MaxLogoUploadSize+1<<20)
+	if err := r.ParseMultipartForm(services.MaxLogoUploadSize); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid upload or file too large")
+		return
+	}
 
-func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusCreated, map[string]string{"message": "User created"})
-}
+	file, _, err := r.FormFile("logo")
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "File is required")
+		return
+	}
+	defer file.Close()
 
-func (h *UserHandler) Get(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Get user"})
-}
+	data, err := io.ReadAll(io.LimitReader(file, services.MaxLogoUploadSize+1))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Failed to read file")
+		return
+	}
+	if int64(len(data)) > services.MaxLogoUploadSize {
+		utils.ErrorResponse(w, http.StatusBadRequest, "File is too large")
+		return
+	}
 
-func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "User updated"})
-}
+	org, err := h.service.UploadLogo(r.Context(), orgID, data)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "User deleted"})
+	utils.SuccessMessageResponse(w, http.StatusOK, "Logo uploaded successfully", org)
 }
 
 // Note: ClientHandler is defined in client.go
@@ -174,161 +219,212 @@ func (h *BudgetHandler) List(w http.ResponseWriter, r *http.Request) {
 	utils.SuccessResponse(w, http.StatusOK, []interface{}{})
 }
 
-func (h *BudgetHandler) Create(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusCreated, map[string]string{"message": "Budget created"})
+type CreateBudgetRequest struct {
+	WorksheetID string                    `json:"worksheet_id"`
+	ValidUntil  string                    `json:"valid_until"`
+	Notes       *string                   `json:"notes"`
+	Items       []CreateBudgetItemRequest `json:"items"`
 }
 
-func (h *BudgetHandler) Get(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Get budget"})
+type CreateBudgetItemRequest struct {
+	WorksheetItemID *string         `json:"worksheet_item_id"`
+	Description     string          `json:"description"`
+	Quantity        float64         `json:"quantity"`
+	Unit            string          `json:"unit"`
+	UnitPrice       decimal.Decimal `json:"unit_price"`
+	Tax             decimal.Decimal `json:"tax"`
 }
 
-func (h *BudgetHandler) Update(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Budget updated"})
-}
+// Create creates a draft budget for an approved worksheet
+func (h *BudgetHandler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
 
-func (h *BudgetHandler) Delete(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Budget deleted"})
-}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
 
-func (h *BudgetHandler) Send(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Budget sent"})
-}
+	var req CreateBudgetRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
 
-func (h *BudgetHandler) Approve(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Budget approved"})
-}
+	worksheetID, err := uuid.Parse(req.WorksheetID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid worksheet ID")
+		return
+	}
+	validUntil, err := time.Parse("2006-01-02", req.ValidUntil)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid valid until date format. Use YYYY-MM-DD")
+		return
+	}
 
-func (h *BudgetHandler) Reject(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Budget rejected"})
-}
+	items := make([]*models.BudgetItem, 0, len(req.Items))
+	for _, it := range req.Items {
+		item := &models.BudgetItem{
+			Description: it.Description,
+			Quantity:    it.Quantity,
+			Unit:        it.Unit,
+			UnitPrice:   it.UnitPrice,
+			Tax:         it.Tax,
+		}
+		if it.WorksheetItemID != nil && *it.WorksheetItemID != "" {
+			worksheetItemID, err := uuid.Parse(*it.WorksheetItemID)
+			if err != nil {
+				utils.ErrorResponse(w, http.StatusBadRequest, "Invalid worksheet item ID")
+				return
+			}
+			item.WorkSheetItemID = &worksheetItemID
+		}
+		items = append(items, item)
+	}
 
-func (h *BudgetHandler) UploadPhoto(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Photo uploaded"})
-}
+	budget := &models.Budget{
+		OrganizationID: orgID,
+		WorkSheetID:    worksheetID,
+		ValidUntil:     validUntil,
+		Notes:          req.Notes,
+		CreatedBy:      userID,
+	}
+	if err := h.service.Create(r.Context(), budget, items); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-func (h *BudgetHandler) ListPhotos(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, []interface{}{})
+	utils.SuccessResponse(w, http.StatusCreated, budget)
 }
 
-func (h *BudgetHandler) GeneratePDF(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "PDF generated"})
+func (h *BudgetHandler) Get(w http.ResponseWriter, r *http.Request) {
+	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Get budget"})
 }
 
-// ProjectHandler
-type ProjectHandler struct {
-	service *services.ProjectService
+func (h *BudgetHandler) Update(w http.ResponseWriter, r *http.Request) {
+	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Budget updated"})
 }
 
-func NewProjectHandler(service *services.ProjectService) *ProjectHandler {
-	return &ProjectHandler{service: service}
+func (h *BudgetHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Budget deleted"})
 }
 
-func (h *ProjectHandler) List(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, []interface{}{})
-}
+// Send sends a draft budget to the client, or holds it for internal approval when a rule applies
+func (h *BudgetHandler) Send(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
 
-func (h *ProjectHandler) Create(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusCreated, map[string]string{"message": "Project created"})
-}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
 
-func (h *ProjectHandler) Get(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Get project"})
-}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid budget ID")
+		return
+	}
 
-func (h *ProjectHandler) Update(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Project updated"})
-}
+	status, err := h.service.Send(r.Context(), id, orgID, userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-func (h *ProjectHandler) Delete(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Project deleted"})
-}
+	message := "Budget sent successfully"
+	if status == models.BudgetStatusPendingInternalApproval {
+		message = "Budget submitted for internal approval"
+	}
 
-func (h *ProjectHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Project status updated"})
+	utils.SuccessMessageResponse(w, http.StatusOK, message, map[string]interface{}{
+		"status": status,
+	})
 }
 
-func (h *ProjectHandler) UpdateProgress(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Project progress updated"})
+func (h *BudgetHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Budget approved"})
 }
 
-func (h *ProjectHandler) UploadPhoto(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Photo uploaded"})
+type BudgetLossRequest struct {
+	Reason string  `json:"reason"` // price, timing, competitor, scope, no_response, other
+	Notes  *string `json:"notes"`
 }
 
-func (h *ProjectHandler) ListPhotos(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, []interface{}{})
-}
+// Reject records the client's rejection of a budget with a structured reason
+func (h *BudgetHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	orgID, userID, id, req, ok := h.parseLossRequest(w, r)
+	if !ok {
+		return
+	}
 
-// TaskHandler
-type TaskHandler struct {
-	service *services.TaskService
-}
+	if err := h.service.Reject(r.Context(), id, orgID, userID, models.LossReason(req.Reason), req.Notes); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-func NewTaskHandler(service *services.TaskService) *TaskHandler {
-	return &TaskHandler{service: service}
+	utils.SuccessMessageResponse(w, http.StatusOK, "Budget rejected successfully", nil)
 }
 
-func (h *TaskHandler) List(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, []interface{}{})
-}
+// RecordLossReason sets the loss reason of a rejected or expired budget
+func (h *BudgetHandler) RecordLossReason(w http.ResponseWriter, r *http.Request) {
+	orgID, userID, id, req, ok := h.parseLossRequest(w, r)
+	if !ok {
+		return
+	}
 
-func (h *TaskHandler) Create(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusCreated, map[string]string{"message": "Task created"})
-}
+	if err := h.service.RecordLossReason(r.Context(), id, orgID, userID, models.LossReason(req.Reason), req.Notes); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-func (h *TaskHandler) Get(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Get task"})
+	utils.SuccessMessageResponse(w, http.StatusOK, "Loss reason recorded successfully", nil)
 }
 
-func (h *TaskHandler) Update(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Task updated"})
-}
+// parseLossRequest extracts the organization, user, budget ID and loss reason body
+func (h *BudgetHandler) parseLossRequest(w http.ResponseWriter, r *http.Request) (orgID, userID, id uuid.UUID, req BudgetLossRequest, ok bool) {
+	orgID, ok = middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
 
-func (h *TaskHandler) Delete(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Task deleted"})
-}
+	userID, ok = middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
 
-func (h *TaskHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Task status updated"})
-}
+	var err error
+	id, err = uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid budget ID")
+		return orgID, userID, id, req, false
+	}
 
-func (h *TaskHandler) Assign(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Task assigned"})
-}
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return orgID, userID, id, req, false
+	}
 
-// PaymentHandler
-type PaymentHandler struct {
-	service *services.PaymentService
+	return orgID, userID, id, req, true
 }
 
-func NewPaymentHandler(service *services.PaymentService) *PaymentHandler {
-	return &PaymentHandler{service: service}
+func (h *BudgetHandler) UploadPhoto(w http.ResponseWriter, r *http.Request) {
+	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Photo uploaded"})
 }
 
-func (h *PaymentHandler) List(w http.ResponseWriter, r *http.Request) {
+func (h *BudgetHandler) ListPhotos(w http.ResponseWriter, r *http.Request) {
 	utils.SuccessResponse(w, http.StatusOK, []interface{}{})
 }
 
-func (h *PaymentHandler) Create(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusCreated, map[string]string{"message": "Payment created"})
-}
-
-func (h *PaymentHandler) Get(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Get payment"})
-}
-
-func (h *PaymentHandler) Update(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Payment updated"})
-}
-
-func (h *PaymentHandler) Delete(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Payment deleted"})
-}
-
-func (h *PaymentHandler) MarkAsPaid(w http.ResponseWriter, r *http.Request) {
-	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Payment marked as paid"})
-}
-
 // NotificationHandler
 type NotificationHandler struct {
 	service *services.NotificationService
@@ -382,3 +478,208 @@ func (h *ReportHandler) Clients(w http.ResponseWriter, r *http.Request) {
 func (h *ReportHandler) Tasks(w http.ResponseWriter, r *http.Request) {
 	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Tasks report"})
 }
+
+// MessageSpend returns message costs per month and channel and the current month's spend against the cap.
+// Query params: months (default 6)
+func (h *ReportHandler) MessageSpend(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	months := 6
+	if m := r.URL.Query().Get("months"); m != "" {
+		parsed, err := strconv.Atoi(m)
+		if err != nil || parsed <= 0 || parsed > 24 {
+			utils.ErrorResponse(w, http.StatusBadRequest, "months must be between 1 and 24")
+			return
+		}
+		months = parsed
+	}
+
+	report, err := h.service.MessageSpend(r.Context(), orgID, months)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, report)
+}
+
+// BudgetConversion returns budget win rates by loss reason, client segment and value band.
+// Query params: from, to (YYYY-MM-DD, on sent date) and bands (comma-separated totals, e.g. 5000,20000,50000)
+func (h *ReportHandler) BudgetConversion(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	var filters services.ConversionFilters
+	query := r.URL.Query()
+	if from := query.Get("from"); from != "" {
+		parsed, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid from date format. Use YYYY-MM-DD")
+			return
+		}
+		filters.From = &parsed
+	}
+	if to := query.Get("to"); to != "" {
+		parsed, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid to date format. Use YYYY-MM-DD")
+			return
+		}
+		// Include the whole end day
+		parsed = parsed.AddDate(0, 0, 1)
+		filters.To = &parsed
+	}
+	if bands := query.Get("bands"); bands != "" {
+		for _, b := range strings.Split(bands, ",") {
+			value, err := decimal.NewFromString(strings.TrimSpace(b))
+			if err != nil || !value.IsPositive() {
+				utils.ErrorResponse(w, http.StatusBadRequest, "Invalid value bands")
+				return
+			}
+			if n := len(filters.ValueBands); n > 0 && !value.GreaterThan(filters.ValueBands[n-1]) {
+				utils.ErrorResponse(w, http.StatusBadRequest, "Value bands must be in ascending order")
+				return
+			}
+			filters.ValueBands = append(filters.ValueBands, value)
+		}
+	}
+
+	report, err := h.service.BudgetConversion(r.Context(), orgID, filters)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, report)
+}
+
+// ReceivablesAging returns unpaid session and project payments bucketed by days past due per patient/client.
+// Query params: debtor_id and bucket (0_30, 31_60, 61_90, 90_plus) to drill down, as_of (YYYY-MM-DD)
+// for the receivables as they were at the end of a past day, format=csv to export
+func (h *ReportHandler) ReceivablesAging(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	var filters services.AgingFilters
+	query := r.URL.Query()
+	if debtorID := query.Get("debtor_id"); debtorID != "" {
+		id, err := uuid.Parse(debtorID)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid debtor ID")
+			return
+		}
+		filters.DebtorID = &id
+	}
+	if bucket := query.Get("bucket"); bucket != "" {
+		if !slices.Contains(services.AgingBuckets, bucket) {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid aging bucket")
+			return
+		}
+		filters.Bucket = bucket
+	}
+	if asOf := query.Get("as_of"); asOf != "" {
+		parsed, ok := parseAsOf(w, asOf)
+		if !ok {
+			return
+		}
+		filters.AsOf = &parsed
+	}
+
+	report, err := h.service.ReceivablesAging(r.Context(), orgID, filters)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if query.Get("format") == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="receivables-aging-`+report.AsOf.Format("2006-01-02")+`.csv"`)
+		w.WriteHeader(http.StatusOK)
+		report.WriteCSV(w)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, report)
+}
+
+// Appointments returns no-show, cancellation, confirmation and utilization figures per therapist and patient.
+// Query params: from, to (YYYY-MM-DD, on scheduled date; defaults to the last 30 days), therapist_id, format=csv to export
+func (h *ReportHandler) Appointments(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	now := time.Now()
+	filters := services.AppointmentReportFilters{
+		To: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1),
+	}
+	filters.From = filters.To.AddDate(0, 0, -30)
+	query := r.URL.Query()
+	if from := query.Get("from"); from != "" {
+		parsed, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid from date format. Use YYYY-MM-DD")
+			return
+		}
+		filters.From = parsed
+	}
+	if to := query.Get("to"); to != "" {
+		parsed, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid to date format. Use YYYY-MM-DD")
+			return
+		}
+		// Include the whole end day
+		filters.To = parsed.AddDate(0, 0, 1)
+	}
+	if !filters.To.After(filters.From) {
+		utils.ErrorResponse(w, http.StatusBadRequest, "end date must be after start date")
+		return
+	}
+	if therapistID := query.Get("therapist_id"); therapistID != "" {
+		id, err := uuid.Parse(therapistID)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid therapist ID")
+			return
+		}
+		filters.TherapistID = &id
+	}
+
+	report, err := h.service.Appointments(r.Context(), orgID, filters)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if query.Get("format") == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="appointments-`+report.From.Format("2006-01-02")+`.csv"`)
+		w.WriteHeader(http.StatusOK)
+		report.WriteCSV(w)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, report)
+}
+
+// statusChangeErrorResponse reports invalid transition inputs field by field
+func statusChangeErrorResponse(w http.ResponseWriter, err error) {
+	var validationErrs *apperrors.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		utils.AppErrorResponse(w, err)
+		return
+	}
+	utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// InboxHandler serves the priority inbox of items needing someone's action
+type InboxHandler struct {
+	service *services.InboxService
+}
+
+func NewInboxHandler(service *services.InboxService) *InboxHandler {
+	return &InboxHandler{service: service}
+}
+
+type AssignInboxItemRequest struct {
+	UserID *string `json:"user_id"` // null unassigns
+}
+
+type SnoozeInboxItemRequest struct {
+	Until string `json:"until"` // RFC3339 format
+}
+
+// List returns the open inbox items.
+// Filters: ?type=, ?assigned_to=me|unassigned|<user id>, ?include_snoozed=true
+func (h *InboxHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	filters := models.InboxFilters{
+		IncludeSnoozed: r.URL.Query().Get("include_snoozed") == "true",
+	}
+
+	if itemType := r.URL.Query().Get("type"); itemType != "" {
+		filters.Type = models.InboxItemType(itemType)
+		if !filters.Type.IsValid() {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid inbox item type")
+			return
+		}
+	}
+
+	switch assignedTo := r.URL.Query().Get("assigned_to"); assignedTo {
+	case "":
+	case "me":
+		filters.AssignedTo = &userID
+	case "unassigned":
+		filters.Unassigned = true
+	default:
+		id, err := uuid.Parse(assignedTo)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+			return
+		}
+		filters.AssignedTo = &id
+	}
+
+	items, err := h.service.List(r.Context(), orgID, userID, filters)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": items,
+		"total": len(items),
+	})
+}
+
+// parseInboxItem reads the item type and ID from the URL
+func parseInboxItem(w http.ResponseWriter, r *http.Request) (models.InboxItemType, uuid.UUID, bool) {
+	itemType := models.InboxItemType(chi.URLParam(r, "type"))
+	if !itemType.IsValid() {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid inbox item type")
+		return "", uuid.Nil, false
+	}
+
+	itemID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid inbox item ID")
+		return "", uuid.Nil, false
+	}
+
+	return itemType, itemID, true
+}
+
+// Assign gives an inbox item to a user
+func (h *InboxHandler) Assign(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	itemType, itemID, ok := parseInboxItem(w, r)
+	if !ok {
+		return
+	}
+
+	var req AssignInboxItemRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	var assignee *uuid.UUID
+	if req.UserID != nil {
+		id, err := uuid.Parse(*req.UserID)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+			return
+		}
+		assignee = &id
+	}
+
+	if err := h.service.Assign(r.Context(), orgID, itemType, itemID, assignee, userID); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Inbox item assigned successfully", nil)
+}
+
+// Snooze hides an inbox item until a later time
+func (h *InboxHandler) Snooze(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	itemType, itemID, ok := parseInboxItem(w, r)
+	if !ok {
+		return
+	}
+
+	var req SnoozeInboxItemRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	until, err := time.Parse(time.RFC3339, req.Until)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid snooze time format")
+		return
+	}
+
+	if err := h.service.Snooze(r.Context(), orgID, itemType, itemID, until, userID); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Inbox item snoozed successfully", nil)
+}
+
+// Dismiss removes an inbox item from the inbox
+func (h *InboxHandler) Dismiss(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	itemType, itemID, ok := parseInboxItem(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Dismiss(r.Context(), orgID, itemType, itemID, userID); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Inbox item dismissed successfully", nil)
+}
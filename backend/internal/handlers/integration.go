@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+)
+
+type IntegrationHandler struct {
+	service *services.IntegrationService
+}
+
+func NewIntegrationHandler(service *services.IntegrationService) *IntegrationHandler {
+	return &IntegrationHandler{service: service}
+}
+
+// Health actively checks each external integration and reports its status and last successful use
+func (h *IntegrationHandler) Health(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can check integrations")
+		return
+	}
+
+	results, err := h.service.CheckHealth(r.Context(), orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	healthy := true
+	for _, result := range results {
+		if result.Status == services.IntegrationStatusError {
+			healthy = false
+		}
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"healthy":      healthy,
+		"integrations": results,
+	})
+}
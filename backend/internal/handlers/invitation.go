@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type InvitationHandler struct {
+	service *services.InvitationService
+}
+
+func NewInvitationHandler(service *services.InvitationService) *InvitationHandler {
+	return &InvitationHandler{service: service}
+}
+
+type CreateInvitationRequest struct {
+	Email string      `json:"email"`
+	Role  models.Role `json:"role"`
+}
+
+// List returns the organization's invitations, optionally filtered by status
+func (h *InvitationHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	if !isUserAdmin(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can manage users")
+		return
+	}
+
+	invitations, err := h.service.List(r.Context(), orgID, r.URL.Query().Get("status"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": invitations,
+		"total": len(invitations),
+	})
+}
+
+// Create invites a colleague by email with a role
+func (h *InvitationHandler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+	if !isUserAdmin(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can manage users")
+		return
+	}
+
+	var req CreateInvitationRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	invitation, err := h.service.Create(r.Context(), orgID, userID, req.Email, req.Role)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Invitation sent successfully", invitation)
+}
+
+// Resend emails a new invite link with a fresh expiry
+func (h *InvitationHandler) Resend(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	if !isUserAdmin(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can manage users")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid invitation ID")
+		return
+	}
+
+	invitation, err := h.service.Resend(r.Context(), id, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Invitation resent successfully", invitation)
+}
+
+// Revoke cancels an open invitation
+func (h *InvitationHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	if !isUserAdmin(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can manage users")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid invitation ID")
+		return
+	}
+
+	invitation, err := h.service.Revoke(r.Context(), id, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Invitation revoked successfully", invitation)
+}
+
+// ============ Public Invitation Handlers ============
+
+// PublicGet returns the invitation behind an invite link so the accept page can show it
+func (h *InvitationHandler) PublicGet(w http.ResponseWriter, r *http.Request) {
+	invitation, err := h.service.GetByToken(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"email":             invitation.Email,
+		"role":              invitation.Role,
+		"organization_name": invitation.OrganizationName,
+		"invited_by_name":   invitation.InvitedByName,
+		"expires_at":        invitation.ExpiresAt,
+	})
+}
+
+// PublicAccept creates the invitee's account and logs them in
+func (h *InvitationHandler) PublicAccept(w http.ResponseWriter, r *http.Request) {
+	var req services.AcceptInvitationRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := h.service.Accept(r.Context(), chi.URLParam(r, "token"), req)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Invitation accepted successfully", response)
+}
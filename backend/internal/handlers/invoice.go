@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type InvoiceHandler struct {
+	service *services.InvoiceService
+}
+
+func NewInvoiceHandler(service *services.InvoiceService) *InvoiceHandler {
+	return &InvoiceHandler{service: service}
+}
+
+// invoiceError responds with 404 for missing invoices and sources and 400 for rule violations
+func invoiceError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case "invoice not found", "payment not found", "session payment not found":
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	default:
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// List returns invoices and credit notes, newest first
+func (h *InvoiceHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	q := r.URL.Query()
+	filters := services.InvoiceFilters{
+		Status:       q.Get("status"),
+		DocumentType: q.Get("document_type"),
+		Limit:        50,
+	}
+	if raw := q.Get("client_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid client ID")
+			return
+		}
+		filters.ClientID = &id
+	}
+	if raw := q.Get("from"); raw != "" {
+		t, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid date format, expected YYYY-MM-DD")
+			return
+		}
+		filters.From = &t
+	}
+	if raw := q.Get("to"); raw != "" {
+		t, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid date format, expected YYYY-MM-DD")
+			return
+		}
+		filters.To = &t
+	}
+	if l := q.Get("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+			filters.Limit = parsed
+		}
+	}
+	if o := q.Get("offset"); o != "" {
+		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
+			filters.Offset = parsed
+		}
+	}
+
+	invoices, total, err := h.service.List(r.Context(), orgID, filters)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": invoices,
+		"total": total,
+	})
+}
+
+// Get returns an invoice with its lines and VAT breakdown
+func (h *InvoiceHandler) Get(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid invoice ID")
+		return
+	}
+
+	invoice, err := h.service.GetByID(r.Context(), id, orgID)
+	if err != nil {
+		invoiceError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, invoice)
+}
+
+// CreateFromSessionPayment creates a draft invoice for a session payment
+func (h *InvoiceHandler) CreateFromSessionPayment(w http.ResponseWriter, r *http.Request) {
+	h.createDraft(w, r, h.service.CreateFromSessionPayment)
+}
+
+// CreateFromPayment creates a draft invoice for a project payment
+func (h *InvoiceHandler) CreateFromPayment(w http.ResponseWriter, r *http.Request) {
+	h.createDraft(w, r, h.service.CreateFromProjectPayment)
+}
+
+func (h *InvoiceHandler) createDraft(w http.ResponseWriter, r *http.Request,
+	create func(ctx context.Context, orgID, sourceID uuid.UUID, opts services.InvoiceOptions) (*models.Invoice, error)) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	sourceID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid payment ID")
+		return
+	}
+
+	// The body is optional: without it the module's VAT rate and payment terms apply
+	var opts services.InvoiceOptions
+	if r.ContentLength > 0 {
+		if err := utils.ParseJSON(r, &opts); err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+	opts.CreatedBy = &userID
+
+	invoice, err := create(r.Context(), orgID, sourceID, opts)
+	if err != nil {
+		invoiceError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Invoice created successfully", invoice)
+}
+
+// Issue assigns the next number of the series to a draft invoice
+func (h *InvoiceHandler) Issue(w http.ResponseWriter, r *http.Request) {
+	orgID, userID, id, ok := h.parseAction(w, r)
+	if !ok {
+		return
+	}
+
+	invoice, err := h.service.Issue(r.Context(), id, orgID, &userID)
+	if err != nil {
+		invoiceError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Invoice issued successfully", invoice)
+}
+
+// MarkPaid marks an issued invoice as paid
+func (h *InvoiceHandler) MarkPaid(w http.ResponseWriter, r *http.Request) {
+	orgID, userID, id, ok := h.parseAction(w, r)
+	if !ok {
+		return
+	}
+
+	invoice, err := h.service.MarkPaid(r.Context(), id, orgID, &userID)
+	if err != nil {
+		invoiceError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Invoice marked as paid", invoice)
+}
+
+// InvoiceReasonRequest is the request body for voiding or crediting an invoice
+type InvoiceReasonRequest struct {
+	Reason string `json:"reason"`
+}
+
+// Void voids an issued invoice. Its number stays used.
+func (h *InvoiceHandler) Void(w http.ResponseWriter, r *http.Request) {
+	orgID, userID, id, ok := h.parseAction(w, r)
+	if !ok {
+		return
+	}
+
+	var req InvoiceReasonRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	invoice, err := h.service.Void(r.Context(), id, orgID, req.Reason, &userID)
+	if err != nil {
+		invoiceError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Invoice voided successfully", invoice)
+}
+
+// CreditNote issues a credit note cancelling an invoice
+func (h *InvoiceHandler) CreditNote(w http.ResponseWriter, r *http.Request) {
+	orgID, userID, id, ok := h.parseAction(w, r)
+	if !ok {
+		return
+	}
+
+	var req InvoiceReasonRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	note, err := h.service.CreateCreditNote(r.Context(), id, orgID, req.Reason, &userID)
+	if err != nil {
+		invoiceError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Credit note issued successfully", note)
+}
+
+// Delete deletes a draft invoice
+func (h *InvoiceHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid invoice ID")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id, orgID); err != nil {
+		invoiceError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Invoice deleted successfully", nil)
+}
+
+// GeneratePDF returns a short-lived link to the invoice PDF, generating it when the invoice
+// changed since the last one
+func (h *InvoiceHandler) GeneratePDF(w http.ResponseWriter, r *http.Request) {
+	h.invoicePDF(w, r, false)
+}
+
+// RegeneratePDF rebuilds the invoice PDF and returns a short-lived link to it
+func (h *InvoiceHandler) RegeneratePDF(w http.ResponseWriter, r *http.Request) {
+	h.invoicePDF(w, r, true)
+}
+
+func (h *InvoiceHandler) invoicePDF(w http.ResponseWriter, r *http.Request, regenerate bool) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid invoice ID")
+		return
+	}
+
+	doc, err := h.service.GetPDF(r.Context(), id, orgID, regenerate)
+	if err != nil {
+		if err.Error() == "invoice not found" {
+			utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, doc)
+}
+
+func (h *InvoiceHandler) parseAction(w http.ResponseWriter, r *http.Request) (orgID, userID, id uuid.UUID, ok bool) {
+	orgID, ok = middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok = middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid invoice ID")
+		return orgID, userID, id, false
+	}
+	return orgID, userID, id, true
+}
@@ -0,0 +1,339 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// maxPriceListSize is the maximum size of an uploaded price list (10MB)
+const maxPriceListSize int64 = 10 << 20
+
+type MaterialHandler struct {
+	service *services.MaterialService
+}
+
+func NewMaterialHandler(service *services.MaterialService) *MaterialHandler {
+	return &MaterialHandler{service: service}
+}
+
+// List returns the materials catalogue
+func (h *MaterialHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	materials, err := h.service.ListMaterials(r.Context(), orgID, r.URL.Query().Get("search"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": materials,
+		"total": len(materials),
+	})
+}
+
+// PreviewImport parses an uploaded supplier price list and returns the rows for review.
+// Multipart fields: file (CSV/XLSX), target ("catalogue" or "budget"), budget_id,
+// supplier and options (JSON with column mapping, unit conversions and tax rate).
+func (h *MaterialHandler) PreviewImport(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxPriceListSize+1<<20)
+	if err := r.ParseMultipartForm(maxPriceListSize); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid upload or file too large")
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "File is required")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxPriceListSize+1))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Failed to read file")
+		return
+	}
+	if int64(len(data)) > maxPriceListSize {
+		utils.ErrorResponse(w, http.StatusBadRequest, "File is too large")
+		return
+	}
+
+	imp := &models.PriceListImport{
+		OrganizationID: orgID,
+		FileName:       header.Filename,
+		Target:         models.PriceListImportTarget(r.FormValue("target")),
+		Supplier:       r.FormValue("supplier"),
+		CreatedBy:      &userID,
+	}
+	if imp.Target == "" {
+		imp.Target = models.PriceListTargetCatalogue
+	}
+	if raw := r.FormValue("budget_id"); raw != "" {
+		budgetID, err := uuid.Parse(raw)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid budget ID")
+			return
+		}
+		imp.BudgetID = &budgetID
+	}
+	if raw := r.FormValue("options"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &imp.Options); err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid import options")
+			return
+		}
+	}
+
+	if err := h.service.PreviewImport(r.Context(), imp, data); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Price list parsed successfully", imp)
+}
+
+// GetImport returns a price list import with its parsed rows
+func (h *MaterialHandler) GetImport(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid import ID")
+		return
+	}
+
+	imp, err := h.service.GetImport(r.Context(), id, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, imp)
+}
+
+// ConfirmImport writes the valid rows of a previewed import
+func (h *MaterialHandler) ConfirmImport(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid import ID")
+		return
+	}
+
+	result, err := h.service.ConfirmImport(r.Context(), id, orgID, userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Price list imported successfully", result)
+}
+
+// CancelImport discards a previewed import
+func (h *MaterialHandler) CancelImport(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid import ID")
+		return
+	}
+
+	if err := h.service.CancelImport(r.Context(), id, orgID); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Price list import cancelled successfully", nil)
+}
+
+// ============ Cost Index Handlers ============
+
+// ListCostIndex returns the monthly construction cost index values
+func (h *MaterialHandler) ListCostIndex(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	values, err := h.service.ListCostIndex(r.Context(), orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": values,
+		"total": len(values),
+	})
+}
+
+// SetCostIndex creates or updates the index value of a month (period as YYYY-MM)
+func (h *MaterialHandler) SetCostIndex(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	period, err := time.Parse("2006-01", chi.URLParam(r, "period"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid period, use YYYY-MM")
+		return
+	}
+
+	var req struct {
+		Value decimal.Decimal `json:"value"`
+	}
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	value, err := h.service.SetCostIndex(r.Context(), orgID, period, req.Value)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Cost index updated successfully", value)
+}
+
+// DeleteCostIndex removes the index value of a month
+func (h *MaterialHandler) DeleteCostIndex(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	period, err := time.Parse("2006-01", chi.URLParam(r, "period"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid period, use YYYY-MM")
+		return
+	}
+
+	if err := h.service.DeleteCostIndex(r.Context(), orgID, period); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Cost index deleted successfully", nil)
+}
+
+// ============ Price Analysis Handlers ============
+
+// PriceAnalysis flags budget items whose price drifted from the price history
+func (h *MaterialHandler) PriceAnalysis(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	budgetID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid budget ID")
+		return
+	}
+
+	threshold := 0.0
+	if raw := r.URL.Query().Get("threshold"); raw != "" {
+		threshold, err = strconv.ParseFloat(raw, 64)
+		if err != nil || threshold <= 0 {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid threshold")
+			return
+		}
+	}
+
+	analysis, err := h.service.AnalyzeBudgetPrices(r.Context(), budgetID, orgID, threshold)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, analysis)
+}
+
+// ApplyPriceSuggestions updates flagged items of a draft budget to the suggested prices
+func (h *MaterialHandler) ApplyPriceSuggestions(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	budgetID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid budget ID")
+		return
+	}
+
+	var req struct {
+		ItemIDs   []uuid.UUID `json:"item_ids"`
+		Threshold float64     `json:"threshold"`
+	}
+	if r.ContentLength > 0 {
+		if err := utils.ParseJSON(r, &req); err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	updated, err := h.service.ApplyPriceSuggestions(r.Context(), budgetID, orgID, req.ItemIDs, req.Threshold)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Budget prices updated successfully", map[string]interface{}{
+		"updated": updated,
+	})
+}
@@ -204,3 +204,95 @@ func (h *ModuleHandler) GetModuleConfig(w http.ResponseWriter, r *http.Request)
 		"config":      config,
 	})
 }
+
+// GetModuleChangelog returns a module's release notes, flagging those the admins have not seen
+func (h *ModuleHandler) GetModuleChangelog(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	moduleName := chi.URLParam(r, "module")
+	if moduleName == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Module name is required")
+		return
+	}
+
+	entries, err := h.service.GetChangelog(r.Context(), orgID, models.ModuleName(moduleName))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": entries,
+		"total": len(entries),
+	})
+}
+
+// GetWhatsNew returns the unseen release notes of the organization's enabled modules
+func (h *ModuleHandler) GetWhatsNew(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can view module updates")
+		return
+	}
+
+	entries, err := h.service.GetWhatsNew(r.Context(), orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": entries,
+		"total": len(entries),
+	})
+}
+
+// MarkWhatsNewSeen clears the what's-new feed. Query param: module (defaults to all enabled modules)
+func (h *ModuleHandler) MarkWhatsNewSeen(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can view module updates")
+		return
+	}
+
+	moduleName := models.ModuleName(r.URL.Query().Get("module"))
+	if err := h.service.MarkChangelogSeen(r.Context(), orgID, moduleName); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Module updates marked as seen", nil)
+}
+
+// GetModuleConfigSchema returns the JSON Schema of a module's configuration for rendering its settings form
+func (h *ModuleHandler) GetModuleConfigSchema(w http.ResponseWriter, r *http.Request) {
+	moduleName := chi.URLParam(r, "module")
+	if moduleName == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Module name is required")
+		return
+	}
+
+	schema, err := h.service.GetConfigSchema(r.Context(), models.ModuleName(moduleName))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, schema)
+}
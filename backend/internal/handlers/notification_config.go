@@ -1,20 +1,32 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"net/mail"
+	"strconv"
+	"time"
 
 	"github.com/controlwise/backend/internal/middleware"
 	"github.com/controlwise/backend/internal/models"
 	"github.com/controlwise/backend/internal/services"
 	"github.com/controlwise/backend/internal/utils"
+	"github.com/google/uuid"
 )
 
 type NotificationConfigHandler struct {
 	whatsappService *services.WhatsAppService
+	emailDelivery   *services.EmailDeliveryService
+	workflowService *services.WorkflowService
 }
 
-func NewNotificationConfigHandler(whatsappService *services.WhatsAppService) *NotificationConfigHandler {
-	return &NotificationConfigHandler{whatsappService: whatsappService}
+func NewNotificationConfigHandler(whatsappService *services.WhatsAppService, emailDelivery *services.EmailDeliveryService, workflowService *services.WorkflowService) *NotificationConfigHandler {
+	return &NotificationConfigHandler{
+		whatsappService: whatsappService,
+		emailDelivery:   emailDelivery,
+		workflowService: workflowService,
+	}
 }
 
 // GetConfig returns the notification configuration for the organization
@@ -37,6 +49,7 @@ func (h *NotificationConfigHandler) GetConfig(w http.ResponseWriter, r *http.Req
 			"config": map[string]interface{}{
 				"whatsapp_enabled":     false,
 				"twilio_configured":    false,
+				"whatsapp_provider":    models.WhatsAppProviderTwilio,
 				"reminder_24h_enabled": true,
 				"reminder_2h_enabled":  true,
 			},
@@ -85,27 +98,105 @@ func (h *NotificationConfigHandler) UpdateConfig(w http.ResponseWriter, r *http.
 	utils.SuccessMessageResponse(w, http.StatusOK, "Notification settings updated successfully", config.ToPublic())
 }
 
-// TestWhatsApp sends a test message to verify configuration
-func (h *NotificationConfigHandler) TestWhatsApp(w http.ResponseWriter, r *http.Request) {
+// SetDoNotDisturb pauses normal workflow messages until a given time ("until": null resumes them).
+// Urgent actions are still sent.
+func (h *NotificationConfigHandler) SetDoNotDisturb(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can update notification settings")
+		return
+	}
+
+	var req struct {
+		Until *time.Time `json:"until"`
+	}
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.whatsappService.SetDoNotDisturb(r.Context(), orgID, req.Until); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	config, err := h.whatsappService.GetConfig(r.Context(), orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Notification settings updated successfully", config.ToPublic())
+}
+
+// SetSendWindow sets the part of the day messages may go out ("window": null sends at any time).
+// Messages due outside it are deferred to the start of the next window.
+func (h *NotificationConfigHandler) SetSendWindow(w http.ResponseWriter, r *http.Request) {
 	orgID, ok := middleware.GetOrganizationID(r.Context())
 	if !ok {
 		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
 		return
 	}
 
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can update notification settings")
+		return
+	}
+
 	var req struct {
-		PhoneNumber string `json:"phone_number"`
+		Window *models.SendWindow `json:"window"`
 	}
 	if err := utils.ParseJSON(r, &req); err != nil {
 		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
+	if err := h.whatsappService.SetSendWindow(r.Context(), orgID, req.Window); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	config, err := h.whatsappService.GetConfig(r.Context(), orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Notification settings updated successfully", config.ToPublic())
+}
+
+// TestWhatsApp sends a test message to verify configuration. With a template_id, the template's
+// approved WhatsApp template is sent with sample data instead, checking it with the provider.
+func (h *NotificationConfigHandler) TestWhatsApp(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	var req TestSendRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
 	if req.PhoneNumber == "" {
 		utils.ErrorResponse(w, http.StatusBadRequest, "Phone number is required")
 		return
 	}
 
+	if req.TemplateID != nil && *req.TemplateID != "" {
+		h.testWhatsAppTemplate(w, r, orgID, req)
+		return
+	}
+
 	testMessage := "Esta e uma mensagem de teste do controlwise. Se recebeu esta mensagem, a configuracao do WhatsApp esta correta!"
 
 	_, err := h.whatsappService.SendMessage(r.Context(), orgID, req.PhoneNumber, testMessage, nil)
@@ -116,3 +207,272 @@ func (h *NotificationConfigHandler) TestWhatsApp(w http.ResponseWriter, r *http.
 
 	utils.SuccessMessageResponse(w, http.StatusOK, "Test message sent successfully", nil)
 }
+
+// testWhatsAppTemplate sends a template's approved WhatsApp template with sample variables
+func (h *NotificationConfigHandler) testWhatsAppTemplate(w http.ResponseWriter, r *http.Request, orgID uuid.UUID, req TestSendRequest) {
+	id, err := uuid.Parse(*req.TemplateID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid template ID")
+		return
+	}
+	template, err := h.workflowService.GetTemplateByID(r.Context(), id, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "Template not found")
+		return
+	}
+	if template.WhatsAppContentSID == nil || *template.WhatsAppContentSID == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Template has no approved WhatsApp template")
+		return
+	}
+
+	variables, err := services.SampleTemplateVariables(template)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if _, err := h.whatsappService.SendTemplate(r.Context(), orgID, req.PhoneNumber, *template.WhatsAppContentSID, variables, nil); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Test message sent successfully", nil)
+}
+
+// GetSessionWindow reports whether free-form WhatsApp messages can currently be sent to a phone number
+func (h *NotificationConfigHandler) GetSessionWindow(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	phone := r.URL.Query().Get("phone")
+	if phone == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Phone number is required")
+		return
+	}
+
+	window, err := h.whatsappService.GetSessionWindow(r.Context(), orgID, phone)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, window)
+}
+
+type TestSendRequest struct {
+	Email       string  `json:"email"`
+	PhoneNumber string  `json:"phone_number"`
+	TemplateID  *string `json:"template_id"` // optional, rendered with sample data
+}
+
+// sampleMessage returns the template rendered with sample data, or the default test text
+func (h *NotificationConfigHandler) sampleMessage(ctx context.Context, orgID uuid.UUID, templateID *string, channel models.MessageChannel) (string, string, error) {
+	if templateID == nil || *templateID == "" {
+		return "Mensagem de teste controlwise",
+			"Esta e uma mensagem de teste do controlwise. Se recebeu esta mensagem, a configuracao esta correta!",
+			nil
+	}
+
+	id, err := uuid.Parse(*templateID)
+	if err != nil {
+		return "", "", errors.New("Invalid template ID")
+	}
+	template, err := h.workflowService.GetTemplateByID(ctx, id, orgID)
+	if err != nil {
+		return "", "", errors.New("Template not found")
+	}
+	if template.Channel != channel {
+		return "", "", errors.New("Template channel does not match the test channel")
+	}
+
+	subject, body := services.RenderSampleTemplate(template)
+	return subject, body, nil
+}
+
+// TestEmail sends a sample email, optionally rendered from a template, through the organization's
+// email provider and returns the provider result
+func (h *NotificationConfigHandler) TestEmail(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	var req TestSendRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if _, err := mail.ParseAddress(req.Email); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "A valid email is required")
+		return
+	}
+
+	subject, body, err := h.sampleMessage(r.Context(), orgID, req.TemplateID, models.MessageChannelEmail)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := h.emailDelivery.SendTest(r.Context(), orgID, req.Email, subject, body)
+	if errors.Is(err, services.ErrRecipientOptedOutEmail) || errors.Is(err, services.ErrRecipientSuppressedEmail) {
+		utils.ErrorResponse(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Test email sent successfully", result)
+}
+
+// TestSMS sends a sample SMS through Twilio, optionally rendered from a template, and returns the provider response
+func (h *NotificationConfigHandler) TestSMS(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	var req TestSendRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.PhoneNumber == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Phone number is required")
+		return
+	}
+
+	// SMS uses the plain-text WhatsApp templates
+	_, body, err := h.sampleMessage(r.Context(), orgID, req.TemplateID, models.MessageChannelWhatsApp)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := h.whatsappService.SendTestSMS(r.Context(), orgID, req.PhoneNumber, body)
+	if errors.Is(err, services.ErrRecipientOptedOutSMS) {
+		utils.ErrorResponse(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Test SMS sent successfully", result)
+}
+
+// RotateWebhookToken issues a new inbound webhook URL for the organization, invalidating the old one
+func (h *NotificationConfigHandler) RotateWebhookToken(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can update notification settings")
+		return
+	}
+
+	token, err := h.whatsappService.RotateWebhookToken(r.Context(), orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Webhook URL rotated successfully", map[string]string{
+		"webhook_path": "/webhooks/whatsapp/" + token,
+	})
+}
+
+// ListWebhookRefusals returns the most recent webhooks refused for the organization, because their
+// signature didn't match its provider credentials. ?limit= defaults to 50.
+func (h *NotificationConfigHandler) ListWebhookRefusals(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can view notification settings")
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	entries, err := h.whatsappService.ListWebhookRefusals(r.Context(), orgID, limit)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": entries,
+		"total": len(entries),
+	})
+}
+
+// PlanReminderMigration shows how the legacy 24h/2h reminders would become session workflow triggers
+func (h *NotificationConfigHandler) PlanReminderMigration(w http.ResponseWriter, r *http.Request) {
+	h.reminderMigration(w, r, true)
+}
+
+// MigrateReminders replaces the legacy 24h/2h reminders with session workflow triggers
+func (h *NotificationConfigHandler) MigrateReminders(w http.ResponseWriter, r *http.Request) {
+	h.reminderMigration(w, r, false)
+}
+
+func (h *NotificationConfigHandler) reminderMigration(w http.ResponseWriter, r *http.Request, dryRun bool) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can update notification settings")
+		return
+	}
+
+	if dryRun {
+		plan, err := h.workflowService.PlanReminderMigration(r.Context(), orgID)
+		if err != nil {
+			if err.Error() == "notification config not found" {
+				utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+				return
+			}
+			utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		utils.SuccessResponse(w, http.StatusOK, plan)
+		return
+	}
+
+	migration, err := h.workflowService.MigrateReminders(r.Context(), orgID)
+	if err != nil {
+		switch err.Error() {
+		case "notification config not found":
+			utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		case "reminders were already migrated":
+			utils.ErrorResponse(w, http.StatusConflict, err.Error())
+		default:
+			utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Reminders migrated to the session workflow successfully", migration)
+}
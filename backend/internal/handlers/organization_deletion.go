@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+)
+
+type OrganizationDeletionHandler struct {
+	service *services.OrganizationDeletionService
+}
+
+func NewOrganizationDeletionHandler(service *services.OrganizationDeletionService) *OrganizationDeletionHandler {
+	return &OrganizationDeletionHandler{service: service}
+}
+
+// Get returns the organization's latest deletion request with its events, or null
+func (h *OrganizationDeletionHandler) Get(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	req, err := h.service.Current(r.Context(), orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"request": req,
+	})
+}
+
+// RequestOrganizationDeletionRequest is the request body for requesting the organization's deletion
+type RequestOrganizationDeletionRequest struct {
+	Reason           string `json:"reason"`
+	ConfirmationName string `json:"confirmation_name"` // the organization name, typed back
+}
+
+// Request starts the deletion of the organization, which runs once its cooling-off period
+// ends (admin only)
+func (h *OrganizationDeletionHandler) Request(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can delete the organization")
+		return
+	}
+
+	var body RequestOrganizationDeletionRequest
+	if err := utils.ParseJSON(r, &body); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	req, err := h.service.RequestDeletion(r.Context(), orgID, userID, body.Reason, body.ConfirmationName)
+	if err != nil {
+		if err.Error() == "organization not found" {
+			utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Organization deletion requested successfully", req)
+}
+
+// Cancel stops the organization's deletion during its cooling-off period (admin only)
+func (h *OrganizationDeletionHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can cancel the organization's deletion")
+		return
+	}
+
+	current, err := h.service.Current(r.Context(), orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if current == nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "deletion request not found")
+		return
+	}
+
+	if err := h.service.Cancel(r.Context(), current.ID, &orgID, &userID, nil); err != nil {
+		if err.Error() == "deletion request not found" {
+			utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Organization deletion cancelled successfully", nil)
+}
@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type PatientPortalHandler struct {
+	service *services.PatientPortalService
+}
+
+func NewPatientPortalHandler(service *services.PatientPortalService) *PatientPortalHandler {
+	return &PatientPortalHandler{service: service}
+}
+
+// ============ Organization settings ============
+
+// GetSettings returns the organization's patient portal settings
+func (h *PatientPortalHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	settings, err := h.service.GetSettings(r.Context(), orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, settings)
+}
+
+// UpdateSettings turns the patient portal on or off and sets what patients can do in it (admin only)
+func (h *PatientPortalHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can update patient portal settings")
+		return
+	}
+
+	var req services.PatientPortalSettingsInput
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(r.Context(), orgID, req)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Patient portal settings updated successfully", settings)
+}
+
+// ============ Sign-in ============
+
+// RequestLoginLinkRequest is the request body for asking for a patient portal sign-in link
+type RequestLoginLinkRequest struct {
+	OrganizationID uuid.UUID `json:"organization_id"`
+	Email          string    `json:"email"`
+}
+
+// RequestLoginLink emails a sign-in link to the patient registered with the email, if any.
+// The answer is the same either way.
+func (h *PatientPortalHandler) RequestLoginLink(w http.ResponseWriter, r *http.Request) {
+	var req RequestLoginLinkRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.OrganizationID == uuid.Nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid organization ID")
+		return
+	}
+
+	if err := h.service.RequestLogin(r.Context(), req.OrganizationID, req.Email, r.RemoteAddr); err != nil {
+		if err.Error() == "email is required" {
+			utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to send sign-in link")
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "If the email belongs to a patient, a sign-in link has been sent", nil)
+}
+
+// PatientPortalLoginRequest is the request body for signing in with a sign-in link
+type PatientPortalLoginRequest struct {
+	Token string `json:"token"`
+}
+
+// Login exchanges a sign-in link for a patient portal token
+func (h *PatientPortalHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req PatientPortalLoginRequest
+	if err := utils.ParseJSON(r, &req); err != nil || req.Token == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	token, profile, err := h.service.Login(r.Context(), req.Token)
+	if err != nil {
+		if err.Error() == "sign-in link is invalid or has expired" {
+			utils.ErrorResponse(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"token":   token,
+		"patient": profile,
+	})
+}
+
+// ============ Signed-in patient ============
+
+// patientFromContext returns the signed-in patient and their organization
+func patientFromContext(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	patientID, ok := middleware.GetPatientID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Patient not found")
+		return uuid.Nil, uuid.Nil, false
+	}
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return patientID, orgID, true
+}
+
+// portalError answers a patient portal request that failed
+func (h *PatientPortalHandler) portalError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case "patient portal is not enabled":
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+	case "patient not found", "session not found":
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	default:
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// Me returns the signed-in patient's details and what the portal lets them do
+func (h *PatientPortalHandler) Me(w http.ResponseWriter, r *http.Request) {
+	patientID, orgID, ok := patientFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	profile, err := h.service.Profile(r.Context(), orgID, patientID)
+	if err != nil {
+		h.portalError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, profile)
+}
+
+// UpdateContact changes the signed-in patient's contact details
+func (h *PatientPortalHandler) UpdateContact(w http.ResponseWriter, r *http.Request) {
+	patientID, orgID, ok := patientFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	var req services.PatientContactInput
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	profile, err := h.service.UpdateContact(r.Context(), orgID, patientID, req)
+	if err != nil {
+		h.portalError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Contact details updated successfully", profile)
+}
+
+// ListSessions returns the signed-in patient's upcoming sessions, or past ones with ?when=past
+func (h *PatientPortalHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	patientID, orgID, ok := patientFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	q := r.URL.Query()
+	limit, offset := 50, 0
+	if l := q.Get("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+			limit = parsed
+		}
+	}
+	if o := q.Get("offset"); o != "" {
+		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
+			offset = parsed
+		}
+	}
+
+	sessions, total, err := h.service.ListSessions(r.Context(), orgID, patientID, q.Get("when") != "past", limit, offset)
+	if err != nil {
+		h.portalError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": sessions,
+		"total": total,
+	})
+}
+
+// ConfirmSession confirms one of the signed-in patient's pending sessions
+func (h *PatientPortalHandler) ConfirmSession(w http.ResponseWriter, r *http.Request) {
+	patientID, orgID, ok := patientFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	sessionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	if err := h.service.ConfirmSession(r.Context(), orgID, patientID, sessionID); err != nil {
+		h.portalError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Session confirmed successfully", nil)
+}
+
+// PatientCancelSessionRequest is the request body for a patient cancelling a session
+type PatientCancelSessionRequest struct {
+	Reason string `json:"reason"`
+}
+
+// CancelSession cancels one of the signed-in patient's sessions, up to the organization's cutoff
+func (h *PatientPortalHandler) CancelSession(w http.ResponseWriter, r *http.Request) {
+	patientID, orgID, ok := patientFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	sessionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	var req PatientCancelSessionRequest
+	if r.ContentLength > 0 {
+		if err := utils.ParseJSON(r, &req); err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	outcome, err := h.service.CancelSession(r.Context(), orgID, patientID, sessionID, req.Reason)
+	if err != nil {
+		h.portalError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Session cancelled successfully", outcome)
+}
+
+// ListPayments returns what the signed-in patient still owes
+func (h *PatientPortalHandler) ListPayments(w http.ResponseWriter, r *http.Request) {
+	patientID, orgID, ok := patientFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	payments, totalCents, err := h.service.OutstandingPayments(r.Context(), orgID, patientID)
+	if err != nil {
+		h.portalError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items":       payments,
+		"total_cents": totalCents,
+	})
+}
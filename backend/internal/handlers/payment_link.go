@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// maxStripeWebhookSize bounds the webhook payloads read from Stripe
+const maxStripeWebhookSize = 1 << 20
+
+type PaymentLinkHandler struct {
+	service *services.PaymentLinkService
+}
+
+func NewPaymentLinkHandler(service *services.PaymentLinkService) *PaymentLinkHandler {
+	return &PaymentLinkHandler{service: service}
+}
+
+// GetConfig returns the organization's Stripe configuration, without its secrets
+func (h *PaymentLinkHandler) GetConfig(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	config, err := h.service.GetConfig(r.Context(), orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if config == nil {
+		utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+			"config": map[string]interface{}{
+				"provider":           models.PaymentProviderStripe,
+				"is_enabled":         false,
+				"secret_key_set":     false,
+				"webhook_secret_set": false,
+			},
+		})
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"config": config.ToPublic(),
+	})
+}
+
+// UpdateConfig connects the organization's Stripe account (admin only)
+func (h *PaymentLinkHandler) UpdateConfig(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can update payment settings")
+		return
+	}
+
+	var req services.PaymentProviderConfigInput
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	config, err := h.service.SaveConfig(r.Context(), orgID, req)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Payment settings updated successfully", map[string]interface{}{
+		"config": config.ToPublic(),
+	})
+}
+
+// List returns payment links, optionally those of one target
+func (h *PaymentLinkHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	q := r.URL.Query()
+	filters := services.PaymentLinkFilters{
+		TargetType: q.Get("target_type"),
+		Status:     q.Get("status"),
+		Limit:      50,
+	}
+	if raw := q.Get("target_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid target ID")
+			return
+		}
+		filters.TargetID = &id
+	}
+	if l := q.Get("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+			filters.Limit = parsed
+		}
+	}
+	if o := q.Get("offset"); o != "" {
+		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
+			filters.Offset = parsed
+		}
+	}
+
+	links, total, err := h.service.List(r.Context(), orgID, filters)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": links,
+		"total": total,
+	})
+}
+
+// CreatePaymentLinkRequest is the request body for creating a payment link
+type CreatePaymentLinkRequest struct {
+	TargetType models.PaymentLinkTargetType `json:"target_type"` // session_payment, payment or budget
+	TargetID   uuid.UUID                    `json:"target_id"`
+}
+
+// Create returns a payment link for an unpaid session payment, project payment or approved
+// budget, reusing the open one when it is still valid
+func (h *PaymentLinkHandler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req CreatePaymentLinkRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	link, err := h.service.Create(r.Context(), orgID, req.TargetType, req.TargetID, &userID)
+	if err != nil {
+		switch err.Error() {
+		case "session payment not found", "payment not found", "budget not found":
+			utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		default:
+			utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Payment link created successfully", link)
+}
+
+// Cancel closes an open payment link
+func (h *PaymentLinkHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid payment link ID")
+		return
+	}
+
+	if err := h.service.Cancel(r.Context(), id, orgID); err != nil {
+		if err.Error() == "payment link not found" {
+			utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Payment link cancelled successfully", nil)
+}
+
+// StripeWebhook receives Stripe events on an organization's webhook URL. Events that fail are
+// answered with an error so Stripe retries them.
+func (h *PaymentLinkHandler) StripeWebhook(w http.ResponseWriter, r *http.Request) {
+	orgID, err := h.service.ResolveOrganizationByToken(r.Context(), chi.URLParam(r, "orgToken"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "Webhook not found")
+		return
+	}
+
+	payload, err := io.ReadAll(io.LimitReader(r.Body, maxStripeWebhookSize))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.service.HandleStripeWebhook(r.Context(), orgID, payload, r.Header.Get("Stripe-Signature")); err != nil {
+		if errors.Is(err, services.ErrInvalidWebhookSignature) {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid signature")
+			return
+		}
+		log.Printf("[StripeWebhook] Failed to process event for org %s: %v", orgID, err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to process event")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
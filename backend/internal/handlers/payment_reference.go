@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// maxEasypayNotificationSize bounds the notification payloads read from Easypay
+const maxEasypayNotificationSize = 64 << 10
+
+type PaymentReferenceHandler struct {
+	service *services.PaymentReferenceService
+}
+
+func NewPaymentReferenceHandler(service *services.PaymentReferenceService) *PaymentReferenceHandler {
+	return &PaymentReferenceHandler{service: service}
+}
+
+// GetConfig returns the organization's Multibanco provider and IBAN, without its secrets
+func (h *PaymentReferenceHandler) GetConfig(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	config, err := h.service.GetConfig(r.Context(), orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if config == nil {
+		utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+			"config": map[string]interface{}{
+				"provider":         models.PaymentReferenceProviderIfthenpay,
+				"is_enabled":       false,
+				"api_key_set":      false,
+				"callback_key_set": false,
+				"validity_days":    30,
+			},
+		})
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"config": config.ToPublic(),
+	})
+}
+
+// UpdateConfig sets up the organization's Multibanco provider and IBAN (admin only)
+func (h *PaymentReferenceHandler) UpdateConfig(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can update payment settings")
+		return
+	}
+
+	var req services.PaymentReferenceConfigInput
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	config, err := h.service.SaveConfig(r.Context(), orgID, req)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Payment settings updated successfully", map[string]interface{}{
+		"config": config.ToPublic(),
+	})
+}
+
+// List returns payment references, optionally those of one target
+func (h *PaymentReferenceHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	q := r.URL.Query()
+	filters := services.PaymentReferenceFilters{
+		TargetType: q.Get("target_type"),
+		Status:     q.Get("status"),
+		Limit:      50,
+	}
+	if raw := q.Get("target_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid target ID")
+			return
+		}
+		filters.TargetID = &id
+	}
+	if l := q.Get("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+			filters.Limit = parsed
+		}
+	}
+	if o := q.Get("offset"); o != "" {
+		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
+			filters.Offset = parsed
+		}
+	}
+
+	refs, total, err := h.service.List(r.Context(), orgID, filters)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": refs,
+		"total": total,
+	})
+}
+
+// CreatePaymentReferenceRequest is the request body for generating a payment reference
+type CreatePaymentReferenceRequest struct {
+	TargetType models.PaymentReferenceTargetType `json:"target_type"` // session_payment or payment
+	TargetID   uuid.UUID                         `json:"target_id"`
+}
+
+// Create returns the Multibanco and SEPA references of an unpaid session payment or project
+// payment, reusing the open ones when they are still valid
+func (h *PaymentReferenceHandler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req CreatePaymentReferenceRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	ref, err := h.service.Generate(r.Context(), orgID, req.TargetType, req.TargetID, &userID)
+	if err != nil {
+		switch err.Error() {
+		case "session payment not found", "payment not found":
+			utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		default:
+			utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Payment reference generated successfully", ref)
+}
+
+// Cancel withdraws an open payment reference
+func (h *PaymentReferenceHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid payment reference ID")
+		return
+	}
+
+	if err := h.service.Cancel(r.Context(), id, orgID); err != nil {
+		if err.Error() == "payment reference not found" {
+			utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Payment reference cancelled successfully", nil)
+}
+
+// IfthenpayCallback receives IfThenPay's Multibanco payment callback on an organization's URL,
+// set in the IfThenPay backoffice as
+// ?chave=[ANTI_PHISHING_KEY]&entidade=[ENTITY]&referencia=[REFERENCE]&valor=[AMOUNT]
+func (h *PaymentReferenceHandler) IfthenpayCallback(w http.ResponseWriter, r *http.Request) {
+	orgID, err := h.service.ResolveOrganizationByToken(r.Context(), models.PaymentReferenceProviderIfthenpay, chi.URLParam(r, "orgToken"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "Callback not found")
+		return
+	}
+
+	q := r.URL.Query()
+	err = h.service.HandleIfthenpayCallback(r.Context(), orgID, q.Get("chave"), q.Get("entidade"), q.Get("referencia"), q.Get("valor"))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCallbackKey) {
+			utils.ErrorResponse(w, http.StatusForbidden, "Invalid callback key")
+			return
+		}
+		log.Printf("[IfthenpayCallback] Failed to process callback for org %s: %v", orgID, err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to process callback")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// EasypayNotification receives Easypay's payment notifications on an organization's URL.
+// Notifications that fail are answered with an error so Easypay retries them.
+func (h *PaymentReferenceHandler) EasypayNotification(w http.ResponseWriter, r *http.Request) {
+	orgID, err := h.service.ResolveOrganizationByToken(r.Context(), models.PaymentReferenceProviderEasypay, chi.URLParam(r, "orgToken"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "Callback not found")
+		return
+	}
+
+	payload, err := io.ReadAll(io.LimitReader(r.Body, maxEasypayNotificationSize))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.service.HandleEasypayNotification(r.Context(), orgID, payload); err != nil {
+		log.Printf("[EasypayNotification] Failed to process notification for org %s: %v", orgID, err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to process notification")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,388 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type ProjectHandler struct {
+	service *services.ProjectService
+}
+
+func NewProjectHandler(service *services.ProjectService) *ProjectHandler {
+	return &ProjectHandler{service: service}
+}
+
+type CreateProjectRequest struct {
+	BudgetID        string  `json:"budget_id"`
+	Title           string  `json:"title"`
+	Description     *string `json:"description"`
+	Category        *string `json:"category"`
+	StartDate       *string `json:"start_date"`
+	ExpectedEndDate string  `json:"expected_end_date"`
+}
+
+type UpdateProjectRequest struct {
+	Title           string  `json:"title"`
+	Description     *string `json:"description"`
+	Category        *string `json:"category"`
+	StartDate       string  `json:"start_date"`
+	ExpectedEndDate string  `json:"expected_end_date"`
+}
+
+type UpdateProjectStatusRequest struct {
+	Status string                 `json:"status"`
+	Inputs map[string]interface{} `json:"inputs"` // declared by the workflow transition
+}
+
+type UpdateProjectProgressRequest struct {
+	Progress int `json:"progress"`
+}
+
+type UpdateProjectProgressModeRequest struct {
+	Mode models.ProjectProgressMode `json:"mode"` // manual or tasks
+}
+
+// List returns projects with their client and financial summary, filtered by status, client_id and search
+func (h *ProjectHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	q := r.URL.Query()
+	filters := services.ProjectFilters{
+		Status: q.Get("status"),
+		Search: q.Get("search"),
+		Limit:  50,
+	}
+	if raw := q.Get("client_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid client ID")
+			return
+		}
+		filters.ClientID = &id
+	}
+	if l := q.Get("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+			filters.Limit = parsed
+		}
+	}
+	if o := q.Get("offset"); o != "" {
+		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
+			filters.Offset = parsed
+		}
+	}
+
+	projects, total, err := h.service.List(r.Context(), orgID, filters)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": projects,
+		"total": total,
+	})
+}
+
+// Create starts a project from an approved budget
+func (h *ProjectHandler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req CreateProjectRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	budgetID, err := uuid.Parse(req.BudgetID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid budget ID")
+		return
+	}
+
+	input := services.CreateProjectRequest{
+		BudgetID:    budgetID,
+		Title:       req.Title,
+		Description: req.Description,
+		Category:    req.Category,
+	}
+	if req.StartDate != nil && *req.StartDate != "" {
+		parsed, err := time.Parse("2006-01-02", *req.StartDate)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid start date format. Use YYYY-MM-DD")
+			return
+		}
+		input.StartDate = &parsed
+	}
+	if req.ExpectedEndDate != "" {
+		parsed, err := time.Parse("2006-01-02", req.ExpectedEndDate)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid date format, expected YYYY-MM-DD")
+			return
+		}
+		input.ExpectedEndDate = parsed
+	}
+
+	project, err := h.service.CreateFromBudget(r.Context(), orgID, userID, input)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Project created successfully", project)
+}
+
+// Get returns a project with its client and financial summary
+func (h *ProjectHandler) Get(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	project, err := h.service.GetByID(r.Context(), id, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, project)
+}
+
+// Update changes a project's details and schedule
+func (h *ProjectHandler) Update(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	var req UpdateProjectRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid date format, expected YYYY-MM-DD")
+		return
+	}
+	endDate, err := time.Parse("2006-01-02", req.ExpectedEndDate)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid date format, expected YYYY-MM-DD")
+		return
+	}
+
+	project, err := h.service.Update(r.Context(), id, orgID, services.UpdateProjectRequest{
+		Title:           req.Title,
+		Description:     req.Description,
+		Category:        req.Category,
+		StartDate:       startDate,
+		ExpectedEndDate: endDate,
+	})
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Project updated successfully", project)
+}
+
+// Delete removes a project that has no paid payments
+func (h *ProjectHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id, orgID); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Project deleted successfully", nil)
+}
+
+func (h *ProjectHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	var req UpdateProjectStatusRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.Inputs == nil {
+		req.Inputs = map[string]interface{}{}
+	}
+
+	var changedBy *uuid.UUID
+	if userID, ok := middleware.GetUserID(r.Context()); ok {
+		changedBy = &userID
+	}
+
+	if err := h.service.UpdateStatus(r.Context(), id, orgID, models.ProjectStatus(req.Status), req.Inputs, changedBy); err != nil {
+		statusChangeErrorResponse(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Project status updated successfully", nil)
+}
+
+// UpdateProgress sets the completion percentage of an active project, as an override when its
+// progress is computed from its tasks
+func (h *ProjectHandler) UpdateProgress(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	var req UpdateProjectProgressRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	project, err := h.service.UpdateProgress(r.Context(), id, orgID, userID, req.Progress)
+	if err != nil {
+		projectProgressError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Project progress updated successfully", project)
+}
+
+// UpdateProgressMode switches a project between progress set by hand and progress computed from its tasks
+func (h *ProjectHandler) UpdateProgressMode(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	var req UpdateProjectProgressModeRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	project, err := h.service.SetProgressMode(r.Context(), id, orgID, req.Mode)
+	if err != nil {
+		projectProgressError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Project progress mode updated successfully", project)
+}
+
+// ClearProgressOverride returns a project to the progress computed from its tasks
+func (h *ProjectHandler) ClearProgressOverride(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	project, err := h.service.ClearProgressOverride(r.Context(), id, orgID)
+	if err != nil {
+		projectProgressError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Project progress override cleared successfully", project)
+}
+
+// projectProgressError maps project progress errors to their status
+func projectProgressError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case "project not found":
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	case "cannot update progress of completed or cancelled projects", "project progress is not computed from tasks":
+		utils.ErrorResponse(w, http.StatusConflict, err.Error())
+	default:
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+func (h *ProjectHandler) UploadPhoto(w http.ResponseWriter, r *http.Request) {
+	utils.SuccessResponse(w, http.StatusOK, map[string]string{"message": "Photo uploaded"})
+}
+
+func (h *ProjectHandler) ListPhotos(w http.ResponseWriter, r *http.Request) {
+	utils.SuccessResponse(w, http.StatusOK, []interface{}{})
+}
@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type ProjectTemplateHandler struct {
+	service *services.ProjectTemplateService
+}
+
+func NewProjectTemplateHandler(service *services.ProjectTemplateService) *ProjectTemplateHandler {
+	return &ProjectTemplateHandler{service: service}
+}
+
+type ProjectTemplateRequest struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+	Category    *string `json:"category"`
+	IsActive    *bool   `json:"is_active"`
+	services.ProjectTemplateStructure
+}
+
+type InstantiateProjectTemplateRequest struct {
+	BudgetID    string  `json:"budget_id"`
+	Title       string  `json:"title"`
+	Description *string `json:"description"`
+	StartDate   *string `json:"start_date"` // Format: "2006-01-02"
+}
+
+// List returns all project templates
+func (h *ProjectTemplateHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	activeOnly := r.URL.Query().Get("active") == "true"
+
+	templates, err := h.service.ListTemplates(r.Context(), orgID, activeOnly)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": templates,
+		"total": len(templates),
+	})
+}
+
+// Get returns a project template with its structure
+func (h *ProjectTemplateHandler) Get(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid template ID")
+		return
+	}
+
+	template, err := h.service.GetTemplate(r.Context(), id, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, template)
+}
+
+// Create creates a project template
+func (h *ProjectTemplateHandler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req ProjectTemplateRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	template := &models.ProjectTemplate{
+		OrganizationID: orgID,
+		Name:           req.Name,
+		Description:    req.Description,
+		Category:       req.Category,
+		CreatedBy:      &userID,
+	}
+
+	if err := h.service.CreateTemplate(r.Context(), template, req.ProjectTemplateStructure); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Project template created successfully", template)
+}
+
+// Update updates a project template and replaces its structure
+func (h *ProjectTemplateHandler) Update(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid template ID")
+		return
+	}
+
+	var req ProjectTemplateRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	template := &models.ProjectTemplate{
+		Name:        req.Name,
+		Description: req.Description,
+		Category:    req.Category,
+		IsActive:    req.IsActive == nil || *req.IsActive,
+	}
+
+	if err := h.service.UpdateTemplate(r.Context(), id, orgID, template, req.ProjectTemplateStructure); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Project template updated successfully", template)
+}
+
+// Delete deletes a project template
+func (h *ProjectTemplateHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid template ID")
+		return
+	}
+
+	if err := h.service.DeleteTemplate(r.Context(), id, orgID); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Project template deleted successfully", nil)
+}
+
+// Instantiate creates a project with milestones, tasks and documents from a template and an approved budget
+func (h *ProjectTemplateHandler) Instantiate(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid template ID")
+		return
+	}
+
+	var req InstantiateProjectTemplateRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	budgetID, err := uuid.Parse(req.BudgetID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid budget ID")
+		return
+	}
+
+	input := services.InstantiateTemplateInput{
+		BudgetID:    budgetID,
+		Title:       req.Title,
+		Description: req.Description,
+		CreatedBy:   userID,
+	}
+	if req.StartDate != nil && *req.StartDate != "" {
+		parsed, err := time.Parse("2006-01-02", *req.StartDate)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid start date format. Use YYYY-MM-DD")
+			return
+		}
+		input.StartDate = parsed
+	}
+
+	result, err := h.service.Instantiate(r.Context(), id, orgID, input)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Project created successfully", result)
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+// QuickCreateHandler creates records from loose typed text
+type QuickCreateHandler struct {
+	service *services.QuickCreateService
+}
+
+func NewQuickCreateHandler(service *services.QuickCreateService) *QuickCreateHandler {
+	return &QuickCreateHandler{service: service}
+}
+
+type QuickSessionRequest struct {
+	Text        string `json:"text"`         // e.g. "João Silva tomorrow 15h with Dr. Maria"
+	Timezone    string `json:"timezone"`     // optional, defaults to the therapist's
+	PatientID   string `json:"patient_id"`   // optional pick among the draft's candidates
+	TherapistID string `json:"therapist_id"` // optional pick among the draft's candidates
+	Confirm     bool   `json:"confirm"`      // book the session instead of only returning the draft
+}
+
+// Session resolves a typed text into a session draft, and books it when confirmed
+func (h *QuickCreateHandler) Session(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req QuickSessionRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	quick := services.QuickSessionRequest{
+		Text:     req.Text,
+		Timezone: req.Timezone,
+		Confirm:  req.Confirm,
+	}
+	if req.PatientID != "" {
+		id, err := uuid.Parse(req.PatientID)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid patient ID")
+			return
+		}
+		quick.PatientID = &id
+	}
+	if req.TherapistID != "" {
+		id, err := uuid.Parse(req.TherapistID)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid therapist ID")
+			return
+		}
+		quick.TherapistID = &id
+	}
+
+	draft, err := h.service.QuickSession(r.Context(), orgID, quick, userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if draft.Session != nil {
+		utils.SuccessMessageResponse(w, http.StatusCreated, "Session created successfully", draft)
+		return
+	}
+	utils.SuccessResponse(w, http.StatusOK, draft)
+}
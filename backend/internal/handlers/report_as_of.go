@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+)
+
+// parseAsOf parses the as_of day of a report, which cannot be in the future
+func parseAsOf(w http.ResponseWriter, value string) (time.Time, bool) {
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid as_of date format. Use YYYY-MM-DD")
+		return time.Time{}, false
+	}
+	if parsed.After(time.Now()) {
+		utils.ErrorResponse(w, http.StatusBadRequest, "as_of date cannot be in the future")
+		return time.Time{}, false
+	}
+	return parsed, true
+}
+
+// SessionsByStatus counts the sessions of a period per status and therapist.
+// Query params: from, to (YYYY-MM-DD, on scheduled date; defaults to the last 30 days) and as_of
+// (YYYY-MM-DD) for the statuses sessions had at the end of a past day
+func (h *ReportHandler) SessionsByStatus(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	now := time.Now()
+	filters := services.SessionStatusFilters{
+		To: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1),
+	}
+	filters.From = filters.To.AddDate(0, 0, -30)
+	query := r.URL.Query()
+	if from := query.Get("from"); from != "" {
+		parsed, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid from date format. Use YYYY-MM-DD")
+			return
+		}
+		filters.From = parsed
+	}
+	if to := query.Get("to"); to != "" {
+		parsed, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid to date format. Use YYYY-MM-DD")
+			return
+		}
+		// Include the whole end day
+		filters.To = parsed.AddDate(0, 0, 1)
+	}
+	if !filters.To.After(filters.From) {
+		utils.ErrorResponse(w, http.StatusBadRequest, "end date must be after start date")
+		return
+	}
+	if asOf := query.Get("as_of"); asOf != "" {
+		parsed, ok := parseAsOf(w, asOf)
+		if !ok {
+			return
+		}
+		filters.AsOf = &parsed
+	}
+
+	report, err := h.service.SessionsByStatus(r.Context(), orgID, filters)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, report)
+}
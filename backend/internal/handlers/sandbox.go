@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// SandboxHandler manages sandbox copies of the organization for workflow experimentation
+type SandboxHandler struct {
+	service *services.SandboxService
+}
+
+func NewSandboxHandler(service *services.SandboxService) *SandboxHandler {
+	return &SandboxHandler{service: service}
+}
+
+type CreateSandboxRequest struct {
+	Name       string `json:"name"`
+	SampleSize int    `json:"sample_size"` // clients copied, 25 by default, at most 100
+}
+
+type PromoteSandboxWorkflowRequest struct {
+	Name string `json:"name"` // defaults to the sandbox workflow's name
+}
+
+// requireSandboxAdmin resolves the organization and checks the user is an administrator
+func requireSandboxAdmin(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return uuid.Nil, false
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can manage sandboxes")
+		return uuid.Nil, false
+	}
+	return orgID, true
+}
+
+// List returns the organization's sandboxes
+func (h *SandboxHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := requireSandboxAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	sandboxes, err := h.service.List(r.Context(), orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": sandboxes,
+		"total": len(sandboxes),
+	})
+}
+
+// Create clones the organization's workflows, templates and a sample of anonymized data into a sandbox
+func (h *SandboxHandler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := requireSandboxAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req CreateSandboxRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	result, err := h.service.Create(r.Context(), orgID, userID, req.Name, req.SampleSize)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Sandbox created successfully", result)
+}
+
+// Access returns a token to work in the sandbox as its administrator
+func (h *SandboxHandler) Access(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := requireSandboxAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid sandbox ID")
+		return
+	}
+
+	access, err := h.service.IssueAccess(r.Context(), id, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, access)
+}
+
+// PromoteWorkflow imports a sandbox workflow into the organization, inactive until reviewed
+func (h *SandboxHandler) PromoteWorkflow(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := requireSandboxAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid sandbox ID")
+		return
+	}
+
+	workflowID, err := uuid.Parse(chi.URLParam(r, "workflowId"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid workflow ID")
+		return
+	}
+
+	var req PromoteSandboxWorkflowRequest
+	if r.ContentLength > 0 {
+		if err := utils.ParseJSON(r, &req); err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	result, err := h.service.Promote(r.Context(), id, orgID, workflowID, req.Name)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Workflow promoted successfully", result)
+}
+
+// Delete removes a sandbox
+func (h *SandboxHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := requireSandboxAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid sandbox ID")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id, orgID); err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Sandbox deleted successfully", nil)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/controlwise/backend/internal/utils"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 type SessionHandler struct {
@@ -29,6 +30,19 @@ type CreateSessionRequest struct {
 	PriceCents      int     `json:"price_cents"`
 	SessionType     string  `json:"session_type"`
 	Notes           *string `json:"notes"`
+	// Book over a conflicting session on purpose (e.g. couples therapy); requires a reason
+	OverrideConflict bool    `json:"override_conflict"`
+	OverrideReason   *string `json:"override_reason"`
+	// Without a therapist_id the least-loaded qualified therapist is picked
+	Specialty string `json:"specialty"`
+	// Catalogue service; fills duration, price and type when not given
+	ServiceID string `json:"service_id"`
+}
+
+// sessionCreatedResponse adds the auto-assignment explanation to a created session
+type sessionCreatedResponse struct {
+	*models.SessionWithDetails
+	Assignment *models.AssignmentDecision `json:"assignment,omitempty"`
 }
 
 type UpdateSessionRequest struct {
@@ -42,7 +56,28 @@ type UpdateSessionRequest struct {
 }
 
 type CancelSessionRequest struct {
-	Reason string `json:"reason"`
+	Reason   string                 `json:"reason"`
+	WaiveFee bool                   `json:"waive_fee"` // admins and managers only
+	Inputs   map[string]interface{} `json:"inputs"`    // declared by the workflow transition
+}
+
+// SessionTransitionRequest carries the inputs declared by the workflow transition of a status change
+type SessionTransitionRequest struct {
+	Inputs map[string]interface{} `json:"inputs"`
+}
+
+// parseTransitionInputs reads the optional transition inputs of a status change request
+func parseTransitionInputs(r *http.Request) (map[string]interface{}, error) {
+	var req SessionTransitionRequest
+	if r.ContentLength > 0 {
+		if err := utils.ParseJSON(r, &req); err != nil {
+			return nil, err
+		}
+	}
+	if req.Inputs == nil {
+		req.Inputs = map[string]interface{}{}
+	}
+	return req.Inputs, nil
 }
 
 func (h *SessionHandler) List(w http.ResponseWriter, r *http.Request) {
@@ -81,11 +116,24 @@ func (h *SessionHandler) List(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if projectID := r.URL.Query().Get("project_id"); projectID != "" {
+		if parsed, err := uuid.Parse(projectID); err == nil {
+			filters.ProjectID = &parsed
+		}
+	}
+
 	if status := r.URL.Query().Get("status"); status != "" {
 		s := models.SessionStatus(status)
 		filters.Status = &s
 	}
 
+	if overrideStatus := r.URL.Query().Get("override_status"); overrideStatus != "" {
+		s := models.SessionOverrideStatus(overrideStatus)
+		filters.OverrideStatus = &s
+	}
+
+	filters.Search = r.URL.Query().Get("search")
+
 	if startDate := r.URL.Query().Get("start_date"); startDate != "" {
 		if parsed, err := time.Parse("2006-01-02", startDate); err == nil {
 			filters.StartDate = &parsed
@@ -122,20 +170,21 @@ func (h *SessionHandler) GetCalendar(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse date range (default to current month)
-	now := time.Now()
-	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	// Parse date range (default to current month), as days in the organization's timezone
+	loc := h.service.Location(r.Context(), orgID)
+	now := time.Now().In(loc)
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
 	end := start.AddDate(0, 1, 0).Add(-time.Second)
 
 	if startStr := r.URL.Query().Get("start"); startStr != "" {
-		if parsed, err := time.Parse("2006-01-02", startStr); err == nil {
+		if parsed, err := time.ParseInLocation("2006-01-02", startStr, loc); err == nil {
 			start = parsed
 		}
 	}
 
 	if endStr := r.URL.Query().Get("end"); endStr != "" {
-		if parsed, err := time.Parse("2006-01-02", endStr); err == nil {
-			end = parsed.Add(24*time.Hour - time.Second)
+		if parsed, err := time.ParseInLocation("2006-01-02", endStr, loc); err == nil {
+			end = parsed.AddDate(0, 0, 1).Add(-time.Second)
 		}
 	}
 
@@ -154,9 +203,10 @@ func (h *SessionHandler) GetCalendar(w http.ResponseWriter, r *http.Request) {
 	}
 
 	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
-		"events": events,
-		"start":  start.Format("2006-01-02"),
-		"end":    end.Format("2006-01-02"),
+		"events":   events,
+		"start":    start.Format("2006-01-02"),
+		"end":      end.Format("2006-01-02"),
+		"timezone": loc.String(),
 	})
 }
 
@@ -202,12 +252,6 @@ func (h *SessionHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	therapistID, err := uuid.Parse(req.TherapistID)
-	if err != nil {
-		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid therapist ID")
-		return
-	}
-
 	patientID, err := uuid.Parse(req.PatientID)
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid patient ID")
@@ -220,15 +264,54 @@ func (h *SessionHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var serviceID *uuid.UUID
+	if req.ServiceID != "" {
+		parsed, err := uuid.Parse(req.ServiceID)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid service ID")
+			return
+		}
+		serviceID = &parsed
+	}
+
+	var therapistID uuid.UUID
+	var assignment *models.AssignmentDecision
+	if req.TherapistID == "" {
+		assignment, err = h.service.SuggestTherapist(r.Context(), orgID, services.SessionAssignmentRequest{
+			ScheduledAt:     scheduledAt,
+			DurationMinutes: req.DurationMinutes,
+			Specialty:       req.Specialty,
+			ServiceID:       serviceID,
+		})
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if assignment.SelectedID == nil {
+			utils.ErrorResponse(w, http.StatusConflict, "No available therapist for this time")
+			return
+		}
+		therapistID = *assignment.SelectedID
+	} else {
+		therapistID, err = uuid.Parse(req.TherapistID)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid therapist ID")
+			return
+		}
+	}
+
 	session := &models.Session{
-		OrganizationID:  orgID,
-		TherapistID:     therapistID,
-		PatientID:       patientID,
-		ScheduledAt:     scheduledAt,
-		DurationMinutes: req.DurationMinutes,
-		PriceCents:      req.PriceCents,
-		SessionType:     models.SessionType(req.SessionType),
-		Notes:           req.Notes,
+		OrganizationID:   orgID,
+		TherapistID:      therapistID,
+		PatientID:        patientID,
+		ScheduledAt:      scheduledAt,
+		DurationMinutes:  req.DurationMinutes,
+		PriceCents:       req.PriceCents,
+		SessionType:      models.SessionType(req.SessionType),
+		Notes:            req.Notes,
+		ConflictOverride: req.OverrideConflict,
+		OverrideReason:   req.OverrideReason,
+		ServiceID:        serviceID,
 	}
 
 	if err := h.service.Create(r.Context(), session, userID); err != nil {
@@ -243,7 +326,104 @@ func (h *SessionHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	utils.SuccessMessageResponse(w, http.StatusCreated, "Session created successfully", createdSession)
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Session created successfully", sessionCreatedResponse{
+		SessionWithDetails: createdSession,
+		Assignment:         assignment,
+	})
+}
+
+// SuggestTherapist explains which therapist would be auto-assigned to a slot.
+// Query params: scheduled_at (RFC3339), duration_minutes, specialty, service_id.
+func (h *SessionHandler) SuggestTherapist(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	scheduledAt, err := time.Parse(time.RFC3339, r.URL.Query().Get("scheduled_at"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid scheduled time format")
+		return
+	}
+
+	req := services.SessionAssignmentRequest{
+		ScheduledAt: scheduledAt,
+		Specialty:   r.URL.Query().Get("specialty"),
+	}
+	if raw := r.URL.Query().Get("service_id"); raw != "" {
+		serviceID, err := uuid.Parse(raw)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid service ID")
+			return
+		}
+		req.ServiceID = &serviceID
+	}
+	if d := r.URL.Query().Get("duration_minutes"); d != "" {
+		req.DurationMinutes, err = strconv.Atoi(d)
+		if err != nil || req.DurationMinutes <= 0 {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid duration")
+			return
+		}
+	}
+
+	decision, err := h.service.SuggestTherapist(r.Context(), orgID, req)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, decision)
+}
+
+type CheckAvailabilityRequest struct {
+	TherapistID      uuid.UUID  `json:"therapist_id"`
+	ScheduledAt      time.Time  `json:"scheduled_at"`
+	DurationMinutes  int        `json:"duration_minutes"`
+	ServiceID        *uuid.UUID `json:"service_id"`
+	ExcludeSessionID *uuid.UUID `json:"exclude_session_id"` // the session being edited
+}
+
+// CheckAvailability reports the conflicts of a session time and the nearest free alternatives,
+// without booking anything
+func (h *SessionHandler) CheckAvailability(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	var req CheckAvailabilityRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.TherapistID == uuid.Nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Therapist is required")
+		return
+	}
+	if req.DurationMinutes < 0 {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid duration")
+		return
+	}
+
+	availability, err := h.service.CheckAvailability(r.Context(), orgID, services.SessionAvailabilityRequest{
+		TherapistID:      req.TherapistID,
+		ScheduledAt:      req.ScheduledAt,
+		DurationMinutes:  req.DurationMinutes,
+		ServiceID:        req.ServiceID,
+		ExcludeSessionID: req.ExcludeSessionID,
+	})
+	if err != nil {
+		if err.Error() == "therapist not found" {
+			utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, availability)
 }
 
 func (h *SessionHandler) Update(w http.ResponseWriter, r *http.Request) {
@@ -335,8 +515,14 @@ func (h *SessionHandler) Confirm(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.Confirm(r.Context(), id, orgID, userID); err != nil {
-		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	inputs, err := parseTransitionInputs(r)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.service.Confirm(r.Context(), id, orgID, userID, inputs); err != nil {
+		statusChangeErrorResponse(w, err)
 		return
 	}
 
@@ -369,12 +555,293 @@ func (h *SessionHandler) Cancel(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.Cancel(r.Context(), id, orgID, req.Reason, userID); err != nil {
+	if req.WaiveFee {
+		role, _ := middleware.GetUserRole(r.Context())
+		if role != string(models.RoleAdmin) && role != string(models.RoleManager) {
+			utils.ErrorResponse(w, http.StatusForbidden, "Only admins and managers can waive cancellation fees")
+			return
+		}
+	}
+
+	if req.Inputs == nil {
+		req.Inputs = map[string]interface{}{}
+	}
+
+	outcome, err := h.service.Cancel(r.Context(), id, orgID, req.Reason, userID, req.WaiveFee, req.Inputs)
+	if err != nil {
+		statusChangeErrorResponse(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Session cancelled successfully", outcome)
+}
+
+// ApproveOverride approves a pending double-booking override (admins and managers only)
+func (h *SessionHandler) ApproveOverride(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	role, _ := middleware.GetUserRole(r.Context())
+	if role != string(models.RoleAdmin) && role != string(models.RoleManager) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only admins and managers can decide conflict overrides")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	if err := h.service.ApproveOverride(r.Context(), id, orgID, userID); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Conflict override approved successfully", nil)
+}
+
+// RejectOverride rejects a pending double-booking override, cancelling the session (admins and managers only)
+func (h *SessionHandler) RejectOverride(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	role, _ := middleware.GetUserRole(r.Context())
+	if role != string(models.RoleAdmin) && role != string(models.RoleManager) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only admins and managers can decide conflict overrides")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	var req struct {
+		Comment string `json:"comment"`
+	}
+	if r.ContentLength > 0 {
+		if err := utils.ParseJSON(r, &req); err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	if err := h.service.RejectOverride(r.Context(), id, orgID, req.Comment, userID); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Conflict override rejected successfully", nil)
+}
+
+// GetCancellationPolicy previews the fee that cancelling the session now would incur
+func (h *SessionHandler) GetCancellationPolicy(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	outcome, err := h.service.PreviewCancellation(r.Context(), id, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, outcome)
+}
+
+// GetUpcomingCommunications lists the reminders and workflow messages still scheduled for the
+// session, with when and how they will be sent and what the patient will receive
+func (h *SessionHandler) GetUpcomingCommunications(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	communications, err := h.service.UpcomingCommunications(r.Context(), id, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if communications == nil {
+		communications = []models.UpcomingCommunication{}
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": communications,
+		"total": len(communications),
+	})
+}
+
+// ============ Cancellation Policy Handlers ============
+
+// CancellationRuleRequest is the body for creating or updating a cancellation policy rule
+type CancellationRuleRequest struct {
+	Name        string          `json:"name"`
+	WindowHours int             `json:"window_hours"`
+	FeePercent  decimal.Decimal `json:"fee_percent"`
+	IsActive    *bool           `json:"is_active"`
+}
+
+func (req CancellationRuleRequest) toModel(orgID uuid.UUID) *models.CancellationPolicyRule {
+	rule := &models.CancellationPolicyRule{
+		OrganizationID: orgID,
+		Name:           req.Name,
+		WindowHours:    req.WindowHours,
+		FeePercent:     req.FeePercent,
+		IsActive:       true,
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+	return rule
+}
+
+// ListCancellationRules returns the organization's cancellation policy
+func (h *SessionHandler) ListCancellationRules(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	rules, err := h.service.ListCancellationRules(r.Context(), orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": rules,
+		"total": len(rules),
+	})
+}
+
+// CreateCancellationRule adds a fee window to the cancellation policy (admin only)
+func (h *SessionHandler) CreateCancellationRule(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only admins can manage the cancellation policy")
+		return
+	}
+
+	var req CancellationRuleRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	rule := req.toModel(orgID)
+	if err := h.service.CreateCancellationRule(r.Context(), rule); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Cancellation rule created successfully", rule)
+}
+
+// UpdateCancellationRule updates a fee window of the cancellation policy (admin only)
+func (h *SessionHandler) UpdateCancellationRule(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only admins can manage the cancellation policy")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid rule ID")
+		return
+	}
+
+	var req CancellationRuleRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	rule := req.toModel(orgID)
+	rule.ID = id
+	if err := h.service.UpdateCancellationRule(r.Context(), rule); err != nil {
 		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	utils.SuccessMessageResponse(w, http.StatusOK, "Session cancelled successfully", nil)
+	utils.SuccessMessageResponse(w, http.StatusOK, "Cancellation rule updated successfully", rule)
+}
+
+// DeleteCancellationRule removes a fee window from the cancellation policy (admin only)
+func (h *SessionHandler) DeleteCancellationRule(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only admins can manage the cancellation policy")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid rule ID")
+		return
+	}
+
+	if err := h.service.DeleteCancellationRule(r.Context(), id, orgID); err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Cancellation rule deleted successfully", nil)
 }
 
 func (h *SessionHandler) Complete(w http.ResponseWriter, r *http.Request) {
@@ -397,8 +864,14 @@ func (h *SessionHandler) Complete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.Complete(r.Context(), id, orgID, userID); err != nil {
-		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	inputs, err := parseTransitionInputs(r)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.service.Complete(r.Context(), id, orgID, userID, inputs); err != nil {
+		statusChangeErrorResponse(w, err)
 		return
 	}
 
@@ -425,8 +898,14 @@ func (h *SessionHandler) MarkNoShow(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.MarkNoShow(r.Context(), id, orgID, userID); err != nil {
-		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	inputs, err := parseTransitionInputs(r)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.service.MarkNoShow(r.Context(), id, orgID, userID, inputs); err != nil {
+		statusChangeErrorResponse(w, err)
 		return
 	}
 
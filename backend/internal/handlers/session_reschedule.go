@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+type SessionRescheduleHandler struct {
+	service *services.SessionRescheduleService
+}
+
+func NewSessionRescheduleHandler(service *services.SessionRescheduleService) *SessionRescheduleHandler {
+	return &SessionRescheduleHandler{service: service}
+}
+
+// rescheduleError answers a reschedule link request that failed
+func rescheduleError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case "reschedule link is invalid or has expired":
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	case "the selected time is no longer available":
+		utils.ErrorResponse(w, http.StatusConflict, err.Error())
+	default:
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// View returns the session a reschedule link moves (public)
+func (h *SessionRescheduleHandler) View(w http.ResponseWriter, r *http.Request) {
+	view, err := h.service.View(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		rescheduleError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, view)
+}
+
+// Slots returns the free slots of the session's therapist, from and to as in the public booking (public)
+func (h *SessionRescheduleHandler) Slots(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := parseSlotRange(w, r)
+	if !ok {
+		return
+	}
+
+	slots, err := h.service.Slots(r.Context(), chi.URLParam(r, "token"), from, to)
+	if err != nil {
+		rescheduleError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": slots,
+		"total": len(slots),
+	})
+}
+
+// RescheduleRequest is the request body for moving a session with a reschedule link
+type RescheduleRequest struct {
+	ScheduledAt time.Time `json:"scheduled_at"`
+}
+
+// Reschedule moves the session to the chosen slot (public)
+func (h *SessionRescheduleHandler) Reschedule(w http.ResponseWriter, r *http.Request) {
+	var req RescheduleRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	view, err := h.service.Reschedule(r.Context(), chi.URLParam(r, "token"), req.ScheduledAt)
+	if err != nil {
+		rescheduleError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Session rescheduled successfully", view)
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type CreateRecurringSessionRequest struct {
+	TherapistID     string  `json:"therapist_id"`
+	PatientID       string  `json:"patient_id"`
+	ScheduledAt     string  `json:"scheduled_at"` // RFC3339 format, the first session
+	DurationMinutes int     `json:"duration_minutes"`
+	PriceCents      int     `json:"price_cents"`
+	SessionType     string  `json:"session_type"`
+	Notes           *string `json:"notes"`
+	ServiceID       string  `json:"service_id"`
+
+	Frequency string   `json:"frequency"` // weekly or biweekly
+	Weekdays  []string `json:"weekdays"`  // MO..SU
+	Count     *int     `json:"count"`
+	Until     string   `json:"until"` // YYYY-MM-DD
+	// Leave out occurrences where the therapist is already booked instead of failing
+	SkipConflicts bool `json:"skip_conflicts"`
+}
+
+// CreateRecurring books a series of sessions from a recurrence rule
+func (h *SessionHandler) CreateRecurring(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req CreateRecurringSessionRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	therapistID, err := uuid.Parse(req.TherapistID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid therapist ID")
+		return
+	}
+
+	patientID, err := uuid.Parse(req.PatientID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid patient ID")
+		return
+	}
+
+	scheduledAt, err := time.Parse(time.RFC3339, req.ScheduledAt)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid scheduled time format")
+		return
+	}
+
+	var serviceID *uuid.UUID
+	if req.ServiceID != "" {
+		parsed, err := uuid.Parse(req.ServiceID)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid service ID")
+			return
+		}
+		serviceID = &parsed
+	}
+
+	rule := models.RecurrenceRule{
+		Frequency: models.RecurrenceFrequency(req.Frequency),
+		Weekdays:  req.Weekdays,
+		Count:     req.Count,
+	}
+	if req.Until != "" {
+		until, err := time.Parse("2006-01-02", req.Until)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid until date format, use YYYY-MM-DD")
+			return
+		}
+		rule.Until = &until
+	}
+
+	template := &models.Session{
+		OrganizationID:  orgID,
+		TherapistID:     therapistID,
+		PatientID:       patientID,
+		ScheduledAt:     scheduledAt,
+		DurationMinutes: req.DurationMinutes,
+		PriceCents:      req.PriceCents,
+		SessionType:     models.SessionType(req.SessionType),
+		Notes:           req.Notes,
+		ServiceID:       serviceID,
+	}
+
+	result, err := h.service.CreateRecurring(r.Context(), template, rule, req.SkipConflicts, userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Session series created successfully", result)
+}
+
+// GetSeries returns a session series and its sessions
+func (h *SessionHandler) GetSeries(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	seriesID, err := uuid.Parse(chi.URLParam(r, "seriesId"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid series ID")
+		return
+	}
+
+	series, err := h.service.GetSeries(r.Context(), seriesID, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, series)
+}
+
+// UpdateSeries edits the upcoming sessions of a series
+func (h *SessionHandler) UpdateSeries(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	seriesID, err := uuid.Parse(chi.URLParam(r, "seriesId"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid series ID")
+		return
+	}
+
+	var change models.SessionSeriesChange
+	if err := utils.ParseJSON(r, &change); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	updated, err := h.service.UpdateSeries(r.Context(), seriesID, orgID, change, userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Session series updated successfully", map[string]int{"updated": updated})
+}
+
+// CancelSeries cancels the upcoming sessions of a series
+func (h *SessionHandler) CancelSeries(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	seriesID, err := uuid.Parse(chi.URLParam(r, "seriesId"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid series ID")
+		return
+	}
+
+	var req CancelSessionRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.WaiveFee {
+		role, _ := middleware.GetUserRole(r.Context())
+		if role != string(models.RoleAdmin) && role != string(models.RoleManager) {
+			utils.ErrorResponse(w, http.StatusForbidden, "Only admins and managers can waive cancellation fees")
+			return
+		}
+	}
+
+	outcomes, err := h.service.CancelSeries(r.Context(), seriesID, orgID, req.Reason, userID, req.WaiveFee)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Session series cancelled successfully", map[string]interface{}{
+		"items": outcomes,
+		"total": len(outcomes),
+	})
+}
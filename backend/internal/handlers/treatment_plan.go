@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type TreatmentPlanHandler struct {
+	service *services.TreatmentPlanService
+}
+
+func NewTreatmentPlanHandler(service *services.TreatmentPlanService) *TreatmentPlanHandler {
+	return &TreatmentPlanHandler{service: service}
+}
+
+// treatmentPlanError answers a treatment plan request that failed
+func treatmentPlanError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case "treatment plan not found", "patient not found", "therapist not found", "goal not found",
+		"session not found", "session outcome not found":
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	case "only completed sessions can have an outcome", "the treatment plan is for another patient":
+		utils.ErrorResponse(w, http.StatusConflict, err.Error())
+	default:
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// ListByPatient returns a patient's treatment plans, latest first
+func (h *TreatmentPlanHandler) ListByPatient(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	patientID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid patient ID")
+		return
+	}
+
+	plans, err := h.service.ListByPatient(r.Context(), patientID, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": plans,
+		"total": len(plans),
+	})
+}
+
+// Create adds a treatment plan for a patient
+func (h *TreatmentPlanHandler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	patientID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid patient ID")
+		return
+	}
+
+	var req services.TreatmentPlanInput
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	plan, err := h.service.Create(r.Context(), patientID, orgID, userID, req)
+	if err != nil {
+		treatmentPlanError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Treatment plan created successfully", plan)
+}
+
+// Get returns a treatment plan with its goals and how it is progressing
+func (h *TreatmentPlanHandler) Get(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid treatment plan ID")
+		return
+	}
+
+	plan, err := h.service.GetByID(r.Context(), id, orgID)
+	if err != nil {
+		treatmentPlanError(w, err)
+		return
+	}
+	summary, err := h.service.Summary(r.Context(), id, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"plan":    plan,
+		"summary": summary,
+	})
+}
+
+// Update changes a treatment plan and its goals
+func (h *TreatmentPlanHandler) Update(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid treatment plan ID")
+		return
+	}
+
+	var req services.TreatmentPlanInput
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	plan, err := h.service.Update(r.Context(), id, orgID, req)
+	if err != nil {
+		treatmentPlanError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Treatment plan updated successfully", plan)
+}
+
+// Delete removes a treatment plan
+func (h *TreatmentPlanHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid treatment plan ID")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id, orgID); err != nil {
+		treatmentPlanError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Treatment plan deleted successfully", nil)
+}
+
+// SetGoalAchieved marks a goal of a plan as achieved ({"achieved": true}) or not
+func (h *TreatmentPlanHandler) SetGoalAchieved(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid treatment plan ID")
+		return
+	}
+	goalID, err := uuid.Parse(chi.URLParam(r, "goalId"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid goal ID")
+		return
+	}
+
+	var req struct {
+		Achieved bool `json:"achieved"`
+	}
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	plan, err := h.service.SetGoalAchieved(r.Context(), id, goalID, orgID, req.Achieved)
+	if err != nil {
+		treatmentPlanError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Goal updated successfully", plan)
+}
+
+// ListOutcomes returns the coded outcomes of a plan's sessions, oldest first
+func (h *TreatmentPlanHandler) ListOutcomes(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid treatment plan ID")
+		return
+	}
+
+	if _, err := h.service.GetByID(r.Context(), id, orgID); err != nil {
+		treatmentPlanError(w, err)
+		return
+	}
+	outcomes, err := h.service.ListOutcomes(r.Context(), id, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": outcomes,
+		"total": len(outcomes),
+	})
+}
+
+// GetSessionOutcome returns the coded outcome of a session
+func (h *TreatmentPlanHandler) GetSessionOutcome(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	sessionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	outcome, err := h.service.GetOutcome(r.Context(), sessionID, orgID)
+	if err != nil {
+		treatmentPlanError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, outcome)
+}
+
+// RecordSessionOutcome codes the outcome of a completed session against the patient's treatment plan
+func (h *TreatmentPlanHandler) RecordSessionOutcome(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	sessionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	var req services.SessionOutcomeInput
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	outcome, err := h.service.RecordOutcome(r.Context(), sessionID, orgID, userID, req)
+	if err != nil {
+		treatmentPlanError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Session outcome recorded successfully", outcome)
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+)
+
+// UsageHandler reports the organization's data usage to its administrators
+type UsageHandler struct {
+	service *services.UsageService
+}
+
+func NewUsageHandler(service *services.UsageService) *UsageHandler {
+	return &UsageHandler{service: service}
+}
+
+// Get returns the organization's latest usage snapshot and quota warnings
+func (h *UsageHandler) Get(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	role, _ := middleware.GetUserRole(r.Context())
+	if role != string(models.RoleAdmin) && role != string(models.RoleManager) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators and managers can view data usage")
+		return
+	}
+
+	usage, err := h.service.Get(r.Context(), orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, usage)
+}
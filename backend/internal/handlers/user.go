@@ -0,0 +1,315 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type UserHandler struct {
+	service *services.UserService
+}
+
+func NewUserHandler(service *services.UserService) *UserHandler {
+	return &UserHandler{service: service}
+}
+
+// isUserAdmin returns true if the current user may manage the organization's users
+func isUserAdmin(r *http.Request) bool {
+	role, ok := middleware.GetUserRole(r.Context())
+	return ok && role == string(models.RoleAdmin)
+}
+
+// List returns the organization's users, filtered by search, role and is_active
+func (h *UserHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	filters := services.UserFilters{
+		Search: r.URL.Query().Get("search"),
+		Role:   r.URL.Query().Get("role"),
+	}
+	if raw := r.URL.Query().Get("is_active"); raw != "" {
+		active, err := strconv.ParseBool(raw)
+		if err == nil {
+			filters.IsActive = &active
+		}
+	}
+
+	users, err := h.service.List(r.Context(), orgID, filters)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to list users")
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": users,
+		"total": len(users),
+	})
+}
+
+// Get returns a user of the organization
+func (h *UserHandler) Get(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	user, err := h.service.GetByID(r.Context(), id, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, user)
+}
+
+// Update changes a user's details and role
+func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	if !isUserAdmin(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can manage users")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var req services.UpdateUserRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	user, err := h.service.Update(r.Context(), id, orgID, req)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "User updated successfully", user)
+}
+
+// Deactivate blocks a user from logging in
+func (h *UserHandler) Deactivate(w http.ResponseWriter, r *http.Request) {
+	h.setActive(w, r, false)
+}
+
+// Reactivate allows a deactivated user to log in again
+func (h *UserHandler) Reactivate(w http.ResponseWriter, r *http.Request) {
+	h.setActive(w, r, true)
+}
+
+func (h *UserHandler) setActive(w http.ResponseWriter, r *http.Request, active bool) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+	if !isUserAdmin(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can manage users")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	user, err := h.service.SetActive(r.Context(), id, orgID, userID, active)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	message := "User deactivated successfully"
+	if active {
+		message = "User reactivated successfully"
+	}
+	utils.SuccessMessageResponse(w, http.StatusOK, message, user)
+}
+
+// Delete removes a user from the organization
+func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+	if !isUserAdmin(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can manage users")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id, orgID, userID); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "User deleted successfully", nil)
+}
+
+// ============ Self-service Profile ============
+
+// GetProfile returns the current user's profile
+func (h *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	user, err := h.service.GetByID(r.Context(), userID, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, user)
+}
+
+// UpdateProfile changes the current user's name and phone
+func (h *UserHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req services.UpdateProfileRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	user, err := h.service.UpdateProfile(r.Context(), userID, orgID, req)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Profile updated successfully", user)
+}
+
+// ChangePassword changes the current user's password, requiring the current one
+func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req services.ChangePasswordRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.service.ChangePassword(r.Context(), userID, orgID, req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Password changed successfully", nil)
+}
+
+// UploadAvatar replaces the current user's avatar. Multipart field: avatar (PNG, JPEG or GIF).
+func (h *UserHandler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, services.MaxAvatarUploadSize+1<<20)
+	if err := r.ParseMultipartForm(services.MaxAvatarUploadSize); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid upload or file too large")
+		return
+	}
+
+	file, _, err := r.FormFile("avatar")
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "File is required")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, services.MaxAvatarUploadSize+1))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Failed to read file")
+		return
+	}
+	if int64(len(data)) > services.MaxAvatarUploadSize {
+		utils.ErrorResponse(w, http.StatusBadRequest, "File is too large")
+		return
+	}
+
+	user, err := h.service.UploadAvatar(r.Context(), userID, orgID, data)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Avatar uploaded successfully", user)
+}
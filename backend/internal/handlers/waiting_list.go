@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type WaitingListHandler struct {
+	service *services.WaitingListService
+}
+
+func NewWaitingListHandler(service *services.WaitingListService) *WaitingListHandler {
+	return &WaitingListHandler{service: service}
+}
+
+// waitingListError answers a waiting list request that failed
+func waitingListError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case "waiting list entry not found", "patient not found", "therapist not found", "service not found",
+		"offer is invalid or has expired":
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	case "the slot is no longer available", "patient is already on the waiting list for this therapist and service":
+		utils.ErrorResponse(w, http.StatusConflict, err.Error())
+	default:
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// List returns the waiting list, longest waiting first, filtered by ?status= and ?therapist_id=
+func (h *WaitingListHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	q := r.URL.Query()
+	filters := services.WaitingListFilters{Limit: 50}
+	if l := q.Get("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+			filters.Limit = parsed
+		}
+	}
+	if o := q.Get("offset"); o != "" {
+		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
+			filters.Offset = parsed
+		}
+	}
+	if status := q.Get("status"); status != "" {
+		s := models.WaitingListStatus(status)
+		filters.Status = &s
+	}
+	if therapistID := q.Get("therapist_id"); therapistID != "" {
+		if parsed, err := uuid.Parse(therapistID); err == nil {
+			filters.TherapistID = &parsed
+		}
+	}
+
+	entries, total, err := h.service.List(r.Context(), orgID, filters)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": entries,
+		"total": total,
+	})
+}
+
+// Get returns a waiting list entry with the slots offered to it
+func (h *WaitingListHandler) Get(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid waiting list entry ID")
+		return
+	}
+
+	entry, err := h.service.GetByID(r.Context(), id, orgID)
+	if err != nil {
+		waitingListError(w, err)
+		return
+	}
+	offers, err := h.service.ListOffers(r.Context(), id, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"entry":  entry,
+		"offers": offers,
+	})
+}
+
+// Create puts a patient on the waiting list
+func (h *WaitingListHandler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req services.WaitingListInput
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	entry, err := h.service.Create(r.Context(), orgID, userID, req)
+	if err != nil {
+		waitingListError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Patient added to the waiting list", entry)
+}
+
+// Update changes the preferences of a waiting patient
+func (h *WaitingListHandler) Update(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid waiting list entry ID")
+		return
+	}
+
+	var req services.WaitingListInput
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	entry, err := h.service.Update(r.Context(), id, orgID, req)
+	if err != nil {
+		waitingListError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Waiting list entry updated successfully", entry)
+}
+
+// Delete takes a patient off the waiting list
+func (h *WaitingListHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid waiting list entry ID")
+		return
+	}
+
+	if err := h.service.Remove(r.Context(), id, orgID); err != nil {
+		waitingListError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Patient removed from the waiting list", nil)
+}
+
+// ============ Offer links (public) ============
+
+// ViewOffer returns the slot offered with an accept link
+func (h *WaitingListHandler) ViewOffer(w http.ResponseWriter, r *http.Request) {
+	offer, err := h.service.ViewOffer(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		waitingListError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, offer)
+}
+
+// AcceptOffer books the offered slot for the patient
+func (h *WaitingListHandler) AcceptOffer(w http.ResponseWriter, r *http.Request) {
+	offer, err := h.service.AcceptOffer(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		waitingListError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Session booked successfully", offer)
+}
+
+// DeclineOffer turns down the offered slot
+func (h *WaitingListHandler) DeclineOffer(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeclineOffer(r.Context(), chi.URLParam(r, "token")); err != nil {
+		waitingListError(w, err)
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Offer declined", nil)
+}
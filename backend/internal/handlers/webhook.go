@@ -1,70 +1,159 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
 	"net/http"
 
+	"github.com/controlwise/backend/internal/models"
 	"github.com/controlwise/backend/internal/services"
 	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
 
+// maxMetaWebhookSize bounds the webhook payloads read from the WhatsApp Cloud API
+const maxMetaWebhookSize = 1 << 20
+
 type WebhookHandler struct {
-	whatsappService *services.WhatsAppService
+	whatsappService         *services.WhatsAppService
+	emailSuppressionService *services.EmailSuppressionService
 }
 
-func NewWebhookHandler(whatsappService *services.WhatsAppService) *WebhookHandler {
-	return &WebhookHandler{whatsappService: whatsappService}
+func NewWebhookHandler(whatsappService *services.WhatsAppService, emailSuppressionService *services.EmailSuppressionService) *WebhookHandler {
+	return &WebhookHandler{whatsappService: whatsappService, emailSuppressionService: emailSuppressionService}
 }
 
-// TwilioIncoming handles incoming WhatsApp messages from Twilio
+// TwilioIncoming handles incoming WhatsApp messages from Twilio on the shared webhook URL.
+// The organization is resolved from the number the message was sent to, and the request must be
+// signed with that organization's auth token.
 func (h *WebhookHandler) TwilioIncoming(w http.ResponseWriter, r *http.Request) {
-	// Parse Twilio webhook data
 	if err := r.ParseForm(); err != nil {
 		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid form data")
 		return
 	}
 
-	from := r.FormValue("From")
-	body := r.FormValue("Body")
-	messageSID := r.FormValue("MessageSid")
-	accountSID := r.FormValue("AccountSid")
-
-	if from == "" || body == "" {
-		utils.ErrorResponse(w, http.StatusBadRequest, "Missing required fields")
+	orgID, err := h.whatsappService.ResolveOrganizationByNumber(r.Context(), r.FormValue("To"))
+	if err != nil {
+		// Unknown numbers are quarantined and acknowledged so Twilio doesn't retry them
+		h.refuseTwilio(r, nil, models.WebhookAuditUnroutable, err.Error())
+		writeEmptyTwiML(w)
+		return
+	}
+	if !h.verifyTwilio(w, r, orgID) {
 		return
 	}
 
-	// Find organization by Twilio account SID
-	// In a real implementation, you'd look up the org by the account SID
-	// For now, we'll use a header or query param
-	orgIDStr := r.URL.Query().Get("org_id")
-	if orgIDStr == "" {
-		// Try to find org by account SID
-		// This would require a database lookup
-		_ = accountSID // Suppress unused warning
-		utils.ErrorResponse(w, http.StatusBadRequest, "Organization ID required")
+	h.processIncoming(w, r, orgID)
+}
+
+// TwilioIncomingForOrg handles incoming WhatsApp messages on an organization's own webhook URL
+func (h *WebhookHandler) TwilioIncomingForOrg(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid form data")
 		return
 	}
 
-	orgID, err := uuid.Parse(orgIDStr)
+	orgID, err := h.whatsappService.ResolveOrganizationByToken(r.Context(), chi.URLParam(r, "orgToken"))
 	if err != nil {
-		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid organization ID")
+		h.refuseTwilio(r, nil, models.WebhookAuditUnroutable, err.Error())
+		utils.ErrorResponse(w, http.StatusNotFound, "Webhook not found")
+		return
+	}
+	if !h.verifyTwilio(w, r, orgID) {
 		return
 	}
 
-	// Process the incoming message
+	h.processIncoming(w, r, orgID)
+}
+
+// verifyTwilio checks the request's X-Twilio-Signature against the organization's auth token,
+// refusing the request when it doesn't match
+func (h *WebhookHandler) verifyTwilio(w http.ResponseWriter, r *http.Request, orgID uuid.UUID) bool {
+	err := h.whatsappService.VerifyTwilioRequest(r.Context(), orgID, webhookRequestURL(r), r.PostForm, r.Header.Get("X-Twilio-Signature"))
+	if err == nil {
+		return true
+	}
+	if !errors.Is(err, services.ErrInvalidWebhookSignature) {
+		log.Printf("[TwilioWebhook] Failed to verify request for org %s: %v", orgID, err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to verify signature")
+		return false
+	}
+	h.refuseTwilio(r, &orgID, models.WebhookAuditRejected, err.Error())
+	utils.ErrorResponse(w, http.StatusForbidden, "Invalid signature")
+	return false
+}
+
+// refuseTwilio records a refused Twilio webhook with its form parameters
+func (h *WebhookHandler) refuseTwilio(r *http.Request, orgID *uuid.UUID, outcome models.WebhookAuditOutcome, reason string) {
+	params := make(map[string]string, len(r.PostForm))
+	for key := range r.PostForm {
+		params[key] = r.PostForm.Get(key)
+	}
+	payload, _ := json.Marshal(params)
+	h.refuse(r, orgID, "twilio", outcome, reason, payload)
+}
+
+// refuse records a refused webhook under its route, which leaves organization tokens out of the log
+func (h *WebhookHandler) refuse(r *http.Request, orgID *uuid.UUID, provider string, outcome models.WebhookAuditOutcome, reason string, payload []byte) {
+	endpoint := r.URL.Path
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+		endpoint = rctx.RoutePattern()
+	}
+	remoteAddr := r.RemoteAddr
+	h.whatsappService.RecordWebhookRefusal(r.Context(), &models.WebhookAuditEntry{
+		OrganizationID: orgID,
+		Provider:       provider,
+		Endpoint:       endpoint,
+		Outcome:        outcome,
+		Reason:         reason,
+		RemoteAddr:     &remoteAddr,
+		Payload:        payload,
+	})
+}
+
+// webhookRequestURL is the absolute URL the provider called, which Twilio signs
+func webhookRequestURL(r *http.Request) string {
+	scheme := "https"
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	} else if r.TLS == nil {
+		scheme = "http"
+	}
+	return scheme + "://" + r.Host + r.URL.RequestURI()
+}
+
+// processIncoming stores an inbound message for the organization and acknowledges it
+func (h *WebhookHandler) processIncoming(w http.ResponseWriter, r *http.Request, orgID uuid.UUID) {
+	from := r.FormValue("From")
+	body := r.FormValue("Body")
+	messageSID := r.FormValue("MessageSid")
+
+	if from == "" || body == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing required fields")
+		return
+	}
+
+	// Log error but don't fail - Twilio expects 200 OK
 	if err := h.whatsappService.ProcessIncomingMessage(r.Context(), orgID, from, body, messageSID); err != nil {
-		// Log error but don't fail - Twilio expects 200 OK
-		// In production, log this error properly
+		log.Printf("[TwilioIncoming] Failed to process message %s for org %s: %v", messageSID, orgID, err)
 	}
 
-	// Twilio expects 200 OK with optional TwiML response
+	writeEmptyTwiML(w)
+}
+
+// writeEmptyTwiML acknowledges a Twilio webhook without replying
+func writeEmptyTwiML(w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "text/xml")
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("<Response></Response>"))
 }
 
-// TwilioStatus handles message status callbacks from Twilio
+// TwilioStatus handles message status callbacks from Twilio. The organization is the one that sent
+// the message, whose auth token must have signed the callback.
 func (h *WebhookHandler) TwilioStatus(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
 		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid form data")
@@ -79,9 +168,113 @@ func (h *WebhookHandler) TwilioStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	orgID, err := h.whatsappService.ResolveOrganizationByMessage(r.Context(), messageSID)
+	if err != nil {
+		// Callbacks of messages we didn't send are quarantined and acknowledged
+		h.refuseTwilio(r, nil, models.WebhookAuditUnroutable, err.Error())
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if !h.verifyTwilio(w, r, orgID) {
+		return
+	}
+
 	// Update message status in database
-	if err := h.whatsappService.UpdateMessageStatus(r.Context(), messageSID, messageStatus); err != nil {
-		// Log error but don't fail
+	// Price and PriceUnit are only present once Twilio has priced the message
+	if err := h.whatsappService.UpdateMessageStatus(r.Context(), messageSID, messageStatus, r.FormValue("Price"), r.FormValue("PriceUnit")); err != nil {
+		log.Printf("[TwilioStatus] Failed to update message %s: %v", messageSID, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// MetaVerify answers the WhatsApp Cloud API subscription check of an organization's webhook URL
+func (h *WebhookHandler) MetaVerify(w http.ResponseWriter, r *http.Request) {
+	orgID, err := h.whatsappService.ResolveOrganizationByToken(r.Context(), chi.URLParam(r, "orgToken"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "Webhook not found")
+		return
+	}
+
+	q := r.URL.Query()
+	if err := h.whatsappService.VerifyMetaSubscription(r.Context(), orgID, q.Get("hub.mode"), q.Get("hub.verify_token")); err != nil {
+		utils.ErrorResponse(w, http.StatusForbidden, "Invalid verify token")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(q.Get("hub.challenge")))
+}
+
+// MetaIncoming receives WhatsApp Cloud API messages and status updates on an organization's
+// webhook URL. Processing errors are logged and acknowledged, like Twilio's, so Meta doesn't retry.
+func (h *WebhookHandler) MetaIncoming(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(io.LimitReader(r.Body, maxMetaWebhookSize))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	orgID, err := h.whatsappService.ResolveOrganizationByToken(r.Context(), chi.URLParam(r, "orgToken"))
+	if err != nil {
+		h.refuse(r, nil, "meta", models.WebhookAuditUnroutable, err.Error(), payload)
+		utils.ErrorResponse(w, http.StatusNotFound, "Webhook not found")
+		return
+	}
+
+	if err := h.whatsappService.HandleMetaWebhook(r.Context(), orgID, payload, r.Header.Get("X-Hub-Signature-256")); err != nil {
+		if errors.Is(err, services.ErrInvalidWebhookSignature) {
+			h.refuse(r, &orgID, "meta", models.WebhookAuditRejected, err.Error(), payload)
+			utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid signature")
+			return
+		}
+		log.Printf("[MetaIncoming] Failed to process webhook for org %s: %v", orgID, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// SendGridEvents receives SendGrid Event Webhook posts on an organization's URL and records their
+// bounces and spam reports. Posts must be signed with the organization's Signed Event Webhook key.
+func (h *WebhookHandler) SendGridEvents(w http.ResponseWriter, r *http.Request) {
+	h.emailEvents(w, r, "sendgrid", func(ctx context.Context, orgID uuid.UUID, payload []byte) error {
+		return h.emailSuppressionService.HandleSendGridEvents(ctx, orgID, payload,
+			r.Header.Get("X-Twilio-Email-Event-Webhook-Signature"), r.Header.Get("X-Twilio-Email-Event-Webhook-Timestamp"))
+	})
+}
+
+// SESEvents receives the Amazon SNS notifications of SES bounces and complaints on an
+// organization's URL, and confirms the topic subscription. Messages must be signed by SNS and come
+// from the organization's topic.
+func (h *WebhookHandler) SESEvents(w http.ResponseWriter, r *http.Request) {
+	h.emailEvents(w, r, "ses", h.emailSuppressionService.HandleSESNotification)
+}
+
+func (h *WebhookHandler) emailEvents(w http.ResponseWriter, r *http.Request, provider string, handle func(ctx context.Context, orgID uuid.UUID, payload []byte) error) {
+	payload, err := io.ReadAll(io.LimitReader(r.Body, maxMetaWebhookSize))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	orgID, err := h.whatsappService.ResolveOrganizationByToken(r.Context(), chi.URLParam(r, "orgToken"))
+	if err != nil {
+		h.refuse(r, nil, provider, models.WebhookAuditUnroutable, err.Error(), payload)
+		utils.ErrorResponse(w, http.StatusNotFound, "Webhook not found")
+		return
+	}
+
+	if err := handle(r.Context(), orgID, payload); err != nil {
+		if errors.Is(err, services.ErrInvalidWebhookSignature) {
+			h.refuse(r, &orgID, provider, models.WebhookAuditRejected, err.Error(), payload)
+			utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid signature")
+			return
+		}
+		// Providers retry failed deliveries, so storage errors are reported to them
+		log.Printf("[EmailEvents] Failed to process %s webhook for org %s: %v", provider, orgID, err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to process events")
+		return
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/controlwise/backend/internal/middleware"
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type WhatsAppConversationHandler struct {
+	service *services.WhatsAppConversationService
+}
+
+func NewWhatsAppConversationHandler(service *services.WhatsAppConversationService) *WhatsAppConversationHandler {
+	return &WhatsAppConversationHandler{service: service}
+}
+
+// pageParams reads ?limit= (50 by default, at most 100) and ?offset=
+func pageParams(r *http.Request) (limit, offset int) {
+	limit = 50
+	q := r.URL.Query()
+	if l := q.Get("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+			limit = parsed
+		}
+	}
+	if o := q.Get("offset"); o != "" {
+		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
+			offset = parsed
+		}
+	}
+	return limit, offset
+}
+
+// ListThreads returns the WhatsApp conversations, most recent first, filtered by ?client_id=,
+// ?patient_id= and ?unread=true
+func (h *WhatsAppConversationHandler) ListThreads(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	q := r.URL.Query()
+	filters := services.WhatsAppThreadFilters{UnreadOnly: q.Get("unread") == "true"}
+	filters.Limit, filters.Offset = pageParams(r)
+	if clientID := q.Get("client_id"); clientID != "" {
+		parsed, err := uuid.Parse(clientID)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid client ID")
+			return
+		}
+		filters.ClientID = &parsed
+	}
+	if patientID := q.Get("patient_id"); patientID != "" {
+		parsed, err := uuid.Parse(patientID)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid patient ID")
+			return
+		}
+		filters.PatientID = &parsed
+	}
+
+	threads, total, err := h.service.ListThreads(r.Context(), orgID, filters)
+	if err != nil {
+		switch err.Error() {
+		case "client not found", "patient not found":
+			utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		default:
+			utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": threads,
+		"total": total,
+	})
+}
+
+// UnreadCount returns the number of unread inbound messages across conversations
+func (h *WhatsAppConversationHandler) UnreadCount(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	count, err := h.service.UnreadCount(r.Context(), orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]int{"count": count})
+}
+
+// Messages returns the messages of a conversation, newest first
+func (h *WhatsAppConversationHandler) Messages(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	limit, offset := pageParams(r)
+	messages, total, err := h.service.Messages(r.Context(), orgID, chi.URLParam(r, "phone"), limit, offset)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": messages,
+		"total": total,
+	})
+}
+
+// WhatsAppReplyRequest is a free-form reply typed in the app, optionally with a file attached
+type WhatsAppReplyRequest struct {
+	Body     string `json:"body"`
+	MediaURL string `json:"media_url"` // public https URL of the file; the body is its caption
+}
+
+// Reply sends a free-form message to a conversation within the WhatsApp 24-hour window
+func (h *WhatsAppConversationHandler) Reply(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req WhatsAppReplyRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	message, err := h.service.Reply(r.Context(), orgID, userID, chi.URLParam(r, "phone"), req.Body, req.MediaURL)
+	if err != nil {
+		switch err.Error() {
+		case "the WhatsApp conversation window is closed; only approved templates can be sent",
+			"recipient has opted out of WhatsApp messages":
+			utils.ErrorResponse(w, http.StatusConflict, err.Error())
+		default:
+			utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Message sent successfully", message)
+}
+
+// MarkRead marks a conversation as read
+func (h *WhatsAppConversationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	if err := h.service.MarkRead(r.Context(), orgID, chi.URLParam(r, "phone")); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Conversation marked as read", nil)
+}
+
+// Media downloads the file of an inbound message received through the WhatsApp Cloud API
+func (h *WhatsAppConversationHandler) Media(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	messageID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid message ID")
+		return
+	}
+
+	contentType, data, err := h.service.DownloadMedia(r.Context(), orgID, messageID)
+	if err != nil {
+		switch err.Error() {
+		case "message not found", "message has no media":
+			utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		case "WhatsApp Cloud API credentials not configured":
+			utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		default:
+			utils.ErrorResponse(w, http.StatusBadGateway, err.Error())
+		}
+		return
+	}
+
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
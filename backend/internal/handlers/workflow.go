@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/controlwise/backend/internal/middleware"
 	"github.com/controlwise/backend/internal/models"
@@ -197,6 +198,59 @@ func (h *WorkflowHandler) DuplicateWorkflow(w http.ResponseWriter, r *http.Reque
 	utils.SuccessMessageResponse(w, http.StatusCreated, "Workflow duplicated successfully", duplicated)
 }
 
+// ExportWorkflow downloads a workflow, with the templates its actions use, as a JSON document
+// that can be imported into another organization
+func (h *WorkflowHandler) ExportWorkflow(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid workflow ID")
+		return
+	}
+
+	export, err := h.service.ExportWorkflow(r.Context(), id, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="workflow-%s.json"`, id))
+	w.WriteHeader(http.StatusOK)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(export)
+}
+
+// ImportWorkflow creates a workflow from an exported document sent as the request body.
+// The optional name query parameter renames it, e.g. when the export's name is taken.
+func (h *WorkflowHandler) ImportWorkflow(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	var export models.WorkflowExport
+	if err := utils.ParseJSON(r, &export); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	result, err := h.service.ImportWorkflow(r.Context(), orgID, &export, r.URL.Query().Get("name"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Workflow imported successfully", result)
+}
+
 // ============ State Handlers ============
 
 type CreateStateRequest struct {
@@ -317,15 +371,125 @@ func (h *WorkflowHandler) ReorderStates(w http.ResponseWriter, r *http.Request)
 	utils.SuccessMessageResponse(w, http.StatusOK, "States reordered successfully", nil)
 }
 
+// GetFollowUpSequence returns the follow-up reminders configured for a state
+func (h *WorkflowHandler) GetFollowUpSequence(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	workflowID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid workflow ID")
+		return
+	}
+
+	stateID, err := uuid.Parse(chi.URLParam(r, "stateId"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid state ID")
+		return
+	}
+
+	steps, err := h.service.GetFollowUpSequence(r.Context(), workflowID, orgID, stateID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": steps,
+		"total": len(steps),
+	})
+}
+
+// SetFollowUpSequence replaces the follow-up reminders of a state
+func (h *WorkflowHandler) SetFollowUpSequence(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	workflowID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid workflow ID")
+		return
+	}
+
+	stateID, err := uuid.Parse(chi.URLParam(r, "stateId"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid state ID")
+		return
+	}
+
+	var req struct {
+		Steps []services.FollowUpStep `json:"steps"`
+	}
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	steps, err := h.service.SetFollowUpSequence(r.Context(), workflowID, orgID, stateID, req.Steps)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Follow-up sequence updated successfully", map[string]interface{}{
+		"items": steps,
+		"total": len(steps),
+	})
+}
+
+// SetTransitionInputs replaces the inputs a transition requires when it is taken
+func (h *WorkflowHandler) SetTransitionInputs(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	workflowID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid workflow ID")
+		return
+	}
+
+	transitionID, err := uuid.Parse(chi.URLParam(r, "transitionId"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid transition ID")
+		return
+	}
+
+	var req struct {
+		Inputs []models.TransitionInput `json:"inputs"`
+	}
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	transition, err := h.service.SetTransitionInputs(r.Context(), workflowID, transitionID, orgID, req.Inputs)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Transition inputs updated successfully", transition)
+}
+
 // ============ Trigger Handlers ============
 
 type CreateTriggerRequest struct {
 	StateID           *string `json:"state_id"`
 	TransitionID      *string `json:"transition_id"`
-	TriggerType       string  `json:"trigger_type" validate:"required,oneof=on_enter on_exit time_before time_after recurring"`
+	TriggerType       string  `json:"trigger_type" validate:"required,oneof=on_enter on_exit time_before time_after recurring compliance_overdue task_assigned task_due payment_overdue treatment_plan_review email_bounced"`
 	TimeOffsetMinutes *int    `json:"time_offset_minutes"`
 	TimeField         *string `json:"time_field"`
 	RecurringCron     *string `json:"recurring_cron"`
+	RecurringSkip     string  `json:"recurring_skip"`
 	Conditions        *json.RawMessage `json:"conditions"`
 }
 
@@ -348,6 +512,7 @@ func (h *WorkflowHandler) CreateTrigger(w http.ResponseWriter, r *http.Request)
 		TimeOffsetMinutes: req.TimeOffsetMinutes,
 		TimeField:         req.TimeField,
 		RecurringCron:     req.RecurringCron,
+		RecurringSkip:     models.RecurringSkipRule(req.RecurringSkip),
 	}
 
 	if req.StateID != nil {
@@ -394,6 +559,7 @@ func (h *WorkflowHandler) UpdateTrigger(w http.ResponseWriter, r *http.Request)
 		TimeOffsetMinutes *int             `json:"time_offset_minutes"`
 		TimeField         *string          `json:"time_field"`
 		RecurringCron     *string          `json:"recurring_cron"`
+		RecurringSkip     string           `json:"recurring_skip"`
 		Conditions        *json.RawMessage `json:"conditions"`
 		IsActive          bool             `json:"is_active"`
 	}
@@ -407,6 +573,7 @@ func (h *WorkflowHandler) UpdateTrigger(w http.ResponseWriter, r *http.Request)
 		TimeOffsetMinutes: req.TimeOffsetMinutes,
 		TimeField:         req.TimeField,
 		RecurringCron:     req.RecurringCron,
+		RecurringSkip:     models.RecurringSkipRule(req.RecurringSkip),
 		IsActive:          req.IsActive,
 	}
 
@@ -448,10 +615,14 @@ func (h *WorkflowHandler) DeleteTrigger(w http.ResponseWriter, r *http.Request)
 // ============ Action Handlers ============
 
 type CreateActionRequest struct {
-	ActionType   string           `json:"action_type" validate:"required,oneof=send_whatsapp send_email update_field create_task"`
+	ActionType   string           `json:"action_type" validate:"required"` // built-in type or a custom type registered on the worker
 	ActionOrder  int              `json:"action_order"`
 	TemplateID   *string          `json:"template_id"`
 	ActionConfig *json.RawMessage `json:"action_config"`
+	Urgency      string           `json:"urgency" validate:"omitempty,oneof=normal urgent"`
+	// Retry policy, defaults to a single attempt
+	RetryMaxAttempts    int `json:"retry_max_attempts"`
+	RetryBackoffSeconds int `json:"retry_backoff_seconds"`
 }
 
 func (h *WorkflowHandler) CreateAction(w http.ResponseWriter, r *http.Request) {
@@ -471,6 +642,10 @@ func (h *WorkflowHandler) CreateAction(w http.ResponseWriter, r *http.Request) {
 		TriggerID:   triggerID,
 		ActionType:  models.ActionType(req.ActionType),
 		ActionOrder: req.ActionOrder,
+		Urgency:     models.ActionUrgency(req.Urgency),
+
+		RetryMaxAttempts:    req.RetryMaxAttempts,
+		RetryBackoffSeconds: req.RetryBackoffSeconds,
 	}
 
 	if req.TemplateID != nil {
@@ -506,7 +681,11 @@ func (h *WorkflowHandler) UpdateAction(w http.ResponseWriter, r *http.Request) {
 		ActionOrder  int              `json:"action_order"`
 		TemplateID   *string          `json:"template_id"`
 		ActionConfig *json.RawMessage `json:"action_config"`
+		Urgency      string           `json:"urgency"`
 		IsActive     bool             `json:"is_active"`
+
+		RetryMaxAttempts    int `json:"retry_max_attempts"`
+		RetryBackoffSeconds int `json:"retry_backoff_seconds"`
 	}
 	if err := utils.ParseJSON(r, &req); err != nil {
 		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
@@ -516,7 +695,11 @@ func (h *WorkflowHandler) UpdateAction(w http.ResponseWriter, r *http.Request) {
 	action := &models.WorkflowAction{
 		ActionType:  models.ActionType(req.ActionType),
 		ActionOrder: req.ActionOrder,
+		Urgency:     models.ActionUrgency(req.Urgency),
 		IsActive:    req.IsActive,
+
+		RetryMaxAttempts:    req.RetryMaxAttempts,
+		RetryBackoffSeconds: req.RetryBackoffSeconds,
 	}
 
 	if req.TemplateID != nil {
@@ -553,11 +736,13 @@ func (h *WorkflowHandler) DeleteAction(w http.ResponseWriter, r *http.Request) {
 // ============ Template Handlers ============
 
 type CreateTemplateRequest struct {
-	Name      string           `json:"name" validate:"required,min=2,max=100"`
-	Channel   string           `json:"channel" validate:"required,oneof=whatsapp email"`
-	Subject   *string          `json:"subject"`
-	Body      string           `json:"body" validate:"required"`
-	Variables *json.RawMessage `json:"variables"`
+	Name               string           `json:"name" validate:"required,min=2,max=100"`
+	Channel            string           `json:"channel" validate:"required,oneof=whatsapp email"`
+	Subject            *string          `json:"subject"`
+	Body               string           `json:"body" validate:"required"`
+	Variables          *json.RawMessage `json:"variables"`
+	WhatsAppContentSID *string          `json:"whatsapp_content_sid"`
+	Force              bool             `json:"force"` // save despite lint errors
 }
 
 func (h *WorkflowHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
@@ -616,21 +801,29 @@ func (h *WorkflowHandler) CreateTemplate(w http.ResponseWriter, r *http.Request)
 	}
 
 	template := &models.MessageTemplate{
-		OrganizationID: orgID,
-		Name:           req.Name,
-		Channel:        models.MessageChannel(req.Channel),
-		Subject:        req.Subject,
-		Body:           req.Body,
+		OrganizationID:     orgID,
+		Name:               req.Name,
+		Channel:            models.MessageChannel(req.Channel),
+		Subject:            req.Subject,
+		Body:               req.Body,
+		WhatsAppContentSID: req.WhatsAppContentSID,
 	}
 
 	if req.Variables != nil {
 		template.Variables = *req.Variables
 	}
 
+	issues, err := h.service.LintTemplateForSave(r.Context(), orgID, nil, template, req.Force)
+	if err != nil {
+		utils.AppErrorResponse(w, err)
+		return
+	}
+
 	if err := h.service.CreateTemplate(r.Context(), template); err != nil {
 		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	template.Issues = issues
 
 	utils.SuccessMessageResponse(w, http.StatusCreated, "Template created successfully", template)
 }
@@ -649,12 +842,14 @@ func (h *WorkflowHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request)
 	}
 
 	var req struct {
-		Name      string           `json:"name"`
-		Channel   string           `json:"channel"`
-		Subject   *string          `json:"subject"`
-		Body      string           `json:"body"`
-		Variables *json.RawMessage `json:"variables"`
-		IsActive  bool             `json:"is_active"`
+		Name               string           `json:"name"`
+		Channel            string           `json:"channel"`
+		Subject            *string          `json:"subject"`
+		Body               string           `json:"body"`
+		Variables          *json.RawMessage `json:"variables"`
+		IsActive           bool             `json:"is_active"`
+		WhatsAppContentSID *string          `json:"whatsapp_content_sid"`
+		Force              bool             `json:"force"` // save despite lint errors
 	}
 	if err := utils.ParseJSON(r, &req); err != nil {
 		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
@@ -662,23 +857,33 @@ func (h *WorkflowHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request)
 	}
 
 	template := &models.MessageTemplate{
-		Name:     req.Name,
-		Channel:  models.MessageChannel(req.Channel),
-		Subject:  req.Subject,
-		Body:     req.Body,
-		IsActive: req.IsActive,
+		Name:               req.Name,
+		Channel:            models.MessageChannel(req.Channel),
+		Subject:            req.Subject,
+		Body:               req.Body,
+		WhatsAppContentSID: req.WhatsAppContentSID,
+		IsActive:           req.IsActive,
 	}
 
 	if req.Variables != nil {
 		template.Variables = *req.Variables
 	}
 
+	issues, err := h.service.LintTemplateForSave(r.Context(), orgID, &id, template, req.Force)
+	if err != nil {
+		utils.AppErrorResponse(w, err)
+		return
+	}
+
 	if err := h.service.UpdateTemplate(r.Context(), id, orgID, template); err != nil {
 		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	updated, _ := h.service.GetTemplateByID(r.Context(), id, orgID)
+	if updated != nil {
+		updated.Issues = issues
+	}
 	utils.SuccessMessageResponse(w, http.StatusOK, "Template updated successfully", updated)
 }
 
@@ -703,6 +908,40 @@ func (h *WorkflowHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request)
 	utils.SuccessMessageResponse(w, http.StatusOK, "Template deleted successfully", nil)
 }
 
+// PreviewTemplate renders a template against an entity or sample data, optionally linting it
+func (h *WorkflowHandler) PreviewTemplate(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid template ID")
+		return
+	}
+
+	var req services.TemplatePreviewRequest
+	if r.ContentLength > 0 {
+		if err := utils.ParseJSON(r, &req); err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+	if r.URL.Query().Get("lint") == "true" {
+		req.Lint = true
+	}
+
+	preview, err := h.service.PreviewTemplate(r.Context(), orgID, id, req)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, preview)
+}
+
 // ============ Execution Log Handlers ============
 
 // GetExecutionLogs returns workflow execution logs with optional filters
@@ -761,6 +1000,35 @@ func (h *WorkflowHandler) GetExecutionLogs(w http.ResponseWriter, r *http.Reques
 	})
 }
 
+// ReplayExecution re-runs a past trigger execution against current entity data in dry-run
+// mode and reports what would differ now
+func (h *WorkflowHandler) ReplayExecution(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	var req services.ExecutionReplayRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	replay, err := h.service.ReplayExecution(r.Context(), orgID, req)
+	if err != nil {
+		switch err.Error() {
+		case "execution log not found", "trigger not found", "workflow not found":
+			utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		default:
+			utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, replay)
+}
+
 // GetScheduledJobs returns pending scheduled jobs
 func (h *WorkflowHandler) GetScheduledJobs(w http.ResponseWriter, r *http.Request) {
 	orgID, ok := middleware.GetOrganizationID(r.Context())
@@ -792,44 +1060,199 @@ func (h *WorkflowHandler) GetScheduledJobs(w http.ResponseWriter, r *http.Reques
 	})
 }
 
-// ============ Default Workflow Handlers ============
+// ScheduledJobActionRequest is the reason given when changing a scheduled job by hand
+type ScheduledJobActionRequest struct {
+	Reason string `json:"reason"`
+}
 
-// InitDefaultWorkflows creates the default workflows for the organization
-func (h *WorkflowHandler) InitDefaultWorkflows(w http.ResponseWriter, r *http.Request) {
+// RescheduleJobRequest moves a scheduled job to a new time
+type RescheduleJobRequest struct {
+	ScheduledFor time.Time `json:"scheduled_for" validate:"required"`
+	Reason       string    `json:"reason"`
+}
+
+// CancelScheduledJob cancels a pending scheduled job
+func (h *WorkflowHandler) CancelScheduledJob(w http.ResponseWriter, r *http.Request) {
+	var req ScheduledJobActionRequest
+	if r.ContentLength > 0 {
+		if err := utils.ParseJSON(r, &req); err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	h.changeScheduledJob(w, r, req.Reason, "Scheduled job cancelled successfully",
+		func(orgID, jobID uuid.UUID, change services.ScheduledJobChange) (*models.ScheduledJob, error) {
+			return h.service.CancelScheduledJob(r.Context(), orgID, jobID, change)
+		})
+}
+
+// RunScheduledJob makes a pending job due now, or retries a failed one
+func (h *WorkflowHandler) RunScheduledJob(w http.ResponseWriter, r *http.Request) {
+	var req ScheduledJobActionRequest
+	if r.ContentLength > 0 {
+		if err := utils.ParseJSON(r, &req); err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	h.changeScheduledJob(w, r, req.Reason, "Scheduled job queued to run",
+		func(orgID, jobID uuid.UUID, change services.ScheduledJobChange) (*models.ScheduledJob, error) {
+			return h.service.RunScheduledJobNow(r.Context(), orgID, jobID, change)
+		})
+}
+
+// RescheduleScheduledJob moves a pending or failed job to a new time
+func (h *WorkflowHandler) RescheduleScheduledJob(w http.ResponseWriter, r *http.Request) {
+	var req RescheduleJobRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.ScheduledFor.IsZero() {
+		utils.ErrorResponse(w, http.StatusBadRequest, "scheduled_for is required")
+		return
+	}
+
+	h.changeScheduledJob(w, r, req.Reason, "Scheduled job rescheduled successfully",
+		func(orgID, jobID uuid.UUID, change services.ScheduledJobChange) (*models.ScheduledJob, error) {
+			return h.service.RescheduleScheduledJob(r.Context(), orgID, jobID, req.ScheduledFor, change)
+		})
+}
+
+// changeScheduledJob resolves the organization, user and job of a scheduled job change and
+// responds with the updated job
+func (h *WorkflowHandler) changeScheduledJob(w http.ResponseWriter, r *http.Request, reason, message string,
+	apply func(orgID, jobID uuid.UUID, change services.ScheduledJobChange) (*models.ScheduledJob, error)) {
 	orgID, ok := middleware.GetOrganizationID(r.Context())
 	if !ok {
 		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
 		return
 	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
 
-	module := r.URL.Query().Get("module")
-
-	var workflows []*models.Workflow
+	jobID, err := uuid.Parse(chi.URLParam(r, "jobId"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
 
-	// Create default workflows based on module
-	switch module {
-	case "construction":
-		// Create budget workflow
-		budgetWorkflow, err := h.service.CreateDefaultBudgetWorkflow(r.Context(), orgID)
-		if err != nil {
-			utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to create budget workflow: "+err.Error())
+	job, err := apply(orgID, jobID, services.ScheduledJobChange{UserID: userID, Reason: reason})
+	if err != nil {
+		if err.Error() == "scheduled job not found" {
+			utils.ErrorResponse(w, http.StatusNotFound, err.Error())
 			return
 		}
-		if budgetWorkflow != nil {
-			workflows = append(workflows, budgetWorkflow)
-		}
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-		// Create project workflow
-		projectWorkflow, err := h.service.CreateDefaultProjectWorkflow(r.Context(), orgID)
+	utils.SuccessMessageResponse(w, http.StatusOK, message, job)
+}
+
+// ListTriggerRuns returns bulk trigger runs, optionally filtered by ?trigger_id=
+func (h *WorkflowHandler) ListTriggerRuns(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	var triggerID *uuid.UUID
+	if t := r.URL.Query().Get("trigger_id"); t != "" {
+		id, err := uuid.Parse(t)
 		if err != nil {
-			utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to create project workflow: "+err.Error())
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid trigger ID")
 			return
 		}
-		if projectWorkflow != nil {
-			workflows = append(workflows, projectWorkflow)
+		triggerID = &id
+	}
+
+	limit := 50
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil {
+			limit = l
 		}
+	}
 
-		// Create default templates for construction
+	runs, err := h.service.ListTriggerRuns(r.Context(), orgID, triggerID, limit)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": runs,
+		"total": len(runs),
+	})
+}
+
+// GetTriggerRun returns the progress and failures of a bulk trigger run
+func (h *WorkflowHandler) GetTriggerRun(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid trigger run ID")
+		return
+	}
+
+	run, err := h.service.GetTriggerRun(r.Context(), id, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, run)
+}
+
+// ============ Default Workflow Handlers ============
+
+// InitDefaultWorkflows creates the default workflows for the organization
+func (h *WorkflowHandler) InitDefaultWorkflows(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	module := r.URL.Query().Get("module")
+
+	var workflows []*models.Workflow
+
+	// Create default workflows based on module
+	switch module {
+	case "construction":
+		// Create budget workflow
+		budgetWorkflow, err := h.service.CreateDefaultBudgetWorkflow(r.Context(), orgID)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to create budget workflow: "+err.Error())
+			return
+		}
+		if budgetWorkflow != nil {
+			workflows = append(workflows, budgetWorkflow)
+		}
+
+		// Create project workflow
+		projectWorkflow, err := h.service.CreateDefaultProjectWorkflow(r.Context(), orgID)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to create project workflow: "+err.Error())
+			return
+		}
+		if projectWorkflow != nil {
+			workflows = append(workflows, projectWorkflow)
+		}
+
+		// Create default templates for construction
 		if err := h.service.CreateDefaultTemplates(r.Context(), orgID, "construction"); err != nil {
 			utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to create default templates: "+err.Error())
 			return
@@ -840,6 +1263,15 @@ func (h *WorkflowHandler) InitDefaultWorkflows(w http.ResponseWriter, r *http.Re
 			utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to create default templates: "+err.Error())
 			return
 		}
+	case "invoices":
+		invoiceWorkflow, err := h.service.CreateDefaultInvoiceWorkflow(r.Context(), orgID)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to create invoice workflow: "+err.Error())
+			return
+		}
+		if invoiceWorkflow != nil {
+			workflows = append(workflows, invoiceWorkflow)
+		}
 	case "":
 		// Create all default workflows
 		budgetWorkflow, err := h.service.CreateDefaultBudgetWorkflow(r.Context(), orgID)
@@ -860,6 +1292,15 @@ func (h *WorkflowHandler) InitDefaultWorkflows(w http.ResponseWriter, r *http.Re
 			workflows = append(workflows, projectWorkflow)
 		}
 
+		invoiceWorkflow, err := h.service.CreateDefaultInvoiceWorkflow(r.Context(), orgID)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to create invoice workflow: "+err.Error())
+			return
+		}
+		if invoiceWorkflow != nil {
+			workflows = append(workflows, invoiceWorkflow)
+		}
+
 		// Create default templates for all modules
 		if err := h.service.CreateDefaultTemplates(r.Context(), orgID, "construction"); err != nil {
 			utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to create default templates: "+err.Error())
@@ -870,7 +1311,7 @@ func (h *WorkflowHandler) InitDefaultWorkflows(w http.ResponseWriter, r *http.Re
 			return
 		}
 	default:
-		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid module. Use 'construction', 'appointments', or leave empty for all")
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid module. Use 'construction', 'appointments', 'invoices', or leave empty for all")
 		return
 	}
 
@@ -912,6 +1353,82 @@ func (h *WorkflowHandler) TestTrigger(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// SimulateWorkflow dry-runs a hypothetical entity through a sequence of states
+func (h *WorkflowHandler) SimulateWorkflow(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	workflowID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid workflow ID")
+		return
+	}
+
+	var req services.WorkflowSimulationRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	result, err := h.service.SimulateWorkflow(r.Context(), orgID, workflowID, req)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, result)
+}
+
+// GetWorkflowAnalytics returns a workflow's funnel, trigger, action and delivery figures.
+// Query params: from, to (YYYY-MM-DD, inclusive); defaults to the last 30 days.
+func (h *WorkflowHandler) GetWorkflowAnalytics(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	workflowID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid workflow ID")
+		return
+	}
+
+	now := time.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	from := to.AddDate(0, 0, -30)
+	if raw := r.URL.Query().Get("from"); raw != "" {
+		from, err = time.Parse("2006-01-02", raw)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid start date format")
+			return
+		}
+	}
+	if raw := r.URL.Query().Get("to"); raw != "" {
+		parsed, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid end date format")
+			return
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+
+	analytics, err := h.service.GetWorkflowAnalytics(r.Context(), orgID, workflowID, from, to)
+	if err != nil {
+		if err.Error() == "workflow not found" {
+			utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, analytics)
+}
+
 // GetAvailableVariables returns available template variables for an entity type
 func (h *WorkflowHandler) GetAvailableVariables(w http.ResponseWriter, r *http.Request) {
 	entityType := r.URL.Query().Get("entity_type")
@@ -943,6 +1460,178 @@ func (h *WorkflowHandler) GetAvailableVariables(w http.ResponseWriter, r *http.R
 	})
 }
 
+// ============ Status Consistency Handlers ============
+
+// GetStatusConsistency returns entity statuses that do not match the default workflow states
+func (h *WorkflowHandler) GetStatusConsistency(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	includeResolved := r.URL.Query().Get("include_resolved") == "true"
+	mismatches, err := h.service.ListStatusMismatches(r.Context(), orgID, includeResolved)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": mismatches,
+		"total": len(mismatches),
+	})
+}
+
+// CheckStatusConsistency runs the consistency check immediately
+func (h *WorkflowHandler) CheckStatusConsistency(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	mismatches, err := h.service.CheckStatusConsistency(r.Context(), orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": mismatches,
+		"total": len(mismatches),
+	})
+}
+
+type StatusRemapRequest struct {
+	EntityType string    `json:"entity_type"`
+	FromStatus string    `json:"from_status"`
+	ToStateID  uuid.UUID `json:"to_state_id"`
+	AutoApply  bool      `json:"auto_apply"`
+}
+
+// ListStatusRemaps returns the configured status remaps
+func (h *WorkflowHandler) ListStatusRemaps(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	remaps, err := h.service.ListStatusRemaps(r.Context(), orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": remaps,
+		"total": len(remaps),
+	})
+}
+
+// SaveStatusRemap creates or replaces the remap of an old status (admin only)
+func (h *WorkflowHandler) SaveStatusRemap(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can manage status remaps")
+		return
+	}
+
+	var req StatusRemapRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	remap := &models.WorkflowStatusRemap{
+		OrganizationID: orgID,
+		EntityType:     models.WorkflowEntityType(req.EntityType),
+		FromStatus:     req.FromStatus,
+		ToStateID:      req.ToStateID,
+		AutoApply:      req.AutoApply,
+		CreatedBy:      &userID,
+	}
+
+	if err := h.service.SaveStatusRemap(r.Context(), remap); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Status remap saved successfully", remap)
+}
+
+// DeleteStatusRemap deletes a status remap (admin only)
+func (h *WorkflowHandler) DeleteStatusRemap(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can manage status remaps")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid remap ID")
+		return
+	}
+
+	if err := h.service.DeleteStatusRemap(r.Context(), id, orgID); err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Status remap deleted successfully", nil)
+}
+
+// ApplyStatusRemap moves entities with the old status to the mapped state (admin only)
+func (h *WorkflowHandler) ApplyStatusRemap(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can manage status remaps")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid remap ID")
+		return
+	}
+
+	updated, err := h.service.ApplyStatusRemap(r.Context(), id, orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Status remap applied successfully", map[string]interface{}{
+		"updated": updated,
+	})
+}
+
 // getVariableDescription returns a human-readable description for a variable
 func getVariableDescription(varName string) string {
 	descriptions := map[string]string{
@@ -954,6 +1643,8 @@ func getVariableDescription(varName string) string {
 		"session_time":       "Hora da sessão (HH:MM)",
 		"session_type":       "Tipo de sessão",
 		"amount":             "Valor da sessão/pagamento",
+		"cancellation_fee":   "Taxa de cancelamento",
+		"cancellation_policy": "Resultado da política de cancelamento",
 		"client_name":        "Nome do cliente",
 		"client_email":       "Email do cliente",
 		"client_phone":       "Telefone do cliente",
@@ -964,6 +1655,14 @@ func getVariableDescription(varName string) string {
 		"budget_total":       "Valor total do orçamento",
 		"budget_link":        "Link para visualizar orçamento",
 		"approval_link":      "Link para aprovar orçamento",
+		"payment_link":       "Link para pagamento online",
+		"reschedule_link":    "Link para remarcar a sessão",
+		"offer_link":         "Link para aceitar a vaga da lista de espera",
+		"offer_expires_at":   "Validade da vaga oferecida",
+		"mb_entity":          "Entidade Multibanco",
+		"mb_reference":       "Referência Multibanco",
+		"sepa_reference":     "Referência RF para transferência",
+		"iban":               "IBAN para transferências",
 		"organization_name":  "Nome da organização",
 		"organization_email": "Email da organização",
 	}
@@ -972,3 +1671,108 @@ func getVariableDescription(varName string) string {
 	}
 	return varName
 }
+
+// ============ Organization Holiday Handlers ============
+
+type HolidayRequest struct {
+	Date string `json:"date"` // YYYY-MM-DD
+	Name string `json:"name"`
+}
+
+// ListHolidays returns the organization's holidays, optionally filtered by ?year=
+func (h *WorkflowHandler) ListHolidays(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	year := 0
+	if y := r.URL.Query().Get("year"); y != "" {
+		parsed, err := strconv.Atoi(y)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid year")
+			return
+		}
+		year = parsed
+	}
+
+	holidays, err := h.service.ListHolidays(r.Context(), orgID, year)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"items": holidays,
+		"total": len(holidays),
+	})
+}
+
+// CreateHoliday adds an organization holiday (admin only)
+func (h *WorkflowHandler) CreateHoliday(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can manage holidays")
+		return
+	}
+
+	var req HolidayRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid date format, expected YYYY-MM-DD")
+		return
+	}
+
+	holiday := &models.OrganizationHoliday{
+		OrganizationID: orgID,
+		Date:           date,
+		Name:           req.Name,
+	}
+
+	if err := h.service.CreateHoliday(r.Context(), holiday); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusCreated, "Holiday created successfully", holiday)
+}
+
+// DeleteHoliday removes an organization holiday (admin only)
+func (h *WorkflowHandler) DeleteHoliday(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationID(r.Context())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Organization not found")
+		return
+	}
+
+	role, ok := middleware.GetUserRole(r.Context())
+	if !ok || role != string(models.RoleAdmin) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only administrators can manage holidays")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid holiday ID")
+		return
+	}
+
+	if err := h.service.DeleteHoliday(r.Context(), id, orgID); err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessMessageResponse(w, http.StatusOK, "Holiday deleted successfully", nil)
+}
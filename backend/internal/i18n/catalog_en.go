@@ -0,0 +1,98 @@
+package i18n
+
+// catalogEN holds the English messages that are not their own ID
+var catalogEN = map[string]string{
+	// ============ Default Workflows ============
+	"workflow.budget.approval_request.message": "Budget {{budget_number}} ({{budget_total}} €) for {{client_name}} is awaiting your approval before being sent.",
+	"workflow.budget.follow_up_3.body":         "Hello {{client_name}},\n\nWe recently sent you budget {{budget_number}} for the project \"{{project_name}}\". Have you had a chance to review it?\n\nWe are available for any questions.\n\nBest regards",
+	"workflow.budget.follow_up_7.body":         "Hello {{client_name}},\n\nWe would like to know whether budget {{budget_number}} ({{budget_total}}€) meets your needs.\n\nIf you prefer, we can adjust the proposal.\n\nBest regards",
+	"workflow.budget.follow_up_14.body":        "Hello {{client_name}},\n\nThis is the last reminder about budget {{budget_number}}. If we do not hear back, the budget will expire on its validity date.\n\nBest regards",
+
+	// ============ Default Templates ============
+	"template.budget_sent.body": `Dear {{client_name}},
+
+Please find attached budget {{budget_number}} for the project "{{project_name}}".
+
+Total: {{budget_total}}€
+
+To view or approve the budget, open the following link:
+{{budget_link}}
+
+We are available for any questions.
+
+Best regards,
+{{organization_name}}`,
+	"template.budget_approved.body": `Budget {{budget_number}} for client {{client_name}} has been approved!
+
+Project: {{project_name}}
+Total: {{budget_total}}€
+
+The project can now be started.
+
+{{organization_name}}`,
+	"template.budget_rejected.body": `Budget {{budget_number}} for client {{client_name}} has been rejected.
+
+Project: {{project_name}}
+Total: {{budget_total}}€
+
+The budget may need to be revised and sent to the client again.
+
+{{organization_name}}`,
+	"template.budget_reminder.body": "Hello {{client_name}}! We sent you budget {{budget_number}} ({{budget_total}}€). Have you had a chance to look at it? We are available for any questions.",
+	"template.project_completed.body": `Dear {{client_name}},
+
+We are pleased to let you know that the project "{{project_name}}" has been successfully completed!
+
+Thank you for your trust. We are available for future projects.
+
+Best regards,
+{{organization_name}}`,
+	"template.reminder_24h.body": `Hello {{patient_name}}! 👋
+
+This is a reminder that you have an appointment tomorrow:
+
+📅 Date: {{session_date}}
+🕐 Time: {{session_time}}
+👤 Therapist: {{therapist_name}}
+
+Please confirm your attendance by replying to this message.
+
+{{organization_name}}`,
+	"template.reminder_2h.body": `Hello {{patient_name}}! 👋
+
+Your appointment is in 2 hours:
+
+🕐 {{session_time}}
+👤 {{therapist_name}}
+
+See you soon!
+
+{{organization_name}}`,
+	"template.session_confirmed.body": `Hello {{patient_name}}! ✅
+
+Your appointment is confirmed:
+
+📅 Date: {{session_date}}
+🕐 Time: {{session_time}}
+👤 Therapist: {{therapist_name}}
+
+See you soon!
+{{organization_name}}`,
+	"template.payment_reminder.body": `Hello {{patient_name}}! 👋
+
+This is a reminder that you have sessions pending payment totalling {{amount}}€.
+
+Please settle the payment at your next appointment or contact us for more information.
+
+Thank you,
+{{organization_name}}`,
+	"template.session_cancelled.body": `Hello {{patient_name}},
+
+Your appointment on {{session_date}} at {{session_time}} has been cancelled.
+
+{{cancellation_policy}}
+
+To reschedule, please contact us.
+
+{{organization_name}}`,
+}
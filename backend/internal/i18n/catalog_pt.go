@@ -0,0 +1,1033 @@
+package i18n
+
+// catalogPT holds the Portuguese messages
+var catalogPT = map[string]string{
+	// ============ Common API Messages ============
+	"Organization not found":                               "Organização não encontrada",
+	"Organization not found in context":                    "Organização não encontrada no contexto",
+	"Organization not found in token":                      "Organização não encontrada no token",
+	"Organization ID not found in context":                 "ID da organização não encontrado no contexto",
+	"Organization ID required":                             "ID da organização obrigatório",
+	"Organization is not active":                           "A organização não está ativa",
+	"User not found":                                       "Utilizador não encontrado",
+	"User not found in context":                            "Utilizador não encontrado no contexto",
+	"Admin not found":                                      "Administrador não encontrado",
+	"Admin not found in context":                           "Administrador não encontrado no contexto",
+	"Invalid request body":                                 "Corpo do pedido inválido",
+	"Invalid form data":                                    "Dados do formulário inválidos",
+	"Missing required fields":                              "Campos obrigatórios em falta",
+	"Missing authorization header":                         "Cabeçalho de autorização em falta",
+	"Invalid authorization header format":                  "Formato do cabeçalho de autorização inválido",
+	"Invalid or expired token":                             "Token inválido ou expirado",
+	"Invalid token claims":                                 "Dados do token inválidos",
+	"Invalid user ID in token":                             "ID de utilizador inválido no token",
+	"Invalid organization ID in token":                     "ID da organização inválido no token",
+	"Invalid admin ID in token":                            "ID de administrador inválido no token",
+	"System administrator access required":                 "Acesso de administrador de sistema necessário",
+	"No active impersonation session":                      "Nenhuma sessão de personificação ativa",
+	"This action cannot be performed during impersonation": "Esta ação não pode ser realizada durante a personificação",
+	"Module not enabled for this organization":             "Módulo não ativo para esta organização",
+	"None of the required modules are enabled":             "Nenhum dos módulos necessários está ativo",
+	"Required module not enabled":                          "Módulo necessário não ativo",
+	"Module name is required":                              "O nome do módulo é obrigatório",
+	"File is required":                                     "O ficheiro é obrigatório",
+	"File is too large":                                    "O ficheiro é demasiado grande",
+	"Failed to read file":                                  "Falha ao ler o ficheiro",
+	"Invalid upload or file too large":                     "Envio inválido ou ficheiro demasiado grande",
+	"A valid email is required":                            "É necessário um email válido",
+	"Template not found":                                   "Modelo não encontrado",
+	"Template channel does not match the test channel":     "O canal do modelo não corresponde ao canal de teste",
+	"Phone number is required":                             "O número de telefone é obrigatório",
+	"Invalid aging bucket":                                 "Intervalo de antiguidade inválido",
+	"Invalid dead letter ID":                               "ID de ação falhada inválido",
+	"Invalid expense ID":                                   "ID de despesa inválido",
+	"Invalid statement ID":                                 "ID de extrato inválido",
+	"Invalid statement line ID":                            "ID de movimento inválido",
+	"Invalid export ID":                                    "ID de exportação inválido",
+	"Invalid entry kind":                                   "Tipo de movimento inválido",
+	"Invalid cash register ID":                             "ID de caixa inválido",
+	"Webhook not found":                                    "Webhook não encontrado",
+	"client_id is required":                                "client_id é obrigatório",
+	"months must be between 1 and 24":                      "months tem de estar entre 1 e 24",
+	"Invalid JSON syntax":                                  "Sintaxe JSON inválida",
+	"Request body cannot be empty":                         "O corpo do pedido não pode estar vazio",
+	"Request body contains extra data":                     "O corpo do pedido contém dados a mais",
+	"Invalid input data":                                   "Dados de entrada inválidos",
+	"An internal error occurred":                           "Ocorreu um erro interno",
+
+	// ============ Invalid Identifiers and Formats ============
+	"Invalid action ID":                         "ID de ação inválido",
+	"Invalid budget ID":                         "ID de orçamento inválido",
+	"Invalid client ID":                         "ID de cliente inválido",
+	"Invalid debtor ID":                         "ID de devedor inválido",
+	"Invalid client_id format":                  "Formato de client_id inválido",
+	"Invalid compliance item ID":                "ID de item de conformidade inválido",
+	"Invalid delegate user ID":                  "ID do utilizador delegado inválido",
+	"Invalid entity_id":                         "entity_id inválido",
+	"Invalid holiday ID":                        "ID de feriado inválido",
+	"Invalid invitation ID":                     "ID de convite inválido",
+	"Invalid import ID":                         "ID de importação inválido",
+	"Invalid organization ID":                   "ID da organização inválido",
+	"Invalid last event ID":                     "ID do último evento inválido",
+	"Invalid out-of-office ID":                  "ID de ausência inválido",
+	"Invalid patient ID":                        "ID de paciente inválido",
+	"Invalid payment ID":                        "ID de pagamento inválido",
+	"Invalid project ID":                        "ID de projeto inválido",
+	"Invalid remap ID":                          "ID de remapeamento inválido",
+	"Invalid requirement ID":                    "ID de requisito inválido",
+	"Invalid rule ID":                           "ID de regra inválido",
+	"Invalid service ID":                        "ID de serviço inválido",
+	"Invalid session ID":                        "ID de sessão inválido",
+	"Invalid series ID":                         "ID de série inválido",
+	"Invalid inbox item ID":                     "ID de item da caixa de entrada inválido",
+	"Invalid inbox item type":                   "Tipo de item da caixa de entrada inválido",
+	"Invalid snooze time format":                "Formato de hora de adiamento inválido",
+	"Invalid follow-up ID":                      "ID de lembrete inválido",
+	"Invalid entity type":                       "Tipo de entidade inválido",
+	"Invalid entity ID":                         "ID de entidade inválido",
+	"Invalid remind time format":                "Formato de hora do lembrete inválido",
+	"Invalid until date format, use YYYY-MM-DD": "Formato da data final inválido, use AAAA-MM-DD",
+	"Invalid state ID":                          "ID de estado inválido",
+	"Invalid state ID in list":                  "ID de estado inválido na lista",
+	"Invalid task ID":                           "ID de tarefa inválido",
+	"Invalid template ID":                       "ID de modelo inválido",
+	"Invalid therapist ID":                      "ID de terapeuta inválido",
+	"Invalid transition ID":                     "ID de transição inválido",
+	"Invalid trigger ID":                        "ID de gatilho inválido",
+	"Invalid trigger run ID":                    "ID de execução de gatilho inválido",
+	"Invalid user ID":                           "ID de utilizador inválido",
+	"Invalid workflow ID":                       "ID de workflow inválido",
+	"Invalid workflow_id":                       "workflow_id inválido",
+	"Invalid date format, expected YYYY-MM-DD":  "Formato de data inválido, use AAAA-MM-DD",
+	"Invalid date of birth format":              "Formato da data de nascimento inválido",
+	"Invalid due date format":                   "Formato da data limite inválido",
+	"Invalid start date format":                 "Formato da data de início inválido",
+	"Invalid start date format. Use YYYY-MM-DD": "Formato da data de início inválido. Use AAAA-MM-DD",
+	"Invalid end date format":                   "Formato da data de fim inválido",
+	"Invalid from date format. Use YYYY-MM-DD":  "Formato da data inicial inválido. Use AAAA-MM-DD",
+	"Invalid to date format. Use YYYY-MM-DD":    "Formato da data final inválido. Use AAAA-MM-DD",
+	"Invalid scheduled time format":             "Formato da hora agendada inválido",
+	"Invalid working hours format":              "Formato do horário de trabalho inválido",
+	"Invalid period, use YYYY-MM":               "Período inválido, use AAAA-MM",
+	"Invalid duration":                          "Duração inválida",
+	"Invalid threshold":                         "Limite inválido",
+	"Invalid year":                              "Ano inválido",
+	"Invalid value bands":                       "Escalões de valor inválidos",
+	"Value bands must be in ascending order":    "Os escalões de valor têm de estar por ordem crescente",
+	"Invalid import options":                    "Opções de importação inválidas",
+	"Invalid module. Use 'construction', 'appointments', 'invoices', or leave empty for all": "Módulo inválido. Use 'construction', 'appointments', 'invoices' ou deixe vazio para todos",
+	"Invalid sandbox ID":                            "ID de sandbox inválido",
+	"Invalid archive ID":                            "ID de arquivo inválido",
+	"Invalid job ID":                                "ID de tarefa inválido",
+	"Invalid merge ID":                              "ID de fusão inválido",
+	"Invalid invoice ID":                            "ID de fatura inválido",
+	"Invalid target ID":                             "ID de destino inválido",
+	"Invalid payment link ID":                       "ID de link de pagamento inválido",
+	"Invalid security alert ID":                     "ID de alerta de segurança inválido",
+	"Invalid payment reference ID":                  "ID de referência de pagamento inválido",
+	"invalid payment reference provider":            "fornecedor de referências de pagamento inválido",
+	"invalid Multibanco entity":                     "entidade Multibanco inválida",
+	"invalid Multibanco sub-entity":                 "subentidade Multibanco inválida",
+	"invalid IBAN":                                  "IBAN inválido",
+	"invalid payment reference target":              "destino da referência de pagamento inválido",
+	"Invalid callback key":                          "Chave de callback inválida",
+	"Invalid deletion request ID":                   "ID de pedido de eliminação inválido",
+	"Patient not found":                             "Paciente não encontrado",
+	"Invalid patient ID in token":                   "ID do paciente inválido no token",
+	"Invalid chat webhook ID":                       "ID de webhook de chat inválido",
+	"invalid webhook URL":                           "URL de webhook inválido",
+	"Slack webhook URLs must be on hooks.slack.com": "Os URLs de webhook do Slack têm de ser de hooks.slack.com",
+	"Teams webhook URLs must be Teams incoming webhook or workflow URLs": "Os URLs de webhook do Teams têm de ser de webhooks de entrada ou de fluxos de trabalho do Teams",
+	"provider must be slack or teams":                                    "O fornecedor tem de ser slack ou teams",
+	"a new webhook URL is required to change the provider":               "É necessário um novo URL de webhook para mudar de fornecedor",
+	"Invalid waiting list entry ID":                                      "ID de entrada da lista de espera inválido",
+	"preferred weekdays must be between 0 (Sunday) and 6 (Saturday)":     "Os dias preferidos têm de estar entre 0 (domingo) e 6 (sábado)",
+	"preferred times must be in HH:MM format":                            "As horas preferidas têm de estar no formato HH:MM",
+	"earliest time must be before latest time":                           "A hora mais cedo tem de ser anterior à hora mais tarde",
+	"Invalid budget item ID":                                             "ID de item do orçamento inválido",
+	"budget item not found":                                              "Item do orçamento não encontrado",
+	"comment is required":                                                "O comentário é obrigatório",
+	"message is required":                                                "A mensagem é obrigatória",
+	"message must have at most 4096 characters":                          "a mensagem deve ter no máximo 4096 caracteres",
+	"Invalid message ID":                                                 "ID de mensagem inválido",
+	"invalid WhatsApp provider":                                          "Fornecedor de WhatsApp inválido",
+	"media URL must be a public https URL":                               "O URL do ficheiro tem de ser um URL https público",
+	"Invalid verify token":                                               "Token de verificação inválido",
+	"Invalid crew member ID":                                             "ID de membro da equipa inválido",
+	"Invalid assignment ID":                                              "ID de alocação inválido",
+	"invalid crew member kind":                                           "Tipo de membro da equipa inválido",
+	"invalid inbound intent":                                             "intenção de mensagem recebida inválida",
+	"an inbound intent can have at most 50 keywords":                     "uma intenção de mensagem recebida pode ter no máximo 50 palavras-chave",
+	"inbound intent reply must have at most 4096 characters":             "a resposta de uma intenção de mensagem recebida pode ter no máximo 4096 caracteres",
+	"Invalid treatment plan ID":                                          "ID de plano de tratamento inválido",
+	"Invalid goal ID":                                                    "ID de objetivo inválido",
+	"invalid treatment plan status":                                      "estado de plano de tratamento inválido",
+	"invalid session outcome":                                            "resultado de sessão inválido",
+	"progress rating must be between 0 and 10":                           "a avaliação de progresso deve estar entre 0 e 10",
+	"planned sessions must be greater than zero":                         "o número de sessões previstas deve ser maior que zero",
+	"review date must not be before the start date":                      "a data de revisão não pode ser anterior à data de início",
+	"a treatment plan can have at most 20 goals":                         "um plano de tratamento pode ter no máximo 20 objetivos",
+	"goal description is required":                                       "a descrição do objetivo é obrigatória",
+	"treatment plan title is required":                                   "o título do plano de tratamento é obrigatório",
+	"Invalid consent ID":                                                 "ID de consentimento inválido",
+	"invalid consent channel":                                            "Canal de consentimento inválido",
+	"invalid consent status":                                             "Estado de consentimento inválido",
+	"invalid consent source":                                             "Origem de consentimento inválida",
+	"address is required":                                                "O endereço é obrigatório",
+	"invalid email address":                                              "Endereço de email inválido",
+	"invalid integrity category":                                         "Categoria de integridade inválida",
+	"send window start must be a time like 09:00":                        "o início da janela de envio tem de ser uma hora como 09:00",
+	"send window end must be a time like 20:00":                          "o fim da janela de envio tem de ser uma hora como 20:00",
+	"send window must start and end at different times":                  "a janela de envio tem de começar e terminar a horas diferentes",
+	"send window days must be between 1 (Monday) and 7 (Sunday)":         "os dias da janela de envio têm de estar entre 1 (segunda-feira) e 7 (domingo)",
+	"Invalid timezone":                                                   "Fuso horário inválido",
+	"Invalid email suppression ID":                                       "ID de supressão de email inválido",
+
+	// ============ Permissions ============
+	"Only administrators and managers can change out-of-office for other users":   "Apenas administradores e gestores podem alterar ausências de outros utilizadores",
+	"Only administrators and managers can remove out-of-office for other users":   "Apenas administradores e gestores podem remover ausências de outros utilizadores",
+	"Only administrators and managers can set out-of-office for other users":      "Apenas administradores e gestores podem definir ausências de outros utilizadores",
+	"Only administrators and managers can waive compliance items":                 "Apenas administradores e gestores podem dispensar itens de conformidade",
+	"Only administrators and owners can update organization settings":             "Apenas administradores e proprietários podem atualizar as definições da organização",
+	"Only administrators and managers can view data usage":                        "Apenas administradores e gestores podem ver a utilização de dados",
+	"Only administrators can disable modules":                                     "Apenas administradores podem desativar módulos",
+	"Only administrators can enable modules":                                      "Apenas administradores podem ativar módulos",
+	"Only administrators can manage approval rules":                               "Apenas administradores podem gerir regras de aprovação",
+	"Only administrators can manage holidays":                                     "Apenas administradores podem gerir feriados",
+	"Only administrators can manage status remaps":                                "Apenas administradores podem gerir remapeamentos de estado",
+	"Only administrators can view module updates":                                 "Apenas administradores podem ver as novidades dos módulos",
+	"Only administrators can check integrations":                                  "Apenas administradores podem verificar as integrações",
+	"Only administrators can update module configuration":                         "Apenas administradores podem atualizar a configuração dos módulos",
+	"Only administrators can update notification settings":                        "Apenas administradores podem atualizar as definições de notificações",
+	"Only administrators can manage failed actions":                               "Apenas administradores podem gerir ações falhadas",
+	"Only administrators, managers and accountants can reconcile bank statements": "Apenas administradores, gestores e contabilistas podem reconciliar extratos bancários",
+	"Only administrators and accountants can access the accountant workspace":     "Apenas administradores e contabilistas podem aceder à área do contabilista",
+	"Only administrators and accountants can close financial periods":             "Apenas administradores e contabilistas podem fechar períodos financeiros",
+	"Only administrators and accountants can reopen financial periods":            "Apenas administradores e contabilistas podem reabrir períodos financeiros",
+	"Only administrators can manage users":                                        "Apenas administradores podem gerir utilizadores",
+	"Only admins and managers can decide conflict overrides":                      "Apenas administradores e gestores podem decidir exceções de conflito",
+	"Only admins and managers can waive cancellation fees":                        "Apenas administradores e gestores podem dispensar taxas de cancelamento",
+	"Only admins can manage the cancellation policy":                              "Apenas administradores podem gerir a política de cancelamento",
+	"Only admins can manage the service catalogue":                                "Apenas administradores podem gerir o catálogo de serviços",
+	"Only administrators can manage sandboxes":                                    "Apenas administradores podem gerir sandboxes",
+	"Only administrators can update payment settings":                             "Apenas administradores podem atualizar as definições de pagamento",
+	"Justify your open security alerts before performing this action":             "Justifique os seus alertas de segurança pendentes antes de realizar esta ação",
+	"only the flagged admin can justify this alert":                               "Apenas o administrador assinalado pode justificar este alerta",
+	"admins cannot review their own alerts":                                       "Os administradores não podem rever os seus próprios alertas",
+	"Only administrators can delete the organization":                             "Apenas administradores podem eliminar a organização",
+	"Only administrators can cancel the organization's deletion":                  "Apenas administradores podem cancelar a eliminação da organização",
+	"Only administrators can update patient portal settings":                      "Apenas administradores podem atualizar as definições do portal do paciente",
+	"patient portal is not enabled":                                               "o portal do paciente não está ativo",
+	"sessions cannot be confirmed in the patient portal":                          "as sessões não podem ser confirmadas no portal do paciente",
+	"sessions cannot be cancelled in the patient portal":                          "as sessões não podem ser canceladas no portal do paciente",
+	"contact details cannot be changed in the patient portal":                     "os contactos não podem ser alterados no portal do paciente",
+	"Only administrators can manage chat webhooks":                                "Apenas administradores podem gerir webhooks de chat",
+	"Only administrators and managers can manage the crew":                        "Apenas administradores e gestores podem gerir a equipa",
+	"Only administrators can view notification settings":                          "Apenas administradores podem ver as definições de notificações",
+	"Only administrators can change benchmarking participation":                   "Apenas administradores podem alterar a participação no benchmarking",
+	"Only administrators and managers can view benchmarks":                        "Apenas administradores e gestores podem ver o benchmarking",
+	"organization is not taking part in benchmarking":                             "A organização não participa no benchmarking",
+	"Only administrators can delete consent records":                              "Apenas administradores podem eliminar registos de consentimento",
+	"Only administrators can remove email suppressions":                           "Apenas administradores podem remover supressões de email",
+
+	// ============ Failures ============
+	"logo must be a PNG, JPEG or GIF image":                                    "o logótipo tem de ser uma imagem PNG, JPEG ou GIF",
+	"logo dimensions are too large":                                            "as dimensões do logótipo são demasiado grandes",
+	"current password is incorrect":                                            "a palavra-passe atual está incorreta",
+	"a user with this email already exists":                                    "já existe um utilizador com este email",
+	"the organization must keep at least one active admin":                     "a organização tem de manter pelo menos um administrador ativo",
+	"invitation not found":                                                     "convite não encontrado",
+	"invitation not found or no longer open":                                   "convite não encontrado ou já não está em aberto",
+	"invitation is expired":                                                    "o convite expirou",
+	"invitation is revoked":                                                    "o convite foi revogado",
+	"invitation is accepted":                                                   "o convite já foi aceite",
+	"client not found":                                                         "cliente não encontrado",
+	"client name is required":                                                  "o nome do cliente é obrigatório",
+	"client email is required":                                                 "o email do cliente é obrigatório",
+	"client phone is required":                                                 "o telefone do cliente é obrigatório",
+	"client with this email already exists":                                    "já existe um cliente com este email",
+	"email already in use by another client":                                   "o email já está a ser usado por outro cliente",
+	"cannot delete client with existing worksheets":                            "não é possível eliminar um cliente com folhas de obra",
+	"task not found":                                                           "tarefa não encontrada",
+	"task title is required":                                                   "o título da tarefa é obrigatório",
+	"invalid task priority":                                                    "prioridade de tarefa inválida",
+	"invalid task status":                                                      "estado de tarefa inválido",
+	"project not found":                                                        "projeto não encontrado",
+	"budget not found":                                                         "orçamento não encontrado",
+	"session not found":                                                        "sessão não encontrada",
+	"patient not found":                                                        "paciente não encontrado",
+	"budget must be approved before creating a project":                        "o orçamento tem de estar aprovado antes de criar um projeto",
+	"budget already has a project":                                             "o orçamento já tem um projeto",
+	"project title is required":                                                "o título do projeto é obrigatório",
+	"project expected end date is required":                                    "a data prevista de conclusão do projeto é obrigatória",
+	"project start and expected end dates are required":                        "as datas de início e de conclusão prevista do projeto são obrigatórias",
+	"expected end date cannot be before the start date":                        "a data prevista de conclusão não pode ser anterior à data de início",
+	"progress must be between 0 and 100":                                       "o progresso tem de estar entre 0 e 100",
+	"cannot update progress of completed or cancelled projects":                "não é possível atualizar o progresso de projetos concluídos ou cancelados",
+	"cannot delete a project with paid payments":                               "não é possível eliminar um projeto com pagamentos pagos",
+	"milestone not found":                                                      "marco não encontrado",
+	"assignee not found":                                                       "responsável não encontrado",
+	"tasks can only be assigned to staff members":                              "as tarefas só podem ser atribuídas a membros da equipa",
+	"cannot assign completed or cancelled tasks":                               "não é possível atribuir tarefas concluídas ou canceladas",
+	"payment not found":                                                        "pagamento não encontrado",
+	"payment not found or not open":                                            "pagamento não encontrado ou já não está em aberto",
+	"payment amount must be greater than zero":                                 "o valor do pagamento tem de ser superior a zero",
+	"payment due date is required":                                             "a data de vencimento do pagamento é obrigatória",
+	"cannot add payments to a cancelled project":                               "não é possível adicionar pagamentos a um projeto cancelado",
+	"cannot modify paid or cancelled payments":                                 "não é possível alterar pagamentos pagos ou cancelados",
+	"cannot delete a paid payment":                                             "não é possível eliminar um pagamento pago",
+	"expense not found":                                                        "despesa não encontrada",
+	"storage is not configured":                                                "o armazenamento não está configurado",
+	"receipt must be a JPEG, PNG or WebP image or a PDF":                       "o recibo deve ser uma imagem JPEG, PNG ou WebP ou um PDF",
+	"only draft expenses can be edited":                                        "apenas despesas em rascunho podem ser editadas",
+	"expense is already posted":                                                "a despesa já foi lançada",
+	"vendor is required":                                                       "o fornecedor é obrigatório",
+	"expense date is required":                                                 "a data da despesa é obrigatória",
+	"project expenses need a project":                                          "as despesas de projeto precisam de um projeto",
+	"clinic expenses can't belong to a project":                                "as despesas da clínica não podem pertencer a um projeto",
+	"scope must be project or clinic":                                          "o âmbito deve ser projeto ou clínica",
+	"amount cannot be negative":                                                "o valor não pode ser negativo",
+	"VAT amount cannot be negative":                                            "o valor do IVA não pode ser negativo",
+	"VAT amount cannot exceed the amount":                                      "o valor do IVA não pode exceder o valor",
+	"VAT rate must be between 0 and 100":                                       "a taxa de IVA deve estar entre 0 e 100",
+	"statement file is empty or not a valid CSV":                               "o ficheiro do extrato está vazio ou não é um CSV válido",
+	"statement file has no date column":                                        "o ficheiro do extrato não tem coluna de data",
+	"statement file has no amount column":                                      "o ficheiro do extrato não tem coluna de montante",
+	"statement file has no transactions":                                       "o ficheiro do extrato não tem movimentos",
+	"statement file is not a valid CAMT.053 document":                          "o ficheiro do extrato não é um documento CAMT.053 válido",
+	"format must be csv or camt":                                               "o formato deve ser csv ou camt",
+	"bank aggregator is not configured":                                        "o agregador bancário não está configurado",
+	"account_id is required":                                                   "account_id é obrigatório",
+	"from must be before to":                                                   "a data inicial deve ser anterior à data final",
+	"no transactions in this period":                                           "não há movimentos neste período",
+	"all transactions in this statement were already imported":                 "todos os movimentos deste extrato já foram importados",
+	"statement not found":                                                      "extrato não encontrado",
+	"statement line not found":                                                 "movimento não encontrado",
+	"kind must be payment or session_payment":                                  "kind deve ser payment ou session_payment",
+	"statement line is already matched or ignored":                             "o movimento já está associado ou ignorado",
+	"only incoming transactions can be matched to a payment":                   "apenas movimentos a crédito podem ser associados a um pagamento",
+	"session payment not found or already paid":                                "pagamento da sessão não encontrado ou já pago",
+	"statement line is not matched":                                            "o movimento não está associado",
+	"statement line not found or already matched":                              "movimento não encontrado ou já associado",
+	"cannot export a future period":                                            "não é possível exportar um período futuro",
+	"an export of this period is already in progress":                          "já existe uma exportação deste período em curso",
+	"export bundle not found":                                                  "exportação não encontrada",
+	"export bundle is not ready":                                               "a exportação ainda não está pronta",
+	"dead letter not found":                                                    "ação falhada não encontrada",
+	"dead letter not found or already requeued":                                "ação falhada não encontrada ou já reenviada",
+	"dead letter not found or not failed":                                      "ação falhada não encontrada ou não está falhada",
+	"retry_max_attempts must be between 1 and 10":                              "retry_max_attempts deve estar entre 1 e 10",
+	"retry_backoff_seconds must be between 1 and 86400":                        "retry_backoff_seconds deve estar entre 1 e 86400",
+	"workflow name is required":                                                "o nome do workflow é obrigatório",
+	"a workflow with this name already exists":                                 "já existe um workflow com este nome",
+	"trigger references an unknown state":                                      "o gatilho refere um estado desconhecido",
+	"trigger references an unknown transition":                                 "o gatilho refere uma transição desconhecida",
+	"action references a template missing from the export":                     "a ação refere um modelo que não está na exportação",
+	"exported templates need a name and a body":                                "os modelos exportados precisam de nome e corpo",
+	"frequency must be weekly or biweekly":                                     "a frequência deve ser semanal ou quinzenal",
+	"either count or until is required":                                        "é obrigatório indicar count ou until",
+	"count must be between 1 and 104":                                          "count deve estar entre 1 e 104",
+	"invalid weekday":                                                          "dia da semana inválido",
+	"the first session must fall on one of the weekdays":                       "a primeira sessão tem de calhar num dos dias da semana indicados",
+	"until must not be before the first session":                               "until não pode ser anterior à primeira sessão",
+	"a series can have at most 104 sessions":                                   "uma série pode ter no máximo 104 sessões",
+	"therapist already has sessions at these times":                            "o terapeuta já tem sessões nestes horários",
+	"scheduling conflict: therapist already has a session at every occurrence": "conflito de horário: o terapeuta já tem uma sessão em todas as ocorrências",
+	"session series not found":                                                 "série de sessões não encontrada",
+	"series has no upcoming sessions":                                          "a série não tem sessões futuras",
+	"time_of_day must be in HH:MM format":                                      "time_of_day deve estar no formato HH:MM",
+	"storage quota must be positive":                                           "a quota de armazenamento deve ser positiva",
+	"inbox item not found":                                                     "item da caixa de entrada não encontrado",
+	"invalid inbox item type":                                                  "tipo de item da caixa de entrada inválido",
+	"snooze time must be in the future":                                        "a hora de adiamento deve ser no futuro",
+	"follow-up not found":                                                      "lembrete não encontrado",
+	"invalid entity type":                                                      "tipo de entidade inválido",
+	"follow-up not found or already closed":                                    "lembrete não encontrado ou já fechado",
+	"remind time must be in the future":                                        "a hora do lembrete deve ser no futuro",
+	"text is required":                                                         "o texto é obrigatório",
+	"quick-create text is incomplete, missing":                                 "o texto de criação rápida está incompleto, falta",
+	"invalid timezone":                                                         "fuso horário inválido",
+	"message quota must be positive":                                           "a quota de mensagens deve ser positiva",
+	"this change affects a closed financial period, reopen it first":           "esta alteração afeta um período financeiro fechado, reabra-o primeiro",
+	"invalid period, expected YYYY-MM":                                         "período inválido, esperado AAAA-MM",
+	"only finished months can be closed":                                       "só é possível fechar meses terminados",
+	"financial period is already closed":                                       "o período financeiro já está fechado",
+	"a reason is required to reopen a financial period":                        "é necessário um motivo para reabrir um período financeiro",
+	"financial period is not closed":                                           "o período financeiro não está fechado",
+	"cash register not found":                                                  "caixa não encontrada",
+	"no cash register is open":                                                 "não há nenhuma caixa aberta",
+	"a cash register is already open":                                          "já existe uma caixa aberta",
+	"cash register is closed":                                                  "a caixa está fechada",
+	"cash register not found or already closed":                                "caixa não encontrada ou já fechada",
+	"opening float cannot be negative":                                         "o fundo de caixa não pode ser negativo",
+	"counted amounts cannot be negative":                                       "os valores contados não podem ser negativos",
+	"method must be one of cash, card, mbway":                                  "o método tem de ser cash, card ou mbway",
+	"amount must be greater than zero":                                         "o valor tem de ser superior a zero",
+	"session payment not found":                                                "pagamento da sessão não encontrado",
+	"module not found":                                                         "módulo não encontrado",
+	"do-not-disturb must end in the future":                                    "o período de não incomodar tem de terminar no futuro",
+	"notification config not found":                                            "configuração de notificações não encontrada",
+	"this WhatsApp number is already used by another organization":             "este número de WhatsApp já é usado por outra organização",
+	"module not found or not enabled":                                          "módulo não encontrado ou não ativo",
+	"changelog version and title are required":                                 "a versão e o título da nota de versão são obrigatórios",
+	"SMTP is not configured":                                                   "O SMTP não está configurado",
+	"email is not configured":                                                  "O email não está configurado",
+	"invalid email provider":                                                   "Fornecedor de email inválido",
+	"invalid SMTP port":                                                        "Porta SMTP inválida",
+	"failed to send email":                                                     "Falha ao enviar email",
+	"Twilio credentials not configured":                                        "As credenciais do Twilio não estão configuradas",
+	"Twilio sender number not configured":                                      "O número de envio do Twilio não está configurado",
+	"Failed to check module status":                                            "Falha ao verificar o estado do módulo",
+	"Failed to create budget workflow":                                         "Falha ao criar o workflow de orçamentos",
+	"Failed to create default templates":                                       "Falha ao criar os modelos predefinidos",
+	"Failed to create organization":                                            "Falha ao criar a organização",
+	"Failed to create project workflow":                                        "Falha ao criar o workflow de projetos",
+	"Failed to delete organization":                                            "Falha ao eliminar a organização",
+	"Failed to end impersonation":                                              "Falha ao terminar a personificação",
+	"Failed to get created session":                                            "Falha ao obter a sessão criada",
+	"Failed to get organization":                                               "Falha ao obter a organização",
+	"Failed to get platform stats":                                             "Falha ao obter as estatísticas da plataforma",
+	"Failed to get recent activity":                                            "Falha ao obter a atividade recente",
+	"Failed to get updated client":                                             "Falha ao obter o cliente atualizado",
+	"Failed to get updated organization":                                       "Falha ao obter a organização atualizada",
+	"Failed to get updated patient":                                            "Falha ao obter o paciente atualizado",
+	"Failed to get updated session":                                            "Falha ao obter a sessão atualizada",
+	"Failed to get updated therapist":                                          "Falha ao obter o terapeuta atualizado",
+	"Failed to list audit logs":                                                "Falha ao listar os registos de auditoria",
+	"Failed to list modules":                                                   "Falha ao listar os módulos",
+	"Failed to list organizations":                                             "Falha ao listar as organizações",
+	"Failed to list usage":                                                     "Falha ao listar a utilização",
+	"Event stream is not available":                                            "O fluxo de eventos não está disponível",
+	"Streaming is not supported":                                               "O envio contínuo não é suportado",
+	"Failed to list sessions":                                                  "Falha ao listar as sessões",
+	"Failed to list users":                                                     "Falha ao listar os utilizadores",
+	"Failed to reactivate organization":                                        "Falha ao reativar a organização",
+	"Failed to reactivate user":                                                "Falha ao reativar o utilizador",
+	"Failed to reset password":                                                 "Falha ao redefinir a palavra-passe",
+	"Failed to suspend organization":                                           "Falha ao suspender a organização",
+	"Failed to suspend user":                                                   "Falha ao suspender o utilizador",
+	"Failed to test trigger":                                                   "Falha ao testar o gatilho",
+	"Failed to update organization":                                            "Falha ao atualizar a organização",
+	"failed to enable module":                                                  "falha ao ativar o módulo",
+	"No available therapist for this time":                                     "Nenhum terapeuta disponível neste horário",
+	"Patient created but failed to fetch details":                              "Paciente criado, mas falha ao obter os detalhes",
+	"sandbox not found":                                                        "Sandbox não encontrada",
+	"sandboxes cannot be cloned":                                               "Não é possível clonar uma sandbox",
+	"sandbox has no administrator":                                             "A sandbox não tem administrador",
+	"user not found":                                                           "Utilizador não encontrado",
+	"organization not found":                                                   "Organização não encontrada",
+	"template not found":                                                       "modelo não encontrado",
+	"entity not found":                                                         "entidade não encontrada",
+	"transition not found":                                                     "transição não encontrada",
+	"input name is required":                                                   "o nome do campo é obrigatório",
+	"at least one step is required":                                            "é necessário pelo menos um passo",
+	"a cutoff date is required when the log is kept forever":                   "é necessária uma data limite quando o registo é mantido para sempre",
+	"cutoff cannot be in the future":                                           "a data limite não pode ser no futuro",
+	"an archive of this organization is already in progress":                   "já existe um arquivo desta organização em curso",
+	"log archive not found":                                                    "arquivo de registo não encontrado",
+	"log archive is not ready":                                                 "o arquivo de registo ainda não está pronto",
+	"Failed to list log archives":                                              "Falha ao listar arquivos de registo",
+	"scheduled job not found":                                                  "tarefa agendada não encontrada",
+	"only pending jobs can be cancelled":                                       "apenas tarefas pendentes podem ser canceladas",
+	"only pending or failed jobs can be run":                                   "apenas tarefas pendentes ou falhadas podem ser executadas",
+	"only pending or failed jobs can be rescheduled":                           "apenas tarefas pendentes ou falhadas podem ser reagendadas",
+	"new time must be in the future":                                           "a nova hora deve ser no futuro",
+	"scheduled_for is required":                                                "scheduled_for é obrigatório",
+	"Therapist is required":                                                    "Terapeuta é obrigatório",
+	"scheduled time is required":                                               "A hora agendada é obrigatória",
+	"therapist not found":                                                      "Terapeuta não encontrado",
+	"workflow not found":                                                       "workflow não encontrado",
+	"end date must be after start date":                                        "a data de fim tem de ser posterior à data de início",
+	"Failed to list merges":                                                    "Falha ao listar fusões",
+	"Source organization is required":                                          "A organização de origem é obrigatória",
+	"cannot merge an organization into itself":                                 "não é possível fundir uma organização consigo própria",
+	"clients rule must be merge or keep_both":                                  "a regra de clientes tem de ser merge ou keep_both",
+	"workflows rule must be rename or skip":                                    "a regra de workflows tem de ser rename ou skip",
+	"templates rule must be rename or skip":                                    "a regra de modelos tem de ser rename ou skip",
+	"number prefix must be at most 10 characters":                              "o prefixo de numeração pode ter no máximo 10 caracteres",
+	"a merge involving these organizations is already in progress":             "já existe uma fusão em curso envolvendo estas organizações",
+	"merge not found":                                                          "fusão não encontrada",
+	"only failed merges can be resumed":                                        "só é possível retomar fusões que falharam",
+	"Website not allowed to embed this organization":                           "Website sem permissão para incorporar esta organização",
+	"Only admins can manage the booking widget":                                "Apenas administradores podem gerir o widget de marcações",
+	"Only admins can export data":                                              "Apenas administradores podem exportar dados",
+	"invalid export entity":                                                    "Tipo de exportação inválido",
+	"an export of this data is already in progress":                            "Já existe uma exportação destes dados em curso",
+	"data export not found":                                                    "Exportação não encontrada",
+	"execution log not found":                                                  "Registo de execução não encontrado",
+	"execution log is not a trigger or action event":                           "O registo de execução não é um evento de gatilho ou ação",
+	"log_id, or trigger_id with entity_type and entity_id, is required":        "É necessário log_id, ou trigger_id com entity_type e entity_id",
+	"trigger not found":                                                        "Gatilho não encontrado",
+	"Only admins can manage the budget PDF template":                           "Apenas administradores podem gerir o modelo de PDF dos orçamentos",
+	"accent color must be in the #RRGGBB format":                               "a cor de destaque deve estar no formato #RRGGBB",
+	"title must have at most 100 characters":                                   "o título deve ter no máximo 100 caracteres",
+	"budget is no longer awaiting a response":                                  "o orçamento já não aguarda resposta",
+	"budget has expired":                                                       "o orçamento expirou",
+	"comment must have at most 2000 characters":                                "o comentário deve ter no máximo 2000 caracteres",
+	"reminders were already migrated":                                          "os lembretes já foram migrados",
+	"client is already linked to a patient":                                    "o cliente já está associado a um paciente",
+	"only draft or sent budgets can be sent":                                   "apenas orçamentos em rascunho ou enviados podem ser enviados",
+	"budget has not changed since it was sent":                                 "o orçamento não foi alterado desde que foi enviado",
+	"budget revision not found":                                                "revisão do orçamento não encontrada",
+	"Invalid revision number":                                                  "Número de revisão inválido",
+	"Invalid as_of date format. Use YYYY-MM-DD":                                "Formato de data as_of inválido. Use AAAA-MM-DD",
+	"as_of date cannot be in the future":                                       "a data as_of não pode estar no futuro",
+	"invalid phone number":                                                     "Número de telefone inválido",
+	"invalid emergency phone number":                                           "Número de telefone de emergência inválido",
+	"Unsupported country":                                                      "País não suportado",
+	"a VAT exemption reason is required for a 0% VAT rate":                     "É necessário um motivo de isenção de IVA para uma taxa de IVA de 0%",
+	"a reason is required for a credit note":                                   "É necessário um motivo para a nota de crédito",
+	"a reason is required to void an invoice":                                  "É necessário um motivo para anular uma fatura",
+	"invalid VAT rate":                                                         "Taxa de IVA inválida",
+	"invalid due days":                                                         "Prazo de vencimento inválido",
+	"invoice already has a credit note":                                        "A fatura já tem uma nota de crédito",
+	"invoice has a credit note":                                                "A fatura tem uma nota de crédito",
+	"invoice not found":                                                        "Fatura não encontrada",
+	"nothing to invoice":                                                       "Nada a faturar",
+	"only draft invoices can be deleted":                                       "Apenas faturas em rascunho podem ser eliminadas",
+	"only draft invoices can be issued":                                        "Apenas faturas em rascunho podem ser emitidas",
+	"only issued invoices can be marked as paid":                               "Apenas faturas emitidas podem ser marcadas como pagas",
+	"only issued invoices can be voided":                                       "Apenas faturas emitidas podem ser anuladas",
+	"only issued or paid invoices can be credited":                             "Apenas faturas emitidas ou pagas podem ser creditadas",
+	"payment is already invoiced":                                              "O pagamento já está faturado",
+	"Failed to create invoice workflow: ":                                      "Falha ao criar o workflow de faturas: ",
+	"invalid Stripe secret key":                                                "Chave secreta do Stripe inválida",
+	"invalid Stripe webhook signing secret":                                    "Segredo de assinatura do webhook do Stripe inválido",
+	"invalid currency":                                                         "Moeda inválida",
+	"a Stripe secret key is required to enable payment links":                  "É necessária uma chave secreta do Stripe para ativar os links de pagamento",
+	"a Stripe webhook signing secret is required to enable payment links":      "É necessário um segredo de assinatura do webhook do Stripe para ativar os links de pagamento",
+	"payment links are not enabled":                                            "Os links de pagamento não estão ativos",
+	"payment is already paid":                                                  "O pagamento já foi pago",
+	"payment is cancelled":                                                     "O pagamento está cancelado",
+	"only approved budgets can be paid online":                                 "Apenas orçamentos aprovados podem ser pagos online",
+	"nothing to pay":                                                           "Nada a pagar",
+	"invalid payment link target":                                              "Destino do link de pagamento inválido",
+	"payment link not found":                                                   "Link de pagamento não encontrado",
+	"only open payment links can be cancelled":                                 "Apenas links de pagamento abertos podem ser cancelados",
+	"Invalid signature":                                                        "Assinatura inválida",
+	"Failed to process event":                                                  "Falha ao processar o evento",
+	"Failed to list security alerts":                                           "Falha ao listar alertas de segurança",
+	"Failed to check security alerts":                                          "Falha ao verificar alertas de segurança",
+	"security alert not found":                                                 "Alerta de segurança não encontrado",
+	"security alert is not open":                                               "O alerta de segurança não está pendente",
+	"justification is required":                                                "A justificação é obrigatória",
+	"payment references are not enabled":                                       "as referências de pagamento não estão ativas",
+	"payment reference not found":                                              "referência de pagamento não encontrada",
+	"only open payment references can be cancelled":                            "apenas referências de pagamento abertas podem ser canceladas",
+	"amount cannot be paid by Multibanco":                                      "o valor não pode ser pago por Multibanco",
+	"validity must be between 1 and 365 days":                                  "a validade deve estar entre 1 e 365 dias",
+	"the Multibanco entity and sub-entity are required to enable IfThenPay":    "a entidade e a subentidade Multibanco são obrigatórias para ativar a IfThenPay",
+	"the anti-phishing key is required to enable IfThenPay":                    "a chave anti-phishing é obrigatória para ativar a IfThenPay",
+	"the account ID and API key are required to enable Easypay":                "o ID da conta e a chave de API são obrigatórios para ativar a Easypay",
+	"Callback not found":                                                       "Callback não encontrado",
+	"Failed to process callback":                                               "Falha ao processar o callback",
+	"Failed to process notification":                                           "Falha ao processar a notificação",
+	"confirmation does not match the organization name":                        "a confirmação não corresponde ao nome da organização",
+	"a deletion of this organization is already in progress":                   "já está em curso uma eliminação desta organização",
+	"deletion request not found":                                               "pedido de eliminação não encontrado",
+	"deletion request not found or not failed":                                 "pedido de eliminação não encontrado ou sem falha",
+	"only deletion requests in their cooling-off period can be cancelled":      "apenas pedidos de eliminação no período de reflexão podem ser cancelados",
+	"the export is not available":                                              "a exportação não está disponível",
+	"Failed to list deletion requests":                                         "Falha ao listar os pedidos de eliminação",
+	"Failed to send sign-in link":                                              "Falha ao enviar o link de acesso",
+	"sign-in link is invalid or has expired":                                   "o link de acesso é inválido ou expirou",
+	"email is required":                                                        "o email é obrigatório",
+	"phone is required":                                                        "o telefone é obrigatório",
+	"only upcoming pending sessions can be confirmed":                          "apenas sessões futuras pendentes podem ser confirmadas",
+	"cancellation cutoff must be between 0 and 720 hours":                      "o prazo de cancelamento deve estar entre 0 e 720 horas",
+	"chat webhook not found":                                                   "Webhook de chat não encontrado",
+	"chat notifications are not configured":                                    "As notificações por chat não estão configuradas",
+	"reschedule link is invalid or has expired":                                "O link de remarcação é inválido ou expirou",
+	"the selected time is no longer available":                                 "O horário escolhido já não está disponível",
+	"waiting list entry not found":                                             "Entrada da lista de espera não encontrada",
+	"patient is already on the waiting list for this therapist and service":    "O paciente já está na lista de espera para este terapeuta e serviço",
+	"only entries still waiting can be changed":                                "Só é possível alterar entradas ainda em espera",
+	"offer is invalid or has expired":                                          "A oferta é inválida ou expirou",
+	"the slot is no longer available":                                          "A vaga já não está disponível",
+	"waiting list offers are not configured":                                   "As ofertas da lista de espera não estão configuradas",
+	"offer_slot action requires a WhatsApp template":                           "A ação offer_slot requer um modelo de WhatsApp",
+	"the WhatsApp conversation window is closed; only approved templates can be sent": "a janela de conversa do WhatsApp está fechada; só podem ser enviados modelos aprovados",
+	"WhatsApp Cloud API credentials not configured":                                   "As credenciais da WhatsApp Cloud API não estão configuradas",
+	"WhatsApp Cloud API credentials are invalid":                                      "As credenciais da WhatsApp Cloud API são inválidas",
+	"WhatsApp Cloud API phone number not found":                                       "Número de telefone da WhatsApp Cloud API não encontrado",
+	"message not found":                                                "Mensagem não encontrada",
+	"message has no media":                                             "A mensagem não tem ficheiro",
+	"Template has no approved WhatsApp template":                       "O modelo não tem um modelo de WhatsApp aprovado",
+	"name is required":                                                 "O nome é obrigatório",
+	"hourly rate cannot be negative":                                   "O valor por hora não pode ser negativo",
+	"daily hours must be between 0 and 24":                             "As horas diárias têm de estar entre 0 e 24",
+	"planned hours must be between 0 and 24":                           "As horas planeadas têm de estar entre 0 e 24",
+	"actual hours must be between 0 and 24":                            "As horas realizadas têm de estar entre 0 e 24",
+	"crew member not found":                                            "Membro da equipa não encontrado",
+	"crew assignment not found":                                        "Alocação da equipa não encontrada",
+	"crew member is not active":                                        "O membro da equipa não está ativo",
+	"crew member has recorded hours; deactivate them instead":          "O membro da equipa tem horas registadas; desative-o em vez de o eliminar",
+	"crew cannot be scheduled on a completed or cancelled project":     "Não é possível alocar equipa a uma obra concluída ou cancelada",
+	"end date must be on or after the start date":                      "A data de fim tem de ser igual ou posterior à data de início",
+	"no days match the selected weekdays":                              "Nenhum dia corresponde aos dias da semana selecionados",
+	"weekdays must be between 0 (Sunday) and 6 (Saturday)":             "Os dias da semana têm de estar entre 0 (domingo) e 6 (sábado)",
+	"hours worked can only be recorded from the day of the assignment": "As horas realizadas só podem ser registadas a partir do dia da alocação",
+	"invalid progress mode":                                            "modo de progresso inválido",
+	"project progress is not computed from tasks":                      "o progresso do projeto não é calculado a partir das tarefas",
+	"task weight must be between 1 and 100":                            "o peso da tarefa tem de estar entre 1 e 100",
+	"Failed to verify signature":                                       "Falha ao verificar a assinatura",
+	"unknown message":                                                  "mensagem desconhecida",
+	"recipient has opted out of WhatsApp messages":                     "o destinatário pediu para não receber mensagens por WhatsApp",
+	"treatment plan not found":                                         "plano de tratamento não encontrado",
+	"goal not found":                                                   "objetivo não encontrado",
+	"session outcome not found":                                        "resultado da sessão não encontrado",
+	"only completed sessions can have an outcome":                      "só as sessões concluídas podem ter resultado",
+	"the treatment plan is for another patient":                        "o plano de tratamento é de outro paciente",
+	"failed to get benchmarking participation":                         "Falha ao obter a participação no benchmarking",
+	"failed to opt in to benchmarking":                                 "Falha ao aderir ao benchmarking",
+	"failed to opt out of benchmarking":                                "Falha ao sair do benchmarking",
+	"consent not found":                                                "Consentimento não encontrado",
+	"recipient has opted out of messages on this channel":              "o destinatário pediu para não receber mensagens por este canal",
+	"recipient has opted out of email messages":                        "o destinatário pediu para não receber mensagens por email",
+	"recipient has opted out of SMS messages":                          "o destinatário pediu para não receber mensagens por SMS",
+	"failed to check consent":                                          "Falha ao verificar o consentimento",
+	"failed to record consent":                                         "Falha ao registar o consentimento",
+	"failed to delete consent":                                         "Falha ao eliminar o consentimento",
+	"this integrity category cannot be repaired automatically":         "Esta categoria de integridade não pode ser reparada automaticamente",
+	"failed to update send window":                                     "falha ao atualizar a janela de envio",
+	"failed to defer reminder":                                         "falha ao adiar o lembrete",
+	"quiet hours last until the session starts":                        "o período de silêncio dura até ao início da sessão",
+	"email suppression not found":                                      "Supressão de email não encontrada",
+	"recipient email address is suppressed":                            "o endereço de email do destinatário está suprimido por devolução ou queixa",
+	"failed to delete email suppression":                               "Falha ao eliminar a supressão de email",
+	"failed to check email suppression":                                "Falha ao verificar a supressão de email",
+	"Failed to process events":                                         "Falha ao processar os eventos",
+	"notification queues are not available without Redis":              "As filas de notificações não estão disponíveis sem Redis",
+
+	// ============ Success Messages ============
+	"Action created successfully":                                     "Ação criada com sucesso",
+	"Action deleted successfully":                                     "Ação eliminada com sucesso",
+	"Action updated successfully":                                     "Ação atualizada com sucesso",
+	"Approval rule created successfully":                              "Regra de aprovação criada com sucesso",
+	"Approval rule deleted successfully":                              "Regra de aprovação eliminada com sucesso",
+	"Approval rule updated successfully":                              "Regra de aprovação atualizada com sucesso",
+	"Budget prices updated successfully":                              "Preços do orçamento atualizados com sucesso",
+	"Budget rejected successfully":                                    "Orçamento rejeitado com sucesso",
+	"Budget returned to draft":                                        "Orçamento devolvido a rascunho",
+	"Cancellation rule created successfully":                          "Regra de cancelamento criada com sucesso",
+	"Cancellation rule deleted successfully":                          "Regra de cancelamento eliminada com sucesso",
+	"Cancellation rule updated successfully":                          "Regra de cancelamento atualizada com sucesso",
+	"Client created successfully":                                     "Cliente criado com sucesso",
+	"Client deleted successfully":                                     "Cliente eliminado com sucesso",
+	"Client updated successfully":                                     "Cliente atualizado com sucesso",
+	"Compliance item completed successfully":                          "Item de conformidade concluído com sucesso",
+	"Compliance item reopened successfully":                           "Item de conformidade reaberto com sucesso",
+	"Compliance item waived successfully":                             "Item de conformidade dispensado com sucesso",
+	"Compliance requirement created successfully":                     "Requisito de conformidade criado com sucesso",
+	"Compliance requirement deleted successfully":                     "Requisito de conformidade eliminado com sucesso",
+	"Compliance requirement updated successfully":                     "Requisito de conformidade atualizado com sucesso",
+	"Compliance requirements applied successfully":                    "Requisitos de conformidade aplicados com sucesso",
+	"Conflict override approved successfully":                         "Exceção de conflito aprovada com sucesso",
+	"Conflict override rejected successfully":                         "Exceção de conflito rejeitada com sucesso",
+	"Cost index deleted successfully":                                 "Índice de custos eliminado com sucesso",
+	"Cost index updated successfully":                                 "Índice de custos atualizado com sucesso",
+	"Default workflows initialized":                                   "Workflows predefinidos inicializados",
+	"Follow-up sequence updated successfully":                         "Sequência de seguimento atualizada com sucesso",
+	"Holiday created successfully":                                    "Feriado criado com sucesso",
+	"Holiday deleted successfully":                                    "Feriado eliminado com sucesso",
+	"Invitation sent successfully":                                    "Convite enviado com sucesso",
+	"Invitation resent successfully":                                  "Convite reenviado com sucesso",
+	"Invitation revoked successfully":                                 "Convite revogado com sucesso",
+	"Invitation accepted successfully":                                "Convite aceite com sucesso",
+	"Logo uploaded successfully":                                      "Logótipo carregado com sucesso",
+	"Loss reason recorded successfully":                               "Motivo de perda registado com sucesso",
+	"Module configuration updated successfully":                       "Configuração do módulo atualizada com sucesso",
+	"Module disabled successfully":                                    "Módulo desativado com sucesso",
+	"Module enabled successfully":                                     "Módulo ativado com sucesso",
+	"Notification settings updated successfully":                      "Definições de notificações atualizadas com sucesso",
+	"Out-of-office created successfully":                              "Ausência criada com sucesso",
+	"Out-of-office deleted successfully":                              "Ausência eliminada com sucesso",
+	"Out-of-office updated successfully":                              "Ausência atualizada com sucesso",
+	"Patient created successfully":                                    "Paciente criado com sucesso",
+	"Patient deleted successfully":                                    "Paciente eliminado com sucesso",
+	"Patient updated successfully":                                    "Paciente atualizado com sucesso",
+	"Payment created successfully":                                    "Pagamento criado com sucesso",
+	"Payment deleted successfully":                                    "Pagamento eliminado com sucesso",
+	"Payment updated successfully":                                    "Pagamento atualizado com sucesso",
+	"Payment marked as paid":                                          "Pagamento marcado como pago",
+	"Receipt uploaded successfully":                                   "Recibo carregado com sucesso",
+	"Expense updated successfully":                                    "Despesa atualizada com sucesso",
+	"Expense posted successfully":                                     "Despesa lançada com sucesso",
+	"Expense deleted successfully":                                    "Despesa eliminada com sucesso",
+	"Export requested successfully":                                   "Exportação pedida com sucesso",
+	"Workflow imported successfully":                                  "Workflow importado com sucesso",
+	"Statement imported successfully":                                 "Extrato importado com sucesso",
+	"Transaction matched successfully":                                "Movimento associado com sucesso",
+	"Transaction unmatched successfully":                              "Associação do movimento removida com sucesso",
+	"Transaction ignored successfully":                                "Movimento ignorado com sucesso",
+	"Action requeued successfully":                                    "Ação reenviada com sucesso",
+	"Action discarded successfully":                                   "Ação descartada com sucesso",
+	"Financial period closed successfully":                            "Período financeiro fechado com sucesso",
+	"Financial period reopened successfully":                          "Período financeiro reaberto com sucesso",
+	"Cash register opened successfully":                               "Caixa aberta com sucesso",
+	"Cash register closed successfully":                               "Caixa fechada com sucesso",
+	"Taking recorded successfully":                                    "Recebimento registado com sucesso",
+	"Price list import cancelled successfully":                        "Importação da tabela de preços cancelada com sucesso",
+	"Price list imported successfully":                                "Tabela de preços importada com sucesso",
+	"Price list parsed successfully":                                  "Tabela de preços analisada com sucesso",
+	"Module updates marked as seen":                                   "Novidades dos módulos marcadas como vistas",
+	"Module changelog published successfully":                         "Notas de versão do módulo publicadas com sucesso",
+	"Project created successfully":                                    "Projeto criado com sucesso",
+	"Project deleted successfully":                                    "Projeto eliminado com sucesso",
+	"Project progress updated successfully":                           "Progresso do projeto atualizado com sucesso",
+	"Project status updated successfully":                             "Estado do projeto atualizado com sucesso",
+	"Project template created successfully":                           "Modelo de projeto criado com sucesso",
+	"Project template deleted successfully":                           "Modelo de projeto eliminado com sucesso",
+	"Project updated successfully":                                    "Projeto atualizado com sucesso",
+	"Project template updated successfully":                           "Modelo de projeto atualizado com sucesso",
+	"Service created successfully":                                    "Serviço criado com sucesso",
+	"Service deleted successfully":                                    "Serviço eliminado com sucesso",
+	"Service updated successfully":                                    "Serviço atualizado com sucesso",
+	"Session cancelled successfully":                                  "Sessão cancelada com sucesso",
+	"Session completed successfully":                                  "Sessão concluída com sucesso",
+	"Session confirmed successfully":                                  "Sessão confirmada com sucesso",
+	"Session created successfully":                                    "Sessão criada com sucesso",
+	"Session deleted successfully":                                    "Sessão eliminada com sucesso",
+	"Session marked as no-show successfully":                          "Sessão marcada como falta com sucesso",
+	"Session updated successfully":                                    "Sessão atualizada com sucesso",
+	"Session series created successfully":                             "Série de sessões criada com sucesso",
+	"Session series updated successfully":                             "Série de sessões atualizada com sucesso",
+	"Session series cancelled successfully":                           "Série de sessões cancelada com sucesso",
+	"Inbox item assigned successfully":                                "Item da caixa de entrada atribuído com sucesso",
+	"Inbox item snoozed successfully":                                 "Item da caixa de entrada adiado com sucesso",
+	"Inbox item dismissed successfully":                               "Item da caixa de entrada descartado com sucesso",
+	"Follow-up created successfully":                                  "Lembrete criado com sucesso",
+	"Follow-up updated successfully":                                  "Lembrete atualizado com sucesso",
+	"Follow-up completed successfully":                                "Lembrete concluído com sucesso",
+	"Follow-up cancelled successfully":                                "Lembrete cancelado com sucesso",
+	"State created successfully":                                      "Estado criado com sucesso",
+	"State deleted successfully":                                      "Estado eliminado com sucesso",
+	"State updated successfully":                                      "Estado atualizado com sucesso",
+	"States reordered successfully":                                   "Estados reordenados com sucesso",
+	"Status remap applied successfully":                               "Remapeamento de estado aplicado com sucesso",
+	"Status remap deleted successfully":                               "Remapeamento de estado eliminado com sucesso",
+	"Status remap saved successfully":                                 "Remapeamento de estado guardado com sucesso",
+	"Task assigned successfully":                                      "Tarefa atribuída com sucesso",
+	"Task created successfully":                                       "Tarefa criada com sucesso",
+	"Task deleted successfully":                                       "Tarefa eliminada com sucesso",
+	"Task status updated successfully":                                "Estado da tarefa atualizado com sucesso",
+	"Task updated successfully":                                       "Tarefa atualizada com sucesso",
+	"Template created successfully":                                   "Modelo criado com sucesso",
+	"Template deleted successfully":                                   "Modelo eliminado com sucesso",
+	"Template updated successfully":                                   "Modelo atualizado com sucesso",
+	"Test email sent successfully":                                    "Email de teste enviado com sucesso",
+	"Test SMS sent successfully":                                      "SMS de teste enviado com sucesso",
+	"Test message sent successfully":                                  "Mensagem de teste enviada com sucesso",
+	"Webhook URL rotated successfully":                                "URL do webhook renovado com sucesso",
+	"Therapist created successfully":                                  "Terapeuta criado com sucesso",
+	"Therapist deleted successfully":                                  "Terapeuta eliminado com sucesso",
+	"Therapist updated successfully":                                  "Terapeuta atualizado com sucesso",
+	"Trigger created successfully":                                    "Gatilho criado com sucesso",
+	"Trigger deleted successfully":                                    "Gatilho eliminado com sucesso",
+	"Trigger test executed":                                           "Teste do gatilho executado",
+	"Trigger updated successfully":                                    "Gatilho atualizado com sucesso",
+	"User created successfully":                                       "Utilizador criado com sucesso",
+	"User updated successfully":                                       "Utilizador atualizado com sucesso",
+	"User deleted successfully":                                       "Utilizador eliminado com sucesso",
+	"User deactivated successfully":                                   "Utilizador desativado com sucesso",
+	"User reactivated successfully":                                   "Utilizador reativado com sucesso",
+	"Profile updated successfully":                                    "Perfil atualizado com sucesso",
+	"Password changed successfully":                                   "Palavra-passe alterada com sucesso",
+	"Avatar uploaded successfully":                                    "Avatar carregado com sucesso",
+	"Workflow created successfully":                                   "Workflow criado com sucesso",
+	"Workflow deleted successfully":                                   "Workflow eliminado com sucesso",
+	"Workflow duplicated successfully":                                "Workflow duplicado com sucesso",
+	"Quotas updated successfully":                                     "Quotas atualizadas com sucesso",
+	"Workflow updated successfully":                                   "Workflow atualizado com sucesso",
+	"Sandbox created successfully":                                    "Sandbox criada com sucesso",
+	"Workflow promoted successfully":                                  "Workflow promovido com sucesso",
+	"Sandbox deleted successfully":                                    "Sandbox eliminada com sucesso",
+	"Transition inputs updated successfully":                          "Campos da transição atualizados com sucesso",
+	"Retention policy updated successfully":                           "Política de retenção atualizada com sucesso",
+	"Log archive requested successfully":                              "Arquivo de registo pedido com sucesso",
+	"Scheduled job cancelled successfully":                            "Tarefa agendada cancelada com sucesso",
+	"Scheduled job queued to run":                                     "Tarefa agendada colocada em execução",
+	"Scheduled job rescheduled successfully":                          "Tarefa agendada reagendada com sucesso",
+	"Organization merge requested successfully":                       "Fusão de organizações pedida com sucesso",
+	"Organization merge resumed successfully":                         "Fusão de organizações retomada com sucesso",
+	"Booking widget updated successfully":                             "Widget de marcações atualizado com sucesso",
+	"Budget PDF template updated successfully":                        "Modelo de PDF dos orçamentos atualizado com sucesso",
+	"Budget approved successfully":                                    "Orçamento aprovado com sucesso",
+	"Budget link rotated successfully":                                "Link do orçamento renovado com sucesso",
+	"Reminders migrated to the session workflow successfully":         "Lembretes migrados para o workflow de sessões com sucesso",
+	"Session linked successfully":                                     "Sessão associada com sucesso",
+	"Patient linked successfully":                                     "Paciente associado com sucesso",
+	"Invoice created successfully":                                    "Fatura criada com sucesso",
+	"Invoice issued successfully":                                     "Fatura emitida com sucesso",
+	"Invoice marked as paid":                                          "Fatura marcada como paga",
+	"Invoice voided successfully":                                     "Fatura anulada com sucesso",
+	"Credit note issued successfully":                                 "Nota de crédito emitida com sucesso",
+	"Invoice deleted successfully":                                    "Fatura eliminada com sucesso",
+	"Payment settings updated successfully":                           "Definições de pagamento atualizadas com sucesso",
+	"Payment link created successfully":                               "Link de pagamento criado com sucesso",
+	"Payment link cancelled successfully":                             "Link de pagamento cancelado com sucesso",
+	"Security alert justified successfully":                           "Alerta de segurança justificado com sucesso",
+	"Security alert acknowledged successfully":                        "Alerta de segurança revisto com sucesso",
+	"Security alert dismissed successfully":                           "Alerta de segurança descartado com sucesso",
+	"Payment reference generated successfully":                        "Referência de pagamento gerada com sucesso",
+	"Payment reference cancelled successfully":                        "Referência de pagamento cancelada com sucesso",
+	"Organization deletion requested successfully":                    "Eliminação da organização pedida com sucesso",
+	"Organization deletion cancelled successfully":                    "Eliminação da organização cancelada com sucesso",
+	"Organization deletion resumed successfully":                      "Eliminação da organização retomada com sucesso",
+	"Patient portal settings updated successfully":                    "Definições do portal do paciente atualizadas com sucesso",
+	"If the email belongs to a patient, a sign-in link has been sent": "Se o email pertencer a um paciente, foi enviado um link de acesso",
+	"Contact details updated successfully":                            "Contactos atualizados com sucesso",
+	"Chat webhook created successfully":                               "Webhook de chat criado com sucesso",
+	"Chat webhook updated successfully":                               "Webhook de chat atualizado com sucesso",
+	"Chat webhook deleted successfully":                               "Webhook de chat eliminado com sucesso",
+	"Session rescheduled successfully":                                "Sessão remarcada com sucesso",
+	"Patient added to the waiting list":                               "Paciente adicionado à lista de espera",
+	"Waiting list entry updated successfully":                         "Entrada da lista de espera atualizada com sucesso",
+	"Patient removed from the waiting list":                           "Paciente removido da lista de espera",
+	"Session booked successfully":                                     "Sessão marcada com sucesso",
+	"Offer declined":                                                  "Oferta recusada",
+	"Comment sent successfully":                                       "Comentário enviado com sucesso",
+	"Reply sent successfully":                                         "Resposta enviada com sucesso",
+	"Message sent successfully":                                       "Mensagem enviada com sucesso",
+	"Conversation marked as read":                                     "Conversa marcada como lida",
+	"Crew member created successfully":                                "Membro da equipa criado com sucesso",
+	"Crew member updated successfully":                                "Membro da equipa atualizado com sucesso",
+	"Crew member deleted successfully":                                "Membro da equipa eliminado com sucesso",
+	"Crew scheduled successfully":                                     "Equipa alocada com sucesso",
+	"Crew assignment updated successfully":                            "Alocação da equipa atualizada com sucesso",
+	"Crew assignment deleted successfully":                            "Alocação da equipa eliminada com sucesso",
+	"Project progress mode updated successfully":                      "Modo de progresso do projeto atualizado com sucesso",
+	"Project progress override cleared successfully":                  "Progresso manual do projeto removido com sucesso",
+	"Treatment plan created successfully":                             "Plano de tratamento criado com sucesso",
+	"Treatment plan updated successfully":                             "Plano de tratamento atualizado com sucesso",
+	"Treatment plan deleted successfully":                             "Plano de tratamento eliminado com sucesso",
+	"Goal updated successfully":                                       "Objetivo atualizado com sucesso",
+	"Session outcome recorded successfully":                           "Resultado da sessão registado com sucesso",
+	"Benchmarking participation updated successfully":                 "Participação no benchmarking atualizada com sucesso",
+	"Consent recorded successfully":                                   "Consentimento registado com sucesso",
+	"Consent updated successfully":                                    "Consentimento atualizado com sucesso",
+	"Consent deleted successfully":                                    "Consentimento eliminado com sucesso",
+	"Integrity issues repaired successfully":                          "Problemas de integridade reparados com sucesso",
+	"Email suppression removed successfully":                          "Supressão de email removida com sucesso",
+
+	// ============ Notifications ============
+	"New task: %s":                                  "Nova tarefa: %s",
+	"You were assigned a task on project %s":        "Foi-lhe atribuída uma tarefa no projeto %s",
+	"Overdue task: %s":                              "Tarefa em atraso: %s",
+	"The task on project %s was due on %s":          "A tarefa do projeto %s tinha prazo a %s",
+	"Treatment plan review: %s":                     "Revisão do plano de tratamento: %s",
+	"The treatment plan %s is due for review on %s": "O plano de tratamento %s deve ser revisto a %s",
+	"Budget %s approved by the client":              "Orçamento %s aprovado pelo cliente",
+	"Budget %s rejected by the client":              "Orçamento %s rejeitado pelo cliente",
+	"Budget %s was not approved internally":         "Orçamento %s não aprovado internamente",
+
+	// ============ Module Settings ============
+	"Construction settings":       "Definições de construção",
+	"Payment grace period (days)": "Período de tolerância de pagamento (dias)",
+	"Days after the due date before a pending payment is marked overdue": "Dias após a data de vencimento até um pagamento pendente ser marcado como em atraso",
+	"Appointments settings":            "Definições de agendamentos",
+	"Double bookings require approval": "Marcações sobrepostas requerem aprovação",
+	"Double-booking overrides by staff stay pending until an admin or manager approves them": "As marcações sobrepostas feitas pela equipa ficam pendentes até um administrador ou gestor as aprovar",
+	"Notifications settings":                               "Definições de notificações",
+	"Invoices settings":                                    "Definições de faturação",
+	"Default VAT rate (%)":                                 "Taxa de IVA predefinida (%)",
+	"VAT rate of new invoices; payment amounts include it": "Taxa de IVA das novas faturas; os valores dos pagamentos incluem-na",
+	"VAT exemption reason":                                 "Motivo de isenção de IVA",
+	"Printed on invoices with a 0% VAT rate, e.g. \"Isento nos termos do art. 9.º do CIVA\"": "Impresso nas faturas com taxa de IVA de 0%, p. ex. \"Isento nos termos do art. 9.º do CIVA\"",
+	"Payment terms (days)":                             "Prazo de pagamento (dias)",
+	"Days from the issue date until an invoice is due": "Dias desde a data de emissão até ao vencimento da fatura",
+	"Treatment plan review notice (days)":              "Aviso de revisão do plano de tratamento (dias)",
+	"Days before a treatment plan's review date that its therapist is notified and its review triggers fire": "Dias antes da data de revisão de um plano de tratamento em que o terapeuta é avisado e os gatilhos de revisão são executados",
+
+	// ============ Default Workflows ============
+	"Budget Lifecycle": "Ciclo de Vida do Orçamento",
+	"Default workflow for managing construction budgets": "Workflow padrão para gestão de orçamentos de construção",
+	"Project Lifecycle": "Ciclo de Vida do Projeto",
+	"Default workflow for managing construction projects": "Workflow padrão para gestão de projetos de construção",
+	"Draft":                 "Rascunho",
+	"Budget being prepared": "Orçamento em preparação",
+	"Internal Approval":     "Aprovação Interna",
+	"Budget awaits internal approval before being sent": "Orçamento aguarda aprovação interna antes do envio",
+	"Sent":                           "Enviado",
+	"Budget sent to the client":      "Orçamento enviado ao cliente",
+	"Approved":                       "Aprovado",
+	"Budget approved by the client":  "Orçamento aprovado pelo cliente",
+	"Rejected":                       "Rejeitado",
+	"Budget rejected by the client":  "Orçamento rejeitado pelo cliente",
+	"Expired":                        "Expirado",
+	"Budget expired without a reply": "Orçamento expirou sem resposta",
+	"Send to Client":                 "Enviar ao Cliente",
+	"Request Internal Approval":      "Pedir Aprovação Interna",
+	"Internal Approval Completed":    "Aprovação Interna Concluída",
+	"Internal Approval Rejected":     "Aprovação Interna Rejeitada",
+	"Client Approves":                "Cliente Aprova",
+	"Client Rejects":                 "Cliente Rejeita",
+	"Expire":                         "Expirar",
+	"Back to Draft":                  "Voltar a Rascunho",
+	"In Progress":                    "Em Progresso",
+	"Project in execution":           "Projeto em execução",
+	"On Hold":                        "Em Espera",
+	"Project paused":                 "Projeto pausado",
+	"Completed":                      "Concluído",
+	"Project finished":               "Projeto finalizado",
+	"Cancelled":                      "Cancelado",
+	"Project cancelled":              "Projeto cancelado",
+	"Pause Project":                  "Pausar Projeto",
+	"Complete Project":               "Concluir Projeto",
+	"Cancel Project":                 "Cancelar Projeto",
+	"Resume Project":                 "Retomar Projeto",
+	"Internal approval required - {{budget_number}}": "Aprovação interna necessária - {{budget_number}}",
+	"New budget available - {{budget_number}}":       "Novo orçamento disponível - {{budget_number}}",
+	"Budget {{budget_number}} has been approved!":    "Orçamento {{budget_number}} foi aprovado!",
+	"Project {{project_name}} has been completed!":   "Projeto {{project_name}} foi concluído!",
+	"Reminder: budget {{budget_number}}":             "Lembrete: orçamento {{budget_number}}",
+	"Still interested? Budget {{budget_number}}":     "Ainda interessado? Orçamento {{budget_number}}",
+	"Last reminder: budget {{budget_number}}":        "Último lembrete: orçamento {{budget_number}}",
+	"workflow.budget.approval_request.message":       "O orçamento {{budget_number}} ({{budget_total}} €) para {{client_name}} aguarda a sua aprovação antes de ser enviado.",
+	"workflow.budget.follow_up_3.body":               "Olá {{client_name}},\n\nEnviámos recentemente o orçamento {{budget_number}} para o projeto \"{{project_name}}\". Teve oportunidade de o analisar?\n\nEstamos ao dispor para qualquer esclarecimento.\n\nCumprimentos",
+	"workflow.budget.follow_up_7.body":               "Olá {{client_name}},\n\nGostaríamos de saber se o orçamento {{budget_number}} ({{budget_total}}€) vai ao encontro do que procura.\n\nSe preferir, podemos ajustar a proposta.\n\nCumprimentos",
+	"workflow.budget.follow_up_14.body":              "Olá {{client_name}},\n\nEste é o último lembrete sobre o orçamento {{budget_number}}. Caso não tenhamos resposta, o orçamento irá expirar na data de validade.\n\nCumprimentos",
+	"Session Lifecycle":                              "Ciclo de Vida da Sessão",
+	"Default workflow for managing sessions":         "Workflow padrão para gestão de sessões",
+	"Pending":                                        "Pendente",
+	"Session awaiting confirmation":                  "Sessão a aguardar confirmação",
+	"Confirmed":                                      "Confirmada",
+	"Session confirmed by the patient":               "Sessão confirmada pelo paciente",
+	"Session took place":                             "Sessão realizada",
+	"Session cancelled":                              "Sessão cancelada",
+	"No Show":                                        "Falta",
+	"Patient did not attend":                         "O paciente não compareceu",
+	"Confirm Session":                                "Confirmar Sessão",
+	"Complete Session":                               "Concluir Sessão",
+	"Cancel Session":                                 "Cancelar Sessão",
+	"Mark No Show":                                   "Marcar Falta",
+	"Invoice Lifecycle":                              "Ciclo de Vida da Fatura",
+	"Default workflow for managing invoices":         "Workflow padrão para gestão de faturas",
+	"Invoice being prepared":                         "Fatura em preparação",
+	"Issued":                                         "Emitida",
+	"Invoice issued to the customer":                 "Fatura emitida ao cliente",
+	"Paid":                                           "Paga",
+	"Invoice paid":                                   "Fatura paga",
+	"Void":                                           "Anulada",
+	"Invoice voided":                                 "Fatura anulada",
+	"Issue Invoice":                                  "Emitir Fatura",
+	"Mark as Paid":                                   "Marcar como Paga",
+	"Void Invoice":                                   "Anular Fatura",
+	"Invoice {{invoice_number}}":                     "Fatura {{invoice_number}}",
+
+	// ============ Default Templates ============
+	"Client name":                        "Nome do cliente",
+	"Budget number":                      "Número do orçamento",
+	"Project name":                       "Nome do projeto",
+	"Budget total":                       "Valor total do orçamento",
+	"Link to view the budget":            "Link para visualizar o orçamento",
+	"Organization name":                  "Nome da organização",
+	"Patient name":                       "Nome do paciente",
+	"Session date":                       "Data da sessão",
+	"Session time":                       "Hora da sessão",
+	"Therapist name":                     "Nome do terapeuta",
+	"Amount due":                         "Valor em dívida",
+	"Cancellation policy outcome":        "Resultado da política de cancelamento",
+	"Budget Sent":                        "Orçamento Enviado",
+	"New Budget - {{budget_number}}":     "Novo Orçamento - {{budget_number}}",
+	"Budget Approved":                    "Orçamento Aprovado",
+	"Budget {{budget_number}} Approved!": "Orçamento {{budget_number}} Aprovado!",
+	"Budget Rejected":                    "Orçamento Rejeitado",
+	"Budget {{budget_number}} Rejected":  "Orçamento {{budget_number}} Rejeitado",
+	"Budget Reminder":                    "Lembrete Orçamento",
+	"Project Completed":                  "Projeto Concluído",
+	"Project {{project_name}} Completed": "Projeto {{project_name}} Concluído",
+	"24h Reminder":                       "Lembrete 24h",
+	"2h Reminder":                        "Lembrete 2h",
+	"Session Confirmed":                  "Sessão Confirmada",
+	"Payment Reminder":                   "Lembrete Pagamento",
+	"Session Cancelled":                  "Sessão Cancelada",
+	"template.budget_sent.body": `Caro(a) {{client_name}},
+
+Enviamos em anexo o orçamento {{budget_number}} para o projeto "{{project_name}}".
+
+Valor Total: {{budget_total}}€
+
+Para visualizar ou aprovar o orçamento, aceda ao seguinte link:
+{{budget_link}}
+
+Ficamos ao dispor para qualquer esclarecimento.
+
+Com os melhores cumprimentos,
+{{organization_name}}`,
+	"template.budget_approved.body": `O orçamento {{budget_number}} para o cliente {{client_name}} foi aprovado!
+
+Projeto: {{project_name}}
+Valor: {{budget_total}}€
+
+O projeto pode agora ser iniciado.
+
+{{organization_name}}`,
+	"template.budget_rejected.body": `O orçamento {{budget_number}} para o cliente {{client_name}} foi rejeitado.
+
+Projeto: {{project_name}}
+Valor: {{budget_total}}€
+
+Poderá ser necessário rever o orçamento e reenviar ao cliente.
+
+{{organization_name}}`,
+	"template.budget_reminder.body": "Olá {{client_name}}! Enviámos-lhe o orçamento {{budget_number}} ({{budget_total}}€). Teve oportunidade de o ver? Estamos ao dispor para qualquer questão.",
+	"template.project_completed.body": `Caro(a) {{client_name}},
+
+Temos o prazer de informar que o projeto "{{project_name}}" foi concluído com sucesso!
+
+Agradecemos a sua confiança e estamos ao dispor para futuros projetos.
+
+Com os melhores cumprimentos,
+{{organization_name}}`,
+	"template.reminder_24h.body": `Olá {{patient_name}}! 👋
+
+Lembramos que tem uma consulta agendada para amanhã:
+
+📅 Data: {{session_date}}
+🕐 Hora: {{session_time}}
+👤 Terapeuta: {{therapist_name}}
+
+Por favor, confirme a sua presença respondendo a esta mensagem.
+
+{{organization_name}}`,
+	"template.reminder_2h.body": `Olá {{patient_name}}! 👋
+
+A sua consulta é daqui a 2 horas:
+
+🕐 {{session_time}}
+👤 {{therapist_name}}
+
+Esperamos por si!
+
+{{organization_name}}`,
+	"template.session_confirmed.body": `Olá {{patient_name}}! ✅
+
+A sua consulta está confirmada:
+
+📅 Data: {{session_date}}
+🕐 Hora: {{session_time}}
+👤 Terapeuta: {{therapist_name}}
+
+Até breve!
+{{organization_name}}`,
+	"template.payment_reminder.body": `Olá {{patient_name}}! 👋
+
+Gostaríamos de lembrar que tem sessões pendentes de pagamento no valor de {{amount}}€.
+
+Por favor, regularize o pagamento na próxima consulta ou contacte-nos para mais informações.
+
+Obrigado,
+{{organization_name}}`,
+	"template.session_cancelled.body": `Olá {{patient_name}},
+
+A sua consulta do dia {{session_date}} às {{session_time}} foi cancelada.
+
+{{cancellation_policy}}
+
+Para reagendar, por favor contacte-nos.
+
+{{organization_name}}`,
+	"Legacy 24h Reminder": "Lembrete 24h (migrado)",
+	"Legacy 2h Reminder":  "Lembrete 2h (migrado)",
+}
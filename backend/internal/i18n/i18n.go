@@ -0,0 +1,149 @@
+package i18n
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Supported locales
+const (
+	LocalePT = "pt"
+	LocaleEN = "en"
+)
+
+// DefaultLocale is used when a request has no supported Accept-Language
+const DefaultLocale = LocalePT
+
+type contextKey struct{}
+
+// catalogs maps a locale to its messages, keyed by message ID.
+// API messages use their English text as ID, so English needs no entry for them
+// and untranslated messages fall back to the text as written in the code.
+var catalogs = map[string]map[string]string{
+	LocalePT: catalogPT,
+	LocaleEN: catalogEN,
+}
+
+// IsSupported reports whether the locale has a message catalog
+func IsSupported(locale string) bool {
+	_, ok := catalogs[locale]
+	return ok
+}
+
+// T returns the message in the given locale, falling back to English and then to the ID itself
+func T(locale, id string) string {
+	if msg, ok := catalogs[locale][id]; ok {
+		return msg
+	}
+	if msg, ok := catalogEN[id]; ok {
+		return msg
+	}
+	return id
+}
+
+// Negotiate picks the best supported locale from an Accept-Language header,
+// honouring quality values. Region subtags are ignored (pt-BR matches pt).
+func Negotiate(acceptLanguage, fallback string) string {
+	type candidate struct {
+		locale string
+		q      float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+
+		locale, _, _ := strings.Cut(tag, "-")
+		if IsSupported(locale) {
+			candidates = append(candidates, candidate{locale, q})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return fallback
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+	return candidates[0].locale
+}
+
+// WithLocale returns a context carrying the locale
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, contextKey{}, locale)
+}
+
+// FromContext returns the context's locale, or DefaultLocale when none was set
+func FromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(contextKey{}).(string); ok {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// ResponseWriter carries the negotiated locale so responses can be translated
+// without passing the request around
+type ResponseWriter struct {
+	http.ResponseWriter
+	locale string
+}
+
+// NewResponseWriter wraps w with the locale
+func NewResponseWriter(w http.ResponseWriter, locale string) *ResponseWriter {
+	return &ResponseWriter{ResponseWriter: w, locale: locale}
+}
+
+// Locale returns the negotiated locale
+func (w *ResponseWriter) Locale() string {
+	return w.locale
+}
+
+// Flush lets streaming handlers flush through the wrapper
+func (w *ResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer for http.ResponseController
+func (w *ResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Translate returns the message in the writer's locale, or unchanged when the
+// writer carries no locale. Messages built as "Prefix: detail" have the prefix translated.
+func Translate(w http.ResponseWriter, message string) string {
+	lw, ok := w.(interface{ Locale() string })
+	if !ok || message == "" {
+		return message
+	}
+
+	if msg, ok := catalogs[lw.Locale()][message]; ok {
+		return msg
+	}
+	if prefix, detail, found := strings.Cut(message, ": "); found {
+		return T(lw.Locale(), prefix) + ": " + detail
+	}
+	return T(lw.Locale(), message)
+}
@@ -3,10 +3,15 @@ package jobs
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/i18n"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/services"
 	"github.com/controlwise/backend/internal/workflow"
 	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
@@ -14,18 +19,68 @@ import (
 
 // Handlers contains all job handlers
 type Handlers struct {
-	db     *database.DB
-	engine *workflow.Engine
+	db         *database.DB
+	engine     *workflow.Engine
+	workflow   *services.WorkflowService
+	accountant *services.AccountantService
+	usage      *services.UsageService
+	benchmark  *services.BenchmarkService
+	followUps  *services.FollowUpService
+	logs       *services.LogRetentionService
+	merges     *services.OrganizationMergeService
+	exports    *services.DataExportService
+	security   *services.AdminSecurityService
+	deletions  *services.OrganizationDeletionService
+	synthetic  *services.SyntheticTrafficService
 }
 
 // NewHandlers creates a new Handlers instance
 func NewHandlers(db *database.DB, engine *workflow.Engine) *Handlers {
 	return &Handlers{
-		db:     db,
-		engine: engine,
+		db:        db,
+		engine:    engine,
+		workflow:  services.NewWorkflowService(db),
+		usage:     services.NewUsageService(db),
+		benchmark: services.NewBenchmarkService(db),
+		merges:    services.NewOrganizationMergeService(db),
 	}
 }
 
+// SetAccountantService enables building the accountant export bundles, which need storage and email
+func (h *Handlers) SetAccountantService(accountant *services.AccountantService) {
+	h.accountant = accountant
+}
+
+// SetDataExportService enables building and expiring data exports, which need storage
+func (h *Handlers) SetDataExportService(exports *services.DataExportService) {
+	h.exports = exports
+}
+
+// SetFollowUpService enables notifying users of their due follow-ups, which needs email
+func (h *Handlers) SetFollowUpService(followUps *services.FollowUpService) {
+	h.followUps = followUps
+}
+
+// SetLogRetentionService enables archiving old execution log entries, which needs storage
+func (h *Handlers) SetLogRetentionService(logs *services.LogRetentionService) {
+	h.logs = logs
+}
+
+// SetAdminSecurityService enables analyzing admin activity, which emails the alerts
+func (h *Handlers) SetAdminSecurityService(security *services.AdminSecurityService) {
+	h.security = security
+}
+
+// SetOrganizationDeletionService enables running organization deletions, which need storage and email
+func (h *Handlers) SetOrganizationDeletionService(deletions *services.OrganizationDeletionService) {
+	h.deletions = deletions
+}
+
+// SetSyntheticTrafficService enables generating synthetic traffic against a staging organization
+func (h *Handlers) SetSyntheticTrafficService(synthetic *services.SyntheticTrafficService) {
+	h.synthetic = synthetic
+}
+
 // HandleSendNotification processes notification sending jobs
 func (h *Handlers) HandleSendNotification(ctx context.Context, t *asynq.Task) error {
 	var payload SendNotificationPayload
@@ -128,6 +183,31 @@ func (h *Handlers) HandleSendNotification(ctx context.Context, t *asynq.Task) er
 	return nil
 }
 
+// HandleSendMessage sends a message that was queued to respect provider rate limits
+func (h *Handlers) HandleSendMessage(ctx context.Context, t *asynq.Task) error {
+	var payload SendMessagePayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+
+	log.Printf("[SendMessage] Sending queued %s message to %s", payload.Channel, payload.To)
+
+	var content *workflow.ApprovedTemplate
+	if payload.ContentSID != "" {
+		content = &workflow.ApprovedTemplate{ContentSID: payload.ContentSID, Variables: payload.ContentVariables}
+	}
+
+	err := h.engine.GetExecutor().SendMessage(ctx, payload.OrganizationID, models.MessageChannel(payload.Channel),
+		payload.To, payload.Subject, payload.Body, content, payload.Critical)
+	if errors.Is(err, workflow.ErrMessageCapReached) || errors.Is(err, workflow.ErrRecipientOptedOut) ||
+		errors.Is(err, workflow.ErrRecipientSuppressed) {
+		// Paused by the monthly cap or refused by the recipient, retrying would not help
+		log.Printf("[SendMessage] %v", err)
+		return nil
+	}
+	return err
+}
+
 // HandleExecuteTrigger processes workflow trigger execution jobs
 func (h *Handlers) HandleExecuteTrigger(ctx context.Context, t *asynq.Task) error {
 	var payload ExecuteTriggerPayload
@@ -149,12 +229,81 @@ func (h *Handlers) HandleExecuteTrigger(ctx context.Context, t *asynq.Task) erro
 	return nil
 }
 
+// HandleRetryAction retries a failed workflow action. Once its attempts are exhausted the action
+// is dead-lettered instead of being archived by asynq.
+func (h *Handlers) HandleRetryAction(ctx context.Context, t *asynq.Task) error {
+	var payload RetryActionPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+
+	retried, _ := asynq.GetRetryCount(ctx)
+	maxRetry, _ := asynq.GetMaxRetry(ctx)
+	final := retried >= maxRetry
+	attempt := payload.PreviousAttempts + retried + 1
+
+	log.Printf("[RetryAction] Attempt %d of action %s for %s/%s", attempt, payload.ActionID, payload.EntityType, payload.EntityID)
+
+	err := h.engine.RetryAction(ctx, workflow.RetryActionPayload(payload), attempt, final)
+	if err != nil && final {
+		log.Printf("[RetryAction] Action %s dead-lettered: %v", payload.ActionID, err)
+		return nil
+	}
+	return err
+}
+
+// HandleExecuteBulkRun splits a bulk trigger run into one task per recipient
+func (h *Handlers) HandleExecuteBulkRun(ctx context.Context, t *asynq.Task) error {
+	var payload ExecuteBulkRunPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+
+	log.Printf("[ExecuteBulkRun] Fanning out run %s", payload.RunID)
+
+	return h.engine.GetScheduler().FanOutBulkRun(ctx, payload.RunID)
+}
+
+// HandleExecuteBulkRunItem executes a bulk run's trigger for one recipient and records the outcome
+func (h *Handlers) HandleExecuteBulkRunItem(ctx context.Context, t *asynq.Task) error {
+	var payload ExecuteBulkRunItemPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+
+	execErr := h.engine.ExecuteTriggerByID(ctx, payload.OrganizationID, payload.TriggerID, payload.EntityType, payload.EntityID)
+
+	// Failures only count against the run once asynq stops retrying
+	retried, _ := asynq.GetRetryCount(ctx)
+	maxRetry, _ := asynq.GetMaxRetry(ctx)
+	final := execErr == nil || retried >= maxRetry
+
+	if err := h.engine.GetScheduler().RecordBulkRunItem(ctx, payload.RunID, payload.ItemID, execErr, final); err != nil {
+		log.Printf("[ExecuteBulkRunItem] Failed to record item %s: %v", payload.ItemID, err)
+	}
+
+	if execErr != nil {
+		return fmt.Errorf("failed to execute trigger for %s/%s: %w", payload.EntityType, payload.EntityID, execErr)
+	}
+	return nil
+}
+
 // HandleCheckTimeTriggers processes periodic time-based trigger checks
 func (h *Handlers) HandleCheckTimeTriggers(ctx context.Context, t *asynq.Task) error {
 	log.Println("[CheckTimeTriggers] Starting time-based trigger scan")
 
 	// Use the scheduler to process pending jobs
 	scheduler := h.engine.GetScheduler()
+	if err := scheduler.ProcessRecurringTriggers(ctx); err != nil {
+		log.Printf("[CheckTimeTriggers] Error processing recurring triggers: %v", err)
+		// Still process the jobs already scheduled
+	}
+	if err := h.engine.ProcessRequeuedDeadLetters(ctx); err != nil {
+		log.Printf("[CheckTimeTriggers] Error processing requeued dead letters: %v", err)
+	}
+	if err := h.engine.ProcessSlotOfferFollowUps(ctx); err != nil {
+		log.Printf("[CheckTimeTriggers] Error following up waiting list offers: %v", err)
+	}
 	if err := scheduler.ProcessPendingJobs(ctx); err != nil {
 		log.Printf("[CheckTimeTriggers] Error processing pending jobs: %v", err)
 		return err
@@ -171,6 +320,412 @@ func (h *Handlers) HandleCheckTimeTriggers(ctx context.Context, t *asynq.Task) e
 	return nil
 }
 
+// HandleCheckComplianceDeadlines escalates overdue project compliance items through the project workflow
+func (h *Handlers) HandleCheckComplianceDeadlines(ctx context.Context, t *asynq.Task) error {
+	log.Println("[CheckComplianceDeadlines] Starting compliance deadline scan")
+
+	rows, err := h.db.Pool.Query(ctx, `
+		SELECT i.id, i.name, i.due_date, p.id, p.organization_id, p.status
+		FROM project_compliance_items i
+		JOIN projects p ON p.id = i.project_id
+		WHERE i.status = 'pending' AND i.escalated_at IS NULL AND i.due_date < CURRENT_DATE
+		AND p.status IN ('in_progress', 'on_hold') AND p.deleted_at IS NULL
+		ORDER BY i.due_date
+		LIMIT 500
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to query overdue compliance items: %w", err)
+	}
+
+	type overdueItem struct {
+		id, projectID, orgID uuid.UUID
+		name, projectStatus  string
+		dueDate              time.Time
+	}
+	var items []overdueItem
+	for rows.Next() {
+		var item overdueItem
+		if err := rows.Scan(&item.id, &item.name, &item.dueDate, &item.projectID, &item.orgID, &item.projectStatus); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan compliance item: %w", err)
+		}
+		items = append(items, item)
+	}
+	rows.Close()
+
+	escalated := 0
+	for _, item := range items {
+		fired, err := h.engine.FireEventTriggers(ctx, item.orgID, models.TriggerTypeComplianceOverdue, "project", item.projectID, item.projectStatus, map[string]interface{}{
+			"compliance_item_name": item.name,
+			"compliance_due_date":  item.dueDate.Format("02/01/2006"),
+		})
+		if err != nil {
+			log.Printf("[CheckComplianceDeadlines] Failed to escalate item %s: %v", item.id, err)
+			continue
+		}
+		// Items without a configured escalation stay pending so they escalate once a trigger is added
+		if fired == 0 {
+			continue
+		}
+
+		if _, err := h.db.Pool.Exec(ctx, `
+			UPDATE project_compliance_items SET escalated_at = NOW() WHERE id = $1
+		`, item.id); err != nil {
+			log.Printf("[CheckComplianceDeadlines] Failed to mark item %s as escalated: %v", item.id, err)
+			continue
+		}
+		escalated++
+	}
+
+	log.Printf("[CheckComplianceDeadlines] Completed: %d overdue, %d escalated", len(items), escalated)
+
+	return nil
+}
+
+// HandleCheckTaskDeadlines notifies the assignees of open tasks past their due date and
+// fires the project workflow's task_due triggers. Each task is flagged once until its due date changes.
+func (h *Handlers) HandleCheckTaskDeadlines(ctx context.Context, t *asynq.Task) error {
+	log.Println("[CheckTaskDeadlines] Starting task deadline scan")
+
+	rows, err := h.db.Pool.Query(ctx, `
+		SELECT t.id, t.title, t.due_date, t.assigned_to, p.id, p.title, p.organization_id, p.status
+		FROM tasks t
+		JOIN projects p ON p.id = t.project_id
+		WHERE t.status IN ('todo', 'in_progress') AND t.due_notified_at IS NULL
+		AND t.due_date < NOW() AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+		ORDER BY t.due_date
+		LIMIT 500
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to query overdue tasks: %w", err)
+	}
+
+	type overdueTask struct {
+		id, projectID, orgID               uuid.UUID
+		title, projectTitle, projectStatus string
+		dueDate                            time.Time
+		assignedTo                         *uuid.UUID
+	}
+	var tasks []overdueTask
+	for rows.Next() {
+		var task overdueTask
+		if err := rows.Scan(&task.id, &task.title, &task.dueDate, &task.assignedTo,
+			&task.projectID, &task.projectTitle, &task.orgID, &task.projectStatus); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan task: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+	rows.Close()
+
+	fired := 0
+	for _, task := range tasks {
+		if task.assignedTo != nil {
+			if _, err := h.db.Pool.Exec(ctx, `
+				INSERT INTO notifications (user_id, type, title, message, entity_type, entity_id)
+				VALUES ($1, $2, $3, $4, 'task', $5)
+			`, *task.assignedTo, models.NotificationTypeTaskDue,
+				fmt.Sprintf(i18n.T(i18n.DefaultLocale, "Overdue task: %s"), task.title),
+				fmt.Sprintf(i18n.T(i18n.DefaultLocale, "The task on project %s was due on %s"), task.projectTitle, task.dueDate.Format("02/01/2006")),
+				task.id); err != nil {
+				log.Printf("[CheckTaskDeadlines] Failed to notify assignee of task %s: %v", task.id, err)
+			}
+		}
+
+		n, err := h.engine.FireProjectTriggers(ctx, task.orgID, models.TriggerTypeTaskDue, "task", task.id, task.projectStatus, nil)
+		if err != nil {
+			log.Printf("[CheckTaskDeadlines] Failed to fire task_due triggers for task %s: %v", task.id, err)
+		}
+		fired += n
+
+		if _, err := h.db.Pool.Exec(ctx, `
+			UPDATE tasks SET due_notified_at = NOW() WHERE id = $1
+		`, task.id); err != nil {
+			log.Printf("[CheckTaskDeadlines] Failed to flag task %s: %v", task.id, err)
+		}
+	}
+
+	log.Printf("[CheckTaskDeadlines] Completed: %d overdue, %d triggers fired", len(tasks), fired)
+
+	return nil
+}
+
+// HandleCheckTreatmentPlanReviews notifies the therapists of active treatment plans whose review
+// date is within the appointments module's treatment_plan_review_days and fires the session
+// workflow's treatment_plan_review triggers against the patient's next session, or their latest
+// one. Each plan is flagged once until its review date changes.
+func (h *Handlers) HandleCheckTreatmentPlanReviews(ctx context.Context, t *asynq.Task) error {
+	log.Println("[CheckTreatmentPlanReviews] Starting treatment plan review scan")
+
+	rows, err := h.db.Pool.Query(ctx, `
+		SELECT tp.id, tp.organization_id, tp.title, tp.review_date, COALESCE(c.name, ''), th.user_id,
+			se.id, se.status
+		FROM treatment_plans tp
+		JOIN patients p ON p.id = tp.patient_id AND p.deleted_at IS NULL
+		LEFT JOIN clients c ON c.id = p.client_id
+		LEFT JOIN therapists th ON th.id = tp.therapist_id
+		LEFT JOIN LATERAL (
+			SELECT s.id, s.status FROM sessions s
+			WHERE s.patient_id = tp.patient_id AND s.deleted_at IS NULL
+			ORDER BY s.status IN ('pending', 'confirmed') AND s.scheduled_at > NOW() DESC,
+				CASE WHEN s.scheduled_at > NOW() THEN s.scheduled_at END ASC,
+				s.scheduled_at DESC
+			LIMIT 1
+		) se ON true
+		WHERE tp.status = 'active' AND tp.review_notified_at IS NULL AND tp.review_date IS NOT NULL
+		AND tp.review_date <= CURRENT_DATE + COALESCE((
+			SELECT (config->>'treatment_plan_review_days')::int
+			FROM organization_modules
+			WHERE organization_id = tp.organization_id AND module_name = 'appointments'
+		), 7)
+		ORDER BY tp.review_date
+		LIMIT 500
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to query treatment plans due for review: %w", err)
+	}
+
+	type duePlan struct {
+		id, orgID                uuid.UUID
+		title, patientName       string
+		reviewDate               time.Time
+		therapistUser, sessionID *uuid.UUID
+		sessionStatus            *string
+	}
+	var plans []duePlan
+	for rows.Next() {
+		var p duePlan
+		if err := rows.Scan(&p.id, &p.orgID, &p.title, &p.reviewDate, &p.patientName, &p.therapistUser,
+			&p.sessionID, &p.sessionStatus); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan treatment plan: %w", err)
+		}
+		plans = append(plans, p)
+	}
+	rows.Close()
+
+	fired := 0
+	for _, p := range plans {
+		if p.therapistUser != nil {
+			if _, err := h.db.Pool.Exec(ctx, `
+				INSERT INTO notifications (user_id, type, title, message, entity_type, entity_id)
+				VALUES ($1, $2, $3, $4, 'treatment_plan', $5)
+			`, *p.therapistUser, models.NotificationTypeTreatmentPlanReview,
+				fmt.Sprintf(i18n.T(i18n.DefaultLocale, "Treatment plan review: %s"), p.patientName),
+				fmt.Sprintf(i18n.T(i18n.DefaultLocale, "The treatment plan %s is due for review on %s"), p.title, p.reviewDate.Format("02/01/2006")),
+				p.id); err != nil {
+				log.Printf("[CheckTreatmentPlanReviews] Failed to notify therapist of plan %s: %v", p.id, err)
+			}
+		}
+
+		if p.sessionID != nil {
+			n, err := h.engine.FireEventTriggers(ctx, p.orgID, models.TriggerTypeTreatmentPlanReview, "session", *p.sessionID, *p.sessionStatus, map[string]interface{}{
+				"treatment_plan_title":       p.title,
+				"treatment_plan_review_date": p.reviewDate.Format("02/01/2006"),
+			})
+			if err != nil {
+				log.Printf("[CheckTreatmentPlanReviews] Failed to fire treatment_plan_review triggers for plan %s: %v", p.id, err)
+			}
+			fired += n
+		}
+
+		if _, err := h.db.Pool.Exec(ctx, `
+			UPDATE treatment_plans SET review_notified_at = NOW() WHERE id = $1
+		`, p.id); err != nil {
+			log.Printf("[CheckTreatmentPlanReviews] Failed to flag plan %s: %v", p.id, err)
+		}
+	}
+
+	log.Printf("[CheckTreatmentPlanReviews] Completed: %d plans due for review, %d triggers fired", len(plans), fired)
+
+	return nil
+}
+
+// HandleMarkOverduePayments marks pending payments past their due date, plus the construction module's
+// payment_grace_days, as overdue and fires the project workflow's payment_overdue triggers,
+// so reminder templates can chase the client
+func (h *Handlers) HandleMarkOverduePayments(ctx context.Context, t *asynq.Task) error {
+	log.Println("[MarkOverduePayments] Starting overdue payment scan")
+
+	rows, err := h.db.Pool.Query(ctx, `
+		UPDATE payments pay SET status = 'overdue', updated_at = CURRENT_TIMESTAMP
+		FROM projects p
+		WHERE p.id = pay.project_id AND pay.status = 'pending' AND pay.deleted_at IS NULL
+		AND pay.due_date + COALESCE((
+			SELECT (config->>'payment_grace_days')::int
+			FROM organization_modules
+			WHERE organization_id = pay.organization_id AND module_name = 'construction'
+		), 0) < CURRENT_DATE
+		RETURNING pay.id, pay.organization_id, p.status
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to mark overdue payments: %w", err)
+	}
+
+	type overduePayment struct {
+		id, orgID     uuid.UUID
+		projectStatus string
+	}
+	var payments []overduePayment
+	for rows.Next() {
+		var p overduePayment
+		if err := rows.Scan(&p.id, &p.orgID, &p.projectStatus); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan payment: %w", err)
+		}
+		payments = append(payments, p)
+	}
+	rows.Close()
+
+	fired := 0
+	for _, p := range payments {
+		n, err := h.engine.FireProjectTriggers(ctx, p.orgID, models.TriggerTypePaymentOverdue, "payment", p.id, p.projectStatus, nil)
+		if err != nil {
+			log.Printf("[MarkOverduePayments] Failed to fire payment_overdue triggers for payment %s: %v", p.id, err)
+			continue
+		}
+		fired += n
+	}
+
+	log.Printf("[MarkOverduePayments] Completed: %d payments overdue, %d triggers fired", len(payments), fired)
+
+	return nil
+}
+
+// HandleExpireBudgets marks sent budgets past their validity date as expired,
+// stopping their follow-up reminders and firing the expired state's on_enter triggers
+func (h *Handlers) HandleExpireBudgets(ctx context.Context, t *asynq.Task) error {
+	log.Println("[ExpireBudgets] Starting budget expiry scan")
+
+	rows, err := h.db.Pool.Query(ctx, `
+		UPDATE budgets SET status = 'expired', updated_at = CURRENT_TIMESTAMP
+		WHERE status = 'sent' AND valid_until < CURRENT_DATE AND deleted_at IS NULL
+		RETURNING id, organization_id
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to expire budgets: %w", err)
+	}
+
+	type expiredBudget struct {
+		id, orgID uuid.UUID
+	}
+	var budgets []expiredBudget
+	for rows.Next() {
+		var b expiredBudget
+		if err := rows.Scan(&b.id, &b.orgID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan budget: %w", err)
+		}
+		budgets = append(budgets, b)
+	}
+	rows.Close()
+
+	scheduler := h.engine.GetScheduler()
+	for _, b := range budgets {
+		if err := scheduler.CancelPendingJobs(ctx, "budget", b.id); err != nil {
+			log.Printf("[ExpireBudgets] Failed to cancel pending jobs for budget %s: %v", b.id, err)
+		}
+		if _, err := h.engine.FireEventTriggers(ctx, b.orgID, models.TriggerTypeOnEnter, "budget", b.id, string(models.BudgetStatusExpired), nil); err != nil {
+			log.Printf("[ExpireBudgets] Failed to fire expired triggers for budget %s: %v", b.id, err)
+		}
+	}
+
+	log.Printf("[ExpireBudgets] Completed: %d budgets expired", len(budgets))
+
+	return nil
+}
+
+// HandleCheckStatusConsistency flags entity statuses that no longer match a workflow state
+// and applies auto-apply status remaps
+func (h *Handlers) HandleCheckStatusConsistency(ctx context.Context, t *asynq.Task) error {
+	log.Println("[CheckStatusConsistency] Starting workflow status consistency check")
+
+	open, err := h.workflow.CheckAllStatusConsistency(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check status consistency: %w", err)
+	}
+
+	log.Printf("[CheckStatusConsistency] Completed: %d open status mismatches", open)
+
+	return nil
+}
+
+// HandleProcessExportBundles builds the accountant export bundles requested since the last run
+func (h *Handlers) HandleProcessExportBundles(ctx context.Context, t *asynq.Task) error {
+	if h.accountant == nil {
+		return nil
+	}
+
+	built, err := h.accountant.ProcessPendingExports(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to process export bundles: %w", err)
+	}
+	if built > 0 {
+		log.Printf("[ProcessExportBundles] Completed: %d bundles built", built)
+	}
+
+	return nil
+}
+
+// HandleComputeOrganizationUsage refreshes every organization's data usage snapshot
+func (h *Handlers) HandleComputeOrganizationUsage(ctx context.Context, t *asynq.Task) error {
+	log.Println("[ComputeUsage] Starting usage computation")
+
+	refreshed, err := h.usage.RefreshAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to compute usage: %w", err)
+	}
+
+	log.Printf("[ComputeUsage] Completed: %d organizations refreshed", refreshed)
+	return nil
+}
+
+// HandleComputeBenchmarks recomputes the metrics of the organizations taking part in benchmarking
+func (h *Handlers) HandleComputeBenchmarks(ctx context.Context, t *asynq.Task) error {
+	log.Println("[ComputeBenchmarks] Starting benchmark computation")
+
+	refreshed, err := h.benchmark.RefreshAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to compute benchmarks: %w", err)
+	}
+
+	log.Printf("[ComputeBenchmarks] Completed: %d organizations refreshed", refreshed)
+	return nil
+}
+
+// HandleGenerateSyntheticTraffic books and moves one minute's worth of synthetic sessions
+func (h *Handlers) HandleGenerateSyntheticTraffic(ctx context.Context, t *asynq.Task) error {
+	if h.synthetic == nil {
+		return nil
+	}
+
+	result, err := h.synthetic.Generate(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to generate synthetic traffic: %w", err)
+	}
+
+	log.Printf("[SyntheticTraffic] Completed: %d sessions created, %d conflicts, %d transitioned, %d failed",
+		result.Created, result.Conflicts, result.Transitioned, result.Failed)
+	return nil
+}
+
+// HandleSendFollowUps notifies users of the follow-ups that came due since the last run
+func (h *Handlers) HandleSendFollowUps(ctx context.Context, t *asynq.Task) error {
+	if h.followUps == nil {
+		return nil
+	}
+
+	notified, err := h.followUps.NotifyDue(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to send follow-ups: %w", err)
+	}
+	if notified > 0 {
+		log.Printf("[SendFollowUps] Completed: %d follow-ups due", notified)
+	}
+
+	return nil
+}
+
 // getEntityData retrieves entity data for notifications
 func (h *Handlers) getEntityData(ctx context.Context, orgID string, entityType string, entityID uuid.UUID) (map[string]interface{}, error) {
 	data := make(map[string]interface{})
@@ -260,3 +815,120 @@ func min(a, b int) int {
 	}
 	return b
 }
+
+// HandleScheduleLogArchives queues an archive for every organization with execution log entries
+// older than its retention period
+func (h *Handlers) HandleScheduleLogArchives(ctx context.Context, t *asynq.Task) error {
+	if h.logs == nil {
+		return nil
+	}
+
+	queued, err := h.logs.ScheduleDueArchives(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to schedule log archives: %w", err)
+	}
+
+	log.Printf("[ScheduleLogArchives] Completed: %d archives queued", queued)
+	return nil
+}
+
+// HandleProcessLogArchives builds the execution log archives queued since the last run
+func (h *Handlers) HandleProcessLogArchives(ctx context.Context, t *asynq.Task) error {
+	if h.logs == nil {
+		return nil
+	}
+
+	built, err := h.logs.ProcessPendingArchives(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to process log archives: %w", err)
+	}
+	if built > 0 {
+		log.Printf("[ProcessLogArchives] Completed: %d archives built", built)
+	}
+
+	return nil
+}
+
+// HandleProcessOrganizationMerges runs the next queued organization merge
+func (h *Handlers) HandleProcessOrganizationMerges(ctx context.Context, t *asynq.Task) error {
+	processed, err := h.merges.ProcessPendingMerges(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to process organization merges: %w", err)
+	}
+	if processed > 0 {
+		log.Printf("[ProcessOrganizationMerges] Completed: %d merges processed", processed)
+	}
+
+	return nil
+}
+
+// HandleProcessDataExports builds the data exports requested since the last run
+func (h *Handlers) HandleProcessDataExports(ctx context.Context, t *asynq.Task) error {
+	if h.exports == nil {
+		return nil
+	}
+
+	built, err := h.exports.ProcessPendingExports(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to process data exports: %w", err)
+	}
+	if built > 0 {
+		log.Printf("[ProcessDataExports] Completed: %d exports built", built)
+	}
+
+	return nil
+}
+
+// HandleCleanupDataExports removes the files of data exports past their expiry
+func (h *Handlers) HandleCleanupDataExports(ctx context.Context, t *asynq.Task) error {
+	if h.exports == nil {
+		return nil
+	}
+
+	removed, err := h.exports.CleanupExpiredExports(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to clean up data exports: %w", err)
+	}
+
+	log.Printf("[CleanupDataExports] Completed: %d exports expired", removed)
+	return nil
+}
+
+// HandleAnalyzeAdminActivity flags unusual system admin activity in the audit log
+func (h *Handlers) HandleAnalyzeAdminActivity(ctx context.Context, t *asynq.Task) error {
+	if h.security == nil {
+		return nil
+	}
+
+	raised, err := h.security.Analyze(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to analyze admin activity: %w", err)
+	}
+
+	if raised > 0 {
+		log.Printf("[AnalyzeAdminActivity] Completed: %d security alerts raised", raised)
+	}
+	return nil
+}
+
+// HandleProcessOrganizationDeletions reminds organization admins of pending deletions and runs
+// the next export or purge due
+func (h *Handlers) HandleProcessOrganizationDeletions(ctx context.Context, t *asynq.Task) error {
+	if h.deletions == nil {
+		return nil
+	}
+
+	reminded, err := h.deletions.SendDueReminders(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to send organization deletion reminders: %w", err)
+	}
+	processed, err := h.deletions.ProcessDueRequests(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to process organization deletions: %w", err)
+	}
+
+	if reminded > 0 || processed > 0 {
+		log.Printf("[ProcessOrganizationDeletions] Completed: %d reminders sent, %d requests processed", reminded, processed)
+	}
+	return nil
+}
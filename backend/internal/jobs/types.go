@@ -9,6 +9,28 @@ const (
 	TypeSendNotification = "workflow:send_notification"
 	TypeExecuteTrigger   = "workflow:execute_trigger"
 	TypeCheckTimeTriggers = "workflow:check_time_triggers"
+	TypeCheckComplianceDeadlines = "compliance:check_deadlines"
+	TypeCheckTaskDeadlines = "tasks:check_deadlines"
+	TypeCheckTreatmentPlanReviews = "treatment_plans:check_reviews"
+	TypeExpireBudgets = "budgets:expire"
+	TypeMarkOverduePayments = "payments:mark_overdue"
+	TypeCheckStatusConsistency = "workflow:check_status_consistency"
+	TypeExecuteBulkRun = "workflow:execute_bulk_run"
+	TypeExecuteBulkRunItem = "workflow:execute_bulk_run_item"
+	TypeSendMessage = "workflow:send_message"
+	TypeRetryAction = "workflow:retry_action"
+	TypeProcessExportBundles = "accounting:process_export_bundles"
+	TypeComputeOrganizationUsage = "organizations:compute_usage"
+	TypeComputeBenchmarks = "benchmarks:compute"
+	TypeSendFollowUps = "followups:send_due"
+	TypeScheduleLogArchives = "workflow:schedule_log_archives"
+	TypeProcessLogArchives = "workflow:process_log_archives"
+	TypeProcessOrganizationMerges = "organizations:process_merges"
+	TypeProcessDataExports = "exports:process"
+	TypeCleanupDataExports = "exports:cleanup"
+	TypeAnalyzeAdminActivity = "admin:analyze_activity"
+	TypeProcessOrganizationDeletions = "organizations:process_deletions"
+	TypeGenerateSyntheticTraffic = "synthetic:generate"
 )
 
 // SendNotificationPayload contains data for sending a notification
@@ -21,6 +43,18 @@ type SendNotificationPayload struct {
 	TemplateID     uuid.UUID `json:"template_id"`
 }
 
+// SendMessagePayload contains a rendered message held back by the rate limiter
+type SendMessagePayload struct {
+	OrganizationID   uuid.UUID         `json:"organization_id"`
+	Channel          string            `json:"channel"` // "whatsapp" or "email"
+	To               string            `json:"to"`
+	Subject          string            `json:"subject,omitempty"`
+	Body             string            `json:"body"`
+	Critical         bool              `json:"critical,omitempty"`
+	ContentSID       string            `json:"content_sid,omitempty"` // approved WhatsApp template sent instead of Body
+	ContentVariables map[string]string `json:"content_variables,omitempty"`
+}
+
 // ExecuteTriggerPayload contains data for executing a workflow trigger
 type ExecuteTriggerPayload struct {
 	OrganizationID uuid.UUID `json:"organization_id"`
@@ -29,5 +63,51 @@ type ExecuteTriggerPayload struct {
 	EntityID       uuid.UUID `json:"entity_id"`
 }
 
+// RetryActionPayload contains data for retrying a failed workflow action
+type RetryActionPayload struct {
+	OrganizationID   uuid.UUID  `json:"organization_id"`
+	WorkflowID       uuid.UUID  `json:"workflow_id"`
+	TriggerID        uuid.UUID  `json:"trigger_id"`
+	ActionID         uuid.UUID  `json:"action_id"`
+	EntityType       string     `json:"entity_type"`
+	EntityID         uuid.UUID  `json:"entity_id"`
+	BackoffSeconds   int        `json:"backoff_seconds"`
+	PreviousAttempts int        `json:"previous_attempts"`
+	DeadLetterID     *uuid.UUID `json:"dead_letter_id,omitempty"`
+}
+
+// ExecuteBulkRunPayload identifies a bulk trigger run to fan out
+type ExecuteBulkRunPayload struct {
+	RunID uuid.UUID `json:"run_id"`
+}
+
+// ExecuteBulkRunItemPayload contains data for executing a trigger for one recipient of a bulk run
+type ExecuteBulkRunItemPayload struct {
+	OrganizationID uuid.UUID `json:"organization_id"`
+	RunID          uuid.UUID `json:"run_id"`
+	ItemID         uuid.UUID `json:"item_id"`
+	TriggerID      uuid.UUID `json:"trigger_id"`
+	EntityType     string    `json:"entity_type"`
+	EntityID       uuid.UUID `json:"entity_id"`
+}
+
 // CheckTimeTriggersPayload is empty - used for periodic job
 type CheckTimeTriggersPayload struct{}
+
+// CheckComplianceDeadlinesPayload is empty - used for periodic job
+type CheckComplianceDeadlinesPayload struct{}
+
+// CheckTaskDeadlinesPayload is empty - used for periodic job
+type CheckTaskDeadlinesPayload struct{}
+
+// CheckTreatmentPlanReviewsPayload is empty - used for periodic job
+type CheckTreatmentPlanReviewsPayload struct{}
+
+// ExpireBudgetsPayload is empty - used for periodic job
+type ExpireBudgetsPayload struct{}
+
+// MarkOverduePaymentsPayload is empty - used for periodic job
+type MarkOverduePaymentsPayload struct{}
+
+// CheckStatusConsistencyPayload is empty - used for periodic job
+type CheckStatusConsistencyPayload struct{}
@@ -68,6 +68,12 @@ func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 			return
 		}
 
+		// Patient portal tokens only open the patient portal
+		if isPatientPortalToken(claims) {
+			utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid or expired token")
+			return
+		}
+
 		ctx := r.Context()
 
 		// Check if this is a system admin token
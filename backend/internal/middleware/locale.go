@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/controlwise/backend/internal/i18n"
+)
+
+// Locale negotiates the response language from Accept-Language. The locale is stored in
+// the request context for services and carried by the response writer so API messages
+// are translated when written.
+func Locale(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locale := i18n.Negotiate(r.Header.Get("Accept-Language"), i18n.DefaultLocale)
+
+		w.Header().Set("Content-Language", locale)
+		w.Header().Add("Vary", "Accept-Language")
+
+		ctx := i18n.WithLocale(r.Context(), locale)
+		next.ServeHTTP(i18n.NewResponseWriter(w, locale), r.WithContext(ctx))
+	})
+}
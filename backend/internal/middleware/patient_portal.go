@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// PatientIDKey holds the patient signed in to the patient portal
+const PatientIDKey contextKey = "patient_id"
+
+// AuthenticatePatient accepts only patient portal tokens, and puts the patient and their
+// organization in the context
+func (m *AuthMiddleware) AuthenticatePatient(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(r.Header.Get("Authorization"), " ")
+		if len(parts) != 2 || parts[0] != "Bearer" {
+			utils.ErrorResponse(w, http.StatusUnauthorized, "Missing authorization header")
+			return
+		}
+
+		token, err := jwt.Parse(parts[1], func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, jwt.ErrSignatureInvalid
+			}
+			return []byte(m.jwtSecret), nil
+		}, jwt.WithAudience(services.PatientPortalAudience), jwt.WithExpirationRequired())
+		if err != nil || !token.Valid {
+			utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid or expired token")
+			return
+		}
+
+		claims, ok := token.Claims.(jwt.MapClaims)
+		if !ok {
+			utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid token claims")
+			return
+		}
+
+		patientIDStr, _ := claims["patient_id"].(string)
+		patientID, err := uuid.Parse(patientIDStr)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid patient ID in token")
+			return
+		}
+		orgIDStr, _ := claims["organization_id"].(string)
+		orgID, err := uuid.Parse(orgIDStr)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid organization ID in token")
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), PatientIDKey, patientID)
+		ctx = context.WithValue(ctx, OrganizationIDKey, orgID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// GetPatientID returns the patient signed in to the patient portal from context
+func GetPatientID(ctx context.Context) (uuid.UUID, bool) {
+	patientID, ok := ctx.Value(PatientIDKey).(uuid.UUID)
+	return patientID, ok
+}
+
+// isPatientPortalToken reports whether a token was issued for the patient portal
+func isPatientPortalToken(claims jwt.MapClaims) bool {
+	aud, err := claims.GetAudience()
+	if err != nil {
+		return true
+	}
+	for _, a := range aud {
+		if a == services.PatientPortalAudience {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/controlwise/backend/internal/services"
+	"github.com/controlwise/backend/internal/utils"
+)
+
+// SecurityAlertMiddleware holds back sensitive admin actions while the admin has security
+// alerts to justify
+type SecurityAlertMiddleware struct {
+	securityService *services.AdminSecurityService
+}
+
+// NewSecurityAlertMiddleware creates a new security alert middleware
+func NewSecurityAlertMiddleware(securityService *services.AdminSecurityService) *SecurityAlertMiddleware {
+	return &SecurityAlertMiddleware{securityService: securityService}
+}
+
+// RequireJustifiedAlerts blocks the request when the system admin has open security alerts
+func (m *SecurityAlertMiddleware) RequireJustifiedAlerts(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adminID, ok := GetSystemAdminID(r.Context())
+		if !ok {
+			utils.ErrorResponse(w, http.StatusUnauthorized, "Admin not found in context")
+			return
+		}
+
+		open, err := m.securityService.CountOpen(r.Context(), adminID)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to check security alerts")
+			return
+		}
+
+		if open > 0 {
+			utils.ErrorResponse(w, http.StatusForbidden, "Justify your open security alerts before performing this action")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// AccountantSummary holds a month's figures as seen from the accountant workspace
+type AccountantSummary struct {
+	Period           string                  `json:"period"` // YYYY-MM
+	PeriodStatus     FinancialPeriodStatus   `json:"period_status"`
+	Collected        FinancialPeriodSnapshot `json:"collected"`
+	ExpensesTotal    decimal.Decimal         `json:"expenses_total"`
+	ExpensesVAT      decimal.Decimal         `json:"expenses_vat"`
+	ExpensesCount    int                     `json:"expenses_count"`
+	DraftExpenses    int                     `json:"draft_expenses"`    // receipts still to be reviewed
+	UnmatchedCredits int                     `json:"unmatched_credits"` // bank credits without a payment
+}
+
+// AccountantEntryKind is the kind of record a ledger entry comes from
+type AccountantEntryKind string
+
+const (
+	AccountantEntryPayment        AccountantEntryKind = "payment"
+	AccountantEntrySessionPayment AccountantEntryKind = "session_payment"
+	AccountantEntryExpense        AccountantEntryKind = "expense"
+)
+
+// AccountantEntry is a money movement of a month: a payment received or a posted expense
+type AccountantEntry struct {
+	Kind       AccountantEntryKind `json:"kind"`
+	ID         uuid.UUID           `json:"id"`
+	Date       time.Time           `json:"date"`
+	Reference  string              `json:"reference"` // project number, session date or expense description
+	PartyID    *uuid.UUID          `json:"party_id"`  // the client, unset for vendors
+	Party      string              `json:"party"`     // client, patient or vendor
+	PartyTaxID *string             `json:"party_tax_id"`
+	Amount     decimal.Decimal     `json:"amount"`
+	VATAmount  *decimal.Decimal    `json:"vat_amount"`
+	Method     *string             `json:"method"`
+}
+
+// ExportBundleStatus represents the progress of an export bundle
+type ExportBundleStatus string
+
+const (
+	ExportBundlePending    ExportBundleStatus = "pending"
+	ExportBundleProcessing ExportBundleStatus = "processing"
+	ExportBundleReady      ExportBundleStatus = "ready"
+	ExportBundleFailed     ExportBundleStatus = "failed"
+)
+
+// ExportBundle is a month's accounting export, built in the background
+type ExportBundle struct {
+	ID             uuid.UUID          `json:"id" db:"id"`
+	OrganizationID uuid.UUID          `json:"organization_id" db:"organization_id"`
+	Period         string             `json:"period" db:"period"` // YYYY-MM
+	Status         ExportBundleStatus `json:"status" db:"status"`
+	SendEmail      bool               `json:"send_email" db:"send_email"`
+	FileURL        *string            `json:"-" db:"file_url"` // served through a download link
+	FileName       *string            `json:"file_name" db:"file_name"`
+	FileSize       *int64             `json:"file_size" db:"file_size"`
+	Error          *string            `json:"error" db:"error"`
+	RequestedBy    uuid.UUID          `json:"requested_by" db:"requested_by"`
+	CreatedAt      time.Time          `json:"created_at" db:"created_at"`
+	StartedAt      *time.Time         `json:"started_at" db:"started_at"`
+	CompletedAt    *time.Time         `json:"completed_at" db:"completed_at"`
+}
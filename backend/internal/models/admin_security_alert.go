@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SecurityAlertRule identifies the unusual admin behavior an alert was raised for
+type SecurityAlertRule string
+
+const (
+	SecurityAlertMassExport            SecurityAlertRule = "mass_export"
+	SecurityAlertOffHoursImpersonation SecurityAlertRule = "off_hours_impersonation"
+	SecurityAlertBulkDeletion          SecurityAlertRule = "bulk_deletion"
+)
+
+// SecurityAlertStatus represents whether an alert still awaits its justification
+type SecurityAlertStatus string
+
+const (
+	SecurityAlertStatusOpen      SecurityAlertStatus = "open"
+	SecurityAlertStatusJustified SecurityAlertStatus = "justified"
+	SecurityAlertStatusDismissed SecurityAlertStatus = "dismissed" // closed by another admin
+)
+
+// AdminSecurityAlert flags audit log entries of a system admin that look suspicious
+type AdminSecurityAlert struct {
+	ID            uuid.UUID              `json:"id" db:"id"`
+	AdminID       uuid.UUID              `json:"admin_id" db:"admin_id"`
+	Rule          SecurityAlertRule      `json:"rule" db:"rule"`
+	Severity      string                 `json:"severity" db:"severity"` // medium or high
+	Details       map[string]interface{} `json:"details" db:"details"`
+	AuditLogIDs   []uuid.UUID            `json:"audit_log_ids" db:"audit_log_ids"`
+	WindowStart   time.Time              `json:"window_start" db:"window_start"`
+	WindowEnd     time.Time              `json:"window_end" db:"window_end"`
+	Status        SecurityAlertStatus    `json:"status" db:"status"`
+	Justification *string                `json:"justification" db:"justification"`
+	JustifiedAt   *time.Time             `json:"justified_at" db:"justified_at"`
+	ReviewedBy    *uuid.UUID             `json:"reviewed_by" db:"reviewed_by"`
+	ReviewedAt    *time.Time             `json:"reviewed_at" db:"reviewed_at"`
+	CreatedAt     time.Time              `json:"created_at" db:"created_at"`
+
+	// Joined
+	AdminName  string `json:"admin_name,omitempty" db:"-"`
+	AdminEmail string `json:"admin_email,omitempty" db:"-"`
+}
@@ -32,6 +32,8 @@ type PatientWithClient struct {
 	ClientName  string  `json:"client_name" db:"client_name"`
 	ClientEmail string  `json:"client_email" db:"client_email"`
 	ClientPhone string  `json:"client_phone" db:"client_phone"`
+	// EmailSuppression is set when the client's email bounced or complained
+	EmailSuppression *EmailSuppression `json:"email_suppression,omitempty" db:"-"`
 }
 
 // Therapist represents a therapist/service provider in the appointments module
@@ -104,6 +106,15 @@ const (
 	SessionTypeFollowUp   SessionType = "follow_up"
 )
 
+// SessionOverrideStatus represents the approval status of a double-booking override
+type SessionOverrideStatus string
+
+const (
+	SessionOverridePending  SessionOverrideStatus = "pending"
+	SessionOverrideApproved SessionOverrideStatus = "approved"
+	SessionOverrideRejected SessionOverrideStatus = "rejected"
+)
+
 // Session represents an appointment/session
 type Session struct {
 	ID              uuid.UUID     `json:"id" db:"id"`
@@ -124,6 +135,20 @@ type Session struct {
 	CreatedAt       time.Time     `json:"created_at" db:"created_at"`
 	UpdatedAt       time.Time     `json:"updated_at" db:"updated_at"`
 	DeletedAt       *time.Time    `json:"deleted_at,omitempty" db:"deleted_at"`
+
+	// Double-booking override: set when the session was booked over a conflicting session on purpose
+	ConflictOverride bool                   `json:"conflict_override" db:"conflict_override"`
+	OverrideReason   *string                `json:"override_reason,omitempty" db:"override_reason"`
+	OverrideStatus   *SessionOverrideStatus `json:"override_status,omitempty" db:"override_status"`
+
+	// Catalogue service the session was booked for
+	ServiceID *uuid.UUID `json:"service_id,omitempty" db:"service_id"`
+
+	// Recurring series the session was created in
+	SeriesID *uuid.UUID `json:"series_id,omitempty" db:"series_id"`
+
+	// Construction project the session is linked to, e.g. an on-site consultation
+	ProjectID *uuid.UUID `json:"project_id,omitempty" db:"project_id"`
 }
 
 // SessionWithDetails includes therapist and patient information
@@ -0,0 +1,24 @@
+package models
+
+import "github.com/google/uuid"
+
+// AssignmentStrategyLeastLoaded picks the eligible candidate with the lowest current load
+const AssignmentStrategyLeastLoaded = "least_loaded"
+
+// AssignmentCandidate is a person considered for auto-assignment and why they were or were not picked
+type AssignmentCandidate struct {
+	ID       uuid.UUID `json:"id"` // therapist ID for sessions, user ID for tasks
+	Name     string    `json:"name"`
+	Eligible bool      `json:"eligible"`
+	Load     int       `json:"load"` // booked minutes in the week for sessions, weighted open tasks for tasks
+	Reasons  []string  `json:"reasons"`
+}
+
+// AssignmentDecision explains an auto-assignment: the selected person and every candidate considered
+type AssignmentDecision struct {
+	EntityType   string                `json:"entity_type"` // "session" or "task"
+	Strategy     string                `json:"strategy"`
+	SelectedID   *uuid.UUID            `json:"selected_id"`
+	SelectedName string                `json:"selected_name,omitempty"`
+	Candidates   []AssignmentCandidate `json:"candidates"`
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// BankStatementSource is where a statement came from
+type BankStatementSource string
+
+const (
+	BankStatementSourceCSV        BankStatementSource = "csv"
+	BankStatementSourceCAMT       BankStatementSource = "camt"
+	BankStatementSourceAggregator BankStatementSource = "aggregator"
+)
+
+// BankStatementLineStatus represents whether a statement line was reconciled
+type BankStatementLineStatus string
+
+const (
+	BankLineUnmatched BankStatementLineStatus = "unmatched"
+	BankLineMatched   BankStatementLineStatus = "matched"
+	// BankLineIgnored lines need no match, such as bank fees
+	BankLineIgnored BankStatementLineStatus = "ignored"
+)
+
+// BankMatchKind is the kind of record a statement line is matched to
+type BankMatchKind string
+
+const (
+	BankMatchPayment        BankMatchKind = "payment"
+	BankMatchSessionPayment BankMatchKind = "session_payment"
+)
+
+// BankStatement is an imported bank statement
+type BankStatement struct {
+	ID             uuid.UUID           `json:"id" db:"id"`
+	OrganizationID uuid.UUID           `json:"organization_id" db:"organization_id"`
+	Source         BankStatementSource `json:"source" db:"source"`
+	AccountIBAN    *string             `json:"account_iban" db:"account_iban"`
+	FileName       *string             `json:"file_name" db:"file_name"`
+	PeriodFrom     *time.Time          `json:"period_from" db:"period_from"`
+	PeriodTo       *time.Time          `json:"period_to" db:"period_to"`
+	LineCount      int                 `json:"line_count" db:"line_count"`
+	DuplicateCount int                 `json:"duplicate_count" db:"duplicate_count"` // lines already imported
+	ImportedBy     *uuid.UUID          `json:"imported_by" db:"imported_by"`
+	CreatedAt      time.Time           `json:"created_at" db:"created_at"`
+
+	// Computed
+	MatchedCount   int `json:"matched_count" db:"matched_count"`
+	UnmatchedCount int `json:"unmatched_count" db:"unmatched_count"`
+}
+
+// BankStatementLine is a transaction of a statement
+type BankStatementLine struct {
+	ID                      uuid.UUID               `json:"id" db:"id"`
+	StatementID             uuid.UUID               `json:"statement_id" db:"statement_id"`
+	OrganizationID          uuid.UUID               `json:"organization_id" db:"organization_id"`
+	ExternalID              string                  `json:"external_id" db:"external_id"`
+	BookingDate             time.Time               `json:"booking_date" db:"booking_date"`
+	ValueDate               *time.Time              `json:"value_date" db:"value_date"`
+	Amount                  decimal.Decimal         `json:"amount" db:"amount"` // credits positive, debits negative
+	Currency                string                  `json:"currency" db:"currency"`
+	Description             *string                 `json:"description" db:"description"`
+	Reference               *string                 `json:"reference" db:"reference"`
+	CounterpartyName        *string                 `json:"counterparty_name" db:"counterparty_name"`
+	CounterpartyIBAN        *string                 `json:"counterparty_iban" db:"counterparty_iban"`
+	Status                  BankStatementLineStatus `json:"status" db:"status"`
+	MatchedPaymentID        *uuid.UUID              `json:"matched_payment_id" db:"matched_payment_id"`
+	MatchedSessionPaymentID *uuid.UUID              `json:"matched_session_payment_id" db:"matched_session_payment_id"`
+	MatchScore              *int                    `json:"match_score" db:"match_score"`
+	AutoMatched             bool                    `json:"auto_matched" db:"auto_matched"`
+	MatchedBy               *uuid.UUID              `json:"matched_by" db:"matched_by"`
+	MatchedAt               *time.Time              `json:"matched_at" db:"matched_at"`
+	CreatedAt               time.Time               `json:"created_at" db:"created_at"`
+}
+
+// BankMatchCandidate is an open payment suggested for a statement line, best first
+type BankMatchCandidate struct {
+	Kind         BankMatchKind   `json:"kind"`
+	ID           uuid.UUID       `json:"id"`
+	Amount       decimal.Decimal `json:"amount"`
+	DueDate      *time.Time      `json:"due_date"`
+	Reference    *string         `json:"reference"`
+	Label        string          `json:"label"` // project number and title, or patient and session date
+	Counterparty string          `json:"counterparty"`
+	Score        int             `json:"score"` // 0-100
+	Reasons      []string        `json:"reasons"`
+}
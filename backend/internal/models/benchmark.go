@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BenchmarkMetric is an aggregate metric organizations are compared on
+type BenchmarkMetric string
+
+const (
+	BenchmarkNoShowRate           BenchmarkMetric = "no_show_rate"           // % of attended or missed sessions missed
+	BenchmarkBudgetConversionRate BenchmarkMetric = "budget_conversion_rate" // % of decided budgets approved
+	BenchmarkResponseTimeHours    BenchmarkMetric = "response_time_hours"    // hours until staff answer a WhatsApp message
+)
+
+// BenchmarkMetrics lists the metrics in the order they are reported
+var BenchmarkMetrics = []BenchmarkMetric{
+	BenchmarkNoShowRate,
+	BenchmarkBudgetConversionRate,
+	BenchmarkResponseTimeHours,
+}
+
+// BenchmarkParticipation is whether an organization shares its metrics for benchmarking
+type BenchmarkParticipation struct {
+	OptedIn   bool       `json:"opted_in"`
+	OptedInBy *uuid.UUID `json:"opted_in_by"`
+	OptedInAt *time.Time `json:"opted_in_at"`
+}
+
+// BenchmarkBands are the percentiles of a metric across the participating organizations
+type BenchmarkBands struct {
+	P10 float64 `json:"p10"`
+	P25 float64 `json:"p25"`
+	P50 float64 `json:"p50"`
+	P75 float64 `json:"p75"`
+	P90 float64 `json:"p90"`
+}
+
+// BenchmarkComparison places an organization's value of a metric among the participants
+type BenchmarkComparison struct {
+	Metric BenchmarkMetric `json:"metric"`
+	// The organization's own value; nil while it has too little activity to measure
+	Value      *float64 `json:"value"`
+	SampleSize int      `json:"sample_size"`
+	// Bands are only given once enough organizations take part to keep each one anonymous
+	Participants int             `json:"participants"`
+	Bands        *BenchmarkBands `json:"bands"`
+	// Share of participants the organization does better than, 0-100
+	Percentile *int `json:"percentile"`
+	// Whether a lower value is better, as for no-shows and response times
+	LowerIsBetter bool `json:"lower_is_better"`
+}
+
+// BenchmarkReport compares an organization to the anonymized participants
+type BenchmarkReport struct {
+	PeriodStart *time.Time             `json:"period_start"`
+	PeriodEnd   *time.Time             `json:"period_end"`
+	Metrics     []*BenchmarkComparison `json:"metrics"`
+}
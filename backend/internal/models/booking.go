@@ -0,0 +1,103 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BookableService is an entry of the appointments service catalogue
+type BookableService struct {
+	ID              uuid.UUID   `json:"id" db:"id"`
+	OrganizationID  uuid.UUID   `json:"organization_id" db:"organization_id"`
+	Name            string      `json:"name" db:"name"`
+	Description     *string     `json:"description" db:"description"`
+	DurationMinutes int         `json:"duration_minutes" db:"duration_minutes"`
+	PriceCents      int         `json:"price_cents" db:"price_cents"`
+	SessionType     SessionType `json:"session_type" db:"session_type"`
+	IsPublic        bool        `json:"is_public" db:"is_public"`
+	IsActive        bool        `json:"is_active" db:"is_active"`
+	Position        int         `json:"position" db:"position"`
+	TherapistIDs    []uuid.UUID `json:"therapist_ids" db:"-"` // empty means every therapist
+	CreatedAt       time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at" db:"updated_at"`
+	DeletedAt       *time.Time  `json:"deleted_at,omitempty" db:"deleted_at"`
+}
+
+// PublicBookableService is the part of a catalogue entry shown to the public
+type PublicBookableService struct {
+	ID              uuid.UUID `json:"id"`
+	Name            string    `json:"name"`
+	Description     *string   `json:"description"`
+	DurationMinutes int       `json:"duration_minutes"`
+	PriceCents      int       `json:"price_cents"`
+}
+
+// BookingSlot is a free time slot for a service with a given therapist
+type BookingSlot struct {
+	Start         time.Time `json:"start"`
+	End           time.Time `json:"end"`
+	TherapistID   uuid.UUID `json:"therapist_id"`
+	TherapistName string    `json:"therapist_name"`
+}
+
+// AvailabilityConflictKind is why a requested session time is not available
+type AvailabilityConflictKind string
+
+const (
+	AvailabilityConflictSession      AvailabilityConflictKind = "session"       // overlaps another session
+	AvailabilityConflictAbsence      AvailabilityConflictKind = "absence"       // the therapist is out of office
+	AvailabilityConflictWorkingHours AvailabilityConflictKind = "working_hours" // outside the therapist's hours
+	AvailabilityConflictService      AvailabilityConflictKind = "service"       // the therapist does not provide the service
+	AvailabilityConflictPast         AvailabilityConflictKind = "past"
+)
+
+// AvailabilityConflict is one reason a requested session time is not available
+type AvailabilityConflict struct {
+	Kind        AvailabilityConflictKind `json:"kind"`
+	Message     string                   `json:"message"`
+	Start       *time.Time               `json:"start,omitempty"`
+	End         *time.Time               `json:"end,omitempty"`
+	SessionID   *uuid.UUID               `json:"session_id,omitempty"`
+	PatientName *string                  `json:"patient_name,omitempty"`
+	Status      *SessionStatus           `json:"status,omitempty"`
+}
+
+// SessionAvailability is the result of checking a session time before booking it
+type SessionAvailability struct {
+	Available       bool                   `json:"available"`
+	TherapistID     uuid.UUID              `json:"therapist_id"`
+	Start           time.Time              `json:"start"`
+	End             time.Time              `json:"end"`
+	Conflicts       []AvailabilityConflict `json:"conflicts"`
+	NearestSlots    []BookingSlot          `json:"nearest_slots"`    // free times of the same therapist
+	OtherTherapists []BookingSlot          `json:"other_therapists"` // therapists free at the requested time
+}
+
+// BookingEmbedSettings are the websites allowed to embed an organization's public availability
+type BookingEmbedSettings struct {
+	AllowedOrigins []string `json:"allowed_origins"` // empty allows any website
+	Snippet        string   `json:"snippet"`         // HTML to paste on the website
+}
+
+// EmbedSlot is a free slot shown by a booking widget, linking to the public booking page
+type EmbedSlot struct {
+	BookingSlot
+	BookingURL string `json:"booking_url"`
+}
+
+// EmbedService is a public service with its free slots
+type EmbedService struct {
+	PublicBookableService
+	BookingURL string      `json:"booking_url"`
+	Slots      []EmbedSlot `json:"slots"`
+}
+
+// EmbedAvailability is the read-only availability served to booking widgets on customer websites
+type EmbedAvailability struct {
+	OrganizationID   uuid.UUID      `json:"organization_id"`
+	OrganizationName string         `json:"organization_name"`
+	From             time.Time      `json:"from"`
+	To               time.Time      `json:"to"`
+	Services         []EmbedService `json:"services"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// BudgetApprovalRule requires internal sign-off from a role for budgets at or above a total
+type BudgetApprovalRule struct {
+	ID             uuid.UUID       `json:"id" db:"id"`
+	OrganizationID uuid.UUID       `json:"organization_id" db:"organization_id"`
+	Name           string          `json:"name" db:"name"`
+	MinTotal       decimal.Decimal `json:"min_total" db:"min_total"`
+	RequiredRole   Role            `json:"required_role" db:"required_role"`
+	IsActive       bool            `json:"is_active" db:"is_active"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// BudgetInternalApprovalStatus represents the state of a sign-off request
+type BudgetInternalApprovalStatus string
+
+const (
+	InternalApprovalPending   BudgetInternalApprovalStatus = "pending"
+	InternalApprovalApproved  BudgetInternalApprovalStatus = "approved"
+	InternalApprovalRejected  BudgetInternalApprovalStatus = "rejected"
+	InternalApprovalCancelled BudgetInternalApprovalStatus = "cancelled"
+)
+
+// BudgetInternalApproval is a sign-off request for a budget from a required role
+type BudgetInternalApproval struct {
+	ID           uuid.UUID                    `json:"id" db:"id"`
+	BudgetID     uuid.UUID                    `json:"budget_id" db:"budget_id"`
+	RuleID       *uuid.UUID                   `json:"rule_id" db:"rule_id"`
+	RequiredRole Role                         `json:"required_role" db:"required_role"`
+	Status       BudgetInternalApprovalStatus `json:"status" db:"status"`
+	RequestedBy  uuid.UUID                    `json:"requested_by" db:"requested_by"`
+	DecidedBy    *uuid.UUID                   `json:"decided_by" db:"decided_by"`
+	DecidedAt    *time.Time                   `json:"decided_at" db:"decided_at"`
+	Comment      *string                      `json:"comment" db:"comment"`
+	CreatedAt    time.Time                    `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time                    `json:"updated_at" db:"updated_at"`
+}
+
+// BudgetApprovalAction is a step recorded in the internal approval audit trail
+type BudgetApprovalAction string
+
+const (
+	BudgetApprovalActionSubmitted BudgetApprovalAction = "submitted"
+	BudgetApprovalActionApproved  BudgetApprovalAction = "approved"
+	BudgetApprovalActionRejected  BudgetApprovalAction = "rejected"
+	BudgetApprovalActionReleased  BudgetApprovalAction = "released"
+)
+
+// BudgetApprovalAudit records who did what during the internal approval of a budget
+type BudgetApprovalAudit struct {
+	ID             uuid.UUID            `json:"id" db:"id"`
+	OrganizationID uuid.UUID            `json:"organization_id" db:"organization_id"`
+	BudgetID       uuid.UUID            `json:"budget_id" db:"budget_id"`
+	ApprovalID     *uuid.UUID           `json:"approval_id" db:"approval_id"`
+	UserID         *uuid.UUID           `json:"user_id" db:"user_id"`
+	UserName       *string              `json:"user_name,omitempty" db:"user_name"`
+	Action         BudgetApprovalAction `json:"action" db:"action"`
+	Comment        *string              `json:"comment" db:"comment"`
+	Details        json.RawMessage      `json:"details,omitempty" db:"details"`
+	CreatedAt      time.Time            `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BudgetPDFLayout controls the spacing of budget PDFs
+type BudgetPDFLayout string
+
+const (
+	BudgetPDFLayoutClassic BudgetPDFLayout = "classic"
+	BudgetPDFLayoutCompact BudgetPDFLayout = "compact" // smaller type, fits more items per page
+)
+
+// BudgetPDFTemplate is an organization's branding for budget PDFs
+type BudgetPDFTemplate struct {
+	ID             *uuid.UUID      `json:"id" db:"id"` // nil while the defaults are in use
+	OrganizationID uuid.UUID       `json:"organization_id" db:"organization_id"`
+	Layout         BudgetPDFLayout `json:"layout" db:"layout"`
+	AccentColor    string          `json:"accent_color" db:"accent_color"` // #RRGGBB
+	Title          *string         `json:"title" db:"title"`
+	HeaderText     *string         `json:"header_text" db:"header_text"`
+	FooterText     *string         `json:"footer_text" db:"footer_text"`
+	Terms          *string         `json:"terms" db:"terms"`
+	ShowLogo       bool            `json:"show_logo" db:"show_logo"`
+	ShowItemTax    bool            `json:"show_item_tax" db:"show_item_tax"`
+	ShowSignature  bool            `json:"show_signature" db:"show_signature"`
+	UpdatedAt      *time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// BudgetPDF is a generated budget PDF and a short-lived link to download it
+type BudgetPDF struct {
+	BudgetID    uuid.UUID `json:"budget_id"`
+	FileName    string    `json:"file_name"`
+	DownloadURL string    `json:"download_url"`
+	GeneratedAt time.Time `json:"generated_at"`
+	ExpiresAt   time.Time `json:"expires_at"` // of the link; the file is kept
+}
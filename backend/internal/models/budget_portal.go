@@ -0,0 +1,90 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// BudgetPortalAction is something the client did through the budget portal
+type BudgetPortalAction string
+
+const (
+	BudgetPortalViewed   BudgetPortalAction = "viewed"
+	BudgetPortalApproved BudgetPortalAction = "approved"
+	BudgetPortalRejected BudgetPortalAction = "rejected"
+)
+
+// BudgetPortalEvent records the client's activity on a budget portal link
+type BudgetPortalEvent struct {
+	ID        uuid.UUID          `json:"id" db:"id"`
+	BudgetID  uuid.UUID          `json:"budget_id" db:"budget_id"`
+	Action    BudgetPortalAction `json:"action" db:"action"`
+	Comment   *string            `json:"comment" db:"comment"`
+	IPAddress *string            `json:"ip_address" db:"ip_address"`
+	UserAgent *string            `json:"user_agent" db:"user_agent"`
+	CreatedAt time.Time          `json:"created_at" db:"created_at"`
+}
+
+// BudgetPortalLink is the client's link to a budget and what they did with it
+type BudgetPortalLink struct {
+	BudgetID    uuid.UUID            `json:"budget_id"`
+	URL         string               `json:"url"`
+	ApprovalURL string               `json:"approval_url"`
+	FirstViewed *time.Time           `json:"first_viewed_at"`
+	Events      []*BudgetPortalEvent `json:"events"`
+}
+
+// BudgetPortalItem is a line of a budget as shown to the client
+type BudgetPortalItem struct {
+	ID          uuid.UUID            `json:"id"`
+	Description string               `json:"description"`
+	Quantity    float64              `json:"quantity"`
+	Unit        string               `json:"unit"`
+	UnitPrice   decimal.Decimal      `json:"unit_price"`
+	Tax         decimal.Decimal      `json:"tax"`
+	Total       decimal.Decimal      `json:"total"`
+	Comments    []*BudgetItemComment `json:"comments"` // the line's question thread, oldest first
+}
+
+// BudgetPortalView is a budget as shown to the client through the portal
+type BudgetPortalView struct {
+	OrganizationName  string             `json:"organization_name"`
+	OrganizationEmail string             `json:"organization_email"`
+	OrganizationPhone *string            `json:"organization_phone"`
+	BudgetNumber      string             `json:"budget_number"`
+	Status            BudgetStatus       `json:"status"`
+	ClientName        string             `json:"client_name"`
+	ProjectName       string             `json:"project_name"`
+	Description       string             `json:"description"`
+	Subtotal          decimal.Decimal    `json:"subtotal"`
+	Tax               decimal.Decimal    `json:"tax"`
+	Total             decimal.Decimal    `json:"total"`
+	Notes             *string            `json:"notes"`
+	SentAt            *time.Time         `json:"sent_at"`
+	ValidUntil        time.Time          `json:"valid_until"`
+	Items             []BudgetPortalItem `json:"items"`
+	CanRespond        bool               `json:"can_respond"` // sent and still valid
+	RespondedAt       *time.Time         `json:"responded_at"`
+}
+
+// BudgetCommentAuthor is who wrote a budget line-item comment
+type BudgetCommentAuthor string
+
+const (
+	BudgetCommentByClient BudgetCommentAuthor = "client"
+	BudgetCommentByStaff  BudgetCommentAuthor = "staff"
+)
+
+// BudgetItemComment is a client question or a staff answer on a budget line
+type BudgetItemComment struct {
+	ID           uuid.UUID           `json:"id" db:"id"`
+	BudgetID     uuid.UUID           `json:"budget_id" db:"budget_id"`
+	BudgetItemID uuid.UUID           `json:"budget_item_id" db:"budget_item_id"`
+	AuthorType   BudgetCommentAuthor `json:"author_type" db:"author_type"`
+	UserID       *uuid.UUID          `json:"user_id" db:"user_id"` // set for staff answers
+	AuthorName   string              `json:"author_name"`
+	Body         string              `json:"body" db:"body"`
+	CreatedAt    time.Time           `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// BudgetRevisionItem is a budget item as it was when a revision was sent
+type BudgetRevisionItem struct {
+	WorkSheetItemID *uuid.UUID      `json:"worksheet_item_id"`
+	Description     string          `json:"description"`
+	Quantity        decimal.Decimal `json:"quantity"`
+	Unit            string          `json:"unit"`
+	UnitPrice       decimal.Decimal `json:"unit_price"`
+	Tax             decimal.Decimal `json:"tax"`
+	Total           decimal.Decimal `json:"total"`
+	Order           int             `json:"order"`
+}
+
+// BudgetRevision is a budget as it was sent to the client. Revision 1 is the first send; each
+// later send of a changed budget adds the next revision.
+type BudgetRevision struct {
+	ID             uuid.UUID            `json:"id" db:"id"`
+	BudgetID       uuid.UUID            `json:"budget_id" db:"budget_id"`
+	RevisionNumber int                  `json:"revision_number" db:"revision_number"`
+	Subtotal       decimal.Decimal      `json:"subtotal" db:"subtotal"`
+	Tax            decimal.Decimal      `json:"tax" db:"tax"`
+	Total          decimal.Decimal      `json:"total" db:"total"`
+	ValidUntil     time.Time            `json:"valid_until" db:"valid_until"`
+	Notes          *string              `json:"notes" db:"notes"`
+	Items          []BudgetRevisionItem `json:"items" db:"items"`
+	SentBy         *uuid.UUID           `json:"sent_by" db:"sent_by"`
+	CreatedAt      time.Time            `json:"created_at" db:"created_at"`
+}
+
+// BudgetItemChangeType is how an item changed between two revisions
+type BudgetItemChangeType string
+
+const (
+	BudgetItemAdded   BudgetItemChangeType = "added"
+	BudgetItemRemoved BudgetItemChangeType = "removed"
+	BudgetItemChanged BudgetItemChangeType = "changed"
+)
+
+// BudgetItemChange is an item that differs between two revisions
+type BudgetItemChange struct {
+	Change      BudgetItemChangeType `json:"change"`
+	Description string               `json:"description"`
+	From        *BudgetRevisionItem  `json:"from,omitempty"`
+	To          *BudgetRevisionItem  `json:"to,omitempty"`
+	Fields      []string             `json:"fields,omitempty"` // changed fields, e.g. quantity, unit_price
+	TotalDelta  decimal.Decimal      `json:"total_delta"`
+}
+
+// BudgetRevisionDiff lists the item changes and total delta between two revisions of a budget
+type BudgetRevisionDiff struct {
+	BudgetID      uuid.UUID          `json:"budget_id"`
+	FromRevision  int                `json:"from_revision"`
+	ToRevision    int                `json:"to_revision"`
+	Items         []BudgetItemChange `json:"items"`
+	SubtotalDelta decimal.Decimal    `json:"subtotal_delta"`
+	TaxDelta      decimal.Decimal    `json:"tax_delta"`
+	TotalDelta    decimal.Decimal    `json:"total_delta"`
+}
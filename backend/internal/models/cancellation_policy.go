@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// CancellationPolicyRule charges a fee for sessions cancelled less than WindowHours before they start
+type CancellationPolicyRule struct {
+	ID             uuid.UUID       `json:"id" db:"id"`
+	OrganizationID uuid.UUID       `json:"organization_id" db:"organization_id"`
+	Name           string          `json:"name" db:"name"`
+	WindowHours    int             `json:"window_hours" db:"window_hours"`
+	FeePercent     decimal.Decimal `json:"fee_percent" db:"fee_percent"`
+	IsActive       bool            `json:"is_active" db:"is_active"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// CancellationOutcome is the result of applying the cancellation policy to a session
+type CancellationOutcome struct {
+	HoursBefore float64         `json:"hours_before"`
+	RuleID      *uuid.UUID      `json:"rule_id,omitempty"`
+	RuleName    string          `json:"rule_name,omitempty"`
+	FeePercent  decimal.Decimal `json:"fee_percent"`
+	FeeCents    int             `json:"fee_cents"`
+	Waived      bool            `json:"waived"`
+	Description string          `json:"description"` // shown to the patient in the cancellation message
+}
+
+// SessionPaymentKind distinguishes session payments from cancellation fees
+type SessionPaymentKind string
+
+const (
+	SessionPaymentKindSession         SessionPaymentKind = "session"
+	SessionPaymentKindCancellationFee SessionPaymentKind = "cancellation_fee"
+)
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CashRegisterStatus represents whether a register is taking payments
+type CashRegisterStatus string
+
+const (
+	CashRegisterOpen   CashRegisterStatus = "open"
+	CashRegisterClosed CashRegisterStatus = "closed"
+)
+
+// IsCashRegisterMethod reports whether takings of the payment method go through the register
+func IsCashRegisterMethod(method PaymentMethod) bool {
+	switch method {
+	case PaymentMethodCash, PaymentMethodCard, PaymentMethodMBWay:
+		return true
+	}
+	return false
+}
+
+// CashRegister is a clinic's register for a working day
+type CashRegister struct {
+	ID                uuid.UUID          `json:"id" db:"id"`
+	OrganizationID    uuid.UUID          `json:"organization_id" db:"organization_id"`
+	Status            CashRegisterStatus `json:"status" db:"status"`
+	OpeningFloatCents int                `json:"opening_float_cents" db:"opening_float_cents"`
+	OpenedBy          *uuid.UUID         `json:"opened_by" db:"opened_by"`
+	OpenedAt          time.Time          `json:"opened_at" db:"opened_at"`
+	CountedCashCents  *int               `json:"counted_cash_cents" db:"counted_cash_cents"`
+	CountedCardCents  *int               `json:"counted_card_cents" db:"counted_card_cents"`
+	CountedMBWayCents *int               `json:"counted_mbway_cents" db:"counted_mbway_cents"`
+	ClosedBy          *uuid.UUID         `json:"closed_by" db:"closed_by"`
+	ClosedAt          *time.Time         `json:"closed_at" db:"closed_at"`
+	Notes             *string            `json:"notes" db:"notes"`
+}
+
+// CashRegisterEntry is a taking recorded in a register
+type CashRegisterEntry struct {
+	ID               uuid.UUID     `json:"id" db:"id"`
+	CashRegisterID   uuid.UUID     `json:"cash_register_id" db:"cash_register_id"`
+	SessionPaymentID *uuid.UUID    `json:"session_payment_id" db:"session_payment_id"`
+	Method           PaymentMethod `json:"method" db:"method"`
+	AmountCents      int           `json:"amount_cents" db:"amount_cents"`
+	Description      *string       `json:"description" db:"description"`
+	RecordedBy       *uuid.UUID    `json:"recorded_by" db:"recorded_by"`
+	CreatedAt        time.Time     `json:"created_at" db:"created_at"`
+	// Joined data
+	PatientName *string `json:"patient_name,omitempty" db:"patient_name"`
+}
+
+// CashRegisterReconciliationLine compares the recorded and counted takings of a payment method
+type CashRegisterReconciliationLine struct {
+	Method          PaymentMethod `json:"method"`
+	ExpectedCents   int           `json:"expected_cents"` // recorded takings, plus the opening float for cash
+	CountedCents    *int          `json:"counted_cents"`  // nil until the register is closed
+	DifferenceCents int           `json:"difference_cents"`
+	Discrepancy     bool          `json:"discrepancy"`
+}
+
+// UnrecordedSessionPayment is a session paid by cash, card or MB Way while the register was open
+// without a matching register entry
+type UnrecordedSessionPayment struct {
+	SessionPaymentID uuid.UUID     `json:"session_payment_id"`
+	SessionID        uuid.UUID     `json:"session_id"`
+	PatientName      string        `json:"patient_name"`
+	Method           PaymentMethod `json:"method"`
+	AmountCents      int           `json:"amount_cents"`
+	PaidAt           time.Time     `json:"paid_at"`
+}
+
+// CashRegisterReconciliation is the end-of-day report of a register
+type CashRegisterReconciliation struct {
+	Register           *CashRegister                    `json:"register"`
+	Entries            []*CashRegisterEntry             `json:"entries"`
+	Lines              []CashRegisterReconciliationLine `json:"lines"`
+	TotalTakingsCents  int                              `json:"total_takings_cents"`
+	UnrecordedPayments []*UnrecordedSessionPayment      `json:"unrecorded_payments"`
+	HasDiscrepancies   bool                             `json:"has_discrepancies"`
+}
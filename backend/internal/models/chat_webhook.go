@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChatProvider is the chat service a team channel is on
+type ChatProvider string
+
+const (
+	ChatProviderSlack ChatProvider = "slack"
+	ChatProviderTeams ChatProvider = "teams"
+)
+
+// ChatWebhook is the incoming webhook of one of an organization's Slack or Teams channels
+type ChatWebhook struct {
+	ID                   uuid.UUID    `json:"id" db:"id"`
+	OrganizationID       uuid.UUID    `json:"organization_id" db:"organization_id"`
+	Provider             ChatProvider `json:"provider" db:"provider"`
+	Name                 string       `json:"name" db:"name"`
+	WebhookURLHint       string       `json:"webhook_url_hint" db:"webhook_url_hint"` // host and last characters of the URL
+	IsActive             bool         `json:"is_active" db:"is_active"`
+	NotifyActionFailures bool         `json:"notify_action_failures" db:"notify_action_failures"`
+	LastSentAt           *time.Time   `json:"last_sent_at" db:"last_sent_at"`
+	LastError            *string      `json:"last_error" db:"last_error"`
+	CreatedBy            *uuid.UUID   `json:"created_by" db:"created_by"`
+	CreatedAt            time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time    `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ComplianceRequirementType represents the kind of compliance requirement
+type ComplianceRequirementType string
+
+const (
+	ComplianceTypeLicense    ComplianceRequirementType = "license"
+	ComplianceTypeSafetyPlan ComplianceRequirementType = "safety_plan"
+	ComplianceTypeInsurance  ComplianceRequirementType = "insurance"
+	ComplianceTypeOther      ComplianceRequirementType = "other"
+)
+
+// ComplianceRequirement defines a checklist item required for projects of a category
+type ComplianceRequirement struct {
+	ID              uuid.UUID                 `json:"id" db:"id"`
+	OrganizationID  uuid.UUID                 `json:"organization_id" db:"organization_id"`
+	Category        *string                   `json:"category" db:"category"`
+	Name            string                    `json:"name" db:"name"`
+	Description     *string                   `json:"description" db:"description"`
+	RequirementType ComplianceRequirementType `json:"requirement_type" db:"requirement_type"`
+	DueOffsetDays   int                       `json:"due_offset_days" db:"due_offset_days"`
+	IsActive        bool                      `json:"is_active" db:"is_active"`
+	CreatedAt       time.Time                 `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time                 `json:"updated_at" db:"updated_at"`
+}
+
+// ComplianceItemStatus represents the status of a project compliance item
+type ComplianceItemStatus string
+
+const (
+	ComplianceItemPending   ComplianceItemStatus = "pending"
+	ComplianceItemCompleted ComplianceItemStatus = "completed"
+	ComplianceItemWaived    ComplianceItemStatus = "waived"
+)
+
+// ProjectComplianceItem is a compliance requirement applied to a specific project
+type ProjectComplianceItem struct {
+	ID              uuid.UUID                 `json:"id" db:"id"`
+	ProjectID       uuid.UUID                 `json:"project_id" db:"project_id"`
+	RequirementID   *uuid.UUID                `json:"requirement_id" db:"requirement_id"`
+	Name            string                    `json:"name" db:"name"`
+	RequirementType ComplianceRequirementType `json:"requirement_type" db:"requirement_type"`
+	DueDate         time.Time                 `json:"due_date" db:"due_date"`
+	Status          ComplianceItemStatus      `json:"status" db:"status"`
+	DocumentURL     *string                   `json:"document_url" db:"document_url"`
+	CompletedAt     *time.Time                `json:"completed_at" db:"completed_at"`
+	CompletedBy     *uuid.UUID                `json:"completed_by" db:"completed_by"`
+	WaiverReason    *string                   `json:"waiver_reason" db:"waiver_reason"`
+	WaivedBy        *uuid.UUID                `json:"waived_by" db:"waived_by"`
+	WaivedAt        *time.Time                `json:"waived_at" db:"waived_at"`
+	EscalatedAt     *time.Time                `json:"escalated_at" db:"escalated_at"`
+	CreatedAt       time.Time                 `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time                 `json:"updated_at" db:"updated_at"`
+}
+
+// IsOverdue returns true if the item is still pending after its due date
+func (i *ProjectComplianceItem) IsOverdue(now time.Time) bool {
+	return i.Status == ComplianceItemPending && now.After(i.DueDate.AddDate(0, 0, 1))
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ConsentChannel is a channel a contact can be messaged on
+type ConsentChannel string
+
+const (
+	ConsentChannelWhatsApp ConsentChannel = "whatsapp"
+	ConsentChannelEmail    ConsentChannel = "email"
+	ConsentChannelSMS      ConsentChannel = "sms"
+)
+
+// IsValid reports whether the channel is known
+func (c ConsentChannel) IsValid() bool {
+	switch c {
+	case ConsentChannelWhatsApp, ConsentChannelEmail, ConsentChannelSMS:
+		return true
+	}
+	return false
+}
+
+// ConsentStatus is whether a contact agreed to be messaged on a channel
+type ConsentStatus string
+
+const (
+	ConsentOptedIn  ConsentStatus = "opted_in"
+	ConsentOptedOut ConsentStatus = "opted_out"
+)
+
+// IsValid reports whether the status is known
+func (s ConsentStatus) IsValid() bool {
+	return s == ConsentOptedIn || s == ConsentOptedOut
+}
+
+// ConsentSource is where a consent change came from
+type ConsentSource string
+
+const (
+	ConsentSourceInboundKeyword ConsentSource = "inbound_keyword" // the contact messaged an opt-out or opt-in keyword
+	ConsentSourceStaff          ConsentSource = "staff"           // recorded by the staff, e.g. asked over the phone
+	ConsentSourcePortal         ConsentSource = "portal"          // changed by the client on the portal
+	ConsentSourceImport         ConsentSource = "import"          // brought over from another system
+)
+
+// IsValid reports whether the source is known
+func (s ConsentSource) IsValid() bool {
+	switch s {
+	case ConsentSourceInboundKeyword, ConsentSourceStaff, ConsentSourcePortal, ConsentSourceImport:
+		return true
+	}
+	return false
+}
+
+// NormalizeConsentAddress returns the form addresses are stored in for the channel: E.164 for
+// phone numbers, lowercase for email addresses
+func NormalizeConsentAddress(channel ConsentChannel, address string) string {
+	address = strings.TrimSpace(address)
+	if channel == ConsentChannelEmail {
+		return strings.ToLower(address)
+	}
+	if address == "" {
+		return ""
+	}
+	return NormalizeWhatsAppPhone(address)
+}
+
+// ContactConsent is whether a contact address may be messaged on a channel. Addresses without a
+// record may be.
+type ContactConsent struct {
+	ID             uuid.UUID      `json:"id" db:"id"`
+	OrganizationID uuid.UUID      `json:"organization_id" db:"organization_id"`
+	Channel        ConsentChannel `json:"channel" db:"channel"`
+	Address        string         `json:"address" db:"address"`
+	ClientID       *uuid.UUID     `json:"client_id" db:"client_id"`
+	PatientID      *uuid.UUID     `json:"patient_id" db:"patient_id"`
+	Status         ConsentStatus  `json:"status" db:"status"`
+	Source         ConsentSource  `json:"source" db:"source"`
+	Note           *string        `json:"note" db:"note"`
+	ChangedBy      *uuid.UUID     `json:"changed_by" db:"changed_by"`
+	ChangedAt      time.Time      `json:"changed_at" db:"changed_at"`
+	CreatedAt      time.Time      `json:"created_at" db:"created_at"`
+
+	// Joined for display
+	ClientName  *string `json:"client_name"`
+	PatientName *string `json:"patient_name"`
+
+	History []*ContactConsentChange `json:"history,omitempty"`
+}
+
+// ContactConsentChange is one change of a contact's consent
+type ContactConsentChange struct {
+	ID        uuid.UUID     `json:"id" db:"id"`
+	Status    ConsentStatus `json:"status" db:"status"`
+	Source    ConsentSource `json:"source" db:"source"`
+	Note      *string       `json:"note" db:"note"`
+	MessageID *uuid.UUID    `json:"message_id" db:"message_id"`
+	ChangedBy *uuid.UUID    `json:"changed_by" db:"changed_by"`
+	ChangedAt time.Time     `json:"changed_at" db:"changed_at"`
+
+	// Joined for display
+	ChangedByName *string `json:"changed_by_name"`
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// CrewMemberKind tells employees from subcontractors
+type CrewMemberKind string
+
+const (
+	CrewMemberWorker        CrewMemberKind = "worker"
+	CrewMemberSubcontractor CrewMemberKind = "subcontractor"
+)
+
+// IsValid reports whether the kind is known
+func (k CrewMemberKind) IsValid() bool {
+	return k == CrewMemberWorker || k == CrewMemberSubcontractor
+}
+
+// CrewMember is a worker or subcontractor who can be scheduled on projects
+type CrewMember struct {
+	ID             uuid.UUID       `json:"id" db:"id"`
+	OrganizationID uuid.UUID       `json:"organization_id" db:"organization_id"`
+	Kind           CrewMemberKind  `json:"kind" db:"kind"`
+	Name           string          `json:"name" db:"name"`
+	UserID         *uuid.UUID      `json:"user_id" db:"user_id"` // the app user, for workers who log in
+	Trade          *string         `json:"trade" db:"trade"`
+	Phone          *string         `json:"phone" db:"phone"`
+	HourlyRate     decimal.Decimal `json:"hourly_rate" db:"hourly_rate"`
+	DailyHours     decimal.Decimal `json:"daily_hours" db:"daily_hours"` // hours beyond which a day is double-booked
+	IsActive       bool            `json:"is_active" db:"is_active"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// CrewAssignment is a crew member's planned, and once worked actual, hours on a project on one day
+type CrewAssignment struct {
+	ID             uuid.UUID        `json:"id" db:"id"`
+	OrganizationID uuid.UUID        `json:"organization_id" db:"organization_id"`
+	ProjectID      uuid.UUID        `json:"project_id" db:"project_id"`
+	CrewMemberID   uuid.UUID        `json:"crew_member_id" db:"crew_member_id"`
+	WorkDate       string           `json:"work_date" db:"work_date"` // YYYY-MM-DD
+	PlannedHours   decimal.Decimal  `json:"planned_hours" db:"planned_hours"`
+	ActualHours    *decimal.Decimal `json:"actual_hours" db:"actual_hours"`
+	HourlyRate     decimal.Decimal  `json:"hourly_rate" db:"hourly_rate"` // the member's rate when assigned
+	Notes          *string          `json:"notes" db:"notes"`
+	CreatedBy      *uuid.UUID       `json:"created_by" db:"created_by"`
+	CreatedAt      time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at" db:"updated_at"`
+
+	ProjectNumber  string `json:"project_number" db:"-"`
+	ProjectTitle   string `json:"project_title" db:"-"`
+	CrewMemberName string `json:"crew_member_name" db:"-"`
+}
+
+// CrewCalendarDay is one crew member's assignments on one day
+type CrewCalendarDay struct {
+	Date           string            `json:"date"` // YYYY-MM-DD
+	CrewMemberID   uuid.UUID         `json:"crew_member_id"`
+	CrewMemberName string            `json:"crew_member_name"`
+	PlannedHours   decimal.Decimal   `json:"planned_hours"`
+	DailyHours     decimal.Decimal   `json:"daily_hours"`
+	DoubleBooked   bool              `json:"double_booked"` // planned across projects beyond the member's day
+	Assignments    []*CrewAssignment `json:"assignments"`
+}
+
+// ProjectLabour is the planned and actual labour of a project
+type ProjectLabour struct {
+	PlannedHours decimal.Decimal `json:"planned_hours"`
+	PlannedCost  decimal.Decimal `json:"planned_cost"`
+	ActualHours  decimal.Decimal `json:"actual_hours"`
+	ActualCost   decimal.Decimal `json:"actual_cost"`
+	// Planned cost of the days with actual hours recorded, which the actual cost is compared to
+	PlannedCostWorked decimal.Decimal `json:"planned_cost_worked"`
+	CostVariance      decimal.Decimal `json:"cost_variance"`   // actual cost less planned cost worked
+	UnrecordedDays    int             `json:"unrecorded_days"` // past assignments without actual hours
+}
+
+// ProjectCosting compares a project's budgeted amount with its labour and expense costs.
+// Amounts are without VAT.
+type ProjectCosting struct {
+	ProjectID    uuid.UUID       `json:"project_id"`
+	BudgetAmount decimal.Decimal `json:"budget_amount"`
+	Labour       ProjectLabour   `json:"labour"`
+	Expenses     decimal.Decimal `json:"expenses"`    // posted project expenses
+	ActualCost   decimal.Decimal `json:"actual_cost"` // actual labour and expenses
+	Margin       decimal.Decimal `json:"margin"`      // budget amount less actual cost
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DashboardEventType identifies an event streamed to connected dashboards
+type DashboardEventType string
+
+const (
+	DashboardEventSessionCreated       DashboardEventType = "session.created"
+	DashboardEventSessionSeriesCreated DashboardEventType = "session_series.created"
+	DashboardEventPaymentReceived      DashboardEventType = "payment.received"
+	DashboardEventActionFailed         DashboardEventType = "workflow.action_failed"
+)
+
+// DashboardEvent is a change dashboards refresh on. It carries enough to update a list in place;
+// clients fetch the entity for anything else.
+type DashboardEvent struct {
+	// Position in the organization's event stream, sent as the SSE event id
+	ID         string                 `json:"id,omitempty"`
+	Type       DashboardEventType     `json:"type"`
+	EntityType string                 `json:"entity_type"`
+	EntityID   uuid.UUID              `json:"entity_id"`
+	Data       map[string]interface{} `json:"data,omitempty"`
+	OccurredAt time.Time              `json:"occurred_at"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DataExportEntity is the kind of records a data export contains
+type DataExportEntity string
+
+const (
+	DataExportClients         DataExportEntity = "clients"
+	DataExportProjects        DataExportEntity = "projects"
+	DataExportPayments        DataExportEntity = "payments"
+	DataExportExpenses        DataExportEntity = "expenses"
+	DataExportPatients        DataExportEntity = "patients"
+	DataExportSessions        DataExportEntity = "sessions"
+	DataExportSessionPayments DataExportEntity = "session_payments"
+)
+
+// DataExportStatus represents the progress of a data export
+type DataExportStatus string
+
+const (
+	DataExportPending    DataExportStatus = "pending"
+	DataExportProcessing DataExportStatus = "processing"
+	DataExportReady      DataExportStatus = "ready"
+	DataExportFailed     DataExportStatus = "failed"
+	DataExportExpired    DataExportStatus = "expired" // the file was removed
+)
+
+// DataExportFilters limits the records exported. Dates apply to each entity's main date,
+// e.g. the scheduled date of sessions or the due date of payments.
+type DataExportFilters struct {
+	From *time.Time `json:"from,omitempty"`
+	To   *time.Time `json:"to,omitempty"`
+}
+
+// DataExport is a CSV export of a module's records, built in the background
+type DataExport struct {
+	ID             uuid.UUID         `json:"id" db:"id"`
+	OrganizationID uuid.UUID         `json:"organization_id" db:"organization_id"`
+	Entity         DataExportEntity  `json:"entity" db:"entity"`
+	Filters        DataExportFilters `json:"filters" db:"filters"`
+	Status         DataExportStatus  `json:"status" db:"status"`
+	TotalRows      *int              `json:"total_rows" db:"total_rows"`
+	ProcessedRows  int               `json:"processed_rows" db:"processed_rows"`
+	Progress       float64           `json:"progress"`        // 0 to 1
+	FileURL        *string           `json:"-" db:"file_url"` // served through a download link
+	FileName       *string           `json:"file_name" db:"file_name"`
+	FileSize       *int64            `json:"file_size" db:"file_size"`
+	DownloadURL    *string           `json:"download_url,omitempty"` // signed link, set while ready
+	Error          *string           `json:"error" db:"error"`
+	RequestedBy    uuid.UUID         `json:"requested_by" db:"requested_by"`
+	CreatedAt      time.Time         `json:"created_at" db:"created_at"`
+	StartedAt      *time.Time        `json:"started_at" db:"started_at"`
+	CompletedAt    *time.Time        `json:"completed_at" db:"completed_at"`
+	ExpiresAt      *time.Time        `json:"expires_at" db:"expires_at"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutOfOffice represents an absence window during which a user's work is rerouted to a delegate
+type OutOfOffice struct {
+	ID                  uuid.UUID  `json:"id" db:"id"`
+	OrganizationID      uuid.UUID  `json:"organization_id" db:"organization_id"`
+	UserID              uuid.UUID  `json:"user_id" db:"user_id"`
+	DelegateUserID      uuid.UUID  `json:"delegate_user_id" db:"delegate_user_id"`
+	StartsAt            time.Time  `json:"starts_at" db:"starts_at"`
+	EndsAt              time.Time  `json:"ends_at" db:"ends_at"`
+	Reason              *string    `json:"reason" db:"reason"`
+	DelegateApprovals   bool       `json:"delegate_approvals" db:"delegate_approvals"`
+	DelegateTasks       bool       `json:"delegate_tasks" db:"delegate_tasks"`
+	DelegateEscalations bool       `json:"delegate_escalations" db:"delegate_escalations"`
+	IsActive            bool       `json:"is_active" db:"is_active"`
+	CreatedBy           *uuid.UUID `json:"created_by" db:"created_by"`
+	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at" db:"updated_at"`
+	// Joined data
+	UserName     string `json:"user_name,omitempty" db:"-"`
+	DelegateName string `json:"delegate_name,omitempty" db:"-"`
+}
+
+// Covers returns true if the absence window covers the given delegation type at time t
+func (o *OutOfOffice) Covers(delegationType DelegationType, t time.Time) bool {
+	if !o.IsActive || t.Before(o.StartsAt) || !t.Before(o.EndsAt) {
+		return false
+	}
+	switch delegationType {
+	case DelegationTypeApproval:
+		return o.DelegateApprovals
+	case DelegationTypeTask:
+		return o.DelegateTasks
+	case DelegationTypeEscalation:
+		return o.DelegateEscalations
+	}
+	return false
+}
+
+// DelegationType represents the kind of work that was rerouted
+type DelegationType string
+
+const (
+	DelegationTypeApproval   DelegationType = "approval"
+	DelegationTypeTask       DelegationType = "task"
+	DelegationTypeEscalation DelegationType = "escalation"
+)
+
+// DelegationAuditLog records a single item rerouted to a delegate
+type DelegationAuditLog struct {
+	ID             uuid.UUID       `json:"id" db:"id"`
+	OrganizationID uuid.UUID       `json:"organization_id" db:"organization_id"`
+	OutOfOfficeID  *uuid.UUID      `json:"out_of_office_id" db:"out_of_office_id"`
+	OriginalUserID uuid.UUID       `json:"original_user_id" db:"original_user_id"`
+	DelegateUserID uuid.UUID       `json:"delegate_user_id" db:"delegate_user_id"`
+	DelegationType DelegationType  `json:"delegation_type" db:"delegation_type"`
+	EntityType     *string         `json:"entity_type" db:"entity_type"`
+	EntityID       *uuid.UUID      `json:"entity_id" db:"entity_id"`
+	Details        json.RawMessage `json:"details" db:"details"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailProvider is the service an organization sends its emails through
+type EmailProvider string
+
+const (
+	EmailProviderSMTP     EmailProvider = "smtp"
+	EmailProviderSendGrid EmailProvider = "sendgrid"
+	// EmailProviderPlatform is the platform SMTP server, used when the organization has no provider
+	EmailProviderPlatform EmailProvider = "platform"
+)
+
+// IsValid reports whether the provider can be configured by an organization
+func (p EmailProvider) IsValid() bool {
+	return p == EmailProviderSMTP || p == EmailProviderSendGrid
+}
+
+// EmailMessageStatus represents email delivery status
+type EmailMessageStatus string
+
+const (
+	EmailStatusQueued    EmailMessageStatus = "queued"
+	EmailStatusSent      EmailMessageStatus = "sent"
+	EmailStatusDelivered EmailMessageStatus = "delivered"
+	EmailStatusFailed    EmailMessageStatus = "failed"
+	// EmailStatusBounced and EmailStatusComplained are reported by the provider after sending
+	EmailStatusBounced    EmailMessageStatus = "bounced"
+	EmailStatusComplained EmailMessageStatus = "complained"
+)
+
+// EmailMessage is a logged outbound email
+type EmailMessage struct {
+	ID                uuid.UUID          `json:"id" db:"id"`
+	OrganizationID    uuid.UUID          `json:"organization_id" db:"organization_id"`
+	SessionID         *uuid.UUID         `json:"session_id" db:"session_id"`
+	ToAddress         string             `json:"to_address" db:"to_address"`
+	Subject           *string            `json:"subject" db:"subject"`
+	MessageContent    *string            `json:"message_content" db:"message_content"`
+	Provider          EmailProvider      `json:"provider" db:"provider"`
+	ProviderMessageID *string            `json:"provider_message_id" db:"provider_message_id"`
+	Status            EmailMessageStatus `json:"status" db:"status"`
+	ErrorMessage      *string            `json:"error_message" db:"error_message"`
+	SentAt            *time.Time         `json:"sent_at" db:"sent_at"`
+	CreatedAt         time.Time          `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailSuppressionReason tells why an address is suppressed
+type EmailSuppressionReason string
+
+const (
+	EmailSuppressionHardBounce EmailSuppressionReason = "hard_bounce"
+	EmailSuppressionComplaint  EmailSuppressionReason = "complaint"
+)
+
+// EmailEventProvider is the provider reporting bounces and complaints
+type EmailEventProvider string
+
+const (
+	EmailEventProviderSendGrid EmailEventProvider = "sendgrid"
+	EmailEventProviderSES      EmailEventProvider = "ses"
+)
+
+// EmailSuppression is an address the organization's emails aren't sent to, because it hard
+// bounced or its owner marked an email as spam
+type EmailSuppression struct {
+	ID             uuid.UUID              `json:"id" db:"id"`
+	OrganizationID uuid.UUID              `json:"organization_id" db:"organization_id"`
+	Address        string                 `json:"address" db:"address"`
+	Reason         EmailSuppressionReason `json:"reason" db:"reason"`
+	Provider       EmailEventProvider     `json:"provider" db:"provider"`
+	Detail         *string                `json:"detail" db:"detail"`
+	EmailMessageID *uuid.UUID             `json:"email_message_id" db:"email_message_id"`
+	CreatedAt      time.Time              `json:"created_at" db:"created_at"`
+}
+
+// EmailEvent is a bounce or complaint reported by a provider for one recipient
+type EmailEvent struct {
+	Address           string
+	ProviderMessageID string // the provider's id of the email, or its Message-ID header
+	Complaint         bool
+	Permanent         bool // a hard bounce; transient bounces don't suppress the address
+	Detail            string
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ExpenseScope tells whether an expense is a project cost or an overhead of the clinic
+type ExpenseScope string
+
+const (
+	ExpenseScopeProject ExpenseScope = "project"
+	ExpenseScopeClinic  ExpenseScope = "clinic"
+)
+
+// ExpenseStatus represents the review state of an expense
+type ExpenseStatus string
+
+const (
+	// ExpenseStatusDraft expenses were pre-filled from a receipt and wait for review
+	ExpenseStatusDraft  ExpenseStatus = "draft"
+	ExpenseStatusPosted ExpenseStatus = "posted"
+)
+
+// Expense is a cost recorded from a receipt
+type Expense struct {
+	ID              uuid.UUID        `json:"id" db:"id"`
+	OrganizationID  uuid.UUID        `json:"organization_id" db:"organization_id"`
+	Scope           ExpenseScope     `json:"scope" db:"scope"`
+	ProjectID       *uuid.UUID       `json:"project_id" db:"project_id"`
+	Status          ExpenseStatus    `json:"status" db:"status"`
+	Vendor          *string          `json:"vendor" db:"vendor"`
+	VendorTaxID     *string          `json:"vendor_tax_id" db:"vendor_tax_id"`
+	ExpenseDate     *time.Time       `json:"expense_date" db:"expense_date"`
+	Amount          *decimal.Decimal `json:"amount" db:"amount"` // VAT included
+	VATAmount       *decimal.Decimal `json:"vat_amount" db:"vat_amount"`
+	VATRate         *decimal.Decimal `json:"vat_rate" db:"vat_rate"`
+	Category        *string          `json:"category" db:"category"`
+	Description     *string          `json:"description" db:"description"`
+	ReceiptURL      *string          `json:"receipt_url" db:"receipt_url"`
+	ReceiptFileName *string          `json:"receipt_file_name" db:"receipt_file_name"`
+	OCRProvider     *string          `json:"ocr_provider" db:"ocr_provider"`
+	OCRConfidence   *decimal.Decimal `json:"ocr_confidence" db:"ocr_confidence"`
+	OCRData         json.RawMessage  `json:"ocr_data,omitempty" db:"ocr_data"`
+	CreatedBy       *uuid.UUID       `json:"created_by" db:"created_by"`
+	ConfirmedBy     *uuid.UUID       `json:"confirmed_by" db:"confirmed_by"`
+	ConfirmedAt     *time.Time       `json:"confirmed_at" db:"confirmed_at"`
+	CreatedAt       time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at" db:"updated_at"`
+	DeletedAt       *time.Time       `json:"deleted_at,omitempty" db:"deleted_at"`
+
+	// Joined fields
+	ProjectNumber *string `json:"project_number,omitempty" db:"project_number"`
+	ProjectTitle  *string `json:"project_title,omitempty" db:"project_title"`
+}
+
+// ReceiptExtraction holds the fields an OCR provider read from a receipt. Fields it could not
+// read are nil.
+type ReceiptExtraction struct {
+	Vendor      *string          `json:"vendor"`
+	VendorTaxID *string          `json:"vendor_tax_id"`
+	Date        *time.Time       `json:"date"`
+	Total       *decimal.Decimal `json:"total"`
+	VATAmount   *decimal.Decimal `json:"vat_amount"`
+	VATRate     *decimal.Decimal `json:"vat_rate"`
+	Confidence  *decimal.Decimal `json:"confidence"` // 0-1, when the provider reports it
+	Text        string           `json:"text,omitempty"`
+}
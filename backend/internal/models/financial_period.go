@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// FinancialPeriodStatus represents whether a month is locked
+type FinancialPeriodStatus string
+
+const (
+	FinancialPeriodOpen     FinancialPeriodStatus = "open" // never closed
+	FinancialPeriodClosed   FinancialPeriodStatus = "closed"
+	FinancialPeriodReopened FinancialPeriodStatus = "reopened"
+)
+
+// FinancialPeriod is a month of an organization's books
+type FinancialPeriod struct {
+	Period       string                   `json:"period"` // YYYY-MM
+	Status       FinancialPeriodStatus    `json:"status"`
+	ClosedBy     *uuid.UUID               `json:"closed_by"`
+	ClosedAt     *time.Time               `json:"closed_at"`
+	ReopenedBy   *uuid.UUID               `json:"reopened_by"`
+	ReopenedAt   *time.Time               `json:"reopened_at"`
+	ReopenReason *string                  `json:"reopen_reason"`
+	Snapshot     *FinancialPeriodSnapshot `json:"snapshot,omitempty"`
+}
+
+// FinancialPeriodSnapshot holds the figures of a month, frozen when it is closed
+type FinancialPeriodSnapshot struct {
+	ProjectPaymentsDue       decimal.Decimal `json:"project_payments_due"`
+	ProjectPaymentsCollected decimal.Decimal `json:"project_payments_collected"`
+	SessionPaymentsDue       decimal.Decimal `json:"session_payments_due"`
+	SessionPaymentsCollected decimal.Decimal `json:"session_payments_collected"`
+	TotalCollected           decimal.Decimal `json:"total_collected"`
+	Outstanding              decimal.Decimal `json:"outstanding"` // due in the month and still unpaid
+	PaymentsCount            int             `json:"payments_count"`
+	GeneratedAt              time.Time       `json:"generated_at"`
+}
+
+// FinancialPeriodEvent is a close or reopen of a month
+type FinancialPeriodEvent struct {
+	ID        uuid.UUID  `json:"id"`
+	Period    string     `json:"period"`
+	Action    string     `json:"action"` // closed or reopened
+	UserID    *uuid.UUID `json:"user_id"`
+	UserName  *string    `json:"user_name"`
+	Reason    *string    `json:"reason"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FollowUpEntityType is the kind of record a follow-up reminds about
+type FollowUpEntityType string
+
+const (
+	FollowUpEntityBudget  FollowUpEntityType = "budget"
+	FollowUpEntityClient  FollowUpEntityType = "client"
+	FollowUpEntitySession FollowUpEntityType = "session"
+	FollowUpEntityProject FollowUpEntityType = "project"
+	FollowUpEntityPatient FollowUpEntityType = "patient"
+)
+
+// IsValid returns true if follow-ups can be set on the entity type
+func (t FollowUpEntityType) IsValid() bool {
+	switch t {
+	case FollowUpEntityBudget, FollowUpEntityClient, FollowUpEntitySession, FollowUpEntityProject, FollowUpEntityPatient:
+		return true
+	}
+	return false
+}
+
+// FollowUpStatus is the state of a follow-up
+type FollowUpStatus string
+
+const (
+	FollowUpStatusPending   FollowUpStatus = "pending"
+	FollowUpStatusNotified  FollowUpStatus = "notified" // due; listed in the inbox and emailed if asked
+	FollowUpStatusDone      FollowUpStatus = "done"
+	FollowUpStatusCancelled FollowUpStatus = "cancelled"
+)
+
+// FollowUp is a personal "remind me about this" set by a user
+type FollowUp struct {
+	ID             uuid.UUID          `json:"id" db:"id"`
+	OrganizationID uuid.UUID          `json:"organization_id" db:"organization_id"`
+	UserID         uuid.UUID          `json:"user_id" db:"user_id"`
+	EntityType     FollowUpEntityType `json:"entity_type" db:"entity_type"`
+	EntityID       uuid.UUID          `json:"entity_id" db:"entity_id"`
+	EntityLabel    string             `json:"entity_label" db:"entity_label"`
+	RemindAt       time.Time          `json:"remind_at" db:"remind_at"`
+	Note           *string            `json:"note,omitempty" db:"note"`
+	SendEmail      bool               `json:"send_email" db:"send_email"`
+	Status         FollowUpStatus     `json:"status" db:"status"`
+	NotifiedAt     *time.Time         `json:"notified_at,omitempty" db:"notified_at"`
+	CompletedAt    *time.Time         `json:"completed_at,omitempty" db:"completed_at"`
+	CreatedAt      time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at" db:"updated_at"`
+}
+
+// FollowUpFilters narrows a user's follow-ups
+type FollowUpFilters struct {
+	Status     FollowUpStatus
+	EntityType FollowUpEntityType
+	EntityID   *uuid.UUID
+}
@@ -0,0 +1,52 @@
+package models
+
+// InboundIntent is what an inbound WhatsApp message asks for
+type InboundIntent string
+
+const (
+	InboundIntentConfirm    InboundIntent = "confirm"    // confirms the next session
+	InboundIntentCancel     InboundIntent = "cancel"     // cancels the next session
+	InboundIntentReschedule InboundIntent = "reschedule" // asks to move the next session
+	InboundIntentOptOut     InboundIntent = "opt_out"    // asks for no more WhatsApp messages
+	InboundIntentOptIn      InboundIntent = "opt_in"     // takes back an opt-out
+	InboundIntentBalance    InboundIntent = "balance"    // asks how much is owed
+	InboundIntentFallback   InboundIntent = "fallback"   // anything else, passed on to the staff
+)
+
+// InboundIntents lists the intents in the order messages are matched against them: the exact
+// opt-out and opt-in words first, then the phrases, then the short confirm and cancel replies
+var InboundIntents = []InboundIntent{
+	InboundIntentOptOut,
+	InboundIntentOptIn,
+	InboundIntentReschedule,
+	InboundIntentBalance,
+	InboundIntentConfirm,
+	InboundIntentCancel,
+}
+
+// IsValid reports whether the intent is known
+func (i InboundIntent) IsValid() bool {
+	if i == InboundIntentFallback {
+		return true
+	}
+	for _, intent := range InboundIntents {
+		if i == intent {
+			return true
+		}
+	}
+	return false
+}
+
+// InboundIntentRule is an organization's setting of one intent, over the built-in one
+type InboundIntentRule struct {
+	Enabled  bool     `json:"enabled"`
+	Keywords []string `json:"keywords"` // matched on top of the built-in keywords
+	Reply    string   `json:"reply"`    // replaces the built-in reply when set
+}
+
+// InboundIntentSettings are an organization's intent rules. Intents without a rule keep their
+// built-in behaviour.
+type InboundIntentSettings map[InboundIntent]InboundIntentRule
+
+// NotificationTypeWhatsAppMessage is sent to the staff for inbound WhatsApp messages no intent handled
+const NotificationTypeWhatsAppMessage = "whatsapp_message"
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InboxItemType is the kind of situation an inbox item asks someone to handle
+type InboxItemType string
+
+const (
+	InboxItemSessionUnconfirmed InboxItemType = "session_unconfirmed" // pending session starting within 24 hours
+	InboxItemFailedReminder     InboxItemType = "failed_reminder"     // reminder for an upcoming session that could not be sent
+	InboxItemBudgetApproval     InboxItemType = "budget_approval"     // budget awaiting internal approval
+	InboxItemOverdueTask        InboxItemType = "overdue_task"
+	InboxItemUnmatchedMessage   InboxItemType = "unmatched_message" // inbound WhatsApp message no session was matched to
+	InboxItemFollowUp           InboxItemType = "follow_up"         // the user's own follow-up reminder that is due
+)
+
+// IsValid returns true if the inbox item type is known
+func (t InboxItemType) IsValid() bool {
+	switch t {
+	case InboxItemSessionUnconfirmed, InboxItemFailedReminder, InboxItemBudgetApproval, InboxItemOverdueTask, InboxItemUnmatchedMessage,
+		InboxItemFollowUp:
+		return true
+	}
+	return false
+}
+
+// InboxPriority orders the inbox, most urgent first
+type InboxPriority string
+
+const (
+	InboxPriorityHigh   InboxPriority = "high"
+	InboxPriorityMedium InboxPriority = "medium"
+	InboxPriorityLow    InboxPriority = "low"
+)
+
+// Rank returns the sort position of the priority, lowest first
+func (p InboxPriority) Rank() int {
+	switch p {
+	case InboxPriorityHigh:
+		return 0
+	case InboxPriorityMedium:
+		return 1
+	}
+	return 2
+}
+
+// InboxItem is something in the organization that needs a person to act on it.
+// ID is the ID of the underlying session, reminder, budget, task, message or follow-up.
+type InboxItem struct {
+	Type      InboxItemType `json:"type"`
+	ID        uuid.UUID     `json:"id"`
+	Title     string        `json:"title"`
+	Detail    string        `json:"detail,omitempty"`
+	Priority  InboxPriority `json:"priority"`
+	DueAt     *time.Time    `json:"due_at,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+	// Entity the user is taken to when opening the item
+	EntityType string    `json:"entity_type"`
+	EntityID   uuid.UUID `json:"entity_id"`
+
+	AssignedTo     *uuid.UUID `json:"assigned_to,omitempty"`
+	AssignedToName *string    `json:"assigned_to_name,omitempty"`
+	SnoozedUntil   *time.Time `json:"snoozed_until,omitempty"`
+}
+
+// InboxFilters narrows the inbox
+type InboxFilters struct {
+	Type           InboxItemType
+	AssignedTo     *uuid.UUID
+	Unassigned     bool
+	IncludeSnoozed bool
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IntegrityCategory is a kind of data inconsistency found in an organization
+type IntegrityCategory string
+
+const (
+	// Pending scheduled jobs whose entity is gone or whose trigger is inactive or belongs to
+	// another organization; repaired by cancelling them
+	IntegrityOrphanedScheduledJob IntegrityCategory = "orphaned_scheduled_job"
+	// Active triggers on a state or transition of another workflow; repaired by deactivating them
+	IntegrityTriggerInvalidState IntegrityCategory = "trigger_invalid_state"
+	// Payments still open on deleted sessions or projects; reported only
+	IntegrityPaymentWithoutParent IntegrityCategory = "payment_without_parent"
+	// Messages linked to a session of another organization; repaired by unlinking them
+	IntegrityMessageWrongOrganization IntegrityCategory = "message_wrong_organization"
+	// Payments and budgets with a negative amount; reported only
+	IntegrityNegativeAmount IntegrityCategory = "negative_amount"
+)
+
+// IntegrityIssue is one inconsistent record
+type IntegrityIssue struct {
+	Category   IntegrityCategory `json:"category"`
+	EntityType string            `json:"entity_type"`
+	EntityID   uuid.UUID         `json:"entity_id"`
+	Detail     string            `json:"detail"`
+	// Whether the issue is repaired automatically when its category is repaired
+	Fixable bool `json:"fixable"`
+}
+
+// IntegrityCategoryCount is how many issues of a category were found
+type IntegrityCategoryCount struct {
+	Category    IntegrityCategory `json:"category"`
+	Description string            `json:"description"`
+	Count       int               `json:"count"`
+	Fixable     bool              `json:"fixable"`
+}
+
+// IntegrityReport is the result of running the integrity checks on an organization. Issues are
+// capped per category; the counts are not.
+type IntegrityReport struct {
+	OrganizationID uuid.UUID                 `json:"organization_id"`
+	CheckedAt      time.Time                 `json:"checked_at"`
+	Categories     []*IntegrityCategoryCount `json:"categories"`
+	Issues         []*IntegrityIssue         `json:"issues"`
+	TotalIssues    int                       `json:"total_issues"`
+}
+
+// IntegrityRepairResult is how many records each repaired category changed
+type IntegrityRepairResult struct {
+	OrganizationID uuid.UUID                 `json:"organization_id"`
+	Repaired       map[IntegrityCategory]int `json:"repaired"`
+}
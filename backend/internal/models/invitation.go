@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InvitationStatus is derived from an invitation's timestamps
+type InvitationStatus string
+
+const (
+	InvitationStatusPending  InvitationStatus = "pending"
+	InvitationStatusAccepted InvitationStatus = "accepted"
+	InvitationStatusRevoked  InvitationStatus = "revoked"
+	InvitationStatusExpired  InvitationStatus = "expired"
+)
+
+// UserInvitation is an emailed invite for a colleague to join the organization with a role
+type UserInvitation struct {
+	ID             uuid.UUID        `json:"id" db:"id"`
+	OrganizationID uuid.UUID        `json:"organization_id" db:"organization_id"`
+	Email          string           `json:"email" db:"email"`
+	Role           Role             `json:"role" db:"role"`
+	InvitedBy      *uuid.UUID       `json:"invited_by" db:"invited_by"`
+	ExpiresAt      time.Time        `json:"expires_at" db:"expires_at"`
+	SentCount      int              `json:"sent_count" db:"sent_count"`
+	LastSentAt     *time.Time       `json:"last_sent_at" db:"last_sent_at"`
+	AcceptedAt     *time.Time       `json:"accepted_at" db:"accepted_at"`
+	AcceptedUserID *uuid.UUID       `json:"accepted_user_id" db:"accepted_user_id"`
+	RevokedAt      *time.Time       `json:"revoked_at" db:"revoked_at"`
+	CreatedAt      time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at" db:"updated_at"`
+	Status         InvitationStatus `json:"status" db:"-"`
+
+	// Joined fields
+	InvitedByName    string `json:"invited_by_name,omitempty" db:"-"`
+	OrganizationName string `json:"organization_name,omitempty" db:"-"`
+}
+
+// SetStatus derives the invitation status at the given time
+func (i *UserInvitation) SetStatus(now time.Time) {
+	switch {
+	case i.AcceptedAt != nil:
+		i.Status = InvitationStatusAccepted
+	case i.RevokedAt != nil:
+		i.Status = InvitationStatusRevoked
+	case now.After(i.ExpiresAt):
+		i.Status = InvitationStatusExpired
+	default:
+		i.Status = InvitationStatusPending
+	}
+}
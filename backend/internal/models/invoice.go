@@ -0,0 +1,112 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// InvoiceDocumentType tells invoices from the credit notes that cancel them
+type InvoiceDocumentType string
+
+const (
+	InvoiceDocumentInvoice    InvoiceDocumentType = "invoice"
+	InvoiceDocumentCreditNote InvoiceDocumentType = "credit_note"
+)
+
+// InvoiceSeries are the number prefixes of each document type, e.g. FT 2026/12
+var InvoiceSeries = map[InvoiceDocumentType]string{
+	InvoiceDocumentInvoice:    "FT",
+	InvoiceDocumentCreditNote: "NC",
+}
+
+// InvoiceStatus represents the lifecycle of an invoice
+type InvoiceStatus string
+
+const (
+	InvoiceStatusDraft  InvoiceStatus = "draft"
+	InvoiceStatusIssued InvoiceStatus = "issued"
+	InvoiceStatusPaid   InvoiceStatus = "paid"
+	InvoiceStatusVoid   InvoiceStatus = "void"
+)
+
+// InvoiceSourceType is what an invoice was created from
+type InvoiceSourceType string
+
+const (
+	InvoiceSourceSessionPayment InvoiceSourceType = "session_payment"
+	InvoiceSourcePayment        InvoiceSourceType = "payment" // project payment
+)
+
+// DefaultVATRate is the Portuguese standard VAT rate, used when none is given
+var DefaultVATRate = decimal.NewFromInt(23)
+
+// Invoice is an invoice or credit note. Drafts have no number; it is assigned when the document
+// is issued.
+type Invoice struct {
+	ID                uuid.UUID           `json:"id" db:"id"`
+	OrganizationID    uuid.UUID           `json:"organization_id" db:"organization_id"`
+	DocumentType      InvoiceDocumentType `json:"document_type" db:"document_type"`
+	Status            InvoiceStatus       `json:"status" db:"status"`
+	Series            string              `json:"series" db:"series"`
+	Year              *int                `json:"year" db:"year"`
+	Number            *int                `json:"number" db:"number"`
+	InvoiceNumber     *string             `json:"invoice_number" db:"invoice_number"`
+	SourceType        *InvoiceSourceType  `json:"source_type" db:"source_type"`
+	SourceID          *uuid.UUID          `json:"source_id" db:"source_id"`
+	CreditedInvoiceID *uuid.UUID          `json:"credited_invoice_id" db:"credited_invoice_id"`
+	ClientID          *uuid.UUID          `json:"client_id" db:"client_id"`
+	CustomerName      string              `json:"customer_name" db:"customer_name"`
+	CustomerTaxID     *string             `json:"customer_tax_id" db:"customer_tax_id"`
+	CustomerAddress   *string             `json:"customer_address" db:"customer_address"`
+	CustomerEmail     *string             `json:"customer_email" db:"customer_email"`
+	IssueDate         *time.Time          `json:"issue_date" db:"issue_date"`
+	DueDate           *time.Time          `json:"due_date" db:"due_date"`
+	Subtotal          decimal.Decimal     `json:"subtotal" db:"subtotal"`
+	VATTotal          decimal.Decimal     `json:"vat_total" db:"vat_total"`
+	Total             decimal.Decimal     `json:"total" db:"total"`
+	Notes             *string             `json:"notes" db:"notes"`
+	VoidReason        *string             `json:"void_reason" db:"void_reason"`
+	IssuedAt          *time.Time          `json:"issued_at" db:"issued_at"`
+	PaidAt            *time.Time          `json:"paid_at" db:"paid_at"`
+	VoidedAt          *time.Time          `json:"voided_at" db:"voided_at"`
+	CreatedBy         *uuid.UUID          `json:"created_by" db:"created_by"`
+	CreatedAt         time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time           `json:"updated_at" db:"updated_at"`
+	// Loaded with the invoice's details
+	Lines        []InvoiceLine `json:"lines,omitempty" db:"-"`
+	VATBreakdown []VATSummary  `json:"vat_breakdown,omitempty" db:"-"`
+}
+
+// InvoiceLine is a line of an invoice. Amounts are positive on credit notes too.
+type InvoiceLine struct {
+	ID                 uuid.UUID       `json:"id" db:"id"`
+	InvoiceID          uuid.UUID       `json:"invoice_id" db:"invoice_id"`
+	Position           int             `json:"position" db:"position"`
+	Description        string          `json:"description" db:"description"`
+	Quantity           decimal.Decimal `json:"quantity" db:"quantity"`
+	UnitPrice          decimal.Decimal `json:"unit_price" db:"unit_price"` // without VAT
+	VATRate            decimal.Decimal `json:"vat_rate" db:"vat_rate"`     // percentage
+	VATExemptionReason *string         `json:"vat_exemption_reason" db:"vat_exemption_reason"`
+	Net                decimal.Decimal `json:"net" db:"net"`
+	VAT                decimal.Decimal `json:"vat" db:"vat"`
+	Total              decimal.Decimal `json:"total" db:"total"`
+}
+
+// VATSummary totals the lines of an invoice taxed at one rate
+type VATSummary struct {
+	Rate            decimal.Decimal `json:"rate"`
+	ExemptionReason *string         `json:"exemption_reason,omitempty"`
+	Base            decimal.Decimal `json:"base"`
+	VAT             decimal.Decimal `json:"vat"`
+}
+
+// InvoicePDF is a generated invoice PDF and a short-lived link to download it
+type InvoicePDF struct {
+	InvoiceID   uuid.UUID `json:"invoice_id"`
+	FileName    string    `json:"file_name"`
+	DownloadURL string    `json:"download_url"`
+	GeneratedAt time.Time `json:"generated_at"`
+	ExpiresAt   time.Time `json:"expires_at"` // of the link; the file is kept
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LogArchiveStatus represents the progress of an execution log archive
+type LogArchiveStatus string
+
+const (
+	LogArchivePending    LogArchiveStatus = "pending"
+	LogArchiveProcessing LogArchiveStatus = "processing"
+	LogArchiveReady      LogArchiveStatus = "ready"
+	LogArchiveFailed     LogArchiveStatus = "failed"
+)
+
+// LogRetentionPolicy is how long an organization keeps its workflow execution log
+type LogRetentionPolicy struct {
+	OrganizationID uuid.UUID  `json:"organization_id"`
+	RetentionDays  *int       `json:"retention_days"` // null keeps the log forever
+	EntryCount     int        `json:"entry_count"`
+	OldestEntryAt  *time.Time `json:"oldest_entry_at"`
+	ArchivableRows int        `json:"archivable_rows"` // entries older than the retention period
+}
+
+// LogArchive is a compressed JSONL export of execution log entries that were removed from the database
+type LogArchive struct {
+	ID             uuid.UUID        `json:"id" db:"id"`
+	OrganizationID uuid.UUID        `json:"organization_id" db:"organization_id"`
+	Status         LogArchiveStatus `json:"status" db:"status"`
+	Cutoff         time.Time        `json:"cutoff" db:"cutoff"` // entries created before it are archived
+	RowCount       int              `json:"row_count" db:"row_count"`
+	FirstEntryAt   *time.Time       `json:"first_entry_at" db:"first_entry_at"`
+	LastEntryAt    *time.Time       `json:"last_entry_at" db:"last_entry_at"`
+	FileURL        *string          `json:"-" db:"file_url"` // served through a download link
+	FileName       *string          `json:"file_name" db:"file_name"`
+	FileSize       *int64           `json:"file_size" db:"file_size"`
+	Error          *string          `json:"error" db:"error"`
+	RequestedBy    *uuid.UUID       `json:"requested_by" db:"requested_by"` // null for the nightly archival
+	CreatedAt      time.Time        `json:"created_at" db:"created_at"`
+	StartedAt      *time.Time       `json:"started_at" db:"started_at"`
+	CompletedAt    *time.Time       `json:"completed_at" db:"completed_at"`
+}
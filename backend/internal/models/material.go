@@ -0,0 +1,116 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Material is an entry of the organization's materials catalogue
+type Material struct {
+	ID             uuid.UUID       `json:"id" db:"id"`
+	OrganizationID uuid.UUID       `json:"organization_id" db:"organization_id"`
+	Supplier       string          `json:"supplier" db:"supplier"`
+	Code           string          `json:"code" db:"code"`
+	Name           string          `json:"name" db:"name"`
+	Unit           string          `json:"unit" db:"unit"`
+	UnitPrice      decimal.Decimal `json:"unit_price" db:"unit_price"`
+	IsActive       bool            `json:"is_active" db:"is_active"`
+	LastImportID   *uuid.UUID      `json:"last_import_id" db:"last_import_id"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// PriceListImportTarget is where the rows of a price list import are written
+type PriceListImportTarget string
+
+const (
+	PriceListTargetCatalogue PriceListImportTarget = "catalogue"
+	PriceListTargetBudget    PriceListImportTarget = "budget"
+)
+
+// PriceListImportStatus represents the status of a price list import
+type PriceListImportStatus string
+
+const (
+	PriceListImportPreview   PriceListImportStatus = "preview"
+	PriceListImportConfirmed PriceListImportStatus = "confirmed"
+	PriceListImportCancelled PriceListImportStatus = "cancelled"
+)
+
+// PriceListColumnMapping maps import fields to the column headers (or column letters) of the file
+type PriceListColumnMapping struct {
+	Code      string `json:"code,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Unit      string `json:"unit,omitempty"`
+	UnitPrice string `json:"unit_price,omitempty"`
+	Quantity  string `json:"quantity,omitempty"` // budget imports only
+}
+
+// UnitConversion converts a supplier unit into the unit used internally,
+// e.g. {from: "cx", to: "un", factor: 25} for boxes of 25 units
+type UnitConversion struct {
+	From   string          `json:"from"`
+	To     string          `json:"to"`
+	Factor decimal.Decimal `json:"factor"`
+}
+
+// PriceListImportOptions configures how a price list file is read
+type PriceListImportOptions struct {
+	Columns         PriceListColumnMapping `json:"columns"`
+	UnitConversions []UnitConversion       `json:"unit_conversions,omitempty"`
+	TaxRate         decimal.Decimal        `json:"tax_rate"`        // percentage applied to budget items
+	Sheet           string                 `json:"sheet,omitempty"` // XLSX sheet name, first sheet by default
+}
+
+// PriceListImportRow is a parsed row of a price list, after mapping and unit conversion
+type PriceListImportRow struct {
+	Line         int             `json:"line"`
+	Code         string          `json:"code"`
+	Name         string          `json:"name"`
+	Unit         string          `json:"unit"`
+	UnitPrice    decimal.Decimal `json:"unit_price"`
+	Quantity     decimal.Decimal `json:"quantity"`
+	OriginalUnit string          `json:"original_unit,omitempty"` // set when a unit conversion was applied
+	MaterialID   *uuid.UUID      `json:"material_id,omitempty"`   // existing catalogue entry that will be updated
+	Errors       []string        `json:"errors,omitempty"`
+}
+
+// PriceListImport is an uploaded supplier price list staged for confirmation
+type PriceListImport struct {
+	ID             uuid.UUID              `json:"id" db:"id"`
+	OrganizationID uuid.UUID              `json:"organization_id" db:"organization_id"`
+	FileName       string                 `json:"file_name" db:"file_name"`
+	FileFormat     string                 `json:"file_format" db:"file_format"`
+	Target         PriceListImportTarget  `json:"target" db:"target"`
+	BudgetID       *uuid.UUID             `json:"budget_id" db:"budget_id"`
+	Supplier       string                 `json:"supplier" db:"supplier"`
+	Options        PriceListImportOptions `json:"options" db:"options"`
+	Rows           []PriceListImportRow   `json:"rows" db:"rows"`
+	RowCount       int                    `json:"row_count" db:"row_count"`
+	ErrorCount     int                    `json:"error_count" db:"error_count"`
+	Status         PriceListImportStatus  `json:"status" db:"status"`
+	CreatedBy      *uuid.UUID             `json:"created_by" db:"created_by"`
+	ConfirmedBy    *uuid.UUID             `json:"confirmed_by" db:"confirmed_by"`
+	ConfirmedAt    *time.Time             `json:"confirmed_at" db:"confirmed_at"`
+	CreatedAt      time.Time              `json:"created_at" db:"created_at"`
+}
+
+// PriceSource is where a price history entry was recorded from
+type PriceSource string
+
+const (
+	PriceSourceBudget    PriceSource = "budget"
+	PriceSourceCatalogue PriceSource = "catalogue"
+)
+
+// CostIndexValue is the construction cost index for a month
+type CostIndexValue struct {
+	ID             uuid.UUID       `json:"id" db:"id"`
+	OrganizationID uuid.UUID       `json:"organization_id" db:"organization_id"`
+	Period         time.Time       `json:"period" db:"period"` // first day of the month
+	Value          decimal.Decimal `json:"value" db:"value"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// MessageCostCurrency is the currency of the static rate table and of monthly caps
+const MessageCostCurrency = "EUR"
+
+// Message cost sources
+const (
+	MessageCostSourceRateTable = "rate_table" // estimated from MessageRates
+	MessageCostSourceProvider  = "provider"   // price reported by the provider
+)
+
+// NotificationTypeMessageCapReached is sent to admins when the monthly message cap is reached
+const NotificationTypeMessageCapReached = "message_cap_reached"
+
+// MessageRates is the static cost per message used until the provider reports the actual price
+var MessageRates = map[MessageChannel]decimal.Decimal{
+	MessageChannelWhatsApp: decimal.RequireFromString("0.05"),
+	MessageChannelEmail:    decimal.Zero,
+}
+
+// MessageCost is the provider cost of one outbound message
+type MessageCost struct {
+	ID             uuid.UUID       `json:"id" db:"id"`
+	OrganizationID uuid.UUID       `json:"organization_id" db:"organization_id"`
+	Channel        MessageChannel  `json:"channel" db:"channel"`
+	Provider       string          `json:"provider" db:"provider"`
+	MessageSID     *string         `json:"message_sid" db:"message_sid"`
+	Cost           decimal.Decimal `json:"cost" db:"cost"`
+	Currency       string          `json:"currency" db:"currency"`
+	Source         string          `json:"source" db:"source"`
+	Critical       bool            `json:"critical" db:"critical"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// MessageSpendMonth aggregates the messages of a channel sent in a month
+type MessageSpendMonth struct {
+	Month    string          `json:"month"` // YYYY-MM
+	Channel  MessageChannel  `json:"channel"`
+	Messages int             `json:"messages"`
+	Cost     decimal.Decimal `json:"cost"`
+}
+
+// MessageSpendReport is the message spend of an organization per month and channel
+type MessageSpendReport struct {
+	Months       []MessageSpendMonth `json:"months"`
+	CurrentSpend decimal.Decimal     `json:"current_spend"`
+	MonthlyCap   *decimal.Decimal    `json:"monthly_cap"`
+	CapReached   bool                `json:"cap_reached"`
+	Currency     string              `json:"currency"`
+}
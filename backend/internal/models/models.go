@@ -9,17 +9,19 @@ import (
 
 // Organization represents a company/tenant
 type Organization struct {
-	ID        uuid.UUID  `json:"id" db:"id"`
-	Name      string     `json:"name" db:"name"`
-	Email     string     `json:"email" db:"email"`
-	Phone     string     `json:"phone" db:"phone"`
-	Address   string     `json:"address" db:"address"`
-	TaxID     string     `json:"tax_id" db:"tax_id"`
-	Logo      *string    `json:"logo" db:"logo"`
-	IsActive  bool       `json:"is_active" db:"is_active"`
-	CreatedAt time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
-	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	ID             uuid.UUID  `json:"id" db:"id"`
+	Name           string     `json:"name" db:"name"`
+	Email          string     `json:"email" db:"email"`
+	Phone          string     `json:"phone" db:"phone"`
+	Address        string     `json:"address" db:"address"`
+	TaxID          string     `json:"tax_id" db:"tax_id"`
+	Logo           *string    `json:"logo" db:"logo"`
+	DefaultCountry string     `json:"default_country" db:"default_country"` // ISO 3166-1 alpha-2, for phone numbers without a country code
+	Timezone       string     `json:"timezone" db:"timezone"`               // IANA name dates and times are shown in
+	IsActive       bool       `json:"is_active" db:"is_active"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
 }
 
 // User represents a user in the system
@@ -50,6 +52,15 @@ const (
 	RoleAccountant Role = "accountant"
 )
 
+// IsStaff returns true if the role is a staff role that tenant admins can assign
+func (r Role) IsStaff() bool {
+	switch r {
+	case RoleAdmin, RoleManager, RoleEmployee, RoleAccountant:
+		return true
+	}
+	return false
+}
+
 // Client represents a customer
 type Client struct {
 	ID             uuid.UUID  `json:"id" db:"id"`
@@ -60,11 +71,15 @@ type Client struct {
 	Address        *string    `json:"address" db:"address"`
 	TaxID          *string    `json:"tax_id" db:"tax_id"`
 	Notes          *string    `json:"notes" db:"notes"`
+	Segment        *string    `json:"segment" db:"segment"`
 	UserID         *uuid.UUID `json:"user_id" db:"user_id"` // If client has portal access
 	CreatedBy      uuid.UUID  `json:"created_by" db:"created_by"`
 	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
 	DeletedAt      *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+
+	// EmailSuppression is set when the client's email bounced or complained
+	EmailSuppression *EmailSuppression `json:"email_suppression,omitempty" db:"-"`
 }
 
 // WorkSheet represents a folha de obra
@@ -123,6 +138,9 @@ type Budget struct {
 	ApprovedAt     *time.Time      `json:"approved_at" db:"approved_at"`
 	RejectedAt     *time.Time      `json:"rejected_at" db:"rejected_at"`
 	RejectionNotes *string         `json:"rejection_notes" db:"rejection_notes"`
+	LossReason     *LossReason     `json:"loss_reason" db:"loss_reason"`
+	LossNotes      *string         `json:"loss_notes" db:"loss_notes"`
+	CurrentRevision int            `json:"current_revision" db:"current_revision"` // last revision sent, 0 before the first send
 	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
 	UpdatedAt      time.Time       `json:"updated_at" db:"updated_at"`
 	DeletedAt      *time.Time      `json:"deleted_at,omitempty" db:"deleted_at"`
@@ -131,13 +149,35 @@ type Budget struct {
 type BudgetStatus string
 
 const (
-	BudgetStatusDraft    BudgetStatus = "draft"
-	BudgetStatusSent     BudgetStatus = "sent"
-	BudgetStatusApproved BudgetStatus = "approved"
-	BudgetStatusRejected BudgetStatus = "rejected"
-	BudgetStatusExpired  BudgetStatus = "expired"
+	BudgetStatusDraft                   BudgetStatus = "draft"
+	BudgetStatusPendingInternalApproval BudgetStatus = "pending_internal_approval"
+	BudgetStatusSent                    BudgetStatus = "sent"
+	BudgetStatusApproved                BudgetStatus = "approved"
+	BudgetStatusRejected                BudgetStatus = "rejected"
+	BudgetStatusExpired                 BudgetStatus = "expired"
 )
 
+// LossReason is the structured reason a budget was rejected or expired
+type LossReason string
+
+const (
+	LossReasonPrice      LossReason = "price"
+	LossReasonTiming     LossReason = "timing"
+	LossReasonCompetitor LossReason = "competitor"
+	LossReasonScope      LossReason = "scope"
+	LossReasonNoResponse LossReason = "no_response"
+	LossReasonOther      LossReason = "other"
+)
+
+// IsValid returns true if the loss reason is known
+func (r LossReason) IsValid() bool {
+	switch r {
+	case LossReasonPrice, LossReasonTiming, LossReasonCompetitor, LossReasonScope, LossReasonNoResponse, LossReasonOther:
+		return true
+	}
+	return false
+}
+
 // BudgetItem represents an item in the budget
 type BudgetItem struct {
 	ID              uuid.UUID       `json:"id" db:"id"`
@@ -163,17 +203,36 @@ type Project struct {
 	ProjectNumber  string        `json:"project_number" db:"project_number"`
 	Title          string        `json:"title" db:"title"`
 	Description    *string       `json:"description" db:"description"`
+	Category       *string       `json:"category" db:"category"`
 	Status         ProjectStatus `json:"status" db:"status"`
-	Progress       int           `json:"progress" db:"progress"` // 0-100
+	Progress       int           `json:"progress" db:"progress"` // 0-100, the override when set
+	ProgressMode   ProjectProgressMode `json:"progress_mode" db:"progress_mode"`
+	ComputedProgress *int        `json:"computed_progress" db:"computed_progress"` // from the tasks, nil without tasks
+	ProgressOverride *int        `json:"progress_override" db:"progress_override"` // set by hand in tasks mode
+	ProgressOverrideBy *uuid.UUID `json:"progress_override_by" db:"progress_override_by"`
+	ProgressOverrideAt *time.Time `json:"progress_override_at" db:"progress_override_at"`
 	StartDate      time.Time     `json:"start_date" db:"start_date"`
 	ExpectedEndDate time.Time    `json:"expected_end_date" db:"expected_end_date"`
 	ActualEndDate  *time.Time    `json:"actual_end_date" db:"actual_end_date"`
+	TemplateID     *uuid.UUID    `json:"template_id" db:"template_id"`
 	CreatedBy      uuid.UUID     `json:"created_by" db:"created_by"`
 	CreatedAt      time.Time     `json:"created_at" db:"created_at"`
 	UpdatedAt      time.Time     `json:"updated_at" db:"updated_at"`
 	DeletedAt      *time.Time    `json:"deleted_at,omitempty" db:"deleted_at"`
 }
 
+// ProjectWithDetails includes the client and the financial summary of a project
+type ProjectWithDetails struct {
+	Project
+	BudgetNumber string          `json:"budget_number" db:"budget_number"`
+	ClientID     *uuid.UUID      `json:"client_id" db:"client_id"`
+	ClientName   string          `json:"client_name" db:"client_name"`
+	BudgetTotal  decimal.Decimal `json:"budget_total" db:"budget_total"`
+	TotalBilled  decimal.Decimal `json:"total_billed" db:"total_billed"`
+	TotalPaid    decimal.Decimal `json:"total_paid" db:"total_paid"`
+	Outstanding  decimal.Decimal `json:"outstanding" db:"outstanding"`
+}
+
 type ProjectStatus string
 
 const (
@@ -183,15 +242,25 @@ const (
 	ProjectStatusCancelled  ProjectStatus = "cancelled"
 )
 
+// ProjectProgressMode tells whether a project's progress is set by hand or computed from its tasks
+type ProjectProgressMode string
+
+const (
+	ProjectProgressManual ProjectProgressMode = "manual"
+	ProjectProgressTasks  ProjectProgressMode = "tasks"
+)
+
 // Task represents a task in a project
 type Task struct {
 	ID          uuid.UUID   `json:"id" db:"id"`
 	ProjectID   uuid.UUID   `json:"project_id" db:"project_id"`
+	MilestoneID *uuid.UUID  `json:"milestone_id" db:"milestone_id"`
 	Title       string      `json:"title" db:"title"`
 	Description *string     `json:"description" db:"description"`
 	AssignedTo  *uuid.UUID  `json:"assigned_to" db:"assigned_to"`
 	Status      TaskStatus  `json:"status" db:"status"`
 	Priority    Priority    `json:"priority" db:"priority"`
+	Weight      int         `json:"weight" db:"weight"` // share of the project's progress
 	DueDate     *time.Time  `json:"due_date" db:"due_date"`
 	CompletedAt *time.Time  `json:"completed_at" db:"completed_at"`
 	CreatedBy   uuid.UUID   `json:"created_by" db:"created_by"`
@@ -230,12 +299,23 @@ type Payment struct {
 	Method         *string         `json:"method" db:"method"`
 	Reference      *string         `json:"reference" db:"reference"`
 	Notes          *string         `json:"notes" db:"notes"`
+	MBEntity       *string         `json:"mb_entity" db:"mb_entity"` // current Multibanco reference
+	MBReference    *string         `json:"mb_reference" db:"mb_reference"`
+	SEPAReference  *string         `json:"sepa_reference" db:"sepa_reference"` // RF reference of SEPA transfers
 	CreatedBy      uuid.UUID       `json:"created_by" db:"created_by"`
 	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
 	UpdatedAt      time.Time       `json:"updated_at" db:"updated_at"`
 	DeletedAt      *time.Time      `json:"deleted_at,omitempty" db:"deleted_at"`
 }
 
+// PaymentWithDetails includes the project and client a payment is billed to
+type PaymentWithDetails struct {
+	Payment
+	ProjectNumber string `json:"project_number" db:"project_number"`
+	ProjectTitle  string `json:"project_title" db:"project_title"`
+	ClientName    string `json:"client_name" db:"client_name"`
+}
+
 type PaymentStatus string
 
 const (
@@ -279,11 +359,16 @@ type Notification struct {
 type NotificationType string
 
 const (
-	NotificationTypeWorkSheetReview NotificationType = "worksheet_review"
-	NotificationTypeBudgetSent      NotificationType = "budget_sent"
-	NotificationTypeBudgetApproved  NotificationType = "budget_approved"
-	NotificationTypeTaskAssigned    NotificationType = "task_assigned"
-	NotificationTypeTaskDue         NotificationType = "task_due"
-	NotificationTypePaymentDue      NotificationType = "payment_due"
-	NotificationTypeProjectUpdate   NotificationType = "project_update"
+	NotificationTypeWorkSheetReview  NotificationType = "worksheet_review"
+	NotificationTypeBudgetSent       NotificationType = "budget_sent"
+	NotificationTypeBudgetApproved   NotificationType = "budget_approved"
+	NotificationTypeBudgetRejected   NotificationType = "budget_rejected"
+	NotificationTypeTaskAssigned     NotificationType = "task_assigned"
+	NotificationTypeTaskDue          NotificationType = "task_due"
+	NotificationTypePaymentDue       NotificationType = "payment_due"
+	NotificationTypeProjectUpdate    NotificationType = "project_update"
+	NotificationTypeApprovalRequest  NotificationType = "approval_request"
+	NotificationTypeEscalation       NotificationType = "escalation"
+	NotificationTypeApprovalDecision NotificationType = "approval_decision"
+	NotificationTypeBudgetComment    NotificationType = "budget_comment"
 )
@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,17 +15,41 @@ const (
 	ModuleConstruction  ModuleName = "construction"
 	ModuleAppointments  ModuleName = "appointments"
 	ModuleNotifications ModuleName = "notifications"
+	ModuleInvoices      ModuleName = "invoices"
+)
+
+// ModulePricingTier is the commercial tier a module is sold in
+type ModulePricingTier string
+
+const (
+	ModulePricingFree     ModulePricingTier = "free"
+	ModulePricingStandard ModulePricingTier = "standard"
+	ModulePricingPremium  ModulePricingTier = "premium"
 )
 
 // AvailableModule represents a system-level module definition
 type AvailableModule struct {
-	Name         ModuleName      `json:"name" db:"name"`
-	DisplayName  string          `json:"display_name" db:"display_name"`
-	Description  *string         `json:"description" db:"description"`
-	Icon         *string         `json:"icon" db:"icon"`
-	Dependencies json.RawMessage `json:"dependencies" db:"dependencies"`
-	IsActive     bool            `json:"is_active" db:"is_active"`
-	CreatedAt    time.Time       `json:"created_at" db:"created_at"`
+	Name         ModuleName        `json:"name" db:"name"`
+	DisplayName  string            `json:"display_name" db:"display_name"`
+	Description  *string           `json:"description" db:"description"`
+	Icon         *string           `json:"icon" db:"icon"`
+	Dependencies json.RawMessage   `json:"dependencies" db:"dependencies"`
+	PricingTier  ModulePricingTier `json:"pricing_tier" db:"pricing_tier"`
+	Version      string            `json:"version" db:"version"`
+	IsActive     bool              `json:"is_active" db:"is_active"`
+	CreatedAt    time.Time         `json:"created_at" db:"created_at"`
+}
+
+// DependencyAlternatives splits a dependency entry into the modules that satisfy it.
+// "appointments|construction" is satisfied by either module being enabled.
+func DependencyAlternatives(dep string) []ModuleName {
+	var names []ModuleName
+	for _, name := range strings.Split(dep, "|") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, ModuleName(name))
+		}
+	}
+	return names
 }
 
 // GetDependencies parses the dependencies JSON into a string slice
@@ -55,10 +80,24 @@ type OrganizationModule struct {
 // OrganizationModuleWithDetails includes module details for API responses
 type OrganizationModuleWithDetails struct {
 	OrganizationModule
-	DisplayName  string   `json:"display_name"`
-	Description  *string  `json:"description"`
-	Icon         *string  `json:"icon"`
-	Dependencies []string `json:"dependencies"`
+	DisplayName   string   `json:"display_name"`
+	Description   *string  `json:"description"`
+	Icon          *string  `json:"icon"`
+	Dependencies  []string `json:"dependencies"`
+	PricingTier   string   `json:"pricing_tier"`
+	Version       string   `json:"version"`
+	UnseenChanges int      `json:"unseen_changes"` // changelog entries since the admins last opened the feed
+}
+
+// ModuleChangelogEntry is a release note in a module's what's-new feed
+type ModuleChangelogEntry struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	ModuleName ModuleName `json:"module_name" db:"module_name"`
+	Version    string     `json:"version" db:"version"`
+	Title      string     `json:"title" db:"title"`
+	Body       *string    `json:"body" db:"body"`
+	ReleasedAt time.Time  `json:"released_at" db:"released_at"`
+	IsNew      bool       `json:"is_new"`
 }
 
 // ModuleConfig represents module-specific configuration
@@ -85,3 +124,26 @@ func (m *OrganizationModule) SetConfig(config ModuleConfig) error {
 	m.Config = data
 	return nil
 }
+
+// ModuleConfigSchema describes a module's configuration as a JSON Schema (draft 2020-12 subset)
+// so clients can render settings forms generically
+type ModuleConfigSchema struct {
+	Schema               string                           `json:"$schema"`
+	Title                string                           `json:"title"`
+	Type                 string                           `json:"type"`
+	Properties           map[string]*ModuleConfigProperty `json:"properties"`
+	Required             []string                         `json:"required,omitempty"`
+	AdditionalProperties bool                             `json:"additionalProperties"`
+}
+
+// ModuleConfigProperty is a single setting in a module configuration schema
+type ModuleConfigProperty struct {
+	Type        string        `json:"type"` // boolean, integer, number or string
+	Title       string        `json:"title"`
+	Description string        `json:"description,omitempty"`
+	Default     interface{}   `json:"default"`
+	Enum        []interface{} `json:"enum,omitempty"`
+	Minimum     *float64      `json:"minimum,omitempty"`
+	Maximum     *float64      `json:"maximum,omitempty"`
+	MaxLength   *int          `json:"maxLength,omitempty"`
+}
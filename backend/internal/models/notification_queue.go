@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationQueueDepth is the size of one of the worker's job queues
+type NotificationQueueDepth struct {
+	Queue     string `json:"queue"`
+	Pending   int    `json:"pending"`
+	Scheduled int    `json:"scheduled"` // includes the messages spread out by the rate limits
+	Active    int    `json:"active"`
+	Retry     int    `json:"retry"`
+	// LatencySeconds is how long the oldest pending job has waited
+	LatencySeconds float64 `json:"latency_seconds"`
+}
+
+// NotificationSendBacklog is how far behind its rate limit a provider, or an organization on a
+// provider, is sending
+type NotificationSendBacklog struct {
+	OrganizationID   *uuid.UUID `json:"organization_id"` // nil for the provider's limit shared by every organization
+	OrganizationName *string    `json:"organization_name"`
+	Provider         string     `json:"provider"`
+	Waiting          int        `json:"waiting"` // messages holding a future send slot
+	DrainSeconds     float64    `json:"drain_seconds"`
+}
+
+// NotificationRunBacklog is the recipients of an organization's bulk trigger runs not run yet
+type NotificationRunBacklog struct {
+	OrganizationID    uuid.UUID `json:"organization_id"`
+	OrganizationName  string    `json:"organization_name"`
+	Runs              int       `json:"runs"`
+	PendingRecipients int       `json:"pending_recipients"`
+}
+
+// NotificationQueueStats shows the outbound notification load across organizations
+type NotificationQueueStats struct {
+	Queues      []*NotificationQueueDepth  `json:"queues"`
+	Backlogs    []*NotificationSendBacklog `json:"backlogs"`
+	RunBacklogs []*NotificationRunBacklog  `json:"run_backlogs"`
+	GeneratedAt time.Time                  `json:"generated_at"`
+}
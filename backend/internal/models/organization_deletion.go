@@ -0,0 +1,85 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrganizationDeletionStatus represents the progress of an organization deletion request
+type OrganizationDeletionStatus string
+
+const (
+	OrganizationDeletionPending   OrganizationDeletionStatus = "pending" // cooling off, can be cancelled
+	OrganizationDeletionCancelled OrganizationDeletionStatus = "cancelled"
+	OrganizationDeletionExporting OrganizationDeletionStatus = "exporting"
+	OrganizationDeletionExported  OrganizationDeletionStatus = "exported" // export available until the purge
+	OrganizationDeletionPurging   OrganizationDeletionStatus = "purging"
+	OrganizationDeletionCompleted OrganizationDeletionStatus = "completed"
+	OrganizationDeletionFailed    OrganizationDeletionStatus = "failed"
+)
+
+// OrganizationDeletionEventType is a step of an organization deletion request
+type OrganizationDeletionEventType string
+
+const (
+	OrganizationDeletionEventRequested     OrganizationDeletionEventType = "requested"
+	OrganizationDeletionEventReminderSent  OrganizationDeletionEventType = "reminder_sent"
+	OrganizationDeletionEventCancelled     OrganizationDeletionEventType = "cancelled"
+	OrganizationDeletionEventExportStarted OrganizationDeletionEventType = "export_started"
+	OrganizationDeletionEventExported      OrganizationDeletionEventType = "exported"
+	OrganizationDeletionEventPurgeStarted  OrganizationDeletionEventType = "purge_started"
+	OrganizationDeletionEventPurged        OrganizationDeletionEventType = "purged"
+	OrganizationDeletionEventFailed        OrganizationDeletionEventType = "failed"
+	OrganizationDeletionEventRetried       OrganizationDeletionEventType = "retried"
+)
+
+// OrganizationPurgeReport is what the purge of an organization removed
+type OrganizationPurgeReport struct {
+	Rows  map[string]int64 `json:"rows"` // rows deleted per table, besides those removed by cascade
+	Files int              `json:"files"`
+}
+
+// OrganizationDeletionRequest is an organization admin's request to delete the organization,
+// run by the worker once its cooling-off period ends
+type OrganizationDeletionRequest struct {
+	ID               uuid.UUID                  `json:"id" db:"id"`
+	OrganizationID   uuid.UUID                  `json:"organization_id" db:"organization_id"`
+	OrganizationName string                     `json:"organization_name" db:"organization_name"`
+	Status           OrganizationDeletionStatus `json:"status" db:"status"`
+	Reason           *string                    `json:"reason" db:"reason"`
+	RequestedBy      *uuid.UUID                 `json:"requested_by" db:"requested_by"`
+	RequestedByEmail string                     `json:"requested_by_email" db:"requested_by_email"`
+	CoolingOffEndsAt time.Time                  `json:"cooling_off_ends_at" db:"cooling_off_ends_at"`
+	RemindersSent    int                        `json:"reminders_sent" db:"reminders_sent"`
+	CancelledBy      *uuid.UUID                 `json:"cancelled_by" db:"cancelled_by"`
+	CancelledAt      *time.Time                 `json:"cancelled_at" db:"cancelled_at"`
+	ExportFileURL    *string                    `json:"-" db:"export_file_url"`
+	ExportFileName   *string                    `json:"export_file_name" db:"export_file_name"`
+	ExportFileSize   *int64                     `json:"export_file_size" db:"export_file_size"`
+	ExportedAt       *time.Time                 `json:"exported_at" db:"exported_at"`
+	PurgeAfter       *time.Time                 `json:"purge_after" db:"purge_after"`
+	PurgeReport      *OrganizationPurgeReport   `json:"purge_report" db:"purge_report"`
+	Error            *string                    `json:"error" db:"error"`
+	CompletedAt      *time.Time                 `json:"completed_at" db:"completed_at"`
+	CreatedAt        time.Time                  `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time                  `json:"updated_at" db:"updated_at"`
+
+	// Temporary link to the export, while it is available
+	DownloadURL *string `json:"download_url,omitempty" db:"-"`
+	// Steps of the request, when fetched on its own
+	Events []OrganizationDeletionEvent `json:"events,omitempty" db:"-"`
+}
+
+// OrganizationDeletionEvent is a step of a deletion request and who took it: an organization
+// user, a system admin or, when neither is set, the worker
+type OrganizationDeletionEvent struct {
+	ID        uuid.UUID                     `json:"id" db:"id"`
+	RequestID uuid.UUID                     `json:"request_id" db:"request_id"`
+	Event     OrganizationDeletionEventType `json:"event" db:"event"`
+	UserID    *uuid.UUID                    `json:"user_id" db:"user_id"`
+	AdminID   *uuid.UUID                    `json:"admin_id" db:"admin_id"`
+	Details   json.RawMessage               `json:"details" db:"details"`
+	CreatedAt time.Time                     `json:"created_at" db:"created_at"`
+}
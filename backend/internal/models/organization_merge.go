@@ -0,0 +1,94 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MergeStatus represents the progress of an organization merge
+type MergeStatus string
+
+const (
+	MergePending    MergeStatus = "pending"
+	MergeProcessing MergeStatus = "processing"
+	MergeCompleted  MergeStatus = "completed"
+	MergeFailed     MergeStatus = "failed"
+)
+
+// MergeStep is a unit of an organization merge, committed on its own
+type MergeStep string
+
+const (
+	MergeStepUsers      MergeStep = "users"
+	MergeStepClients    MergeStep = "clients"
+	MergeStepPatients   MergeStep = "patients"
+	MergeStepTherapists MergeStep = "therapists"
+	MergeStepWorkflows  MergeStep = "workflows"
+	MergeStepProjects   MergeStep = "projects"
+	MergeStepSessions   MergeStep = "sessions"
+	MergeStepRecords    MergeStep = "records" // every other organization-scoped record
+	MergeStepFinalize   MergeStep = "finalize"
+)
+
+// MergeSteps lists the steps in the order they run
+var MergeSteps = []MergeStep{
+	MergeStepUsers, MergeStepClients, MergeStepPatients, MergeStepTherapists,
+	MergeStepWorkflows, MergeStepProjects, MergeStepSessions, MergeStepRecords, MergeStepFinalize,
+}
+
+// Conflict resolutions
+const (
+	MergeResolveMerge    = "merge"     // the source record is folded into the matching target record
+	MergeResolveKeepBoth = "keep_both" // both records are kept
+	MergeResolveRename   = "rename"    // the source record is moved with the source organization name appended
+	MergeResolveSkip     = "skip"      // the source record stays behind in the source organization
+)
+
+// OrganizationMergeRules decides what happens to source records that clash with target records.
+// Users with the same email and patients with the same phone are always folded into the target
+// record, as the database does not allow both.
+type OrganizationMergeRules struct {
+	Clients      string `json:"clients"`       // same email: merge (default) or keep_both
+	Workflows    string `json:"workflows"`     // same name: rename (default) or skip
+	Templates    string `json:"templates"`     // same name and channel: rename (default) or skip
+	NumberPrefix string `json:"number_prefix"` // prepended to clashing budget and project numbers
+}
+
+// MergeConflict is a source record that clashed with a target record and how it was resolved
+type MergeConflict struct {
+	Entity     string     `json:"entity"`
+	SourceID   uuid.UUID  `json:"source_id"`
+	TargetID   *uuid.UUID `json:"target_id,omitempty"`
+	Key        string     `json:"key"` // the clashing value, e.g. the email
+	Resolution string     `json:"resolution"`
+}
+
+// MergeStepReport is what a merge step did, or would do in a dry run
+type MergeStepReport struct {
+	Step        MergeStep       `json:"step"`
+	Moved       map[string]int  `json:"moved"` // rows moved per table
+	Merged      int             `json:"merged"`
+	Renamed     int             `json:"renamed"`
+	Skipped     int             `json:"skipped"`
+	Conflicts   []MergeConflict `json:"conflicts"`
+	Unmovable   map[string]int  `json:"unmovable,omitempty"` // rows per table that can't be moved, which refuse the merge
+	CompletedAt time.Time       `json:"completed_at"`
+}
+
+// OrganizationMerge is a request to merge one organization into another, run by the worker
+type OrganizationMerge struct {
+	ID                   uuid.UUID              `json:"id" db:"id"`
+	SourceOrganizationID uuid.UUID              `json:"source_organization_id" db:"source_organization_id"`
+	TargetOrganizationID uuid.UUID              `json:"target_organization_id" db:"target_organization_id"`
+	DryRun               bool                   `json:"dry_run" db:"dry_run"`
+	Status               MergeStatus            `json:"status" db:"status"`
+	Rules                OrganizationMergeRules `json:"rules" db:"rules"`
+	Report               []MergeStepReport      `json:"report" db:"report"`
+	CurrentStep          *MergeStep             `json:"current_step" db:"current_step"`
+	Error                *string                `json:"error" db:"error"`
+	RequestedBy          *uuid.UUID             `json:"requested_by" db:"requested_by"`
+	CreatedAt            time.Time              `json:"created_at" db:"created_at"`
+	StartedAt            *time.Time             `json:"started_at" db:"started_at"`
+	CompletedAt          *time.Time             `json:"completed_at" db:"completed_at"`
+}
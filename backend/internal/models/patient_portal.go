@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PatientPortalSettings is what an organization lets its patients do in the patient portal
+type PatientPortalSettings struct {
+	OrganizationID          uuid.UUID `json:"organization_id" db:"organization_id"`
+	IsEnabled               bool      `json:"is_enabled" db:"is_enabled"`
+	CancellationCutoffHours int       `json:"cancellation_cutoff_hours" db:"cancellation_cutoff_hours"`
+	AllowConfirmation       bool      `json:"allow_confirmation" db:"allow_confirmation"`
+	AllowCancellation       bool      `json:"allow_cancellation" db:"allow_cancellation"`
+	AllowContactUpdate      bool      `json:"allow_contact_update" db:"allow_contact_update"`
+	UpdatedAt               time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// PatientPortalProfile is the signed-in patient's own details
+type PatientPortalProfile struct {
+	PatientID        uuid.UUID `json:"patient_id"`
+	OrganizationName string    `json:"organization_name"`
+	Name             string    `json:"name"`
+	Email            string    `json:"email"`
+	Phone            string    `json:"phone"`
+	Address          *string   `json:"address"`
+	EmergencyContact *string   `json:"emergency_contact"`
+	EmergencyPhone   *string   `json:"emergency_phone"`
+
+	// What the organization allows in the portal
+	CancellationCutoffHours int  `json:"cancellation_cutoff_hours"`
+	AllowConfirmation       bool `json:"allow_confirmation"`
+	AllowCancellation       bool `json:"allow_cancellation"`
+	AllowContactUpdate      bool `json:"allow_contact_update"`
+}
+
+// PatientPortalSession is a session as the patient sees it, without the therapist's notes
+type PatientPortalSession struct {
+	ID              uuid.UUID     `json:"id"`
+	ScheduledAt     time.Time     `json:"scheduled_at"`
+	DurationMinutes int           `json:"duration_minutes"`
+	Status          SessionStatus `json:"status"`
+	SessionType     SessionType   `json:"session_type"`
+	TherapistName   string        `json:"therapist_name"`
+	ServiceName     *string       `json:"service_name"`
+	PriceCents      int           `json:"price_cents"`
+	CancelledAt     *time.Time    `json:"cancelled_at"`
+	CanConfirm      bool          `json:"can_confirm"`
+	CanCancel       bool          `json:"can_cancel"`
+}
+
+// PatientPortalPayment is an amount the patient still owes for a session or a cancellation
+type PatientPortalPayment struct {
+	ID            uuid.UUID            `json:"id"`
+	SessionID     uuid.UUID            `json:"session_id"`
+	ScheduledAt   time.Time            `json:"scheduled_at"`
+	Kind          SessionPaymentKind   `json:"kind"`
+	AmountCents   int                  `json:"amount_cents"`
+	PaymentStatus SessionPaymentStatus `json:"payment_status"`
+	DueDate       *time.Time           `json:"due_date"`
+	MBEntity      *string              `json:"mb_entity"`
+	MBReference   *string              `json:"mb_reference"`
+	SEPAReference *string              `json:"sepa_reference"`
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// PaymentProviderStripe is the only online payment provider supported
+const PaymentProviderStripe = "stripe"
+
+// PaymentLinkTargetType is what a payment link collects
+type PaymentLinkTargetType string
+
+const (
+	PaymentLinkTargetSessionPayment PaymentLinkTargetType = "session_payment"
+	PaymentLinkTargetPayment        PaymentLinkTargetType = "payment" // project payment
+	PaymentLinkTargetBudget         PaymentLinkTargetType = "budget"  // approved budget, paid in full
+)
+
+// PaymentLinkStatus represents the lifecycle of a payment link
+type PaymentLinkStatus string
+
+const (
+	PaymentLinkStatusOpen      PaymentLinkStatus = "open"
+	PaymentLinkStatusPaid      PaymentLinkStatus = "paid"
+	PaymentLinkStatusExpired   PaymentLinkStatus = "expired"
+	PaymentLinkStatusCancelled PaymentLinkStatus = "cancelled"
+)
+
+// PaymentLink is a hosted checkout page where a client pays a session payment, project payment
+// or approved budget online
+type PaymentLink struct {
+	ID                uuid.UUID             `json:"id" db:"id"`
+	OrganizationID    uuid.UUID             `json:"organization_id" db:"organization_id"`
+	Provider          string                `json:"provider" db:"provider"`
+	TargetType        PaymentLinkTargetType `json:"target_type" db:"target_type"`
+	TargetID          uuid.UUID             `json:"target_id" db:"target_id"`
+	ProviderReference string                `json:"provider_reference" db:"provider_reference"`
+	URL               string                `json:"url" db:"url"`
+	Amount            decimal.Decimal       `json:"amount" db:"amount"`
+	Currency          string                `json:"currency" db:"currency"`
+	Status            PaymentLinkStatus     `json:"status" db:"status"`
+	ExpiresAt         time.Time             `json:"expires_at" db:"expires_at"`
+	PaidAt            *time.Time            `json:"paid_at" db:"paid_at"`
+	PaymentID         *uuid.UUID            `json:"payment_id" db:"payment_id"` // recorded for paid budget links
+	CreatedBy         *uuid.UUID            `json:"created_by" db:"created_by"`
+	CreatedAt         time.Time             `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time             `json:"updated_at" db:"updated_at"`
+}
+
+// PaymentProviderConfig holds an organization's Stripe account credentials
+type PaymentProviderConfig struct {
+	OrganizationID         uuid.UUID `json:"organization_id" db:"organization_id"`
+	Provider               string    `json:"provider" db:"provider"`
+	IsEnabled              bool      `json:"is_enabled" db:"is_enabled"`
+	SecretKeyEncrypted     *string   `json:"-" db:"secret_key_encrypted"`
+	WebhookSecretEncrypted *string   `json:"-" db:"webhook_secret_encrypted"`
+	Currency               string    `json:"currency" db:"currency"`
+	WebhookToken           string    `json:"-" db:"webhook_token"`
+	CreatedAt              time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt              time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// PaymentProviderConfigPublic is the payment provider configuration without its secrets
+type PaymentProviderConfigPublic struct {
+	Provider         string `json:"provider"`
+	IsEnabled        bool   `json:"is_enabled"`
+	SecretKeySet     bool   `json:"secret_key_set"`
+	WebhookSecretSet bool   `json:"webhook_secret_set"`
+	Currency         string `json:"currency"`
+	// Webhook URL path to set in the Stripe dashboard for this organization
+	WebhookPath string `json:"webhook_path"`
+}
+
+// ToPublic converts PaymentProviderConfig to its public version
+func (c *PaymentProviderConfig) ToPublic() PaymentProviderConfigPublic {
+	return PaymentProviderConfigPublic{
+		Provider:         c.Provider,
+		IsEnabled:        c.IsEnabled,
+		SecretKeySet:     c.SecretKeyEncrypted != nil,
+		WebhookSecretSet: c.WebhookSecretEncrypted != nil,
+		Currency:         c.Currency,
+		WebhookPath:      "/webhooks/stripe/" + c.WebhookToken,
+	}
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Providers that generate Multibanco references
+const (
+	PaymentReferenceProviderIfthenpay = "ifthenpay"
+	PaymentReferenceProviderEasypay   = "easypay"
+)
+
+// PaymentReferenceTargetType is what a payment reference collects
+type PaymentReferenceTargetType string
+
+const (
+	PaymentReferenceTargetSessionPayment PaymentReferenceTargetType = "session_payment"
+	PaymentReferenceTargetPayment        PaymentReferenceTargetType = "payment" // project payment
+)
+
+// PaymentReferenceStatus represents the lifecycle of a payment reference
+type PaymentReferenceStatus string
+
+const (
+	PaymentReferenceStatusOpen      PaymentReferenceStatus = "open"
+	PaymentReferenceStatusPaid      PaymentReferenceStatus = "paid"
+	PaymentReferenceStatusCancelled PaymentReferenceStatus = "cancelled" // replaced or withdrawn
+)
+
+// PaymentReference is a Multibanco reference, and the RF reference of a SEPA transfer, a client
+// pays a session payment or project payment with
+type PaymentReference struct {
+	ID                uuid.UUID                  `json:"id" db:"id"`
+	OrganizationID    uuid.UUID                  `json:"organization_id" db:"organization_id"`
+	Provider          string                     `json:"provider" db:"provider"`
+	TargetType        PaymentReferenceTargetType `json:"target_type" db:"target_type"`
+	TargetID          uuid.UUID                  `json:"target_id" db:"target_id"`
+	MBEntity          *string                    `json:"mb_entity" db:"mb_entity"`
+	MBReference       *string                    `json:"mb_reference" db:"mb_reference"`
+	SEPAReference     *string                    `json:"sepa_reference" db:"sepa_reference"`
+	ProviderReference *string                    `json:"provider_reference" db:"provider_reference"`
+	Amount            decimal.Decimal            `json:"amount" db:"amount"`
+	Status            PaymentReferenceStatus     `json:"status" db:"status"`
+	ExpiresAt         time.Time                  `json:"expires_at" db:"expires_at"`
+	PaidAt            *time.Time                 `json:"paid_at" db:"paid_at"`
+	CreatedBy         *uuid.UUID                 `json:"created_by" db:"created_by"`
+	CreatedAt         time.Time                  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time                  `json:"updated_at" db:"updated_at"`
+}
+
+// PaymentReferenceConfig holds an organization's IfThenPay or Easypay account and the IBAN
+// SEPA transfers are made to
+type PaymentReferenceConfig struct {
+	OrganizationID       uuid.UUID `json:"organization_id" db:"organization_id"`
+	Provider             string    `json:"provider" db:"provider"`
+	IsEnabled            bool      `json:"is_enabled" db:"is_enabled"`
+	MBEntity             *string   `json:"mb_entity" db:"mb_entity"`
+	MBSubentity          *string   `json:"mb_subentity" db:"mb_subentity"`
+	AccountID            *string   `json:"account_id" db:"account_id"`
+	APIKeyEncrypted      *string   `json:"-" db:"api_key_encrypted"`
+	CallbackKeyEncrypted *string   `json:"-" db:"callback_key_encrypted"`
+	IBAN                 *string   `json:"iban" db:"iban"`
+	ValidityDays         int       `json:"validity_days" db:"validity_days"`
+	CallbackToken        string    `json:"-" db:"callback_token"`
+	CreatedAt            time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// PaymentReferenceConfigPublic is the payment reference configuration without its secrets
+type PaymentReferenceConfigPublic struct {
+	Provider       string  `json:"provider"`
+	IsEnabled      bool    `json:"is_enabled"`
+	MBEntity       *string `json:"mb_entity"`
+	MBSubentity    *string `json:"mb_subentity"`
+	AccountID      *string `json:"account_id"`
+	APIKeySet      bool    `json:"api_key_set"`
+	CallbackKeySet bool    `json:"callback_key_set"`
+	IBAN           *string `json:"iban"`
+	ValidityDays   int     `json:"validity_days"`
+	// Callback URL path to set in the provider's backoffice for this organization
+	CallbackPath string `json:"callback_path"`
+}
+
+// ToPublic converts PaymentReferenceConfig to its public version
+func (c *PaymentReferenceConfig) ToPublic() PaymentReferenceConfigPublic {
+	return PaymentReferenceConfigPublic{
+		Provider:       c.Provider,
+		IsEnabled:      c.IsEnabled,
+		MBEntity:       c.MBEntity,
+		MBSubentity:    c.MBSubentity,
+		AccountID:      c.AccountID,
+		APIKeySet:      c.APIKeyEncrypted != nil,
+		CallbackKeySet: c.CallbackKeyEncrypted != nil,
+		IBAN:           c.IBAN,
+		ValidityDays:   c.ValidityDays,
+		CallbackPath:   "/webhooks/" + c.Provider + "/" + c.CallbackToken,
+	}
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProjectTemplate represents a reusable project structure
+type ProjectTemplate struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	OrganizationID uuid.UUID  `json:"organization_id" db:"organization_id"`
+	Name           string     `json:"name" db:"name"`
+	Description    *string    `json:"description" db:"description"`
+	Category       *string    `json:"category" db:"category"`
+	IsActive       bool       `json:"is_active" db:"is_active"`
+	CreatedBy      *uuid.UUID `json:"created_by" db:"created_by"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+	// Nested data for full template retrieval
+	Phases    []ProjectTemplatePhase    `json:"phases,omitempty" db:"-"`
+	Tasks     []ProjectTemplateTask     `json:"tasks,omitempty" db:"-"`
+	Documents []ProjectTemplateDocument `json:"documents,omitempty" db:"-"`
+}
+
+// TotalDurationDays returns the number of days from project start to the end of the last phase or task
+func (t *ProjectTemplate) TotalDurationDays() int {
+	phaseStart := make(map[uuid.UUID]int)
+	total := 0
+	for _, p := range t.Phases {
+		phaseStart[p.ID] = p.StartOffsetDays
+		if end := p.StartOffsetDays + p.DurationDays; end > total {
+			total = end
+		}
+	}
+	for _, task := range t.Tasks {
+		start := task.StartOffsetDays
+		if task.PhaseID != nil {
+			start += phaseStart[*task.PhaseID]
+		}
+		if end := start + task.DurationDays; end > total {
+			total = end
+		}
+	}
+	return total
+}
+
+// ProjectTemplatePhase represents a phase of a project template; it becomes a milestone
+type ProjectTemplatePhase struct {
+	ID              uuid.UUID `json:"id" db:"id"`
+	TemplateID      uuid.UUID `json:"template_id" db:"template_id"`
+	Name            string    `json:"name" db:"name"`
+	Description     *string   `json:"description" db:"description"`
+	Position        int       `json:"position" db:"position"`
+	StartOffsetDays int       `json:"start_offset_days" db:"start_offset_days"`
+	DurationDays    int       `json:"duration_days" db:"duration_days"`
+}
+
+// ProjectTemplateTask represents a task of a project template, scheduled relative to its phase
+type ProjectTemplateTask struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	TemplateID      uuid.UUID  `json:"template_id" db:"template_id"`
+	PhaseID         *uuid.UUID `json:"phase_id" db:"phase_id"`
+	Title           string     `json:"title" db:"title"`
+	Description     *string    `json:"description" db:"description"`
+	Priority        Priority   `json:"priority" db:"priority"`
+	Position        int        `json:"position" db:"position"`
+	StartOffsetDays int        `json:"start_offset_days" db:"start_offset_days"`
+	DurationDays    int        `json:"duration_days" db:"duration_days"`
+}
+
+// ProjectTemplateDocument represents a document a project created from the template must collect
+type ProjectTemplateDocument struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	TemplateID  uuid.UUID `json:"template_id" db:"template_id"`
+	Name        string    `json:"name" db:"name"`
+	Description *string   `json:"description" db:"description"`
+	IsRequired  bool      `json:"is_required" db:"is_required"`
+	Position    int       `json:"position" db:"position"`
+}
+
+// ProjectMilestone represents a milestone of a project
+type ProjectMilestone struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	ProjectID   uuid.UUID  `json:"project_id" db:"project_id"`
+	Name        string     `json:"name" db:"name"`
+	Description *string    `json:"description" db:"description"`
+	Position    int        `json:"position" db:"position"`
+	StartDate   time.Time  `json:"start_date" db:"start_date"`
+	DueDate     time.Time  `json:"due_date" db:"due_date"`
+	CompletedAt *time.Time `json:"completed_at" db:"completed_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// ProjectDocumentStatus represents the collection status of a project document
+type ProjectDocumentStatus string
+
+const (
+	ProjectDocumentStatusPending  ProjectDocumentStatus = "pending"
+	ProjectDocumentStatusReceived ProjectDocumentStatus = "received"
+)
+
+// ProjectDocument represents a document a project must collect
+type ProjectDocument struct {
+	ID          uuid.UUID             `json:"id" db:"id"`
+	ProjectID   uuid.UUID             `json:"project_id" db:"project_id"`
+	Name        string                `json:"name" db:"name"`
+	Description *string               `json:"description" db:"description"`
+	IsRequired  bool                  `json:"is_required" db:"is_required"`
+	Status      ProjectDocumentStatus `json:"status" db:"status"`
+	FileURL     *string               `json:"file_url" db:"file_url"`
+	ReceivedAt  *time.Time            `json:"received_at" db:"received_at"`
+	CreatedAt   time.Time             `json:"created_at" db:"created_at"`
+}
+
+// ProjectFromTemplate is the result of instantiating a project template
+type ProjectFromTemplate struct {
+	Project    *Project            `json:"project"`
+	Milestones []*ProjectMilestone `json:"milestones"`
+	Tasks      []*Task             `json:"tasks"`
+	Documents  []*ProjectDocument  `json:"documents"`
+}
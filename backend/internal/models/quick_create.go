@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QuickMatch is a record matched against a name typed in a quick-create text
+type QuickMatch struct {
+	ID    uuid.UUID `json:"id"`
+	Name  string    `json:"name"`
+	Score int       `json:"score"` // 0-100
+}
+
+// QuickSessionDraft is what a quick-create text resolves to. Nothing is booked until the
+// draft is confirmed; Missing lists what still has to be picked or typed before it can be.
+type QuickSessionDraft struct {
+	Text                string       `json:"text"`
+	Patient             *QuickMatch  `json:"patient"`
+	PatientCandidates   []QuickMatch `json:"patient_candidates"`
+	Therapist           *QuickMatch  `json:"therapist"`
+	TherapistCandidates []QuickMatch `json:"therapist_candidates"`
+	// The therapist was not named and was picked by auto-assignment
+	TherapistAutoAssigned bool       `json:"therapist_auto_assigned"`
+	ScheduledAt           *time.Time `json:"scheduled_at"`
+	DurationMinutes       int        `json:"duration_minutes"`
+	PriceCents            int        `json:"price_cents"`
+	Timezone              string     `json:"timezone"`
+	Conflict              bool       `json:"conflict"`
+	Missing               []string   `json:"missing"`
+	Ready                 bool       `json:"ready"`
+
+	// Set once the draft is confirmed
+	Session *SessionWithDetails `json:"session,omitempty"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReminderMigrationStatus is what the migration does with one legacy reminder
+type ReminderMigrationStatus string
+
+const (
+	ReminderMigrationCreate   ReminderMigrationStatus = "create"   // a workflow trigger sends it
+	ReminderMigrationExisting ReminderMigrationStatus = "existing" // the workflow already sends it
+	ReminderMigrationSkipped  ReminderMigrationStatus = "skipped"  // it was not being sent
+)
+
+// ReminderMigrationStep is a legacy 24h/2h reminder and the session workflow trigger replacing it.
+// Each reminder becomes one time_before trigger per session status it was sent in.
+type ReminderMigrationStep struct {
+	ReminderType  ReminderType            `json:"reminder_type"`
+	State         string                  `json:"state"`
+	OffsetMinutes int                     `json:"offset_minutes"`
+	Status        ReminderMigrationStatus `json:"status"`
+	Reason        *string                 `json:"reason,omitempty"`
+	TemplateName  string                  `json:"template_name,omitempty"`
+	Body          string                  `json:"body,omitempty"` // legacy template with the workflow variables
+	TriggerID     *uuid.UUID              `json:"trigger_id,omitempty"`
+}
+
+// ReminderMigration is the conversion of an organization's legacy reminders into session
+// workflow triggers, either planned (dry run) or done
+type ReminderMigration struct {
+	DryRun          bool                    `json:"dry_run"`
+	WorkflowID      *uuid.UUID              `json:"workflow_id"`
+	WorkflowName    string                  `json:"workflow_name"`
+	CreatesWorkflow bool                    `json:"creates_workflow"`
+	Steps           []ReminderMigrationStep `json:"steps"`
+	// Legacy reminders still to be sent, scheduled as workflow jobs by the migration
+	PendingReminders int        `json:"pending_reminders"`
+	MigratedAt       *time.Time `json:"migrated_at"`
+}
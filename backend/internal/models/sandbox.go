@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Sandbox is an organization cloned from another one to experiment with workflows safely
+type Sandbox struct {
+	ID                   uuid.UUID `json:"id"`
+	Name                 string    `json:"name"`
+	SourceOrganizationID uuid.UUID `json:"source_organization_id"`
+	WorkflowCount        int       `json:"workflow_count"`
+	CreatedAt            time.Time `json:"created_at"`
+}
+
+// SandboxCloneResult describes what was copied into a new sandbox
+type SandboxCloneResult struct {
+	Sandbox         *Sandbox `json:"sandbox"`
+	WorkflowsCloned []string `json:"workflows_cloned"`
+	// Workflows that could not be cloned, with the reason
+	WorkflowsFailed map[string]string `json:"workflows_failed"`
+	Templates       int               `json:"templates"`
+	Clients         int               `json:"clients"`
+	Patients        int               `json:"patients"`
+	Therapists      int               `json:"therapists"`
+	Sessions        int               `json:"sessions"`
+	Budgets         int               `json:"budgets"`
+	Projects        int               `json:"projects"`
+}
+
+// SandboxAccess is a token to work in a sandbox as its administrator
+type SandboxAccess struct {
+	Token     string    `json:"token"`
+	SandboxID uuid.UUID `json:"sandbox_id"`
+	User      *User     `json:"user"`
+}
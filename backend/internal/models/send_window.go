@@ -0,0 +1,108 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// DefaultTimezone is the timezone of organizations that haven't chosen one
+const DefaultTimezone = "Europe/Lisbon"
+
+// DefaultSendWindowTimezone is the timezone of send windows that don't name one
+const DefaultSendWindowTimezone = DefaultTimezone
+
+// SendWindow is the part of the day an organization's messages may go out. Outside it are the
+// quiet hours: messages that would be sent then are deferred to the start of the next window.
+type SendWindow struct {
+	Start string `json:"start"` // "09:00"
+	End   string `json:"end"`   // "20:00"; an end before the start closes the window the next day
+	// ISO weekdays, 1 (Monday) to 7 (Sunday), the window opens on; every day when empty
+	Days     []int  `json:"days"`
+	Timezone string `json:"timezone"` // IANA name; set to the organization's when saved without one
+}
+
+// Validate checks the window's times, days and timezone
+func (w *SendWindow) Validate() error {
+	start, err := time.Parse("15:04", w.Start)
+	if err != nil {
+		return errors.New("send window start must be a time like 09:00")
+	}
+	end, err := time.Parse("15:04", w.End)
+	if err != nil {
+		return errors.New("send window end must be a time like 20:00")
+	}
+	if start.Equal(end) {
+		return errors.New("send window must start and end at different times")
+	}
+	for _, d := range w.Days {
+		if d < 1 || d > 7 {
+			return errors.New("send window days must be between 1 (Monday) and 7 (Sunday)")
+		}
+	}
+	if _, err := time.LoadLocation(w.timezone()); err != nil {
+		return errors.New("invalid timezone")
+	}
+	return nil
+}
+
+func (w *SendWindow) timezone() string {
+	if w.Timezone == "" {
+		return DefaultSendWindowTimezone
+	}
+	return w.Timezone
+}
+
+// opensOn reports whether the window opens on a weekday
+func (w *SendWindow) opensOn(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	iso := int(day)
+	if iso == 0 {
+		iso = 7
+	}
+	for _, d := range w.Days {
+		if d == iso {
+			return true
+		}
+	}
+	return false
+}
+
+// NextAllowed returns t when it falls inside the window, otherwise when the window next opens.
+// An invalid window allows any time.
+func (w *SendWindow) NextAllowed(t time.Time) time.Time {
+	loc, err := time.LoadLocation(w.timezone())
+	if err != nil {
+		return t
+	}
+	start, err := time.Parse("15:04", w.Start)
+	if err != nil {
+		return t
+	}
+	end, err := time.Parse("15:04", w.End)
+	if err != nil {
+		return t
+	}
+
+	local := t.In(loc)
+	// A window that closes the next day may have opened the day before
+	for offset := -1; offset <= 7; offset++ {
+		day := time.Date(local.Year(), local.Month(), local.Day()+offset, 0, 0, 0, 0, loc)
+		if !w.opensOn(day.Weekday()) {
+			continue
+		}
+		opens := time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), 0, 0, loc)
+		closes := time.Date(day.Year(), day.Month(), day.Day(), end.Hour(), end.Minute(), 0, 0, loc)
+		if !closes.After(opens) {
+			closes = closes.AddDate(0, 0, 1)
+		}
+		if !local.Before(opens) && local.Before(closes) {
+			return t
+		}
+		if opens.After(local) {
+			return opens
+		}
+	}
+	return t
+}
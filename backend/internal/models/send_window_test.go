@@ -0,0 +1,65 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSendWindowNextAllowed(t *testing.T) {
+	lisbon, err := time.LoadLocation("Europe/Lisbon")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+	at := func(day, hour, minute int) time.Time {
+		// March 2026: the 2nd is a Monday; clocks go forward on the 29th
+		return time.Date(2026, 3, day, hour, minute, 0, 0, lisbon)
+	}
+	weekdays := &SendWindow{Start: "09:00", End: "20:00", Days: []int{1, 2, 3, 4, 5}}
+	overnight := &SendWindow{Start: "22:00", End: "06:00", Timezone: "Europe/Lisbon"}
+
+	tests := []struct {
+		name   string
+		window *SendWindow
+		t      time.Time
+		want   time.Time
+	}{
+		{"inside the window", weekdays, at(3, 10, 30), at(3, 10, 30)},
+		{"before it opens", weekdays, at(3, 3, 0), at(3, 9, 0)},
+		{"after it closes", weekdays, at(3, 20, 0), at(4, 9, 0)},
+		{"friday night to monday", weekdays, at(6, 21, 0), at(9, 9, 0)},
+		{"overnight, after midnight", overnight, at(3, 2, 0), at(3, 2, 0)},
+		{"overnight, during the day", overnight, at(3, 12, 0), at(3, 22, 0)},
+		{"across the clock change", weekdays, at(28, 23, 0), at(30, 9, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.window.NextAllowed(tt.t); !got.Equal(tt.want) {
+				t.Errorf("NextAllowed(%v) = %v, want %v", tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendWindowValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		window  SendWindow
+		wantErr bool
+	}{
+		{"valid", SendWindow{Start: "08:30", End: "21:00", Days: []int{1, 7}}, false},
+		{"overnight", SendWindow{Start: "22:00", End: "06:00", Timezone: "Europe/Madrid"}, false},
+		{"bad time", SendWindow{Start: "8h", End: "21:00"}, true},
+		{"empty window", SendWindow{Start: "09:00", End: "09:00"}, true},
+		{"bad day", SendWindow{Start: "09:00", End: "20:00", Days: []int{0}}, true},
+		{"bad timezone", SendWindow{Start: "09:00", End: "20:00", Timezone: "Lisboa"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.window.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CommunicationSource is what scheduled a message to a patient
+type CommunicationSource string
+
+const (
+	CommunicationSourceReminder CommunicationSource = "reminder" // built-in 24h/2h WhatsApp reminder
+	CommunicationSourceWorkflow CommunicationSource = "workflow" // time-based workflow trigger
+)
+
+// UpcomingCommunication is a message still scheduled for a session, rendered as the patient will receive it
+type UpcomingCommunication struct {
+	Source       CommunicationSource `json:"source"`
+	ReferenceID  uuid.UUID           `json:"reference_id"` // scheduled reminder or scheduled job
+	Type         string              `json:"type"`
+	Channel      MessageChannel      `json:"channel"`
+	ScheduledFor time.Time           `json:"scheduled_for"`
+	Recipient    *string             `json:"recipient"`
+	Subject      *string             `json:"subject,omitempty"`
+	Preview      string              `json:"preview"`
+	TemplateName *string             `json:"template_name,omitempty"`
+	// Sent as the approved WhatsApp template because the customer service window will be closed
+	ApprovedTemplate bool `json:"approved_template,omitempty"`
+	// Trigger conditions are evaluated at send time and may still skip the message
+	Conditional bool    `json:"conditional,omitempty"`
+	WillSend    bool    `json:"will_send"`
+	SkipReason  *string `json:"skip_reason,omitempty"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SessionRescheduleView is the session a reschedule link moves, as the patient sees it
+type SessionRescheduleView struct {
+	SessionID        uuid.UUID `json:"session_id"`
+	OrganizationName string    `json:"organization_name"`
+	PatientName      string    `json:"patient_name"`
+	TherapistName    string    `json:"therapist_name"`
+	ServiceName      *string   `json:"service_name"`
+	ScheduledAt      time.Time `json:"scheduled_at"`
+	DurationMinutes  int       `json:"duration_minutes"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecurrenceFrequency is how often a session series repeats
+type RecurrenceFrequency string
+
+const (
+	RecurrenceWeekly   RecurrenceFrequency = "weekly"
+	RecurrenceBiweekly RecurrenceFrequency = "biweekly"
+)
+
+// RecurrenceRule is a subset of an iCalendar RRULE: a weekly or biweekly repetition on one or
+// more weekdays (MO, TU, WE, TH, FR, SA, SU), ending after a number of occurrences or on a date
+type RecurrenceRule struct {
+	Frequency RecurrenceFrequency `json:"frequency"`
+	// Weekdays defaults to the weekday of the first session
+	Weekdays []string `json:"weekdays"`
+	Count    *int     `json:"count,omitempty"`
+	// Until is the last day (inclusive) a session may fall on, in the therapist's time zone
+	Until *time.Time `json:"until,omitempty"`
+}
+
+// SessionSeries is a group of sessions created from one recurrence rule
+type SessionSeries struct {
+	ID              uuid.UUID           `json:"id" db:"id"`
+	OrganizationID  uuid.UUID           `json:"organization_id" db:"organization_id"`
+	TherapistID     uuid.UUID           `json:"therapist_id" db:"therapist_id"`
+	PatientID       uuid.UUID           `json:"patient_id" db:"patient_id"`
+	ServiceID       *uuid.UUID          `json:"service_id,omitempty" db:"service_id"`
+	Frequency       RecurrenceFrequency `json:"frequency" db:"frequency"`
+	Weekdays        []string            `json:"weekdays" db:"weekdays"`
+	Occurrences     *int                `json:"occurrences,omitempty" db:"occurrences"`
+	UntilDate       *time.Time          `json:"until_date,omitempty" db:"until_date"`
+	StartsAt        time.Time           `json:"starts_at" db:"starts_at"`
+	DurationMinutes int                 `json:"duration_minutes" db:"duration_minutes"`
+	CreatedBy       *uuid.UUID          `json:"created_by" db:"created_by"`
+	CreatedAt       time.Time           `json:"created_at" db:"created_at"`
+}
+
+// SessionSeriesWithSessions includes the sessions of the series still linked to it
+type SessionSeriesWithSessions struct {
+	SessionSeries
+	Sessions []*SessionWithDetails `json:"sessions"`
+}
+
+// RecurringSessionResult describes what creating a series booked
+type RecurringSessionResult struct {
+	Series   *SessionSeries `json:"series"`
+	Sessions []*Session     `json:"sessions"`
+	// Occurrences left out because the therapist was already booked
+	Skipped []time.Time `json:"skipped"`
+}
+
+// SessionSeriesChange is an edit applied to the upcoming sessions of a series.
+// Unset fields are left unchanged.
+type SessionSeriesChange struct {
+	TherapistID     *uuid.UUID   `json:"therapist_id,omitempty"`
+	TimeOfDay       *string      `json:"time_of_day,omitempty"` // HH:MM in the therapist's time zone
+	DurationMinutes *int         `json:"duration_minutes,omitempty"`
+	PriceCents      *int         `json:"price_cents,omitempty"`
+	SessionType     *SessionType `json:"session_type,omitempty"`
+	Notes           *string      `json:"notes,omitempty"`
+}
@@ -56,16 +56,25 @@ const (
 	AuditActionUserImpersonated   AuditAction = "user_impersonated"
 	AuditActionImpersonationEnded AuditAction = "impersonation_ended"
 	AuditActionSettingUpdated     AuditAction = "setting_updated"
+	AuditActionExport             AuditAction = "export"
+	AuditActionAlertJustified     AuditAction = "alert_justified"
+	AuditActionAlertDismissed     AuditAction = "alert_dismissed"
+	AuditActionDeletionCancelled  AuditAction = "deletion_cancelled"
+	AuditActionDeletionRetried    AuditAction = "deletion_retried"
+	AuditActionIntegrityRepaired  AuditAction = "integrity_repaired"
 )
 
 // AuditEntityType constants
 type AuditEntityType string
 
 const (
-	AuditEntityOrganization AuditEntityType = "organization"
-	AuditEntityUser         AuditEntityType = "user"
-	AuditEntitySetting      AuditEntityType = "setting"
-	AuditEntityAdmin        AuditEntityType = "admin"
+	AuditEntityOrganization    AuditEntityType = "organization"
+	AuditEntityUser            AuditEntityType = "user"
+	AuditEntitySetting         AuditEntityType = "setting"
+	AuditEntityAdmin           AuditEntityType = "admin"
+	AuditEntityModule          AuditEntityType = "module"
+	AuditEntitySecurityAlert   AuditEntityType = "security_alert"
+	AuditEntityDeletionRequest AuditEntityType = "deletion_request"
 )
 
 // ImpersonationSession represents an admin impersonation session
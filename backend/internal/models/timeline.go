@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TimelineEntry is something that happened for a client or a project, in any module: worksheets,
+// budgets, projects and payments of the construction module and sessions of the appointments module
+type TimelineEntry struct {
+	At         time.Time `json:"at"`
+	Event      string    `json:"event"`       // e.g. budget_sent, project_started, session_completed
+	EntityType string    `json:"entity_type"` // worksheet, budget, project, payment or session
+	EntityID   uuid.UUID `json:"entity_id"`
+	Title      string    `json:"title"`
+	Status     string    `json:"status"`
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TreatmentPlanStatus is where a treatment plan stands
+type TreatmentPlanStatus string
+
+const (
+	TreatmentPlanActive       TreatmentPlanStatus = "active"
+	TreatmentPlanCompleted    TreatmentPlanStatus = "completed"
+	TreatmentPlanDiscontinued TreatmentPlanStatus = "discontinued"
+)
+
+// IsValid reports whether the status is known
+func (s TreatmentPlanStatus) IsValid() bool {
+	switch s {
+	case TreatmentPlanActive, TreatmentPlanCompleted, TreatmentPlanDiscontinued:
+		return true
+	}
+	return false
+}
+
+// NotificationTypeTreatmentPlanReview is sent to the plan's therapist when its review date approaches
+const NotificationTypeTreatmentPlanReview = "treatment_plan_review"
+
+// TreatmentPlan is a patient's plan of treatment: its goals, how many sessions it takes and when
+// it is reviewed
+type TreatmentPlan struct {
+	ID               uuid.UUID           `json:"id" db:"id"`
+	OrganizationID   uuid.UUID           `json:"organization_id" db:"organization_id"`
+	PatientID        uuid.UUID           `json:"patient_id" db:"patient_id"`
+	TherapistID      *uuid.UUID          `json:"therapist_id" db:"therapist_id"`
+	Title            string              `json:"title" db:"title"`
+	PlannedSessions  *int                `json:"planned_sessions" db:"planned_sessions"`
+	StartDate        time.Time           `json:"start_date" db:"start_date"`
+	ReviewDate       *time.Time          `json:"review_date" db:"review_date"`
+	ReviewNotifiedAt *time.Time          `json:"review_notified_at" db:"review_notified_at"`
+	Status           TreatmentPlanStatus `json:"status" db:"status"`
+	Notes            *string             `json:"notes" db:"notes"`
+	CreatedBy        *uuid.UUID          `json:"created_by" db:"created_by"`
+	CreatedAt        time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time           `json:"updated_at" db:"updated_at"`
+
+	Goals []*TreatmentPlanGoal `json:"goals"`
+
+	// Joined for display
+	PatientName   string  `json:"patient_name"`
+	TherapistName *string `json:"therapist_name"`
+}
+
+// TreatmentPlanGoal is one goal of a treatment plan
+type TreatmentPlanGoal struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	PlanID      uuid.UUID  `json:"plan_id" db:"plan_id"`
+	Description string     `json:"description" db:"description"`
+	Position    int        `json:"position" db:"position"`
+	AchievedAt  *time.Time `json:"achieved_at" db:"achieved_at"`
+}
+
+// SessionOutcomeCode is how the patient did in a session
+type SessionOutcomeCode string
+
+const (
+	SessionOutcomeImproved SessionOutcomeCode = "improved"
+	SessionOutcomeStable   SessionOutcomeCode = "stable"
+	SessionOutcomeWorsened SessionOutcomeCode = "worsened"
+)
+
+// IsValid reports whether the outcome code is known
+func (c SessionOutcomeCode) IsValid() bool {
+	switch c {
+	case SessionOutcomeImproved, SessionOutcomeStable, SessionOutcomeWorsened:
+		return true
+	}
+	return false
+}
+
+// SessionOutcome is the coded outcome of a completed session, against a treatment plan
+type SessionOutcome struct {
+	SessionID      uuid.UUID          `json:"session_id" db:"session_id"`
+	OrganizationID uuid.UUID          `json:"organization_id" db:"organization_id"`
+	PlanID         *uuid.UUID         `json:"plan_id" db:"plan_id"`
+	Outcome        SessionOutcomeCode `json:"outcome" db:"outcome"`
+	ProgressRating *int               `json:"progress_rating" db:"progress_rating"` // 0 (none) to 10 (achieved)
+	GoalIDs        []uuid.UUID        `json:"goal_ids" db:"goal_ids"`
+	Notes          *string            `json:"notes" db:"notes"`
+	RecordedBy     *uuid.UUID         `json:"recorded_by" db:"recorded_by"`
+	RecordedAt     time.Time          `json:"recorded_at" db:"recorded_at"`
+
+	// Joined for display
+	ScheduledAt time.Time `json:"scheduled_at"`
+}
+
+// TreatmentPlanGoalProgress is how often a goal was worked on
+type TreatmentPlanGoalProgress struct {
+	GoalID         uuid.UUID  `json:"goal_id"`
+	Description    string     `json:"description"`
+	Achieved       bool       `json:"achieved"`
+	SessionsWorked int        `json:"sessions_worked"`
+	LastWorkedOnAt *time.Time `json:"last_worked_on_at"`
+}
+
+// TreatmentPlanSummary is how a treatment plan is progressing
+type TreatmentPlanSummary struct {
+	PlanID            uuid.UUID `json:"plan_id"`
+	CompletedSessions int       `json:"completed_sessions"` // completed sessions of the patient since the plan started
+	PlannedSessions   *int      `json:"planned_sessions"`
+	// Share of the planned sessions done, capped at 100; nil without planned sessions
+	SessionsProgress *int `json:"sessions_progress"`
+	CodedSessions    int  `json:"coded_sessions"`
+	Improved         int  `json:"improved"`
+	Stable           int  `json:"stable"`
+	Worsened         int  `json:"worsened"`
+	// Average, first and latest progress ratings of the coded sessions; nil when none was rated
+	AverageRating *float64                     `json:"average_rating"`
+	FirstRating   *int                         `json:"first_rating"`
+	LatestRating  *int                         `json:"latest_rating"`
+	GoalsAchieved int                          `json:"goals_achieved"`
+	Goals         []*TreatmentPlanGoalProgress `json:"goals"`
+	DaysToReview  *int                         `json:"days_to_review"` // negative once the review date has passed
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TriggerRunStatus represents the progress of a bulk trigger run
+type TriggerRunStatus string
+
+const (
+	TriggerRunStatusPending             TriggerRunStatus = "pending"
+	TriggerRunStatusRunning             TriggerRunStatus = "running"
+	TriggerRunStatusCompleted           TriggerRunStatus = "completed"
+	TriggerRunStatusCompletedWithErrors TriggerRunStatus = "completed_with_errors"
+)
+
+// TriggerRunItemStatus represents the outcome of one recipient of a bulk trigger run
+type TriggerRunItemStatus string
+
+const (
+	TriggerRunItemStatusPending   TriggerRunItemStatus = "pending"
+	TriggerRunItemStatusCompleted TriggerRunItemStatus = "completed"
+	TriggerRunItemStatusFailed    TriggerRunItemStatus = "failed"
+)
+
+// TriggerRun is a trigger fired for many entities at once, fanned out into one task per entity
+type TriggerRun struct {
+	ID             uuid.UUID        `json:"id" db:"id"`
+	OrganizationID uuid.UUID        `json:"organization_id" db:"organization_id"`
+	TriggerID      uuid.UUID        `json:"trigger_id" db:"trigger_id"`
+	Source         string           `json:"source" db:"source"`
+	Status         TriggerRunStatus `json:"status" db:"status"`
+	TotalCount     int              `json:"total_count" db:"total_count"`
+	CompletedCount int              `json:"completed_count" db:"completed_count"`
+	FailedCount    int              `json:"failed_count" db:"failed_count"`
+	CreatedAt      time.Time        `json:"created_at" db:"created_at"`
+	StartedAt      *time.Time       `json:"started_at" db:"started_at"`
+	FinishedAt     *time.Time       `json:"finished_at" db:"finished_at"`
+	// Computed
+	Progress float64 `json:"progress" db:"-"` // 0-100
+	// Joined data
+	Failures []TriggerRunItem `json:"failures,omitempty" db:"-"`
+}
+
+// TriggerRunItem is one entity of a bulk trigger run
+type TriggerRunItem struct {
+	ID          uuid.UUID            `json:"id" db:"id"`
+	RunID       uuid.UUID            `json:"run_id" db:"run_id"`
+	EntityType  string               `json:"entity_type" db:"entity_type"`
+	EntityID    uuid.UUID            `json:"entity_id" db:"entity_id"`
+	Status      TriggerRunItemStatus `json:"status" db:"status"`
+	Attempts    int                  `json:"attempts" db:"attempts"`
+	LastError   *string              `json:"last_error" db:"last_error"`
+	ProcessedAt *time.Time           `json:"processed_at" db:"processed_at"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UsageQuota names a quota an organization's usage is measured against
+type UsageQuota string
+
+const (
+	UsageQuotaStorage  UsageQuota = "storage"
+	UsageQuotaMessages UsageQuota = "messages"
+)
+
+// UsageWarningLevel is how close usage is to its quota
+type UsageWarningLevel string
+
+const (
+	UsageWarningApproaching UsageWarningLevel = "approaching" // 80% or more
+	UsageWarningExceeded    UsageWarningLevel = "exceeded"
+)
+
+// UsageWarning flags a quota that is nearly or fully used
+type UsageWarning struct {
+	Quota   UsageQuota        `json:"quota"`
+	Level   UsageWarningLevel `json:"level"`
+	Used    int64             `json:"used"`
+	Limit   int64             `json:"limit"`
+	Percent float64           `json:"percent"`
+}
+
+// OrganizationUsage is the latest snapshot of an organization's data usage
+type OrganizationUsage struct {
+	OrganizationID   uuid.UUID `json:"organization_id" db:"organization_id"`
+	OrganizationName string    `json:"organization_name,omitempty" db:"organization_name"`
+	PhotoCount       int       `json:"photo_count" db:"photo_count"`
+	PhotoBytes       int64     `json:"photo_bytes" db:"photo_bytes"`
+	// Project and compliance documents and expense receipts have no recorded size, so only their count is known
+	DocumentCount     int            `json:"document_count" db:"document_count"`
+	ReceiptCount      int            `json:"receipt_count" db:"receipt_count"`
+	ExportBytes       int64          `json:"export_bytes" db:"export_bytes"`
+	StorageBytes      int64          `json:"storage_bytes" db:"storage_bytes"`
+	MessagesThisMonth int            `json:"messages_this_month" db:"messages_this_month"`
+	MessagesByChannel map[string]int `json:"messages_by_channel" db:"messages_by_channel"`
+	RowCounts         map[string]int `json:"row_counts" db:"row_counts"`
+	ComputedAt        time.Time      `json:"computed_at" db:"computed_at"`
+
+	StorageQuotaBytes   *int64         `json:"storage_quota_bytes" db:"storage_quota_bytes"`
+	MonthlyMessageQuota *int           `json:"monthly_message_quota" db:"monthly_message_quota"`
+	Warnings            []UsageWarning `json:"warnings"`
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WaitingListStatus is where a waiting list entry stands
+type WaitingListStatus string
+
+const (
+	WaitingListStatusWaiting WaitingListStatus = "waiting"
+	WaitingListStatusOffered WaitingListStatus = "offered" // a slot offer is waiting for the patient's answer
+	WaitingListStatusBooked  WaitingListStatus = "booked"
+	WaitingListStatusRemoved WaitingListStatus = "removed"
+)
+
+// WaitingListEntry is a patient waiting for a session slot that suits them
+type WaitingListEntry struct {
+	ID                uuid.UUID         `json:"id" db:"id"`
+	OrganizationID    uuid.UUID         `json:"organization_id" db:"organization_id"`
+	PatientID         uuid.UUID         `json:"patient_id" db:"patient_id"`
+	TherapistID       *uuid.UUID        `json:"therapist_id" db:"therapist_id"` // any therapist when nil
+	ServiceID         *uuid.UUID        `json:"service_id" db:"service_id"`
+	PreferredWeekdays []int             `json:"preferred_weekdays" db:"preferred_weekdays"` // 0 = Sunday; any day when empty
+	EarliestTime      *string           `json:"earliest_time" db:"earliest_time"`           // HH:MM in the therapist's timezone
+	LatestTime        *string           `json:"latest_time" db:"latest_time"`
+	Status            WaitingListStatus `json:"status" db:"status"`
+	Notes             *string           `json:"notes" db:"notes"`
+	BookedSessionID   *uuid.UUID        `json:"booked_session_id" db:"booked_session_id"`
+	CreatedBy         *uuid.UUID        `json:"created_by" db:"created_by"`
+	CreatedAt         time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at" db:"updated_at"`
+
+	// Joined for display
+	PatientName   string  `json:"patient_name"`
+	TherapistName *string `json:"therapist_name"`
+	ServiceName   *string `json:"service_name"`
+}
+
+// WaitingListOfferStatus is where a slot offer stands
+type WaitingListOfferStatus string
+
+const (
+	WaitingListOfferPending   WaitingListOfferStatus = "pending"
+	WaitingListOfferAccepted  WaitingListOfferStatus = "accepted"
+	WaitingListOfferDeclined  WaitingListOfferStatus = "declined"
+	WaitingListOfferExpired   WaitingListOfferStatus = "expired"
+	WaitingListOfferTaken     WaitingListOfferStatus = "taken"     // the slot was booked otherwise before the patient accepted
+	WaitingListOfferWithdrawn WaitingListOfferStatus = "withdrawn" // the offer message could not be sent
+)
+
+// WaitingListOffer is a freed slot offered to a waiting patient
+type WaitingListOffer struct {
+	ID              uuid.UUID              `json:"id" db:"id"`
+	OrganizationID  uuid.UUID              `json:"organization_id" db:"organization_id"`
+	EntryID         uuid.UUID              `json:"entry_id" db:"entry_id"`
+	SourceSessionID uuid.UUID              `json:"source_session_id" db:"source_session_id"`
+	TherapistID     uuid.UUID              `json:"therapist_id" db:"therapist_id"`
+	ServiceID       *uuid.UUID             `json:"service_id" db:"service_id"`
+	ScheduledAt     time.Time              `json:"scheduled_at" db:"scheduled_at"`
+	DurationMinutes int                    `json:"duration_minutes" db:"duration_minutes"`
+	ExpiresAt       time.Time              `json:"expires_at" db:"expires_at"`
+	Status          WaitingListOfferStatus `json:"status" db:"status"`
+	BookedSessionID *uuid.UUID             `json:"booked_session_id" db:"booked_session_id"`
+	RespondedAt     *time.Time             `json:"responded_at" db:"responded_at"`
+	CreatedAt       time.Time              `json:"created_at" db:"created_at"`
+
+	// Joined for display and for the offer message
+	OrganizationName string  `json:"organization_name"`
+	PatientName      string  `json:"patient_name"`
+	PatientPhone     string  `json:"-"`
+	TherapistName    string  `json:"therapist_name"`
+	ServiceName      *string `json:"service_name"`
+
+	// Only known when the offer is made, for the message sent to the patient
+	AcceptLink string `json:"-"`
+}
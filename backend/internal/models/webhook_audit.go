@@ -0,0 +1,29 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookAuditOutcome tells why a provider webhook was refused
+type WebhookAuditOutcome string
+
+const (
+	WebhookAuditRejected   WebhookAuditOutcome = "rejected"   // the signature could not be verified
+	WebhookAuditUnroutable WebhookAuditOutcome = "unroutable" // no organization matches the request
+)
+
+// WebhookAuditEntry is a provider webhook that was refused, kept with what it carried
+type WebhookAuditEntry struct {
+	ID             uuid.UUID           `json:"id" db:"id"`
+	OrganizationID *uuid.UUID          `json:"organization_id" db:"organization_id"`
+	Provider       string              `json:"provider" db:"provider"`
+	Endpoint       string              `json:"endpoint" db:"endpoint"`
+	Outcome        WebhookAuditOutcome `json:"outcome" db:"outcome"`
+	Reason         string              `json:"reason" db:"reason"`
+	RemoteAddr     *string             `json:"remote_addr" db:"remote_addr"`
+	Payload        json.RawMessage     `json:"payload" db:"payload"`
+	CreatedAt      time.Time           `json:"created_at" db:"created_at"`
+}
@@ -2,26 +2,66 @@ package models
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
 
+	phonenumber "github.com/controlwise/backend/internal/phone"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
+// WhatsAppProvider is the platform an organization sends its WhatsApp messages through
+type WhatsAppProvider string
+
+const (
+	WhatsAppProviderTwilio WhatsAppProvider = "twilio"
+	WhatsAppProviderMeta   WhatsAppProvider = "meta" // Meta's WhatsApp Cloud API
+)
+
+// IsValid reports whether the provider can be configured by an organization
+func (p WhatsAppProvider) IsValid() bool {
+	return p == WhatsAppProviderTwilio || p == WhatsAppProviderMeta
+}
+
 // NotificationConfig represents WhatsApp notification settings for an organization
 type NotificationConfig struct {
-	ID                         uuid.UUID `json:"id" db:"id"`
-	OrganizationID             uuid.UUID `json:"organization_id" db:"organization_id"`
-	WhatsAppEnabled            bool      `json:"whatsapp_enabled" db:"whatsapp_enabled"`
-	TwilioAccountSID           *string   `json:"-" db:"twilio_account_sid"`
-	TwilioAuthTokenEncrypted   *string   `json:"-" db:"twilio_auth_token_encrypted"`
-	TwilioWhatsAppNumber       *string   `json:"twilio_whatsapp_number" db:"twilio_whatsapp_number"`
-	Reminder24hEnabled         bool      `json:"reminder_24h_enabled" db:"reminder_24h_enabled"`
-	Reminder2hEnabled          bool      `json:"reminder_2h_enabled" db:"reminder_2h_enabled"`
-	Reminder24hTemplate        *string   `json:"reminder_24h_template" db:"reminder_24h_template"`
-	Reminder2hTemplate         *string   `json:"reminder_2h_template" db:"reminder_2h_template"`
-	ConfirmationResponseTmpl   *string   `json:"confirmation_response_template" db:"confirmation_response_template"`
-	CreatedAt                  time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt                  time.Time `json:"updated_at" db:"updated_at"`
+	ID                        uuid.UUID             `json:"id" db:"id"`
+	OrganizationID            uuid.UUID             `json:"organization_id" db:"organization_id"`
+	WhatsAppEnabled           bool                  `json:"whatsapp_enabled" db:"whatsapp_enabled"`
+	WhatsAppProvider          WhatsAppProvider      `json:"whatsapp_provider" db:"whatsapp_provider"`
+	TwilioAccountSID          *string               `json:"-" db:"twilio_account_sid"`
+	TwilioAuthTokenEncrypted  *string               `json:"-" db:"twilio_auth_token_encrypted"`
+	TwilioWhatsAppNumber      *string               `json:"twilio_whatsapp_number" db:"twilio_whatsapp_number"`
+	MetaPhoneNumberID         *string               `json:"meta_phone_number_id" db:"meta_phone_number_id"`
+	MetaAccessTokenEncrypted  *string               `json:"-" db:"meta_access_token_encrypted"`
+	MetaAppSecretEncrypted    *string               `json:"-" db:"meta_app_secret_encrypted"`
+	Reminder24hEnabled        bool                  `json:"reminder_24h_enabled" db:"reminder_24h_enabled"`
+	Reminder2hEnabled         bool                  `json:"reminder_2h_enabled" db:"reminder_2h_enabled"`
+	Reminder24hTemplate       *string               `json:"reminder_24h_template" db:"reminder_24h_template"`
+	Reminder2hTemplate        *string               `json:"reminder_2h_template" db:"reminder_2h_template"`
+	ConfirmationResponseTmpl  *string               `json:"confirmation_response_template" db:"confirmation_response_template"`
+	InboundIntents            InboundIntentSettings `json:"inbound_intents" db:"inbound_intents"`
+	WhatsAppMessagesPerSecond *float64              `json:"whatsapp_messages_per_second" db:"whatsapp_messages_per_second"`
+	EmailMessagesPerSecond    *float64              `json:"email_messages_per_second" db:"email_messages_per_second"`
+	MonthlyMessageCap         *decimal.Decimal      `json:"monthly_message_cap" db:"monthly_message_cap"`
+	WebhookToken              string                `json:"-" db:"webhook_token"`
+	DoNotDisturbUntil         *time.Time            `json:"do_not_disturb_until" db:"do_not_disturb_until"`
+	SendWindow                *SendWindow           `json:"send_window" db:"send_window"`
+	EmailEnabled              bool                  `json:"email_enabled" db:"email_enabled"`
+	EmailProvider             *EmailProvider        `json:"email_provider" db:"email_provider"`
+	EmailFromAddress          *string               `json:"email_from_address" db:"email_from_address"`
+	EmailFromName             *string               `json:"email_from_name" db:"email_from_name"`
+	SMTPHost                  *string               `json:"smtp_host" db:"smtp_host"`
+	SMTPPort                  *int                  `json:"smtp_port" db:"smtp_port"`
+	SMTPUsername              *string               `json:"-" db:"smtp_username"`
+	SMTPPasswordEncrypted     *string               `json:"-" db:"smtp_password_encrypted"`
+	SendGridAPIKeyEncrypted   *string               `json:"-" db:"sendgrid_api_key_encrypted"`
+	SendGridWebhookKey        *string               `json:"sendgrid_webhook_verification_key" db:"sendgrid_webhook_verification_key"`
+	SESTopicArn               *string               `json:"ses_topic_arn" db:"ses_topic_arn"`
+	RemindersMigratedAt       *time.Time            `json:"reminders_migrated_at" db:"reminders_migrated_at"`
+	RemindersWorkflowID       *uuid.UUID            `json:"reminders_workflow_id" db:"reminders_workflow_id"`
+	CreatedAt                 time.Time             `json:"created_at" db:"created_at"`
+	UpdatedAt                 time.Time             `json:"updated_at" db:"updated_at"`
 }
 
 // NotificationConfigPublic is the public-facing version without sensitive data
@@ -36,26 +76,108 @@ type NotificationConfigPublic struct {
 	Reminder24hTemplate      *string   `json:"reminder_24h_template"`
 	Reminder2hTemplate       *string   `json:"reminder_2h_template"`
 	ConfirmationResponseTmpl *string   `json:"confirmation_response_template"`
-	CreatedAt                time.Time `json:"created_at"`
-	UpdatedAt                time.Time `json:"updated_at"`
+	// Organization overrides of the built-in inbound message intents
+	InboundIntents InboundIntentSettings `json:"inbound_intents"`
+	// WhatsApp Cloud API, used instead of Twilio when whatsapp_provider is "meta". The webhook is
+	// verified with the token at the end of its path.
+	WhatsAppProvider  WhatsAppProvider `json:"whatsapp_provider"`
+	MetaConfigured    bool             `json:"meta_configured"`
+	MetaPhoneNumberID *string          `json:"meta_phone_number_id"`
+	MetaWebhookPath   string           `json:"meta_webhook_path"`
+	// Message rate limits (nil uses the platform default)
+	WhatsAppMessagesPerSecond *float64 `json:"whatsapp_messages_per_second"`
+	EmailMessagesPerSecond    *float64 `json:"email_messages_per_second"`
+	// Monthly message spend cap (nil means no cap)
+	MonthlyMessageCap *decimal.Decimal `json:"monthly_message_cap"`
+	// Inbound webhook URL path to set in Twilio for this organization
+	WebhookPath string `json:"webhook_path"`
+	// Until when only urgent workflow messages are sent (nil when not paused)
+	DoNotDisturbUntil *time.Time `json:"do_not_disturb_until"`
+	// Part of the day messages may go out; outside it they wait for the next window (nil sends any time)
+	SendWindow *SendWindow `json:"send_window"`
+	// Organization email provider, used instead of the platform SMTP server when configured
+	EmailEnabled     bool           `json:"email_enabled"`
+	EmailProvider    *EmailProvider `json:"email_provider"`
+	EmailConfigured  bool           `json:"email_configured"`
+	EmailFromAddress *string        `json:"email_from_address"`
+	EmailFromName    *string        `json:"email_from_name"`
+	SMTPHost         *string        `json:"smtp_host"`
+	SMTPPort         *int           `json:"smtp_port"`
+	// Bounce and complaint webhook URL paths to set in SendGrid and in the SES notifications topic.
+	// SendGrid posts are verified with the Signed Event Webhook key, SES notifications must come
+	// from the topic set here.
+	SendGridEventsWebhookPath string  `json:"sendgrid_events_webhook_path"`
+	SendGridWebhookKey        *string `json:"sendgrid_webhook_verification_key"`
+	SESEventsWebhookPath      string  `json:"ses_events_webhook_path"`
+	SESTopicArn               *string `json:"ses_topic_arn"`
+	// When the 24h/2h reminders above were replaced by the session workflow's triggers; the
+	// reminder settings have no effect afterwards
+	RemindersMigratedAt *time.Time `json:"reminders_migrated_at"`
+	RemindersWorkflowID *uuid.UUID `json:"reminders_workflow_id"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
 }
 
 // ToPublic converts NotificationConfig to public version
 func (c *NotificationConfig) ToPublic() NotificationConfigPublic {
 	return NotificationConfigPublic{
-		ID:                       c.ID,
-		OrganizationID:           c.OrganizationID,
-		WhatsAppEnabled:          c.WhatsAppEnabled,
-		TwilioConfigured:         c.TwilioAccountSID != nil && c.TwilioAuthTokenEncrypted != nil,
-		TwilioWhatsAppNumber:     c.TwilioWhatsAppNumber,
-		Reminder24hEnabled:       c.Reminder24hEnabled,
-		Reminder2hEnabled:        c.Reminder2hEnabled,
-		Reminder24hTemplate:      c.Reminder24hTemplate,
-		Reminder2hTemplate:       c.Reminder2hTemplate,
-		ConfirmationResponseTmpl: c.ConfirmationResponseTmpl,
-		CreatedAt:                c.CreatedAt,
-		UpdatedAt:                c.UpdatedAt,
+		ID:                        c.ID,
+		OrganizationID:            c.OrganizationID,
+		WhatsAppEnabled:           c.WhatsAppEnabled,
+		TwilioConfigured:          c.TwilioAccountSID != nil && c.TwilioAuthTokenEncrypted != nil,
+		TwilioWhatsAppNumber:      c.TwilioWhatsAppNumber,
+		WhatsAppProvider:          c.WhatsAppProvider,
+		MetaConfigured:            c.MetaConfigured(),
+		MetaPhoneNumberID:         c.MetaPhoneNumberID,
+		MetaWebhookPath:           "/webhooks/meta/" + c.WebhookToken,
+		Reminder24hEnabled:        c.Reminder24hEnabled,
+		Reminder2hEnabled:         c.Reminder2hEnabled,
+		Reminder24hTemplate:       c.Reminder24hTemplate,
+		Reminder2hTemplate:        c.Reminder2hTemplate,
+		ConfirmationResponseTmpl:  c.ConfirmationResponseTmpl,
+		InboundIntents:            c.InboundIntents,
+		WhatsAppMessagesPerSecond: c.WhatsAppMessagesPerSecond,
+		EmailMessagesPerSecond:    c.EmailMessagesPerSecond,
+		MonthlyMessageCap:         c.MonthlyMessageCap,
+		WebhookPath:               "/webhooks/whatsapp/" + c.WebhookToken,
+		DoNotDisturbUntil:         c.DoNotDisturbUntil,
+		SendWindow:                c.SendWindow,
+		EmailEnabled:              c.EmailEnabled,
+		EmailProvider:             c.EmailProvider,
+		EmailConfigured:           c.EmailConfigured(),
+		EmailFromAddress:          c.EmailFromAddress,
+		EmailFromName:             c.EmailFromName,
+		SMTPHost:                  c.SMTPHost,
+		SMTPPort:                  c.SMTPPort,
+		SendGridEventsWebhookPath: "/webhooks/email/sendgrid/" + c.WebhookToken,
+		SendGridWebhookKey:        c.SendGridWebhookKey,
+		SESEventsWebhookPath:      "/webhooks/email/ses/" + c.WebhookToken,
+		SESTopicArn:               c.SESTopicArn,
+		RemindersMigratedAt:       c.RemindersMigratedAt,
+		RemindersWorkflowID:       c.RemindersWorkflowID,
+		CreatedAt:                 c.CreatedAt,
+		UpdatedAt:                 c.UpdatedAt,
+	}
+}
+
+// MetaConfigured reports whether the organization has complete WhatsApp Cloud API credentials
+func (c *NotificationConfig) MetaConfigured() bool {
+	return c.MetaPhoneNumberID != nil && *c.MetaPhoneNumberID != "" && c.MetaAccessTokenEncrypted != nil &&
+		c.MetaAppSecretEncrypted != nil
+}
+
+// EmailConfigured reports whether the organization has complete credentials for its email provider
+func (c *NotificationConfig) EmailConfigured() bool {
+	if c.EmailProvider == nil || c.EmailFromAddress == nil || *c.EmailFromAddress == "" {
+		return false
 	}
+	switch *c.EmailProvider {
+	case EmailProviderSMTP:
+		return c.SMTPHost != nil && *c.SMTPHost != "" && c.SMTPPort != nil
+	case EmailProviderSendGrid:
+		return c.SendGridAPIKeyEncrypted != nil
+	}
+	return false
 }
 
 // WhatsAppMessageDirection represents message direction
@@ -81,18 +203,60 @@ const (
 
 // WhatsAppMessage represents a WhatsApp message log entry
 type WhatsAppMessage struct {
-	ID             uuid.UUID               `json:"id" db:"id"`
-	OrganizationID uuid.UUID               `json:"organization_id" db:"organization_id"`
-	SessionID      *uuid.UUID              `json:"session_id" db:"session_id"`
+	ID             uuid.UUID                `json:"id" db:"id"`
+	OrganizationID uuid.UUID                `json:"organization_id" db:"organization_id"`
+	SessionID      *uuid.UUID               `json:"session_id" db:"session_id"`
 	Direction      WhatsAppMessageDirection `json:"direction" db:"direction"`
-	PhoneNumber    string                  `json:"phone_number" db:"phone_number"`
-	MessageContent *string                 `json:"message_content" db:"message_content"`
-	MessageSID     *string                 `json:"message_sid" db:"message_sid"`
-	Status         WhatsAppMessageStatus   `json:"status" db:"status"`
-	ErrorCode      *string                 `json:"error_code" db:"error_code"`
-	ErrorMessage   *string                 `json:"error_message" db:"error_message"`
-	RawPayload     json.RawMessage         `json:"raw_payload" db:"raw_payload"`
-	CreatedAt      time.Time               `json:"created_at" db:"created_at"`
+	PhoneNumber    string                   `json:"phone_number" db:"phone_number"`
+	MessageContent *string                  `json:"message_content" db:"message_content"`
+	MessageSID     *string                  `json:"message_sid" db:"message_sid"`
+	Status         WhatsAppMessageStatus    `json:"status" db:"status"`
+	ErrorCode      *string                  `json:"error_code" db:"error_code"`
+	ErrorMessage   *string                  `json:"error_message" db:"error_message"`
+	RawPayload     json.RawMessage          `json:"raw_payload" db:"raw_payload"`
+	CreatedAt      time.Time                `json:"created_at" db:"created_at"`
+}
+
+// WhatsAppSessionWindowDuration is how long after a contact's last inbound message free-form
+// WhatsApp messages can be sent. Outside it only approved templates are delivered.
+const WhatsAppSessionWindowDuration = 24 * time.Hour
+
+// WhatsAppSessionWindow is the customer service window of a phone number
+type WhatsAppSessionWindow struct {
+	PhoneNumber   string     `json:"phone_number"`
+	LastInboundAt *time.Time `json:"last_inbound_at"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	IsOpen        bool       `json:"is_open"`
+}
+
+// NewWhatsAppSessionWindow builds the window of a phone number from its last inbound message
+func NewWhatsAppSessionWindow(phone string, lastInboundAt *time.Time) *WhatsAppSessionWindow {
+	window := &WhatsAppSessionWindow{PhoneNumber: phone, LastInboundAt: lastInboundAt}
+	if lastInboundAt != nil {
+		expiresAt := lastInboundAt.Add(WhatsAppSessionWindowDuration)
+		window.ExpiresAt = &expiresAt
+		window.IsOpen = time.Now().Before(expiresAt)
+	}
+	return window
+}
+
+// NormalizeWhatsAppPhone returns a phone number in E.164 form without the whatsapp: prefix.
+// Numbers are stored in E.164; numbers saved before that without a country code are assumed
+// to be Portuguese.
+func NormalizeWhatsAppPhone(phone string) string {
+	if e164, err := phonenumber.Normalize(phone, phonenumber.DefaultCountry); err == nil {
+		return e164
+	}
+	phone = strings.TrimPrefix(phone, "whatsapp:")
+	phone = strings.ReplaceAll(phone, " ", "")
+	phone = strings.ReplaceAll(phone, "-", "")
+	if !strings.HasPrefix(phone, "+") {
+		if !strings.HasPrefix(phone, "351") {
+			phone = "351" + phone
+		}
+		phone = "+" + phone
+	}
+	return phone
 }
 
 // ReminderType represents the type of scheduled reminder
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WhatsAppThread is the WhatsApp conversation with one phone number
+type WhatsAppThread struct {
+	PhoneNumber   string                   `json:"phone_number"` // E.164
+	ClientID      *uuid.UUID               `json:"client_id"`    // the client with this phone number, when known
+	ClientName    *string                  `json:"client_name"`
+	PatientID     *uuid.UUID               `json:"patient_id"`
+	LastMessage   *string                  `json:"last_message"`
+	LastDirection WhatsAppMessageDirection `json:"last_direction"`
+	LastMessageAt time.Time                `json:"last_message_at"`
+	UnreadCount   int                      `json:"unread_count"`
+	Window        *WhatsAppSessionWindow   `json:"window"`
+}
+
+// WhatsAppThreadMessage is a message of a WhatsApp conversation
+type WhatsAppThreadMessage struct {
+	ID             uuid.UUID                `json:"id"`
+	SessionID      *uuid.UUID               `json:"session_id"`
+	Direction      WhatsAppMessageDirection `json:"direction"`
+	MessageContent *string                  `json:"message_content"`
+	Status         *WhatsAppMessageStatus   `json:"status"`
+	ErrorMessage   *string                  `json:"error_message"`
+	MediaType      *string                  `json:"media_type"` // the kind of file received, downloadable from the media endpoint
+	SentBy         *uuid.UUID               `json:"sent_by"`    // the user who replied from the app
+	SentByName     *string                  `json:"sent_by_name"`
+	CreatedAt      time.Time                `json:"created_at"`
+}
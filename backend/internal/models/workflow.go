@@ -13,6 +13,7 @@ type WorkflowModule string
 const (
 	WorkflowModuleAppointments  WorkflowModule = "appointments"
 	WorkflowModuleConstruction  WorkflowModule = "construction"
+	WorkflowModuleInvoices      WorkflowModule = "invoices"
 )
 
 // WorkflowEntityType represents the entity type a workflow manages
@@ -22,6 +23,7 @@ const (
 	WorkflowEntitySession WorkflowEntityType = "session"
 	WorkflowEntityBudget  WorkflowEntityType = "budget"
 	WorkflowEntityProject WorkflowEntityType = "project"
+	WorkflowEntityInvoice WorkflowEntityType = "invoice"
 )
 
 // Workflow represents a configurable workflow definition
@@ -68,19 +70,38 @@ type WorkflowState struct {
 
 // WorkflowTransition represents a transition between states
 type WorkflowTransition struct {
-	ID                   uuid.UUID `json:"id" db:"id"`
-	WorkflowID           uuid.UUID `json:"workflow_id" db:"workflow_id"`
-	FromStateID          uuid.UUID `json:"from_state_id" db:"from_state_id"`
-	ToStateID            uuid.UUID `json:"to_state_id" db:"to_state_id"`
-	Name                 string    `json:"name" db:"name"`
-	RequiresConfirmation bool      `json:"requires_confirmation" db:"requires_confirmation"`
-	CreatedAt            time.Time `json:"created_at" db:"created_at"`
+	ID                   uuid.UUID         `json:"id" db:"id"`
+	WorkflowID           uuid.UUID         `json:"workflow_id" db:"workflow_id"`
+	FromStateID          uuid.UUID         `json:"from_state_id" db:"from_state_id"`
+	ToStateID            uuid.UUID         `json:"to_state_id" db:"to_state_id"`
+	Name                 string            `json:"name" db:"name"`
+	RequiresConfirmation bool              `json:"requires_confirmation" db:"requires_confirmation"`
+	Inputs               []TransitionInput `json:"inputs" db:"inputs"` // fields filled in when taking the transition
+	CreatedAt            time.Time         `json:"created_at" db:"created_at"`
 	// Joined data
 	FromStateName string            `json:"from_state_name,omitempty" db:"-"`
 	ToStateName   string            `json:"to_state_name,omitempty" db:"-"`
 	Triggers      []WorkflowTrigger `json:"triggers,omitempty" db:"-"`
 }
 
+// TransitionInputType is the kind of value a transition input accepts
+type TransitionInputType string
+
+const (
+	TransitionInputText   TransitionInputType = "text"
+	TransitionInputSelect TransitionInputType = "select"
+	TransitionInputNumber TransitionInputType = "number"
+)
+
+// TransitionInput declares a field filled in when a transition is taken
+type TransitionInput struct {
+	Name     string              `json:"name"`
+	Label    string              `json:"label"`
+	Type     TransitionInputType `json:"type"`
+	Options  []string            `json:"options,omitempty"` // allowed values of select inputs
+	Required bool                `json:"required"`
+}
+
 // TriggerType represents when a trigger should fire
 type TriggerType string
 
@@ -90,21 +111,65 @@ const (
 	TriggerTypeTimeBefore TriggerType = "time_before"
 	TriggerTypeTimeAfter  TriggerType = "time_after"
 	TriggerTypeRecurring  TriggerType = "recurring"
+	// TriggerTypeComplianceOverdue fires when a project compliance item passes its due date
+	TriggerTypeComplianceOverdue TriggerType = "compliance_overdue"
+	// TriggerTypeTaskAssigned fires when a task of a project is assigned to someone
+	TriggerTypeTaskAssigned TriggerType = "task_assigned"
+	// TriggerTypeTaskDue fires when an open task of a project passes its due date
+	TriggerTypeTaskDue TriggerType = "task_due"
+	// TriggerTypePaymentOverdue fires when a pending payment of a project passes its due date
+	TriggerTypePaymentOverdue TriggerType = "payment_overdue"
+	// TriggerTypeTreatmentPlanReview fires from the session workflow when a patient's treatment plan
+	// is due for review, against the patient's next session
+	TriggerTypeTreatmentPlanReview TriggerType = "treatment_plan_review"
+	// TriggerTypeEmailBounced fires when an email hard bounces, against the session or budget of
+	// the recipient, so staff can correct the address
+	TriggerTypeEmailBounced TriggerType = "email_bounced"
+)
+
+// RecurringSkipRule controls which cron occurrences of a recurring trigger are skipped
+type RecurringSkipRule string
+
+const (
+	RecurringSkipNone         RecurringSkipRule = "none"
+	RecurringSkipWeekends     RecurringSkipRule = "weekends"
+	RecurringSkipHolidays     RecurringSkipRule = "holidays"
+	RecurringSkipBusinessDays RecurringSkipRule = "business_days" // run only on weekdays that are not holidays
 )
 
+// IsValid reports whether the skip rule is known
+func (r RecurringSkipRule) IsValid() bool {
+	switch r {
+	case RecurringSkipNone, RecurringSkipWeekends, RecurringSkipHolidays, RecurringSkipBusinessDays:
+		return true
+	}
+	return false
+}
+
+// OrganizationHoliday is a day on which holiday-aware recurring triggers do not run
+type OrganizationHoliday struct {
+	ID             uuid.UUID `json:"id" db:"id"`
+	OrganizationID uuid.UUID `json:"organization_id" db:"organization_id"`
+	Date           time.Time `json:"date" db:"date"`
+	Name           string    `json:"name" db:"name"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
 // WorkflowTrigger represents a trigger that fires actions
 type WorkflowTrigger struct {
-	ID                uuid.UUID       `json:"id" db:"id"`
-	WorkflowID        uuid.UUID       `json:"workflow_id" db:"workflow_id"`
-	StateID           *uuid.UUID      `json:"state_id" db:"state_id"`
-	TransitionID      *uuid.UUID      `json:"transition_id" db:"transition_id"`
-	TriggerType       TriggerType     `json:"trigger_type" db:"trigger_type"`
-	TimeOffsetMinutes *int            `json:"time_offset_minutes" db:"time_offset_minutes"`
-	TimeField         *string         `json:"time_field" db:"time_field"`
-	RecurringCron     *string         `json:"recurring_cron" db:"recurring_cron"`
-	Conditions        json.RawMessage `json:"conditions" db:"conditions"`
-	IsActive          bool            `json:"is_active" db:"is_active"`
-	CreatedAt         time.Time       `json:"created_at" db:"created_at"`
+	ID                 uuid.UUID         `json:"id" db:"id"`
+	WorkflowID         uuid.UUID         `json:"workflow_id" db:"workflow_id"`
+	StateID            *uuid.UUID        `json:"state_id" db:"state_id"`
+	TransitionID       *uuid.UUID        `json:"transition_id" db:"transition_id"`
+	TriggerType        TriggerType       `json:"trigger_type" db:"trigger_type"`
+	TimeOffsetMinutes  *int              `json:"time_offset_minutes" db:"time_offset_minutes"`
+	TimeField          *string           `json:"time_field" db:"time_field"`
+	RecurringCron      *string           `json:"recurring_cron" db:"recurring_cron"`
+	RecurringSkip      RecurringSkipRule `json:"recurring_skip" db:"recurring_skip"`
+	RecurringLastRunAt *time.Time        `json:"recurring_last_run_at,omitempty" db:"recurring_last_run_at"`
+	Conditions         json.RawMessage   `json:"conditions" db:"conditions"`
+	IsActive           bool              `json:"is_active" db:"is_active"`
+	CreatedAt          time.Time         `json:"created_at" db:"created_at"`
 	// Nested data
 	Actions []WorkflowAction `json:"actions,omitempty" db:"-"`
 }
@@ -117,18 +182,47 @@ const (
 	ActionTypeSendEmail    ActionType = "send_email"
 	ActionTypeUpdateField  ActionType = "update_field"
 	ActionTypeCreateTask   ActionType = "create_task"
+	ActionTypeNotifyUser   ActionType = "notify_user"
+	// Moves an entity related to the one the workflow runs for, e.g. an approved budget's project
+	ActionTypeTransitionEntity ActionType = "transition_entity"
+	// Creates the project of an approved budget, copying its items into tasks or milestones
+	ActionTypeCreateProject ActionType = "create_project"
+	// Posts a message to the organization's Slack or Teams channels
+	ActionTypeSendChat ActionType = "send_chat"
+	// Offers a cancelled session's slot to the next suitable patient on the waiting list
+	ActionTypeOfferSlot ActionType = "offer_slot"
+)
+
+// Related entities a transition_entity action can move
+const (
+	ChainTargetProject        = "project"         // from a budget: creates or transitions its project
+	ChainTargetSessionPayment = "session_payment" // from a session: creates its payment and starts dunning
+)
+
+// MaxWorkflowChainDepth limits how many transition_entity actions can follow one another
+const MaxWorkflowChainDepth = 5
+
+// ActionUrgency controls whether a message action is held during do-not-disturb periods
+type ActionUrgency string
+
+const (
+	ActionUrgencyNormal ActionUrgency = "normal"
+	ActionUrgencyUrgent ActionUrgency = "urgent"
 )
 
 // WorkflowAction represents an action to execute when a trigger fires
 type WorkflowAction struct {
-	ID           uuid.UUID       `json:"id" db:"id"`
-	TriggerID    uuid.UUID       `json:"trigger_id" db:"trigger_id"`
-	ActionType   ActionType      `json:"action_type" db:"action_type"`
-	ActionOrder  int             `json:"action_order" db:"action_order"`
-	TemplateID   *uuid.UUID      `json:"template_id" db:"template_id"`
-	ActionConfig json.RawMessage `json:"action_config" db:"action_config"`
-	IsActive     bool            `json:"is_active" db:"is_active"`
-	CreatedAt    time.Time       `json:"created_at" db:"created_at"`
+	ID                  uuid.UUID       `json:"id" db:"id"`
+	TriggerID           uuid.UUID       `json:"trigger_id" db:"trigger_id"`
+	ActionType          ActionType      `json:"action_type" db:"action_type"`
+	ActionOrder         int             `json:"action_order" db:"action_order"`
+	TemplateID          *uuid.UUID      `json:"template_id" db:"template_id"`
+	ActionConfig        json.RawMessage `json:"action_config" db:"action_config"`
+	Urgency             ActionUrgency   `json:"urgency" db:"urgency"`
+	RetryMaxAttempts    int             `json:"retry_max_attempts" db:"retry_max_attempts"`       // total attempts, 1 = no retry
+	RetryBackoffSeconds int             `json:"retry_backoff_seconds" db:"retry_backoff_seconds"` // delay before the first retry, doubled after each
+	IsActive            bool            `json:"is_active" db:"is_active"`
+	CreatedAt           time.Time       `json:"created_at" db:"created_at"`
 	// Joined data
 	Template *MessageTemplate `json:"template,omitempty" db:"-"`
 }
@@ -150,9 +244,16 @@ type MessageTemplate struct {
 	Subject        *string         `json:"subject" db:"subject"`
 	Body           string          `json:"body" db:"body"`
 	Variables      json.RawMessage `json:"variables" db:"variables"`
-	IsActive       bool            `json:"is_active" db:"is_active"`
-	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
-	UpdatedAt      time.Time       `json:"updated_at" db:"updated_at"`
+	// Approved template used outside the WhatsApp session window: the Twilio Content SID, or the
+	// Cloud API template name with an optional language ("name:pt_PT").
+	// Its {{1}}, {{2}}... placeholders are filled with Variables, in order.
+	WhatsAppContentSID *string   `json:"whatsapp_content_sid" db:"whatsapp_content_sid"`
+	IsActive           bool      `json:"is_active" db:"is_active"`
+	CreatedAt          time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
+
+	// Lint findings of the last save, not stored
+	Issues []TemplateIssue `json:"issues,omitempty" db:"-"`
 }
 
 // TemplateVariable represents a variable available in a template
@@ -161,6 +262,34 @@ type TemplateVariable struct {
 	Description string `json:"description"`
 }
 
+// TemplateIssueSeverity tells whether a template lint issue blocks sending or is only advisory
+type TemplateIssueSeverity string
+
+const (
+	TemplateIssueError   TemplateIssueSeverity = "error"
+	TemplateIssueWarning TemplateIssueSeverity = "warning"
+)
+
+// TemplateIssue is a problem found when linting a message template
+type TemplateIssue struct {
+	Severity TemplateIssueSeverity `json:"severity"`
+	Code     string                `json:"code"`
+	Field    string                `json:"field"` // subject or body
+	Message  string                `json:"message"`
+	Variable string                `json:"variable,omitempty"`
+}
+
+// TemplatePreview is a message template rendered against an entity or sample data
+type TemplatePreview struct {
+	TemplateID uuid.UUID       `json:"template_id"`
+	Channel    MessageChannel  `json:"channel"`
+	EntityType string          `json:"entity_type"`
+	EntityID   *uuid.UUID      `json:"entity_id,omitempty"` // nil when rendered with sample data
+	Subject    *string         `json:"subject,omitempty"`
+	Body       string          `json:"body"`
+	Issues     []TemplateIssue `json:"issues"` // null outside lint mode
+}
+
 // GetVariables parses the variables JSON
 func (t *MessageTemplate) GetVariables() ([]TemplateVariable, error) {
 	if t.Variables == nil {
@@ -222,10 +351,12 @@ const (
 type PaymentMethod string
 
 const (
-	PaymentMethodCash      PaymentMethod = "cash"
-	PaymentMethodTransfer  PaymentMethod = "transfer"
-	PaymentMethodInsurance PaymentMethod = "insurance"
-	PaymentMethodCard      PaymentMethod = "card"
+	PaymentMethodCash       PaymentMethod = "cash"
+	PaymentMethodTransfer   PaymentMethod = "transfer"
+	PaymentMethodInsurance  PaymentMethod = "insurance"
+	PaymentMethodCard       PaymentMethod = "card"
+	PaymentMethodMBWay      PaymentMethod = "mbway"
+	PaymentMethodMultibanco PaymentMethod = "multibanco"
 )
 
 // SessionPayment represents payment information for a session
@@ -240,6 +371,10 @@ type SessionPayment struct {
 	DueDate              *time.Time     `json:"due_date" db:"due_date"`
 	PaidAt               *time.Time     `json:"paid_at" db:"paid_at"`
 	Notes                *string        `json:"notes" db:"notes"`
+	Kind                 SessionPaymentKind `json:"kind" db:"kind"`
+	MBEntity             *string        `json:"mb_entity" db:"mb_entity"` // current Multibanco reference
+	MBReference          *string        `json:"mb_reference" db:"mb_reference"`
+	SEPAReference        *string        `json:"sepa_reference" db:"sepa_reference"` // RF reference of SEPA transfers
 	CreatedAt            time.Time      `json:"created_at" db:"created_at"`
 	UpdatedAt            time.Time      `json:"updated_at" db:"updated_at"`
 }
@@ -260,6 +395,11 @@ const (
 	EventTypeTriggerFired   EventType = "trigger_fired"
 	EventTypeActionExecuted EventType = "action_executed"
 	EventTypeActionFailed   EventType = "action_failed"
+	EventTypeTriggerSkipped EventType = "trigger_skipped" // conditions did not match the entity
+	// Scheduled jobs changed by a user, with who did it and why in the details
+	EventTypeJobCancelled   EventType = "job_cancelled"
+	EventTypeJobRunNow      EventType = "job_run_now"
+	EventTypeJobRescheduled EventType = "job_rescheduled"
 )
 
 // WorkflowExecutionLog represents a log entry for workflow execution
@@ -0,0 +1,139 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ConditionOperator compares an entity field with a condition value
+type ConditionOperator string
+
+const (
+	ConditionEquals         ConditionOperator = "eq"
+	ConditionNotEquals      ConditionOperator = "neq"
+	ConditionGreaterThan    ConditionOperator = "gt"
+	ConditionGreaterOrEqual ConditionOperator = "gte"
+	ConditionLessThan       ConditionOperator = "lt"
+	ConditionLessOrEqual    ConditionOperator = "lte"
+	ConditionContains       ConditionOperator = "contains"
+	ConditionIn             ConditionOperator = "in"
+	ConditionNotIn          ConditionOperator = "not_in"
+	ConditionExists         ConditionOperator = "exists"
+	ConditionNotExists      ConditionOperator = "not_exists"
+)
+
+// IsValid reports whether the operator is known
+func (o ConditionOperator) IsValid() bool {
+	switch o {
+	case ConditionEquals, ConditionNotEquals, ConditionGreaterThan, ConditionGreaterOrEqual,
+		ConditionLessThan, ConditionLessOrEqual, ConditionContains, ConditionIn, ConditionNotIn,
+		ConditionExists, ConditionNotExists:
+		return true
+	}
+	return false
+}
+
+// maxConditionDepth limits how deeply condition groups can be nested
+const maxConditionDepth = 5
+
+// TriggerCondition is either a comparison of an entity field (e.g. {"field": "session_type",
+// "operator": "eq", "value": "online"}) or a group that matches when all or any of its
+// conditions match. Fields of nested data are addressed with dots (e.g. "client.city").
+type TriggerCondition struct {
+	Field    string             `json:"field,omitempty"`
+	Operator ConditionOperator  `json:"operator,omitempty"`
+	Value    interface{}        `json:"value,omitempty"`
+	All      []TriggerCondition `json:"all,omitempty"`
+	Any      []TriggerCondition `json:"any,omitempty"`
+}
+
+// IsGroup reports whether the condition is an AND/OR group
+func (c *TriggerCondition) IsGroup() bool {
+	return c.All != nil || c.Any != nil
+}
+
+// Validate checks the condition and its nested conditions
+func (c *TriggerCondition) Validate() error {
+	return c.validate(1)
+}
+
+func (c *TriggerCondition) validate(depth int) error {
+	if depth > maxConditionDepth {
+		return fmt.Errorf("conditions can be nested at most %d levels deep", maxConditionDepth)
+	}
+
+	if c.IsGroup() {
+		if c.Field != "" || c.Operator != "" {
+			return errors.New("a condition is either a comparison or an all/any group, not both")
+		}
+		if c.All != nil && c.Any != nil {
+			return errors.New("a condition group has either all or any, not both")
+		}
+		group := c.All
+		if c.Any != nil {
+			group = c.Any
+		}
+		if len(group) == 0 {
+			return errors.New("a condition group needs at least one condition")
+		}
+		for i := range group {
+			if err := group[i].validate(depth + 1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if c.Field == "" {
+		return errors.New("condition field is required")
+	}
+	if !c.Operator.IsValid() {
+		return fmt.Errorf("unknown condition operator %q", c.Operator)
+	}
+
+	switch c.Operator {
+	case ConditionExists, ConditionNotExists:
+		return nil
+	case ConditionIn, ConditionNotIn:
+		if _, ok := c.Value.([]interface{}); !ok {
+			return fmt.Errorf("condition on %s: %s needs a list value", c.Field, c.Operator)
+		}
+	case ConditionGreaterThan, ConditionGreaterOrEqual, ConditionLessThan, ConditionLessOrEqual:
+		switch c.Value.(type) {
+		case float64, string:
+		default:
+			return fmt.Errorf("condition on %s: %s needs a number or date value", c.Field, c.Operator)
+		}
+	default:
+		if c.Value == nil {
+			return fmt.Errorf("condition on %s: value is required", c.Field)
+		}
+	}
+	return nil
+}
+
+// ParseTriggerConditions decodes and validates a trigger's conditions. A list of conditions
+// is read as an all group. Empty conditions (null, {} or []) return nil: the trigger always fires.
+func ParseTriggerConditions(raw json.RawMessage) (*TriggerCondition, error) {
+	trimmed := bytes.TrimSpace(raw)
+	switch string(trimmed) {
+	case "", "null", "{}", "[]":
+		return nil, nil
+	}
+
+	var condition TriggerCondition
+	if trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &condition.All); err != nil {
+			return nil, fmt.Errorf("invalid conditions: %w", err)
+		}
+	} else if err := json.Unmarshal(trimmed, &condition); err != nil {
+		return nil, fmt.Errorf("invalid conditions: %w", err)
+	}
+
+	if err := condition.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid conditions: %w", err)
+	}
+	return &condition, nil
+}
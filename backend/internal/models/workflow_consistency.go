@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WorkflowStatusMismatch is an entity status not matching any state of the default workflow
+type WorkflowStatusMismatch struct {
+	ID              uuid.UUID          `json:"id" db:"id"`
+	OrganizationID  uuid.UUID          `json:"organization_id" db:"organization_id"`
+	WorkflowID      uuid.UUID          `json:"workflow_id" db:"workflow_id"`
+	EntityType      WorkflowEntityType `json:"entity_type" db:"entity_type"`
+	Status          string             `json:"status" db:"status"`
+	EntityCount     int                `json:"entity_count" db:"entity_count"`
+	SampleEntityIDs []uuid.UUID        `json:"sample_entity_ids" db:"sample_entity_ids"`
+	RemapError      *string            `json:"remap_error,omitempty" db:"remap_error"`
+	FirstDetectedAt time.Time          `json:"first_detected_at" db:"first_detected_at"`
+	LastCheckedAt   time.Time          `json:"last_checked_at" db:"last_checked_at"`
+	ResolvedAt      *time.Time         `json:"resolved_at,omitempty" db:"resolved_at"`
+}
+
+// WorkflowStatusRemap maps an old entity status to a current workflow state
+type WorkflowStatusRemap struct {
+	ID             uuid.UUID          `json:"id" db:"id"`
+	OrganizationID uuid.UUID          `json:"organization_id" db:"organization_id"`
+	EntityType     WorkflowEntityType `json:"entity_type" db:"entity_type"`
+	FromStatus     string             `json:"from_status" db:"from_status"`
+	ToStateID      uuid.UUID          `json:"to_state_id" db:"to_state_id"`
+	ToStatus       string             `json:"to_status" db:"-"` // name of the target state
+	AutoApply      bool               `json:"auto_apply" db:"auto_apply"`
+	CreatedBy      *uuid.UUID         `json:"created_by" db:"created_by"`
+	CreatedAt      time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at" db:"updated_at"`
+}
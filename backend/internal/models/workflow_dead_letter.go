@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeadLetterStatus represents what happened to an action that exhausted its retries
+type DeadLetterStatus string
+
+const (
+	DeadLetterStatusFailed    DeadLetterStatus = "failed"
+	DeadLetterStatusRequeued  DeadLetterStatus = "requeued" // waiting for the worker to run it again
+	DeadLetterStatusResolved  DeadLetterStatus = "resolved" // ran successfully after a requeue
+	DeadLetterStatusDiscarded DeadLetterStatus = "discarded"
+)
+
+// WorkflowDeadLetter is a workflow action that kept failing after all its retries
+type WorkflowDeadLetter struct {
+	ID             uuid.UUID        `json:"id" db:"id"`
+	OrganizationID uuid.UUID        `json:"organization_id" db:"organization_id"`
+	WorkflowID     uuid.UUID        `json:"workflow_id" db:"workflow_id"`
+	TriggerID      uuid.UUID        `json:"trigger_id" db:"trigger_id"`
+	ActionID       uuid.UUID        `json:"action_id" db:"action_id"`
+	EntityType     string           `json:"entity_type" db:"entity_type"`
+	EntityID       uuid.UUID        `json:"entity_id" db:"entity_id"`
+	Attempts       int              `json:"attempts" db:"attempts"`
+	LastError      string           `json:"last_error" db:"last_error"`
+	Status         DeadLetterStatus `json:"status" db:"status"`
+	RequeuedBy     *uuid.UUID       `json:"requeued_by" db:"requeued_by"`
+	RequeuedAt     *time.Time       `json:"requeued_at" db:"requeued_at"`
+	ResolvedAt     *time.Time       `json:"resolved_at" db:"resolved_at"`
+	CreatedAt      time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at" db:"updated_at"`
+	// Joined data
+	WorkflowName string     `json:"workflow_name" db:"workflow_name"`
+	ActionType   ActionType `json:"action_type" db:"action_type"`
+}
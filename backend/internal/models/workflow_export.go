@@ -0,0 +1,92 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WorkflowExportVersion is the version of the workflow export format
+const WorkflowExportVersion = 1
+
+// WorkflowExport is a portable copy of a workflow and the message templates its actions use.
+// IDs only link the parts of the document together; new ones are assigned on import.
+type WorkflowExport struct {
+	Version    int                      `json:"version"`
+	ExportedAt time.Time                `json:"exported_at"`
+	Workflow   WorkflowExportDefinition `json:"workflow"`
+	Templates  []WorkflowExportTemplate `json:"templates"`
+}
+
+// WorkflowExportDefinition is the exported workflow
+type WorkflowExportDefinition struct {
+	Name        string                     `json:"name"`
+	Description *string                    `json:"description"`
+	Module      WorkflowModule             `json:"module"`
+	EntityType  WorkflowEntityType         `json:"entity_type"`
+	States      []WorkflowExportState      `json:"states"`
+	Transitions []WorkflowExportTransition `json:"transitions"`
+	Triggers    []WorkflowExportTrigger    `json:"triggers"`
+}
+
+type WorkflowExportState struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	DisplayName string    `json:"display_name"`
+	Description *string   `json:"description"`
+	StateType   StateType `json:"state_type"`
+	Color       *string   `json:"color"`
+	Position    int       `json:"position"`
+}
+
+type WorkflowExportTransition struct {
+	ID                   uuid.UUID         `json:"id"`
+	FromStateID          uuid.UUID         `json:"from_state_id"`
+	ToStateID            uuid.UUID         `json:"to_state_id"`
+	Name                 string            `json:"name"`
+	RequiresConfirmation bool              `json:"requires_confirmation"`
+	Inputs               []TransitionInput `json:"inputs,omitempty"`
+}
+
+type WorkflowExportTrigger struct {
+	StateID           *uuid.UUID             `json:"state_id"`
+	TransitionID      *uuid.UUID             `json:"transition_id"`
+	TriggerType       TriggerType            `json:"trigger_type"`
+	TimeOffsetMinutes *int                   `json:"time_offset_minutes"`
+	TimeField         *string                `json:"time_field"`
+	RecurringCron     *string                `json:"recurring_cron"`
+	RecurringSkip     RecurringSkipRule      `json:"recurring_skip"`
+	Conditions        json.RawMessage        `json:"conditions"`
+	IsActive          bool                   `json:"is_active"`
+	Actions           []WorkflowExportAction `json:"actions"`
+}
+
+type WorkflowExportAction struct {
+	ActionType          ActionType      `json:"action_type"`
+	ActionOrder         int             `json:"action_order"`
+	TemplateID          *uuid.UUID      `json:"template_id"`
+	ActionConfig        json.RawMessage `json:"action_config"`
+	Urgency             ActionUrgency   `json:"urgency"`
+	RetryMaxAttempts    int             `json:"retry_max_attempts"`
+	RetryBackoffSeconds int             `json:"retry_backoff_seconds"`
+	IsActive            bool            `json:"is_active"`
+}
+
+type WorkflowExportTemplate struct {
+	ID                 uuid.UUID       `json:"id"`
+	Name               string          `json:"name"`
+	Channel            MessageChannel  `json:"channel"`
+	Subject            *string         `json:"subject"`
+	Body               string          `json:"body"`
+	Variables          json.RawMessage `json:"variables"`
+	WhatsAppContentSID *string         `json:"whatsapp_content_sid"`
+}
+
+// WorkflowImportResult describes what an import created
+type WorkflowImportResult struct {
+	Workflow *Workflow `json:"workflow"`
+	// Templates already in the organization (same name and channel) are reused rather than copied
+	TemplatesCreated []string `json:"templates_created"`
+	TemplatesReused  []string `json:"templates_reused"`
+}
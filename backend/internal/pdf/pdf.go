@@ -0,0 +1,246 @@
+// Package pdf writes simple PDF documents: text in the standard Helvetica fonts, lines,
+// filled rectangles and raster images. It covers business documents such as budgets
+// without a layout engine; callers position everything themselves.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// A4 page size in points, the unit of every coordinate in this package
+const (
+	A4Width  = 595.28
+	A4Height = 841.89
+)
+
+// Font is one of the standard fonts every PDF reader ships
+type Font int
+
+const (
+	Regular Font = iota // Helvetica
+	Bold                // Helvetica-Bold
+)
+
+// Color is an RGB color
+type Color struct {
+	R, G, B uint8
+}
+
+var (
+	Black = Color{0, 0, 0}
+	White = Color{255, 255, 255}
+	Gray  = Color{110, 110, 110}
+)
+
+// ParseHexColor reads colors written as #RRGGBB
+func ParseHexColor(s string) (Color, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(s) != 6 {
+		return Color{}, fmt.Errorf("invalid color %q", s)
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return Color{}, fmt.Errorf("invalid color %q", s)
+	}
+	return Color{uint8(v >> 16), uint8(v >> 8), uint8(v)}, nil
+}
+
+func (c Color) operands() string {
+	return fmt.Sprintf("%.3f %.3f %.3f", float64(c.R)/255, float64(c.G)/255, float64(c.B)/255)
+}
+
+// Document is a PDF being built. Pages are A4 portrait.
+type Document struct {
+	pages  []*Page
+	images []*Image
+}
+
+// Page is a page of a document. Coordinates start at the top-left corner and grow
+// rightwards and downwards; text is placed by its baseline.
+type Page struct {
+	content bytes.Buffer
+	images  map[int]bool
+}
+
+// Image is a picture added to a document, drawn on pages with Page.Image
+type Image struct {
+	index  int
+	Width  int
+	Height int
+	data   []byte // compressed RGB samples
+}
+
+// New starts an empty document
+func New() *Document {
+	return &Document{}
+}
+
+// AddPage appends a blank page
+func (d *Document) AddPage() *Page {
+	p := &Page{images: map[int]bool{}}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// Pages returns the pages added so far, e.g. to stamp page numbers once the layout is done
+func (d *Document) Pages() []*Page {
+	return d.pages
+}
+
+// AddImage embeds a picture once so it can be drawn on any page. Transparent areas are
+// flattened onto white.
+func (d *Document) AddImage(img image.Image) (*Image, error) {
+	b := img.Bounds()
+	raw := make([]byte, 0, b.Dx()*b.Dy()*3)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			a := uint32(c.A)
+			raw = append(raw,
+				uint8((uint32(c.R)*a+255*(255-a))/255),
+				uint8((uint32(c.G)*a+255*(255-a))/255),
+				uint8((uint32(c.B)*a+255*(255-a))/255),
+			)
+		}
+	}
+	data, err := deflate(raw)
+	if err != nil {
+		return nil, err
+	}
+	im := &Image{index: len(d.images), Width: b.Dx(), Height: b.Dy(), data: data}
+	d.images = append(d.images, im)
+	return im, nil
+}
+
+// Text writes s with its baseline at (x, y)
+func (p *Page) Text(x, y float64, font Font, size float64, c Color, s string) {
+	fmt.Fprintf(&p.content, "BT /F%d %.2f Tf %s rg %.2f %.2f Td (%s) Tj ET\n",
+		int(font)+1, size, c.operands(), x, A4Height-y, escape(encode(s)))
+}
+
+// TextRight writes s so that it ends at x
+func (p *Page) TextRight(x, y float64, font Font, size float64, c Color, s string) {
+	p.Text(x-TextWidth(s, font, size), y, font, size, c, s)
+}
+
+// Line draws a straight line
+func (p *Page) Line(x1, y1, x2, y2, width float64, c Color) {
+	fmt.Fprintf(&p.content, "%s RG %.2f w %.2f %.2f m %.2f %.2f l S\n",
+		c.operands(), width, x1, A4Height-y1, x2, A4Height-y2)
+}
+
+// Rect fills a rectangle whose top-left corner is at (x, y)
+func (p *Page) Rect(x, y, w, h float64, c Color) {
+	fmt.Fprintf(&p.content, "%s rg %.2f %.2f %.2f %.2f re f\n", c.operands(), x, A4Height-y-h, w, h)
+}
+
+// Image draws img into the w by h box whose top-left corner is at (x, y)
+func (p *Page) Image(img *Image, x, y, w, h float64) {
+	p.images[img.index] = true
+	fmt.Fprintf(&p.content, "q %.2f 0 0 %.2f %.2f %.2f cm /Im%d Do Q\n", w, h, x, A4Height-y-h, img.index)
+}
+
+// Bytes renders the document
+func (d *Document) Bytes() ([]byte, error) {
+	// Objects: 1 catalog, 2 page tree, 3-4 fonts, then images, then each page and its content
+	firstImage := 5
+	firstPage := firstImage + len(d.images)
+	var objects [][]byte
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	objects = append(objects,
+		[]byte("<< /Type /Catalog /Pages 2 0 R >>"),
+		[]byte(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages))),
+		[]byte("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>"),
+		[]byte("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>"),
+	)
+
+	for _, im := range d.images {
+		dict := fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode /Length %d >>",
+			im.Width, im.Height, len(im.data))
+		objects = append(objects, stream(dict, im.data))
+	}
+
+	for i, p := range d.pages {
+		var xobjects strings.Builder
+		for idx := range d.images {
+			if p.images[idx] {
+				fmt.Fprintf(&xobjects, " /Im%d %d 0 R", idx, firstImage+idx)
+			}
+		}
+		resources := "/Font << /F1 3 0 R /F2 4 0 R >>"
+		if xobjects.Len() > 0 {
+			resources += " /XObject <<" + xobjects.String() + " >>"
+		}
+		objects = append(objects, []byte(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << %s >> /Contents %d 0 R >>",
+			A4Width, A4Height, resources, firstPage+2*i+1)))
+
+		content, err := deflate(p.content.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, stream(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>", len(content)), content))
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n", i+1)
+		buf.Write(obj)
+		buf.WriteString("\nendobj\n")
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes(), nil
+}
+
+func stream(dict string, data []byte) []byte {
+	out := make([]byte, 0, len(dict)+len(data)+32)
+	out = append(out, dict...)
+	out = append(out, "\nstream\n"...)
+	out = append(out, data...)
+	out = append(out, "\nendstream"...)
+	return out
+}
+
+func deflate(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// escape protects the string delimiters of PDF literal strings
+func escape(b []byte) string {
+	var sb strings.Builder
+	for _, c := range b {
+		switch c {
+		case '(', ')', '\\':
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
@@ -0,0 +1,84 @@
+package pdf
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDocumentBytes(t *testing.T) {
+	doc := New()
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.NRGBA{255, 0, 0, 255})
+	logo, err := doc.AddImage(img)
+	if err != nil {
+		t.Fatalf("AddImage: %v", err)
+	}
+
+	page := doc.AddPage()
+	page.Image(logo, 40, 40, 20, 20)
+	page.Text(40, 100, Bold, 12, Black, "Orçamento (N.º 12) – 100,00 €")
+	page.Line(40, 110, 200, 110, 0.5, Gray)
+	doc.AddPage().Rect(40, 40, 100, 20, White)
+
+	out, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes: %v", err)
+	}
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Fatal("missing PDF header or trailer")
+	}
+	if !bytes.Contains(out, []byte("/Count 2")) {
+		t.Error("expected two pages in the page tree")
+	}
+
+	// every cross-reference entry must point at the start of its object
+	xrefAt := bytes.LastIndex(out, []byte("startxref\n"))
+	start, _ := strconv.Atoi(strings.TrimSpace(strings.Split(string(out[xrefAt+len("startxref\n"):]), "\n")[0]))
+	lines := strings.Split(string(out[start:]), "\n")
+	for i := 1; ; i++ {
+		entry := lines[2+i]
+		if entry == "trailer" {
+			break
+		}
+		off, _ := strconv.Atoi(entry[:10])
+		if want := strconv.Itoa(i) + " 0 obj"; !bytes.HasPrefix(out[off:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q", i, out[off:off+10])
+		}
+	}
+}
+
+func TestEncodeAndWidths(t *testing.T) {
+	if got := string(encode("ção € ✓")); got != "\xe7\xe3o \x80 ?" {
+		t.Errorf("encode = %q", got)
+	}
+	if got := TextWidth("Aa", Regular, 10); got != 12.23 {
+		t.Errorf("TextWidth = %v, want 12.23", got)
+	}
+	if TextWidth("é", Bold, 10) != TextWidth("e", Bold, 10) {
+		t.Error("accented letters should be as wide as their base letter")
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	lines := WrapText("Pintura interior de paredes\nRemoção de entulho", Regular, 10, 100)
+	if len(lines) < 3 {
+		t.Fatalf("expected the first paragraph to wrap, got %q", lines)
+	}
+	for _, l := range lines {
+		if TextWidth(l, Regular, 10) > 100 {
+			t.Errorf("line %q is wider than the box", l)
+		}
+	}
+	if lines[len(lines)-1] != "Remoção de entulho" {
+		t.Errorf("last line = %q", lines[len(lines)-1])
+	}
+
+	long := WrapText("Impermeabilização", Bold, 12, 30)
+	if strings.Join(long, "") != "Impermeabilização" {
+		t.Errorf("cut word lost characters: %q", long)
+	}
+}
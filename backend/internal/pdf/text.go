@@ -0,0 +1,122 @@
+package pdf
+
+import "strings"
+
+// Glyph widths of the standard fonts in thousandths of the font size, from the Adobe
+// font metrics, for the printable ASCII range.
+var asciiWidths = [2][95]uint16{
+	Regular: {
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space to /
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556, // 0 to ?
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778, // @ to O
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556, // P to _
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556, // ` to o
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584, // p to ~
+	},
+	Bold: {
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	},
+}
+
+// latinBase gives the unaccented letter of each Latin-1 character from 0xC0, whose width
+// is the same; '.' marks characters with their own width in latinWidths.
+const latinBase = "AAAAAA.CEEEEIIIIDNOOOOO.OUUUUYP.aaaaaa.ceeeeiiiionooooo.ouuuuypy"
+
+var latinWidths = map[byte]uint16{
+	0x80: 556, 0x85: 1000, 0x91: 222, 0x92: 222, 0x93: 333, 0x94: 333, 0x95: 350, 0x96: 556, 0x97: 1000,
+	0xA0: 278, 0xAA: 370, 0xB0: 400, 0xBA: 365, 0xC6: 1000, 0xD7: 584, 0xDF: 611, 0xE6: 889, 0xF7: 584,
+}
+
+// winAnsi maps the characters of the WinAnsi encoding outside Latin-1 to their codes
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+	'‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91, '’': 0x92, '“': 0x93,
+	'”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B,
+	'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// encode converts text to the WinAnsi encoding of the standard fonts. Characters it
+// cannot represent are replaced with '?'.
+func encode(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r':
+			out = append(out, ' ')
+		case r >= 0x20 && r < 0x7F, r >= 0xA0 && r <= 0xFF:
+			out = append(out, byte(r))
+		default:
+			if b, ok := winAnsi[r]; ok {
+				out = append(out, b)
+			} else {
+				out = append(out, '?')
+			}
+		}
+	}
+	return out
+}
+
+func glyphWidth(c byte, font Font) uint16 {
+	switch {
+	case c >= 0x20 && c < 0x7F:
+		return asciiWidths[font][c-0x20]
+	case c >= 0xC0 && latinBase[c-0xC0] != '.':
+		return asciiWidths[font][latinBase[c-0xC0]-0x20]
+	}
+	if w, ok := latinWidths[c]; ok {
+		return w
+	}
+	return 556
+}
+
+// TextWidth is the width in points of s written in font at size
+func TextWidth(s string, font Font, size float64) float64 {
+	var total int
+	for _, c := range encode(s) {
+		total += int(glyphWidth(c, font))
+	}
+	return float64(total) * size / 1000
+}
+
+// WrapText splits s into lines no wider than width, breaking at spaces and at the line
+// breaks of s. Words longer than a line are cut.
+func WrapText(s string, font Font, size, width float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		words := strings.Fields(paragraph)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+		line := ""
+		for _, word := range words {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if TextWidth(candidate, font, size) <= width {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			for len([]rune(word)) > 1 && TextWidth(word, font, size) > width {
+				cut := len([]rune(word)) - 1
+				for cut > 1 && TextWidth(string([]rune(word)[:cut]), font, size) > width {
+					cut--
+				}
+				lines = append(lines, string([]rune(word)[:cut]))
+				word = string([]rune(word)[cut:])
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
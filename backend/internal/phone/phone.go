@@ -0,0 +1,174 @@
+// Package phone parses phone numbers typed in any common national or international form and
+// stores them in E.164 (+<country code><national number>). Numbers without a country code are
+// read with the organization's default country, so international patients and clients can be
+// reached and matched the same way as local ones.
+package phone
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// DefaultCountry is used when an organization has not chosen one
+const DefaultCountry = "PT"
+
+var (
+	ErrInvalid        = errors.New("invalid phone number")
+	ErrUnknownCountry = errors.New("unsupported country")
+)
+
+// Country is the numbering plan of a country
+type Country struct {
+	Code        string // ISO 3166-1 alpha-2
+	CallingCode string
+	TrunkPrefix string // dialled before national numbers inside the country, dropped in E.164
+	MinLength   int    // national number length, without the trunk prefix
+	MaxLength   int
+}
+
+// countries are the numbering plans numbers are validated against. Numbers of other calling
+// codes are accepted when they have a plausible E.164 length.
+var countries = map[string]Country{
+	"PT": {"PT", "351", "", 9, 9},
+	"ES": {"ES", "34", "", 9, 9},
+	"FR": {"FR", "33", "0", 9, 9},
+	"GB": {"GB", "44", "0", 10, 10},
+	"IE": {"IE", "353", "0", 7, 9},
+	"DE": {"DE", "49", "0", 6, 13},
+	"IT": {"IT", "39", "", 6, 11},
+	"NL": {"NL", "31", "0", 9, 9},
+	"BE": {"BE", "32", "0", 8, 9},
+	"LU": {"LU", "352", "", 6, 11},
+	"CH": {"CH", "41", "0", 9, 9},
+	"US": {"US", "1", "1", 10, 10},
+	"BR": {"BR", "55", "0", 10, 11},
+	"AO": {"AO", "244", "", 9, 9},
+	"MZ": {"MZ", "258", "", 8, 9},
+	"CV": {"CV", "238", "", 7, 7},
+}
+
+// byCallingCode finds a country from the calling code at the start of international numbers
+var byCallingCode = func() map[string]Country {
+	m := make(map[string]Country, len(countries))
+	for _, c := range countries {
+		m[c.CallingCode] = c
+	}
+	return m
+}()
+
+// LookupCountry returns the numbering plan of a country code such as "PT"
+func LookupCountry(code string) (Country, bool) {
+	c, ok := countries[strings.ToUpper(code)]
+	return c, ok
+}
+
+// Countries lists the supported country codes, sorted
+func Countries() []string {
+	codes := make([]string, 0, len(countries))
+	for code := range countries {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Normalize returns raw in E.164. Numbers starting with + or 00 are international; others are
+// national numbers of defaultCountry, with or without its trunk prefix or calling code.
+func Normalize(raw, defaultCountry string) (string, error) {
+	digits, international := clean(raw)
+	if digits == "" {
+		return "", ErrInvalid
+	}
+
+	if international {
+		if c, national, ok := splitCallingCode(digits); ok {
+			if !c.validLength(national) {
+				return "", ErrInvalid
+			}
+			return "+" + c.CallingCode + national, nil
+		}
+		// Unknown calling code: E.164 numbers have at most 15 digits
+		if len(digits) < 8 || len(digits) > 15 {
+			return "", ErrInvalid
+		}
+		return "+" + digits, nil
+	}
+
+	c, ok := LookupCountry(defaultCountry)
+	if !ok {
+		return "", ErrUnknownCountry
+	}
+	national := digits
+	if c.TrunkPrefix != "" && strings.HasPrefix(national, c.TrunkPrefix) && c.validLength(national[len(c.TrunkPrefix):]) {
+		national = national[len(c.TrunkPrefix):]
+	} else if strings.HasPrefix(national, c.CallingCode) && !c.validLength(national) && c.validLength(national[len(c.CallingCode):]) {
+		// Calling code typed without + or 00, e.g. 351912345678
+		national = national[len(c.CallingCode):]
+	}
+	if !c.validLength(national) {
+		return "", ErrInvalid
+	}
+	return "+" + c.CallingCode + national, nil
+}
+
+// CountryOf returns the country code of an E.164 number, or "" when its calling code is unknown
+func CountryOf(e164 string) string {
+	if c, _, ok := splitCallingCode(strings.TrimPrefix(e164, "+")); ok {
+		return c.Code
+	}
+	return ""
+}
+
+// MatchKeys returns the digit strings a stored number may have been saved as for an E.164
+// number: the full number and, for numbers of defaultCountry, the national number alone
+func MatchKeys(e164, defaultCountry string) []string {
+	digits := strings.TrimPrefix(e164, "+")
+	keys := []string{digits}
+	c, national, ok := splitCallingCode(digits)
+	if ok && strings.EqualFold(c.Code, defaultCountry) {
+		keys = append(keys, national)
+		if c.TrunkPrefix != "" {
+			keys = append(keys, c.TrunkPrefix+national)
+		}
+	}
+	return keys
+}
+
+// clean strips formatting and the whatsapp: prefix, returning the digits and whether the number
+// was written in international form
+func clean(raw string) (string, bool) {
+	raw = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(raw), "whatsapp:"))
+	international := strings.HasPrefix(raw, "+")
+
+	var b strings.Builder
+	for _, r := range raw {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' || r == ' ' || r == '-' || r == '.' || r == '(' || r == ')' || r == '/':
+		default:
+			return "", false
+		}
+	}
+	digits := b.String()
+	if !international && strings.HasPrefix(digits, "00") {
+		digits, international = digits[2:], true
+	}
+	return digits, international
+}
+
+// splitCallingCode splits the digits of an international number into a known country and its
+// national number
+func splitCallingCode(digits string) (Country, string, bool) {
+	for n := 1; n <= 3 && n < len(digits); n++ {
+		if c, ok := byCallingCode[digits[:n]]; ok {
+			return c, digits[n:], true
+		}
+	}
+	return Country{}, "", false
+}
+
+func (c Country) validLength(national string) bool {
+	return len(national) >= c.MinLength && len(national) <= c.MaxLength
+}
@@ -0,0 +1,56 @@
+package phone
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		raw     string
+		country string
+		want    string
+		wantErr bool
+	}{
+		{raw: "912 345 678", country: "PT", want: "+351912345678"},
+		{raw: "351912345678", country: "PT", want: "+351912345678"},
+		{raw: "+351 912-345-678", country: "ES", want: "+351912345678"},
+		{raw: "whatsapp:+351912345678", country: "PT", want: "+351912345678"},
+		{raw: "0033 6 12 34 56 78", country: "PT", want: "+33612345678"},
+		{raw: "06 12 34 56 78", country: "FR", want: "+33612345678"},
+		{raw: "07700 900123", country: "GB", want: "+447700900123"},
+		{raw: "(11) 91234-5678", country: "BR", want: "+5511912345678"},
+		{raw: "+30 691 234 5678", country: "PT", want: "+306912345678"},
+		{raw: "91234567", country: "PT", wantErr: true},
+		{raw: "+351 91234", country: "PT", wantErr: true},
+		{raw: "912345678", country: "XX", wantErr: true},
+		{raw: "call me", country: "PT", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := Normalize(tt.raw, tt.country)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Normalize(%q) = %q, want error", tt.raw, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("Normalize(%q) = %q, %v, want %q", tt.raw, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchKeys(t *testing.T) {
+	if got := MatchKeys("+33612345678", "FR"); !slices.Equal(got, []string{"33612345678", "612345678", "0612345678"}) {
+		t.Errorf("MatchKeys in default country = %v", got)
+	}
+	if got := MatchKeys("+33612345678", "PT"); !slices.Equal(got, []string{"33612345678"}) {
+		t.Errorf("MatchKeys abroad = %v", got)
+	}
+	if got := CountryOf("+244923456789"); got != "AO" {
+		t.Errorf("CountryOf = %q, want AO", got)
+	}
+}
@@ -28,6 +28,9 @@ func Setup(services *services.Services, cfg *config.Config) http.Handler {
 	// Security headers
 	r.Use(securityHeaders)
 
+	// Response language from Accept-Language
+	r.Use(middleware.Locale)
+
 	// Rate limiting - 100 requests per minute per IP
 	r.Use(httprate.LimitByIP(100, time.Minute))
 
@@ -35,8 +38,8 @@ func Setup(services *services.Services, cfg *config.Config) http.Handler {
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{cfg.App.FrontendURL},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Accept-Language", "Authorization", "Content-Type", "X-CSRF-Token"},
+		ExposedHeaders:   []string{"Link", "Content-Language"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
@@ -48,27 +51,54 @@ func Setup(services *services.Services, cfg *config.Config) http.Handler {
 	// Initialize module middleware
 	moduleMiddleware := middleware.NewModuleMiddleware(services.Module)
 
+	// Sensitive admin actions wait for open security alerts to be justified
+	securityAlertMiddleware := middleware.NewSecurityAlertMiddleware(services.AdminSecurity)
+
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(services.Auth)
 	organizationHandler := handlers.NewOrganizationHandler(services.Organization)
 	userHandler := handlers.NewUserHandler(services.User)
+	invitationHandler := handlers.NewInvitationHandler(services.Invitation)
 	clientHandler := handlers.NewClientHandler(services.Client)
 	worksheetHandler := handlers.NewWorksheetHandler(services.Worksheet)
 	budgetHandler := handlers.NewBudgetHandler(services.Budget)
+	budgetApprovalHandler := handlers.NewBudgetApprovalHandler(services.Budget)
 	projectHandler := handlers.NewProjectHandler(services.Project)
+	projectTemplateHandler := handlers.NewProjectTemplateHandler(services.ProjectTemplate)
+	complianceHandler := handlers.NewComplianceHandler(services.Compliance)
+	crewHandler := handlers.NewCrewHandler(services.Crew)
+	materialHandler := handlers.NewMaterialHandler(services.Material)
 	taskHandler := handlers.NewTaskHandler(services.Task)
 	paymentHandler := handlers.NewPaymentHandler(services.Payment)
+	financialPeriodHandler := handlers.NewFinancialPeriodHandler(services.FinancialPeriod)
+	expenseHandler := handlers.NewExpenseHandler(services.Expense)
+	bankStatementHandler := handlers.NewBankStatementHandler(services.BankStatement)
+	accountantHandler := handlers.NewAccountantHandler(services.Accountant)
+	dataExportHandler := handlers.NewDataExportHandler(services.DataExport, services.AdminAudit)
 	notificationHandler := handlers.NewNotificationHandler(services.Notification)
 	reportHandler := handlers.NewReportHandler(services.Report)
 	moduleHandler := handlers.NewModuleHandler(services.Module)
+	integrationHandler := handlers.NewIntegrationHandler(services.Integration)
+	delegationHandler := handlers.NewDelegationHandler(services.Delegation)
 	// Appointments module handlers
 	patientHandler := handlers.NewPatientHandler(services.Patient)
 	therapistHandler := handlers.NewTherapistHandler(services.Therapist)
 	sessionHandler := handlers.NewSessionHandler(services.Session)
 	sessionPaymentHandler := handlers.NewSessionPaymentHandler(services.SessionPayment)
+	cashRegisterHandler := handlers.NewCashRegisterHandler(services.CashRegister)
+	invoiceHandler := handlers.NewInvoiceHandler(services.Invoice)
+	paymentLinkHandler := handlers.NewPaymentLinkHandler(services.PaymentLink)
+	paymentReferenceHandler := handlers.NewPaymentReferenceHandler(services.PaymentReference)
+	bookingHandler := handlers.NewBookingHandler(services.Booking)
+	patientPortalHandler := handlers.NewPatientPortalHandler(services.PatientPortal)
+	rescheduleHandler := handlers.NewSessionRescheduleHandler(services.Reschedule)
+	waitingListHandler := handlers.NewWaitingListHandler(services.WaitingList)
+	treatmentPlanHandler := handlers.NewTreatmentPlanHandler(services.TreatmentPlan)
 	// Notifications module handlers
-	notificationConfigHandler := handlers.NewNotificationConfigHandler(services.WhatsApp)
-	webhookHandler := handlers.NewWebhookHandler(services.WhatsApp)
+	notificationConfigHandler := handlers.NewNotificationConfigHandler(services.WhatsApp, services.EmailDelivery, services.Workflow)
+	chatWebhookHandler := handlers.NewChatWebhookHandler(services.ChatWebhook)
+	conversationHandler := handlers.NewWhatsAppConversationHandler(services.Conversation)
+	webhookHandler := handlers.NewWebhookHandler(services.WhatsApp, services.EmailSuppression)
 	// Workflow engine handler
 	workflowHandler := handlers.NewWorkflowHandler(services.Workflow)
 	// System Admin handlers
@@ -77,7 +107,24 @@ func Setup(services *services.Services, cfg *config.Config) http.Handler {
 	adminUsersHandler := handlers.NewAdminUsersHandler(services.AdminUser, services.AdminAudit)
 	adminImpersonationHandler := handlers.NewAdminImpersonationHandler(services.Impersonation, services.AdminAudit)
 	adminDashboardHandler := handlers.NewAdminDashboardHandler(services.AdminStats)
+	adminNotificationQueueHandler := handlers.NewAdminNotificationQueueHandler(services.NotificationQueue)
 	adminAuditHandler := handlers.NewAdminAuditHandler(services.AdminAudit)
+	adminUsageHandler := handlers.NewAdminUsageHandler(services.Usage, services.AdminAudit)
+	adminIntegrityHandler := handlers.NewAdminIntegrityHandler(services.Integrity, services.AdminAudit)
+	adminLogRetentionHandler := handlers.NewAdminLogRetentionHandler(services.LogRetention, services.AdminAudit)
+	adminMergeHandler := handlers.NewAdminOrganizationMergeHandler(services.OrganizationMerge, services.AdminAudit)
+	adminSecurityHandler := handlers.NewAdminSecurityHandler(services.AdminSecurity, services.AdminAudit)
+	adminDeletionHandler := handlers.NewAdminOrganizationDeletionHandler(services.OrganizationDeletion, services.AdminAudit)
+	organizationDeletionHandler := handlers.NewOrganizationDeletionHandler(services.OrganizationDeletion)
+	usageHandler := handlers.NewUsageHandler(services.Usage)
+	benchmarkHandler := handlers.NewBenchmarkHandler(services.Benchmark)
+	consentHandler := handlers.NewConsentHandler(services.Consent)
+	emailSuppressionHandler := handlers.NewEmailSuppressionHandler(services.EmailSuppression)
+	eventsHandler := handlers.NewEventsHandler(services.Events)
+	inboxHandler := handlers.NewInboxHandler(services.Inbox)
+	followUpHandler := handlers.NewFollowUpHandler(services.FollowUp)
+	quickCreateHandler := handlers.NewQuickCreateHandler(services.QuickCreate)
+	sandboxHandler := handlers.NewSandboxHandler(services.Sandbox)
 
 	// Public routes
 	r.Group(func(r chi.Router) {
@@ -87,11 +134,48 @@ func Setup(services *services.Services, cfg *config.Config) http.Handler {
 		r.Post("/auth/forgot-password", authHandler.ForgotPassword)
 		r.Post("/auth/reset-password", authHandler.ResetPassword)
 
-		// Twilio webhooks (public endpoints)
+		// Messaging and payment provider webhooks (public endpoints)
 		r.Route("/webhooks", func(r chi.Router) {
 			r.Post("/whatsapp", webhookHandler.TwilioIncoming)
 			r.Post("/whatsapp/status", webhookHandler.TwilioStatus)
+			r.Post("/whatsapp/{orgToken}", webhookHandler.TwilioIncomingForOrg)
+			r.Get("/meta/{orgToken}", webhookHandler.MetaVerify)
+			r.Post("/meta/{orgToken}", webhookHandler.MetaIncoming)
+			r.Post("/email/sendgrid/{orgToken}", webhookHandler.SendGridEvents)
+			r.Post("/email/ses/{orgToken}", webhookHandler.SESEvents)
+			r.Post("/stripe/{orgToken}", paymentLinkHandler.StripeWebhook)
+			r.Get("/ifthenpay/{orgToken}", paymentReferenceHandler.IfthenpayCallback)
+			r.Post("/easypay/{orgToken}", paymentReferenceHandler.EasypayNotification)
+		})
+
+		// Public booking (appointments module)
+		r.Route("/public/organizations/{orgId}", func(r chi.Router) {
+			r.Get("/services", bookingHandler.PublicServices)
+			r.Get("/services/{serviceId}/slots", bookingHandler.PublicSlots)
 		})
+		r.Get("/public/embed/widget.js", bookingHandler.EmbedWidget)
+		r.Get("/public/embed/{orgId}/availability", bookingHandler.EmbedAvailability)
+
+		// Session reschedule links (sent in reminder and cancellation messages)
+		r.Get("/public/reschedule/{token}", rescheduleHandler.View)
+		r.Get("/public/reschedule/{token}/slots", rescheduleHandler.Slots)
+		r.Post("/public/reschedule/{token}", rescheduleHandler.Reschedule)
+
+		// Waiting list slot offers (sent over WhatsApp by offer_slot actions)
+		r.Get("/public/waiting-list/offers/{token}", waitingListHandler.ViewOffer)
+		r.Post("/public/waiting-list/offers/{token}/accept", waitingListHandler.AcceptOffer)
+		r.Post("/public/waiting-list/offers/{token}/decline", waitingListHandler.DeclineOffer)
+
+		// Invitation accept page
+		r.Get("/public/invitations/{token}", invitationHandler.PublicGet)
+		r.Post("/public/invitations/{token}/accept", invitationHandler.PublicAccept)
+
+		// Client budget portal (signed links sent with the budget)
+		r.Get("/public/budgets/{token}", budgetHandler.PortalView)
+		r.Get("/public/budgets/{token}/pdf", budgetHandler.PortalPDF)
+		r.Post("/public/budgets/{token}/approve", budgetHandler.PortalApprove)
+		r.Post("/public/budgets/{token}/reject", budgetHandler.PortalReject)
+		r.Post("/public/budgets/{token}/items/{itemId}/comments", budgetHandler.PortalComment)
 
 		// System Admin public routes (login only)
 		r.Post("/admin/auth/login", adminAuthHandler.Login)
@@ -111,38 +195,99 @@ func Setup(services *services.Services, cfg *config.Config) http.Handler {
 		r.Get("/dashboard/stats", adminDashboardHandler.GetStats)
 		r.Get("/dashboard/recent-activity", adminDashboardHandler.GetRecentActivity)
 
+		// Outbound notification queues and rate limit backlogs
+		r.Get("/notification-queues", adminNotificationQueueHandler.GetStats)
+
 		// Organizations
 		r.Route("/organizations", func(r chi.Router) {
 			r.Get("/", adminOrgsHandler.List)
 			r.Post("/", adminOrgsHandler.Create)
 			r.Get("/{id}", adminOrgsHandler.GetByID)
 			r.Put("/{id}", adminOrgsHandler.Update)
-			r.Post("/{id}/suspend", adminOrgsHandler.Suspend)
+			r.With(securityAlertMiddleware.RequireJustifiedAlerts).Post("/{id}/suspend", adminOrgsHandler.Suspend)
 			r.Post("/{id}/reactivate", adminOrgsHandler.Reactivate)
-			r.Delete("/{id}", adminOrgsHandler.Delete)
+			r.With(securityAlertMiddleware.RequireJustifiedAlerts).Delete("/{id}", adminOrgsHandler.Delete)
 			r.Get("/{id}/users", adminUsersHandler.ListByOrganization)
+			// Data usage and quotas
+			r.Get("/{id}/usage", adminUsageHandler.GetByOrganization)
+			r.Put("/{id}/quotas", adminUsageHandler.UpdateQuotas)
+			// Data integrity checks and repair of the safe categories
+			r.Get("/{id}/integrity", adminIntegrityHandler.Check)
+			r.With(securityAlertMiddleware.RequireJustifiedAlerts).Post("/{id}/integrity/repair", adminIntegrityHandler.Repair)
+			// Execution log retention and archives
+			r.Get("/{id}/log-retention", adminLogRetentionHandler.GetPolicy)
+			r.Put("/{id}/log-retention", adminLogRetentionHandler.UpdatePolicy)
+			r.Get("/{id}/log-archives", adminLogRetentionHandler.ListArchives)
+			r.With(securityAlertMiddleware.RequireJustifiedAlerts).Post("/{id}/log-archives", adminLogRetentionHandler.RequestArchive)
+			r.With(securityAlertMiddleware.RequireJustifiedAlerts).Get("/{id}/log-archives/{archiveId}/download", adminLogRetentionHandler.DownloadArchive)
+			// Merging another organization into this one
+			r.Get("/{id}/merges", adminMergeHandler.List)
+			r.With(securityAlertMiddleware.RequireJustifiedAlerts).Post("/{id}/merges", adminMergeHandler.Create)
+			r.Get("/{id}/merges/{mergeId}", adminMergeHandler.Get)
+			r.Post("/{id}/merges/{mergeId}/resume", adminMergeHandler.Resume)
 			// Module management for organization
 			r.Get("/{id}/modules", adminOrgsHandler.ListModules)
 			r.Post("/{id}/modules/{module}/enable", adminOrgsHandler.EnableModule)
 			r.Post("/{id}/modules/{module}/disable", adminOrgsHandler.DisableModule)
 		})
 
+		// Data usage across organizations
+		r.Get("/usage", adminUsageHandler.List)
+
+		// Module release notes
+		r.Post("/modules/{module}/changelog", adminOrgsHandler.PublishModuleChangelog)
+
 		// Users
 		r.Route("/users", func(r chi.Router) {
 			r.Get("/", adminUsersHandler.List)
 			r.Get("/{id}", adminUsersHandler.GetByID)
-			r.Post("/{id}/suspend", adminUsersHandler.Suspend)
+			r.With(securityAlertMiddleware.RequireJustifiedAlerts).Post("/{id}/suspend", adminUsersHandler.Suspend)
 			r.Post("/{id}/reactivate", adminUsersHandler.Reactivate)
 			r.Post("/{id}/reset-password", adminUsersHandler.ResetPassword)
 		})
 
 		// Impersonation
-		r.Post("/impersonate/{userId}", adminImpersonationHandler.Start)
+		r.With(securityAlertMiddleware.RequireJustifiedAlerts).Post("/impersonate/{userId}", adminImpersonationHandler.Start)
 		r.Get("/impersonate/active", adminImpersonationHandler.GetActiveSession)
 		r.Get("/impersonate/sessions", adminImpersonationHandler.ListSessions)
 
 		// Audit Logs
 		r.Get("/audit-logs", adminAuditHandler.List)
+
+		// Security alerts on unusual admin activity
+		r.Route("/security-alerts", func(r chi.Router) {
+			r.Get("/", adminSecurityHandler.List)
+			r.Get("/{id}", adminSecurityHandler.GetByID)
+			r.Post("/{id}/justify", adminSecurityHandler.Justify)
+			r.Post("/{id}/acknowledge", adminSecurityHandler.Acknowledge)
+			r.Post("/{id}/dismiss", adminSecurityHandler.Dismiss)
+		})
+
+		// Organization deletions requested by their admins
+		r.Route("/organization-deletions", func(r chi.Router) {
+			r.Get("/", adminDeletionHandler.List)
+			r.Get("/{id}", adminDeletionHandler.GetByID)
+			r.Post("/{id}/cancel", adminDeletionHandler.Cancel)
+			r.Post("/{id}/retry", adminDeletionHandler.Retry)
+			r.With(securityAlertMiddleware.RequireJustifiedAlerts).Get("/{id}/export", adminDeletionHandler.DownloadExport)
+		})
+	})
+
+	// Patient portal: sign-in links, then routes for the signed-in patient only
+	r.Route("/patient-portal", func(r chi.Router) {
+		r.Post("/login-link", patientPortalHandler.RequestLoginLink)
+		r.Post("/login", patientPortalHandler.Login)
+
+		r.Group(func(r chi.Router) {
+			r.Use(authMiddleware.AuthenticatePatient)
+			r.Use(moduleMiddleware.RequireModule(models.ModuleAppointments))
+			r.Get("/me", patientPortalHandler.Me)
+			r.Put("/me/contact", patientPortalHandler.UpdateContact)
+			r.Get("/sessions", patientPortalHandler.ListSessions)
+			r.Post("/sessions/{id}/confirm", patientPortalHandler.ConfirmSession)
+			r.Post("/sessions/{id}/cancel", patientPortalHandler.CancelSession)
+			r.Get("/payments", patientPortalHandler.ListPayments)
+		})
 	})
 
 	// End impersonation route (available during impersonation with regular user token)
@@ -166,37 +311,112 @@ func Setup(services *services.Services, cfg *config.Config) http.Handler {
 			r.Get("/", organizationHandler.GetCurrent)
 			r.Put("/", organizationHandler.Update)
 			r.Post("/logo", organizationHandler.UploadLogo)
+			r.Get("/usage", usageHandler.Get)
+			// Opt-in to anonymous benchmarking against other organizations
+			r.Get("/benchmarking", benchmarkHandler.GetParticipation)
+			r.Put("/benchmarking", benchmarkHandler.SetParticipation)
+			// Deletion of the organization, after a cooling-off period
+			r.Get("/deletion-request", organizationDeletionHandler.Get)
+			r.Post("/deletion-request", organizationDeletionHandler.Request)
+			r.Post("/deletion-request/cancel", organizationDeletionHandler.Cancel)
 		})
 
+		// Live dashboard updates (server-sent events)
+		r.Get("/events/stream", eventsHandler.Stream)
+
+		// Priority inbox of items needing action
+		r.Route("/inbox", func(r chi.Router) {
+			r.Get("/", inboxHandler.List)
+			r.Post("/{type}/{id}/assign", inboxHandler.Assign)
+			r.Post("/{type}/{id}/snooze", inboxHandler.Snooze)
+			r.Post("/{type}/{id}/dismiss", inboxHandler.Dismiss)
+		})
+
+		// Personal follow-up reminders
+		r.Route("/follow-ups", func(r chi.Router) {
+			r.Get("/", followUpHandler.List)
+			r.Post("/", followUpHandler.Create)
+			r.Put("/{id}", followUpHandler.Reschedule)
+			r.Post("/{id}/complete", followUpHandler.Complete)
+			r.Delete("/{id}", followUpHandler.Cancel)
+		})
+
+		// External integrations
+		r.Get("/integrations/health", integrationHandler.Health)
+
 		// Modules
 		r.Route("/modules", func(r chi.Router) {
 			r.Get("/available", moduleHandler.ListAvailable)
 			r.Get("/", moduleHandler.ListOrganizationModules)
 			r.Get("/enabled", moduleHandler.GetEnabledModules)
+			r.Get("/whats-new", moduleHandler.GetWhatsNew)
+			r.Post("/whats-new/seen", moduleHandler.MarkWhatsNewSeen)
 			r.Post("/{module}/enable", moduleHandler.EnableModule)
 			r.Post("/{module}/disable", moduleHandler.DisableModule)
 			r.Get("/{module}/config", moduleHandler.GetModuleConfig)
 			r.Put("/{module}/config", moduleHandler.UpdateModuleConfig)
+			r.Get("/{module}/config/schema", moduleHandler.GetModuleConfigSchema)
+			r.Get("/{module}/changelog", moduleHandler.GetModuleChangelog)
 		})
 
 		// Users
 		r.Route("/users", func(r chi.Router) {
 			r.Get("/", userHandler.List)
-			r.Post("/", userHandler.Create)
+			r.Get("/me", userHandler.GetProfile)
+			r.Put("/me", userHandler.UpdateProfile)
+			r.Put("/me/password", userHandler.ChangePassword)
+			r.Post("/me/avatar", userHandler.UploadAvatar)
 			r.Get("/{id}", userHandler.Get)
 			r.Put("/{id}", userHandler.Update)
+			r.Post("/{id}/deactivate", userHandler.Deactivate)
+			r.Post("/{id}/reactivate", userHandler.Reactivate)
 			r.Delete("/{id}", userHandler.Delete)
 		})
 
+		// User invitations
+		r.Route("/invitations", func(r chi.Router) {
+			r.Get("/", invitationHandler.List)
+			r.Post("/", invitationHandler.Create)
+			r.Post("/{id}/resend", invitationHandler.Resend)
+			r.Post("/{id}/revoke", invitationHandler.Revoke)
+		})
+
+		// Out-of-office & delegation
+		r.Route("/out-of-office", func(r chi.Router) {
+			r.Get("/", delegationHandler.ListOutOfOffice)
+			r.Post("/", delegationHandler.CreateOutOfOffice)
+			r.Get("/delegations", delegationHandler.ListDelegations)
+			r.Put("/{id}", delegationHandler.UpdateOutOfOffice)
+			r.Delete("/{id}", delegationHandler.DeleteOutOfOffice)
+		})
+
 		// Clients (Core feature - available to all organizations)
 		r.Route("/clients", func(r chi.Router) {
 			r.Get("/", clientHandler.List)
 			r.Post("/", clientHandler.Create)
+			r.Get("/duplicates", clientHandler.CheckDuplicates)
 			r.Get("/{id}", clientHandler.Get)
+			r.Get("/{id}/detail", clientHandler.GetDetail)
+			r.Get("/{id}/timeline", clientHandler.Timeline)
 			r.Put("/{id}", clientHandler.Update)
 			r.Delete("/{id}", clientHandler.Delete)
 		})
 
+		// Contact consent to be messaged, per channel
+		r.Route("/consents", func(r chi.Router) {
+			r.Get("/", consentHandler.List)
+			r.Post("/", consentHandler.Create)
+			r.Get("/{id}", consentHandler.Get)
+			r.Put("/{id}", consentHandler.Update)
+			r.Delete("/{id}", consentHandler.Delete)
+		})
+
+		// Email addresses suppressed after a hard bounce or spam complaint
+		r.Route("/email-suppressions", func(r chi.Router) {
+			r.Get("/", emailSuppressionHandler.List)
+			r.Delete("/{id}", emailSuppressionHandler.Delete)
+		})
+
 		// Worksheets (Construction module)
 		r.Route("/worksheets", func(r chi.Router) {
 			r.Use(moduleMiddleware.RequireModule(models.ModuleConstruction))
@@ -215,15 +435,59 @@ func Setup(services *services.Services, cfg *config.Config) http.Handler {
 			r.Use(moduleMiddleware.RequireModule(models.ModuleConstruction))
 			r.Get("/", budgetHandler.List)
 			r.Post("/", budgetHandler.Create)
+			r.Get("/pdf-template", budgetHandler.GetPDFTemplate)
+			r.Put("/pdf-template", budgetHandler.UpdatePDFTemplate)
 			r.Get("/{id}", budgetHandler.Get)
 			r.Put("/{id}", budgetHandler.Update)
 			r.Delete("/{id}", budgetHandler.Delete)
 			r.Post("/{id}/send", budgetHandler.Send)
 			r.Post("/{id}/approve", budgetHandler.Approve)
 			r.Post("/{id}/reject", budgetHandler.Reject)
+			r.Post("/{id}/loss-reason", budgetHandler.RecordLossReason)
 			r.Post("/{id}/photos", budgetHandler.UploadPhoto)
 			r.Get("/{id}/photos", budgetHandler.ListPhotos)
 			r.Get("/{id}/pdf", budgetHandler.GeneratePDF)
+			r.Post("/{id}/pdf", budgetHandler.RegeneratePDF)
+			r.Get("/{id}/portal-link", budgetHandler.GetPortalLink)
+			r.Post("/{id}/portal-link/rotate", budgetHandler.RotatePortalLink)
+			r.Get("/{id}/comments", budgetHandler.ListComments)
+			r.Post("/{id}/items/{itemId}/comments", budgetHandler.ReplyToItem)
+			r.Get("/{id}/revisions", budgetHandler.ListRevisions)
+			r.Get("/{id}/revisions/diff", budgetHandler.DiffRevisions)
+			// Internal approval
+			r.Get("/{id}/internal-approvals", budgetApprovalHandler.ListApprovals)
+			r.Post("/{id}/internal-approvals/approve", budgetApprovalHandler.Approve)
+			r.Post("/{id}/internal-approvals/reject", budgetApprovalHandler.Reject)
+			// Price drift against price history and cost index
+			r.Get("/{id}/price-analysis", materialHandler.PriceAnalysis)
+			r.Post("/{id}/price-analysis/apply", materialHandler.ApplyPriceSuggestions)
+		})
+
+		// Budget Approval Rules (Construction module)
+		r.Route("/budget-approval-rules", func(r chi.Router) {
+			r.Use(moduleMiddleware.RequireModule(models.ModuleConstruction))
+			r.Get("/", budgetApprovalHandler.ListRules)
+			r.Post("/", budgetApprovalHandler.CreateRule)
+			r.Put("/{id}", budgetApprovalHandler.UpdateRule)
+			r.Delete("/{id}", budgetApprovalHandler.DeleteRule)
+		})
+
+		// Materials catalogue and supplier price list imports (Construction module)
+		r.Route("/materials", func(r chi.Router) {
+			r.Use(moduleMiddleware.RequireModule(models.ModuleConstruction))
+			r.Get("/", materialHandler.List)
+			r.Post("/imports", materialHandler.PreviewImport)
+			r.Get("/imports/{id}", materialHandler.GetImport)
+			r.Post("/imports/{id}/confirm", materialHandler.ConfirmImport)
+			r.Post("/imports/{id}/cancel", materialHandler.CancelImport)
+		})
+
+		// Construction cost index (Construction module)
+		r.Route("/cost-indexes", func(r chi.Router) {
+			r.Use(moduleMiddleware.RequireModule(models.ModuleConstruction))
+			r.Get("/", materialHandler.ListCostIndex)
+			r.Put("/{period}", materialHandler.SetCostIndex)
+			r.Delete("/{period}", materialHandler.DeleteCostIndex)
 		})
 
 		// Projects (Construction module)
@@ -236,8 +500,52 @@ func Setup(services *services.Services, cfg *config.Config) http.Handler {
 			r.Delete("/{id}", projectHandler.Delete)
 			r.Patch("/{id}/status", projectHandler.UpdateStatus)
 			r.Patch("/{id}/progress", projectHandler.UpdateProgress)
+			r.Put("/{id}/progress/mode", projectHandler.UpdateProgressMode)
+			r.Delete("/{id}/progress/override", projectHandler.ClearProgressOverride)
+			r.Get("/{id}/timeline", projectHandler.Timeline)
+			r.Get("/{id}/costing", crewHandler.ProjectCosting)
 			r.Post("/{id}/photos", projectHandler.UploadPhoto)
 			r.Get("/{id}/photos", projectHandler.ListPhotos)
+			// Compliance checklist
+			r.Get("/{id}/compliance", complianceHandler.ListProjectItems)
+			r.Post("/{id}/compliance/apply", complianceHandler.ApplyRequirements)
+			r.Post("/{id}/compliance/{itemId}/complete", complianceHandler.CompleteItem)
+			r.Post("/{id}/compliance/{itemId}/waive", complianceHandler.WaiveItem)
+			r.Post("/{id}/compliance/{itemId}/reopen", complianceHandler.ReopenItem)
+		})
+
+		// Crew scheduling (Construction module)
+		r.Route("/crew", func(r chi.Router) {
+			r.Use(moduleMiddleware.RequireModule(models.ModuleConstruction))
+			r.Get("/members", crewHandler.ListMembers)
+			r.Post("/members", crewHandler.CreateMember)
+			r.Put("/members/{id}", crewHandler.UpdateMember)
+			r.Delete("/members/{id}", crewHandler.DeleteMember)
+			r.Get("/assignments", crewHandler.ListAssignments)
+			r.Post("/assignments", crewHandler.Assign)
+			r.Patch("/assignments/{id}", crewHandler.UpdateAssignment)
+			r.Delete("/assignments/{id}", crewHandler.DeleteAssignment)
+			r.Get("/calendar", crewHandler.Calendar)
+		})
+
+		// Compliance Requirements (Construction module)
+		r.Route("/compliance-requirements", func(r chi.Router) {
+			r.Use(moduleMiddleware.RequireModule(models.ModuleConstruction))
+			r.Get("/", complianceHandler.ListRequirements)
+			r.Post("/", complianceHandler.CreateRequirement)
+			r.Put("/{id}", complianceHandler.UpdateRequirement)
+			r.Delete("/{id}", complianceHandler.DeleteRequirement)
+		})
+
+		// Project Templates (Construction module)
+		r.Route("/project-templates", func(r chi.Router) {
+			r.Use(moduleMiddleware.RequireModule(models.ModuleConstruction))
+			r.Get("/", projectTemplateHandler.List)
+			r.Post("/", projectTemplateHandler.Create)
+			r.Get("/{id}", projectTemplateHandler.Get)
+			r.Put("/{id}", projectTemplateHandler.Update)
+			r.Delete("/{id}", projectTemplateHandler.Delete)
+			r.Post("/{id}/instantiate", projectTemplateHandler.Instantiate)
 		})
 
 		// Tasks (Construction module)
@@ -245,11 +553,13 @@ func Setup(services *services.Services, cfg *config.Config) http.Handler {
 			r.Use(moduleMiddleware.RequireModule(models.ModuleConstruction))
 			r.Get("/", taskHandler.List)
 			r.Post("/", taskHandler.Create)
+			r.Get("/auto-assign", taskHandler.SuggestAssignee)
 			r.Get("/{id}", taskHandler.Get)
 			r.Put("/{id}", taskHandler.Update)
 			r.Delete("/{id}", taskHandler.Delete)
 			r.Patch("/{id}/status", taskHandler.UpdateStatus)
 			r.Patch("/{id}/assign", taskHandler.Assign)
+			r.Post("/{id}/auto-assign", taskHandler.AutoAssign)
 		})
 
 		// Payments (Construction module)
@@ -257,6 +567,7 @@ func Setup(services *services.Services, cfg *config.Config) http.Handler {
 			r.Use(moduleMiddleware.RequireModule(models.ModuleConstruction))
 			r.Get("/", paymentHandler.List)
 			r.Post("/", paymentHandler.Create)
+			r.Get("/summary", paymentHandler.GetSummary)
 			r.Get("/{id}", paymentHandler.Get)
 			r.Put("/{id}", paymentHandler.Update)
 			r.Delete("/{id}", paymentHandler.Delete)
@@ -271,10 +582,35 @@ func Setup(services *services.Services, cfg *config.Config) http.Handler {
 			r.Get("/", patientHandler.List)
 			r.Post("/", patientHandler.Create)
 			r.Get("/stats", patientHandler.GetStats)
+			r.Get("/portal-settings", patientPortalHandler.GetSettings)
+			r.Put("/portal-settings", patientPortalHandler.UpdateSettings)
 			r.Get("/{id}", patientHandler.Get)
 			r.Put("/{id}", patientHandler.Update)
 			r.Delete("/{id}", patientHandler.Delete)
+			r.Put("/{id}/client", patientHandler.LinkClient)
 			r.Get("/{id}/payments", sessionPaymentHandler.ListByPatient)
+			r.Get("/{id}/treatment-plans", treatmentPlanHandler.ListByPatient)
+			r.Post("/{id}/treatment-plans", treatmentPlanHandler.Create)
+		})
+
+		// Treatment plans (Appointments module)
+		r.Route("/treatment-plans", func(r chi.Router) {
+			r.Use(moduleMiddleware.RequireModule(models.ModuleAppointments))
+			r.Get("/{id}", treatmentPlanHandler.Get)
+			r.Put("/{id}", treatmentPlanHandler.Update)
+			r.Delete("/{id}", treatmentPlanHandler.Delete)
+			r.Put("/{id}/goals/{goalId}/achieved", treatmentPlanHandler.SetGoalAchieved)
+			r.Get("/{id}/outcomes", treatmentPlanHandler.ListOutcomes)
+		})
+
+		// Waiting list (Appointments module)
+		r.Route("/waiting-list", func(r chi.Router) {
+			r.Use(moduleMiddleware.RequireModule(models.ModuleAppointments))
+			r.Get("/", waitingListHandler.List)
+			r.Post("/", waitingListHandler.Create)
+			r.Get("/{id}", waitingListHandler.Get)
+			r.Put("/{id}", waitingListHandler.Update)
+			r.Delete("/{id}", waitingListHandler.Delete)
 		})
 
 		// Therapists (Appointments module)
@@ -288,26 +624,66 @@ func Setup(services *services.Services, cfg *config.Config) http.Handler {
 			r.Delete("/{id}", therapistHandler.Delete)
 		})
 
+		// Quick-create from typed text (Appointments module)
+		r.Route("/quick", func(r chi.Router) {
+			r.Use(moduleMiddleware.RequireModule(models.ModuleAppointments))
+			r.Post("/sessions", quickCreateHandler.Session)
+		})
+
 		// Sessions (Appointments module)
 		r.Route("/sessions", func(r chi.Router) {
 			r.Use(moduleMiddleware.RequireModule(models.ModuleAppointments))
 			r.Get("/", sessionHandler.List)
 			r.Get("/calendar", sessionHandler.GetCalendar)
 			r.Get("/stats", sessionHandler.GetStats)
+			r.Get("/auto-assign", sessionHandler.SuggestTherapist)
+			r.Post("/check-availability", sessionHandler.CheckAvailability)
 			r.Post("/", sessionHandler.Create)
+			r.Post("/recurring", sessionHandler.CreateRecurring)
+			r.Get("/series/{seriesId}", sessionHandler.GetSeries)
+			r.Put("/series/{seriesId}", sessionHandler.UpdateSeries)
+			r.Post("/series/{seriesId}/cancel", sessionHandler.CancelSeries)
 			r.Get("/{id}", sessionHandler.Get)
 			r.Put("/{id}", sessionHandler.Update)
 			r.Delete("/{id}", sessionHandler.Delete)
 			r.Post("/{id}/confirm", sessionHandler.Confirm)
+			r.Get("/{id}/cancellation-policy", sessionHandler.GetCancellationPolicy)
+			r.Get("/{id}/upcoming-communications", sessionHandler.GetUpcomingCommunications)
 			r.Post("/{id}/cancel", sessionHandler.Cancel)
 			r.Post("/{id}/complete", sessionHandler.Complete)
 			r.Post("/{id}/no-show", sessionHandler.MarkNoShow)
+			r.Post("/{id}/override/approve", sessionHandler.ApproveOverride)
+			r.Post("/{id}/override/reject", sessionHandler.RejectOverride)
+			r.Put("/{id}/project", sessionHandler.LinkProject)
+			r.Get("/{id}/outcome", treatmentPlanHandler.GetSessionOutcome)
+			r.Put("/{id}/outcome", treatmentPlanHandler.RecordSessionOutcome)
 			// Session payments
 			r.Get("/{id}/payment", sessionPaymentHandler.GetSessionPayment)
 			r.Put("/{id}/payment", sessionPaymentHandler.UpdateSessionPayment)
 			r.Post("/{id}/payment/mark-paid", sessionPaymentHandler.MarkAsPaid)
 		})
 
+		// Service catalogue (Appointments module)
+		r.Route("/booking-services", func(r chi.Router) {
+			r.Use(moduleMiddleware.RequireModule(models.ModuleAppointments))
+			r.Get("/", bookingHandler.List)
+			r.Post("/", bookingHandler.Create)
+			r.Get("/embed", bookingHandler.GetEmbedSettings)
+			r.Put("/embed", bookingHandler.UpdateEmbedSettings)
+			r.Get("/{id}", bookingHandler.Get)
+			r.Put("/{id}", bookingHandler.Update)
+			r.Delete("/{id}", bookingHandler.Delete)
+		})
+
+		// Cancellation policy (Appointments module)
+		r.Route("/cancellation-policy-rules", func(r chi.Router) {
+			r.Use(moduleMiddleware.RequireModule(models.ModuleAppointments))
+			r.Get("/", sessionHandler.ListCancellationRules)
+			r.Post("/", sessionHandler.CreateCancellationRule)
+			r.Put("/{id}", sessionHandler.UpdateCancellationRule)
+			r.Delete("/{id}", sessionHandler.DeleteCancellationRule)
+		})
+
 		// Session Payments (Appointments module)
 		r.Route("/session-payments", func(r chi.Router) {
 			r.Use(moduleMiddleware.RequireModule(models.ModuleAppointments))
@@ -315,6 +691,17 @@ func Setup(services *services.Services, cfg *config.Config) http.Handler {
 			r.Get("/stats", sessionPaymentHandler.GetPaymentStats)
 		})
 
+		// Cash register (Appointments module)
+		r.Route("/cash-registers", func(r chi.Router) {
+			r.Use(moduleMiddleware.RequireModule(models.ModuleAppointments))
+			r.Get("/", cashRegisterHandler.List)
+			r.Post("/", cashRegisterHandler.Open)
+			r.Get("/current", cashRegisterHandler.Current)
+			r.Get("/{id}/reconciliation", cashRegisterHandler.Reconciliation)
+			r.Post("/{id}/entries", cashRegisterHandler.RecordTaking)
+			r.Post("/{id}/close", cashRegisterHandler.Close)
+		})
+
 		// ============ Notifications Module ============
 
 		// Notification Configuration (Notifications module)
@@ -323,6 +710,31 @@ func Setup(services *services.Services, cfg *config.Config) http.Handler {
 			r.Get("/", notificationConfigHandler.GetConfig)
 			r.Put("/", notificationConfigHandler.UpdateConfig)
 			r.Post("/test", notificationConfigHandler.TestWhatsApp)
+			r.Post("/test-email", notificationConfigHandler.TestEmail)
+			r.Post("/test-sms", notificationConfigHandler.TestSMS)
+			r.Post("/webhook-token/rotate", notificationConfigHandler.RotateWebhookToken)
+			r.Get("/webhook-refusals", notificationConfigHandler.ListWebhookRefusals)
+			r.Get("/session-window", notificationConfigHandler.GetSessionWindow)
+			r.Put("/do-not-disturb", notificationConfigHandler.SetDoNotDisturb)
+			r.Put("/send-window", notificationConfigHandler.SetSendWindow)
+			r.Get("/reminder-migration", notificationConfigHandler.PlanReminderMigration)
+			r.Post("/reminder-migration", notificationConfigHandler.MigrateReminders)
+			r.Get("/chat-webhooks", chatWebhookHandler.List)
+			r.Post("/chat-webhooks", chatWebhookHandler.Create)
+			r.Put("/chat-webhooks/{id}", chatWebhookHandler.Update)
+			r.Delete("/chat-webhooks/{id}", chatWebhookHandler.Delete)
+			r.Post("/chat-webhooks/{id}/test", chatWebhookHandler.Test)
+		})
+
+		// WhatsApp conversations, one thread per phone number
+		r.Route("/whatsapp/threads", func(r chi.Router) {
+			r.Use(moduleMiddleware.RequireModule(models.ModuleNotifications))
+			r.Get("/", conversationHandler.ListThreads)
+			r.Get("/unread-count", conversationHandler.UnreadCount)
+			r.Get("/media/{id}", conversationHandler.Media)
+			r.Get("/{phone}/messages", conversationHandler.Messages)
+			r.Post("/{phone}/messages", conversationHandler.Reply)
+			r.Post("/{phone}/read", conversationHandler.MarkRead)
 		})
 
 		// Notifications
@@ -340,6 +752,96 @@ func Setup(services *services.Services, cfg *config.Config) http.Handler {
 			r.Get("/financials", reportHandler.Financials)
 			r.Get("/clients", reportHandler.Clients)
 			r.Get("/tasks", reportHandler.Tasks)
+			r.Get("/budget-conversion", reportHandler.BudgetConversion)
+			r.Get("/message-spend", reportHandler.MessageSpend)
+			r.Get("/receivables-aging", reportHandler.ReceivablesAging)
+			r.Get("/appointments", reportHandler.Appointments)
+			r.Get("/sessions-by-status", reportHandler.SessionsByStatus)
+			r.Get("/benchmarks", benchmarkHandler.Report)
+		})
+
+		// Financial periods (monthly close)
+		r.Route("/financial-periods", func(r chi.Router) {
+			r.Get("/", financialPeriodHandler.List)
+			r.Get("/{period}", financialPeriodHandler.Get)
+			r.Post("/{period}/close", financialPeriodHandler.Close)
+			r.Post("/{period}/reopen", financialPeriodHandler.Reopen)
+		})
+
+		// Expenses (project and clinic), created from receipts
+		r.Route("/expenses", func(r chi.Router) {
+			r.Get("/", expenseHandler.List)
+			r.Post("/receipts", expenseHandler.UploadReceipt)
+			r.Get("/{id}", expenseHandler.Get)
+			r.Put("/{id}", expenseHandler.Update)
+			r.Post("/{id}/confirm", expenseHandler.Confirm)
+			r.Delete("/{id}", expenseHandler.Delete)
+		})
+
+		// Invoices and credit notes (Invoices module)
+		r.Route("/invoices", func(r chi.Router) {
+			r.Use(moduleMiddleware.RequireModule(models.ModuleInvoices))
+			r.Get("/", invoiceHandler.List)
+			r.Post("/from-session-payment/{id}", invoiceHandler.CreateFromSessionPayment)
+			r.Post("/from-payment/{id}", invoiceHandler.CreateFromPayment)
+			r.Get("/{id}", invoiceHandler.Get)
+			r.Delete("/{id}", invoiceHandler.Delete)
+			r.Post("/{id}/issue", invoiceHandler.Issue)
+			r.Post("/{id}/mark-paid", invoiceHandler.MarkPaid)
+			r.Post("/{id}/void", invoiceHandler.Void)
+			r.Post("/{id}/credit-note", invoiceHandler.CreditNote)
+			r.Get("/{id}/pdf", invoiceHandler.GeneratePDF)
+			r.Post("/{id}/pdf", invoiceHandler.RegeneratePDF)
+		})
+
+		// Online payment links (Stripe Checkout)
+		r.Route("/payment-links", func(r chi.Router) {
+			r.Get("/config", paymentLinkHandler.GetConfig)
+			r.Put("/config", paymentLinkHandler.UpdateConfig)
+			r.Get("/", paymentLinkHandler.List)
+			r.Post("/", paymentLinkHandler.Create)
+			r.Post("/{id}/cancel", paymentLinkHandler.Cancel)
+		})
+
+		// Multibanco and SEPA payment references (IfThenPay, Easypay)
+		r.Route("/payment-references", func(r chi.Router) {
+			r.Get("/config", paymentReferenceHandler.GetConfig)
+			r.Put("/config", paymentReferenceHandler.UpdateConfig)
+			r.Get("/", paymentReferenceHandler.List)
+			r.Post("/", paymentReferenceHandler.Create)
+			r.Post("/{id}/cancel", paymentReferenceHandler.Cancel)
+		})
+
+		// Bank statements and payment matching
+		r.Route("/bank-statements", func(r chi.Router) {
+			r.Get("/", bankStatementHandler.List)
+			r.Post("/import", bankStatementHandler.Import)
+			r.Post("/sync", bankStatementHandler.Sync)
+			r.Get("/lines", bankStatementHandler.ListLines)
+			r.Get("/lines/{lineId}/suggestions", bankStatementHandler.Suggestions)
+			r.Post("/lines/{lineId}/match", bankStatementHandler.Match)
+			r.Post("/lines/{lineId}/unmatch", bankStatementHandler.Unmatch)
+			r.Post("/lines/{lineId}/ignore", bankStatementHandler.Ignore)
+			r.Get("/{id}", bankStatementHandler.Get)
+			r.Get("/{id}/lines", bankStatementHandler.ListLines)
+			r.Post("/{id}/auto-match", bankStatementHandler.AutoMatch)
+		})
+
+		// Accountant workspace (read-only) and monthly export bundles
+		r.Route("/accountant", func(r chi.Router) {
+			r.Get("/summary", accountantHandler.Summary)
+			r.Get("/ledger", accountantHandler.Ledger)
+			r.Get("/exports", accountantHandler.ListExports)
+			r.Post("/exports", accountantHandler.RequestExport)
+			r.Get("/exports/{id}", accountantHandler.GetExport)
+			r.Get("/exports/{id}/download", accountantHandler.DownloadExport)
+		})
+
+		// Large data exports, built in the background
+		r.Route("/exports", func(r chi.Router) {
+			r.Get("/", dataExportHandler.List)
+			r.Post("/", dataExportHandler.Create)
+			r.Get("/{id}", dataExportHandler.Get)
 		})
 
 		// ============ Workflow Engine ============
@@ -349,19 +851,57 @@ func Setup(services *services.Services, cfg *config.Config) http.Handler {
 			r.Get("/", workflowHandler.ListWorkflows)
 			r.Post("/", workflowHandler.CreateWorkflow)
 			r.Post("/init-defaults", workflowHandler.InitDefaultWorkflows)
+			r.Post("/import", workflowHandler.ImportWorkflow)
+			r.Get("/consistency", workflowHandler.GetStatusConsistency)
+			r.Post("/consistency/check", workflowHandler.CheckStatusConsistency)
+			r.Get("/dead-letters", workflowHandler.ListDeadLetters)
+			r.Get("/dead-letters/{id}", workflowHandler.GetDeadLetter)
+			r.Post("/dead-letters/{id}/requeue", workflowHandler.RequeueDeadLetter)
+			r.Post("/dead-letters/{id}/discard", workflowHandler.DiscardDeadLetter)
 			r.Get("/{id}", workflowHandler.GetWorkflow)
 			r.Put("/{id}", workflowHandler.UpdateWorkflow)
 			r.Delete("/{id}", workflowHandler.DeleteWorkflow)
 			r.Post("/{id}/duplicate", workflowHandler.DuplicateWorkflow)
+			r.Get("/{id}/export", workflowHandler.ExportWorkflow)
+			r.Post("/{id}/simulate", workflowHandler.SimulateWorkflow)
+			r.Get("/{id}/analytics", workflowHandler.GetWorkflowAnalytics)
 			// States
 			r.Post("/{id}/states", workflowHandler.CreateState)
 			r.Put("/{id}/states/{stateId}", workflowHandler.UpdateState)
 			r.Delete("/{id}/states/{stateId}", workflowHandler.DeleteState)
 			r.Put("/{id}/states/reorder", workflowHandler.ReorderStates)
+			r.Get("/{id}/states/{stateId}/follow-ups", workflowHandler.GetFollowUpSequence)
+			r.Put("/{id}/states/{stateId}/follow-ups", workflowHandler.SetFollowUpSequence)
+			// Transitions
+			r.Put("/{id}/transitions/{transitionId}/inputs", workflowHandler.SetTransitionInputs)
 			// Triggers
 			r.Post("/{id}/triggers", workflowHandler.CreateTrigger)
 		})
 
+		// Status remaps for entities left with statuses renamed in the workflow
+		r.Route("/workflow-status-remaps", func(r chi.Router) {
+			r.Get("/", workflowHandler.ListStatusRemaps)
+			r.Post("/", workflowHandler.SaveStatusRemap)
+			r.Delete("/{id}", workflowHandler.DeleteStatusRemap)
+			r.Post("/{id}/apply", workflowHandler.ApplyStatusRemap)
+		})
+
+		// Sandbox copies of the organization for workflow experimentation
+		r.Route("/sandboxes", func(r chi.Router) {
+			r.Get("/", sandboxHandler.List)
+			r.Post("/", sandboxHandler.Create)
+			r.Delete("/{id}", sandboxHandler.Delete)
+			r.Post("/{id}/access", sandboxHandler.Access)
+			r.Post("/{id}/workflows/{workflowId}/promote", sandboxHandler.PromoteWorkflow)
+		})
+
+		// Organization holidays skipped by holiday-aware recurring triggers
+		r.Route("/organization-holidays", func(r chi.Router) {
+			r.Get("/", workflowHandler.ListHolidays)
+			r.Post("/", workflowHandler.CreateHoliday)
+			r.Delete("/{id}", workflowHandler.DeleteHoliday)
+		})
+
 		// Triggers (standalone routes for update/delete)
 		r.Route("/triggers", func(r chi.Router) {
 			r.Put("/{triggerId}", workflowHandler.UpdateTrigger)
@@ -382,11 +922,18 @@ func Setup(services *services.Services, cfg *config.Config) http.Handler {
 			r.Get("/{id}", workflowHandler.GetTemplate)
 			r.Put("/{id}", workflowHandler.UpdateTemplate)
 			r.Delete("/{id}", workflowHandler.DeleteTemplate)
+			r.Post("/{id}/preview", workflowHandler.PreviewTemplate)
 		})
 
 		// Execution Logs & Scheduled Jobs
 		r.Get("/execution-logs", workflowHandler.GetExecutionLogs)
+		r.Post("/execution-logs/replay", workflowHandler.ReplayExecution)
 		r.Get("/scheduled-jobs", workflowHandler.GetScheduledJobs)
+		r.Post("/scheduled-jobs/{jobId}/cancel", workflowHandler.CancelScheduledJob)
+		r.Post("/scheduled-jobs/{jobId}/run", workflowHandler.RunScheduledJob)
+		r.Post("/scheduled-jobs/{jobId}/reschedule", workflowHandler.RescheduleScheduledJob)
+		r.Get("/trigger-runs", workflowHandler.ListTriggerRuns)
+		r.Get("/trigger-runs/{id}", workflowHandler.GetTriggerRun)
 
 		// Testing & Variables
 		r.Get("/variables", workflowHandler.GetAvailableVariables)
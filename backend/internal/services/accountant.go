@@ -0,0 +1,431 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// exportBundleBatch is how many bundles the worker builds per run
+	exportBundleBatch = 5
+	// exportBundleStaleAfter releases bundles left processing by a worker that stopped
+	exportBundleStaleAfter = 30 * time.Minute
+	// exportDownloadExpiry is how long a download link stays valid
+	exportDownloadExpiry = 15 * time.Minute
+)
+
+// AccountantService serves the read-only accountant workspace and builds the monthly export bundles
+type AccountantService struct {
+	db          *database.DB
+	storage     *StorageService
+	email       *EmailService
+	frontendURL string
+}
+
+func NewAccountantService(db *database.DB, storage *StorageService, email *EmailService, frontendURL string) *AccountantService {
+	return &AccountantService{db: db, storage: storage, email: email, frontendURL: frontendURL}
+}
+
+// Summary returns the money collected and spent in a month, and what still needs the
+// accountant's attention: receipts to review and bank credits without a payment
+func (s *AccountantService) Summary(ctx context.Context, orgID uuid.UUID, period time.Time) (*models.AccountantSummary, error) {
+	summary := &models.AccountantSummary{
+		Period:       period.Format("2006-01"),
+		PeriodStatus: models.FinancialPeriodOpen,
+	}
+
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT status FROM financial_periods WHERE organization_id = $1 AND period = $2
+	`, orgID, period).Scan(&summary.PeriodStatus)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get financial period: %w", err)
+	}
+
+	snapshot, err := periodSnapshot(ctx, s.db.Pool, orgID, period)
+	if err != nil {
+		return nil, err
+	}
+	summary.Collected = *snapshot
+
+	start, end := period, period.AddDate(0, 1, 0)
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT
+			COALESCE(SUM(amount) FILTER (WHERE status = 'posted'), 0),
+			COALESCE(SUM(vat_amount) FILTER (WHERE status = 'posted'), 0),
+			COUNT(*) FILTER (WHERE status = 'posted'),
+			COUNT(*) FILTER (WHERE status = 'draft')
+		FROM expenses
+		WHERE organization_id = $1 AND deleted_at IS NULL
+			AND (expense_date >= $2 AND expense_date < $3 OR status = 'draft' AND expense_date IS NULL)
+	`, orgID, start, end).Scan(&summary.ExpensesTotal, &summary.ExpensesVAT, &summary.ExpensesCount, &summary.DraftExpenses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute expenses: %w", err)
+	}
+
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM bank_statement_lines
+		WHERE organization_id = $1 AND status = 'unmatched' AND amount > 0
+			AND booking_date >= $2 AND booking_date < $3
+	`, orgID, start, end).Scan(&summary.UnmatchedCredits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count unmatched bank lines: %w", err)
+	}
+
+	return summary, nil
+}
+
+// Ledger returns a month's payments received and posted expenses, oldest first. An empty
+// kind returns every entry.
+func (s *AccountantService) Ledger(ctx context.Context, orgID uuid.UUID, period time.Time, kind models.AccountantEntryKind) ([]*models.AccountantEntry, error) {
+	start, end := period, period.AddDate(0, 1, 0)
+	entries := []*models.AccountantEntry{}
+
+	type ledgerQuery struct {
+		kind  models.AccountantEntryKind
+		query string
+	}
+	queries := []ledgerQuery{
+		{models.AccountantEntryPayment, `
+			SELECT pay.id, pay.paid_at, p.project_number, c.id, COALESCE(c.name, ''), c.tax_id,
+				pay.amount, NULL::decimal, pay.method
+			FROM payments pay
+			JOIN projects p ON p.id = pay.project_id
+			LEFT JOIN budgets b ON b.id = p.budget_id
+			LEFT JOIN worksheets w ON w.id = b.worksheet_id
+			LEFT JOIN clients c ON c.id = w.client_id
+			WHERE pay.organization_id = $1 AND pay.deleted_at IS NULL AND pay.status = 'paid'
+				AND pay.paid_at >= $2 AND pay.paid_at < $3
+		`},
+		{models.AccountantEntrySessionPayment, `
+			SELECT sp.id, sp.paid_at, to_char(s.scheduled_at, 'YYYY-MM-DD HH24:MI'), c.id, COALESCE(c.name, ''), c.tax_id,
+				sp.amount_cents / 100.0, NULL::decimal, sp.payment_method
+			FROM session_payments sp
+			JOIN sessions s ON s.id = sp.session_id
+			LEFT JOIN patients pt ON pt.id = s.patient_id
+			LEFT JOIN clients c ON c.id = pt.client_id
+			WHERE s.organization_id = $1 AND s.deleted_at IS NULL AND sp.payment_status = 'paid'
+				AND sp.paid_at >= $2 AND sp.paid_at < $3
+		`},
+		{models.AccountantEntryExpense, `
+			SELECT e.id, e.expense_date, COALESCE(e.description, e.category, ''), NULL::uuid, COALESCE(e.vendor, ''), e.vendor_tax_id,
+				e.amount, e.vat_amount, NULL::text
+			FROM expenses e
+			WHERE e.organization_id = $1 AND e.deleted_at IS NULL AND e.status = 'posted'
+				AND e.expense_date >= $2 AND e.expense_date < $3
+		`},
+	}
+
+	for _, q := range queries {
+		if kind != "" && kind != q.kind {
+			continue
+		}
+		rows, err := s.db.Pool.Query(ctx, q.query+` ORDER BY 2, 1`, orgID, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s entries: %w", q.kind, err)
+		}
+		for rows.Next() {
+			e := &models.AccountantEntry{Kind: q.kind}
+			if err := rows.Scan(&e.ID, &e.Date, &e.Reference, &e.PartyID, &e.Party, &e.PartyTaxID,
+				&e.Amount, &e.VATAmount, &e.Method); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan %s entry: %w", q.kind, err)
+			}
+			entries = append(entries, e)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to list %s entries: %w", q.kind, err)
+		}
+	}
+
+	return entries, nil
+}
+
+const exportBundleColumns = `id, organization_id, to_char(period, 'YYYY-MM'), status, send_email, file_url, file_name,
+	file_size, error, requested_by, created_at, started_at, completed_at`
+
+func scanExportBundle(row pgx.Row) (*models.ExportBundle, error) {
+	b := &models.ExportBundle{}
+	err := row.Scan(&b.ID, &b.OrganizationID, &b.Period, &b.Status, &b.SendEmail, &b.FileURL, &b.FileName,
+		&b.FileSize, &b.Error, &b.RequestedBy, &b.CreatedAt, &b.StartedAt, &b.CompletedAt)
+	return b, err
+}
+
+// RequestExport queues the export bundle of a month for the worker
+func (s *AccountantService) RequestExport(ctx context.Context, orgID, userID uuid.UUID, period time.Time, sendEmail bool) (*models.ExportBundle, error) {
+	if period.After(periodStart(time.Now())) {
+		return nil, errors.New("cannot export a future period")
+	}
+
+	var inProgress bool
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM export_bundles
+			WHERE organization_id = $1 AND period = $2 AND status IN ('pending', 'processing')
+		)
+	`, orgID, period).Scan(&inProgress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check export bundles: %w", err)
+	}
+	if inProgress {
+		return nil, errors.New("an export of this period is already in progress")
+	}
+
+	bundle, err := scanExportBundle(s.db.Pool.QueryRow(ctx, `
+		INSERT INTO export_bundles (organization_id, period, send_email, requested_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+exportBundleColumns, orgID, period, sendEmail, userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create export bundle: %w", err)
+	}
+	return bundle, nil
+}
+
+// ListExports returns the organization's export bundles, newest first
+func (s *AccountantService) ListExports(ctx context.Context, orgID uuid.UUID, limit, offset int) ([]*models.ExportBundle, int, error) {
+	var total int
+	err := s.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM export_bundles WHERE organization_id = $1`, orgID).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count export bundles: %w", err)
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT `+exportBundleColumns+` FROM export_bundles
+		WHERE organization_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`, orgID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list export bundles: %w", err)
+	}
+	defer rows.Close()
+
+	bundles := []*models.ExportBundle{}
+	for rows.Next() {
+		b, err := scanExportBundle(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan export bundle: %w", err)
+		}
+		bundles = append(bundles, b)
+	}
+	return bundles, total, rows.Err()
+}
+
+// GetExport returns an export bundle
+func (s *AccountantService) GetExport(ctx context.Context, id, orgID uuid.UUID) (*models.ExportBundle, error) {
+	b, err := scanExportBundle(s.db.Pool.QueryRow(ctx, `
+		SELECT `+exportBundleColumns+` FROM export_bundles WHERE id = $1 AND organization_id = $2
+	`, id, orgID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.New("export bundle not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get export bundle: %w", err)
+	}
+	return b, nil
+}
+
+// ExportDownloadURL returns a short-lived link to a ready bundle
+func (s *AccountantService) ExportDownloadURL(ctx context.Context, id, orgID uuid.UUID) (string, error) {
+	b, err := s.GetExport(ctx, id, orgID)
+	if err != nil {
+		return "", err
+	}
+	if b.Status != models.ExportBundleReady || b.FileURL == nil {
+		return "", errors.New("export bundle is not ready")
+	}
+	url, err := s.storage.DownloadURL(ctx, *b.FileURL, exportDownloadExpiry)
+	if err != nil {
+		return "", fmt.Errorf("failed to create download link: %w", err)
+	}
+	return url, nil
+}
+
+// ProcessPendingExports builds the queued bundles. It is run by the worker; bundles are
+// claimed with SKIP LOCKED so several workers never build the same one.
+func (s *AccountantService) ProcessPendingExports(ctx context.Context) (int, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		UPDATE export_bundles SET status = 'processing', started_at = NOW()
+		WHERE id IN (
+			SELECT id FROM export_bundles
+			WHERE status = 'pending' OR (status = 'processing' AND started_at < $1)
+			ORDER BY created_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+exportBundleColumns, time.Now().Add(-exportBundleStaleAfter), exportBundleBatch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim export bundles: %w", err)
+	}
+	var bundles []*models.ExportBundle
+	for rows.Next() {
+		b, err := scanExportBundle(rows)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan export bundle: %w", err)
+		}
+		bundles = append(bundles, b)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to claim export bundles: %w", err)
+	}
+
+	built := 0
+	for _, b := range bundles {
+		if err := s.processExport(ctx, b); err != nil {
+			log.Printf("[ExportBundles] Bundle %s failed: %v", b.ID, err)
+			if _, dbErr := s.db.Pool.Exec(ctx, `
+				UPDATE export_bundles SET status = 'failed', error = $2, completed_at = NOW() WHERE id = $1
+			`, b.ID, err.Error()); dbErr != nil {
+				log.Printf("[ExportBundles] Failed to record error of bundle %s: %v", b.ID, dbErr)
+			}
+			continue
+		}
+		built++
+	}
+	return built, nil
+}
+
+// processExport builds, uploads and optionally emails a bundle
+func (s *AccountantService) processExport(ctx context.Context, b *models.ExportBundle) error {
+	period, err := ParseFinancialPeriod(b.Period)
+	if err != nil {
+		return err
+	}
+	org, err := s.organization(ctx, b.OrganizationID)
+	if err != nil {
+		return err
+	}
+	entries, err := s.Ledger(ctx, b.OrganizationID, period, "")
+	if err != nil {
+		return err
+	}
+
+	data, err := buildExportBundle(org, period, entries, time.Now())
+	if err != nil {
+		return err
+	}
+	fileName := fmt.Sprintf("contabilidade-%s.zip", b.Period)
+	upload, err := s.storage.UploadData(ctx, data, fileName, "application/zip", b.OrganizationID)
+	if err != nil {
+		return fmt.Errorf("failed to upload bundle: %w", err)
+	}
+
+	_, err = s.db.Pool.Exec(ctx, `
+		UPDATE export_bundles
+		SET status = 'ready', file_url = $2, file_name = $3, file_size = $4, error = NULL, completed_at = NOW()
+		WHERE id = $1
+	`, b.ID, upload.URL, fileName, upload.FileSize)
+	if err != nil {
+		return fmt.Errorf("failed to update export bundle: %w", err)
+	}
+
+	if b.SendEmail {
+		var to, firstName string
+		err := s.db.Pool.QueryRow(ctx, `SELECT email, first_name FROM users WHERE id = $1`, b.RequestedBy).Scan(&to, &firstName)
+		if err != nil {
+			log.Printf("[ExportBundles] Failed to get requester of bundle %s: %v", b.ID, err)
+			return nil
+		}
+		link := fmt.Sprintf("%s/accountant/exports/%s", s.frontendURL, b.ID)
+		if err := s.email.SendExportReady(to, firstName, org.Name, b.Period, link); err != nil {
+			// The bundle is ready either way; it stays listed in the workspace
+			log.Printf("[ExportBundles] Failed to email bundle %s: %v", b.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *AccountantService) organization(ctx context.Context, orgID uuid.UUID) (*models.Organization, error) {
+	org := &models.Organization{ID: orgID}
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT name, email, COALESCE(address, ''), COALESCE(tax_id, '') FROM organizations WHERE id = $1
+	`, orgID).Scan(&org.Name, &org.Email, &org.Address, &org.TaxID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+	return org, nil
+}
+
+// buildExportBundle zips a month's entries as CSV files and a SAF-T (PT) file
+func buildExportBundle(org *models.Organization, period time.Time, entries []*models.AccountantEntry, now time.Time) ([]byte, error) {
+	var received, expenses []*models.AccountantEntry
+	for _, e := range entries {
+		if e.Kind == models.AccountantEntryExpense {
+			expenses = append(expenses, e)
+		} else {
+			received = append(received, e)
+		}
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := []struct {
+		name  string
+		write func(io.Writer) error
+	}{
+		{"pagamentos.csv", func(w io.Writer) error { return writeLedgerCSV(w, received) }},
+		{"despesas.csv", func(w io.Writer) error { return writeLedgerCSV(w, expenses) }},
+		{fmt.Sprintf("SAFT-PT-%s.xml", period.Format("2006-01")), func(w io.Writer) error {
+			return writeSAFT(w, org, period, received, now)
+		}},
+	}
+	for _, f := range files {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: now})
+		if err != nil {
+			return nil, fmt.Errorf("failed to add %s: %w", f.name, err)
+		}
+		if err := f.write(w); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", f.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// writeLedgerCSV writes one row per entry
+func writeLedgerCSV(w io.Writer, entries []*models.AccountantEntry) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{
+		"kind", "id", "date", "reference", "party", "party_tax_id", "amount", "vat_amount", "method",
+	}); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		vat := ""
+		if e.VATAmount != nil {
+			vat = e.VATAmount.StringFixed(2)
+		}
+		err := writer.Write([]string{
+			string(e.Kind), e.ID.String(), e.Date.Format("2006-01-02"), e.Reference, e.Party,
+			derefString(e.PartyTaxID), e.Amount.StringFixed(2), vat, derefString(e.Method),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,452 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// securityLookback is how far back the audit log is analyzed on each run
+	securityLookback = 24 * time.Hour
+	// securityBurstWindow is the time span in which exports or deletions are counted
+	securityBurstWindow = time.Hour
+	// Business hours of the platform team; impersonation outside them, or on weekends, is flagged
+	securityTimezone           = "Europe/Lisbon"
+	securityBusinessHoursStart = 8
+	securityBusinessHoursEnd   = 20
+)
+
+// securityBurstRule flags an admin performing many of the given actions within the burst window
+type securityBurstRule struct {
+	rule      models.SecurityAlertRule
+	actions   []string
+	threshold int
+	severity  string
+	summary   string // shown in the alert emails, formatted with the number of actions
+}
+
+var securityBurstRules = []securityBurstRule{
+	{
+		rule:      models.SecurityAlertMassExport,
+		actions:   []string{string(models.AuditActionExport)},
+		threshold: 5,
+		severity:  "high",
+		summary:   "%d exportações de dados numa hora",
+	},
+	{
+		rule:      models.SecurityAlertBulkDeletion,
+		actions:   []string{string(models.AuditActionDelete), string(models.AuditActionSuspend)},
+		threshold: 5,
+		severity:  "high",
+		summary:   "%d eliminações ou suspensões numa hora",
+	},
+}
+
+// securityAuditEntry is an audit log entry that has not been flagged by a rule yet
+type securityAuditEntry struct {
+	ID        uuid.UUID
+	AdminID   uuid.UUID
+	EntityID  *uuid.UUID
+	CreatedAt time.Time
+}
+
+// AdminSecurityService detects unusual system admin behavior in the admin audit log and
+// tracks the resulting alerts until the flagged admin justifies them
+type AdminSecurityService struct {
+	db          *database.DB
+	email       *EmailService
+	frontendURL string
+}
+
+func NewAdminSecurityService(db *database.DB, email *EmailService, frontendURL string) *AdminSecurityService {
+	return &AdminSecurityService{db: db, email: email, frontendURL: frontendURL}
+}
+
+// AdminSecurityAlertFilters filters the security alert list
+type AdminSecurityAlertFilters struct {
+	AdminID *uuid.UUID
+	Status  string
+	Rule    string
+	Page    int
+	Limit   int
+}
+
+// Analyze runs every rule over the recent audit log and raises an alert for each finding.
+// Entries already flagged by a rule are skipped, so runs can overlap. Returns the number of
+// alerts raised.
+func (s *AdminSecurityService) Analyze(ctx context.Context) (int, error) {
+	now := time.Now()
+	since := now.Add(-securityLookback)
+	raised := 0
+
+	for _, rule := range securityBurstRules {
+		entries, err := s.unflaggedEntries(ctx, rule.rule, rule.actions, since)
+		if err != nil {
+			return raised, err
+		}
+
+		for _, burst := range findSecurityBursts(entries, securityBurstWindow, rule.threshold) {
+			details := map[string]interface{}{
+				"count":   len(burst),
+				"summary": fmt.Sprintf(rule.summary, len(burst)),
+			}
+			if err := s.raise(ctx, rule.rule, rule.severity, burst, details); err != nil {
+				return raised, err
+			}
+			raised++
+		}
+	}
+
+	loc, err := time.LoadLocation(securityTimezone)
+	if err != nil {
+		return raised, fmt.Errorf("failed to load timezone: %w", err)
+	}
+
+	entries, err := s.unflaggedEntries(ctx, models.SecurityAlertOffHoursImpersonation,
+		[]string{string(models.AuditActionUserImpersonated)}, since)
+	if err != nil {
+		return raised, err
+	}
+	for _, entry := range entries {
+		if !isOffHours(entry.CreatedAt, loc) {
+			continue
+		}
+		details := map[string]interface{}{
+			"summary":    "Personificação de utilizador a " + entry.CreatedAt.In(loc).Format("2006-01-02 15:04") + ", fora do horário de expediente",
+			"started_at": entry.CreatedAt,
+		}
+		if entry.EntityID != nil {
+			details["impersonated_user_id"] = *entry.EntityID
+		}
+		if err := s.raise(ctx, models.SecurityAlertOffHoursImpersonation, "medium", []securityAuditEntry{entry}, details); err != nil {
+			return raised, err
+		}
+		raised++
+	}
+
+	return raised, nil
+}
+
+// unflaggedEntries returns the audit log entries with the given actions since a time that no
+// alert of the rule covers, ordered by admin and time
+func (s *AdminSecurityService) unflaggedEntries(ctx context.Context, rule models.SecurityAlertRule, actions []string, since time.Time) ([]securityAuditEntry, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT l.id, l.admin_id, l.entity_id, l.created_at
+		FROM system_admin_audit_logs l
+		WHERE l.action = ANY($1) AND l.created_at >= $2
+			AND NOT EXISTS (
+				SELECT 1 FROM admin_security_alerts a
+				WHERE a.rule = $3 AND l.id = ANY(a.audit_log_ids)
+			)
+		ORDER BY l.admin_id, l.created_at
+	`, actions, since, string(rule))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []securityAuditEntry
+	for rows.Next() {
+		var e securityAuditEntry
+		if err := rows.Scan(&e.ID, &e.AdminID, &e.EntityID, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit log entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// findSecurityBursts groups entries, ordered by admin and time, into runs of at least threshold
+// entries of one admin falling within the window. A run keeps growing while its entries stay
+// within the window of its first one; entries outside any run are left for later analysis.
+func findSecurityBursts(entries []securityAuditEntry, window time.Duration, threshold int) [][]securityAuditEntry {
+	var bursts [][]securityAuditEntry
+	i := 0
+	for i < len(entries) {
+		j := i
+		for j+1 < len(entries) && entries[j+1].AdminID == entries[i].AdminID &&
+			entries[j+1].CreatedAt.Sub(entries[i].CreatedAt) <= window {
+			j++
+		}
+		if j-i+1 >= threshold {
+			bursts = append(bursts, entries[i:j+1])
+			i = j + 1
+			continue
+		}
+		i++
+	}
+	return bursts
+}
+
+// isOffHours reports whether a time falls outside business hours in the given location
+func isOffHours(t time.Time, loc *time.Location) bool {
+	local := t.In(loc)
+	if local.Weekday() == time.Saturday || local.Weekday() == time.Sunday {
+		return true
+	}
+	return local.Hour() < securityBusinessHoursStart || local.Hour() >= securityBusinessHoursEnd
+}
+
+// raise records an alert for the flagged entries of one admin and notifies the admins
+func (s *AdminSecurityService) raise(ctx context.Context, rule models.SecurityAlertRule, severity string, entries []securityAuditEntry, details map[string]interface{}) error {
+	ids := make([]uuid.UUID, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ID
+	}
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		detailsJSON = []byte("{}")
+	}
+
+	alert := &models.AdminSecurityAlert{
+		ID:          uuid.New(),
+		AdminID:     entries[0].AdminID,
+		Rule:        rule,
+		Severity:    severity,
+		Details:     details,
+		AuditLogIDs: ids,
+		WindowStart: entries[0].CreatedAt,
+		WindowEnd:   entries[len(entries)-1].CreatedAt,
+		Status:      models.SecurityAlertStatusOpen,
+	}
+
+	err = s.db.Pool.QueryRow(ctx, `
+		INSERT INTO admin_security_alerts (id, admin_id, rule, severity, details, audit_log_ids, window_start, window_end, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING created_at
+	`, alert.ID, alert.AdminID, string(alert.Rule), alert.Severity, detailsJSON, alert.AuditLogIDs,
+		alert.WindowStart, alert.WindowEnd, string(alert.Status)).Scan(&alert.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create security alert: %w", err)
+	}
+
+	log.Printf("[AdminSecurity] Alert %s raised for admin %s: %s", alert.ID, alert.AdminID, rule)
+	s.notify(ctx, alert)
+	return nil
+}
+
+// notify emails the other active system admins about an alert, and asks the flagged admin
+// for a justification. Failures are logged; the alert stays listed in the admin panel.
+func (s *AdminSecurityService) notify(ctx context.Context, alert *models.AdminSecurityAlert) {
+	if s.email == nil {
+		return
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, email, first_name, last_name
+		FROM system_admins
+		WHERE is_active = true AND deleted_at IS NULL
+	`)
+	if err != nil {
+		log.Printf("[AdminSecurity] Failed to load admins to notify of alert %s: %v", alert.ID, err)
+		return
+	}
+	var admins []models.SystemAdmin
+	for rows.Next() {
+		var a models.SystemAdmin
+		if err := rows.Scan(&a.ID, &a.Email, &a.FirstName, &a.LastName); err != nil {
+			rows.Close()
+			log.Printf("[AdminSecurity] Failed to load admins to notify of alert %s: %v", alert.ID, err)
+			return
+		}
+		admins = append(admins, a)
+	}
+	rows.Close()
+
+	var flagged *models.SystemAdmin
+	for i := range admins {
+		if admins[i].ID == alert.AdminID {
+			flagged = &admins[i]
+		}
+	}
+	flaggedName := alert.AdminID.String()
+	if flagged != nil {
+		flaggedName = strings.TrimSpace(flagged.FullName())
+	}
+
+	summary, _ := alert.Details["summary"].(string)
+	link := s.frontendURL + "/admin/security-alerts/" + alert.ID.String()
+
+	for _, a := range admins {
+		if a.ID == alert.AdminID {
+			if err := s.email.SendSecurityJustificationRequest(a.Email, a.FirstName, summary, link); err != nil {
+				log.Printf("[AdminSecurity] Failed to request justification of alert %s: %v", alert.ID, err)
+			}
+			continue
+		}
+		if err := s.email.SendSecurityAlert(a.Email, a.FirstName, flaggedName, summary, link); err != nil {
+			log.Printf("[AdminSecurity] Failed to send alert %s to %s: %v", alert.ID, a.Email, err)
+		}
+	}
+}
+
+const adminSecurityAlertColumns = `
+	a.id, a.admin_id, a.rule, a.severity, a.details, a.audit_log_ids, a.window_start, a.window_end,
+	a.status, a.justification, a.justified_at, a.reviewed_by, a.reviewed_at, a.created_at,
+	COALESCE(sa.first_name || ' ' || sa.last_name, ''), COALESCE(sa.email, '')`
+
+func scanAdminSecurityAlert(row pgx.Row) (*models.AdminSecurityAlert, error) {
+	var alert models.AdminSecurityAlert
+	var rule, status string
+	var detailsJSON []byte
+	err := row.Scan(
+		&alert.ID, &alert.AdminID, &rule, &alert.Severity, &detailsJSON, &alert.AuditLogIDs,
+		&alert.WindowStart, &alert.WindowEnd, &status, &alert.Justification, &alert.JustifiedAt,
+		&alert.ReviewedBy, &alert.ReviewedAt, &alert.CreatedAt, &alert.AdminName, &alert.AdminEmail,
+	)
+	if err != nil {
+		return nil, err
+	}
+	alert.Rule = models.SecurityAlertRule(rule)
+	alert.Status = models.SecurityAlertStatus(status)
+	if err := json.Unmarshal(detailsJSON, &alert.Details); err != nil {
+		alert.Details = make(map[string]interface{})
+	}
+	return &alert, nil
+}
+
+// List returns security alerts, newest first
+func (s *AdminSecurityService) List(ctx context.Context, filters AdminSecurityAlertFilters) ([]models.AdminSecurityAlert, int, error) {
+	if filters.Page < 1 {
+		filters.Page = 1
+	}
+	if filters.Limit < 1 || filters.Limit > 100 {
+		filters.Limit = 50
+	}
+	offset := (filters.Page - 1) * filters.Limit
+
+	where := `WHERE ($1::uuid IS NULL OR a.admin_id = $1)
+		AND ($2 = '' OR a.status = $2)
+		AND ($3 = '' OR a.rule = $3)`
+	args := []interface{}{filters.AdminID, filters.Status, filters.Rule}
+
+	var total int
+	if err := s.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM admin_security_alerts a `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count security alerts: %w", err)
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT `+adminSecurityAlertColumns+`
+		FROM admin_security_alerts a
+		LEFT JOIN system_admins sa ON sa.id = a.admin_id
+		`+where+`
+		ORDER BY a.created_at DESC
+		LIMIT $4 OFFSET $5
+	`, append(args, filters.Limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list security alerts: %w", err)
+	}
+	defer rows.Close()
+
+	alerts := []models.AdminSecurityAlert{}
+	for rows.Next() {
+		alert, err := scanAdminSecurityAlert(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan security alert: %w", err)
+		}
+		alerts = append(alerts, *alert)
+	}
+	return alerts, total, rows.Err()
+}
+
+// GetByID returns a security alert
+func (s *AdminSecurityService) GetByID(ctx context.Context, id uuid.UUID) (*models.AdminSecurityAlert, error) {
+	alert, err := scanAdminSecurityAlert(s.db.Pool.QueryRow(ctx, `
+		SELECT `+adminSecurityAlertColumns+`
+		FROM admin_security_alerts a
+		LEFT JOIN system_admins sa ON sa.id = a.admin_id
+		WHERE a.id = $1
+	`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("security alert not found")
+		}
+		return nil, fmt.Errorf("failed to get security alert: %w", err)
+	}
+	return alert, nil
+}
+
+// CountOpen returns how many alerts an admin has yet to justify
+func (s *AdminSecurityService) CountOpen(ctx context.Context, adminID uuid.UUID) (int, error) {
+	var count int
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM admin_security_alerts WHERE admin_id = $1 AND status = 'open'
+	`, adminID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count open security alerts: %w", err)
+	}
+	return count, nil
+}
+
+// Justify records the flagged admin's note explaining the activity of an open alert
+func (s *AdminSecurityService) Justify(ctx context.Context, id, adminID uuid.UUID, note string) (*models.AdminSecurityAlert, error) {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return nil, errors.New("justification is required")
+	}
+
+	alert, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if alert.AdminID != adminID {
+		return nil, errors.New("only the flagged admin can justify this alert")
+	}
+	if alert.Status != models.SecurityAlertStatusOpen {
+		return nil, errors.New("security alert is not open")
+	}
+
+	tag, err := s.db.Pool.Exec(ctx, `
+		UPDATE admin_security_alerts
+		SET status = 'justified', justification = $2, justified_at = NOW()
+		WHERE id = $1 AND status = 'open'
+	`, id, note)
+	if err != nil {
+		return nil, fmt.Errorf("failed to justify security alert: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, errors.New("security alert is not open")
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+// Review marks an alert as reviewed by another admin. Dismissing closes an open alert without
+// a justification, e.g. when the activity was agreed beforehand.
+func (s *AdminSecurityService) Review(ctx context.Context, id, reviewerID uuid.UUID, dismiss bool) (*models.AdminSecurityAlert, error) {
+	alert, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if alert.AdminID == reviewerID {
+		return nil, errors.New("admins cannot review their own alerts")
+	}
+	if dismiss && alert.Status != models.SecurityAlertStatusOpen {
+		return nil, errors.New("security alert is not open")
+	}
+
+	status := string(alert.Status)
+	if dismiss {
+		status = string(models.SecurityAlertStatusDismissed)
+	}
+	_, err = s.db.Pool.Exec(ctx, `
+		UPDATE admin_security_alerts
+		SET status = $2, reviewed_by = $3, reviewed_at = NOW()
+		WHERE id = $1
+	`, id, status, reviewerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to review security alert: %w", err)
+	}
+
+	return s.GetByID(ctx, id)
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestFindSecurityBursts(t *testing.T) {
+	adminA, adminB := uuid.New(), uuid.New()
+	start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	entry := func(admin uuid.UUID, minutes int) securityAuditEntry {
+		return securityAuditEntry{ID: uuid.New(), AdminID: admin, CreatedAt: start.Add(time.Duration(minutes) * time.Minute)}
+	}
+
+	tests := []struct {
+		name    string
+		entries []securityAuditEntry
+		want    []int // sizes of the bursts found
+	}{
+		{
+			name:    "below threshold",
+			entries: []securityAuditEntry{entry(adminA, 0), entry(adminA, 5)},
+			want:    nil,
+		},
+		{
+			name:    "burst within the window",
+			entries: []securityAuditEntry{entry(adminA, 0), entry(adminA, 10), entry(adminA, 20), entry(adminA, 120)},
+			want:    []int{3},
+		},
+		{
+			name:    "spread over more than the window",
+			entries: []securityAuditEntry{entry(adminA, 0), entry(adminA, 50), entry(adminA, 100), entry(adminA, 150)},
+			want:    nil,
+		},
+		{
+			name:    "burst after a lone entry",
+			entries: []securityAuditEntry{entry(adminA, 0), entry(adminA, 70), entry(adminA, 80), entry(adminA, 90)},
+			want:    []int{3},
+		},
+		{
+			name:    "entries of different admins are not combined",
+			entries: []securityAuditEntry{entry(adminA, 0), entry(adminA, 1), entry(adminB, 2), entry(adminB, 3)},
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bursts := findSecurityBursts(tt.entries, time.Hour, 3)
+			if len(bursts) != len(tt.want) {
+				t.Fatalf("findSecurityBursts() found %d bursts, want %d", len(bursts), len(tt.want))
+			}
+			for i, burst := range bursts {
+				if len(burst) != tt.want[i] {
+					t.Errorf("burst %d has %d entries, want %d", i, len(burst), tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIsOffHours(t *testing.T) {
+	lisbon, err := time.LoadLocation("Europe/Lisbon")
+	if err != nil {
+		t.Skip("Europe/Lisbon timezone not available")
+	}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want bool
+	}{
+		{"weekday afternoon", time.Date(2026, 3, 4, 15, 0, 0, 0, lisbon), false},
+		{"weekday at opening", time.Date(2026, 3, 4, 8, 0, 0, 0, lisbon), false},
+		{"weekday early morning", time.Date(2026, 3, 4, 6, 30, 0, 0, lisbon), true},
+		{"weekday at closing", time.Date(2026, 3, 4, 20, 0, 0, 0, lisbon), true},
+		{"saturday afternoon", time.Date(2026, 3, 7, 15, 0, 0, 0, lisbon), true},
+		{"summer time in UTC", time.Date(2026, 7, 1, 7, 30, 0, 0, time.UTC), false}, // 07:30 UTC is 08:30 in Lisbon
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isOffHours(tt.at, lisbon); got != tt.want {
+				t.Errorf("isOffHours(%v) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
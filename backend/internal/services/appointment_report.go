@@ -0,0 +1,277 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// lateCancellationHours is the notice below which a cancellation counts as late
+const lateCancellationHours = 24
+
+// AppointmentReportFilters limits the sessions included in the appointment report
+type AppointmentReportFilters struct {
+	From        time.Time // sessions scheduled on or after
+	To          time.Time // sessions scheduled before
+	TherapistID *uuid.UUID
+}
+
+// AttendanceStats aggregates the sessions of a therapist, a patient or the whole organization
+type AttendanceStats struct {
+	ID                       *uuid.UUID `json:"id,omitempty"`
+	Name                     string     `json:"name,omitempty"`
+	Sessions                 int        `json:"sessions"`
+	Completed                int        `json:"completed"`
+	NoShows                  int        `json:"no_shows"`
+	Cancelled                int        `json:"cancelled"`
+	NoShowRate               *float64   `json:"no_show_rate"` // no-shows / (completed + no-shows)
+	LateCancellations        int        `json:"late_cancellations"`
+	AvgCancellationLeadHours *float64   `json:"avg_cancellation_lead_hours"`
+	Messaged                 int        `json:"messaged"` // sessions sent a WhatsApp message
+	Replied                  int        `json:"replied"`  // of those, sessions the patient replied to
+	ConfirmationResponseRate *float64   `json:"confirmation_response_rate"`
+}
+
+// TherapistAttendanceStats adds utilization to a therapist's attendance
+type TherapistAttendanceStats struct {
+	AttendanceStats
+	BookedHours    float64  `json:"booked_hours"`    // sessions that were not cancelled
+	AvailableHours float64  `json:"available_hours"` // working hours in the period
+	Utilization    *float64 `json:"utilization"`
+}
+
+// AppointmentReport is the no-show and attendance report for a period
+type AppointmentReport struct {
+	From       time.Time                   `json:"from"`
+	To         time.Time                   `json:"to"`
+	Totals     AttendanceStats             `json:"totals"`
+	Therapists []*TherapistAttendanceStats `json:"therapists"`
+	Patients   []*AttendanceStats          `json:"patients"`
+}
+
+// Appointments reports no-show rates, cancellation lead times, WhatsApp confirmation response
+// rates and therapist utilization for the sessions scheduled in the period
+func (s *ReportService) Appointments(ctx context.Context, orgID uuid.UUID, filters AppointmentReportFilters) (*AppointmentReport, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		WITH period AS (
+			SELECT s.therapist_id, t.name AS therapist_name, s.patient_id, c.name AS patient_name,
+			       s.status, s.duration_minutes, s.scheduled_at, s.cancelled_at,
+			       EXISTS (SELECT 1 FROM whatsapp_messages m WHERE m.session_id = s.id AND m.direction = 'outbound') AS messaged,
+			       EXISTS (SELECT 1 FROM whatsapp_messages m WHERE m.session_id = s.id AND m.direction = 'inbound') AS replied
+			FROM sessions s
+			JOIN therapists t ON t.id = s.therapist_id
+			JOIN patients p ON p.id = s.patient_id
+			LEFT JOIN clients c ON c.id = p.client_id
+			WHERE s.organization_id = $1 AND s.deleted_at IS NULL
+			AND s.scheduled_at >= $2 AND s.scheduled_at < $3
+			AND ($4::uuid IS NULL OR s.therapist_id = $4)
+		)
+		SELECT GROUPING(therapist_id), GROUPING(patient_id),
+		       therapist_id, MAX(therapist_name), patient_id, MAX(patient_name),
+		       COUNT(*),
+		       COUNT(*) FILTER (WHERE status = 'completed'),
+		       COUNT(*) FILTER (WHERE status = 'no_show'),
+		       COUNT(*) FILTER (WHERE status = 'cancelled'),
+		       COUNT(*) FILTER (WHERE status = 'cancelled' AND cancelled_at IS NOT NULL
+		                        AND scheduled_at - cancelled_at < make_interval(hours => $5)),
+		       (AVG(EXTRACT(EPOCH FROM scheduled_at - cancelled_at) / 3600)
+		            FILTER (WHERE status = 'cancelled' AND cancelled_at IS NOT NULL))::float8,
+		       COUNT(*) FILTER (WHERE messaged),
+		       COUNT(*) FILTER (WHERE messaged AND replied),
+		       COALESCE(SUM(duration_minutes) FILTER (WHERE status != 'cancelled'), 0)
+		FROM period
+		GROUP BY GROUPING SETS ((therapist_id), (patient_id), ())
+	`, orgID, filters.From, filters.To, filters.TherapistID, lateCancellationHours)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query appointment report: %w", err)
+	}
+	defer rows.Close()
+
+	report := &AppointmentReport{
+		From:       filters.From,
+		To:         filters.To,
+		Therapists: []*TherapistAttendanceStats{},
+		Patients:   []*AttendanceStats{},
+	}
+	bookedMinutes := map[uuid.UUID]int{}
+
+	for rows.Next() {
+		var byTherapist, byPatient int
+		var therapistID, patientID *uuid.UUID
+		var therapistName, patientName *string
+		var stats AttendanceStats
+		var minutes int
+		if err := rows.Scan(
+			&byTherapist, &byPatient, &therapistID, &therapistName, &patientID, &patientName,
+			&stats.Sessions, &stats.Completed, &stats.NoShows, &stats.Cancelled,
+			&stats.LateCancellations, &stats.AvgCancellationLeadHours, &stats.Messaged, &stats.Replied, &minutes,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan appointment report: %w", err)
+		}
+		stats.computeRates()
+
+		// GROUPING() is 0 for the column a row is grouped by
+		switch {
+		case byTherapist == 0 && therapistID != nil:
+			stats.ID, stats.Name = therapistID, derefString(therapistName)
+			report.Therapists = append(report.Therapists, &TherapistAttendanceStats{AttendanceStats: stats})
+			bookedMinutes[*therapistID] = minutes
+		case byPatient == 0 && patientID != nil:
+			stats.ID, stats.Name = patientID, derefString(patientName)
+			report.Patients = append(report.Patients, &stats)
+		default:
+			report.Totals = stats
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read appointment report: %w", err)
+	}
+
+	if err := s.addUtilization(ctx, orgID, filters, report, bookedMinutes); err != nil {
+		return nil, err
+	}
+
+	// Worst attendance first
+	sort.SliceStable(report.Patients, func(i, j int) bool {
+		return report.Patients[i].NoShows > report.Patients[j].NoShows
+	})
+
+	return report, nil
+}
+
+// addUtilization sets booked and available hours on each therapist. Active therapists without
+// sessions in the period are listed too.
+func (s *ReportService) addUtilization(ctx context.Context, orgID uuid.UUID, filters AppointmentReportFilters, report *AppointmentReport, bookedMinutes map[uuid.UUID]int) error {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, name, working_hours, timezone, is_active
+		FROM therapists
+		WHERE organization_id = $1 AND deleted_at IS NULL
+		AND ($2::uuid IS NULL OR id = $2)
+		ORDER BY name
+	`, orgID, filters.TherapistID)
+	if err != nil {
+		return fmt.Errorf("failed to query therapists: %w", err)
+	}
+	defer rows.Close()
+
+	byID := map[uuid.UUID]*TherapistAttendanceStats{}
+	for _, t := range report.Therapists {
+		byID[*t.ID] = t
+	}
+
+	for rows.Next() {
+		var t models.Therapist
+		if err := rows.Scan(&t.ID, &t.Name, &t.WorkingHours, &t.Timezone, &t.IsActive); err != nil {
+			return fmt.Errorf("failed to scan therapist: %w", err)
+		}
+		stats, ok := byID[t.ID]
+		if !ok {
+			if !t.IsActive {
+				continue
+			}
+			id := t.ID
+			stats = &TherapistAttendanceStats{AttendanceStats: AttendanceStats{ID: &id, Name: t.Name}}
+			report.Therapists = append(report.Therapists, stats)
+		}
+
+		stats.BookedHours = float64(bookedMinutes[t.ID]) / 60
+		stats.AvailableHours = workingHoursBetween(&t, filters.From, filters.To)
+		if stats.AvailableHours > 0 {
+			utilization := stats.BookedHours / stats.AvailableHours
+			stats.Utilization = &utilization
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read therapists: %w", err)
+	}
+
+	sort.SliceStable(report.Therapists, func(i, j int) bool {
+		return report.Therapists[i].Name < report.Therapists[j].Name
+	})
+	return nil
+}
+
+// workingHoursBetween adds up the therapist's working hours on the days between from and to
+func workingHoursBetween(t *models.Therapist, from, to time.Time) float64 {
+	loc := therapistLocation(t)
+	var total time.Duration
+	for day := from.In(loc); day.Before(to); day = day.AddDate(0, 0, 1) {
+		start, end, err := workingWindow(t, day)
+		if err != nil {
+			return 0
+		}
+		if !start.IsZero() && end.After(start) {
+			total += end.Sub(start)
+		}
+	}
+	return total.Hours()
+}
+
+// computeRates derives the no-show and confirmation response rates from the counts
+func (a *AttendanceStats) computeRates() {
+	if attended := a.Completed + a.NoShows; attended > 0 {
+		rate := float64(a.NoShows) / float64(attended)
+		a.NoShowRate = &rate
+	}
+	if a.Messaged > 0 {
+		rate := float64(a.Replied) / float64(a.Messaged)
+		a.ConfirmationResponseRate = &rate
+	}
+}
+
+// WriteCSV writes the report with one row per therapist and per patient
+func (r *AppointmentReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{
+		"group", "name", "sessions", "completed", "no_shows", "cancelled", "no_show_rate",
+		"late_cancellations", "avg_cancellation_lead_hours", "messaged", "replied", "confirmation_response_rate",
+		"booked_hours", "available_hours", "utilization",
+	}); err != nil {
+		return err
+	}
+
+	row := func(group string, a *AttendanceStats, extra ...string) []string {
+		return append([]string{
+			group, a.Name, strconv.Itoa(a.Sessions), strconv.Itoa(a.Completed), strconv.Itoa(a.NoShows),
+			strconv.Itoa(a.Cancelled), formatRate(a.NoShowRate), strconv.Itoa(a.LateCancellations),
+			formatRate(a.AvgCancellationLeadHours), strconv.Itoa(a.Messaged), strconv.Itoa(a.Replied),
+			formatRate(a.ConfirmationResponseRate),
+		}, extra...)
+	}
+
+	for _, t := range r.Therapists {
+		if err := writer.Write(row("therapist", &t.AttendanceStats,
+			strconv.FormatFloat(t.BookedHours, 'f', 2, 64), strconv.FormatFloat(t.AvailableHours, 'f', 2, 64),
+			formatRate(t.Utilization))); err != nil {
+			return err
+		}
+	}
+	for _, p := range r.Patients {
+		if err := writer.Write(row("patient", p, "", "", "")); err != nil {
+			return err
+		}
+	}
+	total := r.Totals
+	total.Name = "total"
+	if err := writer.Write(row("total", &total, "", "", "")); err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// formatRate formats an optional figure with 4 decimal places, empty when missing
+func formatRate(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'f', 4, 64)
+}
@@ -0,0 +1,345 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// taskPriorityWeight is how much an open task of each priority adds to a person's load
+var taskPriorityWeight = map[models.Priority]int{
+	models.PriorityLow:    1,
+	models.PriorityMedium: 2,
+	models.PriorityHigh:   3,
+	models.PriorityUrgent: 4,
+}
+
+// ============ Session Auto-Assignment ============
+
+// SessionAssignmentRequest describes the slot to find a therapist for
+type SessionAssignmentRequest struct {
+	ScheduledAt     time.Time
+	DurationMinutes int        // 0 uses the service duration, or each therapist's default session duration
+	Specialty       string     // optional, matched against the therapist's specialty
+	ServiceID       *uuid.UUID // optional, only therapists providing the service are eligible
+}
+
+// SuggestTherapist picks the least-loaded active therapist who works at the requested time,
+// matches the requested specialty, is not away and has no conflicting session.
+// Load is the number of minutes already booked in the week of the session.
+func (s *SessionService) SuggestTherapist(ctx context.Context, orgID uuid.UUID, req SessionAssignmentRequest) (*models.AssignmentDecision, error) {
+	if req.ScheduledAt.IsZero() {
+		return nil, errors.New("scheduled time is required")
+	}
+
+	var service *models.BookableService
+	if req.ServiceID != nil {
+		bs, err := getBookableService(ctx, s.db, *req.ServiceID, orgID)
+		if err != nil {
+			return nil, err
+		}
+		service = bs
+		if req.DurationMinutes <= 0 {
+			req.DurationMinutes = bs.DurationMinutes
+		}
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT
+			t.id, t.user_id, t.name, t.specialty, t.working_hours, t.session_duration_minutes, t.timezone,
+			COALESCE((
+				SELECT SUM(se.duration_minutes)
+				FROM sessions se
+				WHERE se.therapist_id = t.id AND se.deleted_at IS NULL AND se.status != 'cancelled'
+				AND se.scheduled_at >= date_trunc('week', $2::timestamptz)
+				AND se.scheduled_at < date_trunc('week', $2::timestamptz) + interval '1 week'
+			), 0),
+			EXISTS(
+				SELECT 1 FROM user_out_of_office o
+				WHERE o.organization_id = t.organization_id AND o.user_id = t.user_id AND o.is_active = true
+				AND o.starts_at <= $2 AND o.ends_at > $2
+			)
+		FROM therapists t
+		WHERE t.organization_id = $1 AND t.is_active = true AND t.deleted_at IS NULL
+		ORDER BY t.name
+	`, orgID, req.ScheduledAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query therapists: %w", err)
+	}
+
+	type therapistRow struct {
+		therapist models.Therapist
+		load      int
+		away      bool
+	}
+	var therapists []therapistRow
+	for rows.Next() {
+		var tr therapistRow
+		t := &tr.therapist
+		if err := rows.Scan(
+			&t.ID, &t.UserID, &t.Name, &t.Specialty, &t.WorkingHours, &t.SessionDurationMinutes, &t.Timezone,
+			&tr.load, &tr.away,
+		); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan therapist: %w", err)
+		}
+		therapists = append(therapists, tr)
+	}
+	rows.Close()
+
+	decision := &models.AssignmentDecision{
+		EntityType: "session",
+		Strategy:   models.AssignmentStrategyLeastLoaded,
+		Candidates: []models.AssignmentCandidate{},
+	}
+
+	for _, tr := range therapists {
+		t := tr.therapist
+		candidate := models.AssignmentCandidate{
+			ID:       t.ID,
+			Name:     t.Name,
+			Eligible: true,
+			Load:     tr.load,
+		}
+		reject := func(reason string) {
+			candidate.Eligible = false
+			candidate.Reasons = append(candidate.Reasons, reason)
+		}
+
+		duration := req.DurationMinutes
+		if duration <= 0 {
+			duration = t.SessionDurationMinutes
+		}
+
+		if service != nil && !allowsTherapist(service, t.ID) {
+			reject("does not provide this service")
+		}
+		if req.Specialty != "" && (t.Specialty == nil || !strings.Contains(strings.ToLower(*t.Specialty), strings.ToLower(req.Specialty))) {
+			reject(fmt.Sprintf("specialty does not match %q", req.Specialty))
+		}
+		if ok, reason := worksAt(&t, req.ScheduledAt, duration); !ok {
+			reject(reason)
+		}
+		if tr.away {
+			reject("out of office")
+		}
+		end := req.ScheduledAt.Add(time.Duration(duration) * time.Minute)
+		conflict, err := s.hasConflict(ctx, orgID, t.ID, req.ScheduledAt, end, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check conflicts: %w", err)
+		}
+		if conflict {
+			reject("already has a session at this time")
+		}
+
+		candidate.Reasons = append(candidate.Reasons, fmt.Sprintf("%d minutes booked this week", tr.load))
+		decision.Candidates = append(decision.Candidates, candidate)
+	}
+
+	selectLeastLoaded(decision)
+	return decision, nil
+}
+
+// worksAt reports whether a session fits in the therapist's working hours, in the therapist's timezone
+func worksAt(t *models.Therapist, start time.Time, durationMinutes int) (bool, string) {
+	local := start.In(therapistLocation(t))
+	day := strings.ToLower(local.Weekday().String())
+
+	dayStart, dayEnd, err := workingWindow(t, local)
+	if err != nil {
+		return false, err.Error()
+	}
+	if dayStart.IsZero() {
+		return false, fmt.Sprintf("does not work on %s", day)
+	}
+
+	end := start.Add(time.Duration(durationMinutes) * time.Minute)
+	if start.Before(dayStart) || end.After(dayEnd) {
+		return false, fmt.Sprintf("outside working hours (%s-%s)", dayStart.Format("15:04"), dayEnd.Format("15:04"))
+	}
+
+	return true, ""
+}
+
+// therapistLocation returns the therapist's timezone, falling back to UTC
+func therapistLocation(t *models.Therapist) *time.Location {
+	loc, err := time.LoadLocation(t.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// workingWindow returns the working hours of the therapist on the day of the given local time.
+// Both times are zero when the therapist does not work that day.
+func workingWindow(t *models.Therapist, day time.Time) (time.Time, time.Time, error) {
+	hours, err := t.GetWorkingHours()
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("invalid working hours")
+	}
+
+	wh, ok := hours[strings.ToLower(day.Weekday().String())]
+	if !ok || wh.Start == "" || wh.End == "" {
+		return time.Time{}, time.Time{}, nil
+	}
+
+	startClock, err1 := time.Parse("15:04", wh.Start)
+	endClock, err2 := time.Parse("15:04", wh.End)
+	if err1 != nil || err2 != nil {
+		return time.Time{}, time.Time{}, errors.New("invalid working hours")
+	}
+
+	y, m, d := day.Date()
+	loc := day.Location()
+	return time.Date(y, m, d, startClock.Hour(), startClock.Minute(), 0, 0, loc),
+		time.Date(y, m, d, endClock.Hour(), endClock.Minute(), 0, 0, loc), nil
+}
+
+// ============ Task Auto-Assignment ============
+
+// SuggestAssignee picks the least-loaded active staff member (admin, manager or employee) who is
+// not away. Load is the number of open tasks weighted by priority; people already working on the
+// project win ties.
+func (s *TaskService) SuggestAssignee(ctx context.Context, orgID, projectID uuid.UUID, dueDate *time.Time) (*models.AssignmentDecision, error) {
+	at := time.Now()
+	if dueDate != nil && dueDate.After(at) {
+		at = *dueDate
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT
+			u.id, u.first_name || ' ' || u.last_name,
+			COALESCE(SUM(CASE t.priority
+				WHEN 'urgent' THEN $4 WHEN 'high' THEN $5 WHEN 'medium' THEN $6 ELSE $7 END), 0),
+			COUNT(t.id),
+			COUNT(t.id) FILTER (WHERE t.project_id = $2),
+			EXISTS(
+				SELECT 1 FROM user_out_of_office o
+				WHERE o.organization_id = u.organization_id AND o.user_id = u.id AND o.is_active = true
+				AND o.starts_at <= $3 AND o.ends_at > $3
+			)
+		FROM users u
+		LEFT JOIN tasks t ON t.assigned_to = u.id AND t.deleted_at IS NULL
+			AND t.status IN ('todo', 'in_progress')
+		WHERE u.organization_id = $1 AND u.is_active = true AND u.deleted_at IS NULL
+		AND u.role IN ('admin', 'manager', 'employee')
+		GROUP BY u.id
+		ORDER BY u.first_name, u.last_name
+	`, orgID, projectID, at,
+		taskPriorityWeight[models.PriorityUrgent], taskPriorityWeight[models.PriorityHigh],
+		taskPriorityWeight[models.PriorityMedium], taskPriorityWeight[models.PriorityLow])
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	decision := &models.AssignmentDecision{
+		EntityType: "task",
+		Strategy:   models.AssignmentStrategyLeastLoaded,
+		Candidates: []models.AssignmentCandidate{},
+	}
+	onProject := map[uuid.UUID]bool{}
+
+	for rows.Next() {
+		var candidate models.AssignmentCandidate
+		var openTasks, projectTasks int
+		var away bool
+		if err := rows.Scan(&candidate.ID, &candidate.Name, &candidate.Load, &openTasks, &projectTasks, &away); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+
+		candidate.Eligible = !away
+		if away {
+			candidate.Reasons = append(candidate.Reasons, "out of office")
+		}
+		candidate.Reasons = append(candidate.Reasons, fmt.Sprintf("%d open tasks (weighted load %d)", openTasks, candidate.Load))
+		if projectTasks > 0 {
+			onProject[candidate.ID] = true
+			candidate.Reasons = append(candidate.Reasons, fmt.Sprintf("already has %d open tasks on this project", projectTasks))
+		}
+		decision.Candidates = append(decision.Candidates, candidate)
+	}
+
+	// People already on the project go first among equally loaded candidates
+	sort.SliceStable(decision.Candidates, func(i, j int) bool {
+		a, b := decision.Candidates[i], decision.Candidates[j]
+		if a.Load != b.Load {
+			return a.Load < b.Load
+		}
+		return onProject[a.ID] && !onProject[b.ID]
+	})
+
+	selectLeastLoaded(decision)
+	return decision, nil
+}
+
+// AutoAssign assigns an open task to the suggested assignee
+func (s *TaskService) AutoAssign(ctx context.Context, taskID, orgID uuid.UUID) (*models.AssignmentDecision, error) {
+	var projectID uuid.UUID
+	var dueDate *time.Time
+	var status models.TaskStatus
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT t.project_id, t.due_date, t.status
+		FROM tasks t
+		JOIN projects p ON p.id = t.project_id
+		WHERE t.id = $1 AND p.organization_id = $2 AND t.deleted_at IS NULL
+	`, taskID, orgID).Scan(&projectID, &dueDate, &status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("task not found")
+		}
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	if status == models.TaskStatusCompleted || status == models.TaskStatusCancelled {
+		return nil, errors.New("cannot assign completed or cancelled tasks")
+	}
+
+	decision, err := s.SuggestAssignee(ctx, orgID, projectID, dueDate)
+	if err != nil {
+		return nil, err
+	}
+	if decision.SelectedID == nil {
+		return decision, errors.New("no eligible assignee found")
+	}
+
+	_, err = s.db.Pool.Exec(ctx, `
+		UPDATE tasks SET assigned_to = $1, updated_at = NOW() WHERE id = $2
+	`, *decision.SelectedID, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign task: %w", err)
+	}
+
+	if task, err := s.GetByID(ctx, taskID, orgID); err == nil {
+		s.onAssigned(ctx, orgID, task)
+	}
+
+	return decision, nil
+}
+
+// selectLeastLoaded orders the candidates (eligible first, then by load) and selects the first eligible one
+func selectLeastLoaded(decision *models.AssignmentDecision) {
+	sort.SliceStable(decision.Candidates, func(i, j int) bool {
+		a, b := decision.Candidates[i], decision.Candidates[j]
+		if a.Eligible != b.Eligible {
+			return a.Eligible
+		}
+		return a.Load < b.Load
+	})
+
+	if len(decision.Candidates) == 0 || !decision.Candidates[0].Eligible {
+		return
+	}
+
+	selected := &decision.Candidates[0]
+	decision.SelectedID = &selected.ID
+	decision.SelectedName = selected.Name
+	selected.Reasons = append(selected.Reasons, "selected: lowest load among eligible candidates")
+}
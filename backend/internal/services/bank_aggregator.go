@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/config"
+)
+
+// BankAggregatorProvider reads an account's transactions from a PSD2 aggregator
+type BankAggregatorProvider interface {
+	Name() string
+	FetchTransactions(ctx context.Context, accountID string, from, to time.Time) ([]BankTransaction, string, error)
+}
+
+// NewBankAggregatorProvider returns the configured aggregator, or nil when statements are only imported from files
+func NewBankAggregatorProvider(cfg config.BankingConfig) BankAggregatorProvider {
+	switch cfg.AggregatorProvider {
+	case "http":
+		return &httpBankAggregator{
+			endpoint: cfg.AggregatorEndpoint,
+			apiKey:   cfg.AggregatorAPIKey,
+			client:   &http.Client{Timeout: 60 * time.Second},
+		}
+	default:
+		return nil
+	}
+}
+
+// httpBankAggregator reads transactions from an aggregator gateway that answers
+// GET endpoint?account=&from=&to= with the account IBAN and its booked transactions
+type httpBankAggregator struct {
+	endpoint string
+	apiKey   string
+	client   *http.Client
+}
+
+type httpBankAggregatorResponse struct {
+	IBAN         string `json:"iban"`
+	Transactions []struct {
+		BankTransaction
+		BookingDate string  `json:"booking_date"` // YYYY-MM-DD
+		ValueDate   *string `json:"value_date"`
+	} `json:"transactions"`
+}
+
+func (p *httpBankAggregator) Name() string {
+	return "http"
+}
+
+func (p *httpBankAggregator) FetchTransactions(ctx context.Context, accountID string, from, to time.Time) ([]BankTransaction, string, error) {
+	params := url.Values{}
+	params.Set("account", accountID)
+	params.Set("from", from.Format("2006-01-02"))
+	params.Set("to", to.Format("2006-01-02"))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", p.endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create aggregator request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to call bank aggregator: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, "", fmt.Errorf("bank aggregator returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result httpBankAggregatorResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 10<<20)).Decode(&result); err != nil {
+		return nil, "", fmt.Errorf("failed to decode aggregator response: %w", err)
+	}
+
+	transactions := make([]BankTransaction, 0, len(result.Transactions))
+	for _, raw := range result.Transactions {
+		t := raw.BankTransaction
+		bookingDate, ok := parseBankDate(raw.BookingDate)
+		if !ok {
+			return nil, "", fmt.Errorf("aggregator transaction %s has an invalid booking date", t.ExternalID)
+		}
+		t.BookingDate = bookingDate
+		if raw.ValueDate != nil {
+			if d, ok := parseBankDate(*raw.ValueDate); ok {
+				t.ValueDate = &d
+			}
+		}
+		transactions = append(transactions, t)
+	}
+
+	return transactions, result.IBAN, nil
+}
@@ -0,0 +1,783 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/events"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// MaxStatementUploadSize is the maximum size of an uploaded statement file (5MB)
+const MaxStatementUploadSize int64 = 5 << 20
+
+// Auto-matching links a line to its best candidate only when the score reaches autoMatchMinScore
+// and no other candidate comes within autoMatchMinLead points of it
+const (
+	autoMatchMinScore = 80
+	autoMatchMinLead  = 15
+)
+
+// BankStatementService imports bank statements and reconciles their lines with open payments
+type BankStatementService struct {
+	db         *database.DB
+	aggregator BankAggregatorProvider
+	events     *events.Publisher
+}
+
+func NewBankStatementService(db *database.DB, aggregator BankAggregatorProvider) *BankStatementService {
+	return &BankStatementService{
+		db:         db,
+		aggregator: aggregator,
+	}
+}
+
+// SetEventPublisher sets the publisher matched payments are streamed to dashboards with
+func (s *BankStatementService) SetEventPublisher(p *events.Publisher) {
+	s.events = p
+}
+
+// BankLineFilters contains filters for listing statement lines
+type BankLineFilters struct {
+	StatementID *uuid.UUID
+	Status      string
+	Limit       int
+	Offset      int
+}
+
+// AutoMatchResult reports what an auto-matching run did
+type AutoMatchResult struct {
+	Checked int `json:"checked"`
+	Matched int `json:"matched"`
+}
+
+const bankStatementColumns = `
+	bs.id, bs.organization_id, bs.source, bs.account_iban, bs.file_name, bs.period_from, bs.period_to,
+	bs.line_count, bs.duplicate_count, bs.imported_by, bs.created_at,
+	(SELECT COUNT(*) FROM bank_statement_lines l WHERE l.statement_id = bs.id AND l.status = 'matched'),
+	(SELECT COUNT(*) FROM bank_statement_lines l WHERE l.statement_id = bs.id AND l.status = 'unmatched')
+`
+
+func scanBankStatement(row pgx.Row) (*models.BankStatement, error) {
+	var bs models.BankStatement
+	err := row.Scan(
+		&bs.ID, &bs.OrganizationID, &bs.Source, &bs.AccountIBAN, &bs.FileName, &bs.PeriodFrom, &bs.PeriodTo,
+		&bs.LineCount, &bs.DuplicateCount, &bs.ImportedBy, &bs.CreatedAt,
+		&bs.MatchedCount, &bs.UnmatchedCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &bs, nil
+}
+
+const bankLineColumns = `
+	id, statement_id, organization_id, external_id, booking_date, value_date, amount, currency, description,
+	reference, counterparty_name, counterparty_iban, status, matched_payment_id, matched_session_payment_id,
+	match_score, auto_matched, matched_by, matched_at, created_at
+`
+
+func scanBankLine(row pgx.Row) (*models.BankStatementLine, error) {
+	var l models.BankStatementLine
+	err := row.Scan(
+		&l.ID, &l.StatementID, &l.OrganizationID, &l.ExternalID, &l.BookingDate, &l.ValueDate, &l.Amount, &l.Currency, &l.Description,
+		&l.Reference, &l.CounterpartyName, &l.CounterpartyIBAN, &l.Status, &l.MatchedPaymentID, &l.MatchedSessionPaymentID,
+		&l.MatchScore, &l.AutoMatched, &l.MatchedBy, &l.MatchedAt, &l.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// ImportFile imports a CSV or CAMT.053 statement. An empty format is detected from the content.
+func (s *BankStatementService) ImportFile(ctx context.Context, orgID, userID uuid.UUID, format, fileName string, data []byte) (*models.BankStatement, error) {
+	if format == "" {
+		format = string(models.BankStatementSourceCSV)
+		if bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+			format = string(models.BankStatementSourceCAMT)
+		}
+	}
+
+	var transactions []BankTransaction
+	var iban string
+	var err error
+	switch models.BankStatementSource(format) {
+	case models.BankStatementSourceCSV:
+		transactions, err = ParseBankStatementCSV(data)
+	case models.BankStatementSourceCAMT:
+		transactions, iban, err = ParseCAMT053(data)
+	default:
+		return nil, errors.New("format must be csv or camt")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.store(ctx, orgID, userID, models.BankStatementSource(format), iban, fileName, transactions)
+}
+
+// Sync imports an account's transactions of a date range from the bank aggregator
+func (s *BankStatementService) Sync(ctx context.Context, orgID, userID uuid.UUID, accountID string, from, to time.Time) (*models.BankStatement, error) {
+	if s.aggregator == nil {
+		return nil, errors.New("bank aggregator is not configured")
+	}
+	if accountID == "" {
+		return nil, errors.New("account_id is required")
+	}
+	if to.Before(from) {
+		return nil, errors.New("from must be before to")
+	}
+
+	transactions, iban, err := s.aggregator.FetchTransactions(ctx, accountID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if len(transactions) == 0 {
+		return nil, errors.New("no transactions in this period")
+	}
+
+	return s.store(ctx, orgID, userID, models.BankStatementSourceAggregator, iban, "", transactions)
+}
+
+// store saves a statement and its transactions, skipping the ones already imported
+func (s *BankStatementService) store(ctx context.Context, orgID, userID uuid.UUID, source models.BankStatementSource, iban, fileName string, transactions []BankTransaction) (*models.BankStatement, error) {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var statementID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		INSERT INTO bank_statements (organization_id, source, account_iban, file_name, imported_by)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5)
+		RETURNING id
+	`, orgID, source, iban, fileName, userID).Scan(&statementID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create statement: %w", err)
+	}
+
+	var from, to time.Time
+	inserted, duplicates := 0, 0
+	for i := range transactions {
+		t := &transactions[i]
+		externalID := t.ExternalID
+		if externalID == "" {
+			externalID = t.fingerprint()
+		}
+		currency := t.Currency
+		if currency == "" {
+			currency = "EUR"
+		}
+
+		result, err := tx.Exec(ctx, `
+			INSERT INTO bank_statement_lines (
+				statement_id, organization_id, external_id, booking_date, value_date, amount, currency,
+				description, reference, counterparty_name, counterparty_iban
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), NULLIF($9, ''), NULLIF($10, ''), NULLIF($11, ''))
+			ON CONFLICT (organization_id, external_id) DO NOTHING
+		`, statementID, orgID, externalID, t.BookingDate, t.ValueDate, t.Amount, currency,
+			t.Description, t.Reference, t.CounterpartyName, t.CounterpartyIBAN)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save statement line: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			duplicates++
+			continue
+		}
+		inserted++
+
+		if from.IsZero() || t.BookingDate.Before(from) {
+			from = t.BookingDate
+		}
+		if t.BookingDate.After(to) {
+			to = t.BookingDate
+		}
+	}
+
+	if inserted == 0 {
+		return nil, errors.New("all transactions in this statement were already imported")
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE bank_statements SET period_from = $1, period_to = $2, line_count = $3, duplicate_count = $4
+		WHERE id = $5
+	`, from, to, inserted, duplicates, statementID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update statement: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return s.GetStatement(ctx, statementID, orgID)
+}
+
+// ListStatements returns the imported statements, newest first
+func (s *BankStatementService) ListStatements(ctx context.Context, orgID uuid.UUID, limit, offset int) ([]*models.BankStatement, int, error) {
+	var total int
+	err := s.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM bank_statements WHERE organization_id = $1`, orgID).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count statements: %w", err)
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT `+bankStatementColumns+`
+		FROM bank_statements bs
+		WHERE bs.organization_id = $1
+		ORDER BY bs.created_at DESC
+		LIMIT $2 OFFSET $3
+	`, orgID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query statements: %w", err)
+	}
+	defer rows.Close()
+
+	statements := []*models.BankStatement{}
+	for rows.Next() {
+		bs, err := scanBankStatement(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan statement: %w", err)
+		}
+		statements = append(statements, bs)
+	}
+
+	return statements, total, nil
+}
+
+// GetStatement returns a statement of the organization
+func (s *BankStatementService) GetStatement(ctx context.Context, id, orgID uuid.UUID) (*models.BankStatement, error) {
+	bs, err := scanBankStatement(s.db.Pool.QueryRow(ctx, `
+		SELECT `+bankStatementColumns+`
+		FROM bank_statements bs
+		WHERE bs.id = $1 AND bs.organization_id = $2
+	`, id, orgID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("statement not found")
+		}
+		return nil, fmt.Errorf("failed to get statement: %w", err)
+	}
+	return bs, nil
+}
+
+// ListLines returns statement lines filtered by statement and status
+func (s *BankStatementService) ListLines(ctx context.Context, orgID uuid.UUID, filters BankLineFilters) ([]*models.BankStatementLine, int, error) {
+	where := "WHERE organization_id = $1"
+	args := []interface{}{orgID}
+
+	if filters.StatementID != nil {
+		args = append(args, *filters.StatementID)
+		where += fmt.Sprintf(" AND statement_id = $%d", len(args))
+	}
+	if filters.Status != "" {
+		args = append(args, filters.Status)
+		where += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+
+	var total int
+	err := s.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM bank_statement_lines `+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count statement lines: %w", err)
+	}
+
+	if filters.Limit <= 0 {
+		filters.Limit = 50
+	}
+	args = append(args, filters.Limit, filters.Offset)
+	rows, err := s.db.Pool.Query(ctx, `SELECT `+bankLineColumns+` FROM bank_statement_lines `+where+fmt.Sprintf(`
+		ORDER BY booking_date DESC, created_at ASC
+		LIMIT $%d OFFSET $%d
+	`, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query statement lines: %w", err)
+	}
+	defer rows.Close()
+
+	lines := []*models.BankStatementLine{}
+	for rows.Next() {
+		l, err := scanBankLine(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan statement line: %w", err)
+		}
+		lines = append(lines, l)
+	}
+
+	return lines, total, nil
+}
+
+// GetLine returns a statement line of the organization
+func (s *BankStatementService) GetLine(ctx context.Context, id, orgID uuid.UUID) (*models.BankStatementLine, error) {
+	return s.getLine(ctx, s.db.Pool, id, orgID, false)
+}
+
+func (s *BankStatementService) getLine(ctx context.Context, q rowQuerier, id, orgID uuid.UUID, forUpdate bool) (*models.BankStatementLine, error) {
+	query := `SELECT ` + bankLineColumns + ` FROM bank_statement_lines WHERE id = $1 AND organization_id = $2`
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
+	l, err := scanBankLine(q.QueryRow(ctx, query, id, orgID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("statement line not found")
+		}
+		return nil, fmt.Errorf("failed to get statement line: %w", err)
+	}
+	return l, nil
+}
+
+// Suggestions returns the open payments a line may settle, best match first
+func (s *BankStatementService) Suggestions(ctx context.Context, lineID, orgID uuid.UUID) ([]*models.BankMatchCandidate, error) {
+	line, err := s.GetLine(ctx, lineID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if line.Status != models.BankLineUnmatched {
+		return []*models.BankMatchCandidate{}, nil
+	}
+	return s.candidates(ctx, line)
+}
+
+// AutoMatch links unmatched incoming lines, of a statement or of the whole organization, to their
+// best candidate when it is a clear match. Lines that can't be matched (e.g. in a closed month)
+// are left for manual matching.
+func (s *BankStatementService) AutoMatch(ctx context.Context, orgID, userID uuid.UUID, statementID *uuid.UUID) (*AutoMatchResult, error) {
+	filters := BankLineFilters{StatementID: statementID, Status: string(models.BankLineUnmatched), Limit: 500}
+	lines, _, err := s.ListLines(ctx, orgID, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AutoMatchResult{}
+	claimed := map[uuid.UUID]bool{}
+	for _, line := range lines {
+		if !line.Amount.IsPositive() {
+			continue
+		}
+		result.Checked++
+
+		candidates, err := s.candidates(ctx, line)
+		if err != nil {
+			return nil, err
+		}
+		if len(candidates) == 0 || candidates[0].Score < autoMatchMinScore {
+			continue
+		}
+		if len(candidates) > 1 && candidates[0].Score-candidates[1].Score < autoMatchMinLead {
+			continue
+		}
+		best := candidates[0]
+		if claimed[best.ID] {
+			continue
+		}
+
+		if err := s.match(ctx, orgID, line.ID, best.Kind, best.ID, &best.Score, true, userID); err != nil {
+			log.Printf("[BankStatement] Could not auto-match line %s: %v", line.ID, err)
+			continue
+		}
+		claimed[best.ID] = true
+		result.Matched++
+	}
+
+	return result, nil
+}
+
+// Match links a line to a payment or session payment chosen by the user and marks it paid
+func (s *BankStatementService) Match(ctx context.Context, lineID, orgID, userID uuid.UUID, kind models.BankMatchKind, targetID uuid.UUID) (*models.BankStatementLine, error) {
+	if kind != models.BankMatchPayment && kind != models.BankMatchSessionPayment {
+		return nil, errors.New("kind must be payment or session_payment")
+	}
+
+	line, err := s.GetLine(ctx, lineID, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the score when the user picked a suggestion
+	var score *int
+	if line.Status == models.BankLineUnmatched && line.Amount.IsPositive() {
+		candidates, err := s.candidates(ctx, line)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range candidates {
+			if c.Kind == kind && c.ID == targetID {
+				score = &c.Score
+				break
+			}
+		}
+	}
+
+	if err := s.match(ctx, orgID, lineID, kind, targetID, score, false, userID); err != nil {
+		return nil, err
+	}
+	return s.GetLine(ctx, lineID, orgID)
+}
+
+func (s *BankStatementService) match(ctx context.Context, orgID, lineID uuid.UUID, kind models.BankMatchKind, targetID uuid.UUID, score *int, auto bool, userID uuid.UUID) error {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	line, err := s.getLine(ctx, tx, lineID, orgID, true)
+	if err != nil {
+		return err
+	}
+	if line.Status != models.BankLineUnmatched {
+		return errors.New("statement line is already matched or ignored")
+	}
+	if !line.Amount.IsPositive() {
+		return errors.New("only incoming transactions can be matched to a payment")
+	}
+	if err := checkPeriodsOpen(ctx, tx, orgID, line.BookingDate); err != nil {
+		return err
+	}
+
+	var paymentID, sessionPaymentID *uuid.UUID
+	switch kind {
+	case models.BankMatchPayment:
+		result, err := tx.Exec(ctx, `
+			UPDATE payments
+			SET status = 'paid', paid_at = $1, method = 'transfer', reference = COALESCE(reference, $2), updated_at = NOW()
+			WHERE id = $3 AND organization_id = $4 AND deleted_at IS NULL AND status IN ('pending', 'overdue')
+		`, line.BookingDate, line.Reference, targetID, orgID)
+		if err != nil {
+			return fmt.Errorf("failed to mark payment as paid: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			return ErrPaymentNotOpen
+		}
+		paymentID = &targetID
+	case models.BankMatchSessionPayment:
+		// A transfer short of the session price leaves the payment partial
+		cents := line.Amount.Mul(decimal.NewFromInt(100)).IntPart()
+		result, err := tx.Exec(ctx, `
+			UPDATE session_payments sp
+			SET payment_status = CASE WHEN $1 >= sp.amount_cents THEN 'paid' ELSE 'partial' END,
+				payment_method = 'transfer', paid_at = $2, updated_at = NOW()
+			FROM sessions s
+			WHERE sp.session_id = s.id AND sp.id = $3 AND s.organization_id = $4
+				AND sp.payment_status IN ('unpaid', 'partial')
+		`, cents, line.BookingDate, targetID, orgID)
+		if err != nil {
+			return fmt.Errorf("failed to mark session payment as paid: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			return errors.New("session payment not found or already paid")
+		}
+		sessionPaymentID = &targetID
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE bank_statement_lines
+		SET status = 'matched', matched_payment_id = $1, matched_session_payment_id = $2, match_score = $3,
+			auto_matched = $4, matched_by = $5, matched_at = NOW()
+		WHERE id = $6
+	`, paymentID, sessionPaymentID, score, auto, userID, lineID)
+	if err != nil {
+		return fmt.Errorf("failed to match statement line: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	entityType := "payment"
+	if kind == models.BankMatchSessionPayment {
+		entityType = "session_payment"
+	}
+	s.events.Publish(ctx, orgID, models.DashboardEvent{
+		Type:       models.DashboardEventPaymentReceived,
+		EntityType: entityType,
+		EntityID:   targetID,
+		Data: map[string]interface{}{
+			"amount": line.Amount,
+			"method": models.PaymentMethodTransfer,
+			"source": "bank_statement",
+		},
+	})
+	return nil
+}
+
+// Unmatch returns a matched or ignored line to unmatched. A matched payment is reopened.
+func (s *BankStatementService) Unmatch(ctx context.Context, lineID, orgID uuid.UUID) (*models.BankStatementLine, error) {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	line, err := s.getLine(ctx, tx, lineID, orgID, true)
+	if err != nil {
+		return nil, err
+	}
+	if line.Status == models.BankLineUnmatched {
+		return nil, errors.New("statement line is not matched")
+	}
+
+	if line.Status == models.BankLineMatched {
+		if err := checkPeriodsOpen(ctx, tx, orgID, line.BookingDate); err != nil {
+			return nil, err
+		}
+		if line.MatchedPaymentID != nil {
+			_, err = tx.Exec(ctx, `
+				UPDATE payments
+				SET status = CASE WHEN due_date < CURRENT_DATE THEN 'overdue' ELSE 'pending' END, paid_at = NULL, updated_at = NOW()
+				WHERE id = $1 AND organization_id = $2 AND status = 'paid'
+			`, *line.MatchedPaymentID, orgID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to reopen payment: %w", err)
+			}
+		}
+		if line.MatchedSessionPaymentID != nil {
+			_, err = tx.Exec(ctx, `
+				UPDATE session_payments SET payment_status = 'unpaid', payment_method = NULL, paid_at = NULL, updated_at = NOW()
+				WHERE id = $1
+			`, *line.MatchedSessionPaymentID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to reopen session payment: %w", err)
+			}
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE bank_statement_lines
+		SET status = 'unmatched', matched_payment_id = NULL, matched_session_payment_id = NULL, match_score = NULL,
+			auto_matched = false, matched_by = NULL, matched_at = NULL
+		WHERE id = $1
+	`, lineID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmatch statement line: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return s.GetLine(ctx, lineID, orgID)
+}
+
+// Ignore marks a line that needs no match, such as a bank fee
+func (s *BankStatementService) Ignore(ctx context.Context, lineID, orgID, userID uuid.UUID) (*models.BankStatementLine, error) {
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE bank_statement_lines SET status = 'ignored', matched_by = $1, matched_at = NOW()
+		WHERE id = $2 AND organization_id = $3 AND status = 'unmatched'
+	`, userID, lineID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to ignore statement line: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, errors.New("statement line not found or already matched")
+	}
+	return s.GetLine(ctx, lineID, orgID)
+}
+
+// bankCandidate is an open payment with the identifiers a payer may quote in the transfer
+type bankCandidate struct {
+	models.BankMatchCandidate
+	refs []string
+}
+
+// candidates finds the open payments an incoming line may settle: same amount, or quoting their
+// reference, RF reference or project number. Payments already matched to another line are left out.
+func (s *BankStatementService) candidates(ctx context.Context, line *models.BankStatementLine) ([]*models.BankMatchCandidate, error) {
+	if !line.Amount.IsPositive() {
+		return []*models.BankMatchCandidate{}, nil
+	}
+
+	text := strings.ToLower(bankLineText(line))
+	var found []*bankCandidate
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT pay.id, pay.amount, pay.due_date, pay.reference, pay.sepa_reference, p.project_number, p.title, COALESCE(c.name, '')
+		FROM payments pay
+		JOIN projects p ON p.id = pay.project_id
+		LEFT JOIN budgets b ON b.id = p.budget_id
+		LEFT JOIN worksheets w ON w.id = b.worksheet_id
+		LEFT JOIN clients c ON c.id = w.client_id
+		WHERE pay.organization_id = $1 AND pay.deleted_at IS NULL AND pay.status IN ('pending', 'overdue')
+			AND (pay.amount = $2
+				OR position(lower(p.project_number) IN $3) > 0
+				OR (COALESCE(pay.reference, '') <> '' AND position(lower(pay.reference) IN $3) > 0)
+				OR (COALESCE(pay.sepa_reference, '') <> '' AND position(lower(pay.sepa_reference) IN $3) > 0))
+			AND NOT EXISTS (
+				SELECT 1 FROM bank_statement_lines l WHERE l.matched_payment_id = pay.id AND l.status = 'matched'
+			)
+		ORDER BY pay.due_date ASC
+		LIMIT 25
+	`, line.OrganizationID, line.Amount, text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query payment candidates: %w", err)
+	}
+	for rows.Next() {
+		c := &bankCandidate{}
+		c.Kind = models.BankMatchPayment
+		var dueDate time.Time
+		var projectNumber, title string
+		var sepaReference *string
+		if err := rows.Scan(&c.ID, &c.Amount, &dueDate, &c.Reference, &sepaReference, &projectNumber, &title, &c.Counterparty); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan payment candidate: %w", err)
+		}
+		c.DueDate = &dueDate
+		c.Label = projectNumber + " " + title
+		c.refs = []string{projectNumber}
+		if c.Reference != nil {
+			c.refs = append(c.refs, *c.Reference)
+		}
+		if sepaReference != nil {
+			c.refs = append(c.refs, *sepaReference)
+		}
+		found = append(found, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query payment candidates: %w", err)
+	}
+
+	cents := line.Amount.Mul(decimal.NewFromInt(100)).IntPart()
+	rows, err = s.db.Pool.Query(ctx, `
+		SELECT sp.id, sp.amount_cents, COALESCE(sp.due_date, s.scheduled_at::date), s.scheduled_at, sp.sepa_reference, COALESCE(c.name, '')
+		FROM session_payments sp
+		JOIN sessions s ON s.id = sp.session_id
+		LEFT JOIN patients pt ON pt.id = s.patient_id
+		LEFT JOIN clients c ON c.id = pt.client_id
+		WHERE s.organization_id = $1 AND s.deleted_at IS NULL
+			AND sp.payment_status IN ('unpaid', 'partial')
+			AND (sp.amount_cents = $2
+				OR (COALESCE(sp.sepa_reference, '') <> '' AND position(lower(sp.sepa_reference) IN $4) > 0))
+			AND NOT EXISTS (
+				SELECT 1 FROM bank_statement_lines l WHERE l.matched_session_payment_id = sp.id AND l.status = 'matched'
+			)
+		ORDER BY ABS(s.scheduled_at::date - $3::date) ASC
+		LIMIT 25
+	`, line.OrganizationID, cents, line.BookingDate, text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query session payment candidates: %w", err)
+	}
+	for rows.Next() {
+		c := &bankCandidate{}
+		c.Kind = models.BankMatchSessionPayment
+		var amountCents int
+		var dueDate, scheduledAt time.Time
+		var sepaReference *string
+		if err := rows.Scan(&c.ID, &amountCents, &dueDate, &scheduledAt, &sepaReference, &c.Counterparty); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan session payment candidate: %w", err)
+		}
+		c.Amount = decimal.New(int64(amountCents), -2)
+		c.DueDate = &dueDate
+		c.Label = c.Counterparty + " " + scheduledAt.Format("2006-01-02 15:04")
+		if sepaReference != nil {
+			c.refs = []string{*sepaReference}
+		}
+		found = append(found, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query session payment candidates: %w", err)
+	}
+
+	candidates := make([]*models.BankMatchCandidate, 0, len(found))
+	for _, c := range found {
+		scoreBankMatch(line, c)
+		candidates = append(candidates, &c.BankMatchCandidate)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	return candidates, nil
+}
+
+// bankLineText joins the free text of a line that may identify the payment
+func bankLineText(line *models.BankStatementLine) string {
+	var parts []string
+	for _, p := range []*string{line.Description, line.Reference, line.CounterpartyName} {
+		if p != nil && *p != "" {
+			parts = append(parts, *p)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// scoreBankMatch rates from 0 to 100 how likely a line settles a candidate: the amount weighs
+// most, then a quoted reference, the payer's name and the distance to the due date
+func scoreBankMatch(line *models.BankStatementLine, c *bankCandidate) {
+	score := 0
+	reasons := []string{}
+
+	if line.Amount.Equal(c.Amount) {
+		score += 50
+		reasons = append(reasons, "amount matches")
+	}
+
+	text := normalizeBankReference(bankLineText(line))
+	for _, ref := range c.refs {
+		if r := normalizeBankReference(ref); len(r) >= 4 && strings.Contains(text, r) {
+			score += 30
+			reasons = append(reasons, fmt.Sprintf("reference %s quoted", ref))
+			break
+		}
+	}
+
+	if c.Counterparty != "" && bankNameMatches(bankLineText(line), c.Counterparty) {
+		score += 15
+		reasons = append(reasons, "payer name matches")
+	}
+
+	if c.DueDate != nil {
+		days := line.BookingDate.Sub(*c.DueDate).Hours() / 24
+		if days < 0 {
+			days = -days
+		}
+		switch {
+		case days <= 3:
+			score += 15
+			reasons = append(reasons, "paid around the due date")
+		case days <= 15:
+			score += 10
+			reasons = append(reasons, "paid within two weeks of the due date")
+		case days <= 45:
+			score += 5
+		}
+	}
+
+	c.Score = min(score, 100)
+	c.Reasons = reasons
+}
+
+// normalizeBankReference lowercases a reference and drops the separators banks often add or remove
+func normalizeBankReference(s string) string {
+	return strings.NewReplacer(" ", "", "-", "", "/", "", ".", "", "_", "").Replace(strings.ToLower(s))
+}
+
+// bankNameMatches reports whether most words of a name appear in the line text. Banks
+// truncate and reorder names, so every word of three or more letters counts on its own.
+func bankNameMatches(text, name string) bool {
+	text = accentReplacer.Replace(strings.ToLower(text))
+	words, hits := 0, 0
+	for _, w := range strings.Fields(accentReplacer.Replace(strings.ToLower(name))) {
+		if len([]rune(w)) < 3 {
+			continue
+		}
+		words++
+		if strings.Contains(text, w) {
+			hits++
+		}
+	}
+	return words > 0 && hits*2 >= words && (hits >= 2 || words == 1)
+}
@@ -0,0 +1,316 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// BankTransaction is a statement line read from a file or an aggregator, before it is stored
+type BankTransaction struct {
+	ExternalID       string          `json:"id"`
+	BookingDate      time.Time       `json:"booking_date"`
+	ValueDate        *time.Time      `json:"value_date"`
+	Amount           decimal.Decimal `json:"amount"` // credits positive, debits negative
+	Currency         string          `json:"currency"`
+	Description      string          `json:"description"`
+	Reference        string          `json:"reference"`
+	CounterpartyName string          `json:"counterparty_name"`
+	CounterpartyIBAN string          `json:"counterparty_iban"`
+}
+
+// fingerprint identifies a transaction without a bank id, so importing it again is detected
+func (t *BankTransaction) fingerprint() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		t.BookingDate.Format("2006-01-02"), t.Amount.StringFixed(2), t.Description, t.Reference, t.CounterpartyIBAN,
+	}, "|")))
+	return "sha256:" + hex.EncodeToString(sum[:16])
+}
+
+// bankStatementDateLayouts are the date formats accepted in CSV statements
+var bankStatementDateLayouts = []string{"2006-01-02", "02-01-2006", "02/01/2006", "02.01.2006", "2006/01/02"}
+
+// bankCSVColumns maps normalized CSV headers, in English and Portuguese, to transaction fields
+var bankCSVColumns = map[string]string{
+	"date":                "date",
+	"booking date":        "date",
+	"data":                "date",
+	"data movimento":      "date",
+	"data mov":            "date",
+	"data lancamento":     "date",
+	"data operacao":       "date",
+	"value date":          "value_date",
+	"data valor":          "value_date",
+	"amount":              "amount",
+	"montante":            "amount",
+	"valor":               "amount",
+	"importancia":         "amount",
+	"debit":               "debit",
+	"debito":              "debit",
+	"credit":              "credit",
+	"credito":             "credit",
+	"currency":            "currency",
+	"moeda":               "currency",
+	"description":         "description",
+	"descricao":           "description",
+	"descritivo":          "description",
+	"movimento":           "description",
+	"reference":           "reference",
+	"referencia":          "reference",
+	"ref":                 "reference",
+	"counterparty":        "counterparty",
+	"name":                "counterparty",
+	"nome":                "counterparty",
+	"ordenante":           "counterparty",
+	"beneficiario":        "counterparty",
+	"counterparty iban":   "iban",
+	"iban":                "iban",
+	"iban ordenante":      "iban",
+	"transaction id":      "id",
+	"id":                  "id",
+	"id transacao":        "id",
+	"referencia do banco": "id",
+}
+
+// accentReplacer drops the accents of lowercase Portuguese text, as banks often do
+var accentReplacer = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "é", "e", "ê", "e", "í", "i",
+	"ó", "o", "ô", "o", "õ", "o", "ú", "u", "ç", "c",
+)
+
+// normalizeBankHeader lowercases a CSV header and drops accents and punctuation
+func normalizeBankHeader(h string) string {
+	h = accentReplacer.Replace(strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))))
+	h = strings.NewReplacer(".", "", ":", "", "_", " ").Replace(h)
+	return strings.Join(strings.Fields(h), " ")
+}
+
+// ParseBankStatementCSV reads a CSV export with a header row. The delimiter (comma or semicolon)
+// is detected from the header, and amounts come either from an amount column or from separate
+// debit and credit columns.
+func ParseBankStatementCSV(data []byte) ([]BankTransaction, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("statement file is empty or not a valid CSV")
+	}
+	columns := map[string]int{}
+	for i, h := range header {
+		if field, ok := bankCSVColumns[normalizeBankHeader(h)]; ok {
+			if _, seen := columns[field]; !seen {
+				columns[field] = i
+			}
+		}
+	}
+	if _, ok := columns["date"]; !ok {
+		return nil, errors.New("statement file has no date column")
+	}
+	_, hasAmount := columns["amount"]
+	_, hasDebit := columns["debit"]
+	_, hasCredit := columns["credit"]
+	if !hasAmount && !hasDebit && !hasCredit {
+		return nil, errors.New("statement file has no amount column")
+	}
+
+	var transactions []BankTransaction
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV at row %d: %w", row, err)
+		}
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		if strings.Join(record, "") == "" {
+			continue
+		}
+		bookingDate, ok := parseBankDate(field("date"))
+		if !ok {
+			// Exports often end with balance or summary rows
+			continue
+		}
+
+		t := BankTransaction{
+			ExternalID:       field("id"),
+			BookingDate:      bookingDate,
+			Currency:         strings.ToUpper(field("currency")),
+			Description:      field("description"),
+			Reference:        field("reference"),
+			CounterpartyName: field("counterparty"),
+			CounterpartyIBAN: strings.ReplaceAll(field("iban"), " ", ""),
+		}
+		if d, ok := parseBankDate(field("value_date")); ok {
+			t.ValueDate = &d
+		}
+
+		if hasAmount {
+			amount, err := parseStatementAmount(field("amount"))
+			if err != nil {
+				return nil, fmt.Errorf("invalid amount at row %d", row)
+			}
+			t.Amount = amount
+		} else {
+			credit, errCredit := parseStatementAmount(field("credit"))
+			debit, errDebit := parseStatementAmount(field("debit"))
+			if errCredit != nil || errDebit != nil {
+				return nil, fmt.Errorf("invalid amount at row %d", row)
+			}
+			t.Amount = credit.Abs().Sub(debit.Abs())
+		}
+
+		transactions = append(transactions, t)
+	}
+
+	if len(transactions) == 0 {
+		return nil, errors.New("statement file has no transactions")
+	}
+	return transactions, nil
+}
+
+func parseBankDate(raw string) (time.Time, bool) {
+	raw = strings.TrimSpace(raw)
+	if len(raw) > 10 {
+		raw = raw[:10] // drop a time part
+	}
+	for _, layout := range bankStatementDateLayouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseStatementAmount parses a signed amount; an empty value is zero
+func parseStatementAmount(raw string) (decimal.Decimal, error) {
+	raw = strings.TrimSpace(strings.NewReplacer("€", "", "EUR", "", " ", "").Replace(raw))
+	if raw == "" {
+		return decimal.Zero, nil
+	}
+	return parseLocaleAmount(raw)
+}
+
+// camtDocument is the part of an ISO 20022 CAMT.053 statement read on import
+type camtDocument struct {
+	Statements []struct {
+		Account struct {
+			IBAN string `xml:"Id>IBAN"`
+		} `xml:"Acct"`
+		Entries []camtEntry `xml:"Ntry"`
+	} `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtEntry struct {
+	Amount struct {
+		Value    string `xml:",chardata"`
+		Currency string `xml:"Ccy,attr"`
+	} `xml:"Amt"`
+	CreditDebit     string `xml:"CdtDbtInd"`
+	BookingDate     string `xml:"BookgDt>Dt"`
+	BookingDateTime string `xml:"BookgDt>DtTm"`
+	ValueDate       string `xml:"ValDt>Dt"`
+	ServicerRef     string `xml:"AcctSvcrRef"`
+	AdditionalInfo  string `xml:"AddtlNtryInf"`
+	Details         []struct {
+		EndToEndID   string   `xml:"Refs>EndToEndId"`
+		Unstructured []string `xml:"RmtInf>Ustrd"`
+		CreditorRef  string   `xml:"RmtInf>Strd>CdtrRefInf>Ref"`
+		DebtorName   string   `xml:"RltdPties>Dbtr>Nm"`
+		DebtorIBAN   string   `xml:"RltdPties>DbtrAcct>Id>IBAN"`
+		CreditorName string   `xml:"RltdPties>Cdtr>Nm"`
+		CreditorIBAN string   `xml:"RltdPties>CdtrAcct>Id>IBAN"`
+	} `xml:"NtryDtls>TxDtls"`
+}
+
+// ParseCAMT053 reads the entries of an ISO 20022 CAMT.053 statement and the account IBAN
+func ParseCAMT053(data []byte) ([]BankTransaction, string, error) {
+	var doc camtDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, "", errors.New("statement file is not a valid CAMT.053 document")
+	}
+
+	var iban string
+	var transactions []BankTransaction
+	for _, stmt := range doc.Statements {
+		if iban == "" {
+			iban = stmt.Account.IBAN
+		}
+		for _, entry := range stmt.Entries {
+			rawDate := entry.BookingDate
+			if rawDate == "" {
+				rawDate = entry.BookingDateTime
+			}
+			bookingDate, ok := parseBankDate(rawDate)
+			if !ok {
+				return nil, "", fmt.Errorf("entry %s has no booking date", entry.ServicerRef)
+			}
+			amount, err := decimal.NewFromString(strings.TrimSpace(entry.Amount.Value))
+			if err != nil {
+				return nil, "", fmt.Errorf("entry %s has an invalid amount", entry.ServicerRef)
+			}
+			credit := entry.CreditDebit != "DBIT"
+			if !credit {
+				amount = amount.Neg()
+			}
+
+			t := BankTransaction{
+				ExternalID:  entry.ServicerRef,
+				BookingDate: bookingDate,
+				Amount:      amount,
+				Currency:    entry.Amount.Currency,
+				Description: strings.TrimSpace(entry.AdditionalInfo),
+			}
+			if d, ok := parseBankDate(entry.ValueDate); ok {
+				t.ValueDate = &d
+			}
+			if len(entry.Details) > 0 {
+				// Batched entries carry several transactions; the first one describes the entry
+				d := entry.Details[0]
+				if info := strings.TrimSpace(strings.Join(d.Unstructured, " ")); info != "" {
+					t.Description = info
+				}
+				t.Reference = d.CreditorRef
+				if t.Reference == "" && d.EndToEndID != "NOTPROVIDED" {
+					t.Reference = d.EndToEndID
+				}
+				// The counterparty of a credit is its debtor
+				if credit {
+					t.CounterpartyName, t.CounterpartyIBAN = d.DebtorName, d.DebtorIBAN
+				} else {
+					t.CounterpartyName, t.CounterpartyIBAN = d.CreditorName, d.CreditorIBAN
+				}
+			}
+			transactions = append(transactions, t)
+		}
+	}
+
+	if len(transactions) == 0 {
+		return nil, "", errors.New("statement file has no transactions")
+	}
+	return transactions, iban, nil
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+func TestParseBankStatementCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		amounts []string
+		wantErr bool
+	}{
+		{
+			name: "portuguese export with debit and credit columns",
+			data: "Data Mov.;Data Valor;Descrição;Débito;Crédito\n" +
+				"05-03-2024;05-03-2024;TRF DE JOAO SILVA OBRA-2024-001;;1.250,00\n" +
+				"06-03-2024;06-03-2024;COMISSAO MANUTENCAO;4,16;\n" +
+				";;Saldo final;;\n",
+			amounts: []string{"1250", "-4.16"},
+		},
+		{
+			name: "signed amount column",
+			data: "Date,Description,Amount,Reference\n" +
+				"2024-03-05,Transfer,45.00,RF 123\n",
+			amounts: []string{"45"},
+		},
+		{
+			name:    "no amount column",
+			data:    "Date,Description\n2024-03-05,Transfer\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBankStatementCSV([]byte(tt.data))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tt.amounts) {
+				t.Fatalf("got %d transactions, want %d", len(got), len(tt.amounts))
+			}
+			for i, want := range tt.amounts {
+				if got[i].Amount.String() != want {
+					t.Errorf("transaction %d amount = %s, want %s", i, got[i].Amount, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseCAMT053(t *testing.T) {
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+  <BkToCstmrStmt>
+    <Stmt>
+      <Acct><Id><IBAN>PT50000201231234567890154</IBAN></Id></Acct>
+      <Ntry>
+        <Amt Ccy="EUR">60.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <BookgDt><Dt>2024-03-07</Dt></BookgDt>
+        <AcctSvcrRef>BANK-REF-1</AcctSvcrRef>
+        <NtryDtls><TxDtls>
+          <Refs><EndToEndId>NOTPROVIDED</EndToEndId></Refs>
+          <RltdPties><Dbtr><Nm>Maria Costa</Nm></Dbtr></RltdPties>
+          <RmtInf><Ustrd>Consulta marco</Ustrd></RmtInf>
+        </TxDtls></NtryDtls>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="EUR">12.30</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <BookgDt><Dt>2024-03-08</Dt></BookgDt>
+        <AcctSvcrRef>BANK-REF-2</AcctSvcrRef>
+      </Ntry>
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>`
+
+	got, iban, err := ParseCAMT053([]byte(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if iban != "PT50000201231234567890154" {
+		t.Errorf("iban = %q", iban)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d transactions, want 2", len(got))
+	}
+	if got[0].CounterpartyName != "Maria Costa" || got[0].Description != "Consulta marco" || got[0].Reference != "" {
+		t.Errorf("unexpected credit details: %+v", got[0])
+	}
+	if got[1].Amount.String() != "-12.3" {
+		t.Errorf("debit amount = %s, want -12.3", got[1].Amount)
+	}
+}
+
+func TestScoreBankMatch(t *testing.T) {
+	description := "TRF JOAO SILVA OBRA 2024 001"
+	counterparty := "Joao Silva"
+	line := &models.BankStatementLine{
+		BookingDate:      time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC),
+		Amount:           decimal.RequireFromString("1250.00"),
+		Description:      &description,
+		CounterpartyName: &counterparty,
+	}
+	due := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	exact := &bankCandidate{
+		BankMatchCandidate: models.BankMatchCandidate{Amount: decimal.RequireFromString("1250"), DueDate: &due, Counterparty: "João Silva"},
+		refs:               []string{"OBRA-2024-001"},
+	}
+	scoreBankMatch(line, exact)
+	if exact.Score != 100 {
+		t.Errorf("exact match score = %d (%v), want 100", exact.Score, exact.Reasons)
+	}
+
+	amountOnly := &bankCandidate{
+		BankMatchCandidate: models.BankMatchCandidate{Amount: decimal.RequireFromString("1250")},
+		refs:               []string{"OBRA-2023-099"},
+	}
+	scoreBankMatch(line, amountOnly)
+	if amountOnly.Score != 50 {
+		t.Errorf("amount only score = %d (%v), want 50", amountOnly.Score, amountOnly.Reasons)
+	}
+	if amountOnly.Score >= autoMatchMinScore {
+		t.Error("an amount alone must not be auto-matched")
+	}
+}
@@ -0,0 +1,338 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// benchmarkWindowDays is the trailing window the metrics are computed over
+	benchmarkWindowDays = 90
+	// benchmarkMinParticipants is how many organizations must report a metric before its bands
+	// are shown, so no single organization's value can be inferred from them
+	benchmarkMinParticipants = 5
+)
+
+// benchmarkMinSamples is how much activity a metric needs before an organization's value is kept
+var benchmarkMinSamples = map[models.BenchmarkMetric]int{
+	models.BenchmarkNoShowRate:           20,
+	models.BenchmarkBudgetConversionRate: 5,
+	models.BenchmarkResponseTimeHours:    10,
+}
+
+// benchmarkLowerIsBetter marks the metrics where a lower value is the better one
+var benchmarkLowerIsBetter = map[models.BenchmarkMetric]bool{
+	models.BenchmarkNoShowRate:        true,
+	models.BenchmarkResponseTimeHours: true,
+}
+
+// BenchmarkService computes the aggregate metrics of the organizations that opted in to
+// benchmarking and compares each of them to the anonymized participants
+type BenchmarkService struct {
+	db *database.DB
+}
+
+func NewBenchmarkService(db *database.DB) *BenchmarkService {
+	return &BenchmarkService{db: db}
+}
+
+// GetParticipation returns whether the organization shares its metrics
+func (s *BenchmarkService) GetParticipation(ctx context.Context, orgID uuid.UUID) (*models.BenchmarkParticipation, error) {
+	p := &models.BenchmarkParticipation{}
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT opted_in_by, opted_in_at FROM benchmark_participants WHERE organization_id = $1
+	`, orgID).Scan(&p.OptedInBy, &p.OptedInAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get benchmarking participation: %w", err)
+	}
+	p.OptedIn = true
+	return p, nil
+}
+
+// SetParticipation opts the organization in to or out of benchmarking. Opting in computes its
+// metrics right away; opting out deletes them.
+func (s *BenchmarkService) SetParticipation(ctx context.Context, orgID, userID uuid.UUID, optIn bool) (*models.BenchmarkParticipation, error) {
+	if !optIn {
+		if _, err := s.db.Pool.Exec(ctx, `DELETE FROM benchmark_participants WHERE organization_id = $1`, orgID); err != nil {
+			return nil, fmt.Errorf("failed to opt out of benchmarking: %w", err)
+		}
+		return s.GetParticipation(ctx, orgID)
+	}
+
+	_, err := s.db.Pool.Exec(ctx, `
+		INSERT INTO benchmark_participants (organization_id, opted_in_by)
+		VALUES ($1, $2)
+		ON CONFLICT (organization_id) DO NOTHING
+	`, orgID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to opt in to benchmarking: %w", err)
+	}
+	if err := s.Refresh(ctx, orgID); err != nil {
+		return nil, err
+	}
+	return s.GetParticipation(ctx, orgID)
+}
+
+// benchmarkSample is an organization's value of a metric and how much activity it is based on
+type benchmarkSample struct {
+	value float64
+	size  int
+}
+
+// measure computes the organization's metrics since the given time
+func (s *BenchmarkService) measure(ctx context.Context, orgID uuid.UUID, since time.Time) (map[models.BenchmarkMetric]benchmarkSample, error) {
+	samples := make(map[models.BenchmarkMetric]benchmarkSample)
+
+	// Missed sessions among those that were due to happen
+	var noShows, attended int
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT COUNT(*) FILTER (WHERE status = 'no_show'), COUNT(*) FILTER (WHERE status = 'completed')
+		FROM sessions
+		WHERE organization_id = $1 AND deleted_at IS NULL
+			AND scheduled_at >= $2 AND scheduled_at < NOW()
+	`, orgID, since).Scan(&noShows, &attended)
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure no-shows: %w", err)
+	}
+	if total := noShows + attended; total > 0 {
+		samples[models.BenchmarkNoShowRate] = benchmarkSample{value: float64(noShows) * 100 / float64(total), size: total}
+	}
+
+	// Approved budgets among those sent that the client decided on or let expire
+	var approved, decided int
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT COUNT(*) FILTER (WHERE status = 'approved'), COUNT(*)
+		FROM budgets
+		WHERE organization_id = $1 AND deleted_at IS NULL
+			AND sent_at >= $2 AND status IN ('approved', 'rejected', 'expired')
+	`, orgID, since).Scan(&approved, &decided)
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure budget conversion: %w", err)
+	}
+	if decided > 0 {
+		samples[models.BenchmarkBudgetConversionRate] = benchmarkSample{value: float64(approved) * 100 / float64(decided), size: decided}
+	}
+
+	// Time from the first of a run of inbound WhatsApp messages to the next reply sent by staff;
+	// automated messages (no sender) don't count as an answer
+	var answered int
+	var hours *float64
+	err = s.db.Pool.QueryRow(ctx, `
+		WITH msgs AS (
+			SELECT `+threadPhoneSQL+` AS phone, m.direction, m.sent_by, m.created_at,
+				LAG(m.direction) OVER (PARTITION BY `+threadPhoneSQL+` ORDER BY m.created_at) AS previous_direction
+			FROM whatsapp_messages m
+			WHERE m.organization_id = $1 AND m.created_at >= $2
+		)
+		SELECT COUNT(r.answered_at), AVG(EXTRACT(EPOCH FROM r.answered_at - q.created_at)) / 3600
+		FROM msgs q
+		CROSS JOIN LATERAL (
+			SELECT MIN(a.created_at) AS answered_at
+			FROM msgs a
+			WHERE a.phone = q.phone AND a.direction = 'outbound' AND a.sent_by IS NOT NULL
+				AND a.created_at > q.created_at
+		) r
+		WHERE q.direction = 'inbound' AND q.previous_direction IS DISTINCT FROM 'inbound'
+	`, orgID, since).Scan(&answered, &hours)
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure response time: %w", err)
+	}
+	if answered > 0 && hours != nil {
+		samples[models.BenchmarkResponseTimeHours] = benchmarkSample{value: *hours, size: answered}
+	}
+
+	return samples, nil
+}
+
+// Refresh recomputes the metrics of a participating organization over the trailing window.
+// Metrics with too little activity are dropped rather than shared. Organizations that did not
+// opt in are left alone.
+func (s *BenchmarkService) Refresh(ctx context.Context, orgID uuid.UUID) error {
+	participation, err := s.GetParticipation(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if !participation.OptedIn {
+		return nil
+	}
+
+	end := time.Now().UTC()
+	start := end.AddDate(0, 0, -benchmarkWindowDays)
+	samples, err := s.measure(ctx, orgID, start)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, metric := range models.BenchmarkMetrics {
+		sample, ok := samples[metric]
+		if !ok || sample.size < benchmarkMinSamples[metric] {
+			if _, err := tx.Exec(ctx, `
+				DELETE FROM benchmark_metrics WHERE organization_id = $1 AND metric = $2
+			`, orgID, metric); err != nil {
+				return fmt.Errorf("failed to clear benchmark metric: %w", err)
+			}
+			continue
+		}
+		_, err := tx.Exec(ctx, `
+			INSERT INTO benchmark_metrics (organization_id, metric, value, sample_size, period_start, period_end)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (organization_id, metric) DO UPDATE SET
+				value = EXCLUDED.value, sample_size = EXCLUDED.sample_size,
+				period_start = EXCLUDED.period_start, period_end = EXCLUDED.period_end, computed_at = NOW()
+		`, orgID, metric, sample.value, sample.size, start, end)
+		if err != nil {
+			return fmt.Errorf("failed to save benchmark metric: %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// RefreshAll recomputes the metrics of every participating organization, returning how many were
+// refreshed. An organization that fails is logged and skipped.
+func (s *BenchmarkService) RefreshAll(ctx context.Context) (int, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT bp.organization_id
+		FROM benchmark_participants bp
+		JOIN organizations o ON o.id = bp.organization_id
+		WHERE o.deleted_at IS NULL
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list benchmark participants: %w", err)
+	}
+	var orgIDs []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan organization: %w", err)
+		}
+		orgIDs = append(orgIDs, id)
+	}
+	rows.Close()
+
+	refreshed := 0
+	for _, orgID := range orgIDs {
+		if err := s.Refresh(ctx, orgID); err != nil {
+			log.Printf("[Benchmark] Failed to refresh metrics of organization %s: %v", orgID, err)
+			continue
+		}
+		refreshed++
+	}
+	return refreshed, nil
+}
+
+// Report compares the organization's metrics to the percentile bands of all participants. Only
+// participants can see the report, and no other organization's value is ever returned.
+func (s *BenchmarkService) Report(ctx context.Context, orgID uuid.UUID) (*models.BenchmarkReport, error) {
+	participation, err := s.GetParticipation(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !participation.OptedIn {
+		return nil, errors.New("organization is not taking part in benchmarking")
+	}
+
+	report := &models.BenchmarkReport{Metrics: []*models.BenchmarkComparison{}}
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT MIN(period_start)::timestamptz, MAX(period_end)::timestamptz
+		FROM benchmark_metrics WHERE organization_id = $1
+	`, orgID).Scan(&report.PeriodStart, &report.PeriodEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get benchmark period: %w", err)
+	}
+
+	for _, metric := range models.BenchmarkMetrics {
+		comparison := &models.BenchmarkComparison{Metric: metric, LowerIsBetter: benchmarkLowerIsBetter[metric]}
+
+		var value float64
+		err := s.db.Pool.QueryRow(ctx, `
+			SELECT value, sample_size FROM benchmark_metrics WHERE organization_id = $1 AND metric = $2
+		`, orgID, metric).Scan(&value, &comparison.SampleSize)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("failed to get benchmark metric: %w", err)
+		}
+		if err == nil {
+			comparison.Value = &value
+		}
+
+		var values []float64
+		err = s.db.Pool.QueryRow(ctx, `
+			SELECT COALESCE(array_agg(bm.value), '{}')
+			FROM benchmark_metrics bm
+			JOIN organizations o ON o.id = bm.organization_id
+			WHERE bm.metric = $1 AND o.deleted_at IS NULL
+		`, metric).Scan(&values)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get benchmark values: %w", err)
+		}
+		comparison.Participants = len(values)
+		if len(values) >= benchmarkMinParticipants {
+			comparison.Bands = benchmarkBands(values)
+			if comparison.Value != nil {
+				percentile := benchmarkPercentile(values, value, comparison.LowerIsBetter)
+				comparison.Percentile = &percentile
+			}
+		}
+
+		report.Metrics = append(report.Metrics, comparison)
+	}
+
+	return report, nil
+}
+
+// benchmarkBands returns the percentile bands of the values, interpolating between the closest ranks
+func benchmarkBands(values []float64) *models.BenchmarkBands {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	at := func(p float64) float64 {
+		rank := p * float64(len(sorted)-1)
+		lower := int(math.Floor(rank))
+		upper := int(math.Ceil(rank))
+		v := sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+		return math.Round(v*100) / 100
+	}
+	return &models.BenchmarkBands{P10: at(0.10), P25: at(0.25), P50: at(0.50), P75: at(0.75), P90: at(0.90)}
+}
+
+// benchmarkPercentile is the share of the other participants that value does better than, counting
+// ties as half, from 0 to 100. values includes the organization's own value.
+func benchmarkPercentile(values []float64, value float64, lowerIsBetter bool) int {
+	if len(values) < 2 {
+		return 0
+	}
+	// The organization's own value ties with itself
+	better := -0.5
+	for _, v := range values {
+		switch {
+		case v == value:
+			better += 0.5
+		case lowerIsBetter && value < v, !lowerIsBetter && value > v:
+			better++
+		}
+	}
+	return int(math.Round(better * 100 / float64(len(values)-1)))
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/controlwise/backend/internal/models"
+)
+
+func TestBenchmarkBands(t *testing.T) {
+	got := benchmarkBands([]float64{50, 10, 40, 20, 30})
+	want := models.BenchmarkBands{P10: 14, P25: 20, P50: 30, P75: 40, P90: 46}
+	if got == nil || *got != want {
+		t.Errorf("benchmarkBands() = %+v, want %+v", got, want)
+	}
+	if benchmarkBands(nil) != nil {
+		t.Error("benchmarkBands(nil) should be nil")
+	}
+}
+
+func TestBenchmarkPercentile(t *testing.T) {
+	values := []float64{10, 20, 20, 30, 40}
+	tests := []struct {
+		name          string
+		value         float64
+		lowerIsBetter bool
+		want          int
+	}{
+		{"highest, higher is better", 40, false, 100},
+		{"highest, lower is better", 40, true, 0},
+		{"lowest, lower is better", 10, true, 100},
+		{"tied in the middle", 20, false, 38},
+		{"median, lower is better", 30, true, 25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := benchmarkPercentile(values, tt.value, tt.lowerIsBetter); got != tt.want {
+				t.Errorf("benchmarkPercentile(%v) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,514 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxSlotRangeDays limits how many days of booking slots can be requested at once
+const maxSlotRangeDays = 31
+
+// BookingService handles the appointments service catalogue and public booking slots
+type BookingService struct {
+	db          *database.DB
+	frontendURL string // base of the booking links handed to embedded widgets
+}
+
+// NewBookingService creates a new BookingService
+func NewBookingService(db *database.DB, frontendURL string) *BookingService {
+	return &BookingService{db: db, frontendURL: frontendURL}
+}
+
+// List returns the service catalogue of an organization
+func (s *BookingService) List(ctx context.Context, orgID uuid.UUID, activeOnly bool) ([]*models.BookableService, error) {
+	query := `
+		SELECT id, organization_id, name, description, duration_minutes, price_cents, session_type,
+		       is_public, is_active, position, created_at, updated_at
+		FROM bookable_services
+		WHERE organization_id = $1 AND deleted_at IS NULL
+	`
+	if activeOnly {
+		query += " AND is_active = true"
+	}
+	query += " ORDER BY position, name"
+
+	rows, err := s.db.Pool.Query(ctx, query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	defer rows.Close()
+
+	services := []*models.BookableService{}
+	byID := map[uuid.UUID]*models.BookableService{}
+	for rows.Next() {
+		var bs models.BookableService
+		if err := rows.Scan(
+			&bs.ID, &bs.OrganizationID, &bs.Name, &bs.Description, &bs.DurationMinutes, &bs.PriceCents,
+			&bs.SessionType, &bs.IsPublic, &bs.IsActive, &bs.Position, &bs.CreatedAt, &bs.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan service: %w", err)
+		}
+		bs.TherapistIDs = []uuid.UUID{}
+		services = append(services, &bs)
+		byID[bs.ID] = &bs
+	}
+	rows.Close()
+
+	// Attach eligible therapists
+	therapistRows, err := s.db.Pool.Query(ctx, `
+		SELECT st.service_id, st.therapist_id
+		FROM bookable_service_therapists st
+		JOIN bookable_services bs ON bs.id = st.service_id
+		WHERE bs.organization_id = $1 AND bs.deleted_at IS NULL
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service therapists: %w", err)
+	}
+	defer therapistRows.Close()
+
+	for therapistRows.Next() {
+		var serviceID, therapistID uuid.UUID
+		if err := therapistRows.Scan(&serviceID, &therapistID); err != nil {
+			return nil, fmt.Errorf("failed to scan service therapist: %w", err)
+		}
+		if bs, ok := byID[serviceID]; ok {
+			bs.TherapistIDs = append(bs.TherapistIDs, therapistID)
+		}
+	}
+
+	return services, nil
+}
+
+// GetByID returns a catalogue service with its eligible therapists
+func (s *BookingService) GetByID(ctx context.Context, id, orgID uuid.UUID) (*models.BookableService, error) {
+	return getBookableService(ctx, s.db, id, orgID)
+}
+
+// getBookableService loads a catalogue service; shared with SessionService to pre-fill sessions
+func getBookableService(ctx context.Context, db *database.DB, id, orgID uuid.UUID) (*models.BookableService, error) {
+	var bs models.BookableService
+	err := db.Pool.QueryRow(ctx, `
+		SELECT id, organization_id, name, description, duration_minutes, price_cents, session_type,
+		       is_public, is_active, position, created_at, updated_at
+		FROM bookable_services
+		WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`, id, orgID).Scan(
+		&bs.ID, &bs.OrganizationID, &bs.Name, &bs.Description, &bs.DurationMinutes, &bs.PriceCents,
+		&bs.SessionType, &bs.IsPublic, &bs.IsActive, &bs.Position, &bs.CreatedAt, &bs.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("service not found")
+		}
+		return nil, fmt.Errorf("failed to get service: %w", err)
+	}
+
+	rows, err := db.Pool.Query(ctx, `
+		SELECT therapist_id FROM bookable_service_therapists WHERE service_id = $1
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service therapists: %w", err)
+	}
+	defer rows.Close()
+
+	bs.TherapistIDs = []uuid.UUID{}
+	for rows.Next() {
+		var therapistID uuid.UUID
+		if err := rows.Scan(&therapistID); err != nil {
+			return nil, fmt.Errorf("failed to scan service therapist: %w", err)
+		}
+		bs.TherapistIDs = append(bs.TherapistIDs, therapistID)
+	}
+
+	return &bs, nil
+}
+
+// allowsTherapist reports whether the therapist can provide the service
+func allowsTherapist(bs *models.BookableService, therapistID uuid.UUID) bool {
+	if len(bs.TherapistIDs) == 0 {
+		return true
+	}
+	for _, id := range bs.TherapistIDs {
+		if id == therapistID {
+			return true
+		}
+	}
+	return false
+}
+
+// Create adds a service to the catalogue
+func (s *BookingService) Create(ctx context.Context, bs *models.BookableService) error {
+	if err := s.validate(ctx, bs); err != nil {
+		return err
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	bs.ID = uuid.New()
+	err = tx.QueryRow(ctx, `
+		INSERT INTO bookable_services (
+			id, organization_id, name, description, duration_minutes, price_cents, session_type,
+			is_public, is_active, position
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING created_at, updated_at
+	`, bs.ID, bs.OrganizationID, bs.Name, bs.Description, bs.DurationMinutes, bs.PriceCents,
+		bs.SessionType, bs.IsPublic, bs.IsActive, bs.Position,
+	).Scan(&bs.CreatedAt, &bs.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create service: %w", err)
+	}
+
+	if err := replaceServiceTherapists(ctx, tx, bs); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Update updates a catalogue service and replaces its eligible therapists
+func (s *BookingService) Update(ctx context.Context, bs *models.BookableService) error {
+	if err := s.validate(ctx, bs); err != nil {
+		return err
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		UPDATE bookable_services
+		SET name = $1, description = $2, duration_minutes = $3, price_cents = $4, session_type = $5,
+		    is_public = $6, is_active = $7, position = $8
+		WHERE id = $9 AND organization_id = $10 AND deleted_at IS NULL
+		RETURNING created_at, updated_at
+	`, bs.Name, bs.Description, bs.DurationMinutes, bs.PriceCents, bs.SessionType,
+		bs.IsPublic, bs.IsActive, bs.Position, bs.ID, bs.OrganizationID,
+	).Scan(&bs.CreatedAt, &bs.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("service not found")
+		}
+		return fmt.Errorf("failed to update service: %w", err)
+	}
+
+	if err := replaceServiceTherapists(ctx, tx, bs); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Delete soft deletes a catalogue service; sessions booked for it keep their details
+func (s *BookingService) Delete(ctx context.Context, id, orgID uuid.UUID) error {
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE bookable_services SET deleted_at = NOW()
+		WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to delete service: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("service not found")
+	}
+
+	return nil
+}
+
+func (s *BookingService) validate(ctx context.Context, bs *models.BookableService) error {
+	if bs.Name == "" {
+		return errors.New("name is required")
+	}
+	if bs.DurationMinutes <= 0 {
+		return errors.New("duration must be positive")
+	}
+	if bs.PriceCents < 0 {
+		return errors.New("price cannot be negative")
+	}
+	if bs.SessionType == "" {
+		bs.SessionType = models.SessionTypeRegular
+	}
+
+	if len(bs.TherapistIDs) > 0 {
+		var count int
+		err := s.db.Pool.QueryRow(ctx, `
+			SELECT COUNT(*) FROM therapists
+			WHERE id = ANY($1) AND organization_id = $2 AND deleted_at IS NULL
+		`, bs.TherapistIDs, bs.OrganizationID).Scan(&count)
+		if err != nil {
+			return fmt.Errorf("failed to check therapists: %w", err)
+		}
+		if count != len(bs.TherapistIDs) {
+			return errors.New("therapist not found")
+		}
+	}
+
+	return nil
+}
+
+func replaceServiceTherapists(ctx context.Context, tx pgx.Tx, bs *models.BookableService) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM bookable_service_therapists WHERE service_id = $1`, bs.ID); err != nil {
+		return fmt.Errorf("failed to update service therapists: %w", err)
+	}
+
+	for _, therapistID := range bs.TherapistIDs {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO bookable_service_therapists (service_id, therapist_id)
+			VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+		`, bs.ID, therapistID)
+		if err != nil {
+			return fmt.Errorf("failed to update service therapists: %w", err)
+		}
+	}
+
+	if bs.TherapistIDs == nil {
+		bs.TherapistIDs = []uuid.UUID{}
+	}
+	return nil
+}
+
+// ============ Public Booking ============
+
+// ListPublic returns the public services of an organization that offers online booking
+func (s *BookingService) ListPublic(ctx context.Context, orgID uuid.UUID) ([]models.PublicBookableService, error) {
+	if err := s.checkPublicBooking(ctx, orgID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, name, description, duration_minutes, price_cents
+		FROM bookable_services
+		WHERE organization_id = $1 AND is_public = true AND is_active = true AND deleted_at IS NULL
+		ORDER BY position, name
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	defer rows.Close()
+
+	services := []models.PublicBookableService{}
+	for rows.Next() {
+		var ps models.PublicBookableService
+		if err := rows.Scan(&ps.ID, &ps.Name, &ps.Description, &ps.DurationMinutes, &ps.PriceCents); err != nil {
+			return nil, fmt.Errorf("failed to scan service: %w", err)
+		}
+		services = append(services, ps)
+	}
+
+	return services, nil
+}
+
+// GetPublicSlots returns the free slots of a public service between from and to.
+// Slots follow each eligible therapist's working hours in steps of the service duration,
+// skipping existing sessions, absences and times in the past.
+func (s *BookingService) GetPublicSlots(ctx context.Context, orgID, serviceID uuid.UUID, from, to time.Time) ([]models.BookingSlot, error) {
+	if err := s.checkPublicBooking(ctx, orgID); err != nil {
+		return nil, err
+	}
+
+	bs, err := getBookableService(ctx, s.db, serviceID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !bs.IsPublic || !bs.IsActive {
+		return nil, errors.New("service not found")
+	}
+
+	return s.slots(ctx, bs, from, to)
+}
+
+// checkPublicBooking verifies the organization is active and has the appointments module enabled
+func (s *BookingService) checkPublicBooking(ctx context.Context, orgID uuid.UUID) error {
+	var enabled bool
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM organizations o
+			JOIN organization_modules om ON om.organization_id = o.id
+			WHERE o.id = $1 AND o.is_active = true AND o.deleted_at IS NULL
+			AND om.module_name = $2 AND om.is_enabled = true
+		)
+	`, orgID, models.ModuleAppointments).Scan(&enabled)
+	if err != nil {
+		return fmt.Errorf("failed to check organization: %w", err)
+	}
+	if !enabled {
+		return errors.New("organization not found")
+	}
+	return nil
+}
+
+type busyInterval struct {
+	start, end time.Time
+}
+
+func (s *BookingService) slots(ctx context.Context, bs *models.BookableService, from, to time.Time) ([]models.BookingSlot, error) {
+	if !to.After(from) {
+		return nil, errors.New("end date must be after start date")
+	}
+	if to.Sub(from) > maxSlotRangeDays*24*time.Hour {
+		return nil, fmt.Errorf("date range cannot exceed %d days", maxSlotRangeDays)
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, user_id, name, working_hours, timezone
+		FROM therapists
+		WHERE organization_id = $1 AND is_active = true AND deleted_at IS NULL
+		ORDER BY name
+	`, bs.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query therapists: %w", err)
+	}
+
+	var therapists []*models.Therapist
+	for rows.Next() {
+		var t models.Therapist
+		if err := rows.Scan(&t.ID, &t.UserID, &t.Name, &t.WorkingHours, &t.Timezone); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan therapist: %w", err)
+		}
+		if allowsTherapist(bs, t.ID) {
+			therapists = append(therapists, &t)
+		}
+	}
+	rows.Close()
+
+	if len(therapists) == 0 {
+		return []models.BookingSlot{}, nil
+	}
+
+	busy, err := busyIntervals(ctx, s.db, bs.OrganizationID, therapists, from, to, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	duration := time.Duration(bs.DurationMinutes) * time.Minute
+	now := time.Now()
+	slots := []models.BookingSlot{}
+
+	for _, t := range therapists {
+		slots = append(slots, therapistSlots(t, busy[t.ID], from, to, duration, now)...)
+	}
+
+	sort.SliceStable(slots, func(i, j int) bool {
+		return slots[i].Start.Before(slots[j].Start)
+	})
+
+	return slots, nil
+}
+
+// therapistSlots returns the free slots of a therapist between from and to, following their working
+// hours in steps of the duration and skipping busy intervals and times up to now
+func therapistSlots(t *models.Therapist, busy []busyInterval, from, to time.Time, duration time.Duration, now time.Time) []models.BookingSlot {
+	var slots []models.BookingSlot
+	loc := therapistLocation(t)
+	for day := from.In(loc); day.Before(to); day = day.AddDate(0, 0, 1) {
+		dayStart, dayEnd, err := workingWindow(t, day)
+		if err != nil || dayStart.IsZero() {
+			continue
+		}
+
+		for start := dayStart; !start.Add(duration).After(dayEnd); start = start.Add(duration) {
+			end := start.Add(duration)
+			if start.Before(from) || end.After(to) || !start.After(now) {
+				continue
+			}
+			if overlapsAny(busy, start, end) {
+				continue
+			}
+			slots = append(slots, models.BookingSlot{
+				Start:         start,
+				End:           end,
+				TherapistID:   t.ID,
+				TherapistName: t.Name,
+			})
+		}
+	}
+	return slots
+}
+
+// busyIntervals returns the booked sessions and absences of the therapists, keyed by therapist.
+// excludeSessionID leaves out a session being moved.
+func busyIntervals(ctx context.Context, db *database.DB, orgID uuid.UUID, therapists []*models.Therapist, from, to time.Time, excludeSessionID *uuid.UUID) (map[uuid.UUID][]busyInterval, error) {
+	ids := make([]uuid.UUID, 0, len(therapists))
+	for _, t := range therapists {
+		ids = append(ids, t.ID)
+	}
+
+	busy := map[uuid.UUID][]busyInterval{}
+
+	rows, err := db.Pool.Query(ctx, `
+		SELECT therapist_id, scheduled_at, scheduled_at + (duration_minutes * interval '1 minute')
+		FROM sessions
+		WHERE organization_id = $1 AND therapist_id = ANY($2) AND deleted_at IS NULL
+		AND status != 'cancelled'
+		AND scheduled_at < $4 AND scheduled_at + (duration_minutes * interval '1 minute') > $3
+		AND ($5::uuid IS NULL OR id != $5)
+	`, orgID, ids, from, to, excludeSessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions: %w", err)
+	}
+	for rows.Next() {
+		var therapistID uuid.UUID
+		var b busyInterval
+		if err := rows.Scan(&therapistID, &b.start, &b.end); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		busy[therapistID] = append(busy[therapistID], b)
+	}
+	rows.Close()
+
+	rows, err = db.Pool.Query(ctx, `
+		SELECT t.id, o.starts_at, o.ends_at
+		FROM user_out_of_office o
+		JOIN therapists t ON t.user_id = o.user_id
+		WHERE o.organization_id = $1 AND t.id = ANY($2) AND o.is_active = true
+		AND o.starts_at < $4 AND o.ends_at > $3
+	`, orgID, ids, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query absences: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var therapistID uuid.UUID
+		var b busyInterval
+		if err := rows.Scan(&therapistID, &b.start, &b.end); err != nil {
+			return nil, fmt.Errorf("failed to scan absence: %w", err)
+		}
+		busy[therapistID] = append(busy[therapistID], b)
+	}
+
+	return busy, nil
+}
+
+func overlapsAny(intervals []busyInterval, start, end time.Time) bool {
+	for _, b := range intervals {
+		if b.start.Before(end) && b.end.After(start) {
+			return true
+		}
+	}
+	return false
+}
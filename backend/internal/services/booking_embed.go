@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxEmbedRangeDays limits how many days of availability a booking widget can request at once
+const maxEmbedRangeDays = 14
+
+// maxEmbedOrigins limits how many websites an organization can allow to embed its availability
+const maxEmbedOrigins = 20
+
+// ============ Embeddable Availability ============
+
+// GetEmbedAvailability returns the public services of an organization with their free slots
+// between from and to, for booking widgets on customer websites. Every service and slot carries
+// a link to the public booking page, where the booking itself is made.
+func (s *BookingService) GetEmbedAvailability(ctx context.Context, orgID uuid.UUID, serviceID *uuid.UUID, from, to time.Time) (*models.EmbedAvailability, error) {
+	if !to.After(from) {
+		return nil, errors.New("end date must be after start date")
+	}
+	if to.Sub(from) > maxEmbedRangeDays*24*time.Hour {
+		return nil, fmt.Errorf("date range cannot exceed %d days", maxEmbedRangeDays)
+	}
+
+	public, err := s.ListPublic(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	availability := &models.EmbedAvailability{
+		OrganizationID: orgID,
+		From:           from,
+		To:             to,
+		Services:       []models.EmbedService{},
+	}
+	if err := s.db.Pool.QueryRow(ctx, `SELECT name FROM organizations WHERE id = $1`, orgID).Scan(&availability.OrganizationName); err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	for _, ps := range public {
+		if serviceID != nil && ps.ID != *serviceID {
+			continue
+		}
+
+		bs, err := getBookableService(ctx, s.db, ps.ID, orgID)
+		if err != nil {
+			return nil, err
+		}
+		slots, err := s.slots(ctx, bs, from, to)
+		if err != nil {
+			return nil, err
+		}
+
+		service := models.EmbedService{
+			PublicBookableService: ps,
+			BookingURL:            s.bookingURL(orgID, ps.ID, nil),
+			Slots:                 make([]models.EmbedSlot, 0, len(slots)),
+		}
+		for _, slot := range slots {
+			service.Slots = append(service.Slots, models.EmbedSlot{
+				BookingSlot: slot,
+				BookingURL:  s.bookingURL(orgID, ps.ID, &slot),
+			})
+		}
+		availability.Services = append(availability.Services, service)
+	}
+
+	if serviceID != nil && len(availability.Services) == 0 {
+		return nil, errors.New("service not found")
+	}
+
+	return availability, nil
+}
+
+// bookingURL links to the public booking page, preselecting the service and, when given, the slot
+func (s *BookingService) bookingURL(orgID, serviceID uuid.UUID, slot *models.BookingSlot) string {
+	query := url.Values{}
+	query.Set("service_id", serviceID.String())
+	if slot != nil {
+		query.Set("therapist_id", slot.TherapistID.String())
+		query.Set("start", slot.Start.UTC().Format(time.RFC3339))
+	}
+	return fmt.Sprintf("%s/book/%s?%s", strings.TrimRight(s.frontendURL, "/"), orgID, query.Encode())
+}
+
+// EmbedAllowedOrigins returns the websites allowed to embed the organization's availability.
+// An empty list allows any website.
+func (s *BookingService) EmbedAllowedOrigins(ctx context.Context, orgID uuid.UUID) ([]string, error) {
+	var origins []string
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT booking_embed_origins FROM organizations
+		WHERE id = $1 AND deleted_at IS NULL
+	`, orgID).Scan(&origins)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("organization not found")
+		}
+		return nil, fmt.Errorf("failed to get embed origins: %w", err)
+	}
+	return origins, nil
+}
+
+// EmbedOriginAllowed reports whether a website may embed the organization's availability.
+// Requests without an Origin header (e.g. server-side fetches) are always allowed.
+func EmbedOriginAllowed(allowed []string, origin string) bool {
+	if len(allowed) == 0 || origin == "" {
+		return true
+	}
+	for _, o := range allowed {
+		if strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetEmbedSettings returns the embed settings with the HTML snippet for the organization's website.
+// scriptURL is where the widget script is served from.
+func (s *BookingService) GetEmbedSettings(ctx context.Context, orgID uuid.UUID, scriptURL string) (*models.BookingEmbedSettings, error) {
+	origins, err := s.EmbedAllowedOrigins(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.BookingEmbedSettings{
+		AllowedOrigins: origins,
+		Snippet:        embedSnippet(orgID, scriptURL),
+	}, nil
+}
+
+// UpdateEmbedSettings replaces the websites allowed to embed the organization's availability
+func (s *BookingService) UpdateEmbedSettings(ctx context.Context, orgID uuid.UUID, origins []string, scriptURL string) (*models.BookingEmbedSettings, error) {
+	if len(origins) > maxEmbedOrigins {
+		return nil, fmt.Errorf("at most %d websites can be allowed", maxEmbedOrigins)
+	}
+
+	normalized := make([]string, 0, len(origins))
+	seen := map[string]bool{}
+	for _, raw := range origins {
+		origin, err := normalizeOrigin(raw)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[origin] {
+			seen[origin] = true
+			normalized = append(normalized, origin)
+		}
+	}
+
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE organizations SET booking_embed_origins = $2, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`, orgID, normalized)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update embed origins: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, errors.New("organization not found")
+	}
+
+	return &models.BookingEmbedSettings{
+		AllowedOrigins: normalized,
+		Snippet:        embedSnippet(orgID, scriptURL),
+	}, nil
+}
+
+// normalizeOrigin reduces a website address to the scheme://host[:port] form browsers send as Origin
+func normalizeOrigin(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid website address: %s", raw)
+	}
+	return strings.ToLower(u.Scheme + "://" + u.Host), nil
+}
+
+// embedSnippet is the HTML a customer pastes on their website to show the booking widget
+func embedSnippet(orgID uuid.UUID, scriptURL string) string {
+	return fmt.Sprintf(`<div id="controlwise-booking"></div>
+<script src="%s" data-organization="%s" data-target="controlwise-booking" async></script>`,
+		html.EscapeString(scriptURL), orgID)
+}
@@ -0,0 +1,713 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/i18n"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// BudgetService handles budget operations
+type BudgetService struct {
+	db           *database.DB
+	storage      *StorageService
+	notification *NotificationService
+	workflow     *WorkflowService
+	frontendURL  string // base of the client portal links
+}
+
+func NewBudgetService(db *database.DB, storage *StorageService, notification *NotificationService, frontendURL string) *BudgetService {
+	return &BudgetService{
+		db:           db,
+		storage:      storage,
+		notification: notification,
+		frontendURL:  frontendURL,
+	}
+}
+
+// SetWorkflowService sets the workflow service for triggering workflow actions
+func (s *BudgetService) SetWorkflowService(ws *WorkflowService) {
+	s.workflow = ws
+}
+
+// Create creates a draft budget for an approved worksheet with its priced items. The budget
+// number is allocated from the organization's budget sequence in the same transaction.
+func (s *BudgetService) Create(ctx context.Context, budget *models.Budget, items []*models.BudgetItem) error {
+	if budget.WorkSheetID == uuid.Nil {
+		return errors.New("worksheet is required")
+	}
+	if budget.ValidUntil.IsZero() {
+		return errors.New("valid until date is required")
+	}
+	for _, item := range items {
+		if item.Description == "" || item.Unit == "" {
+			return errors.New("budget items need a description and unit")
+		}
+		if item.Quantity <= 0 || item.UnitPrice.IsNegative() || item.Tax.IsNegative() {
+			return errors.New("budget items need a positive quantity and non-negative prices")
+		}
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var worksheetStatus models.WorkSheetStatus
+	err = tx.QueryRow(ctx, `
+		SELECT status FROM worksheets
+		WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`, budget.WorkSheetID, budget.OrganizationID).Scan(&worksheetStatus)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("worksheet not found")
+		}
+		return fmt.Errorf("failed to get worksheet: %w", err)
+	}
+	if worksheetStatus != models.WorkSheetStatusApproved {
+		return errors.New("only approved worksheets can be budgeted")
+	}
+
+	budgetNumber, err := nextBudgetNumber(ctx, tx, budget.OrganizationID, time.Now().Year())
+	if err != nil {
+		return err
+	}
+
+	budget.ID = uuid.New()
+	budget.BudgetNumber = budgetNumber
+	budget.Status = models.BudgetStatusDraft
+	_, err = tx.Exec(ctx, `
+		INSERT INTO budgets (id, organization_id, worksheet_id, budget_number, status, valid_until, notes, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, budget.ID, budget.OrganizationID, budget.WorkSheetID, budget.BudgetNumber, budget.Status,
+		budget.ValidUntil, budget.Notes, budget.CreatedBy)
+	if err != nil {
+		return fmt.Errorf("failed to create budget: %w", err)
+	}
+
+	for i, item := range items {
+		if item.WorkSheetItemID != nil {
+			var exists bool
+			err := tx.QueryRow(ctx, `
+				SELECT EXISTS(SELECT 1 FROM worksheet_items WHERE id = $1 AND worksheet_id = $2 AND deleted_at IS NULL)
+			`, *item.WorkSheetItemID, budget.WorkSheetID).Scan(&exists)
+			if err != nil {
+				return fmt.Errorf("failed to verify worksheet item: %w", err)
+			}
+			if !exists {
+				return errors.New("worksheet item not found")
+			}
+		}
+
+		item.ID = uuid.New()
+		item.BudgetID = budget.ID
+		item.Order = i
+		item.Total = item.UnitPrice.Mul(decimal.NewFromFloat(item.Quantity)).Add(item.Tax).Round(2)
+		_, err = tx.Exec(ctx, `
+			INSERT INTO budget_items (id, budget_id, worksheet_item_id, description, quantity, unit, unit_price, tax, total, "order")
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`, item.ID, item.BudgetID, item.WorkSheetItemID, item.Description, item.Quantity, item.Unit,
+			item.UnitPrice, item.Tax, item.Total, item.Order)
+		if err != nil {
+			return fmt.Errorf("failed to create budget item: %w", err)
+		}
+	}
+
+	if err := recalculateBudgetTotals(ctx, tx, budget.ID); err != nil {
+		return err
+	}
+	err = tx.QueryRow(ctx, `
+		SELECT subtotal, tax, total, created_at, updated_at FROM budgets WHERE id = $1
+	`, budget.ID).Scan(&budget.Subtotal, &budget.Tax, &budget.Total, &budget.CreatedAt, &budget.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to get budget: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Send sends a draft budget to the client. Budgets matching an active approval rule
+// are held in pending_internal_approval until every required role has signed off.
+// A sent budget can be sent again once it has changed; each send that changes the budget
+// records its next revision.
+func (s *BudgetService) Send(ctx context.Context, id, orgID, userID uuid.UUID) (models.BudgetStatus, error) {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status models.BudgetStatus
+	var total decimal.Decimal
+	err = tx.QueryRow(ctx, `
+		SELECT status, total FROM budgets
+		WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+		FOR UPDATE
+	`, id, orgID).Scan(&status, &total)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", errors.New("budget not found")
+		}
+		return "", fmt.Errorf("failed to get budget: %w", err)
+	}
+
+	if status != models.BudgetStatusDraft && status != models.BudgetStatusSent {
+		return "", errors.New("only draft or sent budgets can be sent")
+	}
+
+	revised, err := recordBudgetRevision(ctx, tx, orgID, id, userID)
+	if err != nil {
+		return "", err
+	}
+	if status == models.BudgetStatusSent && !revised {
+		return "", errors.New("budget has not changed since it was sent")
+	}
+
+	rules, err := s.matchingApprovalRules(ctx, tx, orgID, total)
+	if err != nil {
+		return "", err
+	}
+
+	newStatus := models.BudgetStatusSent
+	if len(rules) > 0 {
+		newStatus = models.BudgetStatusPendingInternalApproval
+		if err := s.requestInternalApproval(ctx, tx, orgID, id, userID, total, rules); err != nil {
+			return "", err
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE budgets SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2
+		`, newStatus, id)
+	} else {
+		err = markBudgetSent(ctx, tx, id)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to update budget status: %w", err)
+	}
+
+	if err := recordBudgetPrices(ctx, tx, orgID, id); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.onStateChange(ctx, orgID, id, status, newStatus)
+
+	return newStatus, nil
+}
+
+// recalculateBudgetTotals sets the budget subtotal, tax and total from its items
+func recalculateBudgetTotals(ctx context.Context, tx pgx.Tx, budgetID uuid.UUID) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE budgets b
+		SET subtotal = t.subtotal, tax = t.tax, total = t.total, updated_at = CURRENT_TIMESTAMP
+		FROM (
+			SELECT COALESCE(SUM(total - tax), 0) AS subtotal, COALESCE(SUM(tax), 0) AS tax, COALESCE(SUM(total), 0) AS total
+			FROM budget_items
+			WHERE budget_id = $1 AND deleted_at IS NULL
+		) t
+		WHERE b.id = $1
+	`, budgetID)
+	if err != nil {
+		return fmt.Errorf("failed to update budget totals: %w", err)
+	}
+	return nil
+}
+
+// Reject records the client's rejection of a sent budget with a structured loss reason
+func (s *BudgetService) Reject(ctx context.Context, id, orgID, userID uuid.UUID, reason models.LossReason, notes *string) error {
+	if !reason.IsValid() {
+		return fmt.Errorf("invalid loss reason: %s", reason)
+	}
+
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE budgets
+		SET status = $1, rejected_at = CURRENT_TIMESTAMP, rejection_notes = $2,
+			loss_reason = $3, loss_notes = $2, loss_recorded_by = $4, loss_recorded_at = NOW(),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5 AND organization_id = $6 AND status = $7 AND deleted_at IS NULL
+	`, models.BudgetStatusRejected, notes, reason, userID, id, orgID, models.BudgetStatusSent)
+	if err != nil {
+		return fmt.Errorf("failed to reject budget: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("budget not found or not awaiting a client decision")
+	}
+
+	s.onStateChange(ctx, orgID, id, models.BudgetStatusSent, models.BudgetStatusRejected)
+
+	return nil
+}
+
+// RecordLossReason sets or corrects the loss reason of a rejected or expired budget
+func (s *BudgetService) RecordLossReason(ctx context.Context, id, orgID, userID uuid.UUID, reason models.LossReason, notes *string) error {
+	if !reason.IsValid() {
+		return fmt.Errorf("invalid loss reason: %s", reason)
+	}
+
+	var status models.BudgetStatus
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT status FROM budgets
+		WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`, id, orgID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("budget not found")
+		}
+		return fmt.Errorf("failed to get budget: %w", err)
+	}
+
+	if status != models.BudgetStatusRejected && status != models.BudgetStatusExpired {
+		return errors.New("loss reasons can only be recorded for rejected or expired budgets")
+	}
+
+	_, err = s.db.Pool.Exec(ctx, `
+		UPDATE budgets
+		SET loss_reason = $1, loss_notes = $2, loss_recorded_by = $3, loss_recorded_at = NOW()
+		WHERE id = $4
+	`, reason, notes, userID, id)
+	if err != nil {
+		return fmt.Errorf("failed to record loss reason: %w", err)
+	}
+
+	return nil
+}
+
+// onStateChange triggers workflow actions for a budget status change without failing the caller
+func (s *BudgetService) onStateChange(ctx context.Context, orgID, budgetID uuid.UUID, from, to models.BudgetStatus) {
+	if s.workflow != nil {
+		if err := s.workflow.OnBudgetStateChange(ctx, orgID, budgetID, string(from), string(to)); err != nil {
+			fmt.Printf("Failed to trigger workflow: %v\n", err)
+		}
+	}
+}
+
+// ============ Internal Approval Rules ============
+
+// ListApprovalRules returns the internal approval rules of an organization
+func (s *BudgetService) ListApprovalRules(ctx context.Context, orgID uuid.UUID) ([]*models.BudgetApprovalRule, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, organization_id, name, min_total, required_role, is_active, created_at, updated_at
+		FROM budget_approval_rules
+		WHERE organization_id = $1
+		ORDER BY min_total, required_role
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query approval rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*models.BudgetApprovalRule
+	for rows.Next() {
+		var r models.BudgetApprovalRule
+		if err := rows.Scan(&r.ID, &r.OrganizationID, &r.Name, &r.MinTotal, &r.RequiredRole, &r.IsActive, &r.CreatedAt, &r.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan approval rule: %w", err)
+		}
+		rules = append(rules, &r)
+	}
+
+	return rules, nil
+}
+
+// CreateApprovalRule creates an internal approval rule
+func (s *BudgetService) CreateApprovalRule(ctx context.Context, rule *models.BudgetApprovalRule) error {
+	if err := validateApprovalRule(rule); err != nil {
+		return err
+	}
+
+	rule.IsActive = true
+	err := s.db.Pool.QueryRow(ctx, `
+		INSERT INTO budget_approval_rules (organization_id, name, min_total, required_role, is_active)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`, rule.OrganizationID, rule.Name, rule.MinTotal, rule.RequiredRole, rule.IsActive).Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create approval rule: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateApprovalRule updates an internal approval rule; budgets already awaiting sign-off are unchanged
+func (s *BudgetService) UpdateApprovalRule(ctx context.Context, id, orgID uuid.UUID, rule *models.BudgetApprovalRule) error {
+	if err := validateApprovalRule(rule); err != nil {
+		return err
+	}
+
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE budget_approval_rules
+		SET name = $1, min_total = $2, required_role = $3, is_active = $4
+		WHERE id = $5 AND organization_id = $6
+	`, rule.Name, rule.MinTotal, rule.RequiredRole, rule.IsActive, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to update approval rule: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("approval rule not found")
+	}
+
+	return nil
+}
+
+// DeleteApprovalRule deletes an internal approval rule
+func (s *BudgetService) DeleteApprovalRule(ctx context.Context, id, orgID uuid.UUID) error {
+	result, err := s.db.Pool.Exec(ctx, `
+		DELETE FROM budget_approval_rules WHERE id = $1 AND organization_id = $2
+	`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to delete approval rule: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("approval rule not found")
+	}
+	return nil
+}
+
+// validateApprovalRule checks a rule before saving
+func validateApprovalRule(rule *models.BudgetApprovalRule) error {
+	if rule.Name == "" {
+		return errors.New("rule name is required")
+	}
+	if rule.MinTotal.IsNegative() {
+		return errors.New("minimum total cannot be negative")
+	}
+	switch rule.RequiredRole {
+	case models.RoleAdmin, models.RoleManager, models.RoleAccountant:
+	default:
+		return fmt.Errorf("invalid required role: %s", rule.RequiredRole)
+	}
+	return nil
+}
+
+// matchingApprovalRules returns the active rules that apply to a budget total, one per role
+func (s *BudgetService) matchingApprovalRules(ctx context.Context, tx pgx.Tx, orgID uuid.UUID, total decimal.Decimal) ([]*models.BudgetApprovalRule, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT DISTINCT ON (required_role) id, name, min_total, required_role
+		FROM budget_approval_rules
+		WHERE organization_id = $1 AND is_active = true AND min_total <= $2
+		ORDER BY required_role, min_total DESC
+	`, orgID, total)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query approval rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*models.BudgetApprovalRule
+	for rows.Next() {
+		var r models.BudgetApprovalRule
+		if err := rows.Scan(&r.ID, &r.Name, &r.MinTotal, &r.RequiredRole); err != nil {
+			return nil, fmt.Errorf("failed to scan approval rule: %w", err)
+		}
+		rules = append(rules, &r)
+	}
+
+	return rules, nil
+}
+
+// ============ Internal Approval ============
+
+// requestInternalApproval creates one sign-off request per required role and records the submission
+func (s *BudgetService) requestInternalApproval(ctx context.Context, tx pgx.Tx, orgID, budgetID, userID uuid.UUID, total decimal.Decimal, rules []*models.BudgetApprovalRule) error {
+	roles := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO budget_internal_approvals (budget_id, rule_id, required_role, requested_by)
+			VALUES ($1, $2, $3, $4)
+		`, budgetID, rule.ID, rule.RequiredRole, userID)
+		if err != nil {
+			return fmt.Errorf("failed to create internal approval: %w", err)
+		}
+		roles = append(roles, string(rule.RequiredRole))
+	}
+
+	return recordBudgetApprovalAudit(ctx, tx, orgID, budgetID, nil, &userID, models.BudgetApprovalActionSubmitted, nil, map[string]interface{}{
+		"total":          total.StringFixed(2),
+		"required_roles": roles,
+	})
+}
+
+// ListInternalApprovals returns the sign-off requests of a budget, most recent first
+func (s *BudgetService) ListInternalApprovals(ctx context.Context, budgetID, orgID uuid.UUID) ([]*models.BudgetInternalApproval, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT a.id, a.budget_id, a.rule_id, a.required_role, a.status, a.requested_by,
+			a.decided_by, a.decided_at, a.comment, a.created_at, a.updated_at
+		FROM budget_internal_approvals a
+		JOIN budgets b ON b.id = a.budget_id
+		WHERE a.budget_id = $1 AND b.organization_id = $2
+		ORDER BY a.created_at DESC, a.required_role
+	`, budgetID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query internal approvals: %w", err)
+	}
+	defer rows.Close()
+
+	var approvals []*models.BudgetInternalApproval
+	for rows.Next() {
+		var a models.BudgetInternalApproval
+		if err := rows.Scan(
+			&a.ID, &a.BudgetID, &a.RuleID, &a.RequiredRole, &a.Status, &a.RequestedBy,
+			&a.DecidedBy, &a.DecidedAt, &a.Comment, &a.CreatedAt, &a.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan internal approval: %w", err)
+		}
+		approvals = append(approvals, &a)
+	}
+
+	return approvals, nil
+}
+
+// ListApprovalAudit returns the internal approval audit trail of a budget
+func (s *BudgetService) ListApprovalAudit(ctx context.Context, budgetID, orgID uuid.UUID) ([]*models.BudgetApprovalAudit, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT a.id, a.organization_id, a.budget_id, a.approval_id, a.user_id,
+			NULLIF(TRIM(CONCAT(u.first_name, ' ', u.last_name)), '') as user_name,
+			a.action, a.comment, a.details, a.created_at
+		FROM budget_approval_audit a
+		LEFT JOIN users u ON u.id = a.user_id
+		WHERE a.budget_id = $1 AND a.organization_id = $2
+		ORDER BY a.created_at
+	`, budgetID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query approval audit: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.BudgetApprovalAudit
+	for rows.Next() {
+		var e models.BudgetApprovalAudit
+		if err := rows.Scan(
+			&e.ID, &e.OrganizationID, &e.BudgetID, &e.ApprovalID, &e.UserID,
+			&e.UserName, &e.Action, &e.Comment, &e.Details, &e.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan approval audit: %w", err)
+		}
+		entries = append(entries, &e)
+	}
+
+	return entries, nil
+}
+
+// ApproveInternal signs off a budget for the user's role. Once every required role has
+// signed off the budget is released and sent to the client.
+// Administrators may sign off on behalf of any role, and delegates on behalf of the approvers
+// whose approval request was rerouted to them.
+func (s *BudgetService) ApproveInternal(ctx context.Context, budgetID, orgID, userID uuid.UUID, role models.Role, comment *string) (models.BudgetStatus, error) {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	approvalID, err := s.lockPendingApproval(ctx, tx, budgetID, orgID, userID, role)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE budget_internal_approvals
+		SET status = 'approved', decided_by = $1, decided_at = NOW(), comment = $2
+		WHERE id = $3
+	`, userID, comment, approvalID)
+	if err != nil {
+		return "", fmt.Errorf("failed to approve budget: %w", err)
+	}
+
+	if err := recordBudgetApprovalAudit(ctx, tx, orgID, budgetID, &approvalID, &userID, models.BudgetApprovalActionApproved, comment, nil); err != nil {
+		return "", err
+	}
+
+	var remaining int
+	err = tx.QueryRow(ctx, `
+		SELECT COUNT(*) FROM budget_internal_approvals WHERE budget_id = $1 AND status = 'pending'
+	`, budgetID).Scan(&remaining)
+	if err != nil {
+		return "", fmt.Errorf("failed to count pending approvals: %w", err)
+	}
+
+	newStatus := models.BudgetStatusPendingInternalApproval
+	if remaining == 0 {
+		newStatus = models.BudgetStatusSent
+		if err := markBudgetSent(ctx, tx, budgetID); err != nil {
+			return "", fmt.Errorf("failed to update budget status: %w", err)
+		}
+		if err := recordBudgetApprovalAudit(ctx, tx, orgID, budgetID, nil, &userID, models.BudgetApprovalActionReleased, nil, nil); err != nil {
+			return "", err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if newStatus == models.BudgetStatusSent {
+		s.onStateChange(ctx, orgID, budgetID, models.BudgetStatusPendingInternalApproval, newStatus)
+	}
+
+	return newStatus, nil
+}
+
+// RejectInternal rejects the sign-off for the user's role and returns the budget to draft.
+// A comment explaining the rejection is mandatory.
+func (s *BudgetService) RejectInternal(ctx context.Context, budgetID, orgID, userID uuid.UUID, role models.Role, comment string) error {
+	if strings.TrimSpace(comment) == "" {
+		return errors.New("a comment is required to reject a budget")
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	approvalID, err := s.lockPendingApproval(ctx, tx, budgetID, orgID, userID, role)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE budget_internal_approvals
+		SET status = 'rejected', decided_by = $1, decided_at = NOW(), comment = $2
+		WHERE id = $3
+	`, userID, comment, approvalID)
+	if err != nil {
+		return fmt.Errorf("failed to reject budget: %w", err)
+	}
+
+	// The remaining requests of this round are no longer needed
+	_, err = tx.Exec(ctx, `
+		UPDATE budget_internal_approvals SET status = 'cancelled'
+		WHERE budget_id = $1 AND status = 'pending'
+	`, budgetID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel pending approvals: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE budgets SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2
+	`, models.BudgetStatusDraft, budgetID)
+	if err != nil {
+		return fmt.Errorf("failed to update budget status: %w", err)
+	}
+
+	if err := recordBudgetApprovalAudit(ctx, tx, orgID, budgetID, &approvalID, &userID, models.BudgetApprovalActionRejected, &comment, nil); err != nil {
+		return err
+	}
+
+	var createdBy uuid.UUID
+	var budgetNumber string
+	if err := tx.QueryRow(ctx, `SELECT created_by, budget_number FROM budgets WHERE id = $1`, budgetID).Scan(&createdBy, &budgetNumber); err != nil {
+		return fmt.Errorf("failed to get budget: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// Let the author know the budget needs changes
+	if s.notification != nil {
+		locale := i18n.FromContext(ctx)
+		entityType := "budget"
+		if err := s.notification.Create(ctx, &models.Notification{
+			UserID:     createdBy,
+			Type:       models.NotificationTypeApprovalDecision,
+			Title:      fmt.Sprintf(i18n.T(locale, "Budget %s was not approved internally"), budgetNumber),
+			Message:    comment,
+			EntityType: &entityType,
+			EntityID:   &budgetID,
+		}); err != nil {
+			fmt.Printf("Failed to create notification: %v\n", err)
+		}
+	}
+
+	s.onStateChange(ctx, orgID, budgetID, models.BudgetStatusPendingInternalApproval, models.BudgetStatusDraft)
+
+	return nil
+}
+
+// lockPendingApproval finds and locks the pending sign-off request the user may decide on
+func (s *BudgetService) lockPendingApproval(ctx context.Context, tx pgx.Tx, budgetID, orgID, userID uuid.UUID, role models.Role) (uuid.UUID, error) {
+	var status models.BudgetStatus
+	var createdBy uuid.UUID
+	err := tx.QueryRow(ctx, `
+		SELECT status, created_by FROM budgets
+		WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+		FOR UPDATE
+	`, budgetID, orgID).Scan(&status, &createdBy)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, errors.New("budget not found")
+		}
+		return uuid.Nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+
+	if status != models.BudgetStatusPendingInternalApproval {
+		return uuid.Nil, errors.New("budget is not awaiting internal approval")
+	}
+	if createdBy == userID {
+		return uuid.Nil, errors.New("you cannot sign off a budget you created")
+	}
+
+	// Prefer the request for the user's own role, then one rerouted to the user as the delegate of
+	// an approver with the required role since it was requested; administrators can decide any
+	var approvalID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		SELECT a.id FROM budget_internal_approvals a
+		LEFT JOIN LATERAL (
+			SELECT true AS delegated
+			FROM delegation_audit_log l
+			JOIN users u ON u.id = l.original_user_id
+			WHERE l.organization_id = $3 AND l.delegate_user_id = $4 AND l.delegation_type = $5
+				AND l.entity_type = 'budget' AND l.entity_id = a.budget_id
+				AND l.created_at >= a.created_at AND u.role = a.required_role
+			LIMIT 1
+		) d ON true
+		WHERE a.budget_id = $1 AND a.status = 'pending'
+			AND (a.required_role = $2 OR d.delegated OR $2 = 'admin')
+		ORDER BY (a.required_role = $2) DESC, d.delegated IS NOT NULL DESC, a.created_at
+		LIMIT 1
+		FOR UPDATE OF a
+	`, budgetID, role, orgID, userID, models.DelegationTypeApproval).Scan(&approvalID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, errors.New("no pending internal approval for your role")
+		}
+		return uuid.Nil, fmt.Errorf("failed to get internal approval: %w", err)
+	}
+
+	return approvalID, nil
+}
+
+// recordBudgetApprovalAudit writes an entry to the internal approval audit trail
+func recordBudgetApprovalAudit(ctx context.Context, tx pgx.Tx, orgID, budgetID uuid.UUID, approvalID, userID *uuid.UUID, action models.BudgetApprovalAction, comment *string, details map[string]interface{}) error {
+	var detailsJSON []byte
+	if details != nil {
+		var err error
+		detailsJSON, err = json.Marshal(details)
+		if err != nil {
+			return fmt.Errorf("failed to marshal audit details: %w", err)
+		}
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO budget_approval_audit (organization_id, budget_id, approval_id, user_id, action, comment, details)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, orgID, budgetID, approvalID, userID, action, comment, detailsJSON)
+	if err != nil {
+		return fmt.Errorf("failed to record approval audit: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// normalizeCommentBody trims a line-item comment and checks it is neither empty nor too long
+func normalizeCommentBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", errors.New("comment is required")
+	}
+	if len([]rune(body)) > maxPortalCommentLength {
+		return "", fmt.Errorf("comment must have at most %d characters", maxPortalCommentLength)
+	}
+	return body, nil
+}
+
+// itemComments returns the line-item comments of a budget, oldest first, only those of one line
+// when itemID is set
+func (s *BudgetService) itemComments(ctx context.Context, budgetID uuid.UUID, itemID *uuid.UUID) ([]*models.BudgetItemComment, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT bc.id, bc.budget_id, bc.budget_item_id, bc.author_type, bc.user_id,
+			CASE WHEN bc.author_type = 'staff' THEN COALESCE(u.first_name || ' ' || u.last_name, '')
+				ELSE COALESCE(c.name, '') END,
+			bc.body, bc.created_at
+		FROM budget_item_comments bc
+		JOIN budgets b ON b.id = bc.budget_id
+		JOIN worksheets w ON w.id = b.worksheet_id
+		LEFT JOIN clients c ON c.id = w.client_id
+		LEFT JOIN users u ON u.id = bc.user_id
+		WHERE bc.budget_id = $1 AND ($2::uuid IS NULL OR bc.budget_item_id = $2)
+		ORDER BY bc.created_at
+	`, budgetID, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list budget comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := make([]*models.BudgetItemComment, 0)
+	for rows.Next() {
+		var c models.BudgetItemComment
+		if err := rows.Scan(&c.ID, &c.BudgetID, &c.BudgetItemID, &c.AuthorType, &c.UserID, &c.AuthorName, &c.Body, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan budget comment: %w", err)
+		}
+		comments = append(comments, &c)
+	}
+
+	return comments, rows.Err()
+}
+
+// checkBudget returns an error when the organization has no such budget
+func (s *BudgetService) checkBudget(ctx context.Context, id, orgID uuid.UUID) error {
+	var exists bool
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM budgets WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL)
+	`, id, orgID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to get budget: %w", err)
+	}
+	if !exists {
+		return errors.New("budget not found")
+	}
+	return nil
+}
+
+// budgetItemDescription returns the description of a line of a budget
+func (s *BudgetService) budgetItemDescription(ctx context.Context, budgetID, itemID uuid.UUID) (string, error) {
+	var description string
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT description FROM budget_items WHERE id = $1 AND budget_id = $2 AND deleted_at IS NULL
+	`, itemID, budgetID).Scan(&description)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", errors.New("budget item not found")
+		}
+		return "", fmt.Errorf("failed to get budget item: %w", err)
+	}
+	return description, nil
+}
+
+// addItemComment stores a line-item comment and returns it as listed
+func (s *BudgetService) addItemComment(ctx context.Context, orgID, budgetID, itemID uuid.UUID, author models.BudgetCommentAuthor, userID *uuid.UUID, body string, ipAddress *string) (*models.BudgetItemComment, error) {
+	var id uuid.UUID
+	err := s.db.Pool.QueryRow(ctx, `
+		INSERT INTO budget_item_comments (organization_id, budget_id, budget_item_id, author_type, user_id, body, ip_address)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`, orgID, budgetID, itemID, author, userID, body, ipAddress).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create budget comment: %w", err)
+	}
+
+	comments, err := s.itemComments(ctx, budgetID, &itemID)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range comments {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return nil, errors.New("budget comment not found")
+}
+
+// ListComments returns the line-item comment threads of a budget, only the thread of one line when
+// itemID is set
+func (s *BudgetService) ListComments(ctx context.Context, id, orgID uuid.UUID, itemID *uuid.UUID) ([]*models.BudgetItemComment, error) {
+	if err := s.checkBudget(ctx, id, orgID); err != nil {
+		return nil, err
+	}
+	return s.itemComments(ctx, id, itemID)
+}
+
+// ReplyToItem adds a staff answer to the thread of a budget line. The client sees it on the portal.
+func (s *BudgetService) ReplyToItem(ctx context.Context, id, orgID, itemID, userID uuid.UUID, body string) (*models.BudgetItemComment, error) {
+	body, err := normalizeCommentBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkBudget(ctx, id, orgID); err != nil {
+		return nil, err
+	}
+	if _, err := s.budgetItemDescription(ctx, id, itemID); err != nil {
+		return nil, err
+	}
+
+	return s.addItemComment(ctx, orgID, id, itemID, models.BudgetCommentByStaff, &userID, body, nil)
+}
+
+// CommentFromPortal records a client question on a line of the budget behind a portal link. Clients
+// can only comment while the budget awaits their response. The budget's author and the staff who
+// answered on that line are notified.
+func (s *BudgetService) CommentFromPortal(ctx context.Context, token string, itemID uuid.UUID, body, ipAddress string) (*models.BudgetItemComment, error) {
+	body, err := normalizeCommentBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	id, orgID, status, err := s.portalBudget(ctx, s.db.Pool, token, false)
+	if err != nil {
+		return nil, err
+	}
+	if status != models.BudgetStatusSent {
+		return nil, errors.New("budget is no longer awaiting a response")
+	}
+	description, err := s.budgetItemDescription(ctx, id, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := s.addItemComment(ctx, orgID, id, itemID, models.BudgetCommentByClient, nil, body, &ipAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyItemComment(ctx, id, itemID, description, body)
+
+	return comment, nil
+}
+
+// notifyItemComment lets the budget's author and the staff in the line's thread know the client
+// asked something
+func (s *BudgetService) notifyItemComment(ctx context.Context, budgetID, itemID uuid.UUID, description, body string) {
+	if s.notification == nil {
+		return
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT b.created_by FROM budgets b WHERE b.id = $1
+		UNION
+		SELECT bc.user_id FROM budget_item_comments bc
+		WHERE bc.budget_id = $1 AND bc.budget_item_id = $2 AND bc.user_id IS NOT NULL
+	`, budgetID, itemID)
+	if err != nil {
+		fmt.Printf("Failed to get budget comment recipients: %v\n", err)
+		return
+	}
+	var recipients []uuid.UUID
+	for rows.Next() {
+		var userID uuid.UUID
+		if err := rows.Scan(&userID); err != nil {
+			rows.Close()
+			fmt.Printf("Failed to scan budget comment recipient: %v\n", err)
+			return
+		}
+		recipients = append(recipients, userID)
+	}
+	rows.Close()
+
+	var budgetNumber string
+	if err := s.db.Pool.QueryRow(ctx, `SELECT budget_number FROM budgets WHERE id = $1`, budgetID).Scan(&budgetNumber); err != nil {
+		fmt.Printf("Failed to get budget: %v\n", err)
+		return
+	}
+
+	entityType := "budget"
+	for _, userID := range recipients {
+		notification := &models.Notification{
+			UserID:     userID,
+			Type:       models.NotificationTypeBudgetComment,
+			Title:      fmt.Sprintf("Pergunta do cliente no orçamento %s", budgetNumber),
+			Message:    fmt.Sprintf("%s: %s", description, body),
+			EntityType: &entityType,
+			EntityID:   &budgetID,
+		}
+		if err := s.notification.Create(ctx, notification); err != nil {
+			fmt.Printf("Failed to create notification: %v\n", err)
+		}
+	}
+}
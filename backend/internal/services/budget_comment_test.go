@@ -0,0 +1,33 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeCommentBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{"trimmed", "  Inclui a pintura?  ", "Inclui a pintura?", false},
+		{"empty", "", "", true},
+		{"only spaces", " \n\t ", "", true},
+		{"at the limit", strings.Repeat("é", maxPortalCommentLength), strings.Repeat("é", maxPortalCommentLength), false},
+		{"too long", strings.Repeat("a", maxPortalCommentLength+1), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeCommentBody(tt.body)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeCommentBody() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("normalizeCommentBody() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
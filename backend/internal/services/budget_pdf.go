@@ -0,0 +1,631 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/pdf"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	defaultBudgetPDFAccent = "#1F4E79"
+	budgetPDFMargin        = 40.0
+	// budgetPDFBottom is where the content of a page ends, leaving room for the footer
+	budgetPDFBottom = pdf.A4Height - 70
+	// budgetPDFLogoWidth and budgetPDFLogoHeight bound the logo in the header
+	budgetPDFLogoWidth  = 150.0
+	budgetPDFLogoHeight = 60.0
+)
+
+// defaultBudgetPDFTemplate is used by organizations that did not customize their budget PDFs
+func defaultBudgetPDFTemplate(orgID uuid.UUID) *models.BudgetPDFTemplate {
+	return &models.BudgetPDFTemplate{
+		OrganizationID: orgID,
+		Layout:         models.BudgetPDFLayoutClassic,
+		AccentColor:    defaultBudgetPDFAccent,
+		ShowLogo:       true,
+		ShowItemTax:    true,
+		ShowSignature:  true,
+	}
+}
+
+// GetPDFTemplate returns the organization's budget PDF branding, or the defaults
+func (s *BudgetService) GetPDFTemplate(ctx context.Context, orgID uuid.UUID) (*models.BudgetPDFTemplate, error) {
+	var t models.BudgetPDFTemplate
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT id, organization_id, layout, accent_color, title, header_text, footer_text, terms,
+			show_logo, show_item_tax, show_signature, updated_at
+		FROM budget_pdf_templates
+		WHERE organization_id = $1
+	`, orgID).Scan(
+		&t.ID, &t.OrganizationID, &t.Layout, &t.AccentColor, &t.Title, &t.HeaderText, &t.FooterText, &t.Terms,
+		&t.ShowLogo, &t.ShowItemTax, &t.ShowSignature, &t.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return defaultBudgetPDFTemplate(orgID), nil
+		}
+		return nil, fmt.Errorf("failed to get budget PDF template: %w", err)
+	}
+	return &t, nil
+}
+
+// UpdatePDFTemplate saves the organization's budget PDF branding. Budget PDFs generated
+// before the change are rebuilt the next time they are downloaded.
+func (s *BudgetService) UpdatePDFTemplate(ctx context.Context, orgID uuid.UUID, t models.BudgetPDFTemplate) (*models.BudgetPDFTemplate, error) {
+	if t.Layout == "" {
+		t.Layout = models.BudgetPDFLayoutClassic
+	}
+	if t.Layout != models.BudgetPDFLayoutClassic && t.Layout != models.BudgetPDFLayoutCompact {
+		return nil, fmt.Errorf("invalid layout: %s", t.Layout)
+	}
+	if t.AccentColor == "" {
+		t.AccentColor = defaultBudgetPDFAccent
+	}
+	if _, err := pdf.ParseHexColor(t.AccentColor); err != nil {
+		return nil, errors.New("accent color must be in the #RRGGBB format")
+	}
+	t.AccentColor = strings.ToUpper(strings.TrimSpace(t.AccentColor))
+	for _, text := range []**string{&t.Title, &t.HeaderText, &t.FooterText, &t.Terms} {
+		if *text != nil {
+			trimmed := strings.TrimSpace(**text)
+			if trimmed == "" {
+				*text = nil
+			} else {
+				*text = &trimmed
+			}
+		}
+	}
+	if t.Title != nil && len([]rune(*t.Title)) > 100 {
+		return nil, errors.New("title must have at most 100 characters")
+	}
+
+	t.OrganizationID = orgID
+	err := s.db.Pool.QueryRow(ctx, `
+		INSERT INTO budget_pdf_templates (organization_id, layout, accent_color, title, header_text, footer_text, terms,
+			show_logo, show_item_tax, show_signature)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (organization_id) DO UPDATE SET
+			layout = EXCLUDED.layout, accent_color = EXCLUDED.accent_color, title = EXCLUDED.title,
+			header_text = EXCLUDED.header_text, footer_text = EXCLUDED.footer_text, terms = EXCLUDED.terms,
+			show_logo = EXCLUDED.show_logo, show_item_tax = EXCLUDED.show_item_tax,
+			show_signature = EXCLUDED.show_signature, updated_at = NOW()
+		RETURNING id, updated_at
+	`, orgID, t.Layout, t.AccentColor, t.Title, t.HeaderText, t.FooterText, t.Terms,
+		t.ShowLogo, t.ShowItemTax, t.ShowSignature,
+	).Scan(&t.ID, &t.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save budget PDF template: %w", err)
+	}
+	return &t, nil
+}
+
+// GetPDF returns a download link to the budget's PDF. The PDF is generated when missing,
+// when regenerate is set, or when the budget, its items, the organization or its PDF template
+// changed since it was built.
+func (s *BudgetService) GetPDF(ctx context.Context, id, orgID uuid.UUID, regenerate bool) (*models.BudgetPDF, error) {
+	if s.storage == nil {
+		return nil, errors.New("storage is not configured")
+	}
+
+	var number string
+	var fileURL *string
+	var generatedAt *time.Time
+	var stale bool
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT b.budget_number, b.pdf_url, b.pdf_generated_at,
+			b.pdf_generated_at IS NULL OR b.pdf_generated_at < GREATEST(
+				b.updated_at,
+				o.updated_at,
+				(SELECT MAX(updated_at) FROM budget_items WHERE budget_id = b.id),
+				(SELECT updated_at FROM budget_pdf_templates WHERE organization_id = b.organization_id)
+			)
+		FROM budgets b
+		JOIN organizations o ON o.id = b.organization_id
+		WHERE b.id = $1 AND b.organization_id = $2 AND b.deleted_at IS NULL
+	`, id, orgID).Scan(&number, &fileURL, &generatedAt, &stale)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("budget not found")
+		}
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+
+	if fileURL == nil || stale || regenerate {
+		url, at, err := s.generatePDF(ctx, id, orgID)
+		if err != nil {
+			return nil, err
+		}
+		fileURL, generatedAt = &url, &at
+	}
+
+	link, err := s.storage.DownloadURL(ctx, *fileURL, exportDownloadExpiry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign download link: %w", err)
+	}
+
+	return &models.BudgetPDF{
+		BudgetID:    id,
+		FileName:    budgetPDFFileName(number),
+		DownloadURL: link,
+		GeneratedAt: *generatedAt,
+		ExpiresAt:   time.Now().Add(exportDownloadExpiry),
+	}, nil
+}
+
+// generatePDF renders and stores the budget PDF, replacing the previous file
+func (s *BudgetService) generatePDF(ctx context.Context, id, orgID uuid.UUID) (string, time.Time, error) {
+	doc, err := s.loadBudgetDocument(ctx, id, orgID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	tpl, err := s.GetPDFTemplate(ctx, orgID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	var logo image.Image
+	if tpl.ShowLogo && doc.org.logo != nil {
+		logo = pdfLogo(ctx, s.storage, *doc.org.logo)
+	}
+
+	data, err := renderBudgetPDF(doc, tpl, logo)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to render budget PDF: %w", err)
+	}
+
+	upload, err := s.storage.UploadGenerated(ctx, data, budgetPDFFileName(doc.number), "application/pdf", orgID)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to store budget PDF: %w", err)
+	}
+
+	var previous *string
+	var generatedAt time.Time
+	err = s.db.Pool.QueryRow(ctx, `
+		UPDATE budgets b SET pdf_url = $1, pdf_generated_at = NOW()
+		FROM (SELECT id, pdf_url FROM budgets WHERE id = $2 FOR UPDATE) old
+		WHERE b.id = old.id
+		RETURNING old.pdf_url, b.pdf_generated_at
+	`, upload.URL, id).Scan(&previous, &generatedAt)
+	if err != nil {
+		s.storage.DeleteFile(ctx, upload.URL)
+		return "", time.Time{}, fmt.Errorf("failed to save budget PDF: %w", err)
+	}
+
+	if previous != nil && *previous != upload.URL {
+		if err := s.storage.DeleteFile(ctx, *previous); err != nil {
+			log.Printf("Failed to delete previous PDF of budget %s: %v", id, err)
+		}
+	}
+
+	return upload.URL, generatedAt, nil
+}
+
+// pdfLogo loads the organization logo for a generated PDF. The PDF is still generated without it
+// when the logo cannot be read, e.g. when files are not stored in S3.
+func pdfLogo(ctx context.Context, storage *StorageService, url string) image.Image {
+	data, err := storage.ReadFile(ctx, url, MaxLogoUploadSize)
+	if err != nil {
+		if !errors.Is(err, errIntegrationNotConfigured) {
+			log.Printf("Failed to read organization logo %s: %v", url, err)
+		}
+		return nil
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		log.Printf("Failed to decode organization logo %s: %v", url, err)
+		return nil
+	}
+	return img
+}
+
+func budgetPDFFileName(number string) string {
+	return "orcamento-" + strings.NewReplacer("/", "-", "\\", "-", " ", "-").Replace(number) + ".pdf"
+}
+
+// budgetParty is the organization or the client as printed on a budget
+type budgetParty struct {
+	name, address, taxID, email, phone string
+	logo                               *string
+}
+
+// budgetDocument is the content of a budget PDF
+type budgetDocument struct {
+	number               string
+	createdAt            time.Time
+	validUntil           time.Time
+	subtotal, tax, total decimal.Decimal
+	notes                string
+	workTitle            string
+	workDescription      string
+	org, client          budgetParty
+	items                []models.BudgetItem
+}
+
+func (s *BudgetService) loadBudgetDocument(ctx context.Context, id, orgID uuid.UUID) (*budgetDocument, error) {
+	var d budgetDocument
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT b.budget_number, b.created_at, b.valid_until, b.subtotal, b.tax, b.total, COALESCE(b.notes, ''),
+			w.title, w.description,
+			o.name, COALESCE(o.address, ''), COALESCE(o.tax_id, ''), o.email, COALESCE(o.phone, ''), o.logo,
+			c.name, COALESCE(c.address, ''), COALESCE(c.tax_id, ''), c.email, c.phone
+		FROM budgets b
+		JOIN worksheets w ON w.id = b.worksheet_id
+		JOIN clients c ON c.id = w.client_id
+		JOIN organizations o ON o.id = b.organization_id
+		WHERE b.id = $1 AND b.organization_id = $2 AND b.deleted_at IS NULL
+	`, id, orgID).Scan(
+		&d.number, &d.createdAt, &d.validUntil, &d.subtotal, &d.tax, &d.total, &d.notes,
+		&d.workTitle, &d.workDescription,
+		&d.org.name, &d.org.address, &d.org.taxID, &d.org.email, &d.org.phone, &d.org.logo,
+		&d.client.name, &d.client.address, &d.client.taxID, &d.client.email, &d.client.phone,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("budget not found")
+		}
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, description, quantity, unit, unit_price, tax, total, "order"
+		FROM budget_items
+		WHERE budget_id = $1 AND deleted_at IS NULL
+		ORDER BY "order", created_at
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item := models.BudgetItem{BudgetID: id}
+		if err := rows.Scan(&item.ID, &item.Description, &item.Quantity, &item.Unit, &item.UnitPrice, &item.Tax, &item.Total, &item.Order); err != nil {
+			return nil, fmt.Errorf("failed to scan budget item: %w", err)
+		}
+		d.items = append(d.items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get budget items: %w", err)
+	}
+
+	return &d, nil
+}
+
+// budgetPDFColumn is a column of the items table
+type budgetPDFColumn struct {
+	title string
+	width float64
+	right bool // right-aligned, for numbers
+}
+
+// budgetPDFRenderer lays out a budget top to bottom, starting new pages as needed
+type budgetPDFRenderer struct {
+	doc     *pdf.Document
+	page    *pdf.Page
+	y       float64
+	size    float64 // body text size
+	leading float64
+	accent  pdf.Color
+	columns []budgetPDFColumn
+	showTax bool // the items table has an IVA column
+}
+
+// renderBudgetPDF builds the PDF of a budget: header with the organization details and logo,
+// client and work, line items, totals, validity, notes, terms and signature block.
+func renderBudgetPDF(d *budgetDocument, tpl *models.BudgetPDFTemplate, logo image.Image) ([]byte, error) {
+	accent, err := pdf.ParseHexColor(tpl.AccentColor)
+	if err != nil {
+		accent, _ = pdf.ParseHexColor(defaultBudgetPDFAccent)
+	}
+
+	r := &budgetPDFRenderer{doc: pdf.New(), size: 10, leading: 14, accent: accent, showTax: tpl.ShowItemTax}
+	if tpl.Layout == models.BudgetPDFLayoutCompact {
+		r.size, r.leading = 8.5, 11.5
+	}
+	r.columns = []budgetPDFColumn{{title: "Descrição"}, {"Qtd.", 45, true}, {"Un.", 40, false}, {"Preço unit.", 70, true}}
+	if r.showTax {
+		r.columns = append(r.columns, budgetPDFColumn{"IVA", 60, true})
+	}
+	r.columns = append(r.columns, budgetPDFColumn{"Total", 75, true})
+	r.columns[0].width = pdf.A4Width - 2*budgetPDFMargin
+	for _, c := range r.columns[1:] {
+		r.columns[0].width -= c.width
+	}
+
+	r.page = r.doc.AddPage()
+	if err := r.header(d, tpl, logo); err != nil {
+		return nil, err
+	}
+	r.parties(d)
+	r.items(d)
+	r.totals(d)
+
+	r.y += r.leading
+	r.paragraph(fmt.Sprintf("Este orçamento é válido até %s.", d.validUntil.Format("02/01/2006")), pdf.Regular, pdf.Black)
+	if d.notes != "" {
+		r.section("Observações", d.notes)
+	}
+	if tpl.Terms != nil {
+		r.section("Condições", *tpl.Terms)
+	}
+	if tpl.ShowSignature {
+		r.signature(d)
+	}
+
+	footer := ""
+	if tpl.FooterText != nil {
+		footer = *tpl.FooterText
+	}
+	pages := r.doc.Pages()
+	for i, p := range pages {
+		footerY := pdf.A4Height - 40
+		p.Line(budgetPDFMargin, footerY-12, pdf.A4Width-budgetPDFMargin, footerY-12, 0.5, pdf.Gray)
+		lines := pdf.WrapText(footer, pdf.Regular, 7.5, pdf.A4Width-2*budgetPDFMargin-80)
+		for j, line := range lines {
+			if j == 2 {
+				break
+			}
+			p.Text(budgetPDFMargin, footerY+float64(j)*9, pdf.Regular, 7.5, pdf.Gray, line)
+		}
+		p.TextRight(pdf.A4Width-budgetPDFMargin, footerY, pdf.Regular, 7.5, pdf.Gray,
+			fmt.Sprintf("%s · Página %d de %d", d.number, i+1, len(pages)))
+	}
+
+	return r.doc.Bytes()
+}
+
+// ensure starts a new page unless height fits on the current one. It reports whether it did.
+func (r *budgetPDFRenderer) ensure(height float64) bool {
+	if r.y+height <= budgetPDFBottom {
+		return false
+	}
+	r.page = r.doc.AddPage()
+	r.y = budgetPDFMargin + r.size
+	return true
+}
+
+func (r *budgetPDFRenderer) header(d *budgetDocument, tpl *models.BudgetPDFTemplate, logo image.Image) error {
+	top := budgetPDFMargin
+	logoBottom := top
+	if logo != nil {
+		img, err := r.doc.AddImage(logo)
+		if err != nil {
+			return err
+		}
+		w, h := budgetPDFLogoWidth, budgetPDFLogoWidth*float64(img.Height)/float64(img.Width)
+		if h > budgetPDFLogoHeight {
+			w, h = budgetPDFLogoHeight*float64(img.Width)/float64(img.Height), budgetPDFLogoHeight
+		}
+		r.page.Image(img, budgetPDFMargin, top, w, h)
+		logoBottom = top + h
+	}
+
+	// Organization details, right-aligned next to the logo
+	right := pdf.A4Width - budgetPDFMargin
+	y := top + 12
+	r.page.TextRight(right, y, pdf.Bold, 12, pdf.Black, d.org.name)
+	for _, line := range nonEmpty(d.org.address, prefixed("NIF ", d.org.taxID), d.org.email, d.org.phone) {
+		for _, wrapped := range pdf.WrapText(line, pdf.Regular, 8.5, 250) {
+			y += 11
+			r.page.TextRight(right, y, pdf.Regular, 8.5, pdf.Gray, wrapped)
+		}
+	}
+
+	r.y = max(logoBottom, y) + 12
+	if tpl.HeaderText != nil {
+		for _, line := range pdf.WrapText(*tpl.HeaderText, pdf.Regular, 8.5, pdf.A4Width-2*budgetPDFMargin) {
+			r.page.Text(budgetPDFMargin, r.y, pdf.Regular, 8.5, pdf.Gray, line)
+			r.y += 11
+		}
+	}
+
+	title := "Orçamento"
+	if tpl.Title != nil {
+		title = *tpl.Title
+	}
+	r.y += 16
+	r.page.Rect(budgetPDFMargin, r.y-14, 4, 18, r.accent)
+	r.page.Text(budgetPDFMargin+10, r.y, pdf.Bold, 16, r.accent, fmt.Sprintf("%s N.º %s", title, d.number))
+	r.y += r.leading + 2
+	r.page.Text(budgetPDFMargin+10, r.y, pdf.Regular, r.size, pdf.Gray,
+		fmt.Sprintf("Data: %s    Válido até: %s", d.createdAt.Format("02/01/2006"), d.validUntil.Format("02/01/2006")))
+	r.y += r.leading * 1.5
+	return nil
+}
+
+// parties prints the client on the left and the work on the right
+func (r *budgetPDFRenderer) parties(d *budgetDocument) {
+	half := (pdf.A4Width - 2*budgetPDFMargin - 20) / 2
+	left := []string{d.client.name}
+	left = append(left, nonEmpty(d.client.address, prefixed("NIF ", d.client.taxID), d.client.email, d.client.phone)...)
+	right := []string{d.workTitle}
+	if d.workDescription != "" {
+		right = append(right, d.workDescription)
+	}
+
+	draw := func(x float64, label string, lines []string) float64 {
+		y := r.y
+		r.page.Text(x, y, pdf.Bold, r.size-1, r.accent, strings.ToUpper(label))
+		for i, line := range lines {
+			font := pdf.Regular
+			if i == 0 {
+				font = pdf.Bold
+			}
+			for _, wrapped := range pdf.WrapText(line, font, r.size, half) {
+				y += r.leading
+				r.page.Text(x, y, font, r.size, pdf.Black, wrapped)
+			}
+		}
+		return y
+	}
+	leftBottom := draw(budgetPDFMargin, "Cliente", left)
+	rightBottom := draw(budgetPDFMargin+half+20, "Obra", right)
+	r.y = max(leftBottom, rightBottom) + r.leading*1.5
+}
+
+func (r *budgetPDFRenderer) tableHeader() {
+	height := r.leading + 6
+	r.page.Rect(budgetPDFMargin, r.y, pdf.A4Width-2*budgetPDFMargin, height, r.accent)
+	r.row(r.y+height-6, pdf.Bold, pdf.White, func(i int) string { return r.columns[i].title })
+	r.y += height
+}
+
+// row writes one line of text per column at baseline y
+func (r *budgetPDFRenderer) row(y float64, font pdf.Font, c pdf.Color, cell func(i int) string) {
+	x := budgetPDFMargin
+	for i, col := range r.columns {
+		if text := cell(i); text != "" {
+			if col.right {
+				r.page.TextRight(x+col.width-4, y, font, r.size, c, text)
+			} else {
+				r.page.Text(x+4, y, font, r.size, c, text)
+			}
+		}
+		x += col.width
+	}
+}
+
+func (r *budgetPDFRenderer) items(d *budgetDocument) {
+	r.ensure(3 * r.leading)
+	r.tableHeader()
+
+	for _, item := range d.items {
+		lines := pdf.WrapText(item.Description, pdf.Regular, r.size, r.columns[0].width-8)
+		height := float64(len(lines))*r.leading + 6
+		if height > budgetPDFBottom-budgetPDFMargin-3*r.leading {
+			height = budgetPDFBottom - budgetPDFMargin - 3*r.leading // very long descriptions are cut
+			lines = lines[:int((height-6)/r.leading)]
+		}
+		if r.ensure(height) {
+			r.tableHeader()
+		}
+
+		values := []string{"", formatQuantity(item.Quantity), item.Unit, formatEuro(item.UnitPrice)}
+		if r.showTax {
+			values = append(values, formatEuro(item.Tax))
+		}
+		values = append(values, formatEuro(item.Total))
+
+		baseline := r.y + r.leading
+		r.row(baseline, pdf.Regular, pdf.Black, func(i int) string { return values[i] })
+		for i, line := range lines {
+			r.page.Text(budgetPDFMargin+4, baseline+float64(i)*r.leading, pdf.Regular, r.size, pdf.Black, line)
+		}
+		r.y += height
+		r.page.Line(budgetPDFMargin, r.y, pdf.A4Width-budgetPDFMargin, r.y, 0.3, pdf.Gray)
+	}
+	if len(d.items) == 0 {
+		r.y += r.leading
+		r.page.Text(budgetPDFMargin+4, r.y, pdf.Regular, r.size, pdf.Gray, "Sem artigos.")
+		r.y += 6
+	}
+}
+
+func (r *budgetPDFRenderer) totals(d *budgetDocument) {
+	r.ensure(4 * r.leading)
+	right := pdf.A4Width - budgetPDFMargin - 4
+	labels := right - 90
+	r.y += r.leading + 4
+	r.page.TextRight(labels, r.y, pdf.Regular, r.size, pdf.Gray, "Subtotal")
+	r.page.TextRight(right, r.y, pdf.Regular, r.size, pdf.Black, formatEuro(d.subtotal))
+	r.y += r.leading
+	r.page.TextRight(labels, r.y, pdf.Regular, r.size, pdf.Gray, "IVA")
+	r.page.TextRight(right, r.y, pdf.Regular, r.size, pdf.Black, formatEuro(d.tax))
+	r.y += 6
+	r.page.Line(labels-60, r.y, right+4, r.y, 1, r.accent)
+	r.y += r.leading + 2
+	r.page.TextRight(labels, r.y, pdf.Bold, r.size+2, r.accent, "Total")
+	r.page.TextRight(right, r.y, pdf.Bold, r.size+2, r.accent, formatEuro(d.total))
+	r.y += r.leading
+}
+
+// paragraph writes wrapped text across the full width
+func (r *budgetPDFRenderer) paragraph(text string, font pdf.Font, c pdf.Color) {
+	for _, line := range pdf.WrapText(text, font, r.size, pdf.A4Width-2*budgetPDFMargin) {
+		r.ensure(r.leading)
+		r.page.Text(budgetPDFMargin, r.y, font, r.size, c, line)
+		r.y += r.leading
+	}
+}
+
+func (r *budgetPDFRenderer) section(title, text string) {
+	r.y += r.leading / 2
+	r.ensure(3 * r.leading)
+	r.page.Text(budgetPDFMargin, r.y, pdf.Bold, r.size, r.accent, title)
+	r.y += r.leading
+	r.paragraph(text, pdf.Regular, pdf.Black)
+}
+
+func (r *budgetPDFRenderer) signature(d *budgetDocument) {
+	r.y += r.leading
+	r.ensure(90)
+	width := (pdf.A4Width - 2*budgetPDFMargin - 40) / 2
+	lineY := r.y + 45
+	for i, label := range []string{"Pelo cliente", "Por " + d.org.name} {
+		x := budgetPDFMargin + float64(i)*(width+40)
+		r.page.Text(x, r.y, pdf.Regular, r.size, pdf.Gray, label)
+		r.page.Line(x, lineY, x+width, lineY, 0.5, pdf.Black)
+		r.page.Text(x, lineY+r.leading, pdf.Regular, r.size-1, pdf.Gray, "Data: ____/____/________")
+	}
+	r.y = lineY + 2*r.leading
+}
+
+// nonEmpty drops the empty values of a list of details
+func nonEmpty(values ...string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func prefixed(prefix, value string) string {
+	if value == "" {
+		return ""
+	}
+	return prefix + value
+}
+
+// formatEuro writes an amount the Portuguese way, e.g. 1.234,50 €
+func formatEuro(d decimal.Decimal) string {
+	return formatPortugueseNumber(d.StringFixed(2)) + " €"
+}
+
+// formatQuantity drops the decimals of whole quantities, e.g. 2 or 2,5
+func formatQuantity(q float64) string {
+	s := decimal.NewFromFloat(q).Round(2).String()
+	return formatPortugueseNumber(s)
+}
+
+// formatPortugueseNumber swaps the separators of a plain decimal string: 1234.5 becomes 1.234,5
+func formatPortugueseNumber(s string) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	var b strings.Builder
+	for i, c := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(c)
+	}
+	if hasFrac {
+		b.WriteString("," + frac)
+	}
+	return sign + b.String()
+}
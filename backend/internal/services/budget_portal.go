@@ -0,0 +1,327 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/i18n"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxPortalCommentLength bounds the comment a client leaves with their decision
+const maxPortalCommentLength = 2000
+
+// newPortalToken returns the random token of a budget portal link
+func newPortalToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate portal token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// markBudgetSent moves a budget to sent and gives it a portal link if it has none yet
+func markBudgetSent(ctx context.Context, tx pgx.Tx, budgetID uuid.UUID) error {
+	token, err := newPortalToken()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE budgets
+		SET status = $1, sent_at = CURRENT_TIMESTAMP, portal_token = COALESCE(portal_token, $2), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`, models.BudgetStatusSent, token, budgetID)
+	return err
+}
+
+// portalURL is the client's link to a budget
+func (s *BudgetService) portalURL(token string) string {
+	return fmt.Sprintf("%s/budget-portal/%s", strings.TrimRight(s.frontendURL, "/"), token)
+}
+
+// GetPortalLink returns the client's link to a budget with the client's activity on it,
+// creating the link when the budget has none
+func (s *BudgetService) GetPortalLink(ctx context.Context, id, orgID uuid.UUID) (*models.BudgetPortalLink, error) {
+	token, err := newPortalToken()
+	if err != nil {
+		return nil, err
+	}
+	return s.savePortalLink(ctx, id, orgID, `COALESCE(portal_token, $3)`, token)
+}
+
+// RotatePortalLink replaces the client's link to a budget; the previous link stops working
+func (s *BudgetService) RotatePortalLink(ctx context.Context, id, orgID uuid.UUID) (*models.BudgetPortalLink, error) {
+	token, err := newPortalToken()
+	if err != nil {
+		return nil, err
+	}
+	return s.savePortalLink(ctx, id, orgID, `$3`, token)
+}
+
+func (s *BudgetService) savePortalLink(ctx context.Context, id, orgID uuid.UUID, tokenExpr, token string) (*models.BudgetPortalLink, error) {
+	link := &models.BudgetPortalLink{BudgetID: id}
+	err := s.db.Pool.QueryRow(ctx, `
+		UPDATE budgets SET portal_token = `+tokenExpr+`
+		WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+		RETURNING portal_token, portal_viewed_at
+	`, id, orgID, token).Scan(&token, &link.FirstViewed)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("budget not found")
+		}
+		return nil, fmt.Errorf("failed to save portal link: %w", err)
+	}
+	link.URL = s.portalURL(token)
+	link.ApprovalURL = link.URL + "?action=approve"
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, budget_id, action, comment, ip_address, user_agent, created_at
+		FROM budget_portal_events
+		WHERE budget_id = $1
+		ORDER BY created_at DESC
+		LIMIT 50
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list portal events: %w", err)
+	}
+	defer rows.Close()
+
+	link.Events = make([]*models.BudgetPortalEvent, 0)
+	for rows.Next() {
+		var e models.BudgetPortalEvent
+		if err := rows.Scan(&e.ID, &e.BudgetID, &e.Action, &e.Comment, &e.IPAddress, &e.UserAgent, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan portal event: %w", err)
+		}
+		link.Events = append(link.Events, &e)
+	}
+
+	return link, rows.Err()
+}
+
+// portalBudget identifies the budget behind a portal token. Budgets that were never sent to the
+// client are not shown, even if a link was created for them.
+func (s *BudgetService) portalBudget(ctx context.Context, q rowQuerier, token string, lock bool) (id, orgID uuid.UUID, status models.BudgetStatus, err error) {
+	query := `
+		SELECT id, organization_id, status FROM budgets
+		WHERE portal_token = $1 AND deleted_at IS NULL
+			AND status NOT IN ('draft', 'pending_internal_approval')`
+	if lock {
+		query += " FOR UPDATE"
+	}
+	err = q.QueryRow(ctx, query, token).Scan(&id, &orgID, &status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return id, orgID, status, errors.New("budget not found")
+		}
+		return id, orgID, status, fmt.Errorf("failed to get budget: %w", err)
+	}
+	return id, orgID, status, nil
+}
+
+// GetPortalView returns the budget behind a portal link as shown to the client and records the
+// visit, at most once an hour
+func (s *BudgetService) GetPortalView(ctx context.Context, token, ipAddress, userAgent string) (*models.BudgetPortalView, error) {
+	id, orgID, _, err := s.portalBudget(ctx, s.db.Pool, token, false)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.db.Pool.Exec(ctx, `
+		WITH first_view AS (
+			UPDATE budgets SET portal_viewed_at = COALESCE(portal_viewed_at, NOW()) WHERE id = $2
+		)
+		INSERT INTO budget_portal_events (organization_id, budget_id, action, ip_address, user_agent)
+		SELECT $1, $2, 'viewed', $3, $4
+		WHERE NOT EXISTS (
+			SELECT 1 FROM budget_portal_events
+			WHERE budget_id = $2 AND action = 'viewed' AND created_at > NOW() - INTERVAL '1 hour'
+		)
+	`, orgID, id, ipAddress, userAgent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record portal visit: %w", err)
+	}
+
+	return s.portalView(ctx, id, orgID)
+}
+
+// PortalPDF returns a short-lived link to the PDF of the budget behind a portal link
+func (s *BudgetService) PortalPDF(ctx context.Context, token string) (*models.BudgetPDF, error) {
+	id, orgID, _, err := s.portalBudget(ctx, s.db.Pool, token, false)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetPDF(ctx, id, orgID, false)
+}
+
+// RespondFromPortal records the client's approval or rejection of a sent budget. The budget moves
+// through the usual state change, so the budget workflow's triggers fire and the author is notified.
+func (s *BudgetService) RespondFromPortal(ctx context.Context, token string, approve bool, comment *string, ipAddress, userAgent string) (*models.BudgetPortalView, error) {
+	if comment != nil {
+		trimmed := strings.TrimSpace(*comment)
+		comment = &trimmed
+		if trimmed == "" {
+			comment = nil
+		} else if len([]rune(trimmed)) > maxPortalCommentLength {
+			return nil, fmt.Errorf("comment must have at most %d characters", maxPortalCommentLength)
+		}
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	id, orgID, status, err := s.portalBudget(ctx, tx, token, true)
+	if err != nil {
+		return nil, err
+	}
+	if status != models.BudgetStatusSent {
+		return nil, errors.New("budget is no longer awaiting a response")
+	}
+
+	var expired bool
+	if err := tx.QueryRow(ctx, `SELECT valid_until < CURRENT_DATE FROM budgets WHERE id = $1`, id).Scan(&expired); err != nil {
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+	if expired {
+		return nil, errors.New("budget has expired")
+	}
+
+	newStatus, action := models.BudgetStatusApproved, models.BudgetPortalApproved
+	if approve {
+		_, err = tx.Exec(ctx, `
+			UPDATE budgets SET status = $1, approved_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE id = $2
+		`, newStatus, id)
+	} else {
+		newStatus, action = models.BudgetStatusRejected, models.BudgetPortalRejected
+		_, err = tx.Exec(ctx, `
+			UPDATE budgets SET status = $1, rejected_at = CURRENT_TIMESTAMP, rejection_notes = $2, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $3
+		`, newStatus, comment, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update budget status: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO budget_portal_events (organization_id, budget_id, action, comment, ip_address, user_agent)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, orgID, id, action, comment, ipAddress, userAgent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record portal response: %w", err)
+	}
+
+	var createdBy uuid.UUID
+	var budgetNumber string
+	if err := tx.QueryRow(ctx, `SELECT created_by, budget_number FROM budgets WHERE id = $1`, id).Scan(&createdBy, &budgetNumber); err != nil {
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// Let the author know the client decided
+	if s.notification != nil {
+		// The request comes from the client, so its locale says nothing of the author's
+		locale := i18n.DefaultLocale
+		entityType := "budget"
+		notification := &models.Notification{
+			UserID:     createdBy,
+			Type:       models.NotificationTypeBudgetApproved,
+			Title:      fmt.Sprintf(i18n.T(locale, "Budget %s approved by the client"), budgetNumber),
+			EntityType: &entityType,
+			EntityID:   &id,
+		}
+		if !approve {
+			notification.Type = models.NotificationTypeBudgetRejected
+			notification.Title = fmt.Sprintf(i18n.T(locale, "Budget %s rejected by the client"), budgetNumber)
+		}
+		if comment != nil {
+			notification.Message = *comment
+		}
+		if err := s.notification.Create(ctx, notification); err != nil {
+			fmt.Printf("Failed to create notification: %v\n", err)
+		}
+	}
+
+	s.onStateChange(ctx, orgID, id, models.BudgetStatusSent, newStatus)
+
+	return s.portalView(ctx, id, orgID)
+}
+
+func (s *BudgetService) portalView(ctx context.Context, id, orgID uuid.UUID) (*models.BudgetPortalView, error) {
+	var v models.BudgetPortalView
+	var approvedAt, rejectedAt *time.Time
+	var expired bool
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT o.name, o.email, o.phone, b.budget_number, b.status, COALESCE(c.name, ''), w.title, w.description,
+			b.subtotal, b.tax, b.total, b.notes, b.sent_at, b.valid_until, b.approved_at, b.rejected_at,
+			b.valid_until < CURRENT_DATE
+		FROM budgets b
+		JOIN organizations o ON o.id = b.organization_id
+		JOIN worksheets w ON w.id = b.worksheet_id
+		LEFT JOIN clients c ON c.id = w.client_id
+		WHERE b.id = $1 AND b.organization_id = $2
+	`, id, orgID).Scan(
+		&v.OrganizationName, &v.OrganizationEmail, &v.OrganizationPhone, &v.BudgetNumber, &v.Status, &v.ClientName,
+		&v.ProjectName, &v.Description, &v.Subtotal, &v.Tax, &v.Total, &v.Notes, &v.SentAt, &v.ValidUntil,
+		&approvedAt, &rejectedAt, &expired,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+	v.CanRespond = v.Status == models.BudgetStatusSent && !expired
+	if v.Status == models.BudgetStatusApproved {
+		v.RespondedAt = approvedAt
+	} else if v.Status == models.BudgetStatusRejected {
+		v.RespondedAt = rejectedAt
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, description, quantity, unit, unit_price, tax, total
+		FROM budget_items
+		WHERE budget_id = $1 AND deleted_at IS NULL
+		ORDER BY "order", created_at
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget items: %w", err)
+	}
+	defer rows.Close()
+
+	v.Items = make([]models.BudgetPortalItem, 0)
+	for rows.Next() {
+		var item models.BudgetPortalItem
+		if err := rows.Scan(&item.ID, &item.Description, &item.Quantity, &item.Unit, &item.UnitPrice, &item.Tax, &item.Total); err != nil {
+			return nil, fmt.Errorf("failed to scan budget item: %w", err)
+		}
+		item.Comments = make([]*models.BudgetItemComment, 0)
+		v.Items = append(v.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get budget items: %w", err)
+	}
+
+	comments, err := s.itemComments(ctx, id, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range comments {
+		for i := range v.Items {
+			if v.Items[i].ID == c.BudgetItemID {
+				v.Items[i].Comments = append(v.Items[i].Comments, c)
+				break
+			}
+		}
+	}
+
+	return &v, nil
+}
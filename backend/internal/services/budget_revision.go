@@ -0,0 +1,311 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// ============ Revisions ============
+
+// recordBudgetRevision freezes the budget's items and totals as its next revision, unless they are
+// the same as in the last revision. It reports whether a revision was added.
+func recordBudgetRevision(ctx context.Context, tx pgx.Tx, orgID, budgetID, userID uuid.UUID) (bool, error) {
+	current := &models.BudgetRevision{BudgetID: budgetID}
+	err := tx.QueryRow(ctx, `
+		SELECT current_revision, subtotal, tax, total, valid_until, notes
+		FROM budgets WHERE id = $1
+	`, budgetID).Scan(&current.RevisionNumber, &current.Subtotal, &current.Tax, &current.Total, &current.ValidUntil, &current.Notes)
+	if err != nil {
+		return false, fmt.Errorf("failed to get budget: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT worksheet_item_id, description, quantity, unit, unit_price, tax, total, "order"
+		FROM budget_items
+		WHERE budget_id = $1 AND deleted_at IS NULL
+		ORDER BY "order"
+	`, budgetID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get budget items: %w", err)
+	}
+	current.Items = make([]models.BudgetRevisionItem, 0)
+	for rows.Next() {
+		var item models.BudgetRevisionItem
+		if err := rows.Scan(&item.WorkSheetItemID, &item.Description, &item.Quantity, &item.Unit,
+			&item.UnitPrice, &item.Tax, &item.Total, &item.Order); err != nil {
+			rows.Close()
+			return false, fmt.Errorf("failed to scan budget item: %w", err)
+		}
+		current.Items = append(current.Items, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return false, fmt.Errorf("failed to get budget items: %w", err)
+	}
+
+	if current.RevisionNumber > 0 {
+		last, err := getBudgetRevision(ctx, tx, budgetID, current.RevisionNumber)
+		if err != nil {
+			return false, err
+		}
+		if sameBudgetRevision(last, current) {
+			return false, nil
+		}
+	}
+
+	items, err := json.Marshal(current.Items)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode budget items: %w", err)
+	}
+	revisionNumber := current.RevisionNumber + 1
+	_, err = tx.Exec(ctx, `
+		INSERT INTO budget_revisions
+		(organization_id, budget_id, revision_number, subtotal, tax, total, valid_until, notes, items, sent_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, orgID, budgetID, revisionNumber, current.Subtotal, current.Tax, current.Total,
+		current.ValidUntil, current.Notes, items, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to create budget revision: %w", err)
+	}
+	_, err = tx.Exec(ctx, `UPDATE budgets SET current_revision = $1 WHERE id = $2`, revisionNumber, budgetID)
+	if err != nil {
+		return false, fmt.Errorf("failed to update budget revision: %w", err)
+	}
+	return true, nil
+}
+
+const budgetRevisionColumns = `id, budget_id, revision_number, subtotal, tax, total, valid_until, notes, items, sent_by, created_at`
+
+func scanBudgetRevision(row pgx.Row) (*models.BudgetRevision, error) {
+	var r models.BudgetRevision
+	var items []byte
+	if err := row.Scan(&r.ID, &r.BudgetID, &r.RevisionNumber, &r.Subtotal, &r.Tax, &r.Total,
+		&r.ValidUntil, &r.Notes, &items, &r.SentBy, &r.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(items, &r.Items); err != nil {
+		return nil, fmt.Errorf("failed to decode budget revision items: %w", err)
+	}
+	return &r, nil
+}
+
+func getBudgetRevision(ctx context.Context, q rowQuerier, budgetID uuid.UUID, number int) (*models.BudgetRevision, error) {
+	r, err := scanBudgetRevision(q.QueryRow(ctx, `
+		SELECT `+budgetRevisionColumns+` FROM budget_revisions
+		WHERE budget_id = $1 AND revision_number = $2
+	`, budgetID, number))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("budget revision not found")
+		}
+		return nil, fmt.Errorf("failed to get budget revision: %w", err)
+	}
+	return r, nil
+}
+
+// ListRevisions returns the revisions a budget was sent in, newest first
+func (s *BudgetService) ListRevisions(ctx context.Context, budgetID, orgID uuid.UUID) ([]*models.BudgetRevision, error) {
+	if _, err := s.currentRevision(ctx, budgetID, orgID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT `+budgetRevisionColumns+` FROM budget_revisions
+		WHERE budget_id = $1
+		ORDER BY revision_number DESC
+	`, budgetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list budget revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := make([]*models.BudgetRevision, 0)
+	for rows.Next() {
+		r, err := scanBudgetRevision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan budget revision: %w", err)
+		}
+		revisions = append(revisions, r)
+	}
+	return revisions, rows.Err()
+}
+
+// DiffRevisions compares two revisions of a budget. Without to, the latest revision is used;
+// without from, the one before to.
+func (s *BudgetService) DiffRevisions(ctx context.Context, budgetID, orgID uuid.UUID, from, to int) (*models.BudgetRevisionDiff, error) {
+	current, err := s.currentRevision(ctx, budgetID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if to == 0 {
+		to = current
+	}
+	if from == 0 {
+		from = to - 1
+	}
+	if from < 1 || to < 1 {
+		return nil, errors.New("budget revision not found")
+	}
+
+	fromRevision, err := getBudgetRevision(ctx, s.db.Pool, budgetID, from)
+	if err != nil {
+		return nil, err
+	}
+	toRevision, err := getBudgetRevision(ctx, s.db.Pool, budgetID, to)
+	if err != nil {
+		return nil, err
+	}
+	return diffBudgetRevisions(fromRevision, toRevision), nil
+}
+
+func (s *BudgetService) currentRevision(ctx context.Context, budgetID, orgID uuid.UUID) (int, error) {
+	var current int
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT current_revision FROM budgets
+		WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`, budgetID, orgID).Scan(&current)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, errors.New("budget not found")
+		}
+		return 0, fmt.Errorf("failed to get budget: %w", err)
+	}
+	return current, nil
+}
+
+// sameBudgetRevision reports whether two revisions have the same items, totals and terms
+func sameBudgetRevision(a, b *models.BudgetRevision) bool {
+	if !a.Total.Equal(b.Total) || !a.Tax.Equal(b.Tax) || !a.Subtotal.Equal(b.Subtotal) {
+		return false
+	}
+	if !sameDate(a.ValidUntil, b.ValidUntil) || derefString(a.Notes) != derefString(b.Notes) {
+		return false
+	}
+	if len(a.Items) != len(b.Items) {
+		return false
+	}
+	for i := range a.Items {
+		if len(budgetItemChangedFields(a.Items[i], b.Items[i])) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// diffBudgetRevisions matches the items of two revisions by worksheet item, or by description for
+// items added by hand, and lists what was added, removed or changed
+func diffBudgetRevisions(from, to *models.BudgetRevision) *models.BudgetRevisionDiff {
+	diff := &models.BudgetRevisionDiff{
+		BudgetID:      to.BudgetID,
+		FromRevision:  from.RevisionNumber,
+		ToRevision:    to.RevisionNumber,
+		Items:         make([]models.BudgetItemChange, 0),
+		SubtotalDelta: to.Subtotal.Sub(from.Subtotal),
+		TaxDelta:      to.Tax.Sub(from.Tax),
+		TotalDelta:    to.Total.Sub(from.Total),
+	}
+
+	fromKeys := budgetRevisionItemKeys(from.Items)
+	toKeys := budgetRevisionItemKeys(to.Items)
+	fromByKey := make(map[string]int, len(fromKeys))
+	for i, key := range fromKeys {
+		fromByKey[key] = i
+	}
+	matched := make(map[string]bool, len(toKeys))
+
+	for i, key := range toKeys {
+		item := to.Items[i]
+		j, ok := fromByKey[key]
+		if !ok {
+			diff.Items = append(diff.Items, models.BudgetItemChange{
+				Change:      models.BudgetItemAdded,
+				Description: item.Description,
+				To:          &item,
+				TotalDelta:  item.Total,
+			})
+			continue
+		}
+		matched[key] = true
+		old := from.Items[j]
+		if fields := budgetItemChangedFields(old, item); len(fields) > 0 {
+			diff.Items = append(diff.Items, models.BudgetItemChange{
+				Change:      models.BudgetItemChanged,
+				Description: item.Description,
+				From:        &old,
+				To:          &item,
+				Fields:      fields,
+				TotalDelta:  item.Total.Sub(old.Total),
+			})
+		}
+	}
+
+	for i, key := range fromKeys {
+		if matched[key] {
+			continue
+		}
+		item := from.Items[i]
+		diff.Items = append(diff.Items, models.BudgetItemChange{
+			Change:      models.BudgetItemRemoved,
+			Description: item.Description,
+			From:        &item,
+			TotalDelta:  item.Total.Neg(),
+		})
+	}
+	return diff
+}
+
+// budgetRevisionItemKeys identifies the items of a revision; repeated keys get a counter
+func budgetRevisionItemKeys(items []models.BudgetRevisionItem) []string {
+	keys := make([]string, len(items))
+	seen := make(map[string]int, len(items))
+	for i, item := range items {
+		key := "description:" + strings.ToLower(strings.TrimSpace(item.Description))
+		if item.WorkSheetItemID != nil {
+			key = "worksheet_item:" + item.WorkSheetItemID.String()
+		}
+		seen[key]++
+		if seen[key] > 1 {
+			key = fmt.Sprintf("%s#%d", key, seen[key])
+		}
+		keys[i] = key
+	}
+	return keys
+}
+
+// budgetItemChangedFields lists the fields that differ between two versions of an item
+func budgetItemChangedFields(a, b models.BudgetRevisionItem) []string {
+	var fields []string
+	if a.Description != b.Description {
+		fields = append(fields, "description")
+	}
+	for _, f := range []struct {
+		name string
+		a, b decimal.Decimal
+	}{
+		{"quantity", a.Quantity, b.Quantity},
+		{"unit_price", a.UnitPrice, b.UnitPrice},
+		{"tax", a.Tax, b.Tax},
+		{"total", a.Total, b.Total},
+	} {
+		if !f.a.Equal(f.b) {
+			fields = append(fields, f.name)
+		}
+	}
+	if a.Unit != b.Unit {
+		fields = append(fields, "unit")
+	}
+	return fields
+}
+
+func sameDate(a, b time.Time) bool {
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func TestDiffBudgetRevisions(t *testing.T) {
+	worksheetItem := uuid.New()
+	item := func(description, quantity, unitPrice string) models.BudgetRevisionItem {
+		q, p := decimal.RequireFromString(quantity), decimal.RequireFromString(unitPrice)
+		return models.BudgetRevisionItem{Description: description, Quantity: q, Unit: "un", UnitPrice: p, Total: q.Mul(p)}
+	}
+	tiles := item("Tiles", "10", "20")
+	tiles.WorkSheetItemID = &worksheetItem
+	moreTiles := item("Tiles (renamed)", "12", "20")
+	moreTiles.WorkSheetItemID = &worksheetItem
+
+	from := &models.BudgetRevision{
+		RevisionNumber: 1,
+		Total:          decimal.RequireFromString("250"),
+		Items:          []models.BudgetRevisionItem{tiles, item("Paint", "1", "50")},
+	}
+	to := &models.BudgetRevision{
+		RevisionNumber: 2,
+		Total:          decimal.RequireFromString("270"),
+		Items:          []models.BudgetRevisionItem{moreTiles, item("Labour", "1", "30")},
+	}
+
+	diff := diffBudgetRevisions(from, to)
+	if !diff.TotalDelta.Equal(decimal.NewFromInt(20)) {
+		t.Errorf("total delta = %s, want 20", diff.TotalDelta)
+	}
+
+	want := []struct {
+		change models.BudgetItemChangeType
+		delta  int64
+	}{
+		{models.BudgetItemChanged, 40},
+		{models.BudgetItemAdded, 30},
+		{models.BudgetItemRemoved, -50},
+	}
+	if len(diff.Items) != len(want) {
+		t.Fatalf("got %d item changes, want %d: %+v", len(diff.Items), len(want), diff.Items)
+	}
+	for i, w := range want {
+		got := diff.Items[i]
+		if got.Change != w.change || !got.TotalDelta.Equal(decimal.NewFromInt(w.delta)) {
+			t.Errorf("item %d = %s %s, want %s %d", i, got.Change, got.TotalDelta, w.change, w.delta)
+		}
+	}
+	if fields := diff.Items[0].Fields; len(fields) != 3 || fields[0] != "description" || fields[1] != "quantity" || fields[2] != "total" {
+		t.Errorf("changed fields = %v, want [description quantity total]", fields)
+	}
+
+	if !sameBudgetRevision(from, from) || sameBudgetRevision(from, to) {
+		t.Error("sameBudgetRevision should only match identical revisions")
+	}
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// TestApproveInternalByDelegate checks that the delegate an approval request was rerouted to can
+// sign off for the approver's role, while other users of the delegate's role cannot
+func TestApproveInternalByDelegate(t *testing.T) {
+	pool := openTestDB(t)
+	ctx := context.Background()
+
+	orgID, adminID := seedTestOrganization(t, pool, "Budget delegation")
+	var awayID, delegateID, outsiderID, budgetID uuid.UUID
+	seedTestData(t, pool, func(tx pgx.Tx) {
+		awayID = insertTestUser(t, ctx, tx, orgID, models.RoleManager)
+		delegateID = insertTestUser(t, ctx, tx, orgID, models.RoleEmployee)
+		outsiderID = insertTestUser(t, ctx, tx, orgID, models.RoleEmployee)
+		budgetID = insertTestBudget(t, ctx, tx, orgID, adminID)
+		steps := []struct {
+			sql  string
+			args []interface{}
+		}{
+			{`UPDATE budgets SET status = $1 WHERE id = $2`,
+				[]interface{}{models.BudgetStatusPendingInternalApproval, budgetID}},
+			{`INSERT INTO budget_internal_approvals (budget_id, required_role, requested_by) VALUES ($1, $2, $3), ($1, $4, $3)`,
+				[]interface{}{budgetID, models.RoleManager, adminID, models.RoleAccountant}},
+			{`INSERT INTO delegation_audit_log (organization_id, original_user_id, delegate_user_id, delegation_type, entity_type, entity_id)
+				VALUES ($1, $2, $3, $4, 'budget', $5)`,
+				[]interface{}{orgID, awayID, delegateID, models.DelegationTypeApproval, budgetID}},
+		}
+		for _, step := range steps {
+			if _, err := tx.Exec(ctx, step.sql, step.args...); err != nil {
+				t.Fatalf("failed to request approval: %v", err)
+			}
+		}
+	})
+
+	s := NewBudgetService(&database.DB{Pool: pool}, nil, nil, "")
+
+	if _, err := s.ApproveInternal(ctx, budgetID, orgID, outsiderID, models.RoleEmployee, nil); err == nil {
+		t.Error("user without a rerouted approval request signed off the budget")
+	}
+
+	status, err := s.ApproveInternal(ctx, budgetID, orgID, delegateID, models.RoleEmployee, nil)
+	if err != nil {
+		t.Fatalf("ApproveInternal by delegate: %v", err)
+	}
+	if status != models.BudgetStatusPendingInternalApproval {
+		t.Errorf("budget status = %s, want %s while the accountant's sign-off is pending", status, models.BudgetStatusPendingInternalApproval)
+	}
+
+	var decidedBy *uuid.UUID
+	var approvalStatus string
+	if err := pool.QueryRow(ctx, `
+		SELECT status, decided_by FROM budget_internal_approvals WHERE budget_id = $1 AND required_role = $2
+	`, budgetID, models.RoleManager).Scan(&approvalStatus, &decidedBy); err != nil {
+		t.Fatalf("failed to get approval: %v", err)
+	}
+	if approvalStatus != "approved" || decidedBy == nil || *decidedBy != delegateID {
+		t.Errorf("manager approval is %s by %v, want approved by delegate %s", approvalStatus, decidedBy, delegateID)
+	}
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// ListCancellationRules returns the cancellation policy rules of an organization, narrowest window first
+func (s *SessionService) ListCancellationRules(ctx context.Context, orgID uuid.UUID) ([]*models.CancellationPolicyRule, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, organization_id, name, window_hours, fee_percent, is_active, created_at, updated_at
+		FROM cancellation_policy_rules
+		WHERE organization_id = $1
+		ORDER BY window_hours ASC
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cancellation rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []*models.CancellationPolicyRule{}
+	for rows.Next() {
+		var rule models.CancellationPolicyRule
+		if err := rows.Scan(
+			&rule.ID, &rule.OrganizationID, &rule.Name, &rule.WindowHours, &rule.FeePercent,
+			&rule.IsActive, &rule.CreatedAt, &rule.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan cancellation rule: %w", err)
+		}
+		rules = append(rules, &rule)
+	}
+
+	return rules, nil
+}
+
+// CreateCancellationRule adds a fee window to the organization's cancellation policy
+func (s *SessionService) CreateCancellationRule(ctx context.Context, rule *models.CancellationPolicyRule) error {
+	if err := s.validateCancellationRule(ctx, rule, nil); err != nil {
+		return err
+	}
+
+	rule.ID = uuid.New()
+	err := s.db.Pool.QueryRow(ctx, `
+		INSERT INTO cancellation_policy_rules (id, organization_id, name, window_hours, fee_percent, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at, updated_at
+	`, rule.ID, rule.OrganizationID, rule.Name, rule.WindowHours, rule.FeePercent, rule.IsActive,
+	).Scan(&rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create cancellation rule: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateCancellationRule updates a fee window of the cancellation policy
+func (s *SessionService) UpdateCancellationRule(ctx context.Context, rule *models.CancellationPolicyRule) error {
+	if err := s.validateCancellationRule(ctx, rule, &rule.ID); err != nil {
+		return err
+	}
+
+	err := s.db.Pool.QueryRow(ctx, `
+		UPDATE cancellation_policy_rules
+		SET name = $1, window_hours = $2, fee_percent = $3, is_active = $4
+		WHERE id = $5 AND organization_id = $6
+		RETURNING created_at, updated_at
+	`, rule.Name, rule.WindowHours, rule.FeePercent, rule.IsActive, rule.ID, rule.OrganizationID,
+	).Scan(&rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("cancellation rule not found")
+		}
+		return fmt.Errorf("failed to update cancellation rule: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteCancellationRule removes a fee window from the cancellation policy
+func (s *SessionService) DeleteCancellationRule(ctx context.Context, id, orgID uuid.UUID) error {
+	result, err := s.db.Pool.Exec(ctx, `
+		DELETE FROM cancellation_policy_rules WHERE id = $1 AND organization_id = $2
+	`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to delete cancellation rule: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("cancellation rule not found")
+	}
+
+	return nil
+}
+
+func (s *SessionService) validateCancellationRule(ctx context.Context, rule *models.CancellationPolicyRule, excludeID *uuid.UUID) error {
+	if rule.Name == "" {
+		return errors.New("name is required")
+	}
+	if rule.WindowHours <= 0 {
+		return errors.New("window_hours must be greater than zero")
+	}
+	if rule.FeePercent.IsNegative() || rule.FeePercent.GreaterThan(decimal.NewFromInt(100)) {
+		return errors.New("fee_percent must be between 0 and 100")
+	}
+
+	// Each window can only have one rule
+	var exists bool
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM cancellation_policy_rules
+			WHERE organization_id = $1 AND window_hours = $2 AND ($3::uuid IS NULL OR id != $3)
+		)
+	`, rule.OrganizationID, rule.WindowHours, excludeID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check cancellation rule: %w", err)
+	}
+	if exists {
+		return errors.New("a rule with this window already exists")
+	}
+
+	return nil
+}
+
+// PreviewCancellation returns the policy outcome of cancelling a session now, without cancelling it
+func (s *SessionService) PreviewCancellation(ctx context.Context, id, orgID uuid.UUID) (*models.CancellationOutcome, error) {
+	existing, err := s.GetByID(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.evaluateCancellation(ctx, s.db.Pool, &existing.Session, time.Now(), false)
+}
+
+// evaluateCancellation applies the narrowest active rule whose window the cancellation falls in.
+// Sessions cancelled after their start are charged by the narrowest rule.
+func (s *SessionService) evaluateCancellation(ctx context.Context, q rowQuerier, session *models.Session, at time.Time, waive bool) (*models.CancellationOutcome, error) {
+	outcome := &models.CancellationOutcome{
+		HoursBefore: session.ScheduledAt.Sub(at).Hours(),
+		FeePercent:  decimal.Zero,
+	}
+
+	var rule models.CancellationPolicyRule
+	err := q.QueryRow(ctx, `
+		SELECT id, name, window_hours, fee_percent
+		FROM cancellation_policy_rules
+		WHERE organization_id = $1 AND is_active = true AND window_hours > $2 AND fee_percent > 0
+		ORDER BY window_hours ASC
+		LIMIT 1
+	`, session.OrganizationID, outcome.HoursBefore).Scan(&rule.ID, &rule.Name, &rule.WindowHours, &rule.FeePercent)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to evaluate cancellation policy: %w", err)
+	}
+
+	if err != nil {
+		outcome.Description = "Cancelamento sem custos."
+		return outcome, nil
+	}
+
+	outcome.RuleID = &rule.ID
+	outcome.RuleName = rule.Name
+	outcome.FeePercent = rule.FeePercent
+
+	if waive {
+		outcome.Waived = true
+		outcome.Description = "Taxa de cancelamento dispensada."
+		return outcome, nil
+	}
+
+	outcome.FeeCents = int(decimal.NewFromInt(int64(session.PriceCents)).
+		Mul(rule.FeePercent).Div(decimal.NewFromInt(100)).Round(0).IntPart())
+	outcome.Description = fmt.Sprintf("Cancelamento com menos de %dh de antecedência: taxa de %s%% (%.2f€).",
+		rule.WindowHours, rule.FeePercent.String(), float64(outcome.FeeCents)/100)
+
+	return outcome, nil
+}
+
+// applyCancellationFee stores the fee as the session's payment record.
+// Payments already settled are left untouched.
+func (s *SessionService) applyCancellationFee(ctx context.Context, tx pgx.Tx, sessionID uuid.UUID, outcome *models.CancellationOutcome) error {
+	if outcome.FeeCents == 0 {
+		_, err := tx.Exec(ctx, `
+			DELETE FROM session_payments WHERE session_id = $1 AND payment_status = 'unpaid'
+		`, sessionID)
+		if err != nil {
+			return fmt.Errorf("failed to clear session payment: %w", err)
+		}
+		return nil
+	}
+
+	note := outcome.Description
+	_, err := tx.Exec(ctx, `
+		INSERT INTO session_payments (id, session_id, amount_cents, payment_status, kind, due_date, notes)
+		VALUES ($1, $2, $3, 'unpaid', $4, CURRENT_DATE, $5)
+		ON CONFLICT (session_id) DO UPDATE
+		SET amount_cents = EXCLUDED.amount_cents, kind = EXCLUDED.kind,
+		    due_date = EXCLUDED.due_date, notes = EXCLUDED.notes, updated_at = NOW()
+		WHERE session_payments.payment_status = 'unpaid'
+	`, uuid.New(), sessionID, outcome.FeeCents, models.SessionPaymentKindCancellationFee, note)
+	if err != nil {
+		return fmt.Errorf("failed to create cancellation fee payment: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,353 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// CashRegisterService handles the clinic's daily register and its reconciliation
+type CashRegisterService struct {
+	db *database.DB
+}
+
+func NewCashRegisterService(db *database.DB) *CashRegisterService {
+	return &CashRegisterService{db: db}
+}
+
+// RecordTakingInput is a payment received at the register
+type RecordTakingInput struct {
+	SessionPaymentID *uuid.UUID           `json:"session_payment_id"`
+	Method           models.PaymentMethod `json:"method"`
+	AmountCents      int                  `json:"amount_cents"`
+	Description      *string              `json:"description"`
+}
+
+// CloseCashRegisterInput holds the amounts counted at the end of the day
+type CloseCashRegisterInput struct {
+	CountedCashCents  int     `json:"counted_cash_cents"`
+	CountedCardCents  int     `json:"counted_card_cents"`
+	CountedMBWayCents int     `json:"counted_mbway_cents"`
+	Notes             *string `json:"notes"`
+}
+
+const cashRegisterColumns = `
+	id, organization_id, status, opening_float_cents, opened_by, opened_at,
+	counted_cash_cents, counted_card_cents, counted_mbway_cents, closed_by, closed_at, notes
+`
+
+func scanCashRegister(row pgx.Row) (*models.CashRegister, error) {
+	var c models.CashRegister
+	err := row.Scan(
+		&c.ID, &c.OrganizationID, &c.Status, &c.OpeningFloatCents, &c.OpenedBy, &c.OpenedAt,
+		&c.CountedCashCents, &c.CountedCardCents, &c.CountedMBWayCents, &c.ClosedBy, &c.ClosedAt, &c.Notes,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// List returns the registers opened within an optional date range, most recent first
+func (s *CashRegisterService) List(ctx context.Context, orgID uuid.UUID, from, to *time.Time) ([]*models.CashRegister, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT `+cashRegisterColumns+`
+		FROM cash_registers
+		WHERE organization_id = $1
+			AND ($2::timestamptz IS NULL OR opened_at >= $2)
+			AND ($3::timestamptz IS NULL OR opened_at < $3)
+		ORDER BY opened_at DESC
+		LIMIT 100
+	`, orgID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cash registers: %w", err)
+	}
+	defer rows.Close()
+
+	registers := []*models.CashRegister{}
+	for rows.Next() {
+		c, err := scanCashRegister(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan cash register: %w", err)
+		}
+		registers = append(registers, c)
+	}
+	return registers, nil
+}
+
+// GetByID returns a register of the organization
+func (s *CashRegisterService) GetByID(ctx context.Context, id, orgID uuid.UUID) (*models.CashRegister, error) {
+	c, err := scanCashRegister(s.db.Pool.QueryRow(ctx, `
+		SELECT `+cashRegisterColumns+` FROM cash_registers WHERE id = $1 AND organization_id = $2
+	`, id, orgID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("cash register not found")
+		}
+		return nil, fmt.Errorf("failed to get cash register: %w", err)
+	}
+	return c, nil
+}
+
+// GetOpen returns the organization's open register
+func (s *CashRegisterService) GetOpen(ctx context.Context, orgID uuid.UUID) (*models.CashRegister, error) {
+	c, err := scanCashRegister(s.db.Pool.QueryRow(ctx, `
+		SELECT `+cashRegisterColumns+` FROM cash_registers WHERE organization_id = $1 AND status = 'open'
+	`, orgID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("no cash register is open")
+		}
+		return nil, fmt.Errorf("failed to get cash register: %w", err)
+	}
+	return c, nil
+}
+
+// Open starts a register with the cash already in the drawer
+func (s *CashRegisterService) Open(ctx context.Context, orgID, userID uuid.UUID, openingFloatCents int, notes *string) (*models.CashRegister, error) {
+	if openingFloatCents < 0 {
+		return nil, errors.New("opening float cannot be negative")
+	}
+
+	var exists bool
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM cash_registers WHERE organization_id = $1 AND status = 'open')
+	`, orgID).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check cash registers: %w", err)
+	}
+	if exists {
+		return nil, errors.New("a cash register is already open")
+	}
+
+	c, err := scanCashRegister(s.db.Pool.QueryRow(ctx, `
+		INSERT INTO cash_registers (organization_id, opening_float_cents, opened_by, notes)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+cashRegisterColumns, orgID, openingFloatCents, userID, notes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cash register: %w", err)
+	}
+	return c, nil
+}
+
+// RecordTaking adds a payment received at an open register. A taking against a session payment
+// also marks it paid, or partially paid while the recorded takings are below its amount.
+func (s *CashRegisterService) RecordTaking(ctx context.Context, id, orgID, userID uuid.UUID, input RecordTakingInput) (*models.CashRegisterEntry, error) {
+	if !models.IsCashRegisterMethod(input.Method) {
+		return nil, errors.New("method must be one of cash, card, mbway")
+	}
+	if input.AmountCents <= 0 {
+		return nil, errors.New("amount must be greater than zero")
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status models.CashRegisterStatus
+	err = tx.QueryRow(ctx, `
+		SELECT status FROM cash_registers WHERE id = $1 AND organization_id = $2 FOR UPDATE
+	`, id, orgID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("cash register not found")
+		}
+		return nil, fmt.Errorf("failed to get cash register: %w", err)
+	}
+	if status != models.CashRegisterOpen {
+		return nil, errors.New("cash register is closed")
+	}
+
+	if input.SessionPaymentID != nil {
+		if err := checkPeriodsOpen(ctx, tx, orgID, time.Now()); err != nil {
+			return nil, err
+		}
+		var exists bool
+		err = tx.QueryRow(ctx, `
+			SELECT EXISTS(
+				SELECT 1 FROM session_payments sp
+				JOIN sessions s ON s.id = sp.session_id
+				WHERE sp.id = $1 AND s.organization_id = $2 AND s.deleted_at IS NULL
+			)
+		`, *input.SessionPaymentID, orgID).Scan(&exists)
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify session payment: %w", err)
+		}
+		if !exists {
+			return nil, errors.New("session payment not found")
+		}
+	}
+
+	entry := models.CashRegisterEntry{
+		CashRegisterID:   id,
+		SessionPaymentID: input.SessionPaymentID,
+		Method:           input.Method,
+		AmountCents:      input.AmountCents,
+		Description:      input.Description,
+		RecordedBy:       &userID,
+	}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO cash_register_entries (cash_register_id, organization_id, session_payment_id, method, amount_cents, description, recorded_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`, id, orgID, input.SessionPaymentID, input.Method, input.AmountCents, input.Description, userID).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record taking: %w", err)
+	}
+
+	if input.SessionPaymentID != nil {
+		_, err = tx.Exec(ctx, `
+			UPDATE session_payments sp
+			SET payment_status = CASE WHEN recorded.total >= sp.amount_cents THEN 'paid' ELSE 'partial' END,
+				payment_method = $2,
+				paid_at = CASE WHEN recorded.total >= sp.amount_cents THEN NOW() ELSE sp.paid_at END,
+				updated_at = NOW()
+			FROM (
+				SELECT COALESCE(SUM(amount_cents), 0) AS total
+				FROM cash_register_entries WHERE session_payment_id = $1
+			) recorded
+			WHERE sp.id = $1
+		`, *input.SessionPaymentID, input.Method)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update session payment: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return &entry, nil
+}
+
+// Close records the counted amounts, ends the register and returns its reconciliation
+func (s *CashRegisterService) Close(ctx context.Context, id, orgID, userID uuid.UUID, input CloseCashRegisterInput) (*models.CashRegisterReconciliation, error) {
+	if input.CountedCashCents < 0 || input.CountedCardCents < 0 || input.CountedMBWayCents < 0 {
+		return nil, errors.New("counted amounts cannot be negative")
+	}
+
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE cash_registers
+		SET status = 'closed', counted_cash_cents = $1, counted_card_cents = $2, counted_mbway_cents = $3,
+			notes = COALESCE($4, notes), closed_by = $5, closed_at = NOW()
+		WHERE id = $6 AND organization_id = $7 AND status = 'open'
+	`, input.CountedCashCents, input.CountedCardCents, input.CountedMBWayCents, input.Notes, userID, id, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to close cash register: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, errors.New("cash register not found or already closed")
+	}
+
+	return s.Reconciliation(ctx, id, orgID)
+}
+
+// Reconciliation compares a register's recorded takings with the counted amounts per method and
+// lists the sessions paid by cash, card or MB Way during the register that were not recorded in it
+func (s *CashRegisterService) Reconciliation(ctx context.Context, id, orgID uuid.UUID) (*models.CashRegisterReconciliation, error) {
+	register, err := s.GetByID(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.CashRegisterReconciliation{
+		Register:           register,
+		Entries:            []*models.CashRegisterEntry{},
+		UnrecordedPayments: []*models.UnrecordedSessionPayment{},
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT e.id, e.cash_register_id, e.session_payment_id, e.method, e.amount_cents, e.description,
+			e.recorded_by, e.created_at, c.name
+		FROM cash_register_entries e
+		LEFT JOIN session_payments sp ON sp.id = e.session_payment_id
+		LEFT JOIN sessions s ON s.id = sp.session_id
+		LEFT JOIN patients p ON p.id = s.patient_id
+		LEFT JOIN clients c ON c.id = p.client_id
+		WHERE e.cash_register_id = $1
+		ORDER BY e.created_at
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get register entries: %w", err)
+	}
+	defer rows.Close()
+
+	recorded := make(map[models.PaymentMethod]int)
+	for rows.Next() {
+		var e models.CashRegisterEntry
+		if err := rows.Scan(&e.ID, &e.CashRegisterID, &e.SessionPaymentID, &e.Method, &e.AmountCents, &e.Description,
+			&e.RecordedBy, &e.CreatedAt, &e.PatientName); err != nil {
+			return nil, fmt.Errorf("failed to scan register entry: %w", err)
+		}
+		report.Entries = append(report.Entries, &e)
+		recorded[e.Method] += e.AmountCents
+		report.TotalTakingsCents += e.AmountCents
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read register entries: %w", err)
+	}
+
+	counted := map[models.PaymentMethod]*int{
+		models.PaymentMethodCash:  register.CountedCashCents,
+		models.PaymentMethodCard:  register.CountedCardCents,
+		models.PaymentMethodMBWay: register.CountedMBWayCents,
+	}
+	for _, method := range []models.PaymentMethod{models.PaymentMethodCash, models.PaymentMethodCard, models.PaymentMethodMBWay} {
+		line := models.CashRegisterReconciliationLine{
+			Method:        method,
+			ExpectedCents: recorded[method],
+			CountedCents:  counted[method],
+		}
+		if method == models.PaymentMethodCash {
+			line.ExpectedCents += register.OpeningFloatCents
+		}
+		if line.CountedCents != nil {
+			line.DifferenceCents = *line.CountedCents - line.ExpectedCents
+			line.Discrepancy = line.DifferenceCents != 0
+		}
+		report.HasDiscrepancies = report.HasDiscrepancies || line.Discrepancy
+		report.Lines = append(report.Lines, line)
+	}
+
+	until := time.Now()
+	if register.ClosedAt != nil {
+		until = *register.ClosedAt
+	}
+	unrecorded, err := s.db.Pool.Query(ctx, `
+		SELECT sp.id, sp.session_id, COALESCE(c.name, ''), sp.payment_method, sp.amount_cents, sp.paid_at
+		FROM session_payments sp
+		JOIN sessions s ON s.id = sp.session_id
+		LEFT JOIN patients p ON p.id = s.patient_id
+		LEFT JOIN clients c ON c.id = p.client_id
+		WHERE s.organization_id = $1 AND s.deleted_at IS NULL
+			AND sp.payment_status = 'paid' AND sp.payment_method IN ('cash', 'card', 'mbway')
+			AND sp.paid_at >= $2 AND sp.paid_at <= $3
+			AND NOT EXISTS (SELECT 1 FROM cash_register_entries e WHERE e.session_payment_id = sp.id)
+		ORDER BY sp.paid_at
+	`, orgID, register.OpenedAt, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unrecorded payments: %w", err)
+	}
+	defer unrecorded.Close()
+
+	for unrecorded.Next() {
+		var u models.UnrecordedSessionPayment
+		if err := unrecorded.Scan(&u.SessionPaymentID, &u.SessionID, &u.PatientName, &u.Method, &u.AmountCents, &u.PaidAt); err != nil {
+			return nil, fmt.Errorf("failed to scan unrecorded payment: %w", err)
+		}
+		report.UnrecordedPayments = append(report.UnrecordedPayments, &u)
+	}
+	if err := unrecorded.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read unrecorded payments: %w", err)
+	}
+	report.HasDiscrepancies = report.HasDiscrepancies || len(report.UnrecordedPayments) > 0
+
+	return report, nil
+}
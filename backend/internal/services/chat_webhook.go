@@ -0,0 +1,362 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxChatMessageLength bounds the text posted to a channel; longer messages are cut
+const maxChatMessageLength = 3000
+
+// ChatWebhookService keeps the Slack and Teams webhooks of organizations' team channels and
+// posts messages to them
+type ChatWebhookService struct {
+	db            *database.DB
+	encryptionKey []byte
+	client        *http.Client
+}
+
+func NewChatWebhookService(db *database.DB, encryptionKey string) *ChatWebhookService {
+	return &ChatWebhookService{
+		db:            db,
+		encryptionKey: secretKey(encryptionKey),
+		client:        &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// ChatWebhookInput is the request body for adding or changing a chat webhook
+type ChatWebhookInput struct {
+	Provider             models.ChatProvider `json:"provider"`
+	Name                 string              `json:"name"`
+	WebhookURL           string              `json:"webhook_url"` // kept when left empty on update
+	IsActive             *bool               `json:"is_active"`
+	NotifyActionFailures bool                `json:"notify_action_failures"`
+}
+
+// validateChatWebhookURL checks a webhook URL is an HTTPS URL of the provider, so messages are
+// never posted anywhere else
+func validateChatWebhookURL(provider models.ChatProvider, raw string) error {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Scheme != "https" || u.User != nil || u.Port() != "" {
+		return errors.New("invalid webhook URL")
+	}
+	host := strings.ToLower(u.Hostname())
+
+	switch provider {
+	case models.ChatProviderSlack:
+		if host != "hooks.slack.com" {
+			return errors.New("Slack webhook URLs must be on hooks.slack.com")
+		}
+	case models.ChatProviderTeams:
+		if !strings.HasSuffix(host, ".webhook.office.com") && !strings.HasSuffix(host, ".logic.azure.com") &&
+			!strings.HasSuffix(host, ".api.powerplatform.com") {
+			return errors.New("Teams webhook URLs must be Teams incoming webhook or workflow URLs")
+		}
+	default:
+		return errors.New("provider must be slack or teams")
+	}
+	return nil
+}
+
+// chatWebhookURLHint shows which webhook is set without revealing it, e.g. hooks.slack.com/…X9k2
+func chatWebhookURLHint(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	tail := raw
+	if len(tail) > 4 {
+		tail = tail[len(tail)-4:]
+	}
+	return u.Hostname() + "/…" + tail
+}
+
+// truncateChatMessage cuts a message to maxChatMessageLength characters
+func truncateChatMessage(text string) string {
+	if utf8.RuneCountInString(text) <= maxChatMessageLength {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:maxChatMessageLength-1]) + "…"
+}
+
+// chatPayload builds the message a provider's incoming webhook expects. Teams gets an Adaptive
+// Card, which both its incoming webhooks and workflow webhooks accept.
+func chatPayload(provider models.ChatProvider, text string) ([]byte, error) {
+	text = truncateChatMessage(text)
+	if provider == models.ChatProviderTeams {
+		return json.Marshal(map[string]interface{}{
+			"type": "message",
+			"attachments": []map[string]interface{}{{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": map[string]interface{}{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.4",
+					"body": []map[string]interface{}{
+						{"type": "TextBlock", "text": text, "wrap": true},
+					},
+				},
+			}},
+		})
+	}
+	return json.Marshal(map[string]string{"text": text})
+}
+
+const chatWebhookColumns = `id, organization_id, provider, name, webhook_url_hint, is_active, notify_action_failures,
+	last_sent_at, last_error, created_by, created_at, updated_at`
+
+func scanChatWebhook(row pgx.Row) (*models.ChatWebhook, error) {
+	var w models.ChatWebhook
+	err := row.Scan(&w.ID, &w.OrganizationID, &w.Provider, &w.Name, &w.WebhookURLHint, &w.IsActive, &w.NotifyActionFailures,
+		&w.LastSentAt, &w.LastError, &w.CreatedBy, &w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// List returns the organization's chat webhooks
+func (s *ChatWebhookService) List(ctx context.Context, orgID uuid.UUID) ([]*models.ChatWebhook, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT `+chatWebhookColumns+` FROM chat_webhooks
+		WHERE organization_id = $1
+		ORDER BY name
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chat webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := []*models.ChatWebhook{}
+	for rows.Next() {
+		w, err := scanChatWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan chat webhook: %w", err)
+		}
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, rows.Err()
+}
+
+// GetByID returns one of the organization's chat webhooks
+func (s *ChatWebhookService) GetByID(ctx context.Context, id, orgID uuid.UUID) (*models.ChatWebhook, error) {
+	w, err := scanChatWebhook(s.db.Pool.QueryRow(ctx, `
+		SELECT `+chatWebhookColumns+` FROM chat_webhooks WHERE id = $1 AND organization_id = $2
+	`, id, orgID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("chat webhook not found")
+		}
+		return nil, fmt.Errorf("failed to get chat webhook: %w", err)
+	}
+	return w, nil
+}
+
+// Create adds a team channel's webhook
+func (s *ChatWebhookService) Create(ctx context.Context, orgID, userID uuid.UUID, input ChatWebhookInput) (*models.ChatWebhook, error) {
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		return nil, errors.New("name is required")
+	}
+	if err := validateChatWebhookURL(input.Provider, input.WebhookURL); err != nil {
+		return nil, err
+	}
+	encrypted, err := encryptSecret(s.encryptionKey, strings.TrimSpace(input.WebhookURL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt webhook URL: %w", err)
+	}
+	isActive := input.IsActive == nil || *input.IsActive
+
+	w, err := scanChatWebhook(s.db.Pool.QueryRow(ctx, `
+		INSERT INTO chat_webhooks (organization_id, provider, name, webhook_url_encrypted, webhook_url_hint,
+			is_active, notify_action_failures, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING `+chatWebhookColumns,
+		orgID, input.Provider, input.Name, encrypted, chatWebhookURLHint(strings.TrimSpace(input.WebhookURL)),
+		isActive, input.NotifyActionFailures, userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create chat webhook: %w", err)
+	}
+	return w, nil
+}
+
+// Update changes a chat webhook; its URL is only replaced when a new one is given
+func (s *ChatWebhookService) Update(ctx context.Context, id, orgID uuid.UUID, input ChatWebhookInput) (*models.ChatWebhook, error) {
+	existing, err := s.GetByID(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		return nil, errors.New("name is required")
+	}
+	if input.Provider == "" {
+		input.Provider = existing.Provider
+	}
+	isActive := existing.IsActive
+	if input.IsActive != nil {
+		isActive = *input.IsActive
+	}
+
+	var encrypted, hint *string
+	if input.WebhookURL != "" {
+		if err := validateChatWebhookURL(input.Provider, input.WebhookURL); err != nil {
+			return nil, err
+		}
+		value, err := encryptSecret(s.encryptionKey, strings.TrimSpace(input.WebhookURL))
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt webhook URL: %w", err)
+		}
+		h := chatWebhookURLHint(strings.TrimSpace(input.WebhookURL))
+		encrypted, hint = &value, &h
+	} else if input.Provider != existing.Provider {
+		return nil, errors.New("a new webhook URL is required to change the provider")
+	}
+
+	w, err := scanChatWebhook(s.db.Pool.QueryRow(ctx, `
+		UPDATE chat_webhooks
+		SET provider = $1, name = $2, is_active = $3, notify_action_failures = $4,
+			webhook_url_encrypted = COALESCE($5, webhook_url_encrypted),
+			webhook_url_hint = COALESCE($6, webhook_url_hint),
+			last_error = CASE WHEN $5::text IS NULL THEN last_error END
+		WHERE id = $7 AND organization_id = $8
+		RETURNING `+chatWebhookColumns,
+		input.Provider, input.Name, isActive, input.NotifyActionFailures, encrypted, hint, id, orgID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update chat webhook: %w", err)
+	}
+	return w, nil
+}
+
+// Delete removes a chat webhook
+func (s *ChatWebhookService) Delete(ctx context.Context, id, orgID uuid.UUID) error {
+	result, err := s.db.Pool.Exec(ctx, `DELETE FROM chat_webhooks WHERE id = $1 AND organization_id = $2`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to delete chat webhook: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("chat webhook not found")
+	}
+	return nil
+}
+
+// Test posts a test message to a chat webhook
+func (s *ChatWebhookService) Test(ctx context.Context, id, orgID uuid.UUID) error {
+	return s.sendTo(ctx, orgID, `id = $2`, []interface{}{id},
+		"Mensagem de teste do controlwise: este canal está ligado e vai receber as notificações da equipa.", false)
+}
+
+// SendChat posts a message to one of the organization's active chat webhooks, or to all of them
+// when webhookID is nil. It satisfies the workflow executor's ChatSender.
+func (s *ChatWebhookService) SendChat(ctx context.Context, orgID uuid.UUID, webhookID *uuid.UUID, text string) error {
+	if webhookID != nil {
+		return s.sendTo(ctx, orgID, `id = $2 AND is_active = true`, []interface{}{*webhookID}, text, false)
+	}
+	return s.sendTo(ctx, orgID, `is_active = true`, nil, text, true)
+}
+
+// SendAlert posts a message to the organization's active chat webhooks that asked to be told
+// about failed workflow actions
+func (s *ChatWebhookService) SendAlert(ctx context.Context, orgID uuid.UUID, text string) error {
+	return s.sendTo(ctx, orgID, `is_active = true AND notify_action_failures = true`, nil, text, true)
+}
+
+// sendTo posts text to the organization's webhooks matching where, whose arguments start at $2.
+// When none matches it returns nil if allowNone is set, and a not found error otherwise.
+func (s *ChatWebhookService) sendTo(ctx context.Context, orgID uuid.UUID, where string, args []interface{}, text string, allowNone bool) error {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, provider, webhook_url_encrypted FROM chat_webhooks
+		WHERE organization_id = $1 AND `+where,
+		append([]interface{}{orgID}, args...)...)
+	if err != nil {
+		return fmt.Errorf("failed to get chat webhooks: %w", err)
+	}
+	type target struct {
+		id        uuid.UUID
+		provider  models.ChatProvider
+		encrypted string
+	}
+	var targets []target
+	for rows.Next() {
+		var t target
+		if err := rows.Scan(&t.id, &t.provider, &t.encrypted); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan chat webhook: %w", err)
+		}
+		targets = append(targets, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to get chat webhooks: %w", err)
+	}
+
+	if len(targets) == 0 {
+		if allowNone {
+			log.Printf("[ChatWebhook] No chat webhooks for organization %s, skipping message", orgID)
+			return nil
+		}
+		return errors.New("chat webhook not found")
+	}
+
+	var errs []error
+	for _, t := range targets {
+		sendErr := s.post(ctx, t.provider, t.encrypted, text)
+		if sendErr != nil {
+			errs = append(errs, sendErr)
+			_, err = s.db.Pool.Exec(ctx, `UPDATE chat_webhooks SET last_error = $1 WHERE id = $2`, sendErr.Error(), t.id)
+		} else {
+			_, err = s.db.Pool.Exec(ctx, `UPDATE chat_webhooks SET last_sent_at = NOW(), last_error = NULL WHERE id = $1`, t.id)
+		}
+		if err != nil {
+			log.Printf("[ChatWebhook] Failed to record delivery to webhook %s: %v", t.id, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// post sends a message to a webhook
+func (s *ChatWebhookService) post(ctx context.Context, provider models.ChatProvider, encrypted, text string) error {
+	webhookURL, err := decryptSecret(s.encryptionKey, encrypted)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt webhook URL: %w", err)
+	}
+	payload, err := chatPayload(provider, text)
+	if err != nil {
+		return fmt.Errorf("failed to build %s message: %w", provider, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to build %s request: %w", provider, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post to %s: %w", provider, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %d: %s", provider, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/controlwise/backend/internal/models"
+)
+
+func TestValidateChatWebhookURL(t *testing.T) {
+	tests := []struct {
+		provider models.ChatProvider
+		url      string
+		valid    bool
+	}{
+		{models.ChatProviderSlack, "https://hooks.slack.com/services/T000/B000/XXXX", true},
+		{models.ChatProviderSlack, "http://hooks.slack.com/services/T000/B000/XXXX", false},
+		{models.ChatProviderSlack, "https://hooks.slack.com.evil.example/services/x", false},
+		{models.ChatProviderSlack, "https://hooks.slack.com:8443/services/x", false},
+		{models.ChatProviderTeams, "https://contoso.webhook.office.com/webhookb2/abc", true},
+		{models.ChatProviderTeams, "https://prod-12.westeurope.logic.azure.com/workflows/abc", true},
+		{models.ChatProviderTeams, "https://webhook.office.com.example/abc", false},
+		{models.ChatProviderTeams, "https://hooks.slack.com/services/x", false},
+		{"discord", "https://hooks.slack.com/services/x", false},
+	}
+
+	for _, tt := range tests {
+		err := validateChatWebhookURL(tt.provider, tt.url)
+		if (err == nil) != tt.valid {
+			t.Errorf("validateChatWebhookURL(%q, %q) = %v, want valid %v", tt.provider, tt.url, err, tt.valid)
+		}
+	}
+}
+
+func TestChatPayload(t *testing.T) {
+	slack, err := chatPayload(models.ChatProviderSlack, "Orçamento aprovado")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(slack) != `{"text":"Orçamento aprovado"}` {
+		t.Errorf("slack payload = %s", slack)
+	}
+
+	teams, err := chatPayload(models.ChatProviderTeams, strings.Repeat("a", maxChatMessageLength+10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var card struct {
+		Attachments []struct {
+			Content struct {
+				Body []struct {
+					Text string `json:"text"`
+				} `json:"body"`
+			} `json:"content"`
+		} `json:"attachments"`
+	}
+	if err := json.Unmarshal(teams, &card); err != nil {
+		t.Fatal(err)
+	}
+	text := card.Attachments[0].Content.Body[0].Text
+	if n := len([]rune(text)); n != maxChatMessageLength {
+		t.Errorf("teams message has %d characters, want %d", n, maxChatMessageLength)
+	}
+}
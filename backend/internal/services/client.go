@@ -4,11 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/controlwise/backend/internal/database"
 	"github.com/controlwise/backend/internal/models"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
 )
 
 type ClientService struct {
@@ -19,183 +23,193 @@ func NewClientService(db *database.DB) *ClientService {
 	return &ClientService{db: db}
 }
 
-// List returns all clients for an organization with pagination
-func (s *ClientService) List(ctx context.Context, orgID uuid.UUID, limit, offset int) ([]*models.Client, int, error) {
-	// Get total count
+const clientColumns = `
+	id, organization_id, name, email, phone, address, tax_id,
+	notes, segment, user_id, created_by, created_at, updated_at`
+
+func scanClient(row pgx.Row) (*models.Client, error) {
+	var c models.Client
+	err := row.Scan(
+		&c.ID,
+		&c.OrganizationID,
+		&c.Name,
+		&c.Email,
+		&c.Phone,
+		&c.Address,
+		&c.TaxID,
+		&c.Notes,
+		&c.Segment,
+		&c.UserID,
+		&c.CreatedBy,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// ClientFilters narrows the client list
+type ClientFilters struct {
+	Search            string // matches name, email, phone or tax ID
+	Segment           string
+	HasActiveProjects *bool
+	Sort              string // "name" or "created_at" (default, newest first)
+}
+
+// List returns the organization's clients matching the filters, with pagination
+func (s *ClientService) List(ctx context.Context, orgID uuid.UUID, filters ClientFilters, limit, offset int) ([]*models.Client, int, error) {
+	where := "WHERE c.organization_id = $1 AND c.deleted_at IS NULL"
+	args := []interface{}{orgID}
+
+	if filters.Search != "" {
+		args = append(args, "%"+filters.Search+"%")
+		n := len(args)
+		where += fmt.Sprintf(" AND (c.name ILIKE $%d OR c.email ILIKE $%d OR c.phone ILIKE $%d OR c.tax_id ILIKE $%d)", n, n, n, n)
+	}
+	if filters.Segment != "" {
+		args = append(args, filters.Segment)
+		where += fmt.Sprintf(" AND c.segment = $%d", len(args))
+	}
+	if filters.HasActiveProjects != nil {
+		not := ""
+		if !*filters.HasActiveProjects {
+			not = "NOT "
+		}
+		where += " AND " + not + `EXISTS (
+			SELECT 1 FROM worksheets w
+			JOIN budgets b ON b.worksheet_id = w.id AND b.deleted_at IS NULL
+			JOIN projects p ON p.budget_id = b.id AND p.deleted_at IS NULL
+			WHERE w.client_id = c.id AND w.deleted_at IS NULL
+				AND p.status IN ('in_progress', 'on_hold')
+		)`
+	}
+
 	var total int
-	err := s.db.Pool.QueryRow(ctx, `
-		SELECT COUNT(*) FROM clients 
-		WHERE organization_id = $1 AND deleted_at IS NULL
-	`, orgID).Scan(&total)
+	err := s.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM clients c "+where, args...).Scan(&total)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count clients: %w", err)
 	}
 
-	// Get clients
-	rows, err := s.db.Pool.Query(ctx, `
-		SELECT 
-			id, organization_id, name, email, phone, address, tax_id, 
-			notes, user_id, created_by, created_at, updated_at
-		FROM clients
-		WHERE organization_id = $1 AND deleted_at IS NULL
-		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3
-	`, orgID, limit, offset)
+	orderBy := "c.created_at DESC"
+	if filters.Sort == "name" {
+		orderBy = "c.name ASC"
+	}
+
+	args = append(args, limit, offset)
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM clients c
+		%s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, prefixColumns("c", clientColumns), where, orderBy, len(args)-1, len(args))
+
+	rows, err := s.db.Pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to query clients: %w", err)
 	}
 	defer rows.Close()
 
-	var clients []*models.Client
+	clients := []*models.Client{}
 	for rows.Next() {
-		var c models.Client
-		err := rows.Scan(
-			&c.ID,
-			&c.OrganizationID,
-			&c.Name,
-			&c.Email,
-			&c.Phone,
-			&c.Address,
-			&c.TaxID,
-			&c.Notes,
-			&c.UserID,
-			&c.CreatedBy,
-			&c.CreatedAt,
-			&c.UpdatedAt,
-		)
+		c, err := scanClient(rows)
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to scan client: %w", err)
 		}
-		clients = append(clients, &c)
+		clients = append(clients, c)
 	}
 
 	return clients, total, nil
 }
 
-// Search clients by name or email
-func (s *ClientService) Search(ctx context.Context, orgID uuid.UUID, query string, limit int) ([]*models.Client, error) {
-	searchPattern := "%" + query + "%"
-	
-	rows, err := s.db.Pool.Query(ctx, `
-		SELECT 
-			id, organization_id, name, email, phone, address, tax_id, 
-			notes, user_id, created_by, created_at, updated_at
-		FROM clients
-		WHERE organization_id = $1 
-			AND deleted_at IS NULL
-			AND (name ILIKE $2 OR email ILIKE $2)
-		ORDER BY name
-		LIMIT $3
-	`, orgID, searchPattern, limit)
-	if err != nil {
-		return nil, fmt.Errorf("failed to search clients: %w", err)
-	}
-	defer rows.Close()
-
-	var clients []*models.Client
-	for rows.Next() {
-		var c models.Client
-		err := rows.Scan(
-			&c.ID,
-			&c.OrganizationID,
-			&c.Name,
-			&c.Email,
-			&c.Phone,
-			&c.Address,
-			&c.TaxID,
-			&c.Notes,
-			&c.UserID,
-			&c.CreatedBy,
-			&c.CreatedAt,
-			&c.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan client: %w", err)
-		}
-		clients = append(clients, &c)
+// prefixColumns qualifies a comma separated column list with a table alias
+func prefixColumns(alias, columns string) string {
+	parts := strings.Split(columns, ",")
+	for i, part := range parts {
+		parts[i] = alias + "." + strings.TrimSpace(part)
 	}
-
-	return clients, nil
+	return strings.Join(parts, ", ")
 }
 
 // GetByID returns a single client by ID
 func (s *ClientService) GetByID(ctx context.Context, id, orgID uuid.UUID) (*models.Client, error) {
-	var c models.Client
-	err := s.db.Pool.QueryRow(ctx, `
-		SELECT 
-			id, organization_id, name, email, phone, address, tax_id, 
-			notes, user_id, created_by, created_at, updated_at
+	c, err := scanClient(s.db.Pool.QueryRow(ctx, `
+		SELECT `+clientColumns+`
 		FROM clients
 		WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
-	`, id, orgID).Scan(
-		&c.ID,
-		&c.OrganizationID,
-		&c.Name,
-		&c.Email,
-		&c.Phone,
-		&c.Address,
-		&c.TaxID,
-		&c.Notes,
-		&c.UserID,
-		&c.CreatedBy,
-		&c.CreatedAt,
-		&c.UpdatedAt,
-	)
+	`, id, orgID))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, errors.New("client not found")
 		}
 		return nil, fmt.Errorf("failed to get client: %w", err)
 	}
+	if c.EmailSuppression, err = emailSuppression(ctx, s.db.Pool, orgID, c.Email); err != nil {
+		return nil, err
+	}
 
-	return &c, nil
+	return c, nil
 }
 
-// Create creates a new client
-func (s *ClientService) Create(ctx context.Context, client *models.Client) error {
+// Create creates a new client. Clients that look like the new one (same phone, tax ID or
+// name) don't block creation and are returned as possible duplicates.
+func (s *ClientService) Create(ctx context.Context, client *models.Client) ([]*ClientDuplicate, error) {
 	// Validate required fields
 	if client.Name == "" {
-		return errors.New("client name is required")
+		return nil, errors.New("client name is required")
 	}
 	if client.Email == "" {
-		return errors.New("client email is required")
+		return nil, errors.New("client email is required")
 	}
 	if client.Phone == "" {
-		return errors.New("client phone is required")
+		return nil, errors.New("client phone is required")
+	}
+	normalized, err := normalizeOrgPhone(ctx, s.db.Pool, client.OrganizationID, client.Phone)
+	if err != nil {
+		return nil, err
 	}
+	client.Phone = normalized
 
 	// Check if email already exists for this organization
 	var exists bool
-	err := s.db.Pool.QueryRow(ctx, `
+	err = s.db.Pool.QueryRow(ctx, `
 		SELECT EXISTS(
-			SELECT 1 FROM clients 
+			SELECT 1 FROM clients
 			WHERE organization_id = $1 AND email = $2 AND deleted_at IS NULL
 		)
 	`, client.OrganizationID, client.Email).Scan(&exists)
 	if err != nil {
-		return fmt.Errorf("failed to check email existence: %w", err)
+		return nil, fmt.Errorf("failed to check email existence: %w", err)
 	}
 	if exists {
-		return errors.New("client with this email already exists")
+		return nil, errors.New("client with this email already exists")
+	}
+
+	duplicates, err := s.FindDuplicates(ctx, client.OrganizationID, client, uuid.Nil)
+	if err != nil {
+		return nil, err
 	}
 
 	// Generate ID
 	client.ID = uuid.New()
 
 	// Insert client
-	_, err = s.db.Pool.Exec(ctx, `
+	err = s.db.Pool.QueryRow(ctx, `
 		INSERT INTO clients (
-			id, organization_id, name, email, phone, address, tax_id, 
-			notes, user_id, created_by
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			id, organization_id, name, email, phone, address, tax_id,
+			notes, segment, user_id, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING created_at, updated_at
 	`, client.ID, client.OrganizationID, client.Name, client.Email, client.Phone,
-		client.Address, client.TaxID, client.Notes, client.UserID, client.CreatedBy)
-	
+		client.Address, client.TaxID, client.Notes, client.Segment, client.UserID, client.CreatedBy,
+	).Scan(&client.CreatedAt, &client.UpdatedAt)
 	if err != nil {
-		return fmt.Errorf("failed to create client: %w", err)
+		return nil, fmt.Errorf("failed to create client: %w", err)
 	}
 
-	return nil
+	return duplicates, nil
 }
 
 // Update updates an existing client
@@ -210,12 +224,17 @@ func (s *ClientService) Update(ctx context.Context, id, orgID uuid.UUID, client
 	if client.Phone == "" {
 		return errors.New("client phone is required")
 	}
+	normalized, err := normalizeOrgPhone(ctx, s.db.Pool, orgID, client.Phone)
+	if err != nil {
+		return err
+	}
+	client.Phone = normalized
 
 	// Check if client exists and belongs to organization
 	var exists bool
-	err := s.db.Pool.QueryRow(ctx, `
+	err = s.db.Pool.QueryRow(ctx, `
 		SELECT EXISTS(
-			SELECT 1 FROM clients 
+			SELECT 1 FROM clients
 			WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
 		)
 	`, id, orgID).Scan(&exists)
@@ -230,7 +249,7 @@ func (s *ClientService) Update(ctx context.Context, id, orgID uuid.UUID, client
 	var emailTaken bool
 	err = s.db.Pool.QueryRow(ctx, `
 		SELECT EXISTS(
-			SELECT 1 FROM clients 
+			SELECT 1 FROM clients
 			WHERE organization_id = $1 AND email = $2 AND id != $3 AND deleted_at IS NULL
 		)
 	`, orgID, client.Email, id).Scan(&emailTaken)
@@ -244,12 +263,12 @@ func (s *ClientService) Update(ctx context.Context, id, orgID uuid.UUID, client
 	// Update client
 	result, err := s.db.Pool.Exec(ctx, `
 		UPDATE clients
-		SET name = $1, email = $2, phone = $3, address = $4, 
-		    tax_id = $5, notes = $6
-		WHERE id = $7 AND organization_id = $8 AND deleted_at IS NULL
+		SET name = $1, email = $2, phone = $3, address = $4,
+		    tax_id = $5, notes = $6, segment = $7
+		WHERE id = $8 AND organization_id = $9 AND deleted_at IS NULL
 	`, client.Name, client.Email, client.Phone, client.Address,
-		client.TaxID, client.Notes, id, orgID)
-	
+		client.TaxID, client.Notes, client.Segment, id, orgID)
+
 	if err != nil {
 		return fmt.Errorf("failed to update client: %w", err)
 	}
@@ -267,7 +286,7 @@ func (s *ClientService) Delete(ctx context.Context, id, orgID uuid.UUID) error {
 	var hasWorksheets bool
 	err := s.db.Pool.QueryRow(ctx, `
 		SELECT EXISTS(
-			SELECT 1 FROM worksheets 
+			SELECT 1 FROM worksheets
 			WHERE client_id = $1 AND deleted_at IS NULL
 		)
 	`, id).Scan(&hasWorksheets)
@@ -284,7 +303,7 @@ func (s *ClientService) Delete(ctx context.Context, id, orgID uuid.UUID) error {
 		SET deleted_at = CURRENT_TIMESTAMP
 		WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
 	`, id, orgID)
-	
+
 	if err != nil {
 		return fmt.Errorf("failed to delete client: %w", err)
 	}
@@ -343,3 +362,231 @@ func (s *ClientService) GetStats(ctx context.Context, orgID uuid.UUID) (map[stri
 
 	return stats, nil
 }
+
+// ============ Duplicate Detection ============
+
+// ClientDuplicate is an existing client that looks like the one being entered
+type ClientDuplicate struct {
+	Client    *models.Client `json:"client"`
+	MatchedOn []string       `json:"matched_on"` // email, phone, tax_id, name
+}
+
+var nonDigits = regexp.MustCompile(`\D`)
+
+// phoneMatchKey keeps the last 9 digits so "+351 912 345 678" matches "912345678"
+func phoneMatchKey(phone string) string {
+	digits := nonDigits.ReplaceAllString(phone, "")
+	if len(digits) > 9 {
+		digits = digits[len(digits)-9:]
+	}
+	return digits
+}
+
+func normalizeTaxID(taxID *string) string {
+	if taxID == nil {
+		return ""
+	}
+	return strings.ToUpper(strings.Join(strings.Fields(*taxID), ""))
+}
+
+// FindDuplicates returns clients of the organization sharing the email, phone, tax ID or
+// name of the given client. excludeID skips the client itself when editing.
+func (s *ClientService) FindDuplicates(ctx context.Context, orgID uuid.UUID, client *models.Client, excludeID uuid.UUID) ([]*ClientDuplicate, error) {
+	email := strings.ToLower(strings.TrimSpace(client.Email))
+	phone := phoneMatchKey(client.Phone)
+	taxID := normalizeTaxID(client.TaxID)
+	name := strings.ToLower(strings.Join(strings.Fields(client.Name), " "))
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT `+clientColumns+`
+		FROM clients
+		WHERE organization_id = $1 AND deleted_at IS NULL AND id <> $2
+			AND (
+				($3 <> '' AND LOWER(email) = $3)
+				OR ($4 <> '' AND RIGHT(regexp_replace(phone, '\D', '', 'g'), 9) = $4)
+				OR ($5 <> '' AND UPPER(regexp_replace(COALESCE(tax_id, ''), '\s', '', 'g')) = $5)
+				OR ($6 <> '' AND LOWER(regexp_replace(TRIM(name), '\s+', ' ', 'g')) = $6)
+			)
+		ORDER BY created_at DESC
+		LIMIT 10
+	`, orgID, excludeID, email, phone, taxID, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check duplicate clients: %w", err)
+	}
+	defer rows.Close()
+
+	duplicates := []*ClientDuplicate{}
+	for rows.Next() {
+		c, err := scanClient(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan client: %w", err)
+		}
+
+		var matched []string
+		if email != "" && strings.ToLower(c.Email) == email {
+			matched = append(matched, "email")
+		}
+		if phone != "" && phoneMatchKey(c.Phone) == phone {
+			matched = append(matched, "phone")
+		}
+		if taxID != "" && normalizeTaxID(c.TaxID) == taxID {
+			matched = append(matched, "tax_id")
+		}
+		if name != "" && strings.ToLower(strings.Join(strings.Fields(c.Name), " ")) == name {
+			matched = append(matched, "name")
+		}
+		duplicates = append(duplicates, &ClientDuplicate{Client: c, MatchedOn: matched})
+	}
+
+	return duplicates, nil
+}
+
+// ============ Client Detail ============
+
+// ClientDetail is a client with their worksheets, budgets, projects and billing totals
+type ClientDetail struct {
+	*models.Client
+	Worksheets []*ClientWorksheetSummary `json:"worksheets"`
+	Budgets    []*ClientBudgetSummary    `json:"budgets"`
+	Projects   []*ClientProjectSummary   `json:"projects"`
+	Billing    ClientBilling             `json:"billing"`
+}
+
+type ClientWorksheetSummary struct {
+	ID        uuid.UUID              `json:"id"`
+	Title     string                 `json:"title"`
+	Status    models.WorkSheetStatus `json:"status"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+type ClientBudgetSummary struct {
+	ID           uuid.UUID           `json:"id"`
+	WorkSheetID  uuid.UUID           `json:"worksheet_id"`
+	BudgetNumber string              `json:"budget_number"`
+	Status       models.BudgetStatus `json:"status"`
+	Total        decimal.Decimal     `json:"total"`
+	ValidUntil   time.Time           `json:"valid_until"`
+	CreatedAt    time.Time           `json:"created_at"`
+}
+
+type ClientProjectSummary struct {
+	ID              uuid.UUID            `json:"id"`
+	BudgetID        uuid.UUID            `json:"budget_id"`
+	ProjectNumber   string               `json:"project_number"`
+	Title           string               `json:"title"`
+	Status          models.ProjectStatus `json:"status"`
+	Progress        int                  `json:"progress"`
+	StartDate       time.Time            `json:"start_date"`
+	ExpectedEndDate time.Time            `json:"expected_end_date"`
+}
+
+// ClientBilling sums the client's approved budgets and the payments of their projects
+type ClientBilling struct {
+	TotalBudgeted decimal.Decimal `json:"total_budgeted"` // approved budgets
+	TotalBilled   decimal.Decimal `json:"total_billed"`   // non-cancelled payments
+	TotalPaid     decimal.Decimal `json:"total_paid"`
+	Outstanding   decimal.Decimal `json:"outstanding"`
+	OverdueCount  int             `json:"overdue_count"`
+}
+
+// GetDetail returns a client with everything done for them
+func (s *ClientService) GetDetail(ctx context.Context, id, orgID uuid.UUID) (*ClientDetail, error) {
+	client, err := s.GetByID(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &ClientDetail{
+		Client:     client,
+		Worksheets: []*ClientWorksheetSummary{},
+		Budgets:    []*ClientBudgetSummary{},
+		Projects:   []*ClientProjectSummary{},
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, title, status, created_at
+		FROM worksheets
+		WHERE client_id = $1 AND organization_id = $2 AND deleted_at IS NULL
+		ORDER BY created_at DESC
+	`, id, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query client worksheets: %w", err)
+	}
+	for rows.Next() {
+		var ws ClientWorksheetSummary
+		if err := rows.Scan(&ws.ID, &ws.Title, &ws.Status, &ws.CreatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan worksheet: %w", err)
+		}
+		detail.Worksheets = append(detail.Worksheets, &ws)
+	}
+	rows.Close()
+
+	rows, err = s.db.Pool.Query(ctx, `
+		SELECT b.id, b.worksheet_id, b.budget_number, b.status, b.total, b.valid_until, b.created_at
+		FROM budgets b
+		JOIN worksheets w ON w.id = b.worksheet_id AND w.deleted_at IS NULL
+		WHERE w.client_id = $1 AND b.organization_id = $2 AND b.deleted_at IS NULL
+		ORDER BY b.created_at DESC
+	`, id, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query client budgets: %w", err)
+	}
+	for rows.Next() {
+		var b ClientBudgetSummary
+		if err := rows.Scan(&b.ID, &b.WorkSheetID, &b.BudgetNumber, &b.Status, &b.Total, &b.ValidUntil, &b.CreatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan budget: %w", err)
+		}
+		detail.Budgets = append(detail.Budgets, &b)
+	}
+	rows.Close()
+
+	rows, err = s.db.Pool.Query(ctx, `
+		SELECT p.id, p.budget_id, p.project_number, p.title, p.status, p.progress,
+			p.start_date, p.expected_end_date
+		FROM projects p
+		JOIN budgets b ON b.id = p.budget_id AND b.deleted_at IS NULL
+		JOIN worksheets w ON w.id = b.worksheet_id AND w.deleted_at IS NULL
+		WHERE w.client_id = $1 AND p.organization_id = $2 AND p.deleted_at IS NULL
+		ORDER BY p.start_date DESC
+	`, id, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query client projects: %w", err)
+	}
+	for rows.Next() {
+		var p ClientProjectSummary
+		if err := rows.Scan(&p.ID, &p.BudgetID, &p.ProjectNumber, &p.Title, &p.Status, &p.Progress,
+			&p.StartDate, &p.ExpectedEndDate); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan project: %w", err)
+		}
+		detail.Projects = append(detail.Projects, &p)
+	}
+	rows.Close()
+
+	for _, b := range detail.Budgets {
+		if b.Status == models.BudgetStatusApproved {
+			detail.Billing.TotalBudgeted = detail.Billing.TotalBudgeted.Add(b.Total)
+		}
+	}
+
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT
+			COALESCE(SUM(pay.amount), 0),
+			COALESCE(SUM(pay.amount) FILTER (WHERE pay.status = 'paid'), 0),
+			COUNT(*) FILTER (WHERE pay.status = 'overdue')
+		FROM payments pay
+		JOIN projects p ON p.id = pay.project_id AND p.deleted_at IS NULL
+		JOIN budgets b ON b.id = p.budget_id
+		JOIN worksheets w ON w.id = b.worksheet_id
+		WHERE w.client_id = $1 AND pay.organization_id = $2
+			AND pay.deleted_at IS NULL AND pay.status <> 'cancelled'
+	`, id, orgID).Scan(&detail.Billing.TotalBilled, &detail.Billing.TotalPaid, &detail.Billing.OverdueCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum client payments: %w", err)
+	}
+	detail.Billing.Outstanding = detail.Billing.TotalBilled.Sub(detail.Billing.TotalPaid)
+
+	return detail, nil
+}
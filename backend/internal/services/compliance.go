@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ComplianceService handles compliance checklists for projects
+type ComplianceService struct {
+	db *database.DB
+}
+
+func NewComplianceService(db *database.DB) *ComplianceService {
+	return &ComplianceService{db: db}
+}
+
+// ============ Requirements ============
+
+// ListRequirements returns compliance requirements for an organization, optionally filtered by category
+func (s *ComplianceService) ListRequirements(ctx context.Context, orgID uuid.UUID, category string) ([]*models.ComplianceRequirement, error) {
+	query := `
+		SELECT id, organization_id, category, name, description, requirement_type,
+			due_offset_days, is_active, created_at, updated_at
+		FROM compliance_requirements
+		WHERE organization_id = $1
+	`
+	args := []interface{}{orgID}
+	if category != "" {
+		query += " AND (category = $2 OR category IS NULL)"
+		args = append(args, category)
+	}
+	query += " ORDER BY category NULLS FIRST, due_offset_days, name"
+
+	rows, err := s.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query compliance requirements: %w", err)
+	}
+	defer rows.Close()
+
+	var requirements []*models.ComplianceRequirement
+	for rows.Next() {
+		var req models.ComplianceRequirement
+		if err := rows.Scan(
+			&req.ID, &req.OrganizationID, &req.Category, &req.Name, &req.Description, &req.RequirementType,
+			&req.DueOffsetDays, &req.IsActive, &req.CreatedAt, &req.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan compliance requirement: %w", err)
+		}
+		requirements = append(requirements, &req)
+	}
+
+	return requirements, nil
+}
+
+// CreateRequirement creates a compliance requirement
+func (s *ComplianceService) CreateRequirement(ctx context.Context, req *models.ComplianceRequirement) error {
+	if err := validateRequirement(req); err != nil {
+		return err
+	}
+
+	req.ID = uuid.New()
+	req.IsActive = true
+	req.CreatedAt = time.Now()
+	req.UpdatedAt = req.CreatedAt
+
+	_, err := s.db.Pool.Exec(ctx, `
+		INSERT INTO compliance_requirements (
+			id, organization_id, category, name, description, requirement_type,
+			due_offset_days, is_active, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, req.ID, req.OrganizationID, req.Category, req.Name, req.Description, req.RequirementType,
+		req.DueOffsetDays, req.IsActive, req.CreatedAt, req.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create compliance requirement: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateRequirement updates a compliance requirement; items already applied to projects are unchanged
+func (s *ComplianceService) UpdateRequirement(ctx context.Context, id, orgID uuid.UUID, req *models.ComplianceRequirement) error {
+	if err := validateRequirement(req); err != nil {
+		return err
+	}
+
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE compliance_requirements
+		SET category = $1, name = $2, description = $3, requirement_type = $4,
+			due_offset_days = $5, is_active = $6, updated_at = NOW()
+		WHERE id = $7 AND organization_id = $8
+	`, req.Category, req.Name, req.Description, req.RequirementType,
+		req.DueOffsetDays, req.IsActive, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to update compliance requirement: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("compliance requirement not found")
+	}
+
+	return nil
+}
+
+// DeleteRequirement deletes a compliance requirement
+func (s *ComplianceService) DeleteRequirement(ctx context.Context, id, orgID uuid.UUID) error {
+	result, err := s.db.Pool.Exec(ctx, `
+		DELETE FROM compliance_requirements WHERE id = $1 AND organization_id = $2
+	`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to delete compliance requirement: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("compliance requirement not found")
+	}
+	return nil
+}
+
+// validateRequirement checks a requirement before saving
+func validateRequirement(req *models.ComplianceRequirement) error {
+	if req.Name == "" {
+		return errors.New("requirement name is required")
+	}
+	switch req.RequirementType {
+	case "":
+		req.RequirementType = models.ComplianceTypeOther
+	case models.ComplianceTypeLicense, models.ComplianceTypeSafetyPlan, models.ComplianceTypeInsurance, models.ComplianceTypeOther:
+	default:
+		return fmt.Errorf("invalid requirement type: %s", req.RequirementType)
+	}
+	return nil
+}
+
+// ============ Project checklist ============
+
+// ApplyToProject creates checklist items for every active requirement matching the project's category.
+// Requirements already applied to the project are skipped.
+func (s *ComplianceService) ApplyToProject(ctx context.Context, orgID, projectID uuid.UUID) ([]*models.ProjectComplianceItem, error) {
+	var category *string
+	var startDate time.Time
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT category, start_date FROM projects
+		WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`, projectID, orgID).Scan(&category, &startDate)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("project not found")
+		}
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		INSERT INTO project_compliance_items (project_id, requirement_id, name, requirement_type, due_date)
+		SELECT $1, r.id, r.name, r.requirement_type, $2::date + r.due_offset_days
+		FROM compliance_requirements r
+		WHERE r.organization_id = $3 AND r.is_active = true
+		AND (r.category IS NULL OR r.category = $4)
+		ON CONFLICT (project_id, requirement_id) DO NOTHING
+		RETURNING id
+	`, projectID, startDate, orgID, category)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply compliance requirements: %w", err)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to apply compliance requirements: %w", err)
+	}
+
+	return s.ListProjectItems(ctx, orgID, projectID)
+}
+
+// ListProjectItems returns the compliance checklist for a project
+func (s *ComplianceService) ListProjectItems(ctx context.Context, orgID, projectID uuid.UUID) ([]*models.ProjectComplianceItem, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT
+			i.id, i.project_id, i.requirement_id, i.name, i.requirement_type, i.due_date, i.status,
+			i.document_url, i.completed_at, i.completed_by, i.waiver_reason, i.waived_by, i.waived_at,
+			i.escalated_at, i.created_at, i.updated_at
+		FROM project_compliance_items i
+		JOIN projects p ON p.id = i.project_id
+		WHERE i.project_id = $1 AND p.organization_id = $2
+		ORDER BY i.due_date, i.name
+	`, projectID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query compliance items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*models.ProjectComplianceItem
+	for rows.Next() {
+		var item models.ProjectComplianceItem
+		if err := rows.Scan(
+			&item.ID, &item.ProjectID, &item.RequirementID, &item.Name, &item.RequirementType, &item.DueDate, &item.Status,
+			&item.DocumentURL, &item.CompletedAt, &item.CompletedBy, &item.WaiverReason, &item.WaivedBy, &item.WaivedAt,
+			&item.EscalatedAt, &item.CreatedAt, &item.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan compliance item: %w", err)
+		}
+		items = append(items, &item)
+	}
+
+	return items, nil
+}
+
+// CompleteItem marks a compliance item as completed
+func (s *ComplianceService) CompleteItem(ctx context.Context, orgID, projectID, itemID, userID uuid.UUID, documentURL *string) error {
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE project_compliance_items i
+		SET status = 'completed', document_url = COALESCE($1, i.document_url),
+			completed_at = NOW(), completed_by = $2
+		FROM projects p
+		WHERE i.id = $3 AND i.project_id = $4 AND p.id = i.project_id AND p.organization_id = $5
+	`, documentURL, userID, itemID, projectID, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to complete compliance item: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("compliance item not found")
+	}
+	return nil
+}
+
+// WaiveItem waives a compliance item; a reason is mandatory
+func (s *ComplianceService) WaiveItem(ctx context.Context, orgID, projectID, itemID, userID uuid.UUID, reason string) error {
+	if strings.TrimSpace(reason) == "" {
+		return errors.New("a reason is required to waive a compliance item")
+	}
+
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE project_compliance_items i
+		SET status = 'waived', waiver_reason = $1, waived_at = NOW(), waived_by = $2
+		FROM projects p
+		WHERE i.id = $3 AND i.project_id = $4 AND p.id = i.project_id AND p.organization_id = $5
+	`, reason, userID, itemID, projectID, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to waive compliance item: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("compliance item not found")
+	}
+	return nil
+}
+
+// ReopenItem sets a completed or waived item back to pending
+func (s *ComplianceService) ReopenItem(ctx context.Context, orgID, projectID, itemID uuid.UUID) error {
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE project_compliance_items i
+		SET status = 'pending', completed_at = NULL, completed_by = NULL,
+			waiver_reason = NULL, waived_at = NULL, waived_by = NULL, escalated_at = NULL
+		WHERE i.id = $1 AND i.project_id = (
+			-- Waits for a completion of the project under way, which checks for pending items
+			SELECT id FROM projects WHERE id = $2 AND organization_id = $3 FOR SHARE
+		)
+	`, itemID, projectID, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to reopen compliance item: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("compliance item not found")
+	}
+	return nil
+}
+
+// CheckProjectCompletable returns an error listing outstanding compliance items, if any. It runs
+// in the transaction completing the project, with the project row locked.
+func (s *ComplianceService) CheckProjectCompletable(ctx context.Context, tx pgx.Tx, projectID uuid.UUID) error {
+	rows, err := tx.Query(ctx, `
+		SELECT name FROM project_compliance_items
+		WHERE project_id = $1 AND status = 'pending'
+		ORDER BY due_date, name
+	`, projectID)
+	if err != nil {
+		return fmt.Errorf("failed to check compliance items: %w", err)
+	}
+	defer rows.Close()
+
+	var pending []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return fmt.Errorf("failed to scan compliance item: %w", err)
+		}
+		pending = append(pending, name)
+	}
+
+	if len(pending) > 0 {
+		return fmt.Errorf("project has unresolved compliance items: %s", strings.Join(pending, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,311 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ConsentService keeps whether contacts agreed to be messaged on each channel. Senders check it
+// through contactOptedOut before sending.
+type ConsentService struct {
+	db *database.DB
+}
+
+func NewConsentService(db *database.DB) *ConsentService {
+	return &ConsentService{db: db}
+}
+
+// ConsentFilters narrows the consent list
+type ConsentFilters struct {
+	Channel   models.ConsentChannel
+	Status    models.ConsentStatus
+	ClientID  *uuid.UUID
+	PatientID *uuid.UUID
+	Search    string // matches the address
+}
+
+// ConsentInput records a contact's consent on a channel
+type ConsentInput struct {
+	Channel   models.ConsentChannel `json:"channel"`
+	Address   string                `json:"address"`
+	Status    models.ConsentStatus  `json:"status"`
+	Source    models.ConsentSource  `json:"source"` // defaults to staff
+	Note      *string               `json:"note"`
+	ClientID  *uuid.UUID            `json:"client_id"`
+	PatientID *uuid.UUID            `json:"patient_id"`
+}
+
+// validateConsentInput checks a consent change and normalizes its address and source
+func validateConsentInput(input *ConsentInput) error {
+	if !input.Channel.IsValid() {
+		return errors.New("invalid consent channel")
+	}
+	if !input.Status.IsValid() {
+		return errors.New("invalid consent status")
+	}
+	if input.Source == "" {
+		input.Source = models.ConsentSourceStaff
+	}
+	if !input.Source.IsValid() {
+		return errors.New("invalid consent source")
+	}
+	input.Address = models.NormalizeConsentAddress(input.Channel, input.Address)
+	if input.Address == "" {
+		return errors.New("address is required")
+	}
+	if input.Channel == models.ConsentChannelEmail && !strings.Contains(input.Address, "@") {
+		return errors.New("invalid email address")
+	}
+	return nil
+}
+
+const consentColumns = `
+	cc.id, cc.organization_id, cc.channel, cc.address, cc.client_id, cc.patient_id, cc.status,
+	cc.source, cc.note, cc.changed_by, cc.changed_at, cc.created_at, c.name, pc.name`
+
+const consentJoins = `
+	LEFT JOIN clients c ON c.id = cc.client_id
+	LEFT JOIN patients p ON p.id = cc.patient_id
+	LEFT JOIN clients pc ON pc.id = p.client_id`
+
+func scanConsent(row pgx.Row) (*models.ContactConsent, error) {
+	var c models.ContactConsent
+	err := row.Scan(
+		&c.ID, &c.OrganizationID, &c.Channel, &c.Address, &c.ClientID, &c.PatientID, &c.Status,
+		&c.Source, &c.Note, &c.ChangedBy, &c.ChangedAt, &c.CreatedAt, &c.ClientName, &c.PatientName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// List returns the organization's consent records matching the filters, latest change first
+func (s *ConsentService) List(ctx context.Context, orgID uuid.UUID, filters ConsentFilters, limit, offset int) ([]*models.ContactConsent, int, error) {
+	where := "WHERE cc.organization_id = $1"
+	args := []interface{}{orgID}
+
+	if filters.Channel != "" {
+		args = append(args, filters.Channel)
+		where += fmt.Sprintf(" AND cc.channel = $%d", len(args))
+	}
+	if filters.Status != "" {
+		args = append(args, filters.Status)
+		where += fmt.Sprintf(" AND cc.status = $%d", len(args))
+	}
+	if filters.ClientID != nil {
+		args = append(args, *filters.ClientID)
+		where += fmt.Sprintf(" AND cc.client_id = $%d", len(args))
+	}
+	if filters.PatientID != nil {
+		args = append(args, *filters.PatientID)
+		where += fmt.Sprintf(" AND cc.patient_id = $%d", len(args))
+	}
+	if filters.Search != "" {
+		args = append(args, "%"+filters.Search+"%")
+		where += fmt.Sprintf(" AND cc.address ILIKE $%d", len(args))
+	}
+
+	var total int
+	err := s.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM contact_consents cc "+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count consents: %w", err)
+	}
+
+	args = append(args, limit, offset)
+	rows, err := s.db.Pool.Query(ctx, fmt.Sprintf(`
+		SELECT %s
+		FROM contact_consents cc
+		%s
+		%s
+		ORDER BY cc.changed_at DESC
+		LIMIT $%d OFFSET $%d
+	`, consentColumns, consentJoins, where, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query consents: %w", err)
+	}
+	defer rows.Close()
+
+	consents := []*models.ContactConsent{}
+	for rows.Next() {
+		c, err := scanConsent(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan consent: %w", err)
+		}
+		consents = append(consents, c)
+	}
+	return consents, total, nil
+}
+
+// GetByID returns a consent record with the history of its changes, latest first
+func (s *ConsentService) GetByID(ctx context.Context, id, orgID uuid.UUID) (*models.ContactConsent, error) {
+	consent, err := scanConsent(s.db.Pool.QueryRow(ctx, `
+		SELECT `+consentColumns+`
+		FROM contact_consents cc
+		`+consentJoins+`
+		WHERE cc.id = $1 AND cc.organization_id = $2
+	`, id, orgID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.New("consent not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consent: %w", err)
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT h.id, h.status, h.source, h.note, h.message_id, h.changed_by, h.changed_at, u.name
+		FROM contact_consent_history h
+		LEFT JOIN users u ON u.id = h.changed_by
+		WHERE h.consent_id = $1
+		ORDER BY h.changed_at DESC
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consent history: %w", err)
+	}
+	defer rows.Close()
+
+	consent.History = []*models.ContactConsentChange{}
+	for rows.Next() {
+		var h models.ContactConsentChange
+		if err := rows.Scan(&h.ID, &h.Status, &h.Source, &h.Note, &h.MessageID, &h.ChangedBy, &h.ChangedAt, &h.ChangedByName); err != nil {
+			return nil, fmt.Errorf("failed to scan consent history: %w", err)
+		}
+		consent.History = append(consent.History, &h)
+	}
+	return consent, nil
+}
+
+// Record sets a contact's consent on a channel, creating its record if it has none
+func (s *ConsentService) Record(ctx context.Context, orgID, userID uuid.UUID, input ConsentInput) (*models.ContactConsent, error) {
+	if err := validateConsentInput(&input); err != nil {
+		return nil, err
+	}
+	if input.ClientID != nil {
+		var exists bool
+		err := s.db.Pool.QueryRow(ctx, `
+			SELECT EXISTS(SELECT 1 FROM clients WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL)
+		`, *input.ClientID, orgID).Scan(&exists)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check client: %w", err)
+		}
+		if !exists {
+			return nil, errors.New("client not found")
+		}
+	}
+	if input.PatientID != nil {
+		var exists bool
+		err := s.db.Pool.QueryRow(ctx, `
+			SELECT EXISTS(SELECT 1 FROM patients WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL)
+		`, *input.PatientID, orgID).Scan(&exists)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check patient: %w", err)
+		}
+		if !exists {
+			return nil, errors.New("patient not found")
+		}
+	}
+
+	id, err := recordContactConsent(ctx, s.db, orgID, input, &userID, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetByID(ctx, id, orgID)
+}
+
+// Update changes the status or note of a consent record
+func (s *ConsentService) Update(ctx context.Context, id, orgID, userID uuid.UUID, status models.ConsentStatus, note *string) (*models.ContactConsent, error) {
+	consent, err := s.GetByID(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+	input := ConsentInput{
+		Channel:   consent.Channel,
+		Address:   consent.Address,
+		Status:    status,
+		Note:      note,
+		ClientID:  consent.ClientID,
+		PatientID: consent.PatientID,
+	}
+	if err := validateConsentInput(&input); err != nil {
+		return nil, err
+	}
+	if _, err := recordContactConsent(ctx, s.db, orgID, input, &userID, nil); err != nil {
+		return nil, err
+	}
+	return s.GetByID(ctx, id, orgID)
+}
+
+// Delete removes a consent record and its history; the address may be messaged again
+func (s *ConsentService) Delete(ctx context.Context, id, orgID uuid.UUID) error {
+	result, err := s.db.Pool.Exec(ctx, `
+		DELETE FROM contact_consents WHERE id = $1 AND organization_id = $2
+	`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to delete consent: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("consent not found")
+	}
+	return nil
+}
+
+// recordContactConsent upserts the consent of a validated input and adds the change to its
+// history, returning the record's ID. Links to a client or patient are kept when not given.
+func recordContactConsent(ctx context.Context, db *database.DB, orgID uuid.UUID, input ConsentInput, changedBy, messageID *uuid.UUID) (uuid.UUID, error) {
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var id uuid.UUID
+	err = tx.QueryRow(ctx, `
+		INSERT INTO contact_consents (organization_id, channel, address, client_id, patient_id, status, source, note, changed_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (organization_id, channel, address) DO UPDATE SET
+			client_id = COALESCE(EXCLUDED.client_id, contact_consents.client_id),
+			patient_id = COALESCE(EXCLUDED.patient_id, contact_consents.patient_id),
+			status = EXCLUDED.status, source = EXCLUDED.source, note = EXCLUDED.note,
+			changed_by = EXCLUDED.changed_by, changed_at = NOW()
+		RETURNING id
+	`, orgID, input.Channel, input.Address, input.ClientID, input.PatientID, input.Status, input.Source,
+		input.Note, changedBy).Scan(&id)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to record consent: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO contact_consent_history (consent_id, status, source, note, message_id, changed_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, id, input.Status, input.Source, input.Note, messageID, changedBy)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to record consent: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to record consent: %w", err)
+	}
+	return id, nil
+}
+
+// contactOptedOut reports whether an address opted out of the organization's messages on a channel
+func contactOptedOut(ctx context.Context, q rowQuerier, orgID uuid.UUID, channel models.ConsentChannel, address string) (bool, error) {
+	var optedOut bool
+	err := q.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM contact_consents
+			WHERE organization_id = $1 AND channel = $2 AND address = $3 AND status = 'opted_out'
+		)
+	`, orgID, channel, models.NormalizeConsentAddress(channel, address)).Scan(&optedOut)
+	if err != nil {
+		return false, fmt.Errorf("failed to check consent: %w", err)
+	}
+	return optedOut, nil
+}
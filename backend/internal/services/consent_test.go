@@ -0,0 +1,43 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/controlwise/backend/internal/models"
+)
+
+func TestValidateConsentInput(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       ConsentInput
+		wantErr     bool
+		wantAddress string
+	}{
+		{"phone normalized", ConsentInput{Channel: models.ConsentChannelWhatsApp, Address: "912 345 678", Status: models.ConsentOptedOut}, false, "+351912345678"},
+		{"email lowercased", ConsentInput{Channel: models.ConsentChannelEmail, Address: " Ana@Example.PT ", Status: models.ConsentOptedIn}, false, "ana@example.pt"},
+		{"sms", ConsentInput{Channel: models.ConsentChannelSMS, Address: "+351912345678", Status: models.ConsentOptedOut}, false, "+351912345678"},
+		{"invalid email", ConsentInput{Channel: models.ConsentChannelEmail, Address: "ana", Status: models.ConsentOptedOut}, true, ""},
+		{"missing address", ConsentInput{Channel: models.ConsentChannelSMS, Address: " ", Status: models.ConsentOptedOut}, true, ""},
+		{"unknown channel", ConsentInput{Channel: "fax", Address: "123", Status: models.ConsentOptedOut}, true, ""},
+		{"unknown status", ConsentInput{Channel: models.ConsentChannelEmail, Address: "a@b.pt", Status: "maybe"}, true, ""},
+		{"unknown source", ConsentInput{Channel: models.ConsentChannelEmail, Address: "a@b.pt", Status: models.ConsentOptedOut, Source: "letter"}, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateConsentInput(&tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateConsentInput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if tt.input.Address != tt.wantAddress {
+				t.Errorf("Address = %q, want %q", tt.input.Address, tt.wantAddress)
+			}
+			if tt.input.Source != models.ConsentSourceStaff {
+				t.Errorf("Source = %q, want staff by default", tt.input.Source)
+			}
+		})
+	}
+}
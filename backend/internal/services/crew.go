@@ -0,0 +1,651 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// maxCrewScheduleDays bounds the days a single assignment request or calendar spans
+const maxCrewScheduleDays = 62
+
+// CrewService schedules workers and subcontractors on projects and reports the labour cost
+type CrewService struct {
+	db *database.DB
+}
+
+func NewCrewService(db *database.DB) *CrewService {
+	return &CrewService{db: db}
+}
+
+// Location returns the timezone the organization's crew days are counted in
+func (s *CrewService) Location(ctx context.Context, orgID uuid.UUID) *time.Location {
+	return orgLocation(ctx, s.db.Pool, orgID)
+}
+
+// CrewMemberInput creates or updates a crew member
+type CrewMemberInput struct {
+	Kind       models.CrewMemberKind `json:"kind"`
+	Name       string                `json:"name"`
+	UserID     *uuid.UUID            `json:"user_id"`
+	Trade      *string               `json:"trade"`
+	Phone      *string               `json:"phone"`
+	HourlyRate decimal.Decimal       `json:"hourly_rate"`
+	DailyHours *decimal.Decimal      `json:"daily_hours"` // 8 when not set
+	IsActive   *bool                 `json:"is_active"`   // true when not set
+}
+
+// CrewAssignmentRequest assigns a crew member to a project on every day from From to To, or only on
+// the given weekdays (0 = Sunday) of that range. Days already assigned to the project are updated.
+type CrewAssignmentRequest struct {
+	ProjectID    uuid.UUID       `json:"project_id"`
+	CrewMemberID uuid.UUID       `json:"crew_member_id"`
+	From         string          `json:"from"` // YYYY-MM-DD
+	To           string          `json:"to"`   // YYYY-MM-DD, From when empty
+	Weekdays     []int           `json:"weekdays"`
+	PlannedHours decimal.Decimal `json:"planned_hours"`
+	Notes        *string         `json:"notes"`
+	// Assign even on days the member is already fully booked on other projects
+	AllowDoubleBooking bool `json:"allow_double_booking"`
+}
+
+// UpdateCrewAssignmentRequest changes the planned hours of an assignment or records the hours worked
+type UpdateCrewAssignmentRequest struct {
+	PlannedHours       *decimal.Decimal `json:"planned_hours"`
+	ActualHours        *decimal.Decimal `json:"actual_hours"`
+	Notes              *string          `json:"notes"`
+	AllowDoubleBooking bool             `json:"allow_double_booking"`
+}
+
+// CrewAssignmentFilters narrows the assignment list; From and To are inclusive dates
+type CrewAssignmentFilters struct {
+	ProjectID    *uuid.UUID
+	CrewMemberID *uuid.UUID
+	From         *time.Time
+	To           *time.Time
+}
+
+// ============ Crew members ============
+
+func validateCrewMember(input *CrewMemberInput) error {
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		return errors.New("name is required")
+	}
+	if !input.Kind.IsValid() {
+		return errors.New("invalid crew member kind")
+	}
+	if input.HourlyRate.IsNegative() {
+		return errors.New("hourly rate cannot be negative")
+	}
+	if input.DailyHours != nil && (!input.DailyHours.IsPositive() || input.DailyHours.GreaterThan(decimal.NewFromInt(24))) {
+		return errors.New("daily hours must be between 0 and 24")
+	}
+	return nil
+}
+
+const crewMemberColumns = `id, organization_id, kind, name, user_id, trade, phone, hourly_rate, daily_hours, is_active,
+	created_at, updated_at`
+
+func scanCrewMember(row pgx.Row) (*models.CrewMember, error) {
+	var m models.CrewMember
+	err := row.Scan(&m.ID, &m.OrganizationID, &m.Kind, &m.Name, &m.UserID, &m.Trade, &m.Phone, &m.HourlyRate,
+		&m.DailyHours, &m.IsActive, &m.CreatedAt, &m.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// checkCrewUser checks the user linked to a crew member belongs to the organization
+func (s *CrewService) checkCrewUser(ctx context.Context, orgID uuid.UUID, userID *uuid.UUID) error {
+	if userID == nil {
+		return nil
+	}
+	var exists bool
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM users WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL)
+	`, *userID, orgID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check user: %w", err)
+	}
+	if !exists {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+// ListMembers returns the organization's crew members by name, only the active ones unless all is set
+func (s *CrewService) ListMembers(ctx context.Context, orgID uuid.UUID, all bool) ([]*models.CrewMember, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT `+crewMemberColumns+` FROM crew_members
+		WHERE organization_id = $1 AND ($2 OR is_active)
+		ORDER BY name
+	`, orgID, all)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list crew members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []*models.CrewMember{}
+	for rows.Next() {
+		m, err := scanCrewMember(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan crew member: %w", err)
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+// CreateMember adds a worker or subcontractor to the organization's crew
+func (s *CrewService) CreateMember(ctx context.Context, orgID uuid.UUID, input CrewMemberInput) (*models.CrewMember, error) {
+	if err := validateCrewMember(&input); err != nil {
+		return nil, err
+	}
+	if err := s.checkCrewUser(ctx, orgID, input.UserID); err != nil {
+		return nil, err
+	}
+
+	member, err := scanCrewMember(s.db.Pool.QueryRow(ctx, `
+		INSERT INTO crew_members (organization_id, kind, name, user_id, trade, phone, hourly_rate, daily_hours, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE($8, 8), COALESCE($9, true))
+		RETURNING `+crewMemberColumns,
+		orgID, input.Kind, input.Name, input.UserID, input.Trade, input.Phone, input.HourlyRate, input.DailyHours, input.IsActive))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create crew member: %w", err)
+	}
+	return member, nil
+}
+
+// UpdateMember updates a crew member. A new hourly rate applies to assignments made from then on.
+func (s *CrewService) UpdateMember(ctx context.Context, id, orgID uuid.UUID, input CrewMemberInput) (*models.CrewMember, error) {
+	if err := validateCrewMember(&input); err != nil {
+		return nil, err
+	}
+	if err := s.checkCrewUser(ctx, orgID, input.UserID); err != nil {
+		return nil, err
+	}
+
+	member, err := scanCrewMember(s.db.Pool.QueryRow(ctx, `
+		UPDATE crew_members
+		SET kind = $1, name = $2, user_id = $3, trade = $4, phone = $5, hourly_rate = $6,
+			daily_hours = COALESCE($7, daily_hours), is_active = COALESCE($8, is_active)
+		WHERE id = $9 AND organization_id = $10
+		RETURNING `+crewMemberColumns,
+		input.Kind, input.Name, input.UserID, input.Trade, input.Phone, input.HourlyRate, input.DailyHours, input.IsActive,
+		id, orgID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("crew member not found")
+		}
+		return nil, fmt.Errorf("failed to update crew member: %w", err)
+	}
+	return member, nil
+}
+
+// DeleteMember removes a crew member with their assignments. Members with recorded hours are kept
+// for project costing and must be deactivated instead.
+func (s *CrewService) DeleteMember(ctx context.Context, id, orgID uuid.UUID) error {
+	var worked bool
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM crew_assignments
+			WHERE crew_member_id = $1 AND organization_id = $2 AND actual_hours IS NOT NULL
+		)
+	`, id, orgID).Scan(&worked)
+	if err != nil {
+		return fmt.Errorf("failed to check crew assignments: %w", err)
+	}
+	if worked {
+		return errors.New("crew member has recorded hours; deactivate them instead")
+	}
+
+	result, err := s.db.Pool.Exec(ctx, `DELETE FROM crew_members WHERE id = $1 AND organization_id = $2`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to delete crew member: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("crew member not found")
+	}
+	return nil
+}
+
+// ============ Assignments ============
+
+// crewWorkDates returns the days from from to to, only those on the given weekdays when any are given
+func crewWorkDates(from, to time.Time, weekdays []int) ([]time.Time, error) {
+	if to.Before(from) {
+		return nil, errors.New("end date must be on or after the start date")
+	}
+	if to.Sub(from) >= maxCrewScheduleDays*24*time.Hour {
+		return nil, fmt.Errorf("schedules can span at most %d days", maxCrewScheduleDays)
+	}
+	onDay := map[time.Weekday]bool{}
+	for _, d := range weekdays {
+		if d < 0 || d > 6 {
+			return nil, errors.New("weekdays must be between 0 (Sunday) and 6 (Saturday)")
+		}
+		onDay[time.Weekday(d)] = true
+	}
+
+	var dates []time.Time
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if len(onDay) == 0 || onDay[day.Weekday()] {
+			dates = append(dates, day)
+		}
+	}
+	if len(dates) == 0 {
+		return nil, errors.New("no days match the selected weekdays")
+	}
+	return dates, nil
+}
+
+// crewDoubleBooked reports whether hours planned on a project on top of the hours already planned
+// on other projects that day exceed the member's day. Long days on one project are not double-booked.
+func crewDoubleBooked(otherHours, hours, dailyHours decimal.Decimal) bool {
+	return otherHours.IsPositive() && otherHours.Add(hours).GreaterThan(dailyHours)
+}
+
+func validatePlannedHours(hours decimal.Decimal) error {
+	if !hours.IsPositive() || hours.GreaterThan(decimal.NewFromInt(24)) {
+		return errors.New("planned hours must be between 0 and 24")
+	}
+	return nil
+}
+
+// checkSchedulableProject checks the project belongs to the organization and is still running
+func checkSchedulableProject(ctx context.Context, tx pgx.Tx, projectID, orgID uuid.UUID) error {
+	var status models.ProjectStatus
+	err := tx.QueryRow(ctx, `
+		SELECT status FROM projects WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`, projectID, orgID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("project not found")
+		}
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+	if status == models.ProjectStatusCompleted || status == models.ProjectStatusCancelled {
+		return errors.New("crew cannot be scheduled on a completed or cancelled project")
+	}
+	return nil
+}
+
+// lockCrewMember locks a crew member for the transaction, so concurrent schedules of the same
+// member are checked for double-booking one after the other
+func lockCrewMember(ctx context.Context, tx pgx.Tx, memberID, orgID uuid.UUID) (*models.CrewMember, error) {
+	member, err := scanCrewMember(tx.QueryRow(ctx, `
+		SELECT `+crewMemberColumns+` FROM crew_members
+		WHERE id = $1 AND organization_id = $2
+		FOR UPDATE
+	`, memberID, orgID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("crew member not found")
+		}
+		return nil, fmt.Errorf("failed to get crew member: %w", err)
+	}
+	return member, nil
+}
+
+// otherProjectHours returns the hours a member is planned on projects other than projectID on a day
+func otherProjectHours(ctx context.Context, tx pgx.Tx, memberID, projectID uuid.UUID, day time.Time) (decimal.Decimal, error) {
+	var hours decimal.Decimal
+	err := tx.QueryRow(ctx, `
+		SELECT COALESCE(SUM(planned_hours), 0) FROM crew_assignments
+		WHERE crew_member_id = $1 AND project_id <> $2 AND work_date = $3
+	`, memberID, projectID, day).Scan(&hours)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to check crew bookings: %w", err)
+	}
+	return hours, nil
+}
+
+const crewAssignmentQuery = `
+	SELECT a.id, a.organization_id, a.project_id, a.crew_member_id, to_char(a.work_date, 'YYYY-MM-DD'),
+		a.planned_hours, a.actual_hours, a.hourly_rate, a.notes, a.created_by, a.created_at, a.updated_at,
+		p.project_number, p.title, m.name
+	FROM crew_assignments a
+	JOIN projects p ON p.id = a.project_id
+	JOIN crew_members m ON m.id = a.crew_member_id
+`
+
+func scanCrewAssignment(row pgx.Row) (*models.CrewAssignment, error) {
+	var a models.CrewAssignment
+	err := row.Scan(&a.ID, &a.OrganizationID, &a.ProjectID, &a.CrewMemberID, &a.WorkDate,
+		&a.PlannedHours, &a.ActualHours, &a.HourlyRate, &a.Notes, &a.CreatedBy, &a.CreatedAt, &a.UpdatedAt,
+		&a.ProjectNumber, &a.ProjectTitle, &a.CrewMemberName)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// ListAssignments returns the assignments matching the filters by day and crew member
+func (s *CrewService) ListAssignments(ctx context.Context, orgID uuid.UUID, filters CrewAssignmentFilters) ([]*models.CrewAssignment, error) {
+	rows, err := s.db.Pool.Query(ctx, crewAssignmentQuery+`
+		WHERE a.organization_id = $1
+			AND ($2::uuid IS NULL OR a.project_id = $2)
+			AND ($3::uuid IS NULL OR a.crew_member_id = $3)
+			AND ($4::date IS NULL OR a.work_date >= $4)
+			AND ($5::date IS NULL OR a.work_date <= $5)
+		ORDER BY a.work_date, m.name
+	`, orgID, filters.ProjectID, filters.CrewMemberID, filters.From, filters.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list crew assignments: %w", err)
+	}
+	defer rows.Close()
+
+	assignments := []*models.CrewAssignment{}
+	for rows.Next() {
+		a, err := scanCrewAssignment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan crew assignment: %w", err)
+		}
+		assignments = append(assignments, a)
+	}
+	return assignments, rows.Err()
+}
+
+// Assign schedules a crew member on a project for the requested days at their current hourly rate.
+// Days on which the member would be double-booked fail the whole request unless double-booking is
+// allowed.
+func (s *CrewService) Assign(ctx context.Context, orgID, userID uuid.UUID, req CrewAssignmentRequest) ([]*models.CrewAssignment, error) {
+	if err := validatePlannedHours(req.PlannedHours); err != nil {
+		return nil, err
+	}
+	from, err := time.Parse("2006-01-02", req.From)
+	if err != nil {
+		return nil, errors.New("Invalid date format, expected YYYY-MM-DD")
+	}
+	to := from
+	if req.To != "" {
+		if to, err = time.Parse("2006-01-02", req.To); err != nil {
+			return nil, errors.New("Invalid date format, expected YYYY-MM-DD")
+		}
+	}
+	dates, err := crewWorkDates(from, to, req.Weekdays)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := checkSchedulableProject(ctx, tx, req.ProjectID, orgID); err != nil {
+		return nil, err
+	}
+	member, err := lockCrewMember(ctx, tx, req.CrewMemberID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !member.IsActive {
+		return nil, errors.New("crew member is not active")
+	}
+
+	var conflicts []string
+	ids := make([]uuid.UUID, 0, len(dates))
+	for _, day := range dates {
+		other, err := otherProjectHours(ctx, tx, member.ID, req.ProjectID, day)
+		if err != nil {
+			return nil, err
+		}
+		if crewDoubleBooked(other, req.PlannedHours, member.DailyHours) {
+			conflicts = append(conflicts, day.Format("2006-01-02"))
+		}
+
+		var id uuid.UUID
+		err = tx.QueryRow(ctx, `
+			INSERT INTO crew_assignments (organization_id, project_id, crew_member_id, work_date, planned_hours,
+				hourly_rate, notes, created_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (crew_member_id, project_id, work_date) DO UPDATE
+				SET planned_hours = EXCLUDED.planned_hours, notes = COALESCE(EXCLUDED.notes, crew_assignments.notes)
+			RETURNING id
+		`, orgID, req.ProjectID, member.ID, day, req.PlannedHours, member.HourlyRate, req.Notes, userID).Scan(&id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to assign crew member: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if len(conflicts) > 0 && !req.AllowDoubleBooking {
+		return nil, fmt.Errorf("crew member is already booked on other projects on these days: %s", strings.Join(conflicts, ", "))
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return s.assignmentsByID(ctx, ids)
+}
+
+func (s *CrewService) assignmentsByID(ctx context.Context, ids []uuid.UUID) ([]*models.CrewAssignment, error) {
+	rows, err := s.db.Pool.Query(ctx, crewAssignmentQuery+`
+		WHERE a.id = ANY($1)
+		ORDER BY a.work_date
+	`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get crew assignments: %w", err)
+	}
+	defer rows.Close()
+
+	assignments := []*models.CrewAssignment{}
+	for rows.Next() {
+		a, err := scanCrewAssignment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan crew assignment: %w", err)
+		}
+		assignments = append(assignments, a)
+	}
+	return assignments, rows.Err()
+}
+
+// UpdateAssignment changes the planned hours of an assignment, checked for double-booking, or
+// records the hours worked, which only days that have come can have
+func (s *CrewService) UpdateAssignment(ctx context.Context, id, orgID uuid.UUID, req UpdateCrewAssignmentRequest) (*models.CrewAssignment, error) {
+	if req.PlannedHours != nil {
+		if err := validatePlannedHours(*req.PlannedHours); err != nil {
+			return nil, err
+		}
+	}
+	if req.ActualHours != nil && (req.ActualHours.IsNegative() || req.ActualHours.GreaterThan(decimal.NewFromInt(24))) {
+		return nil, errors.New("actual hours must be between 0 and 24")
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	assignment, err := scanCrewAssignment(tx.QueryRow(ctx, crewAssignmentQuery+`
+		WHERE a.id = $1 AND a.organization_id = $2
+	`, id, orgID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("crew assignment not found")
+		}
+		return nil, fmt.Errorf("failed to get crew assignment: %w", err)
+	}
+	day, err := time.Parse("2006-01-02", assignment.WorkDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid work date: %w", err)
+	}
+
+	if req.ActualHours != nil && day.After(time.Now()) {
+		return nil, errors.New("hours worked can only be recorded from the day of the assignment")
+	}
+	if req.PlannedHours != nil && !req.AllowDoubleBooking {
+		member, err := lockCrewMember(ctx, tx, assignment.CrewMemberID, orgID)
+		if err != nil {
+			return nil, err
+		}
+		other, err := otherProjectHours(ctx, tx, member.ID, assignment.ProjectID, day)
+		if err != nil {
+			return nil, err
+		}
+		if crewDoubleBooked(other, *req.PlannedHours, member.DailyHours) {
+			return nil, fmt.Errorf("crew member is already booked on other projects on these days: %s", assignment.WorkDate)
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE crew_assignments
+		SET planned_hours = COALESCE($1, planned_hours), actual_hours = COALESCE($2, actual_hours),
+			notes = COALESCE($3, notes)
+		WHERE id = $4
+	`, req.PlannedHours, req.ActualHours, req.Notes, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update crew assignment: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	assignments, err := s.assignmentsByID(ctx, []uuid.UUID{id})
+	if err != nil {
+		return nil, err
+	}
+	return assignments[0], nil
+}
+
+// DeleteAssignment removes a crew member from a project on one day
+func (s *CrewService) DeleteAssignment(ctx context.Context, id, orgID uuid.UUID) error {
+	result, err := s.db.Pool.Exec(ctx, `DELETE FROM crew_assignments WHERE id = $1 AND organization_id = $2`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to delete crew assignment: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("crew assignment not found")
+	}
+	return nil
+}
+
+// Calendar returns each crew member's assigned days between from and to, flagging double-booked
+// days. Filtered by project, it shows the days of the project's crew with their other projects.
+func (s *CrewService) Calendar(ctx context.Context, orgID uuid.UUID, from, to time.Time, memberID, projectID *uuid.UUID) ([]*models.CrewCalendarDay, error) {
+	if to.Before(from) {
+		return nil, errors.New("end date must be on or after the start date")
+	}
+	if to.Sub(from) >= maxCrewScheduleDays*24*time.Hour {
+		return nil, fmt.Errorf("schedules can span at most %d days", maxCrewScheduleDays)
+	}
+
+	assignments, err := s.ListAssignments(ctx, orgID, CrewAssignmentFilters{CrewMemberID: memberID, From: &from, To: &to})
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.ListMembers(ctx, orgID, true)
+	if err != nil {
+		return nil, err
+	}
+	dailyHours := make(map[uuid.UUID]decimal.Decimal, len(members))
+	for _, m := range members {
+		dailyHours[m.ID] = m.DailyHours
+	}
+
+	type dayKey struct {
+		date     string
+		memberID uuid.UUID
+	}
+	days := []*models.CrewCalendarDay{}
+	byKey := map[dayKey]*models.CrewCalendarDay{}
+	for _, a := range assignments {
+		key := dayKey{a.WorkDate, a.CrewMemberID}
+		day, ok := byKey[key]
+		if !ok {
+			day = &models.CrewCalendarDay{
+				Date:           a.WorkDate,
+				CrewMemberID:   a.CrewMemberID,
+				CrewMemberName: a.CrewMemberName,
+				DailyHours:     dailyHours[a.CrewMemberID],
+			}
+			byKey[key] = day
+			days = append(days, day)
+		}
+		day.Assignments = append(day.Assignments, a)
+		day.PlannedHours = day.PlannedHours.Add(a.PlannedHours)
+	}
+
+	calendar := []*models.CrewCalendarDay{}
+	for _, day := range days {
+		day.DoubleBooked = len(day.Assignments) > 1 && day.PlannedHours.GreaterThan(day.DailyHours)
+		if projectID == nil || onProject(day.Assignments, *projectID) {
+			calendar = append(calendar, day)
+		}
+	}
+	return calendar, nil
+}
+
+// onProject reports whether any of the assignments is on the project
+func onProject(assignments []*models.CrewAssignment, projectID uuid.UUID) bool {
+	for _, a := range assignments {
+		if a.ProjectID == projectID {
+			return true
+		}
+	}
+	return false
+}
+
+// ProjectCosting returns the budgeted amount of a project against its planned and actual labour
+// and its posted expenses
+func (s *CrewService) ProjectCosting(ctx context.Context, projectID, orgID uuid.UUID) (*models.ProjectCosting, error) {
+	costing := &models.ProjectCosting{ProjectID: projectID}
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT b.subtotal FROM projects p
+		JOIN budgets b ON b.id = p.budget_id
+		WHERE p.id = $1 AND p.organization_id = $2 AND p.deleted_at IS NULL
+	`, projectID, orgID).Scan(&costing.BudgetAmount)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("project not found")
+		}
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	labour := &costing.Labour
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT
+			COALESCE(SUM(planned_hours), 0),
+			COALESCE(SUM(planned_hours * hourly_rate), 0),
+			COALESCE(SUM(actual_hours), 0),
+			COALESCE(SUM(actual_hours * hourly_rate), 0),
+			COALESCE(SUM(planned_hours * hourly_rate) FILTER (WHERE actual_hours IS NOT NULL), 0),
+			COUNT(*) FILTER (WHERE actual_hours IS NULL AND work_date < CURRENT_DATE)
+		FROM crew_assignments
+		WHERE project_id = $1
+	`, projectID).Scan(&labour.PlannedHours, &labour.PlannedCost, &labour.ActualHours, &labour.ActualCost,
+		&labour.PlannedCostWorked, &labour.UnrecordedDays)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project labour: %w", err)
+	}
+	labour.CostVariance = labour.ActualCost.Sub(labour.PlannedCostWorked)
+
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT COALESCE(SUM(amount - COALESCE(vat_amount, 0)), 0) FROM expenses
+		WHERE project_id = $1 AND organization_id = $2 AND scope = 'project' AND status = 'posted'
+			AND deleted_at IS NULL
+	`, projectID, orgID).Scan(&costing.Expenses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project expenses: %w", err)
+	}
+
+	costing.ActualCost = labour.ActualCost.Add(costing.Expenses).Round(2)
+	costing.Margin = costing.BudgetAmount.Sub(costing.ActualCost)
+	labour.PlannedCost = labour.PlannedCost.Round(2)
+	labour.ActualCost = labour.ActualCost.Round(2)
+	labour.PlannedCostWorked = labour.PlannedCostWorked.Round(2)
+	labour.CostVariance = labour.CostVariance.Round(2)
+	return costing, nil
+}
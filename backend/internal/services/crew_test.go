@@ -0,0 +1,64 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestCrewWorkDates(t *testing.T) {
+	day := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+
+	tests := []struct {
+		name     string
+		from, to string
+		weekdays []int
+		want     int
+		wantErr  bool
+	}{
+		{"single day", "2026-03-02", "2026-03-02", nil, 1, false},
+		{"every day of a week", "2026-03-02", "2026-03-08", nil, 7, false},
+		{"working week", "2026-03-02", "2026-03-08", []int{1, 2, 3, 4, 5}, 5, false},
+		{"two saturdays", "2026-03-01", "2026-03-14", []int{6}, 2, false},
+		{"no matching weekday", "2026-03-02", "2026-03-03", []int{0}, 0, true},
+		{"invalid weekday", "2026-03-02", "2026-03-03", []int{7}, 0, true},
+		{"end before start", "2026-03-03", "2026-03-02", nil, 0, true},
+		{"too long", "2026-03-01", "2026-05-02", nil, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dates, err := crewWorkDates(day(tt.from), day(tt.to), tt.weekdays)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("crewWorkDates() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(dates) != tt.want {
+				t.Errorf("crewWorkDates() returned %d days, want %d", len(dates), tt.want)
+			}
+		})
+	}
+}
+
+func TestCrewDoubleBooked(t *testing.T) {
+	h := decimal.NewFromFloat
+	tests := []struct {
+		name         string
+		other, hours float64
+		want         bool
+	}{
+		{"free day", 0, 8, false},
+		{"long day on one project", 0, 10, false},
+		{"split day", 4, 4, false},
+		{"over the day", 6, 4, true},
+	}
+
+	for _, tt := range tests {
+		if got := crewDoubleBooked(h(tt.other), h(tt.hours), h(8)); got != tt.want {
+			t.Errorf("%s: crewDoubleBooked() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,424 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// dataExportBatch is how many exports the worker builds per run
+	dataExportBatch = 2
+	// dataExportStaleAfter releases exports left processing by a worker that stopped
+	dataExportStaleAfter = 30 * time.Minute
+	// dataExportProgressEvery is how many rows are written between progress updates
+	dataExportProgressEvery = 1000
+)
+
+// dataExportSource is how the records of an entity are read. Queries take the organization
+// and the optional from/to bounds of the date column, and select every column as text.
+type dataExportSource struct {
+	header []string
+	from   string // FROM and JOIN clauses, the main table aliased as x
+	cols   string
+	org    string // the column holding the organization, x.organization_id when empty
+	where  string // extra conditions besides the organization
+	date   string
+	order  string
+}
+
+var dataExportSources = map[models.DataExportEntity]dataExportSource{
+	models.DataExportClients: {
+		header: []string{"id", "name", "email", "phone", "address", "tax_id", "segment", "notes", "created_at"},
+		from:   `clients x`,
+		cols:   `x.id::text, x.name, x.email, x.phone, x.address, x.tax_id, x.segment, x.notes, x.created_at::text`,
+		where:  `x.deleted_at IS NULL`,
+		date:   `x.created_at`,
+		order:  `x.created_at, x.id`,
+	},
+	models.DataExportProjects: {
+		header: []string{"id", "project_number", "title", "status", "progress", "category", "client", "start_date", "expected_end_date", "actual_end_date", "created_at"},
+		from: `projects x
+			LEFT JOIN budgets b ON b.id = x.budget_id
+			LEFT JOIN worksheets w ON w.id = b.worksheet_id
+			LEFT JOIN clients c ON c.id = w.client_id`,
+		cols: `x.id::text, x.project_number, x.title, x.status, x.progress::text, x.category, c.name,
+			x.start_date::text, x.expected_end_date::text, x.actual_end_date::text, x.created_at::text`,
+		where: `x.deleted_at IS NULL`,
+		date:  `x.created_at`,
+		order: `x.created_at, x.id`,
+	},
+	models.DataExportPayments: {
+		header: []string{"id", "project_number", "amount", "status", "due_date", "paid_at", "method", "reference", "notes"},
+		from:   `payments x JOIN projects p ON p.id = x.project_id`,
+		cols: `x.id::text, p.project_number, x.amount::text, x.status, x.due_date::text, x.paid_at::text,
+			x.method, x.reference, x.notes`,
+		where: `x.deleted_at IS NULL`,
+		date:  `x.due_date`,
+		order: `x.due_date, x.id`,
+	},
+	models.DataExportExpenses: {
+		header: []string{"id", "scope", "project_number", "status", "vendor", "vendor_tax_id", "expense_date", "amount", "vat_amount", "vat_rate", "category", "description"},
+		from:   `expenses x LEFT JOIN projects p ON p.id = x.project_id`,
+		cols: `x.id::text, x.scope, p.project_number, x.status, x.vendor, x.vendor_tax_id, x.expense_date::text,
+			x.amount::text, x.vat_amount::text, x.vat_rate::text, x.category, x.description`,
+		where: `x.deleted_at IS NULL`,
+		date:  `x.expense_date`,
+		order: `x.expense_date, x.id`,
+	},
+	models.DataExportPatients: {
+		header: []string{"id", "name", "email", "phone", "date_of_birth", "emergency_contact", "emergency_phone", "is_active", "notes", "created_at"},
+		from:   `patients x LEFT JOIN clients c ON c.id = x.client_id`,
+		cols: `x.id::text, c.name, c.email, c.phone, x.date_of_birth::text, x.emergency_contact, x.emergency_phone,
+			x.is_active::text, x.notes, x.created_at::text`,
+		where: `x.deleted_at IS NULL`,
+		date:  `x.created_at`,
+		order: `x.created_at, x.id`,
+	},
+	models.DataExportSessions: {
+		header: []string{"id", "scheduled_at", "duration_minutes", "status", "session_type", "patient", "therapist", "price_cents", "cancel_reason", "cancelled_at", "completed_at", "notes"},
+		from: `sessions x
+			JOIN patients pt ON pt.id = x.patient_id
+			LEFT JOIN clients c ON c.id = pt.client_id
+			JOIN therapists t ON t.id = x.therapist_id`,
+		cols: `x.id::text, x.scheduled_at::text, x.duration_minutes::text, x.status, x.session_type, c.name, t.name,
+			x.price_cents::text, x.cancel_reason, x.cancelled_at::text, x.completed_at::text, x.notes`,
+		where: `x.deleted_at IS NULL`,
+		date:  `x.scheduled_at`,
+		order: `x.scheduled_at, x.id`,
+	},
+	models.DataExportSessionPayments: {
+		header: []string{"id", "session_id", "scheduled_at", "patient", "kind", "amount_cents", "payment_status", "payment_method", "insurance_provider", "insurance_amount_cents", "due_date", "paid_at"},
+		from: `session_payments x
+			JOIN sessions s ON s.id = x.session_id
+			JOIN patients pt ON pt.id = s.patient_id
+			LEFT JOIN clients c ON c.id = pt.client_id`,
+		cols: `x.id::text, s.id::text, s.scheduled_at::text, c.name, x.kind, x.amount_cents::text, x.payment_status,
+			x.payment_method, x.insurance_provider, x.insurance_amount_cents::text, x.due_date::text, x.paid_at::text`,
+		org:   `s.organization_id`,
+		where: `s.deleted_at IS NULL`,
+		date:  `s.scheduled_at`,
+		order: `s.scheduled_at, x.id`,
+	},
+}
+
+func (src dataExportSource) conditions() string {
+	org := src.org
+	if org == "" {
+		org = `x.organization_id`
+	}
+	return org + ` = $1 AND ` + src.where + `
+		AND ($2::timestamptz IS NULL OR ` + src.date + ` >= $2)
+		AND ($3::timestamptz IS NULL OR ` + src.date + ` < $3)`
+}
+
+// query is the statement selecting the records, in export order
+func (src dataExportSource) query() string {
+	return `SELECT ` + src.cols + ` FROM ` + src.from + ` WHERE ` + src.conditions() + ` ORDER BY ` + src.order
+}
+
+// writeCSV writes the records matching args as CSV with a header row, calling progress every
+// dataExportProgressEvery rows when given, and returns how many records were written
+func (src dataExportSource) writeCSV(ctx context.Context, db *database.DB, w io.Writer, args []interface{}, progress func(written int)) (int, error) {
+	rows, err := db.Pool.Query(ctx, src.query(), args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query records: %w", err)
+	}
+	defer rows.Close()
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(src.header); err != nil {
+		return 0, err
+	}
+
+	record := make([]string, len(src.header))
+	written := 0
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return written, fmt.Errorf("failed to read record: %w", err)
+		}
+		for i, v := range values {
+			record[i] = ""
+			if v != nil {
+				record[i] = fmt.Sprint(v)
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return written, err
+		}
+
+		written++
+		if progress != nil && written%dataExportProgressEvery == 0 {
+			progress(written)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return written, fmt.Errorf("failed to read records: %w", err)
+	}
+	writer.Flush()
+	return written, writer.Error()
+}
+
+// DataExportService queues and builds CSV exports of a module's records, which are too large
+// for a synchronous request in big organizations
+type DataExportService struct {
+	db        *database.DB
+	storage   *StorageService
+	retention time.Duration
+}
+
+func NewDataExportService(db *database.DB, storage *StorageService, retention time.Duration) *DataExportService {
+	return &DataExportService{db: db, storage: storage, retention: retention}
+}
+
+const dataExportColumns = `id, organization_id, entity, filters, status, total_rows, processed_rows, file_url,
+	file_name, file_size, error, requested_by, created_at, started_at, completed_at, expires_at`
+
+func scanDataExport(row pgx.Row) (*models.DataExport, error) {
+	e := &models.DataExport{}
+	err := row.Scan(&e.ID, &e.OrganizationID, &e.Entity, &e.Filters, &e.Status, &e.TotalRows, &e.ProcessedRows,
+		&e.FileURL, &e.FileName, &e.FileSize, &e.Error, &e.RequestedBy, &e.CreatedAt, &e.StartedAt,
+		&e.CompletedAt, &e.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case e.Status == models.DataExportReady || e.Status == models.DataExportExpired:
+		e.Progress = 1
+	case e.TotalRows != nil && *e.TotalRows > 0:
+		e.Progress = float64(e.ProcessedRows) / float64(*e.TotalRows)
+	}
+	return e, nil
+}
+
+// RequestExport queues an export of an entity's records for the worker
+func (s *DataExportService) RequestExport(ctx context.Context, orgID, userID uuid.UUID, entity models.DataExportEntity, filters models.DataExportFilters) (*models.DataExport, error) {
+	if _, ok := dataExportSources[entity]; !ok {
+		return nil, errors.New("invalid export entity")
+	}
+	if filters.From != nil && filters.To != nil && !filters.To.After(*filters.From) {
+		return nil, errors.New("end date must be after start date")
+	}
+
+	var inProgress bool
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM data_exports
+			WHERE organization_id = $1 AND entity = $2 AND status IN ('pending', 'processing')
+		)
+	`, orgID, entity).Scan(&inProgress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check data exports: %w", err)
+	}
+	if inProgress {
+		return nil, errors.New("an export of this data is already in progress")
+	}
+
+	export, err := scanDataExport(s.db.Pool.QueryRow(ctx, `
+		INSERT INTO data_exports (organization_id, entity, filters, requested_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+dataExportColumns, orgID, entity, filters, userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create data export: %w", err)
+	}
+	return export, nil
+}
+
+// ListExports returns the organization's data exports, newest first
+func (s *DataExportService) ListExports(ctx context.Context, orgID uuid.UUID, limit, offset int) ([]*models.DataExport, int, error) {
+	var total int
+	err := s.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM data_exports WHERE organization_id = $1`, orgID).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count data exports: %w", err)
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT `+dataExportColumns+` FROM data_exports
+		WHERE organization_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`, orgID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list data exports: %w", err)
+	}
+	defer rows.Close()
+
+	exports := []*models.DataExport{}
+	for rows.Next() {
+		e, err := scanDataExport(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan data export: %w", err)
+		}
+		exports = append(exports, e)
+	}
+	return exports, total, rows.Err()
+}
+
+// GetExport returns a data export with its progress and, once ready, a signed download link
+func (s *DataExportService) GetExport(ctx context.Context, id, orgID uuid.UUID) (*models.DataExport, error) {
+	e, err := scanDataExport(s.db.Pool.QueryRow(ctx, `
+		SELECT `+dataExportColumns+` FROM data_exports WHERE id = $1 AND organization_id = $2
+	`, id, orgID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.New("data export not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data export: %w", err)
+	}
+
+	if e.Status == models.DataExportReady && e.FileURL != nil {
+		url, err := s.storage.DownloadURL(ctx, *e.FileURL, exportDownloadExpiry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create download link: %w", err)
+		}
+		e.DownloadURL = &url
+	}
+	return e, nil
+}
+
+// ProcessPendingExports builds the queued exports. It is run by the worker; exports are
+// claimed with SKIP LOCKED so several workers never build the same one.
+func (s *DataExportService) ProcessPendingExports(ctx context.Context) (int, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		UPDATE data_exports SET status = 'processing', started_at = NOW(), processed_rows = 0
+		WHERE id IN (
+			SELECT id FROM data_exports
+			WHERE status = 'pending' OR (status = 'processing' AND started_at < $1)
+			ORDER BY created_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+dataExportColumns, time.Now().Add(-dataExportStaleAfter), dataExportBatch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim data exports: %w", err)
+	}
+	var exports []*models.DataExport
+	for rows.Next() {
+		e, err := scanDataExport(rows)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan data export: %w", err)
+		}
+		exports = append(exports, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to claim data exports: %w", err)
+	}
+
+	built := 0
+	for _, e := range exports {
+		if err := s.processExport(ctx, e); err != nil {
+			log.Printf("[DataExports] Export %s failed: %v", e.ID, err)
+			if _, dbErr := s.db.Pool.Exec(ctx, `
+				UPDATE data_exports SET status = 'failed', error = $2, completed_at = NOW() WHERE id = $1
+			`, e.ID, err.Error()); dbErr != nil {
+				log.Printf("[DataExports] Failed to record error of export %s: %v", e.ID, dbErr)
+			}
+			continue
+		}
+		built++
+	}
+	return built, nil
+}
+
+// processExport writes the records as CSV, recording progress as it goes, and uploads the file
+func (s *DataExportService) processExport(ctx context.Context, e *models.DataExport) error {
+	src, ok := dataExportSources[e.Entity]
+	if !ok {
+		return fmt.Errorf("unknown export entity %q", e.Entity)
+	}
+	args := []interface{}{e.OrganizationID, e.Filters.From, e.Filters.To}
+
+	var total int
+	if err := s.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM `+src.from+` WHERE `+src.conditions(), args...).Scan(&total); err != nil {
+		return fmt.Errorf("failed to count records: %w", err)
+	}
+	if _, err := s.db.Pool.Exec(ctx, `UPDATE data_exports SET total_rows = $2 WHERE id = $1`, e.ID, total); err != nil {
+		return fmt.Errorf("failed to update data export: %w", err)
+	}
+
+	var buf bytes.Buffer
+	written, err := src.writeCSV(ctx, s.db, &buf, args, func(written int) {
+		if _, err := s.db.Pool.Exec(ctx, `UPDATE data_exports SET processed_rows = $2 WHERE id = $1`, e.ID, written); err != nil {
+			log.Printf("[DataExports] Failed to record progress of export %s: %v", e.ID, err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	fileName := fmt.Sprintf("%s-%s.csv", e.Entity, time.Now().Format("2006-01-02"))
+	upload, err := s.storage.UploadGenerated(ctx, buf.Bytes(), fileName, "text/csv", e.OrganizationID)
+	if err != nil {
+		return fmt.Errorf("failed to upload export: %w", err)
+	}
+
+	_, err = s.db.Pool.Exec(ctx, `
+		UPDATE data_exports
+		SET status = 'ready', total_rows = $2, processed_rows = $2, file_url = $3, file_name = $4, file_size = $5,
+		    error = NULL, completed_at = NOW(), expires_at = $6
+		WHERE id = $1
+	`, e.ID, written, upload.URL, fileName, upload.FileSize, time.Now().Add(s.retention))
+	if err != nil {
+		return fmt.Errorf("failed to update data export: %w", err)
+	}
+	return nil
+}
+
+// CleanupExpiredExports removes the files of exports past their expiry. It is run by the worker.
+func (s *DataExportService) CleanupExpiredExports(ctx context.Context) (int, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, file_url FROM data_exports
+		WHERE status = 'ready' AND expires_at < NOW()
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list expired data exports: %w", err)
+	}
+	type expired struct {
+		id      uuid.UUID
+		fileURL *string
+	}
+	var due []expired
+	for rows.Next() {
+		var e expired
+		if err := rows.Scan(&e.id, &e.fileURL); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan data export: %w", err)
+		}
+		due = append(due, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to list expired data exports: %w", err)
+	}
+
+	removed := 0
+	for _, e := range due {
+		if e.fileURL != nil {
+			if err := s.storage.DeleteFile(ctx, *e.fileURL); err != nil {
+				// Kept as ready so the next run tries again
+				log.Printf("[DataExports] Failed to delete file of export %s: %v", e.id, err)
+				continue
+			}
+		}
+		if _, err := s.db.Pool.Exec(ctx, `
+			UPDATE data_exports SET status = 'expired', file_url = NULL WHERE id = $1
+		`, e.id); err != nil {
+			return removed, fmt.Errorf("failed to expire data export: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/controlwise/backend/internal/models"
+)
+
+// TestDataExportSourcesQuery runs the query of every export source, so a column missing from
+// the schema fails here rather than in the worker. Everything is rolled back.
+func TestDataExportSourcesQuery(t *testing.T) {
+	pool := openTestDB(t)
+	ctx := context.Background()
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	orgID, userID := insertTestOrganization(t, ctx, tx, "Export")
+	_, patientID := insertTestPatient(t, ctx, tx, orgID, userID, "Ana", "ana@test.local", "+351910000001")
+
+	for entity, src := range dataExportSources {
+		rows, err := tx.Query(ctx, src.query(), orgID, nil, nil)
+		if err != nil {
+			t.Errorf("%s export: %v", entity, err)
+			continue
+		}
+		for rows.Next() {
+			values, err := rows.Values()
+			if err != nil {
+				t.Errorf("%s export: %v", entity, err)
+				break
+			}
+			if len(values) != len(src.header) {
+				t.Errorf("%s export selects %d columns, header has %d", entity, len(values), len(src.header))
+			}
+			if entity == models.DataExportPatients && values[0] == patientID.String() && values[1] != "Ana" {
+				t.Errorf("patient export name = %v, want the client's name", values[1])
+			}
+		}
+		if err := rows.Err(); err != nil {
+			t.Errorf("%s export: %v", entity, err)
+		}
+		rows.Close()
+	}
+}
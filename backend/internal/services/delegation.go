@@ -0,0 +1,273 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// DelegationService handles out-of-office settings and rerouting of work to delegates
+type DelegationService struct {
+	db *database.DB
+}
+
+func NewDelegationService(db *database.DB) *DelegationService {
+	return &DelegationService{db: db}
+}
+
+// ============ Out-of-office ============
+
+// ListOutOfOffice returns out-of-office entries for an organization, optionally filtered by user
+func (s *DelegationService) ListOutOfOffice(ctx context.Context, orgID uuid.UUID, userID *uuid.UUID, currentOnly bool) ([]*models.OutOfOffice, error) {
+	query := `
+		SELECT
+			o.id, o.organization_id, o.user_id, o.delegate_user_id, o.starts_at, o.ends_at,
+			o.reason, o.delegate_approvals, o.delegate_tasks, o.delegate_escalations,
+			o.is_active, o.created_by, o.created_at, o.updated_at,
+			COALESCE(u.first_name || ' ' || u.last_name, '') as user_name,
+			COALESCE(d.first_name || ' ' || d.last_name, '') as delegate_name
+		FROM user_out_of_office o
+		LEFT JOIN users u ON u.id = o.user_id
+		LEFT JOIN users d ON d.id = o.delegate_user_id
+		WHERE o.organization_id = $1
+	`
+	args := []interface{}{orgID}
+	argNum := 2
+
+	if userID != nil {
+		query += fmt.Sprintf(" AND o.user_id = $%d", argNum)
+		args = append(args, *userID)
+		argNum++
+	}
+	if currentOnly {
+		query += " AND o.is_active = true AND o.ends_at > NOW()"
+	}
+	query += " ORDER BY o.starts_at DESC"
+
+	rows, err := s.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query out-of-office entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.OutOfOffice
+	for rows.Next() {
+		var o models.OutOfOffice
+		if err := rows.Scan(
+			&o.ID, &o.OrganizationID, &o.UserID, &o.DelegateUserID, &o.StartsAt, &o.EndsAt,
+			&o.Reason, &o.DelegateApprovals, &o.DelegateTasks, &o.DelegateEscalations,
+			&o.IsActive, &o.CreatedBy, &o.CreatedAt, &o.UpdatedAt,
+			&o.UserName, &o.DelegateName,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan out-of-office entry: %w", err)
+		}
+		entries = append(entries, &o)
+	}
+
+	return entries, nil
+}
+
+// GetOutOfOffice returns a single out-of-office entry
+func (s *DelegationService) GetOutOfOffice(ctx context.Context, id, orgID uuid.UUID) (*models.OutOfOffice, error) {
+	var o models.OutOfOffice
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT
+			id, organization_id, user_id, delegate_user_id, starts_at, ends_at,
+			reason, delegate_approvals, delegate_tasks, delegate_escalations,
+			is_active, created_by, created_at, updated_at
+		FROM user_out_of_office
+		WHERE id = $1 AND organization_id = $2
+	`, id, orgID).Scan(
+		&o.ID, &o.OrganizationID, &o.UserID, &o.DelegateUserID, &o.StartsAt, &o.EndsAt,
+		&o.Reason, &o.DelegateApprovals, &o.DelegateTasks, &o.DelegateEscalations,
+		&o.IsActive, &o.CreatedBy, &o.CreatedAt, &o.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("out-of-office entry not found")
+		}
+		return nil, fmt.Errorf("failed to get out-of-office entry: %w", err)
+	}
+
+	return &o, nil
+}
+
+// CreateOutOfOffice creates a new out-of-office entry
+func (s *DelegationService) CreateOutOfOffice(ctx context.Context, o *models.OutOfOffice) error {
+	if err := s.validateOutOfOffice(ctx, o, nil); err != nil {
+		return err
+	}
+
+	o.ID = uuid.New()
+	o.IsActive = true
+	o.CreatedAt = time.Now()
+	o.UpdatedAt = o.CreatedAt
+
+	_, err := s.db.Pool.Exec(ctx, `
+		INSERT INTO user_out_of_office (
+			id, organization_id, user_id, delegate_user_id, starts_at, ends_at,
+			reason, delegate_approvals, delegate_tasks, delegate_escalations,
+			is_active, created_by, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`, o.ID, o.OrganizationID, o.UserID, o.DelegateUserID, o.StartsAt, o.EndsAt,
+		o.Reason, o.DelegateApprovals, o.DelegateTasks, o.DelegateEscalations,
+		o.IsActive, o.CreatedBy, o.CreatedAt, o.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create out-of-office entry: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateOutOfOffice updates an out-of-office entry
+func (s *DelegationService) UpdateOutOfOffice(ctx context.Context, id, orgID uuid.UUID, o *models.OutOfOffice) error {
+	o.ID = id
+	o.OrganizationID = orgID
+	if o.IsActive {
+		if err := s.validateOutOfOffice(ctx, o, &id); err != nil {
+			return err
+		}
+	}
+
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE user_out_of_office
+		SET delegate_user_id = $1, starts_at = $2, ends_at = $3, reason = $4,
+			delegate_approvals = $5, delegate_tasks = $6, delegate_escalations = $7,
+			is_active = $8, updated_at = NOW()
+		WHERE id = $9 AND organization_id = $10
+	`, o.DelegateUserID, o.StartsAt, o.EndsAt, o.Reason,
+		o.DelegateApprovals, o.DelegateTasks, o.DelegateEscalations,
+		o.IsActive, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to update out-of-office entry: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("out-of-office entry not found")
+	}
+
+	return nil
+}
+
+// DeleteOutOfOffice deletes an out-of-office entry; its audit records are kept
+func (s *DelegationService) DeleteOutOfOffice(ctx context.Context, id, orgID uuid.UUID) error {
+	result, err := s.db.Pool.Exec(ctx, `
+		DELETE FROM user_out_of_office WHERE id = $1 AND organization_id = $2
+	`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to delete out-of-office entry: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("out-of-office entry not found")
+	}
+	return nil
+}
+
+// validateOutOfOffice checks dates, the delegate and overlapping absence windows
+func (s *DelegationService) validateOutOfOffice(ctx context.Context, o *models.OutOfOffice, excludeID *uuid.UUID) error {
+	if !o.EndsAt.After(o.StartsAt) {
+		return errors.New("end date must be after start date")
+	}
+	if o.UserID == o.DelegateUserID {
+		return errors.New("a user cannot delegate to themselves")
+	}
+
+	var delegateActive bool
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT is_active FROM users
+		WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`, o.DelegateUserID, o.OrganizationID).Scan(&delegateActive)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("delegate not found")
+		}
+		return fmt.Errorf("failed to check delegate: %w", err)
+	}
+	if !delegateActive {
+		return errors.New("delegate is not an active user")
+	}
+
+	var overlapping bool
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM user_out_of_office
+			WHERE user_id = $1 AND organization_id = $2 AND is_active = true
+			AND starts_at < $4 AND ends_at > $3
+			AND ($5::uuid IS NULL OR id <> $5)
+		)
+	`, o.UserID, o.OrganizationID, o.StartsAt, o.EndsAt, excludeID).Scan(&overlapping)
+	if err != nil {
+		return fmt.Errorf("failed to check overlapping entries: %w", err)
+	}
+	if overlapping {
+		return errors.New("user already has an out-of-office entry in this period")
+	}
+
+	return nil
+}
+
+// ============ Delegation audit ============
+
+// DelegationAuditFilters contains filters for listing delegation audit records
+type DelegationAuditFilters struct {
+	UserID *uuid.UUID
+	Page   int
+	Limit  int
+}
+
+// ListDelegationAudit returns delegation audit records for an organization
+func (s *DelegationService) ListDelegationAudit(ctx context.Context, orgID uuid.UUID, filters DelegationAuditFilters) ([]*models.DelegationAuditLog, int, error) {
+	if filters.Page < 1 {
+		filters.Page = 1
+	}
+	if filters.Limit < 1 || filters.Limit > 100 {
+		filters.Limit = 50
+	}
+
+	where := " WHERE organization_id = $1"
+	args := []interface{}{orgID}
+	argNum := 2
+
+	if filters.UserID != nil {
+		where += fmt.Sprintf(" AND (original_user_id = $%d OR delegate_user_id = $%d)", argNum, argNum)
+		args = append(args, *filters.UserID)
+		argNum++
+	}
+
+	var total int
+	if err := s.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM delegation_audit_log"+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count delegation records: %w", err)
+	}
+
+	query := `
+		SELECT id, organization_id, out_of_office_id, original_user_id, delegate_user_id,
+			delegation_type, entity_type, entity_id, details, created_at
+		FROM delegation_audit_log` + where +
+		fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argNum, argNum+1)
+	args = append(args, filters.Limit, (filters.Page-1)*filters.Limit)
+
+	rows, err := s.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query delegation records: %w", err)
+	}
+	defer rows.Close()
+
+	var records []*models.DelegationAuditLog
+	for rows.Next() {
+		var d models.DelegationAuditLog
+		if err := rows.Scan(
+			&d.ID, &d.OrganizationID, &d.OutOfOfficeID, &d.OriginalUserID, &d.DelegateUserID,
+			&d.DelegationType, &d.EntityType, &d.EntityID, &d.Details, &d.CreatedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan delegation record: %w", err)
+		}
+		records = append(records, &d)
+	}
+
+	return records, total, nil
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// maxDocumentNumberAttempts bounds how many allocated numbers are skipped because a document
+// already uses them before giving up
+const maxDocumentNumberAttempts = 50
+
+// documentSequence is a kind of numbered document and where its numbers are kept
+type documentSequence struct {
+	documentType string
+	table        string
+	column       string
+}
+
+var (
+	projectSequence = documentSequence{documentType: "project", table: "projects", column: "project_number"}
+	budgetSequence  = documentSequence{documentType: "budget", table: "budgets", column: "budget_number"}
+)
+
+// formatDocumentNumber builds a document number such as PRJ-2026-007
+func formatDocumentNumber(prefix string, year, number int) string {
+	return fmt.Sprintf("%s-%d-%03d", prefix, year, number)
+}
+
+// nextDocumentNumber allocates the next number of an organization's sequence for a document
+// type, prefix and year. The sequence row stays locked until the transaction ends, so documents
+// created at the same time never get the same number. A new sequence starts after the highest
+// number already in use, and numbers taken since (set by hand or kept by a merge) are skipped.
+func nextDocumentNumber(ctx context.Context, q rowQuerier, seq documentSequence, orgID uuid.UUID, prefix string, year int) (string, error) {
+	base := fmt.Sprintf("%s-%d-", prefix, year)
+	suffixStart := utf8.RuneCountInString(base) + 1
+
+	for attempt := 0; attempt < maxDocumentNumberAttempts; attempt++ {
+		var number int
+		err := q.QueryRow(ctx, `
+			INSERT INTO document_sequences (organization_id, document_type, prefix, year, last_number)
+			VALUES ($1, $2, $3, $4, (
+				SELECT COALESCE(MAX(substring(`+seq.column+` FROM $5::int)::int), 0) + 1
+				FROM `+seq.table+`
+				WHERE organization_id = $1 AND left(`+seq.column+`, $5::int - 1) = $6
+					AND substring(`+seq.column+` FROM $5::int) ~ '^[0-9]{1,9}$'
+			))
+			ON CONFLICT (organization_id, document_type, prefix, year)
+			DO UPDATE SET last_number = document_sequences.last_number + 1, updated_at = NOW()
+			RETURNING last_number
+		`, orgID, seq.documentType, prefix, year, suffixStart, base).Scan(&number)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate %s number: %w", seq.documentType, err)
+		}
+
+		documentNumber := formatDocumentNumber(prefix, year, number)
+		var taken bool
+		err = q.QueryRow(ctx, `
+			SELECT EXISTS(SELECT 1 FROM `+seq.table+` WHERE organization_id = $1 AND `+seq.column+` = $2)
+		`, orgID, documentNumber).Scan(&taken)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate %s number: %w", seq.documentType, err)
+		}
+		if !taken {
+			return documentNumber, nil
+		}
+	}
+
+	return "", fmt.Errorf("failed to generate %s number: too many numbers of %s already in use", seq.documentType, base)
+}
+
+// nextBudgetNumber allocates the next budget number for an organization (ORC-YYYY-NNN)
+func nextBudgetNumber(ctx context.Context, q rowQuerier, orgID uuid.UUID, year int) (string, error) {
+	return nextDocumentNumber(ctx, q, budgetSequence, orgID, "ORC", year)
+}
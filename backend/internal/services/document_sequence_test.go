@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestFormatDocumentNumber(t *testing.T) {
+	tests := []struct {
+		prefix string
+		year   int
+		number int
+		want   string
+	}{
+		{"PRJ", 2026, 1, "PRJ-2026-001"},
+		{"OBRA", 2026, 42, "OBRA-2026-042"},
+		{"ORC", 2025, 1234, "ORC-2025-1234"},
+	}
+
+	for _, tt := range tests {
+		if got := formatDocumentNumber(tt.prefix, tt.year, tt.number); got != tt.want {
+			t.Errorf("formatDocumentNumber(%q, %d, %d) = %q, want %q", tt.prefix, tt.year, tt.number, got, tt.want)
+		}
+	}
+}
+
+// TestNextDocumentNumberConcurrent allocates project numbers from many transactions at once and
+// checks each got its own number, without gaps. It needs a migrated database in TEST_DATABASE_URL.
+func TestNextDocumentNumberConcurrent(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer pool.Close()
+
+	orgID := uuid.New()
+	if _, err := pool.Exec(ctx, `INSERT INTO organizations (id, name, email) VALUES ($1, 'Sequence test', 'sequence@test.local')`, orgID); err != nil {
+		t.Fatalf("failed to create organization: %v", err)
+	}
+	defer pool.Exec(ctx, `DELETE FROM organizations WHERE id = $1`, orgID)
+
+	const workers = 40
+	numbers := make(chan string, workers)
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tx, err := pool.Begin(ctx)
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer tx.Rollback(ctx)
+
+			number, err := nextProjectNumber(ctx, tx, orgID, 2026)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if err := tx.Commit(ctx); err != nil {
+				errs <- err
+				return
+			}
+			numbers <- number
+		}()
+	}
+	wg.Wait()
+	close(numbers)
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("failed to allocate number: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	for number := range numbers {
+		if seen[number] {
+			t.Errorf("number %s allocated twice", number)
+		}
+		seen[number] = true
+	}
+	for n := 1; n <= workers; n++ {
+		if want := formatDocumentNumber("PRJ", 2026, n); !seen[want] {
+			t.Errorf("number %s was not allocated", want)
+		}
+	}
+}
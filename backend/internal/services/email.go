@@ -2,9 +2,15 @@ package services
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"html"
 	"html/template"
+	"net"
 	"net/smtp"
+	"time"
 
 	"github.com/controlwise/backend/internal/config"
 )
@@ -89,6 +95,169 @@ func (s *EmailService) SendPaymentDue(to, clientName, amount, dueDate string) er
 	return s.send(to, subject, body)
 }
 
+func (s *EmailService) SendUserInvite(to, inviterName, organizationName, acceptURL string, expiresAt time.Time) error {
+	subject := "Convite para " + organizationName
+	invitedBy := ""
+	if inviterName != "" {
+		invitedBy = " por " + html.EscapeString(inviterName)
+	}
+	body := fmt.Sprintf(`
+		<html>
+		<body>
+			<h2>Olá,</h2>
+			<p>Foi convidado(a)%s para se juntar à equipa <strong>%s</strong> no controlwise.</p>
+			<p>Para aceitar o convite e criar a sua conta, aceda a <a href="%s">este link</a>.</p>
+			<p>O convite é válido até %s.</p>
+			<br>
+			<p>Obrigado,<br>A equipa controlwise</p>
+		</body>
+		</html>
+	`, invitedBy, html.EscapeString(organizationName), html.EscapeString(acceptURL), expiresAt.Format("02/01/2006 15:04"))
+
+	return s.send(to, subject, body)
+}
+
+// SendExportReady tells the requester of an export bundle that it can be downloaded
+func (s *EmailService) SendExportReady(to, userName, organizationName, period, downloadURL string) error {
+	subject := "Exportação contabilística " + period + " disponível"
+	body := fmt.Sprintf(`
+		<html>
+		<body>
+			<h2>Olá %s,</h2>
+			<p>A exportação contabilística de <strong>%s</strong> referente a <strong>%s</strong> está pronta.</p>
+			<p>Inclui os pagamentos recebidos, as despesas lançadas e o ficheiro SAF-T do mês.</p>
+			<p>Pode descarregá-la a partir de <a href="%s">este link</a>.</p>
+			<br>
+			<p>Obrigado,<br>A equipa controlwise</p>
+		</body>
+		</html>
+	`, html.EscapeString(userName), html.EscapeString(organizationName), period, html.EscapeString(downloadURL))
+
+	return s.send(to, subject, body)
+}
+
+// SendFollowUpReminder reminds a user of a follow-up they set on a record
+func (s *EmailService) SendFollowUpReminder(to, userName, entityLabel, note, link string) error {
+	subject := "Lembrete: " + entityLabel
+	noteHTML := ""
+	if note != "" {
+		noteHTML = "<p><em>" + html.EscapeString(note) + "</em></p>"
+	}
+	body := fmt.Sprintf(`
+		<html>
+		<body>
+			<h2>Olá %s,</h2>
+			<p>Pediu para ser lembrado(a) de <strong>%s</strong>.</p>
+			%s
+			<p>Pode abri-lo a partir de <a href="%s">este link</a>.</p>
+			<br>
+			<p>Obrigado,<br>A equipa controlwise</p>
+		</body>
+		</html>
+	`, html.EscapeString(userName), html.EscapeString(entityLabel), noteHTML, html.EscapeString(link))
+
+	return s.send(to, subject, body)
+}
+
+// SendSecurityAlert tells a system admin that another admin's activity was flagged
+func (s *EmailService) SendSecurityAlert(to, adminName, flaggedAdmin, summary, link string) error {
+	subject := "Alerta de segurança: " + flaggedAdmin
+	body := fmt.Sprintf(`
+		<html>
+		<body>
+			<h2>Olá %s,</h2>
+			<p>Foi detetada atividade invulgar do administrador <strong>%s</strong>:</p>
+			<p><strong>%s</strong></p>
+			<p>O administrador terá de justificar esta atividade. Pode rever o alerta a partir de <a href="%s">este link</a>.</p>
+			<br>
+			<p>Obrigado,<br>A equipa controlwise</p>
+		</body>
+		</html>
+	`, html.EscapeString(adminName), html.EscapeString(flaggedAdmin), html.EscapeString(summary), html.EscapeString(link))
+
+	return s.send(to, subject, body)
+}
+
+// SendSecurityJustificationRequest asks a system admin to justify activity that was flagged
+func (s *EmailService) SendSecurityJustificationRequest(to, adminName, summary, link string) error {
+	subject := "Justificação necessária: alerta de segurança"
+	body := fmt.Sprintf(`
+		<html>
+		<body>
+			<h2>Olá %s,</h2>
+			<p>A sua atividade recente foi assinalada como invulgar:</p>
+			<p><strong>%s</strong></p>
+			<p>Até a justificar, não poderá realizar outras ações sensíveis. Pode justificá-la a partir de <a href="%s">este link</a>.</p>
+			<br>
+			<p>Obrigado,<br>A equipa controlwise</p>
+		</body>
+		</html>
+	`, html.EscapeString(adminName), html.EscapeString(summary), html.EscapeString(link))
+
+	return s.send(to, subject, body)
+}
+
+// SendOrganizationDeletionNotice tells an organization admin that the organization will be
+// deleted, when it was requested and again as the cooling-off period runs out
+func (s *EmailService) SendOrganizationDeletionNotice(to, userName, organizationName string, deletesAt time.Time, reminder bool, link string) error {
+	subject := "Pedido de eliminação de " + organizationName
+	if reminder {
+		subject = "Lembrete: " + organizationName + " será eliminada a " + deletesAt.Format("02/01/2006")
+	}
+	body := fmt.Sprintf(`
+		<html>
+		<body>
+			<h2>Olá %s,</h2>
+			<p>Foi pedida a eliminação da organização <strong>%s</strong>.</p>
+			<p>A <strong>%s</strong>, os dados da organização serão exportados e a organização será suspensa. Os dados serão depois eliminados definitivamente.</p>
+			<p>Até lá, o pedido pode ser cancelado por um administrador a partir de <a href="%s">este link</a>.</p>
+			<br>
+			<p>Obrigado,<br>A equipa controlwise</p>
+		</body>
+		</html>
+	`, html.EscapeString(userName), html.EscapeString(organizationName), deletesAt.Format("02/01/2006 15:04"), html.EscapeString(link))
+
+	return s.send(to, subject, body)
+}
+
+// SendOrganizationDeletionExport gives an organization admin the export of an organization
+// about to be purged
+func (s *EmailService) SendOrganizationDeletionExport(to, userName, organizationName, downloadURL string, purgeAfter time.Time) error {
+	subject := "Exportação final de " + organizationName
+	body := fmt.Sprintf(`
+		<html>
+		<body>
+			<h2>Olá %s,</h2>
+			<p>A organização <strong>%s</strong> foi suspensa e os seus dados foram exportados.</p>
+			<p>Pode descarregar a exportação a partir de <a href="%s">este link</a> até <strong>%s</strong>, data em que os dados e a exportação serão eliminados definitivamente.</p>
+			<br>
+			<p>Obrigado,<br>A equipa controlwise</p>
+		</body>
+		</html>
+	`, html.EscapeString(userName), html.EscapeString(organizationName), html.EscapeString(downloadURL), purgeAfter.Format("02/01/2006 15:04"))
+
+	return s.send(to, subject, body)
+}
+
+// SendPatientPortalLogin sends a patient the one-time link that signs them in to the patient portal
+func (s *EmailService) SendPatientPortalLogin(to, patientName, organizationName, link string, expiresIn time.Duration) error {
+	subject := "Acesso ao portal do paciente - " + organizationName
+	body := fmt.Sprintf(`
+		<html>
+		<body>
+			<h2>Olá %s,</h2>
+			<p>Recebemos um pedido de acesso ao seu portal de paciente em <strong>%s</strong>.</p>
+			<p><a href="%s">Entrar no portal</a></p>
+			<p>O link é válido durante %d minutos e só pode ser usado uma vez. Se não pediu este acesso, pode ignorar este email.</p>
+			<br>
+			<p>Obrigado,<br>A equipa controlwise</p>
+		</body>
+		</html>
+	`, html.EscapeString(patientName), html.EscapeString(organizationName), html.EscapeString(link), int(expiresIn.Minutes()))
+
+	return s.send(to, subject, body)
+}
+
 func (s *EmailService) send(to, subject, body string) error {
 	// Skip if SMTP not configured
 	if s.cfg.SMTPHost == "" || s.cfg.SMTPUser == "" {
@@ -108,6 +277,70 @@ func (s *EmailService) send(to, subject, body string) error {
 	return smtp.SendMail(addr, auth, s.cfg.SMTPFrom, []string{to}, msg)
 }
 
+// EmailSendResult describes an accepted SMTP delivery
+type EmailSendResult struct {
+	Provider   string    `json:"provider"`
+	Server     string    `json:"server"`
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	MessageID  string    `json:"message_id,omitempty"`
+	AcceptedAt time.Time `json:"accepted_at"`
+}
+
+// SendTest sends an email and reports the SMTP server that accepted it. Unlike regular sends,
+// it fails when SMTP is not configured.
+func (s *EmailService) SendTest(to, subject, body string) (*EmailSendResult, error) {
+	if s.cfg.SMTPHost == "" || s.cfg.SMTPUser == "" {
+		return nil, errors.New("SMTP is not configured")
+	}
+	if err := s.send(to, subject, body); err != nil {
+		return nil, fmt.Errorf("smtp error: %w", err)
+	}
+	return &EmailSendResult{
+		Provider:   "smtp",
+		Server:     net.JoinHostPort(s.cfg.SMTPHost, s.cfg.SMTPPort),
+		From:       s.cfg.SMTPFrom,
+		To:         to,
+		AcceptedAt: time.Now(),
+	}, nil
+}
+
+// CheckConnection connects to the SMTP server and authenticates without sending anything.
+// It returns errIntegrationNotConfigured when SMTP is not set up.
+func (s *EmailService) CheckConnection(ctx context.Context) error {
+	if s.cfg.SMTPHost == "" || s.cfg.SMTPUser == "" {
+		return errIntegrationNotConfigured
+	}
+
+	dialer := net.Dialer{Timeout: 10 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(s.cfg.SMTPHost, s.cfg.SMTPPort))
+	if err != nil {
+		return fmt.Errorf("smtp server unreachable: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.cfg.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp handshake failed: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.cfg.SMTPHost}); err != nil {
+			return fmt.Errorf("smtp starttls failed: %w", err)
+		}
+	}
+	if ok, _ := client.Extension("AUTH"); ok {
+		if err := client.Auth(smtp.PlainAuth("", s.cfg.SMTPUser, s.cfg.SMTPPassword, s.cfg.SMTPHost)); err != nil {
+			return fmt.Errorf("smtp authentication failed: %w", err)
+		}
+	}
+	return client.Quit()
+}
+
 func (s *EmailService) renderTemplate(templateName string, data interface{}) (string, error) {
 	tmpl, err := template.New(templateName).Parse(getTemplate(templateName))
 	if err != nil {
@@ -0,0 +1,402 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const sendGridSendURL = "https://api.sendgrid.com/v3/mail/send"
+
+// ErrRecipientOptedOutEmail is returned for emails to addresses that opted out of them
+var ErrRecipientOptedOutEmail = errors.New("recipient has opted out of email messages")
+
+// EmailDeliveryService sends organization emails through their own SMTP server or SendGrid
+// account, falling back to the platform SMTP server, and logs every send in email_messages
+type EmailDeliveryService struct {
+	db            *database.DB
+	encryptionKey []byte
+	platform      *EmailService
+}
+
+func NewEmailDeliveryService(db *database.DB, encryptionKey string, platform *EmailService) *EmailDeliveryService {
+	return &EmailDeliveryService{
+		db:            db,
+		encryptionKey: secretKey(encryptionKey),
+		platform:      platform,
+	}
+}
+
+// emailTransport is a resolved provider with decrypted credentials
+type emailTransport struct {
+	provider  models.EmailProvider
+	fromEmail string
+	fromName  string
+	host      string
+	port      int
+	username  string
+	password  string
+	apiKey    string
+}
+
+// SendEmail sends an email for an organization. It satisfies the workflow executor's EmailSender.
+func (s *EmailDeliveryService) SendEmail(ctx context.Context, orgID uuid.UUID, to, subject, body string) error {
+	_, err := s.Send(ctx, orgID, to, subject, body, nil)
+	return err
+}
+
+// Send sends an email through the organization's provider and logs its delivery status.
+// It returns nil without sending when neither the organization nor the platform has email set up,
+// ErrRecipientOptedOutEmail for addresses that opted out of the organization's emails, and
+// ErrRecipientSuppressedEmail for addresses that hard bounced or complained.
+func (s *EmailDeliveryService) Send(ctx context.Context, orgID uuid.UUID, to, subject, body string, sessionID *uuid.UUID) (*models.EmailMessage, error) {
+	optedOut, err := contactOptedOut(ctx, s.db.Pool, orgID, models.ConsentChannelEmail, to)
+	if err != nil {
+		return nil, err
+	}
+	if optedOut {
+		return nil, ErrRecipientOptedOutEmail
+	}
+	suppression, err := emailSuppression(ctx, s.db.Pool, orgID, to)
+	if err != nil {
+		return nil, err
+	}
+	if suppression != nil {
+		return nil, ErrRecipientSuppressedEmail
+	}
+
+	transport, err := s.transport(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if transport == nil {
+		log.Printf("[EmailDelivery] Email not configured for organization %s, skipping send", orgID)
+		return nil, nil
+	}
+
+	msgLog := &models.EmailMessage{
+		OrganizationID: orgID,
+		SessionID:      sessionID,
+		ToAddress:      to,
+		Subject:        &subject,
+		MessageContent: &body,
+		Provider:       transport.provider,
+		Status:         models.EmailStatusQueued,
+	}
+	err = s.db.Pool.QueryRow(ctx, `
+		INSERT INTO email_messages (organization_id, session_id, to_address, subject, message_content, provider, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`, orgID, sessionID, to, subject, body, transport.provider, msgLog.Status).Scan(&msgLog.ID, &msgLog.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to log email message: %w", err)
+	}
+
+	providerMessageID, sendErr := s.deliver(ctx, transport, to, subject, body)
+	if sendErr != nil {
+		errMsg := sendErr.Error()
+		msgLog.Status = models.EmailStatusFailed
+		msgLog.ErrorMessage = &errMsg
+		if _, err := s.db.Pool.Exec(ctx, `
+			UPDATE email_messages SET status = $1, error_message = $2 WHERE id = $3
+		`, msgLog.Status, errMsg, msgLog.ID); err != nil {
+			log.Printf("[EmailDelivery] Failed to update email message %s: %v", msgLog.ID, err)
+		}
+		return msgLog, fmt.Errorf("failed to send email: %w", sendErr)
+	}
+
+	now := time.Now()
+	msgLog.Status = models.EmailStatusSent
+	msgLog.SentAt = &now
+	if providerMessageID != "" {
+		msgLog.ProviderMessageID = &providerMessageID
+	}
+	if _, err := s.db.Pool.Exec(ctx, `
+		UPDATE email_messages SET status = $1, provider_message_id = $2, sent_at = $3 WHERE id = $4
+	`, msgLog.Status, msgLog.ProviderMessageID, now, msgLog.ID); err != nil {
+		log.Printf("[EmailDelivery] Failed to update email message %s: %v", msgLog.ID, err)
+	}
+
+	return msgLog, nil
+}
+
+// SendTest sends an email and reports the provider that accepted it. Unlike regular sends,
+// it fails when no email provider is configured.
+func (s *EmailDeliveryService) SendTest(ctx context.Context, orgID uuid.UUID, to, subject, body string) (*EmailSendResult, error) {
+	transport, err := s.transport(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if transport == nil {
+		return nil, errors.New("email is not configured")
+	}
+
+	msg, err := s.Send(ctx, orgID, to, subject, body, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &EmailSendResult{
+		Provider:   string(transport.provider),
+		Server:     "api.sendgrid.com",
+		From:       transport.fromEmail,
+		To:         to,
+		AcceptedAt: time.Now(),
+	}
+	if transport.provider != models.EmailProviderSendGrid {
+		result.Server = net.JoinHostPort(transport.host, strconv.Itoa(transport.port))
+	}
+	if msg != nil && msg.ProviderMessageID != nil {
+		result.MessageID = *msg.ProviderMessageID
+	}
+	return result, nil
+}
+
+// transport resolves how an organization's emails are sent. It returns nil when email is not set up.
+func (s *EmailDeliveryService) transport(ctx context.Context, orgID uuid.UUID) (*emailTransport, error) {
+	var config models.NotificationConfig
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT email_enabled, email_provider, email_from_address, email_from_name,
+			smtp_host, smtp_port, smtp_username, smtp_password_encrypted, sendgrid_api_key_encrypted
+		FROM notification_configs
+		WHERE organization_id = $1
+	`, orgID).Scan(
+		&config.EmailEnabled,
+		&config.EmailProvider,
+		&config.EmailFromAddress,
+		&config.EmailFromName,
+		&config.SMTPHost,
+		&config.SMTPPort,
+		&config.SMTPUsername,
+		&config.SMTPPasswordEncrypted,
+		&config.SendGridAPIKeyEncrypted,
+	)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get email config: %w", err)
+	}
+
+	if err == nil && config.EmailEnabled && config.EmailConfigured() {
+		return s.orgTransport(&config)
+	}
+	return s.platformTransport(), nil
+}
+
+// orgTransport decrypts the organization's provider credentials
+func (s *EmailDeliveryService) orgTransport(config *models.NotificationConfig) (*emailTransport, error) {
+	t := &emailTransport{
+		provider:  *config.EmailProvider,
+		fromEmail: *config.EmailFromAddress,
+	}
+	if config.EmailFromName != nil {
+		t.fromName = *config.EmailFromName
+	}
+
+	switch t.provider {
+	case models.EmailProviderSMTP:
+		t.host = *config.SMTPHost
+		t.port = *config.SMTPPort
+		if config.SMTPUsername != nil {
+			t.username = *config.SMTPUsername
+		}
+		if config.SMTPPasswordEncrypted != nil {
+			password, err := decryptSecret(s.encryptionKey, *config.SMTPPasswordEncrypted)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt SMTP password: %w", err)
+			}
+			t.password = password
+		}
+	case models.EmailProviderSendGrid:
+		apiKey, err := decryptSecret(s.encryptionKey, *config.SendGridAPIKeyEncrypted)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt SendGrid API key: %w", err)
+		}
+		t.apiKey = apiKey
+	}
+	return t, nil
+}
+
+// platformTransport returns the platform SMTP server, or nil when it is not configured
+func (s *EmailDeliveryService) platformTransport() *emailTransport {
+	if s.platform == nil || s.platform.cfg.SMTPHost == "" || s.platform.cfg.SMTPUser == "" {
+		return nil
+	}
+	cfg := s.platform.cfg
+	port, err := strconv.Atoi(cfg.SMTPPort)
+	if err != nil {
+		log.Printf("[EmailDelivery] Invalid platform SMTP port %q", cfg.SMTPPort)
+		return nil
+	}
+
+	t := &emailTransport{
+		provider:  models.EmailProviderPlatform,
+		fromEmail: cfg.SMTPFrom,
+		host:      cfg.SMTPHost,
+		port:      port,
+		username:  cfg.SMTPUser,
+		password:  cfg.SMTPPassword,
+	}
+	if addr, err := mail.ParseAddress(cfg.SMTPFrom); err == nil {
+		t.fromEmail = addr.Address
+		t.fromName = addr.Name
+	}
+	return t
+}
+
+// deliver hands the email to the provider and returns its message id
+func (s *EmailDeliveryService) deliver(ctx context.Context, t *emailTransport, to, subject, body string) (string, error) {
+	if t.provider == models.EmailProviderSendGrid {
+		return sendSendGrid(ctx, t, to, subject, body)
+	}
+	return sendSMTP(ctx, t, to, subject, body)
+}
+
+// sendSMTP sends an HTML email over SMTP. Port 465 uses implicit TLS, other ports upgrade
+// with STARTTLS when the server offers it.
+func sendSMTP(ctx context.Context, t *emailTransport, to, subject, body string) (string, error) {
+	messageID := fmt.Sprintf("<%s@%s>", uuid.New(), emailDomain(t.fromEmail))
+	from := mail.Address{Name: t.fromName, Address: t.fromEmail}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from.String())
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "Message-ID: %s\r\n", messageID)
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/html; charset=UTF-8\r\n\r\n")
+	msg.WriteString(body)
+	msg.WriteString("\r\n")
+
+	addr := net.JoinHostPort(t.host, strconv.Itoa(t.port))
+	dialer := net.Dialer{Timeout: 30 * time.Second}
+	var conn net.Conn
+	var err error
+	if t.port == 465 {
+		conn, err = tls.DialWithDialer(&dialer, "tcp", addr, &tls.Config{ServerName: t.host})
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return "", fmt.Errorf("smtp server unreachable: %w", err)
+	}
+	conn.SetDeadline(time.Now().Add(time.Minute))
+
+	client, err := smtp.NewClient(conn, t.host)
+	if err != nil {
+		conn.Close()
+		return "", fmt.Errorf("smtp handshake failed: %w", err)
+	}
+	defer client.Close()
+
+	if t.port != 465 {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: t.host}); err != nil {
+				return "", fmt.Errorf("smtp starttls failed: %w", err)
+			}
+		}
+	}
+	if t.username != "" {
+		if ok, _ := client.Extension("AUTH"); ok {
+			if err := client.Auth(smtp.PlainAuth("", t.username, t.password, t.host)); err != nil {
+				return "", fmt.Errorf("smtp authentication failed: %w", err)
+			}
+		}
+	}
+
+	if err := client.Mail(t.fromEmail); err != nil {
+		return "", fmt.Errorf("smtp sender rejected: %w", err)
+	}
+	if err := client.Rcpt(to); err != nil {
+		return "", fmt.Errorf("smtp recipient rejected: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return "", fmt.Errorf("smtp data failed: %w", err)
+	}
+	if _, err := w.Write(msg.Bytes()); err != nil {
+		return "", fmt.Errorf("smtp data failed: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("smtp data failed: %w", err)
+	}
+	client.Quit()
+
+	return strings.Trim(messageID, "<>"), nil
+}
+
+// sendSendGrid sends an HTML email with the SendGrid v3 mail send API
+func sendSendGrid(ctx context.Context, t *emailTransport, to, subject, body string) (string, error) {
+	type address struct {
+		Email string `json:"email"`
+		Name  string `json:"name,omitempty"`
+	}
+	payload := map[string]interface{}{
+		"personalizations": []map[string]interface{}{
+			{"to": []address{{Email: to}}},
+		},
+		"from":    address{Email: t.fromEmail, Name: t.fromName},
+		"subject": subject,
+		"content": []map[string]string{
+			{"type": "text/html", "value": body},
+		},
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", sendGridSendURL, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+t.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		var errorResp struct {
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		respBody, _ := io.ReadAll(resp.Body)
+		if json.Unmarshal(respBody, &errorResp) == nil && len(errorResp.Errors) > 0 {
+			return "", fmt.Errorf("sendgrid error: %s", errorResp.Errors[0].Message)
+		}
+		return "", fmt.Errorf("sendgrid error: status %d", resp.StatusCode)
+	}
+
+	return resp.Header.Get("X-Message-Id"), nil
+}
+
+// emailDomain returns the domain part of an email address, used for Message-ID headers
+func emailDomain(address string) string {
+	if i := strings.LastIndex(address, "@"); i >= 0 && i < len(address)-1 {
+		return address[i+1:]
+	}
+	return "controlwise.local"
+}
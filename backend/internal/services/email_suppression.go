@@ -0,0 +1,612 @@
+package services
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrRecipientSuppressedEmail is returned for emails to addresses that hard bounced or complained
+var ErrRecipientSuppressedEmail = errors.New("recipient email address is suppressed")
+
+// EmailSuppressionService ingests the bounces and complaints SendGrid and Amazon SES report for
+// an organization's emails. Hard-bounced and complaining addresses are suppressed, which
+// EmailDeliveryService checks before sending, and a hard bounce fires the email_bounced triggers
+// of the recipient's session or budget workflow. SendGrid posts must carry the signature of the
+// organization's Signed Event Webhook key, and SNS messages must be signed by Amazon and come from
+// the organization's SES topic.
+type EmailSuppressionService struct {
+	db       *database.DB
+	workflow *WorkflowService
+	client   *http.Client
+
+	certsMu sync.Mutex
+	certs   map[string]*x509.Certificate // SNS signing certificates by URL
+}
+
+func NewEmailSuppressionService(db *database.DB, workflow *WorkflowService) *EmailSuppressionService {
+	return &EmailSuppressionService{
+		db:       db,
+		workflow: workflow,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		certs:    make(map[string]*x509.Certificate),
+	}
+}
+
+// sendGridEvent is one event of a SendGrid Event Webhook post
+type sendGridEvent struct {
+	Email       string `json:"email"`
+	Event       string `json:"event"`
+	Type        string `json:"type"` // "bounce" or "blocked" on bounce events
+	Reason      string `json:"reason"`
+	SGMessageID string `json:"sg_message_id"`
+}
+
+// parseSendGridEvents returns the bounces and spam reports of a SendGrid Event Webhook post,
+// skipping other events
+func parseSendGridEvents(payload []byte) ([]models.EmailEvent, error) {
+	var raw []sendGridEvent
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, fmt.Errorf("invalid sendgrid events: %w", err)
+	}
+
+	var events []models.EmailEvent
+	for _, e := range raw {
+		if e.Email == "" {
+			continue
+		}
+		// sg_message_id is the X-Message-Id returned on send followed by a suffix
+		messageID, _, _ := strings.Cut(e.SGMessageID, ".")
+		event := models.EmailEvent{Address: e.Email, ProviderMessageID: messageID, Detail: e.Reason}
+		switch e.Event {
+		case "bounce":
+			// Blocked messages are soft bounces that may go through later
+			event.Permanent = e.Type != "blocked"
+		case "spamreport":
+			event.Complaint = true
+		default:
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// verifySendGridSignature checks the X-Twilio-Email-Event-Webhook-Signature of a SendGrid post: an
+// ECDSA signature of the timestamp header followed by the payload, with the base64 DER public key
+// SendGrid shows for the organization's Signed Event Webhook
+func verifySendGridSignature(verificationKey string, payload []byte, signature, timestamp string) error {
+	key, err := parseSendGridVerificationKey(verificationKey)
+	if err != nil {
+		return err
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || signature == "" || timestamp == "" {
+		return ErrInvalidWebhookSignature
+	}
+	digest := sha256.Sum256(append([]byte(timestamp), payload...))
+	if !ecdsa.VerifyASN1(key, digest[:], sig) {
+		return ErrInvalidWebhookSignature
+	}
+	return nil
+}
+
+// parseSendGridVerificationKey parses the base64 DER public key of a Signed Event Webhook
+func parseSendGridVerificationKey(verificationKey string) (*ecdsa.PublicKey, error) {
+	der, err := base64.StdEncoding.DecodeString(verificationKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sendgrid verification key: %w", err)
+	}
+	parsed, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sendgrid verification key: %w", err)
+	}
+	key, ok := parsed.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.New("invalid sendgrid verification key: not an ECDSA key")
+	}
+	return key, nil
+}
+
+// snsEnvelope is an Amazon SNS HTTP notification
+type snsEnvelope struct {
+	Type             string `json:"Type"`
+	MessageID        string `json:"MessageId"`
+	Token            string `json:"Token"`
+	TopicArn         string `json:"TopicArn"`
+	Subject          string `json:"Subject"`
+	Message          string `json:"Message"`
+	SubscribeURL     string `json:"SubscribeURL"`
+	Timestamp        string `json:"Timestamp"`
+	SignatureVersion string `json:"SignatureVersion"`
+	Signature        string `json:"Signature"`
+	SigningCertURL   string `json:"SigningCertURL"`
+}
+
+// stringToSign builds the text SNS signs for a message: the message's fields in alphabetical
+// order, each as its name and value on their own lines
+func (e *snsEnvelope) stringToSign() string {
+	fields := [][2]string{{"Message", e.Message}, {"MessageId", e.MessageID}}
+	if e.Type == "Notification" {
+		if e.Subject != "" {
+			fields = append(fields, [2]string{"Subject", e.Subject})
+		}
+		fields = append(fields, [2]string{"Timestamp", e.Timestamp})
+	} else {
+		fields = append(fields, [2]string{"SubscribeURL", e.SubscribeURL},
+			[2]string{"Timestamp", e.Timestamp}, [2]string{"Token", e.Token})
+	}
+	fields = append(fields, [2]string{"TopicArn", e.TopicArn}, [2]string{"Type", e.Type})
+
+	var b strings.Builder
+	for _, f := range fields {
+		b.WriteString(f[0] + "\n" + f[1] + "\n")
+	}
+	return b.String()
+}
+
+// verifySNSSignature checks an SNS message's signature with its signing certificate
+func verifySNSSignature(e *snsEnvelope, cert *x509.Certificate) error {
+	key, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("%w: unsupported sns signing key", ErrInvalidWebhookSignature)
+	}
+	sig, err := base64.StdEncoding.DecodeString(e.Signature)
+	if err != nil {
+		return ErrInvalidWebhookSignature
+	}
+
+	data := []byte(e.stringToSign())
+	switch e.SignatureVersion {
+	case "1":
+		digest := sha1.Sum(data)
+		err = rsa.VerifyPKCS1v15(key, crypto.SHA1, digest[:], sig)
+	case "2":
+		digest := sha256.Sum256(data)
+		err = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig)
+	default:
+		return fmt.Errorf("%w: unsupported sns signature version %q", ErrInvalidWebhookSignature, e.SignatureVersion)
+	}
+	if err != nil {
+		return ErrInvalidWebhookSignature
+	}
+	return nil
+}
+
+// sesNotification is an Amazon SES bounce or complaint notification
+type sesNotification struct {
+	NotificationType string `json:"notificationType"`
+	EventType        string `json:"eventType"` // set instead of notificationType by event publishing
+	Bounce           struct {
+		BounceType        string `json:"bounceType"`
+		BouncedRecipients []struct {
+			EmailAddress   string `json:"emailAddress"`
+			DiagnosticCode string `json:"diagnosticCode"`
+		} `json:"bouncedRecipients"`
+	} `json:"bounce"`
+	Complaint struct {
+		ComplaintFeedbackType string `json:"complaintFeedbackType"`
+		ComplainedRecipients  []struct {
+			EmailAddress string `json:"emailAddress"`
+		} `json:"complainedRecipients"`
+	} `json:"complaint"`
+	Mail struct {
+		MessageID     string `json:"messageId"`
+		CommonHeaders struct {
+			MessageID string `json:"messageId"`
+		} `json:"commonHeaders"`
+	} `json:"mail"`
+}
+
+// parseSESNotification returns the bounces and complaints of an Amazon SES notification
+func parseSESNotification(message []byte) ([]models.EmailEvent, error) {
+	var n sesNotification
+	if err := json.Unmarshal(message, &n); err != nil {
+		return nil, fmt.Errorf("invalid ses notification: %w", err)
+	}
+
+	// Emails sent over SMTP are logged with their Message-ID header
+	messageID := n.Mail.CommonHeaders.MessageID
+	if messageID == "" {
+		messageID = n.Mail.MessageID
+	}
+
+	kind := n.NotificationType
+	if kind == "" {
+		kind = n.EventType
+	}
+	var events []models.EmailEvent
+	switch kind {
+	case "Bounce":
+		for _, r := range n.Bounce.BouncedRecipients {
+			events = append(events, models.EmailEvent{
+				Address:           r.EmailAddress,
+				ProviderMessageID: messageID,
+				Permanent:         n.Bounce.BounceType == "Permanent",
+				Detail:            r.DiagnosticCode,
+			})
+		}
+	case "Complaint":
+		for _, r := range n.Complaint.ComplainedRecipients {
+			events = append(events, models.EmailEvent{
+				Address:           r.EmailAddress,
+				ProviderMessageID: messageID,
+				Complaint:         true,
+				Detail:            n.Complaint.ComplaintFeedbackType,
+			})
+		}
+	}
+	return events, nil
+}
+
+// validSNSSubscribeURL reports whether a subscription confirmation URL points at Amazon SNS, so
+// confirming it can't be used to make the server call elsewhere
+func validSNSSubscribeURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" {
+		return false
+	}
+	host := u.Hostname()
+	return strings.HasPrefix(host, "sns.") && strings.HasSuffix(host, ".amazonaws.com")
+}
+
+// validSNSSigningCertURL reports whether a signing certificate URL is a certificate served by
+// Amazon SNS, so a forged message can't bring its own certificate
+func validSNSSigningCertURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && validSNSSubscribeURL(raw) && strings.HasSuffix(u.Path, ".pem")
+}
+
+// HandleSendGridEvents ingests a SendGrid Event Webhook post for an organization. Posts not signed
+// with the organization's verification key are refused with ErrInvalidWebhookSignature.
+func (s *EmailSuppressionService) HandleSendGridEvents(ctx context.Context, orgID uuid.UUID, payload []byte, signature, timestamp string) error {
+	var verificationKey *string
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT sendgrid_webhook_verification_key FROM notification_configs WHERE organization_id = $1
+	`, orgID).Scan(&verificationKey)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("failed to get sendgrid verification key: %w", err)
+	}
+	if verificationKey == nil || *verificationKey == "" {
+		return fmt.Errorf("%w: sendgrid webhook verification key not configured", ErrInvalidWebhookSignature)
+	}
+	if err := verifySendGridSignature(*verificationKey, payload, signature, timestamp); err != nil {
+		return err
+	}
+
+	events, err := parseSendGridEvents(payload)
+	if err != nil {
+		return err
+	}
+	return s.Ingest(ctx, orgID, models.EmailEventProviderSendGrid, events)
+}
+
+// HandleSESNotification ingests an Amazon SNS notification of SES bounces and complaints for an
+// organization, confirming the topic subscription when SNS asks. Messages that aren't signed by
+// SNS or come from another topic than the organization's are refused with ErrInvalidWebhookSignature.
+func (s *EmailSuppressionService) HandleSESNotification(ctx context.Context, orgID uuid.UUID, payload []byte) error {
+	var envelope snsEnvelope
+	if err := json.Unmarshal(payload, &envelope); err != nil {
+		return fmt.Errorf("invalid sns notification: %w", err)
+	}
+
+	var topicArn *string
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT ses_topic_arn FROM notification_configs WHERE organization_id = $1
+	`, orgID).Scan(&topicArn)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("failed to get ses topic: %w", err)
+	}
+	if topicArn == nil || *topicArn != envelope.TopicArn {
+		return fmt.Errorf("%w: sns topic %q is not the organization's ses topic", ErrInvalidWebhookSignature, envelope.TopicArn)
+	}
+
+	cert, err := s.snsSigningCert(ctx, envelope.SigningCertURL)
+	if err != nil {
+		return err
+	}
+	if err := verifySNSSignature(&envelope, cert); err != nil {
+		return err
+	}
+
+	switch envelope.Type {
+	case "SubscriptionConfirmation":
+		return s.confirmSNSSubscription(ctx, orgID, envelope.SubscribeURL)
+	case "Notification":
+		events, err := parseSESNotification([]byte(envelope.Message))
+		if err != nil {
+			return err
+		}
+		return s.Ingest(ctx, orgID, models.EmailEventProviderSES, events)
+	}
+	return nil
+}
+
+// snsSigningCert returns the certificate SNS signed a message with, fetching it on first use
+func (s *EmailSuppressionService) snsSigningCert(ctx context.Context, certURL string) (*x509.Certificate, error) {
+	if !validSNSSigningCertURL(certURL) {
+		return nil, fmt.Errorf("%w: invalid sns signing certificate url", ErrInvalidWebhookSignature)
+	}
+
+	s.certsMu.Lock()
+	cert, ok := s.certs[certURL]
+	s.certsMu.Unlock()
+	if ok {
+		return cert, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, certURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sns signing certificate: %w", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sns signing certificate: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get sns signing certificate: status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sns signing certificate: %w", err)
+	}
+	block, _ := pem.Decode(body)
+	if block == nil {
+		return nil, errors.New("invalid sns signing certificate")
+	}
+	cert, err = x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sns signing certificate: %w", err)
+	}
+
+	s.certsMu.Lock()
+	s.certs[certURL] = cert
+	s.certsMu.Unlock()
+	return cert, nil
+}
+
+func (s *EmailSuppressionService) confirmSNSSubscription(ctx context.Context, orgID uuid.UUID, subscribeURL string) error {
+	if !validSNSSubscribeURL(subscribeURL) {
+		return errors.New("invalid sns subscribe url")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, subscribeURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to confirm sns subscription: %w", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to confirm sns subscription: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to confirm sns subscription: status %d", resp.StatusCode)
+	}
+	log.Printf("[EmailSuppression] Confirmed SES notifications subscription for organization %s", orgID)
+	return nil
+}
+
+// Ingest records bounces and complaints: the email is marked bounced or complained, and hard
+// bounces and complaints suppress the address. Addresses suppressed for the first time by a hard
+// bounce fire the email_bounced triggers.
+func (s *EmailSuppressionService) Ingest(ctx context.Context, orgID uuid.UUID, provider models.EmailEventProvider, events []models.EmailEvent) error {
+	for _, event := range events {
+		address := models.NormalizeConsentAddress(models.ConsentChannelEmail, event.Address)
+		if address == "" {
+			continue
+		}
+
+		var messageID, sessionID *uuid.UUID
+		if event.ProviderMessageID != "" {
+			var id uuid.UUID
+			err := s.db.Pool.QueryRow(ctx, `
+				SELECT id, session_id FROM email_messages
+				WHERE organization_id = $1 AND provider_message_id = $2
+				ORDER BY created_at DESC
+				LIMIT 1
+			`, orgID, event.ProviderMessageID).Scan(&id, &sessionID)
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("failed to find bounced email: %w", err)
+			}
+			if err == nil {
+				messageID = &id
+			}
+		}
+
+		var detail *string
+		if event.Detail != "" {
+			detail = &event.Detail
+		}
+
+		if !event.Complaint && !event.Permanent {
+			log.Printf("[EmailSuppression] Transient bounce for %s in organization %s: %s", address, orgID, event.Detail)
+			continue
+		}
+
+		status, reason := models.EmailStatusBounced, models.EmailSuppressionHardBounce
+		if event.Complaint {
+			status, reason = models.EmailStatusComplained, models.EmailSuppressionComplaint
+		}
+		if messageID != nil {
+			if _, err := s.db.Pool.Exec(ctx, `
+				UPDATE email_messages SET status = $1, error_message = COALESCE($2, error_message) WHERE id = $3
+			`, status, detail, *messageID); err != nil {
+				return fmt.Errorf("failed to update bounced email: %w", err)
+			}
+		}
+
+		result, err := s.db.Pool.Exec(ctx, `
+			INSERT INTO email_suppressions (organization_id, address, reason, provider, detail, email_message_id)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (organization_id, address) DO NOTHING
+		`, orgID, address, reason, provider, detail, messageID)
+		if err != nil {
+			return fmt.Errorf("failed to suppress email address: %w", err)
+		}
+		if result.RowsAffected() == 0 || reason != models.EmailSuppressionHardBounce {
+			continue
+		}
+
+		log.Printf("[EmailSuppression] Suppressed %s in organization %s after a hard bounce", address, orgID)
+		if err := s.fireBounced(ctx, orgID, address, sessionID); err != nil {
+			log.Printf("[EmailSuppression] Failed to fire email_bounced triggers for %s in organization %s: %v", address, orgID, err)
+		}
+	}
+	return nil
+}
+
+// fireBounced schedules the email_bounced triggers against the bounced email's session, or the
+// recipient's next session (their latest when none is upcoming), or their latest budget
+func (s *EmailSuppressionService) fireBounced(ctx context.Context, orgID uuid.UUID, address string, sessionID *uuid.UUID) error {
+	var id uuid.UUID
+	var status string
+	var err error
+	if sessionID != nil {
+		err = s.db.Pool.QueryRow(ctx, `
+			SELECT id, status FROM sessions WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+		`, *sessionID, orgID).Scan(&id, &status)
+	} else {
+		err = s.db.Pool.QueryRow(ctx, `
+			SELECT s.id, s.status
+			FROM sessions s
+			JOIN patients p ON p.id = s.patient_id
+			JOIN clients c ON c.id = p.client_id
+			WHERE s.organization_id = $1 AND LOWER(c.email) = $2 AND s.deleted_at IS NULL
+			ORDER BY s.scheduled_at < NOW(),
+				CASE WHEN s.scheduled_at >= NOW() THEN s.scheduled_at END,
+				s.scheduled_at DESC
+			LIMIT 1
+		`, orgID, address).Scan(&id, &status)
+	}
+	if err == nil {
+		return s.workflow.OnEmailBounced(ctx, orgID, models.WorkflowEntitySession, id, status)
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("failed to find session of bounced email: %w", err)
+	}
+
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT b.id, b.status
+		FROM budgets b
+		JOIN clients c ON c.id = b.client_id
+		WHERE b.organization_id = $1 AND LOWER(c.email) = $2 AND b.deleted_at IS NULL
+		ORDER BY b.created_at DESC
+		LIMIT 1
+	`, orgID, address).Scan(&id, &status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find budget of bounced email: %w", err)
+	}
+	return s.workflow.OnEmailBounced(ctx, orgID, models.WorkflowEntityBudget, id, status)
+}
+
+const emailSuppressionColumns = `
+	id, organization_id, address, reason, provider, detail, email_message_id, created_at`
+
+func scanEmailSuppression(row pgx.Row) (*models.EmailSuppression, error) {
+	var e models.EmailSuppression
+	err := row.Scan(&e.ID, &e.OrganizationID, &e.Address, &e.Reason, &e.Provider, &e.Detail, &e.EmailMessageID, &e.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// List returns the organization's suppressed addresses, latest first, optionally matching search
+func (s *EmailSuppressionService) List(ctx context.Context, orgID uuid.UUID, search string, limit, offset int) ([]*models.EmailSuppression, int, error) {
+	where := "WHERE organization_id = $1"
+	args := []interface{}{orgID}
+	if search != "" {
+		args = append(args, "%"+search+"%")
+		where += fmt.Sprintf(" AND address ILIKE $%d", len(args))
+	}
+
+	var total int
+	err := s.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM email_suppressions "+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count email suppressions: %w", err)
+	}
+
+	args = append(args, limit, offset)
+	rows, err := s.db.Pool.Query(ctx, fmt.Sprintf(`
+		SELECT %s
+		FROM email_suppressions
+		%s
+		ORDER BY created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, emailSuppressionColumns, where, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query email suppressions: %w", err)
+	}
+	defer rows.Close()
+
+	suppressions := []*models.EmailSuppression{}
+	for rows.Next() {
+		e, err := scanEmailSuppression(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan email suppression: %w", err)
+		}
+		suppressions = append(suppressions, e)
+	}
+	return suppressions, total, nil
+}
+
+// Delete removes a suppression once the address is corrected or confirmed; it may be emailed again
+func (s *EmailSuppressionService) Delete(ctx context.Context, id, orgID uuid.UUID) error {
+	result, err := s.db.Pool.Exec(ctx, `
+		DELETE FROM email_suppressions WHERE id = $1 AND organization_id = $2
+	`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to delete email suppression: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("email suppression not found")
+	}
+	return nil
+}
+
+// emailSuppression returns the suppression of an address in the organization, or nil when it
+// isn't suppressed
+func emailSuppression(ctx context.Context, q rowQuerier, orgID uuid.UUID, address string) (*models.EmailSuppression, error) {
+	address = models.NormalizeConsentAddress(models.ConsentChannelEmail, address)
+	if address == "" {
+		return nil, nil
+	}
+	e, err := scanEmailSuppression(q.QueryRow(ctx, `
+		SELECT `+emailSuppressionColumns+`
+		FROM email_suppressions
+		WHERE organization_id = $1 AND address = $2
+	`, orgID, address))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check email suppression: %w", err)
+	}
+	return e, nil
+}
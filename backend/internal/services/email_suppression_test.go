@@ -0,0 +1,178 @@
+package services
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/controlwise/backend/internal/models"
+)
+
+func TestParseSendGridEvents(t *testing.T) {
+	payload := []byte(`[
+		{"email": "a@example.com", "event": "bounce", "type": "bounce", "reason": "550 no such user", "sg_message_id": "abc123.filter0001.1.0"},
+		{"email": "b@example.com", "event": "bounce", "type": "blocked", "sg_message_id": "def456.filter0001.1.0"},
+		{"email": "c@example.com", "event": "spamreport", "sg_message_id": "ghi789.filter0001.1.0"},
+		{"email": "d@example.com", "event": "delivered", "sg_message_id": "jkl012.filter0001.1.0"}
+	]`)
+	events, err := parseSendGridEvents(payload)
+	if err != nil {
+		t.Fatalf("parseSendGridEvents: %v", err)
+	}
+	want := []models.EmailEvent{
+		{Address: "a@example.com", ProviderMessageID: "abc123", Permanent: true, Detail: "550 no such user"},
+		{Address: "b@example.com", ProviderMessageID: "def456"},
+		{Address: "c@example.com", ProviderMessageID: "ghi789", Complaint: true},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d", len(events), len(want))
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("event %d = %+v, want %+v", i, events[i], want[i])
+		}
+	}
+}
+
+func TestParseSESNotification(t *testing.T) {
+	bounce := []byte(`{
+		"notificationType": "Bounce",
+		"bounce": {"bounceType": "Permanent", "bouncedRecipients": [{"emailAddress": "a@example.com", "diagnosticCode": "smtp; 550 5.1.1"}]},
+		"mail": {"messageId": "ses-id", "commonHeaders": {"messageId": "<x@example.org>"}}
+	}`)
+	events, err := parseSESNotification(bounce)
+	if err != nil {
+		t.Fatalf("parseSESNotification: %v", err)
+	}
+	want := models.EmailEvent{Address: "a@example.com", ProviderMessageID: "<x@example.org>", Permanent: true, Detail: "smtp; 550 5.1.1"}
+	if len(events) != 1 || events[0] != want {
+		t.Errorf("bounce events = %+v, want [%+v]", events, want)
+	}
+
+	complaint := []byte(`{
+		"eventType": "Complaint",
+		"complaint": {"complaintFeedbackType": "abuse", "complainedRecipients": [{"emailAddress": "b@example.com"}]},
+		"mail": {"messageId": "ses-id"}
+	}`)
+	events, err = parseSESNotification(complaint)
+	if err != nil {
+		t.Fatalf("parseSESNotification: %v", err)
+	}
+	want = models.EmailEvent{Address: "b@example.com", ProviderMessageID: "ses-id", Complaint: true, Detail: "abuse"}
+	if len(events) != 1 || events[0] != want {
+		t.Errorf("complaint events = %+v, want [%+v]", events, want)
+	}
+}
+
+func TestValidSNSSubscribeURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://sns.eu-west-1.amazonaws.com/?Action=ConfirmSubscription&Token=x", true},
+		{"http://sns.eu-west-1.amazonaws.com/?Action=ConfirmSubscription", false},
+		{"https://sns.eu-west-1.amazonaws.com.example.com/", false},
+		{"https://example.com/sns.amazonaws.com", false},
+	}
+	for _, tt := range tests {
+		if got := validSNSSubscribeURL(tt.url); got != tt.want {
+			t.Errorf("validSNSSubscribeURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestVerifySendGridSignature(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verificationKey := base64.StdEncoding.EncodeToString(der)
+
+	payload := []byte(`[{"email": "a@example.com", "event": "bounce"}]`)
+	timestamp := "1700000000"
+	digest := sha256.Sum256(append([]byte(timestamp), payload...))
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := base64.StdEncoding.EncodeToString(sig)
+
+	if err := verifySendGridSignature(verificationKey, payload, signature, timestamp); err != nil {
+		t.Errorf("valid signature: %v", err)
+	}
+	forged := []byte(`[{"email": "b@example.com", "event": "spamreport"}]`)
+	if err := verifySendGridSignature(verificationKey, forged, signature, timestamp); !errors.Is(err, ErrInvalidWebhookSignature) {
+		t.Errorf("forged payload: got %v, want ErrInvalidWebhookSignature", err)
+	}
+	if err := verifySendGridSignature(verificationKey, payload, signature, "1700000001"); !errors.Is(err, ErrInvalidWebhookSignature) {
+		t.Errorf("changed timestamp: got %v, want ErrInvalidWebhookSignature", err)
+	}
+	if err := verifySendGridSignature(verificationKey, payload, "", timestamp); !errors.Is(err, ErrInvalidWebhookSignature) {
+		t.Errorf("missing signature: got %v, want ErrInvalidWebhookSignature", err)
+	}
+}
+
+func TestVerifySNSSignature(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := &x509.Certificate{PublicKey: &key.PublicKey}
+
+	envelope := &snsEnvelope{
+		Type:             "Notification",
+		MessageID:        "msg-1",
+		TopicArn:         "arn:aws:sns:eu-west-1:123456789012:ses-bounces",
+		Message:          `{"notificationType": "Bounce"}`,
+		Timestamp:        "2026-10-15T12:00:00.000Z",
+		SignatureVersion: "2",
+	}
+	want := "Message\n{\"notificationType\": \"Bounce\"}\nMessageId\nmsg-1\n" +
+		"Timestamp\n2026-10-15T12:00:00.000Z\nTopicArn\narn:aws:sns:eu-west-1:123456789012:ses-bounces\nType\nNotification\n"
+	if got := envelope.stringToSign(); got != want {
+		t.Fatalf("stringToSign = %q, want %q", got, want)
+	}
+
+	digest := sha256.Sum256([]byte(envelope.stringToSign()))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope.Signature = base64.StdEncoding.EncodeToString(sig)
+	if err := verifySNSSignature(envelope, cert); err != nil {
+		t.Errorf("valid signature: %v", err)
+	}
+
+	envelope.Message = `{"notificationType": "Complaint"}`
+	if err := verifySNSSignature(envelope, cert); !errors.Is(err, ErrInvalidWebhookSignature) {
+		t.Errorf("forged message: got %v, want ErrInvalidWebhookSignature", err)
+	}
+}
+
+func TestValidSNSSigningCertURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://sns.eu-west-1.amazonaws.com/SimpleNotificationService-abc.pem", true},
+		{"https://sns.eu-west-1.amazonaws.com/?Action=ConfirmSubscription", false},
+		{"http://sns.eu-west-1.amazonaws.com/SimpleNotificationService-abc.pem", false},
+		{"https://attacker.example.com/SimpleNotificationService-abc.pem", false},
+	}
+	for _, tt := range tests {
+		if got := validSNSSigningCertURL(tt.url); got != tt.want {
+			t.Errorf("validSNSSigningCertURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
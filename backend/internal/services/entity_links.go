@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// ============ Cross-Module Links ============
+
+// LinkProject links a session to a construction project of the organization, or unlinks it when
+// projectID is nil
+func (s *SessionService) LinkProject(ctx context.Context, id, orgID uuid.UUID, projectID *uuid.UUID) (*models.SessionWithDetails, error) {
+	if projectID != nil {
+		var exists bool
+		err := s.db.Pool.QueryRow(ctx, `
+			SELECT EXISTS(SELECT 1 FROM projects WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL)
+		`, *projectID, orgID).Scan(&exists)
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify project: %w", err)
+		}
+		if !exists {
+			return nil, errors.New("project not found")
+		}
+	}
+
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE sessions SET project_id = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND organization_id = $3 AND deleted_at IS NULL
+	`, projectID, id, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to link session: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, errors.New("session not found")
+	}
+
+	return s.GetByID(ctx, id, orgID)
+}
+
+// LinkClient moves a patient to another client record of the organization, e.g. the client the
+// construction module already knows, so both modules share one customer. The client must not
+// belong to another patient.
+func (s *PatientService) LinkClient(ctx context.Context, id, orgID, clientID uuid.UUID) (*models.PatientWithClient, error) {
+	var clientExists bool
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM clients WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL)
+	`, clientID, orgID).Scan(&clientExists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify client: %w", err)
+	}
+	if !clientExists {
+		return nil, errors.New("client not found")
+	}
+
+	existing, err := s.GetByClientID(ctx, orgID, clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing patient: %w", err)
+	}
+	if existing != nil && existing.ID != id {
+		return nil, errors.New("client is already linked to a patient")
+	}
+
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE patients SET client_id = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND organization_id = $3 AND deleted_at IS NULL
+	`, clientID, id, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to link patient: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, errors.New("patient not found")
+	}
+
+	return s.GetByID(ctx, id, orgID)
+}
+
+// ============ Timelines ============
+
+// maxTimelineEntries is the most entries a timeline returns
+const maxTimelineEntries = 200
+
+// clientTimelineQuery lists what happened for a client ($1) in both modules: its worksheets,
+// budgets, projects and their payments, and the sessions of the patient linked to it
+const clientTimelineQuery = `
+	SELECT w.created_at::timestamptz, 'worksheet_created', 'worksheet', w.id, w.title::text, w.status::text
+	FROM worksheets w
+	WHERE w.client_id = $1 AND w.organization_id = $2 AND w.deleted_at IS NULL
+	UNION ALL
+	SELECT e.at::timestamptz, e.event, 'budget', b.id, b.budget_number::text, b.status::text
+	FROM budgets b
+	JOIN worksheets w ON w.id = b.worksheet_id
+	CROSS JOIN LATERAL (VALUES
+		(b.sent_at, 'budget_sent'), (b.approved_at, 'budget_approved'), (b.rejected_at, 'budget_rejected')
+	) AS e(at, event)
+	WHERE w.client_id = $1 AND b.organization_id = $2 AND b.deleted_at IS NULL AND e.at IS NOT NULL
+	UNION ALL
+	SELECT e.at::timestamptz, e.event, 'project', pr.id, pr.title::text, pr.status::text
+	FROM projects pr
+	JOIN budgets b ON b.id = pr.budget_id
+	JOIN worksheets w ON w.id = b.worksheet_id
+	CROSS JOIN LATERAL (VALUES
+		(pr.start_date::timestamp, 'project_started'), (pr.actual_end_date::timestamp, 'project_ended')
+	) AS e(at, event)
+	WHERE w.client_id = $1 AND pr.organization_id = $2 AND pr.deleted_at IS NULL AND e.at IS NOT NULL
+	UNION ALL
+	SELECT pay.paid_at::timestamptz, 'payment_received', 'payment', pay.id, pr.title::text, pay.status::text
+	FROM payments pay
+	JOIN projects pr ON pr.id = pay.project_id
+	JOIN budgets b ON b.id = pr.budget_id
+	JOIN worksheets w ON w.id = b.worksheet_id
+	WHERE w.client_id = $1 AND pay.organization_id = $2 AND pay.deleted_at IS NULL AND pay.paid_at IS NOT NULL
+	UNION ALL
+	SELECT s.scheduled_at::timestamptz, 'session_' || s.status, 'session', s.id, COALESCE(t.name, '')::text, s.status::text
+	FROM sessions s
+	JOIN patients p ON p.id = s.patient_id
+	LEFT JOIN therapists t ON t.id = s.therapist_id
+	WHERE p.client_id = $1 AND s.organization_id = $2 AND s.deleted_at IS NULL
+	ORDER BY 1 DESC
+	LIMIT $3`
+
+// projectTimelineQuery lists what happened for a project ($1): its budget, its own milestones,
+// its payments and the sessions linked to it
+const projectTimelineQuery = `
+	SELECT e.at::timestamptz, e.event, 'budget', b.id, b.budget_number::text, b.status::text
+	FROM projects pr
+	JOIN budgets b ON b.id = pr.budget_id
+	CROSS JOIN LATERAL (VALUES
+		(b.sent_at, 'budget_sent'), (b.approved_at, 'budget_approved'), (b.rejected_at, 'budget_rejected')
+	) AS e(at, event)
+	WHERE pr.id = $1 AND pr.organization_id = $2 AND e.at IS NOT NULL
+	UNION ALL
+	SELECT e.at::timestamptz, e.event, 'project', pr.id, pr.title::text, pr.status::text
+	FROM projects pr
+	CROSS JOIN LATERAL (VALUES
+		(pr.start_date::timestamp, 'project_started'), (pr.actual_end_date::timestamp, 'project_ended')
+	) AS e(at, event)
+	WHERE pr.id = $1 AND pr.organization_id = $2 AND e.at IS NOT NULL
+	UNION ALL
+	SELECT pay.paid_at::timestamptz, 'payment_received', 'payment', pay.id, pr.title::text, pay.status::text
+	FROM payments pay
+	JOIN projects pr ON pr.id = pay.project_id
+	WHERE pr.id = $1 AND pay.organization_id = $2 AND pay.deleted_at IS NULL AND pay.paid_at IS NOT NULL
+	UNION ALL
+	SELECT s.scheduled_at::timestamptz, 'session_' || s.status, 'session', s.id, COALESCE(c.name, '')::text, s.status::text
+	FROM sessions s
+	LEFT JOIN patients p ON p.id = s.patient_id
+	LEFT JOIN clients c ON c.id = p.client_id
+	WHERE s.project_id = $1 AND s.organization_id = $2 AND s.deleted_at IS NULL
+	ORDER BY 1 DESC
+	LIMIT $3`
+
+// Timeline returns what happened for a client across modules, newest first
+func (s *ClientService) Timeline(ctx context.Context, id, orgID uuid.UUID) ([]*models.TimelineEntry, error) {
+	if _, err := s.GetByID(ctx, id, orgID); err != nil {
+		return nil, err
+	}
+	return queryTimeline(ctx, s.db, clientTimelineQuery, id, orgID)
+}
+
+// Timeline returns what happened for a project, including the sessions linked to it, newest first
+func (s *ProjectService) Timeline(ctx context.Context, id, orgID uuid.UUID) ([]*models.TimelineEntry, error) {
+	var exists bool
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM projects WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL)
+	`, id, orgID).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	if !exists {
+		return nil, errors.New("project not found")
+	}
+	return queryTimeline(ctx, s.db, projectTimelineQuery, id, orgID)
+}
+
+func queryTimeline(ctx context.Context, db *database.DB, query string, id, orgID uuid.UUID) ([]*models.TimelineEntry, error) {
+	rows, err := db.Pool.Query(ctx, query, id, orgID, maxTimelineEntries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query timeline: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]*models.TimelineEntry, 0)
+	for rows.Next() {
+		var e models.TimelineEntry
+		if err := rows.Scan(&e.At, &e.Event, &e.EntityType, &e.EntityID, &e.Title, &e.Status); err != nil {
+			return nil, fmt.Errorf("failed to scan timeline entry: %w", err)
+		}
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
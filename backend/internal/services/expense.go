@@ -0,0 +1,387 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// MaxReceiptUploadSize is the maximum size of an uploaded receipt (10MB)
+const MaxReceiptUploadSize int64 = 10 << 20
+
+// receiptMimeTypes are the receipt formats accepted, keyed by detected content type
+var receiptMimeTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+// ExpenseService records expenses from receipts. Receipts are read by the OCR provider into a
+// draft that is posted once a user confirms it.
+type ExpenseService struct {
+	db      *database.DB
+	storage *StorageService
+	ocr     ReceiptOCRProvider
+}
+
+func NewExpenseService(db *database.DB, storage *StorageService, ocr ReceiptOCRProvider) *ExpenseService {
+	return &ExpenseService{
+		db:      db,
+		storage: storage,
+		ocr:     ocr,
+	}
+}
+
+// ExpenseFilters contains filters for listing expenses
+type ExpenseFilters struct {
+	Scope     string
+	Status    string
+	ProjectID *uuid.UUID
+	From      *time.Time
+	To        *time.Time
+	Limit     int
+	Offset    int
+}
+
+// UploadReceiptInput says what a receipt is for
+type UploadReceiptInput struct {
+	Scope     models.ExpenseScope
+	ProjectID *uuid.UUID
+	FileName  string
+}
+
+// UpdateExpenseRequest corrects the fields of a draft expense
+type UpdateExpenseRequest struct {
+	Scope       models.ExpenseScope `json:"scope"`
+	ProjectID   *uuid.UUID          `json:"project_id"`
+	Vendor      *string             `json:"vendor"`
+	VendorTaxID *string             `json:"vendor_tax_id"`
+	ExpenseDate *time.Time          `json:"expense_date"`
+	Amount      *decimal.Decimal    `json:"amount"`
+	VATAmount   *decimal.Decimal    `json:"vat_amount"`
+	VATRate     *decimal.Decimal    `json:"vat_rate"`
+	Category    *string             `json:"category"`
+	Description *string             `json:"description"`
+}
+
+const expenseColumns = `
+	e.id, e.organization_id, e.scope, e.project_id, e.status, e.vendor, e.vendor_tax_id, e.expense_date,
+	e.amount, e.vat_amount, e.vat_rate, e.category, e.description, e.receipt_url, e.receipt_file_name,
+	e.ocr_provider, e.ocr_confidence, e.ocr_data, e.created_by, e.confirmed_by, e.confirmed_at,
+	e.created_at, e.updated_at, p.project_number, p.title
+`
+
+func scanExpense(row pgx.Row) (*models.Expense, error) {
+	var e models.Expense
+	err := row.Scan(
+		&e.ID, &e.OrganizationID, &e.Scope, &e.ProjectID, &e.Status, &e.Vendor, &e.VendorTaxID, &e.ExpenseDate,
+		&e.Amount, &e.VATAmount, &e.VATRate, &e.Category, &e.Description, &e.ReceiptURL, &e.ReceiptFileName,
+		&e.OCRProvider, &e.OCRConfidence, &e.OCRData, &e.CreatedBy, &e.ConfirmedBy, &e.ConfirmedAt,
+		&e.CreatedAt, &e.UpdatedAt, &e.ProjectNumber, &e.ProjectTitle,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// List returns expenses filtered by scope, status, project and expense date range
+func (s *ExpenseService) List(ctx context.Context, orgID uuid.UUID, filters ExpenseFilters) ([]*models.Expense, int, error) {
+	where := "WHERE e.organization_id = $1 AND e.deleted_at IS NULL"
+	args := []interface{}{orgID}
+
+	if filters.Scope != "" {
+		args = append(args, filters.Scope)
+		where += fmt.Sprintf(" AND e.scope = $%d", len(args))
+	}
+	if filters.Status != "" {
+		args = append(args, filters.Status)
+		where += fmt.Sprintf(" AND e.status = $%d", len(args))
+	}
+	if filters.ProjectID != nil {
+		args = append(args, *filters.ProjectID)
+		where += fmt.Sprintf(" AND e.project_id = $%d", len(args))
+	}
+	if filters.From != nil {
+		args = append(args, *filters.From)
+		where += fmt.Sprintf(" AND e.expense_date >= $%d", len(args))
+	}
+	if filters.To != nil {
+		args = append(args, *filters.To)
+		where += fmt.Sprintf(" AND e.expense_date <= $%d", len(args))
+	}
+
+	var total int
+	err := s.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM expenses e `+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count expenses: %w", err)
+	}
+
+	if filters.Limit <= 0 {
+		filters.Limit = 50
+	}
+	args = append(args, filters.Limit, filters.Offset)
+	query := `SELECT ` + expenseColumns + ` FROM expenses e LEFT JOIN projects p ON p.id = e.project_id ` + where + fmt.Sprintf(`
+		ORDER BY e.status = 'draft' DESC, e.expense_date DESC NULLS FIRST, e.created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, len(args)-1, len(args))
+
+	rows, err := s.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query expenses: %w", err)
+	}
+	defer rows.Close()
+
+	expenses := []*models.Expense{}
+	for rows.Next() {
+		e, err := scanExpense(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan expense: %w", err)
+		}
+		expenses = append(expenses, e)
+	}
+
+	return expenses, total, nil
+}
+
+// GetByID returns an expense of the organization
+func (s *ExpenseService) GetByID(ctx context.Context, id, orgID uuid.UUID) (*models.Expense, error) {
+	e, err := scanExpense(s.db.Pool.QueryRow(ctx, `
+		SELECT `+expenseColumns+`
+		FROM expenses e
+		LEFT JOIN projects p ON p.id = e.project_id
+		WHERE e.id = $1 AND e.organization_id = $2 AND e.deleted_at IS NULL
+	`, id, orgID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("expense not found")
+		}
+		return nil, fmt.Errorf("failed to get expense: %w", err)
+	}
+	return e, nil
+}
+
+// UploadReceipt stores a receipt and creates a draft expense pre-filled with what the OCR
+// provider read from it. A failed extraction still creates the draft, to be filled in by hand.
+func (s *ExpenseService) UploadReceipt(ctx context.Context, orgID, userID uuid.UUID, input UploadReceiptInput, data []byte) (*models.Expense, error) {
+	if s.storage == nil {
+		return nil, errors.New("storage is not configured")
+	}
+	if err := s.checkScope(ctx, orgID, input.Scope, input.ProjectID); err != nil {
+		return nil, err
+	}
+
+	mimeType := http.DetectContentType(data)
+	ext, ok := receiptMimeTypes[mimeType]
+	if !ok {
+		return nil, errors.New("receipt must be a JPEG, PNG or WebP image or a PDF")
+	}
+	fileName := input.FileName
+	if fileName == "" {
+		fileName = "receipt" + ext
+	}
+
+	var extraction *models.ReceiptExtraction
+	var provider *string
+	if s.ocr != nil {
+		name := s.ocr.Name()
+		provider = &name
+		extracted, err := s.ocr.Extract(ctx, data, mimeType)
+		if err != nil {
+			log.Printf("Warning: failed to read receipt with %s OCR: %v", name, err)
+		} else {
+			extraction = extracted
+		}
+	}
+
+	result, err := s.storage.UploadData(ctx, data, fileName, mimeType, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store receipt: %w", err)
+	}
+
+	expense := &models.Expense{
+		Scope:           input.Scope,
+		ProjectID:       input.ProjectID,
+		ReceiptURL:      &result.URL,
+		ReceiptFileName: &fileName,
+		OCRProvider:     provider,
+	}
+	if extraction != nil {
+		expense.Vendor = extraction.Vendor
+		expense.VendorTaxID = extraction.VendorTaxID
+		expense.ExpenseDate = extraction.Date
+		expense.Amount = extraction.Total
+		expense.VATAmount = extraction.VATAmount
+		expense.VATRate = extraction.VATRate
+		expense.OCRConfidence = extraction.Confidence
+		expense.OCRData, _ = json.Marshal(extraction)
+	}
+
+	var id uuid.UUID
+	err = s.db.Pool.QueryRow(ctx, `
+		INSERT INTO expenses (
+			organization_id, scope, project_id, vendor, vendor_tax_id, expense_date, amount, vat_amount, vat_rate,
+			receipt_url, receipt_file_name, ocr_provider, ocr_confidence, ocr_data, created_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING id
+	`, orgID, expense.Scope, expense.ProjectID, expense.Vendor, expense.VendorTaxID, expense.ExpenseDate,
+		expense.Amount, expense.VATAmount, expense.VATRate, expense.ReceiptURL, expense.ReceiptFileName,
+		expense.OCRProvider, expense.OCRConfidence, expense.OCRData, userID).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create expense: %w", err)
+	}
+
+	return s.GetByID(ctx, id, orgID)
+}
+
+// Update corrects the fields of a draft expense
+func (s *ExpenseService) Update(ctx context.Context, id, orgID uuid.UUID, req UpdateExpenseRequest) (*models.Expense, error) {
+	expense, err := s.GetByID(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if expense.Status != models.ExpenseStatusDraft {
+		return nil, errors.New("only draft expenses can be edited")
+	}
+	if err := s.checkScope(ctx, orgID, req.Scope, req.ProjectID); err != nil {
+		return nil, err
+	}
+	if err := validateExpenseAmounts(req.Amount, req.VATAmount, req.VATRate); err != nil {
+		return nil, err
+	}
+
+	_, err = s.db.Pool.Exec(ctx, `
+		UPDATE expenses SET
+			scope = $1, project_id = $2, vendor = $3, vendor_tax_id = $4, expense_date = $5, amount = $6,
+			vat_amount = $7, vat_rate = $8, category = $9, description = $10, updated_at = NOW()
+		WHERE id = $11 AND organization_id = $12 AND status = 'draft' AND deleted_at IS NULL
+	`, req.Scope, req.ProjectID, req.Vendor, req.VendorTaxID, req.ExpenseDate, req.Amount,
+		req.VATAmount, req.VATRate, req.Category, req.Description, id, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update expense: %w", err)
+	}
+
+	return s.GetByID(ctx, id, orgID)
+}
+
+// Confirm posts a reviewed draft. It needs a vendor, date and amount, and the expense's month
+// must be open.
+func (s *ExpenseService) Confirm(ctx context.Context, id, orgID, userID uuid.UUID) (*models.Expense, error) {
+	expense, err := s.GetByID(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if expense.Status != models.ExpenseStatusDraft {
+		return nil, errors.New("expense is already posted")
+	}
+	switch {
+	case expense.Vendor == nil || *expense.Vendor == "":
+		return nil, errors.New("vendor is required")
+	case expense.ExpenseDate == nil:
+		return nil, errors.New("expense date is required")
+	case expense.Amount == nil || !expense.Amount.IsPositive():
+		return nil, errors.New("amount must be greater than zero")
+	case expense.Scope == models.ExpenseScopeProject && expense.ProjectID == nil:
+		return nil, errors.New("project expenses need a project")
+	}
+	if err := validateExpenseAmounts(expense.Amount, expense.VATAmount, expense.VATRate); err != nil {
+		return nil, err
+	}
+	if err := checkPeriodsOpen(ctx, s.db.Pool, orgID, *expense.ExpenseDate); err != nil {
+		return nil, err
+	}
+
+	tag, err := s.db.Pool.Exec(ctx, `
+		UPDATE expenses SET status = 'posted', confirmed_by = $1, confirmed_at = NOW(), updated_at = NOW()
+		WHERE id = $2 AND organization_id = $3 AND status = 'draft' AND deleted_at IS NULL
+	`, userID, id, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to confirm expense: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, errors.New("expense is already posted")
+	}
+
+	return s.GetByID(ctx, id, orgID)
+}
+
+// Delete removes a draft, or a posted expense of an open month
+func (s *ExpenseService) Delete(ctx context.Context, id, orgID uuid.UUID) error {
+	expense, err := s.GetByID(ctx, id, orgID)
+	if err != nil {
+		return err
+	}
+	if expense.Status == models.ExpenseStatusPosted && expense.ExpenseDate != nil {
+		if err := checkPeriodsOpen(ctx, s.db.Pool, orgID, *expense.ExpenseDate); err != nil {
+			return err
+		}
+	}
+
+	_, err = s.db.Pool.Exec(ctx, `
+		UPDATE expenses SET deleted_at = NOW() WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to delete expense: %w", err)
+	}
+	return nil
+}
+
+// checkScope validates the scope of an expense and that its project belongs to the organization
+func (s *ExpenseService) checkScope(ctx context.Context, orgID uuid.UUID, scope models.ExpenseScope, projectID *uuid.UUID) error {
+	switch scope {
+	case models.ExpenseScopeClinic:
+		if projectID != nil {
+			return errors.New("clinic expenses can't belong to a project")
+		}
+		return nil
+	case models.ExpenseScopeProject:
+		if projectID == nil {
+			return nil // chosen during review
+		}
+	default:
+		return errors.New("scope must be project or clinic")
+	}
+
+	var exists bool
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM projects WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL)
+	`, *projectID, orgID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check project: %w", err)
+	}
+	if !exists {
+		return errors.New("project not found")
+	}
+	return nil
+}
+
+// validateExpenseAmounts checks the amounts that are set are consistent
+func validateExpenseAmounts(amount, vatAmount, vatRate *decimal.Decimal) error {
+	if amount != nil && amount.IsNegative() {
+		return errors.New("amount cannot be negative")
+	}
+	if vatAmount != nil {
+		if vatAmount.IsNegative() {
+			return errors.New("VAT amount cannot be negative")
+		}
+		if amount != nil && vatAmount.GreaterThan(*amount) {
+			return errors.New("VAT amount cannot exceed the amount")
+		}
+	}
+	if vatRate != nil && (vatRate.IsNegative() || vatRate.GreaterThan(decimal.NewFromInt(100))) {
+		return errors.New("VAT rate must be between 0 and 100")
+	}
+	return nil
+}
@@ -0,0 +1,302 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// errPeriodClosed is returned when a change touches a payment or invoice of a closed month
+var errPeriodClosed = errors.New("this change affects a closed financial period, reopen it first")
+
+// FinancialPeriodService handles the monthly close of an organization's books
+type FinancialPeriodService struct {
+	db *database.DB
+}
+
+func NewFinancialPeriodService(db *database.DB) *FinancialPeriodService {
+	return &FinancialPeriodService{db: db}
+}
+
+// ParseFinancialPeriod parses a YYYY-MM period into the first day of the month
+func ParseFinancialPeriod(period string) (time.Time, error) {
+	start, err := time.Parse("2006-01", period)
+	if err != nil {
+		return time.Time{}, errors.New("invalid period, expected YYYY-MM")
+	}
+	return start, nil
+}
+
+// periodStart returns the first day of the month of t
+func periodStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// checkPeriodsOpen returns errPeriodClosed when any of the dates falls in a closed month.
+// A payment belongs to the month it was paid in or, while open, to the month it is due.
+func checkPeriodsOpen(ctx context.Context, q rowQuerier, orgID uuid.UUID, dates ...time.Time) error {
+	periods := make([]time.Time, 0, len(dates))
+	for _, d := range dates {
+		if !d.IsZero() {
+			periods = append(periods, periodStart(d))
+		}
+	}
+	if len(periods) == 0 {
+		return nil
+	}
+
+	var closed bool
+	err := q.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM financial_periods
+			WHERE organization_id = $1 AND status = 'closed' AND period = ANY($2::date[])
+		)
+	`, orgID, periods).Scan(&closed)
+	if err != nil {
+		return fmt.Errorf("failed to check financial periods: %w", err)
+	}
+	if closed {
+		return errPeriodClosed
+	}
+	return nil
+}
+
+// paymentPeriodDate returns the date that places a payment in a month: paid_at once paid,
+// otherwise the due date, falling back to the given date (the session day)
+func paymentPeriodDate(dueDate, paidAt *time.Time, fallback time.Time) time.Time {
+	switch {
+	case paidAt != nil:
+		return *paidAt
+	case dueDate != nil:
+		return *dueDate
+	default:
+		return fallback
+	}
+}
+
+// List returns the months that were closed at some point, most recent first
+func (s *FinancialPeriodService) List(ctx context.Context, orgID uuid.UUID) ([]*models.FinancialPeriod, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT to_char(period, 'YYYY-MM'), status, closed_by, closed_at, reopened_by, reopened_at, reopen_reason
+		FROM financial_periods
+		WHERE organization_id = $1
+		ORDER BY period DESC
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list financial periods: %w", err)
+	}
+	defer rows.Close()
+
+	periods := []*models.FinancialPeriod{}
+	for rows.Next() {
+		var p models.FinancialPeriod
+		if err := rows.Scan(&p.Period, &p.Status, &p.ClosedBy, &p.ClosedAt, &p.ReopenedBy, &p.ReopenedAt, &p.ReopenReason); err != nil {
+			return nil, fmt.Errorf("failed to scan financial period: %w", err)
+		}
+		periods = append(periods, &p)
+	}
+	return periods, nil
+}
+
+// Get returns a month with its figures: the snapshot taken at close when it is closed,
+// otherwise computed from the current data
+func (s *FinancialPeriodService) Get(ctx context.Context, orgID uuid.UUID, period time.Time) (*models.FinancialPeriod, error) {
+	p := &models.FinancialPeriod{Period: period.Format("2006-01"), Status: models.FinancialPeriodOpen}
+
+	var snapshot []byte
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT status, closed_by, closed_at, reopened_by, reopened_at, reopen_reason, snapshot
+		FROM financial_periods
+		WHERE organization_id = $1 AND period = $2
+	`, orgID, period).Scan(&p.Status, &p.ClosedBy, &p.ClosedAt, &p.ReopenedBy, &p.ReopenedAt, &p.ReopenReason, &snapshot)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get financial period: %w", err)
+	}
+
+	if p.Status == models.FinancialPeriodClosed && snapshot != nil {
+		if err := json.Unmarshal(snapshot, &p.Snapshot); err != nil {
+			return nil, fmt.Errorf("failed to read period snapshot: %w", err)
+		}
+		return p, nil
+	}
+
+	p.Snapshot, err = periodSnapshot(ctx, s.db.Pool, orgID, period)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Close locks a finished month and freezes its figures
+func (s *FinancialPeriodService) Close(ctx context.Context, orgID, userID uuid.UUID, period time.Time) (*models.FinancialPeriod, error) {
+	if !period.Before(periodStart(time.Now())) {
+		return nil, errors.New("only finished months can be closed")
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	snapshot, err := periodSnapshot(ctx, tx, orgID, period)
+	if err != nil {
+		return nil, err
+	}
+	snapshotJSON, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode period snapshot: %w", err)
+	}
+
+	result, err := tx.Exec(ctx, `
+		INSERT INTO financial_periods (organization_id, period, status, snapshot, closed_by, closed_at)
+		VALUES ($1, $2, 'closed', $3, $4, NOW())
+		ON CONFLICT (organization_id, period) DO UPDATE
+		SET status = 'closed', snapshot = EXCLUDED.snapshot, closed_by = EXCLUDED.closed_by, closed_at = EXCLUDED.closed_at
+		WHERE financial_periods.status <> 'closed'
+	`, orgID, period, snapshotJSON, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to close financial period: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, errors.New("financial period is already closed")
+	}
+
+	if err := logPeriodEvent(ctx, tx, orgID, period, "closed", userID, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return s.Get(ctx, orgID, period)
+}
+
+// Reopen unlocks a closed month so its payments can be corrected. The reason is kept in the history.
+func (s *FinancialPeriodService) Reopen(ctx context.Context, orgID, userID uuid.UUID, period time.Time, reason string) (*models.FinancialPeriod, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.New("a reason is required to reopen a financial period")
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE financial_periods
+		SET status = 'reopened', reopened_by = $1, reopened_at = NOW(), reopen_reason = $2
+		WHERE organization_id = $3 AND period = $4 AND status = 'closed'
+	`, userID, reason, orgID, period)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reopen financial period: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, errors.New("financial period is not closed")
+	}
+
+	if err := logPeriodEvent(ctx, tx, orgID, period, "reopened", userID, &reason); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return s.Get(ctx, orgID, period)
+}
+
+// History returns the closes and reopens of a month, most recent first
+func (s *FinancialPeriodService) History(ctx context.Context, orgID uuid.UUID, period time.Time) ([]*models.FinancialPeriodEvent, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT e.id, to_char(e.period, 'YYYY-MM'), e.action, e.user_id, u.first_name || ' ' || u.last_name, e.reason, e.created_at
+		FROM financial_period_events e
+		LEFT JOIN users u ON u.id = e.user_id
+		WHERE e.organization_id = $1 AND e.period = $2
+		ORDER BY e.created_at DESC
+	`, orgID, period)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get financial period history: %w", err)
+	}
+	defer rows.Close()
+
+	events := []*models.FinancialPeriodEvent{}
+	for rows.Next() {
+		var e models.FinancialPeriodEvent
+		if err := rows.Scan(&e.ID, &e.Period, &e.Action, &e.UserID, &e.UserName, &e.Reason, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan financial period event: %w", err)
+		}
+		events = append(events, &e)
+	}
+	return events, nil
+}
+
+// logPeriodEvent records a close or reopen of a month
+func logPeriodEvent(ctx context.Context, tx pgx.Tx, orgID uuid.UUID, period time.Time, action string, userID uuid.UUID, reason *string) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO financial_period_events (organization_id, period, action, user_id, reason)
+		VALUES ($1, $2, $3, $4, $5)
+	`, orgID, period, action, userID, reason)
+	if err != nil {
+		return fmt.Errorf("failed to record financial period event: %w", err)
+	}
+	return nil
+}
+
+// periodSnapshot computes the figures of a month from the project and session payments
+func periodSnapshot(ctx context.Context, q rowQuerier, orgID uuid.UUID, period time.Time) (*models.FinancialPeriodSnapshot, error) {
+	start, end := period, period.AddDate(0, 1, 0)
+	snapshot := &models.FinancialPeriodSnapshot{GeneratedAt: time.Now()}
+
+	var projectOutstanding decimal.Decimal
+	var projectCount int
+	err := q.QueryRow(ctx, `
+		SELECT
+			COALESCE(SUM(amount) FILTER (WHERE status <> 'cancelled' AND due_date >= $2 AND due_date < $3), 0),
+			COALESCE(SUM(amount) FILTER (WHERE status = 'paid' AND paid_at >= $2 AND paid_at < $3), 0),
+			COALESCE(SUM(amount) FILTER (WHERE status IN ('pending', 'overdue') AND due_date >= $2 AND due_date < $3), 0),
+			COUNT(*) FILTER (WHERE status <> 'cancelled'
+				AND ((due_date >= $2 AND due_date < $3) OR (paid_at >= $2 AND paid_at < $3)))
+		FROM payments
+		WHERE organization_id = $1 AND deleted_at IS NULL
+	`, orgID, start, end).Scan(&snapshot.ProjectPaymentsDue, &snapshot.ProjectPaymentsCollected, &projectOutstanding, &projectCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute project payments: %w", err)
+	}
+
+	var sessionDue, sessionCollected, sessionOutstanding int64
+	var sessionCount int
+	err = q.QueryRow(ctx, `
+		SELECT
+			COALESCE(SUM(sp.amount_cents) FILTER (WHERE COALESCE(sp.due_date, s.scheduled_at::date) >= $2
+				AND COALESCE(sp.due_date, s.scheduled_at::date) < $3), 0),
+			COALESCE(SUM(sp.amount_cents) FILTER (WHERE sp.payment_status = 'paid' AND sp.paid_at >= $2 AND sp.paid_at < $3), 0),
+			COALESCE(SUM(sp.amount_cents) FILTER (WHERE sp.payment_status IN ('unpaid', 'partial')
+				AND COALESCE(sp.due_date, s.scheduled_at::date) >= $2 AND COALESCE(sp.due_date, s.scheduled_at::date) < $3), 0),
+			COUNT(*) FILTER (WHERE (COALESCE(sp.due_date, s.scheduled_at::date) >= $2 AND COALESCE(sp.due_date, s.scheduled_at::date) < $3)
+				OR (sp.paid_at >= $2 AND sp.paid_at < $3))
+		FROM session_payments sp
+		JOIN sessions s ON s.id = sp.session_id
+		WHERE s.organization_id = $1 AND s.deleted_at IS NULL
+	`, orgID, start, end).Scan(&sessionDue, &sessionCollected, &sessionOutstanding, &sessionCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute session payments: %w", err)
+	}
+
+	snapshot.SessionPaymentsDue = decimal.New(sessionDue, -2)
+	snapshot.SessionPaymentsCollected = decimal.New(sessionCollected, -2)
+	snapshot.TotalCollected = snapshot.ProjectPaymentsCollected.Add(snapshot.SessionPaymentsCollected)
+	snapshot.Outstanding = projectOutstanding.Add(decimal.New(sessionOutstanding, -2))
+	snapshot.PaymentsCount = projectCount + sessionCount
+	return snapshot, nil
+}
@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// followUpBatch caps how many due follow-ups the worker handles per run
+const followUpBatch = 100
+
+// followUpLabelJoins and followUpLabel name the record a follow-up (aliased f) is about
+const followUpLabelJoins = `
+		LEFT JOIN budgets fb ON f.entity_type = 'budget' AND fb.id = f.entity_id
+		LEFT JOIN clients fc ON f.entity_type = 'client' AND fc.id = f.entity_id
+		LEFT JOIN sessions fs ON f.entity_type = 'session' AND fs.id = f.entity_id
+		LEFT JOIN patients fsp ON fsp.id = fs.patient_id
+		LEFT JOIN clients fspc ON fspc.id = fsp.client_id
+		LEFT JOIN projects fp ON f.entity_type = 'project' AND fp.id = f.entity_id
+		LEFT JOIN patients fpt ON f.entity_type = 'patient' AND fpt.id = f.entity_id
+		LEFT JOIN clients fptc ON fptc.id = fpt.client_id`
+
+const followUpLabel = `COALESCE(CASE f.entity_type
+			WHEN 'budget' THEN fb.budget_number
+			WHEN 'client' THEN fc.name
+			WHEN 'session' THEN fspc.name || ' ' || to_char(fs.scheduled_at, 'YYYY-MM-DD HH24:MI')
+			WHEN 'project' THEN fp.title
+			WHEN 'patient' THEN fptc.name
+		END, '')`
+
+const followUpColumns = `
+	f.id, f.organization_id, f.user_id, f.entity_type, f.entity_id, ` + followUpLabel + `,
+	f.remind_at, f.note, f.send_email, f.status, f.notified_at, f.completed_at, f.created_at, f.updated_at`
+
+// followUpEntityQueries check that a record belongs to the organization
+var followUpEntityQueries = map[models.FollowUpEntityType]string{
+	models.FollowUpEntityBudget:  `SELECT EXISTS (SELECT 1 FROM budgets WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL)`,
+	models.FollowUpEntityClient:  `SELECT EXISTS (SELECT 1 FROM clients WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL)`,
+	models.FollowUpEntitySession: `SELECT EXISTS (SELECT 1 FROM sessions WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL)`,
+	models.FollowUpEntityProject: `SELECT EXISTS (SELECT 1 FROM projects WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL)`,
+	models.FollowUpEntityPatient: `SELECT EXISTS (SELECT 1 FROM patients WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL)`,
+}
+
+// followUpPaths are the frontend pages of the records follow-ups are about
+var followUpPaths = map[models.FollowUpEntityType]string{
+	models.FollowUpEntityBudget:  "budgets",
+	models.FollowUpEntityClient:  "clients",
+	models.FollowUpEntitySession: "agenda",
+	models.FollowUpEntityProject: "projects",
+	models.FollowUpEntityPatient: "patients",
+}
+
+// FollowUpService manages users' personal follow-up reminders
+type FollowUpService struct {
+	db          *database.DB
+	email       *EmailService
+	frontendURL string
+}
+
+func NewFollowUpService(db *database.DB, email *EmailService, frontendURL string) *FollowUpService {
+	return &FollowUpService{db: db, email: email, frontendURL: frontendURL}
+}
+
+func scanFollowUp(row pgx.Row) (*models.FollowUp, error) {
+	var f models.FollowUp
+	err := row.Scan(
+		&f.ID, &f.OrganizationID, &f.UserID, &f.EntityType, &f.EntityID, &f.EntityLabel,
+		&f.RemindAt, &f.Note, &f.SendEmail, &f.Status, &f.NotifiedAt, &f.CompletedAt, &f.CreatedAt, &f.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// List returns the user's follow-ups, soonest first
+func (s *FollowUpService) List(ctx context.Context, orgID, userID uuid.UUID, filters models.FollowUpFilters) ([]*models.FollowUp, error) {
+	query := `SELECT ` + followUpColumns + ` FROM follow_ups f ` + followUpLabelJoins + `
+		WHERE f.organization_id = $1 AND f.user_id = $2`
+	args := []interface{}{orgID, userID}
+
+	if filters.Status != "" {
+		args = append(args, filters.Status)
+		query += fmt.Sprintf(" AND f.status = $%d", len(args))
+	}
+	if filters.EntityType != "" {
+		args = append(args, filters.EntityType)
+		query += fmt.Sprintf(" AND f.entity_type = $%d", len(args))
+	}
+	if filters.EntityID != nil {
+		args = append(args, *filters.EntityID)
+		query += fmt.Sprintf(" AND f.entity_id = $%d", len(args))
+	}
+	query += " ORDER BY f.remind_at"
+
+	rows, err := s.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list follow-ups: %w", err)
+	}
+	defer rows.Close()
+
+	followUps := []*models.FollowUp{}
+	for rows.Next() {
+		f, err := scanFollowUp(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan follow-up: %w", err)
+		}
+		followUps = append(followUps, f)
+	}
+	return followUps, rows.Err()
+}
+
+// GetByID returns one of the user's follow-ups
+func (s *FollowUpService) GetByID(ctx context.Context, id, orgID, userID uuid.UUID) (*models.FollowUp, error) {
+	f, err := scanFollowUp(s.db.Pool.QueryRow(ctx, `
+		SELECT `+followUpColumns+` FROM follow_ups f `+followUpLabelJoins+`
+		WHERE f.id = $1 AND f.organization_id = $2 AND f.user_id = $3
+	`, id, orgID, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.New("follow-up not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get follow-up: %w", err)
+	}
+	return f, nil
+}
+
+// Create sets a follow-up for the user on a record of the organization
+func (s *FollowUpService) Create(ctx context.Context, f *models.FollowUp) (*models.FollowUp, error) {
+	query, ok := followUpEntityQueries[f.EntityType]
+	if !ok {
+		return nil, errors.New("invalid entity type")
+	}
+	if !f.RemindAt.After(time.Now()) {
+		return nil, errors.New("remind time must be in the future")
+	}
+
+	var exists bool
+	if err := s.db.Pool.QueryRow(ctx, query, f.EntityID, f.OrganizationID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to find %s: %w", f.EntityType, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("%s not found", f.EntityType)
+	}
+
+	f.ID = uuid.New()
+	_, err := s.db.Pool.Exec(ctx, `
+		INSERT INTO follow_ups (id, organization_id, user_id, entity_type, entity_id, remind_at, note, send_email)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, f.ID, f.OrganizationID, f.UserID, f.EntityType, f.EntityID, f.RemindAt, f.Note, f.SendEmail)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create follow-up: %w", err)
+	}
+
+	return s.GetByID(ctx, f.ID, f.OrganizationID, f.UserID)
+}
+
+// Reschedule moves an open follow-up to a new time, which also brings it back into the inbox
+func (s *FollowUpService) Reschedule(ctx context.Context, id, orgID, userID uuid.UUID, remindAt time.Time, note *string, sendEmail bool) (*models.FollowUp, error) {
+	if !remindAt.After(time.Now()) {
+		return nil, errors.New("remind time must be in the future")
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE follow_ups
+		SET remind_at = $4, note = $5, send_email = $6, status = 'pending', notified_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND organization_id = $2 AND user_id = $3 AND status IN ('pending', 'notified')
+	`, id, orgID, userID, remindAt, note, sendEmail)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update follow-up: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, errors.New("follow-up not found or already closed")
+	}
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM inbox_item_states WHERE organization_id = $1 AND item_type = $2 AND item_id = $3
+	`, orgID, models.InboxItemFollowUp, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reset inbox state: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return s.GetByID(ctx, id, orgID, userID)
+}
+
+// Complete marks an open follow-up as done
+func (s *FollowUpService) Complete(ctx context.Context, id, orgID, userID uuid.UUID) error {
+	return s.close(ctx, id, orgID, userID, models.FollowUpStatusDone)
+}
+
+// Cancel drops an open follow-up
+func (s *FollowUpService) Cancel(ctx context.Context, id, orgID, userID uuid.UUID) error {
+	return s.close(ctx, id, orgID, userID, models.FollowUpStatusCancelled)
+}
+
+func (s *FollowUpService) close(ctx context.Context, id, orgID, userID uuid.UUID, status models.FollowUpStatus) error {
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE follow_ups SET status = $4, completed_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND organization_id = $2 AND user_id = $3 AND status IN ('pending', 'notified')
+	`, id, orgID, userID, status)
+	if err != nil {
+		return fmt.Errorf("failed to update follow-up: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("follow-up not found or already closed")
+	}
+	return nil
+}
+
+// NotifyDue marks the follow-ups that came due as notified, which lists them in their
+// owner's inbox, and emails those that asked for it. It is run by the worker; follow-ups
+// are claimed with SKIP LOCKED so several workers never notify the same one.
+func (s *FollowUpService) NotifyDue(ctx context.Context) (int, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		UPDATE follow_ups SET status = 'notified', notified_at = NOW(), updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM follow_ups
+			WHERE status = 'pending' AND remind_at <= NOW()
+			ORDER BY remind_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, organization_id, user_id
+	`, followUpBatch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim follow-ups: %w", err)
+	}
+	type claimed struct{ id, orgID, userID uuid.UUID }
+	var due []claimed
+	for rows.Next() {
+		var c claimed
+		if err := rows.Scan(&c.id, &c.orgID, &c.userID); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan follow-up: %w", err)
+		}
+		due = append(due, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to claim follow-ups: %w", err)
+	}
+
+	for _, c := range due {
+		f, err := s.GetByID(ctx, c.id, c.orgID, c.userID)
+		if err != nil {
+			log.Printf("[FollowUps] Failed to get follow-up %s: %v", c.id, err)
+			continue
+		}
+		if f.SendEmail {
+			s.sendEmail(ctx, f)
+		}
+	}
+	return len(due), nil
+}
+
+// sendEmail emails a due follow-up to its owner; the follow-up stays in the inbox either way
+func (s *FollowUpService) sendEmail(ctx context.Context, f *models.FollowUp) {
+	if s.email == nil {
+		return
+	}
+
+	var to, firstName string
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT email, first_name FROM users WHERE id = $1 AND is_active AND deleted_at IS NULL
+	`, f.UserID).Scan(&to, &firstName)
+	if err != nil {
+		log.Printf("[FollowUps] Failed to get owner of follow-up %s: %v", f.ID, err)
+		return
+	}
+
+	note := ""
+	if f.Note != nil {
+		note = *f.Note
+	}
+	link := fmt.Sprintf("%s/dashboard/%s/%s", s.frontendURL, followUpPaths[f.EntityType], f.EntityID)
+	if err := s.email.SendFollowUpReminder(to, firstName, f.EntityLabel, note, link); err != nil {
+		log.Printf("[FollowUps] Failed to email follow-up %s: %v", f.ID, err)
+	}
+}
@@ -0,0 +1,324 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// inboxSourceLimit caps how many items each kind of inbox item contributes
+const inboxSourceLimit = 200
+
+// inboxSource finds one kind of inbox item. The query takes the organization ID as $1, and the
+// user's ID as $2 for personal sources, and returns id, title, detail, due_at, created_at,
+// entity_type, entity_id and priority.
+type inboxSource struct {
+	itemType models.InboxItemType
+	personal bool // only the user's own items
+	query    string
+}
+
+var inboxSources = []inboxSource{
+	{models.InboxItemSessionUnconfirmed, false, `
+		SELECT s.id, COALESCE(c.name, '') AS title, t.name AS detail, s.scheduled_at AS due_at,
+			s.created_at, 'session' AS entity_type, s.id AS entity_id, 'high' AS priority
+		FROM sessions s
+		JOIN therapists t ON t.id = s.therapist_id
+		LEFT JOIN patients p ON p.id = s.patient_id
+		LEFT JOIN clients c ON c.id = p.client_id
+		WHERE s.organization_id = $1 AND s.deleted_at IS NULL AND s.status = 'pending'
+			AND s.scheduled_at >= NOW() AND s.scheduled_at < NOW() + INTERVAL '24 hours'
+	`},
+	{models.InboxItemFailedReminder, false, `
+		SELECT r.id, COALESCE(c.name, '') AS title, COALESCE(r.error_message, '') AS detail, s.scheduled_at AS due_at,
+			COALESCE(r.processed_at, r.created_at) AS created_at, 'session' AS entity_type, s.id AS entity_id, 'high' AS priority
+		FROM scheduled_reminders r
+		JOIN sessions s ON s.id = r.session_id
+		LEFT JOIN patients p ON p.id = s.patient_id
+		LEFT JOIN clients c ON c.id = p.client_id
+		WHERE s.organization_id = $1 AND s.deleted_at IS NULL AND r.status = 'failed'
+			AND s.status IN ('pending', 'confirmed') AND s.scheduled_at > NOW()
+	`},
+	{models.InboxItemBudgetApproval, false, `
+		SELECT b.id, b.budget_number AS title, COALESCE(c.name, '') AS detail, b.valid_until::timestamp AS due_at,
+			b.created_at, 'budget' AS entity_type, b.id AS entity_id, 'medium' AS priority
+		FROM budgets b
+		LEFT JOIN worksheets w ON w.id = b.worksheet_id
+		LEFT JOIN clients c ON c.id = w.client_id
+		WHERE b.organization_id = $1 AND b.deleted_at IS NULL AND b.status = 'pending_internal_approval'
+	`},
+	{models.InboxItemOverdueTask, false, `
+		SELECT t.id, t.title, pr.title AS detail, t.due_date AS due_at, t.created_at, 'project' AS entity_type, pr.id AS entity_id,
+			CASE WHEN t.priority IN ('high', 'urgent') THEN 'high' ELSE 'medium' END AS priority
+		FROM tasks t
+		JOIN projects pr ON pr.id = t.project_id
+		WHERE pr.organization_id = $1 AND t.deleted_at IS NULL AND pr.deleted_at IS NULL
+			AND t.status IN ('todo', 'in_progress') AND t.due_date < NOW()
+	`},
+	{models.InboxItemUnmatchedMessage, false, `
+		SELECT m.id, m.phone_number AS title, COALESCE(m.message_content, '') AS detail, NULL::timestamp AS due_at,
+			m.created_at, 'whatsapp_message' AS entity_type, m.id AS entity_id, 'medium' AS priority
+		FROM whatsapp_messages m
+		WHERE m.organization_id = $1 AND m.direction = 'inbound' AND m.session_id IS NULL
+			AND m.created_at > NOW() - INTERVAL '7 days'
+	`},
+	{models.InboxItemFollowUp, true, `
+		SELECT f.id, ` + followUpLabel + ` AS title, COALESCE(f.note, '') AS detail, f.remind_at AS due_at,
+			f.created_at, f.entity_type, f.entity_id, 'medium' AS priority
+		FROM follow_ups f
+		` + followUpLabelJoins + `
+		WHERE f.organization_id = $1 AND f.user_id = $2
+			AND f.status IN ('pending', 'notified') AND f.remind_at <= NOW()
+	`},
+}
+
+func inboxSourceFor(itemType models.InboxItemType) (inboxSource, bool) {
+	for _, source := range inboxSources {
+		if source.itemType == itemType {
+			return source, true
+		}
+	}
+	return inboxSource{}, false
+}
+
+// InboxService gathers the items across modules that need someone to act on them
+type InboxService struct {
+	db *database.DB
+}
+
+func NewInboxService(db *database.DB) *InboxService {
+	return &InboxService{db: db}
+}
+
+type inboxItemState struct {
+	assignedTo     *uuid.UUID
+	assignedToName *string
+	snoozedUntil   *time.Time
+	dismissed      bool
+}
+
+type inboxItemKey struct {
+	itemType models.InboxItemType
+	id       uuid.UUID
+}
+
+// args returns the query arguments of the source, followed by extra ones
+func (source inboxSource) args(orgID, userID uuid.UUID, extra ...interface{}) []interface{} {
+	args := []interface{}{orgID}
+	if source.personal {
+		args = append(args, userID)
+	}
+	return append(args, extra...)
+}
+
+// List returns the user's open inbox items, highest priority and soonest due first.
+// Dismissed items are left out, and snoozed ones unless filters.IncludeSnoozed is set.
+func (s *InboxService) List(ctx context.Context, orgID, userID uuid.UUID, filters models.InboxFilters) ([]*models.InboxItem, error) {
+	states, err := s.states(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	items := []*models.InboxItem{}
+	for _, source := range inboxSources {
+		if filters.Type != "" && filters.Type != source.itemType {
+			continue
+		}
+
+		args := source.args(orgID, userID, inboxSourceLimit)
+		rows, err := s.db.Pool.Query(ctx, fmt.Sprintf(`
+			SELECT i.id, i.title, i.detail, i.due_at, i.created_at, i.entity_type, i.entity_id, i.priority
+			FROM (%s) i
+			ORDER BY i.due_at NULLS LAST, i.created_at
+			LIMIT $%d
+		`, source.query, len(args)), args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s items: %w", source.itemType, err)
+		}
+		for rows.Next() {
+			item := &models.InboxItem{Type: source.itemType}
+			if err := rows.Scan(&item.ID, &item.Title, &item.Detail, &item.DueAt, &item.CreatedAt, &item.EntityType, &item.EntityID, &item.Priority); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan %s item: %w", source.itemType, err)
+			}
+
+			if state, ok := states[inboxItemKey{source.itemType, item.ID}]; ok {
+				if state.dismissed {
+					continue
+				}
+				if state.snoozedUntil != nil && state.snoozedUntil.After(now) {
+					if !filters.IncludeSnoozed {
+						continue
+					}
+					item.SnoozedUntil = state.snoozedUntil
+				}
+				item.AssignedTo = state.assignedTo
+				item.AssignedToName = state.assignedToName
+			}
+
+			if filters.Unassigned && item.AssignedTo != nil {
+				continue
+			}
+			if filters.AssignedTo != nil && (item.AssignedTo == nil || *item.AssignedTo != *filters.AssignedTo) {
+				continue
+			}
+			items = append(items, item)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to list %s items: %w", source.itemType, err)
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.Priority.Rank() != b.Priority.Rank() {
+			return a.Priority.Rank() < b.Priority.Rank()
+		}
+		if (a.DueAt == nil) != (b.DueAt == nil) {
+			return a.DueAt != nil
+		}
+		if a.DueAt != nil && !a.DueAt.Equal(*b.DueAt) {
+			return a.DueAt.Before(*b.DueAt)
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+
+	return items, nil
+}
+
+func (s *InboxService) states(ctx context.Context, orgID uuid.UUID) (map[inboxItemKey]inboxItemState, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT st.item_type, st.item_id, st.assigned_to,
+			CASE WHEN u.id IS NOT NULL THEN CONCAT(u.first_name, ' ', u.last_name) END,
+			st.snoozed_until, st.dismissed_at IS NOT NULL
+		FROM inbox_item_states st
+		LEFT JOIN users u ON u.id = st.assigned_to
+		WHERE st.organization_id = $1
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inbox item states: %w", err)
+	}
+	defer rows.Close()
+
+	states := map[inboxItemKey]inboxItemState{}
+	for rows.Next() {
+		var key inboxItemKey
+		var state inboxItemState
+		if err := rows.Scan(&key.itemType, &key.id, &state.assignedTo, &state.assignedToName, &state.snoozedUntil, &state.dismissed); err != nil {
+			return nil, fmt.Errorf("failed to scan inbox item state: %w", err)
+		}
+		states[key] = state
+	}
+	return states, rows.Err()
+}
+
+// checkItem ensures the item is currently in the user's inbox
+func (s *InboxService) checkItem(ctx context.Context, orgID, userID uuid.UUID, itemType models.InboxItemType, itemID uuid.UUID) error {
+	source, ok := inboxSourceFor(itemType)
+	if !ok {
+		return errors.New("invalid inbox item type")
+	}
+
+	var exists bool
+	args := source.args(orgID, userID, itemID)
+	err := s.db.Pool.QueryRow(ctx, fmt.Sprintf(`
+		SELECT EXISTS (SELECT 1 FROM (%s) i WHERE i.id = $%d)
+	`, source.query, len(args)), args...).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to find inbox item: %w", err)
+	}
+	if !exists {
+		return errors.New("inbox item not found")
+	}
+	return nil
+}
+
+// Assign gives an inbox item to a user of the organization; a nil userID unassigns it
+func (s *InboxService) Assign(ctx context.Context, orgID uuid.UUID, itemType models.InboxItemType, itemID uuid.UUID, userID *uuid.UUID, updatedBy uuid.UUID) error {
+	if err := s.checkItem(ctx, orgID, updatedBy, itemType, itemID); err != nil {
+		return err
+	}
+
+	if userID != nil {
+		var exists bool
+		err := s.db.Pool.QueryRow(ctx, `
+			SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND organization_id = $2 AND is_active AND deleted_at IS NULL)
+		`, *userID, orgID).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to find user: %w", err)
+		}
+		if !exists {
+			return errors.New("user not found")
+		}
+	}
+
+	_, err := s.db.Pool.Exec(ctx, `
+		INSERT INTO inbox_item_states (organization_id, item_type, item_id, assigned_to, updated_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (organization_id, item_type, item_id) DO UPDATE SET
+			assigned_to = EXCLUDED.assigned_to, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+	`, orgID, itemType, itemID, userID, updatedBy)
+	if err != nil {
+		return fmt.Errorf("failed to assign inbox item: %w", err)
+	}
+	return nil
+}
+
+// Snooze hides an inbox item until the given time
+func (s *InboxService) Snooze(ctx context.Context, orgID uuid.UUID, itemType models.InboxItemType, itemID uuid.UUID, until time.Time, updatedBy uuid.UUID) error {
+	if !until.After(time.Now()) {
+		return errors.New("snooze time must be in the future")
+	}
+	if err := s.checkItem(ctx, orgID, updatedBy, itemType, itemID); err != nil {
+		return err
+	}
+
+	_, err := s.db.Pool.Exec(ctx, `
+		INSERT INTO inbox_item_states (organization_id, item_type, item_id, snoozed_until, updated_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (organization_id, item_type, item_id) DO UPDATE SET
+			snoozed_until = EXCLUDED.snoozed_until, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+	`, orgID, itemType, itemID, until, updatedBy)
+	if err != nil {
+		return fmt.Errorf("failed to snooze inbox item: %w", err)
+	}
+	return nil
+}
+
+// Dismiss removes an inbox item from the inbox for good. Dismissing a follow-up completes it.
+func (s *InboxService) Dismiss(ctx context.Context, orgID uuid.UUID, itemType models.InboxItemType, itemID uuid.UUID, dismissedBy uuid.UUID) error {
+	if err := s.checkItem(ctx, orgID, dismissedBy, itemType, itemID); err != nil {
+		return err
+	}
+
+	if itemType == models.InboxItemFollowUp {
+		_, err := s.db.Pool.Exec(ctx, `
+			UPDATE follow_ups SET status = 'done', completed_at = NOW(), updated_at = NOW()
+			WHERE id = $1 AND organization_id = $2
+		`, itemID, orgID)
+		if err != nil {
+			return fmt.Errorf("failed to complete follow-up: %w", err)
+		}
+		return nil
+	}
+
+	_, err := s.db.Pool.Exec(ctx, `
+		INSERT INTO inbox_item_states (organization_id, item_type, item_id, dismissed_at, dismissed_by, updated_by)
+		VALUES ($1, $2, $3, NOW(), $4, $4)
+		ON CONFLICT (organization_id, item_type, item_id) DO UPDATE SET
+			dismissed_at = EXCLUDED.dismissed_at, dismissed_by = EXCLUDED.dismissed_by,
+			updated_by = EXCLUDED.updated_by, updated_at = NOW()
+	`, orgID, itemType, itemID, dismissedBy)
+	if err != nil {
+		return fmt.Errorf("failed to dismiss inbox item: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// errIntegrationNotConfigured is returned by integration checks when the integration is not set up
+var errIntegrationNotConfigured = errors.New("not configured")
+
+// Integration health statuses
+const (
+	IntegrationStatusOK            = "ok"
+	IntegrationStatusError         = "error"
+	IntegrationStatusNotConfigured = "not_configured"
+)
+
+// integrationCheckTimeout bounds each integration check so one slow provider doesn't stall the report
+const integrationCheckTimeout = 15 * time.Second
+
+// IntegrationHealth is the result of actively checking an external integration
+type IntegrationHealth struct {
+	Name          string     `json:"name"`
+	Status        string     `json:"status"`
+	Message       string     `json:"message,omitempty"`
+	LatencyMs     int64      `json:"latency_ms"`
+	LastSuccessAt *time.Time `json:"last_success_at"` // last message delivered through the integration
+	CheckedAt     time.Time  `json:"checked_at"`
+}
+
+// IntegrationService verifies the external integrations an organization depends on
+type IntegrationService struct {
+	db       *database.DB
+	whatsapp *WhatsAppService
+	email    *EmailService
+	storage  *StorageService
+}
+
+func NewIntegrationService(db *database.DB, whatsapp *WhatsAppService, email *EmailService, storage *StorageService) *IntegrationService {
+	return &IntegrationService{
+		db:       db,
+		whatsapp: whatsapp,
+		email:    email,
+		storage:  storage,
+	}
+}
+
+// CheckHealth runs every integration check in parallel and reports their status
+func (s *IntegrationService) CheckHealth(ctx context.Context, orgID uuid.UUID) ([]*IntegrationHealth, error) {
+	checks := []struct {
+		name  string
+		check func(context.Context) error
+	}{
+		{"twilio_whatsapp", func(ctx context.Context) error { return s.whatsapp.CheckCredentials(ctx, orgID) }},
+		{"smtp_email", s.email.CheckConnection},
+		{"s3_storage", s.storage.CheckConnection},
+	}
+
+	results := make([]*IntegrationHealth, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = runIntegrationCheck(ctx, c.name, c.check)
+		}()
+	}
+	wg.Wait()
+
+	lastSuccess, err := s.lastSuccessfulUse(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		result.LastSuccessAt = lastSuccess[result.Name]
+	}
+
+	return results, nil
+}
+
+// runIntegrationCheck times a single check and converts its error into a status
+func runIntegrationCheck(ctx context.Context, name string, check func(context.Context) error) *IntegrationHealth {
+	ctx, cancel := context.WithTimeout(ctx, integrationCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	result := &IntegrationHealth{
+		Name:      name,
+		Status:    IntegrationStatusOK,
+		LatencyMs: time.Since(start).Milliseconds(),
+		CheckedAt: time.Now(),
+	}
+	switch {
+	case errors.Is(err, errIntegrationNotConfigured):
+		result.Status = IntegrationStatusNotConfigured
+		result.LatencyMs = 0
+	case err != nil:
+		result.Status = IntegrationStatusError
+		result.Message = err.Error()
+	}
+	return result
+}
+
+// lastSuccessfulUse returns when each integration last delivered a message for the organization
+func (s *IntegrationService) lastSuccessfulUse(ctx context.Context, orgID uuid.UUID) (map[string]*time.Time, error) {
+	var whatsappAt, emailAt *time.Time
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT
+			(SELECT MAX(created_at) FROM whatsapp_messages
+			 WHERE organization_id = $1 AND direction = 'outbound' AND status IN ('sent', 'delivered', 'read')),
+			(SELECT MAX(created_at) FROM message_costs
+			 WHERE organization_id = $1 AND channel = $2)
+	`, orgID, models.MessageChannelEmail).Scan(&whatsappAt, &emailAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last integration use: %w", err)
+	}
+
+	return map[string]*time.Time{
+		"twilio_whatsapp": whatsappAt,
+		"smtp_email":      emailAt,
+	}, nil
+}
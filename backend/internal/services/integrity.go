@@ -0,0 +1,305 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// integrityIssuesPerCategory caps how many issues of a category a report lists
+const integrityIssuesPerCategory = 100
+
+// scheduledJobEntityExistsSQL is whether the entity of scheduled job sj still exists in its organization
+const scheduledJobEntityExistsSQL = `
+	CASE sj.entity_type
+		WHEN 'session' THEN EXISTS(SELECT 1 FROM sessions x WHERE x.id = sj.entity_id AND x.organization_id = sj.organization_id AND x.deleted_at IS NULL)
+		WHEN 'budget' THEN EXISTS(SELECT 1 FROM budgets x WHERE x.id = sj.entity_id AND x.organization_id = sj.organization_id AND x.deleted_at IS NULL)
+		WHEN 'project' THEN EXISTS(SELECT 1 FROM projects x WHERE x.id = sj.entity_id AND x.organization_id = sj.organization_id AND x.deleted_at IS NULL)
+		WHEN 'task' THEN EXISTS(
+			SELECT 1 FROM tasks x JOIN projects p ON p.id = x.project_id
+			WHERE x.id = sj.entity_id AND p.organization_id = sj.organization_id AND x.deleted_at IS NULL
+		)
+		WHEN 'payment' THEN EXISTS(SELECT 1 FROM payments x WHERE x.id = sj.entity_id AND x.organization_id = sj.organization_id AND x.deleted_at IS NULL)
+		WHEN 'invoice' THEN EXISTS(SELECT 1 FROM invoices x WHERE x.id = sj.entity_id AND x.organization_id = sj.organization_id AND x.deleted_at IS NULL)
+		ELSE true
+	END`
+
+// integrityCheck finds one category of inconsistencies in an organization. Its query returns the
+// entity type, ID and a detail for each issue; its repairs, when the category is safe to repair,
+// fix all of them. Both take the organization ID as $1.
+type integrityCheck struct {
+	category    models.IntegrityCategory
+	description string
+	query       string
+	repairs     []string
+}
+
+var integrityChecks = []integrityCheck{
+	{
+		category:    models.IntegrityOrphanedScheduledJob,
+		description: "Pending scheduled jobs whose entity is gone or whose trigger is inactive or belongs to another organization",
+		query: `
+			SELECT 'scheduled_job', sj.id,
+				CASE
+					WHEN w.organization_id <> sj.organization_id THEN 'trigger belongs to another organization'
+					WHEN NOT COALESCE(t.is_active, false) THEN 'trigger is inactive'
+					ELSE sj.entity_type || ' ' || sj.entity_id || ' no longer exists'
+				END
+			FROM scheduled_jobs sj
+			JOIN workflow_triggers t ON t.id = sj.trigger_id
+			JOIN workflows w ON w.id = t.workflow_id
+			WHERE sj.organization_id = $1 AND sj.status = 'pending'
+				AND (w.organization_id <> sj.organization_id OR NOT COALESCE(t.is_active, false) OR NOT ` + scheduledJobEntityExistsSQL + `)`,
+		repairs: []string{`
+			UPDATE scheduled_jobs sj
+			SET status = 'cancelled', last_error = 'cancelled by integrity repair', processed_at = NOW()
+			FROM workflow_triggers t, workflows w
+			WHERE t.id = sj.trigger_id AND w.id = t.workflow_id
+				AND sj.organization_id = $1 AND sj.status = 'pending'
+				AND (w.organization_id <> sj.organization_id OR NOT COALESCE(t.is_active, false) OR NOT ` + scheduledJobEntityExistsSQL + `)`,
+		},
+	},
+	{
+		category:    models.IntegrityTriggerInvalidState,
+		description: "Active triggers on a state or transition of another workflow",
+		query: `
+			SELECT 'workflow_trigger', t.id,
+				CASE
+					WHEN s.workflow_id <> t.workflow_id THEN 'state ' || s.name || ' belongs to another workflow'
+					WHEN tr.workflow_id <> t.workflow_id THEN 'transition ' || tr.name || ' belongs to another workflow'
+					ELSE 'transition ' || tr.name || ' connects states of another workflow'
+				END
+			FROM workflow_triggers t
+			JOIN workflows w ON w.id = t.workflow_id
+			LEFT JOIN workflow_states s ON s.id = t.state_id
+			LEFT JOIN workflow_transitions tr ON tr.id = t.transition_id
+			LEFT JOIN workflow_states fs ON fs.id = tr.from_state_id
+			LEFT JOIN workflow_states ts ON ts.id = tr.to_state_id
+			WHERE w.organization_id = $1 AND t.is_active = true
+				AND (s.workflow_id <> t.workflow_id OR tr.workflow_id <> t.workflow_id
+					OR fs.workflow_id <> tr.workflow_id OR ts.workflow_id <> tr.workflow_id)`,
+		repairs: []string{`
+			UPDATE workflow_triggers t
+			SET is_active = false
+			FROM workflows w
+			WHERE w.id = t.workflow_id AND w.organization_id = $1 AND t.is_active = true
+				AND (
+					EXISTS(SELECT 1 FROM workflow_states s WHERE s.id = t.state_id AND s.workflow_id <> t.workflow_id)
+					OR EXISTS(
+						SELECT 1 FROM workflow_transitions tr
+						JOIN workflow_states fs ON fs.id = tr.from_state_id
+						JOIN workflow_states ts ON ts.id = tr.to_state_id
+						WHERE tr.id = t.transition_id
+							AND (tr.workflow_id <> t.workflow_id OR fs.workflow_id <> tr.workflow_id OR ts.workflow_id <> tr.workflow_id)
+					)
+				)`,
+		},
+	},
+	{
+		category:    models.IntegrityPaymentWithoutParent,
+		description: "Open payments on deleted sessions or projects, or on another organization's project",
+		query: `
+			SELECT 'session_payment', sp.id, 'session ' || se.id || ' was deleted'
+			FROM session_payments sp
+			JOIN sessions se ON se.id = sp.session_id
+			WHERE se.organization_id = $1 AND se.deleted_at IS NOT NULL AND sp.payment_status <> 'paid'
+			UNION ALL
+			SELECT 'payment', p.id,
+				CASE WHEN pr.organization_id <> p.organization_id THEN 'project ' || pr.id || ' belongs to another organization'
+					ELSE 'project ' || pr.id || ' was deleted' END
+			FROM payments p
+			JOIN projects pr ON pr.id = p.project_id
+			WHERE p.organization_id = $1 AND p.deleted_at IS NULL AND p.status IN ('pending', 'overdue')
+				AND (pr.deleted_at IS NOT NULL OR pr.organization_id <> p.organization_id)`,
+	},
+	{
+		category:    models.IntegrityMessageWrongOrganization,
+		description: "WhatsApp and email messages linked to a session of another organization",
+		query: `
+			SELECT 'whatsapp_message', m.id, 'linked to session ' || se.id || ' of another organization'
+			FROM whatsapp_messages m
+			JOIN sessions se ON se.id = m.session_id
+			WHERE m.organization_id = $1 AND se.organization_id <> m.organization_id
+			UNION ALL
+			SELECT 'email_message', m.id, 'linked to session ' || se.id || ' of another organization'
+			FROM email_messages m
+			JOIN sessions se ON se.id = m.session_id
+			WHERE m.organization_id = $1 AND se.organization_id <> m.organization_id`,
+		repairs: []string{`
+			UPDATE whatsapp_messages m SET session_id = NULL
+			FROM sessions se
+			WHERE se.id = m.session_id AND m.organization_id = $1 AND se.organization_id <> m.organization_id`, `
+			UPDATE email_messages m SET session_id = NULL
+			FROM sessions se
+			WHERE se.id = m.session_id AND m.organization_id = $1 AND se.organization_id <> m.organization_id`,
+		},
+	},
+	{
+		category:    models.IntegrityNegativeAmount,
+		description: "Session payments, payments and budgets with a negative amount",
+		query: `
+			SELECT 'session_payment', sp.id, 'amount is ' || (sp.amount_cents / 100.0)::text
+			FROM session_payments sp
+			JOIN sessions se ON se.id = sp.session_id
+			WHERE se.organization_id = $1 AND sp.amount_cents < 0
+			UNION ALL
+			SELECT 'payment', p.id, 'amount is ' || p.amount::text
+			FROM payments p
+			WHERE p.organization_id = $1 AND p.deleted_at IS NULL AND p.amount < 0
+			UNION ALL
+			SELECT 'budget', b.id, 'total is ' || b.total::text
+			FROM budgets b
+			WHERE b.organization_id = $1 AND b.deleted_at IS NULL AND b.total < 0`,
+	},
+}
+
+// findIntegrityCheck returns the check of a category
+func findIntegrityCheck(category models.IntegrityCategory) (integrityCheck, bool) {
+	for _, check := range integrityChecks {
+		if check.category == category {
+			return check, true
+		}
+	}
+	return integrityCheck{}, false
+}
+
+// repairableIntegrityCategories resolves the categories to repair: all the safe ones when none
+// are given. Categories that are unknown or not safe to repair automatically are refused.
+func repairableIntegrityCategories(categories []models.IntegrityCategory) ([]models.IntegrityCategory, error) {
+	if len(categories) == 0 {
+		for _, check := range integrityChecks {
+			if len(check.repairs) > 0 {
+				categories = append(categories, check.category)
+			}
+		}
+		return categories, nil
+	}
+	seen := make(map[models.IntegrityCategory]bool)
+	var result []models.IntegrityCategory
+	for _, category := range categories {
+		check, ok := findIntegrityCheck(category)
+		if !ok {
+			return nil, errors.New("invalid integrity category")
+		}
+		if len(check.repairs) == 0 {
+			return nil, errors.New("this integrity category cannot be repaired automatically")
+		}
+		if !seen[category] {
+			seen[category] = true
+			result = append(result, category)
+		}
+	}
+	return result, nil
+}
+
+// IntegrityService checks an organization's data for inconsistencies the database constraints
+// don't catch, and repairs the categories that are safe to repair
+type IntegrityService struct {
+	db *database.DB
+}
+
+func NewIntegrityService(db *database.DB) *IntegrityService {
+	return &IntegrityService{db: db}
+}
+
+// ensureOrganization checks the organization exists
+func (s *IntegrityService) ensureOrganization(ctx context.Context, orgID uuid.UUID) error {
+	var exists bool
+	err := s.db.Pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM organizations WHERE id = $1)`, orgID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check organization: %w", err)
+	}
+	if !exists {
+		return errors.New("organization not found")
+	}
+	return nil
+}
+
+// Check runs every integrity check on the organization
+func (s *IntegrityService) Check(ctx context.Context, orgID uuid.UUID) (*models.IntegrityReport, error) {
+	if err := s.ensureOrganization(ctx, orgID); err != nil {
+		return nil, err
+	}
+
+	report := &models.IntegrityReport{
+		OrganizationID: orgID,
+		CheckedAt:      time.Now(),
+		Categories:     []*models.IntegrityCategoryCount{},
+		Issues:         []*models.IntegrityIssue{},
+	}
+	for _, check := range integrityChecks {
+		count := &models.IntegrityCategoryCount{
+			Category:    check.category,
+			Description: check.description,
+			Fixable:     len(check.repairs) > 0,
+		}
+
+		rows, err := s.db.Pool.Query(ctx, check.query, orgID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run integrity check %s: %w", check.category, err)
+		}
+		for rows.Next() {
+			issue := &models.IntegrityIssue{Category: check.category, Fixable: count.Fixable}
+			if err := rows.Scan(&issue.EntityType, &issue.EntityID, &issue.Detail); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan integrity issue: %w", err)
+			}
+			count.Count++
+			if count.Count <= integrityIssuesPerCategory {
+				report.Issues = append(report.Issues, issue)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to run integrity check %s: %w", check.category, err)
+		}
+
+		report.Categories = append(report.Categories, count)
+		report.TotalIssues += count.Count
+	}
+
+	return report, nil
+}
+
+// Repair fixes the issues of the given categories, or of every safe one when none are given, in
+// a single transaction
+func (s *IntegrityService) Repair(ctx context.Context, orgID uuid.UUID, categories []models.IntegrityCategory) (*models.IntegrityRepairResult, error) {
+	categories, err := repairableIntegrityCategories(categories)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureOrganization(ctx, orgID); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result := &models.IntegrityRepairResult{
+		OrganizationID: orgID,
+		Repaired:       make(map[models.IntegrityCategory]int),
+	}
+	for _, category := range categories {
+		check, _ := findIntegrityCheck(category)
+		for _, repair := range check.repairs {
+			tag, err := tx.Exec(ctx, repair, orgID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to repair %s: %w", category, err)
+			}
+			result.Repaired[category] += int(tag.RowsAffected())
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit integrity repair: %w", err)
+	}
+	return result, nil
+}
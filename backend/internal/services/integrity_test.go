@@ -0,0 +1,40 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/controlwise/backend/internal/models"
+)
+
+func TestRepairableIntegrityCategories(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []models.IntegrityCategory
+		want    []models.IntegrityCategory
+		wantErr bool
+	}{
+		{"all safe categories by default", nil, []models.IntegrityCategory{
+			models.IntegrityOrphanedScheduledJob,
+			models.IntegrityTriggerInvalidState,
+			models.IntegrityMessageWrongOrganization,
+		}, false},
+		{"repeated category", []models.IntegrityCategory{
+			models.IntegrityTriggerInvalidState, models.IntegrityTriggerInvalidState,
+		}, []models.IntegrityCategory{models.IntegrityTriggerInvalidState}, false},
+		{"report-only category", []models.IntegrityCategory{models.IntegrityNegativeAmount}, nil, true},
+		{"unknown category", []models.IntegrityCategory{"everything"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repairableIntegrityCategories(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("repairableIntegrityCategories() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("repairableIntegrityCategories() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// InvitationTTL is how long an invite link stays valid after it is sent
+const InvitationTTL = 7 * 24 * time.Hour
+
+// InvitationService handles inviting colleagues into an organization
+type InvitationService struct {
+	db          *database.DB
+	auth        *AuthService
+	email       *EmailService
+	frontendURL string
+}
+
+func NewInvitationService(db *database.DB, auth *AuthService, email *EmailService, frontendURL string) *InvitationService {
+	return &InvitationService{
+		db:          db,
+		auth:        auth,
+		email:       email,
+		frontendURL: frontendURL,
+	}
+}
+
+// AcceptInvitationRequest contains the details the invitee fills in to create their account
+type AcceptInvitationRequest struct {
+	FirstName string  `json:"first_name"`
+	LastName  string  `json:"last_name"`
+	Phone     *string `json:"phone"`
+	Password  string  `json:"password"`
+}
+
+const invitationColumns = `i.id, i.organization_id, i.email, i.role, i.invited_by, i.expires_at, i.sent_count,
+	i.last_sent_at, i.accepted_at, i.accepted_user_id, i.revoked_at, i.created_at, i.updated_at,
+	COALESCE(u.first_name || ' ' || u.last_name, ''), o.name`
+
+const invitationJoins = `FROM user_invitations i
+	JOIN organizations o ON o.id = i.organization_id
+	LEFT JOIN users u ON u.id = i.invited_by`
+
+func scanInvitation(row pgx.Row) (*models.UserInvitation, error) {
+	var inv models.UserInvitation
+	err := row.Scan(&inv.ID, &inv.OrganizationID, &inv.Email, &inv.Role, &inv.InvitedBy, &inv.ExpiresAt, &inv.SentCount,
+		&inv.LastSentAt, &inv.AcceptedAt, &inv.AcceptedUserID, &inv.RevokedAt, &inv.CreatedAt, &inv.UpdatedAt,
+		&inv.InvitedByName, &inv.OrganizationName)
+	if err != nil {
+		return nil, err
+	}
+	inv.SetStatus(time.Now())
+	return &inv, nil
+}
+
+// newInvitationToken returns a random token for the invite link and the hash that is stored
+func newInvitationToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate invitation token: %w", err)
+	}
+	token := hex.EncodeToString(b)
+	return token, hashInvitationToken(token), nil
+}
+
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// List returns the organization's invitations, optionally only those with a status
+func (s *InvitationService) List(ctx context.Context, orgID uuid.UUID, status string) ([]*models.UserInvitation, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT `+invitationColumns+` `+invitationJoins+`
+		WHERE i.organization_id = $1
+		ORDER BY i.created_at DESC
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invitations: %w", err)
+	}
+	defer rows.Close()
+
+	invitations := []*models.UserInvitation{}
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan invitation: %w", err)
+		}
+		if status != "" && string(inv.Status) != status {
+			continue
+		}
+		invitations = append(invitations, inv)
+	}
+
+	return invitations, nil
+}
+
+// GetByID returns an invitation of the organization
+func (s *InvitationService) GetByID(ctx context.Context, id, orgID uuid.UUID) (*models.UserInvitation, error) {
+	inv, err := scanInvitation(s.db.Pool.QueryRow(ctx, `
+		SELECT `+invitationColumns+` `+invitationJoins+`
+		WHERE i.id = $1 AND i.organization_id = $2
+	`, id, orgID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("invitation not found")
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	return inv, nil
+}
+
+// Create invites an email address into the organization with a role and sends the invite link
+func (s *InvitationService) Create(ctx context.Context, orgID, invitedBy uuid.UUID, email string, role models.Role) (*models.UserInvitation, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if _, err := mail.ParseAddress(email); err != nil {
+		return nil, errors.New("a valid email is required")
+	}
+	if !role.IsStaff() {
+		return nil, errors.New("role must be one of admin, manager, employee, accountant")
+	}
+
+	// Login is by email alone, so emails must be unique across organizations
+	var userExists, inviteExists bool
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT
+			EXISTS(SELECT 1 FROM users WHERE LOWER(email) = $1 AND deleted_at IS NULL),
+			EXISTS(
+				SELECT 1 FROM user_invitations
+				WHERE organization_id = $2 AND LOWER(email) = $1 AND accepted_at IS NULL AND revoked_at IS NULL
+			)
+	`, email, orgID).Scan(&userExists, &inviteExists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check email: %w", err)
+	}
+	if userExists {
+		return nil, errors.New("a user with this email already exists")
+	}
+	if inviteExists {
+		return nil, errors.New("an invitation for this email is already open, resend or revoke it instead")
+	}
+
+	token, tokenHash, err := newInvitationToken()
+	if err != nil {
+		return nil, err
+	}
+
+	id := uuid.New()
+	_, err = s.db.Pool.Exec(ctx, `
+		INSERT INTO user_invitations (id, organization_id, email, role, token_hash, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, id, orgID, email, role, tokenHash, invitedBy, time.Now().Add(InvitationTTL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	inv, err := s.GetByID(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+	s.sendInvite(inv, token)
+
+	return inv, nil
+}
+
+// Resend issues a new invite link with a fresh expiry; the previous link stops working
+func (s *InvitationService) Resend(ctx context.Context, id, orgID uuid.UUID) (*models.UserInvitation, error) {
+	token, tokenHash, err := newInvitationToken()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE user_invitations
+		SET token_hash = $1, expires_at = $2, sent_count = sent_count + 1, last_sent_at = NOW()
+		WHERE id = $3 AND organization_id = $4 AND accepted_at IS NULL AND revoked_at IS NULL
+	`, tokenHash, time.Now().Add(InvitationTTL), id, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resend invitation: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, errors.New("invitation not found or no longer open")
+	}
+
+	inv, err := s.GetByID(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+	s.sendInvite(inv, token)
+
+	return inv, nil
+}
+
+// Revoke cancels an open invitation so its link can no longer be used
+func (s *InvitationService) Revoke(ctx context.Context, id, orgID uuid.UUID) (*models.UserInvitation, error) {
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE user_invitations SET revoked_at = NOW()
+		WHERE id = $1 AND organization_id = $2 AND accepted_at IS NULL AND revoked_at IS NULL
+	`, id, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke invitation: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, errors.New("invitation not found or no longer open")
+	}
+
+	return s.GetByID(ctx, id, orgID)
+}
+
+// GetByToken returns an open invitation for the accept page
+func (s *InvitationService) GetByToken(ctx context.Context, token string) (*models.UserInvitation, error) {
+	inv, err := scanInvitation(s.db.Pool.QueryRow(ctx, `
+		SELECT `+invitationColumns+` `+invitationJoins+`
+		WHERE i.token_hash = $1
+	`, hashInvitationToken(token)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("invitation not found")
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	if inv.Status != models.InvitationStatusPending {
+		return nil, fmt.Errorf("invitation is %s", inv.Status)
+	}
+	return inv, nil
+}
+
+// Accept creates the invited user in the organization with the chosen password and logs them in
+func (s *InvitationService) Accept(ctx context.Context, token string, req AcceptInvitationRequest) (*AuthResponse, error) {
+	if strings.TrimSpace(req.FirstName) == "" || strings.TrimSpace(req.LastName) == "" {
+		return nil, errors.New("first_name and last_name are required")
+	}
+	if len(req.Password) < minPasswordLength {
+		return nil, fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, errors.New("failed to hash password")
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var inv models.UserInvitation
+	err = tx.QueryRow(ctx, `
+		SELECT id, organization_id, email, role, expires_at, accepted_at, revoked_at
+		FROM user_invitations
+		WHERE token_hash = $1
+		FOR UPDATE
+	`, hashInvitationToken(token)).Scan(&inv.ID, &inv.OrganizationID, &inv.Email, &inv.Role, &inv.ExpiresAt,
+		&inv.AcceptedAt, &inv.RevokedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("invitation not found")
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	inv.SetStatus(time.Now())
+	if inv.Status != models.InvitationStatusPending {
+		return nil, fmt.Errorf("invitation is %s", inv.Status)
+	}
+
+	var exists bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM users WHERE LOWER(email) = LOWER($1) AND deleted_at IS NULL)
+	`, inv.Email).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check email: %w", err)
+	}
+	if exists {
+		return nil, errors.New("a user with this email already exists")
+	}
+
+	user, err := scanUser(tx.QueryRow(ctx, `
+		INSERT INTO users (id, organization_id, email, password_hash, first_name, last_name, phone, role, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, true)
+		RETURNING `+userColumns,
+		uuid.New(), inv.OrganizationID, inv.Email, string(hash), strings.TrimSpace(req.FirstName),
+		strings.TrimSpace(req.LastName), req.Phone, inv.Role))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE user_invitations SET accepted_at = NOW(), accepted_user_id = $1 WHERE id = $2
+	`, user.ID, inv.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to accept invitation: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	token, err = s.auth.generateToken(user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthResponse{Token: token, User: user}, nil
+}
+
+// sendInvite emails the invite link; failures are logged so the admin can resend
+func (s *InvitationService) sendInvite(inv *models.UserInvitation, token string) {
+	if s.email == nil {
+		return
+	}
+	link := fmt.Sprintf("%s/accept-invite?token=%s", s.frontendURL, url.QueryEscape(token))
+	if err := s.email.SendUserInvite(inv.Email, inv.InvitedByName, inv.OrganizationName, link, inv.ExpiresAt); err != nil {
+		fmt.Printf("Warning: failed to send invitation email to %s: %v\n", inv.Email, err)
+	}
+}
@@ -0,0 +1,712 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// InvoiceService issues invoices for session and project payments, and the credit notes that
+// cancel them
+type InvoiceService struct {
+	db       *database.DB
+	storage  *StorageService
+	workflow *WorkflowService
+}
+
+func NewInvoiceService(db *database.DB, storage *StorageService) *InvoiceService {
+	return &InvoiceService{db: db, storage: storage}
+}
+
+// SetWorkflowService sets the workflow service for triggering workflow actions
+func (s *InvoiceService) SetWorkflowService(ws *WorkflowService) {
+	s.workflow = ws
+}
+
+// InvoiceOptions configures an invoice created from a payment. Unset values come from the
+// invoices module settings.
+type InvoiceOptions struct {
+	VATRate            *decimal.Decimal `json:"vat_rate"` // percentage, e.g. 23
+	VATExemptionReason *string          `json:"vat_exemption_reason"`
+	DueDays            *int             `json:"due_days"`
+	Notes              *string          `json:"notes"`
+	CreatedBy          *uuid.UUID       `json:"-"`
+}
+
+// InvoiceFilters contains filters for listing invoices
+type InvoiceFilters struct {
+	Status       string
+	DocumentType string
+	ClientID     *uuid.UUID
+	From         *time.Time // issue date
+	To           *time.Time
+	Limit        int
+	Offset       int
+}
+
+// invoiceCustomer is the client an invoice is addressed to
+type invoiceCustomer struct {
+	id                    uuid.UUID
+	name                  string
+	taxID, address, email *string
+}
+
+// invoiceSettings reads the invoices module settings, falling back to their defaults
+func (s *InvoiceService) invoiceSettings(ctx context.Context, orgID uuid.UUID) (decimal.Decimal, *string, int, error) {
+	var rate decimal.Decimal
+	var reason string
+	var dueDays int
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT
+			COALESCE((config->>'default_vat_rate')::numeric, $3::numeric),
+			COALESCE(config->>'vat_exemption_reason', ''),
+			COALESCE((config->>'payment_terms_days')::int, 30)
+		FROM (SELECT $1::uuid AS organization_id) o
+		LEFT JOIN organization_modules om ON om.organization_id = o.organization_id AND om.module_name = $2
+	`, orgID, models.ModuleInvoices, models.DefaultVATRate).Scan(&rate, &reason, &dueDays)
+	if err != nil {
+		return decimal.Zero, nil, 0, fmt.Errorf("failed to get invoice settings: %w", err)
+	}
+	if reason == "" {
+		return rate, nil, dueDays, nil
+	}
+	return rate, &reason, dueDays, nil
+}
+
+// resolveOptions fills the options left unset with the organization's settings and checks the VAT
+func (s *InvoiceService) resolveOptions(ctx context.Context, orgID uuid.UUID, opts InvoiceOptions) (InvoiceOptions, error) {
+	rate, reason, dueDays, err := s.invoiceSettings(ctx, orgID)
+	if err != nil {
+		return opts, err
+	}
+	if opts.VATRate == nil {
+		opts.VATRate = &rate
+		if opts.VATExemptionReason == nil {
+			opts.VATExemptionReason = reason
+		}
+	}
+	if opts.DueDays == nil {
+		opts.DueDays = &dueDays
+	}
+
+	if opts.VATRate.IsNegative() || opts.VATRate.GreaterThan(decimal.NewFromInt(100)) {
+		return opts, errors.New("invalid VAT rate")
+	}
+	if !opts.VATRate.IsZero() {
+		opts.VATExemptionReason = nil
+	} else if opts.VATExemptionReason == nil || *opts.VATExemptionReason == "" {
+		return opts, errors.New("a VAT exemption reason is required for a 0% VAT rate")
+	}
+	if *opts.DueDays < 0 {
+		return opts, errors.New("invalid due days")
+	}
+	return opts, nil
+}
+
+// newInvoiceLine builds a line for an amount the customer pays, VAT included
+func newInvoiceLine(position int, description string, gross, rate decimal.Decimal, exemptionReason *string) models.InvoiceLine {
+	net := gross.Div(decimal.NewFromInt(1).Add(rate.Div(decimal.NewFromInt(100)))).Round(2)
+	return models.InvoiceLine{
+		ID:                 uuid.New(),
+		Position:           position,
+		Description:        description,
+		Quantity:           decimal.NewFromInt(1),
+		UnitPrice:          net,
+		VATRate:            rate,
+		VATExemptionReason: exemptionReason,
+		Net:                net,
+		VAT:                gross.Sub(net),
+		Total:              gross,
+	}
+}
+
+// CreateFromSessionPayment creates a draft invoice for a session payment, addressed to the
+// patient's client
+func (s *InvoiceService) CreateFromSessionPayment(ctx context.Context, orgID, sessionPaymentID uuid.UUID, opts InvoiceOptions) (*models.Invoice, error) {
+	var amountCents int
+	var kind models.SessionPaymentKind
+	var scheduledAt time.Time
+	var therapistName string
+	var customer invoiceCustomer
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT sp.amount_cents, sp.kind, s.scheduled_at, t.name,
+			c.id, c.name, c.tax_id, c.address, c.email
+		FROM session_payments sp
+		JOIN sessions s ON s.id = sp.session_id
+		JOIN therapists t ON t.id = s.therapist_id
+		JOIN patients p ON p.id = s.patient_id
+		JOIN clients c ON c.id = p.client_id
+		WHERE sp.id = $1 AND s.organization_id = $2 AND s.deleted_at IS NULL
+	`, sessionPaymentID, orgID).Scan(&amountCents, &kind, &scheduledAt, &therapistName,
+		&customer.id, &customer.name, &customer.taxID, &customer.address, &customer.email)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("session payment not found")
+		}
+		return nil, fmt.Errorf("failed to get session payment: %w", err)
+	}
+	if amountCents <= 0 {
+		return nil, errors.New("nothing to invoice")
+	}
+
+	description := fmt.Sprintf("Sessão de %s com %s", scheduledAt.Format("02/01/2006 15:04"), therapistName)
+	if kind == models.SessionPaymentKindCancellationFee {
+		description = fmt.Sprintf("Taxa de cancelamento da sessão de %s", scheduledAt.Format("02/01/2006 15:04"))
+	}
+
+	return s.createDraft(ctx, orgID, models.InvoiceSourceSessionPayment, sessionPaymentID, customer,
+		description, decimal.New(int64(amountCents), -2), opts)
+}
+
+// CreateFromProjectPayment creates a draft invoice for a project payment, addressed to the client
+// of the project's budget
+func (s *InvoiceService) CreateFromProjectPayment(ctx context.Context, orgID, paymentID uuid.UUID, opts InvoiceOptions) (*models.Invoice, error) {
+	var amount decimal.Decimal
+	var reference *string
+	var projectNumber, projectTitle string
+	var customer invoiceCustomer
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT pay.amount, pay.reference, p.project_number, p.title,
+			c.id, c.name, c.tax_id, c.address, c.email
+		FROM payments pay
+		JOIN projects p ON p.id = pay.project_id
+		JOIN budgets b ON b.id = p.budget_id
+		JOIN worksheets w ON w.id = b.worksheet_id
+		JOIN clients c ON c.id = w.client_id
+		WHERE pay.id = $1 AND pay.organization_id = $2 AND pay.deleted_at IS NULL
+	`, paymentID, orgID).Scan(&amount, &reference, &projectNumber, &projectTitle,
+		&customer.id, &customer.name, &customer.taxID, &customer.address, &customer.email)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("payment not found")
+		}
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+	if !amount.IsPositive() {
+		return nil, errors.New("nothing to invoice")
+	}
+
+	description := fmt.Sprintf("Projeto %s - %s", projectNumber, projectTitle)
+	if reference != nil && *reference != "" {
+		description += fmt.Sprintf(" (%s)", *reference)
+	}
+
+	return s.createDraft(ctx, orgID, models.InvoiceSourcePayment, paymentID, customer, description, amount, opts)
+}
+
+func (s *InvoiceService) createDraft(ctx context.Context, orgID uuid.UUID, sourceType models.InvoiceSourceType, sourceID uuid.UUID,
+	customer invoiceCustomer, description string, gross decimal.Decimal, opts InvoiceOptions) (*models.Invoice, error) {
+	opts, err := s.resolveOptions(ctx, orgID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	line := newInvoiceLine(1, description, gross, *opts.VATRate, opts.VATExemptionReason)
+	dueDate := truncateToDate(time.Now().AddDate(0, 0, *opts.DueDays))
+	invoice := &models.Invoice{
+		ID:              uuid.New(),
+		OrganizationID:  orgID,
+		DocumentType:    models.InvoiceDocumentInvoice,
+		Status:          models.InvoiceStatusDraft,
+		Series:          models.InvoiceSeries[models.InvoiceDocumentInvoice],
+		SourceType:      &sourceType,
+		SourceID:        &sourceID,
+		ClientID:        &customer.id,
+		CustomerName:    customer.name,
+		CustomerTaxID:   customer.taxID,
+		CustomerAddress: customer.address,
+		CustomerEmail:   customer.email,
+		DueDate:         &dueDate,
+		Subtotal:        line.Net,
+		VATTotal:        line.VAT,
+		Total:           line.Total,
+		Notes:           opts.Notes,
+		CreatedBy:       opts.CreatedBy,
+		Lines:           []models.InvoiceLine{line},
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var invoiced bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM invoices
+			WHERE source_type = $1 AND source_id = $2 AND document_type = 'invoice'
+				AND status <> 'void' AND deleted_at IS NULL
+		)
+	`, sourceType, sourceID).Scan(&invoiced)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing invoice: %w", err)
+	}
+	if invoiced {
+		return nil, errors.New("payment is already invoiced")
+	}
+
+	if err := insertInvoice(ctx, tx, invoice); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if s.workflow != nil {
+		if err := s.workflow.OnInvoiceStateChange(ctx, orgID, invoice.ID, "", string(models.InvoiceStatusDraft)); err != nil {
+			fmt.Printf("Failed to trigger workflow: %v\n", err)
+		}
+	}
+
+	return s.GetByID(ctx, invoice.ID, orgID)
+}
+
+// insertInvoice saves a new invoice and its lines
+func insertInvoice(ctx context.Context, tx pgx.Tx, invoice *models.Invoice) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO invoices (
+			id, organization_id, document_type, status, series, source_type, source_id, credited_invoice_id,
+			client_id, customer_name, customer_tax_id, customer_address, customer_email,
+			due_date, subtotal, vat_total, total, notes, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+	`, invoice.ID, invoice.OrganizationID, invoice.DocumentType, invoice.Status, invoice.Series,
+		invoice.SourceType, invoice.SourceID, invoice.CreditedInvoiceID,
+		invoice.ClientID, invoice.CustomerName, invoice.CustomerTaxID, invoice.CustomerAddress, invoice.CustomerEmail,
+		invoice.DueDate, invoice.Subtotal, invoice.VATTotal, invoice.Total, invoice.Notes, invoice.CreatedBy)
+	if err != nil {
+		return fmt.Errorf("failed to create invoice: %w", err)
+	}
+
+	for _, line := range invoice.Lines {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO invoice_lines (
+				id, invoice_id, position, description, quantity, unit_price, vat_rate, vat_exemption_reason, net, vat, total
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		`, uuid.New(), invoice.ID, line.Position, line.Description, line.Quantity, line.UnitPrice,
+			line.VATRate, line.VATExemptionReason, line.Net, line.VAT, line.Total)
+		if err != nil {
+			return fmt.Errorf("failed to create invoice line: %w", err)
+		}
+	}
+	return nil
+}
+
+// assignInvoiceNumber gives an issued document the next number of its series for the year. The
+// sequence row stays locked until the transaction ends, so numbers have no gaps or repeats.
+func assignInvoiceNumber(ctx context.Context, tx pgx.Tx, orgID, id uuid.UUID, documentType models.InvoiceDocumentType) (string, error) {
+	year := time.Now().Year()
+	var number int
+	err := tx.QueryRow(ctx, `
+		INSERT INTO invoice_sequences (organization_id, document_type, year, last_number)
+		VALUES ($1, $2, $3, 1)
+		ON CONFLICT (organization_id, document_type, year)
+		DO UPDATE SET last_number = invoice_sequences.last_number + 1
+		RETURNING last_number
+	`, orgID, documentType, year).Scan(&number)
+	if err != nil {
+		return "", fmt.Errorf("failed to get next invoice number: %w", err)
+	}
+
+	invoiceNumber := fmt.Sprintf("%s %d/%d", models.InvoiceSeries[documentType], year, number)
+	_, err = tx.Exec(ctx, `
+		UPDATE invoices
+		SET status = 'issued', year = $1, number = $2, invoice_number = $3,
+			issue_date = CURRENT_DATE, due_date = GREATEST(due_date, CURRENT_DATE), issued_at = NOW()
+		WHERE id = $4
+	`, year, number, invoiceNumber, id)
+	if err != nil {
+		return "", fmt.Errorf("failed to issue invoice: %w", err)
+	}
+	return invoiceNumber, nil
+}
+
+const invoiceColumns = `
+	id, organization_id, document_type, status, series, year, number, invoice_number,
+	source_type, source_id, credited_invoice_id, client_id, customer_name, customer_tax_id,
+	customer_address, customer_email, issue_date, due_date, subtotal, vat_total, total,
+	notes, void_reason, issued_at, paid_at, voided_at, created_by, created_at, updated_at`
+
+func scanInvoice(row pgx.Row) (*models.Invoice, error) {
+	var i models.Invoice
+	err := row.Scan(
+		&i.ID, &i.OrganizationID, &i.DocumentType, &i.Status, &i.Series, &i.Year, &i.Number, &i.InvoiceNumber,
+		&i.SourceType, &i.SourceID, &i.CreditedInvoiceID, &i.ClientID, &i.CustomerName, &i.CustomerTaxID,
+		&i.CustomerAddress, &i.CustomerEmail, &i.IssueDate, &i.DueDate, &i.Subtotal, &i.VATTotal, &i.Total,
+		&i.Notes, &i.VoidReason, &i.IssuedAt, &i.PaidAt, &i.VoidedAt, &i.CreatedBy, &i.CreatedAt, &i.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+// List returns the organization's invoices and credit notes, newest first
+func (s *InvoiceService) List(ctx context.Context, orgID uuid.UUID, filters InvoiceFilters) ([]*models.Invoice, int, error) {
+	where := "WHERE organization_id = $1 AND deleted_at IS NULL"
+	args := []interface{}{orgID}
+
+	if filters.Status != "" {
+		args = append(args, filters.Status)
+		where += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	if filters.DocumentType != "" {
+		args = append(args, filters.DocumentType)
+		where += fmt.Sprintf(" AND document_type = $%d", len(args))
+	}
+	if filters.ClientID != nil {
+		args = append(args, *filters.ClientID)
+		where += fmt.Sprintf(" AND client_id = $%d", len(args))
+	}
+	if filters.From != nil {
+		args = append(args, *filters.From)
+		where += fmt.Sprintf(" AND issue_date >= $%d", len(args))
+	}
+	if filters.To != nil {
+		args = append(args, *filters.To)
+		where += fmt.Sprintf(" AND issue_date <= $%d", len(args))
+	}
+
+	var total int
+	if err := s.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM invoices `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count invoices: %w", err)
+	}
+
+	if filters.Limit <= 0 {
+		filters.Limit = 50
+	}
+	args = append(args, filters.Limit, filters.Offset)
+	rows, err := s.db.Pool.Query(ctx, `SELECT `+invoiceColumns+` FROM invoices `+where+fmt.Sprintf(`
+		ORDER BY COALESCE(issue_date, created_at::date) DESC, number DESC NULLS FIRST, created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query invoices: %w", err)
+	}
+	defer rows.Close()
+
+	invoices := []*models.Invoice{}
+	for rows.Next() {
+		invoice, err := scanInvoice(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan invoice: %w", err)
+		}
+		invoices = append(invoices, invoice)
+	}
+	return invoices, total, rows.Err()
+}
+
+// GetByID returns an invoice with its lines and VAT breakdown
+func (s *InvoiceService) GetByID(ctx context.Context, id, orgID uuid.UUID) (*models.Invoice, error) {
+	invoice, err := scanInvoice(s.db.Pool.QueryRow(ctx, `
+		SELECT `+invoiceColumns+` FROM invoices
+		WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`, id, orgID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("invoice not found")
+		}
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, invoice_id, position, description, quantity, unit_price, vat_rate, vat_exemption_reason, net, vat, total
+		FROM invoice_lines
+		WHERE invoice_id = $1
+		ORDER BY position
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoice lines: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l models.InvoiceLine
+		if err := rows.Scan(&l.ID, &l.InvoiceID, &l.Position, &l.Description, &l.Quantity, &l.UnitPrice,
+			&l.VATRate, &l.VATExemptionReason, &l.Net, &l.VAT, &l.Total); err != nil {
+			return nil, fmt.Errorf("failed to scan invoice line: %w", err)
+		}
+		invoice.Lines = append(invoice.Lines, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get invoice lines: %w", err)
+	}
+
+	invoice.VATBreakdown = vatBreakdown(invoice.Lines)
+	return invoice, nil
+}
+
+// vatBreakdown totals the lines per VAT rate and exemption reason, highest rate first
+func vatBreakdown(lines []models.InvoiceLine) []models.VATSummary {
+	summaries := []models.VATSummary{}
+	for _, l := range lines {
+		found := false
+		for i := range summaries {
+			if summaries[i].Rate.Equal(l.VATRate) && derefString(summaries[i].ExemptionReason) == derefString(l.VATExemptionReason) {
+				summaries[i].Base = summaries[i].Base.Add(l.Net)
+				summaries[i].VAT = summaries[i].VAT.Add(l.VAT)
+				found = true
+				break
+			}
+		}
+		if !found {
+			summaries = append(summaries, models.VATSummary{
+				Rate: l.VATRate, ExemptionReason: l.VATExemptionReason, Base: l.Net, VAT: l.VAT,
+			})
+		}
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].Rate.GreaterThan(summaries[j].Rate)
+	})
+	return summaries
+}
+
+// lockInvoice returns the status, type and issue date of an invoice, locked for the rest of the
+// transaction
+func lockInvoice(ctx context.Context, tx pgx.Tx, id, orgID uuid.UUID) (models.InvoiceStatus, models.InvoiceDocumentType, *time.Time, error) {
+	var status models.InvoiceStatus
+	var documentType models.InvoiceDocumentType
+	var issueDate *time.Time
+	err := tx.QueryRow(ctx, `
+		SELECT status, document_type, issue_date FROM invoices
+		WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+		FOR UPDATE
+	`, id, orgID).Scan(&status, &documentType, &issueDate)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", "", nil, errors.New("invoice not found")
+		}
+		return "", "", nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+	return status, documentType, issueDate, nil
+}
+
+// invoicePeriodDates returns the dates whose months a change to an invoice made today touches:
+// today's and, once issued, the invoice's issue date
+func invoicePeriodDates(issueDate *time.Time) []time.Time {
+	dates := []time.Time{time.Now()}
+	if issueDate != nil {
+		dates = append(dates, *issueDate)
+	}
+	return dates
+}
+
+// Issue gives a draft invoice its legal number and date. Issued invoices can no longer be
+// changed or deleted; they are voided or credited instead.
+func (s *InvoiceService) Issue(ctx context.Context, id, orgID uuid.UUID, changedBy *uuid.UUID) (*models.Invoice, error) {
+	err := s.changeStatus(ctx, id, orgID, models.InvoiceStatusIssued, changedBy, func(tx pgx.Tx, status models.InvoiceStatus, documentType models.InvoiceDocumentType) error {
+		if status != models.InvoiceStatusDraft {
+			return errors.New("only draft invoices can be issued")
+		}
+		_, err := assignInvoiceNumber(ctx, tx, orgID, id, documentType)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetByID(ctx, id, orgID)
+}
+
+// MarkPaid records that an issued invoice was paid
+func (s *InvoiceService) MarkPaid(ctx context.Context, id, orgID uuid.UUID, changedBy *uuid.UUID) (*models.Invoice, error) {
+	err := s.changeStatus(ctx, id, orgID, models.InvoiceStatusPaid, changedBy, func(tx pgx.Tx, status models.InvoiceStatus, documentType models.InvoiceDocumentType) error {
+		if documentType != models.InvoiceDocumentInvoice || status != models.InvoiceStatusIssued {
+			return errors.New("only issued invoices can be marked as paid")
+		}
+		_, err := tx.Exec(ctx, `UPDATE invoices SET status = 'paid', paid_at = NOW() WHERE id = $1`, id)
+		if err != nil {
+			return fmt.Errorf("failed to update invoice: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetByID(ctx, id, orgID)
+}
+
+// Void cancels an issued document that was not paid. Its number stays used.
+func (s *InvoiceService) Void(ctx context.Context, id, orgID uuid.UUID, reason string, changedBy *uuid.UUID) (*models.Invoice, error) {
+	if reason == "" {
+		return nil, errors.New("a reason is required to void an invoice")
+	}
+	err := s.changeStatus(ctx, id, orgID, models.InvoiceStatusVoid, changedBy, func(tx pgx.Tx, status models.InvoiceStatus, documentType models.InvoiceDocumentType) error {
+		if status != models.InvoiceStatusIssued {
+			return errors.New("only issued invoices can be voided")
+		}
+		var credited bool
+		err := tx.QueryRow(ctx, `
+			SELECT EXISTS(SELECT 1 FROM invoices WHERE credited_invoice_id = $1 AND status <> 'void' AND deleted_at IS NULL)
+		`, id).Scan(&credited)
+		if err != nil {
+			return fmt.Errorf("failed to check credit notes: %w", err)
+		}
+		if credited {
+			return errors.New("invoice has a credit note")
+		}
+		_, err = tx.Exec(ctx, `UPDATE invoices SET status = 'void', void_reason = $1, voided_at = NOW() WHERE id = $2`, reason, id)
+		if err != nil {
+			return fmt.Errorf("failed to update invoice: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetByID(ctx, id, orgID)
+}
+
+// changeStatus runs update with the invoice locked and its financial periods open, then records
+// the transition and fires the invoice workflow
+func (s *InvoiceService) changeStatus(ctx context.Context, id, orgID uuid.UUID, to models.InvoiceStatus, changedBy *uuid.UUID,
+	update func(tx pgx.Tx, status models.InvoiceStatus, documentType models.InvoiceDocumentType) error) error {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	from, documentType, issueDate, err := lockInvoice(ctx, tx, id, orgID)
+	if err != nil {
+		return err
+	}
+	if err := checkPeriodsOpen(ctx, tx, orgID, invoicePeriodDates(issueDate)...); err != nil {
+		return err
+	}
+	if err := update(tx, from, documentType); err != nil {
+		return err
+	}
+
+	var transition *TransitionInputs
+	if s.workflow != nil {
+		transition, err = s.workflow.PrepareTransition(ctx, orgID, models.WorkflowModuleInvoices, models.WorkflowEntityInvoice,
+			string(from), string(to), nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// Trigger workflow (non-blocking)
+	if s.workflow != nil {
+		if err := s.workflow.RecordTransition(ctx, orgID, "invoice", id, transition, changedBy); err != nil {
+			fmt.Printf("Failed to record transition: %v\n", err)
+		}
+		if err := s.workflow.OnInvoiceStateChange(ctx, orgID, id, string(from), string(to)); err != nil {
+			fmt.Printf("Failed to trigger workflow: %v\n", err)
+		}
+	}
+	return nil
+}
+
+// CreateCreditNote issues a credit note cancelling the whole of an issued or paid invoice
+func (s *InvoiceService) CreateCreditNote(ctx context.Context, id, orgID uuid.UUID, reason string, createdBy *uuid.UUID) (*models.Invoice, error) {
+	if reason == "" {
+		return nil, errors.New("a reason is required for a credit note")
+	}
+	original, err := s.GetByID(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	status, documentType, issueDate, err := lockInvoice(ctx, tx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+	// The credit note is dated today and cancels the invoice in its own month
+	if err := checkPeriodsOpen(ctx, tx, orgID, invoicePeriodDates(issueDate)...); err != nil {
+		return nil, err
+	}
+	if documentType != models.InvoiceDocumentInvoice || (status != models.InvoiceStatusIssued && status != models.InvoiceStatusPaid) {
+		return nil, errors.New("only issued or paid invoices can be credited")
+	}
+	var credited bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM invoices WHERE credited_invoice_id = $1 AND status <> 'void' AND deleted_at IS NULL)
+	`, id).Scan(&credited)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check credit notes: %w", err)
+	}
+	if credited {
+		return nil, errors.New("invoice already has a credit note")
+	}
+
+	note := &models.Invoice{
+		ID:                uuid.New(),
+		OrganizationID:    orgID,
+		DocumentType:      models.InvoiceDocumentCreditNote,
+		Status:            models.InvoiceStatusDraft,
+		Series:            models.InvoiceSeries[models.InvoiceDocumentCreditNote],
+		CreditedInvoiceID: &original.ID,
+		ClientID:          original.ClientID,
+		CustomerName:      original.CustomerName,
+		CustomerTaxID:     original.CustomerTaxID,
+		CustomerAddress:   original.CustomerAddress,
+		CustomerEmail:     original.CustomerEmail,
+		DueDate:           original.DueDate,
+		Subtotal:          original.Subtotal,
+		VATTotal:          original.VATTotal,
+		Total:             original.Total,
+		Notes:             &reason,
+		CreatedBy:         createdBy,
+		Lines:             original.Lines,
+	}
+	if err := insertInvoice(ctx, tx, note); err != nil {
+		return nil, err
+	}
+	if _, err := assignInvoiceNumber(ctx, tx, orgID, note.ID, note.DocumentType); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if s.workflow != nil {
+		if err := s.workflow.OnInvoiceStateChange(ctx, orgID, note.ID, "", string(models.InvoiceStatusIssued)); err != nil {
+			fmt.Printf("Failed to trigger workflow: %v\n", err)
+		}
+	}
+
+	return s.GetByID(ctx, note.ID, orgID)
+}
+
+// Delete soft deletes a draft invoice. Issued invoices are voided or credited instead.
+func (s *InvoiceService) Delete(ctx context.Context, id, orgID uuid.UUID) error {
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE invoices SET deleted_at = NOW()
+		WHERE id = $1 AND organization_id = $2 AND status = 'draft' AND deleted_at IS NULL
+	`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to delete invoice: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		if _, err := s.GetByID(ctx, id, orgID); err != nil {
+			return err
+		}
+		return errors.New("only draft invoices can be deleted")
+	}
+
+	if s.workflow != nil {
+		if err := s.workflow.cancelPendingJobsForEntity(ctx, "invoice", id); err != nil {
+			fmt.Printf("Failed to cancel pending jobs: %v\n", err)
+		}
+	}
+	return nil
+}
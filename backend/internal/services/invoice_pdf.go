@@ -0,0 +1,342 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/pdf"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// GetPDF returns a download link to the invoice's PDF. The PDF is generated when missing, when
+// regenerate is set, or when the invoice or the organization changed since it was built.
+func (s *InvoiceService) GetPDF(ctx context.Context, id, orgID uuid.UUID, regenerate bool) (*models.InvoicePDF, error) {
+	if s.storage == nil {
+		return nil, errors.New("storage is not configured")
+	}
+
+	var fileURL *string
+	var generatedAt *time.Time
+	var stale bool
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT i.pdf_url, i.pdf_generated_at,
+			i.pdf_generated_at IS NULL OR i.pdf_generated_at < GREATEST(i.updated_at, o.updated_at)
+		FROM invoices i
+		JOIN organizations o ON o.id = i.organization_id
+		WHERE i.id = $1 AND i.organization_id = $2 AND i.deleted_at IS NULL
+	`, id, orgID).Scan(&fileURL, &generatedAt, &stale)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("invoice not found")
+		}
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+
+	invoice, err := s.GetByID(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	if fileURL == nil || stale || regenerate {
+		url, at, err := s.generatePDF(ctx, invoice)
+		if err != nil {
+			return nil, err
+		}
+		fileURL, generatedAt = &url, &at
+	}
+
+	link, err := s.storage.DownloadURL(ctx, *fileURL, exportDownloadExpiry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign download link: %w", err)
+	}
+
+	return &models.InvoicePDF{
+		InvoiceID:   id,
+		FileName:    invoicePDFFileName(invoice),
+		DownloadURL: link,
+		GeneratedAt: *generatedAt,
+		ExpiresAt:   time.Now().Add(exportDownloadExpiry),
+	}, nil
+}
+
+// generatePDF renders and stores the invoice PDF, replacing the previous file
+func (s *InvoiceService) generatePDF(ctx context.Context, invoice *models.Invoice) (string, time.Time, error) {
+	d := &invoiceDocument{invoice: invoice}
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT name, COALESCE(address, ''), COALESCE(tax_id, ''), email, COALESCE(phone, ''), logo
+		FROM organizations WHERE id = $1
+	`, invoice.OrganizationID).Scan(&d.org.name, &d.org.address, &d.org.taxID, &d.org.email, &d.org.phone, &d.org.logo)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to get organization: %w", err)
+	}
+	if invoice.CreditedInvoiceID != nil {
+		err := s.db.Pool.QueryRow(ctx, `
+			SELECT COALESCE(invoice_number, '') FROM invoices WHERE id = $1
+		`, *invoice.CreditedInvoiceID).Scan(&d.creditedNumber)
+		if err != nil {
+			return "", time.Time{}, fmt.Errorf("failed to get credited invoice: %w", err)
+		}
+	}
+
+	var logo image.Image
+	if d.org.logo != nil {
+		logo = pdfLogo(ctx, s.storage, *d.org.logo)
+	}
+
+	data, err := renderInvoicePDF(d, logo)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to render invoice PDF: %w", err)
+	}
+
+	upload, err := s.storage.UploadGenerated(ctx, data, invoicePDFFileName(invoice), "application/pdf", invoice.OrganizationID)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to store invoice PDF: %w", err)
+	}
+
+	var previous *string
+	var generatedAt time.Time
+	err = s.db.Pool.QueryRow(ctx, `
+		UPDATE invoices i SET pdf_url = $1, pdf_generated_at = NOW()
+		FROM (SELECT id, pdf_url FROM invoices WHERE id = $2 FOR UPDATE) old
+		WHERE i.id = old.id
+		RETURNING old.pdf_url, i.pdf_generated_at
+	`, upload.URL, invoice.ID).Scan(&previous, &generatedAt)
+	if err != nil {
+		s.storage.DeleteFile(ctx, upload.URL)
+		return "", time.Time{}, fmt.Errorf("failed to save invoice PDF: %w", err)
+	}
+
+	if previous != nil && *previous != upload.URL {
+		if err := s.storage.DeleteFile(ctx, *previous); err != nil {
+			log.Printf("Failed to delete previous PDF of invoice %s: %v", invoice.ID, err)
+		}
+	}
+
+	return upload.URL, generatedAt, nil
+}
+
+func invoicePDFFileName(invoice *models.Invoice) string {
+	name := "rascunho-" + invoice.ID.String()[:8]
+	if invoice.InvoiceNumber != nil {
+		name = strings.NewReplacer("/", "-", "\\", "-", " ", "-").Replace(*invoice.InvoiceNumber)
+	}
+	if invoice.DocumentType == models.InvoiceDocumentCreditNote {
+		return "nota-de-credito-" + name + ".pdf"
+	}
+	return "fatura-" + name + ".pdf"
+}
+
+// invoiceDocument is the content of an invoice PDF
+type invoiceDocument struct {
+	invoice        *models.Invoice
+	org            budgetParty
+	creditedNumber string
+}
+
+// title is the document name and number printed in the header
+func (d *invoiceDocument) title() string {
+	title := "Fatura"
+	if d.invoice.DocumentType == models.InvoiceDocumentCreditNote {
+		title = "Nota de crédito"
+	}
+	if d.invoice.InvoiceNumber == nil {
+		return title + " (rascunho)"
+	}
+	return fmt.Sprintf("%s N.º %s", title, *d.invoice.InvoiceNumber)
+}
+
+// invoicePDFRenderer lays out an invoice with the budget PDF building blocks
+type invoicePDFRenderer struct {
+	*budgetPDFRenderer
+}
+
+// renderInvoicePDF builds the PDF of an invoice or credit note: header with the organization
+// details and logo, customer and document details, lines, VAT breakdown and totals
+func renderInvoicePDF(d *invoiceDocument, logo image.Image) ([]byte, error) {
+	accent, _ := pdf.ParseHexColor(defaultBudgetPDFAccent)
+	r := &invoicePDFRenderer{&budgetPDFRenderer{doc: pdf.New(), size: 10, leading: 14, accent: accent}}
+	r.columns = []budgetPDFColumn{{title: "Descrição"}, {"Qtd.", 45, true}, {"Preço unit.", 75, true}, {"IVA", 50, true}, {"Valor", 80, true}}
+	r.columns[0].width = pdf.A4Width - 2*budgetPDFMargin
+	for _, c := range r.columns[1:] {
+		r.columns[0].width -= c.width
+	}
+
+	r.page = r.doc.AddPage()
+	if err := r.header(d, logo); err != nil {
+		return nil, err
+	}
+	r.parties(d)
+	r.lines(d)
+	r.totals(d)
+
+	if d.invoice.Notes != nil && *d.invoice.Notes != "" {
+		r.section("Observações", *d.invoice.Notes)
+	}
+	if d.invoice.Status == models.InvoiceStatusVoid {
+		r.section("Documento anulado", derefString(d.invoice.VoidReason))
+	}
+
+	number := d.title()
+	pages := r.doc.Pages()
+	for i, p := range pages {
+		footerY := pdf.A4Height - 40
+		p.Line(budgetPDFMargin, footerY-12, pdf.A4Width-budgetPDFMargin, footerY-12, 0.5, pdf.Gray)
+		p.TextRight(pdf.A4Width-budgetPDFMargin, footerY, pdf.Regular, 7.5, pdf.Gray,
+			fmt.Sprintf("%s · Página %d de %d", number, i+1, len(pages)))
+	}
+
+	return r.doc.Bytes()
+}
+
+func (r *invoicePDFRenderer) header(d *invoiceDocument, logo image.Image) error {
+	top := budgetPDFMargin
+	logoBottom := top
+	if logo != nil {
+		img, err := r.doc.AddImage(logo)
+		if err != nil {
+			return err
+		}
+		w, h := budgetPDFLogoWidth, budgetPDFLogoWidth*float64(img.Height)/float64(img.Width)
+		if h > budgetPDFLogoHeight {
+			w, h = budgetPDFLogoHeight*float64(img.Width)/float64(img.Height), budgetPDFLogoHeight
+		}
+		r.page.Image(img, budgetPDFMargin, top, w, h)
+		logoBottom = top + h
+	}
+
+	right := pdf.A4Width - budgetPDFMargin
+	y := top + 12
+	r.page.TextRight(right, y, pdf.Bold, 12, pdf.Black, d.org.name)
+	for _, line := range nonEmpty(d.org.address, prefixed("NIF ", d.org.taxID), d.org.email, d.org.phone) {
+		for _, wrapped := range pdf.WrapText(line, pdf.Regular, 8.5, 250) {
+			y += 11
+			r.page.TextRight(right, y, pdf.Regular, 8.5, pdf.Gray, wrapped)
+		}
+	}
+
+	r.y = max(logoBottom, y) + 28
+	r.page.Rect(budgetPDFMargin, r.y-14, 4, 18, r.accent)
+	r.page.Text(budgetPDFMargin+10, r.y, pdf.Bold, 16, r.accent, d.title())
+	if d.invoice.Status == models.InvoiceStatusVoid {
+		r.page.TextRight(right, r.y, pdf.Bold, 16, pdf.Gray, "ANULADA")
+	}
+	r.y += r.leading * 1.5
+	return nil
+}
+
+// parties prints the customer on the left and the document dates on the right
+func (r *invoicePDFRenderer) parties(d *invoiceDocument) {
+	inv := d.invoice
+	half := (pdf.A4Width - 2*budgetPDFMargin - 20) / 2
+	left := []string{inv.CustomerName}
+	taxID := derefString(inv.CustomerTaxID)
+	if taxID == "" {
+		taxID = "Consumidor final"
+	} else {
+		taxID = "NIF " + taxID
+	}
+	left = append(left, nonEmpty(derefString(inv.CustomerAddress), taxID, derefString(inv.CustomerEmail))...)
+
+	right := []string{}
+	if inv.IssueDate != nil {
+		right = append(right, "Data de emissão: "+inv.IssueDate.Format("02/01/2006"))
+	}
+	if inv.DueDate != nil && inv.DocumentType == models.InvoiceDocumentInvoice {
+		right = append(right, "Vencimento: "+inv.DueDate.Format("02/01/2006"))
+	}
+	if d.creditedNumber != "" {
+		right = append(right, "Referente à fatura "+d.creditedNumber)
+	}
+	if inv.Status == models.InvoiceStatusPaid && inv.PaidAt != nil {
+		right = append(right, "Paga em "+inv.PaidAt.Format("02/01/2006"))
+	}
+
+	draw := func(x float64, label string, lines []string, boldFirst bool) float64 {
+		y := r.y
+		r.page.Text(x, y, pdf.Bold, r.size-1, r.accent, strings.ToUpper(label))
+		for i, line := range lines {
+			font := pdf.Regular
+			if i == 0 && boldFirst {
+				font = pdf.Bold
+			}
+			for _, wrapped := range pdf.WrapText(line, font, r.size, half) {
+				y += r.leading
+				r.page.Text(x, y, font, r.size, pdf.Black, wrapped)
+			}
+		}
+		return y
+	}
+	leftBottom := draw(budgetPDFMargin, "Cliente", left, true)
+	rightBottom := draw(budgetPDFMargin+half+20, "Documento", right, false)
+	r.y = max(leftBottom, rightBottom) + r.leading*1.5
+}
+
+func (r *invoicePDFRenderer) lines(d *invoiceDocument) {
+	r.ensure(3 * r.leading)
+	r.tableHeader()
+
+	for _, line := range d.invoice.Lines {
+		lines := pdf.WrapText(line.Description, pdf.Regular, r.size, r.columns[0].width-8)
+		height := float64(len(lines))*r.leading + 6
+		if r.ensure(height) {
+			r.tableHeader()
+		}
+
+		values := []string{"", formatPortugueseNumber(line.Quantity.String()), formatEuro(line.UnitPrice),
+			formatPortugueseNumber(line.VATRate.String()) + "%", formatEuro(line.Net)}
+		baseline := r.y + r.leading
+		r.row(baseline, pdf.Regular, pdf.Black, func(i int) string { return values[i] })
+		for i, text := range lines {
+			r.page.Text(budgetPDFMargin+4, baseline+float64(i)*r.leading, pdf.Regular, r.size, pdf.Black, text)
+		}
+		r.y += height
+		r.page.Line(budgetPDFMargin, r.y, pdf.A4Width-budgetPDFMargin, r.y, 0.3, pdf.Gray)
+	}
+}
+
+// totals prints the VAT breakdown on the left and the document totals on the right
+func (r *invoicePDFRenderer) totals(d *invoiceDocument) {
+	inv := d.invoice
+	r.ensure(float64(len(inv.VATBreakdown)+5) * r.leading)
+	r.y += r.leading + 4
+	top := r.y
+
+	// VAT breakdown: rate, base and VAT, with the exemption reason of 0% rates
+	x := budgetPDFMargin
+	r.page.Text(x, r.y, pdf.Bold, r.size-1, r.accent, "QUADRO DE IVA")
+	for _, v := range inv.VATBreakdown {
+		r.y += r.leading
+		r.page.Text(x, r.y, pdf.Regular, r.size, pdf.Black, formatPortugueseNumber(v.Rate.String())+"%")
+		r.page.TextRight(x+150, r.y, pdf.Regular, r.size, pdf.Black, formatEuro(v.Base))
+		r.page.TextRight(x+230, r.y, pdf.Regular, r.size, pdf.Black, formatEuro(v.VAT))
+		if v.ExemptionReason != nil {
+			for _, wrapped := range pdf.WrapText(*v.ExemptionReason, pdf.Regular, r.size-1.5, 230) {
+				r.y += r.leading - 2
+				r.page.Text(x, r.y, pdf.Regular, r.size-1.5, pdf.Gray, wrapped)
+			}
+		}
+	}
+	bottom := r.y
+
+	right := pdf.A4Width - budgetPDFMargin - 4
+	labels := right - 90
+	r.y = top
+	r.page.TextRight(labels, r.y, pdf.Regular, r.size, pdf.Gray, "Incidência")
+	r.page.TextRight(right, r.y, pdf.Regular, r.size, pdf.Black, formatEuro(inv.Subtotal))
+	r.y += r.leading
+	r.page.TextRight(labels, r.y, pdf.Regular, r.size, pdf.Gray, "IVA")
+	r.page.TextRight(right, r.y, pdf.Regular, r.size, pdf.Black, formatEuro(inv.VATTotal))
+	r.y += 6
+	r.page.Line(labels-60, r.y, right+4, r.y, 1, r.accent)
+	r.y += r.leading + 2
+	r.page.TextRight(labels, r.y, pdf.Bold, r.size+2, r.accent, "Total")
+	r.page.TextRight(right, r.y, pdf.Bold, r.size+2, r.accent, formatEuro(inv.Total))
+	r.y = max(r.y, bottom) + r.leading*1.5
+}
@@ -0,0 +1,366 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// MinLogRetentionDays is the shortest execution log retention an organization can have
+	MinLogRetentionDays = 30
+	// logArchiveMaxRows caps the entries of one archive so the file stays under the upload limit;
+	// the rest are picked up by a follow-up archive
+	logArchiveMaxRows = 20000
+	// logArchiveBatch is how many archives the worker builds per run
+	logArchiveBatch = 3
+	// logArchiveStaleAfter releases archives left processing by a worker that stopped
+	logArchiveStaleAfter = 30 * time.Minute
+	// logArchiveDownloadExpiry is how long a download link stays valid
+	logArchiveDownloadExpiry = 15 * time.Minute
+)
+
+// LogRetentionService applies the organizations' execution log retention, moving old entries
+// to archives in object storage
+type LogRetentionService struct {
+	db      *database.DB
+	storage *StorageService
+}
+
+func NewLogRetentionService(db *database.DB, storage *StorageService) *LogRetentionService {
+	return &LogRetentionService{db: db, storage: storage}
+}
+
+const logArchiveColumns = `id, organization_id, status, cutoff, row_count, first_entry_at, last_entry_at,
+	file_url, file_name, file_size, error, requested_by, created_at, started_at, completed_at`
+
+func scanLogArchive(row pgx.Row) (*models.LogArchive, error) {
+	a := &models.LogArchive{}
+	err := row.Scan(&a.ID, &a.OrganizationID, &a.Status, &a.Cutoff, &a.RowCount, &a.FirstEntryAt, &a.LastEntryAt,
+		&a.FileURL, &a.FileName, &a.FileSize, &a.Error, &a.RequestedBy, &a.CreatedAt, &a.StartedAt, &a.CompletedAt)
+	return a, err
+}
+
+// GetPolicy returns an organization's retention with the size of its execution log
+func (s *LogRetentionService) GetPolicy(ctx context.Context, orgID uuid.UUID) (*models.LogRetentionPolicy, error) {
+	policy := &models.LogRetentionPolicy{OrganizationID: orgID}
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT execution_log_retention_days FROM organizations WHERE id = $1 AND deleted_at IS NULL
+	`, orgID).Scan(&policy.RetentionDays)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("organization not found")
+		}
+		return nil, fmt.Errorf("failed to get retention policy: %w", err)
+	}
+
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT COUNT(*), MIN(created_at),
+			COUNT(*) FILTER (WHERE $2::int IS NOT NULL AND created_at < NOW() - make_interval(days => $2::int))
+		FROM workflow_execution_log
+		WHERE organization_id = $1
+	`, orgID, policy.RetentionDays).Scan(&policy.EntryCount, &policy.OldestEntryAt, &policy.ArchivableRows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count execution log: %w", err)
+	}
+	return policy, nil
+}
+
+// SetPolicy sets how many days an organization keeps its execution log; nil keeps it forever
+func (s *LogRetentionService) SetPolicy(ctx context.Context, orgID uuid.UUID, retentionDays *int) error {
+	if retentionDays != nil && *retentionDays < MinLogRetentionDays {
+		return fmt.Errorf("retention must be at least %d days", MinLogRetentionDays)
+	}
+
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE organizations SET execution_log_retention_days = $1
+		WHERE id = $2 AND deleted_at IS NULL
+	`, retentionDays, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to update retention policy: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("organization not found")
+	}
+	return nil
+}
+
+// RequestArchive queues an archive of the entries created before the cutoff, which defaults to
+// the start of the organization's retention period
+func (s *LogRetentionService) RequestArchive(ctx context.Context, orgID, adminID uuid.UUID, before *time.Time) (*models.LogArchive, error) {
+	policy, err := s.GetPolicy(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	var cutoff time.Time
+	switch {
+	case before != nil:
+		cutoff = *before
+	case policy.RetentionDays != nil:
+		cutoff = time.Now().AddDate(0, 0, -*policy.RetentionDays)
+	default:
+		return nil, errors.New("a cutoff date is required when the log is kept forever")
+	}
+	if cutoff.After(time.Now()) {
+		return nil, errors.New("cutoff cannot be in the future")
+	}
+
+	var inProgress bool
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM execution_log_archives
+			WHERE organization_id = $1 AND status IN ('pending', 'processing')
+		)
+	`, orgID).Scan(&inProgress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check log archives: %w", err)
+	}
+	if inProgress {
+		return nil, errors.New("an archive of this organization is already in progress")
+	}
+
+	archive, err := scanLogArchive(s.db.Pool.QueryRow(ctx, `
+		INSERT INTO execution_log_archives (organization_id, cutoff, requested_by)
+		VALUES ($1, $2, $3)
+		RETURNING `+logArchiveColumns, orgID, cutoff, adminID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create log archive: %w", err)
+	}
+	return archive, nil
+}
+
+// ListArchives returns an organization's archives, newest first
+func (s *LogRetentionService) ListArchives(ctx context.Context, orgID uuid.UUID, limit, offset int) ([]*models.LogArchive, int, error) {
+	var total int
+	err := s.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM execution_log_archives WHERE organization_id = $1`, orgID).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count log archives: %w", err)
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT `+logArchiveColumns+` FROM execution_log_archives
+		WHERE organization_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`, orgID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list log archives: %w", err)
+	}
+	defer rows.Close()
+
+	archives := []*models.LogArchive{}
+	for rows.Next() {
+		a, err := scanLogArchive(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan log archive: %w", err)
+		}
+		archives = append(archives, a)
+	}
+	return archives, total, rows.Err()
+}
+
+// ArchiveDownloadURL returns a short-lived link to a ready archive
+func (s *LogRetentionService) ArchiveDownloadURL(ctx context.Context, id, orgID uuid.UUID) (string, error) {
+	a, err := scanLogArchive(s.db.Pool.QueryRow(ctx, `
+		SELECT `+logArchiveColumns+` FROM execution_log_archives WHERE id = $1 AND organization_id = $2
+	`, id, orgID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", errors.New("log archive not found")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get log archive: %w", err)
+	}
+	if a.Status != models.LogArchiveReady || a.FileURL == nil {
+		return "", errors.New("log archive is not ready")
+	}
+
+	url, err := s.storage.DownloadURL(ctx, *a.FileURL, logArchiveDownloadExpiry)
+	if err != nil {
+		return "", fmt.Errorf("failed to create download link: %w", err)
+	}
+	return url, nil
+}
+
+// ScheduleDueArchives queues an archive for every organization with entries older than its
+// retention period. It is run nightly by the worker.
+func (s *LogRetentionService) ScheduleDueArchives(ctx context.Context) (int, error) {
+	result, err := s.db.Pool.Exec(ctx, `
+		INSERT INTO execution_log_archives (organization_id, cutoff)
+		SELECT o.id, NOW() - make_interval(days => o.execution_log_retention_days)
+		FROM organizations o
+		WHERE o.deleted_at IS NULL AND o.execution_log_retention_days IS NOT NULL
+		AND EXISTS (
+			SELECT 1 FROM workflow_execution_log l
+			WHERE l.organization_id = o.id
+			AND l.created_at < NOW() - make_interval(days => o.execution_log_retention_days)
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM execution_log_archives a
+			WHERE a.organization_id = o.id AND a.status IN ('pending', 'processing')
+		)
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to schedule log archives: %w", err)
+	}
+	return int(result.RowsAffected()), nil
+}
+
+// ProcessPendingArchives builds the queued archives. Archives are claimed with SKIP LOCKED so
+// several workers never build the same one.
+func (s *LogRetentionService) ProcessPendingArchives(ctx context.Context) (int, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		UPDATE execution_log_archives SET status = 'processing', started_at = NOW()
+		WHERE id IN (
+			SELECT id FROM execution_log_archives
+			WHERE status = 'pending' OR (status = 'processing' AND started_at < $1)
+			ORDER BY created_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+logArchiveColumns, time.Now().Add(-logArchiveStaleAfter), logArchiveBatch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim log archives: %w", err)
+	}
+	var archives []*models.LogArchive
+	for rows.Next() {
+		a, err := scanLogArchive(rows)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan log archive: %w", err)
+		}
+		archives = append(archives, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to claim log archives: %w", err)
+	}
+
+	built := 0
+	for _, a := range archives {
+		if err := s.processArchive(ctx, a); err != nil {
+			log.Printf("[LogArchives] Archive %s failed: %v", a.ID, err)
+			if _, dbErr := s.db.Pool.Exec(ctx, `
+				UPDATE execution_log_archives SET status = 'failed', error = $2, completed_at = NOW() WHERE id = $1
+			`, a.ID, err.Error()); dbErr != nil {
+				log.Printf("[LogArchives] Failed to record error of archive %s: %v", a.ID, dbErr)
+			}
+			continue
+		}
+		built++
+	}
+	return built, nil
+}
+
+// processArchive writes the entries before the cutoff to a gzipped JSONL file, uploads it and
+// deletes the archived entries. When more entries remain, a follow-up archive is queued.
+func (s *LogRetentionService) processArchive(ctx context.Context, a *models.LogArchive) error {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, organization_id, workflow_id, entity_type, entity_id, trigger_id, action_id,
+			event_type, from_state, to_state, details, created_at
+		FROM workflow_execution_log
+		WHERE organization_id = $1 AND created_at < $2
+		ORDER BY created_at, id
+		LIMIT $3
+	`, a.OrganizationID, a.Cutoff, logArchiveMaxRows+1)
+	if err != nil {
+		return fmt.Errorf("failed to read execution log: %w", err)
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	enc := json.NewEncoder(gz)
+	var ids []uuid.UUID
+	var first, last *time.Time
+	more := false
+	for rows.Next() {
+		if len(ids) == logArchiveMaxRows {
+			more = true
+			break
+		}
+		var entry models.WorkflowExecutionLog
+		if err := rows.Scan(&entry.ID, &entry.OrganizationID, &entry.WorkflowID, &entry.EntityType, &entry.EntityID,
+			&entry.TriggerID, &entry.ActionID, &entry.EventType, &entry.FromState, &entry.ToState,
+			&entry.Details, &entry.CreatedAt); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan execution log: %w", err)
+		}
+		if err := enc.Encode(entry); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to write archive: %w", err)
+		}
+		ids = append(ids, entry.ID)
+		createdAt := entry.CreatedAt
+		if first == nil {
+			first = &createdAt
+		}
+		last = &createdAt
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read execution log: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	if len(ids) == 0 {
+		_, err := s.db.Pool.Exec(ctx, `
+			UPDATE execution_log_archives SET status = 'ready', row_count = 0, error = NULL, completed_at = NOW()
+			WHERE id = $1
+		`, a.ID)
+		if err != nil {
+			return fmt.Errorf("failed to update log archive: %w", err)
+		}
+		return nil
+	}
+
+	fileName := fmt.Sprintf("execution-log-%s-%s.jsonl.gz", first.Format("20060102"), last.Format("20060102"))
+	upload, err := s.storage.UploadData(ctx, buf.Bytes(), fileName, "application/gzip", a.OrganizationID)
+	if err != nil {
+		return fmt.Errorf("failed to upload archive: %w", err)
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM workflow_execution_log WHERE id = ANY($1)`, ids); err != nil {
+		return fmt.Errorf("failed to delete archived entries: %w", err)
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE execution_log_archives
+		SET status = 'ready', row_count = $2, first_entry_at = $3, last_entry_at = $4,
+			file_url = $5, file_name = $6, file_size = $7, error = NULL, completed_at = NOW()
+		WHERE id = $1
+	`, a.ID, len(ids), first, last, upload.URL, fileName, upload.FileSize)
+	if err != nil {
+		return fmt.Errorf("failed to update log archive: %w", err)
+	}
+	if more {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO execution_log_archives (organization_id, cutoff, requested_by)
+			VALUES ($1, $2, $3)
+		`, a.OrganizationID, a.Cutoff, a.RequestedBy)
+		if err != nil {
+			return fmt.Errorf("failed to queue follow-up archive: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
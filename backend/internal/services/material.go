@@ -0,0 +1,396 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// MaterialService handles the materials catalogue and supplier price list imports
+type MaterialService struct {
+	db *database.DB
+}
+
+func NewMaterialService(db *database.DB) *MaterialService {
+	return &MaterialService{db: db}
+}
+
+// rowQuerier is satisfied by both the connection pool and a transaction
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// PriceListImportResult summarises a confirmed price list import
+type PriceListImportResult struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"` // rows with errors
+}
+
+// ============ Catalogue ============
+
+// ListMaterials returns the active catalogue entries, optionally filtered by code, name or supplier
+func (s *MaterialService) ListMaterials(ctx context.Context, orgID uuid.UUID, search string) ([]*models.Material, error) {
+	query := `
+		SELECT id, organization_id, supplier, code, name, unit, unit_price, is_active,
+			last_import_id, created_at, updated_at
+		FROM materials
+		WHERE organization_id = $1 AND is_active = true
+	`
+	args := []interface{}{orgID}
+	if search != "" {
+		query += " AND (code ILIKE $2 OR name ILIKE $2 OR supplier ILIKE $2)"
+		args = append(args, "%"+search+"%")
+	}
+	query += " ORDER BY name, supplier"
+
+	rows, err := s.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query materials: %w", err)
+	}
+	defer rows.Close()
+
+	var materials []*models.Material
+	for rows.Next() {
+		var m models.Material
+		if err := rows.Scan(
+			&m.ID, &m.OrganizationID, &m.Supplier, &m.Code, &m.Name, &m.Unit, &m.UnitPrice, &m.IsActive,
+			&m.LastImportID, &m.CreatedAt, &m.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan material: %w", err)
+		}
+		materials = append(materials, &m)
+	}
+
+	return materials, nil
+}
+
+// ============ Price List Imports ============
+
+// PreviewImport parses an uploaded price list and stages it for confirmation.
+// Nothing is written to the catalogue or the budget until the import is confirmed.
+func (s *MaterialService) PreviewImport(ctx context.Context, imp *models.PriceListImport, data []byte) error {
+	imp.Supplier = strings.TrimSpace(imp.Supplier)
+
+	switch imp.Target {
+	case models.PriceListTargetCatalogue:
+		imp.BudgetID = nil
+	case models.PriceListTargetBudget:
+		if imp.BudgetID == nil {
+			return errors.New("budget_id is required when importing into a budget")
+		}
+		if err := s.checkDraftBudget(ctx, s.db.Pool, *imp.BudgetID, imp.OrganizationID, false); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("invalid target: %s", imp.Target)
+	}
+	if imp.Options.TaxRate.IsNegative() || imp.Options.TaxRate.GreaterThan(decimal.NewFromInt(100)) {
+		return errors.New("tax rate must be between 0 and 100")
+	}
+
+	format, table, err := readPriceListTable(imp.FileName, data, imp.Options.Sheet)
+	if err != nil {
+		return err
+	}
+	rows, err := parsePriceListRows(table, imp.Options, imp.Target)
+	if err != nil {
+		return err
+	}
+
+	if imp.Target == models.PriceListTargetCatalogue {
+		if err := s.matchExistingMaterials(ctx, imp.OrganizationID, imp.Supplier, rows); err != nil {
+			return err
+		}
+	}
+
+	imp.ID = uuid.New()
+	imp.FileFormat = format
+	imp.Rows = rows
+	imp.RowCount = len(rows)
+	imp.ErrorCount = 0
+	for _, row := range rows {
+		if len(row.Errors) > 0 {
+			imp.ErrorCount++
+		}
+	}
+	imp.Status = models.PriceListImportPreview
+
+	optionsJSON, err := json.Marshal(imp.Options)
+	if err != nil {
+		return fmt.Errorf("failed to marshal import options: %w", err)
+	}
+	rowsJSON, err := json.Marshal(imp.Rows)
+	if err != nil {
+		return fmt.Errorf("failed to marshal import rows: %w", err)
+	}
+
+	err = s.db.Pool.QueryRow(ctx, `
+		INSERT INTO price_list_imports (
+			id, organization_id, file_name, file_format, target, budget_id, supplier,
+			options, rows, row_count, error_count, status, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING created_at
+	`, imp.ID, imp.OrganizationID, imp.FileName, imp.FileFormat, imp.Target, imp.BudgetID, imp.Supplier,
+		optionsJSON, rowsJSON, imp.RowCount, imp.ErrorCount, imp.Status, imp.CreatedBy).Scan(&imp.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create price list import: %w", err)
+	}
+
+	return nil
+}
+
+// GetImport returns a staged or processed price list import with its rows
+func (s *MaterialService) GetImport(ctx context.Context, id, orgID uuid.UUID) (*models.PriceListImport, error) {
+	return s.getImport(ctx, s.db.Pool, id, orgID, false)
+}
+
+// ConfirmImport writes the valid rows of a previewed import to the catalogue or the budget.
+// Catalogue rows are upserted by supplier and code; budget rows are appended as items
+// and the budget totals recalculated.
+func (s *MaterialService) ConfirmImport(ctx context.Context, id, orgID, userID uuid.UUID) (*PriceListImportResult, error) {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	imp, err := s.getImport(ctx, tx, id, orgID, true)
+	if err != nil {
+		return nil, err
+	}
+	if imp.Status != models.PriceListImportPreview {
+		return nil, fmt.Errorf("import is already %s", imp.Status)
+	}
+
+	result := &PriceListImportResult{Skipped: imp.ErrorCount}
+	if imp.RowCount == imp.ErrorCount {
+		return nil, errors.New("no valid rows to import")
+	}
+
+	switch imp.Target {
+	case models.PriceListTargetCatalogue:
+		err = s.applyToCatalogue(ctx, tx, imp, result)
+	case models.PriceListTargetBudget:
+		err = s.applyToBudget(ctx, tx, imp, result)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE price_list_imports
+		SET status = $1, confirmed_by = $2, confirmed_at = NOW()
+		WHERE id = $3
+	`, models.PriceListImportConfirmed, userID, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to confirm import: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result, nil
+}
+
+// CancelImport discards a previewed import
+func (s *MaterialService) CancelImport(ctx context.Context, id, orgID uuid.UUID) error {
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE price_list_imports SET status = $1
+		WHERE id = $2 AND organization_id = $3 AND status = $4
+	`, models.PriceListImportCancelled, id, orgID, models.PriceListImportPreview)
+	if err != nil {
+		return fmt.Errorf("failed to cancel import: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("import not found or already processed")
+	}
+	return nil
+}
+
+// applyToCatalogue upserts the valid rows into the materials catalogue
+func (s *MaterialService) applyToCatalogue(ctx context.Context, tx pgx.Tx, imp *models.PriceListImport, result *PriceListImportResult) error {
+	for _, row := range imp.Rows {
+		if len(row.Errors) > 0 {
+			continue
+		}
+
+		var materialID uuid.UUID
+		var inserted bool
+		err := tx.QueryRow(ctx, `
+			INSERT INTO materials (organization_id, supplier, code, name, unit, unit_price, is_active, last_import_id)
+			VALUES ($1, $2, $3, $4, $5, $6, true, $7)
+			ON CONFLICT (organization_id, supplier, code) DO UPDATE
+			SET name = EXCLUDED.name, unit = EXCLUDED.unit, unit_price = EXCLUDED.unit_price,
+				is_active = true, last_import_id = EXCLUDED.last_import_id
+			RETURNING id, (xmax = 0)
+		`, imp.OrganizationID, imp.Supplier, row.Code, row.Name, row.Unit, row.UnitPrice, imp.ID).Scan(&materialID, &inserted)
+		if err != nil {
+			return fmt.Errorf("failed to import line %d: %w", row.Line, err)
+		}
+
+		if err := recordCataloguePrice(ctx, tx, imp.OrganizationID, materialID, materialDescription(row.Code, row.Name), row.Unit, row.UnitPrice); err != nil {
+			return err
+		}
+
+		if inserted {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+	}
+	return nil
+}
+
+// applyToBudget appends the valid rows as items of the draft budget and recalculates its totals
+func (s *MaterialService) applyToBudget(ctx context.Context, tx pgx.Tx, imp *models.PriceListImport, result *PriceListImportResult) error {
+	if err := s.checkDraftBudget(ctx, tx, *imp.BudgetID, imp.OrganizationID, true); err != nil {
+		return err
+	}
+
+	var order int
+	err := tx.QueryRow(ctx, `
+		SELECT COALESCE(MAX("order"), 0) FROM budget_items
+		WHERE budget_id = $1 AND deleted_at IS NULL
+	`, imp.BudgetID).Scan(&order)
+	if err != nil {
+		return fmt.Errorf("failed to get budget items: %w", err)
+	}
+
+	hundred := decimal.NewFromInt(100)
+	for _, row := range imp.Rows {
+		if len(row.Errors) > 0 {
+			continue
+		}
+		order++
+
+		description := materialDescription(row.Code, row.Name)
+		quantity := row.Quantity.Round(2)
+		unitPrice := row.UnitPrice.Round(2)
+		subtotal := quantity.Mul(unitPrice).Round(2)
+		tax := subtotal.Mul(imp.Options.TaxRate).Div(hundred).Round(2)
+
+		_, err := tx.Exec(ctx, `
+			INSERT INTO budget_items (budget_id, description, quantity, unit, unit_price, tax, total, "order")
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, imp.BudgetID, description, quantity, row.Unit, unitPrice, tax, subtotal.Add(tax), order)
+		if err != nil {
+			return fmt.Errorf("failed to import line %d: %w", row.Line, err)
+		}
+		result.Created++
+	}
+
+	return recalculateBudgetTotals(ctx, tx, *imp.BudgetID)
+}
+
+// matchExistingMaterials flags catalogue rows that will update an existing material
+func (s *MaterialService) matchExistingMaterials(ctx context.Context, orgID uuid.UUID, supplier string, rows []models.PriceListImportRow) error {
+	codes := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if row.Code != "" {
+			codes = append(codes, row.Code)
+		}
+	}
+
+	dbRows, err := s.db.Pool.Query(ctx, `
+		SELECT id, code FROM materials
+		WHERE organization_id = $1 AND supplier = $2 AND code = ANY($3)
+	`, orgID, supplier, codes)
+	if err != nil {
+		return fmt.Errorf("failed to query materials: %w", err)
+	}
+	defer dbRows.Close()
+
+	existing := make(map[string]uuid.UUID)
+	for dbRows.Next() {
+		var id uuid.UUID
+		var code string
+		if err := dbRows.Scan(&id, &code); err != nil {
+			return fmt.Errorf("failed to scan material: %w", err)
+		}
+		existing[code] = id
+	}
+
+	for i := range rows {
+		if id, ok := existing[rows[i].Code]; ok {
+			rows[i].MaterialID = &id
+		}
+	}
+	return nil
+}
+
+// materialDescription is the budget item description of a material, also used as its price history key
+func materialDescription(code, name string) string {
+	if code == "" {
+		return name
+	}
+	return code + " - " + name
+}
+
+// checkDraftBudget ensures the budget exists in the organization and is still a draft
+func (s *MaterialService) checkDraftBudget(ctx context.Context, q rowQuerier, budgetID, orgID uuid.UUID, lock bool) error {
+	query := `
+		SELECT status FROM budgets
+		WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`
+	if lock {
+		query += " FOR UPDATE"
+	}
+
+	var status models.BudgetStatus
+	if err := q.QueryRow(ctx, query, budgetID, orgID).Scan(&status); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("budget not found")
+		}
+		return fmt.Errorf("failed to get budget: %w", err)
+	}
+	if status != models.BudgetStatusDraft {
+		return errors.New("items can only be imported into draft budgets")
+	}
+	return nil
+}
+
+// getImport loads an import, optionally locking it for update
+func (s *MaterialService) getImport(ctx context.Context, q rowQuerier, id, orgID uuid.UUID, lock bool) (*models.PriceListImport, error) {
+	query := `
+		SELECT id, organization_id, file_name, file_format, target, budget_id, supplier,
+			options, rows, row_count, error_count, status, created_by, confirmed_by, confirmed_at, created_at
+		FROM price_list_imports
+		WHERE id = $1 AND organization_id = $2
+	`
+	if lock {
+		query += " FOR UPDATE"
+	}
+
+	var imp models.PriceListImport
+	var optionsJSON, rowsJSON []byte
+	err := q.QueryRow(ctx, query, id, orgID).Scan(
+		&imp.ID, &imp.OrganizationID, &imp.FileName, &imp.FileFormat, &imp.Target, &imp.BudgetID, &imp.Supplier,
+		&optionsJSON, &rowsJSON, &imp.RowCount, &imp.ErrorCount, &imp.Status, &imp.CreatedBy, &imp.ConfirmedBy,
+		&imp.ConfirmedAt, &imp.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("import not found")
+		}
+		return nil, fmt.Errorf("failed to get import: %w", err)
+	}
+
+	if err := json.Unmarshal(optionsJSON, &imp.Options); err != nil {
+		return nil, fmt.Errorf("failed to parse import options: %w", err)
+	}
+	if err := json.Unmarshal(rowsJSON, &imp.Rows); err != nil {
+		return nil, fmt.Errorf("failed to parse import rows: %w", err)
+	}
+
+	return &imp, nil
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/controlwise/backend/internal/database"
@@ -24,7 +26,7 @@ func NewModuleService(db *database.DB) *ModuleService {
 // ListAvailable returns all available modules in the system
 func (s *ModuleService) ListAvailable(ctx context.Context) ([]*models.AvailableModule, error) {
 	rows, err := s.db.Pool.Query(ctx, `
-		SELECT name, display_name, description, icon, dependencies, is_active, created_at
+		SELECT name, display_name, description, icon, dependencies, pricing_tier, version, is_active, created_at
 		FROM available_modules
 		WHERE is_active = TRUE
 		ORDER BY name
@@ -43,6 +45,8 @@ func (s *ModuleService) ListAvailable(ctx context.Context) ([]*models.AvailableM
 			&m.Description,
 			&m.Icon,
 			&m.Dependencies,
+			&m.PricingTier,
+			&m.Version,
 			&m.IsActive,
 			&m.CreatedAt,
 		)
@@ -64,6 +68,13 @@ func (s *ModuleService) ListForOrganization(ctx context.Context, orgID uuid.UUID
 			am.description,
 			am.icon,
 			am.dependencies,
+			am.pricing_tier,
+			am.version,
+			CASE WHEN COALESCE(om.is_enabled, FALSE) THEN (
+				SELECT COUNT(*) FROM module_changelog mc
+				WHERE mc.module_name = am.name
+					AND mc.released_at > COALESCE(om.changelog_seen_at, om.enabled_at, om.created_at)
+			) ELSE 0 END as unseen_changes,
 			COALESCE(om.id, '00000000-0000-0000-0000-000000000000'::uuid) as id,
 			COALESCE(om.organization_id, $1) as organization_id,
 			COALESCE(om.is_enabled, FALSE) as is_enabled,
@@ -92,6 +103,9 @@ func (s *ModuleService) ListForOrganization(ctx context.Context, orgID uuid.UUID
 			&m.Description,
 			&m.Icon,
 			&dependencies,
+			&m.PricingTier,
+			&m.Version,
+			&m.UnseenChanges,
 			&m.ID,
 			&m.OrganizationID,
 			&m.IsEnabled,
@@ -191,14 +205,20 @@ func (s *ModuleService) enableModule(ctx context.Context, orgID uuid.UUID, modul
 		return err
 	}
 
-	// Enable or insert
+	defaultsJSON, err := json.Marshal(configDefaults(moduleName))
+	if err != nil {
+		return fmt.Errorf("failed to marshal config defaults: %w", err)
+	}
+
+	// Enable or insert, keeping settings saved before the module was last disabled
 	now := time.Now()
 	_, err = s.db.Pool.Exec(ctx, `
-		INSERT INTO organization_modules (organization_id, module_name, is_enabled, enabled_at, enabled_by, enabled_by_admin)
-		VALUES ($1, $2, TRUE, $3, $4, $5)
+		INSERT INTO organization_modules (organization_id, module_name, is_enabled, enabled_at, enabled_by, enabled_by_admin, config)
+		VALUES ($1, $2, TRUE, $3, $4, $5, $6)
 		ON CONFLICT (organization_id, module_name)
-		DO UPDATE SET is_enabled = TRUE, enabled_at = $3, enabled_by = $4, enabled_by_admin = $5, updated_at = CURRENT_TIMESTAMP
-	`, orgID, moduleName, now, enabledBy, enabledByAdmin)
+		DO UPDATE SET is_enabled = TRUE, enabled_at = $3, enabled_by = $4, enabled_by_admin = $5,
+			config = $6::jsonb || COALESCE(organization_modules.config, '{}'), updated_at = CURRENT_TIMESTAMP
+	`, orgID, moduleName, now, enabledBy, enabledByAdmin, defaultsJSON)
 	if err != nil {
 		return fmt.Errorf("failed to enable module: %w", err)
 	}
@@ -230,9 +250,13 @@ func (s *ModuleService) Disable(ctx context.Context, orgID uuid.UUID, moduleName
 	return nil
 }
 
-// UpdateConfig updates the configuration for a module
+// UpdateConfig validates and saves the configuration for a module. Omitted settings take their defaults.
 func (s *ModuleService) UpdateConfig(ctx context.Context, orgID uuid.UUID, moduleName models.ModuleName, config models.ModuleConfig) error {
-	configJSON, err := json.Marshal(config)
+	if err := validateConfig(moduleName, config); err != nil {
+		return err
+	}
+
+	configJSON, err := json.Marshal(withDefaults(moduleName, config))
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
@@ -253,7 +277,7 @@ func (s *ModuleService) UpdateConfig(ctx context.Context, orgID uuid.UUID, modul
 	return nil
 }
 
-// GetConfig returns the configuration for a module
+// GetConfig returns the configuration for a module, with defaults for settings that were never saved
 func (s *ModuleService) GetConfig(ctx context.Context, orgID uuid.UUID, moduleName models.ModuleName) (models.ModuleConfig, error) {
 	var configJSON json.RawMessage
 	err := s.db.Pool.QueryRow(ctx, `
@@ -263,7 +287,7 @@ func (s *ModuleService) GetConfig(ctx context.Context, orgID uuid.UUID, moduleNa
 	`, orgID, moduleName).Scan(&configJSON)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return models.ModuleConfig{}, nil
+			return configDefaults(moduleName), nil
 		}
 		return nil, fmt.Errorf("failed to get config: %w", err)
 	}
@@ -275,7 +299,7 @@ func (s *ModuleService) GetConfig(ctx context.Context, orgID uuid.UUID, moduleNa
 		}
 	}
 
-	return config, nil
+	return withDefaults(moduleName, config), nil
 }
 
 // checkDependencies verifies that all dependencies are enabled
@@ -296,20 +320,50 @@ func (s *ModuleService) checkDependencies(ctx context.Context, orgID uuid.UUID,
 		}
 	}
 
-	// Check each dependency
+	// Each entry must be satisfied by one of its alternatives ("a|b")
 	for _, dep := range deps {
-		enabled, err := s.IsEnabled(ctx, orgID, models.ModuleName(dep))
+		alternatives := models.DependencyAlternatives(dep)
+		satisfied, err := s.anyEnabled(ctx, orgID, alternatives, "")
 		if err != nil {
 			return err
 		}
-		if !enabled {
-			return fmt.Errorf("required module '%s' is not enabled", dep)
+		if !satisfied {
+			if len(alternatives) == 1 {
+				return fmt.Errorf("required module '%s' is not enabled", alternatives[0])
+			}
+			return fmt.Errorf("requires one of the modules %s to be enabled", joinModuleNames(alternatives))
 		}
 	}
 
 	return nil
 }
 
+// anyEnabled returns true if any of the modules, other than except, is enabled for the organization
+func (s *ModuleService) anyEnabled(ctx context.Context, orgID uuid.UUID, names []models.ModuleName, except models.ModuleName) (bool, error) {
+	for _, name := range names {
+		if name == except {
+			continue
+		}
+		enabled, err := s.IsEnabled(ctx, orgID, name)
+		if err != nil {
+			return false, err
+		}
+		if enabled {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// joinModuleNames formats module names for error messages, e.g. 'appointments', 'construction'
+func joinModuleNames(names []models.ModuleName) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = "'" + string(name) + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
+
 // checkDependents verifies that no other enabled modules depend on this one
 func (s *ModuleService) checkDependents(ctx context.Context, orgID uuid.UUID, moduleName models.ModuleName) error {
 	// Get all enabled modules
@@ -337,7 +391,16 @@ func (s *ModuleService) checkDependents(ctx context.Context, orgID uuid.UUID, mo
 				continue
 			}
 			for _, dep := range deps {
-				if dep == string(moduleName) {
+				alternatives := models.DependencyAlternatives(dep)
+				if !slices.Contains(alternatives, moduleName) {
+					continue
+				}
+				// Still satisfied if another alternative stays enabled
+				satisfied, err := s.anyEnabled(ctx, orgID, alternatives, moduleName)
+				if err != nil {
+					return err
+				}
+				if !satisfied {
 					return fmt.Errorf("cannot disable: module '%s' depends on this module", name)
 				}
 			}
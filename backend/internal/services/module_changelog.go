@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PublishChangelogRequest contains a release note for a module
+type PublishChangelogRequest struct {
+	Version    string     `json:"version"`
+	Title      string     `json:"title"`
+	Body       *string    `json:"body"`
+	ReleasedAt *time.Time `json:"released_at"` // defaults to now
+}
+
+// GetChangelog returns a module's release notes, newest first. Entries released since the
+// organization's admins last opened the feed are flagged as new.
+func (s *ModuleService) GetChangelog(ctx context.Context, orgID uuid.UUID, moduleName models.ModuleName) ([]*models.ModuleChangelogEntry, error) {
+	var exists bool
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM available_modules WHERE name = $1)
+	`, moduleName).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check module: %w", err)
+	}
+	if !exists {
+		return nil, errors.New("module not found")
+	}
+
+	return s.queryChangelog(ctx, `
+		WHERE mc.module_name = $2
+		ORDER BY mc.released_at DESC
+	`, orgID, moduleName)
+}
+
+// GetWhatsNew returns the unseen release notes of the organization's enabled modules, newest first
+func (s *ModuleService) GetWhatsNew(ctx context.Context, orgID uuid.UUID) ([]*models.ModuleChangelogEntry, error) {
+	return s.queryChangelog(ctx, `
+		WHERE om.is_enabled = TRUE
+			AND mc.released_at > COALESCE(om.changelog_seen_at, om.enabled_at, om.created_at)
+		ORDER BY mc.released_at DESC
+	`, orgID)
+}
+
+// queryChangelog selects changelog entries joined with the organization's module state ($1)
+func (s *ModuleService) queryChangelog(ctx context.Context, where string, args ...interface{}) ([]*models.ModuleChangelogEntry, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT mc.id, mc.module_name, mc.version, mc.title, mc.body, mc.released_at,
+			COALESCE(om.is_enabled, FALSE)
+				AND mc.released_at > COALESCE(om.changelog_seen_at, om.enabled_at, om.created_at)
+		FROM module_changelog mc
+		LEFT JOIN organization_modules om ON om.module_name = mc.module_name AND om.organization_id = $1
+	`+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query module changelog: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*models.ModuleChangelogEntry{}
+	for rows.Next() {
+		var e models.ModuleChangelogEntry
+		var isNew *bool
+		if err := rows.Scan(&e.ID, &e.ModuleName, &e.Version, &e.Title, &e.Body, &e.ReleasedAt, &isNew); err != nil {
+			return nil, fmt.Errorf("failed to scan changelog entry: %w", err)
+		}
+		e.IsNew = isNew != nil && *isNew
+		entries = append(entries, &e)
+	}
+
+	return entries, nil
+}
+
+// MarkChangelogSeen clears the what's-new feed of a module, or of all enabled modules when moduleName is empty
+func (s *ModuleService) MarkChangelogSeen(ctx context.Context, orgID uuid.UUID, moduleName models.ModuleName) error {
+	query := `
+		UPDATE organization_modules SET changelog_seen_at = CURRENT_TIMESTAMP
+		WHERE organization_id = $1 AND is_enabled = TRUE
+	`
+	args := []interface{}{orgID}
+	if moduleName != "" {
+		query += " AND module_name = $2"
+		args = append(args, moduleName)
+	}
+
+	result, err := s.db.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to mark changelog as seen: %w", err)
+	}
+	if moduleName != "" && result.RowsAffected() == 0 {
+		return errors.New("module not found or not enabled")
+	}
+	return nil
+}
+
+// PublishChangelog adds a release note to a module and bumps the module's version (called by system admins)
+func (s *ModuleService) PublishChangelog(ctx context.Context, moduleName models.ModuleName, req PublishChangelogRequest) (*models.ModuleChangelogEntry, error) {
+	version := strings.TrimSpace(req.Version)
+	title := strings.TrimSpace(req.Title)
+	if version == "" || title == "" {
+		return nil, errors.New("changelog version and title are required")
+	}
+	releasedAt := time.Now()
+	if req.ReleasedAt != nil && !req.ReleasedAt.IsZero() {
+		releasedAt = *req.ReleasedAt
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var name models.ModuleName
+	err = tx.QueryRow(ctx, `
+		UPDATE available_modules SET version = $1 WHERE name = $2 RETURNING name
+	`, version, moduleName).Scan(&name)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("module not found")
+		}
+		return nil, fmt.Errorf("failed to update module version: %w", err)
+	}
+
+	entry := &models.ModuleChangelogEntry{
+		ID:         uuid.New(),
+		ModuleName: moduleName,
+		Version:    version,
+		Title:      title,
+		Body:       req.Body,
+		ReleasedAt: releasedAt,
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO module_changelog (id, module_name, version, title, body, released_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, entry.ID, entry.ModuleName, entry.Version, entry.Title, entry.Body, entry.ReleasedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create changelog entry: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return entry, nil
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/controlwise/backend/internal/i18n"
+	"github.com/controlwise/backend/internal/models"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+func floatPtr(f float64) *float64 { return &f }
+
+// moduleConfigSchemas defines the settings each module accepts in organization_modules.config
+var moduleConfigSchemas = map[models.ModuleName]*models.ModuleConfigSchema{
+	models.ModuleConstruction: {
+		Title: "Construction settings",
+		Properties: map[string]*models.ModuleConfigProperty{
+			"payment_grace_days": {
+				Type:        "integer",
+				Title:       "Payment grace period (days)",
+				Description: "Days after the due date before a pending payment is marked overdue",
+				Default:     float64(0),
+				Minimum:     floatPtr(0),
+				Maximum:     floatPtr(90),
+			},
+		},
+	},
+	models.ModuleAppointments: {
+		Title: "Appointments settings",
+		Properties: map[string]*models.ModuleConfigProperty{
+			"double_booking_requires_approval": {
+				Type:        "boolean",
+				Title:       "Double bookings require approval",
+				Description: "Double-booking overrides by staff stay pending until an admin or manager approves them",
+				Default:     false,
+			},
+			"treatment_plan_review_days": {
+				Type:        "integer",
+				Title:       "Treatment plan review notice (days)",
+				Description: "Days before a treatment plan's review date that its therapist is notified and its review triggers fire",
+				Default:     float64(7),
+				Minimum:     floatPtr(0),
+				Maximum:     floatPtr(60),
+			},
+		},
+	},
+	models.ModuleNotifications: {
+		Title:      "Notifications settings",
+		Properties: map[string]*models.ModuleConfigProperty{},
+	},
+	models.ModuleInvoices: {
+		Title: "Invoices settings",
+		Properties: map[string]*models.ModuleConfigProperty{
+			"default_vat_rate": {
+				Type:        "number",
+				Title:       "Default VAT rate (%)",
+				Description: "VAT rate of new invoices; payment amounts include it",
+				Default:     float64(23),
+				Minimum:     floatPtr(0),
+				Maximum:     floatPtr(100),
+			},
+			"vat_exemption_reason": {
+				Type:        "string",
+				Title:       "VAT exemption reason",
+				Description: "Printed on invoices with a 0% VAT rate, e.g. \"Isento nos termos do art. 9.º do CIVA\"",
+				Default:     "",
+				MaxLength:   intPtr(255),
+			},
+			"payment_terms_days": {
+				Type:        "integer",
+				Title:       "Payment terms (days)",
+				Description: "Days from the issue date until an invoice is due",
+				Default:     float64(30),
+				Minimum:     floatPtr(0),
+				Maximum:     floatPtr(365),
+			},
+		},
+	},
+}
+
+// GetConfigSchema returns a module's configuration schema with titles in the request locale
+func (s *ModuleService) GetConfigSchema(ctx context.Context, moduleName models.ModuleName) (*models.ModuleConfigSchema, error) {
+	schema, ok := moduleConfigSchemas[moduleName]
+	if !ok {
+		return nil, errors.New("module not found")
+	}
+
+	locale := i18n.FromContext(ctx)
+	result := &models.ModuleConfigSchema{
+		Schema:               jsonSchemaDraft,
+		Title:                i18n.T(locale, schema.Title),
+		Type:                 "object",
+		Properties:           make(map[string]*models.ModuleConfigProperty, len(schema.Properties)),
+		Required:             schema.Required,
+		AdditionalProperties: false,
+	}
+	for key, prop := range schema.Properties {
+		translated := *prop
+		translated.Title = i18n.T(locale, prop.Title)
+		if prop.Description != "" {
+			translated.Description = i18n.T(locale, prop.Description)
+		}
+		result.Properties[key] = &translated
+	}
+	return result, nil
+}
+
+// configDefaults returns the default value of every setting of a module
+func configDefaults(moduleName models.ModuleName) models.ModuleConfig {
+	defaults := models.ModuleConfig{}
+	if schema, ok := moduleConfigSchemas[moduleName]; ok {
+		for key, prop := range schema.Properties {
+			if prop.Default != nil {
+				defaults[key] = prop.Default
+			}
+		}
+	}
+	return defaults
+}
+
+// withDefaults fills settings missing from config with their defaults
+func withDefaults(moduleName models.ModuleName, config models.ModuleConfig) models.ModuleConfig {
+	result := configDefaults(moduleName)
+	for key, value := range config {
+		result[key] = value
+	}
+	return result
+}
+
+// validateConfig checks a configuration against the module's schema
+func validateConfig(moduleName models.ModuleName, config models.ModuleConfig) error {
+	schema, ok := moduleConfigSchemas[moduleName]
+	if !ok {
+		return errors.New("module not found")
+	}
+
+	for _, key := range schema.Required {
+		if _, ok := config[key]; !ok {
+			return fmt.Errorf("invalid config: '%s' is required", key)
+		}
+	}
+
+	// Sorted so the reported error is stable
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		prop, ok := schema.Properties[key]
+		if !ok {
+			if schema.AdditionalProperties {
+				continue
+			}
+			return fmt.Errorf("invalid config: unknown setting '%s'", key)
+		}
+		if err := validateConfigValue(prop, config[key]); err != nil {
+			return fmt.Errorf("invalid config: '%s' %w", key, err)
+		}
+	}
+	return nil
+}
+
+// validateConfigValue checks a decoded JSON value against a property
+func validateConfigValue(prop *models.ModuleConfigProperty, value interface{}) error {
+	switch prop.Type {
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return errors.New("must be a boolean")
+		}
+	case "integer", "number":
+		n, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("must be a %s", prop.Type)
+		}
+		if prop.Type == "integer" && n != math.Trunc(n) {
+			return errors.New("must be an integer")
+		}
+		if prop.Minimum != nil && n < *prop.Minimum {
+			return fmt.Errorf("must be at least %v", *prop.Minimum)
+		}
+		if prop.Maximum != nil && n > *prop.Maximum {
+			return fmt.Errorf("must be at most %v", *prop.Maximum)
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return errors.New("must be a string")
+		}
+		if prop.MaxLength != nil && len([]rune(str)) > *prop.MaxLength {
+			return fmt.Errorf("must be at most %d characters", *prop.MaxLength)
+		}
+	}
+
+	if len(prop.Enum) > 0 && !slices.Contains(prop.Enum, value) {
+		return errors.New("is not an allowed value")
+	}
+	return nil
+}
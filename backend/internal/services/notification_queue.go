@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/controlwise/backend/internal/config"
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/workflow"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+)
+
+// NotificationQueueService reports the outbound notification load: the depth of the worker's
+// queues, the messages waiting for a send slot under the provider and organization rate limits,
+// and the recipients of bulk trigger runs not dispatched yet
+type NotificationQueueService struct {
+	db        *database.DB
+	inspector *asynq.Inspector
+	limiter   *workflow.RateLimiter
+}
+
+// NewNotificationQueueService creates the service; without Redis it has no queues to report on
+func NewNotificationQueueService(db *database.DB, redis *database.Redis, cfg config.RateLimitConfig) *NotificationQueueService {
+	s := &NotificationQueueService{db: db}
+	if redis != nil {
+		s.inspector = asynq.NewInspectorFromRedisClient(redis.Client)
+		s.limiter = workflow.NewRateLimiter(redis.Client, db, cfg)
+	}
+	return s
+}
+
+// Stats returns the current outbound notification load
+func (s *NotificationQueueService) Stats(ctx context.Context) (*models.NotificationQueueStats, error) {
+	if s.inspector == nil {
+		return nil, errors.New("notification queues are not available without Redis")
+	}
+
+	stats := &models.NotificationQueueStats{
+		Queues:      []*models.NotificationQueueDepth{},
+		RunBacklogs: []*models.NotificationRunBacklog{},
+		GeneratedAt: time.Now(),
+	}
+
+	queues, err := s.inspector.Queues()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list queues: %w", err)
+	}
+	for _, queue := range queues {
+		info, err := s.inspector.GetQueueInfo(queue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get queue %s: %w", queue, err)
+		}
+		stats.Queues = append(stats.Queues, &models.NotificationQueueDepth{
+			Queue:          info.Queue,
+			Pending:        info.Pending,
+			Scheduled:      info.Scheduled,
+			Active:         info.Active,
+			Retry:          info.Retry,
+			LatencySeconds: info.Latency.Seconds(),
+		})
+	}
+
+	if stats.Backlogs, err = s.limiter.Backlogs(ctx); err != nil {
+		return nil, err
+	}
+	var orgIDs []uuid.UUID
+	for _, b := range stats.Backlogs {
+		if b.OrganizationID != nil {
+			orgIDs = append(orgIDs, *b.OrganizationID)
+		}
+	}
+	if len(orgIDs) > 0 {
+		rows, err := s.db.Pool.Query(ctx, `SELECT id, name FROM organizations WHERE id = ANY($1)`, orgIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get organization names: %w", err)
+		}
+		names := map[uuid.UUID]string{}
+		for rows.Next() {
+			var id uuid.UUID
+			var name string
+			if err := rows.Scan(&id, &name); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan organization name: %w", err)
+			}
+			names[id] = name
+		}
+		rows.Close()
+		for _, b := range stats.Backlogs {
+			if b.OrganizationID == nil {
+				continue
+			}
+			if name, ok := names[*b.OrganizationID]; ok {
+				b.OrganizationName = &name
+			}
+		}
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT r.organization_id, o.name, COUNT(DISTINCT r.id), COUNT(i.id)
+		FROM trigger_runs r
+		JOIN organizations o ON o.id = r.organization_id
+		JOIN trigger_run_items i ON i.run_id = r.id AND i.status = 'pending'
+		WHERE r.status IN ('pending', 'running')
+		GROUP BY r.organization_id, o.name
+		ORDER BY COUNT(i.id) DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending trigger runs: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var b models.NotificationRunBacklog
+		if err := rows.Scan(&b.OrganizationID, &b.OrganizationName, &b.Runs, &b.PendingRecipients); err != nil {
+			return nil, fmt.Errorf("failed to scan pending trigger run: %w", err)
+		}
+		stats.RunBacklogs = append(stats.RunBacklogs, &b)
+	}
+	return stats, nil
+}
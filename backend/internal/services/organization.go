@@ -9,7 +9,8 @@ import (
 )
 
 type OrganizationService struct {
-	db *database.DB
+	db      *database.DB
+	storage *StorageService
 }
 
 func NewOrganizationService(db *database.DB) *OrganizationService {
@@ -19,7 +20,7 @@ func NewOrganizationService(db *database.DB) *OrganizationService {
 func (s *OrganizationService) GetByID(ctx context.Context, id uuid.UUID) (*models.Organization, error) {
 	var org models.Organization
 	err := s.db.Pool.QueryRow(ctx, `
-		SELECT id, name, email, COALESCE(phone, ''), COALESCE(address, ''), COALESCE(tax_id, ''), logo, is_active, created_at, updated_at
+		SELECT id, name, email, COALESCE(phone, ''), COALESCE(address, ''), COALESCE(tax_id, ''), logo, default_country, timezone, is_active, created_at, updated_at
 		FROM organizations
 		WHERE id = $1 AND deleted_at IS NULL
 	`, id).Scan(
@@ -30,6 +31,8 @@ func (s *OrganizationService) GetByID(ctx context.Context, id uuid.UUID) (*model
 		&org.Address,
 		&org.TaxID,
 		&org.Logo,
+		&org.DefaultCountry,
+		&org.Timezone,
 		&org.IsActive,
 		&org.CreatedAt,
 		&org.UpdatedAt,
@@ -43,8 +46,10 @@ func (s *OrganizationService) GetByID(ctx context.Context, id uuid.UUID) (*model
 func (s *OrganizationService) Update(ctx context.Context, id uuid.UUID, org *models.Organization) error {
 	_, err := s.db.Pool.Exec(ctx, `
 		UPDATE organizations
-		SET name = $1, email = $2, phone = $3, address = $4, tax_id = $5
+		SET name = $1, email = $2, phone = $3, address = $4, tax_id = $5,
+			default_country = COALESCE(NULLIF($7, ''), default_country),
+			timezone = COALESCE(NULLIF($8, ''), timezone)
 		WHERE id = $6 AND deleted_at IS NULL
-	`, org.Name, org.Email, org.Phone, org.Address, org.TaxID, id)
+	`, org.Name, org.Email, org.Phone, org.Address, org.TaxID, id, org.DefaultCountry, org.Timezone)
 	return err
 }
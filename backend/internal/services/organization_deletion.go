@@ -0,0 +1,731 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	// organizationDeletionCoolingOff is how long a deletion request can be cancelled
+	organizationDeletionCoolingOff = 30 * 24 * time.Hour
+	// organizationDeletionExportWindow is how long the final export can be downloaded before
+	// the purge. Download links are signed for as long, which S3 caps at a week.
+	organizationDeletionExportWindow = 7 * 24 * time.Hour
+	// organizationDeletionStaleAfter releases requests left exporting or purging by a worker that stopped
+	organizationDeletionStaleAfter = 30 * time.Minute
+)
+
+// organizationDeletionReminders are how long before the end of the cooling-off period the
+// organization admins are reminded of a deletion request
+var organizationDeletionReminders = []time.Duration{7 * 24 * time.Hour, 24 * time.Hour}
+
+// OrganizationDeletionService runs tenant-initiated organization deletions: a cooling-off
+// period with reminders, a final export of the organization's data, then the purge. Every
+// step is recorded as an event of the request, which outlives the organization.
+type OrganizationDeletionService struct {
+	db          *database.DB
+	storage     *StorageService
+	email       *EmailService
+	frontendURL string
+}
+
+func NewOrganizationDeletionService(db *database.DB, storage *StorageService, email *EmailService, frontendURL string) *OrganizationDeletionService {
+	return &OrganizationDeletionService{db: db, storage: storage, email: email, frontendURL: frontendURL}
+}
+
+// OrganizationDeletionFilters filters the deletion requests system admins see
+type OrganizationDeletionFilters struct {
+	OrganizationID *uuid.UUID
+	Status         string
+	Page           int
+	Limit          int
+}
+
+// deletionEventExecer is satisfied by both the connection pool and a transaction
+type deletionEventExecer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+const organizationDeletionColumns = `id, organization_id, organization_name, status, reason, requested_by,
+	requested_by_email, cooling_off_ends_at, reminders_sent, cancelled_by, cancelled_at, export_file_url,
+	export_file_name, export_file_size, exported_at, purge_after, purge_report, error, completed_at,
+	created_at, updated_at`
+
+func scanOrganizationDeletion(row pgx.Row) (*models.OrganizationDeletionRequest, error) {
+	r := &models.OrganizationDeletionRequest{}
+	var report []byte
+	err := row.Scan(&r.ID, &r.OrganizationID, &r.OrganizationName, &r.Status, &r.Reason, &r.RequestedBy,
+		&r.RequestedByEmail, &r.CoolingOffEndsAt, &r.RemindersSent, &r.CancelledBy, &r.CancelledAt, &r.ExportFileURL,
+		&r.ExportFileName, &r.ExportFileSize, &r.ExportedAt, &r.PurgeAfter, &report, &r.Error, &r.CompletedAt,
+		&r.CreatedAt, &r.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if report != nil {
+		r.PurgeReport = &models.OrganizationPurgeReport{}
+		if err := json.Unmarshal(report, r.PurgeReport); err != nil {
+			return nil, fmt.Errorf("failed to decode purge report: %w", err)
+		}
+	}
+	return r, nil
+}
+
+// addDeletionEvent records a step of a deletion request. Events without a user or admin were taken by the worker.
+func addDeletionEvent(ctx context.Context, q deletionEventExecer, requestID uuid.UUID, event models.OrganizationDeletionEventType, userID, adminID *uuid.UUID, details map[string]interface{}) error {
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("failed to encode event details: %w", err)
+	}
+	_, err = q.Exec(ctx, `
+		INSERT INTO organization_deletion_events (request_id, event, user_id, admin_id, details)
+		VALUES ($1, $2, $3, $4, $5)
+	`, requestID, event, userID, adminID, detailsJSON)
+	if err != nil {
+		return fmt.Errorf("failed to record deletion event: %w", err)
+	}
+	return nil
+}
+
+// RequestDeletion starts the cooling-off period of an organization's deletion. The organization
+// name must be typed back as confirmation.
+func (s *OrganizationDeletionService) RequestDeletion(ctx context.Context, orgID, userID uuid.UUID, reason, confirmName string) (*models.OrganizationDeletionRequest, error) {
+	var orgName, email string
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT o.name, u.email FROM organizations o
+		JOIN users u ON u.id = $2 AND u.organization_id = o.id
+		WHERE o.id = $1 AND o.deleted_at IS NULL
+	`, orgID, userID).Scan(&orgName, &email)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("organization not found")
+		}
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+	if !strings.EqualFold(strings.TrimSpace(confirmName), strings.TrimSpace(orgName)) {
+		return nil, errors.New("confirmation does not match the organization name")
+	}
+
+	var reasonArg *string
+	if r := strings.TrimSpace(reason); r != "" {
+		reasonArg = &r
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	req, err := scanOrganizationDeletion(tx.QueryRow(ctx, `
+		INSERT INTO organization_deletion_requests (organization_id, organization_name, reason, requested_by,
+			requested_by_email, cooling_off_ends_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (organization_id) WHERE status NOT IN ('cancelled', 'completed') DO NOTHING
+		RETURNING `+organizationDeletionColumns,
+		orgID, orgName, reasonArg, userID, email, time.Now().Add(organizationDeletionCoolingOff)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("a deletion of this organization is already in progress")
+		}
+		return nil, fmt.Errorf("failed to create deletion request: %w", err)
+	}
+	if err := addDeletionEvent(ctx, tx, req.ID, models.OrganizationDeletionEventRequested, &userID, nil, map[string]interface{}{
+		"cooling_off_ends_at": req.CoolingOffEndsAt,
+	}); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.notifyAdmins(ctx, req, false)
+	return req, nil
+}
+
+// Current returns the organization's latest deletion request with its events, or nil
+func (s *OrganizationDeletionService) Current(ctx context.Context, orgID uuid.UUID) (*models.OrganizationDeletionRequest, error) {
+	req, err := scanOrganizationDeletion(s.db.Pool.QueryRow(ctx, `
+		SELECT `+organizationDeletionColumns+` FROM organization_deletion_requests
+		WHERE organization_id = $1
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, orgID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get deletion request: %w", err)
+	}
+	if req.Events, err = s.events(ctx, req.ID); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// List returns deletion requests for system admins, newest first
+func (s *OrganizationDeletionService) List(ctx context.Context, filters OrganizationDeletionFilters) ([]*models.OrganizationDeletionRequest, int, error) {
+	if filters.Page < 1 {
+		filters.Page = 1
+	}
+	if filters.Limit < 1 || filters.Limit > 100 {
+		filters.Limit = 50
+	}
+	offset := (filters.Page - 1) * filters.Limit
+
+	where := `WHERE ($1::uuid IS NULL OR organization_id = $1) AND ($2 = '' OR status = $2)`
+	args := []interface{}{filters.OrganizationID, filters.Status}
+
+	var total int
+	if err := s.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM organization_deletion_requests `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count deletion requests: %w", err)
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT `+organizationDeletionColumns+` FROM organization_deletion_requests
+		`+where+`
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`, append(args, filters.Limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list deletion requests: %w", err)
+	}
+	defer rows.Close()
+
+	requests := []*models.OrganizationDeletionRequest{}
+	for rows.Next() {
+		req, err := scanOrganizationDeletion(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan deletion request: %w", err)
+		}
+		requests = append(requests, req)
+	}
+	return requests, total, rows.Err()
+}
+
+// GetByID returns a deletion request with its events for system admins
+func (s *OrganizationDeletionService) GetByID(ctx context.Context, id uuid.UUID) (*models.OrganizationDeletionRequest, error) {
+	req, err := scanOrganizationDeletion(s.db.Pool.QueryRow(ctx, `
+		SELECT `+organizationDeletionColumns+` FROM organization_deletion_requests WHERE id = $1
+	`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("deletion request not found")
+		}
+		return nil, fmt.Errorf("failed to get deletion request: %w", err)
+	}
+	if req.Events, err = s.events(ctx, req.ID); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func (s *OrganizationDeletionService) events(ctx context.Context, requestID uuid.UUID) ([]models.OrganizationDeletionEvent, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, request_id, event, user_id, admin_id, details, created_at
+		FROM organization_deletion_events
+		WHERE request_id = $1
+		ORDER BY created_at, id
+	`, requestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deletion events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.OrganizationDeletionEvent{}
+	for rows.Next() {
+		var e models.OrganizationDeletionEvent
+		if err := rows.Scan(&e.ID, &e.RequestID, &e.Event, &e.UserID, &e.AdminID, &e.Details, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan deletion event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// Cancel stops a deletion request during its cooling-off period. Organization users cancel
+// their organization's request; system admins pass a nil orgID.
+func (s *OrganizationDeletionService) Cancel(ctx context.Context, id uuid.UUID, orgID, userID, adminID *uuid.UUID) error {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status models.OrganizationDeletionStatus
+	err = tx.QueryRow(ctx, `
+		SELECT status FROM organization_deletion_requests
+		WHERE id = $1 AND ($2::uuid IS NULL OR organization_id = $2)
+		FOR UPDATE
+	`, id, orgID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("deletion request not found")
+		}
+		return fmt.Errorf("failed to get deletion request: %w", err)
+	}
+	if status != models.OrganizationDeletionPending {
+		return errors.New("only deletion requests in their cooling-off period can be cancelled")
+	}
+
+	cancelledBy := userID
+	if cancelledBy == nil {
+		cancelledBy = adminID
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE organization_deletion_requests SET status = 'cancelled', cancelled_by = $2, cancelled_at = NOW()
+		WHERE id = $1
+	`, id, cancelledBy); err != nil {
+		return fmt.Errorf("failed to cancel deletion request: %w", err)
+	}
+	if err := addDeletionEvent(ctx, tx, id, models.OrganizationDeletionEventCancelled, userID, adminID, nil); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Retry resumes a failed deletion request from the step that failed
+func (s *OrganizationDeletionService) Retry(ctx context.Context, id, adminID uuid.UUID) (*models.OrganizationDeletionRequest, error) {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Requests that failed before their export was ready export again; the others purge
+	req, err := scanOrganizationDeletion(tx.QueryRow(ctx, `
+		UPDATE organization_deletion_requests
+		SET status = CASE WHEN exported_at IS NULL THEN 'pending' ELSE 'exported' END, error = NULL
+		WHERE id = $1 AND status = 'failed'
+		RETURNING `+organizationDeletionColumns, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("deletion request not found or not failed")
+		}
+		return nil, fmt.Errorf("failed to retry deletion request: %w", err)
+	}
+	if err := addDeletionEvent(ctx, tx, id, models.OrganizationDeletionEventRetried, nil, &adminID, nil); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return req, nil
+}
+
+// ExportDownloadURL returns a temporary link to the final export of a request, while it is available
+func (s *OrganizationDeletionService) ExportDownloadURL(ctx context.Context, id uuid.UUID) (string, error) {
+	var fileURL *string
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT export_file_url FROM organization_deletion_requests WHERE id = $1
+	`, id).Scan(&fileURL)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", errors.New("deletion request not found")
+		}
+		return "", fmt.Errorf("failed to get deletion request: %w", err)
+	}
+	if fileURL == nil {
+		return "", errors.New("the export is not available")
+	}
+	return s.storage.DownloadURL(ctx, *fileURL, exportDownloadExpiry)
+}
+
+// organizationDeletionRemindersDue returns how many reminders are due for a cooling-off
+// period ending at endsAt
+func organizationDeletionRemindersDue(endsAt, now time.Time) int {
+	due := 0
+	for _, before := range organizationDeletionReminders {
+		if !now.Before(endsAt.Add(-before)) {
+			due++
+		}
+	}
+	return due
+}
+
+// SendDueReminders reminds the organization admins of deletions whose cooling-off period is
+// running out. It is run by the worker.
+func (s *OrganizationDeletionService) SendDueReminders(ctx context.Context) (int, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT `+organizationDeletionColumns+` FROM organization_deletion_requests
+		WHERE status = 'pending' AND reminders_sent < $1 AND cooling_off_ends_at > NOW()
+		AND cooling_off_ends_at <= $2
+	`, len(organizationDeletionReminders), time.Now().Add(organizationDeletionReminders[0]))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list deletion requests: %w", err)
+	}
+	var requests []*models.OrganizationDeletionRequest
+	for rows.Next() {
+		req, err := scanOrganizationDeletion(rows)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan deletion request: %w", err)
+		}
+		requests = append(requests, req)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to list deletion requests: %w", err)
+	}
+
+	sent := 0
+	for _, req := range requests {
+		due := organizationDeletionRemindersDue(req.CoolingOffEndsAt, time.Now())
+		if due <= req.RemindersSent {
+			continue
+		}
+		// Only the worker that moves the counter sends the reminder
+		result, err := s.db.Pool.Exec(ctx, `
+			UPDATE organization_deletion_requests SET reminders_sent = $3
+			WHERE id = $1 AND reminders_sent = $2 AND status = 'pending'
+		`, req.ID, req.RemindersSent, due)
+		if err != nil {
+			return sent, fmt.Errorf("failed to update deletion request: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			continue
+		}
+		s.notifyAdmins(ctx, req, true)
+		if err := addDeletionEvent(ctx, s.db.Pool, req.ID, models.OrganizationDeletionEventReminderSent, nil, nil, map[string]interface{}{
+			"reminder": due,
+		}); err != nil {
+			log.Printf("[OrganizationDeletion] %v", err)
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// ProcessDueRequests runs the next step of the next deletion request due: the export once the
+// cooling-off period ends, the purge once the export window ends. Requests are claimed with
+// SKIP LOCKED so several workers never run the same one.
+func (s *OrganizationDeletionService) ProcessDueRequests(ctx context.Context) (int, error) {
+	req, err := scanOrganizationDeletion(s.db.Pool.QueryRow(ctx, `
+		UPDATE organization_deletion_requests
+		SET status = CASE WHEN status IN ('pending', 'exporting') THEN 'exporting' ELSE 'purging' END,
+			heartbeat_at = NOW()
+		WHERE id = (
+			SELECT id FROM organization_deletion_requests
+			WHERE (status = 'pending' AND cooling_off_ends_at <= NOW())
+				OR (status = 'exported' AND purge_after <= NOW())
+				OR (status IN ('exporting', 'purging') AND heartbeat_at < $1)
+			ORDER BY cooling_off_ends_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+organizationDeletionColumns, time.Now().Add(-organizationDeletionStaleAfter)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to claim deletion request: %w", err)
+	}
+
+	step := "export"
+	if req.Status == models.OrganizationDeletionExporting {
+		err = s.export(ctx, req)
+	} else {
+		step = "purge"
+		err = s.purge(ctx, req)
+	}
+	if err != nil {
+		log.Printf("[OrganizationDeletion] Request %s failed to %s: %v", req.ID, step, err)
+		if _, dbErr := s.db.Pool.Exec(ctx, `
+			UPDATE organization_deletion_requests SET status = 'failed', error = $2 WHERE id = $1
+		`, req.ID, err.Error()); dbErr != nil {
+			log.Printf("[OrganizationDeletion] Failed to record error of request %s: %v", req.ID, dbErr)
+		}
+		if evErr := addDeletionEvent(ctx, s.db.Pool, req.ID, models.OrganizationDeletionEventFailed, nil, nil, map[string]interface{}{
+			"step":  step,
+			"error": err.Error(),
+		}); evErr != nil {
+			log.Printf("[OrganizationDeletion] %v", evErr)
+		}
+		return 0, nil
+	}
+	return 1, nil
+}
+
+// export suspends the organization, so nothing changes after its data is exported, and
+// uploads a ZIP with a CSV file per data export entity
+func (s *OrganizationDeletionService) export(ctx context.Context, req *models.OrganizationDeletionRequest) error {
+	if err := addDeletionEvent(ctx, s.db.Pool, req.ID, models.OrganizationDeletionEventExportStarted, nil, nil, nil); err != nil {
+		return err
+	}
+	if _, err := s.db.Pool.Exec(ctx, `
+		UPDATE organizations
+		SET is_active = false, suspended_at = COALESCE(suspended_at, NOW()), suspend_reason = 'Deletion requested', updated_at = NOW()
+		WHERE id = $1
+	`, req.OrganizationID); err != nil {
+		return fmt.Errorf("failed to suspend organization: %w", err)
+	}
+
+	entities := make([]string, 0, len(dataExportSources))
+	for entity := range dataExportSources {
+		entities = append(entities, string(entity))
+	}
+	sort.Strings(entities)
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	counts := map[string]int{}
+	args := []interface{}{req.OrganizationID, nil, nil}
+	for _, entity := range entities {
+		w, err := archive.Create(entity + ".csv")
+		if err != nil {
+			return fmt.Errorf("failed to write export: %w", err)
+		}
+		written, err := dataExportSources[models.DataExportEntity(entity)].writeCSV(ctx, s.db, w, args, nil)
+		if err != nil {
+			return fmt.Errorf("failed to export %s: %w", entity, err)
+		}
+		counts[entity] = written
+	}
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+
+	fileName := fmt.Sprintf("organization-export-%s.zip", time.Now().Format("2006-01-02"))
+	upload, err := s.storage.UploadGenerated(ctx, buf.Bytes(), fileName, "application/zip", req.OrganizationID)
+	if err != nil {
+		return fmt.Errorf("failed to upload export: %w", err)
+	}
+
+	purgeAfter := time.Now().Add(organizationDeletionExportWindow)
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE organization_deletion_requests
+		SET status = 'exported', export_file_url = $2, export_file_name = $3, export_file_size = $4,
+			exported_at = NOW(), purge_after = $5, error = NULL
+		WHERE id = $1
+	`, req.ID, upload.URL, fileName, upload.FileSize, purgeAfter); err != nil {
+		return fmt.Errorf("failed to update deletion request: %w", err)
+	}
+	if err := addDeletionEvent(ctx, tx, req.ID, models.OrganizationDeletionEventExported, nil, nil, map[string]interface{}{
+		"file_name":   fileName,
+		"file_size":   upload.FileSize,
+		"rows":        counts,
+		"purge_after": purgeAfter,
+	}); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	link, err := s.storage.DownloadURL(ctx, upload.URL, organizationDeletionExportWindow)
+	if err != nil {
+		log.Printf("[OrganizationDeletion] Failed to sign export link of request %s: %v", req.ID, err)
+		return nil
+	}
+	for _, admin := range s.admins(ctx, req) {
+		if err := s.email.SendOrganizationDeletionExport(admin.email, admin.name, req.OrganizationName, link, purgeAfter); err != nil {
+			log.Printf("[OrganizationDeletion] Failed to send export of request %s to %s: %v", req.ID, admin.email, err)
+		}
+	}
+	return nil
+}
+
+// purge removes every row of the organization in one transaction, then its files, including
+// the export. Files are only deleted once the purge is committed, so a failed purge still has
+// its export; files a failed deletion leaves behind are logged and recorded on the request
+// for retry.
+func (s *OrganizationDeletionService) purge(ctx context.Context, req *models.OrganizationDeletionRequest) error {
+	if err := addDeletionEvent(ctx, s.db.Pool, req.ID, models.OrganizationDeletionEventPurgeStarted, nil, nil, nil); err != nil {
+		return err
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := purgeOrganization(ctx, tx, req.OrganizationID)
+	if err != nil {
+		return err
+	}
+	reportJSON, err := json.Marshal(models.OrganizationPurgeReport{Rows: rows})
+	if err != nil {
+		return fmt.Errorf("failed to encode purge report: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE organization_deletion_requests
+		SET status = 'completed', purge_report = $2, export_file_url = NULL, error = NULL, completed_at = NOW()
+		WHERE id = $1
+	`, req.ID, reportJSON); err != nil {
+		return fmt.Errorf("failed to complete deletion request: %w", err)
+	}
+	if err := addDeletionEvent(ctx, tx, req.ID, models.OrganizationDeletionEventPurged, nil, nil, map[string]interface{}{
+		"rows": rows,
+	}); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.purgeFiles(ctx, req)
+	return nil
+}
+
+// purgeFiles deletes the files of a purged organization and records how many were removed.
+// A failure does not undo the purge: it is logged with the organization to retry and kept
+// as the request's error.
+func (s *OrganizationDeletionService) purgeFiles(ctx context.Context, req *models.OrganizationDeletionRequest) {
+	files, err := s.storage.DeleteOrganizationFiles(ctx, req.OrganizationID)
+	if err != nil {
+		log.Printf("[OrganizationDeletion] Failed to delete files of organization %s (request %s), retry required: %v", req.OrganizationID, req.ID, err)
+		if _, dbErr := s.db.Pool.Exec(ctx, `
+			UPDATE organization_deletion_requests SET error = $2 WHERE id = $1
+		`, req.ID, fmt.Sprintf("failed to delete files: %v", err)); dbErr != nil {
+			log.Printf("[OrganizationDeletion] Failed to record file deletion error of request %s: %v", req.ID, dbErr)
+		}
+		return
+	}
+
+	if _, err := s.db.Pool.Exec(ctx, `
+		UPDATE organization_deletion_requests
+		SET purge_report = jsonb_set(purge_report, '{files}', to_jsonb($2::int))
+		WHERE id = $1
+	`, req.ID, files); err != nil {
+		log.Printf("[OrganizationDeletion] Failed to record files removed by request %s: %v", req.ID, err)
+	}
+}
+
+// purgeOrganization deletes the organization and every row of it. Tables are found through
+// their organization_id column and deleted in passes: a table still referenced by rows of
+// another one is retried after it, and tables without the column go with their parents'
+// ON DELETE CASCADE. It returns the rows deleted per table.
+func purgeOrganization(ctx context.Context, tx pgx.Tx, orgID uuid.UUID) (map[string]int64, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT c.table_name
+		FROM information_schema.columns c
+		JOIN information_schema.tables t ON t.table_schema = c.table_schema AND t.table_name = c.table_name
+		WHERE c.table_schema = current_schema() AND c.column_name = 'organization_id'
+			AND t.table_type = 'BASE TABLE' AND c.table_name <> 'organization_deletion_requests'
+		ORDER BY c.table_name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization tables: %w", err)
+	}
+	var pending []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to list organization tables: %w", err)
+		}
+		pending = append(pending, table)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list organization tables: %w", err)
+	}
+
+	deleted := map[string]int64{}
+	for len(pending) > 0 {
+		var blocked []string
+		var lastErr error
+		for _, table := range pending {
+			if _, err := tx.Exec(ctx, `SAVEPOINT purge_table`); err != nil {
+				return nil, fmt.Errorf("failed to purge %s: %w", table, err)
+			}
+			result, err := tx.Exec(ctx, `DELETE FROM `+pgx.Identifier{table}.Sanitize()+` WHERE organization_id = $1`, orgID)
+			if err != nil {
+				if _, rbErr := tx.Exec(ctx, `ROLLBACK TO SAVEPOINT purge_table`); rbErr != nil {
+					return nil, fmt.Errorf("failed to purge %s: %w", table, rbErr)
+				}
+				blocked = append(blocked, table)
+				lastErr = err
+				continue
+			}
+			if _, err := tx.Exec(ctx, `RELEASE SAVEPOINT purge_table`); err != nil {
+				return nil, fmt.Errorf("failed to purge %s: %w", table, err)
+			}
+			if n := result.RowsAffected(); n > 0 {
+				deleted[table] += n
+			}
+		}
+		if len(blocked) == len(pending) {
+			return nil, fmt.Errorf("failed to purge %s: %w", strings.Join(blocked, ", "), lastErr)
+		}
+		pending = blocked
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM organizations WHERE id = $1`, orgID); err != nil {
+		return nil, fmt.Errorf("failed to purge organization: %w", err)
+	}
+	deleted["organizations"] = 1
+	return deleted, nil
+}
+
+type deletionRecipient struct {
+	email string
+	name  string
+}
+
+// admins returns the organization admins to notify about a deletion request, with the
+// requester when they are no longer an admin
+func (s *OrganizationDeletionService) admins(ctx context.Context, req *models.OrganizationDeletionRequest) []deletionRecipient {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT email, first_name FROM users
+		WHERE organization_id = $1 AND role = 'admin' AND is_active AND deleted_at IS NULL
+	`, req.OrganizationID)
+	if err != nil {
+		log.Printf("[OrganizationDeletion] Failed to list admins of organization %s: %v", req.OrganizationID, err)
+		return []deletionRecipient{{email: req.RequestedByEmail}}
+	}
+	defer rows.Close()
+
+	var recipients []deletionRecipient
+	requester := false
+	for rows.Next() {
+		var r deletionRecipient
+		if err := rows.Scan(&r.email, &r.name); err != nil {
+			log.Printf("[OrganizationDeletion] Failed to scan admin: %v", err)
+			continue
+		}
+		requester = requester || strings.EqualFold(r.email, req.RequestedByEmail)
+		recipients = append(recipients, r)
+	}
+	if !requester {
+		recipients = append(recipients, deletionRecipient{email: req.RequestedByEmail})
+	}
+	return recipients
+}
+
+// notifyAdmins tells the organization admins about a deletion request and how to cancel it
+func (s *OrganizationDeletionService) notifyAdmins(ctx context.Context, req *models.OrganizationDeletionRequest, reminder bool) {
+	link := s.frontendURL + "/settings/organization"
+	for _, admin := range s.admins(ctx, req) {
+		if err := s.email.SendOrganizationDeletionNotice(admin.email, admin.name, req.OrganizationName, req.CoolingOffEndsAt, reminder, link); err != nil {
+			log.Printf("[OrganizationDeletion] Failed to notify %s of request %s: %v", admin.email, req.ID, err)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOrganizationDeletionRemindersDue(t *testing.T) {
+	endsAt := time.Date(2026, 5, 31, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want int
+	}{
+		{"just requested", endsAt.Add(-30 * 24 * time.Hour), 0},
+		{"a week before", endsAt.Add(-7 * 24 * time.Hour), 1},
+		{"three days before", endsAt.Add(-3 * 24 * time.Hour), 1},
+		{"the day before", endsAt.Add(-24 * time.Hour), 2},
+		{"an hour before", endsAt.Add(-time.Hour), 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := organizationDeletionRemindersDue(endsAt, tt.now); got != tt.want {
+				t.Errorf("organizationDeletionRemindersDue() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
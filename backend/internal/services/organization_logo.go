@@ -0,0 +1,136 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// Logos are scaled down to fit these dimensions, keeping their aspect ratio, and stored as PNG
+const (
+	LogoMaxWidth  = 512
+	LogoMaxHeight = 512
+	// MaxLogoUploadSize is the maximum size of an uploaded logo before processing (5MB)
+	MaxLogoUploadSize int64 = 5 << 20
+	// maxLogoPixels guards against decompression bombs
+	maxLogoPixels = 25_000_000
+)
+
+// SetStorageService sets the storage used for organization logos
+func (s *OrganizationService) SetStorageService(storage *StorageService) {
+	s.storage = storage
+}
+
+// UploadLogo validates and resizes an uploaded logo, stores it and saves its URL on the
+// organization. The previous logo is removed from storage.
+func (s *OrganizationService) UploadLogo(ctx context.Context, orgID uuid.UUID, data []byte) (*models.Organization, error) {
+	if s.storage == nil {
+		return nil, errors.New("storage is not configured")
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("logo must be a PNG, JPEG or GIF image")
+	}
+	if cfg.Width == 0 || cfg.Height == 0 || cfg.Width*cfg.Height > maxLogoPixels {
+		return nil, errors.New("logo dimensions are too large")
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s logo: %w", format, err)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, resizeToFit(img, LogoMaxWidth, LogoMaxHeight)); err != nil {
+		return nil, fmt.Errorf("failed to encode logo: %w", err)
+	}
+
+	org, err := s.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, errors.New("organization not found")
+	}
+
+	result, err := s.storage.UploadData(ctx, buf.Bytes(), "logo.png", "image/png", orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store logo: %w", err)
+	}
+
+	_, err = s.db.Pool.Exec(ctx, `
+		UPDATE organizations SET logo = $1 WHERE id = $2 AND deleted_at IS NULL
+	`, result.URL, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save logo: %w", err)
+	}
+
+	if org.Logo != nil && *org.Logo != "" {
+		if err := s.storage.DeleteFile(ctx, *org.Logo); err != nil {
+			fmt.Printf("Warning: failed to delete previous logo: %v\n", err)
+		}
+	}
+
+	org.Logo = &result.URL
+	return org, nil
+}
+
+// resizeToFit scales an image down to fit within maxWidth x maxHeight, averaging the source
+// pixels covered by each destination pixel. Smaller images are only converted.
+func resizeToFit(src image.Image, maxWidth, maxHeight int) *image.NRGBA {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	scale := 1.0
+	if width > maxWidth {
+		scale = float64(maxWidth) / float64(width)
+	}
+	if h := float64(maxHeight) / float64(height); height > maxHeight && h < scale {
+		scale = h
+	}
+
+	if scale == 1.0 {
+		dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+		draw.Draw(dst, dst.Bounds(), src, bounds.Min, draw.Src)
+		return dst
+	}
+
+	dstWidth := max(1, int(float64(width)*scale))
+	dstHeight := max(1, int(float64(height)*scale))
+	dst := image.NewNRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	for y := 0; y < dstHeight; y++ {
+		y0 := bounds.Min.Y + y*height/dstHeight
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*height/dstHeight)
+		for x := 0; x < dstWidth; x++ {
+			x0 := bounds.Min.X + x*width/dstWidth
+			x1 := max(x0+1, bounds.Min.X+(x+1)*width/dstWidth)
+
+			// Average in premultiplied alpha so transparent pixels don't darken edges
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(b / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,993 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// defaultMergeNumberPrefix is prepended to clashing budget and project numbers when no prefix is given
+	defaultMergeNumberPrefix = "MRG-"
+	// mergeStaleAfter releases merges left processing by a worker that stopped
+	mergeStaleAfter = 15 * time.Minute
+)
+
+// OrganizationMergeService merges one organization into another for system admins
+type OrganizationMergeService struct {
+	db *database.DB
+}
+
+func NewOrganizationMergeService(db *database.DB) *OrganizationMergeService {
+	return &OrganizationMergeService{db: db}
+}
+
+const organizationMergeColumns = `id, source_organization_id, target_organization_id, dry_run, status, rules, report,
+	current_step, error, requested_by, created_at, started_at, completed_at`
+
+func scanOrganizationMerge(row pgx.Row) (*models.OrganizationMerge, error) {
+	m := &models.OrganizationMerge{}
+	var rules, report []byte
+	err := row.Scan(&m.ID, &m.SourceOrganizationID, &m.TargetOrganizationID, &m.DryRun, &m.Status, &rules, &report,
+		&m.CurrentStep, &m.Error, &m.RequestedBy, &m.CreatedAt, &m.StartedAt, &m.CompletedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(rules, &m.Rules); err != nil {
+		return nil, fmt.Errorf("failed to decode merge rules: %w", err)
+	}
+	if err := json.Unmarshal(report, &m.Report); err != nil {
+		return nil, fmt.Errorf("failed to decode merge report: %w", err)
+	}
+	return m, nil
+}
+
+// normalizeMergeRules fills in the default resolutions and rejects unknown ones
+func normalizeMergeRules(rules *models.OrganizationMergeRules) error {
+	if rules.Clients == "" {
+		rules.Clients = models.MergeResolveMerge
+	}
+	if rules.Workflows == "" {
+		rules.Workflows = models.MergeResolveRename
+	}
+	if rules.Templates == "" {
+		rules.Templates = models.MergeResolveRename
+	}
+	if rules.NumberPrefix == "" {
+		rules.NumberPrefix = defaultMergeNumberPrefix
+	}
+
+	if rules.Clients != models.MergeResolveMerge && rules.Clients != models.MergeResolveKeepBoth {
+		return errors.New("clients rule must be merge or keep_both")
+	}
+	if rules.Workflows != models.MergeResolveRename && rules.Workflows != models.MergeResolveSkip {
+		return errors.New("workflows rule must be rename or skip")
+	}
+	if rules.Templates != models.MergeResolveRename && rules.Templates != models.MergeResolveSkip {
+		return errors.New("templates rule must be rename or skip")
+	}
+	if len(rules.NumberPrefix) > 10 {
+		return errors.New("number prefix must be at most 10 characters")
+	}
+	return nil
+}
+
+// RequestMerge queues a merge of the source organization into the target one. A dry run only
+// reports what the merge would do. A merge is refused while the source holds records that can't be
+// moved to the target, e.g. issued invoices.
+func (s *OrganizationMergeService) RequestMerge(ctx context.Context, targetID, sourceID, adminID uuid.UUID, rules models.OrganizationMergeRules, dryRun bool) (*models.OrganizationMerge, error) {
+	if sourceID == targetID {
+		return nil, errors.New("cannot merge an organization into itself")
+	}
+	if err := normalizeMergeRules(&rules); err != nil {
+		return nil, err
+	}
+
+	var found int
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM organizations WHERE id = ANY($1) AND deleted_at IS NULL
+	`, []uuid.UUID{sourceID, targetID}).Scan(&found)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check organizations: %w", err)
+	}
+	if found != 2 {
+		return nil, errors.New("organization not found")
+	}
+
+	if !dryRun {
+		var active bool
+		err := s.db.Pool.QueryRow(ctx, `
+			SELECT EXISTS (
+				SELECT 1 FROM organization_merges
+				WHERE dry_run = false AND status IN ('pending', 'processing')
+				AND (source_organization_id = ANY($1) OR target_organization_id = ANY($1))
+			)
+		`, []uuid.UUID{sourceID, targetID}).Scan(&active)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check running merges: %w", err)
+		}
+		if active {
+			return nil, errors.New("a merge involving these organizations is already in progress")
+		}
+		if err := s.checkMovable(ctx, sourceID, targetID); err != nil {
+			return nil, err
+		}
+	}
+
+	rulesJSON, err := json.Marshal(rules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode merge rules: %w", err)
+	}
+
+	merge, err := scanOrganizationMerge(s.db.Pool.QueryRow(ctx, `
+		INSERT INTO organization_merges (source_organization_id, target_organization_id, dry_run, rules, requested_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+organizationMergeColumns, sourceID, targetID, dryRun, rulesJSON, adminID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create merge: %w", err)
+	}
+	return merge, nil
+}
+
+// ListMerges returns the merges an organization took part in, newest first
+func (s *OrganizationMergeService) ListMerges(ctx context.Context, orgID uuid.UUID, limit, offset int) ([]*models.OrganizationMerge, int, error) {
+	var total int
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM organization_merges
+		WHERE source_organization_id = $1 OR target_organization_id = $1
+	`, orgID).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count merges: %w", err)
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT `+organizationMergeColumns+`
+		FROM organization_merges
+		WHERE source_organization_id = $1 OR target_organization_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`, orgID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list merges: %w", err)
+	}
+	defer rows.Close()
+
+	merges := []*models.OrganizationMerge{}
+	for rows.Next() {
+		m, err := scanOrganizationMerge(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan merge: %w", err)
+		}
+		merges = append(merges, m)
+	}
+	return merges, total, rows.Err()
+}
+
+// GetMerge returns a merge the organization took part in
+func (s *OrganizationMergeService) GetMerge(ctx context.Context, id, orgID uuid.UUID) (*models.OrganizationMerge, error) {
+	m, err := scanOrganizationMerge(s.db.Pool.QueryRow(ctx, `
+		SELECT `+organizationMergeColumns+`
+		FROM organization_merges
+		WHERE id = $1 AND (source_organization_id = $2 OR target_organization_id = $2)
+	`, id, orgID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("merge not found")
+		}
+		return nil, fmt.Errorf("failed to get merge: %w", err)
+	}
+	return m, nil
+}
+
+// ResumeMerge queues a failed merge again. It continues after the last completed step.
+func (s *OrganizationMergeService) ResumeMerge(ctx context.Context, id, orgID uuid.UUID) (*models.OrganizationMerge, error) {
+	m, err := s.GetMerge(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if m.Status != models.MergeFailed {
+		return nil, errors.New("only failed merges can be resumed")
+	}
+
+	m, err = scanOrganizationMerge(s.db.Pool.QueryRow(ctx, `
+		UPDATE organization_merges SET status = 'pending', error = NULL, completed_at = NULL
+		WHERE id = $1
+		RETURNING `+organizationMergeColumns, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resume merge: %w", err)
+	}
+	return m, nil
+}
+
+// ProcessPendingMerges runs the next queued merge, or one left processing by a stopped worker
+func (s *OrganizationMergeService) ProcessPendingMerges(ctx context.Context) (int, error) {
+	m, err := scanOrganizationMerge(s.db.Pool.QueryRow(ctx, `
+		UPDATE organization_merges SET status = 'processing', started_at = COALESCE(started_at, NOW()), heartbeat_at = NOW()
+		WHERE id = (
+			SELECT id FROM organization_merges
+			WHERE status = 'pending' OR (status = 'processing' AND heartbeat_at < $1)
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+organizationMergeColumns, time.Now().Add(-mergeStaleAfter)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to claim merge: %w", err)
+	}
+
+	if m.DryRun {
+		err = s.dryRun(ctx, m)
+	} else {
+		err = s.run(ctx, m)
+	}
+	if err != nil {
+		log.Printf("[OrganizationMerge] Merge %s failed: %v", m.ID, err)
+		if _, dbErr := s.db.Pool.Exec(ctx, `
+			UPDATE organization_merges SET status = 'failed', error = $2, completed_at = NOW() WHERE id = $1
+		`, m.ID, err.Error()); dbErr != nil {
+			log.Printf("[OrganizationMerge] Failed to record error of merge %s: %v", m.ID, dbErr)
+		}
+		return 0, nil
+	}
+	return 1, nil
+}
+
+// dryRun runs every step in one transaction and rolls it back, keeping the reports
+func (s *OrganizationMergeService) dryRun(ctx context.Context, m *models.OrganizationMerge) error {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var reports []models.MergeStepReport
+	for _, step := range models.MergeSteps {
+		report, err := s.runStep(ctx, tx, m, step)
+		if err != nil {
+			return fmt.Errorf("step %s: %w", step, err)
+		}
+		reports = append(reports, *report)
+	}
+	if err := tx.Rollback(ctx); err != nil {
+		return fmt.Errorf("failed to roll back dry run: %w", err)
+	}
+
+	reportJSON, err := json.Marshal(reports)
+	if err != nil {
+		return fmt.Errorf("failed to encode merge report: %w", err)
+	}
+	_, err = s.db.Pool.Exec(ctx, `
+		UPDATE organization_merges SET status = 'completed', report = $2, current_step = NULL, completed_at = NOW()
+		WHERE id = $1
+	`, m.ID, reportJSON)
+	if err != nil {
+		return fmt.Errorf("failed to save merge report: %w", err)
+	}
+	return nil
+}
+
+// run commits the steps one by one, each with its report, skipping those already done. It fails
+// before moving anything when some records couldn't follow.
+func (s *OrganizationMergeService) run(ctx context.Context, m *models.OrganizationMerge) error {
+	if err := s.checkMovable(ctx, m.SourceOrganizationID, m.TargetOrganizationID); err != nil {
+		return err
+	}
+
+	for _, step := range models.MergeSteps {
+		if err := s.commitStep(ctx, m, step); err != nil {
+			return err
+		}
+	}
+
+	_, err := s.db.Pool.Exec(ctx, `
+		UPDATE organization_merges SET status = 'completed', current_step = NULL, completed_at = NOW() WHERE id = $1
+	`, m.ID)
+	if err != nil {
+		return fmt.Errorf("failed to complete merge: %w", err)
+	}
+	return nil
+}
+
+// commitStep runs a step and appends its report in the same transaction, unless it already ran
+func (s *OrganizationMergeService) commitStep(ctx context.Context, m *models.OrganizationMerge, step models.MergeStep) error {
+	if _, err := s.db.Pool.Exec(ctx, `
+		UPDATE organization_merges SET current_step = $2, heartbeat_at = NOW() WHERE id = $1
+	`, m.ID, step); err != nil {
+		log.Printf("[OrganizationMerge] Failed to record progress of merge %s: %v", m.ID, err)
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the merge and re-read its report, in case another worker finished the step meanwhile
+	var reportJSON []byte
+	if err := tx.QueryRow(ctx, `
+		SELECT report FROM organization_merges WHERE id = $1 FOR UPDATE
+	`, m.ID).Scan(&reportJSON); err != nil {
+		return fmt.Errorf("failed to lock merge: %w", err)
+	}
+	var done []models.MergeStepReport
+	if err := json.Unmarshal(reportJSON, &done); err != nil {
+		return fmt.Errorf("failed to decode merge report: %w", err)
+	}
+	for _, r := range done {
+		if r.Step == step {
+			return nil
+		}
+	}
+
+	report, err := s.runStep(ctx, tx, m, step)
+	if err != nil {
+		return fmt.Errorf("step %s: %w", step, err)
+	}
+	stepJSON, err := json.Marshal([]models.MergeStepReport{*report})
+	if err != nil {
+		return fmt.Errorf("failed to encode merge report: %w", err)
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE organization_merges SET report = report || $2::jsonb, heartbeat_at = NOW() WHERE id = $1
+	`, m.ID, stepJSON)
+	if err != nil {
+		return fmt.Errorf("failed to save merge report: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// runStep moves the records of one step from the source to the target organization
+func (s *OrganizationMergeService) runStep(ctx context.Context, tx pgx.Tx, m *models.OrganizationMerge, step models.MergeStep) (*models.MergeStepReport, error) {
+	r := &models.MergeStepReport{Step: step, Moved: map[string]int{}, Conflicts: []models.MergeConflict{}}
+	var err error
+	switch step {
+	case models.MergeStepUsers:
+		err = mergeUsers(ctx, tx, m, r)
+	case models.MergeStepClients:
+		err = mergeClients(ctx, tx, m, r)
+	case models.MergeStepPatients:
+		err = mergePatients(ctx, tx, m, r)
+	case models.MergeStepTherapists:
+		err = moveRows(ctx, tx, m, r, "therapists", "bookable_services")
+	case models.MergeStepWorkflows:
+		err = mergeWorkflows(ctx, tx, m, r)
+	case models.MergeStepProjects:
+		err = mergeProjects(ctx, tx, m, r)
+	case models.MergeStepSessions:
+		err = mergeSessions(ctx, tx, m, r)
+	case models.MergeStepRecords:
+		err = mergeRecords(ctx, tx, m, r)
+	case models.MergeStepFinalize:
+		_, err = tx.Exec(ctx, `UPDATE organizations SET is_active = false WHERE id = $1`, m.SourceOrganizationID)
+	default:
+		err = fmt.Errorf("unknown step %s", step)
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.CompletedAt = time.Now()
+	return r, nil
+}
+
+// moveRows moves every row of the tables from the source to the target organization
+func moveRows(ctx context.Context, tx pgx.Tx, m *models.OrganizationMerge, r *models.MergeStepReport, tables ...string) error {
+	for _, table := range tables {
+		if err := moveRowsExcept(ctx, tx, m, r, table, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveRowsExcept moves the rows of a table from the source to the target organization, leaving
+// out the given ids
+func moveRowsExcept(ctx context.Context, tx pgx.Tx, m *models.OrganizationMerge, r *models.MergeStepReport, table string, except []uuid.UUID) error {
+	query := `UPDATE ` + table + ` SET organization_id = $2 WHERE organization_id = $1`
+	args := []interface{}{m.SourceOrganizationID, m.TargetOrganizationID}
+	if len(except) > 0 {
+		query += ` AND NOT (id = ANY($3))`
+		args = append(args, except)
+	}
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to move %s: %w", table, err)
+	}
+	r.Moved[table] += int(tag.RowsAffected())
+	return nil
+}
+
+// mergeConflicts returns the source records matching a target record, as source id, target id and key
+func mergeConflicts(ctx context.Context, tx pgx.Tx, m *models.OrganizationMerge, query string) ([]models.MergeConflict, error) {
+	rows, err := tx.Query(ctx, query, m.SourceOrganizationID, m.TargetOrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find conflicts: %w", err)
+	}
+	defer rows.Close()
+
+	var conflicts []models.MergeConflict
+	for rows.Next() {
+		var c models.MergeConflict
+		var targetID uuid.UUID
+		if err := rows.Scan(&c.SourceID, &targetID, &c.Key); err != nil {
+			return nil, fmt.Errorf("failed to scan conflict: %w", err)
+		}
+		c.TargetID = &targetID
+		conflicts = append(conflicts, c)
+	}
+	return conflicts, rows.Err()
+}
+
+// mergeUsers moves the users. A user whose email exists in the target stays behind deactivated,
+// and their therapist profiles and open tasks go to the target account.
+func mergeUsers(ctx context.Context, tx pgx.Tx, m *models.OrganizationMerge, r *models.MergeStepReport) error {
+	conflicts, err := mergeConflicts(ctx, tx, m, `
+		SELECT DISTINCT ON (s.id) s.id, t.id, s.email
+		FROM users s
+		JOIN users t ON t.organization_id = $2 AND LOWER(t.email) = LOWER(s.email)
+		WHERE s.organization_id = $1
+		ORDER BY s.id, t.deleted_at NULLS FIRST
+	`)
+	if err != nil {
+		return err
+	}
+
+	var merged []uuid.UUID
+	for _, c := range conflicts {
+		if _, err := tx.Exec(ctx, `UPDATE therapists SET user_id = $2 WHERE user_id = $1`, c.SourceID, *c.TargetID); err != nil {
+			return fmt.Errorf("failed to reassign therapist profiles: %w", err)
+		}
+		if _, err := tx.Exec(ctx, `
+			UPDATE tasks SET assigned_to = $2 WHERE assigned_to = $1 AND status NOT IN ('completed', 'cancelled')
+		`, c.SourceID, *c.TargetID); err != nil {
+			return fmt.Errorf("failed to reassign tasks: %w", err)
+		}
+		if _, err := tx.Exec(ctx, `UPDATE users SET is_active = false WHERE id = $1`, c.SourceID); err != nil {
+			return fmt.Errorf("failed to deactivate user: %w", err)
+		}
+		c.Entity, c.Resolution = "user", models.MergeResolveMerge
+		r.Conflicts = append(r.Conflicts, c)
+		merged = append(merged, c.SourceID)
+	}
+	r.Merged = len(merged)
+
+	return moveRowsExcept(ctx, tx, m, r, "users", merged)
+}
+
+// mergeClients moves the clients. With the merge rule, a client whose email exists in the target
+// hands their worksheets and patients to the target client and stays behind deleted.
+func mergeClients(ctx context.Context, tx pgx.Tx, m *models.OrganizationMerge, r *models.MergeStepReport) error {
+	conflicts, err := mergeConflicts(ctx, tx, m, `
+		SELECT DISTINCT ON (s.id) s.id, t.id, s.email
+		FROM clients s
+		JOIN clients t ON t.organization_id = $2 AND t.deleted_at IS NULL AND LOWER(t.email) = LOWER(s.email)
+		WHERE s.organization_id = $1 AND s.deleted_at IS NULL
+		ORDER BY s.id, t.created_at
+	`)
+	if err != nil {
+		return err
+	}
+
+	var merged []uuid.UUID
+	for _, c := range conflicts {
+		c.Entity, c.Resolution = "client", m.Rules.Clients
+		r.Conflicts = append(r.Conflicts, c)
+		if m.Rules.Clients != models.MergeResolveMerge {
+			continue
+		}
+		for _, table := range []string{"worksheets", "patients"} {
+			if _, err := tx.Exec(ctx, `UPDATE `+table+` SET client_id = $2 WHERE client_id = $1`, c.SourceID, *c.TargetID); err != nil {
+				return fmt.Errorf("failed to reassign %s: %w", table, err)
+			}
+		}
+		if _, err := tx.Exec(ctx, `UPDATE clients SET deleted_at = NOW() WHERE id = $1`, c.SourceID); err != nil {
+			return fmt.Errorf("failed to delete merged client: %w", err)
+		}
+		merged = append(merged, c.SourceID)
+	}
+	r.Merged = len(merged)
+
+	return moveRowsExcept(ctx, tx, m, r, "clients", merged)
+}
+
+// mergePatients moves the patients. A patient whose client's phone is a target patient's client's
+// phone, which includes clients merged in the previous step, hands their sessions to the target
+// patient and stays behind deleted.
+func mergePatients(ctx context.Context, tx pgx.Tx, m *models.OrganizationMerge, r *models.MergeStepReport) error {
+	conflicts, err := mergeConflicts(ctx, tx, m, `
+		SELECT DISTINCT ON (s.id) s.id, t.id, sc.phone
+		FROM patients s
+		JOIN clients sc ON sc.id = s.client_id
+		JOIN clients tc ON tc.organization_id = $2 AND tc.phone = sc.phone
+		JOIN patients t ON t.organization_id = $2 AND t.client_id = tc.id
+		WHERE s.organization_id = $1
+		ORDER BY s.id, t.deleted_at NULLS FIRST, t.created_at
+	`)
+	if err != nil {
+		return err
+	}
+
+	var merged []uuid.UUID
+	for _, c := range conflicts {
+		for _, table := range []string{"sessions", "session_series"} {
+			if _, err := tx.Exec(ctx, `UPDATE `+table+` SET patient_id = $2 WHERE patient_id = $1`, c.SourceID, *c.TargetID); err != nil {
+				return fmt.Errorf("failed to reassign %s: %w", table, err)
+			}
+		}
+		// A deleted target patient comes back when the source one was still active
+		if _, err := tx.Exec(ctx, `
+			UPDATE patients t SET deleted_at = NULL
+			FROM patients s
+			WHERE t.id = $2 AND s.id = $1 AND t.deleted_at IS NOT NULL AND s.deleted_at IS NULL
+		`, c.SourceID, *c.TargetID); err != nil {
+			return fmt.Errorf("failed to restore patient: %w", err)
+		}
+		if _, err := tx.Exec(ctx, `UPDATE patients SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`, c.SourceID); err != nil {
+			return fmt.Errorf("failed to delete merged patient: %w", err)
+		}
+		c.Entity, c.Resolution = "patient", models.MergeResolveMerge
+		r.Conflicts = append(r.Conflicts, c)
+		merged = append(merged, c.SourceID)
+	}
+	r.Merged = len(merged)
+
+	return moveRowsExcept(ctx, tx, m, r, "patients", merged)
+}
+
+// mergeWorkflows moves the workflows with their execution history and the message templates.
+// Moved workflows are never the target's default. Name clashes are renamed or skipped per the rules;
+// actions of moved workflows using a skipped template use the target template of the same name.
+func mergeWorkflows(ctx context.Context, tx pgx.Tx, m *models.OrganizationMerge, r *models.MergeStepReport) error {
+	var sourceName string
+	if err := tx.QueryRow(ctx, `SELECT name FROM organizations WHERE id = $1`, m.SourceOrganizationID).Scan(&sourceName); err != nil {
+		return fmt.Errorf("failed to get source organization: %w", err)
+	}
+	suffix := " (" + sourceName + ")"
+
+	conflicts, err := mergeConflicts(ctx, tx, m, `
+		SELECT s.id, t.id, s.name
+		FROM workflows s
+		JOIN workflows t ON t.organization_id = $2 AND t.name = s.name
+		WHERE s.organization_id = $1
+	`)
+	if err != nil {
+		return err
+	}
+	var skipped []uuid.UUID
+	for _, c := range conflicts {
+		c.Entity, c.Resolution = "workflow", m.Rules.Workflows
+		r.Conflicts = append(r.Conflicts, c)
+		if m.Rules.Workflows == models.MergeResolveSkip {
+			skipped = append(skipped, c.SourceID)
+			continue
+		}
+		if _, err := tx.Exec(ctx, `UPDATE workflows SET name = name || $2 WHERE id = $1`, c.SourceID, suffix); err != nil {
+			return fmt.Errorf("failed to rename workflow: %w", err)
+		}
+		r.Renamed++
+	}
+	r.Skipped += len(skipped)
+
+	if skipped == nil {
+		skipped = []uuid.UUID{}
+	}
+	tag, err := tx.Exec(ctx, `
+		UPDATE workflows SET organization_id = $2, is_default = false
+		WHERE organization_id = $1 AND NOT (id = ANY($3))
+	`, m.SourceOrganizationID, m.TargetOrganizationID, skipped)
+	if err != nil {
+		return fmt.Errorf("failed to move workflows: %w", err)
+	}
+	r.Moved["workflows"] = int(tag.RowsAffected())
+	for _, table := range []string{"workflow_execution_log", "workflow_dead_letters"} {
+		tag, err := tx.Exec(ctx, `
+			UPDATE `+table+` SET organization_id = $2
+			WHERE organization_id = $1 AND workflow_id IN (SELECT id FROM workflows WHERE organization_id = $2)
+		`, m.SourceOrganizationID, m.TargetOrganizationID)
+		if err != nil {
+			return fmt.Errorf("failed to move %s: %w", table, err)
+		}
+		r.Moved[table] = int(tag.RowsAffected())
+	}
+
+	conflicts, err = mergeConflicts(ctx, tx, m, `
+		SELECT s.id, t.id, s.name || ' (' || s.channel || ')'
+		FROM message_templates s
+		JOIN message_templates t ON t.organization_id = $2 AND t.name = s.name AND t.channel = s.channel
+		WHERE s.organization_id = $1
+	`)
+	if err != nil {
+		return err
+	}
+	var kept []uuid.UUID
+	for _, c := range conflicts {
+		c.Entity, c.Resolution = "message_template", m.Rules.Templates
+		r.Conflicts = append(r.Conflicts, c)
+		if m.Rules.Templates == models.MergeResolveSkip {
+			if _, err := tx.Exec(ctx, `
+				UPDATE workflow_actions SET template_id = $2
+				WHERE template_id = $1 AND trigger_id IN (
+					SELECT tr.id FROM workflow_triggers tr
+					JOIN workflows w ON w.id = tr.workflow_id
+					WHERE w.organization_id = $3
+				)
+			`, c.SourceID, *c.TargetID, m.TargetOrganizationID); err != nil {
+				return fmt.Errorf("failed to reassign template: %w", err)
+			}
+			kept = append(kept, c.SourceID)
+			continue
+		}
+		if _, err := tx.Exec(ctx, `UPDATE message_templates SET name = name || $2 WHERE id = $1`, c.SourceID, suffix); err != nil {
+			return fmt.Errorf("failed to rename template: %w", err)
+		}
+		r.Renamed++
+	}
+	r.Skipped += len(kept)
+
+	return moveRowsExcept(ctx, tx, m, r, "message_templates", kept)
+}
+
+// mergeProjects moves worksheets, budgets, projects, payments and photos. Budget and project
+// numbers already used in the target get the rules' prefix.
+func mergeProjects(ctx context.Context, tx pgx.Tx, m *models.OrganizationMerge, r *models.MergeStepReport) error {
+	for _, numbered := range []struct{ table, column string }{{"budgets", "budget_number"}, {"projects", "project_number"}} {
+		rows, err := tx.Query(ctx, `
+			UPDATE `+numbered.table+` s SET `+numbered.column+` = $3 || s.`+numbered.column+`
+			FROM `+numbered.table+` t
+			WHERE s.organization_id = $1 AND t.organization_id = $2 AND t.`+numbered.column+` = s.`+numbered.column+`
+			RETURNING s.id, t.id, t.`+numbered.column+`
+		`, m.SourceOrganizationID, m.TargetOrganizationID, m.Rules.NumberPrefix)
+		if err != nil {
+			return fmt.Errorf("failed to renumber %s: %w", numbered.table, err)
+		}
+		for rows.Next() {
+			c := models.MergeConflict{Entity: numbered.table, Resolution: models.MergeResolveRename}
+			var targetID uuid.UUID
+			if err := rows.Scan(&c.SourceID, &targetID, &c.Key); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan renumbered %s: %w", numbered.table, err)
+			}
+			c.TargetID = &targetID
+			r.Conflicts = append(r.Conflicts, c)
+			r.Renamed++
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to renumber %s: %w", numbered.table, err)
+		}
+	}
+
+	return moveRows(ctx, tx, m, r, "worksheets", "budgets", "projects", "payments", "photos")
+}
+
+// mergeSessions moves sessions, series, their messages and scheduled jobs. Pending jobs of
+// workflows that stayed behind are cancelled.
+func mergeSessions(ctx context.Context, tx pgx.Tx, m *models.OrganizationMerge, r *models.MergeStepReport) error {
+	tag, err := tx.Exec(ctx, `
+		UPDATE scheduled_jobs SET status = 'cancelled', processed_at = NOW()
+		WHERE organization_id = $1 AND status = 'pending' AND trigger_id IN (
+			SELECT tr.id FROM workflow_triggers tr
+			JOIN workflows w ON w.id = tr.workflow_id
+			WHERE w.organization_id = $1
+		)
+	`, m.SourceOrganizationID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel scheduled jobs: %w", err)
+	}
+	r.Skipped = int(tag.RowsAffected())
+
+	return moveRows(ctx, tx, m, r, "sessions", "session_series", "whatsapp_messages", "email_messages", "scheduled_jobs")
+}
+
+// mergeStepTables are the organization-scoped tables moved by the steps before records
+var mergeStepTables = []string{
+	"users", "clients", "patients", "therapists", "bookable_services",
+	"workflows", "workflow_execution_log", "workflow_dead_letters", "message_templates",
+	"worksheets", "budgets", "projects", "payments", "photos",
+	"sessions", "session_series", "whatsapp_messages", "email_messages", "scheduled_jobs",
+}
+
+// mergeKeptTables stay with the source organization: its settings and document numbering, and
+// the records about the organization itself
+var mergeKeptTables = []string{
+	"organization_modules", "notification_configs", "budget_pdf_templates", "patient_portal_settings",
+	"payment_provider_configs", "payment_reference_configs", "chat_webhooks",
+	"invoice_sequences", "document_sequences",
+	"organization_usage", "organization_deletion_requests",
+	"data_exports", "export_bundles", "webhook_audit_log", "benchmark_participants", "benchmark_metrics",
+}
+
+// mergeRecordTable is an organization-scoped table moved by the records step. Its queries take the
+// source organization as $1 and the target as $2.
+type mergeRecordTable struct {
+	table string
+	// fold folds the source rows whose unique key is taken in the target into the target rows, as
+	// clients are merged: references move to the target row and the source row goes. The last
+	// statement's row count is the number of rows folded.
+	fold []string
+	// rename appends the source organization name to the source rows whose name is taken
+	rename string
+	// blocked counts the source rows that clash with the target and can't be folded
+	blocked string
+}
+
+var mergeRecordTables = []mergeRecordTable{
+	{table: "audit_logs"},
+	{table: "user_out_of_office"},
+	{table: "delegation_audit_log"},
+	{table: "user_invitations", fold: []string{`
+		UPDATE user_invitations s SET revoked_at = NOW()
+		FROM user_invitations t
+		WHERE s.organization_id = $1 AND t.organization_id = $2 AND LOWER(t.email) = LOWER(s.email)
+		AND s.accepted_at IS NULL AND s.revoked_at IS NULL AND t.accepted_at IS NULL AND t.revoked_at IS NULL
+	`}},
+	{table: "project_templates", rename: `
+		UPDATE project_templates s SET name = s.name || ' (' || o.name || ')'
+		FROM project_templates t, organizations o
+		WHERE s.organization_id = $1 AND t.organization_id = $2 AND t.name = s.name AND o.id = $1
+	`},
+	{table: "compliance_requirements"},
+	{table: "budget_approval_rules"},
+	{table: "budget_approval_audit"},
+	{table: "budget_portal_events"},
+	{table: "budget_revisions"},
+	{table: "budget_item_comments"},
+	{table: "materials", fold: []string{`
+		UPDATE price_history p SET material_id = t.id
+		FROM materials s
+		JOIN materials t ON t.organization_id = $2 AND t.supplier = s.supplier AND t.code = s.code
+		WHERE s.organization_id = $1 AND p.material_id = s.id
+	`, `
+		DELETE FROM materials s USING materials t
+		WHERE s.organization_id = $1 AND t.organization_id = $2 AND t.supplier = s.supplier AND t.code = s.code
+	`}},
+	{table: "price_list_imports"},
+	{table: "price_history"},
+	{table: "cost_index_values", fold: []string{`
+		DELETE FROM cost_index_values s USING cost_index_values t
+		WHERE s.organization_id = $1 AND t.organization_id = $2 AND t.period = s.period
+	`}},
+	{table: "workflow_status_remaps", fold: []string{`
+		DELETE FROM workflow_status_remaps s USING workflow_status_remaps t
+		WHERE s.organization_id = $1 AND t.organization_id = $2
+		AND t.entity_type = s.entity_type AND t.from_status = s.from_status
+	`}},
+	{table: "workflow_status_mismatches", fold: []string{`
+		DELETE FROM workflow_status_mismatches s USING workflow_status_mismatches t
+		WHERE s.organization_id = $1 AND t.organization_id = $2
+		AND t.entity_type = s.entity_type AND t.status = s.status
+	`}},
+	{table: "workflow_chain_links"},
+	{table: "execution_log_archives"},
+	{table: "trigger_runs"},
+	{table: "entity_state_history"},
+	{table: "cancellation_policy_rules", fold: []string{`
+		UPDATE sessions ses SET cancellation_rule_id = t.id
+		FROM cancellation_policy_rules s
+		JOIN cancellation_policy_rules t ON t.organization_id = $2 AND t.window_hours = s.window_hours
+		WHERE s.organization_id = $1 AND ses.cancellation_rule_id = s.id
+	`, `
+		DELETE FROM cancellation_policy_rules s USING cancellation_policy_rules t
+		WHERE s.organization_id = $1 AND t.organization_id = $2 AND t.window_hours = s.window_hours
+	`}},
+	{table: "organization_holidays", fold: []string{`
+		DELETE FROM organization_holidays s USING organization_holidays t
+		WHERE s.organization_id = $1 AND t.organization_id = $2 AND t.date = s.date
+	`}},
+	{table: "session_read_model"},
+	{table: "session_reschedule_links"},
+	{table: "session_outcomes"},
+	{table: "treatment_plans"},
+	{table: "waiting_list_entries"},
+	{table: "waiting_list_offers"},
+	{table: "patient_portal_login_tokens"},
+	{table: "crew_members"},
+	{table: "crew_assignments"},
+	{table: "follow_ups"},
+	{table: "inbox_item_states"},
+	{table: "message_costs"},
+	{table: "whatsapp_numbers"},
+	{table: "whatsapp_conversations", fold: []string{`
+		UPDATE whatsapp_conversations t SET last_inbound_at = GREATEST(t.last_inbound_at, s.last_inbound_at)
+		FROM whatsapp_conversations s
+		WHERE s.organization_id = $1 AND t.organization_id = $2 AND t.phone_number = s.phone_number
+	`, `
+		DELETE FROM whatsapp_conversations s USING whatsapp_conversations t
+		WHERE s.organization_id = $1 AND t.organization_id = $2 AND t.phone_number = s.phone_number
+	`}},
+	// An address that opted out in one organization and in to the other can't be decided for the client
+	{table: "contact_consents", fold: []string{`
+		UPDATE contact_consent_history h SET consent_id = t.id
+		FROM contact_consents s
+		JOIN contact_consents t ON t.organization_id = $2 AND t.channel = s.channel AND t.address = s.address
+			AND t.status = s.status
+		WHERE s.organization_id = $1 AND h.consent_id = s.id
+	`, `
+		DELETE FROM contact_consents s USING contact_consents t
+		WHERE s.organization_id = $1 AND t.organization_id = $2 AND t.channel = s.channel AND t.address = s.address
+		AND t.status = s.status
+	`}, blocked: `
+		SELECT COUNT(*) FROM contact_consents s
+		JOIN contact_consents t ON t.organization_id = $2 AND t.channel = s.channel AND t.address = s.address
+		WHERE s.organization_id = $1 AND t.status <> s.status
+	`},
+	{table: "email_suppressions", fold: []string{`
+		DELETE FROM email_suppressions s USING email_suppressions t
+		WHERE s.organization_id = $1 AND t.organization_id = $2 AND t.address = s.address
+	`}},
+	{table: "expenses"},
+	// Only one register can be open per organization
+	{table: "cash_registers", blocked: `
+		SELECT COUNT(*) FROM cash_registers s
+		JOIN cash_registers t ON t.organization_id = $2 AND t.status = 'open'
+		WHERE s.organization_id = $1 AND s.status = 'open'
+	`},
+	{table: "cash_register_entries"},
+	{table: "bank_statements"},
+	{table: "bank_statement_lines", fold: []string{`
+		DELETE FROM bank_statement_lines s USING bank_statement_lines t
+		WHERE s.organization_id = $1 AND t.organization_id = $2 AND t.external_id = s.external_id
+	`}},
+	{table: "financial_periods", fold: []string{`
+		DELETE FROM financial_periods s USING financial_periods t
+		WHERE s.organization_id = $1 AND t.organization_id = $2 AND t.period = s.period
+	`}},
+	{table: "financial_period_events"},
+	// Issued invoices are numbered in the source organization's sequence, which the target's
+	// numbering would repeat
+	{table: "invoices", blocked: `
+		SELECT COUNT(*) FROM invoices WHERE organization_id = $1 AND number IS NOT NULL
+	`},
+	{table: "payment_links"},
+	{table: "payment_references"},
+}
+
+// unmovableRecords counts, per table, the source rows the merge can't move: rows clashing with the
+// target that can't be folded, and rows of organization-scoped tables the merge doesn't know
+func unmovableRecords(ctx context.Context, tx pgx.Tx, m *models.OrganizationMerge) (map[string]int, error) {
+	unmovable := map[string]int{}
+	known := map[string]bool{}
+	for _, table := range mergeStepTables {
+		known[table] = true
+	}
+	for _, table := range mergeKeptTables {
+		known[table] = true
+	}
+	for _, t := range mergeRecordTables {
+		known[t.table] = true
+		if t.blocked == "" {
+			continue
+		}
+		var count int
+		if err := tx.QueryRow(ctx, t.blocked, m.SourceOrganizationID, m.TargetOrganizationID).Scan(&count); err != nil {
+			return nil, fmt.Errorf("failed to check %s: %w", t.table, err)
+		}
+		if count > 0 {
+			unmovable[t.table] = count
+		}
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT c.table_name
+		FROM information_schema.columns c
+		JOIN information_schema.tables t ON t.table_schema = c.table_schema AND t.table_name = c.table_name
+		WHERE c.table_schema = current_schema() AND c.column_name = 'organization_id' AND t.table_type = 'BASE TABLE'
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization tables: %w", err)
+	}
+	var unknown []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan organization table: %w", err)
+		}
+		if !known[table] {
+			unknown = append(unknown, table)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list organization tables: %w", err)
+	}
+
+	for _, table := range unknown {
+		var count int
+		err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM `+pgx.Identifier{table}.Sanitize()+` WHERE organization_id = $1`,
+			m.SourceOrganizationID).Scan(&count)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count %s: %w", table, err)
+		}
+		if count > 0 {
+			unmovable[table] = count
+		}
+	}
+	return unmovable, nil
+}
+
+// unmovableError describes the records that refuse a merge
+func unmovableError(unmovable map[string]int) error {
+	tables := make([]string, 0, len(unmovable))
+	for table, count := range unmovable {
+		tables = append(tables, fmt.Sprintf("%s (%d)", table, count))
+	}
+	sort.Strings(tables)
+	return fmt.Errorf("records that cannot be moved to the target organization: %s", strings.Join(tables, ", "))
+}
+
+// checkMovable refuses a merge while the source holds records that can't be moved
+func (s *OrganizationMergeService) checkMovable(ctx context.Context, sourceID, targetID uuid.UUID) error {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	unmovable, err := unmovableRecords(ctx, tx, &models.OrganizationMerge{SourceOrganizationID: sourceID, TargetOrganizationID: targetID})
+	if err != nil {
+		return err
+	}
+	if len(unmovable) > 0 {
+		return unmovableError(unmovable)
+	}
+	return nil
+}
+
+// mergeRecords moves the records of every other organization-scoped table, folding or renaming
+// those that clash with the target. A dry run reports the records that can't be moved and leaves
+// their tables behind; a merge fails on them.
+func mergeRecords(ctx context.Context, tx pgx.Tx, m *models.OrganizationMerge, r *models.MergeStepReport) error {
+	unmovable, err := unmovableRecords(ctx, tx, m)
+	if err != nil {
+		return err
+	}
+	if len(unmovable) > 0 {
+		if !m.DryRun {
+			return unmovableError(unmovable)
+		}
+		r.Unmovable = unmovable
+	}
+
+	for _, t := range mergeRecordTables {
+		if unmovable[t.table] > 0 {
+			continue
+		}
+		for i, stmt := range t.fold {
+			tag, err := tx.Exec(ctx, stmt, m.SourceOrganizationID, m.TargetOrganizationID)
+			if err != nil {
+				return fmt.Errorf("failed to merge %s: %w", t.table, err)
+			}
+			if i == len(t.fold)-1 {
+				r.Merged += int(tag.RowsAffected())
+			}
+		}
+		if t.rename != "" {
+			tag, err := tx.Exec(ctx, t.rename, m.SourceOrganizationID, m.TargetOrganizationID)
+			if err != nil {
+				return fmt.Errorf("failed to rename %s: %w", t.table, err)
+			}
+			r.Renamed += int(tag.RowsAffected())
+		}
+		if err := moveRowsExcept(ctx, tx, m, r, t.table, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
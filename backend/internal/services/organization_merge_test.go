@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// openTestDB connects to the migrated database in TEST_DATABASE_URL, skipping the test without one
+func openTestDB(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	pool, err := pgxpool.New(context.Background(), dsn)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+// insertTestOrganization creates an organization with an admin user, returning both ids
+func insertTestOrganization(t *testing.T, ctx context.Context, tx pgx.Tx, name string) (uuid.UUID, uuid.UUID) {
+	t.Helper()
+	orgID, userID := uuid.New(), uuid.New()
+	if _, err := tx.Exec(ctx, `INSERT INTO organizations (id, name, email) VALUES ($1, $2, $3)`,
+		orgID, name, orgID.String()+"@test.local"); err != nil {
+		t.Fatalf("failed to create organization: %v", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO users (id, organization_id, email, password_hash, first_name, last_name, role)
+		VALUES ($1, $2, $3, 'x', 'Test', 'Admin', 'admin')
+	`, userID, orgID, userID.String()+"@test.local"); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	return orgID, userID
+}
+
+// insertTestPatient creates a client with the email and phone and a patient of it
+func insertTestPatient(t *testing.T, ctx context.Context, tx pgx.Tx, orgID, userID uuid.UUID, name, email, phone string) (uuid.UUID, uuid.UUID) {
+	t.Helper()
+	clientID, patientID := uuid.New(), uuid.New()
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO clients (id, organization_id, name, email, phone, created_by) VALUES ($1, $2, $3, $4, $5, $6)
+	`, clientID, orgID, name, email, phone, userID); err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO patients (id, organization_id, client_id) VALUES ($1, $2, $3)
+	`, patientID, orgID, clientID); err != nil {
+		t.Fatalf("failed to create patient: %v", err)
+	}
+	return clientID, patientID
+}
+
+// TestMergeStepsMatchPatientsByClient runs every merge step and checks patients are matched
+// through their clients, both merged by email and sharing a phone. Everything is rolled back.
+func TestMergeStepsMatchPatientsByClient(t *testing.T) {
+	pool := openTestDB(t)
+	ctx := context.Background()
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	sourceID, sourceUser := insertTestOrganization(t, ctx, tx, "Merge source")
+	targetID, targetUser := insertTestOrganization(t, ctx, tx, "Merge target")
+
+	_, sameEmailSource := insertTestPatient(t, ctx, tx, sourceID, sourceUser, "Ana", "ana@test.local", "+351910000001")
+	_, sameEmailTarget := insertTestPatient(t, ctx, tx, targetID, targetUser, "Ana Silva", "ana@test.local", "+351910000009")
+	_, samePhoneSource := insertTestPatient(t, ctx, tx, sourceID, sourceUser, "Rui", "rui@test.local", "+351910000002")
+	_, samePhoneTarget := insertTestPatient(t, ctx, tx, targetID, targetUser, "Rui Costa", "rui.costa@test.local", "+351910000002")
+	_, newPatient := insertTestPatient(t, ctx, tx, sourceID, sourceUser, "Eva", "eva@test.local", "+351910000003")
+
+	m := &models.OrganizationMerge{
+		ID:                   uuid.New(),
+		SourceOrganizationID: sourceID,
+		TargetOrganizationID: targetID,
+		Rules:                models.OrganizationMergeRules{Clients: models.MergeResolveMerge},
+	}
+	if err := normalizeMergeRules(&m.Rules); err != nil {
+		t.Fatalf("normalizeMergeRules: %v", err)
+	}
+
+	s := &OrganizationMergeService{}
+	var patients *models.MergeStepReport
+	for _, step := range models.MergeSteps {
+		report, err := s.runStep(ctx, tx, m, step)
+		if err != nil {
+			t.Fatalf("step %s: %v", step, err)
+		}
+		if step == models.MergeStepPatients {
+			patients = report
+		}
+	}
+
+	want := map[uuid.UUID]uuid.UUID{sameEmailSource: sameEmailTarget, samePhoneSource: samePhoneTarget}
+	if len(patients.Conflicts) != len(want) {
+		t.Fatalf("patient conflicts = %+v, want %d", patients.Conflicts, len(want))
+	}
+	for _, c := range patients.Conflicts {
+		if c.TargetID == nil || want[c.SourceID] != *c.TargetID {
+			t.Errorf("patient %s matched %v, want %s", c.SourceID, c.TargetID, want[c.SourceID])
+		}
+	}
+
+	var orgID uuid.UUID
+	if err := tx.QueryRow(ctx, `SELECT organization_id FROM patients WHERE id = $1`, newPatient).Scan(&orgID); err != nil {
+		t.Fatalf("failed to get patient: %v", err)
+	}
+	if orgID != targetID {
+		t.Errorf("unmatched patient stayed in organization %s, want %s", orgID, targetID)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/controlwise/backend/internal/database"
 	"github.com/controlwise/backend/internal/models"
@@ -163,6 +164,9 @@ func (s *PatientService) GetByID(ctx context.Context, id, orgID uuid.UUID) (*mod
 		}
 		return nil, fmt.Errorf("failed to get patient: %w", err)
 	}
+	if p.EmailSuppression, err = emailSuppression(ctx, s.db.Pool, orgID, p.ClientEmail); err != nil {
+		return nil, err
+	}
 
 	return &p, nil
 }
@@ -227,6 +231,10 @@ func (s *PatientService) Create(ctx context.Context, patient *models.Patient) er
 		return errors.New("client not found")
 	}
 
+	if err := s.normalizeEmergencyPhone(ctx, patient.OrganizationID, patient); err != nil {
+		return err
+	}
+
 	// Check if client is already linked to a patient
 	existing, err := s.GetByClientID(ctx, patient.OrganizationID, patient.ClientID)
 	if err != nil {
@@ -259,6 +267,10 @@ func (s *PatientService) Create(ctx context.Context, patient *models.Patient) er
 
 // Update updates an existing patient (healthcare fields only, not client link)
 func (s *PatientService) Update(ctx context.Context, id, orgID uuid.UUID, patient *models.Patient) error {
+	if err := s.normalizeEmergencyPhone(ctx, orgID, patient); err != nil {
+		return err
+	}
+
 	// Update patient healthcare fields (not client_id)
 	result, err := s.db.Pool.Exec(ctx, `
 		UPDATE patients
@@ -279,6 +291,23 @@ func (s *PatientService) Update(ctx context.Context, id, orgID uuid.UUID, patien
 	return nil
 }
 
+// normalizeEmergencyPhone stores the patient's emergency phone in E.164
+func (s *PatientService) normalizeEmergencyPhone(ctx context.Context, orgID uuid.UUID, patient *models.Patient) error {
+	if patient.EmergencyPhone == nil || strings.TrimSpace(*patient.EmergencyPhone) == "" {
+		patient.EmergencyPhone = nil
+		return nil
+	}
+	normalized, err := normalizeOrgPhone(ctx, s.db.Pool, orgID, *patient.EmergencyPhone)
+	if err != nil {
+		if err.Error() == "invalid phone number" {
+			return errors.New("invalid emergency phone number")
+		}
+		return err
+	}
+	patient.EmergencyPhone = &normalized
+	return nil
+}
+
 // Delete soft deletes a patient
 func (s *PatientService) Delete(ctx context.Context, id, orgID uuid.UUID) error {
 	// Check if patient has any future sessions
@@ -0,0 +1,494 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/config"
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PatientPortalAudience is the audience of patient portal tokens. Staff routes refuse them and
+// patient portal routes accept nothing else.
+const PatientPortalAudience = "patient-portal"
+
+const (
+	// patientPortalLinkTTL is how long a sign-in link stays valid
+	patientPortalLinkTTL = 15 * time.Minute
+	// patientPortalTokenExpiry is how long a patient stays signed in
+	patientPortalTokenExpiry = 12 * time.Hour
+	// maxPatientPortalLinksPerHour bounds the sign-in links sent to a patient
+	maxPatientPortalLinksPerHour = 5
+	// maxPortalCancelReasonLength bounds the reason a patient gives for cancelling
+	maxPortalCancelReasonLength = 500
+)
+
+// PatientPortalService serves the patient portal: sign-in links, and the signed-in patient's
+// sessions, payments and contact details. Every query is scoped to the patient and organization
+// of the token, and checks the portal is still enabled.
+type PatientPortalService struct {
+	db          *database.DB
+	sessions    *SessionService
+	email       *EmailService
+	jwtCfg      config.JWTConfig
+	frontendURL string
+}
+
+func NewPatientPortalService(db *database.DB, sessions *SessionService, email *EmailService, jwtCfg config.JWTConfig, frontendURL string) *PatientPortalService {
+	return &PatientPortalService{
+		db:          db,
+		sessions:    sessions,
+		email:       email,
+		jwtCfg:      jwtCfg,
+		frontendURL: frontendURL,
+	}
+}
+
+// PatientPortalSettingsInput is what an organization admin sets for the patient portal
+type PatientPortalSettingsInput struct {
+	IsEnabled               bool `json:"is_enabled"`
+	CancellationCutoffHours int  `json:"cancellation_cutoff_hours"`
+	AllowConfirmation       bool `json:"allow_confirmation"`
+	AllowCancellation       bool `json:"allow_cancellation"`
+	AllowContactUpdate      bool `json:"allow_contact_update"`
+}
+
+// PatientContactInput is the contact details a patient can change in the portal
+type PatientContactInput struct {
+	Phone            string  `json:"phone"`
+	Address          *string `json:"address"`
+	EmergencyContact *string `json:"emergency_contact"`
+	EmergencyPhone   *string `json:"emergency_phone"`
+}
+
+// GetSettings returns the organization's patient portal settings, or the defaults when the
+// portal was never set up
+func (s *PatientPortalService) GetSettings(ctx context.Context, orgID uuid.UUID) (*models.PatientPortalSettings, error) {
+	settings := &models.PatientPortalSettings{
+		OrganizationID:          orgID,
+		CancellationCutoffHours: 24,
+		AllowConfirmation:       true,
+		AllowCancellation:       true,
+		AllowContactUpdate:      true,
+	}
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT is_enabled, cancellation_cutoff_hours, allow_confirmation, allow_cancellation,
+			allow_contact_update, updated_at
+		FROM patient_portal_settings WHERE organization_id = $1
+	`, orgID).Scan(&settings.IsEnabled, &settings.CancellationCutoffHours, &settings.AllowConfirmation,
+		&settings.AllowCancellation, &settings.AllowContactUpdate, &settings.UpdatedAt)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get patient portal settings: %w", err)
+	}
+	return settings, nil
+}
+
+// UpdateSettings saves the organization's patient portal settings
+func (s *PatientPortalService) UpdateSettings(ctx context.Context, orgID uuid.UUID, input PatientPortalSettingsInput) (*models.PatientPortalSettings, error) {
+	if input.CancellationCutoffHours < 0 || input.CancellationCutoffHours > 720 {
+		return nil, errors.New("cancellation cutoff must be between 0 and 720 hours")
+	}
+
+	_, err := s.db.Pool.Exec(ctx, `
+		INSERT INTO patient_portal_settings (organization_id, is_enabled, cancellation_cutoff_hours,
+			allow_confirmation, allow_cancellation, allow_contact_update)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (organization_id) DO UPDATE SET
+			is_enabled = EXCLUDED.is_enabled,
+			cancellation_cutoff_hours = EXCLUDED.cancellation_cutoff_hours,
+			allow_confirmation = EXCLUDED.allow_confirmation,
+			allow_cancellation = EXCLUDED.allow_cancellation,
+			allow_contact_update = EXCLUDED.allow_contact_update
+	`, orgID, input.IsEnabled, input.CancellationCutoffHours, input.AllowConfirmation,
+		input.AllowCancellation, input.AllowContactUpdate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save patient portal settings: %w", err)
+	}
+	return s.GetSettings(ctx, orgID)
+}
+
+// RequestLogin emails a one-time sign-in link to each active patient of the organization
+// registered with the email. It says nothing about whether any was found, so the portal cannot
+// be used to find out who is a patient.
+func (s *PatientPortalService) RequestLogin(ctx context.Context, orgID uuid.UUID, email, ipAddress string) error {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return errors.New("email is required")
+	}
+
+	settings, err := s.GetSettings(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if !settings.IsEnabled {
+		return nil
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT p.id, c.name, c.email, o.name,
+			(SELECT COUNT(*) FROM patient_portal_login_tokens t
+			 WHERE t.patient_id = p.id AND t.created_at > NOW() - INTERVAL '1 hour')
+		FROM patients p
+		JOIN clients c ON c.id = p.client_id AND c.deleted_at IS NULL
+		JOIN organizations o ON o.id = p.organization_id
+		WHERE p.organization_id = $1 AND p.deleted_at IS NULL AND p.is_active = true
+			AND LOWER(c.email) = LOWER($2)
+	`, orgID, email)
+	if err != nil {
+		return fmt.Errorf("failed to find patient: %w", err)
+	}
+	type recipient struct {
+		patientID            uuid.UUID
+		name, email, orgName string
+		recentLinks          int
+	}
+	var recipients []recipient
+	for rows.Next() {
+		var r recipient
+		if err := rows.Scan(&r.patientID, &r.name, &r.email, &r.orgName, &r.recentLinks); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan patient: %w", err)
+		}
+		recipients = append(recipients, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to find patient: %w", err)
+	}
+
+	for _, r := range recipients {
+		if r.recentLinks >= maxPatientPortalLinksPerHour {
+			log.Printf("[PatientPortal] Too many sign-in links for patient %s, skipping", r.patientID)
+			continue
+		}
+
+		token, hash, err := newInvitationToken()
+		if err != nil {
+			return err
+		}
+		_, err = s.db.Pool.Exec(ctx, `
+			INSERT INTO patient_portal_login_tokens (organization_id, patient_id, token_hash, expires_at, ip_address)
+			VALUES ($1, $2, $3, $4, $5)
+		`, orgID, r.patientID, hash, time.Now().Add(patientPortalLinkTTL), ipAddress)
+		if err != nil {
+			return fmt.Errorf("failed to create sign-in link: %w", err)
+		}
+
+		link := fmt.Sprintf("%s/patient-portal/login?token=%s", strings.TrimRight(s.frontendURL, "/"), url.QueryEscape(token))
+		if err := s.email.SendPatientPortalLogin(r.email, r.name, r.orgName, link, patientPortalLinkTTL); err != nil {
+			log.Printf("[PatientPortal] Failed to send sign-in link to patient %s: %v", r.patientID, err)
+		}
+	}
+
+	return nil
+}
+
+// Login exchanges a sign-in link for a patient portal token. Each link works once.
+func (s *PatientPortalService) Login(ctx context.Context, token string) (string, *models.PatientPortalProfile, error) {
+	var orgID, patientID uuid.UUID
+	err := s.db.Pool.QueryRow(ctx, `
+		UPDATE patient_portal_login_tokens SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING organization_id, patient_id
+	`, hashInvitationToken(token)).Scan(&orgID, &patientID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil, errors.New("sign-in link is invalid or has expired")
+		}
+		return "", nil, fmt.Errorf("failed to sign in: %w", err)
+	}
+
+	profile, err := s.Profile(ctx, orgID, patientID)
+	if err != nil {
+		if err.Error() == "patient not found" || err.Error() == "patient portal is not enabled" {
+			return "", nil, errors.New("sign-in link is invalid or has expired")
+		}
+		return "", nil, err
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"aud":             PatientPortalAudience,
+		"patient_id":      patientID.String(),
+		"organization_id": orgID.String(),
+		"exp":             now.Add(patientPortalTokenExpiry).Unix(),
+		"iat":             now.Unix(),
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.jwtCfg.Secret))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signed, profile, nil
+}
+
+// portalSettings returns the organization's settings, failing when the portal was turned off
+// since the patient signed in
+func (s *PatientPortalService) portalSettings(ctx context.Context, orgID uuid.UUID) (*models.PatientPortalSettings, error) {
+	settings, err := s.GetSettings(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.IsEnabled {
+		return nil, errors.New("patient portal is not enabled")
+	}
+	return settings, nil
+}
+
+// Profile returns the patient's details and what the portal lets them do
+func (s *PatientPortalService) Profile(ctx context.Context, orgID, patientID uuid.UUID) (*models.PatientPortalProfile, error) {
+	settings, err := s.portalSettings(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &models.PatientPortalProfile{
+		PatientID:               patientID,
+		CancellationCutoffHours: settings.CancellationCutoffHours,
+		AllowConfirmation:       settings.AllowConfirmation,
+		AllowCancellation:       settings.AllowCancellation,
+		AllowContactUpdate:      settings.AllowContactUpdate,
+	}
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT o.name, c.name, c.email, c.phone, c.address, p.emergency_contact, p.emergency_phone
+		FROM patients p
+		JOIN clients c ON c.id = p.client_id AND c.deleted_at IS NULL
+		JOIN organizations o ON o.id = p.organization_id
+		WHERE p.id = $1 AND p.organization_id = $2 AND p.deleted_at IS NULL AND p.is_active = true
+	`, patientID, orgID).Scan(&profile.OrganizationName, &profile.Name, &profile.Email, &profile.Phone,
+		&profile.Address, &profile.EmergencyContact, &profile.EmergencyPhone)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("patient not found")
+		}
+		return nil, fmt.Errorf("failed to get patient: %w", err)
+	}
+	return profile, nil
+}
+
+// patientCanCancel reports whether a patient may still cancel a session themselves: it is
+// pending or confirmed and starts at least cutoffHours from now
+func patientCanCancel(status models.SessionStatus, scheduledAt, now time.Time, cutoffHours int) bool {
+	if status != models.SessionStatusPending && status != models.SessionStatusConfirmed {
+		return false
+	}
+	return !now.Add(time.Duration(cutoffHours) * time.Hour).After(scheduledAt)
+}
+
+// ListSessions returns the patient's upcoming sessions, soonest first, or their past ones,
+// latest first
+func (s *PatientPortalService) ListSessions(ctx context.Context, orgID, patientID uuid.UUID, upcoming bool, limit, offset int) ([]*models.PatientPortalSession, int, error) {
+	settings, err := s.portalSettings(ctx, orgID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	when, order := `s.scheduled_at >= NOW()`, `s.scheduled_at ASC`
+	if !upcoming {
+		when, order = `s.scheduled_at < NOW()`, `s.scheduled_at DESC`
+	}
+
+	var total int
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM sessions s
+		WHERE s.patient_id = $1 AND s.organization_id = $2 AND s.deleted_at IS NULL AND `+when,
+		patientID, orgID).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count sessions: %w", err)
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT s.id, s.scheduled_at, s.duration_minutes, s.status, s.session_type, t.name, bs.name,
+			COALESCE(s.price_cents, 0), s.cancelled_at
+		FROM sessions s
+		JOIN therapists t ON t.id = s.therapist_id
+		LEFT JOIN bookable_services bs ON bs.id = s.service_id
+		WHERE s.patient_id = $1 AND s.organization_id = $2 AND s.deleted_at IS NULL AND `+when+`
+		ORDER BY `+order+`
+		LIMIT $3 OFFSET $4
+	`, patientID, orgID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	sessions := []*models.PatientPortalSession{}
+	for rows.Next() {
+		var ps models.PatientPortalSession
+		if err := rows.Scan(&ps.ID, &ps.ScheduledAt, &ps.DurationMinutes, &ps.Status, &ps.SessionType,
+			&ps.TherapistName, &ps.ServiceName, &ps.PriceCents, &ps.CancelledAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan session: %w", err)
+		}
+		ps.CanConfirm = settings.AllowConfirmation && ps.Status == models.SessionStatusPending && ps.ScheduledAt.After(now)
+		ps.CanCancel = settings.AllowCancellation && patientCanCancel(ps.Status, ps.ScheduledAt, now, settings.CancellationCutoffHours)
+		sessions = append(sessions, &ps)
+	}
+	return sessions, total, rows.Err()
+}
+
+// patientSession returns the status and start of one of the patient's sessions
+func (s *PatientPortalService) patientSession(ctx context.Context, orgID, patientID, sessionID uuid.UUID) (models.SessionStatus, time.Time, error) {
+	var status models.SessionStatus
+	var scheduledAt time.Time
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT status, scheduled_at FROM sessions
+		WHERE id = $1 AND patient_id = $2 AND organization_id = $3 AND deleted_at IS NULL
+	`, sessionID, patientID, orgID).Scan(&status, &scheduledAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", time.Time{}, errors.New("session not found")
+		}
+		return "", time.Time{}, fmt.Errorf("failed to get session: %w", err)
+	}
+	return status, scheduledAt, nil
+}
+
+// ConfirmSession confirms one of the patient's upcoming pending sessions
+func (s *PatientPortalService) ConfirmSession(ctx context.Context, orgID, patientID, sessionID uuid.UUID) error {
+	settings, err := s.portalSettings(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if !settings.AllowConfirmation {
+		return errors.New("sessions cannot be confirmed in the patient portal")
+	}
+
+	status, scheduledAt, err := s.patientSession(ctx, orgID, patientID, sessionID)
+	if err != nil {
+		return err
+	}
+	if status != models.SessionStatusPending || !scheduledAt.After(time.Now()) {
+		return errors.New("only upcoming pending sessions can be confirmed")
+	}
+
+	return s.sessions.confirm(ctx, sessionID, orgID, nil, nil)
+}
+
+// CancelSession cancels one of the patient's sessions while it is outside the organization's
+// cutoff. The cancellation policy applies as it would for a cancellation by the staff.
+func (s *PatientPortalService) CancelSession(ctx context.Context, orgID, patientID, sessionID uuid.UUID, reason string) (*models.CancellationOutcome, error) {
+	settings, err := s.portalSettings(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.AllowCancellation {
+		return nil, errors.New("sessions cannot be cancelled in the patient portal")
+	}
+
+	reason = strings.TrimSpace(reason)
+	if len(reason) > maxPortalCancelReasonLength {
+		return nil, fmt.Errorf("reason must be at most %d characters", maxPortalCancelReasonLength)
+	}
+	if reason == "" {
+		reason = "Cancelled by the patient"
+	}
+
+	status, scheduledAt, err := s.patientSession(ctx, orgID, patientID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if !patientCanCancel(status, scheduledAt, time.Now(), settings.CancellationCutoffHours) {
+		return nil, fmt.Errorf("sessions can only be cancelled up to %d hours before they start", settings.CancellationCutoffHours)
+	}
+
+	return s.sessions.cancel(ctx, sessionID, orgID, reason, nil, false, nil)
+}
+
+// OutstandingPayments returns what the patient still owes, oldest first, and its total
+func (s *PatientPortalService) OutstandingPayments(ctx context.Context, orgID, patientID uuid.UUID) ([]*models.PatientPortalPayment, int, error) {
+	if _, err := s.portalSettings(ctx, orgID); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT sp.id, s.id, s.scheduled_at, sp.kind, sp.amount_cents, sp.payment_status, sp.due_date,
+			sp.mb_entity, sp.mb_reference, sp.sepa_reference
+		FROM session_payments sp
+		JOIN sessions s ON s.id = sp.session_id
+		WHERE s.patient_id = $1 AND s.organization_id = $2 AND s.deleted_at IS NULL
+			AND sp.payment_status IN ('unpaid', 'partial') AND sp.amount_cents > 0
+		ORDER BY COALESCE(sp.due_date, s.scheduled_at::date), s.scheduled_at
+	`, patientID, orgID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list payments: %w", err)
+	}
+	defer rows.Close()
+
+	payments := []*models.PatientPortalPayment{}
+	totalCents := 0
+	for rows.Next() {
+		var p models.PatientPortalPayment
+		if err := rows.Scan(&p.ID, &p.SessionID, &p.ScheduledAt, &p.Kind, &p.AmountCents, &p.PaymentStatus,
+			&p.DueDate, &p.MBEntity, &p.MBReference, &p.SEPAReference); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan payment: %w", err)
+		}
+		totalCents += p.AmountCents
+		payments = append(payments, &p)
+	}
+	return payments, totalCents, rows.Err()
+}
+
+// UpdateContact changes the patient's phone, address and emergency contact
+func (s *PatientPortalService) UpdateContact(ctx context.Context, orgID, patientID uuid.UUID, input PatientContactInput) (*models.PatientPortalProfile, error) {
+	settings, err := s.portalSettings(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.AllowContactUpdate {
+		return nil, errors.New("contact details cannot be changed in the patient portal")
+	}
+
+	if strings.TrimSpace(input.Phone) == "" {
+		return nil, errors.New("phone is required")
+	}
+	phone, err := normalizeOrgPhone(ctx, s.db.Pool, orgID, input.Phone)
+	if err != nil {
+		return nil, err
+	}
+	patient := &models.Patient{EmergencyPhone: input.EmergencyPhone}
+	if err := (&PatientService{db: s.db}).normalizeEmergencyPhone(ctx, orgID, patient); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE clients c SET phone = $1, address = $2, updated_at = CURRENT_TIMESTAMP
+		FROM patients p
+		WHERE p.client_id = c.id AND p.id = $3 AND p.organization_id = $4 AND p.deleted_at IS NULL
+			AND c.deleted_at IS NULL
+	`, phone, input.Address, patientID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update contact details: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, errors.New("patient not found")
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE patients SET emergency_contact = $1, emergency_phone = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND organization_id = $4
+	`, input.EmergencyContact, patient.EmergencyPhone, patientID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update emergency contact: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return s.Profile(ctx, orgID, patientID)
+}
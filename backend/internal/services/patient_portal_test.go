@@ -0,0 +1,36 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+)
+
+func TestPatientCanCancel(t *testing.T) {
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		status      models.SessionStatus
+		scheduledAt time.Time
+		cutoffHours int
+		want        bool
+	}{
+		{"pending, two days ahead", models.SessionStatusPending, now.Add(48 * time.Hour), 24, true},
+		{"confirmed, exactly at the cutoff", models.SessionStatusConfirmed, now.Add(24 * time.Hour), 24, true},
+		{"confirmed, inside the cutoff", models.SessionStatusConfirmed, now.Add(23 * time.Hour), 24, false},
+		{"no cutoff, about to start", models.SessionStatusConfirmed, now.Add(time.Minute), 0, true},
+		{"no cutoff, already started", models.SessionStatusConfirmed, now.Add(-time.Minute), 0, false},
+		{"already cancelled", models.SessionStatusCancelled, now.Add(72 * time.Hour), 24, false},
+		{"completed", models.SessionStatusCompleted, now.Add(-72 * time.Hour), 24, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := patientCanCancel(tt.status, tt.scheduledAt, now, tt.cutoffHours); got != tt.want {
+				t.Errorf("patientCanCancel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
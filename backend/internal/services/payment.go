@@ -0,0 +1,350 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/events"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// PaymentService handles payment operations
+type PaymentService struct {
+	db           *database.DB
+	notification *NotificationService
+	events       *events.Publisher
+}
+
+func NewPaymentService(db *database.DB, notification *NotificationService) *PaymentService {
+	return &PaymentService{
+		db:           db,
+		notification: notification,
+	}
+}
+
+// SetEventPublisher sets the publisher received payments are streamed to dashboards with
+func (s *PaymentService) SetEventPublisher(p *events.Publisher) {
+	s.events = p
+}
+
+// PaymentFilters contains filters for listing payments
+type PaymentFilters struct {
+	ProjectID *uuid.UUID
+	ClientID  *uuid.UUID
+	Status    string
+	DueFrom   *time.Time
+	DueTo     *time.Time
+	Limit     int
+	Offset    int
+}
+
+type CreatePaymentRequest struct {
+	ProjectID uuid.UUID       `json:"project_id"`
+	Amount    decimal.Decimal `json:"amount"`
+	DueDate   time.Time       `json:"due_date"`
+	Method    *string         `json:"method"`
+	Reference *string         `json:"reference"`
+	Notes     *string         `json:"notes"`
+}
+
+type UpdatePaymentRequest struct {
+	Amount    decimal.Decimal `json:"amount"`
+	DueDate   time.Time       `json:"due_date"`
+	Method    *string         `json:"method"`
+	Reference *string         `json:"reference"`
+	Notes     *string         `json:"notes"`
+}
+
+type MarkPaymentPaidRequest struct {
+	PaidAt    *time.Time `json:"paid_at"` // defaults to now
+	Method    *string    `json:"method"`
+	Reference *string    `json:"reference"`
+}
+
+const paymentDetailsQuery = `
+	SELECT
+		pay.id, pay.organization_id, pay.project_id, pay.amount, pay.status, pay.due_date, pay.paid_at,
+		pay.method, pay.reference, pay.notes, pay.mb_entity, pay.mb_reference, pay.sepa_reference,
+		pay.created_by, pay.created_at, pay.updated_at,
+		p.project_number, p.title, COALESCE(c.name, '')
+	FROM payments pay
+	JOIN projects p ON p.id = pay.project_id
+	LEFT JOIN budgets b ON b.id = p.budget_id
+	LEFT JOIN worksheets w ON w.id = b.worksheet_id
+	LEFT JOIN clients c ON c.id = w.client_id
+`
+
+func scanPaymentWithDetails(row pgx.Row) (*models.PaymentWithDetails, error) {
+	var p models.PaymentWithDetails
+	err := row.Scan(
+		&p.ID, &p.OrganizationID, &p.ProjectID, &p.Amount, &p.Status, &p.DueDate, &p.PaidAt,
+		&p.Method, &p.Reference, &p.Notes, &p.MBEntity, &p.MBReference, &p.SEPAReference,
+		&p.CreatedBy, &p.CreatedAt, &p.UpdatedAt,
+		&p.ProjectNumber, &p.ProjectTitle, &p.ClientName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// List returns the organization's payments matching the filters, earliest due first
+func (s *PaymentService) List(ctx context.Context, orgID uuid.UUID, filters PaymentFilters) ([]*models.PaymentWithDetails, int, error) {
+	where := "WHERE pay.organization_id = $1 AND pay.deleted_at IS NULL"
+	args := []interface{}{orgID}
+
+	if filters.ProjectID != nil {
+		args = append(args, *filters.ProjectID)
+		where += fmt.Sprintf(" AND pay.project_id = $%d", len(args))
+	}
+	if filters.ClientID != nil {
+		args = append(args, *filters.ClientID)
+		where += fmt.Sprintf(" AND w.client_id = $%d", len(args))
+	}
+	if filters.Status != "" {
+		args = append(args, filters.Status)
+		where += fmt.Sprintf(" AND pay.status = $%d", len(args))
+	}
+	if filters.DueFrom != nil {
+		args = append(args, *filters.DueFrom)
+		where += fmt.Sprintf(" AND pay.due_date >= $%d", len(args))
+	}
+	if filters.DueTo != nil {
+		args = append(args, *filters.DueTo)
+		where += fmt.Sprintf(" AND pay.due_date <= $%d", len(args))
+	}
+
+	var total int
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM payments pay
+		JOIN projects p ON p.id = pay.project_id
+		LEFT JOIN budgets b ON b.id = p.budget_id
+		LEFT JOIN worksheets w ON w.id = b.worksheet_id
+		`+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count payments: %w", err)
+	}
+
+	if filters.Limit <= 0 {
+		filters.Limit = 50
+	}
+	args = append(args, filters.Limit, filters.Offset)
+	query := paymentDetailsQuery + where + fmt.Sprintf(`
+		ORDER BY pay.due_date ASC, pay.created_at ASC
+		LIMIT $%d OFFSET $%d
+	`, len(args)-1, len(args))
+
+	rows, err := s.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query payments: %w", err)
+	}
+	defer rows.Close()
+
+	payments := []*models.PaymentWithDetails{}
+	for rows.Next() {
+		p, err := scanPaymentWithDetails(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan payment: %w", err)
+		}
+		payments = append(payments, p)
+	}
+
+	return payments, total, nil
+}
+
+// GetByID returns a payment of the organization
+func (s *PaymentService) GetByID(ctx context.Context, id, orgID uuid.UUID) (*models.PaymentWithDetails, error) {
+	p, err := scanPaymentWithDetails(s.db.Pool.QueryRow(ctx, paymentDetailsQuery+`
+		WHERE pay.id = $1 AND pay.organization_id = $2 AND pay.deleted_at IS NULL
+	`, id, orgID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("payment not found")
+		}
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+	return p, nil
+}
+
+// Create schedules a payment for a project of the organization
+func (s *PaymentService) Create(ctx context.Context, orgID, userID uuid.UUID, req CreatePaymentRequest) (*models.PaymentWithDetails, error) {
+	if !req.Amount.IsPositive() {
+		return nil, errors.New("payment amount must be greater than zero")
+	}
+	if req.DueDate.IsZero() {
+		return nil, errors.New("payment due date is required")
+	}
+
+	var projectStatus models.ProjectStatus
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT status FROM projects WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`, req.ProjectID, orgID).Scan(&projectStatus)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("project not found")
+		}
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	if projectStatus == models.ProjectStatusCancelled {
+		return nil, errors.New("cannot add payments to a cancelled project")
+	}
+	if err := checkPeriodsOpen(ctx, s.db.Pool, orgID, req.DueDate); err != nil {
+		return nil, err
+	}
+
+	id := uuid.New()
+	_, err = s.db.Pool.Exec(ctx, `
+		INSERT INTO payments (id, organization_id, project_id, amount, status, due_date, method, reference, notes, created_by)
+		VALUES ($1, $2, $3, $4, 'pending', $5, $6, $7, $8, $9)
+	`, id, orgID, req.ProjectID, req.Amount, req.DueDate, req.Method, req.Reference, req.Notes, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create payment: %w", err)
+	}
+
+	return s.GetByID(ctx, id, orgID)
+}
+
+// Update changes an open payment. Moving an overdue payment's due date to the future makes it pending again.
+func (s *PaymentService) Update(ctx context.Context, id, orgID uuid.UUID, req UpdatePaymentRequest) (*models.PaymentWithDetails, error) {
+	if !req.Amount.IsPositive() {
+		return nil, errors.New("payment amount must be greater than zero")
+	}
+	if req.DueDate.IsZero() {
+		return nil, errors.New("payment due date is required")
+	}
+
+	payment, err := s.GetByID(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status == models.PaymentStatusPaid || payment.Status == models.PaymentStatusCancelled {
+		return nil, errors.New("cannot modify paid or cancelled payments")
+	}
+	if err := checkPeriodsOpen(ctx, s.db.Pool, orgID, payment.DueDate, req.DueDate); err != nil {
+		return nil, err
+	}
+
+	_, err = s.db.Pool.Exec(ctx, `
+		UPDATE payments
+		SET amount = $1, due_date = $2, method = $3, reference = $4, notes = $5,
+			status = CASE WHEN status = 'overdue' AND $2::date >= CURRENT_DATE THEN 'pending' ELSE status END,
+			updated_at = NOW()
+		WHERE id = $6 AND organization_id = $7 AND deleted_at IS NULL
+	`, req.Amount, req.DueDate, req.Method, req.Reference, req.Notes, id, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update payment: %w", err)
+	}
+
+	return s.GetByID(ctx, id, orgID)
+}
+
+// ErrPaymentNotOpen is returned when a payment to mark as paid is missing or no longer open
+var ErrPaymentNotOpen = errors.New("payment not found or not open")
+
+// MarkAsPaid records that an open payment was received
+func (s *PaymentService) MarkAsPaid(ctx context.Context, id, orgID uuid.UUID, req MarkPaymentPaidRequest) (*models.PaymentWithDetails, error) {
+	paidAt := time.Now()
+	if req.PaidAt != nil {
+		paidAt = *req.PaidAt
+	}
+	if err := checkPeriodsOpen(ctx, s.db.Pool, orgID, paidAt); err != nil {
+		return nil, err
+	}
+
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE payments
+		SET status = 'paid', paid_at = $1,
+			method = COALESCE($2, method), reference = COALESCE($3, reference),
+			updated_at = NOW()
+		WHERE id = $4 AND organization_id = $5 AND deleted_at IS NULL AND status IN ('pending', 'overdue')
+	`, paidAt, req.Method, req.Reference, id, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark payment as paid: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, ErrPaymentNotOpen
+	}
+
+	payment, err := s.GetByID(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.events.Publish(ctx, orgID, models.DashboardEvent{
+		Type:       models.DashboardEventPaymentReceived,
+		EntityType: "payment",
+		EntityID:   id,
+		Data: map[string]interface{}{
+			"amount": payment.Amount,
+			"method": payment.Method,
+		},
+	})
+
+	return payment, nil
+}
+
+// Delete soft deletes a payment. Received payments are kept for the records.
+func (s *PaymentService) Delete(ctx context.Context, id, orgID uuid.UUID) error {
+	payment, err := s.GetByID(ctx, id, orgID)
+	if err != nil {
+		return err
+	}
+	if payment.Status == models.PaymentStatusPaid {
+		return errors.New("cannot delete a paid payment")
+	}
+	if err := checkPeriodsOpen(ctx, s.db.Pool, orgID, payment.DueDate); err != nil {
+		return err
+	}
+
+	_, err = s.db.Pool.Exec(ctx, `
+		UPDATE payments SET deleted_at = NOW() WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to delete payment: %w", err)
+	}
+	return nil
+}
+
+// ReceivablesSummary is the organization's money still to be collected
+type ReceivablesSummary struct {
+	Outstanding       decimal.Decimal `json:"outstanding"` // pending and overdue
+	Overdue           decimal.Decimal `json:"overdue"`
+	DueNext30Days     decimal.Decimal `json:"due_next_30_days"`
+	CollectedMonth    decimal.Decimal `json:"collected_this_month"`
+	PendingCount      int             `json:"pending_count"`
+	OverdueCount      int             `json:"overdue_count"`
+	OverdueProjects   int             `json:"overdue_projects"`
+	OldestOverdueDate *time.Time      `json:"oldest_overdue_date"`
+}
+
+// GetReceivablesSummary totals the organization's open and recently collected payments
+func (s *PaymentService) GetReceivablesSummary(ctx context.Context, orgID uuid.UUID) (*ReceivablesSummary, error) {
+	var summary ReceivablesSummary
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT
+			COALESCE(SUM(amount) FILTER (WHERE status IN ('pending', 'overdue')), 0),
+			COALESCE(SUM(amount) FILTER (WHERE status = 'overdue'), 0),
+			COALESCE(SUM(amount) FILTER (WHERE status = 'pending' AND due_date < CURRENT_DATE + 30), 0),
+			COALESCE(SUM(amount) FILTER (WHERE status = 'paid' AND paid_at >= DATE_TRUNC('month', CURRENT_DATE)), 0),
+			COUNT(*) FILTER (WHERE status = 'pending'),
+			COUNT(*) FILTER (WHERE status = 'overdue'),
+			COUNT(DISTINCT project_id) FILTER (WHERE status = 'overdue'),
+			MIN(due_date) FILTER (WHERE status = 'overdue')
+		FROM payments
+		WHERE organization_id = $1 AND deleted_at IS NULL
+	`, orgID).Scan(
+		&summary.Outstanding, &summary.Overdue, &summary.DueNext30Days, &summary.CollectedMonth,
+		&summary.PendingCount, &summary.OverdueCount, &summary.OverdueProjects, &summary.OldestOverdueDate,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receivables summary: %w", err)
+	}
+	return &summary, nil
+}
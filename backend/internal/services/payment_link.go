@@ -0,0 +1,730 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// paymentLinkValidity is how long a checkout link can be paid; Stripe allows at most 24 hours
+const paymentLinkValidity = 23 * time.Hour
+
+// paymentLinkReuseMargin keeps links about to expire out of new messages
+const paymentLinkReuseMargin = time.Hour
+
+var currencyPattern = regexp.MustCompile(`^[a-z]{3}$`)
+
+// PaymentLinkService creates Stripe Checkout links for unpaid session payments, project payments
+// and approved budgets, and marks them as paid when Stripe reports the checkout completed
+type PaymentLinkService struct {
+	db              *database.DB
+	encryptionKey   []byte
+	frontendURL     string
+	apiBase         string
+	client          *http.Client
+	sessionPayments *SessionPaymentService
+	payments        *PaymentService
+}
+
+func NewPaymentLinkService(db *database.DB, encryptionKey, frontendURL string, sessionPayments *SessionPaymentService, payments *PaymentService) *PaymentLinkService {
+	return &PaymentLinkService{
+		db:              db,
+		encryptionKey:   secretKey(encryptionKey),
+		frontendURL:     strings.TrimRight(frontendURL, "/"),
+		apiBase:         stripeAPIBase,
+		client:          &http.Client{Timeout: 30 * time.Second},
+		sessionPayments: sessionPayments,
+		payments:        payments,
+	}
+}
+
+// PaymentProviderConfigInput updates an organization's Stripe account. Secrets are kept when omitted.
+type PaymentProviderConfigInput struct {
+	IsEnabled     bool    `json:"is_enabled"`
+	SecretKey     *string `json:"secret_key"`
+	WebhookSecret *string `json:"webhook_secret"`
+	Currency      *string `json:"currency"` // ISO 4217, e.g. eur
+}
+
+// PaymentLinkFilters contains filters for listing payment links
+type PaymentLinkFilters struct {
+	TargetType string
+	TargetID   *uuid.UUID
+	Status     string
+	Limit      int
+	Offset     int
+}
+
+// paymentLinkTarget is what a link collects, resolved from its target
+type paymentLinkTarget struct {
+	amount      decimal.Decimal
+	description string
+	email       *string
+}
+
+// GetConfig returns the organization's payment provider configuration, or nil when not set up
+func (s *PaymentLinkService) GetConfig(ctx context.Context, orgID uuid.UUID) (*models.PaymentProviderConfig, error) {
+	var config models.PaymentProviderConfig
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT organization_id, provider, is_enabled, secret_key_encrypted, webhook_secret_encrypted,
+			currency, webhook_token, created_at, updated_at
+		FROM payment_provider_configs WHERE organization_id = $1
+	`, orgID).Scan(&config.OrganizationID, &config.Provider, &config.IsEnabled, &config.SecretKeyEncrypted,
+		&config.WebhookSecretEncrypted, &config.Currency, &config.WebhookToken, &config.CreatedAt, &config.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get payment provider config: %w", err)
+	}
+	return &config, nil
+}
+
+// SaveConfig creates or updates the organization's Stripe account
+func (s *PaymentLinkService) SaveConfig(ctx context.Context, orgID uuid.UUID, input PaymentProviderConfigInput) (*models.PaymentProviderConfig, error) {
+	var encryptedKey, encryptedWebhookSecret *string
+	if input.SecretKey != nil && *input.SecretKey != "" {
+		key := strings.TrimSpace(*input.SecretKey)
+		if !strings.HasPrefix(key, "sk_") && !strings.HasPrefix(key, "rk_") {
+			return nil, errors.New("invalid Stripe secret key")
+		}
+		encrypted, err := encryptSecret(s.encryptionKey, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt secret key: %w", err)
+		}
+		encryptedKey = &encrypted
+	}
+	if input.WebhookSecret != nil && *input.WebhookSecret != "" {
+		secret := strings.TrimSpace(*input.WebhookSecret)
+		if !strings.HasPrefix(secret, "whsec_") {
+			return nil, errors.New("invalid Stripe webhook signing secret")
+		}
+		encrypted, err := encryptSecret(s.encryptionKey, secret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt webhook secret: %w", err)
+		}
+		encryptedWebhookSecret = &encrypted
+	}
+	var currency *string
+	if input.Currency != nil {
+		c := strings.ToLower(strings.TrimSpace(*input.Currency))
+		if !currencyPattern.MatchString(c) {
+			return nil, errors.New("invalid currency")
+		}
+		currency = &c
+	}
+
+	existing, err := s.GetConfig(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if input.IsEnabled {
+		if encryptedKey == nil && (existing == nil || existing.SecretKeyEncrypted == nil) {
+			return nil, errors.New("a Stripe secret key is required to enable payment links")
+		}
+		if encryptedWebhookSecret == nil && (existing == nil || existing.WebhookSecretEncrypted == nil) {
+			return nil, errors.New("a Stripe webhook signing secret is required to enable payment links")
+		}
+	}
+
+	_, err = s.db.Pool.Exec(ctx, `
+		INSERT INTO payment_provider_configs (organization_id, is_enabled, secret_key_encrypted, webhook_secret_encrypted, currency)
+		VALUES ($1, $2, $3, $4, COALESCE($5, 'eur'))
+		ON CONFLICT (organization_id) DO UPDATE SET
+			is_enabled = EXCLUDED.is_enabled,
+			secret_key_encrypted = COALESCE($3, payment_provider_configs.secret_key_encrypted),
+			webhook_secret_encrypted = COALESCE($4, payment_provider_configs.webhook_secret_encrypted),
+			currency = COALESCE($5, payment_provider_configs.currency),
+			updated_at = CURRENT_TIMESTAMP
+	`, orgID, input.IsEnabled, encryptedKey, encryptedWebhookSecret, currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save payment provider config: %w", err)
+	}
+
+	return s.GetConfig(ctx, orgID)
+}
+
+// stripe returns a client for the organization's Stripe account, or nil when payment links are
+// not enabled
+func (s *PaymentLinkService) stripe(ctx context.Context, orgID uuid.UUID) (*stripeClient, *models.PaymentProviderConfig, error) {
+	config, err := s.GetConfig(ctx, orgID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if config == nil || !config.IsEnabled || config.SecretKeyEncrypted == nil {
+		return nil, config, nil
+	}
+	key, err := decryptSecret(s.encryptionKey, *config.SecretKeyEncrypted)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decrypt Stripe secret key: %w", err)
+	}
+	return &stripeClient{apiBase: s.apiBase, secretKey: key, client: s.client}, config, nil
+}
+
+// Create returns an open payment link for a target, creating a Stripe checkout when there is
+// none that stays valid for a while
+func (s *PaymentLinkService) Create(ctx context.Context, orgID uuid.UUID, targetType models.PaymentLinkTargetType, targetID uuid.UUID, createdBy *uuid.UUID) (*models.PaymentLink, error) {
+	client, config, err := s.stripe(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return nil, errors.New("payment links are not enabled")
+	}
+
+	target, err := s.resolveTarget(ctx, orgID, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	link, err := s.openLink(ctx, orgID, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if link != nil {
+		if link.Amount.Equal(target.amount) && time.Until(link.ExpiresAt) > paymentLinkReuseMargin {
+			return link, nil
+		}
+		// The amount changed or the link is about to expire; replace it
+		if err := client.expireCheckoutSession(ctx, link.ProviderReference); err != nil {
+			log.Printf("[PaymentLinks] Failed to expire checkout %s: %v", link.ProviderReference, err)
+		}
+		if err := s.setStatus(ctx, link.ID, models.PaymentLinkStatusExpired); err != nil {
+			return nil, err
+		}
+	}
+
+	id := uuid.New()
+	req := stripeCheckoutRequest{
+		Reference:   id.String(),
+		Description: target.description,
+		AmountCents: target.amount.Shift(2).Round(0).IntPart(),
+		Currency:    config.Currency,
+		SuccessURL:  s.frontendURL + "/payments/success",
+		CancelURL:   s.frontendURL + "/payments/cancelled",
+		ExpiresAt:   time.Now().Add(paymentLinkValidity),
+	}
+	if target.email != nil {
+		req.CustomerEmail = *target.email
+	}
+	session, err := client.createCheckoutSession(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	link = &models.PaymentLink{}
+	err = s.db.Pool.QueryRow(ctx, `
+		INSERT INTO payment_links (id, organization_id, provider, target_type, target_id, provider_reference,
+			url, amount, currency, expires_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING `+paymentLinkColumns,
+		id, orgID, models.PaymentProviderStripe, targetType, targetID, session.ID,
+		session.URL, target.amount, config.Currency, time.Unix(session.ExpiresAt, 0), createdBy,
+	).Scan(paymentLinkFields(link)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save payment link: %w", err)
+	}
+	return link, nil
+}
+
+// resolveTarget returns the amount still due on a target and who pays it
+func (s *PaymentLinkService) resolveTarget(ctx context.Context, orgID uuid.UUID, targetType models.PaymentLinkTargetType, targetID uuid.UUID) (*paymentLinkTarget, error) {
+	target := &paymentLinkTarget{}
+	switch targetType {
+	case models.PaymentLinkTargetSessionPayment:
+		var amountCents int
+		var status models.SessionPaymentStatus
+		var scheduledAt time.Time
+		var therapistName string
+		err := s.db.Pool.QueryRow(ctx, `
+			SELECT sp.amount_cents, sp.payment_status, s.scheduled_at, t.name, c.email
+			FROM session_payments sp
+			JOIN sessions s ON s.id = sp.session_id
+			JOIN therapists t ON t.id = s.therapist_id
+			JOIN patients p ON p.id = s.patient_id
+			LEFT JOIN clients c ON c.id = p.client_id
+			WHERE sp.id = $1 AND s.organization_id = $2 AND s.deleted_at IS NULL
+		`, targetID, orgID).Scan(&amountCents, &status, &scheduledAt, &therapistName, &target.email)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, errors.New("session payment not found")
+			}
+			return nil, fmt.Errorf("failed to get session payment: %w", err)
+		}
+		if status == models.SessionPaymentStatusPaid {
+			return nil, errors.New("payment is already paid")
+		}
+		target.amount = decimal.New(int64(amountCents), -2)
+		target.description = fmt.Sprintf("Sessão de %s com %s", scheduledAt.Format("02/01/2006 15:04"), therapistName)
+
+	case models.PaymentLinkTargetPayment:
+		var status models.PaymentStatus
+		var projectNumber, projectTitle string
+		err := s.db.Pool.QueryRow(ctx, `
+			SELECT pay.amount, pay.status, p.project_number, p.title, c.email
+			FROM payments pay
+			JOIN projects p ON p.id = pay.project_id
+			JOIN budgets b ON b.id = p.budget_id
+			JOIN worksheets w ON w.id = b.worksheet_id
+			JOIN clients c ON c.id = w.client_id
+			WHERE pay.id = $1 AND pay.organization_id = $2 AND pay.deleted_at IS NULL
+		`, targetID, orgID).Scan(&target.amount, &status, &projectNumber, &projectTitle, &target.email)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, errors.New("payment not found")
+			}
+			return nil, fmt.Errorf("failed to get payment: %w", err)
+		}
+		if status == models.PaymentStatusPaid {
+			return nil, errors.New("payment is already paid")
+		}
+		if status == models.PaymentStatusCancelled {
+			return nil, errors.New("payment is cancelled")
+		}
+		target.description = fmt.Sprintf("Projeto %s - %s", projectNumber, projectTitle)
+
+	case models.PaymentLinkTargetBudget:
+		// Payments already received on projects built from the budget are deducted
+		var status models.BudgetStatus
+		var budgetNumber string
+		var paid decimal.Decimal
+		err := s.db.Pool.QueryRow(ctx, `
+			SELECT b.total, b.status, b.budget_number, c.email,
+				COALESCE((
+					SELECT SUM(pay.amount) FROM payments pay
+					JOIN projects p ON p.id = pay.project_id
+					WHERE p.budget_id = b.id AND pay.status = 'paid' AND pay.deleted_at IS NULL
+				), 0)
+			FROM budgets b
+			JOIN worksheets w ON w.id = b.worksheet_id
+			JOIN clients c ON c.id = w.client_id
+			WHERE b.id = $1 AND b.organization_id = $2 AND b.deleted_at IS NULL
+		`, targetID, orgID).Scan(&target.amount, &status, &budgetNumber, &target.email, &paid)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, errors.New("budget not found")
+			}
+			return nil, fmt.Errorf("failed to get budget: %w", err)
+		}
+		if status != models.BudgetStatusApproved {
+			return nil, errors.New("only approved budgets can be paid online")
+		}
+		target.amount = target.amount.Sub(paid)
+		target.description = fmt.Sprintf("Orçamento %s", budgetNumber)
+
+	default:
+		return nil, errors.New("invalid payment link target")
+	}
+
+	if !target.amount.IsPositive() {
+		return nil, errors.New("nothing to pay")
+	}
+	if target.email != nil && *target.email == "" {
+		target.email = nil
+	}
+	return target, nil
+}
+
+// LinkFor returns the payment link of a workflow entity, for the {{payment_link}} template
+// variable: the unpaid payment of a session, a project payment or an approved budget. It returns
+// "" when payment links are not enabled or nothing is due.
+func (s *PaymentLinkService) LinkFor(ctx context.Context, orgID uuid.UUID, entityType string, entityID uuid.UUID) (string, error) {
+	config, err := s.GetConfig(ctx, orgID)
+	if err != nil || config == nil || !config.IsEnabled {
+		return "", err
+	}
+
+	var targetType models.PaymentLinkTargetType
+	targetID := entityID
+	switch entityType {
+	case "session":
+		targetType = models.PaymentLinkTargetSessionPayment
+		err := s.db.Pool.QueryRow(ctx, `
+			SELECT sp.id FROM session_payments sp
+			JOIN sessions s ON s.id = sp.session_id
+			WHERE sp.session_id = $1 AND s.organization_id = $2 AND sp.payment_status <> 'paid'
+		`, entityID, orgID).Scan(&targetID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to get session payment: %w", err)
+		}
+	case "payment":
+		targetType = models.PaymentLinkTargetPayment
+	case "budget":
+		targetType = models.PaymentLinkTargetBudget
+	default:
+		return "", nil
+	}
+
+	link, err := s.Create(ctx, orgID, targetType, targetID, nil)
+	if err != nil {
+		switch err.Error() {
+		case "payment is already paid", "payment is cancelled", "only approved budgets can be paid online", "nothing to pay":
+			return "", nil
+		}
+		return "", err
+	}
+	return link.URL, nil
+}
+
+const paymentLinkColumns = `id, organization_id, provider, target_type, target_id, provider_reference, url,
+	amount, currency, status, expires_at, paid_at, payment_id, created_by, created_at, updated_at`
+
+func paymentLinkFields(l *models.PaymentLink) []interface{} {
+	return []interface{}{&l.ID, &l.OrganizationID, &l.Provider, &l.TargetType, &l.TargetID, &l.ProviderReference, &l.URL,
+		&l.Amount, &l.Currency, &l.Status, &l.ExpiresAt, &l.PaidAt, &l.PaymentID, &l.CreatedBy, &l.CreatedAt, &l.UpdatedAt}
+}
+
+// openLink returns the open link of a target, or nil
+func (s *PaymentLinkService) openLink(ctx context.Context, orgID uuid.UUID, targetType models.PaymentLinkTargetType, targetID uuid.UUID) (*models.PaymentLink, error) {
+	link := &models.PaymentLink{}
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT `+paymentLinkColumns+` FROM payment_links
+		WHERE organization_id = $1 AND target_type = $2 AND target_id = $3 AND status = 'open'
+	`, orgID, targetType, targetID).Scan(paymentLinkFields(link)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get payment link: %w", err)
+	}
+	return link, nil
+}
+
+func (s *PaymentLinkService) setStatus(ctx context.Context, id uuid.UUID, status models.PaymentLinkStatus) error {
+	_, err := s.db.Pool.Exec(ctx, `
+		UPDATE payment_links SET status = $1 WHERE id = $2 AND status = 'open'
+	`, status, id)
+	if err != nil {
+		return fmt.Errorf("failed to update payment link: %w", err)
+	}
+	return nil
+}
+
+// List returns payment links, newest first
+func (s *PaymentLinkService) List(ctx context.Context, orgID uuid.UUID, filters PaymentLinkFilters) ([]*models.PaymentLink, int, error) {
+	where := "WHERE organization_id = $1"
+	args := []interface{}{orgID}
+	if filters.TargetType != "" {
+		args = append(args, filters.TargetType)
+		where += fmt.Sprintf(" AND target_type = $%d", len(args))
+	}
+	if filters.TargetID != nil {
+		args = append(args, *filters.TargetID)
+		where += fmt.Sprintf(" AND target_id = $%d", len(args))
+	}
+	if filters.Status != "" {
+		args = append(args, filters.Status)
+		where += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+
+	var total int
+	if err := s.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM payment_links "+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count payment links: %w", err)
+	}
+
+	args = append(args, filters.Limit, filters.Offset)
+	rows, err := s.db.Pool.Query(ctx, fmt.Sprintf(`
+		SELECT %s FROM payment_links %s
+		ORDER BY created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, paymentLinkColumns, where, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list payment links: %w", err)
+	}
+	defer rows.Close()
+
+	links := []*models.PaymentLink{}
+	for rows.Next() {
+		link := &models.PaymentLink{}
+		if err := rows.Scan(paymentLinkFields(link)...); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan payment link: %w", err)
+		}
+		links = append(links, link)
+	}
+	return links, total, rows.Err()
+}
+
+// Cancel expires an open link at Stripe so it can no longer be paid
+func (s *PaymentLinkService) Cancel(ctx context.Context, id, orgID uuid.UUID) error {
+	link := &models.PaymentLink{}
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT `+paymentLinkColumns+` FROM payment_links WHERE id = $1 AND organization_id = $2
+	`, id, orgID).Scan(paymentLinkFields(link)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("payment link not found")
+		}
+		return fmt.Errorf("failed to get payment link: %w", err)
+	}
+	if link.Status != models.PaymentLinkStatusOpen {
+		return errors.New("only open payment links can be cancelled")
+	}
+
+	client, _, err := s.stripe(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if client != nil {
+		if err := client.expireCheckoutSession(ctx, link.ProviderReference); err != nil {
+			return err
+		}
+	}
+	return s.setStatus(ctx, id, models.PaymentLinkStatusCancelled)
+}
+
+// ResolveOrganizationByToken returns the organization that owns a Stripe webhook token
+func (s *PaymentLinkService) ResolveOrganizationByToken(ctx context.Context, token string) (uuid.UUID, error) {
+	var orgID uuid.UUID
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT organization_id FROM payment_provider_configs WHERE webhook_token = $1
+	`, token).Scan(&orgID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, errors.New("unknown webhook token")
+		}
+		return uuid.Nil, fmt.Errorf("failed to resolve webhook token: %w", err)
+	}
+	return orgID, nil
+}
+
+// ErrInvalidWebhookSignature is returned for webhooks not signed with the organization's secret
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// HandleStripeWebhook verifies and processes a Stripe event for an organization. Completed
+// checkouts mark their payment as paid; expired ones close the link.
+func (s *PaymentLinkService) HandleStripeWebhook(ctx context.Context, orgID uuid.UUID, payload []byte, signature string) error {
+	config, err := s.GetConfig(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if config == nil || config.WebhookSecretEncrypted == nil {
+		return ErrInvalidWebhookSignature
+	}
+	secret, err := decryptSecret(s.encryptionKey, *config.WebhookSecretEncrypted)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt webhook secret: %w", err)
+	}
+	if err := verifyStripeSignature(payload, signature, secret, time.Now()); err != nil {
+		log.Printf("[PaymentLinks] Rejected Stripe webhook for org %s: %v", orgID, err)
+		return ErrInvalidWebhookSignature
+	}
+
+	var event stripeEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return fmt.Errorf("failed to decode Stripe event: %w", err)
+	}
+
+	switch event.Type {
+	case "checkout.session.completed", "checkout.session.async_payment_succeeded":
+		var session stripeCheckoutSession
+		if err := json.Unmarshal(event.Data.Object, &session); err != nil {
+			return fmt.Errorf("failed to decode checkout session: %w", err)
+		}
+		// Delayed payment methods complete the checkout before the money arrives
+		if session.PaymentStatus != "paid" {
+			return nil
+		}
+		return s.markPaid(ctx, orgID, session.ID)
+	case "checkout.session.expired":
+		var session stripeCheckoutSession
+		if err := json.Unmarshal(event.Data.Object, &session); err != nil {
+			return fmt.Errorf("failed to decode checkout session: %w", err)
+		}
+		_, err := s.db.Pool.Exec(ctx, `
+			UPDATE payment_links SET status = 'expired'
+			WHERE organization_id = $1 AND provider = $2 AND provider_reference = $3 AND status = 'open'
+		`, orgID, models.PaymentProviderStripe, session.ID)
+		if err != nil {
+			return fmt.Errorf("failed to expire payment link: %w", err)
+		}
+	}
+	return nil
+}
+
+// markPaid records a paid checkout on its target and link. Stripe retries events until they are
+// acknowledged, so the target is updated before the link and a link already paid is skipped.
+func (s *PaymentLinkService) markPaid(ctx context.Context, orgID uuid.UUID, checkoutID string) error {
+	link := &models.PaymentLink{}
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT `+paymentLinkColumns+` FROM payment_links
+		WHERE organization_id = $1 AND provider = $2 AND provider_reference = $3
+	`, orgID, models.PaymentProviderStripe, checkoutID).Scan(paymentLinkFields(link)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("[PaymentLinks] Ignoring unknown checkout %s for org %s", checkoutID, orgID)
+			return nil
+		}
+		return fmt.Errorf("failed to get payment link: %w", err)
+	}
+	if link.Status == models.PaymentLinkStatusPaid {
+		return nil
+	}
+
+	paidAt := time.Now()
+	method := "stripe"
+	switch link.TargetType {
+	case models.PaymentLinkTargetSessionPayment:
+		var sessionID uuid.UUID
+		if err := s.db.Pool.QueryRow(ctx, `
+			SELECT session_id FROM session_payments WHERE id = $1
+		`, link.TargetID).Scan(&sessionID); err != nil {
+			return fmt.Errorf("failed to get session payment: %w", err)
+		}
+		card := models.PaymentMethodCard
+		if err := s.sessionPayments.MarkAsPaid(ctx, sessionID, orgID, &card); err != nil {
+			return err
+		}
+
+	case models.PaymentLinkTargetPayment:
+		_, err := s.payments.MarkAsPaid(ctx, link.TargetID, orgID, MarkPaymentPaidRequest{
+			PaidAt:    &paidAt,
+			Method:    &method,
+			Reference: &checkoutID,
+		})
+		// Payments recorded by hand in the meantime are not open anymore
+		if err != nil && !errors.Is(err, ErrPaymentNotOpen) {
+			return err
+		}
+
+	case models.PaymentLinkTargetBudget:
+		return s.recordBudgetPayment(ctx, orgID, link, paidAt)
+	}
+
+	_, err = s.db.Pool.Exec(ctx, `
+		UPDATE payment_links SET status = 'paid', paid_at = $1 WHERE id = $2 AND status <> 'paid'
+	`, paidAt, link.ID)
+	if err != nil {
+		return fmt.Errorf("failed to mark payment link as paid: %w", err)
+	}
+	return nil
+}
+
+// recordBudgetPayment marks a budget link as paid and records the payment as received on the
+// project built from the budget. The budget is locked first, as when its project is created, so
+// a payment made before the project exists is recorded by recordBudgetLinkPayments instead.
+func (s *PaymentLinkService) recordBudgetPayment(ctx context.Context, orgID uuid.UUID, link *models.PaymentLink, paidAt time.Time) error {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var createdBy uuid.UUID
+	err = tx.QueryRow(ctx, `
+		SELECT created_by FROM budgets WHERE id = $1 AND organization_id = $2 FOR UPDATE
+	`, link.TargetID, orgID).Scan(&createdBy)
+	if err != nil {
+		return fmt.Errorf("failed to get budget: %w", err)
+	}
+
+	result, err := tx.Exec(ctx, `
+		UPDATE payment_links SET status = 'paid', paid_at = $1 WHERE id = $2 AND status <> 'paid'
+	`, paidAt, link.ID)
+	if err != nil {
+		return fmt.Errorf("failed to mark payment link as paid: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil
+	}
+	link.Status = models.PaymentLinkStatusPaid
+	link.PaidAt = &paidAt
+
+	var projectID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		SELECT id FROM projects
+		WHERE budget_id = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`, link.TargetID, orgID).Scan(&projectID)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		log.Printf("[PaymentLinks] Budget %s was paid online before it has a project, recording the payment when it is created", link.TargetID)
+	case err != nil:
+		return fmt.Errorf("failed to get budget project: %w", err)
+	default:
+		if err := recordBudgetLinkPayment(ctx, tx, orgID, projectID, createdBy, link); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// recordBudgetLinkPayments records the budget's links paid before its project existed as
+// payments received on the project. It runs in the transaction creating the project, with
+// the budget locked.
+func recordBudgetLinkPayments(ctx context.Context, tx pgx.Tx, orgID, budgetID, projectID, createdBy uuid.UUID) error {
+	rows, err := tx.Query(ctx, `
+		SELECT `+paymentLinkColumns+` FROM payment_links
+		WHERE organization_id = $1 AND target_type = $2 AND target_id = $3 AND status = 'paid' AND payment_id IS NULL
+		ORDER BY paid_at
+		FOR UPDATE
+	`, orgID, models.PaymentLinkTargetBudget, budgetID)
+	if err != nil {
+		return fmt.Errorf("failed to get budget payment links: %w", err)
+	}
+	var links []*models.PaymentLink
+	for rows.Next() {
+		link := &models.PaymentLink{}
+		if err := rows.Scan(paymentLinkFields(link)...); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan payment link: %w", err)
+		}
+		links = append(links, link)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to get budget payment links: %w", err)
+	}
+
+	for _, link := range links {
+		if err := recordBudgetLinkPayment(ctx, tx, orgID, projectID, createdBy, link); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordBudgetLinkPayment records a paid budget link as a payment received on the budget's
+// project, by the link's creator or else createdBy, in an open financial period
+func recordBudgetLinkPayment(ctx context.Context, tx pgx.Tx, orgID, projectID, createdBy uuid.UUID, link *models.PaymentLink) error {
+	if link.PaidAt == nil {
+		return errors.New("payment link is not paid")
+	}
+	if err := checkPeriodsOpen(ctx, tx, orgID, *link.PaidAt); err != nil {
+		return err
+	}
+	if link.CreatedBy != nil {
+		createdBy = *link.CreatedBy
+	}
+
+	paymentID := uuid.New()
+	_, err := tx.Exec(ctx, `
+		INSERT INTO payments (id, organization_id, project_id, amount, status, due_date, paid_at, method, reference, created_by)
+		VALUES ($1, $2, $3, $4, 'paid', $5, $5, $6, $7, $8)
+	`, paymentID, orgID, projectID, link.Amount, *link.PaidAt, link.Provider, link.ProviderReference, createdBy)
+	if err != nil {
+		return fmt.Errorf("failed to record budget payment: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE payment_links SET payment_id = $1 WHERE id = $2`, paymentID, link.ID); err != nil {
+		return fmt.Errorf("failed to update payment link: %w", err)
+	}
+	link.PaymentID = &paymentID
+	return nil
+}
@@ -0,0 +1,740 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// paymentReferenceReuseMargin keeps references about to expire out of new messages
+const paymentReferenceReuseMargin = 24 * time.Hour
+
+var ibanPattern = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{11,30}$`)
+
+// PaymentReferenceService generates Multibanco references, with an organization's IfThenPay or
+// Easypay account, and SEPA RF references for unpaid session payments and project payments, and
+// marks them as paid when the provider reports the payment
+type PaymentReferenceService struct {
+	db              *database.DB
+	encryptionKey   []byte
+	easypayAPIBase  string
+	client          *http.Client
+	sessionPayments *SessionPaymentService
+	payments        *PaymentService
+}
+
+func NewPaymentReferenceService(db *database.DB, encryptionKey string, sessionPayments *SessionPaymentService, payments *PaymentService) *PaymentReferenceService {
+	return &PaymentReferenceService{
+		db:              db,
+		encryptionKey:   secretKey(encryptionKey),
+		easypayAPIBase:  easypayAPIBase,
+		client:          &http.Client{Timeout: 30 * time.Second},
+		sessionPayments: sessionPayments,
+		payments:        payments,
+	}
+}
+
+// PaymentReferenceConfigInput updates an organization's Multibanco provider. Secrets are kept
+// when omitted.
+type PaymentReferenceConfigInput struct {
+	Provider     string  `json:"provider"` // ifthenpay or easypay
+	IsEnabled    bool    `json:"is_enabled"`
+	MBEntity     *string `json:"mb_entity"`
+	MBSubentity  *string `json:"mb_subentity"`
+	AccountID    *string `json:"account_id"`
+	APIKey       *string `json:"api_key"`
+	CallbackKey  *string `json:"callback_key"`
+	IBAN         *string `json:"iban"` // SEPA transfers; RF references are only generated with it
+	ValidityDays *int    `json:"validity_days"`
+}
+
+// PaymentReferenceFilters contains filters for listing payment references
+type PaymentReferenceFilters struct {
+	TargetType string
+	TargetID   *uuid.UUID
+	Status     string
+	Limit      int
+	Offset     int
+}
+
+// paymentReferenceTarget is what a reference collects, resolved from its target
+type paymentReferenceTarget struct {
+	amount      decimal.Decimal
+	description string
+	name        string
+	email       *string
+}
+
+// GetConfig returns the organization's payment reference configuration, or nil when not set up
+func (s *PaymentReferenceService) GetConfig(ctx context.Context, orgID uuid.UUID) (*models.PaymentReferenceConfig, error) {
+	var config models.PaymentReferenceConfig
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT organization_id, provider, is_enabled, mb_entity, mb_subentity, account_id, api_key_encrypted,
+			callback_key_encrypted, iban, validity_days, callback_token, created_at, updated_at
+		FROM payment_reference_configs WHERE organization_id = $1
+	`, orgID).Scan(&config.OrganizationID, &config.Provider, &config.IsEnabled, &config.MBEntity, &config.MBSubentity,
+		&config.AccountID, &config.APIKeyEncrypted, &config.CallbackKeyEncrypted, &config.IBAN, &config.ValidityDays,
+		&config.CallbackToken, &config.CreatedAt, &config.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get payment reference config: %w", err)
+	}
+	return &config, nil
+}
+
+// SaveConfig creates or updates the organization's Multibanco provider and IBAN
+func (s *PaymentReferenceService) SaveConfig(ctx context.Context, orgID uuid.UUID, input PaymentReferenceConfigInput) (*models.PaymentReferenceConfig, error) {
+	if input.Provider != models.PaymentReferenceProviderIfthenpay && input.Provider != models.PaymentReferenceProviderEasypay {
+		return nil, errors.New("invalid payment reference provider")
+	}
+	if input.MBEntity != nil && *input.MBEntity != "" && !mbEntityPattern.MatchString(*input.MBEntity) {
+		return nil, errors.New("invalid Multibanco entity")
+	}
+	if input.MBSubentity != nil && *input.MBSubentity != "" && !mbSubentityPattern.MatchString(*input.MBSubentity) {
+		return nil, errors.New("invalid Multibanco sub-entity")
+	}
+	var iban *string
+	if input.IBAN != nil {
+		normalized := strings.ToUpper(strings.ReplaceAll(*input.IBAN, " ", ""))
+		if normalized != "" && !ibanPattern.MatchString(normalized) {
+			return nil, errors.New("invalid IBAN")
+		}
+		iban = &normalized
+	}
+	if input.ValidityDays != nil && (*input.ValidityDays < 1 || *input.ValidityDays > 365) {
+		return nil, errors.New("validity must be between 1 and 365 days")
+	}
+
+	var encryptedAPIKey, encryptedCallbackKey *string
+	if input.APIKey != nil && *input.APIKey != "" {
+		encrypted, err := encryptSecret(s.encryptionKey, strings.TrimSpace(*input.APIKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt API key: %w", err)
+		}
+		encryptedAPIKey = &encrypted
+	}
+	if input.CallbackKey != nil && *input.CallbackKey != "" {
+		encrypted, err := encryptSecret(s.encryptionKey, strings.TrimSpace(*input.CallbackKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt callback key: %w", err)
+		}
+		encryptedCallbackKey = &encrypted
+	}
+
+	existing, err := s.GetConfig(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if input.IsEnabled {
+		set := func(value *string, current *string) bool {
+			if value != nil {
+				return *value != ""
+			}
+			return existing != nil && current != nil && *current != ""
+		}
+		var current models.PaymentReferenceConfig
+		if existing != nil {
+			current = *existing
+		}
+		switch input.Provider {
+		case models.PaymentReferenceProviderIfthenpay:
+			if !set(input.MBEntity, current.MBEntity) || !set(input.MBSubentity, current.MBSubentity) {
+				return nil, errors.New("the Multibanco entity and sub-entity are required to enable IfThenPay")
+			}
+			if !set(input.CallbackKey, current.CallbackKeyEncrypted) {
+				return nil, errors.New("the anti-phishing key is required to enable IfThenPay")
+			}
+		case models.PaymentReferenceProviderEasypay:
+			if !set(input.AccountID, current.AccountID) || !set(input.APIKey, current.APIKeyEncrypted) {
+				return nil, errors.New("the account ID and API key are required to enable Easypay")
+			}
+		}
+	}
+
+	_, err = s.db.Pool.Exec(ctx, `
+		INSERT INTO payment_reference_configs (organization_id, provider, is_enabled, mb_entity, mb_subentity,
+			account_id, api_key_encrypted, callback_key_encrypted, iban, validity_days)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''), $7, $8, NULLIF($9, ''), COALESCE($10, 30))
+		ON CONFLICT (organization_id) DO UPDATE SET
+			provider = EXCLUDED.provider,
+			is_enabled = EXCLUDED.is_enabled,
+			mb_entity = CASE WHEN $4::text IS NULL THEN payment_reference_configs.mb_entity ELSE NULLIF($4, '') END,
+			mb_subentity = CASE WHEN $5::text IS NULL THEN payment_reference_configs.mb_subentity ELSE NULLIF($5, '') END,
+			account_id = CASE WHEN $6::text IS NULL THEN payment_reference_configs.account_id ELSE NULLIF($6, '') END,
+			api_key_encrypted = COALESCE($7, payment_reference_configs.api_key_encrypted),
+			callback_key_encrypted = COALESCE($8, payment_reference_configs.callback_key_encrypted),
+			iban = CASE WHEN $9::text IS NULL THEN payment_reference_configs.iban ELSE NULLIF($9, '') END,
+			validity_days = COALESCE($10, payment_reference_configs.validity_days),
+			updated_at = CURRENT_TIMESTAMP
+	`, orgID, input.Provider, input.IsEnabled, input.MBEntity, input.MBSubentity, input.AccountID,
+		encryptedAPIKey, encryptedCallbackKey, iban, input.ValidityDays)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save payment reference config: %w", err)
+	}
+
+	return s.GetConfig(ctx, orgID)
+}
+
+// provider returns the Multibanco provider of the organization's account
+func (s *PaymentReferenceService) provider(config *models.PaymentReferenceConfig) (multibancoProvider, error) {
+	switch config.Provider {
+	case models.PaymentReferenceProviderIfthenpay:
+		if config.MBEntity == nil || config.MBSubentity == nil {
+			return nil, errors.New("the Multibanco entity is not configured")
+		}
+		return &ifthenpayProvider{entity: *config.MBEntity, subentity: *config.MBSubentity}, nil
+	case models.PaymentReferenceProviderEasypay:
+		client, err := s.easypay(config)
+		if err != nil {
+			return nil, err
+		}
+		return &easypayProvider{client: client}, nil
+	}
+	return nil, errors.New("invalid payment reference provider")
+}
+
+func (s *PaymentReferenceService) easypay(config *models.PaymentReferenceConfig) (*easypayClient, error) {
+	if config.AccountID == nil || config.APIKeyEncrypted == nil {
+		return nil, errors.New("the Easypay account is not configured")
+	}
+	key, err := decryptSecret(s.encryptionKey, *config.APIKeyEncrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt Easypay API key: %w", err)
+	}
+	return &easypayClient{apiBase: s.easypayAPIBase, accountID: *config.AccountID, apiKey: key, client: s.client}, nil
+}
+
+// Generate returns the open payment reference of a target, generating new Multibanco and SEPA
+// references when there is none for the amount due that stays valid for a while
+func (s *PaymentReferenceService) Generate(ctx context.Context, orgID uuid.UUID, targetType models.PaymentReferenceTargetType, targetID uuid.UUID, createdBy *uuid.UUID) (*models.PaymentReference, error) {
+	config, err := s.GetConfig(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil || !config.IsEnabled {
+		return nil, errors.New("payment references are not enabled")
+	}
+
+	target, err := s.resolveTarget(ctx, orgID, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	ref, err := s.openReference(ctx, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if ref != nil {
+		if ref.Amount.Equal(target.amount) && time.Until(ref.ExpiresAt) > paymentReferenceReuseMargin {
+			return ref, nil
+		}
+		// The amount changed or the reference is about to expire; replace it
+		if err := s.setStatus(ctx, ref.ID, models.PaymentReferenceStatusCancelled); err != nil {
+			return nil, err
+		}
+	}
+
+	provider, err := s.provider(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var orderID int64
+	err = s.db.Pool.QueryRow(ctx, `
+		UPDATE payment_reference_configs SET next_order_id = next_order_id + 1
+		WHERE organization_id = $1
+		RETURNING next_order_id - 1
+	`, orgID).Scan(&orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate payment reference order: %w", err)
+	}
+
+	id := uuid.New()
+	expiresAt := time.Now().AddDate(0, 0, config.ValidityDays)
+	req := multibancoRequest{
+		Key:          id.String(),
+		OrderID:      orderID,
+		Description:  target.description,
+		Amount:       target.amount,
+		ExpiresAt:    expiresAt,
+		CustomerName: target.name,
+	}
+	if target.email != nil {
+		req.CustomerEmail = *target.email
+	}
+	mb, err := provider.generate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var sepaReference *string
+	if config.IBAN != nil {
+		rf := sepaCreditorReference(fmt.Sprintf("%010d", orderID))
+		sepaReference = &rf
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	ref = &models.PaymentReference{}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO payment_references (id, organization_id, provider, target_type, target_id, mb_entity, mb_reference,
+			sepa_reference, provider_reference, amount, expires_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING `+paymentReferenceColumns,
+		id, orgID, config.Provider, targetType, targetID, mb.Entity, mb.Reference,
+		sepaReference, mb.ProviderReference, target.amount, expiresAt, createdBy,
+	).Scan(paymentReferenceFields(ref)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save payment reference: %w", err)
+	}
+
+	// The current references are kept on the payment, where they are shown and matched
+	table := "payments"
+	if targetType == models.PaymentReferenceTargetSessionPayment {
+		table = "session_payments"
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE `+table+` SET mb_entity = $1, mb_reference = $2, sepa_reference = $3, updated_at = NOW()
+		WHERE id = $4
+	`, mb.Entity, mb.Reference, sepaReference, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store payment reference: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return ref, nil
+}
+
+// resolveTarget returns the amount still due on a target and who pays it
+func (s *PaymentReferenceService) resolveTarget(ctx context.Context, orgID uuid.UUID, targetType models.PaymentReferenceTargetType, targetID uuid.UUID) (*paymentReferenceTarget, error) {
+	target := &paymentReferenceTarget{}
+	switch targetType {
+	case models.PaymentReferenceTargetSessionPayment:
+		var amountCents int
+		var status models.SessionPaymentStatus
+		var scheduledAt time.Time
+		var therapistName string
+		err := s.db.Pool.QueryRow(ctx, `
+			SELECT sp.amount_cents, sp.payment_status, s.scheduled_at, t.name, COALESCE(c.name, ''), c.email
+			FROM session_payments sp
+			JOIN sessions s ON s.id = sp.session_id
+			JOIN therapists t ON t.id = s.therapist_id
+			JOIN patients p ON p.id = s.patient_id
+			LEFT JOIN clients c ON c.id = p.client_id
+			WHERE sp.id = $1 AND s.organization_id = $2 AND s.deleted_at IS NULL
+		`, targetID, orgID).Scan(&amountCents, &status, &scheduledAt, &therapistName, &target.name, &target.email)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, errors.New("session payment not found")
+			}
+			return nil, fmt.Errorf("failed to get session payment: %w", err)
+		}
+		if status == models.SessionPaymentStatusPaid {
+			return nil, errors.New("payment is already paid")
+		}
+		target.amount = decimal.New(int64(amountCents), -2)
+		target.description = fmt.Sprintf("Sessão de %s com %s", scheduledAt.Format("02/01/2006 15:04"), therapistName)
+
+	case models.PaymentReferenceTargetPayment:
+		var status models.PaymentStatus
+		var projectNumber, projectTitle string
+		err := s.db.Pool.QueryRow(ctx, `
+			SELECT pay.amount, pay.status, p.project_number, p.title, COALESCE(c.name, ''), c.email
+			FROM payments pay
+			JOIN projects p ON p.id = pay.project_id
+			LEFT JOIN budgets b ON b.id = p.budget_id
+			LEFT JOIN worksheets w ON w.id = b.worksheet_id
+			LEFT JOIN clients c ON c.id = w.client_id
+			WHERE pay.id = $1 AND pay.organization_id = $2 AND pay.deleted_at IS NULL
+		`, targetID, orgID).Scan(&target.amount, &status, &projectNumber, &projectTitle, &target.name, &target.email)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, errors.New("payment not found")
+			}
+			return nil, fmt.Errorf("failed to get payment: %w", err)
+		}
+		if status == models.PaymentStatusPaid {
+			return nil, errors.New("payment is already paid")
+		}
+		if status == models.PaymentStatusCancelled {
+			return nil, errors.New("payment is cancelled")
+		}
+		target.description = fmt.Sprintf("Projeto %s - %s", projectNumber, projectTitle)
+
+	default:
+		return nil, errors.New("invalid payment reference target")
+	}
+
+	if !target.amount.IsPositive() {
+		return nil, errors.New("nothing to pay")
+	}
+	if target.email != nil && *target.email == "" {
+		target.email = nil
+	}
+	return target, nil
+}
+
+// ReferencesFor returns the payment references of a workflow entity, for the {{mb_entity}},
+// {{mb_reference}}, {{sepa_reference}} and {{iban}} template variables: those of the unpaid
+// payment of a session or of a project payment. It returns nil when payment references are not
+// enabled or nothing is due.
+func (s *PaymentReferenceService) ReferencesFor(ctx context.Context, orgID uuid.UUID, entityType string, entityID uuid.UUID) (map[string]string, error) {
+	config, err := s.GetConfig(ctx, orgID)
+	if err != nil || config == nil || !config.IsEnabled {
+		return nil, err
+	}
+
+	var targetType models.PaymentReferenceTargetType
+	targetID := entityID
+	switch entityType {
+	case "session":
+		targetType = models.PaymentReferenceTargetSessionPayment
+		err := s.db.Pool.QueryRow(ctx, `
+			SELECT sp.id FROM session_payments sp
+			JOIN sessions s ON s.id = sp.session_id
+			WHERE sp.session_id = $1 AND s.organization_id = $2 AND sp.payment_status <> 'paid'
+		`, entityID, orgID).Scan(&targetID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get session payment: %w", err)
+		}
+	case "payment":
+		targetType = models.PaymentReferenceTargetPayment
+	default:
+		return nil, nil
+	}
+
+	ref, err := s.Generate(ctx, orgID, targetType, targetID, nil)
+	if err != nil {
+		switch err.Error() {
+		case "payment is already paid", "payment is cancelled", "nothing to pay", "amount cannot be paid by Multibanco":
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	vars := map[string]string{}
+	if ref.MBEntity != nil && ref.MBReference != nil {
+		vars["mb_entity"] = *ref.MBEntity
+		vars["mb_reference"] = formatMultibancoReference(*ref.MBReference)
+	}
+	if ref.SEPAReference != nil && config.IBAN != nil {
+		vars["sepa_reference"] = *ref.SEPAReference
+		vars["iban"] = *config.IBAN
+	}
+	return vars, nil
+}
+
+// formatMultibancoReference groups a reference in threes, as ATMs and home banking show it
+func formatMultibancoReference(reference string) string {
+	if len(reference) != 9 {
+		return reference
+	}
+	return reference[0:3] + " " + reference[3:6] + " " + reference[6:9]
+}
+
+const paymentReferenceColumns = `id, organization_id, provider, target_type, target_id, mb_entity, mb_reference,
+	sepa_reference, provider_reference, amount, status, expires_at, paid_at, created_by, created_at, updated_at`
+
+func paymentReferenceFields(r *models.PaymentReference) []interface{} {
+	return []interface{}{&r.ID, &r.OrganizationID, &r.Provider, &r.TargetType, &r.TargetID, &r.MBEntity, &r.MBReference,
+		&r.SEPAReference, &r.ProviderReference, &r.Amount, &r.Status, &r.ExpiresAt, &r.PaidAt, &r.CreatedBy, &r.CreatedAt, &r.UpdatedAt}
+}
+
+// openReference returns the open reference of a target, or nil
+func (s *PaymentReferenceService) openReference(ctx context.Context, targetType models.PaymentReferenceTargetType, targetID uuid.UUID) (*models.PaymentReference, error) {
+	ref := &models.PaymentReference{}
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT `+paymentReferenceColumns+` FROM payment_references
+		WHERE target_type = $1 AND target_id = $2 AND status = 'open'
+	`, targetType, targetID).Scan(paymentReferenceFields(ref)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get payment reference: %w", err)
+	}
+	return ref, nil
+}
+
+func (s *PaymentReferenceService) setStatus(ctx context.Context, id uuid.UUID, status models.PaymentReferenceStatus) error {
+	_, err := s.db.Pool.Exec(ctx, `
+		UPDATE payment_references SET status = $1 WHERE id = $2 AND status = 'open'
+	`, status, id)
+	if err != nil {
+		return fmt.Errorf("failed to update payment reference: %w", err)
+	}
+	return nil
+}
+
+// List returns payment references, newest first
+func (s *PaymentReferenceService) List(ctx context.Context, orgID uuid.UUID, filters PaymentReferenceFilters) ([]*models.PaymentReference, int, error) {
+	where := "WHERE organization_id = $1"
+	args := []interface{}{orgID}
+	if filters.TargetType != "" {
+		args = append(args, filters.TargetType)
+		where += fmt.Sprintf(" AND target_type = $%d", len(args))
+	}
+	if filters.TargetID != nil {
+		args = append(args, *filters.TargetID)
+		where += fmt.Sprintf(" AND target_id = $%d", len(args))
+	}
+	if filters.Status != "" {
+		args = append(args, filters.Status)
+		where += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+
+	var total int
+	if err := s.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM payment_references "+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count payment references: %w", err)
+	}
+
+	args = append(args, filters.Limit, filters.Offset)
+	rows, err := s.db.Pool.Query(ctx, fmt.Sprintf(`
+		SELECT %s FROM payment_references %s
+		ORDER BY created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, paymentReferenceColumns, where, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list payment references: %w", err)
+	}
+	defer rows.Close()
+
+	refs := []*models.PaymentReference{}
+	for rows.Next() {
+		ref := &models.PaymentReference{}
+		if err := rows.Scan(paymentReferenceFields(ref)...); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan payment reference: %w", err)
+		}
+		refs = append(refs, ref)
+	}
+	return refs, total, rows.Err()
+}
+
+// Cancel withdraws an open reference and removes it from its payment. Multibanco references
+// cannot be revoked at the ATM, so a payment made with it afterwards is still recorded.
+func (s *PaymentReferenceService) Cancel(ctx context.Context, id, orgID uuid.UUID) error {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var targetType models.PaymentReferenceTargetType
+	var targetID uuid.UUID
+	var status models.PaymentReferenceStatus
+	err = tx.QueryRow(ctx, `
+		SELECT target_type, target_id, status FROM payment_references
+		WHERE id = $1 AND organization_id = $2
+		FOR UPDATE
+	`, id, orgID).Scan(&targetType, &targetID, &status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("payment reference not found")
+		}
+		return fmt.Errorf("failed to get payment reference: %w", err)
+	}
+	if status != models.PaymentReferenceStatusOpen {
+		return errors.New("only open payment references can be cancelled")
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE payment_references SET status = 'cancelled' WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to cancel payment reference: %w", err)
+	}
+	table := "payments"
+	if targetType == models.PaymentReferenceTargetSessionPayment {
+		table = "session_payments"
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE `+table+` SET mb_entity = NULL, mb_reference = NULL, sepa_reference = NULL, updated_at = NOW()
+		WHERE id = $1
+	`, targetID)
+	if err != nil {
+		return fmt.Errorf("failed to remove payment reference: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// ResolveOrganizationByToken returns the organization that owns a payment reference callback token
+func (s *PaymentReferenceService) ResolveOrganizationByToken(ctx context.Context, provider, token string) (uuid.UUID, error) {
+	var orgID uuid.UUID
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT organization_id FROM payment_reference_configs WHERE callback_token = $1 AND provider = $2
+	`, token, provider).Scan(&orgID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, errors.New("unknown callback token")
+		}
+		return uuid.Nil, fmt.Errorf("failed to resolve callback token: %w", err)
+	}
+	return orgID, nil
+}
+
+// ErrInvalidCallbackKey is returned for IfThenPay callbacks without the organization's anti-phishing key
+var ErrInvalidCallbackKey = errors.New("invalid callback key")
+
+// HandleIfthenpayCallback records a Multibanco payment IfThenPay reports with the entity,
+// reference and amount paid
+func (s *PaymentReferenceService) HandleIfthenpayCallback(ctx context.Context, orgID uuid.UUID, key, entity, reference, amount string) error {
+	config, err := s.GetConfig(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if config == nil || config.CallbackKeyEncrypted == nil {
+		return ErrInvalidCallbackKey
+	}
+	expected, err := decryptSecret(s.encryptionKey, *config.CallbackKeyEncrypted)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt callback key: %w", err)
+	}
+	if subtle.ConstantTimeCompare([]byte(key), []byte(expected)) != 1 {
+		log.Printf("[PaymentReferences] Rejected IfThenPay callback for org %s", orgID)
+		return ErrInvalidCallbackKey
+	}
+
+	paid, err := decimal.NewFromString(strings.ReplaceAll(amount, ",", "."))
+	if err != nil {
+		return fmt.Errorf("invalid amount %q", amount)
+	}
+
+	// References repeat once the order sequence wraps; the amount tells them apart
+	ref := &models.PaymentReference{}
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT `+paymentReferenceColumns+` FROM payment_references
+		WHERE organization_id = $1 AND mb_entity = $2 AND mb_reference = $3 AND amount = $4
+		ORDER BY (status = 'paid'), created_at DESC
+		LIMIT 1
+	`, orgID, entity, strings.ReplaceAll(reference, " ", ""), paid).Scan(paymentReferenceFields(ref)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("[PaymentReferences] Ignoring unknown Multibanco reference %s %s for org %s", entity, reference, orgID)
+			return nil
+		}
+		return fmt.Errorf("failed to get payment reference: %w", err)
+	}
+	return s.markPaid(ctx, orgID, ref)
+}
+
+// HandleEasypayNotification records a Multibanco payment Easypay reports. The notification is
+// only trusted once the payment reads back as paid from the organization's account.
+func (s *PaymentReferenceService) HandleEasypayNotification(ctx context.Context, orgID uuid.UUID, payload []byte) error {
+	var notification easypayNotification
+	if err := json.Unmarshal(payload, &notification); err != nil {
+		return fmt.Errorf("failed to decode Easypay notification: %w", err)
+	}
+	if notification.Type != "capture" || notification.Status != "success" || notification.ID == "" {
+		return nil
+	}
+
+	ref := &models.PaymentReference{}
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT `+paymentReferenceColumns+` FROM payment_references
+		WHERE organization_id = $1 AND provider = $2 AND provider_reference = $3
+	`, orgID, models.PaymentReferenceProviderEasypay, notification.ID).Scan(paymentReferenceFields(ref)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("[PaymentReferences] Ignoring unknown Easypay payment %s for org %s", notification.ID, orgID)
+			return nil
+		}
+		return fmt.Errorf("failed to get payment reference: %w", err)
+	}
+	if ref.Status == models.PaymentReferenceStatusPaid {
+		return nil
+	}
+
+	config, err := s.GetConfig(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if config == nil {
+		return errors.New("payment references are not configured")
+	}
+	client, err := s.easypay(config)
+	if err != nil {
+		return err
+	}
+	payment, err := client.getPayment(ctx, notification.ID)
+	if err != nil {
+		return err
+	}
+	if payment.PaymentStatus != "paid" {
+		log.Printf("[PaymentReferences] Easypay payment %s notified as captured but is %s", notification.ID, payment.PaymentStatus)
+		return nil
+	}
+	return s.markPaid(ctx, orgID, ref)
+}
+
+// markPaid records a paid reference on its target and on the reference. Providers retry
+// callbacks until they are acknowledged, so the target is updated first and a reference
+// already paid is skipped. Payments of replaced references are recorded all the same.
+func (s *PaymentReferenceService) markPaid(ctx context.Context, orgID uuid.UUID, ref *models.PaymentReference) error {
+	if ref.Status == models.PaymentReferenceStatusPaid {
+		return nil
+	}
+
+	paidAt := time.Now()
+	switch ref.TargetType {
+	case models.PaymentReferenceTargetSessionPayment:
+		var sessionID uuid.UUID
+		if err := s.db.Pool.QueryRow(ctx, `
+			SELECT session_id FROM session_payments WHERE id = $1
+		`, ref.TargetID).Scan(&sessionID); err != nil {
+			return fmt.Errorf("failed to get session payment: %w", err)
+		}
+		method := models.PaymentMethodMultibanco
+		if err := s.sessionPayments.MarkAsPaid(ctx, sessionID, orgID, &method); err != nil {
+			return err
+		}
+
+	case models.PaymentReferenceTargetPayment:
+		method := string(models.PaymentMethodMultibanco)
+		reference := ""
+		if ref.MBEntity != nil && ref.MBReference != nil {
+			reference = *ref.MBEntity + " " + *ref.MBReference
+		}
+		_, err := s.payments.MarkAsPaid(ctx, ref.TargetID, orgID, MarkPaymentPaidRequest{
+			PaidAt:    &paidAt,
+			Method:    &method,
+			Reference: &reference,
+		})
+		// Payments recorded by hand in the meantime are not open anymore
+		if err != nil && !errors.Is(err, ErrPaymentNotOpen) {
+			return err
+		}
+	}
+
+	_, err := s.db.Pool.Exec(ctx, `
+		UPDATE payment_references SET status = 'paid', paid_at = $1 WHERE id = $2 AND status <> 'paid'
+	`, paidAt, ref.ID)
+	if err != nil {
+		return fmt.Errorf("failed to mark payment reference as paid: %w", err)
+	}
+	return nil
+}
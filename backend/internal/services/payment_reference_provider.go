@@ -0,0 +1,243 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const easypayAPIBase = "https://api.prod.easypay.pt/2.0"
+
+var (
+	mbEntityPattern    = regexp.MustCompile(`^[0-9]{5}$`)
+	mbSubentityPattern = regexp.MustCompile(`^[0-9]{1,3}$`)
+)
+
+// multibancoRequest is what a Multibanco reference is generated for
+type multibancoRequest struct {
+	Key           string // our payment reference ID
+	OrderID       int64  // per-organization sequence
+	Description   string
+	Amount        decimal.Decimal
+	ExpiresAt     time.Time
+	CustomerName  string
+	CustomerEmail string
+}
+
+// multibancoResult is a generated Multibanco reference
+type multibancoResult struct {
+	Entity            string
+	Reference         string
+	ProviderReference *string // the provider's payment ID, when it tracks one
+}
+
+// multibancoProvider generates Multibanco references with an organization's provider account
+type multibancoProvider interface {
+	generate(ctx context.Context, req multibancoRequest) (*multibancoResult, error)
+}
+
+// ifthenpayProvider computes references offline from the account's entity and sub-entity
+type ifthenpayProvider struct {
+	entity    string
+	subentity string
+}
+
+func (p *ifthenpayProvider) generate(ctx context.Context, req multibancoRequest) (*multibancoResult, error) {
+	reference, err := multibancoReference(p.entity, p.subentity, req.OrderID, req.Amount)
+	if err != nil {
+		return nil, err
+	}
+	return &multibancoResult{Entity: p.entity, Reference: reference}, nil
+}
+
+// easypayProvider requests references through the Easypay API
+type easypayProvider struct {
+	client *easypayClient
+}
+
+func (p *easypayProvider) generate(ctx context.Context, req multibancoRequest) (*multibancoResult, error) {
+	payment, err := p.client.createMultibanco(ctx, req.Key, req.Description, req.Amount, req.ExpiresAt, req.CustomerName, req.CustomerEmail)
+	if err != nil {
+		return nil, err
+	}
+	return &multibancoResult{
+		Entity:            payment.Method.Entity.String(),
+		Reference:         payment.Method.Reference,
+		ProviderReference: &payment.ID,
+	}, nil
+}
+
+// mbCheckWeights are the weights of the Multibanco check digits, from the last digit backwards
+var mbCheckWeights = [20]int{3, 30, 9, 90, 27, 76, 81, 34, 49, 5, 50, 15, 53, 45, 62, 38, 89, 17, 73, 51}
+
+// multibancoReference computes the 9-digit reference of an IfThenPay entity and sub-entity for
+// an order and amount: the sub-entity, the last four digits of the order and two check digits
+// over the entity, sub-entity, order and amount in cents.
+func multibancoReference(entity, subentity string, orderID int64, amount decimal.Decimal) (string, error) {
+	if !mbEntityPattern.MatchString(entity) {
+		return "", errors.New("invalid Multibanco entity")
+	}
+	if !mbSubentityPattern.MatchString(subentity) {
+		return "", errors.New("invalid Multibanco sub-entity")
+	}
+	cents := amount.Shift(2).Round(0).IntPart()
+	if cents <= 0 || cents > 99999999 {
+		return "", errors.New("amount cannot be paid by Multibanco")
+	}
+
+	sub, _ := strconv.Atoi(subentity)
+	order := orderID % 10000
+	digits := fmt.Sprintf("%s%03d%04d%08d", entity, sub, order, cents)
+
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		sum += int(digits[len(digits)-1-i]-'0') * mbCheckWeights[i]
+	}
+	check := 98 - sum%97
+
+	return fmt.Sprintf("%03d%04d%02d", sub, order, check), nil
+}
+
+// sepaCreditorReference builds an ISO 11649 RF creditor reference around an alphanumeric payload
+// of up to 21 characters, which banks check before accepting a SEPA transfer
+func sepaCreditorReference(payload string) string {
+	payload = strings.ToUpper(payload)
+	var numeric strings.Builder
+	for _, r := range payload + "RF00" {
+		if r >= 'A' && r <= 'Z' {
+			numeric.WriteString(fmt.Sprint(r - 'A' + 10))
+		} else {
+			numeric.WriteRune(r)
+		}
+	}
+	n, _ := new(big.Int).SetString(numeric.String(), 10)
+	mod := new(big.Int).Mod(n, big.NewInt(97)).Int64()
+	return fmt.Sprintf("RF%02d%s", 98-mod, payload)
+}
+
+// easypayClient calls the Easypay API with an organization's account
+type easypayClient struct {
+	apiBase   string
+	accountID string
+	apiKey    string
+	client    *http.Client
+}
+
+// easypayPayment is the part of an Easypay single payment used for Multibanco references
+type easypayPayment struct {
+	ID            string `json:"id"`
+	Status        string `json:"status"`         // ok or error, on creation
+	PaymentStatus string `json:"payment_status"` // pending, paid, ...
+	Method        struct {
+		Type      string      `json:"type"`
+		Status    string      `json:"status"`
+		Entity    json.Number `json:"entity"`
+		Reference string      `json:"reference"`
+	} `json:"method"`
+	Message []string `json:"message"`
+}
+
+// easypayNotification is the generic notification Easypay posts when a payment changes. It is
+// not signed, so the payment is read back from the API before being trusted.
+type easypayNotification struct {
+	ID     string `json:"id"`
+	Key    string `json:"key"`
+	Type   string `json:"type"`   // capture, refund, ...
+	Status string `json:"status"` // success or failed
+}
+
+// createMultibanco requests a Multibanco reference for an amount, identified by our key
+func (c *easypayClient) createMultibanco(ctx context.Context, key, description string, amount decimal.Decimal, expiresAt time.Time, customerName, customerEmail string) (*easypayPayment, error) {
+	body := map[string]interface{}{
+		"type":            "sale",
+		"key":             key,
+		"value":           amount.InexactFloat64(),
+		"currency":        "EUR",
+		"method":          "mb",
+		"expiration_time": expiresAt.Format("2006-01-02 15:04"),
+		"capture":         map[string]string{"descriptive": description},
+	}
+	if customerName != "" || customerEmail != "" {
+		customer := map[string]string{}
+		if customerName != "" {
+			customer["name"] = customerName
+		}
+		if customerEmail != "" {
+			customer["email"] = customerEmail
+		}
+		body["customer"] = customer
+	}
+
+	var payment easypayPayment
+	if err := c.do(ctx, "POST", "/single", body, &payment); err != nil {
+		return nil, err
+	}
+	if payment.Method.Reference == "" || payment.Method.Entity == "" {
+		return nil, errors.New("Easypay did not return a Multibanco reference")
+	}
+	return &payment, nil
+}
+
+// getPayment reads a single payment back from Easypay
+func (c *easypayClient) getPayment(ctx context.Context, id string) (*easypayPayment, error) {
+	var payment easypayPayment
+	if err := c.do(ctx, "GET", "/single/"+url.PathEscape(id), nil, &payment); err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (c *easypayClient) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode Easypay request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.apiBase+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create Easypay request: %w", err)
+	}
+	req.Header.Set("AccountId", c.accountID)
+	req.Header.Set("ApiKey", c.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call Easypay: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read Easypay response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var easypayErr struct {
+			Message []string `json:"message"`
+		}
+		if json.Unmarshal(data, &easypayErr) == nil && len(easypayErr.Message) > 0 {
+			return fmt.Errorf("Easypay returned status %d: %s", resp.StatusCode, strings.Join(easypayErr.Message, "; "))
+		}
+		return fmt.Errorf("Easypay returned status %d", resp.StatusCode)
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode Easypay response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestMultibancoReference(t *testing.T) {
+	tests := []struct {
+		name      string
+		entity    string
+		subentity string
+		orderID   int64
+		amount    string
+		want      string
+		wantErr   bool
+	}{
+		{"pads the sub-entity and order", "11604", "1", 1, "25.50", "001000119", false},
+		{"keeps the last four order digits", "11604", "999", 123456, "100.00", "999345617", false},
+		{"rejects an invalid entity", "1160", "999", 1, "10.00", "", true},
+		{"rejects a zero amount", "11604", "999", 1, "0", "", true},
+		{"rejects an amount over the Multibanco limit", "11604", "999", 1, "1000000.00", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := multibancoReference(tt.entity, tt.subentity, tt.orderID, decimal.RequireFromString(tt.amount))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("multibancoReference() = %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("multibancoReference() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("multibancoReference() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSepaCreditorReference(t *testing.T) {
+	if got := sepaCreditorReference("539007547034"); got != "RF18539007547034" {
+		t.Errorf("sepaCreditorReference() = %q, want RF18539007547034", got)
+	}
+	if got := sepaCreditorReference("0000000001"); got != "RF740000000001" {
+		t.Errorf("sepaCreditorReference() = %q, want RF740000000001", got)
+	}
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/controlwise/backend/internal/phone"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// orgDefaultCountry returns the country numbers without a country code are read with
+func orgDefaultCountry(ctx context.Context, q rowQuerier, orgID uuid.UUID) (string, error) {
+	var country string
+	err := q.QueryRow(ctx, `SELECT default_country FROM organizations WHERE id = $1`, orgID).Scan(&country)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return phone.DefaultCountry, nil
+		}
+		return "", fmt.Errorf("failed to get organization country: %w", err)
+	}
+	return country, nil
+}
+
+// normalizeOrgPhone returns a phone number typed for the organization in E.164
+func normalizeOrgPhone(ctx context.Context, q rowQuerier, orgID uuid.UUID, raw string) (string, error) {
+	country, err := orgDefaultCountry(ctx, q, orgID)
+	if err != nil {
+		return "", err
+	}
+	e164, err := phone.Normalize(raw, country)
+	if err != nil {
+		return "", errors.New("invalid phone number")
+	}
+	return e164, nil
+}
@@ -0,0 +1,361 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// DefaultPriceDriftThreshold is the drift percentage above which an item is flagged
+const DefaultPriceDriftThreshold = 10.0
+
+// priceKeySQL normalizes a description into the key used to match price history
+func priceKeySQL(expr string) string {
+	return fmt.Sprintf(`lower(regexp_replace(trim(%s), '\s+', ' ', 'g'))`, expr)
+}
+
+// PriceSuggestion is the price analysis of a single budget item
+type PriceSuggestion struct {
+	ItemID           uuid.UUID           `json:"item_id"`
+	Description      string              `json:"description"`
+	Unit             string              `json:"unit"`
+	CurrentPrice     decimal.Decimal     `json:"current_price"`
+	ReferenceDate    time.Time           `json:"reference_date"` // when the current price was last recorded
+	LatestPrice      *decimal.Decimal    `json:"latest_price,omitempty"`
+	LatestSource     *models.PriceSource `json:"latest_source,omitempty"`
+	LatestRecordedAt *time.Time          `json:"latest_recorded_at,omitempty"`
+	IndexFactor      decimal.Decimal     `json:"index_factor"` // cost index adjustment applied to the suggestion
+	SuggestedPrice   decimal.Decimal     `json:"suggested_price"`
+	DriftPercent     float64             `json:"drift_percent"`
+	Flagged          bool                `json:"flagged"`
+}
+
+// PriceAnalysis compares the prices of a budget against the price history
+type PriceAnalysis struct {
+	BudgetID     uuid.UUID         `json:"budget_id"`
+	Threshold    float64           `json:"threshold"`
+	FlaggedCount int               `json:"flagged_count"`
+	Items        []PriceSuggestion `json:"items"`
+}
+
+type priceObservation struct {
+	price      decimal.Decimal
+	source     models.PriceSource
+	recordedAt time.Time
+}
+
+// ============ Price History ============
+
+// recordBudgetPrices stores the item prices of a budget being sent. Earlier entries of the
+// same budget are replaced, so a budget re-sent after changes keeps a single observation.
+func recordBudgetPrices(ctx context.Context, tx pgx.Tx, orgID, budgetID uuid.UUID) error {
+	_, err := tx.Exec(ctx, `DELETE FROM price_history WHERE budget_id = $1 AND source = $2`, budgetID, models.PriceSourceBudget)
+	if err != nil {
+		return fmt.Errorf("failed to clear price history: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO price_history (organization_id, item_key, description, unit, unit_price, source, budget_id)
+		SELECT $1, `+priceKeySQL("description")+`, description, unit, unit_price, $2, budget_id
+		FROM budget_items
+		WHERE budget_id = $3 AND deleted_at IS NULL
+	`, orgID, models.PriceSourceBudget, budgetID)
+	if err != nil {
+		return fmt.Errorf("failed to record price history: %w", err)
+	}
+	return nil
+}
+
+// recordCataloguePrice stores the price of a catalogue entry written by an import
+func recordCataloguePrice(ctx context.Context, tx pgx.Tx, orgID, materialID uuid.UUID, description, unit string, price decimal.Decimal) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO price_history (organization_id, item_key, description, unit, unit_price, source, material_id)
+		VALUES ($1, `+priceKeySQL("$2::text")+`, $2, $3, $4, $5, $6)
+	`, orgID, description, unit, price, models.PriceSourceCatalogue, materialID)
+	if err != nil {
+		return fmt.Errorf("failed to record price history: %w", err)
+	}
+	return nil
+}
+
+// ============ Cost Indexes ============
+
+// ListCostIndex returns the cost index values of an organization by period
+func (s *MaterialService) ListCostIndex(ctx context.Context, orgID uuid.UUID) ([]*models.CostIndexValue, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, organization_id, period, value, created_at, updated_at
+		FROM cost_index_values
+		WHERE organization_id = $1
+		ORDER BY period
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cost index: %w", err)
+	}
+	defer rows.Close()
+
+	var values []*models.CostIndexValue
+	for rows.Next() {
+		var v models.CostIndexValue
+		if err := rows.Scan(&v.ID, &v.OrganizationID, &v.Period, &v.Value, &v.CreatedAt, &v.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan cost index value: %w", err)
+		}
+		values = append(values, &v)
+	}
+
+	return values, nil
+}
+
+// SetCostIndex creates or updates the cost index value of a month
+func (s *MaterialService) SetCostIndex(ctx context.Context, orgID uuid.UUID, period time.Time, value decimal.Decimal) (*models.CostIndexValue, error) {
+	if !value.IsPositive() {
+		return nil, errors.New("index value must be positive")
+	}
+	period = time.Date(period.Year(), period.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	v := &models.CostIndexValue{OrganizationID: orgID, Period: period, Value: value}
+	err := s.db.Pool.QueryRow(ctx, `
+		INSERT INTO cost_index_values (organization_id, period, value)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (organization_id, period) DO UPDATE SET value = EXCLUDED.value
+		RETURNING id, created_at, updated_at
+	`, orgID, period, value).Scan(&v.ID, &v.CreatedAt, &v.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set cost index value: %w", err)
+	}
+
+	return v, nil
+}
+
+// DeleteCostIndex removes the cost index value of a month
+func (s *MaterialService) DeleteCostIndex(ctx context.Context, orgID uuid.UUID, period time.Time) error {
+	period = time.Date(period.Year(), period.Month(), 1, 0, 0, 0, 0, time.UTC)
+	result, err := s.db.Pool.Exec(ctx, `
+		DELETE FROM cost_index_values WHERE organization_id = $1 AND period = $2
+	`, orgID, period)
+	if err != nil {
+		return fmt.Errorf("failed to delete cost index value: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("cost index value not found")
+	}
+	return nil
+}
+
+// ============ Price Analysis ============
+
+// AnalyzeBudgetPrices compares each item of a budget with the latest recorded price for
+// the same description and unit. The suggestion is the most recent price (or the current
+// one if nothing newer exists) adjusted by the cost index variation since it was recorded;
+// items drifting at least threshold percent are flagged.
+func (s *MaterialService) AnalyzeBudgetPrices(ctx context.Context, budgetID, orgID uuid.UUID, threshold float64) (*PriceAnalysis, error) {
+	if threshold <= 0 {
+		threshold = DefaultPriceDriftThreshold
+	}
+
+	var budgetCreatedAt time.Time
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT created_at FROM budgets
+		WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`, budgetID, orgID).Scan(&budgetCreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("budget not found")
+		}
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+
+	history, err := s.priceHistoryForBudget(ctx, budgetID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	index, err := s.ListCostIndex(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, description, unit, unit_price, `+priceKeySQL("description")+`
+		FROM budget_items
+		WHERE budget_id = $1 AND deleted_at IS NULL
+		ORDER BY "order"
+	`, budgetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query budget items: %w", err)
+	}
+	defer rows.Close()
+
+	analysis := &PriceAnalysis{BudgetID: budgetID, Threshold: threshold, Items: []PriceSuggestion{}}
+	now := time.Now()
+	for rows.Next() {
+		var item PriceSuggestion
+		var key string
+		if err := rows.Scan(&item.ItemID, &item.Description, &item.Unit, &item.CurrentPrice, &key); err != nil {
+			return nil, fmt.Errorf("failed to scan budget item: %w", err)
+		}
+		observations := history[key+"\x00"+item.Unit]
+
+		// Date the current price comes from: its last observation, else the budget creation
+		item.ReferenceDate = budgetCreatedAt
+		for _, o := range observations {
+			if o.price.Equal(item.CurrentPrice) {
+				item.ReferenceDate = o.recordedAt
+				break
+			}
+		}
+
+		base, baseDate := item.CurrentPrice, item.ReferenceDate
+		if len(observations) > 0 {
+			latest := observations[0]
+			item.LatestPrice = &latest.price
+			item.LatestSource = &latest.source
+			item.LatestRecordedAt = &latest.recordedAt
+			if latest.recordedAt.After(item.ReferenceDate) {
+				base, baseDate = latest.price, latest.recordedAt
+			}
+		}
+
+		item.IndexFactor = costIndexFactor(index, baseDate, now)
+		item.SuggestedPrice = base.Mul(item.IndexFactor).Round(2)
+
+		if item.CurrentPrice.IsZero() {
+			item.Flagged = item.SuggestedPrice.IsPositive()
+		} else {
+			drift, _ := item.SuggestedPrice.Sub(item.CurrentPrice).Div(item.CurrentPrice).Mul(decimal.NewFromInt(100)).Round(2).Float64()
+			item.DriftPercent = drift
+			item.Flagged = drift >= threshold || drift <= -threshold
+		}
+		if item.Flagged {
+			analysis.FlaggedCount++
+		}
+
+		analysis.Items = append(analysis.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read budget items: %w", err)
+	}
+
+	return analysis, nil
+}
+
+// ApplyPriceSuggestions updates flagged items of a draft budget to their suggested price.
+// When itemIDs is empty every flagged item is updated. Item tax keeps its current rate.
+func (s *MaterialService) ApplyPriceSuggestions(ctx context.Context, budgetID, orgID uuid.UUID, itemIDs []uuid.UUID, threshold float64) (int, error) {
+	analysis, err := s.AnalyzeBudgetPrices(ctx, budgetID, orgID, threshold)
+	if err != nil {
+		return 0, err
+	}
+
+	selected := make(map[uuid.UUID]bool, len(itemIDs))
+	for _, id := range itemIDs {
+		selected[id] = true
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := s.checkDraftBudget(ctx, tx, budgetID, orgID, true); err != nil {
+		return 0, errors.New("prices can only be updated on draft budgets")
+	}
+
+	updated := 0
+	for _, item := range analysis.Items {
+		if !item.Flagged || (len(selected) > 0 && !selected[item.ItemID]) {
+			continue
+		}
+
+		_, err := tx.Exec(ctx, `
+			UPDATE budget_items
+			SET unit_price = $1::numeric,
+				tax = CASE WHEN quantity * unit_price = 0 THEN 0
+					ELSE ROUND(quantity * $1::numeric * tax / (quantity * unit_price), 2) END,
+				total = ROUND(quantity * $1::numeric, 2) + CASE WHEN quantity * unit_price = 0 THEN 0
+					ELSE ROUND(quantity * $1::numeric * tax / (quantity * unit_price), 2) END,
+				updated_at = CURRENT_TIMESTAMP
+			WHERE id = $2 AND budget_id = $3
+		`, item.SuggestedPrice, item.ItemID, budgetID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to update budget item: %w", err)
+		}
+		updated++
+	}
+
+	if updated > 0 {
+		if err := recalculateBudgetTotals(ctx, tx, budgetID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return updated, nil
+}
+
+// priceHistoryForBudget returns the price observations matching the items of a budget,
+// keyed by item key and unit, newest first. Observations from the budget itself are excluded.
+func (s *MaterialService) priceHistoryForBudget(ctx context.Context, budgetID, orgID uuid.UUID) (map[string][]priceObservation, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT ph.item_key, ph.unit, ph.unit_price, ph.source, ph.recorded_at
+		FROM price_history ph
+		WHERE ph.organization_id = $1
+		AND (ph.budget_id IS NULL OR ph.budget_id <> $2)
+		AND (ph.item_key, ph.unit) IN (
+			SELECT `+priceKeySQL("description")+`, unit FROM budget_items
+			WHERE budget_id = $2 AND deleted_at IS NULL
+		)
+		ORDER BY ph.recorded_at DESC
+	`, orgID, budgetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price history: %w", err)
+	}
+	defer rows.Close()
+
+	history := make(map[string][]priceObservation)
+	for rows.Next() {
+		var key, unit string
+		var o priceObservation
+		if err := rows.Scan(&key, &unit, &o.price, &o.source, &o.recordedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan price history: %w", err)
+		}
+		history[key+"\x00"+unit] = append(history[key+"\x00"+unit], o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read price history: %w", err)
+	}
+
+	return history, nil
+}
+
+// costIndexFactor returns index(to) / index(from) using the latest value published on or
+// before each month, or 1 when either month has no index value
+func costIndexFactor(index []*models.CostIndexValue, from, to time.Time) decimal.Decimal {
+	valueAt := func(t time.Time) (decimal.Decimal, bool) {
+		month := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		i := sort.Search(len(index), func(i int) bool { return index[i].Period.After(month) })
+		if i == 0 {
+			return decimal.Zero, false
+		}
+		return index[i-1].Value, true
+	}
+
+	fromValue, ok := valueAt(from)
+	if !ok {
+		return decimal.NewFromInt(1)
+	}
+	toValue, ok := valueAt(to)
+	if !ok {
+		return decimal.NewFromInt(1)
+	}
+	return toValue.Div(fromValue).Round(4)
+}
@@ -0,0 +1,443 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+// maxPriceListRows bounds the number of data rows read from a price list
+const maxPriceListRows = 5000
+
+// priceListHeaderAliases are the headers recognised when a column is not mapped explicitly
+var priceListHeaderAliases = map[string][]string{
+	"code":       {"code", "código", "codigo", "ref", "referência", "referencia", "sku"},
+	"name":       {"name", "description", "descrição", "descricao", "designação", "designacao", "artigo", "produto"},
+	"unit":       {"unit", "unidade", "un", "und"},
+	"unit_price": {"unit_price", "price", "preço", "preco", "preço unitário", "preco unitario", "pvp"},
+	"quantity":   {"quantity", "quantidade", "qtd", "qty"},
+}
+
+// readPriceListTable reads the rows of a CSV or XLSX file, header row included
+func readPriceListTable(fileName string, data []byte, sheet string) (string, [][]string, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv", ".txt":
+		rows, err := readCSVTable(data)
+		return "csv", rows, err
+	case ".xlsx":
+		rows, err := readXLSXTable(data, sheet)
+		return "xlsx", rows, err
+	default:
+		return "", nil, errors.New("unsupported file format, use CSV or XLSX")
+	}
+}
+
+// readCSVTable reads a CSV file, detecting ';' (common in Portuguese exports) or ',' as delimiter
+func readCSVTable(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	firstLine := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		firstLine = data[:i]
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	return rows, nil
+}
+
+// xlsxWorkbook is the subset of xl/workbook.xml needed to locate sheets
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxRichText is a shared or inline string, either plain or split into runs
+type xlsxRichText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxRichText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var sb strings.Builder
+	for _, r := range t.Runs {
+		sb.WriteString(r.T)
+	}
+	return sb.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxRichText `xml:"si"`
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string        `xml:"r,attr"`
+			Type   string        `xml:"t,attr"`
+			Value  string        `xml:"v"`
+			Inline *xlsxRichText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readXLSXTable reads the cell values of a worksheet. Only the stored values are read:
+// formulas are not evaluated and number formats are ignored.
+func readXLSXTable(data []byte, sheetName string) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, errors.New("invalid XLSX file")
+	}
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	var workbook xlsxWorkbook
+	if err := decodeXLSXPart(files, "xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	var rels xlsxRelationships
+	if err := decodeXLSXPart(files, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+	if len(workbook.Sheets) == 0 {
+		return nil, errors.New("XLSX file has no sheets")
+	}
+
+	rID := workbook.Sheets[0].RID
+	if sheetName != "" {
+		rID = ""
+		for _, sheet := range workbook.Sheets {
+			if strings.EqualFold(sheet.Name, sheetName) {
+				rID = sheet.RID
+				break
+			}
+		}
+		if rID == "" {
+			return nil, fmt.Errorf("sheet not found: %s", sheetName)
+		}
+	}
+
+	sheetPath := ""
+	for _, rel := range rels.Relationships {
+		if rel.ID == rID {
+			sheetPath = rel.Target
+			break
+		}
+	}
+	if sheetPath == "" {
+		return nil, errors.New("invalid XLSX file: sheet part not found")
+	}
+	if strings.HasPrefix(sheetPath, "/") {
+		sheetPath = strings.TrimPrefix(sheetPath, "/")
+	} else {
+		sheetPath = path.Join("xl", sheetPath)
+	}
+
+	var shared xlsxSharedStrings
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeXLSXPart(files, "xl/sharedStrings.xml", &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	var sheet xlsxSheet
+	if err := decodeXLSXPart(files, sheetPath, &sheet); err != nil {
+		return nil, err
+	}
+
+	table := make([][]string, 0, len(sheet.Rows))
+	for _, row := range sheet.Rows {
+		var values []string
+		for i, cell := range row.Cells {
+			col := i
+			if cell.Ref != "" {
+				if c, ok := columnIndex(strings.TrimRight(cell.Ref, "0123456789")); ok {
+					col = c
+				}
+			}
+			for len(values) <= col {
+				values = append(values, "")
+			}
+
+			switch cell.Type {
+			case "s":
+				idx, err := strconv.Atoi(cell.Value)
+				if err == nil && idx >= 0 && idx < len(shared.Items) {
+					values[col] = shared.Items[idx].String()
+				}
+			case "inlineStr":
+				if cell.Inline != nil {
+					values[col] = cell.Inline.String()
+				}
+			default:
+				values[col] = cell.Value
+			}
+		}
+		table = append(table, values)
+	}
+
+	return table, nil
+}
+
+// decodeXLSXPart unmarshals an XML part of an XLSX archive
+func decodeXLSXPart(files map[string]*zip.File, name string, v interface{}) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("invalid XLSX file: missing %s", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer rc.Close()
+
+	if err := xml.NewDecoder(io.LimitReader(rc, 64<<20)).Decode(v); err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return nil
+}
+
+// columnIndex converts a column letter reference ("A", "AB") to a zero-based index
+func columnIndex(ref string) (int, bool) {
+	if ref == "" || len(ref) > 3 {
+		return 0, false
+	}
+	idx := 0
+	for _, r := range strings.ToUpper(ref) {
+		if r < 'A' || r > 'Z' {
+			return 0, false
+		}
+		idx = idx*26 + int(r-'A'+1)
+	}
+	return idx - 1, true
+}
+
+// resolvePriceListColumns returns the column index of each field, -1 when absent.
+// Mapped columns match a header (case-insensitive) or a column letter; unmapped
+// fields are detected from common header names.
+func resolvePriceListColumns(header []string, mapping models.PriceListColumnMapping) (map[string]int, error) {
+	normalized := make([]string, len(header))
+	for i, h := range header {
+		normalized[i] = strings.ToLower(strings.TrimSpace(h))
+	}
+
+	mapped := map[string]string{
+		"code":       mapping.Code,
+		"name":       mapping.Name,
+		"unit":       mapping.Unit,
+		"unit_price": mapping.UnitPrice,
+		"quantity":   mapping.Quantity,
+	}
+
+	columns := make(map[string]int, len(mapped))
+	for field, column := range mapped {
+		columns[field] = -1
+		column = strings.ToLower(strings.TrimSpace(column))
+
+		if column == "" {
+			for _, alias := range priceListHeaderAliases[field] {
+				if i := indexOf(normalized, alias); i >= 0 {
+					columns[field] = i
+					break
+				}
+			}
+			continue
+		}
+
+		if i := indexOf(normalized, column); i >= 0 {
+			columns[field] = i
+		} else if i, ok := columnIndex(column); ok {
+			columns[field] = i
+		} else {
+			return nil, fmt.Errorf("column not found for %s: %s", field, column)
+		}
+	}
+
+	if columns["name"] < 0 {
+		return nil, errors.New("could not find the name/description column, map it explicitly")
+	}
+	if columns["unit_price"] < 0 {
+		return nil, errors.New("could not find the price column, map it explicitly")
+	}
+
+	return columns, nil
+}
+
+// parsePriceListRows maps the data rows of a table, applying unit conversions.
+// Rows with problems are kept with their errors so they can be shown in the preview.
+func parsePriceListRows(table [][]string, opts models.PriceListImportOptions, target models.PriceListImportTarget) ([]models.PriceListImportRow, error) {
+	if len(table) < 2 {
+		return nil, errors.New("the file has no data rows")
+	}
+	if len(table)-1 > maxPriceListRows {
+		return nil, fmt.Errorf("the file has more than %d rows", maxPriceListRows)
+	}
+
+	columns, err := resolvePriceListColumns(table[0], opts.Columns)
+	if err != nil {
+		return nil, err
+	}
+
+	conversions := make(map[string]models.UnitConversion, len(opts.UnitConversions))
+	for _, c := range opts.UnitConversions {
+		if !c.Factor.IsPositive() {
+			return nil, fmt.Errorf("unit conversion factor for %s must be positive", c.From)
+		}
+		conversions[normalizeUnit(c.From)] = c
+	}
+
+	cell := func(values []string, field string) string {
+		i := columns[field]
+		if i < 0 || i >= len(values) {
+			return ""
+		}
+		return strings.TrimSpace(values[i])
+	}
+
+	rows := make([]models.PriceListImportRow, 0, len(table)-1)
+	seenCodes := make(map[string]int)
+	for i, values := range table[1:] {
+		if isBlankRow(values) {
+			continue
+		}
+
+		row := models.PriceListImportRow{
+			Line:     i + 2,
+			Code:     cell(values, "code"),
+			Name:     cell(values, "name"),
+			Unit:     normalizeUnit(cell(values, "unit")),
+			Quantity: decimal.NewFromInt(1),
+		}
+		if row.Unit == "" {
+			row.Unit = "un"
+		}
+		if row.Name == "" {
+			row.Errors = append(row.Errors, "missing name")
+		}
+
+		price, err := parseDecimalCell(cell(values, "unit_price"))
+		if err != nil {
+			row.Errors = append(row.Errors, "invalid price")
+		} else if price.IsNegative() {
+			row.Errors = append(row.Errors, "price cannot be negative")
+		}
+		row.UnitPrice = price
+
+		if target == models.PriceListTargetBudget {
+			if raw := cell(values, "quantity"); raw != "" {
+				qty, err := parseDecimalCell(raw)
+				if err != nil || !qty.IsPositive() {
+					row.Errors = append(row.Errors, "invalid quantity")
+				} else {
+					row.Quantity = qty
+				}
+			}
+		} else {
+			if row.Code == "" {
+				row.Errors = append(row.Errors, "missing code")
+			} else if line, ok := seenCodes[row.Code]; ok {
+				row.Errors = append(row.Errors, fmt.Sprintf("duplicate code (line %d)", line))
+			} else {
+				seenCodes[row.Code] = row.Line
+			}
+		}
+
+		if c, ok := conversions[row.Unit]; ok {
+			row.OriginalUnit = row.Unit
+			row.Unit = normalizeUnit(c.To)
+			row.UnitPrice = row.UnitPrice.Div(c.Factor)
+			row.Quantity = row.Quantity.Mul(c.Factor)
+		}
+		row.UnitPrice = row.UnitPrice.Round(4)
+
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.New("the file has no data rows")
+	}
+	return rows, nil
+}
+
+// parseDecimalCell parses a price or quantity, accepting both "1.234,56" and "1,234.56"
+// notations and ignoring currency symbols
+func parseDecimalCell(raw string) (decimal.Decimal, error) {
+	s := strings.Map(func(r rune) rune {
+		if (r >= '0' && r <= '9') || r == '.' || r == ',' || r == '-' {
+			return r
+		}
+		return -1
+	}, raw)
+
+	lastDot := strings.LastIndex(s, ".")
+	lastComma := strings.LastIndex(s, ",")
+	switch {
+	case lastComma > lastDot:
+		// Comma is the decimal separator
+		s = strings.ReplaceAll(s, ".", "")
+		s = strings.Replace(s, ",", ".", 1)
+	case lastDot > lastComma && lastComma >= 0:
+		s = strings.ReplaceAll(s, ",", "")
+	}
+
+	return decimal.NewFromString(s)
+}
+
+// normalizeUnit lowercases and trims a unit, dropping a trailing dot ("Un." -> "un")
+func normalizeUnit(unit string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(unit)), ".")
+}
+
+func isBlankRow(values []string) bool {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func indexOf(values []string, target string) int {
+	for i, v := range values {
+		if v == target {
+			return i
+		}
+	}
+	return -1
+}
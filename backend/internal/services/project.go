@@ -0,0 +1,499 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ProjectService handles project operations
+type ProjectService struct {
+	db           *database.DB
+	storage      *StorageService
+	notification *NotificationService
+	workflow     *WorkflowService
+	compliance   *ComplianceService
+}
+
+func NewProjectService(db *database.DB, storage *StorageService, notification *NotificationService) *ProjectService {
+	return &ProjectService{
+		db:           db,
+		storage:      storage,
+		notification: notification,
+	}
+}
+
+// SetWorkflowService sets the workflow service for triggering workflow actions
+func (s *ProjectService) SetWorkflowService(ws *WorkflowService) {
+	s.workflow = ws
+}
+
+// SetComplianceService sets the compliance service used to gate project completion
+func (s *ProjectService) SetComplianceService(cs *ComplianceService) {
+	s.compliance = cs
+}
+
+// ProjectFilters contains filters for listing projects
+type ProjectFilters struct {
+	Status   string
+	ClientID *uuid.UUID
+	Search   string
+	Limit    int
+	Offset   int
+}
+
+type CreateProjectRequest struct {
+	BudgetID        uuid.UUID  `json:"budget_id"`
+	Title           string     `json:"title"`
+	Description     *string    `json:"description"`
+	Category        *string    `json:"category"`
+	StartDate       *time.Time `json:"start_date"`        // defaults to today
+	ExpectedEndDate time.Time  `json:"expected_end_date"` // required
+	NumberPrefix    string     `json:"number_prefix"`     // project numbers are <prefix>-<year>-<seq>, defaults to PRJ
+	ItemsAs         string     `json:"items_as"`          // copy the budget's items as tasks, milestones or none (default)
+}
+
+type UpdateProjectRequest struct {
+	Title           string    `json:"title"`
+	Description     *string   `json:"description"`
+	Category        *string   `json:"category"`
+	StartDate       time.Time `json:"start_date"`
+	ExpectedEndDate time.Time `json:"expected_end_date"`
+}
+
+const projectDetailsQuery = `
+	SELECT
+		p.id, p.organization_id, p.budget_id, p.project_number, p.title, p.description, p.category,
+		p.status, p.progress, p.progress_mode, p.computed_progress, p.progress_override,
+		p.progress_override_by, p.progress_override_at, p.start_date, p.expected_end_date, p.actual_end_date, p.template_id,
+		p.created_by, p.created_at, p.updated_at,
+		b.budget_number, w.client_id, COALESCE(c.name, ''), b.total,
+		COALESCE(fin.billed, 0), COALESCE(fin.paid, 0)
+	FROM projects p
+	JOIN budgets b ON b.id = p.budget_id
+	LEFT JOIN worksheets w ON w.id = b.worksheet_id
+	LEFT JOIN clients c ON c.id = w.client_id
+	LEFT JOIN LATERAL (
+		SELECT
+			SUM(amount) FILTER (WHERE status <> 'cancelled') AS billed,
+			SUM(amount) FILTER (WHERE status = 'paid') AS paid
+		FROM payments
+		WHERE project_id = p.id AND deleted_at IS NULL
+	) fin ON true
+`
+
+func scanProjectWithDetails(row pgx.Row) (*models.ProjectWithDetails, error) {
+	var p models.ProjectWithDetails
+	err := row.Scan(
+		&p.ID, &p.OrganizationID, &p.BudgetID, &p.ProjectNumber, &p.Title, &p.Description, &p.Category,
+		&p.Status, &p.Progress, &p.ProgressMode, &p.ComputedProgress, &p.ProgressOverride,
+		&p.ProgressOverrideBy, &p.ProgressOverrideAt, &p.StartDate, &p.ExpectedEndDate, &p.ActualEndDate, &p.TemplateID,
+		&p.CreatedBy, &p.CreatedAt, &p.UpdatedAt,
+		&p.BudgetNumber, &p.ClientID, &p.ClientName, &p.BudgetTotal,
+		&p.TotalBilled, &p.TotalPaid,
+	)
+	if err != nil {
+		return nil, err
+	}
+	p.Outstanding = p.TotalBilled.Sub(p.TotalPaid)
+	return &p, nil
+}
+
+// List returns the organization's projects matching the filters, most recently started first
+func (s *ProjectService) List(ctx context.Context, orgID uuid.UUID, filters ProjectFilters) ([]*models.ProjectWithDetails, int, error) {
+	where := "WHERE p.organization_id = $1 AND p.deleted_at IS NULL"
+	args := []interface{}{orgID}
+
+	if filters.Status != "" {
+		args = append(args, filters.Status)
+		where += fmt.Sprintf(" AND p.status = $%d", len(args))
+	}
+	if filters.ClientID != nil {
+		args = append(args, *filters.ClientID)
+		where += fmt.Sprintf(" AND w.client_id = $%d", len(args))
+	}
+	if search := strings.TrimSpace(filters.Search); search != "" {
+		args = append(args, "%"+search+"%")
+		where += fmt.Sprintf(" AND (p.title ILIKE $%d OR p.project_number ILIKE $%d OR c.name ILIKE $%d)", len(args), len(args), len(args))
+	}
+
+	var total int
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM projects p
+		JOIN budgets b ON b.id = p.budget_id
+		LEFT JOIN worksheets w ON w.id = b.worksheet_id
+		LEFT JOIN clients c ON c.id = w.client_id
+		`+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count projects: %w", err)
+	}
+
+	if filters.Limit <= 0 {
+		filters.Limit = 50
+	}
+	args = append(args, filters.Limit, filters.Offset)
+	query := projectDetailsQuery + where + fmt.Sprintf(`
+		ORDER BY p.start_date DESC, p.created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, len(args)-1, len(args))
+
+	rows, err := s.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query projects: %w", err)
+	}
+	defer rows.Close()
+
+	projects := []*models.ProjectWithDetails{}
+	for rows.Next() {
+		p, err := scanProjectWithDetails(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan project: %w", err)
+		}
+		projects = append(projects, p)
+	}
+
+	return projects, total, nil
+}
+
+// GetByID returns a project of the organization
+func (s *ProjectService) GetByID(ctx context.Context, id, orgID uuid.UUID) (*models.ProjectWithDetails, error) {
+	p, err := scanProjectWithDetails(s.db.Pool.QueryRow(ctx, projectDetailsQuery+`
+		WHERE p.id = $1 AND p.organization_id = $2 AND p.deleted_at IS NULL
+	`, id, orgID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("project not found")
+		}
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	return p, nil
+}
+
+// ErrBudgetHasProject is returned when a project is created from a budget that already backs one
+var ErrBudgetHasProject = errors.New("budget already has a project")
+
+// budgetForProject is the approved budget a project is being created from
+type budgetForProject struct {
+	worksheetTitle string
+	budgetNumber   string
+	createdBy      uuid.UUID
+}
+
+// lockBudgetForProject locks the row of the budget a project is being created from, so concurrent
+// creations from the same budget run one after the other, and checks that the budget is approved
+// and doesn't back a project yet
+func lockBudgetForProject(ctx context.Context, tx pgx.Tx, orgID, budgetID uuid.UUID) (*budgetForProject, error) {
+	var budgetStatus models.BudgetStatus
+	budget := &budgetForProject{}
+	err := tx.QueryRow(ctx, `
+		SELECT b.status, COALESCE(w.title, ''), b.budget_number, b.created_by
+		FROM budgets b
+		LEFT JOIN worksheets w ON w.id = b.worksheet_id
+		WHERE b.id = $1 AND b.organization_id = $2 AND b.deleted_at IS NULL
+		FOR UPDATE OF b
+	`, budgetID, orgID).Scan(&budgetStatus, &budget.worksheetTitle, &budget.budgetNumber, &budget.createdBy)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("budget not found")
+		}
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+	if budgetStatus != models.BudgetStatusApproved {
+		return nil, errors.New("budget must be approved before creating a project")
+	}
+
+	var hasProject bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM projects WHERE budget_id = $1 AND deleted_at IS NULL)
+	`, budgetID).Scan(&hasProject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing project: %w", err)
+	}
+	if hasProject {
+		return nil, ErrBudgetHasProject
+	}
+	return budget, nil
+}
+
+// CreateFromBudget starts a project for an approved budget. A budget can back only one project.
+// Without a title the budget's worksheet title is used, falling back to the budget number, and the
+// budget's items are copied into tasks or milestones when ItemsAs asks for it.
+func (s *ProjectService) CreateFromBudget(ctx context.Context, orgID, userID uuid.UUID, req CreateProjectRequest) (*models.ProjectWithDetails, error) {
+	itemsAs := req.ItemsAs
+	if itemsAs == "" {
+		itemsAs = BudgetItemsAsNone
+	}
+	if itemsAs != BudgetItemsAsTasks && itemsAs != BudgetItemsAsMilestones && itemsAs != BudgetItemsAsNone {
+		return nil, fmt.Errorf("invalid items_as: %q", itemsAs)
+	}
+	prefix := req.NumberPrefix
+	if prefix == "" {
+		prefix = "PRJ"
+	}
+
+	startDate := truncateToDate(time.Now())
+	if req.StartDate != nil && !req.StartDate.IsZero() {
+		startDate = truncateToDate(*req.StartDate)
+	}
+	if req.ExpectedEndDate.IsZero() {
+		return nil, errors.New("project expected end date is required")
+	}
+	endDate := truncateToDate(req.ExpectedEndDate)
+	if endDate.Before(startDate) {
+		return nil, errors.New("expected end date cannot be before the start date")
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	budget, err := lockBudgetForProject(ctx, tx, orgID, req.BudgetID)
+	if err != nil {
+		return nil, err
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = budget.worksheetTitle
+	}
+	if title == "" {
+		title = budget.budgetNumber
+	}
+
+	projectNumber, err := nextPrefixedProjectNumber(ctx, tx, orgID, prefix, startDate.Year())
+	if err != nil {
+		return nil, err
+	}
+
+	id := uuid.New()
+	_, err = tx.Exec(ctx, `
+		INSERT INTO projects (
+			id, organization_id, budget_id, project_number, title, description, category, status,
+			progress, start_date, expected_end_date, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 0, $9, $10, $11)
+	`, id, orgID, req.BudgetID, projectNumber, title, req.Description, req.Category,
+		models.ProjectStatusInProgress, startDate, endDate, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create project: %w", err)
+	}
+	// Payments made through the budget's links before the project existed
+	if err := recordBudgetLinkPayments(ctx, tx, orgID, req.BudgetID, id, budget.createdBy); err != nil {
+		return nil, err
+	}
+
+	if itemsAs != BudgetItemsAsNone {
+		if err := copyBudgetItems(ctx, tx, id, req.BudgetID, itemsAs, startDate, endDate, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// Build the compliance checklist for the project's category
+	if s.compliance != nil {
+		if _, err := s.compliance.ApplyToProject(ctx, orgID, id); err != nil {
+			fmt.Printf("Failed to apply compliance requirements: %v\n", err)
+		}
+	}
+
+	// Trigger workflow (non-blocking)
+	if s.workflow != nil {
+		if err := s.workflow.OnProjectStateChange(ctx, orgID, id, "", string(models.ProjectStatusInProgress)); err != nil {
+			fmt.Printf("Failed to trigger workflow: %v\n", err)
+		}
+	}
+
+	return s.GetByID(ctx, id, orgID)
+}
+
+// Update changes a project's details and schedule
+func (s *ProjectService) Update(ctx context.Context, id, orgID uuid.UUID, req UpdateProjectRequest) (*models.ProjectWithDetails, error) {
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return nil, errors.New("project title is required")
+	}
+	if req.StartDate.IsZero() || req.ExpectedEndDate.IsZero() {
+		return nil, errors.New("project start and expected end dates are required")
+	}
+	if req.ExpectedEndDate.Before(req.StartDate) {
+		return nil, errors.New("expected end date cannot be before the start date")
+	}
+
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE projects
+		SET title = $1, description = $2, category = $3, start_date = $4, expected_end_date = $5,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $6 AND organization_id = $7 AND deleted_at IS NULL
+	`, title, req.Description, req.Category, truncateToDate(req.StartDate), truncateToDate(req.ExpectedEndDate), id, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update project: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, errors.New("project not found")
+	}
+
+	return s.GetByID(ctx, id, orgID)
+}
+
+// UpdateProgress sets the completion percentage (0-100) of an active project. On a project whose
+// progress is computed from its tasks it is recorded as an override, with who set it and when.
+func (s *ProjectService) UpdateProgress(ctx context.Context, id, orgID, userID uuid.UUID, progress int) (*models.ProjectWithDetails, error) {
+	if progress < 0 || progress > 100 {
+		return nil, errors.New("progress must be between 0 and 100")
+	}
+
+	mode, err := s.activeProjectProgressMode(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	if mode == models.ProjectProgressTasks {
+		_, err = s.db.Pool.Exec(ctx, `
+			UPDATE projects
+			SET progress = $1, progress_override = $1, progress_override_by = $2, progress_override_at = NOW(),
+				updated_at = CURRENT_TIMESTAMP
+			WHERE id = $3 AND organization_id = $4
+		`, progress, userID, id, orgID)
+	} else {
+		_, err = s.db.Pool.Exec(ctx, `
+			UPDATE projects SET progress = $1, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $2 AND organization_id = $3
+		`, progress, id, orgID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update project progress: %w", err)
+	}
+
+	return s.GetByID(ctx, id, orgID)
+}
+
+// Delete soft-deletes a project. Projects with paid payments are kept for the financial history.
+func (s *ProjectService) Delete(ctx context.Context, id, orgID uuid.UUID) error {
+	var hasPaid bool
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM payments WHERE project_id = $1 AND status = 'paid' AND deleted_at IS NULL)
+	`, id).Scan(&hasPaid)
+	if err != nil {
+		return fmt.Errorf("failed to check project payments: %w", err)
+	}
+	if hasPaid {
+		return errors.New("cannot delete a project with paid payments")
+	}
+
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE projects SET deleted_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to delete project: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("project not found")
+	}
+
+	// Open payments and scheduled workflow jobs no longer apply
+	_, err = s.db.Pool.Exec(ctx, `
+		UPDATE payments SET status = 'cancelled', updated_at = CURRENT_TIMESTAMP
+		WHERE project_id = $1 AND status IN ('pending', 'overdue') AND deleted_at IS NULL
+	`, id)
+	if err != nil {
+		fmt.Printf("Warning: failed to cancel project payments: %v\n", err)
+	}
+	if s.workflow != nil {
+		if err := s.workflow.cancelPendingJobsForEntity(ctx, "project", id); err != nil {
+			fmt.Printf("Warning: failed to cancel pending jobs: %v\n", err)
+		}
+	}
+
+	return nil
+}
+
+// UpdateStatus changes the status of a project.
+// A project cannot be completed while it has unresolved compliance items.
+// inputs are checked against the inputs declared by the workflow transition taken.
+func (s *ProjectService) UpdateStatus(ctx context.Context, id, orgID uuid.UUID, status models.ProjectStatus, inputs map[string]interface{}, changedBy *uuid.UUID) error {
+	switch status {
+	case models.ProjectStatusInProgress, models.ProjectStatusOnHold, models.ProjectStatusCompleted, models.ProjectStatusCancelled:
+	default:
+		return fmt.Errorf("invalid project status: %s", status)
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Locked so the compliance gate and the change see the same project, and concurrent
+	// status changes apply one after the other
+	var currentStatus models.ProjectStatus
+	err = tx.QueryRow(ctx, `
+		SELECT status FROM projects
+		WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+		FOR UPDATE
+	`, id, orgID).Scan(&currentStatus)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("project not found")
+		}
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	if currentStatus == status {
+		return nil
+	}
+
+	if status == models.ProjectStatusCompleted && s.compliance != nil {
+		if err := s.compliance.CheckProjectCompletable(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+
+	var transition *TransitionInputs
+	if s.workflow != nil {
+		transition, err = s.workflow.PrepareTransition(ctx, orgID, models.WorkflowModuleConstruction, models.WorkflowEntityProject,
+			string(currentStatus), string(status), inputs)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE projects
+		SET status = $1,
+			actual_end_date = CASE WHEN $1 = 'completed' THEN CURRENT_DATE ELSE actual_end_date END,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND organization_id = $3
+	`, status, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to update project status: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// Trigger workflow (non-blocking)
+	if s.workflow != nil {
+		if err := s.workflow.RecordTransition(ctx, orgID, "project", id, transition, changedBy); err != nil {
+			fmt.Printf("Failed to record transition: %v\n", err)
+		}
+		if err := s.workflow.OnProjectStateChange(ctx, orgID, id, string(currentStatus), string(status)); err != nil {
+			fmt.Printf("Failed to trigger workflow: %v\n", err)
+		}
+	}
+
+	return nil
+}
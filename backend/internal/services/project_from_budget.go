@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// How the items of a budget are copied into the project created from it
+const (
+	BudgetItemsAsTasks      = "tasks"
+	BudgetItemsAsMilestones = "milestones"
+	BudgetItemsAsNone       = "none"
+)
+
+type budgetItemForProject struct {
+	description string
+	quantity    decimal.Decimal
+	unit        string
+}
+
+// copyBudgetItems copies the items of a budget into tasks or milestones of the project created
+// from it, spread evenly between the project's start and expected end dates
+func copyBudgetItems(ctx context.Context, tx pgx.Tx, projectID, budgetID uuid.UUID, itemsAs string, startDate, endDate time.Time, createdBy uuid.UUID) error {
+	items, err := budgetItemsForProject(ctx, tx, budgetID)
+	if err != nil {
+		return err
+	}
+
+	durationDays := int(endDate.Sub(startDate).Hours() / 24)
+	now := time.Now()
+	for i, item := range items {
+		// Each item gets an equal slice of the project, in the budget's order
+		itemStart := startDate.AddDate(0, 0, i*durationDays/len(items))
+		itemDue := startDate.AddDate(0, 0, (i+1)*durationDays/len(items))
+		description := fmt.Sprintf("%s %s", item.quantity.String(), item.unit)
+
+		if itemsAs == BudgetItemsAsMilestones {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO project_milestones (id, project_id, name, description, position, start_date, due_date, created_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			`, uuid.New(), projectID, truncateRunes(item.description, 100), description, i+1, itemStart, itemDue, now)
+			if err != nil {
+				return fmt.Errorf("failed to create milestone: %w", err)
+			}
+			continue
+		}
+
+		_, err := tx.Exec(ctx, `
+			INSERT INTO tasks (id, project_id, title, description, status, priority, due_date, created_by, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`, uuid.New(), projectID, truncateRunes(item.description, 255), description, models.TaskStatusTodo,
+			models.PriorityMedium, itemDue, createdBy, now, now)
+		if err != nil {
+			return fmt.Errorf("failed to create task: %w", err)
+		}
+	}
+	return nil
+}
+
+func budgetItemsForProject(ctx context.Context, tx pgx.Tx, budgetID uuid.UUID) ([]budgetItemForProject, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT description, quantity, unit
+		FROM budget_items
+		WHERE budget_id = $1 AND deleted_at IS NULL
+		ORDER BY "order"
+	`, budgetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []budgetItemForProject
+	for rows.Next() {
+		var item budgetItemForProject
+		if err := rows.Scan(&item.description, &item.quantity, &item.unit); err != nil {
+			return nil, fmt.Errorf("failed to scan budget item: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// truncateRunes cuts s to at most n characters
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// taskProgressItem is a task counted towards its project's progress
+type taskProgressItem struct {
+	Weight int
+	Done   bool
+}
+
+// computeTaskProgress returns the weighted share of done tasks as a percentage, or nil when there
+// are no tasks. It rounds down so a project only shows 100 once every task is done.
+func computeTaskProgress(items []taskProgressItem) *int {
+	total, done := 0, 0
+	for _, item := range items {
+		total += item.Weight
+		if item.Done {
+			done += item.Weight
+		}
+	}
+	if total == 0 {
+		return nil
+	}
+	progress := done * 100 / total
+	return &progress
+}
+
+// recalculateProjectProgress stores the progress computed from a project's tasks. Projects in tasks
+// mode without an override show it as their progress; completed and cancelled projects keep theirs.
+func recalculateProjectProgress(ctx context.Context, db *database.DB, projectID uuid.UUID) error {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT t.weight, t.status = 'completed' OR m.completed_at IS NOT NULL
+		FROM tasks t
+		LEFT JOIN project_milestones m ON m.id = t.milestone_id
+		WHERE t.project_id = $1 AND t.deleted_at IS NULL AND t.status <> 'cancelled'
+	`, projectID)
+	if err != nil {
+		return fmt.Errorf("failed to query project tasks: %w", err)
+	}
+	defer rows.Close()
+
+	items := []taskProgressItem{}
+	for rows.Next() {
+		var item taskProgressItem
+		if err := rows.Scan(&item.Weight, &item.Done); err != nil {
+			return fmt.Errorf("failed to scan project task: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to query project tasks: %w", err)
+	}
+
+	_, err = db.Pool.Exec(ctx, `
+		UPDATE projects
+		SET computed_progress = $2::int,
+			progress = CASE
+				WHEN progress_mode = 'tasks' AND progress_override IS NULL AND status NOT IN ('completed', 'cancelled')
+				THEN COALESCE($2::int, 0)
+				ELSE progress
+			END,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL
+	`, projectID, computeTaskProgress(items))
+	if err != nil {
+		return fmt.Errorf("failed to update project progress: %w", err)
+	}
+	return nil
+}
+
+// activeProjectProgressMode returns the progress mode of a project that isn't completed or cancelled
+func (s *ProjectService) activeProjectProgressMode(ctx context.Context, id, orgID uuid.UUID) (models.ProjectProgressMode, error) {
+	var status models.ProjectStatus
+	var mode models.ProjectProgressMode
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT status, progress_mode FROM projects
+		WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`, id, orgID).Scan(&status, &mode)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", errors.New("project not found")
+		}
+		return "", fmt.Errorf("failed to get project: %w", err)
+	}
+	if status == models.ProjectStatusCompleted || status == models.ProjectStatusCancelled {
+		return "", errors.New("cannot update progress of completed or cancelled projects")
+	}
+	return mode, nil
+}
+
+// SetProgressMode switches a project between progress set by hand and progress computed from its
+// tasks. Any override is cleared: a project switched to manual keeps the progress it shows.
+func (s *ProjectService) SetProgressMode(ctx context.Context, id, orgID uuid.UUID, mode models.ProjectProgressMode) (*models.ProjectWithDetails, error) {
+	if mode != models.ProjectProgressManual && mode != models.ProjectProgressTasks {
+		return nil, errors.New("invalid progress mode")
+	}
+	if _, err := s.activeProjectProgressMode(ctx, id, orgID); err != nil {
+		return nil, err
+	}
+
+	_, err := s.db.Pool.Exec(ctx, `
+		UPDATE projects
+		SET progress_mode = $1, progress_override = NULL, progress_override_by = NULL, progress_override_at = NULL,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND organization_id = $3
+	`, mode, id, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update project progress mode: %w", err)
+	}
+	if err := recalculateProjectProgress(ctx, s.db, id); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, id, orgID)
+}
+
+// ClearProgressOverride drops the progress set by hand on a project in tasks mode, which shows the
+// progress computed from its tasks again
+func (s *ProjectService) ClearProgressOverride(ctx context.Context, id, orgID uuid.UUID) (*models.ProjectWithDetails, error) {
+	mode, err := s.activeProjectProgressMode(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if mode != models.ProjectProgressTasks {
+		return nil, errors.New("project progress is not computed from tasks")
+	}
+
+	_, err = s.db.Pool.Exec(ctx, `
+		UPDATE projects
+		SET progress_override = NULL, progress_override_by = NULL, progress_override_at = NULL,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND organization_id = $2
+	`, id, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clear project progress override: %w", err)
+	}
+	if err := recalculateProjectProgress(ctx, s.db, id); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, id, orgID)
+}
@@ -0,0 +1,28 @@
+package services
+
+import "testing"
+
+func TestComputeTaskProgress(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []taskProgressItem
+		want  *int
+	}{
+		{"no tasks", nil, nil},
+		{"nothing done", []taskProgressItem{{1, false}, {1, false}}, intPtr(0)},
+		{"half done", []taskProgressItem{{1, true}, {1, false}}, intPtr(50)},
+		{"weighted", []taskProgressItem{{3, true}, {1, false}}, intPtr(75)},
+		{"rounds down", []taskProgressItem{{1, true}, {1, true}, {1, false}}, intPtr(66)},
+		{"almost done stays below 100", []taskProgressItem{{99, true}, {1, false}}, intPtr(99)},
+		{"all done", []taskProgressItem{{2, true}, {5, true}}, intPtr(100)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := computeTaskProgress(tt.items)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("computeTaskProgress() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,607 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ProjectTemplateService handles project templates and their instantiation
+type ProjectTemplateService struct {
+	db         *database.DB
+	compliance *ComplianceService
+}
+
+func NewProjectTemplateService(db *database.DB) *ProjectTemplateService {
+	return &ProjectTemplateService{db: db}
+}
+
+// SetComplianceService sets the compliance service used to build checklists for new projects
+func (s *ProjectTemplateService) SetComplianceService(cs *ComplianceService) {
+	s.compliance = cs
+}
+
+// ============ Templates ============
+
+// ListTemplates returns all project templates for an organization
+func (s *ProjectTemplateService) ListTemplates(ctx context.Context, orgID uuid.UUID, activeOnly bool) ([]*models.ProjectTemplate, error) {
+	query := `
+		SELECT id, organization_id, name, description, category, is_active, created_by, created_at, updated_at
+		FROM project_templates
+		WHERE organization_id = $1
+	`
+	if activeOnly {
+		query += " AND is_active = true"
+	}
+	query += " ORDER BY name"
+
+	rows, err := s.db.Pool.Query(ctx, query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query project templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []*models.ProjectTemplate
+	for rows.Next() {
+		var t models.ProjectTemplate
+		if err := rows.Scan(
+			&t.ID, &t.OrganizationID, &t.Name, &t.Description, &t.Category,
+			&t.IsActive, &t.CreatedBy, &t.CreatedAt, &t.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan project template: %w", err)
+		}
+		templates = append(templates, &t)
+	}
+
+	return templates, nil
+}
+
+// GetTemplate returns a project template with its phases, tasks and documents
+func (s *ProjectTemplateService) GetTemplate(ctx context.Context, id, orgID uuid.UUID) (*models.ProjectTemplate, error) {
+	var t models.ProjectTemplate
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT id, organization_id, name, description, category, is_active, created_by, created_at, updated_at
+		FROM project_templates
+		WHERE id = $1 AND organization_id = $2
+	`, id, orgID).Scan(
+		&t.ID, &t.OrganizationID, &t.Name, &t.Description, &t.Category,
+		&t.IsActive, &t.CreatedBy, &t.CreatedAt, &t.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("project template not found")
+		}
+		return nil, fmt.Errorf("failed to get project template: %w", err)
+	}
+
+	// Phases
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, template_id, name, description, position, start_offset_days, duration_days
+		FROM project_template_phases
+		WHERE template_id = $1
+		ORDER BY position
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query template phases: %w", err)
+	}
+	for rows.Next() {
+		var p models.ProjectTemplatePhase
+		if err := rows.Scan(&p.ID, &p.TemplateID, &p.Name, &p.Description, &p.Position, &p.StartOffsetDays, &p.DurationDays); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan template phase: %w", err)
+		}
+		t.Phases = append(t.Phases, p)
+	}
+	rows.Close()
+
+	// Tasks
+	rows, err = s.db.Pool.Query(ctx, `
+		SELECT id, template_id, phase_id, title, description, priority, position, start_offset_days, duration_days
+		FROM project_template_tasks
+		WHERE template_id = $1
+		ORDER BY position
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query template tasks: %w", err)
+	}
+	for rows.Next() {
+		var task models.ProjectTemplateTask
+		if err := rows.Scan(&task.ID, &task.TemplateID, &task.PhaseID, &task.Title, &task.Description, &task.Priority, &task.Position, &task.StartOffsetDays, &task.DurationDays); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan template task: %w", err)
+		}
+		t.Tasks = append(t.Tasks, task)
+	}
+	rows.Close()
+
+	// Documents
+	rows, err = s.db.Pool.Query(ctx, `
+		SELECT id, template_id, name, description, is_required, position
+		FROM project_template_documents
+		WHERE template_id = $1
+		ORDER BY position
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query template documents: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var d models.ProjectTemplateDocument
+		if err := rows.Scan(&d.ID, &d.TemplateID, &d.Name, &d.Description, &d.IsRequired, &d.Position); err != nil {
+			return nil, fmt.Errorf("failed to scan template document: %w", err)
+		}
+		t.Documents = append(t.Documents, d)
+	}
+
+	return &t, nil
+}
+
+// ProjectTemplatePhaseInput describes a phase and its tasks when saving a template
+type ProjectTemplatePhaseInput struct {
+	Name            string                     `json:"name"`
+	Description     *string                    `json:"description"`
+	StartOffsetDays int                        `json:"start_offset_days"`
+	DurationDays    int                        `json:"duration_days"`
+	Tasks           []ProjectTemplateTaskInput `json:"tasks"`
+}
+
+// ProjectTemplateTaskInput describes a task when saving a template
+type ProjectTemplateTaskInput struct {
+	Title           string          `json:"title"`
+	Description     *string         `json:"description"`
+	Priority        models.Priority `json:"priority"`
+	StartOffsetDays int             `json:"start_offset_days"`
+	DurationDays    int             `json:"duration_days"`
+}
+
+// ProjectTemplateDocumentInput describes a required document when saving a template
+type ProjectTemplateDocumentInput struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+	IsRequired  *bool   `json:"is_required"`
+}
+
+// ProjectTemplateStructure is the full structure of a template (phases with tasks, loose tasks, documents)
+type ProjectTemplateStructure struct {
+	Phases    []ProjectTemplatePhaseInput    `json:"phases"`
+	Tasks     []ProjectTemplateTaskInput     `json:"tasks"`
+	Documents []ProjectTemplateDocumentInput `json:"documents"`
+}
+
+// validate checks the template structure
+func (st *ProjectTemplateStructure) validate() error {
+	checkTask := func(task ProjectTemplateTaskInput) error {
+		if task.Title == "" {
+			return errors.New("task title is required")
+		}
+		if task.StartOffsetDays < 0 || task.DurationDays < 0 {
+			return fmt.Errorf("task '%s' has negative offset or duration", task.Title)
+		}
+		switch task.Priority {
+		case "", models.PriorityLow, models.PriorityMedium, models.PriorityHigh, models.PriorityUrgent:
+		default:
+			return fmt.Errorf("invalid priority for task '%s'", task.Title)
+		}
+		return nil
+	}
+
+	for _, phase := range st.Phases {
+		if phase.Name == "" {
+			return errors.New("phase name is required")
+		}
+		if phase.StartOffsetDays < 0 || phase.DurationDays < 1 {
+			return fmt.Errorf("phase '%s' must start on or after day 0 and last at least one day", phase.Name)
+		}
+		for _, task := range phase.Tasks {
+			if err := checkTask(task); err != nil {
+				return err
+			}
+		}
+	}
+	for _, task := range st.Tasks {
+		if err := checkTask(task); err != nil {
+			return err
+		}
+	}
+	for _, doc := range st.Documents {
+		if doc.Name == "" {
+			return errors.New("document name is required")
+		}
+	}
+	return nil
+}
+
+// CreateTemplate creates a project template with its structure
+func (s *ProjectTemplateService) CreateTemplate(ctx context.Context, t *models.ProjectTemplate, structure ProjectTemplateStructure) error {
+	if t.Name == "" {
+		return errors.New("template name is required")
+	}
+	if err := structure.validate(); err != nil {
+		return err
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	t.ID = uuid.New()
+	t.IsActive = true
+	t.CreatedAt = time.Now()
+	t.UpdatedAt = t.CreatedAt
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO project_templates (id, organization_id, name, description, category, is_active, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, t.ID, t.OrganizationID, t.Name, t.Description, t.Category, t.IsActive, t.CreatedBy, t.CreatedAt, t.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create project template: %w", err)
+	}
+
+	if err := s.insertStructure(ctx, tx, t, structure); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateTemplate updates a project template, replacing its structure
+func (s *ProjectTemplateService) UpdateTemplate(ctx context.Context, id, orgID uuid.UUID, t *models.ProjectTemplate, structure ProjectTemplateStructure) error {
+	if t.Name == "" {
+		return errors.New("template name is required")
+	}
+	if err := structure.validate(); err != nil {
+		return err
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE project_templates
+		SET name = $1, description = $2, category = $3, is_active = $4, updated_at = NOW()
+		WHERE id = $5 AND organization_id = $6
+	`, t.Name, t.Description, t.Category, t.IsActive, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to update project template: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("project template not found")
+	}
+
+	// Replace structure (tasks cascade from phases, loose tasks are removed explicitly)
+	for _, table := range []string{"project_template_tasks", "project_template_phases", "project_template_documents"} {
+		if _, err := tx.Exec(ctx, fmt.Sprintf("DELETE FROM %s WHERE template_id = $1", table), id); err != nil {
+			return fmt.Errorf("failed to clear template structure: %w", err)
+		}
+	}
+
+	t.ID = id
+	t.OrganizationID = orgID
+	t.Phases, t.Tasks, t.Documents = nil, nil, nil
+	if err := s.insertStructure(ctx, tx, t, structure); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// insertStructure inserts phases, tasks and documents for a template and fills the nested slices
+func (s *ProjectTemplateService) insertStructure(ctx context.Context, tx pgx.Tx, t *models.ProjectTemplate, structure ProjectTemplateStructure) error {
+	taskPosition := 0
+	insertTask := func(phaseID *uuid.UUID, input ProjectTemplateTaskInput) error {
+		task := models.ProjectTemplateTask{
+			ID:              uuid.New(),
+			TemplateID:      t.ID,
+			PhaseID:         phaseID,
+			Title:           input.Title,
+			Description:     input.Description,
+			Priority:        input.Priority,
+			Position:        taskPosition,
+			StartOffsetDays: input.StartOffsetDays,
+			DurationDays:    input.DurationDays,
+		}
+		if task.Priority == "" {
+			task.Priority = models.PriorityMedium
+		}
+		taskPosition++
+
+		_, err := tx.Exec(ctx, `
+			INSERT INTO project_template_tasks (id, template_id, phase_id, title, description, priority, position, start_offset_days, duration_days)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`, task.ID, task.TemplateID, task.PhaseID, task.Title, task.Description, task.Priority, task.Position, task.StartOffsetDays, task.DurationDays)
+		if err != nil {
+			return fmt.Errorf("failed to create template task: %w", err)
+		}
+		t.Tasks = append(t.Tasks, task)
+		return nil
+	}
+
+	for i, input := range structure.Phases {
+		phase := models.ProjectTemplatePhase{
+			ID:              uuid.New(),
+			TemplateID:      t.ID,
+			Name:            input.Name,
+			Description:     input.Description,
+			Position:        i,
+			StartOffsetDays: input.StartOffsetDays,
+			DurationDays:    input.DurationDays,
+		}
+		_, err := tx.Exec(ctx, `
+			INSERT INTO project_template_phases (id, template_id, name, description, position, start_offset_days, duration_days)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, phase.ID, phase.TemplateID, phase.Name, phase.Description, phase.Position, phase.StartOffsetDays, phase.DurationDays)
+		if err != nil {
+			return fmt.Errorf("failed to create template phase: %w", err)
+		}
+		t.Phases = append(t.Phases, phase)
+
+		for _, taskInput := range input.Tasks {
+			if err := insertTask(&phase.ID, taskInput); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, taskInput := range structure.Tasks {
+		if err := insertTask(nil, taskInput); err != nil {
+			return err
+		}
+	}
+
+	for i, input := range structure.Documents {
+		doc := models.ProjectTemplateDocument{
+			ID:          uuid.New(),
+			TemplateID:  t.ID,
+			Name:        input.Name,
+			Description: input.Description,
+			IsRequired:  input.IsRequired == nil || *input.IsRequired,
+			Position:    i,
+		}
+		_, err := tx.Exec(ctx, `
+			INSERT INTO project_template_documents (id, template_id, name, description, is_required, position)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, doc.ID, doc.TemplateID, doc.Name, doc.Description, doc.IsRequired, doc.Position)
+		if err != nil {
+			return fmt.Errorf("failed to create template document: %w", err)
+		}
+		t.Documents = append(t.Documents, doc)
+	}
+
+	return nil
+}
+
+// DeleteTemplate deletes a project template; projects created from it are kept
+func (s *ProjectTemplateService) DeleteTemplate(ctx context.Context, id, orgID uuid.UUID) error {
+	result, err := s.db.Pool.Exec(ctx, `
+		DELETE FROM project_templates WHERE id = $1 AND organization_id = $2
+	`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to delete project template: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("project template not found")
+	}
+	return nil
+}
+
+// ============ Instantiation ============
+
+// InstantiateTemplateInput contains the parameters for creating a project from a template
+type InstantiateTemplateInput struct {
+	BudgetID    uuid.UUID
+	Title       string
+	Description *string
+	StartDate   time.Time
+	CreatedBy   uuid.UUID
+}
+
+// Instantiate creates a project, its milestones, tasks and required documents from a template
+func (s *ProjectTemplateService) Instantiate(ctx context.Context, templateID, orgID uuid.UUID, input InstantiateTemplateInput) (*models.ProjectFromTemplate, error) {
+	template, err := s.GetTemplate(ctx, templateID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !template.IsActive {
+		return nil, errors.New("project template is not active")
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// The budget must be approved and not yet linked to a project
+	budget, err := lockBudgetForProject(ctx, tx, orgID, input.BudgetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Title == "" {
+		input.Title = budget.worksheetTitle
+	}
+	if input.Title == "" {
+		input.Title = template.Name
+	}
+	if input.StartDate.IsZero() {
+		input.StartDate = time.Now()
+	}
+	startDate := truncateToDate(input.StartDate)
+
+	durationDays := template.TotalDurationDays()
+	if durationDays < 1 {
+		durationDays = 1
+	}
+
+	projectNumber, err := nextProjectNumber(ctx, tx, orgID, startDate.Year())
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	project := &models.Project{
+		ID:              uuid.New(),
+		OrganizationID:  orgID,
+		BudgetID:        input.BudgetID,
+		ProjectNumber:   projectNumber,
+		Title:           input.Title,
+		Description:     input.Description,
+		Category:        template.Category,
+		Status:          models.ProjectStatusInProgress,
+		ProgressMode:    models.ProjectProgressManual,
+		StartDate:       startDate,
+		ExpectedEndDate: startDate.AddDate(0, 0, durationDays),
+		TemplateID:      &template.ID,
+		CreatedBy:       input.CreatedBy,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO projects (
+			id, organization_id, budget_id, project_number, title, description, category, status,
+			progress, start_date, expected_end_date, template_id, created_by, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 0, $9, $10, $11, $12, $13, $14)
+	`, project.ID, project.OrganizationID, project.BudgetID, project.ProjectNumber, project.Title,
+		project.Description, project.Category, project.Status, project.StartDate, project.ExpectedEndDate,
+		project.TemplateID, project.CreatedBy, project.CreatedAt, project.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create project: %w", err)
+	}
+	if err := recordBudgetLinkPayments(ctx, tx, orgID, input.BudgetID, project.ID, budget.createdBy); err != nil {
+		return nil, err
+	}
+
+	result := &models.ProjectFromTemplate{Project: project}
+
+	// Milestones from phases
+	milestoneByPhase := make(map[uuid.UUID]*models.ProjectMilestone)
+	for _, phase := range template.Phases {
+		phaseStart := startDate.AddDate(0, 0, phase.StartOffsetDays)
+		milestone := &models.ProjectMilestone{
+			ID:          uuid.New(),
+			ProjectID:   project.ID,
+			Name:        phase.Name,
+			Description: phase.Description,
+			Position:    phase.Position,
+			StartDate:   phaseStart,
+			DueDate:     phaseStart.AddDate(0, 0, phase.DurationDays),
+			CreatedAt:   now,
+		}
+		_, err := tx.Exec(ctx, `
+			INSERT INTO project_milestones (id, project_id, name, description, position, start_date, due_date, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, milestone.ID, milestone.ProjectID, milestone.Name, milestone.Description,
+			milestone.Position, milestone.StartDate, milestone.DueDate, milestone.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create milestone: %w", err)
+		}
+		milestoneByPhase[phase.ID] = milestone
+		result.Milestones = append(result.Milestones, milestone)
+	}
+
+	// Tasks, scheduled relative to their phase
+	for _, templateTask := range template.Tasks {
+		taskStart := startDate
+		var milestoneID *uuid.UUID
+		if templateTask.PhaseID != nil {
+			if milestone, ok := milestoneByPhase[*templateTask.PhaseID]; ok {
+				taskStart = milestone.StartDate
+				milestoneID = &milestone.ID
+			}
+		}
+		dueDate := taskStart.AddDate(0, 0, templateTask.StartOffsetDays+templateTask.DurationDays)
+
+		task := &models.Task{
+			ID:          uuid.New(),
+			ProjectID:   project.ID,
+			MilestoneID: milestoneID,
+			Title:       templateTask.Title,
+			Description: templateTask.Description,
+			Status:      models.TaskStatusTodo,
+			Priority:    templateTask.Priority,
+			Weight:      1,
+			DueDate:     &dueDate,
+			CreatedBy:   input.CreatedBy,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		_, err := tx.Exec(ctx, `
+			INSERT INTO tasks (id, project_id, milestone_id, title, description, status, priority, due_date, created_by, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		`, task.ID, task.ProjectID, task.MilestoneID, task.Title, task.Description, task.Status,
+			task.Priority, task.DueDate, task.CreatedBy, task.CreatedAt, task.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create task: %w", err)
+		}
+		result.Tasks = append(result.Tasks, task)
+	}
+
+	// Required documents
+	for _, templateDoc := range template.Documents {
+		doc := &models.ProjectDocument{
+			ID:          uuid.New(),
+			ProjectID:   project.ID,
+			Name:        templateDoc.Name,
+			Description: templateDoc.Description,
+			IsRequired:  templateDoc.IsRequired,
+			Status:      models.ProjectDocumentStatusPending,
+			CreatedAt:   now,
+		}
+		_, err := tx.Exec(ctx, `
+			INSERT INTO project_documents (id, project_id, name, description, is_required, status, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, doc.ID, doc.ProjectID, doc.Name, doc.Description, doc.IsRequired, doc.Status, doc.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create project document: %w", err)
+		}
+		result.Documents = append(result.Documents, doc)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// Build the compliance checklist for the project's category
+	if s.compliance != nil {
+		if _, err := s.compliance.ApplyToProject(ctx, orgID, project.ID); err != nil {
+			fmt.Printf("Failed to apply compliance requirements: %v\n", err)
+		}
+	}
+
+	return result, nil
+}
+
+// nextProjectNumber generates the next sequential project number for an organization (PRJ-YYYY-NNN)
+func nextProjectNumber(ctx context.Context, tx pgx.Tx, orgID uuid.UUID, year int) (string, error) {
+	return nextPrefixedProjectNumber(ctx, tx, orgID, "PRJ", year)
+}
+
+// nextPrefixedProjectNumber generates the next project number of a sequence, e.g. OBRA-2025-001
+func nextPrefixedProjectNumber(ctx context.Context, tx pgx.Tx, orgID uuid.UUID, sequence string, year int) (string, error) {
+	return nextDocumentNumber(ctx, tx, projectSequence, orgID, sequence, year)
+}
+
+// truncateToDate strips the time component from t
+func truncateToDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,467 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+const (
+	// quickMatchMinScore is the score from which a record is offered as a candidate
+	quickMatchMinScore = 50
+	// quickMatchResolveScore is the score from which the best candidate is picked, if it beats the others
+	quickMatchResolveScore = 75
+	quickMatchCandidates   = 5
+	// quickDefaultTimezone is used until a therapist is known, as in the therapists table default
+	quickDefaultTimezone = "Europe/Lisbon"
+)
+
+// QuickCreateService turns loose typed text into records, e.g.
+// "João Silva tomorrow 15h with Dr. Maria" into a session
+type QuickCreateService struct {
+	db         *database.DB
+	sessions   *SessionService
+	therapists *TherapistService
+}
+
+func NewQuickCreateService(db *database.DB, sessions *SessionService, therapists *TherapistService) *QuickCreateService {
+	return &QuickCreateService{db: db, sessions: sessions, therapists: therapists}
+}
+
+// QuickSessionRequest is a quick-create text with the picks made on an earlier draft
+type QuickSessionRequest struct {
+	Text        string
+	Timezone    string     // optional, defaults to the therapist's
+	PatientID   *uuid.UUID // picks a patient when the text was ambiguous
+	TherapistID *uuid.UUID
+	Confirm     bool // book the session if the draft is complete
+}
+
+// quickSessionText is what was recognised in a quick-create text
+type quickSessionText struct {
+	patient   string
+	therapist string
+
+	dayOffset *int          // today, tomorrow
+	weekday   *time.Weekday // next such weekday
+	date      *[3]int       // year (0 if not typed), month, day
+	hasTime   bool
+	hour      int
+	minute    int
+	duration  int
+}
+
+var (
+	quickClockPattern    = regexp.MustCompile(`^(\d{1,2})(?:h|:)(\d{2})?$`)
+	quickAmPmPattern     = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)$`)
+	quickDatePattern     = regexp.MustCompile(`^(\d{1,2})/(\d{1,2})(?:/(\d{2}|\d{4}))?$`)
+	quickISODatePattern  = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})$`)
+	quickDurationPattern = regexp.MustCompile(`^(\d{1,3})(?:m|min|mins|minutes|minutos)$`)
+	quickNumberPattern   = regexp.MustCompile(`^\d{1,2}$`)
+)
+
+var quickWeekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+	"domingo": time.Sunday, "segunda": time.Monday, "terca": time.Tuesday, "quarta": time.Wednesday,
+	"quinta": time.Thursday, "sexta": time.Friday, "sabado": time.Saturday,
+}
+
+// quickConnectors separate the patient from the therapist
+var quickConnectors = map[string]bool{"with": true, "com": true}
+
+// quickFillers carry no meaning of their own; "at" and "as" (às) also mark a following bare hour
+var quickFillers = map[string]bool{
+	"at": true, "as": true, "a": true, "on": true, "next": true, "for": true, "the": true,
+	"no": true, "na": true, "em": true, "dia": true, "proxima": true, "proximo": true, "para": true, "durante": true,
+	"dr": true, "dra": true, "doctor": true, "doutor": true, "doutora": true, "terapeuta": true, "therapist": true,
+}
+
+// normalizeQuickText lowercases text and drops accents and punctuation around words
+func normalizeQuickText(s string) string {
+	s = accentReplacer.Replace(strings.ToLower(s))
+	s = strings.NewReplacer(",", " ", ";", " ", "!", " ", "?", " ").Replace(s)
+	words := strings.Fields(s)
+	for i, w := range words {
+		words[i] = strings.TrimRight(w, ".")
+	}
+	return strings.Join(words, " ")
+}
+
+// parseQuickSessionText splits a quick-create text into the patient and therapist names and
+// the date, time and duration it mentions. Names come before and after "with"/"com".
+func parseQuickSessionText(text string) quickSessionText {
+	var p quickSessionText
+	var patient, therapist []string
+	afterConnector := false
+	words := strings.Fields(normalizeQuickText(text))
+
+	for i := 0; i < len(words); i++ {
+		w := words[i]
+		prev := ""
+		if i > 0 {
+			prev = words[i-1]
+		}
+
+		switch {
+		case quickConnectors[w]:
+			afterConnector = true
+			continue
+		case w == "today" || w == "hoje":
+			p.dayOffset = intPtr(0)
+			continue
+		case w == "tomorrow" || w == "amanha":
+			p.dayOffset = intPtr(1)
+			continue
+		case w == "depois" && i+2 < len(words) && words[i+1] == "de" && words[i+2] == "amanha":
+			p.dayOffset = intPtr(2)
+			i += 2
+			continue
+		}
+
+		if day, ok := quickWeekdays[strings.TrimSuffix(w, "-feira")]; ok {
+			p.weekday = &day
+			continue
+		}
+		if m := quickISODatePattern.FindStringSubmatch(w); m != nil {
+			p.date = &[3]int{atoi(m[1]), atoi(m[2]), atoi(m[3])}
+			continue
+		}
+		if m := quickDatePattern.FindStringSubmatch(w); m != nil {
+			year := 0
+			if m[3] != "" {
+				year = atoi(m[3])
+				if year < 100 {
+					year += 2000
+				}
+			}
+			p.date = &[3]int{year, atoi(m[2]), atoi(m[1])}
+			continue
+		}
+		if m := quickDurationPattern.FindStringSubmatch(w); m != nil {
+			p.duration = atoi(m[1])
+			continue
+		}
+		if quickNumberPattern.MatchString(w) && i+1 < len(words) && quickDurationPattern.MatchString("0"+words[i+1]) {
+			p.duration = atoi(w)
+			i++
+			continue
+		}
+		if m := quickClockPattern.FindStringSubmatch(w); m != nil && atoi(m[1]) < 24 {
+			p.hasTime, p.hour, p.minute = true, atoi(m[1]), atoi(m[2])
+			continue
+		}
+		if m := quickAmPmPattern.FindStringSubmatch(w); m != nil && atoi(m[1]) >= 1 && atoi(m[1]) <= 12 {
+			p.hasTime, p.hour, p.minute = true, atoi(m[1])%12, atoi(m[2])
+			if m[3] == "pm" {
+				p.hour += 12
+			}
+			continue
+		}
+		if quickNumberPattern.MatchString(w) && (prev == "at" || prev == "as") && atoi(w) < 24 {
+			p.hasTime, p.hour, p.minute = true, atoi(w), 0
+			continue
+		}
+		if quickFillers[w] {
+			continue
+		}
+
+		if afterConnector {
+			therapist = append(therapist, w)
+		} else {
+			patient = append(patient, w)
+		}
+	}
+
+	p.patient = strings.Join(patient, " ")
+	p.therapist = strings.Join(therapist, " ")
+	return p
+}
+
+// at returns when the text asks for the session, in loc. A time without a day is the next
+// time that clock comes around; a weekday is its next occurrence that is still ahead.
+func (p quickSessionText) at(now time.Time, loc *time.Location) *time.Time {
+	if !p.hasTime {
+		return nil
+	}
+	now = now.In(loc)
+	clock := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, p.hour, p.minute, 0, 0, loc)
+	}
+
+	var t time.Time
+	switch {
+	case p.date != nil:
+		year := p.date[0]
+		if year == 0 {
+			year = now.Year()
+		}
+		t = clock(year, time.Month(p.date[1]), p.date[2])
+		if p.date[0] == 0 && t.Before(now) {
+			t = t.AddDate(1, 0, 0)
+		}
+	case p.dayOffset != nil:
+		t = clock(now.Year(), now.Month(), now.Day()+*p.dayOffset)
+	case p.weekday != nil:
+		days := (int(*p.weekday) - int(now.Weekday()) + 7) % 7
+		t = clock(now.Year(), now.Month(), now.Day()+days)
+		if t.Before(now) {
+			t = clock(now.Year(), now.Month(), now.Day()+days+7)
+		}
+	default:
+		t = clock(now.Year(), now.Month(), now.Day())
+		if t.Before(now) {
+			t = clock(now.Year(), now.Month(), now.Day()+1)
+		}
+	}
+	return &t
+}
+
+// quickNameScore scores how well a typed name matches a record's name, 0-100. Every typed word
+// must match a word of the name exactly, as its prefix, or with one typo; the full name
+// scores higher than some of its words.
+func quickNameScore(query, name string) int {
+	queryWords := strings.Fields(normalizeQuickText(query))
+	nameWords := strings.Fields(normalizeQuickText(name))
+	if len(queryWords) == 0 || len(nameWords) == 0 {
+		return 0
+	}
+
+	matched := 0
+	for _, q := range queryWords {
+		for _, n := range nameWords {
+			if q == n || (len(q) >= 3 && strings.HasPrefix(n, q)) || (len(q) >= 4 && editDistance(q, n) <= 1) {
+				matched++
+				break
+			}
+		}
+	}
+
+	score := matched * 90 / len(queryWords)
+	if matched == len(queryWords) && len(queryWords) == len(nameWords) {
+		score += 10
+	}
+	return score
+}
+
+// editDistance is the Levenshtein distance between two words
+func editDistance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	prev := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		cur := make([]int, len(br)+1)
+		cur[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(br)]
+}
+
+// quickMatches returns the records whose name matches the query, best first, and the one picked
+// if it clearly beats the others
+func quickMatches(query string, records []models.QuickMatch) ([]models.QuickMatch, *models.QuickMatch) {
+	matches := []models.QuickMatch{}
+	for _, r := range records {
+		if r.Score = quickNameScore(query, r.Name); r.Score >= quickMatchMinScore {
+			matches = append(matches, r)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	if len(matches) > quickMatchCandidates {
+		matches = matches[:quickMatchCandidates]
+	}
+
+	if len(matches) > 0 && matches[0].Score >= quickMatchResolveScore &&
+		(len(matches) == 1 || matches[0].Score > matches[1].Score) {
+		picked := matches[0]
+		return matches, &picked
+	}
+	return matches, nil
+}
+
+func (s *QuickCreateService) quickRecords(ctx context.Context, query string, orgID uuid.UUID) ([]models.QuickMatch, error) {
+	rows, err := s.db.Pool.Query(ctx, query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []models.QuickMatch
+	for rows.Next() {
+		var r models.QuickMatch
+		if err := rows.Scan(&r.ID, &r.Name); err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
+
+// pick resolves a typed name, or an explicit pick, against the records
+func pick(picked *uuid.UUID, typed string, records []models.QuickMatch) ([]models.QuickMatch, *models.QuickMatch) {
+	if picked != nil {
+		for _, r := range records {
+			if r.ID == *picked {
+				r.Score = 100
+				return []models.QuickMatch{r}, &r
+			}
+		}
+		return []models.QuickMatch{}, nil
+	}
+	if typed == "" {
+		return []models.QuickMatch{}, nil
+	}
+	return quickMatches(typed, records)
+}
+
+// QuickSession resolves a quick-create text into a session draft and, when asked and the
+// draft is complete, books it
+func (s *QuickCreateService) QuickSession(ctx context.Context, orgID uuid.UUID, req QuickSessionRequest, createdBy uuid.UUID) (*models.QuickSessionDraft, error) {
+	if strings.TrimSpace(req.Text) == "" && req.PatientID == nil {
+		return nil, errors.New("text is required")
+	}
+	parsed := parseQuickSessionText(req.Text)
+	draft := &models.QuickSessionDraft{Text: req.Text, Missing: []string{}}
+
+	patients, err := s.quickRecords(ctx, `
+		SELECT p.id, c.name FROM patients p
+		JOIN clients c ON c.id = p.client_id
+		WHERE p.organization_id = $1 AND p.deleted_at IS NULL
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list patients: %w", err)
+	}
+	draft.PatientCandidates, draft.Patient = pick(req.PatientID, parsed.patient, patients)
+
+	therapists, err := s.quickRecords(ctx, `
+		SELECT id, name FROM therapists
+		WHERE organization_id = $1 AND is_active = true AND deleted_at IS NULL
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list therapists: %w", err)
+	}
+	draft.TherapistCandidates, draft.Therapist = pick(req.TherapistID, parsed.therapist, therapists)
+
+	var therapist *models.Therapist
+	if draft.Therapist != nil {
+		therapist, err = s.therapists.GetByID(ctx, draft.Therapist.ID, orgID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	draft.Timezone = quickDefaultTimezone
+	if therapist != nil && therapist.Timezone != "" {
+		draft.Timezone = therapist.Timezone
+	}
+	if req.Timezone != "" {
+		draft.Timezone = req.Timezone
+	}
+	loc, err := time.LoadLocation(draft.Timezone)
+	if err != nil {
+		return nil, errors.New("invalid timezone")
+	}
+	draft.ScheduledAt = parsed.at(time.Now(), loc)
+
+	// Without a named therapist, pick one the way session creation does
+	if draft.Therapist == nil && req.TherapistID == nil && parsed.therapist == "" && draft.ScheduledAt != nil {
+		assignment, err := s.sessions.SuggestTherapist(ctx, orgID, SessionAssignmentRequest{
+			ScheduledAt:     *draft.ScheduledAt,
+			DurationMinutes: parsed.duration,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if assignment.SelectedID != nil {
+			draft.Therapist = &models.QuickMatch{ID: *assignment.SelectedID, Name: assignment.SelectedName}
+			draft.TherapistAutoAssigned = true
+			if therapist, err = s.therapists.GetByID(ctx, *assignment.SelectedID, orgID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	draft.DurationMinutes = parsed.duration
+	if therapist != nil {
+		if draft.DurationMinutes == 0 {
+			draft.DurationMinutes = therapist.SessionDurationMinutes
+		}
+		draft.PriceCents = therapist.DefaultPriceCents
+	}
+
+	if draft.Patient == nil {
+		draft.Missing = append(draft.Missing, "patient")
+	}
+	if draft.Therapist == nil {
+		draft.Missing = append(draft.Missing, "therapist")
+	}
+	if !parsed.hasTime {
+		draft.Missing = append(draft.Missing, "time")
+	}
+	if draft.DurationMinutes <= 0 {
+		draft.Missing = append(draft.Missing, "duration")
+	}
+	if draft.ScheduledAt != nil && !draft.ScheduledAt.After(time.Now()) {
+		draft.Missing = append(draft.Missing, "future_time")
+	}
+
+	if draft.Therapist != nil && draft.ScheduledAt != nil && draft.DurationMinutes > 0 {
+		end := draft.ScheduledAt.Add(time.Duration(draft.DurationMinutes) * time.Minute)
+		draft.Conflict, err = s.sessions.hasConflict(ctx, orgID, draft.Therapist.ID, *draft.ScheduledAt, end, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check conflicts: %w", err)
+		}
+	}
+	draft.Ready = len(draft.Missing) == 0 && !draft.Conflict
+
+	if !req.Confirm {
+		return draft, nil
+	}
+	if len(draft.Missing) > 0 {
+		return nil, fmt.Errorf("quick-create text is incomplete, missing: %s", strings.Join(draft.Missing, ", "))
+	}
+
+	session := &models.Session{
+		OrganizationID:  orgID,
+		TherapistID:     draft.Therapist.ID,
+		PatientID:       draft.Patient.ID,
+		ScheduledAt:     *draft.ScheduledAt,
+		DurationMinutes: draft.DurationMinutes,
+		PriceCents:      draft.PriceCents,
+	}
+	if err := s.sessions.Create(ctx, session, createdBy); err != nil {
+		return nil, err
+	}
+	draft.Session, err = s.sessions.GetByID(ctx, session.ID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func intPtr(n int) *int {
+	return &n
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseQuickSessionText(t *testing.T) {
+	lisbon, err := time.LoadLocation("Europe/Lisbon")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+	// Monday 2024-03-04 at 10:00 in Lisbon
+	now := time.Date(2024, 3, 4, 10, 0, 0, 0, lisbon)
+
+	tests := []struct {
+		text         string
+		patient      string
+		therapist    string
+		at           string
+		duration     int
+		wantNoTiming bool
+	}{
+		{text: "João Silva tomorrow 15h with Dr. Maria", patient: "joao silva", therapist: "maria", at: "2024-03-05 15:00"},
+		{text: "Ana Costa amanhã às 9h30 com a Dra. Inês", patient: "ana costa", therapist: "ines", at: "2024-03-05 09:30"},
+		{text: "rui sexta-feira as 14 com pedro 45 min", patient: "rui", therapist: "pedro", at: "2024-03-08 14:00", duration: 45},
+		{text: "Marta 9h", patient: "marta", at: "2024-03-05 09:00"},
+		{text: "Marta 11:15", patient: "marta", at: "2024-03-04 11:15"},
+		{text: "Marta monday 3pm", patient: "marta", at: "2024-03-04 15:00"},
+		{text: "Marta monday 9am", patient: "marta", at: "2024-03-11 09:00"},
+		{text: "Marta 02/03 16h with Rita, 60min", patient: "marta", therapist: "rita", at: "2025-03-02 16:00", duration: 60},
+		{text: "Marta 2024-04-01 16h", patient: "marta", at: "2024-04-01 16:00"},
+		{text: "Marta de Sousa tomorrow", patient: "marta de sousa", wantNoTiming: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			p := parseQuickSessionText(tt.text)
+			if p.patient != tt.patient || p.therapist != tt.therapist {
+				t.Errorf("names = %q / %q, want %q / %q", p.patient, p.therapist, tt.patient, tt.therapist)
+			}
+			if p.duration != tt.duration {
+				t.Errorf("duration = %d, want %d", p.duration, tt.duration)
+			}
+			at := p.at(now, lisbon)
+			if tt.wantNoTiming {
+				if at != nil {
+					t.Errorf("expected no time, got %v", at)
+				}
+				return
+			}
+			if at == nil {
+				t.Fatal("expected a time")
+			}
+			if got := at.Format("2006-01-02 15:04"); got != tt.at {
+				t.Errorf("at = %s, want %s", got, tt.at)
+			}
+		})
+	}
+}
+
+func TestQuickNameScore(t *testing.T) {
+	tests := []struct {
+		query, name string
+		want        int
+	}{
+		{"joao silva", "João Silva", 100},
+		{"joao", "João Silva", 90},
+		{"joa silv", "João Silva", 100},
+		{"joao silvq", "João Silva", 100},
+		{"joao costa", "João Silva", 45},
+		{"maria", "Mario Santos", 90},
+		{"rub", "Ruben Alves", 90},
+		{"ru", "Ruben Alves", 0},
+	}
+	for _, tt := range tests {
+		if got := quickNameScore(tt.query, tt.name); got != tt.want {
+			t.Errorf("quickNameScore(%q, %q) = %d, want %d", tt.query, tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/config"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+// ReceiptOCRProvider reads the fields of an expense receipt from its image or PDF
+type ReceiptOCRProvider interface {
+	Name() string
+	Extract(ctx context.Context, data []byte, mimeType string) (*models.ReceiptExtraction, error)
+}
+
+// NewReceiptOCRProvider returns the configured provider, or nil when receipts are filled in by hand
+func NewReceiptOCRProvider(cfg config.OCRConfig) ReceiptOCRProvider {
+	switch cfg.Provider {
+	case "http":
+		return &httpReceiptOCR{
+			endpoint: cfg.Endpoint,
+			apiKey:   cfg.APIKey,
+			client:   &http.Client{Timeout: 60 * time.Second},
+		}
+	default:
+		return nil
+	}
+}
+
+// httpReceiptOCR posts the receipt to an OCR service. The service answers with the fields it
+// read and, optionally, the raw text, which fills in the fields it left out.
+type httpReceiptOCR struct {
+	endpoint string
+	apiKey   string
+	client   *http.Client
+}
+
+type httpReceiptOCRResponse struct {
+	Vendor      *string          `json:"vendor"`
+	VendorTaxID *string          `json:"vendor_tax_id"`
+	Date        *string          `json:"date"` // YYYY-MM-DD
+	Total       *decimal.Decimal `json:"total"`
+	VATAmount   *decimal.Decimal `json:"vat_amount"`
+	VATRate     *decimal.Decimal `json:"vat_rate"`
+	Confidence  *decimal.Decimal `json:"confidence"`
+	Text        string           `json:"text"`
+}
+
+func (p *httpReceiptOCR) Name() string {
+	return "http"
+}
+
+func (p *httpReceiptOCR) Extract(ctx context.Context, data []byte, mimeType string) (*models.ReceiptExtraction, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", p.endpoint, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OCR request: %w", err)
+	}
+	req.Header.Set("Content-Type", mimeType)
+	req.Header.Set("Accept", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call OCR provider: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("OCR provider returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result httpReceiptOCRResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode OCR response: %w", err)
+	}
+
+	extraction := parseReceiptText(result.Text)
+	extraction.Text = result.Text
+	if result.Vendor != nil && strings.TrimSpace(*result.Vendor) != "" {
+		extraction.Vendor = result.Vendor
+	}
+	if result.VendorTaxID != nil && strings.TrimSpace(*result.VendorTaxID) != "" {
+		extraction.VendorTaxID = result.VendorTaxID
+	}
+	if result.Date != nil {
+		if d, err := time.Parse("2006-01-02", *result.Date); err == nil {
+			extraction.Date = &d
+		}
+	}
+	if result.Total != nil {
+		extraction.Total = result.Total
+	}
+	if result.VATAmount != nil {
+		extraction.VATAmount = result.VATAmount
+	}
+	if result.VATRate != nil {
+		extraction.VATRate = result.VATRate
+	}
+	extraction.Confidence = result.Confidence
+
+	return extraction, nil
+}
+
+var (
+	receiptTaxIDPattern   = regexp.MustCompile(`(?i)\b(?:NIF|NIPC|contribuinte|VAT)\b[^0-9\n]{0,15}(?:PT\s?)?(\d{9})\b`)
+	receiptISODatePattern = regexp.MustCompile(`\b(\d{4})-(\d{2})-(\d{2})\b`)
+	receiptDatePattern    = regexp.MustCompile(`\b(\d{2})[/.-](\d{2})[/.-](\d{4})\b`)
+	receiptAmountPattern  = regexp.MustCompile(`\d{1,3}(?:[. ]\d{3})+[.,]\d{2}\b|\d+[.,]\d{2}\b`)
+	receiptRatePattern    = regexp.MustCompile(`(\d{1,2}(?:[.,]\d{1,2})?)\s?%`)
+	receiptVATLinePattern = regexp.MustCompile(`(?i)\b(?:iva|vat)\b`)
+)
+
+// parseReceiptText reads the vendor, tax id, date, total and VAT of a Portuguese receipt from
+// its OCR text. Fields that can't be found are left nil.
+func parseReceiptText(text string) *models.ReceiptExtraction {
+	extraction := &models.ReceiptExtraction{}
+	if strings.TrimSpace(text) == "" {
+		return extraction
+	}
+
+	if m := receiptTaxIDPattern.FindStringSubmatch(text); m != nil {
+		extraction.VendorTaxID = &m[1]
+	}
+
+	if m := receiptISODatePattern.FindStringSubmatch(text); m != nil {
+		if d, err := time.Parse("2006-01-02", m[0]); err == nil {
+			extraction.Date = &d
+		}
+	}
+	if extraction.Date == nil {
+		if m := receiptDatePattern.FindStringSubmatch(text); m != nil {
+			if d, err := time.Parse("2006-01-02", m[3]+"-"+m[2]+"-"+m[1]); err == nil {
+				extraction.Date = &d
+			}
+		}
+	}
+
+	var vatTotal decimal.Decimal
+	var vatRate *decimal.Decimal
+	vatFound, mixedRates := false, false
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		lower := strings.ToLower(line)
+
+		isVATLine := receiptVATLinePattern.MatchString(line)
+		if extraction.Vendor == nil && !isVATLine && isReceiptVendorLine(lower) {
+			vendor := line
+			extraction.Vendor = &vendor
+		}
+
+		// Dates look like amounts (12.03.2024), drop them before reading amounts
+		clean := receiptDatePattern.ReplaceAllString(receiptISODatePattern.ReplaceAllString(line, ""), "")
+
+		switch {
+		case isVATLine:
+			if m := receiptRatePattern.FindStringSubmatch(clean); m != nil {
+				rate, err := parseLocaleAmount(m[1])
+				if err == nil {
+					if vatRate != nil && !vatRate.Equal(rate) {
+						mixedRates = true
+					}
+					vatRate = &rate
+				}
+				clean = strings.Replace(clean, m[0], "", 1)
+			}
+			amounts := receiptAmountPattern.FindAllString(clean, -1)
+			if len(amounts) == 0 {
+				continue
+			}
+			// A VAT summary line lists the base before the tax
+			if amount, err := parseLocaleAmount(amounts[len(amounts)-1]); err == nil {
+				vatTotal = vatTotal.Add(amount)
+				vatFound = true
+			}
+		case strings.Contains(lower, "total") && !strings.Contains(lower, "sub"):
+			for _, raw := range receiptAmountPattern.FindAllString(clean, -1) {
+				amount, err := parseLocaleAmount(raw)
+				if err != nil {
+					continue
+				}
+				if extraction.Total == nil || amount.GreaterThan(*extraction.Total) {
+					extraction.Total = &amount
+				}
+			}
+		}
+	}
+
+	if vatFound {
+		extraction.VATAmount = &vatTotal
+	}
+	if vatRate != nil && !mixedRates {
+		extraction.VATRate = vatRate
+	}
+
+	return extraction
+}
+
+// receiptHeaderWords appear in receipt lines that are not the vendor's name
+var receiptHeaderWords = []string{"fatura", "factura", "recibo", "nif", "nipc", "contribuinte", "data", "original", "duplicado", "talão", "talao", "total"}
+
+// isReceiptVendorLine reports whether a line can be the vendor's name, usually the first line
+// of the receipt with more letters than digits
+func isReceiptVendorLine(lower string) bool {
+	for _, word := range receiptHeaderWords {
+		if strings.Contains(lower, word) {
+			return false
+		}
+	}
+	letters, digits := 0, 0
+	for _, r := range lower {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case strings.ContainsRune(" .,-/:%", r):
+		default:
+			letters++
+		}
+	}
+	return letters >= 3 && letters > digits
+}
+
+// parseLocaleAmount parses an amount written with a decimal comma or point and optional
+// thousands separators
+func parseLocaleAmount(raw string) (decimal.Decimal, error) {
+	raw = strings.ReplaceAll(raw, " ", "")
+	if i := strings.LastIndexAny(raw, ".,"); i >= 0 {
+		raw = strings.NewReplacer(".", "", ",", "").Replace(raw[:i]) + "." + raw[i+1:]
+	}
+	return decimal.NewFromString(raw)
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"testing"
+)
+
+func TestParseReceiptText(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		vendor      string
+		taxID       string
+		date        string
+		total       string
+		vatAmount   string
+		vatRate     string
+		noVATAmount bool
+	}{
+		{
+			name: "simplified invoice",
+			text: `Clínica Privada Lda
+NIF: 501234567
+Fatura Simplificada FS 2024/123
+Data: 12.03.2024
+Consulta 20,00
+IVA 23% 4,60
+TOTAL 24,60 EUR`,
+			vendor:    "Clínica Privada Lda",
+			taxID:     "501234567",
+			date:      "2024-03-12",
+			total:     "24.6",
+			vatAmount: "4.6",
+			vatRate:   "23",
+		},
+		{
+			name: "mixed rates and thousands separator",
+			text: `Materiais Silva
+Contribuinte PT 509876543
+2024-05-02
+Taxa IVA 6% base 100,00 6,00
+Taxa IVA 23% base 1.000,00 230,00
+Subtotal 1.100,00
+Total a pagar 1.336,00`,
+			vendor:    "Materiais Silva",
+			taxID:     "509876543",
+			date:      "2024-05-02",
+			total:     "1336",
+			vatAmount: "236",
+		},
+		{
+			name:        "nothing readable",
+			text:        "",
+			noVATAmount: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseReceiptText(tt.text)
+
+			if tt.vendor == "" && got.Vendor != nil {
+				t.Errorf("vendor = %q, want none", *got.Vendor)
+			}
+			if tt.vendor != "" && (got.Vendor == nil || *got.Vendor != tt.vendor) {
+				t.Errorf("vendor = %v, want %q", got.Vendor, tt.vendor)
+			}
+			if tt.taxID != "" && (got.VendorTaxID == nil || *got.VendorTaxID != tt.taxID) {
+				t.Errorf("vendor tax id = %v, want %q", got.VendorTaxID, tt.taxID)
+			}
+			if tt.date != "" && (got.Date == nil || got.Date.Format("2006-01-02") != tt.date) {
+				t.Errorf("date = %v, want %s", got.Date, tt.date)
+			}
+			if tt.total != "" && (got.Total == nil || got.Total.String() != tt.total) {
+				t.Errorf("total = %v, want %s", got.Total, tt.total)
+			}
+			if tt.vatAmount != "" && (got.VATAmount == nil || got.VATAmount.String() != tt.vatAmount) {
+				t.Errorf("VAT amount = %v, want %s", got.VATAmount, tt.vatAmount)
+			}
+			if tt.noVATAmount && got.VATAmount != nil {
+				t.Errorf("VAT amount = %v, want none", got.VATAmount)
+			}
+			if tt.vatRate == "" && got.VATRate != nil {
+				t.Errorf("VAT rate = %v, want none", got.VATRate)
+			}
+			if tt.vatRate != "" && (got.VATRate == nil || got.VATRate.String() != tt.vatRate) {
+				t.Errorf("VAT rate = %v, want %s", got.VATRate, tt.vatRate)
+			}
+		})
+	}
+}
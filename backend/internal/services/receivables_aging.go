@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// Receivables aging buckets, by days past the due date
+const (
+	AgingBucket0To30  = "0_30"
+	AgingBucket31To60 = "31_60"
+	AgingBucket61To90 = "61_90"
+	AgingBucketOver90 = "90_plus"
+)
+
+// AgingBuckets lists the buckets in order
+var AgingBuckets = []string{AgingBucket0To30, AgingBucket31To60, AgingBucket61To90, AgingBucketOver90}
+
+// Receivable sources
+const (
+	ReceivableSessionPayment = "session_payment"
+	ReceivableProjectPayment = "project_payment"
+)
+
+// AgingFilters narrows the aging report down to a debtor or a bucket (drill-down)
+type AgingFilters struct {
+	DebtorID *uuid.UUID
+	Bucket   string
+	AsOf     *time.Time // day the receivables are reported as of, from their state history; today when nil
+}
+
+// AgingAmounts are outstanding amounts per bucket
+type AgingAmounts struct {
+	Days0To30  decimal.Decimal `json:"0_30"`
+	Days31To60 decimal.Decimal `json:"31_60"`
+	Days61To90 decimal.Decimal `json:"61_90"`
+	Over90     decimal.Decimal `json:"90_plus"`
+	Total      decimal.Decimal `json:"total"`
+}
+
+// add counts an amount in a bucket and the total
+func (a *AgingAmounts) add(bucket string, amount decimal.Decimal) {
+	switch bucket {
+	case AgingBucket0To30:
+		a.Days0To30 = a.Days0To30.Add(amount)
+	case AgingBucket31To60:
+		a.Days31To60 = a.Days31To60.Add(amount)
+	case AgingBucket61To90:
+		a.Days61To90 = a.Days61To90.Add(amount)
+	default:
+		a.Over90 = a.Over90.Add(amount)
+	}
+	a.Total = a.Total.Add(amount)
+}
+
+// ReceivableItem is an unpaid session or project payment
+type ReceivableItem struct {
+	Source      string          `json:"source"` // session_payment or project_payment
+	ID          uuid.UUID       `json:"id"`
+	Reference   string          `json:"reference"` // session date or project number
+	DueDate     time.Time       `json:"due_date"`
+	DaysOverdue int             `json:"days_overdue"` // 0 when not yet due
+	Bucket      string          `json:"bucket"`
+	Amount      decimal.Decimal `json:"amount"`
+}
+
+// AgingDebtor groups the receivables of a client or patient
+type AgingDebtor struct {
+	DebtorType string            `json:"debtor_type"` // client or patient
+	DebtorID   uuid.UUID         `json:"debtor_id"`
+	DebtorName string            `json:"debtor_name"`
+	Amounts    AgingAmounts      `json:"amounts"`
+	Items      []*ReceivableItem `json:"items"`
+}
+
+// ReceivablesAgingReport buckets outstanding receivables by age per debtor
+type ReceivablesAgingReport struct {
+	AsOf    time.Time      `json:"as_of"`
+	Totals  AgingAmounts   `json:"totals"`
+	Debtors []*AgingDebtor `json:"debtors"`
+}
+
+// agingBucket returns the bucket of a receivable that is the given days past due
+func agingBucket(daysOverdue int) string {
+	switch {
+	case daysOverdue <= 30:
+		return AgingBucket0To30
+	case daysOverdue <= 60:
+		return AgingBucket31To60
+	case daysOverdue <= 90:
+		return AgingBucket61To90
+	default:
+		return AgingBucketOver90
+	}
+}
+
+// receivablesAsOfQuery is the receivables query on the payments as they were at $4, read from
+// their state history
+var receivablesAsOfQuery = `
+	SELECT 'patient', p.id, COALESCE(pc.name, ''), $2::text, sp.entity_id, to_char((ss.state->>'scheduled_at')::timestamp, 'YYYY-MM-DD HH24:MI'),
+		COALESCE((sp.state->>'due_date')::date, (ss.state->>'scheduled_at')::date), (sp.state->>'amount_cents')::numeric / 100
+	FROM ` + stateAsOf("session_payment", "$4") + ` sp
+	JOIN ` + stateAsOf("session", "$4") + ` ss ON ss.entity_id = (sp.state->>'session_id')::uuid
+	JOIN patients p ON p.id = (ss.state->>'patient_id')::uuid
+	LEFT JOIN clients pc ON pc.id = p.client_id
+	WHERE sp.state->>'payment_status' IN ('unpaid', 'partial')
+	UNION ALL
+	SELECT 'client', c.id, c.name, $3::text, py.entity_id, pr.project_number, (py.state->>'due_date')::date, (py.state->>'amount')::numeric
+	FROM ` + stateAsOf("payment", "$4") + ` py
+	JOIN projects pr ON pr.id = (py.state->>'project_id')::uuid
+	JOIN budgets b ON b.id = pr.budget_id
+	JOIN worksheets w ON w.id = b.worksheet_id
+	JOIN clients c ON c.id = w.client_id
+	WHERE py.state->>'status' IN ('pending', 'overdue')`
+
+// ReceivablesAging buckets unpaid session payments (per patient) and project payments (per client)
+// by days past their due date. Session payments without a due date are due on the session day.
+// With AsOf, the payments are reported as they were at the end of that day.
+func (s *ReportService) ReceivablesAging(ctx context.Context, orgID uuid.UUID, filters AgingFilters) (*ReceivablesAgingReport, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if filters.AsOf != nil {
+		today = time.Date(filters.AsOf.Year(), filters.AsOf.Month(), filters.AsOf.Day(), 0, 0, 0, 0, time.UTC)
+		rows, err := s.db.Pool.Query(ctx, receivablesAsOfQuery,
+			orgID, ReceivableSessionPayment, ReceivableProjectPayment, today.AddDate(0, 0, 1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to query receivables: %w", err)
+		}
+		defer rows.Close()
+		return agingReport(rows, today, filters)
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT 'patient', p.id, COALESCE(pc.name, ''), $2::text, sp.id, to_char(s.scheduled_at, 'YYYY-MM-DD HH24:MI'),
+			COALESCE(sp.due_date, s.scheduled_at::date), sp.amount_cents::numeric / 100
+		FROM session_payments sp
+		JOIN sessions s ON s.id = sp.session_id
+		JOIN patients p ON p.id = s.patient_id
+		LEFT JOIN clients pc ON pc.id = p.client_id
+		WHERE s.organization_id = $1 AND s.deleted_at IS NULL
+			AND sp.payment_status IN ('unpaid', 'partial')
+		UNION ALL
+		SELECT 'client', c.id, c.name, $3::text, py.id, pr.project_number, py.due_date, py.amount
+		FROM payments py
+		JOIN projects pr ON pr.id = py.project_id
+		JOIN budgets b ON b.id = pr.budget_id
+		JOIN worksheets w ON w.id = b.worksheet_id
+		JOIN clients c ON c.id = w.client_id
+		WHERE py.organization_id = $1 AND py.deleted_at IS NULL
+			AND py.status IN ('pending', 'overdue')
+	`, orgID, ReceivableSessionPayment, ReceivableProjectPayment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query receivables: %w", err)
+	}
+	defer rows.Close()
+
+	return agingReport(rows, today, filters)
+}
+
+// agingReport buckets the receivables read from rows by their age on the given day
+func agingReport(rows pgx.Rows, today time.Time, filters AgingFilters) (*ReceivablesAgingReport, error) {
+	report := &ReceivablesAgingReport{AsOf: today, Debtors: []*AgingDebtor{}}
+	debtors := make(map[uuid.UUID]*AgingDebtor)
+
+	for rows.Next() {
+		var debtor AgingDebtor
+		var item ReceivableItem
+		if err := rows.Scan(
+			&debtor.DebtorType, &debtor.DebtorID, &debtor.DebtorName,
+			&item.Source, &item.ID, &item.Reference, &item.DueDate, &item.Amount,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan receivable: %w", err)
+		}
+		if filters.DebtorID != nil && debtor.DebtorID != *filters.DebtorID {
+			continue
+		}
+
+		item.DaysOverdue = max(int(today.Sub(item.DueDate).Hours()/24), 0)
+		item.Bucket = agingBucket(item.DaysOverdue)
+		if filters.Bucket != "" && item.Bucket != filters.Bucket {
+			continue
+		}
+
+		d, ok := debtors[debtor.DebtorID]
+		if !ok {
+			d = &debtor
+			d.Items = []*ReceivableItem{}
+			debtors[debtor.DebtorID] = d
+			report.Debtors = append(report.Debtors, d)
+		}
+		d.Items = append(d.Items, &item)
+		d.Amounts.add(item.Bucket, item.Amount)
+		report.Totals.add(item.Bucket, item.Amount)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read receivables: %w", err)
+	}
+
+	// Largest debts first, oldest items first
+	sort.Slice(report.Debtors, func(i, j int) bool {
+		return report.Debtors[i].Amounts.Total.GreaterThan(report.Debtors[j].Amounts.Total)
+	})
+	for _, d := range report.Debtors {
+		sort.Slice(d.Items, func(i, j int) bool { return d.Items[i].DueDate.Before(d.Items[j].DueDate) })
+	}
+
+	return report, nil
+}
+
+// WriteCSV writes the report with one row per receivable
+func (r *ReceivablesAgingReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{
+		"debtor_type", "debtor_name", "source", "reference", "due_date", "days_overdue", "bucket", "amount",
+	}); err != nil {
+		return err
+	}
+	for _, d := range r.Debtors {
+		for _, item := range d.Items {
+			err := writer.Write([]string{
+				d.DebtorType, d.DebtorName, item.Source, item.Reference, item.DueDate.Format("2006-01-02"),
+				fmt.Sprint(item.DaysOverdue), item.Bucket, item.Amount.StringFixed(2),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
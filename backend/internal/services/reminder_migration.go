@@ -0,0 +1,353 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/i18n"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ============ Legacy Reminder Migration ============
+
+// legacyReminders are the reminders of notification_configs and the templates replacing them
+var legacyReminders = []struct {
+	reminderType  models.ReminderType
+	offsetMinutes int
+	templateName  string
+}{
+	{models.ReminderType24h, 24 * 60, "Legacy 24h Reminder"},
+	{models.ReminderType2h, 2 * 60, "Legacy 2h Reminder"},
+}
+
+// legacyReminderStates are the session statuses the legacy reminders were sent in
+var legacyReminderStates = []string{string(models.SessionStatusPending), string(models.SessionStatusConfirmed)}
+
+// legacyReminderVariables renames the variables of the legacy templates to the workflow ones
+var legacyReminderVariables = strings.NewReplacer(
+	"{{therapist}}", "{{therapist_name}}",
+	"{{date}}", "{{session_date}}",
+	"{{time}}", "{{session_time}}",
+)
+
+// legacyReminderConfig returns the reminder settings of the organization's notification config
+func (s *WorkflowService) legacyReminderConfig(ctx context.Context, orgID uuid.UUID) (*models.NotificationConfig, error) {
+	var config models.NotificationConfig
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT whatsapp_enabled, reminder_24h_enabled, reminder_2h_enabled,
+			reminder_24h_template, reminder_2h_template, reminders_migrated_at, reminders_workflow_id
+		FROM notification_configs
+		WHERE organization_id = $1
+	`, orgID).Scan(
+		&config.WhatsAppEnabled,
+		&config.Reminder24hEnabled,
+		&config.Reminder2hEnabled,
+		&config.Reminder24hTemplate,
+		&config.Reminder2hTemplate,
+		&config.RemindersMigratedAt,
+		&config.RemindersWorkflowID,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("notification config not found")
+		}
+		return nil, fmt.Errorf("failed to get notification config: %w", err)
+	}
+	return &config, nil
+}
+
+// PlanReminderMigration shows how the organization's legacy 24h/2h reminders would be converted
+// into session workflow triggers, without changing anything
+func (s *WorkflowService) PlanReminderMigration(ctx context.Context, orgID uuid.UUID) (*models.ReminderMigration, error) {
+	config, err := s.legacyReminderConfig(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	workflow, err := s.GetDefaultWorkflow(ctx, orgID, models.WorkflowModuleAppointments, models.WorkflowEntitySession)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := planReminderMigration(i18n.FromContext(ctx), config, workflow)
+	plan.DryRun = true
+	if config.RemindersMigratedAt == nil {
+		err = s.db.Pool.QueryRow(ctx, `
+			SELECT COUNT(*) FROM scheduled_reminders r
+			JOIN sessions s ON s.id = r.session_id
+			WHERE s.organization_id = $1 AND r.status = 'pending' AND r.scheduled_for > NOW()
+		`, orgID).Scan(&plan.PendingReminders)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count pending reminders: %w", err)
+		}
+	}
+	return plan, nil
+}
+
+// MigrateReminders replaces the organization's legacy reminders with time_before triggers of the
+// default session workflow, created when missing. Reminders still to be sent are moved to workflow
+// jobs and sessions stop getting legacy reminders.
+func (s *WorkflowService) MigrateReminders(ctx context.Context, orgID uuid.UUID) (*models.ReminderMigration, error) {
+	config, err := s.legacyReminderConfig(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if config.RemindersMigratedAt != nil {
+		return nil, errors.New("reminders were already migrated")
+	}
+
+	// Claim the migration first, so sessions scheduled meanwhile no longer get legacy reminders
+	var migratedAt time.Time
+	err = s.db.Pool.QueryRow(ctx, `
+		UPDATE notification_configs SET reminders_migrated_at = NOW(), updated_at = CURRENT_TIMESTAMP
+		WHERE organization_id = $1 AND reminders_migrated_at IS NULL
+		RETURNING reminders_migrated_at
+	`, orgID).Scan(&migratedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("reminders were already migrated")
+		}
+		return nil, fmt.Errorf("failed to update notification config: %w", err)
+	}
+	done := false
+	defer func() {
+		if !done {
+			s.db.Pool.Exec(context.WithoutCancel(ctx), `
+				UPDATE notification_configs SET reminders_migrated_at = NULL WHERE organization_id = $1
+			`, orgID)
+		}
+	}()
+
+	locale := i18n.FromContext(ctx)
+	workflow, err := s.GetDefaultWorkflow(ctx, orgID, models.WorkflowModuleAppointments, models.WorkflowEntitySession)
+	if err != nil {
+		return nil, err
+	}
+	if workflow == nil {
+		if workflow, err = s.CreateDefaultSessionWorkflow(ctx, orgID); err != nil {
+			return nil, err
+		}
+	}
+
+	migration := planReminderMigration(locale, config, workflow)
+	migration.MigratedAt = &migratedAt
+
+	// Create the templates and triggers, remembering which trigger now sends each reminder
+	templates := make(map[models.ReminderType]uuid.UUID)
+	triggers := make(map[string]uuid.UUID)
+	for i := range migration.Steps {
+		step := &migration.Steps[i]
+		if step.Status != models.ReminderMigrationCreate {
+			continue
+		}
+
+		templateID, ok := templates[step.ReminderType]
+		if !ok {
+			if templateID, err = s.legacyReminderTemplate(ctx, orgID, locale, step.TemplateName, step.Body); err != nil {
+				return nil, err
+			}
+			templates[step.ReminderType] = templateID
+		}
+
+		stateID := workflowStateID(workflow, step.State)
+		offset := step.OffsetMinutes
+		trigger := &models.WorkflowTrigger{
+			WorkflowID:        workflow.ID,
+			StateID:           stateID,
+			TriggerType:       models.TriggerTypeTimeBefore,
+			TimeOffsetMinutes: &offset,
+		}
+		if err := s.CreateTrigger(ctx, trigger); err != nil {
+			return nil, fmt.Errorf("failed to create %s trigger: %w", step.ReminderType, err)
+		}
+		action := &models.WorkflowAction{
+			TriggerID:  trigger.ID,
+			ActionType: models.ActionTypeSendWhatsApp,
+			TemplateID: &templateID,
+		}
+		if err := s.CreateAction(ctx, action); err != nil {
+			return nil, fmt.Errorf("failed to create %s action: %w", step.ReminderType, err)
+		}
+		step.TriggerID = &trigger.ID
+		triggers[step.State+"/"+string(step.ReminderType)] = trigger.ID
+	}
+
+	// Hand the reminders still to be sent over to the new triggers
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT r.id, r.session_id, r.type, r.scheduled_for, s.status
+		FROM scheduled_reminders r
+		JOIN sessions s ON s.id = r.session_id
+		WHERE s.organization_id = $1 AND r.status = 'pending'
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending reminders: %w", err)
+	}
+	type pendingReminder struct {
+		id, sessionID uuid.UUID
+		reminderType  models.ReminderType
+		scheduledFor  time.Time
+		sessionStatus string
+	}
+	var pending []pendingReminder
+	for rows.Next() {
+		var r pendingReminder
+		if err := rows.Scan(&r.id, &r.sessionID, &r.reminderType, &r.scheduledFor, &r.sessionStatus); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan pending reminder: %w", err)
+		}
+		pending = append(pending, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list pending reminders: %w", err)
+	}
+
+	for _, r := range pending {
+		if triggerID, ok := triggers[r.sessionStatus+"/"+string(r.reminderType)]; ok && r.scheduledFor.After(time.Now()) {
+			if err := s.scheduleJob(ctx, orgID, triggerID, "session", r.sessionID, r.scheduledFor); err != nil {
+				return nil, fmt.Errorf("failed to schedule reminder: %w", err)
+			}
+			migration.PendingReminders++
+		}
+		_, err := s.db.Pool.Exec(ctx, `
+			UPDATE scheduled_reminders
+			SET status = 'skipped', processed_at = NOW(), error_message = 'migrated to workflow'
+			WHERE id = $1 AND status = 'pending'
+		`, r.id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update reminder: %w", err)
+		}
+	}
+
+	_, err = s.db.Pool.Exec(ctx, `
+		UPDATE notification_configs SET reminders_workflow_id = $1 WHERE organization_id = $2
+	`, workflow.ID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update notification config: %w", err)
+	}
+
+	done = true
+	return migration, nil
+}
+
+// planReminderMigration lists the triggers replacing the legacy reminders. Reminders that were not
+// being sent are skipped, as are those the workflow already sends with a WhatsApp message at the same offset.
+func planReminderMigration(locale string, config *models.NotificationConfig, workflow *models.Workflow) *models.ReminderMigration {
+	plan := &models.ReminderMigration{
+		MigratedAt:      config.RemindersMigratedAt,
+		CreatesWorkflow: workflow == nil,
+		WorkflowName:    i18n.T(locale, "Session Lifecycle"),
+		Steps:           make([]models.ReminderMigrationStep, 0, len(legacyReminders)*len(legacyReminderStates)),
+	}
+	if workflow != nil {
+		plan.WorkflowID = &workflow.ID
+		plan.WorkflowName = workflow.Name
+	}
+
+	for _, reminder := range legacyReminders {
+		template, skipReason := reminderTemplate(config, reminder.reminderType)
+		if skipReason == "" && !config.WhatsAppEnabled {
+			skipReason = "WhatsApp notifications disabled"
+		}
+
+		for _, state := range legacyReminderStates {
+			step := models.ReminderMigrationStep{
+				ReminderType:  reminder.reminderType,
+				State:         state,
+				OffsetMinutes: reminder.offsetMinutes,
+				Status:        models.ReminderMigrationCreate,
+			}
+			reason := skipReason
+			if reason == "" && workflow != nil {
+				stateID := workflowStateID(workflow, state)
+				if stateID == nil {
+					reason = fmt.Sprintf("workflow has no %s state", state)
+				} else if hasWhatsAppReminder(workflow, *stateID, reminder.offsetMinutes) {
+					step.Status = models.ReminderMigrationExisting
+				}
+			}
+			if reason != "" {
+				step.Status = models.ReminderMigrationSkipped
+				step.Reason = &reason
+			}
+			if step.Status == models.ReminderMigrationCreate {
+				step.TemplateName = i18n.T(locale, reminder.templateName)
+				step.Body = legacyReminderVariables.Replace(template)
+			}
+			plan.Steps = append(plan.Steps, step)
+		}
+	}
+	return plan
+}
+
+// workflowStateID returns the ID of the workflow state with the given name
+func workflowStateID(workflow *models.Workflow, name string) *uuid.UUID {
+	for i := range workflow.States {
+		if workflow.States[i].Name == name {
+			return &workflow.States[i].ID
+		}
+	}
+	return nil
+}
+
+// hasWhatsAppReminder reports whether a time_before trigger of the state already sends a WhatsApp
+// message at the offset
+func hasWhatsAppReminder(workflow *models.Workflow, stateID uuid.UUID, offsetMinutes int) bool {
+	for _, trigger := range workflow.Triggers {
+		if trigger.StateID == nil || *trigger.StateID != stateID || trigger.TriggerType != models.TriggerTypeTimeBefore ||
+			trigger.TimeOffsetMinutes == nil || *trigger.TimeOffsetMinutes != offsetMinutes || !trigger.IsActive {
+			continue
+		}
+		for _, action := range trigger.Actions {
+			if action.ActionType == models.ActionTypeSendWhatsApp && action.IsActive {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// legacyReminderTemplate returns the WhatsApp template holding a converted legacy reminder,
+// creating it unless a previous migration attempt already did
+func (s *WorkflowService) legacyReminderTemplate(ctx context.Context, orgID uuid.UUID, locale, name, body string) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT id FROM message_templates WHERE organization_id = $1 AND name = $2 AND channel = $3
+	`, orgID, name, models.MessageChannelWhatsApp).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, fmt.Errorf("failed to check template existence: %w", err)
+	}
+
+	vars := []models.TemplateVariable{
+		{Name: "patient_name", Description: i18n.T(locale, "Patient name")},
+		{Name: "session_date", Description: i18n.T(locale, "Session date")},
+		{Name: "session_time", Description: i18n.T(locale, "Session time")},
+		{Name: "therapist_name", Description: i18n.T(locale, "Therapist name")},
+	}
+	varsJSON, err := json.Marshal(vars)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to marshal variables: %w", err)
+	}
+
+	template := &models.MessageTemplate{
+		OrganizationID: orgID,
+		Name:           name,
+		Channel:        models.MessageChannelWhatsApp,
+		Body:           body,
+		Variables:      varsJSON,
+		IsActive:       true,
+	}
+	if err := s.CreateTemplate(ctx, template); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create template %s: %w", name, err)
+	}
+	return template.ID, nil
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// ReportService handles report generation
+type ReportService struct {
+	db *database.DB
+}
+
+func NewReportService(db *database.DB) *ReportService {
+	return &ReportService{db: db}
+}
+
+// ============ Budget Conversion ============
+
+// DefaultValueBands are the budget total boundaries used when none are given
+var DefaultValueBands = []decimal.Decimal{
+	decimal.NewFromInt(5000),
+	decimal.NewFromInt(20000),
+	decimal.NewFromInt(50000),
+}
+
+// ConversionFilters limits the budgets included in the conversion report
+type ConversionFilters struct {
+	From       *time.Time        // budgets sent on or after
+	To         *time.Time        // budgets sent before
+	ValueBands []decimal.Decimal // ascending band boundaries
+}
+
+// ConversionStats aggregates decided budgets for a group
+type ConversionStats struct {
+	Key        string          `json:"key"`
+	Won        int             `json:"won"`
+	Lost       int             `json:"lost"`
+	Expired    int             `json:"expired"`
+	Open       int             `json:"open"`
+	WinRate    float64         `json:"win_rate"` // won / (won + lost + expired)
+	WonValue   decimal.Decimal `json:"won_value"`
+	LostValue  decimal.Decimal `json:"lost_value"` // rejected and expired
+	TotalValue decimal.Decimal `json:"total_value"`
+}
+
+// ConversionReport is the budget pipeline conversion report
+type ConversionReport struct {
+	Totals       ConversionStats   `json:"totals"`
+	ByLossReason []ConversionStats `json:"by_loss_reason"`
+	BySegment    []ConversionStats `json:"by_segment"`
+	ByValueBand  []ConversionStats `json:"by_value_band"`
+}
+
+// BudgetConversion aggregates win rates of sent budgets by loss reason, client segment and value band
+func (s *ReportService) BudgetConversion(ctx context.Context, orgID uuid.UUID, filters ConversionFilters) (*ConversionReport, error) {
+	bands := filters.ValueBands
+	if len(bands) == 0 {
+		bands = DefaultValueBands
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT b.status, b.total, COALESCE(b.loss_reason, ''), COALESCE(NULLIF(c.segment, ''), 'unspecified')
+		FROM budgets b
+		LEFT JOIN worksheets w ON w.id = b.worksheet_id
+		LEFT JOIN clients c ON c.id = w.client_id
+		WHERE b.organization_id = $1 AND b.deleted_at IS NULL
+		AND b.status IN ('sent', 'approved', 'rejected', 'expired')
+		AND ($2::timestamptz IS NULL OR b.sent_at >= $2)
+		AND ($3::timestamptz IS NULL OR b.sent_at < $3)
+	`, orgID, filters.From, filters.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query budgets: %w", err)
+	}
+	defer rows.Close()
+
+	report := &ConversionReport{Totals: ConversionStats{Key: "all"}}
+	byReason := make(map[string]*ConversionStats)
+	bySegment := make(map[string]*ConversionStats)
+	byBand := make(map[string]*ConversionStats)
+	bandOrder := make(map[string]int)
+
+	for rows.Next() {
+		var status, lossReason, segment string
+		var total decimal.Decimal
+		if err := rows.Scan(&status, &total, &lossReason, &segment); err != nil {
+			return nil, fmt.Errorf("failed to scan budget: %w", err)
+		}
+
+		bandIndex, band := valueBand(total, bands)
+		bandOrder[band] = bandIndex
+
+		groups := []*ConversionStats{
+			&report.Totals,
+			conversionGroup(bySegment, segment),
+			conversionGroup(byBand, band),
+		}
+		// Loss reasons only apply to lost budgets
+		if status == "rejected" || status == "expired" {
+			if lossReason == "" {
+				lossReason = "unspecified"
+			}
+			groups = append(groups, conversionGroup(byReason, lossReason))
+		}
+
+		for _, g := range groups {
+			g.add(status, total)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read budgets: %w", err)
+	}
+
+	report.Totals.computeWinRate()
+	report.ByLossReason = sortedConversionGroups(byReason, func(a, b ConversionStats) bool { return a.Lost+a.Expired > b.Lost+b.Expired })
+	report.BySegment = sortedConversionGroups(bySegment, func(a, b ConversionStats) bool { return a.Key < b.Key })
+	report.ByValueBand = sortedConversionGroups(byBand, func(a, b ConversionStats) bool { return bandOrder[a.Key] < bandOrder[b.Key] })
+
+	return report, nil
+}
+
+// add counts a budget in the group
+func (c *ConversionStats) add(status string, total decimal.Decimal) {
+	c.TotalValue = c.TotalValue.Add(total)
+	switch status {
+	case "approved":
+		c.Won++
+		c.WonValue = c.WonValue.Add(total)
+	case "rejected":
+		c.Lost++
+		c.LostValue = c.LostValue.Add(total)
+	case "expired":
+		c.Expired++
+		c.LostValue = c.LostValue.Add(total)
+	default:
+		c.Open++
+	}
+}
+
+// computeWinRate sets the win rate over decided budgets
+func (c *ConversionStats) computeWinRate() {
+	decided := c.Won + c.Lost + c.Expired
+	if decided == 0 {
+		c.WinRate = 0
+		return
+	}
+	c.WinRate = float64(c.Won) / float64(decided)
+}
+
+// conversionGroup returns the group for a key, creating it if needed
+func conversionGroup(groups map[string]*ConversionStats, key string) *ConversionStats {
+	g, ok := groups[key]
+	if !ok {
+		g = &ConversionStats{Key: key}
+		groups[key] = g
+	}
+	return g
+}
+
+// sortedConversionGroups computes win rates and returns the groups in order
+func sortedConversionGroups(groups map[string]*ConversionStats, less func(a, b ConversionStats) bool) []ConversionStats {
+	result := make([]ConversionStats, 0, len(groups))
+	for _, g := range groups {
+		g.computeWinRate()
+		result = append(result, *g)
+	}
+	sort.Slice(result, func(i, j int) bool { return less(result[i], result[j]) })
+	return result
+}
+
+// valueBand returns the index and label of the band a total falls in, e.g. "5000-20000" or "50000+"
+func valueBand(total decimal.Decimal, bands []decimal.Decimal) (int, string) {
+	lower := decimal.Zero
+	for i, upper := range bands {
+		if total.LessThan(upper) {
+			return i, fmt.Sprintf("%s-%s", lower.String(), upper.String())
+		}
+		lower = upper
+	}
+	return len(bands), fmt.Sprintf("%s+", lower.String())
+}
+
+// ============ Message Spend ============
+
+// MessageSpend returns the organization's message cost per month and channel over the last
+// months, with the current month's spend against the monthly cap
+func (s *ReportService) MessageSpend(ctx context.Context, orgID uuid.UUID, months int) (*models.MessageSpendReport, error) {
+	if months <= 0 {
+		months = 6
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT to_char(date_trunc('month', created_at), 'YYYY-MM'), channel, COUNT(*), COALESCE(SUM(cost), 0)
+		FROM message_costs
+		WHERE organization_id = $1 AND created_at >= date_trunc('month', NOW()) - make_interval(months => $2 - 1)
+		GROUP BY 1, 2
+		ORDER BY 1 DESC, 2
+	`, orgID, months)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query message spend: %w", err)
+	}
+	defer rows.Close()
+
+	report := &models.MessageSpendReport{
+		Months:   []models.MessageSpendMonth{},
+		Currency: models.MessageCostCurrency,
+	}
+	currentMonth := time.Now().Format("2006-01")
+	for rows.Next() {
+		var m models.MessageSpendMonth
+		if err := rows.Scan(&m.Month, &m.Channel, &m.Messages, &m.Cost); err != nil {
+			return nil, fmt.Errorf("failed to scan message spend: %w", err)
+		}
+		if m.Month == currentMonth {
+			report.CurrentSpend = report.CurrentSpend.Add(m.Cost)
+		}
+		report.Months = append(report.Months, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read message spend: %w", err)
+	}
+
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT monthly_message_cap FROM notification_configs WHERE organization_id = $1
+	`, orgID).Scan(&report.MonthlyCap)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get monthly message cap: %w", err)
+	}
+	report.CapReached = report.MonthlyCap != nil && report.CurrentSpend.GreaterThanOrEqual(*report.MonthlyCap)
+
+	return report, nil
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============ As-Of Reports ============
+
+// stateAsOf selects the states of an entity type that were valid at the given timestamp
+// parameter, for the organization in $1. Rows have entity_id and state (JSONB) columns.
+func stateAsOf(entityType, at string) string {
+	return fmt.Sprintf(`(
+		SELECT entity_id, state FROM entity_state_history
+		WHERE organization_id = $1 AND entity_type = '%s'
+			AND valid_from <= %s AND (valid_to IS NULL OR valid_to > %s)
+	)`, entityType, at, at)
+}
+
+// SessionStatusFilters limits the sessions by status report to the sessions scheduled in
+// [From, To), counted with the status they had at the end of AsOf (now when nil)
+type SessionStatusFilters struct {
+	From time.Time
+	To   time.Time
+	AsOf *time.Time
+}
+
+// SessionStatusCounts counts sessions per status
+type SessionStatusCounts struct {
+	ByStatus map[string]int `json:"by_status"`
+	Total    int            `json:"total"`
+}
+
+func (c *SessionStatusCounts) add(status string, n int) {
+	if c.ByStatus == nil {
+		c.ByStatus = make(map[string]int)
+	}
+	c.ByStatus[status] += n
+	c.Total += n
+}
+
+// TherapistSessionStatus counts a therapist's sessions per status
+type TherapistSessionStatus struct {
+	TherapistID   uuid.UUID `json:"therapist_id"`
+	TherapistName string    `json:"therapist_name"`
+	SessionStatusCounts
+}
+
+// SessionStatusReport counts the sessions of a period per status, as they were on a given day
+type SessionStatusReport struct {
+	From       time.Time                 `json:"from"`
+	To         time.Time                 `json:"to"`
+	AsOf       *time.Time                `json:"as_of"` // nil for the current statuses
+	Totals     SessionStatusCounts       `json:"totals"`
+	Therapists []*TherapistSessionStatus `json:"therapists"`
+}
+
+// SessionsByStatus counts the sessions scheduled in the period per status and therapist, with
+// the status and schedule each session had at the end of the AsOf day
+func (s *ReportService) SessionsByStatus(ctx context.Context, orgID uuid.UUID, filters SessionStatusFilters) (*SessionStatusReport, error) {
+	at := time.Now()
+	if filters.AsOf != nil {
+		at = filters.AsOf.AddDate(0, 0, 1)
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT t.id, t.name, COALESCE(h.state->>'status', 'pending'), COUNT(*)
+		FROM `+stateAsOf("session", "$2")+` h
+		JOIN therapists t ON t.id = (h.state->>'therapist_id')::uuid
+		WHERE (h.state->>'scheduled_at')::timestamp >= $3 AND (h.state->>'scheduled_at')::timestamp < $4
+		GROUP BY t.id, t.name, 3
+	`, orgID, at, filters.From, filters.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions by status: %w", err)
+	}
+	defer rows.Close()
+
+	report := &SessionStatusReport{
+		From:       filters.From,
+		To:         filters.To,
+		AsOf:       filters.AsOf,
+		Therapists: []*TherapistSessionStatus{},
+	}
+	therapists := make(map[uuid.UUID]*TherapistSessionStatus)
+	for rows.Next() {
+		var therapistID uuid.UUID
+		var therapistName, status string
+		var count int
+		if err := rows.Scan(&therapistID, &therapistName, &status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan session status: %w", err)
+		}
+		t, ok := therapists[therapistID]
+		if !ok {
+			t = &TherapistSessionStatus{TherapistID: therapistID, TherapistName: therapistName}
+			therapists[therapistID] = t
+			report.Therapists = append(report.Therapists, t)
+		}
+		t.add(status, count)
+		report.Totals.add(status, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read sessions by status: %w", err)
+	}
+
+	sort.Slice(report.Therapists, func(i, j int) bool {
+		return report.Therapists[i].TherapistName < report.Therapists[j].TherapistName
+	})
+	return report, nil
+}
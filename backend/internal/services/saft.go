@@ -0,0 +1,226 @@
+package services
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+// The SAF-T (PT) file of an export bundle follows the 1.04_01 schema with tax accounting basis
+// "R" (receipts): controlwise issues no invoices, so the file carries the customers and the
+// payments received in the month. The accountant's software reconciles them with the invoices
+// issued elsewhere.
+const (
+	saftNamespace      = "urn:OECD:StandardAuditFile-Tax:PT_1.04_01"
+	saftVersion        = "1.04_01"
+	saftUnknown        = "Desconhecido"
+	saftFinalConsumer  = "999999990" // tax id of customers who gave none
+	saftProductID      = "controlwise/controlwise"
+	saftProductVersion = "1.0"
+)
+
+type saftAuditFile struct {
+	XMLName         xml.Name            `xml:"AuditFile"`
+	Namespace       string              `xml:"xmlns,attr"`
+	Header          saftHeader          `xml:"Header"`
+	Customers       []saftCustomer      `xml:"MasterFiles>Customer"`
+	SourceDocuments saftSourceDocuments `xml:"SourceDocuments"`
+}
+
+type saftHeader struct {
+	AuditFileVersion          string      `xml:"AuditFileVersion"`
+	CompanyID                 string      `xml:"CompanyID"`
+	TaxRegistrationNumber     string      `xml:"TaxRegistrationNumber"`
+	TaxAccountingBasis        string      `xml:"TaxAccountingBasis"`
+	CompanyName               string      `xml:"CompanyName"`
+	CompanyAddress            saftAddress `xml:"CompanyAddress"`
+	FiscalYear                int         `xml:"FiscalYear"`
+	StartDate                 string      `xml:"StartDate"`
+	EndDate                   string      `xml:"EndDate"`
+	CurrencyCode              string      `xml:"CurrencyCode"`
+	DateCreated               string      `xml:"DateCreated"`
+	TaxEntity                 string      `xml:"TaxEntity"`
+	ProductCompanyTaxID       string      `xml:"ProductCompanyTaxID"`
+	SoftwareCertificateNumber string      `xml:"SoftwareCertificateNumber"`
+	ProductID                 string      `xml:"ProductID"`
+	ProductVersion            string      `xml:"ProductVersion"`
+	Email                     string      `xml:"Email,omitempty"`
+}
+
+type saftAddress struct {
+	AddressDetail string `xml:"AddressDetail"`
+	City          string `xml:"City"`
+	PostalCode    string `xml:"PostalCode"`
+	Country       string `xml:"Country"`
+}
+
+type saftCustomer struct {
+	CustomerID           string      `xml:"CustomerID"`
+	AccountID            string      `xml:"AccountID"`
+	CustomerTaxID        string      `xml:"CustomerTaxID"`
+	CompanyName          string      `xml:"CompanyName"`
+	BillingAddress       saftAddress `xml:"BillingAddress"`
+	SelfBillingIndicator int         `xml:"SelfBillingIndicator"`
+}
+
+type saftSourceDocuments struct {
+	Payments saftPayments `xml:"Payments"`
+}
+
+type saftPayments struct {
+	NumberOfEntries int           `xml:"NumberOfEntries"`
+	TotalDebit      string        `xml:"TotalDebit"`
+	TotalCredit     string        `xml:"TotalCredit"`
+	Payments        []saftPayment `xml:"Payment"`
+}
+
+type saftPayment struct {
+	PaymentRefNo    string `xml:"PaymentRefNo"`
+	TransactionDate string `xml:"TransactionDate"`
+	PaymentType     string `xml:"PaymentType"`
+	DocumentStatus  struct {
+		PaymentStatus     string `xml:"PaymentStatus"`
+		PaymentStatusDate string `xml:"PaymentStatusDate"`
+		SourceID          string `xml:"SourceID"`
+		SourcePayment     string `xml:"SourcePayment"`
+	} `xml:"DocumentStatus"`
+	PaymentMethod struct {
+		PaymentMechanism string `xml:"PaymentMechanism"`
+		PaymentAmount    string `xml:"PaymentAmount"`
+		PaymentDate      string `xml:"PaymentDate"`
+	} `xml:"PaymentMethod"`
+	SourceID        string `xml:"SourceID"`
+	SystemEntryDate string `xml:"SystemEntryDate"`
+	CustomerID      string `xml:"CustomerID"`
+	Line            struct {
+		LineNumber   int    `xml:"LineNumber"`
+		CreditAmount string `xml:"CreditAmount"`
+	} `xml:"Line"`
+	DocumentTotals struct {
+		TaxPayable string `xml:"TaxPayable"`
+		NetTotal   string `xml:"NetTotal"`
+		GrossTotal string `xml:"GrossTotal"`
+	} `xml:"DocumentTotals"`
+}
+
+// saftPaymentMechanisms maps payment methods to SAF-T payment mechanism codes; others,
+// such as MB WAY and insurance, are reported as "OU" (other)
+var saftPaymentMechanisms = map[models.PaymentMethod]string{
+	models.PaymentMethodCash:     "NU",
+	models.PaymentMethodTransfer: "TB",
+	models.PaymentMethodCard:     "CC",
+}
+
+func saftPaymentMechanism(method *string) string {
+	if method != nil {
+		if code, ok := saftPaymentMechanisms[models.PaymentMethod(strings.ToLower(*method))]; ok {
+			return code
+		}
+	}
+	return "OU"
+}
+
+// writeSAFT writes the SAF-T (PT) file of a month's payments received
+func writeSAFT(w io.Writer, org *models.Organization, period time.Time, payments []*models.AccountantEntry, now time.Time) error {
+	taxID := org.TaxID
+	if taxID == "" {
+		taxID = saftFinalConsumer
+	}
+	address := org.Address
+	if address == "" {
+		address = saftUnknown
+	}
+	end := period.AddDate(0, 1, -1)
+
+	file := saftAuditFile{
+		Namespace: saftNamespace,
+		Header: saftHeader{
+			AuditFileVersion:          saftVersion,
+			CompanyID:                 taxID,
+			TaxRegistrationNumber:     taxID,
+			TaxAccountingBasis:        "R",
+			CompanyName:               org.Name,
+			CompanyAddress:            saftAddress{AddressDetail: address, City: saftUnknown, PostalCode: saftUnknown, Country: "PT"},
+			FiscalYear:                period.Year(),
+			StartDate:                 period.Format("2006-01-02"),
+			EndDate:                   end.Format("2006-01-02"),
+			CurrencyCode:              "EUR",
+			DateCreated:               now.Format("2006-01-02"),
+			TaxEntity:                 "Global",
+			ProductCompanyTaxID:       taxID,
+			SoftwareCertificateNumber: "0",
+			ProductID:                 saftProductID,
+			ProductVersion:            saftProductVersion,
+			Email:                     org.Email,
+		},
+	}
+
+	customers := map[string]bool{}
+	total := decimal.Zero
+	for i, p := range payments {
+		customerID := "CF" // final consumer
+		if p.PartyID != nil {
+			customerID = p.PartyID.String()
+		}
+		if !customers[customerID] {
+			customers[customerID] = true
+			customer := saftCustomer{
+				CustomerID:     customerID,
+				AccountID:      saftUnknown,
+				CustomerTaxID:  saftFinalConsumer,
+				CompanyName:    "Consumidor final",
+				BillingAddress: saftAddress{AddressDetail: saftUnknown, City: saftUnknown, PostalCode: saftUnknown, Country: saftUnknown},
+			}
+			if p.PartyID != nil {
+				customer.CompanyName = p.Party
+				if p.PartyTaxID != nil && *p.PartyTaxID != "" {
+					customer.CustomerTaxID = *p.PartyTaxID
+				}
+			}
+			file.Customers = append(file.Customers, customer)
+		}
+
+		amount := p.Amount.StringFixed(2)
+		payment := saftPayment{
+			PaymentRefNo:    fmt.Sprintf("RG %s/%d", period.Format("200601"), i+1),
+			TransactionDate: p.Date.Format("2006-01-02"),
+			PaymentType:     "RG",
+			SourceID:        p.ID.String(),
+			SystemEntryDate: p.Date.Format("2006-01-02T15:04:05"),
+			CustomerID:      customerID,
+		}
+		payment.DocumentStatus.PaymentStatus = "N"
+		payment.DocumentStatus.PaymentStatusDate = payment.SystemEntryDate
+		payment.DocumentStatus.SourceID = p.ID.String()
+		payment.DocumentStatus.SourcePayment = "P"
+		payment.PaymentMethod.PaymentMechanism = saftPaymentMechanism(p.Method)
+		payment.PaymentMethod.PaymentAmount = amount
+		payment.PaymentMethod.PaymentDate = payment.TransactionDate
+		payment.Line.LineNumber = 1
+		payment.Line.CreditAmount = amount
+		payment.DocumentTotals.TaxPayable = "0.00"
+		payment.DocumentTotals.NetTotal = amount
+		payment.DocumentTotals.GrossTotal = amount
+
+		file.SourceDocuments.Payments.Payments = append(file.SourceDocuments.Payments.Payments, payment)
+		total = total.Add(p.Amount)
+	}
+	file.SourceDocuments.Payments.NumberOfEntries = len(payments)
+	file.SourceDocuments.Payments.TotalDebit = "0.00"
+	file.SourceDocuments.Payments.TotalCredit = total.StringFixed(2)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(file); err != nil {
+		return err
+	}
+	return enc.Close()
+}
@@ -0,0 +1,403 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	defaultSandboxSampleSize = 25
+	maxSandboxSampleSize     = 100
+	// Sandbox users can't log in: the hash matches no password, access goes through IssueAccess
+	sandboxPasswordHash = "!sandbox"
+)
+
+// SandboxService clones organizations into sandboxes where admins can iterate on workflows
+// without touching real data or messaging real contacts
+type SandboxService struct {
+	db       *database.DB
+	workflow *WorkflowService
+	auth     *AuthService
+}
+
+func NewSandboxService(db *database.DB, workflow *WorkflowService, auth *AuthService) *SandboxService {
+	return &SandboxService{db: db, workflow: workflow, auth: auth}
+}
+
+// List returns the active sandboxes cloned from an organization
+func (s *SandboxService) List(ctx context.Context, orgID uuid.UUID) ([]*models.Sandbox, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT o.id, o.name, o.sandbox_of, o.created_at,
+			(SELECT COUNT(*) FROM workflows w WHERE w.organization_id = o.id)
+		FROM organizations o
+		WHERE o.sandbox_of = $1 AND o.deleted_at IS NULL
+		ORDER BY o.created_at DESC
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sandboxes: %w", err)
+	}
+	defer rows.Close()
+
+	sandboxes := []*models.Sandbox{}
+	for rows.Next() {
+		var sb models.Sandbox
+		if err := rows.Scan(&sb.ID, &sb.Name, &sb.SourceOrganizationID, &sb.CreatedAt, &sb.WorkflowCount); err != nil {
+			return nil, fmt.Errorf("failed to scan sandbox: %w", err)
+		}
+		sandboxes = append(sandboxes, &sb)
+	}
+	return sandboxes, rows.Err()
+}
+
+// Get returns a sandbox cloned from the organization
+func (s *SandboxService) Get(ctx context.Context, id, orgID uuid.UUID) (*models.Sandbox, error) {
+	var sb models.Sandbox
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT o.id, o.name, o.sandbox_of, o.created_at,
+			(SELECT COUNT(*) FROM workflows w WHERE w.organization_id = o.id)
+		FROM organizations o
+		WHERE o.id = $1 AND o.sandbox_of = $2 AND o.deleted_at IS NULL
+	`, id, orgID).Scan(&sb.ID, &sb.Name, &sb.SourceOrganizationID, &sb.CreatedAt, &sb.WorkflowCount)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("sandbox not found")
+		}
+		return nil, fmt.Errorf("failed to get sandbox: %w", err)
+	}
+	return &sb, nil
+}
+
+// Create clones the organization's modules, message templates and workflows into a new sandbox
+// organization, with a sample of the most recent records anonymized. Contact details are replaced
+// by unroutable placeholders and no notification settings are copied, so nothing run in the
+// sandbox reaches real people.
+func (s *SandboxService) Create(ctx context.Context, orgID, userID uuid.UUID, name string, sampleSize int) (*models.SandboxCloneResult, error) {
+	if sampleSize <= 0 {
+		sampleSize = defaultSandboxSampleSize
+	}
+	if sampleSize > maxSandboxSampleSize {
+		sampleSize = maxSandboxSampleSize
+	}
+
+	var orgName, orgEmail string
+	var sandboxOf *uuid.UUID
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT name, email, sandbox_of FROM organizations WHERE id = $1 AND deleted_at IS NULL
+	`, orgID).Scan(&orgName, &orgEmail, &sandboxOf)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("organization not found")
+		}
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+	if sandboxOf != nil {
+		return nil, errors.New("sandboxes cannot be cloned")
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = orgName + " (sandbox)"
+	}
+
+	var firstName, lastName string
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT first_name, last_name FROM users WHERE id = $1 AND organization_id = $2
+	`, userID, orgID).Scan(&firstName, &lastName)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("user not found")
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	sandboxID := uuid.New()
+	sandboxUserID := uuid.New()
+	result := &models.SandboxCloneResult{
+		Sandbox:         &models.Sandbox{ID: sandboxID, Name: name, SourceOrganizationID: orgID},
+		WorkflowsCloned: []string{},
+		WorkflowsFailed: map[string]string{},
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO organizations (id, name, email, sandbox_of, is_active)
+		VALUES ($1, $2, $3, $4, true)
+		RETURNING created_at
+	`, sandboxID, name, orgEmail, orgID).Scan(&result.Sandbox.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sandbox organization: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO organization_modules (organization_id, module_name, is_enabled, config, enabled_at)
+		SELECT $1, module_name, is_enabled, config, CURRENT_TIMESTAMP
+		FROM organization_modules WHERE organization_id = $2
+	`, sandboxID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy modules: %w", err)
+	}
+
+	// The sandbox administrator is only reachable through sandbox access tokens
+	_, err = tx.Exec(ctx, `
+		INSERT INTO users (id, organization_id, email, password_hash, first_name, last_name, role, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, true)
+	`, sandboxUserID, sandboxID, fmt.Sprintf("sandbox-%s@controlwise.invalid", sandboxID), sandboxPasswordHash,
+		firstName, lastName, models.RoleAdmin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sandbox user: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO message_templates (organization_id, name, channel, subject, body, variables, whatsapp_content_sid, is_active)
+		SELECT $1, name, channel, subject, body, variables, whatsapp_content_sid, is_active
+		FROM message_templates WHERE organization_id = $2
+	`, sandboxID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy templates: %w", err)
+	}
+	result.Templates = int(tag.RowsAffected())
+
+	if err := cloneSandboxData(ctx, tx, orgID, sandboxID, sandboxUserID, sampleSize, result); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// Workflows go through export/import, the same path used to promote them back
+	workflows, err := s.workflow.ListWorkflows(ctx, orgID, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range workflows {
+		if err := s.cloneWorkflow(ctx, orgID, sandboxID, &w.Workflow); err != nil {
+			result.WorkflowsFailed[w.Name] = err.Error()
+			continue
+		}
+		result.WorkflowsCloned = append(result.WorkflowsCloned, w.Name)
+	}
+	result.Sandbox.WorkflowCount = len(result.WorkflowsCloned)
+
+	return result, nil
+}
+
+// cloneWorkflow copies a workflow into the sandbox, keeping whether it is active and the default
+func (s *SandboxService) cloneWorkflow(ctx context.Context, orgID, sandboxID uuid.UUID, w *models.Workflow) error {
+	export, err := s.workflow.ExportWorkflow(ctx, w.ID, orgID)
+	if err != nil {
+		return err
+	}
+	imported, err := s.workflow.ImportWorkflow(ctx, sandboxID, export, w.Name)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Pool.Exec(ctx, `
+		UPDATE workflows SET is_active = $1, is_default = $2 WHERE id = $3
+	`, w.IsActive, w.IsDefault, imported.Workflow.ID)
+	if err != nil {
+		return fmt.Errorf("failed to activate workflow: %w", err)
+	}
+	return nil
+}
+
+// cloneSandboxData copies the most recent clients with their patients, budgets and projects, the
+// therapists and the sessions between copied patients and therapists. Names become numbered
+// placeholders and personal details are dropped.
+func cloneSandboxData(ctx context.Context, tx pgx.Tx, orgID, sandboxID, userID uuid.UUID, sampleSize int, result *models.SandboxCloneResult) error {
+	// Old to new IDs of every copied record, UUIDs don't collide across tables
+	_, err := tx.Exec(ctx, `
+		CREATE TEMP TABLE sandbox_ids (
+			old_id UUID PRIMARY KEY,
+			new_id UUID NOT NULL DEFAULT uuid_generate_v4()
+		) ON COMMIT DROP
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare sandbox copy: %w", err)
+	}
+
+	steps := []struct {
+		what   string
+		ids    string
+		insert string
+		count  *int
+	}{
+		{
+			what: "clients",
+			ids: `SELECT id FROM clients WHERE organization_id = $1 AND deleted_at IS NULL
+				ORDER BY created_at DESC LIMIT $2`,
+			insert: `INSERT INTO clients (id, organization_id, name, email, phone, segment, created_by)
+				SELECT m.new_id, $1, 'Cliente ' || n, 'cliente-' || n || '@example.invalid', '', c.segment, $2
+				FROM (SELECT c.*, ROW_NUMBER() OVER (ORDER BY c.created_at) AS n FROM clients c JOIN sandbox_ids m0 ON m0.old_id = c.id) c
+				JOIN sandbox_ids m ON m.old_id = c.id`,
+			count: &result.Clients,
+		},
+		{
+			what: "patients",
+			ids: `SELECT p.id FROM patients p JOIN sandbox_ids m ON m.old_id = p.client_id
+				WHERE p.organization_id = $1 AND p.deleted_at IS NULL`,
+			insert: `INSERT INTO patients (id, organization_id, client_id, is_active, created_by)
+				SELECT m.new_id, $1, mc.new_id, p.is_active, $2
+				FROM patients p
+				JOIN sandbox_ids m ON m.old_id = p.id
+				JOIN sandbox_ids mc ON mc.old_id = p.client_id`,
+			count: &result.Patients,
+		},
+		{
+			what: "therapists",
+			ids: `SELECT id FROM therapists WHERE organization_id = $1 AND deleted_at IS NULL
+				ORDER BY created_at LIMIT $2`,
+			insert: `INSERT INTO therapists (id, organization_id, name, specialty, working_hours,
+					session_duration_minutes, default_price_cents, timezone, is_active)
+				SELECT m.new_id, $1, 'Terapeuta ' || n, specialty, working_hours,
+					session_duration_minutes, default_price_cents, timezone, is_active
+				FROM (SELECT t.*, ROW_NUMBER() OVER (ORDER BY t.created_at) AS n FROM therapists t JOIN sandbox_ids m0 ON m0.old_id = t.id) t
+				JOIN sandbox_ids m ON m.old_id = t.id`,
+			count: &result.Therapists,
+		},
+		{
+			what: "sessions",
+			ids: `SELECT s.id FROM sessions s
+				JOIN sandbox_ids mp ON mp.old_id = s.patient_id
+				JOIN sandbox_ids mt ON mt.old_id = s.therapist_id
+				WHERE s.organization_id = $1 AND s.deleted_at IS NULL
+				ORDER BY s.scheduled_at DESC LIMIT $2 * 4`,
+			insert: `INSERT INTO sessions (id, organization_id, therapist_id, patient_id, scheduled_at,
+					duration_minutes, price_cents, status, session_type, cancelled_at, completed_at, created_by)
+				SELECT m.new_id, $1, mt.new_id, mp.new_id, s.scheduled_at,
+					s.duration_minutes, s.price_cents, s.status, s.session_type, s.cancelled_at, s.completed_at, $2
+				FROM sessions s
+				JOIN sandbox_ids m ON m.old_id = s.id
+				JOIN sandbox_ids mp ON mp.old_id = s.patient_id
+				JOIN sandbox_ids mt ON mt.old_id = s.therapist_id`,
+			count: &result.Sessions,
+		},
+		{
+			what: "worksheets",
+			ids: `SELECT w.id FROM worksheets w
+				JOIN budgets b ON b.worksheet_id = w.id AND b.deleted_at IS NULL
+				JOIN sandbox_ids mc ON mc.old_id = w.client_id
+				WHERE w.organization_id = $1 AND w.deleted_at IS NULL
+				GROUP BY w.id
+				ORDER BY MAX(b.created_at) DESC LIMIT $2`,
+			insert: `INSERT INTO worksheets (id, organization_id, client_id, title, description, status, created_by)
+				SELECT m.new_id, $1, mc.new_id, 'Folha de obra ' || w.n, '', w.status, $2
+				FROM (SELECT w.*, ROW_NUMBER() OVER (ORDER BY w.created_at) AS n FROM worksheets w JOIN sandbox_ids m0 ON m0.old_id = w.id) w
+				JOIN sandbox_ids m ON m.old_id = w.id
+				JOIN sandbox_ids mc ON mc.old_id = w.client_id`,
+		},
+		{
+			what: "budgets",
+			ids: `SELECT b.id FROM budgets b JOIN sandbox_ids m ON m.old_id = b.worksheet_id
+				WHERE b.organization_id = $1 AND b.deleted_at IS NULL`,
+			insert: `INSERT INTO budgets (id, organization_id, worksheet_id, budget_number, status,
+					subtotal, tax, total, valid_until, created_by, sent_at, approved_at, rejected_at)
+				SELECT m.new_id, $1, mw.new_id, b.budget_number, b.status,
+					b.subtotal, b.tax, b.total, b.valid_until, $2, b.sent_at, b.approved_at, b.rejected_at
+				FROM budgets b
+				JOIN sandbox_ids m ON m.old_id = b.id
+				JOIN sandbox_ids mw ON mw.old_id = b.worksheet_id`,
+			count: &result.Budgets,
+		},
+		{
+			what: "projects",
+			ids: `SELECT p.id FROM projects p JOIN sandbox_ids m ON m.old_id = p.budget_id
+				WHERE p.organization_id = $1 AND p.deleted_at IS NULL`,
+			insert: `INSERT INTO projects (id, organization_id, budget_id, project_number, title, status,
+					progress, start_date, expected_end_date, actual_end_date, category, created_by)
+				SELECT m.new_id, $1, mb.new_id, p.project_number, 'Projeto ' || p.n, p.status,
+					p.progress, p.start_date, p.expected_end_date, p.actual_end_date, p.category, $2
+				FROM (SELECT p.*, ROW_NUMBER() OVER (ORDER BY p.created_at) AS n FROM projects p JOIN sandbox_ids m0 ON m0.old_id = p.id) p
+				JOIN sandbox_ids m ON m.old_id = p.id
+				JOIN sandbox_ids mb ON mb.old_id = p.budget_id`,
+			count: &result.Projects,
+		},
+	}
+
+	for _, step := range steps {
+		args := []interface{}{orgID}
+		if strings.Contains(step.ids, "$2") {
+			args = append(args, sampleSize)
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO sandbox_ids (old_id) `+step.ids, args...); err != nil {
+			return fmt.Errorf("failed to select %s: %w", step.what, err)
+		}
+		tag, err := tx.Exec(ctx, step.insert, sandboxID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to copy %s: %w", step.what, err)
+		}
+		if step.count != nil {
+			*step.count = int(tag.RowsAffected())
+		}
+	}
+	return nil
+}
+
+// IssueAccess returns a token to work in the sandbox as its administrator
+func (s *SandboxService) IssueAccess(ctx context.Context, id, orgID uuid.UUID) (*models.SandboxAccess, error) {
+	if _, err := s.Get(ctx, id, orgID); err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT id, organization_id, email, first_name, last_name, role, is_active
+		FROM users
+		WHERE organization_id = $1 AND role = $2 AND deleted_at IS NULL
+		ORDER BY created_at
+		LIMIT 1
+	`, id, models.RoleAdmin).Scan(&user.ID, &user.OrganizationID, &user.Email, &user.FirstName, &user.LastName,
+		&user.Role, &user.IsActive)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("sandbox has no administrator")
+		}
+		return nil, fmt.Errorf("failed to get sandbox user: %w", err)
+	}
+
+	token, err := s.auth.generateToken(&user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	return &models.SandboxAccess{Token: token, SandboxID: id, User: &user}, nil
+}
+
+// Promote imports a sandbox workflow into the organization it was cloned from. Like any import,
+// the workflow arrives inactive so it can be reviewed before it replaces the live one.
+func (s *SandboxService) Promote(ctx context.Context, id, orgID, workflowID uuid.UUID, name string) (*models.WorkflowImportResult, error) {
+	if _, err := s.Get(ctx, id, orgID); err != nil {
+		return nil, err
+	}
+
+	export, err := s.workflow.ExportWorkflow(ctx, workflowID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.workflow.ImportWorkflow(ctx, orgID, export, name)
+}
+
+// Delete deactivates a sandbox
+func (s *SandboxService) Delete(ctx context.Context, id, orgID uuid.UUID) error {
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE organizations SET is_active = false, deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND sandbox_of = $2 AND deleted_at IS NULL
+	`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to delete sandbox: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("sandbox not found")
+	}
+	return nil
+}
@@ -3,39 +3,86 @@ package services
 import (
 	"github.com/controlwise/backend/internal/config"
 	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/events"
+	"github.com/controlwise/backend/internal/models"
 )
 
 type Services struct {
-	Auth         *AuthService
-	Organization *OrganizationService
-	User         *UserService
-	Client       *ClientService
-	Worksheet    *WorksheetService
-	Budget       *BudgetService
-	Project      *ProjectService
-	Task         *TaskService
-	Payment      *PaymentService
-	Notification *NotificationService
-	Report       *ReportService
-	Storage      *StorageService
-	Email        *EmailService
-	Module       *ModuleService
+	Auth            *AuthService
+	Organization    *OrganizationService
+	User            *UserService
+	Invitation      *InvitationService
+	Client          *ClientService
+	Worksheet       *WorksheetService
+	Budget          *BudgetService
+	Project         *ProjectService
+	ProjectTemplate *ProjectTemplateService
+	Compliance      *ComplianceService
+	Crew            *CrewService
+	Material        *MaterialService
+	Task            *TaskService
+	Payment         *PaymentService
+	FinancialPeriod *FinancialPeriodService
+	Expense         *ExpenseService
+	BankStatement   *BankStatementService
+	Accountant      *AccountantService
+	DataExport      *DataExportService
+	Notification    *NotificationService
+	Report          *ReportService
+	Storage         *StorageService
+	Email           *EmailService
+	Module          *ModuleService
+	Integration     *IntegrationService
+	Delegation      *DelegationService
 	// Appointments module
 	Patient        *PatientService
 	Therapist      *TherapistService
 	Session        *SessionService
+	Booking        *BookingService
 	SessionPayment *SessionPaymentService
+	CashRegister   *CashRegisterService
+	PatientPortal  *PatientPortalService
+	Reschedule     *SessionRescheduleService
+	WaitingList    *WaitingListService
+	TreatmentPlan  *TreatmentPlanService
+	// Invoices module
+	Invoice *InvoiceService
+	// Online payment links
+	PaymentLink      *PaymentLinkService
+	PaymentReference *PaymentReferenceService
 	// Notifications module
-	WhatsApp *WhatsAppService
+	WhatsApp          *WhatsAppService
+	EmailDelivery     *EmailDeliveryService
+	EmailSuppression  *EmailSuppressionService
+	ChatWebhook       *ChatWebhookService
+	Conversation      *WhatsAppConversationService
+	NotificationQueue *NotificationQueueService
 	// Workflow engine
 	Workflow *WorkflowService
+	Sandbox  *SandboxService
 	// System Admin services
 	SystemAdmin       *SystemAdminService
 	AdminOrganization *AdminOrganizationService
 	AdminUser         *AdminUserService
 	AdminAudit        *AdminAuditService
+	AdminSecurity     *AdminSecurityService
 	AdminStats        *AdminStatsService
 	Impersonation     *ImpersonationService
+	Usage             *UsageService
+	Integrity         *IntegrityService
+	Benchmark         *BenchmarkService
+	Consent           *ConsentService
+	LogRetention      *LogRetentionService
+	OrganizationMerge *OrganizationMergeService
+	// Tenant-initiated organization deletion
+	OrganizationDeletion *OrganizationDeletionService
+	// Priority inbox and personal follow-ups
+	Inbox    *InboxService
+	FollowUp *FollowUpService
+	// Quick-create from typed text
+	QuickCreate *QuickCreateService
+	// Dashboard event stream
+	Events *events.Publisher
 }
 
 func NewServices(db *database.DB, redis *database.Redis, cfg *config.Config) *Services {
@@ -51,47 +98,135 @@ func NewServices(db *database.DB, redis *database.Redis, cfg *config.Config) *Se
 	// Initialize system admin service
 	systemAdminService := NewSystemAdminService(db, cfg.JWT)
 
+	// Dashboard events are shared with the worker through Redis
+	var eventPublisher *events.Publisher
+	if redis != nil {
+		eventPublisher = events.NewPublisher(redis.Client)
+	}
+
 	// Initialize workflow service
 	workflowService := NewWorkflowService(db)
 
 	// Initialize session service with workflow integration
 	sessionService := NewSessionService(db)
 	sessionService.SetWorkflowService(workflowService)
+	sessionService.SetEventPublisher(eventPublisher)
+	bookingService := NewBookingService(db, cfg.App.FrontendURL)
+	therapistService := NewTherapistService(db)
+
+	// Initialize payment services with dashboard events
+	paymentService := NewPaymentService(db, notificationService)
+	paymentService.SetEventPublisher(eventPublisher)
+	sessionPaymentService := NewSessionPaymentService(db)
+	sessionPaymentService.SetEventPublisher(eventPublisher)
+	bankStatementService := NewBankStatementService(db, NewBankAggregatorProvider(cfg.Banking))
+	bankStatementService.SetEventPublisher(eventPublisher)
 
 	// Initialize budget service with workflow integration
-	budgetService := NewBudgetService(db, storageService, notificationService)
+	budgetService := NewBudgetService(db, storageService, notificationService, cfg.App.FrontendURL)
 	budgetService.SetWorkflowService(workflowService)
 
+	// Initialize project services with workflow and compliance integration
+	complianceService := NewComplianceService(db)
+	projectService := NewProjectService(db, storageService, notificationService)
+	projectService.SetWorkflowService(workflowService)
+	projectService.SetComplianceService(complianceService)
+	projectTemplateService := NewProjectTemplateService(db)
+	projectTemplateService.SetComplianceService(complianceService)
+
+	// Initialize task service with workflow integration for task_assigned triggers
+	taskService := NewTaskService(db, notificationService)
+	taskService.SetWorkflowService(workflowService)
+
+	// Initialize invoice service with workflow integration
+	invoiceService := NewInvoiceService(db, storageService)
+	invoiceService.SetWorkflowService(workflowService)
+
+	authService := NewAuthService(db, cfg.JWT)
+
+	whatsappService := NewWhatsAppService(db, cfg.Encryption.Key)
+	rescheduleService := NewSessionRescheduleService(db, sessionService, bookingService, cfg.App.FrontendURL)
+	whatsappService.RegisterIntentHandler(models.InboundIntentReschedule, rescheduleService)
+
+	// Initialize organization service with logo storage
+	organizationService := NewOrganizationService(db)
+	organizationService.SetStorageService(storageService)
+
 	return &Services{
-		Auth:         NewAuthService(db, cfg.JWT),
-		Organization: NewOrganizationService(db),
-		User:         NewUserService(db),
-		Client:       NewClientService(db),
-		Worksheet:    NewWorksheetService(db, storageService, notificationService),
-		Budget:       budgetService,
-		Project:      NewProjectService(db, storageService, notificationService),
-		Task:         NewTaskService(db, notificationService),
-		Payment:      NewPaymentService(db, notificationService),
-		Notification: notificationService,
-		Report:       NewReportService(db),
-		Storage:      storageService,
-		Email:        emailService,
-		Module:       NewModuleService(db),
+		Auth:            authService,
+		Organization:    organizationService,
+		User:            NewUserService(db, storageService),
+		Invitation:      NewInvitationService(db, authService, emailService, cfg.App.FrontendURL),
+		Client:          NewClientService(db),
+		Worksheet:       NewWorksheetService(db, storageService, notificationService),
+		Budget:          budgetService,
+		Project:         projectService,
+		ProjectTemplate: projectTemplateService,
+		Compliance:      complianceService,
+		Crew:            NewCrewService(db),
+		Material:        NewMaterialService(db),
+		Task:            taskService,
+		Payment:         paymentService,
+		FinancialPeriod: NewFinancialPeriodService(db),
+		Expense:         NewExpenseService(db, storageService, NewReceiptOCRProvider(cfg.OCR)),
+		BankStatement:   bankStatementService,
+		Accountant:      NewAccountantService(db, storageService, emailService, cfg.App.FrontendURL),
+		DataExport:      NewDataExportService(db, storageService, cfg.Storage.ExportRetention),
+		Notification:    notificationService,
+		Report:          NewReportService(db),
+		Storage:         storageService,
+		Email:           emailService,
+		Module:          NewModuleService(db),
+		Integration:     NewIntegrationService(db, whatsappService, emailService, storageService),
+		Delegation:      NewDelegationService(db),
 		// Appointments module
 		Patient:        NewPatientService(db),
-		Therapist:      NewTherapistService(db),
+		Therapist:      therapistService,
 		Session:        sessionService,
-		SessionPayment: NewSessionPaymentService(db),
+		Booking:        bookingService,
+		SessionPayment: sessionPaymentService,
+		CashRegister:   NewCashRegisterService(db),
+		PatientPortal:  NewPatientPortalService(db, sessionService, emailService, cfg.JWT, cfg.App.FrontendURL),
+		Reschedule:     rescheduleService,
+		WaitingList:    NewWaitingListService(db, sessionService, cfg.App.FrontendURL),
+		TreatmentPlan:  NewTreatmentPlanService(db),
+		// Invoices module
+		Invoice: invoiceService,
+		// Online payment links
+		PaymentLink:      NewPaymentLinkService(db, cfg.Encryption.Key, cfg.App.FrontendURL, sessionPaymentService, paymentService),
+		PaymentReference: NewPaymentReferenceService(db, cfg.Encryption.Key, sessionPaymentService, paymentService),
 		// Notifications module
-		WhatsApp: NewWhatsAppService(db, cfg.Encryption.Key),
+		WhatsApp:          whatsappService,
+		Conversation:      NewWhatsAppConversationService(db, whatsappService),
+		EmailDelivery:     NewEmailDeliveryService(db, cfg.Encryption.Key, emailService),
+		EmailSuppression:  NewEmailSuppressionService(db, workflowService),
+		NotificationQueue: NewNotificationQueueService(db, redis, cfg.RateLimit),
+		ChatWebhook:       NewChatWebhookService(db, cfg.Encryption.Key),
 		// Workflow engine
 		Workflow: workflowService,
+		Sandbox:  NewSandboxService(db, workflowService, authService),
 		// System Admin services
 		SystemAdmin:       systemAdminService,
 		AdminOrganization: NewAdminOrganizationService(db),
 		AdminUser:         NewAdminUserService(db),
 		AdminAudit:        NewAdminAuditService(db),
+		AdminSecurity:     NewAdminSecurityService(db, emailService, cfg.App.FrontendURL),
 		AdminStats:        NewAdminStatsService(db),
 		Impersonation:     NewImpersonationService(db, systemAdminService),
+		Usage:             NewUsageService(db),
+		Integrity:         NewIntegrityService(db),
+		Benchmark:         NewBenchmarkService(db),
+		Consent:           NewConsentService(db),
+		LogRetention:      NewLogRetentionService(db, storageService),
+		OrganizationMerge: NewOrganizationMergeService(db),
+		// Tenant-initiated organization deletion
+		OrganizationDeletion: NewOrganizationDeletionService(db, storageService, emailService, cfg.App.FrontendURL),
+		// Priority inbox and personal follow-ups
+		Inbox:    NewInboxService(db),
+		FollowUp: NewFollowUpService(db, emailService, cfg.App.FrontendURL),
+		// Quick-create from typed text
+		QuickCreate: NewQuickCreateService(db, sessionService, therapistService),
+		// Dashboard event stream
+		Events: eventPublisher,
 	}
 }
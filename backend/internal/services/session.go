@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/events"
 	"github.com/controlwise/backend/internal/models"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -16,6 +18,7 @@ import (
 type SessionService struct {
 	db       *database.DB
 	workflow *WorkflowService
+	events   *events.Publisher
 }
 
 func NewSessionService(db *database.DB) *SessionService {
@@ -27,67 +30,134 @@ func (s *SessionService) SetWorkflowService(ws *WorkflowService) {
 	s.workflow = ws
 }
 
-// List returns sessions for an organization with filters
+// SetEventPublisher sets the publisher new bookings are streamed to dashboards with
+func (s *SessionService) SetEventPublisher(p *events.Publisher) {
+	s.events = p
+}
+
+// sessionReadModelColumns are the columns of session_read_model scanned by scanSessionReadModel
+const sessionReadModelColumns = `
+	session_id, organization_id, therapist_id, patient_id,
+	scheduled_at, duration_minutes, price_cents, status,
+	session_type, notes, cancel_reason, cancelled_at,
+	cancelled_by, completed_at,
+	conflict_override, override_reason, override_status, service_id,
+	series_id, project_id, created_by, created_at, updated_at,
+	therapist_name, patient_name, patient_phone, patient_email`
+
+// scanSessionReadModel scans a session_read_model row, which holds a session with its therapist
+// and patient details
+func scanSessionReadModel(row pgx.Row) (*models.SessionWithDetails, error) {
+	var sd models.SessionWithDetails
+	err := row.Scan(
+		&sd.ID,
+		&sd.OrganizationID,
+		&sd.TherapistID,
+		&sd.PatientID,
+		&sd.ScheduledAt,
+		&sd.DurationMinutes,
+		&sd.PriceCents,
+		&sd.Status,
+		&sd.SessionType,
+		&sd.Notes,
+		&sd.CancelReason,
+		&sd.CancelledAt,
+		&sd.CancelledBy,
+		&sd.CompletedAt,
+		&sd.ConflictOverride,
+		&sd.OverrideReason,
+		&sd.OverrideStatus,
+		&sd.ServiceID,
+		&sd.SeriesID,
+		&sd.ProjectID,
+		&sd.CreatedBy,
+		&sd.CreatedAt,
+		&sd.UpdatedAt,
+		&sd.TherapistName,
+		&sd.PatientName,
+		&sd.PatientPhone,
+		&sd.PatientEmail,
+	)
+	return &sd, err
+}
+
+// List returns sessions for an organization with filters. It reads the session read model,
+// which the database keeps in sync with sessions, therapists and patients.
 func (s *SessionService) List(ctx context.Context, orgID uuid.UUID, filters SessionFilters) ([]*models.SessionWithDetails, int, error) {
 	args := []interface{}{orgID}
 	argNum := 1
 
-	whereClause := "WHERE s.organization_id = $1 AND s.deleted_at IS NULL"
+	whereClause := "WHERE organization_id = $1"
 
 	if filters.TherapistID != nil {
 		argNum++
-		whereClause += fmt.Sprintf(" AND s.therapist_id = $%d", argNum)
+		whereClause += fmt.Sprintf(" AND therapist_id = $%d", argNum)
 		args = append(args, *filters.TherapistID)
 	}
 
 	if filters.PatientID != nil {
 		argNum++
-		whereClause += fmt.Sprintf(" AND s.patient_id = $%d", argNum)
+		whereClause += fmt.Sprintf(" AND patient_id = $%d", argNum)
 		args = append(args, *filters.PatientID)
 	}
 
 	if filters.Status != nil {
 		argNum++
-		whereClause += fmt.Sprintf(" AND s.status = $%d", argNum)
+		whereClause += fmt.Sprintf(" AND status = $%d", argNum)
 		args = append(args, *filters.Status)
 	}
 
+	if filters.OverrideStatus != nil {
+		argNum++
+		whereClause += fmt.Sprintf(" AND override_status = $%d", argNum)
+		args = append(args, *filters.OverrideStatus)
+	}
+
+	if filters.SeriesID != nil {
+		argNum++
+		whereClause += fmt.Sprintf(" AND series_id = $%d", argNum)
+		args = append(args, *filters.SeriesID)
+	}
+
+	if filters.ProjectID != nil {
+		argNum++
+		whereClause += fmt.Sprintf(" AND project_id = $%d", argNum)
+		args = append(args, *filters.ProjectID)
+	}
+
 	if filters.StartDate != nil {
 		argNum++
-		whereClause += fmt.Sprintf(" AND s.scheduled_at >= $%d", argNum)
+		whereClause += fmt.Sprintf(" AND scheduled_at >= $%d", argNum)
 		args = append(args, *filters.StartDate)
 	}
 
 	if filters.EndDate != nil {
 		argNum++
-		whereClause += fmt.Sprintf(" AND s.scheduled_at <= $%d", argNum)
+		whereClause += fmt.Sprintf(" AND scheduled_at <= $%d", argNum)
 		args = append(args, *filters.EndDate)
 	}
 
+	if search := strings.TrimSpace(filters.Search); search != "" {
+		argNum++
+		whereClause += fmt.Sprintf(" AND search_text LIKE $%d", argNum)
+		args = append(args, "%"+strings.ToLower(search)+"%")
+	}
+
 	// Get total count
 	var total int
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM sessions s %s", whereClause)
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM session_read_model %s", whereClause)
 	err := s.db.Pool.QueryRow(ctx, countQuery, args...).Scan(&total)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count sessions: %w", err)
 	}
 
-	// Get sessions with details
 	query := fmt.Sprintf(`
-		SELECT
-			s.id, s.organization_id, s.therapist_id, s.patient_id,
-			s.scheduled_at, s.duration_minutes, s.price_cents, s.status,
-			s.session_type, s.notes, s.cancel_reason, s.cancelled_at,
-			s.cancelled_by, s.completed_at, s.created_by, s.created_at, s.updated_at,
-			t.name as therapist_name,
-			p.name as patient_name, p.phone as patient_phone, p.email as patient_email
-		FROM sessions s
-		JOIN therapists t ON t.id = s.therapist_id
-		JOIN patients p ON p.id = s.patient_id
+		SELECT %s
+		FROM session_read_model
 		%s
-		ORDER BY s.scheduled_at DESC
+		ORDER BY scheduled_at DESC
 		LIMIT $%d OFFSET $%d
-	`, whereClause, argNum+1, argNum+2)
+	`, sessionReadModelColumns, whereClause, argNum+1, argNum+2)
 
 	args = append(args, filters.Limit, filters.Offset)
 
@@ -99,63 +169,38 @@ func (s *SessionService) List(ctx context.Context, orgID uuid.UUID, filters Sess
 
 	var sessions []*models.SessionWithDetails
 	for rows.Next() {
-		var sd models.SessionWithDetails
-		err := rows.Scan(
-			&sd.ID,
-			&sd.OrganizationID,
-			&sd.TherapistID,
-			&sd.PatientID,
-			&sd.ScheduledAt,
-			&sd.DurationMinutes,
-			&sd.PriceCents,
-			&sd.Status,
-			&sd.SessionType,
-			&sd.Notes,
-			&sd.CancelReason,
-			&sd.CancelledAt,
-			&sd.CancelledBy,
-			&sd.CompletedAt,
-			&sd.CreatedBy,
-			&sd.CreatedAt,
-			&sd.UpdatedAt,
-			&sd.TherapistName,
-			&sd.PatientName,
-			&sd.PatientPhone,
-			&sd.PatientEmail,
-		)
+		sd, err := scanSessionReadModel(rows)
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to scan session: %w", err)
 		}
-		sessions = append(sessions, &sd)
+		sessions = append(sessions, sd)
 	}
 
 	return sessions, total, nil
 }
 
-// GetCalendarEvents returns sessions formatted for calendar display
+// Location returns the timezone the organization's calendar is shown in
+func (s *SessionService) Location(ctx context.Context, orgID uuid.UUID) *time.Location {
+	return orgLocation(ctx, s.db.Pool, orgID)
+}
+
+// GetCalendarEvents returns sessions overlapping the period formatted for calendar display, with
+// times in the organization's timezone
 func (s *SessionService) GetCalendarEvents(ctx context.Context, orgID uuid.UUID, start, end time.Time, therapistID *uuid.UUID) ([]models.CalendarEvent, error) {
+	loc := s.Location(ctx, orgID)
 	args := []interface{}{orgID, start, end}
 	query := `
-		SELECT
-			s.id, s.organization_id, s.therapist_id, s.patient_id,
-			s.scheduled_at, s.duration_minutes, s.price_cents, s.status,
-			s.session_type, s.notes, s.cancel_reason, s.cancelled_at,
-			s.cancelled_by, s.completed_at, s.created_by, s.created_at, s.updated_at,
-			t.name as therapist_name,
-			p.name as patient_name, p.phone as patient_phone, p.email as patient_email
-		FROM sessions s
-		JOIN therapists t ON t.id = s.therapist_id
-		JOIN patients p ON p.id = s.patient_id
-		WHERE s.organization_id = $1 AND s.deleted_at IS NULL
-			AND s.scheduled_at >= $2 AND s.scheduled_at <= $3
+		SELECT ` + sessionReadModelColumns + `
+		FROM session_read_model
+		WHERE organization_id = $1 AND ends_at > $2 AND scheduled_at <= $3
 	`
 
 	if therapistID != nil {
-		query += " AND s.therapist_id = $4"
+		query += " AND therapist_id = $4"
 		args = append(args, *therapistID)
 	}
 
-	query += " ORDER BY s.scheduled_at ASC"
+	query += " ORDER BY scheduled_at ASC"
 
 	rows, err := s.db.Pool.Query(ctx, query, args...)
 	if err != nil {
@@ -165,34 +210,13 @@ func (s *SessionService) GetCalendarEvents(ctx context.Context, orgID uuid.UUID,
 
 	var events []models.CalendarEvent
 	for rows.Next() {
-		var sd models.SessionWithDetails
-		err := rows.Scan(
-			&sd.ID,
-			&sd.OrganizationID,
-			&sd.TherapistID,
-			&sd.PatientID,
-			&sd.ScheduledAt,
-			&sd.DurationMinutes,
-			&sd.PriceCents,
-			&sd.Status,
-			&sd.SessionType,
-			&sd.Notes,
-			&sd.CancelReason,
-			&sd.CancelledAt,
-			&sd.CancelledBy,
-			&sd.CompletedAt,
-			&sd.CreatedBy,
-			&sd.CreatedAt,
-			&sd.UpdatedAt,
-			&sd.TherapistName,
-			&sd.PatientName,
-			&sd.PatientPhone,
-			&sd.PatientEmail,
-		)
+		sd, err := scanSessionReadModel(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan session: %w", err)
 		}
-		events = append(events, sd.ToCalendarEvent())
+		event := sd.ToCalendarEvent()
+		event.Start, event.End = event.Start.In(loc), event.End.In(loc)
+		events = append(events, event)
 	}
 
 	return events, nil
@@ -206,7 +230,9 @@ func (s *SessionService) GetByID(ctx context.Context, id, orgID uuid.UUID) (*mod
 			s.id, s.organization_id, s.therapist_id, s.patient_id,
 			s.scheduled_at, s.duration_minutes, s.price_cents, s.status,
 			s.session_type, s.notes, s.cancel_reason, s.cancelled_at,
-			s.cancelled_by, s.completed_at, s.created_by, s.created_at, s.updated_at,
+			s.cancelled_by, s.completed_at,
+			COALESCE(s.conflict_override, false), s.override_reason, s.override_status, s.service_id,
+			s.series_id, s.project_id, s.created_by, s.created_at, s.updated_at,
 			t.name as therapist_name,
 			p.name as patient_name, p.phone as patient_phone, p.email as patient_email
 		FROM sessions s
@@ -228,6 +254,12 @@ func (s *SessionService) GetByID(ctx context.Context, id, orgID uuid.UUID) (*mod
 		&sd.CancelledAt,
 		&sd.CancelledBy,
 		&sd.CompletedAt,
+		&sd.ConflictOverride,
+		&sd.OverrideReason,
+		&sd.OverrideStatus,
+		&sd.ServiceID,
+		&sd.SeriesID,
+		&sd.ProjectID,
 		&sd.CreatedBy,
 		&sd.CreatedAt,
 		&sd.UpdatedAt,
@@ -248,6 +280,13 @@ func (s *SessionService) GetByID(ctx context.Context, id, orgID uuid.UUID) (*mod
 
 // Create creates a new session with conflict detection
 func (s *SessionService) Create(ctx context.Context, session *models.Session, createdBy uuid.UUID) error {
+	// Pre-fill duration, price and type from the service catalogue
+	if session.ServiceID != nil {
+		if err := s.applyBookableService(ctx, session); err != nil {
+			return err
+		}
+	}
+
 	// Validate required fields
 	if session.TherapistID == uuid.Nil {
 		return errors.New("therapist is required")
@@ -268,10 +307,26 @@ func (s *SessionService) Create(ctx context.Context, session *models.Session, cr
 	if err != nil {
 		return fmt.Errorf("failed to check conflicts: %w", err)
 	}
-	if hasConflict {
+	if hasConflict && !session.ConflictOverride {
 		return errors.New("scheduling conflict: therapist already has a session at this time")
 	}
 
+	// Overlaps booked on purpose need a reason, and possibly a manager's approval
+	if hasConflict {
+		if session.OverrideReason == nil || strings.TrimSpace(*session.OverrideReason) == "" {
+			return errors.New("a reason is required to override a scheduling conflict")
+		}
+		status, err := s.overrideStatusFor(ctx, session.OrganizationID, createdBy)
+		if err != nil {
+			return err
+		}
+		session.OverrideStatus = &status
+	} else {
+		session.ConflictOverride = false
+		session.OverrideReason = nil
+		session.OverrideStatus = nil
+	}
+
 	// Set defaults
 	session.ID = uuid.New()
 	session.Status = models.SessionStatusPending
@@ -284,11 +339,13 @@ func (s *SessionService) Create(ctx context.Context, session *models.Session, cr
 	_, err = s.db.Pool.Exec(ctx, `
 		INSERT INTO sessions (
 			id, organization_id, therapist_id, patient_id, scheduled_at,
-			duration_minutes, price_cents, status, session_type, notes, created_by
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			duration_minutes, price_cents, status, session_type, notes, created_by,
+			conflict_override, override_reason, override_status, service_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 	`, session.ID, session.OrganizationID, session.TherapistID, session.PatientID,
 		session.ScheduledAt, session.DurationMinutes, session.PriceCents,
-		session.Status, session.SessionType, session.Notes, session.CreatedBy)
+		session.Status, session.SessionType, session.Notes, session.CreatedBy,
+		session.ConflictOverride, session.OverrideReason, session.OverrideStatus, session.ServiceID)
 
 	if err != nil {
 		return fmt.Errorf("failed to create session: %w", err)
@@ -296,6 +353,9 @@ func (s *SessionService) Create(ctx context.Context, session *models.Session, cr
 
 	// Record history
 	s.recordHistory(ctx, session.ID, "created", nil, session, &createdBy)
+	if session.ConflictOverride {
+		s.recordHistory(ctx, session.ID, "conflict_override_"+string(*session.OverrideStatus), nil, session, &createdBy)
+	}
 
 	// Trigger workflow for session creation (entering pending state)
 	if s.workflow != nil {
@@ -305,6 +365,18 @@ func (s *SessionService) Create(ctx context.Context, session *models.Session, cr
 		}
 	}
 
+	s.events.Publish(ctx, session.OrganizationID, models.DashboardEvent{
+		Type:       models.DashboardEventSessionCreated,
+		EntityType: "session",
+		EntityID:   session.ID,
+		Data: map[string]interface{}{
+			"therapist_id": session.TherapistID,
+			"patient_id":   session.PatientID,
+			"scheduled_at": session.ScheduledAt,
+			"status":       session.Status,
+		},
+	})
+
 	return nil
 }
 
@@ -358,7 +430,12 @@ func (s *SessionService) Update(ctx context.Context, id, orgID uuid.UUID, sessio
 }
 
 // Confirm confirms a pending session
-func (s *SessionService) Confirm(ctx context.Context, id, orgID uuid.UUID, confirmedBy uuid.UUID) error {
+func (s *SessionService) Confirm(ctx context.Context, id, orgID uuid.UUID, confirmedBy uuid.UUID, inputs map[string]interface{}) error {
+	return s.confirm(ctx, id, orgID, &confirmedBy, inputs)
+}
+
+// confirm confirms a pending session on behalf of a user, or of the patient when confirmedBy is nil
+func (s *SessionService) confirm(ctx context.Context, id, orgID uuid.UUID, confirmedBy *uuid.UUID, inputs map[string]interface{}) error {
 	existing, err := s.GetByID(ctx, id, orgID)
 	if err != nil {
 		return err
@@ -368,6 +445,11 @@ func (s *SessionService) Confirm(ctx context.Context, id, orgID uuid.UUID, confi
 		return errors.New("can only confirm pending sessions")
 	}
 
+	transition, err := s.prepareTransition(ctx, orgID, existing.Status, models.SessionStatusConfirmed, inputs)
+	if err != nil {
+		return err
+	}
+
 	result, err := s.db.Pool.Exec(ctx, `
 		UPDATE sessions
 		SET status = $1
@@ -383,10 +465,13 @@ func (s *SessionService) Confirm(ctx context.Context, id, orgID uuid.UUID, confi
 	}
 
 	// Record history
-	s.recordHistory(ctx, id, "confirmed", &existing.Session, nil, &confirmedBy)
+	s.recordHistory(ctx, id, "confirmed", &existing.Session, nil, confirmedBy)
 
 	// Trigger workflow for state change
 	if s.workflow != nil {
+		if err := s.workflow.RecordTransition(ctx, orgID, "session", id, transition, confirmedBy); err != nil {
+			fmt.Printf("Failed to record transition: %v\n", err)
+		}
 		if err := s.workflow.OnSessionStateChange(ctx, orgID, id, string(existing.Status), string(models.SessionStatusConfirmed), existing.ScheduledAt); err != nil {
 			fmt.Printf("Failed to trigger workflow: %v\n", err)
 		}
@@ -395,47 +480,83 @@ func (s *SessionService) Confirm(ctx context.Context, id, orgID uuid.UUID, confi
 	return nil
 }
 
-// Cancel cancels a session
-func (s *SessionService) Cancel(ctx context.Context, id, orgID uuid.UUID, reason string, cancelledBy uuid.UUID) error {
+// Cancel cancels a session, applying the organization's cancellation policy.
+// A fee due under the policy is recorded as the session's payment unless waived.
+func (s *SessionService) Cancel(ctx context.Context, id, orgID uuid.UUID, reason string, cancelledBy uuid.UUID, waiveFee bool, inputs map[string]interface{}) (*models.CancellationOutcome, error) {
+	return s.cancel(ctx, id, orgID, reason, &cancelledBy, waiveFee, inputs)
+}
+
+// cancel cancels a session on behalf of a user, or of the patient when cancelledBy is nil
+func (s *SessionService) cancel(ctx context.Context, id, orgID uuid.UUID, reason string, cancelledBy *uuid.UUID, waiveFee bool, inputs map[string]interface{}) (*models.CancellationOutcome, error) {
 	existing, err := s.GetByID(ctx, id, orgID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if existing.Status == models.SessionStatusCompleted || existing.Status == models.SessionStatusCancelled {
-		return errors.New("cannot cancel completed or already cancelled sessions")
+		return nil, errors.New("cannot cancel completed or already cancelled sessions")
+	}
+
+	transition, err := s.prepareTransition(ctx, orgID, existing.Status, models.SessionStatusCancelled, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback(ctx)
 
 	now := time.Now()
-	result, err := s.db.Pool.Exec(ctx, `
+	outcome, err := s.evaluateCancellation(ctx, tx, &existing.Session, now, waiveFee)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := tx.Exec(ctx, `
 		UPDATE sessions
-		SET status = $1, cancel_reason = $2, cancelled_at = $3, cancelled_by = $4
-		WHERE id = $5 AND organization_id = $6 AND deleted_at IS NULL
-	`, models.SessionStatusCancelled, reason, now, cancelledBy, id, orgID)
+		SET status = $1, cancel_reason = $2, cancelled_at = $3, cancelled_by = $4,
+		    cancellation_fee_cents = $5, cancellation_rule_id = $6, cancellation_fee_waived = $7,
+		    cancellation_policy_note = $8
+		WHERE id = $9 AND organization_id = $10 AND deleted_at IS NULL
+	`, models.SessionStatusCancelled, reason, now, cancelledBy,
+		outcome.FeeCents, outcome.RuleID, outcome.Waived, outcome.Description, id, orgID)
 
 	if err != nil {
-		return fmt.Errorf("failed to cancel session: %w", err)
+		return nil, fmt.Errorf("failed to cancel session: %w", err)
 	}
 
 	if result.RowsAffected() == 0 {
-		return errors.New("session not found")
+		return nil, errors.New("session not found")
+	}
+
+	if err := s.applyCancellationFee(ctx, tx, id, outcome); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	// Record history
-	s.recordHistory(ctx, id, "cancelled", &existing.Session, nil, &cancelledBy)
+	s.recordHistory(ctx, id, "cancelled", &existing.Session, nil, cancelledBy)
 
 	// Trigger workflow for state change (cancelling pending jobs)
 	if s.workflow != nil {
+		if err := s.workflow.RecordTransition(ctx, orgID, "session", id, transition, cancelledBy); err != nil {
+			fmt.Printf("Failed to record transition: %v\n", err)
+		}
 		if err := s.workflow.OnSessionStateChange(ctx, orgID, id, string(existing.Status), string(models.SessionStatusCancelled), existing.ScheduledAt); err != nil {
 			fmt.Printf("Failed to trigger workflow: %v\n", err)
 		}
 	}
 
-	return nil
+	return outcome, nil
 }
 
 // Complete marks a session as completed
-func (s *SessionService) Complete(ctx context.Context, id, orgID uuid.UUID, completedBy uuid.UUID) error {
+func (s *SessionService) Complete(ctx context.Context, id, orgID uuid.UUID, completedBy uuid.UUID, inputs map[string]interface{}) error {
 	existing, err := s.GetByID(ctx, id, orgID)
 	if err != nil {
 		return err
@@ -448,6 +569,11 @@ func (s *SessionService) Complete(ctx context.Context, id, orgID uuid.UUID, comp
 		return errors.New("session is already completed")
 	}
 
+	transition, err := s.prepareTransition(ctx, orgID, existing.Status, models.SessionStatusCompleted, inputs)
+	if err != nil {
+		return err
+	}
+
 	now := time.Now()
 	result, err := s.db.Pool.Exec(ctx, `
 		UPDATE sessions
@@ -468,6 +594,9 @@ func (s *SessionService) Complete(ctx context.Context, id, orgID uuid.UUID, comp
 
 	// Trigger workflow for state change
 	if s.workflow != nil {
+		if err := s.workflow.RecordTransition(ctx, orgID, "session", id, transition, &completedBy); err != nil {
+			fmt.Printf("Failed to record transition: %v\n", err)
+		}
 		if err := s.workflow.OnSessionStateChange(ctx, orgID, id, string(existing.Status), string(models.SessionStatusCompleted), existing.ScheduledAt); err != nil {
 			fmt.Printf("Failed to trigger workflow: %v\n", err)
 		}
@@ -477,7 +606,7 @@ func (s *SessionService) Complete(ctx context.Context, id, orgID uuid.UUID, comp
 }
 
 // MarkNoShow marks a session as no-show
-func (s *SessionService) MarkNoShow(ctx context.Context, id, orgID uuid.UUID, markedBy uuid.UUID) error {
+func (s *SessionService) MarkNoShow(ctx context.Context, id, orgID uuid.UUID, markedBy uuid.UUID, inputs map[string]interface{}) error {
 	existing, err := s.GetByID(ctx, id, orgID)
 	if err != nil {
 		return err
@@ -487,6 +616,11 @@ func (s *SessionService) MarkNoShow(ctx context.Context, id, orgID uuid.UUID, ma
 		return errors.New("cannot mark cancelled or completed sessions as no-show")
 	}
 
+	transition, err := s.prepareTransition(ctx, orgID, existing.Status, models.SessionStatusNoShow, inputs)
+	if err != nil {
+		return err
+	}
+
 	result, err := s.db.Pool.Exec(ctx, `
 		UPDATE sessions
 		SET status = $1
@@ -506,6 +640,9 @@ func (s *SessionService) MarkNoShow(ctx context.Context, id, orgID uuid.UUID, ma
 
 	// Trigger workflow for state change
 	if s.workflow != nil {
+		if err := s.workflow.RecordTransition(ctx, orgID, "session", id, transition, &markedBy); err != nil {
+			fmt.Printf("Failed to record transition: %v\n", err)
+		}
 		if err := s.workflow.OnSessionStateChange(ctx, orgID, id, string(existing.Status), string(models.SessionStatusNoShow), existing.ScheduledAt); err != nil {
 			fmt.Printf("Failed to trigger workflow: %v\n", err)
 		}
@@ -514,6 +651,14 @@ func (s *SessionService) MarkNoShow(ctx context.Context, id, orgID uuid.UUID, ma
 	return nil
 }
 
+// prepareTransition validates the transition inputs of a session status change
+func (s *SessionService) prepareTransition(ctx context.Context, orgID uuid.UUID, from, to models.SessionStatus, inputs map[string]interface{}) (*TransitionInputs, error) {
+	if s.workflow == nil {
+		return nil, nil
+	}
+	return s.workflow.PrepareTransition(ctx, orgID, models.WorkflowModuleAppointments, models.WorkflowEntitySession, string(from), string(to), inputs)
+}
+
 // Delete soft deletes a session
 func (s *SessionService) Delete(ctx context.Context, id, orgID uuid.UUID) error {
 	result, err := s.db.Pool.Exec(ctx, `
@@ -533,8 +678,124 @@ func (s *SessionService) Delete(ctx context.Context, id, orgID uuid.UUID) error
 	return nil
 }
 
+// applyBookableService fills the unset session fields from its catalogue service
+// and checks that the therapist provides the service
+func (s *SessionService) applyBookableService(ctx context.Context, session *models.Session) error {
+	bs, err := getBookableService(ctx, s.db, *session.ServiceID, session.OrganizationID)
+	if err != nil {
+		return err
+	}
+	if !bs.IsActive {
+		return errors.New("service is not active")
+	}
+
+	if session.DurationMinutes == 0 {
+		session.DurationMinutes = bs.DurationMinutes
+	}
+	if session.PriceCents == 0 {
+		session.PriceCents = bs.PriceCents
+	}
+	if session.SessionType == "" {
+		session.SessionType = bs.SessionType
+	}
+
+	if session.TherapistID != uuid.Nil && !allowsTherapist(bs, session.TherapistID) {
+		return errors.New("therapist does not provide this service")
+	}
+
+	return nil
+}
+
+// overrideStatusFor returns the initial status of a double-booking override.
+// Overrides need approval when the appointments module config sets "double_booking_requires_approval",
+// unless they are made by an admin or manager.
+func (s *SessionService) overrideStatusFor(ctx context.Context, orgID, userID uuid.UUID) (models.SessionOverrideStatus, error) {
+	var requiresApproval bool
+	var role string
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT
+			COALESCE((
+				SELECT (config->>'double_booking_requires_approval')::boolean
+				FROM organization_modules
+				WHERE organization_id = $1 AND module_name = $2
+			), false),
+			COALESCE((SELECT role FROM users WHERE id = $3), '')
+	`, orgID, models.ModuleAppointments, userID).Scan(&requiresApproval, &role)
+	if err != nil {
+		return "", fmt.Errorf("failed to check override approval: %w", err)
+	}
+
+	if requiresApproval && role != string(models.RoleAdmin) && role != string(models.RoleManager) {
+		return models.SessionOverridePending, nil
+	}
+	return models.SessionOverrideApproved, nil
+}
+
+// ApproveOverride approves a pending double-booking override
+func (s *SessionService) ApproveOverride(ctx context.Context, id, orgID uuid.UUID, approvedBy uuid.UUID) error {
+	existing, err := s.GetByID(ctx, id, orgID)
+	if err != nil {
+		return err
+	}
+
+	if existing.OverrideStatus == nil || *existing.OverrideStatus != models.SessionOverridePending {
+		return errors.New("session has no pending conflict override")
+	}
+
+	_, err = s.db.Pool.Exec(ctx, `
+		UPDATE sessions
+		SET override_status = $1, override_decided_by = $2, override_decided_at = NOW()
+		WHERE id = $3 AND organization_id = $4
+	`, models.SessionOverrideApproved, approvedBy, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to approve override: %w", err)
+	}
+
+	s.recordHistory(ctx, id, "conflict_override_approved", &existing.Session, nil, &approvedBy)
+
+	return nil
+}
+
+// RejectOverride rejects a pending double-booking override and cancels the session free of charge
+func (s *SessionService) RejectOverride(ctx context.Context, id, orgID uuid.UUID, comment string, rejectedBy uuid.UUID) error {
+	existing, err := s.GetByID(ctx, id, orgID)
+	if err != nil {
+		return err
+	}
+
+	if existing.OverrideStatus == nil || *existing.OverrideStatus != models.SessionOverridePending {
+		return errors.New("session has no pending conflict override")
+	}
+
+	_, err = s.db.Pool.Exec(ctx, `
+		UPDATE sessions
+		SET override_status = $1, override_decided_by = $2, override_decided_at = NOW()
+		WHERE id = $3 AND organization_id = $4
+	`, models.SessionOverrideRejected, rejectedBy, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to reject override: %w", err)
+	}
+
+	s.recordHistory(ctx, id, "conflict_override_rejected", &existing.Session, nil, &rejectedBy)
+
+	reason := "Conflict override rejected"
+	if comment != "" {
+		reason += ": " + comment
+	}
+	if _, err := s.Cancel(ctx, id, orgID, reason, rejectedBy, true, nil); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // hasConflict checks if there's a scheduling conflict
 func (s *SessionService) hasConflict(ctx context.Context, orgID, therapistID uuid.UUID, start, end time.Time, excludeID *uuid.UUID) (bool, error) {
+	return sessionConflict(ctx, s.db.Pool, orgID, therapistID, start, end, excludeID)
+}
+
+// sessionConflict checks for an overlapping session, also within a transaction
+func sessionConflict(ctx context.Context, q rowQuerier, orgID, therapistID uuid.UUID, start, end time.Time, excludeID *uuid.UUID) (bool, error) {
 	query := `
 		SELECT EXISTS(
 			SELECT 1 FROM sessions
@@ -556,7 +817,7 @@ func (s *SessionService) hasConflict(ctx context.Context, orgID, therapistID uui
 	query += ")"
 
 	var hasConflict bool
-	err := s.db.Pool.QueryRow(ctx, query, args...).Scan(&hasConflict)
+	err := q.QueryRow(ctx, query, args...).Scan(&hasConflict)
 	return hasConflict, err
 }
 
@@ -626,11 +887,15 @@ func (s *SessionService) GetStats(ctx context.Context, orgID uuid.UUID) (map[str
 
 // SessionFilters represents filters for session queries
 type SessionFilters struct {
-	TherapistID *uuid.UUID
-	PatientID   *uuid.UUID
-	Status      *models.SessionStatus
-	StartDate   *time.Time
-	EndDate     *time.Time
-	Limit       int
-	Offset      int
+	TherapistID    *uuid.UUID
+	PatientID      *uuid.UUID
+	Status         *models.SessionStatus
+	OverrideStatus *models.SessionOverrideStatus
+	SeriesID       *uuid.UUID
+	ProjectID      *uuid.UUID
+	StartDate      *time.Time
+	EndDate        *time.Time
+	Search         string // matches the patient's name, phone or email
+	Limit          int
+	Offset         int
 }
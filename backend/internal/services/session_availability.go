@@ -0,0 +1,248 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+const (
+	// availabilitySearchDays is how far around the requested time nearest slots are looked for
+	availabilitySearchDays = 7
+	// availabilityStep is the granularity of suggested start times
+	availabilityStep = 15 * time.Minute
+	// availabilityAlternatives is how many nearest slots and other therapists are suggested
+	availabilityAlternatives = 3
+)
+
+// SessionAvailabilityRequest is a session time to check before booking it
+type SessionAvailabilityRequest struct {
+	TherapistID      uuid.UUID
+	ScheduledAt      time.Time
+	DurationMinutes  int        // 0 uses the service duration, or the therapist's default session duration
+	ServiceID        *uuid.UUID // optional, the therapist must provide the service
+	ExcludeSessionID *uuid.UUID // the session being moved, when editing
+}
+
+// CheckAvailability reports whether a therapist can take a session at the requested time, with
+// the details of every conflict and the nearest free alternatives. Nothing is created.
+func (s *SessionService) CheckAvailability(ctx context.Context, orgID uuid.UUID, req SessionAvailabilityRequest) (*models.SessionAvailability, error) {
+	if req.ScheduledAt.IsZero() {
+		return nil, errors.New("scheduled time is required")
+	}
+
+	var service *models.BookableService
+	if req.ServiceID != nil {
+		bs, err := getBookableService(ctx, s.db, *req.ServiceID, orgID)
+		if err != nil {
+			return nil, err
+		}
+		service = bs
+		if req.DurationMinutes <= 0 {
+			req.DurationMinutes = bs.DurationMinutes
+		}
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, user_id, name, working_hours, COALESCE(session_duration_minutes, 60), timezone
+		FROM therapists
+		WHERE organization_id = $1 AND is_active = true AND deleted_at IS NULL
+		ORDER BY name
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query therapists: %w", err)
+	}
+	var therapists []*models.Therapist
+	var therapist *models.Therapist
+	for rows.Next() {
+		var t models.Therapist
+		if err := rows.Scan(&t.ID, &t.UserID, &t.Name, &t.WorkingHours, &t.SessionDurationMinutes, &t.Timezone); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan therapist: %w", err)
+		}
+		if t.ID == req.TherapistID {
+			therapist = &t
+		}
+		therapists = append(therapists, &t)
+	}
+	rows.Close()
+	if therapist == nil {
+		return nil, errors.New("therapist not found")
+	}
+
+	duration := req.DurationMinutes
+	if duration <= 0 {
+		duration = therapist.SessionDurationMinutes
+	}
+	if duration <= 0 {
+		duration = 60
+	}
+	length := time.Duration(duration) * time.Minute
+	start, end := req.ScheduledAt, req.ScheduledAt.Add(length)
+
+	result := &models.SessionAvailability{
+		TherapistID:     therapist.ID,
+		Start:           start,
+		End:             end,
+		Conflicts:       []models.AvailabilityConflict{},
+		NearestSlots:    []models.BookingSlot{},
+		OtherTherapists: []models.BookingSlot{},
+	}
+
+	if !start.After(time.Now()) {
+		result.Conflicts = append(result.Conflicts, models.AvailabilityConflict{
+			Kind: models.AvailabilityConflictPast, Message: "the requested time has already passed",
+		})
+	}
+	if service != nil && !allowsTherapist(service, therapist.ID) {
+		result.Conflicts = append(result.Conflicts, models.AvailabilityConflict{
+			Kind: models.AvailabilityConflictService, Message: fmt.Sprintf("%s does not provide %s", therapist.Name, service.Name),
+		})
+	}
+	if ok, reason := worksAt(therapist, start, duration); !ok {
+		result.Conflicts = append(result.Conflicts, models.AvailabilityConflict{
+			Kind: models.AvailabilityConflictWorkingHours, Message: reason,
+		})
+	}
+	conflicts, err := s.timeConflicts(ctx, orgID, therapist, start, end, req.ExcludeSessionID)
+	if err != nil {
+		return nil, err
+	}
+	result.Conflicts = append(result.Conflicts, conflicts...)
+	result.Available = len(result.Conflicts) == 0
+	if result.Available {
+		return result, nil
+	}
+
+	// Alternatives: the same therapist around the requested time, and others at that time
+	from := start.AddDate(0, 0, -availabilitySearchDays)
+	if now := time.Now(); from.Before(now) {
+		from = now
+	}
+	to := start.AddDate(0, 0, availabilitySearchDays)
+	busy, err := busyIntervals(ctx, s.db, orgID, therapists, from, to.Add(length), req.ExcludeSessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if service == nil || allowsTherapist(service, therapist.ID) {
+		result.NearestSlots = nearestFreeSlots(therapist, busy[therapist.ID], start, from, to, length)
+	}
+	for _, t := range therapists {
+		if len(result.OtherTherapists) == availabilityAlternatives {
+			break
+		}
+		if t.ID == therapist.ID || (service != nil && !allowsTherapist(service, t.ID)) {
+			continue
+		}
+		if ok, _ := worksAt(t, start, duration); !ok || overlapsAny(busy[t.ID], start, end) || !start.After(time.Now()) {
+			continue
+		}
+		result.OtherTherapists = append(result.OtherTherapists, models.BookingSlot{
+			Start: start, End: end, TherapistID: t.ID, TherapistName: t.Name,
+		})
+	}
+
+	return result, nil
+}
+
+// timeConflicts returns the sessions and absences of the therapist overlapping the period
+func (s *SessionService) timeConflicts(ctx context.Context, orgID uuid.UUID, t *models.Therapist, start, end time.Time, excludeSessionID *uuid.UUID) ([]models.AvailabilityConflict, error) {
+	var conflicts []models.AvailabilityConflict
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT s.id, s.scheduled_at, s.scheduled_at + (s.duration_minutes * interval '1 minute'), s.status, COALESCE(c.name, '')
+		FROM sessions s
+		JOIN patients p ON p.id = s.patient_id
+		LEFT JOIN clients c ON c.id = p.client_id
+		WHERE s.organization_id = $1 AND s.therapist_id = $2 AND s.deleted_at IS NULL
+		AND s.status != 'cancelled'
+		AND s.scheduled_at < $4 AND s.scheduled_at + (s.duration_minutes * interval '1 minute') > $3
+		AND ($5::uuid IS NULL OR s.id != $5)
+		ORDER BY s.scheduled_at
+	`, orgID, t.ID, start, end, excludeSessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions: %w", err)
+	}
+	for rows.Next() {
+		var c models.AvailabilityConflict
+		var sessionID uuid.UUID
+		var sessionStart, sessionEnd time.Time
+		var status models.SessionStatus
+		var patientName string
+		if err := rows.Scan(&sessionID, &sessionStart, &sessionEnd, &status, &patientName); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		c.Kind = models.AvailabilityConflictSession
+		c.Message = fmt.Sprintf("%s already has a session from %s to %s", t.Name, sessionStart.Format("15:04"), sessionEnd.Format("15:04"))
+		c.Start, c.End = &sessionStart, &sessionEnd
+		c.SessionID, c.PatientName, c.Status = &sessionID, &patientName, &status
+		conflicts = append(conflicts, c)
+	}
+	rows.Close()
+
+	rows, err = s.db.Pool.Query(ctx, `
+		SELECT starts_at, ends_at
+		FROM user_out_of_office
+		WHERE organization_id = $1 AND user_id = $2 AND is_active = true
+		AND starts_at < $4 AND ends_at > $3
+		ORDER BY starts_at
+	`, orgID, t.UserID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query absences: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var absenceStart, absenceEnd time.Time
+		if err := rows.Scan(&absenceStart, &absenceEnd); err != nil {
+			return nil, fmt.Errorf("failed to scan absence: %w", err)
+		}
+		conflicts = append(conflicts, models.AvailabilityConflict{
+			Kind:    models.AvailabilityConflictAbsence,
+			Message: fmt.Sprintf("%s is out of office until %s", t.Name, absenceEnd.Format("02/01/2006 15:04")),
+			Start:   &absenceStart,
+			End:     &absenceEnd,
+		})
+	}
+
+	return conflicts, nil
+}
+
+// nearestFreeSlots returns the free start times of the therapist between from and to closest to
+// the requested time, stepping through each working day
+func nearestFreeSlots(t *models.Therapist, busy []busyInterval, requested, from, to time.Time, length time.Duration) []models.BookingSlot {
+	var slots []models.BookingSlot
+	loc := therapistLocation(t)
+	for day := from.In(loc); !day.After(to); day = day.AddDate(0, 0, 1) {
+		dayStart, dayEnd, err := workingWindow(t, day)
+		if err != nil || dayStart.IsZero() {
+			continue
+		}
+		for start := dayStart; !start.Add(length).After(dayEnd); start = start.Add(availabilityStep) {
+			end := start.Add(length)
+			if start.Before(from) || start.After(to) || start.Equal(requested) || overlapsAny(busy, start, end) {
+				continue
+			}
+			slots = append(slots, models.BookingSlot{Start: start, End: end, TherapistID: t.ID, TherapistName: t.Name})
+		}
+	}
+
+	distance := func(slot models.BookingSlot) time.Duration {
+		d := slot.Start.Sub(requested)
+		if d < 0 {
+			return -d
+		}
+		return d
+	}
+	sort.SliceStable(slots, func(i, j int) bool { return distance(slots[i]) < distance(slots[j]) })
+	if len(slots) > availabilityAlternatives {
+		slots = slots[:availabilityAlternatives]
+	}
+	return slots
+}
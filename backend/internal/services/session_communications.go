@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// UpcomingCommunications lists the reminders and time-based workflow messages still scheduled for
+// a session, rendered with the same templates and data used when they are sent
+func (s *SessionService) UpcomingCommunications(ctx context.Context, id, orgID uuid.UUID) ([]models.UpcomingCommunication, error) {
+	var scheduledAt time.Time
+	var sessionType, status, patientName, therapistName, cancellationNote string
+	var patientPhone, patientEmail *string
+	var cancellationFeeCents int
+	var orgName, orgEmail string
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT
+			s.scheduled_at, s.session_type, s.status,
+			COALESCE(c.name, ''), c.phone, c.email,
+			COALESCE(t.name, ''),
+			COALESCE(s.cancellation_fee_cents, 0), COALESCE(s.cancellation_policy_note, ''),
+			o.name, o.email
+		FROM sessions s
+		JOIN organizations o ON o.id = s.organization_id
+		LEFT JOIN patients p ON p.id = s.patient_id
+		LEFT JOIN clients c ON c.id = p.client_id
+		LEFT JOIN therapists t ON t.id = s.therapist_id
+		WHERE s.id = $1 AND s.organization_id = $2 AND s.deleted_at IS NULL
+	`, id, orgID).Scan(
+		&scheduledAt, &sessionType, &status,
+		&patientName, &patientPhone, &patientEmail,
+		&therapistName,
+		&cancellationFeeCents, &cancellationNote,
+		&orgName, &orgEmail,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("session not found")
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	// Same variables the workflow engine renders session messages with, on the organization's wall clock
+	scheduledAt = scheduledAt.In(orgLocation(ctx, s.db.Pool, orgID))
+	data := map[string]interface{}{
+		"session_id":          id.String(),
+		"scheduled_at":        scheduledAt,
+		"session_date":        scheduledAt.Format("02/01/2006"),
+		"session_time":        scheduledAt.Format("15:04"),
+		"session_type":        sessionType,
+		"status":              status,
+		"patient_name":        patientName,
+		"therapist_name":      therapistName,
+		"cancellation_fee":    fmt.Sprintf("%.2f", float64(cancellationFeeCents)/100),
+		"cancellation_policy": cancellationNote,
+		"organization_name":   orgName,
+		"organization_email":  orgEmail,
+	}
+	if patientPhone != nil {
+		data["patient_phone"] = *patientPhone
+	}
+	if patientEmail != nil {
+		data["patient_email"] = *patientEmail
+	}
+
+	var lastInboundAt *time.Time
+	if patientPhone != nil && *patientPhone != "" {
+		err := s.db.Pool.QueryRow(ctx, `
+			SELECT last_inbound_at FROM whatsapp_conversations
+			WHERE organization_id = $1 AND phone_number = $2
+		`, orgID, models.NormalizeWhatsAppPhone(*patientPhone)).Scan(&lastInboundAt)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("failed to get WhatsApp conversation: %w", err)
+		}
+	}
+
+	reminders, err := s.upcomingReminders(ctx, id, orgID, patientName, therapistName, scheduledAt, patientPhone)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := s.upcomingWorkflowMessages(ctx, id, orgID, data, lastInboundAt)
+	if err != nil {
+		return nil, err
+	}
+
+	communications := append(reminders, messages...)
+	sort.SliceStable(communications, func(i, j int) bool {
+		return communications[i].ScheduledFor.Before(communications[j].ScheduledFor)
+	})
+	return communications, nil
+}
+
+// upcomingReminders renders the pending 24h/2h WhatsApp reminders of a session
+func (s *SessionService) upcomingReminders(ctx context.Context, sessionID, orgID uuid.UUID, patientName, therapistName string, scheduledAt time.Time, phone *string) ([]models.UpcomingCommunication, error) {
+	var config models.NotificationConfig
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT whatsapp_enabled, reminder_24h_enabled, reminder_2h_enabled,
+			reminder_24h_template, reminder_2h_template, reminders_migrated_at
+		FROM notification_configs
+		WHERE organization_id = $1
+	`, orgID).Scan(
+		&config.WhatsAppEnabled,
+		&config.Reminder24hEnabled,
+		&config.Reminder2hEnabled,
+		&config.Reminder24hTemplate,
+		&config.Reminder2hTemplate,
+		&config.RemindersMigratedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get notification config: %w", err)
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, type, scheduled_for
+		FROM scheduled_reminders
+		WHERE session_id = $1 AND status = 'pending'
+		ORDER BY scheduled_for
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled reminders: %w", err)
+	}
+	defer rows.Close()
+
+	var communications []models.UpcomingCommunication
+	for rows.Next() {
+		var reminderType models.ReminderType
+		c := models.UpcomingCommunication{
+			Source:    models.CommunicationSourceReminder,
+			Channel:   models.MessageChannelWhatsApp,
+			Recipient: phone,
+		}
+		if err := rows.Scan(&c.ReferenceID, &reminderType, &c.ScheduledFor); err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled reminder: %w", err)
+		}
+		c.Type = string(reminderType)
+
+		template, skipReason := reminderTemplate(&config, reminderType)
+		switch {
+		case !config.WhatsAppEnabled:
+			skipReason = "WhatsApp notifications disabled"
+		case skipReason == "" && (phone == nil || *phone == ""):
+			skipReason = "patient has no phone number"
+		}
+		if template != "" {
+			c.Preview = buildReminderMessage(template, patientName, therapistName, scheduledAt)
+		}
+		c.WillSend = skipReason == ""
+		if skipReason != "" {
+			c.SkipReason = &skipReason
+		}
+		communications = append(communications, c)
+	}
+	return communications, rows.Err()
+}
+
+// upcomingWorkflowMessages renders the WhatsApp and email actions of the time-based workflow
+// triggers still scheduled for a session
+func (s *SessionService) upcomingWorkflowMessages(ctx context.Context, sessionID, orgID uuid.UUID, data map[string]interface{}, lastInboundAt *time.Time) ([]models.UpcomingCommunication, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT j.id, j.scheduled_for, wt.trigger_type, COALESCE(wt.conditions::text, ''),
+			wa.action_type, wa.action_config,
+			mt.name, mt.channel, mt.subject, mt.body, mt.whatsapp_content_sid
+		FROM scheduled_jobs j
+		JOIN workflow_triggers wt ON wt.id = j.trigger_id
+		JOIN workflow_actions wa ON wa.trigger_id = wt.id
+		LEFT JOIN message_templates mt ON mt.id = wa.template_id
+		WHERE j.organization_id = $1 AND j.entity_type = 'session' AND j.entity_id = $2
+			AND j.status = 'pending' AND wt.is_active = true AND wa.is_active = true
+			AND wa.action_type IN ('send_whatsapp', 'send_email')
+		ORDER BY j.scheduled_for, wa.action_order
+	`, orgID, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled workflow messages: %w", err)
+	}
+	defer rows.Close()
+
+	var communications []models.UpcomingCommunication
+	for rows.Next() {
+		var triggerType, conditions string
+		var actionType models.ActionType
+		var actionConfig json.RawMessage
+		var templateName, subject, body, contentSID *string
+		var templateChannel *models.MessageChannel
+		c := models.UpcomingCommunication{Source: models.CommunicationSourceWorkflow}
+		if err := rows.Scan(&c.ReferenceID, &c.ScheduledFor, &triggerType, &conditions,
+			&actionType, &actionConfig,
+			&templateName, &templateChannel, &subject, &body, &contentSID); err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled workflow message: %w", err)
+		}
+		c.Type = triggerType
+		c.TemplateName = templateName
+		c.Conditional = conditions != "" && conditions != "null" && conditions != "[]" && conditions != "{}"
+
+		var skipReason string
+		if actionType == models.ActionTypeSendWhatsApp {
+			c.Channel = models.MessageChannelWhatsApp
+			if phone, ok := data["patient_phone"].(string); ok && phone != "" {
+				c.Recipient = &phone
+			}
+			switch {
+			case body == nil || templateChannel == nil || *templateChannel != models.MessageChannelWhatsApp:
+				skipReason = "WhatsApp action has no WhatsApp template"
+			case c.Recipient == nil:
+				skipReason = "patient has no phone number"
+			default:
+				c.Preview = renderTemplateString(*body, data)
+				// Outside the customer service window the approved template is sent instead
+				window := models.NewWhatsAppSessionWindow(*c.Recipient, lastInboundAt)
+				if window.ExpiresAt == nil || window.ExpiresAt.Before(c.ScheduledFor) {
+					c.ApprovedTemplate = true
+					if contentSID == nil || *contentSID == "" {
+						skipReason = "WhatsApp window will be closed and the template has no approved version"
+					}
+				}
+			}
+		} else {
+			c.Channel = models.MessageChannelEmail
+			config := parseActionConfigJSON(actionConfig)
+			subjectTemplate, bodyTemplate := "Notificação", ""
+			if body != nil && templateChannel != nil && *templateChannel == models.MessageChannelEmail {
+				bodyTemplate = *body
+				if subject != nil {
+					subjectTemplate = *subject
+				}
+			} else if body == nil {
+				subjectTemplate, _ = config["subject"].(string)
+				bodyTemplate, _ = config["body"].(string)
+				if subjectTemplate == "" {
+					subjectTemplate = "Notificação - {{client_name}}"
+				}
+				if bodyTemplate == "" {
+					bodyTemplate = "Olá {{client_name}},\n\nTem uma nova notificação.\n\nCumprimentos"
+				}
+			} else {
+				skipReason = "template is not an email template"
+			}
+			if skipReason == "" {
+				renderedSubject := renderTemplateString(subjectTemplate, data)
+				c.Subject = &renderedSubject
+				c.Preview = renderTemplateString(bodyTemplate, data)
+			}
+
+			var email string
+			if toField, _ := config["to_field"].(string); toField != "" {
+				email, _ = data[toField].(string)
+			}
+			if email == "" {
+				email, _ = data["patient_email"].(string)
+			}
+			if email != "" {
+				c.Recipient = &email
+			} else if skipReason == "" {
+				skipReason = "patient has no email address"
+			}
+		}
+
+		c.WillSend = skipReason == ""
+		if skipReason != "" {
+			c.SkipReason = &skipReason
+		}
+		communications = append(communications, c)
+	}
+	return communications, rows.Err()
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/events"
 	"github.com/controlwise/backend/internal/models"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -14,7 +15,8 @@ import (
 
 // SessionPaymentService handles session payment operations
 type SessionPaymentService struct {
-	db *database.DB
+	db     *database.DB
+	events *events.Publisher
 }
 
 // NewSessionPaymentService creates a new SessionPaymentService
@@ -22,6 +24,11 @@ func NewSessionPaymentService(db *database.DB) *SessionPaymentService {
 	return &SessionPaymentService{db: db}
 }
 
+// SetEventPublisher sets the publisher received payments are streamed to dashboards with
+func (s *SessionPaymentService) SetEventPublisher(p *events.Publisher) {
+	s.events = p
+}
+
 // SessionPaymentFilters contains filters for listing session payments
 type SessionPaymentFilters struct {
 	Status      *string
@@ -39,14 +46,14 @@ func (s *SessionPaymentService) GetBySessionID(ctx context.Context, sessionID, o
 	err := s.db.Pool.QueryRow(ctx, `
 		SELECT sp.id, sp.session_id, sp.amount_cents, sp.payment_status, sp.payment_method,
 		       sp.insurance_provider, sp.insurance_amount_cents, sp.due_date, sp.paid_at,
-		       sp.notes, sp.created_at, sp.updated_at
+		       sp.notes, sp.kind, sp.mb_entity, sp.mb_reference, sp.sepa_reference, sp.created_at, sp.updated_at
 		FROM session_payments sp
 		JOIN sessions s ON s.id = sp.session_id
 		WHERE sp.session_id = $1 AND s.organization_id = $2
 	`, sessionID, orgID).Scan(
 		&p.ID, &p.SessionID, &p.AmountCents, &p.PaymentStatus, &p.PaymentMethod,
 		&p.InsuranceProvider, &p.InsuranceAmountCents, &p.DueDate, &p.PaidAt,
-		&p.Notes, &p.CreatedAt, &p.UpdatedAt,
+		&p.Notes, &p.Kind, &p.MBEntity, &p.MBReference, &p.SEPAReference, &p.CreatedAt, &p.UpdatedAt,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -61,9 +68,10 @@ func (s *SessionPaymentService) GetBySessionID(ctx context.Context, sessionID, o
 func (s *SessionPaymentService) CreateOrUpdate(ctx context.Context, sessionID, orgID uuid.UUID, payment *models.SessionPayment) error {
 	// Verify session exists and belongs to organization
 	var sessionOrgID uuid.UUID
+	var scheduledAt time.Time
 	err := s.db.Pool.QueryRow(ctx, `
-		SELECT organization_id FROM sessions WHERE id = $1 AND deleted_at IS NULL
-	`, sessionID).Scan(&sessionOrgID)
+		SELECT organization_id, scheduled_at FROM sessions WHERE id = $1 AND deleted_at IS NULL
+	`, sessionID).Scan(&sessionOrgID, &scheduledAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return errors.New("session not found")
@@ -80,6 +88,15 @@ func (s *SessionPaymentService) CreateOrUpdate(ctx context.Context, sessionID, o
 		return err
 	}
 
+	// Neither the month the payment was in nor the one it moves to may be closed
+	dates := []time.Time{paymentPeriodDate(payment.DueDate, payment.PaidAt, scheduledAt)}
+	if existing != nil {
+		dates = append(dates, paymentPeriodDate(existing.DueDate, existing.PaidAt, scheduledAt))
+	}
+	if err := checkPeriodsOpen(ctx, s.db.Pool, orgID, dates...); err != nil {
+		return err
+	}
+
 	if existing != nil {
 		// Update existing record
 		_, err = s.db.Pool.Exec(ctx, `
@@ -117,6 +134,9 @@ func (s *SessionPaymentService) CreateOrUpdate(ctx context.Context, sessionID, o
 // MarkAsPaid marks a session payment as paid
 func (s *SessionPaymentService) MarkAsPaid(ctx context.Context, sessionID, orgID uuid.UUID, method *models.PaymentMethod) error {
 	now := time.Now()
+	if err := checkPeriodsOpen(ctx, s.db.Pool, orgID, now); err != nil {
+		return err
+	}
 	result, err := s.db.Pool.Exec(ctx, `
 		UPDATE session_payments sp
 		SET payment_status = 'paid', payment_method = $1, paid_at = $2, updated_at = NOW()
@@ -129,6 +149,24 @@ func (s *SessionPaymentService) MarkAsPaid(ctx context.Context, sessionID, orgID
 	if result.RowsAffected() == 0 {
 		return errors.New("payment record not found")
 	}
+
+	// Dunning scheduled when the payment was created stops once it is paid
+	if _, err := s.db.Pool.Exec(ctx, `
+		UPDATE scheduled_jobs sj
+		SET status = 'cancelled'
+		FROM workflow_triggers t
+		WHERE t.id = sj.trigger_id AND t.trigger_type = $1
+		AND sj.entity_type = 'session' AND sj.entity_id = $2 AND sj.status = 'pending'
+	`, models.TriggerTypePaymentOverdue, sessionID); err != nil {
+		fmt.Printf("Failed to cancel dunning jobs: %v\n", err)
+	}
+
+	s.events.Publish(ctx, orgID, models.DashboardEvent{
+		Type:       models.DashboardEventPaymentReceived,
+		EntityType: "session",
+		EntityID:   sessionID,
+		Data:       map[string]interface{}{"method": method},
+	})
 	return nil
 }
 
@@ -191,6 +229,10 @@ func (s *SessionPaymentService) ListUnpaid(ctx context.Context, orgID uuid.UUID,
 			sp.due_date,
 			sp.paid_at,
 			sp.notes,
+			COALESCE(sp.kind, 'session') as kind,
+			sp.mb_entity,
+			sp.mb_reference,
+			sp.sepa_reference,
 			COALESCE(sp.created_at, s.created_at) as created_at,
 			COALESCE(sp.updated_at, s.updated_at) as updated_at,
 			c.name as patient_name,
@@ -220,7 +262,7 @@ func (s *SessionPaymentService) ListUnpaid(ctx context.Context, orgID uuid.UUID,
 		err := rows.Scan(
 			&p.ID, &p.SessionID, &p.AmountCents, &p.PaymentStatus, &p.PaymentMethod,
 			&p.InsuranceProvider, &p.InsuranceAmountCents, &p.DueDate, &p.PaidAt,
-			&p.Notes, &p.CreatedAt, &p.UpdatedAt,
+			&p.Notes, &p.Kind, &p.MBEntity, &p.MBReference, &p.SEPAReference, &p.CreatedAt, &p.UpdatedAt,
 			&p.PatientName, &p.TherapistName, &p.ScheduledAt,
 		)
 		if err != nil {
@@ -302,6 +344,10 @@ func (s *SessionPaymentService) ListByPatient(ctx context.Context, patientID, or
 			sp.due_date,
 			sp.paid_at,
 			sp.notes,
+			COALESCE(sp.kind, 'session') as kind,
+			sp.mb_entity,
+			sp.mb_reference,
+			sp.sepa_reference,
 			COALESCE(sp.created_at, s.created_at) as created_at,
 			COALESCE(sp.updated_at, s.updated_at) as updated_at,
 			c.name as patient_name,
@@ -326,7 +372,7 @@ func (s *SessionPaymentService) ListByPatient(ctx context.Context, patientID, or
 		err := rows.Scan(
 			&p.ID, &p.SessionID, &p.AmountCents, &p.PaymentStatus, &p.PaymentMethod,
 			&p.InsuranceProvider, &p.InsuranceAmountCents, &p.DueDate, &p.PaidAt,
-			&p.Notes, &p.CreatedAt, &p.UpdatedAt,
+			&p.Notes, &p.Kind, &p.MBEntity, &p.MBReference, &p.SEPAReference, &p.CreatedAt, &p.UpdatedAt,
 			&p.PatientName, &p.TherapistName, &p.ScheduledAt,
 		)
 		if err != nil {
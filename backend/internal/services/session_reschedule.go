@@ -0,0 +1,292 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// SessionRescheduleService handles the links with which patients move their own sessions to
+// another free slot of the same therapist
+type SessionRescheduleService struct {
+	db          *database.DB
+	sessions    *SessionService
+	booking     *BookingService
+	frontendURL string
+}
+
+func NewSessionRescheduleService(db *database.DB, sessions *SessionService, booking *BookingService, frontendURL string) *SessionRescheduleService {
+	return &SessionRescheduleService{
+		db:          db,
+		sessions:    sessions,
+		booking:     booking,
+		frontendURL: strings.TrimRight(frontendURL, "/"),
+	}
+}
+
+// canReschedule reports whether a session in the status can still be moved by the patient
+func canReschedule(status models.SessionStatus, scheduledAt, now time.Time) bool {
+	if status != models.SessionStatusPending && status != models.SessionStatusConfirmed {
+		return false
+	}
+	return scheduledAt.After(now)
+}
+
+// LinkFor returns a new reschedule link of a session for the {{reschedule_link}} template variable,
+// or "" when the session can no longer be moved. It satisfies the workflow executor's RescheduleLinker.
+func (s *SessionRescheduleService) LinkFor(ctx context.Context, orgID uuid.UUID, entityType string, entityID uuid.UUID) (string, error) {
+	if entityType != "session" || s.frontendURL == "" {
+		return "", nil
+	}
+
+	var status models.SessionStatus
+	var scheduledAt time.Time
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT status, scheduled_at FROM sessions
+		WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`, entityID, orgID).Scan(&status, &scheduledAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get session: %w", err)
+	}
+	if !canReschedule(status, scheduledAt, time.Now()) {
+		return "", nil
+	}
+
+	token, tokenHash, err := newInvitationToken()
+	if err != nil {
+		return "", err
+	}
+	_, err = s.db.Pool.Exec(ctx, `
+		INSERT INTO session_reschedule_links (organization_id, session_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`, orgID, entityID, tokenHash, scheduledAt)
+	if err != nil {
+		return "", fmt.Errorf("failed to create reschedule link: %w", err)
+	}
+
+	return fmt.Sprintf("%s/reschedule/%s", s.frontendURL, token), nil
+}
+
+// HandleInbound answers a WhatsApp request to move a session with a reschedule link of the sender's
+// next session. It is the WhatsApp service's handler of the reschedule intent.
+func (s *SessionRescheduleService) HandleInbound(ctx context.Context, msg *InboundMessage) (map[string]string, error) {
+	session, err := nextSessionForPhone(ctx, s.db.Pool, msg.OrganizationID, msg.From)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, ErrInboundIntentUnhandled
+	}
+	link, err := s.LinkFor(ctx, msg.OrganizationID, "session", session.ID)
+	if err != nil {
+		return nil, err
+	}
+	if link == "" {
+		return nil, ErrInboundIntentUnhandled
+	}
+
+	vars := sessionReplyVars(session)
+	vars["reschedule_link"] = link
+	return vars, nil
+}
+
+// rescheduleLink is an unused, unexpired reschedule link with its session
+type rescheduleLink struct {
+	id          uuid.UUID
+	orgID       uuid.UUID
+	sessionID   uuid.UUID
+	therapistID uuid.UUID
+	status      models.SessionStatus
+	view        models.SessionRescheduleView
+}
+
+// linkByToken returns the link of a token, failing the same way whether it never existed,
+// was used, expired or its session can no longer be moved
+func (s *SessionRescheduleService) linkByToken(ctx context.Context, token string) (*rescheduleLink, error) {
+	var l rescheduleLink
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT l.id, l.organization_id, l.session_id, l.expires_at,
+			s.therapist_id, s.status, s.scheduled_at, s.duration_minutes,
+			o.name, COALESCE(c.name, ''), COALESCE(t.name, ''), bs.name
+		FROM session_reschedule_links l
+		JOIN sessions s ON s.id = l.session_id AND s.deleted_at IS NULL
+		JOIN organizations o ON o.id = l.organization_id
+		LEFT JOIN patients p ON p.id = s.patient_id
+		LEFT JOIN clients c ON c.id = p.client_id
+		LEFT JOIN therapists t ON t.id = s.therapist_id
+		LEFT JOIN bookable_services bs ON bs.id = s.service_id
+		WHERE l.token_hash = $1 AND l.used_at IS NULL AND l.expires_at > NOW()
+	`, hashInvitationToken(token)).Scan(&l.id, &l.orgID, &l.sessionID, &l.view.ExpiresAt,
+		&l.therapistID, &l.status, &l.view.ScheduledAt, &l.view.DurationMinutes,
+		&l.view.OrganizationName, &l.view.PatientName, &l.view.TherapistName, &l.view.ServiceName)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("reschedule link is invalid or has expired")
+		}
+		return nil, fmt.Errorf("failed to get reschedule link: %w", err)
+	}
+	if !canReschedule(l.status, l.view.ScheduledAt, time.Now()) {
+		return nil, errors.New("reschedule link is invalid or has expired")
+	}
+	if err := s.booking.checkPublicBooking(ctx, l.orgID); err != nil {
+		if err.Error() == "organization not found" {
+			return nil, errors.New("reschedule link is invalid or has expired")
+		}
+		return nil, err
+	}
+	l.view.SessionID = l.sessionID
+	return &l, nil
+}
+
+// View returns the session a reschedule link moves
+func (s *SessionRescheduleService) View(ctx context.Context, token string) (*models.SessionRescheduleView, error) {
+	l, err := s.linkByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return &l.view, nil
+}
+
+// Slots returns the free slots of the session's therapist between from and to, for the session's duration
+func (s *SessionRescheduleService) Slots(ctx context.Context, token string, from, to time.Time) ([]models.BookingSlot, error) {
+	l, err := s.linkByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if !to.After(from) {
+		return nil, errors.New("end date must be after start date")
+	}
+	if to.Sub(from) > maxSlotRangeDays*24*time.Hour {
+		return nil, fmt.Errorf("date range cannot exceed %d days", maxSlotRangeDays)
+	}
+
+	return s.freeSlots(ctx, l, from, to)
+}
+
+// freeSlots returns the slots the session can move to, leaving out the session itself and its current time
+func (s *SessionRescheduleService) freeSlots(ctx context.Context, l *rescheduleLink, from, to time.Time) ([]models.BookingSlot, error) {
+	var t models.Therapist
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT id, user_id, name, working_hours, timezone
+		FROM therapists
+		WHERE id = $1 AND organization_id = $2 AND is_active = true AND deleted_at IS NULL
+	`, l.therapistID, l.orgID).Scan(&t.ID, &t.UserID, &t.Name, &t.WorkingHours, &t.Timezone)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []models.BookingSlot{}, nil
+		}
+		return nil, fmt.Errorf("failed to get therapist: %w", err)
+	}
+
+	busy, err := busyIntervals(ctx, s.db, l.orgID, []*models.Therapist{&t}, from, to, &l.sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	duration := time.Duration(l.view.DurationMinutes) * time.Minute
+	slots := []models.BookingSlot{}
+	for _, slot := range therapistSlots(&t, busy[t.ID], from, to, duration, time.Now()) {
+		if !slot.Start.Equal(l.view.ScheduledAt) {
+			slots = append(slots, slot)
+		}
+	}
+	return slots, nil
+}
+
+// Reschedule moves the session of a reschedule link to one of its free slots and uses up the link.
+// The session keeps its status and its workflow runs again for that status, so reminders follow
+// the new time and on-enter messages tell the patient about it.
+func (s *SessionRescheduleService) Reschedule(ctx context.Context, token string, start time.Time) (*models.SessionRescheduleView, error) {
+	l, err := s.linkByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if start.IsZero() {
+		return nil, errors.New("scheduled time is required")
+	}
+
+	// The new time must be one of the slots offered
+	duration := time.Duration(l.view.DurationMinutes) * time.Minute
+	slots, err := s.freeSlots(ctx, l, start, start.Add(duration))
+	if err != nil {
+		return nil, err
+	}
+	if len(slots) == 0 {
+		return nil, errors.New("the selected time is no longer available")
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the therapist so two patients cannot take the same slot at once
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM therapists WHERE id = $1 FOR UPDATE`, l.therapistID); err != nil {
+		return nil, fmt.Errorf("failed to lock therapist: %w", err)
+	}
+
+	result, err := tx.Exec(ctx, `
+		UPDATE session_reschedule_links
+		SET used_at = NOW(), previous_scheduled_at = $1, new_scheduled_at = $2
+		WHERE id = $3 AND used_at IS NULL
+	`, l.view.ScheduledAt, start, l.id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to use reschedule link: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, errors.New("reschedule link is invalid or has expired")
+	}
+
+	conflict, err := sessionConflict(ctx, tx, l.orgID, l.therapistID, start, start.Add(duration), &l.sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check conflicts: %w", err)
+	}
+	if conflict {
+		return nil, errors.New("the selected time is no longer available")
+	}
+
+	result, err = tx.Exec(ctx, `
+		UPDATE sessions SET scheduled_at = $1, updated_at = NOW()
+		WHERE id = $2 AND organization_id = $3 AND status IN ('pending', 'confirmed') AND deleted_at IS NULL
+	`, start, l.sessionID, l.orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reschedule session: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, errors.New("reschedule link is invalid or has expired")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit reschedule: %w", err)
+	}
+
+	previous := models.Session{ID: l.sessionID, ScheduledAt: l.view.ScheduledAt}
+	moved := models.Session{ID: l.sessionID, ScheduledAt: start}
+	s.sessions.recordHistory(ctx, l.sessionID, "rescheduled_by_patient", &previous, &moved, nil)
+
+	// Plan the session's reminders again for the new time
+	if s.sessions.workflow != nil {
+		if err := s.sessions.workflow.OnSessionStateChange(ctx, l.orgID, l.sessionID, string(l.status), string(l.status), start); err != nil {
+			log.Printf("[SessionReschedule] Failed to trigger workflow for session %s: %v", l.sessionID, err)
+		}
+	}
+
+	log.Printf("[SessionReschedule] Session %s moved by the patient from %s to %s", l.sessionID, l.view.ScheduledAt, start)
+
+	view := l.view
+	view.ScheduledAt = start
+	return &view, nil
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+)
+
+func TestCanReschedule(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		status      models.SessionStatus
+		scheduledAt time.Time
+		want        bool
+	}{
+		{"pending upcoming", models.SessionStatusPending, now.Add(time.Hour), true},
+		{"confirmed upcoming", models.SessionStatusConfirmed, now.Add(time.Hour), true},
+		{"already started", models.SessionStatusConfirmed, now.Add(-time.Minute), false},
+		{"cancelled", models.SessionStatusCancelled, now.Add(time.Hour), false},
+		{"completed", models.SessionStatusCompleted, now.Add(time.Hour), false},
+	}
+
+	for _, tt := range tests {
+		if got := canReschedule(tt.status, tt.scheduledAt, now); got != tt.want {
+			t.Errorf("%s: canReschedule() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
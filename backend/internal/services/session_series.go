@@ -0,0 +1,453 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxSeriesOccurrences caps a series at about two years of weekly sessions
+const maxSeriesOccurrences = 104
+
+var recurrenceWeekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// expandRecurrence returns the start times of a rule's occurrences, the first of which is start.
+// Occurrences keep start's wall-clock time in loc, so they don't shift across DST changes.
+func expandRecurrence(rule models.RecurrenceRule, start time.Time, loc *time.Location) ([]time.Time, error) {
+	interval := 1
+	switch rule.Frequency {
+	case models.RecurrenceWeekly:
+	case models.RecurrenceBiweekly:
+		interval = 2
+	default:
+		return nil, errors.New("frequency must be weekly or biweekly")
+	}
+
+	if rule.Count == nil && rule.Until == nil {
+		return nil, errors.New("either count or until is required")
+	}
+	if rule.Count != nil && (*rule.Count < 1 || *rule.Count > maxSeriesOccurrences) {
+		return nil, fmt.Errorf("count must be between 1 and %d", maxSeriesOccurrences)
+	}
+
+	local := start.In(loc)
+	days := map[time.Weekday]bool{}
+	for _, code := range rule.Weekdays {
+		day, ok := recurrenceWeekdays[strings.ToUpper(code)]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday: %s", code)
+		}
+		days[day] = true
+	}
+	if len(days) == 0 {
+		days[local.Weekday()] = true
+	}
+	if !days[local.Weekday()] {
+		return nil, errors.New("the first session must fall on one of the weekdays")
+	}
+
+	var last time.Time
+	if rule.Until != nil {
+		last = time.Date(rule.Until.Year(), rule.Until.Month(), rule.Until.Day(), 23, 59, 59, 0, loc)
+		if last.Before(start) {
+			return nil, errors.New("until must not be before the first session")
+		}
+	}
+
+	// Weeks start on Monday
+	monday := local.AddDate(0, 0, -((int(local.Weekday()) + 6) % 7))
+	var occurrences []time.Time
+	for week := 0; ; week += interval {
+		for offset := 0; offset < 7; offset++ {
+			day := monday.AddDate(0, 0, week*7+offset)
+			if !days[day.Weekday()] {
+				continue
+			}
+			at := time.Date(day.Year(), day.Month(), day.Day(), local.Hour(), local.Minute(), local.Second(), 0, loc)
+			if at.Before(start) {
+				continue
+			}
+			if rule.Until != nil && at.After(last) {
+				return occurrences, nil
+			}
+			occurrences = append(occurrences, at)
+			if rule.Count != nil && len(occurrences) == *rule.Count {
+				return occurrences, nil
+			}
+			if len(occurrences) > maxSeriesOccurrences {
+				return nil, fmt.Errorf("a series can have at most %d sessions", maxSeriesOccurrences)
+			}
+		}
+	}
+}
+
+// therapistTimezone returns the location the therapist's wall-clock times are in
+func (s *SessionService) therapistTimezone(ctx context.Context, therapistID, orgID uuid.UUID) (*time.Location, error) {
+	var t models.Therapist
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT timezone FROM therapists
+		WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`, therapistID, orgID).Scan(&t.Timezone)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.New("therapist not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get therapist: %w", err)
+	}
+	return therapistLocation(&t), nil
+}
+
+// CreateRecurring books a series of sessions from a recurrence rule in one transaction.
+// The template session sets the therapist, patient, first start time and session details.
+// Occurrences where the therapist is already booked fail the whole series, unless skipConflicts
+// is set, in which case they are left out and reported.
+func (s *SessionService) CreateRecurring(ctx context.Context, template *models.Session, rule models.RecurrenceRule, skipConflicts bool, createdBy uuid.UUID) (*models.RecurringSessionResult, error) {
+	if template.ServiceID != nil {
+		if err := s.applyBookableService(ctx, template); err != nil {
+			return nil, err
+		}
+	}
+
+	if template.TherapistID == uuid.Nil {
+		return nil, errors.New("therapist is required")
+	}
+	if template.PatientID == uuid.Nil {
+		return nil, errors.New("patient is required")
+	}
+	if template.ScheduledAt.IsZero() {
+		return nil, errors.New("scheduled time is required")
+	}
+	if template.DurationMinutes <= 0 {
+		return nil, errors.New("duration must be positive")
+	}
+	if template.SessionType == "" {
+		template.SessionType = models.SessionTypeRegular
+	}
+
+	loc, err := s.therapistTimezone(ctx, template.TherapistID, template.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	occurrences, err := expandRecurrence(rule, template.ScheduledAt, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	series := &models.SessionSeries{
+		ID:              uuid.New(),
+		OrganizationID:  template.OrganizationID,
+		TherapistID:     template.TherapistID,
+		PatientID:       template.PatientID,
+		ServiceID:       template.ServiceID,
+		Frequency:       rule.Frequency,
+		Weekdays:        make([]string, 0, len(rule.Weekdays)),
+		Occurrences:     rule.Count,
+		UntilDate:       rule.Until,
+		StartsAt:        template.ScheduledAt,
+		DurationMinutes: template.DurationMinutes,
+		CreatedBy:       &createdBy,
+	}
+	for _, code := range rule.Weekdays {
+		series.Weekdays = append(series.Weekdays, strings.ToUpper(code))
+	}
+	if len(series.Weekdays) == 0 {
+		for code, day := range recurrenceWeekdays {
+			if day == template.ScheduledAt.In(loc).Weekday() {
+				series.Weekdays = append(series.Weekdays, code)
+			}
+		}
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO session_series (
+			id, organization_id, therapist_id, patient_id, service_id, frequency, weekdays,
+			occurrences, until_date, starts_at, duration_minutes, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING created_at
+	`, series.ID, series.OrganizationID, series.TherapistID, series.PatientID, series.ServiceID,
+		series.Frequency, series.Weekdays, series.Occurrences, series.UntilDate, series.StartsAt,
+		series.DurationMinutes, series.CreatedBy).Scan(&series.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session series: %w", err)
+	}
+
+	result := &models.RecurringSessionResult{Series: series, Sessions: []*models.Session{}, Skipped: []time.Time{}}
+	var conflicts []string
+	for _, at := range occurrences {
+		end := at.Add(time.Duration(template.DurationMinutes) * time.Minute)
+		conflict, err := sessionConflict(ctx, tx, template.OrganizationID, template.TherapistID, at, end, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check conflicts: %w", err)
+		}
+		if conflict {
+			if skipConflicts {
+				result.Skipped = append(result.Skipped, at)
+			} else {
+				conflicts = append(conflicts, at.In(loc).Format("2006-01-02 15:04"))
+			}
+			continue
+		}
+
+		session := *template
+		session.ID = uuid.New()
+		session.ScheduledAt = at
+		session.Status = models.SessionStatusPending
+		session.CreatedBy = &createdBy
+		session.ConflictOverride = false
+		session.OverrideReason = nil
+		session.OverrideStatus = nil
+		session.SeriesID = &series.ID
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO sessions (
+				id, organization_id, therapist_id, patient_id, scheduled_at,
+				duration_minutes, price_cents, status, session_type, notes, created_by,
+				service_id, series_id
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		`, session.ID, session.OrganizationID, session.TherapistID, session.PatientID,
+			session.ScheduledAt, session.DurationMinutes, session.PriceCents,
+			session.Status, session.SessionType, session.Notes, session.CreatedBy,
+			session.ServiceID, session.SeriesID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create session: %w", err)
+		}
+		result.Sessions = append(result.Sessions, &session)
+	}
+
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("therapist already has sessions at these times: %s", strings.Join(conflicts, ", "))
+	}
+	if len(result.Sessions) == 0 {
+		return nil, errors.New("scheduling conflict: therapist already has a session at every occurrence")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	for _, session := range result.Sessions {
+		s.recordHistory(ctx, session.ID, "created", nil, session, &createdBy)
+
+		if s.workflow != nil {
+			if err := s.workflow.OnSessionStateChange(ctx, session.OrganizationID, session.ID, "", string(session.Status), session.ScheduledAt); err != nil {
+				fmt.Printf("Failed to trigger workflow: %v\n", err)
+			}
+		}
+	}
+
+	s.events.Publish(ctx, series.OrganizationID, models.DashboardEvent{
+		Type:       models.DashboardEventSessionSeriesCreated,
+		EntityType: "session_series",
+		EntityID:   series.ID,
+		Data: map[string]interface{}{
+			"therapist_id": series.TherapistID,
+			"patient_id":   series.PatientID,
+			"starts_at":    series.StartsAt,
+			"sessions":     len(result.Sessions),
+		},
+	})
+
+	return result, nil
+}
+
+// GetSeries returns a series and its sessions
+func (s *SessionService) GetSeries(ctx context.Context, id, orgID uuid.UUID) (*models.SessionSeriesWithSessions, error) {
+	var series models.SessionSeriesWithSessions
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT id, organization_id, therapist_id, patient_id, service_id, frequency, weekdays,
+			occurrences, until_date, starts_at, duration_minutes, created_by, created_at
+		FROM session_series
+		WHERE id = $1 AND organization_id = $2
+	`, id, orgID).Scan(
+		&series.ID, &series.OrganizationID, &series.TherapistID, &series.PatientID, &series.ServiceID,
+		&series.Frequency, &series.Weekdays, &series.Occurrences, &series.UntilDate, &series.StartsAt,
+		&series.DurationMinutes, &series.CreatedBy, &series.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.New("session series not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session series: %w", err)
+	}
+
+	series.Sessions, _, err = s.List(ctx, orgID, SessionFilters{SeriesID: &id, Limit: 2 * maxSeriesOccurrences})
+	if err != nil {
+		return nil, err
+	}
+
+	return &series, nil
+}
+
+// upcomingSeriesSessions returns the sessions of a series that are still to happen and not cancelled or completed
+func (s *SessionService) upcomingSeriesSessions(ctx context.Context, id, orgID uuid.UUID) ([]*models.SessionWithDetails, error) {
+	series, err := s.GetSeries(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var upcoming []*models.SessionWithDetails
+	for _, session := range series.Sessions {
+		if session.ScheduledAt.Before(now) {
+			continue
+		}
+		if session.Status != models.SessionStatusPending && session.Status != models.SessionStatusConfirmed {
+			continue
+		}
+		upcoming = append(upcoming, session)
+	}
+	return upcoming, nil
+}
+
+// UpdateSeries applies a change to the upcoming sessions of a series in one transaction and
+// returns how many were updated. A change of therapist, time or duration is checked for conflicts.
+func (s *SessionService) UpdateSeries(ctx context.Context, id, orgID uuid.UUID, change models.SessionSeriesChange, updatedBy uuid.UUID) (int, error) {
+	if change.DurationMinutes != nil && *change.DurationMinutes <= 0 {
+		return 0, errors.New("duration must be positive")
+	}
+	var hour, minute int
+	if change.TimeOfDay != nil {
+		t, err := time.Parse("15:04", *change.TimeOfDay)
+		if err != nil {
+			return 0, errors.New("time_of_day must be in HH:MM format")
+		}
+		hour, minute = t.Hour(), t.Minute()
+	}
+
+	upcoming, err := s.upcomingSeriesSessions(ctx, id, orgID)
+	if err != nil {
+		return 0, err
+	}
+	if len(upcoming) == 0 {
+		return 0, errors.New("series has no upcoming sessions")
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	locations := map[uuid.UUID]*time.Location{}
+	updated := make([]*models.Session, 0, len(upcoming))
+	var conflicts []string
+	for _, existing := range upcoming {
+		session := existing.Session
+		if change.TherapistID != nil {
+			session.TherapistID = *change.TherapistID
+		}
+		if change.DurationMinutes != nil {
+			session.DurationMinutes = *change.DurationMinutes
+		}
+		if change.PriceCents != nil {
+			session.PriceCents = *change.PriceCents
+		}
+		if change.SessionType != nil {
+			session.SessionType = *change.SessionType
+		}
+		if change.Notes != nil {
+			session.Notes = change.Notes
+		}
+
+		loc, ok := locations[session.TherapistID]
+		if !ok {
+			loc, err = s.therapistTimezone(ctx, session.TherapistID, orgID)
+			if err != nil {
+				return 0, err
+			}
+			locations[session.TherapistID] = loc
+		}
+		if change.TimeOfDay != nil {
+			local := session.ScheduledAt.In(loc)
+			session.ScheduledAt = time.Date(local.Year(), local.Month(), local.Day(), hour, minute, 0, 0, loc)
+		}
+
+		if change.TherapistID != nil || change.TimeOfDay != nil || change.DurationMinutes != nil {
+			conflict, err := sessionConflict(ctx, tx, orgID, session.TherapistID, session.ScheduledAt, session.EndTime(), &session.ID)
+			if err != nil {
+				return 0, fmt.Errorf("failed to check conflicts: %w", err)
+			}
+			if conflict {
+				conflicts = append(conflicts, session.ScheduledAt.In(loc).Format("2006-01-02 15:04"))
+				continue
+			}
+		}
+
+		_, err = tx.Exec(ctx, `
+			UPDATE sessions
+			SET therapist_id = $1, scheduled_at = $2, duration_minutes = $3,
+			    price_cents = $4, session_type = $5, notes = $6
+			WHERE id = $7 AND organization_id = $8 AND deleted_at IS NULL
+		`, session.TherapistID, session.ScheduledAt, session.DurationMinutes,
+			session.PriceCents, session.SessionType, session.Notes, session.ID, orgID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to update session: %w", err)
+		}
+		updated = append(updated, &session)
+	}
+
+	if len(conflicts) > 0 {
+		return 0, fmt.Errorf("therapist already has sessions at these times: %s", strings.Join(conflicts, ", "))
+	}
+
+	if change.TherapistID != nil || change.DurationMinutes != nil {
+		_, err = tx.Exec(ctx, `
+			UPDATE session_series
+			SET therapist_id = COALESCE($1, therapist_id), duration_minutes = COALESCE($2, duration_minutes)
+			WHERE id = $3 AND organization_id = $4
+		`, change.TherapistID, change.DurationMinutes, id, orgID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to update session series: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	for i, session := range updated {
+		s.recordHistory(ctx, session.ID, "updated", &upcoming[i].Session, session, &updatedBy)
+	}
+
+	return len(updated), nil
+}
+
+// CancelSeries cancels the upcoming sessions of a series, applying the cancellation policy to each.
+// It returns the outcome of every cancelled session.
+func (s *SessionService) CancelSeries(ctx context.Context, id, orgID uuid.UUID, reason string, cancelledBy uuid.UUID, waiveFee bool) ([]*models.CancellationOutcome, error) {
+	upcoming, err := s.upcomingSeriesSessions(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	outcomes := make([]*models.CancellationOutcome, 0, len(upcoming))
+	for _, session := range upcoming {
+		outcome, err := s.Cancel(ctx, session.ID, orgID, reason, cancelledBy, waiveFee, nil)
+		if err != nil {
+			return outcomes, fmt.Errorf("failed to cancel session of %s: %w", session.ScheduledAt.Format("2006-01-02"), err)
+		}
+		outcomes = append(outcomes, outcome)
+	}
+
+	return outcomes, nil
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+)
+
+func TestExpandRecurrence(t *testing.T) {
+	lisbon, err := time.LoadLocation("Europe/Lisbon")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+	count := func(n int) *int { return &n }
+	until := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	// Monday 2024-03-04 at 10:00 in Lisbon
+	start := time.Date(2024, 3, 4, 10, 0, 0, 0, lisbon)
+
+	tests := []struct {
+		name    string
+		rule    models.RecurrenceRule
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "weekly on the first session's weekday",
+			rule: models.RecurrenceRule{Frequency: models.RecurrenceWeekly, Count: count(3)},
+			want: []string{"2024-03-04 10:00", "2024-03-11 10:00", "2024-03-18 10:00"},
+		},
+		{
+			name: "biweekly on two weekdays",
+			rule: models.RecurrenceRule{Frequency: models.RecurrenceBiweekly, Weekdays: []string{"mo", "TH"}, Count: count(4)},
+			want: []string{"2024-03-04 10:00", "2024-03-07 10:00", "2024-03-18 10:00", "2024-03-21 10:00"},
+		},
+		{
+			name: "until date is inclusive",
+			rule: models.RecurrenceRule{Frequency: models.RecurrenceWeekly, Weekdays: []string{"MO", "FR"}, Until: &until},
+			want: []string{"2024-03-04 10:00", "2024-03-08 10:00", "2024-03-11 10:00", "2024-03-15 10:00"},
+		},
+		{
+			name: "keeps the wall-clock time across the DST change",
+			rule: models.RecurrenceRule{Frequency: models.RecurrenceWeekly, Count: count(5)},
+			want: []string{"2024-03-04 10:00", "2024-03-11 10:00", "2024-03-18 10:00", "2024-03-25 10:00", "2024-04-01 10:00"},
+		},
+		{
+			name:    "first session not on a listed weekday",
+			rule:    models.RecurrenceRule{Frequency: models.RecurrenceWeekly, Weekdays: []string{"TU"}, Count: count(2)},
+			wantErr: true,
+		},
+		{
+			name:    "no end",
+			rule:    models.RecurrenceRule{Frequency: models.RecurrenceWeekly},
+			wantErr: true,
+		},
+		{
+			name:    "too many occurrences",
+			rule:    models.RecurrenceRule{Frequency: models.RecurrenceWeekly, Weekdays: []string{"MO", "TU", "WE", "TH", "FR"}, Until: &[]time.Time{until.AddDate(1, 0, 0)}[0]},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandRecurrence(tt.rule, start, lisbon)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d occurrences, want %d", len(got), len(tt.want))
+			}
+			for i, at := range got {
+				if s := at.In(lisbon).Format("2006-01-02 15:04"); s != tt.want[i] {
+					t.Errorf("occurrence %d = %s, want %s", i, s, tt.want[i])
+				}
+			}
+		})
+	}
+
+	// The DST change moves the UTC offset, not the local time
+	got, _ := expandRecurrence(models.RecurrenceRule{Frequency: models.RecurrenceWeekly, Count: count(5)}, start, lisbon)
+	if got[0].UTC().Hour() != 10 || got[4].UTC().Hour() != 9 {
+		t.Errorf("unexpected UTC hours %d and %d", got[0].UTC().Hour(), got[4].UTC().Hour())
+	}
+}
@@ -1,11 +1,13 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -82,6 +84,32 @@ func (s *StorageService) UploadFile(ctx context.Context, file multipart.File, he
 	}, nil
 }
 
+// UploadData stores generated content, such as a processed image, under the organization's prefix
+func (s *StorageService) UploadData(ctx context.Context, data []byte, fileName, mimeType string, orgID uuid.UUID) (*UploadResult, error) {
+	if int64(len(data)) > s.cfg.MaxUploadSize {
+		return nil, fmt.Errorf("file size exceeds maximum allowed size")
+	}
+
+	return s.UploadGenerated(ctx, data, fileName, mimeType, orgID)
+}
+
+// UploadGenerated stores content built by the application, such as data exports, which is not
+// bound by the upload size limit
+func (s *StorageService) UploadGenerated(ctx context.Context, data []byte, fileName, mimeType string, orgID uuid.UUID) (*UploadResult, error) {
+	key := fmt.Sprintf("%s/%s%s", orgID.String(), uuid.New().String(), filepath.Ext(fileName))
+
+	if s.s3Client != nil {
+		return s.uploadToS3(ctx, bytes.NewReader(data), key, mimeType, int64(len(data)))
+	}
+
+	return &UploadResult{
+		FileName: fileName,
+		FileSize: int64(len(data)),
+		MimeType: mimeType,
+		URL:      fmt.Sprintf("/uploads/%s", key),
+	}, nil
+}
+
 func (s *StorageService) uploadToS3(ctx context.Context, file io.Reader, key, mimeType string, size int64) (*UploadResult, error) {
 	_, err := s.s3Client.PutObject(ctx, &s3.PutObjectInput{
 		Bucket:        aws.String(s.cfg.S3Bucket),
@@ -111,18 +139,77 @@ func (s *StorageService) DeleteFile(ctx context.Context, url string) error {
 		return nil
 	}
 
-	// Extract key from URL
-	// This is simplified - in production, you'd want more robust URL parsing
-	key := filepath.Base(url)
-
 	_, err := s.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
 		Bucket: aws.String(s.cfg.S3Bucket),
-		Key:    aws.String(key),
+		Key:    aws.String(s.objectKey(url)),
 	})
 
 	return err
 }
 
+// DeleteOrganizationFiles removes every file stored under the organization's prefix and
+// returns how many were removed. Files stored without S3 are left to the web server.
+func (s *StorageService) DeleteOrganizationFiles(ctx context.Context, orgID uuid.UUID) (int, error) {
+	if s.s3Client == nil {
+		return 0, nil
+	}
+
+	removed := 0
+	paginator := s3.NewListObjectsV2Paginator(s.s3Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.cfg.S3Bucket),
+		Prefix: aws.String(orgID.String() + "/"),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return removed, fmt.Errorf("failed to list files: %w", err)
+		}
+		for _, obj := range page.Contents {
+			if _, err := s.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+				Bucket: aws.String(s.cfg.S3Bucket),
+				Key:    obj.Key,
+			}); err != nil {
+				return removed, fmt.Errorf("failed to delete %s: %w", aws.ToString(obj.Key), err)
+			}
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// ReadFile returns the content of a stored file, up to limit bytes. Files stored without S3
+// are served by the web server and cannot be read back.
+func (s *StorageService) ReadFile(ctx context.Context, url string, limit int64) ([]byte, error) {
+	if s.s3Client == nil {
+		return nil, errIntegrationNotConfigured
+	}
+
+	out, err := s.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.cfg.S3Bucket),
+		Key:    aws.String(s.objectKey(url)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from S3: %w", err)
+	}
+	defer out.Body.Close()
+
+	return io.ReadAll(io.LimitReader(out.Body, limit))
+}
+
+// objectKey extracts the S3 key from a file URL, keeping the organization prefix
+func (s *StorageService) objectKey(url string) string {
+	return strings.TrimPrefix(url, fmt.Sprintf("https://%s.s3.%s.amazonaws.com/", s.cfg.S3Bucket, s.cfg.AWSRegion))
+}
+
+// DownloadURL returns a temporary link to a stored file. Without S3 the file is served
+// from the local uploads path, which is returned as is.
+func (s *StorageService) DownloadURL(ctx context.Context, url string, duration time.Duration) (string, error) {
+	if s.s3Client == nil {
+		return url, nil
+	}
+	return s.GeneratePresignedURL(ctx, s.objectKey(url), duration)
+}
+
 func (s *StorageService) GeneratePresignedURL(ctx context.Context, key string, duration time.Duration) (string, error) {
 	if s.s3Client == nil {
 		return "", fmt.Errorf("S3 client not configured")
@@ -143,6 +230,22 @@ func (s *StorageService) GeneratePresignedURL(ctx context.Context, key string, d
 	return presignResult.URL, nil
 }
 
+// CheckConnection verifies the bucket is reachable with the configured credentials.
+// It returns errIntegrationNotConfigured when S3 is not set up.
+func (s *StorageService) CheckConnection(ctx context.Context) error {
+	if s.s3Client == nil {
+		return errIntegrationNotConfigured
+	}
+
+	_, err := s.s3Client.HeadBucket(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(s.cfg.S3Bucket),
+	})
+	if err != nil {
+		return fmt.Errorf("bucket %s is not accessible: %w", s.cfg.S3Bucket, err)
+	}
+	return nil
+}
+
 func (s *StorageService) isAllowedFileType(mimeType string) bool {
 	for _, allowed := range s.cfg.AllowedFileTypes {
 		if allowed == mimeType {
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const stripeAPIBase = "https://api.stripe.com/v1"
+
+// stripeSignatureTolerance is how old a webhook signature may be, to reject replayed events
+const stripeSignatureTolerance = 5 * time.Minute
+
+// stripeClient calls the Stripe API with an organization's secret key
+type stripeClient struct {
+	apiBase   string
+	secretKey string
+	client    *http.Client
+}
+
+// stripeCheckoutSession is the part of a Stripe Checkout Session used for payment links
+type stripeCheckoutSession struct {
+	ID                string            `json:"id"`
+	URL               string            `json:"url"`
+	Status            string            `json:"status"`         // open, complete, expired
+	PaymentStatus     string            `json:"payment_status"` // paid, unpaid, no_payment_required
+	ExpiresAt         int64             `json:"expires_at"`
+	ClientReferenceID string            `json:"client_reference_id"`
+	Metadata          map[string]string `json:"metadata"`
+}
+
+// stripeCheckoutRequest describes the single item a checkout collects
+type stripeCheckoutRequest struct {
+	Reference     string // our payment link ID, echoed back in webhooks
+	Description   string
+	AmountCents   int64
+	Currency      string
+	CustomerEmail string
+	SuccessURL    string
+	CancelURL     string
+	ExpiresAt     time.Time
+}
+
+// createCheckoutSession creates a hosted Stripe Checkout page for one payment
+func (c *stripeClient) createCheckoutSession(ctx context.Context, req stripeCheckoutRequest) (*stripeCheckoutSession, error) {
+	form := url.Values{}
+	form.Set("mode", "payment")
+	form.Set("client_reference_id", req.Reference)
+	form.Set("metadata[payment_link_id]", req.Reference)
+	form.Set("line_items[0][quantity]", "1")
+	form.Set("line_items[0][price_data][currency]", req.Currency)
+	form.Set("line_items[0][price_data][unit_amount]", strconv.FormatInt(req.AmountCents, 10))
+	form.Set("line_items[0][price_data][product_data][name]", req.Description)
+	form.Set("success_url", req.SuccessURL)
+	form.Set("cancel_url", req.CancelURL)
+	form.Set("expires_at", strconv.FormatInt(req.ExpiresAt.Unix(), 10))
+	if req.CustomerEmail != "" {
+		form.Set("customer_email", req.CustomerEmail)
+	}
+
+	var session stripeCheckoutSession
+	if err := c.post(ctx, "/checkout/sessions", form, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// expireCheckoutSession closes an open checkout so it can no longer be paid
+func (c *stripeClient) expireCheckoutSession(ctx context.Context, id string) error {
+	return c.post(ctx, "/checkout/sessions/"+url.PathEscape(id)+"/expire", url.Values{}, nil)
+}
+
+func (c *stripeClient) post(ctx context.Context, path string, form url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", c.apiBase+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create Stripe request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.secretKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call Stripe: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read Stripe response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var stripeErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(body, &stripeErr) == nil && stripeErr.Error.Message != "" {
+			return fmt.Errorf("Stripe returned status %d: %s", resp.StatusCode, stripeErr.Error.Message)
+		}
+		return fmt.Errorf("Stripe returned status %d", resp.StatusCode)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode Stripe response: %w", err)
+	}
+	return nil
+}
+
+// stripeEvent is a webhook event; Data.Object is the checkout session for checkout.session.* events
+type stripeEvent struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+// verifyStripeSignature checks the Stripe-Signature header of a webhook: an HMAC-SHA256 of
+// "timestamp.payload" with the endpoint's signing secret, made within the tolerance
+func verifyStripeSignature(payload []byte, header, secret string, now time.Time) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return errors.New("invalid signature header")
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("invalid signature header")
+	}
+	if age := now.Sub(time.Unix(ts, 0)); age > stripeSignatureTolerance || age < -stripeSignatureTolerance {
+		return errors.New("signature timestamp outside the tolerance")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	expected := mac.Sum(nil)
+
+	for _, sig := range signatures {
+		decoded, err := hex.DecodeString(sig)
+		if err == nil && hmac.Equal(decoded, expected) {
+			return nil
+		}
+	}
+	return errors.New("signature mismatch")
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestVerifyStripeSignature(t *testing.T) {
+	const secret = "whsec_test"
+	payload := []byte(`{"id":"evt_1","type":"checkout.session.completed"}`)
+	now := time.Unix(1700000000, 0)
+
+	sign := func(ts int64, secret string) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(fmt.Sprintf("%d.%s", ts, payload)))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	tests := []struct {
+		name    string
+		header  string
+		wantErr bool
+	}{
+		{"valid", fmt.Sprintf("t=%d,v1=%s", now.Unix(), sign(now.Unix(), secret)), false},
+		{"valid among rotated secrets", fmt.Sprintf("t=%d,v1=%s,v1=%s", now.Unix(), sign(now.Unix(), "whsec_old"), sign(now.Unix(), secret)), false},
+		{"wrong secret", fmt.Sprintf("t=%d,v1=%s", now.Unix(), sign(now.Unix(), "whsec_other")), true},
+		{"too old", fmt.Sprintf("t=%d,v1=%s", now.Unix()-600, sign(now.Unix()-600, secret)), true},
+		{"missing signature", fmt.Sprintf("t=%d", now.Unix()), true},
+		{"malformed", "garbage", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyStripeSignature(payload, tt.header, secret, now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("verifyStripeSignature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
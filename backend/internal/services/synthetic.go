@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// syntheticSessionNote marks the sessions booked by synthetic traffic
+const syntheticSessionNote = "[synthetic]"
+
+// SyntheticTrafficService generates realistic load against a staging organization: it books fake
+// sessions with the organization's therapists and patients and moves them through their states
+// through the session service, so workflow triggers, scheduled jobs and messages follow as they
+// would for real bookings. The worker makes the organization's messages no-ops.
+type SyntheticTrafficService struct {
+	db                   *database.DB
+	sessions             *SessionService
+	orgID                uuid.UUID
+	sessionsPerMinute    int
+	transitionsPerMinute int
+}
+
+func NewSyntheticTrafficService(db *database.DB, sessions *SessionService, orgID uuid.UUID, sessionsPerMinute, transitionsPerMinute int) *SyntheticTrafficService {
+	return &SyntheticTrafficService{
+		db:                   db,
+		sessions:             sessions,
+		orgID:                orgID,
+		sessionsPerMinute:    sessionsPerMinute,
+		transitionsPerMinute: transitionsPerMinute,
+	}
+}
+
+// SyntheticTrafficResult counts what one minute of synthetic traffic did
+type SyntheticTrafficResult struct {
+	Created      int `json:"created"`
+	Conflicts    int `json:"conflicts"` // bookings skipped because the therapist was busy
+	Transitioned int `json:"transitioned"`
+	Failed       int `json:"failed"`
+}
+
+// syntheticTransition picks the state a synthetic session moves to from roll, in [0, 1): most
+// pending sessions are confirmed and most confirmed ones completed, the rest cancelled or missed
+func syntheticTransition(status models.SessionStatus, roll float64) models.SessionStatus {
+	switch status {
+	case models.SessionStatusPending:
+		if roll < 0.8 {
+			return models.SessionStatusConfirmed
+		}
+		return models.SessionStatusCancelled
+	case models.SessionStatusConfirmed:
+		switch {
+		case roll < 0.7:
+			return models.SessionStatusCompleted
+		case roll < 0.85:
+			return models.SessionStatusNoShow
+		}
+		return models.SessionStatusCancelled
+	}
+	return ""
+}
+
+// Generate runs one minute of synthetic traffic
+func (s *SyntheticTrafficService) Generate(ctx context.Context) (*SyntheticTrafficResult, error) {
+	var actorID uuid.UUID
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT id FROM users
+		WHERE organization_id = $1 AND role = 'admin' AND is_active AND deleted_at IS NULL
+		ORDER BY created_at
+		LIMIT 1
+	`, s.orgID).Scan(&actorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get synthetic traffic admin: %w", err)
+	}
+
+	therapists, err := s.ids(ctx, `
+		SELECT id FROM therapists WHERE organization_id = $1 AND is_active AND deleted_at IS NULL
+	`)
+	if err != nil {
+		return nil, err
+	}
+	patients, err := s.ids(ctx, `
+		SELECT id FROM patients WHERE organization_id = $1 AND is_active AND deleted_at IS NULL
+	`)
+	if err != nil {
+		return nil, err
+	}
+	if len(therapists) == 0 || len(patients) == 0 {
+		return nil, errors.New("synthetic traffic needs an active therapist and patient in the organization")
+	}
+
+	result := &SyntheticTrafficResult{}
+	note := syntheticSessionNote
+	today := time.Now().Truncate(24 * time.Hour)
+	for i := 0; i < s.sessionsPerMinute; i++ {
+		// A half-hour slot in working hours over the next two weeks
+		scheduledAt := today.AddDate(0, 0, 1+rand.IntN(14)).
+			Add(time.Duration(8*60+30*rand.IntN(22)) * time.Minute)
+		session := &models.Session{
+			OrganizationID:  s.orgID,
+			TherapistID:     therapists[rand.IntN(len(therapists))],
+			PatientID:       patients[rand.IntN(len(patients))],
+			ScheduledAt:     scheduledAt,
+			DurationMinutes: 50,
+			Notes:           &note,
+		}
+		conflict, err := s.sessions.hasConflict(ctx, s.orgID, session.TherapistID, scheduledAt, scheduledAt.Add(50*time.Minute), nil)
+		if err == nil && conflict {
+			result.Conflicts++
+			continue
+		}
+		if err := s.sessions.Create(ctx, session, actorID); err != nil {
+			result.Failed++
+			continue
+		}
+		result.Created++
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, status FROM sessions
+		WHERE organization_id = $1 AND notes = $2 AND status IN ('pending', 'confirmed') AND deleted_at IS NULL
+		ORDER BY random()
+		LIMIT $3
+	`, s.orgID, syntheticSessionNote, s.transitionsPerMinute)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get synthetic sessions: %w", err)
+	}
+	type pick struct {
+		id     uuid.UUID
+		status models.SessionStatus
+	}
+	var picks []pick
+	for rows.Next() {
+		var p pick
+		if err := rows.Scan(&p.id, &p.status); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan synthetic session: %w", err)
+		}
+		picks = append(picks, p)
+	}
+	rows.Close()
+
+	for _, p := range picks {
+		switch syntheticTransition(p.status, rand.Float64()) {
+		case models.SessionStatusConfirmed:
+			err = s.sessions.Confirm(ctx, p.id, s.orgID, actorID, nil)
+		case models.SessionStatusCompleted:
+			err = s.sessions.Complete(ctx, p.id, s.orgID, actorID, nil)
+		case models.SessionStatusNoShow:
+			err = s.sessions.MarkNoShow(ctx, p.id, s.orgID, actorID, nil)
+		default:
+			_, err = s.sessions.Cancel(ctx, p.id, s.orgID, "synthetic traffic", actorID, true, nil)
+		}
+		if err != nil {
+			result.Failed++
+			continue
+		}
+		result.Transitioned++
+	}
+	return result, nil
+}
+
+func (s *SyntheticTrafficService) ids(ctx context.Context, query string) ([]uuid.UUID, error) {
+	rows, err := s.db.Pool.Query(ctx, query, s.orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query synthetic traffic participants: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan synthetic traffic participant: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
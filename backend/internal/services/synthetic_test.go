@@ -0,0 +1,27 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/controlwise/backend/internal/models"
+)
+
+func TestSyntheticTransition(t *testing.T) {
+	tests := []struct {
+		status models.SessionStatus
+		roll   float64
+		want   models.SessionStatus
+	}{
+		{models.SessionStatusPending, 0, models.SessionStatusConfirmed},
+		{models.SessionStatusPending, 0.9, models.SessionStatusCancelled},
+		{models.SessionStatusConfirmed, 0.5, models.SessionStatusCompleted},
+		{models.SessionStatusConfirmed, 0.8, models.SessionStatusNoShow},
+		{models.SessionStatusConfirmed, 0.99, models.SessionStatusCancelled},
+		{models.SessionStatusCompleted, 0.5, ""},
+	}
+	for _, tt := range tests {
+		if got := syntheticTransition(tt.status, tt.roll); got != tt.want {
+			t.Errorf("syntheticTransition(%s, %v) = %q, want %q", tt.status, tt.roll, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,430 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/i18n"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/workflow"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// TaskService handles task operations
+type TaskService struct {
+	db           *database.DB
+	notification *NotificationService
+	workflow     *WorkflowService
+}
+
+func NewTaskService(db *database.DB, notification *NotificationService) *TaskService {
+	return &TaskService{
+		db:           db,
+		notification: notification,
+	}
+}
+
+// SetWorkflowService sets the workflow service used for task_assigned triggers
+func (s *TaskService) SetWorkflowService(ws *WorkflowService) {
+	s.workflow = ws
+}
+
+// TaskFilters narrows the task list
+type TaskFilters struct {
+	ProjectID  *uuid.UUID
+	AssignedTo *uuid.UUID
+	Status     string
+	Priority   string
+	Overdue    bool // open tasks past their due date
+}
+
+type CreateTaskRequest struct {
+	ProjectID   uuid.UUID       `json:"project_id"`
+	MilestoneID *uuid.UUID      `json:"milestone_id"`
+	Title       string          `json:"title"`
+	Description *string         `json:"description"`
+	AssignedTo  *uuid.UUID      `json:"assigned_to"`
+	Priority    models.Priority `json:"priority"`
+	Weight      int             `json:"weight"` // defaults to 1
+	DueDate     *time.Time      `json:"due_date"`
+}
+
+type UpdateTaskRequest struct {
+	MilestoneID *uuid.UUID      `json:"milestone_id"`
+	Title       string          `json:"title"`
+	Description *string         `json:"description"`
+	Priority    models.Priority `json:"priority"`
+	Weight      int             `json:"weight"` // defaults to 1
+	DueDate     *time.Time      `json:"due_date"`
+}
+
+const taskColumns = `
+	t.id, t.project_id, t.milestone_id, t.title, t.description, t.assigned_to, t.status,
+	t.priority, t.weight, t.due_date, t.completed_at, t.created_by, t.created_at, t.updated_at`
+
+func scanTask(row pgx.Row) (*models.Task, error) {
+	var t models.Task
+	err := row.Scan(
+		&t.ID, &t.ProjectID, &t.MilestoneID, &t.Title, &t.Description, &t.AssignedTo, &t.Status,
+		&t.Priority, &t.Weight, &t.DueDate, &t.CompletedAt, &t.CreatedBy, &t.CreatedAt, &t.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func validPriority(p models.Priority) bool {
+	_, ok := taskPriorityWeight[p]
+	return ok
+}
+
+// List returns the organization's tasks matching the filters, most urgent first
+func (s *TaskService) List(ctx context.Context, orgID uuid.UUID, filters TaskFilters) ([]*models.Task, error) {
+	query := `
+		SELECT ` + taskColumns + `
+		FROM tasks t
+		JOIN projects p ON p.id = t.project_id
+		WHERE p.organization_id = $1 AND p.deleted_at IS NULL AND t.deleted_at IS NULL
+	`
+	args := []interface{}{orgID}
+
+	if filters.ProjectID != nil {
+		args = append(args, *filters.ProjectID)
+		query += fmt.Sprintf(" AND t.project_id = $%d", len(args))
+	}
+	if filters.AssignedTo != nil {
+		args = append(args, *filters.AssignedTo)
+		query += fmt.Sprintf(" AND t.assigned_to = $%d", len(args))
+	}
+	if filters.Status != "" {
+		args = append(args, filters.Status)
+		query += fmt.Sprintf(" AND t.status = $%d", len(args))
+	}
+	if filters.Priority != "" {
+		args = append(args, filters.Priority)
+		query += fmt.Sprintf(" AND t.priority = $%d", len(args))
+	}
+	if filters.Overdue {
+		query += " AND t.status IN ('todo', 'in_progress') AND t.due_date < NOW()"
+	}
+
+	query += `
+		ORDER BY t.due_date ASC NULLS LAST,
+			CASE t.priority WHEN 'urgent' THEN 0 WHEN 'high' THEN 1 WHEN 'medium' THEN 2 ELSE 3 END,
+			t.created_at DESC
+	`
+
+	rows, err := s.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := []*models.Task{}
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task: %w", err)
+		}
+		tasks = append(tasks, t)
+	}
+
+	return tasks, nil
+}
+
+// GetByID returns a task of the organization
+func (s *TaskService) GetByID(ctx context.Context, id, orgID uuid.UUID) (*models.Task, error) {
+	t, err := scanTask(s.db.Pool.QueryRow(ctx, `
+		SELECT `+taskColumns+`
+		FROM tasks t
+		JOIN projects p ON p.id = t.project_id
+		WHERE t.id = $1 AND p.organization_id = $2 AND t.deleted_at IS NULL
+	`, id, orgID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("task not found")
+		}
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+	return t, nil
+}
+
+// Create adds a task to a project of the organization, notifying the assignee or their
+// delegate when they are out of office
+func (s *TaskService) Create(ctx context.Context, orgID, userID uuid.UUID, req CreateTaskRequest) (*models.Task, error) {
+	if req.Title == "" {
+		return nil, errors.New("task title is required")
+	}
+	if req.Priority == "" {
+		req.Priority = models.PriorityMedium
+	}
+	if !validPriority(req.Priority) {
+		return nil, errors.New("invalid task priority")
+	}
+	if req.Weight == 0 {
+		req.Weight = 1
+	}
+	if req.Weight < 1 || req.Weight > 100 {
+		return nil, errors.New("task weight must be between 1 and 100")
+	}
+
+	var projectExists bool
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM projects WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL)
+	`, req.ProjectID, orgID).Scan(&projectExists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check project: %w", err)
+	}
+	if !projectExists {
+		return nil, errors.New("project not found")
+	}
+
+	if err := s.checkMilestone(ctx, req.ProjectID, req.MilestoneID); err != nil {
+		return nil, err
+	}
+	id := uuid.New()
+	if req.AssignedTo != nil {
+		if err := s.checkAssignee(ctx, orgID, *req.AssignedTo); err != nil {
+			return nil, err
+		}
+		delegate := s.resolveAssignee(ctx, orgID, *req.AssignedTo, id)
+		req.AssignedTo = &delegate
+	}
+
+	_, err = s.db.Pool.Exec(ctx, `
+		INSERT INTO tasks (id, project_id, milestone_id, title, description, assigned_to, status, priority, weight, due_date, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, 'todo', $7, $8, $9, $10)
+	`, id, req.ProjectID, req.MilestoneID, req.Title, req.Description, req.AssignedTo, req.Priority, req.Weight, req.DueDate, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create task: %w", err)
+	}
+	s.recalculateProgress(ctx, req.ProjectID)
+
+	task, err := s.GetByID(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	if task.AssignedTo != nil && *task.AssignedTo != userID {
+		s.onAssigned(ctx, orgID, task)
+	}
+
+	return task, nil
+}
+
+// Update changes a task's details. Moving the due date re-arms the overdue notification.
+func (s *TaskService) Update(ctx context.Context, id, orgID uuid.UUID, req UpdateTaskRequest) (*models.Task, error) {
+	if req.Title == "" {
+		return nil, errors.New("task title is required")
+	}
+	if req.Priority == "" {
+		req.Priority = models.PriorityMedium
+	}
+	if !validPriority(req.Priority) {
+		return nil, errors.New("invalid task priority")
+	}
+	if req.Weight == 0 {
+		req.Weight = 1
+	}
+	if req.Weight < 1 || req.Weight > 100 {
+		return nil, errors.New("task weight must be between 1 and 100")
+	}
+
+	task, err := s.GetByID(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkMilestone(ctx, task.ProjectID, req.MilestoneID); err != nil {
+		return nil, err
+	}
+
+	_, err = s.db.Pool.Exec(ctx, `
+		UPDATE tasks
+		SET milestone_id = $1, title = $2, description = $3, priority = $4, due_date = $5, weight = $6,
+			due_notified_at = CASE WHEN due_date IS DISTINCT FROM $5 THEN NULL ELSE due_notified_at END,
+			updated_at = NOW()
+		WHERE id = $7 AND deleted_at IS NULL
+	`, req.MilestoneID, req.Title, req.Description, req.Priority, req.DueDate, req.Weight, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update task: %w", err)
+	}
+	s.recalculateProgress(ctx, task.ProjectID)
+
+	return s.GetByID(ctx, id, orgID)
+}
+
+// UpdateStatus moves a task to a new status, recording when it was completed.
+// The progress of a project computed from its tasks follows.
+func (s *TaskService) UpdateStatus(ctx context.Context, id, orgID uuid.UUID, status models.TaskStatus) (*models.Task, error) {
+	switch status {
+	case models.TaskStatusTodo, models.TaskStatusInProgress, models.TaskStatusCompleted, models.TaskStatusCancelled:
+	default:
+		return nil, errors.New("invalid task status")
+	}
+
+	task, err := s.GetByID(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.db.Pool.Exec(ctx, `
+		UPDATE tasks
+		SET status = $1,
+			completed_at = CASE WHEN $1 = 'completed' THEN COALESCE(completed_at, NOW()) ELSE NULL END,
+			updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL
+	`, status, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update task status: %w", err)
+	}
+	s.recalculateProgress(ctx, task.ProjectID)
+
+	return s.GetByID(ctx, id, orgID)
+}
+
+// Assign sets or clears a task's assignee, rerouted to their delegate when they are out of office.
+// The new assignee is notified and the project workflow's task_assigned triggers fire.
+func (s *TaskService) Assign(ctx context.Context, id, orgID, actorID uuid.UUID, assigneeID *uuid.UUID) (*models.Task, error) {
+	task, err := s.GetByID(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status == models.TaskStatusCompleted || task.Status == models.TaskStatusCancelled {
+		return nil, errors.New("cannot assign completed or cancelled tasks")
+	}
+	if assigneeID != nil {
+		if err := s.checkAssignee(ctx, orgID, *assigneeID); err != nil {
+			return nil, err
+		}
+		delegate := s.resolveAssignee(ctx, orgID, *assigneeID, id)
+		assigneeID = &delegate
+	}
+
+	_, err = s.db.Pool.Exec(ctx, `
+		UPDATE tasks SET assigned_to = $1, updated_at = NOW() WHERE id = $2 AND deleted_at IS NULL
+	`, assigneeID, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign task: %w", err)
+	}
+
+	changed := assigneeID != nil && (task.AssignedTo == nil || *task.AssignedTo != *assigneeID)
+	task.AssignedTo = assigneeID
+	if changed && *assigneeID != actorID {
+		s.onAssigned(ctx, orgID, task)
+	}
+
+	return task, nil
+}
+
+// Delete soft deletes a task
+func (s *TaskService) Delete(ctx context.Context, id, orgID uuid.UUID) error {
+	var projectID uuid.UUID
+	err := s.db.Pool.QueryRow(ctx, `
+		UPDATE tasks t SET deleted_at = NOW()
+		FROM projects p
+		WHERE t.id = $1 AND p.id = t.project_id AND p.organization_id = $2 AND t.deleted_at IS NULL
+		RETURNING t.project_id
+	`, id, orgID).Scan(&projectID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("task not found")
+		}
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+	s.recalculateProgress(ctx, projectID)
+	return nil
+}
+
+// recalculateProgress refreshes the progress of a project computed from its tasks.
+// Failures are logged and don't undo the task change.
+func (s *TaskService) recalculateProgress(ctx context.Context, projectID uuid.UUID) {
+	if err := recalculateProjectProgress(ctx, s.db, projectID); err != nil {
+		fmt.Printf("Warning: failed to recalculate progress of project %s: %v\n", projectID, err)
+	}
+}
+
+// checkAssignee ensures tasks are only assigned to active staff of the organization
+func (s *TaskService) checkAssignee(ctx context.Context, orgID, userID uuid.UUID) error {
+	var role models.Role
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT role FROM users
+		WHERE id = $1 AND organization_id = $2 AND is_active = true AND deleted_at IS NULL
+	`, userID, orgID).Scan(&role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("assignee not found")
+		}
+		return fmt.Errorf("failed to check assignee: %w", err)
+	}
+	if !role.IsStaff() {
+		return errors.New("tasks can only be assigned to staff members")
+	}
+	return nil
+}
+
+// resolveAssignee returns who a task assigned to userID goes to, rerouting it to their delegate
+// while they are out of office
+func (s *TaskService) resolveAssignee(ctx context.Context, orgID, userID, taskID uuid.UUID) uuid.UUID {
+	return workflow.ResolveDelegate(ctx, s.db, orgID, userID, models.DelegationTypeTask, "task", taskID, "task")
+}
+
+// checkMilestone ensures the milestone, if any, belongs to the task's project
+func (s *TaskService) checkMilestone(ctx context.Context, projectID uuid.UUID, milestoneID *uuid.UUID) error {
+	if milestoneID == nil {
+		return nil
+	}
+	var exists bool
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM project_milestones WHERE id = $1 AND project_id = $2)
+	`, *milestoneID, projectID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check milestone: %w", err)
+	}
+	if !exists {
+		return errors.New("milestone not found")
+	}
+	return nil
+}
+
+// onAssigned notifies the new assignee in-app and fires the project workflow's task_assigned triggers.
+// Failures are logged and don't undo the assignment.
+func (s *TaskService) onAssigned(ctx context.Context, orgID uuid.UUID, task *models.Task) {
+	if task.AssignedTo == nil {
+		return
+	}
+
+	var projectTitle, projectStatus string
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT title, status FROM projects WHERE id = $1
+	`, task.ProjectID).Scan(&projectTitle, &projectStatus)
+	if err != nil {
+		fmt.Printf("Warning: failed to load project for task %s: %v\n", task.ID, err)
+		return
+	}
+
+	if s.notification != nil {
+		locale := i18n.FromContext(ctx)
+		entityType := "task"
+		if err := s.notification.Create(ctx, &models.Notification{
+			UserID:     *task.AssignedTo,
+			Type:       models.NotificationTypeTaskAssigned,
+			Title:      fmt.Sprintf(i18n.T(locale, "New task: %s"), task.Title),
+			Message:    fmt.Sprintf(i18n.T(locale, "You were assigned a task on project %s"), projectTitle),
+			EntityType: &entityType,
+			EntityID:   &task.ID,
+		}); err != nil {
+			fmt.Printf("Warning: failed to notify task assignee: %v\n", err)
+		}
+	}
+
+	if s.workflow != nil {
+		if err := s.workflow.OnTaskEvent(ctx, orgID, task.ProjectID, task.ID, projectStatus, models.TriggerTypeTaskAssigned); err != nil {
+			fmt.Printf("Warning: failed to fire task_assigned triggers: %v\n", err)
+		}
+	}
+}
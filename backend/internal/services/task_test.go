@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// seedTestOrganization commits an organization with an admin user for services that don't take a
+// transaction, purging it when the test ends
+func seedTestOrganization(t *testing.T, pool *pgxpool.Pool, name string) (uuid.UUID, uuid.UUID) {
+	t.Helper()
+	ctx := context.Background()
+	var orgID, userID uuid.UUID
+	seedTestData(t, pool, func(tx pgx.Tx) {
+		orgID, userID = insertTestOrganization(t, ctx, tx, name)
+	})
+	t.Cleanup(func() {
+		tx, err := pool.Begin(ctx)
+		if err != nil {
+			t.Errorf("failed to begin cleanup: %v", err)
+			return
+		}
+		defer tx.Rollback(ctx)
+		if _, err := purgeOrganization(ctx, tx, orgID); err != nil {
+			t.Errorf("failed to purge organization: %v", err)
+			return
+		}
+		if err := tx.Commit(ctx); err != nil {
+			t.Errorf("failed to commit cleanup: %v", err)
+		}
+	})
+	return orgID, userID
+}
+
+// seedTestData runs insert in a transaction and commits it
+func seedTestData(t *testing.T, pool *pgxpool.Pool, insert func(tx pgx.Tx)) {
+	t.Helper()
+	ctx := context.Background()
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+	insert(tx)
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("failed to commit test data: %v", err)
+	}
+}
+
+// insertTestUser adds an active user with the role to the organization
+func insertTestUser(t *testing.T, ctx context.Context, tx pgx.Tx, orgID uuid.UUID, role models.Role) uuid.UUID {
+	t.Helper()
+	id := uuid.New()
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO users (id, organization_id, email, password_hash, first_name, last_name, role)
+		VALUES ($1, $2, $3, 'x', 'Test', 'User', $4)
+	`, id, orgID, id.String()+"@test.local", role); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	return id
+}
+
+// insertTestBudget creates a draft budget with the client and worksheet it comes from
+func insertTestBudget(t *testing.T, ctx context.Context, tx pgx.Tx, orgID, userID uuid.UUID) uuid.UUID {
+	t.Helper()
+	clientID, worksheetID, budgetID := uuid.New(), uuid.New(), uuid.New()
+	steps := []struct {
+		sql  string
+		args []interface{}
+	}{
+		{`INSERT INTO clients (id, organization_id, name, email, phone, created_by) VALUES ($1, $2, 'Client', $3, '+351910000000', $4)`,
+			[]interface{}{clientID, orgID, clientID.String() + "@test.local", userID}},
+		{`INSERT INTO worksheets (id, organization_id, client_id, title, description, created_by) VALUES ($1, $2, $3, 'Works', 'Works', $4)`,
+			[]interface{}{worksheetID, orgID, clientID, userID}},
+		{`INSERT INTO budgets (id, organization_id, worksheet_id, budget_number, valid_until, created_by) VALUES ($1, $2, $3, $4, CURRENT_DATE + 30, $5)`,
+			[]interface{}{budgetID, orgID, worksheetID, "ORC-" + budgetID.String()[:8], userID}},
+	}
+	for _, step := range steps {
+		if _, err := tx.Exec(ctx, step.sql, step.args...); err != nil {
+			t.Fatalf("failed to create budget: %v", err)
+		}
+	}
+	return budgetID
+}
+
+// insertTestProject creates a project with the client, worksheet and budget it comes from
+func insertTestProject(t *testing.T, ctx context.Context, tx pgx.Tx, orgID, userID uuid.UUID) uuid.UUID {
+	t.Helper()
+	budgetID, projectID := insertTestBudget(t, ctx, tx, orgID, userID), uuid.New()
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO projects (id, organization_id, budget_id, project_number, title, start_date, expected_end_date, created_by)
+		VALUES ($1, $2, $3, $4, 'Project', CURRENT_DATE, CURRENT_DATE + 30, $5)
+	`, projectID, orgID, budgetID, "PRJ-"+projectID.String()[:8], userID); err != nil {
+		t.Fatalf("failed to create project: %v", err)
+	}
+	return projectID
+}
+
+// TestTaskAssignmentReroutesToDelegate creates and reassigns tasks to a user who is out of office
+// and checks they go to the delegate, with the rerouting in the audit log
+func TestTaskAssignmentReroutesToDelegate(t *testing.T) {
+	pool := openTestDB(t)
+	ctx := context.Background()
+
+	orgID, adminID := seedTestOrganization(t, pool, "Task delegation")
+	var awayID, delegateID, otherID, projectID uuid.UUID
+	seedTestData(t, pool, func(tx pgx.Tx) {
+		awayID = insertTestUser(t, ctx, tx, orgID, models.RoleManager)
+		delegateID = insertTestUser(t, ctx, tx, orgID, models.RoleManager)
+		otherID = insertTestUser(t, ctx, tx, orgID, models.RoleManager)
+		projectID = insertTestProject(t, ctx, tx, orgID, adminID)
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO user_out_of_office (organization_id, user_id, delegate_user_id, starts_at, ends_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`, orgID, awayID, delegateID, time.Now().Add(-time.Hour), time.Now().Add(24*time.Hour)); err != nil {
+			t.Fatalf("failed to create out-of-office: %v", err)
+		}
+	})
+
+	s := NewTaskService(&database.DB{Pool: pool}, nil)
+
+	created, err := s.Create(ctx, orgID, adminID, CreateTaskRequest{ProjectID: projectID, Title: "Created", AssignedTo: &awayID})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	other, err := s.Create(ctx, orgID, adminID, CreateTaskRequest{ProjectID: projectID, Title: "Assigned", AssignedTo: &otherID})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	assigned, err := s.Assign(ctx, other.ID, orgID, adminID, &awayID)
+	if err != nil {
+		t.Fatalf("Assign: %v", err)
+	}
+
+	for _, task := range []*models.Task{created, assigned} {
+		if task.AssignedTo == nil || *task.AssignedTo != delegateID {
+			t.Errorf("task %q assigned to %v, want delegate %s", task.Title, task.AssignedTo, delegateID)
+		}
+		var logged int
+		if err := pool.QueryRow(ctx, `
+			SELECT COUNT(*) FROM delegation_audit_log
+			WHERE organization_id = $1 AND original_user_id = $2 AND delegate_user_id = $3
+				AND delegation_type = $4 AND entity_type = 'task' AND entity_id = $5
+		`, orgID, awayID, delegateID, models.DelegationTypeTask, task.ID).Scan(&logged); err != nil {
+			t.Fatalf("failed to count delegations: %v", err)
+		}
+		if logged != 1 {
+			t.Errorf("task %q has %d delegation audit entries, want 1", task.Title, logged)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	apperrors "github.com/controlwise/backend/internal/errors"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/workflow"
+	"github.com/google/uuid"
+)
+
+// templateEntityTypes are the entity types whose data fills message templates
+var templateEntityTypes = []string{"session", "budget", "project", "invoice"}
+
+// templateVariables returns the variables available to templates sent for an entity type
+func templateVariables(entityType string) map[string]bool {
+	known := make(map[string]bool)
+	for name := range GetSampleDataForEntityType(entityType) {
+		known[name] = true
+	}
+	for _, v := range workflow.GetAvailableVariables(entityType) {
+		known[v.Name] = true
+	}
+	return known
+}
+
+// templateEntityTypesInUse returns the entity types of the workflows whose actions send a template
+func (s *WorkflowService) templateEntityTypesInUse(ctx context.Context, orgID, templateID uuid.UUID) ([]string, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT DISTINCT w.entity_type
+		FROM workflow_actions a
+		JOIN workflow_triggers t ON t.id = a.trigger_id
+		JOIN workflows w ON w.id = t.workflow_id
+		WHERE a.template_id = $1 AND w.organization_id = $2
+		ORDER BY w.entity_type
+	`, templateID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template usage: %w", err)
+	}
+	defer rows.Close()
+
+	var entityTypes []string
+	for rows.Next() {
+		var entityType string
+		if err := rows.Scan(&entityType); err != nil {
+			return nil, fmt.Errorf("failed to scan template usage: %w", err)
+		}
+		entityTypes = append(entityTypes, entityType)
+	}
+	return entityTypes, rows.Err()
+}
+
+// LintTemplateForSave checks a template before it is created or updated (templateID nil on
+// create). Its variables must be available for every entity type of the workflows that send it;
+// a template no workflow sends yet may use the variables of any entity type. Lint errors block
+// the save unless force is set. The issues found are returned to be shown with the saved template.
+func (s *WorkflowService) LintTemplateForSave(ctx context.Context, orgID uuid.UUID, templateID *uuid.UUID, t *models.MessageTemplate, force bool) ([]models.TemplateIssue, error) {
+	declared, err := t.GetVariables()
+	if err != nil {
+		return nil, apperrors.NewValidationErrors([]apperrors.ValidationError{
+			{Field: "variables", Message: "variables must be a list of objects with name and description"},
+		})
+	}
+
+	var entityTypes []string
+	if templateID != nil {
+		if entityTypes, err = s.templateEntityTypesInUse(ctx, orgID, *templateID); err != nil {
+			return nil, err
+		}
+	}
+
+	known := make(map[string]bool)
+	if len(entityTypes) == 0 {
+		for _, entityType := range templateEntityTypes {
+			for name := range templateVariables(entityType) {
+				known[name] = true
+			}
+		}
+	} else {
+		for name := range templateVariables(entityTypes[0]) {
+			known[name] = true
+		}
+		for _, entityType := range entityTypes[1:] {
+			available := templateVariables(entityType)
+			for name := range known {
+				if !available[name] {
+					delete(known, name)
+				}
+			}
+		}
+	}
+
+	// Length limits apply to the longest rendering among the entity types
+	rendered := ""
+	candidates := entityTypes
+	if len(candidates) == 0 {
+		candidates = templateEntityTypes
+	}
+	for _, entityType := range candidates {
+		body := renderTemplateString(t.Body, GetSampleDataForEntityType(entityType))
+		if utf8.RuneCountInString(body) > utf8.RuneCountInString(rendered) {
+			rendered = body
+		}
+	}
+
+	issues := workflow.LintTemplate(t.Channel, t.Subject, t.Body, rendered, known)
+	issues = append(issues, workflow.UnusedVariables(declared, t.Subject, t.Body)...)
+
+	if !force {
+		var fieldErrors []apperrors.ValidationError
+		for _, issue := range issues {
+			if issue.Severity == models.TemplateIssueError {
+				fieldErrors = append(fieldErrors, apperrors.ValidationError{Field: issue.Field, Message: issue.Message})
+			}
+		}
+		if len(fieldErrors) > 0 {
+			return nil, apperrors.NewValidationErrors(fieldErrors)
+		}
+	}
+
+	return issues, nil
+}
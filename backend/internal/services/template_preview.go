@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/workflow"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// TemplatePreviewRequest selects what a template is previewed against. Subject and Body override
+// the saved template, so unsaved edits can be previewed and linted.
+type TemplatePreviewRequest struct {
+	EntityType string     `json:"entity_type"`
+	EntityID   *uuid.UUID `json:"entity_id"` // sample data when nil
+	Subject    *string    `json:"subject"`
+	Body       *string    `json:"body"`
+	Lint       bool       `json:"lint"`
+}
+
+// PreviewTemplate renders a message template against a real entity or sample data and, in lint
+// mode, reports the issues found in it
+func (s *WorkflowService) PreviewTemplate(ctx context.Context, orgID, templateID uuid.UUID, req TemplatePreviewRequest) (*models.TemplatePreview, error) {
+	template, err := s.GetTemplateByID(ctx, templateID, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	subject, body := template.Subject, template.Body
+	if req.Subject != nil {
+		subject = req.Subject
+	}
+	if req.Body != nil {
+		body = *req.Body
+	}
+	if req.EntityType == "" {
+		req.EntityType = "session"
+	}
+
+	var data map[string]interface{}
+	if req.EntityID != nil {
+		data, err = workflow.NewExecutor(s.db).EntityData(ctx, orgID, req.EntityType, *req.EntityID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, errors.New("entity not found")
+			}
+			return nil, fmt.Errorf("failed to get entity data: %w", err)
+		}
+	} else {
+		data = GetSampleDataForEntityType(req.EntityType)
+	}
+
+	preview := &models.TemplatePreview{
+		TemplateID: template.ID,
+		Channel:    template.Channel,
+		EntityType: req.EntityType,
+		EntityID:   req.EntityID,
+		Body:       renderTemplateString(body, data),
+	}
+	if subject != nil {
+		rendered := renderTemplateString(*subject, data)
+		preview.Subject = &rendered
+	}
+
+	if req.Lint {
+		known := templateVariables(req.EntityType)
+		for name := range data {
+			known[name] = true
+		}
+		preview.Issues = workflow.LintTemplate(template.Channel, subject, body, preview.Body, known)
+	}
+
+	return preview, nil
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/controlwise/backend/internal/models"
+)
+
+// sampleTemplateData returns sample data for every entity type, so test sends show realistic
+// content whatever the template is used for
+func sampleTemplateData() map[string]interface{} {
+	data := map[string]interface{}{}
+	for _, entityType := range []string{"invoice", "project", "budget", "session"} {
+		for key, value := range GetSampleDataForEntityType(entityType) {
+			data[key] = value
+		}
+	}
+	return data
+}
+
+// RenderSampleTemplate renders a message template with sample data
+func RenderSampleTemplate(template *models.MessageTemplate) (subject, body string) {
+	data := sampleTemplateData()
+	if template.Subject != nil {
+		subject = renderTemplateString(*template.Subject, data)
+	}
+	return subject, renderTemplateString(template.Body, data)
+}
+
+// SampleTemplateVariables returns the numbered placeholders of a template's approved WhatsApp
+// template filled with sample data, in the order of the template variables
+func SampleTemplateVariables(template *models.MessageTemplate) (map[string]string, error) {
+	vars, err := template.GetVariables()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template variables: %w", err)
+	}
+
+	data := sampleTemplateData()
+	values := make(map[string]string, len(vars))
+	for i, v := range vars {
+		value := ""
+		if sample, ok := data[v.Name]; ok {
+			value = fmt.Sprintf("%v", sample)
+		}
+		values[strconv.Itoa(i+1)] = value
+	}
+	return values, nil
+}
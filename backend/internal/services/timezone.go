@@ -0,0 +1,20 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/controlwise/backend/internal/workflow"
+	"github.com/google/uuid"
+)
+
+// orgLocation returns the timezone an organization's dates and times are shown and counted in
+func orgLocation(ctx context.Context, q rowQuerier, orgID uuid.UUID) *time.Location {
+	return workflow.OrganizationLocation(ctx, q, orgID)
+}
+
+// triggerTime returns when a time trigger offset by minutes from base runs, whole days counted on
+// the organization's wall clock
+func (s *WorkflowService) triggerTime(ctx context.Context, orgID uuid.UUID, base time.Time, minutes int) time.Time {
+	return workflow.OffsetTime(base, minutes, orgLocation(ctx, s.db.Pool, orgID))
+}
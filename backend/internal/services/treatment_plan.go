@@ -0,0 +1,619 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxTreatmentPlanGoals bounds the goals of one treatment plan
+const maxTreatmentPlanGoals = 20
+
+// TreatmentPlanService handles patients' treatment plans and the coded outcomes of their sessions
+type TreatmentPlanService struct {
+	db *database.DB
+}
+
+func NewTreatmentPlanService(db *database.DB) *TreatmentPlanService {
+	return &TreatmentPlanService{db: db}
+}
+
+// TreatmentPlanGoalInput is a goal of a treatment plan; goals without an ID are added
+type TreatmentPlanGoalInput struct {
+	ID          *uuid.UUID `json:"id"`
+	Description string     `json:"description"`
+}
+
+// TreatmentPlanInput is the request body for creating or updating a treatment plan. Goals left
+// out of an update are removed.
+type TreatmentPlanInput struct {
+	TherapistID     *uuid.UUID                 `json:"therapist_id"`
+	Title           string                     `json:"title"`
+	PlannedSessions *int                       `json:"planned_sessions"`
+	StartDate       *time.Time                 `json:"start_date"` // today when not set
+	ReviewDate      *time.Time                 `json:"review_date"`
+	Status          models.TreatmentPlanStatus `json:"status"` // update only
+	Notes           *string                    `json:"notes"`
+	Goals           []TreatmentPlanGoalInput   `json:"goals"`
+}
+
+// SessionOutcomeInput is the request body for coding a session's outcome. The plan defaults to
+// the patient's active plan.
+type SessionOutcomeInput struct {
+	PlanID         *uuid.UUID                `json:"plan_id"`
+	Outcome        models.SessionOutcomeCode `json:"outcome"`
+	ProgressRating *int                      `json:"progress_rating"`
+	GoalIDs        []uuid.UUID               `json:"goal_ids"`
+	Notes          *string                   `json:"notes"`
+}
+
+// validateTreatmentPlanInput checks a plan, trimming its title and goals
+func validateTreatmentPlanInput(input *TreatmentPlanInput) error {
+	input.Title = strings.TrimSpace(input.Title)
+	if input.Title == "" {
+		return errors.New("treatment plan title is required")
+	}
+	if input.PlannedSessions != nil && *input.PlannedSessions <= 0 {
+		return errors.New("planned sessions must be greater than zero")
+	}
+	if input.StartDate != nil && input.ReviewDate != nil && input.ReviewDate.Before(*input.StartDate) {
+		return errors.New("review date must not be before the start date")
+	}
+	if input.Status != "" && !input.Status.IsValid() {
+		return errors.New("invalid treatment plan status")
+	}
+	if len(input.Goals) > maxTreatmentPlanGoals {
+		return errors.New("a treatment plan can have at most 20 goals")
+	}
+	for i := range input.Goals {
+		input.Goals[i].Description = strings.TrimSpace(input.Goals[i].Description)
+		if input.Goals[i].Description == "" {
+			return errors.New("goal description is required")
+		}
+	}
+	return nil
+}
+
+// validateSessionOutcomeInput checks a session outcome, dropping repeated goals
+func validateSessionOutcomeInput(input *SessionOutcomeInput) error {
+	if !input.Outcome.IsValid() {
+		return errors.New("invalid session outcome")
+	}
+	if input.ProgressRating != nil && (*input.ProgressRating < 0 || *input.ProgressRating > 10) {
+		return errors.New("progress rating must be between 0 and 10")
+	}
+	seen := make(map[uuid.UUID]bool)
+	goals := []uuid.UUID{}
+	for _, id := range input.GoalIDs {
+		if !seen[id] {
+			seen[id] = true
+			goals = append(goals, id)
+		}
+	}
+	input.GoalIDs = goals
+	return nil
+}
+
+// summarizeTreatmentPlan works out how a plan is progressing from its coded outcomes, oldest
+// first, and the patient's completed sessions since it started
+func summarizeTreatmentPlan(plan *models.TreatmentPlan, outcomes []*models.SessionOutcome, completedSessions int, today time.Time) *models.TreatmentPlanSummary {
+	summary := &models.TreatmentPlanSummary{
+		PlanID:            plan.ID,
+		CompletedSessions: completedSessions,
+		PlannedSessions:   plan.PlannedSessions,
+		CodedSessions:     len(outcomes),
+		Goals:             []*models.TreatmentPlanGoalProgress{},
+	}
+
+	if plan.PlannedSessions != nil && *plan.PlannedSessions > 0 {
+		progress := completedSessions * 100 / *plan.PlannedSessions
+		if progress > 100 {
+			progress = 100
+		}
+		summary.SessionsProgress = &progress
+	}
+
+	goals := make(map[uuid.UUID]*models.TreatmentPlanGoalProgress, len(plan.Goals))
+	for _, g := range plan.Goals {
+		progress := &models.TreatmentPlanGoalProgress{
+			GoalID:      g.ID,
+			Description: g.Description,
+			Achieved:    g.AchievedAt != nil,
+		}
+		if progress.Achieved {
+			summary.GoalsAchieved++
+		}
+		goals[g.ID] = progress
+		summary.Goals = append(summary.Goals, progress)
+	}
+
+	var ratingSum, rated int
+	for _, o := range outcomes {
+		switch o.Outcome {
+		case models.SessionOutcomeImproved:
+			summary.Improved++
+		case models.SessionOutcomeStable:
+			summary.Stable++
+		case models.SessionOutcomeWorsened:
+			summary.Worsened++
+		}
+		if o.ProgressRating != nil {
+			if summary.FirstRating == nil {
+				summary.FirstRating = o.ProgressRating
+			}
+			summary.LatestRating = o.ProgressRating
+			ratingSum += *o.ProgressRating
+			rated++
+		}
+		for _, id := range o.GoalIDs {
+			if g, ok := goals[id]; ok {
+				g.SessionsWorked++
+				at := o.ScheduledAt
+				g.LastWorkedOnAt = &at
+			}
+		}
+	}
+	if rated > 0 {
+		average := math.Round(float64(ratingSum)/float64(rated)*10) / 10
+		summary.AverageRating = &average
+	}
+
+	if plan.ReviewDate != nil {
+		day := func(t time.Time) time.Time { return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC) }
+		days := int(day(*plan.ReviewDate).Sub(day(today)).Hours() / 24)
+		summary.DaysToReview = &days
+	}
+	return summary
+}
+
+const treatmentPlanColumns = `tp.id, tp.organization_id, tp.patient_id, tp.therapist_id, tp.title, tp.planned_sessions,
+	tp.start_date, tp.review_date, tp.review_notified_at, tp.status, tp.notes, tp.created_by, tp.created_at, tp.updated_at,
+	COALESCE(c.name, ''), t.name`
+
+const treatmentPlanJoins = `FROM treatment_plans tp
+	JOIN patients p ON p.id = tp.patient_id
+	LEFT JOIN clients c ON c.id = p.client_id
+	LEFT JOIN therapists t ON t.id = tp.therapist_id`
+
+func scanTreatmentPlan(row pgx.Row) (*models.TreatmentPlan, error) {
+	var tp models.TreatmentPlan
+	err := row.Scan(&tp.ID, &tp.OrganizationID, &tp.PatientID, &tp.TherapistID, &tp.Title, &tp.PlannedSessions,
+		&tp.StartDate, &tp.ReviewDate, &tp.ReviewNotifiedAt, &tp.Status, &tp.Notes, &tp.CreatedBy, &tp.CreatedAt, &tp.UpdatedAt,
+		&tp.PatientName, &tp.TherapistName)
+	if err != nil {
+		return nil, err
+	}
+	tp.Goals = []*models.TreatmentPlanGoal{}
+	return &tp, nil
+}
+
+// loadGoals fills in the goals of the plans
+func (s *TreatmentPlanService) loadGoals(ctx context.Context, plans ...*models.TreatmentPlan) error {
+	if len(plans) == 0 {
+		return nil
+	}
+	byID := make(map[uuid.UUID]*models.TreatmentPlan, len(plans))
+	ids := make([]uuid.UUID, 0, len(plans))
+	for _, tp := range plans {
+		byID[tp.ID] = tp
+		ids = append(ids, tp.ID)
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, plan_id, description, position, achieved_at
+		FROM treatment_plan_goals
+		WHERE plan_id = ANY($1)
+		ORDER BY position, created_at
+	`, ids)
+	if err != nil {
+		return fmt.Errorf("failed to get treatment plan goals: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var g models.TreatmentPlanGoal
+		if err := rows.Scan(&g.ID, &g.PlanID, &g.Description, &g.Position, &g.AchievedAt); err != nil {
+			return fmt.Errorf("failed to scan treatment plan goal: %w", err)
+		}
+		byID[g.PlanID].Goals = append(byID[g.PlanID].Goals, &g)
+	}
+	return rows.Err()
+}
+
+// ListByPatient returns a patient's treatment plans, latest first
+func (s *TreatmentPlanService) ListByPatient(ctx context.Context, patientID, orgID uuid.UUID) ([]*models.TreatmentPlan, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT `+treatmentPlanColumns+` `+treatmentPlanJoins+`
+		WHERE tp.patient_id = $1 AND tp.organization_id = $2
+		ORDER BY tp.start_date DESC, tp.created_at DESC
+	`, patientID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list treatment plans: %w", err)
+	}
+	defer rows.Close()
+
+	plans := []*models.TreatmentPlan{}
+	for rows.Next() {
+		tp, err := scanTreatmentPlan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan treatment plan: %w", err)
+		}
+		plans = append(plans, tp)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if err := s.loadGoals(ctx, plans...); err != nil {
+		return nil, err
+	}
+	return plans, nil
+}
+
+// GetByID returns a treatment plan with its goals
+func (s *TreatmentPlanService) GetByID(ctx context.Context, id, orgID uuid.UUID) (*models.TreatmentPlan, error) {
+	tp, err := scanTreatmentPlan(s.db.Pool.QueryRow(ctx, `
+		SELECT `+treatmentPlanColumns+` `+treatmentPlanJoins+`
+		WHERE tp.id = $1 AND tp.organization_id = $2
+	`, id, orgID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("treatment plan not found")
+		}
+		return nil, fmt.Errorf("failed to get treatment plan: %w", err)
+	}
+	if err := s.loadGoals(ctx, tp); err != nil {
+		return nil, err
+	}
+	return tp, nil
+}
+
+// checkTherapist verifies the therapist belongs to the organization
+func (s *TreatmentPlanService) checkTherapist(ctx context.Context, orgID uuid.UUID, therapistID *uuid.UUID) error {
+	if therapistID == nil {
+		return nil
+	}
+	var exists bool
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM therapists WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL)
+	`, *therapistID, orgID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check therapist: %w", err)
+	}
+	if !exists {
+		return errors.New("therapist not found")
+	}
+	return nil
+}
+
+// Create adds a treatment plan for a patient
+func (s *TreatmentPlanService) Create(ctx context.Context, patientID, orgID, userID uuid.UUID, input TreatmentPlanInput) (*models.TreatmentPlan, error) {
+	if input.StartDate == nil {
+		today := time.Now()
+		input.StartDate = &today
+	}
+	input.Status = ""
+	if err := validateTreatmentPlanInput(&input); err != nil {
+		return nil, err
+	}
+	for _, g := range input.Goals {
+		if g.ID != nil {
+			return nil, errors.New("goal not found")
+		}
+	}
+
+	var patientExists bool
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM patients WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL)
+	`, patientID, orgID).Scan(&patientExists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check patient: %w", err)
+	}
+	if !patientExists {
+		return nil, errors.New("patient not found")
+	}
+	if err := s.checkTherapist(ctx, orgID, input.TherapistID); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var id uuid.UUID
+	err = tx.QueryRow(ctx, `
+		INSERT INTO treatment_plans (organization_id, patient_id, therapist_id, title, planned_sessions,
+			start_date, review_date, notes, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6::date, $7::date, $8, $9)
+		RETURNING id
+	`, orgID, patientID, input.TherapistID, input.Title, input.PlannedSessions,
+		input.StartDate, input.ReviewDate, input.Notes, userID).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create treatment plan: %w", err)
+	}
+	if err := saveTreatmentPlanGoals(ctx, tx, id, input.Goals); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return s.GetByID(ctx, id, orgID)
+}
+
+// saveTreatmentPlanGoals replaces the goals of a plan with the given ones, keeping the ones with
+// an ID and their achievement
+func saveTreatmentPlanGoals(ctx context.Context, tx pgx.Tx, planID uuid.UUID, goals []TreatmentPlanGoalInput) error {
+	kept := []uuid.UUID{}
+	for _, g := range goals {
+		if g.ID != nil {
+			kept = append(kept, *g.ID)
+		}
+	}
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM treatment_plan_goals WHERE plan_id = $1 AND NOT (id = ANY($2))
+	`, planID, kept); err != nil {
+		return fmt.Errorf("failed to remove treatment plan goals: %w", err)
+	}
+
+	for position, g := range goals {
+		if g.ID == nil {
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO treatment_plan_goals (plan_id, description, position) VALUES ($1, $2, $3)
+			`, planID, g.Description, position); err != nil {
+				return fmt.Errorf("failed to add treatment plan goal: %w", err)
+			}
+			continue
+		}
+		result, err := tx.Exec(ctx, `
+			UPDATE treatment_plan_goals SET description = $1, position = $2 WHERE id = $3 AND plan_id = $4
+		`, g.Description, position, *g.ID, planID)
+		if err != nil {
+			return fmt.Errorf("failed to update treatment plan goal: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			return errors.New("goal not found")
+		}
+	}
+	return nil
+}
+
+// Update changes a treatment plan and its goals. A new review date is reviewed again.
+func (s *TreatmentPlanService) Update(ctx context.Context, id, orgID uuid.UUID, input TreatmentPlanInput) (*models.TreatmentPlan, error) {
+	existing, err := s.GetByID(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if input.StartDate == nil {
+		input.StartDate = &existing.StartDate
+	}
+	if input.Status == "" {
+		input.Status = existing.Status
+	}
+	if err := validateTreatmentPlanInput(&input); err != nil {
+		return nil, err
+	}
+	if err := s.checkTherapist(ctx, orgID, input.TherapistID); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE treatment_plans
+		SET therapist_id = $1, title = $2, planned_sessions = $3, start_date = $4::date, status = $5, notes = $6,
+			review_notified_at = CASE WHEN review_date IS NOT DISTINCT FROM $7::date THEN review_notified_at END,
+			review_date = $7::date
+		WHERE id = $8 AND organization_id = $9
+	`, input.TherapistID, input.Title, input.PlannedSessions, input.StartDate, input.Status, input.Notes,
+		input.ReviewDate, id, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update treatment plan: %w", err)
+	}
+	if err := saveTreatmentPlanGoals(ctx, tx, id, input.Goals); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return s.GetByID(ctx, id, orgID)
+}
+
+// Delete removes a treatment plan. Its coded session outcomes are kept without a plan.
+func (s *TreatmentPlanService) Delete(ctx context.Context, id, orgID uuid.UUID) error {
+	result, err := s.db.Pool.Exec(ctx, `
+		DELETE FROM treatment_plans WHERE id = $1 AND organization_id = $2
+	`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to delete treatment plan: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("treatment plan not found")
+	}
+	return nil
+}
+
+// SetGoalAchieved marks a goal of a plan as achieved, or not
+func (s *TreatmentPlanService) SetGoalAchieved(ctx context.Context, planID, goalID, orgID uuid.UUID, achieved bool) (*models.TreatmentPlan, error) {
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE treatment_plan_goals g
+		SET achieved_at = CASE WHEN $1 THEN COALESCE(g.achieved_at, NOW()) END
+		FROM treatment_plans tp
+		WHERE g.id = $2 AND g.plan_id = $3 AND tp.id = g.plan_id AND tp.organization_id = $4
+	`, achieved, goalID, planID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update goal: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, errors.New("goal not found")
+	}
+	return s.GetByID(ctx, planID, orgID)
+}
+
+const sessionOutcomeColumns = `o.session_id, o.organization_id, o.plan_id, o.outcome, o.progress_rating, o.goal_ids,
+	o.notes, o.recorded_by, o.recorded_at, s.scheduled_at`
+
+func scanSessionOutcome(row pgx.Row) (*models.SessionOutcome, error) {
+	var o models.SessionOutcome
+	err := row.Scan(&o.SessionID, &o.OrganizationID, &o.PlanID, &o.Outcome, &o.ProgressRating, &o.GoalIDs,
+		&o.Notes, &o.RecordedBy, &o.RecordedAt, &o.ScheduledAt)
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// GetOutcome returns the coded outcome of a session
+func (s *TreatmentPlanService) GetOutcome(ctx context.Context, sessionID, orgID uuid.UUID) (*models.SessionOutcome, error) {
+	o, err := scanSessionOutcome(s.db.Pool.QueryRow(ctx, `
+		SELECT `+sessionOutcomeColumns+`
+		FROM session_outcomes o
+		JOIN sessions s ON s.id = o.session_id
+		WHERE o.session_id = $1 AND o.organization_id = $2
+	`, sessionID, orgID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("session outcome not found")
+		}
+		return nil, fmt.Errorf("failed to get session outcome: %w", err)
+	}
+	return o, nil
+}
+
+// RecordOutcome codes the outcome of a completed session, replacing any earlier one
+func (s *TreatmentPlanService) RecordOutcome(ctx context.Context, sessionID, orgID, userID uuid.UUID, input SessionOutcomeInput) (*models.SessionOutcome, error) {
+	if err := validateSessionOutcomeInput(&input); err != nil {
+		return nil, err
+	}
+
+	var patientID uuid.UUID
+	var status models.SessionStatus
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT patient_id, status FROM sessions
+		WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`, sessionID, orgID).Scan(&patientID, &status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("session not found")
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if status != models.SessionStatusCompleted {
+		return nil, errors.New("only completed sessions can have an outcome")
+	}
+
+	if input.PlanID == nil {
+		var planID uuid.UUID
+		err := s.db.Pool.QueryRow(ctx, `
+			SELECT id FROM treatment_plans
+			WHERE patient_id = $1 AND organization_id = $2 AND status = 'active'
+			ORDER BY start_date DESC, created_at DESC
+			LIMIT 1
+		`, patientID, orgID).Scan(&planID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("failed to find treatment plan: %w", err)
+		}
+		if err == nil {
+			input.PlanID = &planID
+		}
+	}
+	if input.PlanID == nil {
+		if len(input.GoalIDs) > 0 {
+			return nil, errors.New("goal not found")
+		}
+	} else {
+		plan, err := s.GetByID(ctx, *input.PlanID, orgID)
+		if err != nil {
+			return nil, err
+		}
+		if plan.PatientID != patientID {
+			return nil, errors.New("the treatment plan is for another patient")
+		}
+		goals := make(map[uuid.UUID]bool, len(plan.Goals))
+		for _, g := range plan.Goals {
+			goals[g.ID] = true
+		}
+		for _, id := range input.GoalIDs {
+			if !goals[id] {
+				return nil, errors.New("goal not found")
+			}
+		}
+	}
+
+	_, err = s.db.Pool.Exec(ctx, `
+		INSERT INTO session_outcomes (session_id, organization_id, plan_id, outcome, progress_rating, goal_ids, notes, recorded_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (session_id) DO UPDATE SET
+			plan_id = EXCLUDED.plan_id, outcome = EXCLUDED.outcome, progress_rating = EXCLUDED.progress_rating,
+			goal_ids = EXCLUDED.goal_ids, notes = EXCLUDED.notes, recorded_by = EXCLUDED.recorded_by, recorded_at = NOW()
+	`, sessionID, orgID, input.PlanID, input.Outcome, input.ProgressRating, input.GoalIDs, input.Notes, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record session outcome: %w", err)
+	}
+	return s.GetOutcome(ctx, sessionID, orgID)
+}
+
+// ListOutcomes returns the coded outcomes of a plan's sessions, oldest first
+func (s *TreatmentPlanService) ListOutcomes(ctx context.Context, planID, orgID uuid.UUID) ([]*models.SessionOutcome, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT `+sessionOutcomeColumns+`
+		FROM session_outcomes o
+		JOIN sessions s ON s.id = o.session_id
+		WHERE o.plan_id = $1 AND o.organization_id = $2 AND s.deleted_at IS NULL
+		ORDER BY s.scheduled_at
+	`, planID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session outcomes: %w", err)
+	}
+	defer rows.Close()
+
+	outcomes := []*models.SessionOutcome{}
+	for rows.Next() {
+		o, err := scanSessionOutcome(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session outcome: %w", err)
+		}
+		outcomes = append(outcomes, o)
+	}
+	return outcomes, rows.Err()
+}
+
+// Summary returns how a treatment plan is progressing
+func (s *TreatmentPlanService) Summary(ctx context.Context, planID, orgID uuid.UUID) (*models.TreatmentPlanSummary, error) {
+	plan, err := s.GetByID(ctx, planID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	outcomes, err := s.ListOutcomes(ctx, planID, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	var completed int
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM sessions
+		WHERE patient_id = $1 AND organization_id = $2 AND status = 'completed' AND deleted_at IS NULL
+			AND scheduled_at >= $3::date
+	`, plan.PatientID, orgID, plan.StartDate).Scan(&completed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count completed sessions: %w", err)
+	}
+
+	return summarizeTreatmentPlan(plan, outcomes, completed, time.Now()), nil
+}
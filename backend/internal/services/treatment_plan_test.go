@@ -0,0 +1,87 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+func TestSummarizeTreatmentPlan(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 10, 0, 0, 0, time.UTC) }
+	achieved := day(5)
+	review := day(20)
+	goalA, goalB := uuid.New(), uuid.New()
+	plan := &models.TreatmentPlan{
+		ID:              uuid.New(),
+		PlannedSessions: intPtr(8),
+		ReviewDate:      &review,
+		Goals: []*models.TreatmentPlanGoal{
+			{ID: goalA, Description: "Reduzir a dor"},
+			{ID: goalB, Description: "Recuperar mobilidade", AchievedAt: &achieved},
+		},
+	}
+	outcomes := []*models.SessionOutcome{
+		{Outcome: models.SessionOutcomeStable, ProgressRating: intPtr(3), GoalIDs: []uuid.UUID{goalA}, ScheduledAt: day(2)},
+		{Outcome: models.SessionOutcomeImproved, GoalIDs: []uuid.UUID{goalA, goalB}, ScheduledAt: day(9)},
+		{Outcome: models.SessionOutcomeImproved, ProgressRating: intPtr(6), GoalIDs: []uuid.UUID{uuid.New()}, ScheduledAt: day(16)},
+	}
+
+	got := summarizeTreatmentPlan(plan, outcomes, 4, day(17))
+
+	if got.SessionsProgress == nil || *got.SessionsProgress != 50 {
+		t.Errorf("SessionsProgress = %v, want 50", got.SessionsProgress)
+	}
+	if got.CodedSessions != 3 || got.Improved != 2 || got.Stable != 1 || got.Worsened != 0 {
+		t.Errorf("outcome counts = %d/%d/%d of %d, want 2/1/0 of 3", got.Improved, got.Stable, got.Worsened, got.CodedSessions)
+	}
+	if got.AverageRating == nil || *got.AverageRating != 4.5 {
+		t.Errorf("AverageRating = %v, want 4.5", got.AverageRating)
+	}
+	if got.FirstRating == nil || *got.FirstRating != 3 || got.LatestRating == nil || *got.LatestRating != 6 {
+		t.Errorf("ratings = %v..%v, want 3..6", got.FirstRating, got.LatestRating)
+	}
+	if got.GoalsAchieved != 1 {
+		t.Errorf("GoalsAchieved = %d, want 1", got.GoalsAchieved)
+	}
+	if got.Goals[0].SessionsWorked != 2 || !got.Goals[0].LastWorkedOnAt.Equal(day(9)) {
+		t.Errorf("goal A worked %d times, last %v; want 2, %v", got.Goals[0].SessionsWorked, got.Goals[0].LastWorkedOnAt, day(9))
+	}
+	if got.DaysToReview == nil || *got.DaysToReview != 3 {
+		t.Errorf("DaysToReview = %v, want 3", got.DaysToReview)
+	}
+
+	over := summarizeTreatmentPlan(plan, nil, 12, day(25))
+	if *over.SessionsProgress != 100 || *over.DaysToReview != -5 || over.AverageRating != nil {
+		t.Errorf("past plan = %d%%, %d days, rating %v; want 100%%, -5 days, no rating",
+			*over.SessionsProgress, *over.DaysToReview, over.AverageRating)
+	}
+}
+
+func TestValidateSessionOutcomeInput(t *testing.T) {
+	goal := uuid.New()
+	tests := []struct {
+		name    string
+		input   SessionOutcomeInput
+		wantErr bool
+	}{
+		{"valid", SessionOutcomeInput{Outcome: models.SessionOutcomeImproved, ProgressRating: intPtr(10)}, false},
+		{"repeated goals", SessionOutcomeInput{Outcome: models.SessionOutcomeStable, GoalIDs: []uuid.UUID{goal, goal}}, false},
+		{"unknown outcome", SessionOutcomeInput{Outcome: "better"}, true},
+		{"rating too high", SessionOutcomeInput{Outcome: models.SessionOutcomeWorsened, ProgressRating: intPtr(11)}, true},
+		{"negative rating", SessionOutcomeInput{Outcome: models.SessionOutcomeWorsened, ProgressRating: intPtr(-1)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSessionOutcomeInput(&tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateSessionOutcomeInput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && len(tt.input.GoalIDs) > 1 {
+				t.Errorf("GoalIDs = %v, want repeated goals dropped", tt.input.GoalIDs)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxTriggerRunFailures bounds the failed recipients returned with a run
+const maxTriggerRunFailures = 100
+
+const triggerRunColumns = `
+	id, organization_id, trigger_id, source, status, total_count, completed_count, failed_count,
+	created_at, started_at, finished_at
+`
+
+func scanTriggerRun(row pgx.Row) (*models.TriggerRun, error) {
+	var run models.TriggerRun
+	err := row.Scan(
+		&run.ID, &run.OrganizationID, &run.TriggerID, &run.Source, &run.Status,
+		&run.TotalCount, &run.CompletedCount, &run.FailedCount,
+		&run.CreatedAt, &run.StartedAt, &run.FinishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if run.TotalCount > 0 {
+		run.Progress = float64(run.CompletedCount+run.FailedCount) * 100 / float64(run.TotalCount)
+	}
+	return &run, nil
+}
+
+// ListTriggerRuns returns the organization's bulk trigger runs, most recent first
+func (s *WorkflowService) ListTriggerRuns(ctx context.Context, orgID uuid.UUID, triggerID *uuid.UUID, limit int) ([]*models.TriggerRun, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT `+triggerRunColumns+`
+		FROM trigger_runs
+		WHERE organization_id = $1 AND ($2::uuid IS NULL OR trigger_id = $2)
+		ORDER BY created_at DESC
+		LIMIT $3
+	`, orgID, triggerID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trigger runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []*models.TriggerRun{}
+	for rows.Next() {
+		run, err := scanTriggerRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan trigger run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, nil
+}
+
+// GetTriggerRun returns the progress of a bulk trigger run with its failed recipients
+func (s *WorkflowService) GetTriggerRun(ctx context.Context, id, orgID uuid.UUID) (*models.TriggerRun, error) {
+	run, err := scanTriggerRun(s.db.Pool.QueryRow(ctx, `
+		SELECT `+triggerRunColumns+`
+		FROM trigger_runs
+		WHERE id = $1 AND organization_id = $2
+	`, id, orgID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("trigger run not found")
+		}
+		return nil, fmt.Errorf("failed to get trigger run: %w", err)
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, run_id, entity_type, entity_id, status, attempts, last_error, processed_at
+		FROM trigger_run_items
+		WHERE run_id = $1 AND status = 'failed'
+		ORDER BY processed_at DESC
+		LIMIT $2
+	`, id, maxTriggerRunFailures)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trigger run failures: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.TriggerRunItem
+		if err := rows.Scan(
+			&item.ID, &item.RunID, &item.EntityType, &item.EntityID, &item.Status,
+			&item.Attempts, &item.LastError, &item.ProcessedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan trigger run item: %w", err)
+		}
+		run.Failures = append(run.Failures, item)
+	}
+
+	return run, nil
+}
@@ -0,0 +1,288 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// usageWarningRatio is the share of a quota from which usage is reported as approaching it
+const usageWarningRatio = 0.8
+
+// UsageService measures organizations' data usage against their quotas
+type UsageService struct {
+	db *database.DB
+}
+
+func NewUsageService(db *database.DB) *UsageService {
+	return &UsageService{db: db}
+}
+
+const usageColumns = `
+	u.organization_id, o.name, u.photo_count, u.photo_bytes, u.document_count, u.receipt_count,
+	u.export_bytes, u.storage_bytes, u.messages_this_month, u.messages_by_channel, u.row_counts,
+	u.computed_at, o.storage_quota_bytes, o.monthly_message_quota`
+
+func scanUsage(row pgx.Row) (*models.OrganizationUsage, error) {
+	var u models.OrganizationUsage
+	err := row.Scan(
+		&u.OrganizationID, &u.OrganizationName, &u.PhotoCount, &u.PhotoBytes, &u.DocumentCount, &u.ReceiptCount,
+		&u.ExportBytes, &u.StorageBytes, &u.MessagesThisMonth, &u.MessagesByChannel, &u.RowCounts,
+		&u.ComputedAt, &u.StorageQuotaBytes, &u.MonthlyMessageQuota,
+	)
+	if err != nil {
+		return nil, err
+	}
+	u.Warnings = usageWarnings(&u)
+	return &u, nil
+}
+
+// usageWarnings lists the quotas the usage is approaching or over
+func usageWarnings(u *models.OrganizationUsage) []models.UsageWarning {
+	warnings := []models.UsageWarning{}
+	check := func(quota models.UsageQuota, used, limit int64) {
+		if limit <= 0 {
+			return
+		}
+		ratio := float64(used) / float64(limit)
+		if ratio < usageWarningRatio {
+			return
+		}
+		level := models.UsageWarningApproaching
+		if ratio >= 1 {
+			level = models.UsageWarningExceeded
+		}
+		warnings = append(warnings, models.UsageWarning{
+			Quota:   quota,
+			Level:   level,
+			Used:    used,
+			Limit:   limit,
+			Percent: float64(int(ratio*1000)) / 10,
+		})
+	}
+
+	if u.StorageQuotaBytes != nil {
+		check(models.UsageQuotaStorage, u.StorageBytes, *u.StorageQuotaBytes)
+	}
+	if u.MonthlyMessageQuota != nil {
+		check(models.UsageQuotaMessages, int64(u.MessagesThisMonth), int64(*u.MonthlyMessageQuota))
+	}
+	return warnings
+}
+
+// Get returns the organization's latest usage snapshot, computing it if there is none yet
+func (s *UsageService) Get(ctx context.Context, orgID uuid.UUID) (*models.OrganizationUsage, error) {
+	usage, err := scanUsage(s.db.Pool.QueryRow(ctx, `
+		SELECT `+usageColumns+`
+		FROM organization_usage u
+		JOIN organizations o ON o.id = u.organization_id
+		WHERE u.organization_id = $1
+	`, orgID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		if err := s.Refresh(ctx, orgID); err != nil {
+			return nil, err
+		}
+		return s.Get(ctx, orgID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usage: %w", err)
+	}
+	return usage, nil
+}
+
+// List returns the organizations' usage snapshots, largest storage first.
+// With warningsOnly, only organizations at 80% or more of a quota are returned.
+func (s *UsageService) List(ctx context.Context, warningsOnly bool, limit, offset int) ([]*models.OrganizationUsage, int, error) {
+	where := "WHERE o.deleted_at IS NULL"
+	if warningsOnly {
+		where += fmt.Sprintf(` AND (
+			u.storage_bytes >= o.storage_quota_bytes * %[1]g
+			OR u.messages_this_month >= o.monthly_message_quota * %[1]g
+		)`, usageWarningRatio)
+	}
+
+	var total int
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM organization_usage u
+		JOIN organizations o ON o.id = u.organization_id
+		`+where).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count usage: %w", err)
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT `+usageColumns+`
+		FROM organization_usage u
+		JOIN organizations o ON o.id = u.organization_id
+		`+where+`
+		ORDER BY u.storage_bytes DESC, o.name
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list usage: %w", err)
+	}
+	defer rows.Close()
+
+	usages := []*models.OrganizationUsage{}
+	for rows.Next() {
+		usage, err := scanUsage(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan usage: %w", err)
+		}
+		usages = append(usages, usage)
+	}
+
+	return usages, total, rows.Err()
+}
+
+// Refresh recomputes an organization's usage snapshot
+func (s *UsageService) Refresh(ctx context.Context, orgID uuid.UUID) error {
+	var u models.OrganizationUsage
+	u.OrganizationID = orgID
+
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM photos WHERE organization_id = $1 AND deleted_at IS NULL),
+			(SELECT COALESCE(SUM(file_size), 0) FROM photos WHERE organization_id = $1 AND deleted_at IS NULL),
+			(SELECT COUNT(*) FROM project_documents d JOIN projects p ON p.id = d.project_id
+				WHERE p.organization_id = $1 AND d.file_url IS NOT NULL)
+			+ (SELECT COUNT(*) FROM project_compliance_items i JOIN projects p ON p.id = i.project_id
+				WHERE p.organization_id = $1 AND i.document_url IS NOT NULL),
+			(SELECT COUNT(*) FROM expenses WHERE organization_id = $1 AND receipt_url IS NOT NULL AND deleted_at IS NULL),
+			(SELECT COALESCE(SUM(file_size), 0) FROM export_bundles WHERE organization_id = $1 AND status = 'ready')
+	`, orgID).Scan(&u.PhotoCount, &u.PhotoBytes, &u.DocumentCount, &u.ReceiptCount, &u.ExportBytes)
+	if err != nil {
+		return fmt.Errorf("failed to measure storage: %w", err)
+	}
+	u.StorageBytes = u.PhotoBytes + u.ExportBytes
+
+	now := time.Now().UTC()
+	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT channel, COUNT(*) FROM message_costs
+		WHERE organization_id = $1 AND created_at >= $2
+		GROUP BY channel
+	`, orgID, startOfMonth)
+	if err != nil {
+		return fmt.Errorf("failed to count messages: %w", err)
+	}
+	u.MessagesByChannel = map[string]int{}
+	for rows.Next() {
+		var channel string
+		var count int
+		if err := rows.Scan(&channel, &count); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan message count: %w", err)
+		}
+		u.MessagesByChannel[channel] = count
+		u.MessagesThisMonth += count
+	}
+	rows.Close()
+
+	var clients, patients, therapists, sessions, worksheets, budgets, projects, payments, expenses, executions int
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM clients WHERE organization_id = $1 AND deleted_at IS NULL),
+			(SELECT COUNT(*) FROM patients WHERE organization_id = $1 AND deleted_at IS NULL),
+			(SELECT COUNT(*) FROM therapists WHERE organization_id = $1 AND deleted_at IS NULL),
+			(SELECT COUNT(*) FROM sessions WHERE organization_id = $1 AND deleted_at IS NULL),
+			(SELECT COUNT(*) FROM worksheets WHERE organization_id = $1 AND deleted_at IS NULL),
+			(SELECT COUNT(*) FROM budgets WHERE organization_id = $1 AND deleted_at IS NULL),
+			(SELECT COUNT(*) FROM projects WHERE organization_id = $1 AND deleted_at IS NULL),
+			(SELECT COUNT(*) FROM payments WHERE organization_id = $1 AND deleted_at IS NULL),
+			(SELECT COUNT(*) FROM expenses WHERE organization_id = $1 AND deleted_at IS NULL),
+			(SELECT COUNT(*) FROM workflow_execution_log WHERE organization_id = $1)
+	`, orgID).Scan(&clients, &patients, &therapists, &sessions, &worksheets, &budgets, &projects, &payments, &expenses, &executions)
+	if err != nil {
+		return fmt.Errorf("failed to count rows: %w", err)
+	}
+	u.RowCounts = map[string]int{
+		"clients":             clients,
+		"patients":            patients,
+		"therapists":          therapists,
+		"sessions":            sessions,
+		"worksheets":          worksheets,
+		"budgets":             budgets,
+		"projects":            projects,
+		"payments":            payments,
+		"expenses":            expenses,
+		"workflow_executions": executions,
+	}
+
+	_, err = s.db.Pool.Exec(ctx, `
+		INSERT INTO organization_usage (
+			organization_id, photo_count, photo_bytes, document_count, receipt_count, export_bytes,
+			storage_bytes, messages_this_month, messages_by_channel, row_counts, computed_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
+		ON CONFLICT (organization_id) DO UPDATE SET
+			photo_count = EXCLUDED.photo_count, photo_bytes = EXCLUDED.photo_bytes,
+			document_count = EXCLUDED.document_count, receipt_count = EXCLUDED.receipt_count,
+			export_bytes = EXCLUDED.export_bytes, storage_bytes = EXCLUDED.storage_bytes,
+			messages_this_month = EXCLUDED.messages_this_month, messages_by_channel = EXCLUDED.messages_by_channel,
+			row_counts = EXCLUDED.row_counts, computed_at = EXCLUDED.computed_at
+	`, orgID, u.PhotoCount, u.PhotoBytes, u.DocumentCount, u.ReceiptCount, u.ExportBytes,
+		u.StorageBytes, u.MessagesThisMonth, u.MessagesByChannel, u.RowCounts)
+	if err != nil {
+		return fmt.Errorf("failed to save usage: %w", err)
+	}
+
+	return nil
+}
+
+// RefreshAll recomputes the usage of every organization and returns how many were refreshed.
+// An organization that fails is logged and skipped.
+func (s *UsageService) RefreshAll(ctx context.Context) (int, error) {
+	rows, err := s.db.Pool.Query(ctx, `SELECT id FROM organizations WHERE deleted_at IS NULL`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	var orgIDs []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan organization: %w", err)
+		}
+		orgIDs = append(orgIDs, id)
+	}
+	rows.Close()
+
+	refreshed := 0
+	for _, orgID := range orgIDs {
+		if err := s.Refresh(ctx, orgID); err != nil {
+			log.Printf("[Usage] Failed to refresh usage of organization %s: %v", orgID, err)
+			continue
+		}
+		refreshed++
+	}
+	return refreshed, nil
+}
+
+// SetQuotas sets an organization's quotas; nil removes the limit
+func (s *UsageService) SetQuotas(ctx context.Context, orgID uuid.UUID, storageBytes *int64, monthlyMessages *int) error {
+	if storageBytes != nil && *storageBytes <= 0 {
+		return errors.New("storage quota must be positive")
+	}
+	if monthlyMessages != nil && *monthlyMessages <= 0 {
+		return errors.New("message quota must be positive")
+	}
+
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE organizations SET storage_quota_bytes = $1, monthly_message_quota = $2
+		WHERE id = $3 AND deleted_at IS NULL
+	`, storageBytes, monthlyMessages, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to update quotas: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("organization not found")
+	}
+	return nil
+}
@@ -0,0 +1,349 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Avatars are scaled down to fit these dimensions and stored as PNG
+const (
+	AvatarMaxSize = 256
+	// MaxAvatarUploadSize is the maximum size of an uploaded avatar before processing (5MB)
+	MaxAvatarUploadSize int64 = 5 << 20
+	// minPasswordLength is the minimum length of a user password
+	minPasswordLength = 8
+)
+
+// UserService handles user operations within an organization.
+// Users join through invitations (see InvitationService).
+type UserService struct {
+	db      *database.DB
+	storage *StorageService
+}
+
+func NewUserService(db *database.DB, storage *StorageService) *UserService {
+	return &UserService{
+		db:      db,
+		storage: storage,
+	}
+}
+
+// UserFilters contains filters for listing organization users
+type UserFilters struct {
+	Search   string
+	Role     string
+	IsActive *bool
+}
+
+// UpdateUserRequest contains the fields an admin can change on a user
+type UpdateUserRequest struct {
+	FirstName string      `json:"first_name"`
+	LastName  string      `json:"last_name"`
+	Phone     *string     `json:"phone"`
+	Role      models.Role `json:"role"`
+}
+
+// UpdateProfileRequest contains the fields users can change on their own profile
+type UpdateProfileRequest struct {
+	FirstName string  `json:"first_name"`
+	LastName  string  `json:"last_name"`
+	Phone     *string `json:"phone"`
+}
+
+// ChangePasswordRequest contains the current and new password for a self-service change
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+const userColumns = `id, organization_id, email, first_name, last_name, phone, avatar, role, is_active,
+	last_login_at, created_at, updated_at`
+
+func scanUser(row pgx.Row) (*models.User, error) {
+	var u models.User
+	err := row.Scan(&u.ID, &u.OrganizationID, &u.Email, &u.FirstName, &u.LastName, &u.Phone, &u.Avatar,
+		&u.Role, &u.IsActive, &u.LastLoginAt, &u.CreatedAt, &u.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// List returns the organization's users
+func (s *UserService) List(ctx context.Context, orgID uuid.UUID, filters UserFilters) ([]*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE organization_id = $1 AND deleted_at IS NULL`
+	args := []interface{}{orgID}
+
+	if filters.Search != "" {
+		args = append(args, "%"+filters.Search+"%")
+		query += fmt.Sprintf(" AND (email ILIKE $%d OR first_name ILIKE $%d OR last_name ILIKE $%d)", len(args), len(args), len(args))
+	}
+	if filters.Role != "" {
+		args = append(args, filters.Role)
+		query += fmt.Sprintf(" AND role = $%d", len(args))
+	}
+	if filters.IsActive != nil {
+		args = append(args, *filters.IsActive)
+		query += fmt.Sprintf(" AND is_active = $%d", len(args))
+	}
+	query += " ORDER BY first_name, last_name"
+
+	rows, err := s.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []*models.User{}
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, u)
+	}
+
+	return users, nil
+}
+
+// GetByID returns a user of the organization
+func (s *UserService) GetByID(ctx context.Context, id, orgID uuid.UUID) (*models.User, error) {
+	u, err := scanUser(s.db.Pool.QueryRow(ctx, `
+		SELECT `+userColumns+` FROM users
+		WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`, id, orgID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("user not found")
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return u, nil
+}
+
+// Update changes a user's details and role. The organization always keeps an active admin.
+func (s *UserService) Update(ctx context.Context, id, orgID uuid.UUID, req UpdateUserRequest) (*models.User, error) {
+	if strings.TrimSpace(req.FirstName) == "" || strings.TrimSpace(req.LastName) == "" {
+		return nil, errors.New("first_name and last_name are required")
+	}
+	if !req.Role.IsStaff() {
+		return nil, errors.New("role must be one of admin, manager, employee, accountant")
+	}
+
+	current, err := s.GetByID(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if current.Role == models.RoleAdmin && req.Role != models.RoleAdmin && current.IsActive {
+		if err := s.ensureAnotherAdmin(ctx, id, orgID); err != nil {
+			return nil, err
+		}
+	}
+
+	u, err := scanUser(s.db.Pool.QueryRow(ctx, `
+		UPDATE users SET first_name = $1, last_name = $2, phone = $3, role = $4, updated_at = NOW()
+		WHERE id = $5 AND organization_id = $6 AND deleted_at IS NULL
+		RETURNING `+userColumns,
+		strings.TrimSpace(req.FirstName), strings.TrimSpace(req.LastName), req.Phone, req.Role, id, orgID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	return u, nil
+}
+
+// SetActive deactivates or reactivates a user. Deactivated users cannot log in.
+func (s *UserService) SetActive(ctx context.Context, id, orgID, actorID uuid.UUID, active bool) (*models.User, error) {
+	current, err := s.GetByID(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		if id == actorID {
+			return nil, errors.New("you cannot deactivate your own account")
+		}
+		if current.Role == models.RoleAdmin && current.IsActive {
+			if err := s.ensureAnotherAdmin(ctx, id, orgID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	u, err := scanUser(s.db.Pool.QueryRow(ctx, `
+		UPDATE users SET is_active = $1, updated_at = NOW()
+		WHERE id = $2 AND organization_id = $3 AND deleted_at IS NULL
+		RETURNING `+userColumns,
+		active, id, orgID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update user status: %w", err)
+	}
+
+	return u, nil
+}
+
+// Delete soft-deletes a user
+func (s *UserService) Delete(ctx context.Context, id, orgID, actorID uuid.UUID) error {
+	if id == actorID {
+		return errors.New("you cannot delete your own account")
+	}
+
+	current, err := s.GetByID(ctx, id, orgID)
+	if err != nil {
+		return err
+	}
+	if current.Role == models.RoleAdmin && current.IsActive {
+		if err := s.ensureAnotherAdmin(ctx, id, orgID); err != nil {
+			return err
+		}
+	}
+
+	_, err = s.db.Pool.Exec(ctx, `
+		UPDATE users SET deleted_at = NOW(), is_active = false, updated_at = NOW()
+		WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	return nil
+}
+
+// ensureAnotherAdmin fails when the user is the organization's last active admin
+func (s *UserService) ensureAnotherAdmin(ctx context.Context, id, orgID uuid.UUID) error {
+	var exists bool
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM users
+			WHERE organization_id = $1 AND id <> $2 AND role = $3 AND is_active = true AND deleted_at IS NULL
+		)
+	`, orgID, id, models.RoleAdmin).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check admins: %w", err)
+	}
+	if !exists {
+		return errors.New("the organization must keep at least one active admin")
+	}
+	return nil
+}
+
+// ============ Self-service Profile ============
+
+// UpdateProfile changes the current user's own name and phone
+func (s *UserService) UpdateProfile(ctx context.Context, id, orgID uuid.UUID, req UpdateProfileRequest) (*models.User, error) {
+	if strings.TrimSpace(req.FirstName) == "" || strings.TrimSpace(req.LastName) == "" {
+		return nil, errors.New("first_name and last_name are required")
+	}
+
+	u, err := scanUser(s.db.Pool.QueryRow(ctx, `
+		UPDATE users SET first_name = $1, last_name = $2, phone = $3, updated_at = NOW()
+		WHERE id = $4 AND organization_id = $5 AND deleted_at IS NULL
+		RETURNING `+userColumns,
+		strings.TrimSpace(req.FirstName), strings.TrimSpace(req.LastName), req.Phone, id, orgID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("user not found")
+		}
+		return nil, fmt.Errorf("failed to update profile: %w", err)
+	}
+
+	return u, nil
+}
+
+// ChangePassword sets a new password for the current user after verifying the current one
+func (s *UserService) ChangePassword(ctx context.Context, id, orgID uuid.UUID, req ChangePasswordRequest) error {
+	if len(req.NewPassword) < minPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+
+	var hash string
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT password_hash FROM users WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`, id, orgID).Scan(&hash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("user not found")
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(req.CurrentPassword)); err != nil {
+		return errors.New("current password is incorrect")
+	}
+
+	newHash, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return errors.New("failed to hash password")
+	}
+
+	_, err = s.db.Pool.Exec(ctx, `
+		UPDATE users SET password_hash = $1, updated_at = NOW() WHERE id = $2
+	`, string(newHash), id)
+	if err != nil {
+		return fmt.Errorf("failed to change password: %w", err)
+	}
+
+	return nil
+}
+
+// UploadAvatar resizes an uploaded image and stores it as the user's avatar,
+// removing the previous one from storage
+func (s *UserService) UploadAvatar(ctx context.Context, id, orgID uuid.UUID, data []byte) (*models.User, error) {
+	if s.storage == nil {
+		return nil, errors.New("storage is not configured")
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("avatar must be a PNG, JPEG or GIF image")
+	}
+	if cfg.Width == 0 || cfg.Height == 0 || cfg.Width*cfg.Height > maxLogoPixels {
+		return nil, errors.New("avatar dimensions are too large")
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode avatar: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, resizeToFit(img, AvatarMaxSize, AvatarMaxSize)); err != nil {
+		return nil, fmt.Errorf("failed to encode avatar: %w", err)
+	}
+
+	current, err := s.GetByID(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.storage.UploadData(ctx, buf.Bytes(), "avatar.png", "image/png", orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store avatar: %w", err)
+	}
+
+	u, err := scanUser(s.db.Pool.QueryRow(ctx, `
+		UPDATE users SET avatar = $1, updated_at = NOW()
+		WHERE id = $2 AND organization_id = $3 AND deleted_at IS NULL
+		RETURNING `+userColumns,
+		result.URL, id, orgID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to save avatar: %w", err)
+	}
+
+	if current.Avatar != nil && *current.Avatar != "" {
+		if err := s.storage.DeleteFile(ctx, *current.Avatar); err != nil {
+			fmt.Printf("Warning: failed to delete previous avatar: %v\n", err)
+		}
+	}
+
+	return u, nil
+}
@@ -0,0 +1,684 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// WaitingListService keeps the patients waiting for a session slot and offers them the slots
+// freed by cancellations
+type WaitingListService struct {
+	db          *database.DB
+	sessions    *SessionService
+	frontendURL string
+}
+
+func NewWaitingListService(db *database.DB, sessions *SessionService, frontendURL string) *WaitingListService {
+	return &WaitingListService{
+		db:          db,
+		sessions:    sessions,
+		frontendURL: strings.TrimRight(frontendURL, "/"),
+	}
+}
+
+// WaitingListInput is the request body for adding a patient to the waiting list or changing their preferences
+type WaitingListInput struct {
+	PatientID         uuid.UUID  `json:"patient_id"`
+	TherapistID       *uuid.UUID `json:"therapist_id"`
+	ServiceID         *uuid.UUID `json:"service_id"`
+	PreferredWeekdays []int      `json:"preferred_weekdays"`
+	EarliestTime      *string    `json:"earliest_time"`
+	LatestTime        *string    `json:"latest_time"`
+	Notes             *string    `json:"notes"`
+}
+
+// WaitingListFilters narrows the waiting list
+type WaitingListFilters struct {
+	Status      *models.WaitingListStatus
+	TherapistID *uuid.UUID
+	Limit       int
+	Offset      int
+}
+
+// validateWaitingListInput checks the preferences, dropping empty times and repeated weekdays
+func validateWaitingListInput(input *WaitingListInput) error {
+	seen := make(map[int]bool)
+	weekdays := []int{}
+	for _, d := range input.PreferredWeekdays {
+		if d < 0 || d > 6 {
+			return errors.New("preferred weekdays must be between 0 (Sunday) and 6 (Saturday)")
+		}
+		if !seen[d] {
+			seen[d] = true
+			weekdays = append(weekdays, d)
+		}
+	}
+	input.PreferredWeekdays = weekdays
+
+	for _, t := range []**string{&input.EarliestTime, &input.LatestTime} {
+		if *t == nil || strings.TrimSpace(**t) == "" {
+			*t = nil
+			continue
+		}
+		if _, err := time.Parse("15:04", **t); err != nil {
+			return errors.New("preferred times must be in HH:MM format")
+		}
+	}
+	if input.EarliestTime != nil && input.LatestTime != nil && *input.EarliestTime > *input.LatestTime {
+		return errors.New("earliest time must be before latest time")
+	}
+	return nil
+}
+
+// matchesWaitingPreference reports whether a slot of the therapist, for the service (if any),
+// starting at start in the therapist's timezone suits a waiting list entry
+func matchesWaitingPreference(entry *models.WaitingListEntry, therapistID uuid.UUID, serviceID *uuid.UUID, start time.Time) bool {
+	if entry.TherapistID != nil && *entry.TherapistID != therapistID {
+		return false
+	}
+	if entry.ServiceID != nil && (serviceID == nil || *entry.ServiceID != *serviceID) {
+		return false
+	}
+	if len(entry.PreferredWeekdays) > 0 {
+		found := false
+		for _, d := range entry.PreferredWeekdays {
+			if d == int(start.Weekday()) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	clock := start.Format("15:04")
+	if entry.EarliestTime != nil && clock < *entry.EarliestTime {
+		return false
+	}
+	if entry.LatestTime != nil && clock > *entry.LatestTime {
+		return false
+	}
+	return true
+}
+
+const waitingListEntryColumns = `e.id, e.organization_id, e.patient_id, e.therapist_id, e.service_id, e.preferred_weekdays,
+	to_char(e.earliest_time, 'HH24:MI'), to_char(e.latest_time, 'HH24:MI'), e.status, e.notes, e.booked_session_id,
+	e.created_by, e.created_at, e.updated_at, COALESCE(c.name, ''), t.name, bs.name`
+
+const waitingListEntryJoins = `FROM waiting_list_entries e
+	JOIN patients p ON p.id = e.patient_id
+	LEFT JOIN clients c ON c.id = p.client_id
+	LEFT JOIN therapists t ON t.id = e.therapist_id
+	LEFT JOIN bookable_services bs ON bs.id = e.service_id`
+
+func scanWaitingListEntry(row pgx.Row) (*models.WaitingListEntry, error) {
+	var e models.WaitingListEntry
+	err := row.Scan(&e.ID, &e.OrganizationID, &e.PatientID, &e.TherapistID, &e.ServiceID, &e.PreferredWeekdays,
+		&e.EarliestTime, &e.LatestTime, &e.Status, &e.Notes, &e.BookedSessionID,
+		&e.CreatedBy, &e.CreatedAt, &e.UpdatedAt, &e.PatientName, &e.TherapistName, &e.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// List returns the organization's waiting list, longest waiting first
+func (s *WaitingListService) List(ctx context.Context, orgID uuid.UUID, filters WaitingListFilters) ([]*models.WaitingListEntry, int, error) {
+	where := `WHERE e.organization_id = $1
+		AND ($2::varchar IS NULL OR e.status = $2)
+		AND ($3::uuid IS NULL OR e.therapist_id = $3)`
+	args := []interface{}{orgID, filters.Status, filters.TherapistID}
+
+	var total int
+	if err := s.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM waiting_list_entries e `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count waiting list: %w", err)
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT `+waitingListEntryColumns+` `+waitingListEntryJoins+` `+where+`
+		ORDER BY e.created_at
+		LIMIT $4 OFFSET $5
+	`, append(args, filters.Limit, filters.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list waiting list: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*models.WaitingListEntry{}
+	for rows.Next() {
+		e, err := scanWaitingListEntry(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan waiting list entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, total, rows.Err()
+}
+
+// GetByID returns a waiting list entry
+func (s *WaitingListService) GetByID(ctx context.Context, id, orgID uuid.UUID) (*models.WaitingListEntry, error) {
+	e, err := scanWaitingListEntry(s.db.Pool.QueryRow(ctx, `
+		SELECT `+waitingListEntryColumns+` `+waitingListEntryJoins+`
+		WHERE e.id = $1 AND e.organization_id = $2
+	`, id, orgID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("waiting list entry not found")
+		}
+		return nil, fmt.Errorf("failed to get waiting list entry: %w", err)
+	}
+	return e, nil
+}
+
+// checkWaitingListReferences verifies the therapist and service belong to the organization
+func (s *WaitingListService) checkWaitingListReferences(ctx context.Context, orgID uuid.UUID, input WaitingListInput) error {
+	if input.TherapistID != nil {
+		var exists bool
+		err := s.db.Pool.QueryRow(ctx, `
+			SELECT EXISTS(SELECT 1 FROM therapists WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL)
+		`, *input.TherapistID, orgID).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to check therapist: %w", err)
+		}
+		if !exists {
+			return errors.New("therapist not found")
+		}
+	}
+	if input.ServiceID != nil {
+		if _, err := getBookableService(ctx, s.db, *input.ServiceID, orgID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Create puts a patient on the waiting list
+func (s *WaitingListService) Create(ctx context.Context, orgID, userID uuid.UUID, input WaitingListInput) (*models.WaitingListEntry, error) {
+	if input.PatientID == uuid.Nil {
+		return nil, errors.New("patient is required")
+	}
+	if err := validateWaitingListInput(&input); err != nil {
+		return nil, err
+	}
+
+	var patientExists, alreadyWaiting bool
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT
+			EXISTS(SELECT 1 FROM patients WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL),
+			EXISTS(SELECT 1 FROM waiting_list_entries WHERE patient_id = $1 AND organization_id = $2
+				AND status IN ('waiting', 'offered')
+				AND therapist_id IS NOT DISTINCT FROM $3 AND service_id IS NOT DISTINCT FROM $4)
+	`, input.PatientID, orgID, input.TherapistID, input.ServiceID).Scan(&patientExists, &alreadyWaiting)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check patient: %w", err)
+	}
+	if !patientExists {
+		return nil, errors.New("patient not found")
+	}
+	if alreadyWaiting {
+		return nil, errors.New("patient is already on the waiting list for this therapist and service")
+	}
+	if err := s.checkWaitingListReferences(ctx, orgID, input); err != nil {
+		return nil, err
+	}
+
+	var id uuid.UUID
+	err = s.db.Pool.QueryRow(ctx, `
+		INSERT INTO waiting_list_entries (organization_id, patient_id, therapist_id, service_id, preferred_weekdays,
+			earliest_time, latest_time, notes, created_by)
+		VALUES ($1, $2, $3, $4, $5::smallint[], $6::time, $7::time, $8, $9)
+		RETURNING id
+	`, orgID, input.PatientID, input.TherapistID, input.ServiceID, input.PreferredWeekdays,
+		input.EarliestTime, input.LatestTime, input.Notes, userID).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create waiting list entry: %w", err)
+	}
+
+	return s.GetByID(ctx, id, orgID)
+}
+
+// Update changes the preferences of a patient still waiting
+func (s *WaitingListService) Update(ctx context.Context, id, orgID uuid.UUID, input WaitingListInput) (*models.WaitingListEntry, error) {
+	if err := validateWaitingListInput(&input); err != nil {
+		return nil, err
+	}
+	if err := s.checkWaitingListReferences(ctx, orgID, input); err != nil {
+		return nil, err
+	}
+
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE waiting_list_entries
+		SET therapist_id = $1, service_id = $2, preferred_weekdays = $3::smallint[],
+			earliest_time = $4::time, latest_time = $5::time, notes = $6
+		WHERE id = $7 AND organization_id = $8 AND status IN ('waiting', 'offered')
+	`, input.TherapistID, input.ServiceID, input.PreferredWeekdays, input.EarliestTime, input.LatestTime,
+		input.Notes, id, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update waiting list entry: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		if _, err := s.GetByID(ctx, id, orgID); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("only entries still waiting can be changed")
+	}
+
+	return s.GetByID(ctx, id, orgID)
+}
+
+// Remove takes a patient off the waiting list. A slot offered to them and not yet answered is
+// treated as declined, so it passes to the next patient.
+func (s *WaitingListService) Remove(ctx context.Context, id, orgID uuid.UUID) error {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE waiting_list_entries SET status = 'removed'
+		WHERE id = $1 AND organization_id = $2 AND status IN ('waiting', 'offered')
+	`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to remove waiting list entry: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("waiting list entry not found")
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE waiting_list_offers SET status = 'declined', responded_at = NOW()
+		WHERE entry_id = $1 AND status = 'pending'
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to decline pending offers: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+const waitingListOfferColumns = `o.id, o.organization_id, o.entry_id, o.source_session_id, o.therapist_id, o.service_id,
+	o.scheduled_at, o.duration_minutes, o.expires_at, o.status, o.booked_session_id, o.responded_at, o.created_at,
+	org.name, COALESCE(c.name, ''), COALESCE(c.phone, ''), COALESCE(t.name, ''), bs.name`
+
+const waitingListOfferJoins = `FROM waiting_list_offers o
+	JOIN organizations org ON org.id = o.organization_id
+	JOIN waiting_list_entries e ON e.id = o.entry_id
+	JOIN patients p ON p.id = e.patient_id
+	LEFT JOIN clients c ON c.id = p.client_id
+	LEFT JOIN therapists t ON t.id = o.therapist_id
+	LEFT JOIN bookable_services bs ON bs.id = o.service_id`
+
+func scanWaitingListOffer(row pgx.Row) (*models.WaitingListOffer, error) {
+	var o models.WaitingListOffer
+	err := row.Scan(&o.ID, &o.OrganizationID, &o.EntryID, &o.SourceSessionID, &o.TherapistID, &o.ServiceID,
+		&o.ScheduledAt, &o.DurationMinutes, &o.ExpiresAt, &o.Status, &o.BookedSessionID, &o.RespondedAt, &o.CreatedAt,
+		&o.OrganizationName, &o.PatientName, &o.PatientPhone, &o.TherapistName, &o.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// ListOffers returns the slots offered to a waiting list entry, latest first
+func (s *WaitingListService) ListOffers(ctx context.Context, entryID, orgID uuid.UUID) ([]*models.WaitingListOffer, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT `+waitingListOfferColumns+` `+waitingListOfferJoins+`
+		WHERE o.entry_id = $1 AND o.organization_id = $2
+		ORDER BY o.created_at DESC
+	`, entryID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list offers: %w", err)
+	}
+	defer rows.Close()
+
+	offers := []*models.WaitingListOffer{}
+	for rows.Next() {
+		o, err := scanWaitingListOffer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan offer: %w", err)
+		}
+		offers = append(offers, o)
+	}
+	return offers, rows.Err()
+}
+
+// ============ Slot offers ============
+
+// OfferSlot offers the slot of a cancelled session to the first waiting patient it suits, valid
+// until expiresAt. It returns nil when the slot is gone, already on offer, or suits nobody.
+// It satisfies the workflow executor's SlotOfferer.
+func (s *WaitingListService) OfferSlot(ctx context.Context, orgID, actionID, sessionID uuid.UUID, expiresAt time.Time) (*models.WaitingListOffer, error) {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Locking the cancelled session keeps two offers of its slot from being made at once
+	var therapistID, patientID uuid.UUID
+	var serviceID *uuid.UUID
+	var status models.SessionStatus
+	var scheduledAt time.Time
+	var duration int
+	var timezone string
+	err = tx.QueryRow(ctx, `
+		SELECT s.therapist_id, s.patient_id, s.service_id, s.status, s.scheduled_at, s.duration_minutes, t.timezone
+		FROM sessions s
+		JOIN therapists t ON t.id = s.therapist_id
+		WHERE s.id = $1 AND s.organization_id = $2 AND s.deleted_at IS NULL
+		FOR UPDATE OF s
+	`, sessionID, orgID).Scan(&therapistID, &patientID, &serviceID, &status, &scheduledAt, &duration, &timezone)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("session not found")
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if status != models.SessionStatusCancelled || !scheduledAt.After(time.Now()) {
+		return nil, nil
+	}
+
+	var onOffer bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM waiting_list_offers
+			WHERE source_session_id = $1 AND (status = 'accepted' OR (status = 'pending' AND expires_at > NOW())))
+	`, sessionID).Scan(&onOffer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check offers: %w", err)
+	}
+	if onOffer {
+		return nil, nil
+	}
+
+	conflict, err := sessionConflict(ctx, tx, orgID, therapistID, scheduledAt, scheduledAt.Add(time.Duration(duration)*time.Minute), &sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check conflicts: %w", err)
+	}
+	if conflict {
+		return nil, nil
+	}
+
+	// The first patient waiting whose preferences the slot suits, who was not offered it before
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	rows, err := tx.Query(ctx, `
+		SELECT `+waitingListEntryColumns+`, COALESCE(c.phone, '') `+waitingListEntryJoins+`
+		WHERE e.organization_id = $1 AND e.status = 'waiting' AND e.patient_id <> $2 AND p.deleted_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM waiting_list_offers o
+			WHERE o.entry_id = e.id AND o.source_session_id = $3 AND o.status <> 'withdrawn')
+		ORDER BY e.created_at
+		FOR UPDATE OF e SKIP LOCKED
+	`, orgID, patientID, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get waiting list: %w", err)
+	}
+	var entry *models.WaitingListEntry
+	var phone string
+	for rows.Next() {
+		var e models.WaitingListEntry
+		var p string
+		if err := rows.Scan(&e.ID, &e.OrganizationID, &e.PatientID, &e.TherapistID, &e.ServiceID, &e.PreferredWeekdays,
+			&e.EarliestTime, &e.LatestTime, &e.Status, &e.Notes, &e.BookedSessionID,
+			&e.CreatedBy, &e.CreatedAt, &e.UpdatedAt, &e.PatientName, &e.TherapistName, &e.ServiceName, &p); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan waiting list entry: %w", err)
+		}
+		if p != "" && matchesWaitingPreference(&e, therapistID, serviceID, scheduledAt.In(loc)) {
+			entry, phone = &e, p
+			break
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get waiting list: %w", err)
+	}
+	if entry == nil {
+		log.Printf("[WaitingList] No waiting patient suits the slot of session %s", sessionID)
+		return nil, nil
+	}
+
+	token, tokenHash, err := newInvitationToken()
+	if err != nil {
+		return nil, err
+	}
+
+	var offerID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		INSERT INTO waiting_list_offers (organization_id, entry_id, source_session_id, action_id, therapist_id,
+			service_id, scheduled_at, duration_minutes, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`, orgID, entry.ID, sessionID, actionID, therapistID, serviceID, scheduledAt, duration, tokenHash, expiresAt).Scan(&offerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create offer: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE waiting_list_entries SET status = 'offered' WHERE id = $1`, entry.ID); err != nil {
+		return nil, fmt.Errorf("failed to update waiting list entry: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit offer: %w", err)
+	}
+
+	offer, err := s.getOffer(ctx, offerID)
+	if err != nil {
+		return nil, err
+	}
+	offer.PatientPhone = phone
+	offer.AcceptLink = fmt.Sprintf("%s/waiting-list/offers/%s", s.frontendURL, token)
+
+	log.Printf("[WaitingList] Offered the slot of session %s to waiting list entry %s", sessionID, entry.ID)
+	return offer, nil
+}
+
+// WithdrawOffer takes back an offer whose message could not be sent; the patient keeps their
+// place and can be offered the slot again
+func (s *WaitingListService) WithdrawOffer(ctx context.Context, offerID uuid.UUID) error {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var entryID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		UPDATE waiting_list_offers SET status = 'withdrawn', followed_up = true
+		WHERE id = $1 AND status = 'pending'
+		RETURNING entry_id
+	`, offerID).Scan(&entryID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to withdraw offer: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE waiting_list_entries SET status = 'waiting' WHERE id = $1 AND status = 'offered'`, entryID); err != nil {
+		return fmt.Errorf("failed to update waiting list entry: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+func (s *WaitingListService) getOffer(ctx context.Context, offerID uuid.UUID) (*models.WaitingListOffer, error) {
+	o, err := scanWaitingListOffer(s.db.Pool.QueryRow(ctx, `
+		SELECT `+waitingListOfferColumns+` `+waitingListOfferJoins+` WHERE o.id = $1
+	`, offerID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get offer: %w", err)
+	}
+	return o, nil
+}
+
+// ViewOffer returns the slot offered with an accept link
+func (s *WaitingListService) ViewOffer(ctx context.Context, token string) (*models.WaitingListOffer, error) {
+	o, err := scanWaitingListOffer(s.db.Pool.QueryRow(ctx, `
+		SELECT `+waitingListOfferColumns+` `+waitingListOfferJoins+`
+		WHERE o.token_hash = $1 AND o.status = 'pending' AND o.expires_at > NOW()
+	`, hashInvitationToken(token)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("offer is invalid or has expired")
+		}
+		return nil, fmt.Errorf("failed to get offer: %w", err)
+	}
+	return o, nil
+}
+
+// AcceptOffer books the offered slot for the patient. The slot is checked and the session created
+// in one transaction, so it is never booked twice.
+func (s *WaitingListService) AcceptOffer(ctx context.Context, token string) (*models.WaitingListOffer, error) {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var offerID, orgID, entryID, patientID, sourceID, therapistID uuid.UUID
+	var serviceID *uuid.UUID
+	var scheduledAt time.Time
+	var duration int
+	err = tx.QueryRow(ctx, `
+		SELECT o.id, o.organization_id, o.entry_id, e.patient_id, o.source_session_id, o.therapist_id,
+			o.service_id, o.scheduled_at, o.duration_minutes
+		FROM waiting_list_offers o
+		JOIN waiting_list_entries e ON e.id = o.entry_id
+		WHERE o.token_hash = $1 AND o.status = 'pending' AND o.expires_at > NOW()
+		FOR UPDATE OF o, e
+	`, hashInvitationToken(token)).Scan(&offerID, &orgID, &entryID, &patientID, &sourceID, &therapistID,
+		&serviceID, &scheduledAt, &duration)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("offer is invalid or has expired")
+		}
+		return nil, fmt.Errorf("failed to get offer: %w", err)
+	}
+
+	// Lock the therapist so the slot cannot be booked by someone else meanwhile
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM therapists WHERE id = $1 FOR UPDATE`, therapistID); err != nil {
+		return nil, fmt.Errorf("failed to lock therapist: %w", err)
+	}
+	end := scheduledAt.Add(time.Duration(duration) * time.Minute)
+	conflict, err := sessionConflict(ctx, tx, orgID, therapistID, scheduledAt, end, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check conflicts: %w", err)
+	}
+	if conflict || !scheduledAt.After(time.Now()) {
+		if _, err := tx.Exec(ctx, `
+			UPDATE waiting_list_offers SET status = 'taken', responded_at = NOW(), followed_up = true WHERE id = $1
+		`, offerID); err != nil {
+			return nil, fmt.Errorf("failed to update offer: %w", err)
+		}
+		if _, err := tx.Exec(ctx, `UPDATE waiting_list_entries SET status = 'waiting' WHERE id = $1`, entryID); err != nil {
+			return nil, fmt.Errorf("failed to update waiting list entry: %w", err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return nil, fmt.Errorf("failed to commit offer: %w", err)
+		}
+		return nil, errors.New("the slot is no longer available")
+	}
+
+	// The new session takes the service's price, or the cancelled session's
+	session := &models.Session{
+		ID:              uuid.New(),
+		OrganizationID:  orgID,
+		TherapistID:     therapistID,
+		PatientID:       patientID,
+		ScheduledAt:     scheduledAt,
+		DurationMinutes: duration,
+		Status:          models.SessionStatusPending,
+		ServiceID:       serviceID,
+	}
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(bs.price_cents, s.price_cents), s.session_type
+		FROM sessions s
+		LEFT JOIN bookable_services bs ON bs.id = $2 AND bs.deleted_at IS NULL
+		WHERE s.id = $1
+	`, sourceID, serviceID).Scan(&session.PriceCents, &session.SessionType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session price: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO sessions (id, organization_id, therapist_id, patient_id, scheduled_at,
+			duration_minutes, price_cents, status, session_type, service_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, session.ID, orgID, therapistID, patientID, scheduledAt, duration, session.PriceCents,
+		session.Status, session.SessionType, serviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE waiting_list_offers SET status = 'accepted', responded_at = NOW(), followed_up = true, booked_session_id = $1
+		WHERE id = $2
+	`, session.ID, offerID); err != nil {
+		return nil, fmt.Errorf("failed to update offer: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE waiting_list_entries SET status = 'booked', booked_session_id = $1 WHERE id = $2
+	`, session.ID, entryID); err != nil {
+		return nil, fmt.Errorf("failed to update waiting list entry: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit booking: %w", err)
+	}
+
+	s.sessions.recordHistory(ctx, session.ID, "created_from_waiting_list", nil, session, nil)
+	if s.sessions.workflow != nil {
+		if err := s.sessions.workflow.OnSessionStateChange(ctx, orgID, session.ID, "", string(session.Status), scheduledAt); err != nil {
+			log.Printf("[WaitingList] Failed to trigger workflow for session %s: %v", session.ID, err)
+		}
+	}
+	s.sessions.events.Publish(ctx, orgID, models.DashboardEvent{
+		Type:       models.DashboardEventSessionCreated,
+		EntityType: "session",
+		EntityID:   session.ID,
+		Data: map[string]interface{}{
+			"therapist_id": therapistID,
+			"patient_id":   patientID,
+			"scheduled_at": scheduledAt,
+			"status":       session.Status,
+		},
+	})
+
+	log.Printf("[WaitingList] Offer %s accepted, booked session %s", offerID, session.ID)
+	return s.getOffer(ctx, offerID)
+}
+
+// DeclineOffer turns down an offered slot; it passes to the next waiting patient and the patient
+// stays on the waiting list
+func (s *WaitingListService) DeclineOffer(ctx context.Context, token string) error {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var entryID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		UPDATE waiting_list_offers SET status = 'declined', responded_at = NOW()
+		WHERE token_hash = $1 AND status = 'pending' AND expires_at > NOW()
+		RETURNING entry_id
+	`, hashInvitationToken(token)).Scan(&entryID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("offer is invalid or has expired")
+		}
+		return fmt.Errorf("failed to decline offer: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE waiting_list_entries SET status = 'waiting' WHERE id = $1 AND status = 'offered'`, entryID); err != nil {
+		return fmt.Errorf("failed to update waiting list entry: %w", err)
+	}
+	return tx.Commit(ctx)
+}
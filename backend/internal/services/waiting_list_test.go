@@ -0,0 +1,70 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+func TestMatchesWaitingPreference(t *testing.T) {
+	therapist, other := uuid.New(), uuid.New()
+	service := uuid.New()
+	morning, evening := "09:00", "12:00"
+	// Tuesday 10:30
+	start := time.Date(2026, 3, 10, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		entry     models.WaitingListEntry
+		serviceID *uuid.UUID
+		want      bool
+	}{
+		{"no preferences", models.WaitingListEntry{}, nil, true},
+		{"same therapist", models.WaitingListEntry{TherapistID: &therapist}, nil, true},
+		{"other therapist", models.WaitingListEntry{TherapistID: &other}, nil, false},
+		{"service wanted, slot without service", models.WaitingListEntry{ServiceID: &service}, nil, false},
+		{"same service", models.WaitingListEntry{ServiceID: &service}, &service, true},
+		{"on a preferred weekday", models.WaitingListEntry{PreferredWeekdays: []int{1, 2}}, nil, true},
+		{"not on a preferred weekday", models.WaitingListEntry{PreferredWeekdays: []int{4}}, nil, false},
+		{"within the time window", models.WaitingListEntry{EarliestTime: &morning, LatestTime: &evening}, nil, true},
+		{"before the time window", models.WaitingListEntry{EarliestTime: &evening}, nil, false},
+		{"after the time window", models.WaitingListEntry{LatestTime: &morning}, nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := matchesWaitingPreference(&tt.entry, therapist, tt.serviceID, start); got != tt.want {
+			t.Errorf("%s: matchesWaitingPreference() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidateWaitingListInput(t *testing.T) {
+	late, early, bad, empty := "18:00", "09:00", "9h", ""
+
+	tests := []struct {
+		name    string
+		input   WaitingListInput
+		wantErr bool
+	}{
+		{"no preferences", WaitingListInput{}, false},
+		{"valid window", WaitingListInput{EarliestTime: &early, LatestTime: &late}, false},
+		{"reversed window", WaitingListInput{EarliestTime: &late, LatestTime: &early}, true},
+		{"bad time", WaitingListInput{EarliestTime: &bad}, true},
+		{"empty time", WaitingListInput{LatestTime: &empty}, false},
+		{"bad weekday", WaitingListInput{PreferredWeekdays: []int{7}}, true},
+	}
+
+	for _, tt := range tests {
+		input := tt.input
+		if err := validateWaitingListInput(&input); (err != nil) != tt.wantErr {
+			t.Errorf("%s: validateWaitingListInput() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	input := WaitingListInput{PreferredWeekdays: []int{1, 3, 1}}
+	if err := validateWaitingListInput(&input); err != nil || len(input.PreferredWeekdays) != 2 {
+		t.Errorf("repeated weekdays not dropped: %v, %v", input.PreferredWeekdays, err)
+	}
+}
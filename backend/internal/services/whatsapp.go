@@ -2,15 +2,10 @@ package services
 
 import (
 	"context"
-	"crypto/aes"
-	"crypto/cipher"
-	"crypto/rand"
-	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
+	"log"
 	"net/url"
 	"strings"
 	"time"
@@ -19,29 +14,22 @@ import (
 	"github.com/controlwise/backend/internal/models"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
 )
 
 type WhatsAppService struct {
-	db            *database.DB
-	encryptionKey []byte
+	db             *database.DB
+	encryptionKey  []byte
+	intentHandlers map[models.InboundIntent]InboundIntentHandler
 }
 
 func NewWhatsAppService(db *database.DB, encryptionKey string) *WhatsAppService {
-	// Key should be 32 bytes for AES-256
-	key := []byte(encryptionKey)
-	if len(key) < 32 {
-		// Pad or truncate to 32 bytes
-		padded := make([]byte, 32)
-		copy(padded, key)
-		key = padded
-	} else if len(key) > 32 {
-		key = key[:32]
-	}
-
-	return &WhatsAppService{
+	s := &WhatsAppService{
 		db:            db,
-		encryptionKey: key,
+		encryptionKey: secretKey(encryptionKey),
 	}
+	s.registerBuiltinIntentHandlers()
+	return s
 }
 
 // GetConfig returns the notification config for an organization
@@ -53,7 +41,14 @@ func (s *WhatsAppService) GetConfig(ctx context.Context, orgID uuid.UUID) (*mode
 			twilio_auth_token_encrypted, twilio_whatsapp_number,
 			reminder_24h_enabled, reminder_2h_enabled,
 			reminder_24h_template, reminder_2h_template,
-			confirmation_response_template, created_at, updated_at
+			confirmation_response_template, inbound_intents,
+			whatsapp_messages_per_second::float8, email_messages_per_second::float8,
+			monthly_message_cap, webhook_token, do_not_disturb_until, send_window,
+			email_enabled, email_provider, email_from_address, email_from_name,
+			smtp_host, smtp_port, smtp_username, smtp_password_encrypted,
+			sendgrid_api_key_encrypted, sendgrid_webhook_verification_key, ses_topic_arn,
+			reminders_migrated_at, reminders_workflow_id, whatsapp_provider, meta_phone_number_id, meta_access_token_encrypted, meta_app_secret_encrypted,
+			created_at, updated_at
 		FROM notification_configs
 		WHERE organization_id = $1
 	`, orgID).Scan(
@@ -68,6 +63,30 @@ func (s *WhatsAppService) GetConfig(ctx context.Context, orgID uuid.UUID) (*mode
 		&config.Reminder24hTemplate,
 		&config.Reminder2hTemplate,
 		&config.ConfirmationResponseTmpl,
+		&config.InboundIntents,
+		&config.WhatsAppMessagesPerSecond,
+		&config.EmailMessagesPerSecond,
+		&config.MonthlyMessageCap,
+		&config.WebhookToken,
+		&config.DoNotDisturbUntil,
+		&config.SendWindow,
+		&config.EmailEnabled,
+		&config.EmailProvider,
+		&config.EmailFromAddress,
+		&config.EmailFromName,
+		&config.SMTPHost,
+		&config.SMTPPort,
+		&config.SMTPUsername,
+		&config.SMTPPasswordEncrypted,
+		&config.SendGridAPIKeyEncrypted,
+		&config.SendGridWebhookKey,
+		&config.SESTopicArn,
+		&config.RemindersMigratedAt,
+		&config.RemindersWorkflowID,
+		&config.WhatsAppProvider,
+		&config.MetaPhoneNumberID,
+		&config.MetaAccessTokenEncrypted,
+		&config.MetaAppSecretEncrypted,
 		&config.CreatedAt,
 		&config.UpdatedAt,
 	)
@@ -82,6 +101,15 @@ func (s *WhatsAppService) GetConfig(ctx context.Context, orgID uuid.UUID) (*mode
 
 // SaveConfig creates or updates notification config
 func (s *WhatsAppService) SaveConfig(ctx context.Context, orgID uuid.UUID, config *NotificationConfigInput) error {
+	for _, rate := range []*float64{config.WhatsAppMessagesPerSecond, config.EmailMessagesPerSecond} {
+		if rate != nil && *rate <= 0 {
+			return errors.New("messages per second must be greater than zero")
+		}
+	}
+	if config.MonthlyMessageCap != nil && config.MonthlyMessageCap.IsNegative() {
+		return errors.New("monthly message cap cannot be negative")
+	}
+
 	// Encrypt auth token if provided
 	var encryptedToken *string
 	if config.TwilioAuthToken != nil && *config.TwilioAuthToken != "" {
@@ -92,14 +120,96 @@ func (s *WhatsAppService) SaveConfig(ctx context.Context, orgID uuid.UUID, confi
 		encryptedToken = &encrypted
 	}
 
-	_, err := s.db.Pool.Exec(ctx, `
+	if config.WhatsAppProvider != nil && !config.WhatsAppProvider.IsValid() {
+		return errors.New("invalid WhatsApp provider")
+	}
+
+	// Encrypt WhatsApp Cloud API credentials if provided
+	var encryptedMetaToken, encryptedMetaSecret *string
+	if config.MetaAccessToken != nil && *config.MetaAccessToken != "" {
+		encrypted, err := s.encrypt(*config.MetaAccessToken)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt access token: %w", err)
+		}
+		encryptedMetaToken = &encrypted
+	}
+	if config.MetaAppSecret != nil && *config.MetaAppSecret != "" {
+		encrypted, err := s.encrypt(*config.MetaAppSecret)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt app secret: %w", err)
+		}
+		encryptedMetaSecret = &encrypted
+	}
+
+	if err := validateInboundIntents(config.InboundIntents); err != nil {
+		return err
+	}
+
+	if config.EmailProvider != nil && !config.EmailProvider.IsValid() {
+		return errors.New("invalid email provider")
+	}
+	if config.SMTPPort != nil && (*config.SMTPPort <= 0 || *config.SMTPPort > 65535) {
+		return errors.New("invalid SMTP port")
+	}
+
+	// Encrypt email provider credentials if provided
+	var encryptedSMTPPassword, encryptedSendGridKey *string
+	if config.SMTPPassword != nil && *config.SMTPPassword != "" {
+		encrypted, err := s.encrypt(*config.SMTPPassword)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt SMTP password: %w", err)
+		}
+		encryptedSMTPPassword = &encrypted
+	}
+	if config.SendGridAPIKey != nil && *config.SendGridAPIKey != "" {
+		encrypted, err := s.encrypt(*config.SendGridAPIKey)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt SendGrid API key: %w", err)
+		}
+		encryptedSendGridKey = &encrypted
+	}
+	if config.SendGridWebhookKey != nil && *config.SendGridWebhookKey != "" {
+		if _, err := parseSendGridVerificationKey(*config.SendGridWebhookKey); err != nil {
+			return errors.New("invalid SendGrid webhook verification key")
+		}
+	}
+
+	// JSONB parameters must be typed; nil keeps the organization's settings
+	var inboundIntents *string
+	if config.InboundIntents != nil {
+		encoded, err := json.Marshal(config.InboundIntents)
+		if err != nil {
+			return fmt.Errorf("failed to encode inbound intents: %w", err)
+		}
+		value := string(encoded)
+		inboundIntents = &value
+	}
+
+	var senderNumber string
+	if config.TwilioWhatsAppNumber != nil && *config.TwilioWhatsAppNumber != "" {
+		senderNumber = models.NormalizeWhatsAppPhone(*config.TwilioWhatsAppNumber)
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
 		INSERT INTO notification_configs (
 			organization_id, whatsapp_enabled, twilio_account_sid,
 			twilio_auth_token_encrypted, twilio_whatsapp_number,
 			reminder_24h_enabled, reminder_2h_enabled,
 			reminder_24h_template, reminder_2h_template,
-			confirmation_response_template
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			confirmation_response_template,
+			whatsapp_messages_per_second, email_messages_per_second, monthly_message_cap,
+			email_enabled, email_provider, email_from_address, email_from_name,
+			smtp_host, smtp_port, smtp_username, smtp_password_encrypted, sendgrid_api_key_encrypted,
+			whatsapp_provider, meta_phone_number_id, meta_access_token_encrypted, meta_app_secret_encrypted,
+			inbound_intents, sendgrid_webhook_verification_key, ses_topic_arn
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22,
+			COALESCE($23, 'twilio'), $24, $25, $26, COALESCE($27, '{}'::jsonb), $28, $29)
 		ON CONFLICT (organization_id) DO UPDATE SET
 			whatsapp_enabled = EXCLUDED.whatsapp_enabled,
 			twilio_account_sid = COALESCE(EXCLUDED.twilio_account_sid, notification_configs.twilio_account_sid),
@@ -110,19 +220,133 @@ func (s *WhatsAppService) SaveConfig(ctx context.Context, orgID uuid.UUID, confi
 			reminder_24h_template = COALESCE(EXCLUDED.reminder_24h_template, notification_configs.reminder_24h_template),
 			reminder_2h_template = COALESCE(EXCLUDED.reminder_2h_template, notification_configs.reminder_2h_template),
 			confirmation_response_template = COALESCE(EXCLUDED.confirmation_response_template, notification_configs.confirmation_response_template),
+			whatsapp_messages_per_second = EXCLUDED.whatsapp_messages_per_second,
+			email_messages_per_second = EXCLUDED.email_messages_per_second,
+			monthly_message_cap = EXCLUDED.monthly_message_cap,
+			email_enabled = EXCLUDED.email_enabled,
+			email_provider = COALESCE(EXCLUDED.email_provider, notification_configs.email_provider),
+			email_from_address = COALESCE(EXCLUDED.email_from_address, notification_configs.email_from_address),
+			email_from_name = COALESCE(EXCLUDED.email_from_name, notification_configs.email_from_name),
+			smtp_host = COALESCE(EXCLUDED.smtp_host, notification_configs.smtp_host),
+			smtp_port = COALESCE(EXCLUDED.smtp_port, notification_configs.smtp_port),
+			smtp_username = COALESCE(EXCLUDED.smtp_username, notification_configs.smtp_username),
+			smtp_password_encrypted = COALESCE(EXCLUDED.smtp_password_encrypted, notification_configs.smtp_password_encrypted),
+			sendgrid_api_key_encrypted = COALESCE(EXCLUDED.sendgrid_api_key_encrypted, notification_configs.sendgrid_api_key_encrypted),
+			whatsapp_provider = COALESCE($23, notification_configs.whatsapp_provider),
+			meta_phone_number_id = COALESCE(EXCLUDED.meta_phone_number_id, notification_configs.meta_phone_number_id),
+			meta_access_token_encrypted = COALESCE(EXCLUDED.meta_access_token_encrypted, notification_configs.meta_access_token_encrypted),
+			meta_app_secret_encrypted = COALESCE(EXCLUDED.meta_app_secret_encrypted, notification_configs.meta_app_secret_encrypted),
+			inbound_intents = COALESCE($27, notification_configs.inbound_intents),
+			sendgrid_webhook_verification_key = COALESCE(EXCLUDED.sendgrid_webhook_verification_key, notification_configs.sendgrid_webhook_verification_key),
+			ses_topic_arn = COALESCE(EXCLUDED.ses_topic_arn, notification_configs.ses_topic_arn),
 			updated_at = CURRENT_TIMESTAMP
 	`, orgID, config.WhatsAppEnabled, config.TwilioAccountSID, encryptedToken,
 		config.TwilioWhatsAppNumber, config.Reminder24hEnabled, config.Reminder2hEnabled,
-		config.Reminder24hTemplate, config.Reminder2hTemplate, config.ConfirmationResponseTmpl)
+		config.Reminder24hTemplate, config.Reminder2hTemplate, config.ConfirmationResponseTmpl,
+		config.WhatsAppMessagesPerSecond, config.EmailMessagesPerSecond, config.MonthlyMessageCap,
+		config.EmailEnabled, config.EmailProvider, config.EmailFromAddress, config.EmailFromName,
+		config.SMTPHost, config.SMTPPort, config.SMTPUsername, encryptedSMTPPassword, encryptedSendGridKey,
+		config.WhatsAppProvider, config.MetaPhoneNumberID, encryptedMetaToken, encryptedMetaSecret,
+		inboundIntents, config.SendGridWebhookKey, config.SESTopicArn)
 
 	if err != nil {
 		return fmt.Errorf("failed to save notification config: %w", err)
 	}
+
+	// Map the sender number to the organization for inbound routing
+	if senderNumber != "" {
+		if err := registerWhatsAppNumber(ctx, tx, orgID, senderNumber); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
 	return nil
 }
 
-// SendMessage sends a WhatsApp message using Twilio
+// SetDoNotDisturb pauses normal workflow messages until the given time, or resumes them when until is nil
+func (s *WhatsAppService) SetDoNotDisturb(ctx context.Context, orgID uuid.UUID, until *time.Time) error {
+	if until != nil && !until.After(time.Now()) {
+		return errors.New("do-not-disturb must end in the future")
+	}
+
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE notification_configs SET do_not_disturb_until = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE organization_id = $2
+	`, until, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to update do-not-disturb: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("notification config not found")
+	}
+	return nil
+}
+
+// SetSendWindow sets the part of the day messages may go out, or lets them go out at any time
+// when window is nil
+func (s *WhatsAppService) SetSendWindow(ctx context.Context, orgID uuid.UUID, window *models.SendWindow) error {
+	if window != nil {
+		if err := window.Validate(); err != nil {
+			return err
+		}
+		if window.Timezone == "" {
+			window.Timezone = orgLocation(ctx, s.db.Pool, orgID).String()
+		}
+	}
+
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE notification_configs SET send_window = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE organization_id = $2
+	`, window, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to update send window: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("notification config not found")
+	}
+	return nil
+}
+
+// SendMessage sends a WhatsApp text message through the organization's provider
 func (s *WhatsAppService) SendMessage(ctx context.Context, orgID uuid.UUID, to, message string, sessionID *uuid.UUID) (*models.WhatsAppMessage, error) {
+	if err := s.checkOptOut(ctx, orgID, models.ConsentChannelWhatsApp, to); err != nil {
+		return nil, err
+	}
+	return s.send(ctx, orgID, to, message, sessionID, func(p WhatsAppProvider) (string, error) {
+		return p.SendText(ctx, to, message)
+	})
+}
+
+// SendMedia sends the file at a public URL with an optional caption, which is what the message log shows
+func (s *WhatsAppService) SendMedia(ctx context.Context, orgID uuid.UUID, to, mediaURL, caption string, sessionID *uuid.UUID) (*models.WhatsAppMessage, error) {
+	if err := s.checkOptOut(ctx, orgID, models.ConsentChannelWhatsApp, to); err != nil {
+		return nil, err
+	}
+	content := strings.TrimSpace(caption + " " + mediaURL)
+	return s.send(ctx, orgID, to, content, sessionID, func(p WhatsAppProvider) (string, error) {
+		return p.SendMedia(ctx, to, mediaURL, caption)
+	})
+}
+
+// SendTemplate sends a pre-approved template: a Twilio Content SID or a Cloud API template name.
+// Unlike free-form messages, templates are delivered outside the conversation window.
+func (s *WhatsAppService) SendTemplate(ctx context.Context, orgID uuid.UUID, to, templateRef string, variables map[string]string, sessionID *uuid.UUID) (*models.WhatsAppMessage, error) {
+	if err := s.checkOptOut(ctx, orgID, models.ConsentChannelWhatsApp, to); err != nil {
+		return nil, err
+	}
+	content := "[template " + templateRef + "]"
+	return s.send(ctx, orgID, to, content, sessionID, func(p WhatsAppProvider) (string, error) {
+		return p.SendTemplate(ctx, to, templateRef, variables)
+	})
+}
+
+// send logs an outbound message, sends it through the organization's provider and records the
+// outcome and cost. On a provider failure the failed message log is returned with the error.
+func (s *WhatsAppService) send(ctx context.Context, orgID uuid.UUID, to, content string, sessionID *uuid.UUID,
+	deliver func(WhatsAppProvider) (string, error)) (*models.WhatsAppMessage, error) {
 	config, err := s.GetConfig(ctx, orgID)
 	if err != nil {
 		return nil, err
@@ -133,20 +357,11 @@ func (s *WhatsAppService) SendMessage(ctx context.Context, orgID uuid.UUID, to,
 	if !config.WhatsAppEnabled {
 		return nil, errors.New("WhatsApp is not enabled")
 	}
-	if config.TwilioAccountSID == nil || config.TwilioAuthTokenEncrypted == nil {
-		return nil, errors.New("Twilio credentials not configured")
-	}
-
-	// Decrypt auth token
-	authToken, err := s.decrypt(*config.TwilioAuthTokenEncrypted)
+	provider, err := s.providerFor(config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decrypt auth token: %w", err)
+		return nil, err
 	}
 
-	// Format phone number for WhatsApp
-	whatsappTo := formatWhatsAppNumber(to)
-	whatsappFrom := *config.TwilioWhatsAppNumber
-
 	// Create message log entry
 	msgLog := &models.WhatsAppMessage{
 		ID:             uuid.New(),
@@ -154,7 +369,7 @@ func (s *WhatsAppService) SendMessage(ctx context.Context, orgID uuid.UUID, to,
 		SessionID:      sessionID,
 		Direction:      models.MessageDirectionOutbound,
 		PhoneNumber:    to,
-		MessageContent: &message,
+		MessageContent: &content,
 		Status:         models.MessageStatusQueued,
 		CreatedAt:      time.Now(),
 	}
@@ -171,8 +386,7 @@ func (s *WhatsAppService) SendMessage(ctx context.Context, orgID uuid.UUID, to,
 		return nil, fmt.Errorf("failed to log message: %w", err)
 	}
 
-	// Send via Twilio API
-	messageSID, err := s.sendTwilioMessage(*config.TwilioAccountSID, authToken, whatsappFrom, whatsappTo, message)
+	messageSID, err := deliver(provider)
 	if err != nil {
 		// Update log with error
 		errMsg := err.Error()
@@ -195,52 +409,88 @@ func (s *WhatsAppService) SendMessage(ctx context.Context, orgID uuid.UUID, to,
 	msgLog.Status = models.MessageStatusSent
 	msgLog.MessageSID = &messageSID
 
+	// Direct sends (tests, reminders, replies) count towards spend but are never paused by the cap
+	if err := s.recordMessageCost(ctx, orgID, provider.Name(), &messageSID); err != nil {
+		fmt.Printf("Failed to record message cost: %v\n", err)
+	}
+
 	return msgLog, nil
 }
 
-// sendTwilioMessage sends a message via Twilio REST API
-func (s *WhatsAppService) sendTwilioMessage(accountSID, authToken, from, to, body string) (string, error) {
-	twilioURL := fmt.Sprintf("https://api.twilio.com/2010-04-01/Accounts/%s/Messages.json", accountSID)
+// recordMessageCost stores the rate table cost of a sent message until the provider reports its price
+func (s *WhatsAppService) recordMessageCost(ctx context.Context, orgID uuid.UUID, provider string, messageSID *string) error {
+	_, err := s.db.Pool.Exec(ctx, `
+		INSERT INTO message_costs (organization_id, channel, provider, message_sid, cost, currency, source, critical)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, true)
+	`, orgID, models.MessageChannelWhatsApp, provider, messageSID, models.MessageRates[models.MessageChannelWhatsApp],
+		models.MessageCostCurrency, models.MessageCostSourceRateTable)
+	return err
+}
 
-	data := url.Values{}
-	data.Set("To", to)
-	data.Set("From", from)
-	data.Set("Body", body)
+// TwilioMessageResponse is the provider's answer to a message send
+type TwilioMessageResponse struct {
+	SID         string  `json:"sid"`
+	Status      string  `json:"status"`
+	To          string  `json:"to"`
+	From        string  `json:"from"`
+	NumSegments string  `json:"num_segments"`
+	ErrorCode   *int    `json:"error_code"`
+	ErrorMsg    *string `json:"error_message"`
+}
 
-	req, err := http.NewRequest("POST", twilioURL, strings.NewReader(data.Encode()))
+// SendTestSMS sends a plain SMS through the organization's Twilio account, from the configured
+// WhatsApp sender number, and returns the provider response
+func (s *WhatsAppService) SendTestSMS(ctx context.Context, orgID uuid.UUID, to, message string) (*TwilioMessageResponse, error) {
+	if err := s.checkOptOut(ctx, orgID, models.ConsentChannelSMS, to); err != nil {
+		return nil, err
+	}
+	config, err := s.GetConfig(ctx, orgID)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if config == nil || config.TwilioAccountSID == nil || config.TwilioAuthTokenEncrypted == nil {
+		return nil, errors.New("Twilio credentials not configured")
+	}
+	if config.TwilioWhatsAppNumber == nil || *config.TwilioWhatsAppNumber == "" {
+		return nil, errors.New("Twilio sender number not configured")
 	}
 
-	req.SetBasicAuth(accountSID, authToken)
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+	authToken, err := s.decrypt(*config.TwilioAuthTokenEncrypted)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("failed to decrypt auth token: %w", err)
 	}
-	defer resp.Body.Close()
 
-	body_bytes, _ := io.ReadAll(resp.Body)
+	provider := newTwilioProvider(*config.TwilioAccountSID, authToken, *config.TwilioWhatsAppNumber)
+	return provider.send(ctx, url.Values{
+		"To":   {strings.TrimPrefix(formatWhatsAppNumber(to), "whatsapp:")},
+		"From": {strings.TrimPrefix(*config.TwilioWhatsAppNumber, "whatsapp:")},
+		"Body": {message},
+	})
+}
 
-	if resp.StatusCode >= 400 {
-		var errorResp struct {
-			Message string `json:"message"`
-			Code    int    `json:"code"`
-		}
-		json.Unmarshal(body_bytes, &errorResp)
-		return "", fmt.Errorf("twilio error: %s (code: %d)", errorResp.Message, errorResp.Code)
+// CheckCredentials verifies the organization's credentials with its WhatsApp provider.
+// It returns errIntegrationNotConfigured when WhatsApp is not set up.
+func (s *WhatsAppService) CheckCredentials(ctx context.Context, orgID uuid.UUID) error {
+	config, err := s.GetConfig(ctx, orgID)
+	if err != nil {
+		return err
 	}
-
-	var successResp struct {
-		SID string `json:"sid"`
+	if config == nil || !config.WhatsAppEnabled {
+		return errIntegrationNotConfigured
 	}
-	if err := json.Unmarshal(body_bytes, &successResp); err != nil {
-		return "", err
+	if config.WhatsAppProvider == models.WhatsAppProviderMeta {
+		if !config.MetaConfigured() {
+			return errIntegrationNotConfigured
+		}
+	} else if config.TwilioAccountSID == nil || config.TwilioAuthTokenEncrypted == nil {
+		return errIntegrationNotConfigured
 	}
 
-	return successResp.SID, nil
+	provider, err := s.providerFor(config)
+	if err != nil {
+		return err
+	}
+	return provider.CheckCredentials(ctx)
 }
 
 // SendSessionReminder sends a reminder for a session
@@ -254,37 +504,37 @@ func (s *WhatsAppService) SendSessionReminder(ctx context.Context, reminder *mod
 	}
 
 	// Get appropriate template
-	var template string
-	if reminder.Type == models.ReminderType24h {
-		if !config.Reminder24hEnabled {
-			return s.skipReminder(ctx, reminder.ID, "24h reminders disabled")
-		}
-		if config.Reminder24hTemplate != nil {
-			template = *config.Reminder24hTemplate
-		}
-	} else {
-		if !config.Reminder2hEnabled {
-			return s.skipReminder(ctx, reminder.ID, "2h reminders disabled")
-		}
-		if config.Reminder2hTemplate != nil {
-			template = *config.Reminder2hTemplate
+	template, skipReason := reminderTemplate(config, reminder.Type)
+	if skipReason != "" {
+		return s.skipReminder(ctx, reminder.ID, skipReason)
+	}
+
+	// Reminders due in quiet hours wait for the next send window, unless it opens too late
+	if config.SendWindow != nil {
+		now := time.Now()
+		if opens := config.SendWindow.NextAllowed(now); opens.After(now) {
+			if !opens.Before(reminder.ScheduledAt) {
+				return s.skipReminder(ctx, reminder.ID, "quiet hours last until the session starts")
+			}
+			if _, err := s.db.Pool.Exec(ctx, `
+				UPDATE scheduled_reminders SET deferred_until = $1 WHERE id = $2
+			`, opens, reminder.ID); err != nil {
+				return fmt.Errorf("failed to defer reminder: %w", err)
+			}
+			log.Printf("[WhatsApp] Quiet hours, reminder %s deferred until %v", reminder.ID, opens)
+			return nil
 		}
 	}
 
-	if template == "" {
-		return s.skipReminder(ctx, reminder.ID, "no template configured")
-	}
-
 	// Build message from template
-	message := s.buildMessage(template, models.MessageTemplateVars{
-		PatientName:   reminder.PatientName,
-		TherapistName: reminder.TherapistName,
-		Date:          reminder.ScheduledAt.Format("02/01/2006"),
-		Time:          reminder.ScheduledAt.Format("15:04"),
-	})
+	message := buildReminderMessage(template, reminder.PatientName, reminder.TherapistName,
+		reminder.ScheduledAt.In(orgLocation(ctx, s.db.Pool, orgID)))
 
 	// Send message
 	msgLog, err := s.SendMessage(ctx, orgID, reminder.PatientPhone, message, &reminder.SessionID)
+	if errors.Is(err, ErrRecipientOptedOut) {
+		return s.skipReminder(ctx, reminder.ID, "patient opted out of WhatsApp messages")
+	}
 
 	// Update reminder status
 	if err != nil {
@@ -320,6 +570,7 @@ func (s *WhatsAppService) GetPendingReminders(ctx context.Context) ([]*models.Sc
 		JOIN therapists t ON t.id = sess.therapist_id
 		WHERE sr.status = 'pending'
 			AND sr.scheduled_for <= NOW()
+			AND (sr.deferred_until IS NULL OR sr.deferred_until <= NOW())
 			AND sess.status IN ('pending', 'confirmed')
 			AND sess.deleted_at IS NULL
 		ORDER BY sr.scheduled_for ASC
@@ -359,80 +610,96 @@ func (s *WhatsAppService) GetPendingReminders(ctx context.Context) ([]*models.Sc
 
 // ProcessIncomingMessage handles incoming WhatsApp messages (webhook)
 func (s *WhatsAppService) ProcessIncomingMessage(ctx context.Context, orgID uuid.UUID, from, body, messageSID string) error {
+	return s.processIncoming(ctx, orgID, from, body, messageSID, nil)
+}
+
+// processIncoming logs an inbound message with the provider payload it came in, when kept, and
+// routes it to the handler of its intent
+func (s *WhatsAppService) processIncoming(ctx context.Context, orgID uuid.UUID, from, body, messageSID string, rawPayload json.RawMessage) error {
 	// Log the incoming message
 	content := body
+	messageID := uuid.New()
 	_, err := s.db.Pool.Exec(ctx, `
 		INSERT INTO whatsapp_messages (
-			id, organization_id, direction, phone_number, message_content, message_sid, status
-		) VALUES ($1, $2, $3, $4, $5, $6, $7)
-	`, uuid.New(), orgID, models.MessageDirectionInbound, from, content, messageSID, models.MessageStatusDelivered)
+			id, organization_id, direction, phone_number, message_content, message_sid, status, raw_payload
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, messageID, orgID, models.MessageDirectionInbound, from, content, messageSID, models.MessageStatusDelivered, rawPayload)
 	if err != nil {
 		return fmt.Errorf("failed to log incoming message: %w", err)
 	}
 
-	// Parse response (SIM/NAO, YES/NO, etc.)
-	response := parseConfirmationResponse(body)
-
-	// Find pending session for this phone number
-	var sessionID uuid.UUID
-	var currentStatus models.SessionStatus
-	err = s.db.Pool.QueryRow(ctx, `
-		SELECT s.id, s.status
-		FROM sessions s
-		JOIN patients p ON p.id = s.patient_id
-		WHERE s.organization_id = $1
-			AND p.phone = $2
-			AND s.status IN ('pending', 'confirmed')
-			AND s.scheduled_at > NOW()
-			AND s.deleted_at IS NULL
-		ORDER BY s.scheduled_at ASC
-		LIMIT 1
-	`, orgID, normalizePhone(from)).Scan(&sessionID, &currentStatus)
+	// Every inbound message reopens the 24-hour customer service window
+	_, err = s.db.Pool.Exec(ctx, `
+		INSERT INTO whatsapp_conversations (organization_id, phone_number, last_inbound_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (organization_id, phone_number) DO UPDATE SET last_inbound_at = EXCLUDED.last_inbound_at
+	`, orgID, models.NormalizeWhatsAppPhone(from))
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			// No pending session found - that's okay
-			return nil
-		}
-		return fmt.Errorf("failed to find session: %w", err)
+		return fmt.Errorf("failed to update conversation window: %w", err)
 	}
 
-	// Update session based on response
-	if response == "confirmed" && currentStatus == models.SessionStatusPending {
-		s.db.Pool.Exec(ctx, `
-			UPDATE sessions SET status = 'confirmed' WHERE id = $1
-		`, sessionID)
+	config, err := s.GetConfig(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	return s.routeInbound(ctx, config, &InboundMessage{
+		OrganizationID: orgID,
+		MessageID:      messageID,
+		From:           from,
+		Body:           body,
+	})
+}
 
-		// Update session confirmation record
-		s.db.Pool.Exec(ctx, `
-			UPDATE session_confirmations
-			SET responded_at = NOW(), response = 'confirmed'
-			WHERE session_id = $1 AND response IS NULL
-		`, sessionID)
-	} else if response == "cancelled" {
-		s.db.Pool.Exec(ctx, `
-			UPDATE sessions SET status = 'cancelled', cancel_reason = 'Cancelled via WhatsApp', cancelled_at = NOW()
-			WHERE id = $1
-		`, sessionID)
+// GetSessionWindow returns the customer service window of a phone number, within which
+// free-form WhatsApp messages can be sent
+func (s *WhatsAppService) GetSessionWindow(ctx context.Context, orgID uuid.UUID, phone string) (*models.WhatsAppSessionWindow, error) {
+	phone = models.NormalizeWhatsAppPhone(phone)
 
-		s.db.Pool.Exec(ctx, `
-			UPDATE session_confirmations
-			SET responded_at = NOW(), response = 'cancelled'
-			WHERE session_id = $1 AND response IS NULL
-		`, sessionID)
+	var lastInboundAt *time.Time
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT last_inbound_at FROM whatsapp_conversations
+		WHERE organization_id = $1 AND phone_number = $2
+	`, orgID, phone).Scan(&lastInboundAt)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get session window: %w", err)
 	}
 
-	return nil
+	return models.NewWhatsAppSessionWindow(phone, lastInboundAt), nil
 }
 
-// UpdateMessageStatus updates message status from Twilio webhook
-func (s *WhatsAppService) UpdateMessageStatus(ctx context.Context, messageSID, status string) error {
+// UpdateMessageStatus updates message status from Twilio webhook.
+// When the callback carries the price, it replaces the rate table estimate of the message cost.
+func (s *WhatsAppService) UpdateMessageStatus(ctx context.Context, messageSID, status, price, priceUnit string) error {
 	twilioStatus := mapTwilioStatus(status)
 	_, err := s.db.Pool.Exec(ctx, `
 		UPDATE whatsapp_messages
 		SET status = $1
 		WHERE message_sid = $2
 	`, twilioStatus, messageSID)
-	return err
+	if err != nil {
+		return err
+	}
+
+	if price == "" {
+		return nil
+	}
+	cost, err := decimal.NewFromString(price)
+	if err != nil {
+		return fmt.Errorf("invalid price %q: %w", price, err)
+	}
+	if priceUnit == "" {
+		priceUnit = models.MessageCostCurrency
+	}
+
+	// Twilio reports prices as negative amounts (debits)
+	_, err = s.db.Pool.Exec(ctx, `
+		UPDATE message_costs SET cost = $1, currency = $2, source = $3
+		WHERE message_sid = $4
+	`, cost.Abs(), strings.ToUpper(priceUnit), models.MessageCostSourceProvider, messageSID)
+	if err != nil {
+		return fmt.Errorf("failed to update message cost: %w", err)
+	}
+	return nil
 }
 
 // skipReminder marks a reminder as skipped
@@ -445,8 +712,45 @@ func (s *WhatsAppService) skipReminder(ctx context.Context, reminderID uuid.UUID
 	return err
 }
 
+// reminderTemplate returns the configured template for a reminder type, or the reason it is skipped
+func reminderTemplate(config *models.NotificationConfig, reminderType models.ReminderType) (template, skipReason string) {
+	if config.RemindersMigratedAt != nil {
+		return "", "reminders migrated to workflow"
+	}
+	if reminderType == models.ReminderType24h {
+		if !config.Reminder24hEnabled {
+			return "", "24h reminders disabled"
+		}
+		if config.Reminder24hTemplate != nil {
+			template = *config.Reminder24hTemplate
+		}
+	} else {
+		if !config.Reminder2hEnabled {
+			return "", "2h reminders disabled"
+		}
+		if config.Reminder2hTemplate != nil {
+			template = *config.Reminder2hTemplate
+		}
+	}
+
+	if template == "" {
+		return "", "no template configured"
+	}
+	return template, ""
+}
+
+// buildReminderMessage renders a session reminder template
+func buildReminderMessage(template, patientName, therapistName string, scheduledAt time.Time) string {
+	return buildMessage(template, models.MessageTemplateVars{
+		PatientName:   patientName,
+		TherapistName: therapistName,
+		Date:          scheduledAt.Format("02/01/2006"),
+		Time:          scheduledAt.Format("15:04"),
+	})
+}
+
 // buildMessage replaces template variables with values
-func (s *WhatsAppService) buildMessage(template string, vars models.MessageTemplateVars) string {
+func buildMessage(template string, vars models.MessageTemplateVars) string {
 	result := template
 	result = strings.ReplaceAll(result, "{{patient_name}}", vars.PatientName)
 	result = strings.ReplaceAll(result, "{{therapist}}", vars.TherapistName)
@@ -458,102 +762,18 @@ func (s *WhatsAppService) buildMessage(template string, vars models.MessageTempl
 
 // encrypt encrypts a string using AES-256-GCM
 func (s *WhatsAppService) encrypt(plaintext string) (string, error) {
-	block, err := aes.NewCipher(s.encryptionKey)
-	if err != nil {
-		return "", err
-	}
-
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return "", err
-	}
-
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		return "", err
-	}
-
-	ciphertext := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
-	return base64.StdEncoding.EncodeToString(ciphertext), nil
+	return encryptSecret(s.encryptionKey, plaintext)
 }
 
 // decrypt decrypts a string using AES-256-GCM
 func (s *WhatsAppService) decrypt(ciphertext string) (string, error) {
-	data, err := base64.StdEncoding.DecodeString(ciphertext)
-	if err != nil {
-		return "", err
-	}
-
-	block, err := aes.NewCipher(s.encryptionKey)
-	if err != nil {
-		return "", err
-	}
-
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return "", err
-	}
-
-	nonceSize := gcm.NonceSize()
-	if len(data) < nonceSize {
-		return "", errors.New("ciphertext too short")
-	}
-
-	nonce, ciphertextBytes := data[:nonceSize], data[nonceSize:]
-	plaintext, err := gcm.Open(nil, nonce, ciphertextBytes, nil)
-	if err != nil {
-		return "", err
-	}
-
-	return string(plaintext), nil
+	return decryptSecret(s.encryptionKey, ciphertext)
 }
 
 // Helper functions
 
 func formatWhatsAppNumber(phone string) string {
-	// Remove any existing whatsapp: prefix
-	phone = strings.TrimPrefix(phone, "whatsapp:")
-	// Remove spaces and dashes
-	phone = strings.ReplaceAll(phone, " ", "")
-	phone = strings.ReplaceAll(phone, "-", "")
-	// Add + if not present
-	if !strings.HasPrefix(phone, "+") {
-		// Assume Portugal country code if no prefix
-		if !strings.HasPrefix(phone, "351") {
-			phone = "351" + phone
-		}
-		phone = "+" + phone
-	}
-	return "whatsapp:" + phone
-}
-
-func normalizePhone(phone string) string {
-	phone = strings.TrimPrefix(phone, "whatsapp:")
-	phone = strings.ReplaceAll(phone, " ", "")
-	phone = strings.ReplaceAll(phone, "-", "")
-	return phone
-}
-
-func parseConfirmationResponse(message string) string {
-	message = strings.ToLower(strings.TrimSpace(message))
-
-	// Positive responses
-	positiveResponses := []string{"sim", "yes", "s", "y", "1", "confirmo", "confirmado", "ok"}
-	for _, resp := range positiveResponses {
-		if message == resp || strings.HasPrefix(message, resp+" ") {
-			return "confirmed"
-		}
-	}
-
-	// Negative responses
-	negativeResponses := []string{"nao", "não", "no", "n", "0", "cancelar", "cancelo", "cancelado"}
-	for _, resp := range negativeResponses {
-		if message == resp || strings.HasPrefix(message, resp+" ") {
-			return "cancelled"
-		}
-	}
-
-	return "unknown"
+	return "whatsapp:" + models.NormalizeWhatsAppPhone(phone)
 }
 
 func mapTwilioStatus(status string) models.WhatsAppMessageStatus {
@@ -588,4 +808,30 @@ type NotificationConfigInput struct {
 	Reminder24hTemplate      *string `json:"reminder_24h_template"`
 	Reminder2hTemplate       *string `json:"reminder_2h_template"`
 	ConfirmationResponseTmpl *string `json:"confirmation_response_template"`
+	// Replaces the organization's inbound intent settings when set
+	InboundIntents models.InboundIntentSettings `json:"inbound_intents"`
+	// Message rate limits, nil restores the platform default
+	WhatsAppMessagesPerSecond *float64 `json:"whatsapp_messages_per_second"`
+	EmailMessagesPerSecond    *float64 `json:"email_messages_per_second"`
+	// Monthly message spend cap, nil removes the cap
+	MonthlyMessageCap *decimal.Decimal `json:"monthly_message_cap"`
+	// Organization email provider, secrets are kept when omitted
+	EmailEnabled     bool                  `json:"email_enabled"`
+	EmailProvider    *models.EmailProvider `json:"email_provider"`
+	EmailFromAddress *string               `json:"email_from_address"`
+	EmailFromName    *string               `json:"email_from_name"`
+	SMTPHost         *string               `json:"smtp_host"`
+	SMTPPort         *int                  `json:"smtp_port"`
+	SMTPUsername     *string               `json:"smtp_username"`
+	SMTPPassword     *string               `json:"smtp_password"`
+	SendGridAPIKey   *string               `json:"sendgrid_api_key"`
+	// Bounce and complaint webhooks: SendGrid's Signed Event Webhook public key and the SES
+	// notifications topic, kept when omitted
+	SendGridWebhookKey *string `json:"sendgrid_webhook_verification_key"`
+	SESTopicArn        *string `json:"ses_topic_arn"`
+	// WhatsApp provider, Cloud API secrets are kept when omitted
+	WhatsAppProvider  *models.WhatsAppProvider `json:"whatsapp_provider"`
+	MetaPhoneNumberID *string                  `json:"meta_phone_number_id"`
+	MetaAccessToken   *string                  `json:"meta_access_token"`
+	MetaAppSecret     *string                  `json:"meta_app_secret"`
 }
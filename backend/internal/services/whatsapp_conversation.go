@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxWhatsAppReplyLength is the longest free-form message WhatsApp accepts
+const maxWhatsAppReplyLength = 4096
+
+// threadPhoneSQL is the E.164 phone number of the thread of a message. Messages were logged with
+// and without the whatsapp: prefix.
+const threadPhoneSQL = `'+' || regexp_replace(m.phone_number, '\D', '', 'g')`
+
+// whatsAppThreadsQuery lists the organization's threads ($1), newest message first, only the
+// thread of one phone number when $2 is set and only threads with unread messages when $3 is
+func whatsAppThreadsQuery(selectList string) string {
+	return `
+		WITH threads AS (
+			SELECT ` + threadPhoneSQL + ` AS phone_number, MAX(m.created_at) AS last_message_at
+			FROM whatsapp_messages m
+			WHERE m.organization_id = $1
+			GROUP BY 1
+		), listed AS (
+			SELECT t.phone_number, cl.id AS client_id, cl.name AS client_name, cl.patient_id,
+				last.message_content, last.direction, t.last_message_at, wc.last_inbound_at,
+				(
+					SELECT COUNT(*) FROM whatsapp_messages m
+					WHERE m.organization_id = $1 AND m.direction = 'inbound' AND ` + threadPhoneSQL + ` = t.phone_number
+						AND m.created_at > COALESCE(wc.last_read_at, '-infinity')
+				) AS unread_count
+			FROM threads t
+			LEFT JOIN whatsapp_conversations wc ON wc.organization_id = $1 AND wc.phone_number = t.phone_number
+			LEFT JOIN LATERAL (
+				SELECT m.message_content, m.direction FROM whatsapp_messages m
+				WHERE m.organization_id = $1 AND ` + threadPhoneSQL + ` = t.phone_number
+				ORDER BY m.created_at DESC
+				LIMIT 1
+			) last ON true
+			LEFT JOIN LATERAL (
+				SELECT c.id, c.name,
+					(SELECT p.id FROM patients p WHERE p.client_id = c.id ORDER BY p.created_at LIMIT 1) AS patient_id
+				FROM clients c
+				WHERE c.organization_id = $1 AND c.deleted_at IS NULL
+					AND '+' || regexp_replace(c.phone, '\D', '', 'g') = t.phone_number
+				ORDER BY c.created_at
+				LIMIT 1
+			) cl ON true
+		)
+		SELECT ` + selectList + ` FROM listed
+		WHERE ($2::varchar IS NULL OR phone_number = $2) AND (NOT $3 OR unread_count > 0)`
+}
+
+// WhatsAppThreadFilters narrows the conversation list
+type WhatsAppThreadFilters struct {
+	ClientID   *uuid.UUID
+	PatientID  *uuid.UUID
+	UnreadOnly bool
+	Limit      int
+	Offset     int
+}
+
+// WhatsAppConversationService shows the logged WhatsApp messages as one conversation per phone
+// number and sends replies typed in the app
+type WhatsAppConversationService struct {
+	db       *database.DB
+	whatsapp *WhatsAppService
+}
+
+func NewWhatsAppConversationService(db *database.DB, whatsapp *WhatsAppService) *WhatsAppConversationService {
+	return &WhatsAppConversationService{db: db, whatsapp: whatsapp}
+}
+
+// normalizeWhatsAppReply trims a reply and checks it can be sent
+func normalizeWhatsAppReply(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", errors.New("message is required")
+	}
+	if len([]rune(body)) > maxWhatsAppReplyLength {
+		return "", fmt.Errorf("message must have at most %d characters", maxWhatsAppReplyLength)
+	}
+	return body, nil
+}
+
+// filterPhone returns the phone number of the client or patient the threads are filtered by
+func (s *WhatsAppConversationService) filterPhone(ctx context.Context, orgID uuid.UUID, filters WhatsAppThreadFilters) (*string, error) {
+	var raw string
+	switch {
+	case filters.PatientID != nil:
+		err := s.db.Pool.QueryRow(ctx, `
+			SELECT c.phone FROM patients p
+			JOIN clients c ON c.id = p.client_id
+			WHERE p.id = $1 AND p.organization_id = $2
+		`, *filters.PatientID, orgID).Scan(&raw)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, errors.New("patient not found")
+			}
+			return nil, fmt.Errorf("failed to get patient: %w", err)
+		}
+	case filters.ClientID != nil:
+		err := s.db.Pool.QueryRow(ctx, `
+			SELECT phone FROM clients WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+		`, *filters.ClientID, orgID).Scan(&raw)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, errors.New("client not found")
+			}
+			return nil, fmt.Errorf("failed to get client: %w", err)
+		}
+	default:
+		return nil, nil
+	}
+
+	phone, err := normalizeOrgPhone(ctx, s.db.Pool, orgID, raw)
+	if err != nil {
+		phone = models.NormalizeWhatsAppPhone(raw)
+	}
+	return &phone, nil
+}
+
+// ListThreads returns the organization's WhatsApp conversations, most recent first
+func (s *WhatsAppConversationService) ListThreads(ctx context.Context, orgID uuid.UUID, filters WhatsAppThreadFilters) ([]*models.WhatsAppThread, int, error) {
+	phone, err := s.filterPhone(ctx, orgID, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+	args := []interface{}{orgID, phone, filters.UnreadOnly}
+
+	var total int
+	if err := s.db.Pool.QueryRow(ctx, whatsAppThreadsQuery("COUNT(*)"), args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count conversations: %w", err)
+	}
+
+	rows, err := s.db.Pool.Query(ctx, whatsAppThreadsQuery(`
+		phone_number, client_id, client_name, patient_id, message_content, direction, last_message_at,
+		last_inbound_at, unread_count`)+`
+		ORDER BY last_message_at DESC
+		LIMIT $4 OFFSET $5
+	`, append(args, filters.Limit, filters.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list conversations: %w", err)
+	}
+	defer rows.Close()
+
+	threads := []*models.WhatsAppThread{}
+	for rows.Next() {
+		var t models.WhatsAppThread
+		var lastInboundAt *time.Time
+		if err := rows.Scan(&t.PhoneNumber, &t.ClientID, &t.ClientName, &t.PatientID, &t.LastMessage, &t.LastDirection,
+			&t.LastMessageAt, &lastInboundAt, &t.UnreadCount); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan conversation: %w", err)
+		}
+		t.Window = models.NewWhatsAppSessionWindow(t.PhoneNumber, lastInboundAt)
+		threads = append(threads, &t)
+	}
+	return threads, total, rows.Err()
+}
+
+// UnreadCount returns how many inbound messages nobody has read yet
+func (s *WhatsAppConversationService) UnreadCount(ctx context.Context, orgID uuid.UUID) (int, error) {
+	var count int
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM whatsapp_messages m
+		LEFT JOIN whatsapp_conversations wc ON wc.organization_id = m.organization_id AND wc.phone_number = `+threadPhoneSQL+`
+		WHERE m.organization_id = $1 AND m.direction = 'inbound' AND m.created_at > COALESCE(wc.last_read_at, '-infinity')
+	`, orgID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread messages: %w", err)
+	}
+	return count, nil
+}
+
+// Messages returns the messages of the conversation with a phone number, newest first
+func (s *WhatsAppConversationService) Messages(ctx context.Context, orgID uuid.UUID, phone string, limit, offset int) ([]*models.WhatsAppThreadMessage, int, error) {
+	phone = models.NormalizeWhatsAppPhone(phone)
+
+	var total int
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM whatsapp_messages m WHERE m.organization_id = $1 AND `+threadPhoneSQL+` = $2
+	`, orgID, phone).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count messages: %w", err)
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT m.id, m.session_id, m.direction, m.message_content, m.status, m.error_message,
+			CASE WHEN m.raw_payload->>'type' = ANY($5) THEN m.raw_payload->>'type' END, m.sent_by,
+			CASE WHEN u.id IS NOT NULL THEN CONCAT(u.first_name, ' ', u.last_name) END, m.created_at
+		FROM whatsapp_messages m
+		LEFT JOIN users u ON u.id = m.sent_by
+		WHERE m.organization_id = $1 AND `+threadPhoneSQL+` = $2
+		ORDER BY m.created_at DESC
+		LIMIT $3 OFFSET $4
+	`, orgID, phone, limit, offset, metaMediaTypes)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := []*models.WhatsAppThreadMessage{}
+	for rows.Next() {
+		var m models.WhatsAppThreadMessage
+		if err := rows.Scan(&m.ID, &m.SessionID, &m.Direction, &m.MessageContent, &m.Status, &m.ErrorMessage, &m.MediaType, &m.SentBy,
+			&m.SentByName, &m.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan message: %w", err)
+		}
+		messages = append(messages, &m)
+	}
+	return messages, total, rows.Err()
+}
+
+// DownloadMedia returns the file of an inbound message
+func (s *WhatsAppConversationService) DownloadMedia(ctx context.Context, orgID, messageID uuid.UUID) (string, []byte, error) {
+	return s.whatsapp.DownloadMedia(ctx, orgID, messageID)
+}
+
+// MarkRead marks the conversation with a phone number as read up to now
+func (s *WhatsAppConversationService) MarkRead(ctx context.Context, orgID uuid.UUID, phone string) error {
+	_, err := s.db.Pool.Exec(ctx, `
+		UPDATE whatsapp_conversations SET last_read_at = NOW()
+		WHERE organization_id = $1 AND phone_number = $2
+	`, orgID, models.NormalizeWhatsAppPhone(phone))
+	if err != nil {
+		return fmt.Errorf("failed to mark conversation as read: %w", err)
+	}
+	return nil
+}
+
+// Reply sends a free-form message typed by a user to the conversation with a phone number, with
+// the file at mediaURL attached when set. WhatsApp only delivers free-form messages within 24 hours
+// of the contact's last message, so replies outside that window are refused. Replying marks the
+// conversation as read.
+func (s *WhatsAppConversationService) Reply(ctx context.Context, orgID, userID uuid.UUID, phone, body, mediaURL string) (*models.WhatsAppThreadMessage, error) {
+	mediaURL = strings.TrimSpace(mediaURL)
+	if mediaURL != "" {
+		if u, err := url.Parse(mediaURL); err != nil || u.Scheme != "https" || u.Host == "" {
+			return nil, errors.New("media URL must be a public https URL")
+		}
+		body = strings.TrimSpace(body)
+		if len([]rune(body)) > maxWhatsAppReplyLength {
+			return nil, fmt.Errorf("message must have at most %d characters", maxWhatsAppReplyLength)
+		}
+	} else {
+		var err error
+		if body, err = normalizeWhatsAppReply(body); err != nil {
+			return nil, err
+		}
+	}
+
+	window, err := s.whatsapp.GetSessionWindow(ctx, orgID, phone)
+	if err != nil {
+		return nil, err
+	}
+	if !window.IsOpen {
+		return nil, errors.New("the WhatsApp conversation window is closed; only approved templates can be sent")
+	}
+
+	var msg *models.WhatsAppMessage
+	var sendErr error
+	if mediaURL != "" {
+		msg, sendErr = s.whatsapp.SendMedia(ctx, orgID, window.PhoneNumber, mediaURL, body, nil)
+	} else {
+		msg, sendErr = s.whatsapp.SendMessage(ctx, orgID, window.PhoneNumber, body, nil)
+	}
+	if msg == nil {
+		return nil, sendErr
+	}
+	if _, err := s.db.Pool.Exec(ctx, `UPDATE whatsapp_messages SET sent_by = $1 WHERE id = $2`, userID, msg.ID); err != nil {
+		return nil, fmt.Errorf("failed to record reply sender: %w", err)
+	}
+	if sendErr != nil {
+		return nil, sendErr
+	}
+
+	if err := s.MarkRead(ctx, orgID, window.PhoneNumber); err != nil {
+		return nil, err
+	}
+
+	status := msg.Status
+	return &models.WhatsAppThreadMessage{
+		ID:             msg.ID,
+		Direction:      msg.Direction,
+		MessageContent: msg.MessageContent,
+		Status:         &status,
+		SentBy:         &userID,
+		CreatedAt:      msg.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeWhatsAppReply(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{"trimmed", "  Olá, confirmamos amanhã às 10h.\n", "Olá, confirmamos amanhã às 10h.", false},
+		{"empty", "   ", "", true},
+		{"at the limit", strings.Repeat("ã", maxWhatsAppReplyLength), strings.Repeat("ã", maxWhatsAppReplyLength), false},
+		{"too long", strings.Repeat("a", maxWhatsAppReplyLength+1), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeWhatsAppReply(tt.body)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeWhatsAppReply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("normalizeWhatsAppReply() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
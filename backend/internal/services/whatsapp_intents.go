@@ -0,0 +1,512 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/phone"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// ErrInboundIntentUnhandled is returned by intent handlers that can't act on a message, such as a
+// reschedule request without an upcoming session. The message then falls back to the staff.
+var ErrInboundIntentUnhandled = errors.New("inbound intent not handled")
+
+// ErrRecipientOptedOut is returned for WhatsApp messages to numbers that opted out
+var ErrRecipientOptedOut = errors.New("recipient has opted out of WhatsApp messages")
+
+// ErrRecipientOptedOutSMS is returned for SMS to numbers that opted out of them
+var ErrRecipientOptedOutSMS = errors.New("recipient has opted out of SMS messages")
+
+// maxIntentKeywords bounds the keywords an organization adds to one intent
+const maxIntentKeywords = 50
+
+// intentMatch is how a keyword is looked for in a message
+type intentMatch int
+
+const (
+	matchExact    intentMatch = iota // the whole message
+	matchPrefix                      // the whole message or its first words
+	matchContains                    // anywhere in the message
+)
+
+// builtinIntent is the default behaviour of an intent
+type builtinIntent struct {
+	match    intentMatch
+	keywords []string
+	reply    string // may use the variables the handler returns, e.g. {{balance}}
+}
+
+var builtinInboundIntents = map[models.InboundIntent]builtinIntent{
+	models.InboundIntentOptOut: {
+		match:    matchExact,
+		keywords: []string{"stop", "parar", "sair", "unsubscribe"},
+		reply:    "Não voltará a receber mensagens nossas por WhatsApp. Se mudar de ideias, responda START.",
+	},
+	models.InboundIntentOptIn: {
+		match:    matchExact,
+		keywords: []string{"start", "voltar", "subscrever"},
+		reply:    "Voltará a receber as nossas mensagens por WhatsApp.",
+	},
+	models.InboundIntentReschedule: {
+		match: matchContains,
+		keywords: []string{"remarcar", "reagendar", "mudar a consulta", "mudar a sessão", "alterar a consulta",
+			"alterar a sessão", "outro horário", "outra hora", "outro dia", "reschedule"},
+		reply: "Pode escolher outro horário para a sua sessão de {{session_date}} aqui: {{reschedule_link}}",
+	},
+	models.InboundIntentBalance: {
+		match:    matchContains,
+		keywords: []string{"saldo", "quanto devo", "valor em dívida", "valor em divida", "pagamentos em falta", "balance"},
+		reply:    "O valor em aberto é de {{balance}}.",
+	},
+	models.InboundIntentConfirm: {
+		match:    matchPrefix,
+		keywords: []string{"sim", "yes", "s", "y", "1", "confirmo", "confirmado", "ok"},
+	},
+	models.InboundIntentCancel: {
+		match:    matchPrefix,
+		keywords: []string{"nao", "não", "no", "n", "0", "cancelar", "cancelo", "cancelado"},
+	},
+}
+
+// normalizeInboundText lowercases a message and drops the spaces and punctuation around it
+func normalizeInboundText(text string) string {
+	return strings.Trim(strings.ToLower(strings.TrimSpace(text)), " .,;:!?")
+}
+
+// keywordMatches reports whether a normalized message matches a keyword
+func keywordMatches(message, keyword string, match intentMatch) bool {
+	keyword = normalizeInboundText(keyword)
+	if keyword == "" {
+		return false
+	}
+	switch match {
+	case matchExact:
+		return message == keyword
+	case matchPrefix:
+		return message == keyword || strings.HasPrefix(message, keyword+" ")
+	default:
+		return strings.Contains(message, keyword)
+	}
+}
+
+// matchInboundIntent returns the first enabled intent a message matches, or the fallback
+func matchInboundIntent(body string, settings models.InboundIntentSettings) models.InboundIntent {
+	message := normalizeInboundText(body)
+	if message == "" {
+		return models.InboundIntentFallback
+	}
+
+	for _, intent := range models.InboundIntents {
+		builtin := builtinInboundIntents[intent]
+		keywords := builtin.keywords
+		if rule, ok := settings[intent]; ok {
+			if !rule.Enabled {
+				continue
+			}
+			keywords = append(append([]string(nil), keywords...), rule.Keywords...)
+		}
+		for _, keyword := range keywords {
+			if keywordMatches(message, keyword, builtin.match) {
+				return intent
+			}
+		}
+	}
+	return models.InboundIntentFallback
+}
+
+// validateInboundIntents checks an organization's intent settings, normalizing their keywords
+func validateInboundIntents(settings models.InboundIntentSettings) error {
+	for intent, rule := range settings {
+		if !intent.IsValid() {
+			return errors.New("invalid inbound intent")
+		}
+		if len(rule.Keywords) > maxIntentKeywords {
+			return errors.New("an inbound intent can have at most 50 keywords")
+		}
+		keywords := make([]string, 0, len(rule.Keywords))
+		for _, keyword := range rule.Keywords {
+			if keyword = normalizeInboundText(keyword); keyword != "" {
+				keywords = append(keywords, keyword)
+			}
+		}
+		rule.Keywords = keywords
+		rule.Reply = strings.TrimSpace(rule.Reply)
+		if len([]rune(rule.Reply)) > maxWhatsAppReplyLength {
+			return errors.New("inbound intent reply must have at most 4096 characters")
+		}
+		settings[intent] = rule
+	}
+	return nil
+}
+
+// renderIntentReply fills the {{name}} variables of a reply
+func renderIntentReply(reply string, vars map[string]string) string {
+	for name, value := range vars {
+		reply = strings.ReplaceAll(reply, "{{"+name+"}}", value)
+	}
+	return reply
+}
+
+// InboundMessage is an inbound WhatsApp message being routed to the handler of its intent
+type InboundMessage struct {
+	OrganizationID uuid.UUID
+	MessageID      uuid.UUID // the logged message
+	From           string    // E.164
+	Body           string
+	Intent         models.InboundIntent
+}
+
+// InboundIntentHandler acts on inbound messages of an intent. It returns the variables of the
+// reply, or ErrInboundIntentUnhandled to pass the message on to the staff.
+type InboundIntentHandler interface {
+	HandleInbound(ctx context.Context, msg *InboundMessage) (map[string]string, error)
+}
+
+// InboundIntentHandlerFunc adapts a function to an InboundIntentHandler
+type InboundIntentHandlerFunc func(ctx context.Context, msg *InboundMessage) (map[string]string, error)
+
+func (f InboundIntentHandlerFunc) HandleInbound(ctx context.Context, msg *InboundMessage) (map[string]string, error) {
+	return f(ctx, msg)
+}
+
+// RegisterIntentHandler sets the handler of an intent, replacing the built-in one
+func (s *WhatsAppService) RegisterIntentHandler(intent models.InboundIntent, handler InboundIntentHandler) {
+	s.intentHandlers[intent] = handler
+}
+
+// registerBuiltinIntentHandlers sets the handlers the service implements itself. Rescheduling is
+// registered by the reschedule link service.
+func (s *WhatsAppService) registerBuiltinIntentHandlers() {
+	s.intentHandlers = map[models.InboundIntent]InboundIntentHandler{
+		models.InboundIntentConfirm:  InboundIntentHandlerFunc(s.handleConfirm),
+		models.InboundIntentCancel:   InboundIntentHandlerFunc(s.handleCancel),
+		models.InboundIntentOptOut:   InboundIntentHandlerFunc(s.handleOptOut),
+		models.InboundIntentOptIn:    InboundIntentHandlerFunc(s.handleOptIn),
+		models.InboundIntentBalance:  InboundIntentHandlerFunc(s.handleBalance),
+		models.InboundIntentFallback: InboundIntentHandlerFunc(s.handleFallback),
+	}
+}
+
+// routeInbound matches an inbound message to an intent, records it on the message, runs its handler
+// and sends the reply. Messages no handler acts on fall back to the staff, unless the organization
+// switched the fallback off.
+func (s *WhatsAppService) routeInbound(ctx context.Context, config *models.NotificationConfig, msg *InboundMessage) error {
+	var settings models.InboundIntentSettings
+	if config != nil {
+		settings = config.InboundIntents
+	}
+
+	msg.Intent = matchInboundIntent(msg.Body, settings)
+	vars, err := s.runIntentHandler(ctx, msg)
+	if errors.Is(err, ErrInboundIntentUnhandled) && msg.Intent != models.InboundIntentFallback {
+		msg.Intent = models.InboundIntentFallback
+		if rule, ok := settings[msg.Intent]; ok && !rule.Enabled {
+			err = nil
+		} else {
+			vars, err = s.runIntentHandler(ctx, msg)
+		}
+	}
+	if errors.Is(err, ErrInboundIntentUnhandled) {
+		err = nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to handle %s message: %w", msg.Intent, err)
+	}
+
+	if _, err := s.db.Pool.Exec(ctx, `
+		UPDATE whatsapp_messages SET intent = $1 WHERE id = $2
+	`, msg.Intent, msg.MessageID); err != nil {
+		return fmt.Errorf("failed to record message intent: %w", err)
+	}
+
+	reply := builtinInboundIntents[msg.Intent].reply
+	if msg.Intent == models.InboundIntentConfirm && config != nil && config.ConfirmationResponseTmpl != nil {
+		reply = *config.ConfirmationResponseTmpl
+	}
+	if rule, ok := settings[msg.Intent]; ok && rule.Reply != "" {
+		reply = rule.Reply
+	}
+	if reply = strings.TrimSpace(renderIntentReply(reply, vars)); reply == "" || config == nil || !config.WhatsAppEnabled {
+		return nil
+	}
+
+	// The inbound message opened the conversation window, so the reply can be free-form. It goes
+	// out even to a number that just opted out, to acknowledge it.
+	_, err = s.send(ctx, msg.OrganizationID, msg.From, reply, nil, func(p WhatsAppProvider) (string, error) {
+		return p.SendText(ctx, msg.From, reply)
+	})
+	if err != nil {
+		log.Printf("[WhatsApp] Failed to reply to %s message from %s: %v", msg.Intent, msg.From, err)
+	}
+	return nil
+}
+
+// runIntentHandler runs the handler of the message's intent
+func (s *WhatsAppService) runIntentHandler(ctx context.Context, msg *InboundMessage) (map[string]string, error) {
+	handler, ok := s.intentHandlers[msg.Intent]
+	if !ok {
+		return nil, ErrInboundIntentUnhandled
+	}
+	return handler.HandleInbound(ctx, msg)
+}
+
+// upcomingSession is the next pending or confirmed session of a phone number
+type upcomingSession struct {
+	ID            uuid.UUID
+	Status        models.SessionStatus
+	ScheduledAt   time.Time
+	TherapistUser *uuid.UUID
+}
+
+// nextSessionForPhone returns the next pending or confirmed session of the patients whose client has
+// the phone number, or nil. Numbers saved before they were stored in E.164 may lack the country
+// code when they are from the organization's country.
+func nextSessionForPhone(ctx context.Context, q rowQuerier, orgID uuid.UUID, from string) (*upcomingSession, error) {
+	country, err := orgDefaultCountry(ctx, q, orgID)
+	if err != nil {
+		return nil, err
+	}
+	var session upcomingSession
+	err = q.QueryRow(ctx, `
+		SELECT s.id, s.status, s.scheduled_at, t.user_id
+		FROM sessions s
+		JOIN patients p ON p.id = s.patient_id
+		JOIN clients c ON c.id = p.client_id
+		LEFT JOIN therapists t ON t.id = s.therapist_id
+		WHERE s.organization_id = $1
+			AND regexp_replace(c.phone, '\D', '', 'g') = ANY($2)
+			AND s.status IN ('pending', 'confirmed')
+			AND s.scheduled_at > NOW()
+			AND s.deleted_at IS NULL
+		ORDER BY s.scheduled_at ASC
+		LIMIT 1
+	`, orgID, phone.MatchKeys(models.NormalizeWhatsAppPhone(from), country)).Scan(
+		&session.ID, &session.Status, &session.ScheduledAt, &session.TherapistUser)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find session: %w", err)
+	}
+	session.ScheduledAt = session.ScheduledAt.In(orgLocation(ctx, q, orgID))
+	return &session, nil
+}
+
+// sessionReplyVars are the variables of replies about a session
+func sessionReplyVars(session *upcomingSession) map[string]string {
+	return map[string]string{
+		"session_date": session.ScheduledAt.Format("02/01/2006"),
+		"session_time": session.ScheduledAt.Format("15:04"),
+	}
+}
+
+// linkMessageToSession ties an inbound message to the session it answered
+func (s *WhatsAppService) linkMessageToSession(ctx context.Context, messageID, sessionID uuid.UUID) error {
+	_, err := s.db.Pool.Exec(ctx, `
+		UPDATE whatsapp_messages SET session_id = $1 WHERE id = $2
+	`, sessionID, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to link message to session: %w", err)
+	}
+	return nil
+}
+
+// handleConfirm confirms the sender's next pending session
+func (s *WhatsAppService) handleConfirm(ctx context.Context, msg *InboundMessage) (map[string]string, error) {
+	session, err := nextSessionForPhone(ctx, s.db.Pool, msg.OrganizationID, msg.From)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, ErrInboundIntentUnhandled
+	}
+	if err := s.linkMessageToSession(ctx, msg.MessageID, session.ID); err != nil {
+		return nil, err
+	}
+
+	if session.Status == models.SessionStatusPending {
+		s.db.Pool.Exec(ctx, `
+			UPDATE sessions SET status = 'confirmed' WHERE id = $1
+		`, session.ID)
+
+		// Update session confirmation record
+		s.db.Pool.Exec(ctx, `
+			UPDATE session_confirmations
+			SET responded_at = NOW(), response = 'confirmed'
+			WHERE session_id = $1 AND response IS NULL
+		`, session.ID)
+	}
+	return sessionReplyVars(session), nil
+}
+
+// handleCancel cancels the sender's next session
+func (s *WhatsAppService) handleCancel(ctx context.Context, msg *InboundMessage) (map[string]string, error) {
+	session, err := nextSessionForPhone(ctx, s.db.Pool, msg.OrganizationID, msg.From)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, ErrInboundIntentUnhandled
+	}
+	if err := s.linkMessageToSession(ctx, msg.MessageID, session.ID); err != nil {
+		return nil, err
+	}
+
+	s.db.Pool.Exec(ctx, `
+		UPDATE sessions SET status = 'cancelled', cancel_reason = 'Cancelled via WhatsApp', cancelled_at = NOW()
+		WHERE id = $1
+	`, session.ID)
+
+	s.db.Pool.Exec(ctx, `
+		UPDATE session_confirmations
+		SET responded_at = NOW(), response = 'cancelled'
+		WHERE session_id = $1 AND response IS NULL
+	`, session.ID)
+	return sessionReplyVars(session), nil
+}
+
+// handleOptOut stops WhatsApp messages to the sender
+func (s *WhatsAppService) handleOptOut(ctx context.Context, msg *InboundMessage) (map[string]string, error) {
+	return nil, s.recordKeywordConsent(ctx, msg, models.ConsentOptedOut)
+}
+
+// handleOptIn takes back the sender's opt-out
+func (s *WhatsAppService) handleOptIn(ctx context.Context, msg *InboundMessage) (map[string]string, error) {
+	return nil, s.recordKeywordConsent(ctx, msg, models.ConsentOptedIn)
+}
+
+// recordKeywordConsent records the WhatsApp consent the sender gave by keyword, with the message
+// it came with
+func (s *WhatsAppService) recordKeywordConsent(ctx context.Context, msg *InboundMessage, status models.ConsentStatus) error {
+	input := ConsentInput{
+		Channel: models.ConsentChannelWhatsApp,
+		Address: models.NormalizeWhatsAppPhone(msg.From),
+		Status:  status,
+		Source:  models.ConsentSourceInboundKeyword,
+	}
+	messageID := msg.MessageID
+	_, err := recordContactConsent(ctx, s.db, msg.OrganizationID, input, nil, &messageID)
+	return err
+}
+
+// handleBalance answers with what the sender's clients owe: unpaid session payments and open
+// project payments
+func (s *WhatsAppService) handleBalance(ctx context.Context, msg *InboundMessage) (map[string]string, error) {
+	country, err := orgDefaultCountry(ctx, s.db.Pool, msg.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	var known bool
+	var sessionCents int64
+	var projects decimal.Decimal
+	err = s.db.Pool.QueryRow(ctx, `
+		WITH senders AS (
+			SELECT id FROM clients
+			WHERE organization_id = $1 AND deleted_at IS NULL
+				AND regexp_replace(phone, '\D', '', 'g') = ANY($2)
+		)
+		SELECT
+			EXISTS (SELECT 1 FROM senders),
+			COALESCE((
+				SELECT SUM(sp.amount_cents)
+				FROM session_payments sp
+				JOIN sessions s ON s.id = sp.session_id
+				JOIN patients p ON p.id = s.patient_id
+				WHERE p.client_id IN (SELECT id FROM senders) AND s.organization_id = $1 AND s.deleted_at IS NULL
+					AND sp.payment_status IN ('unpaid', 'partial') AND sp.amount_cents > 0
+			), 0),
+			COALESCE((
+				SELECT SUM(pay.amount)
+				FROM payments pay
+				JOIN projects pr ON pr.id = pay.project_id AND pr.deleted_at IS NULL
+				JOIN budgets b ON b.id = pr.budget_id
+				JOIN worksheets w ON w.id = b.worksheet_id
+				WHERE w.client_id IN (SELECT id FROM senders) AND pay.organization_id = $1
+					AND pay.deleted_at IS NULL AND pay.status IN ('pending', 'overdue')
+			), 0)
+	`, msg.OrganizationID, phone.MatchKeys(models.NormalizeWhatsAppPhone(msg.From), country)).Scan(&known, &sessionCents, &projects)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute balance: %w", err)
+	}
+	if !known {
+		return nil, ErrInboundIntentUnhandled
+	}
+
+	return map[string]string{
+		"balance": formatEuro(projects.Add(decimal.New(sessionCents, -2))),
+	}, nil
+}
+
+// handleFallback notifies the staff of a message no intent handled: the admins and managers, and
+// the therapist of the sender's next session. Staff who haven't read the previous notification of
+// the conversation aren't notified again.
+func (s *WhatsAppService) handleFallback(ctx context.Context, msg *InboundMessage) (map[string]string, error) {
+	session, err := nextSessionForPhone(ctx, s.db.Pool, msg.OrganizationID, msg.From)
+	if err != nil {
+		return nil, err
+	}
+	var therapistUser *uuid.UUID
+	if session != nil {
+		therapistUser = session.TherapistUser
+	}
+
+	var sender string
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT COALESCE((
+			SELECT name FROM clients
+			WHERE organization_id = $1 AND deleted_at IS NULL AND '+' || regexp_replace(phone, '\D', '', 'g') = $2
+			ORDER BY created_at
+			LIMIT 1
+		), $2)
+	`, msg.OrganizationID, models.NormalizeWhatsAppPhone(msg.From)).Scan(&sender)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find sender: %w", err)
+	}
+
+	preview := []rune(strings.TrimSpace(msg.Body))
+	if len(preview) > 200 {
+		preview = append(preview[:200], '…')
+	}
+	_, err = s.db.Pool.Exec(ctx, `
+		INSERT INTO notifications (user_id, type, title, message, entity_type, entity_id)
+		SELECT u.id, $3, LEFT($4, 255), $5, 'whatsapp_message', $6
+		FROM users u
+		WHERE u.organization_id = $1 AND u.is_active = true AND u.deleted_at IS NULL
+			AND (u.role IN ('admin', 'manager') OR u.id = $7)
+			AND NOT EXISTS (
+				SELECT 1 FROM notifications n
+				JOIN whatsapp_messages m ON m.id = n.entity_id
+				WHERE n.user_id = u.id AND n.type = $3 AND n.is_read = false
+					AND m.organization_id = $1 AND '+' || regexp_replace(m.phone_number, '\D', '', 'g') = $2
+			)
+	`, msg.OrganizationID, models.NormalizeWhatsAppPhone(msg.From), models.NotificationTypeWhatsAppMessage,
+		"Nova mensagem de WhatsApp de "+sender, string(preview), msg.MessageID, therapistUser)
+	if err != nil {
+		return nil, fmt.Errorf("failed to notify staff: %w", err)
+	}
+	return nil, nil
+}
+
+// checkOptOut refuses messages to numbers that opted out of the channel
+func (s *WhatsAppService) checkOptOut(ctx context.Context, orgID uuid.UUID, channel models.ConsentChannel, to string) error {
+	optedOut, err := contactOptedOut(ctx, s.db.Pool, orgID, channel, to)
+	if err != nil {
+		return err
+	}
+	if optedOut {
+		if channel == models.ConsentChannelSMS {
+			return ErrRecipientOptedOutSMS
+		}
+		return ErrRecipientOptedOut
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/controlwise/backend/internal/models"
+)
+
+func TestMatchInboundIntent(t *testing.T) {
+	custom := models.InboundIntentSettings{
+		models.InboundIntentBalance:    {Enabled: true, Keywords: []string{"conta"}},
+		models.InboundIntentReschedule: {Enabled: false},
+	}
+
+	tests := []struct {
+		name     string
+		body     string
+		settings models.InboundIntentSettings
+		want     models.InboundIntent
+	}{
+		{"empty", "  ", nil, models.InboundIntentFallback},
+		{"confirm", "Sim", nil, models.InboundIntentConfirm},
+		{"confirm with text", "sim, obrigado", nil, models.InboundIntentFallback},
+		{"confirm followed by words", "ok até amanhã", nil, models.InboundIntentConfirm},
+		{"cancel", "NÃO!", nil, models.InboundIntentCancel},
+		{"opt out", "STOP", nil, models.InboundIntentOptOut},
+		{"opt out only as the whole message", "stop sending the reminders later", nil, models.InboundIntentFallback},
+		{"opt in", "start.", nil, models.InboundIntentOptIn},
+		{"reschedule", "Posso remarcar para outro dia?", nil, models.InboundIntentReschedule},
+		{"reschedule before cancel", "não posso, queria remarcar", nil, models.InboundIntentReschedule},
+		{"balance", "Qual é o meu saldo?", nil, models.InboundIntentBalance},
+		{"other", "Bom dia, a clínica está aberta hoje?", nil, models.InboundIntentFallback},
+		{"custom keyword", "quero pagar a conta", custom, models.InboundIntentBalance},
+		{"disabled intent", "queria remarcar", custom, models.InboundIntentFallback},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchInboundIntent(tt.body, tt.settings); got != tt.want {
+				t.Errorf("matchInboundIntent(%q) = %s, want %s", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateInboundIntents(t *testing.T) {
+	settings := models.InboundIntentSettings{
+		models.InboundIntentBalance: {Enabled: true, Keywords: []string{" Conta! ", "", "?"}, Reply: "  Deve {{balance}}. "},
+	}
+	if err := validateInboundIntents(settings); err != nil {
+		t.Fatalf("validateInboundIntents() error = %v", err)
+	}
+	rule := settings[models.InboundIntentBalance]
+	if len(rule.Keywords) != 1 || rule.Keywords[0] != "conta" {
+		t.Errorf("keywords = %q, want [conta]", rule.Keywords)
+	}
+	if rule.Reply != "Deve {{balance}}." {
+		t.Errorf("reply = %q, want %q", rule.Reply, "Deve {{balance}}.")
+	}
+
+	if err := validateInboundIntents(models.InboundIntentSettings{"refund": {Enabled: true}}); err == nil {
+		t.Error("validateInboundIntents() accepted an unknown intent")
+	}
+}
@@ -0,0 +1,495 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const metaGraphAPIBase = "https://graph.facebook.com/v21.0"
+
+// metaDefaultTemplateLanguage is the language of approved templates referenced without one
+const metaDefaultTemplateLanguage = "pt_PT"
+
+// maxMetaMediaSize bounds media downloaded from the Cloud API
+const maxMetaMediaSize = 25 << 20
+
+// metaProvider sends WhatsApp messages through Meta's WhatsApp Cloud API from one business phone number
+type metaProvider struct {
+	apiBase       string
+	phoneNumberID string
+	accessToken   string
+	client        *http.Client
+}
+
+func newMetaProvider(phoneNumberID, accessToken string) *metaProvider {
+	return &metaProvider{
+		apiBase:       metaGraphAPIBase,
+		phoneNumberID: phoneNumberID,
+		accessToken:   accessToken,
+		client:        &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (p *metaProvider) Name() string { return "meta" }
+
+// metaRecipient is a phone number as the Cloud API takes it: E.164 digits without the plus
+func metaRecipient(to string) string {
+	return strings.TrimPrefix(models.NormalizeWhatsAppPhone(to), "+")
+}
+
+func (p *metaProvider) SendText(ctx context.Context, to, body string) (string, error) {
+	return p.sendMessage(ctx, map[string]interface{}{
+		"to":   metaRecipient(to),
+		"type": "text",
+		"text": map[string]interface{}{"body": body},
+	})
+}
+
+func (p *metaProvider) SendMedia(ctx context.Context, to, mediaURL, caption string) (string, error) {
+	kind := metaMediaKind(mediaURL)
+	media := map[string]interface{}{"link": mediaURL}
+	if caption != "" && kind != "audio" {
+		media["caption"] = caption
+	}
+	return p.sendMessage(ctx, map[string]interface{}{
+		"to":   metaRecipient(to),
+		"type": kind,
+		kind:   media,
+	})
+}
+
+// SendTemplate sends an approved message template. The reference is the template name, optionally
+// followed by its language ("appointment_reminder:pt_PT").
+func (p *metaProvider) SendTemplate(ctx context.Context, to, templateRef string, variables map[string]string) (string, error) {
+	name, language := parseMetaTemplateRef(templateRef)
+	template := map[string]interface{}{
+		"name":     name,
+		"language": map[string]string{"code": language},
+	}
+	if params := metaTemplateParameters(variables); len(params) > 0 {
+		template["components"] = []map[string]interface{}{{"type": "body", "parameters": params}}
+	}
+	return p.sendMessage(ctx, map[string]interface{}{
+		"to":       metaRecipient(to),
+		"type":     "template",
+		"template": template,
+	})
+}
+
+// CheckCredentials fetches the business phone number the provider sends from
+func (p *metaProvider) CheckCredentials(ctx context.Context) error {
+	var number struct {
+		DisplayPhoneNumber string `json:"display_phone_number"`
+	}
+	if err := p.call(ctx, "GET", "/"+url.PathEscape(p.phoneNumberID)+"?fields=display_phone_number", nil, &number); err != nil {
+		return err
+	}
+	if number.DisplayPhoneNumber == "" {
+		return errors.New("WhatsApp Cloud API phone number not found")
+	}
+	return nil
+}
+
+// downloadMedia fetches a media file received in a message
+func (p *metaProvider) downloadMedia(ctx context.Context, mediaID string) (contentType string, data []byte, err error) {
+	var media struct {
+		URL      string `json:"url"`
+		MimeType string `json:"mime_type"`
+	}
+	if err := p.call(ctx, "GET", "/"+url.PathEscape(mediaID), nil, &media); err != nil {
+		return "", nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", media.URL, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create media request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.accessToken)
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to download media: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("media download returned status %d", resp.StatusCode)
+	}
+
+	data, err = io.ReadAll(io.LimitReader(resp.Body, maxMetaMediaSize))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to download media: %w", err)
+	}
+	return media.MimeType, data, nil
+}
+
+func (p *metaProvider) sendMessage(ctx context.Context, message map[string]interface{}) (string, error) {
+	message["messaging_product"] = "whatsapp"
+	message["recipient_type"] = "individual"
+
+	var resp struct {
+		Messages []struct {
+			ID string `json:"id"`
+		} `json:"messages"`
+	}
+	if err := p.call(ctx, "POST", "/"+url.PathEscape(p.phoneNumberID)+"/messages", message, &resp); err != nil {
+		return "", err
+	}
+	if len(resp.Messages) == 0 {
+		return "", errors.New("WhatsApp Cloud API returned no message ID")
+	}
+	return resp.Messages[0].ID, nil
+}
+
+func (p *metaProvider) call(ctx context.Context, method, path string, payload interface{}, out interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to encode WhatsApp Cloud API request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.apiBase+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create WhatsApp Cloud API request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.accessToken)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call WhatsApp Cloud API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read WhatsApp Cloud API response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		var metaErr struct {
+			Error struct {
+				Message string `json:"message"`
+				Type    string `json:"type"`
+				Code    int    `json:"code"`
+			} `json:"error"`
+		}
+		json.Unmarshal(data, &metaErr)
+		if resp.StatusCode == http.StatusUnauthorized || metaErr.Error.Type == "OAuthException" && metaErr.Error.Code == 190 {
+			return errors.New("WhatsApp Cloud API credentials are invalid")
+		}
+		return fmt.Errorf("WhatsApp Cloud API error: %s (code: %d)", metaErr.Error.Message, metaErr.Error.Code)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode WhatsApp Cloud API response: %w", err)
+	}
+	return nil
+}
+
+// parseMetaTemplateRef splits an approved template reference into its name and language
+func parseMetaTemplateRef(ref string) (name, language string) {
+	name, language, _ = strings.Cut(strings.TrimSpace(ref), ":")
+	if language == "" {
+		language = metaDefaultTemplateLanguage
+	}
+	return name, language
+}
+
+// metaTemplateParameters returns the template variables in placeholder order as body parameters
+func metaTemplateParameters(variables map[string]string) []map[string]string {
+	keys := make([]int, 0, len(variables))
+	for key := range variables {
+		if n, err := strconv.Atoi(key); err == nil {
+			keys = append(keys, n)
+		}
+	}
+	sort.Ints(keys)
+
+	params := make([]map[string]string, 0, len(keys))
+	for _, n := range keys {
+		params = append(params, map[string]string{"type": "text", "text": variables[strconv.Itoa(n)]})
+	}
+	return params
+}
+
+// metaMediaKind returns the Cloud API message type for a file, from the extension of its URL
+func metaMediaKind(mediaURL string) string {
+	ext := mediaURL
+	if u, err := url.Parse(mediaURL); err == nil {
+		ext = u.Path
+	}
+	switch strings.ToLower(path.Ext(ext)) {
+	case ".jpg", ".jpeg", ".png", ".webp":
+		return "image"
+	case ".mp4", ".3gp":
+		return "video"
+	case ".mp3", ".ogg", ".opus", ".aac", ".amr", ".m4a":
+		return "audio"
+	default:
+		return "document"
+	}
+}
+
+// verifyMetaSignature checks the X-Hub-Signature-256 header of a webhook: "sha256=" followed by
+// the HMAC-SHA256 of the payload with the app secret
+func verifyMetaSignature(payload []byte, header, appSecret string) error {
+	sig, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return errors.New("invalid signature header")
+	}
+	decoded, err := hex.DecodeString(sig)
+	if err != nil {
+		return errors.New("invalid signature header")
+	}
+
+	mac := hmac.New(sha256.New, []byte(appSecret))
+	mac.Write(payload)
+	if !hmac.Equal(decoded, mac.Sum(nil)) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+// metaWebhook is the payload of a WhatsApp Business Account webhook
+type metaWebhook struct {
+	Object string `json:"object"`
+	Entry  []struct {
+		Changes []struct {
+			Field string `json:"field"`
+			Value struct {
+				Metadata struct {
+					PhoneNumberID string `json:"phone_number_id"`
+				} `json:"metadata"`
+				Messages []json.RawMessage   `json:"messages"`
+				Statuses []metaMessageStatus `json:"statuses"`
+			} `json:"value"`
+		} `json:"changes"`
+	} `json:"entry"`
+}
+
+// metaMedia is a file received in a message; its ID downloads it
+type metaMedia struct {
+	ID       string `json:"id"`
+	MimeType string `json:"mime_type"`
+	Caption  string `json:"caption"`
+	Filename string `json:"filename"`
+}
+
+// metaInboundMessage is a message a contact sent to the business number
+type metaInboundMessage struct {
+	ID   string `json:"id"`
+	From string `json:"from"` // E.164 digits without the plus
+	Type string `json:"type"`
+	Text *struct {
+		Body string `json:"body"`
+	} `json:"text"`
+	Button *struct {
+		Text string `json:"text"`
+	} `json:"button"`
+	Interactive *struct {
+		ButtonReply *struct {
+			Title string `json:"title"`
+		} `json:"button_reply"`
+		ListReply *struct {
+			Title string `json:"title"`
+		} `json:"list_reply"`
+	} `json:"interactive"`
+	Image    *metaMedia `json:"image"`
+	Video    *metaMedia `json:"video"`
+	Audio    *metaMedia `json:"audio"`
+	Document *metaMedia `json:"document"`
+	Sticker  *metaMedia `json:"sticker"`
+}
+
+// metaMessageStatus is a delivery update of a message the business sent
+type metaMessageStatus struct {
+	ID     string `json:"id"`
+	Status string `json:"status"` // sent, delivered, read, failed
+	Errors []struct {
+		Code  int    `json:"code"`
+		Title string `json:"title"`
+	} `json:"errors"`
+}
+
+// media returns the file attached to the message, if any
+func (m metaInboundMessage) media() *metaMedia {
+	for _, media := range []*metaMedia{m.Image, m.Video, m.Audio, m.Document, m.Sticker} {
+		if media != nil {
+			return media
+		}
+	}
+	return nil
+}
+
+// content returns the text logged for the message. Quick replies log the button pressed and
+// media their caption, after the kind of file.
+func (m metaInboundMessage) content() string {
+	switch {
+	case m.Text != nil:
+		return m.Text.Body
+	case m.Button != nil:
+		return m.Button.Text
+	case m.Interactive != nil && m.Interactive.ButtonReply != nil:
+		return m.Interactive.ButtonReply.Title
+	case m.Interactive != nil && m.Interactive.ListReply != nil:
+		return m.Interactive.ListReply.Title
+	}
+	if media := m.media(); media != nil {
+		label := "[" + m.Type + "]"
+		if media.Filename != "" {
+			label += " " + media.Filename
+		}
+		if media.Caption != "" {
+			label += " " + media.Caption
+		}
+		return label
+	}
+	return "[" + m.Type + "]"
+}
+
+// metaMediaTypes are the inbound message types that carry a file
+var metaMediaTypes = []string{"image", "video", "audio", "document", "sticker"}
+
+// VerifyMetaSubscription answers the Cloud API webhook verification: the verify token configured
+// in the Meta app must be the organization's webhook token
+func (s *WhatsAppService) VerifyMetaSubscription(ctx context.Context, orgID uuid.UUID, mode, verifyToken string) error {
+	config, err := s.GetConfig(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if config == nil || mode != "subscribe" || !hmac.Equal([]byte(verifyToken), []byte(config.WebhookToken)) {
+		return errors.New("invalid verify token")
+	}
+	return nil
+}
+
+// HandleMetaWebhook verifies and processes a Cloud API webhook for an organization. Inbound
+// messages are logged like Twilio's, keeping the message payload for media downloads, and status
+// updates move the sent messages along.
+func (s *WhatsAppService) HandleMetaWebhook(ctx context.Context, orgID uuid.UUID, payload []byte, signature string) error {
+	config, err := s.GetConfig(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if config == nil || config.MetaAppSecretEncrypted == nil {
+		return fmt.Errorf("%w: the Cloud API app secret is not configured", ErrInvalidWebhookSignature)
+	}
+	appSecret, err := s.decrypt(*config.MetaAppSecretEncrypted)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt app secret: %w", err)
+	}
+	if err := verifyMetaSignature(payload, signature, appSecret); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWebhookSignature, err)
+	}
+
+	var webhook metaWebhook
+	if err := json.Unmarshal(payload, &webhook); err != nil {
+		return fmt.Errorf("failed to decode Cloud API webhook: %w", err)
+	}
+
+	for _, entry := range webhook.Entry {
+		for _, change := range entry.Changes {
+			if change.Field != "messages" {
+				continue
+			}
+			// Webhooks of other numbers of the same business account are not ours
+			if config.MetaPhoneNumberID != nil && change.Value.Metadata.PhoneNumberID != *config.MetaPhoneNumberID {
+				continue
+			}
+			for _, raw := range change.Value.Messages {
+				var msg metaInboundMessage
+				if err := json.Unmarshal(raw, &msg); err != nil {
+					return fmt.Errorf("failed to decode Cloud API message: %w", err)
+				}
+				if err := s.processIncoming(ctx, orgID, "+"+msg.From, msg.content(), msg.ID, raw); err != nil {
+					return err
+				}
+			}
+			for _, status := range change.Value.Statuses {
+				if err := s.updateMetaStatus(ctx, status); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// updateMetaStatus applies a Cloud API delivery update, with the reason of failures
+func (s *WhatsAppService) updateMetaStatus(ctx context.Context, status metaMessageStatus) error {
+	if len(status.Errors) == 0 {
+		return s.UpdateMessageStatus(ctx, status.ID, status.Status, "", "")
+	}
+	_, err := s.db.Pool.Exec(ctx, `
+		UPDATE whatsapp_messages
+		SET status = $1, error_code = $2, error_message = $3
+		WHERE message_sid = $4
+	`, mapTwilioStatus(status.Status), strconv.Itoa(status.Errors[0].Code), status.Errors[0].Title, status.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update message status: %w", err)
+	}
+	return nil
+}
+
+// DownloadMedia returns the file of an inbound message received through the Cloud API
+func (s *WhatsAppService) DownloadMedia(ctx context.Context, orgID, messageID uuid.UUID) (contentType string, data []byte, err error) {
+	var raw []byte
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT raw_payload FROM whatsapp_messages
+		WHERE id = $1 AND organization_id = $2 AND direction = 'inbound'
+	`, messageID, orgID).Scan(&raw)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil, errors.New("message not found")
+		}
+		return "", nil, fmt.Errorf("failed to get message: %w", err)
+	}
+
+	var msg metaInboundMessage
+	if raw != nil {
+		if err := json.Unmarshal(raw, &msg); err != nil {
+			return "", nil, fmt.Errorf("failed to decode message: %w", err)
+		}
+	}
+	media := msg.media()
+	if media == nil {
+		return "", nil, errors.New("message has no media")
+	}
+
+	config, err := s.GetConfig(ctx, orgID)
+	if err != nil {
+		return "", nil, err
+	}
+	if config == nil || config.WhatsAppProvider != models.WhatsAppProviderMeta {
+		return "", nil, errors.New("WhatsApp Cloud API credentials not configured")
+	}
+	provider, err := s.providerFor(config)
+	if err != nil {
+		return "", nil, err
+	}
+	return provider.(*metaProvider).downloadMedia(ctx, media.ID)
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+)
+
+func TestVerifyMetaSignature(t *testing.T) {
+	const secret = "app_secret"
+	payload := []byte(`{"object":"whatsapp_business_account","entry":[]}`)
+
+	sign := func(secret string) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(payload)
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	tests := []struct {
+		name    string
+		header  string
+		wantErr bool
+	}{
+		{"valid", sign(secret), false},
+		{"wrong secret", sign("other_secret"), true},
+		{"missing prefix", sign(secret)[len("sha256="):], true},
+		{"malformed", "sha256=zz", true},
+		{"empty", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyMetaSignature(payload, tt.header, secret)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("verifyMetaSignature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseMetaTemplateRef(t *testing.T) {
+	tests := []struct {
+		ref          string
+		wantName     string
+		wantLanguage string
+	}{
+		{"appointment_reminder", "appointment_reminder", "pt_PT"},
+		{"appointment_reminder:en_US", "appointment_reminder", "en_US"},
+		{" invoice_due:pt_BR ", "invoice_due", "pt_BR"},
+		{"invoice_due:", "invoice_due", "pt_PT"},
+	}
+
+	for _, tt := range tests {
+		name, language := parseMetaTemplateRef(tt.ref)
+		if name != tt.wantName || language != tt.wantLanguage {
+			t.Errorf("parseMetaTemplateRef(%q) = %q, %q, want %q, %q", tt.ref, name, language, tt.wantName, tt.wantLanguage)
+		}
+	}
+}
+
+func TestMetaTemplateParameters(t *testing.T) {
+	params := metaTemplateParameters(map[string]string{"2": "14:00", "10": "last", "1": "Ana"})
+	want := []string{"Ana", "14:00", "last"}
+	if len(params) != len(want) {
+		t.Fatalf("metaTemplateParameters() returned %d parameters, want %d", len(params), len(want))
+	}
+	for i, text := range want {
+		if params[i]["text"] != text {
+			t.Errorf("parameter %d = %q, want %q", i+1, params[i]["text"], text)
+		}
+	}
+}
+
+func TestMetaMediaKind(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://cdn.example.com/photo.JPG", "image"},
+		{"https://cdn.example.com/clip.mp4?sig=abc", "video"},
+		{"https://cdn.example.com/note.ogg", "audio"},
+		{"https://cdn.example.com/invoice.pdf", "document"},
+		{"https://cdn.example.com/download", "document"},
+	}
+
+	for _, tt := range tests {
+		if got := metaMediaKind(tt.url); got != tt.want {
+			t.Errorf("metaMediaKind(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestMetaInboundMessageContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    string
+	}{
+		{"text", `{"type":"text","text":{"body":"SIM"}}`, "SIM"},
+		{"quick reply", `{"type":"button","button":{"text":"Confirmar"}}`, "Confirmar"},
+		{"interactive", `{"type":"interactive","interactive":{"button_reply":{"title":"Cancelar"}}}`, "Cancelar"},
+		{"image with caption", `{"type":"image","image":{"id":"m1","caption":"receita"}}`, "[image] receita"},
+		{"document", `{"type":"document","document":{"id":"m2","filename":"exame.pdf"}}`, "[document] exame.pdf"},
+		{"unsupported", `{"type":"location"}`, "[location]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var msg metaInboundMessage
+			if err := json.Unmarshal([]byte(tt.payload), &msg); err != nil {
+				t.Fatal(err)
+			}
+			if got := msg.content(); got != tt.want {
+				t.Errorf("content() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+)
+
+// WhatsAppProvider sends WhatsApp messages through one messaging platform with an organization's
+// credentials. Sends return the provider's message ID, which status updates refer to.
+type WhatsAppProvider interface {
+	// Name is the provider recorded with message costs
+	Name() string
+	SendText(ctx context.Context, to, body string) (string, error)
+	// SendMedia sends the file at a public URL, with an optional caption
+	SendMedia(ctx context.Context, to, mediaURL, caption string) (string, error)
+	// SendTemplate sends a pre-approved template, delivered even outside the customer service
+	// window. Variables are numbered from "1".
+	SendTemplate(ctx context.Context, to, templateRef string, variables map[string]string) (string, error)
+	// CheckCredentials verifies the credentials and the sender with the provider
+	CheckCredentials(ctx context.Context) error
+}
+
+// providerFor returns the organization's WhatsApp provider with its decrypted credentials
+func (s *WhatsAppService) providerFor(config *models.NotificationConfig) (WhatsAppProvider, error) {
+	if config.WhatsAppProvider == models.WhatsAppProviderMeta {
+		if !config.MetaConfigured() {
+			return nil, errors.New("WhatsApp Cloud API credentials not configured")
+		}
+		accessToken, err := s.decrypt(*config.MetaAccessTokenEncrypted)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt access token: %w", err)
+		}
+		return newMetaProvider(*config.MetaPhoneNumberID, accessToken), nil
+	}
+
+	if config.TwilioAccountSID == nil || config.TwilioAuthTokenEncrypted == nil {
+		return nil, errors.New("Twilio credentials not configured")
+	}
+	if config.TwilioWhatsAppNumber == nil || *config.TwilioWhatsAppNumber == "" {
+		return nil, errors.New("Twilio sender number not configured")
+	}
+	authToken, err := s.decrypt(*config.TwilioAuthTokenEncrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt auth token: %w", err)
+	}
+	return newTwilioProvider(*config.TwilioAccountSID, authToken, *config.TwilioWhatsAppNumber), nil
+}
+
+const twilioAPIBase = "https://api.twilio.com/2010-04-01"
+
+// twilioProvider sends WhatsApp messages through the Twilio Messages API
+type twilioProvider struct {
+	apiBase    string
+	accountSID string
+	authToken  string
+	from       string // the sender number
+	client     *http.Client
+}
+
+func newTwilioProvider(accountSID, authToken, from string) *twilioProvider {
+	return &twilioProvider{
+		apiBase:    twilioAPIBase,
+		accountSID: accountSID,
+		authToken:  authToken,
+		from:       from,
+		client:     &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (p *twilioProvider) Name() string { return "twilio" }
+
+func (p *twilioProvider) SendText(ctx context.Context, to, body string) (string, error) {
+	return p.sendWhatsApp(ctx, to, url.Values{"Body": {body}})
+}
+
+func (p *twilioProvider) SendMedia(ctx context.Context, to, mediaURL, caption string) (string, error) {
+	form := url.Values{"MediaUrl": {mediaURL}}
+	if caption != "" {
+		form.Set("Body", caption)
+	}
+	return p.sendWhatsApp(ctx, to, form)
+}
+
+// SendTemplate sends a Twilio Content template; the template reference is its Content SID
+func (p *twilioProvider) SendTemplate(ctx context.Context, to, templateRef string, variables map[string]string) (string, error) {
+	form := url.Values{"ContentSid": {templateRef}}
+	if len(variables) > 0 {
+		data, err := json.Marshal(variables)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode template variables: %w", err)
+		}
+		form.Set("ContentVariables", string(data))
+	}
+	return p.sendWhatsApp(ctx, to, form)
+}
+
+func (p *twilioProvider) sendWhatsApp(ctx context.Context, to string, form url.Values) (string, error) {
+	form.Set("To", formatWhatsAppNumber(to))
+	form.Set("From", formatWhatsAppNumber(p.from))
+	resp, err := p.send(ctx, form)
+	if err != nil {
+		return "", err
+	}
+	return resp.SID, nil
+}
+
+// send posts a message to the Twilio Messages API and returns the provider response
+func (p *twilioProvider) send(ctx context.Context, form url.Values) (*TwilioMessageResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/Accounts/%s/Messages.json", p.apiBase, p.accountSID),
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(p.accountSID, p.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read twilio response: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		var errorResp struct {
+			Message string `json:"message"`
+			Code    int    `json:"code"`
+		}
+		json.Unmarshal(body, &errorResp)
+		return nil, fmt.Errorf("twilio error: %s (code: %d)", errorResp.Message, errorResp.Code)
+	}
+
+	var successResp TwilioMessageResponse
+	if err := json.Unmarshal(body, &successResp); err != nil {
+		return nil, err
+	}
+	return &successResp, nil
+}
+
+// CheckCredentials fetches the Twilio account and checks it is active
+func (p *twilioProvider) CheckCredentials(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/Accounts/%s.json", p.apiBase, p.accountSID), nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(p.accountSID, p.authToken)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("twilio unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusNotFound {
+		return errors.New("twilio credentials are invalid")
+	}
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("twilio error: status %d", resp.StatusCode)
+	}
+
+	var account struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&account); err != nil {
+		return fmt.Errorf("failed to parse twilio response: %w", err)
+	}
+	if account.Status != "" && account.Status != "active" {
+		return fmt.Errorf("twilio account is %s", account.Status)
+	}
+	return nil
+}
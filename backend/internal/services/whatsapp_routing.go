@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// registerWhatsAppNumber maps a sender number to the organization, replacing its previous numbers.
+// A number can only belong to one organization.
+func registerWhatsAppNumber(ctx context.Context, tx pgx.Tx, orgID uuid.UUID, number string) error {
+	var ownerID uuid.UUID
+	err := tx.QueryRow(ctx, `
+		SELECT organization_id FROM whatsapp_numbers WHERE phone_number = $1
+	`, number).Scan(&ownerID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("failed to check WhatsApp number: %w", err)
+	}
+	if err == nil && ownerID != orgID {
+		return errors.New("this WhatsApp number is already used by another organization")
+	}
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM whatsapp_numbers WHERE organization_id = $1 AND phone_number <> $2
+	`, orgID, number)
+	if err != nil {
+		return fmt.Errorf("failed to update WhatsApp numbers: %w", err)
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO whatsapp_numbers (phone_number, organization_id) VALUES ($1, $2)
+		ON CONFLICT (phone_number) DO NOTHING
+	`, number, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to register WhatsApp number: %w", err)
+	}
+	return nil
+}
+
+// ResolveOrganizationByToken returns the organization that owns a webhook token
+func (s *WhatsAppService) ResolveOrganizationByToken(ctx context.Context, token string) (uuid.UUID, error) {
+	var orgID uuid.UUID
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT organization_id FROM notification_configs WHERE webhook_token = $1
+	`, token).Scan(&orgID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, errors.New("unknown webhook token")
+		}
+		return uuid.Nil, fmt.Errorf("failed to resolve webhook token: %w", err)
+	}
+	return orgID, nil
+}
+
+// ResolveOrganizationByNumber returns the organization an inbound message is addressed to,
+// from the number it was sent to
+func (s *WhatsAppService) ResolveOrganizationByNumber(ctx context.Context, to string) (uuid.UUID, error) {
+	var orgID uuid.UUID
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT organization_id FROM whatsapp_numbers WHERE phone_number = $1
+	`, models.NormalizeWhatsAppPhone(to)).Scan(&orgID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, errors.New("no organization uses this WhatsApp number")
+		}
+		return uuid.Nil, fmt.Errorf("failed to resolve WhatsApp number: %w", err)
+	}
+	return orgID, nil
+}
+
+// RotateWebhookToken replaces the organization's webhook token, invalidating the previous URL
+func (s *WhatsAppService) RotateWebhookToken(ctx context.Context, orgID uuid.UUID) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate webhook token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE notification_configs SET webhook_token = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE organization_id = $2
+	`, token, orgID)
+	if err != nil {
+		return "", fmt.Errorf("failed to rotate webhook token: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return "", errors.New("notification config not found")
+	}
+	return token, nil
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// twilioSignature computes the X-Twilio-Signature of a request: the base64 HMAC-SHA1, keyed with
+// the auth token, of the URL Twilio called followed by each POST parameter and its value, sorted
+// by name
+func twilioSignature(authToken, requestURL string, params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	data := requestURL
+	for _, key := range keys {
+		values := append([]string(nil), params[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			data += key + value
+		}
+	}
+
+	mac := hmac.New(sha1.New, []byte(authToken))
+	mac.Write([]byte(data))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verifyTwilioSignature checks the X-Twilio-Signature header of a request
+func verifyTwilioSignature(authToken, requestURL string, params url.Values, signature string) error {
+	if signature == "" {
+		return errors.New("missing signature")
+	}
+	expected := twilioSignature(authToken, requestURL, params)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+// VerifyTwilioRequest checks that a webhook was signed by Twilio with the organization's auth token.
+// Organizations without Twilio credentials can't receive Twilio webhooks.
+func (s *WhatsAppService) VerifyTwilioRequest(ctx context.Context, orgID uuid.UUID, requestURL string, params url.Values, signature string) error {
+	config, err := s.GetConfig(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if config == nil || config.TwilioAuthTokenEncrypted == nil {
+		return fmt.Errorf("%w: Twilio is not configured", ErrInvalidWebhookSignature)
+	}
+	authToken, err := s.decrypt(*config.TwilioAuthTokenEncrypted)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt auth token: %w", err)
+	}
+	if err := verifyTwilioSignature(authToken, requestURL, params, signature); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWebhookSignature, err)
+	}
+	return nil
+}
+
+// ResolveOrganizationByMessage returns the organization that sent a message, for its status callbacks
+func (s *WhatsAppService) ResolveOrganizationByMessage(ctx context.Context, messageSID string) (uuid.UUID, error) {
+	var orgID uuid.UUID
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT organization_id FROM whatsapp_messages WHERE message_sid = $1 LIMIT 1
+	`, messageSID).Scan(&orgID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, errors.New("unknown message")
+		}
+		return uuid.Nil, fmt.Errorf("failed to resolve message: %w", err)
+	}
+	return orgID, nil
+}
+
+// RecordWebhookRefusal keeps a refused webhook in the audit log. Failures are logged: the request
+// is refused either way.
+func (s *WhatsAppService) RecordWebhookRefusal(ctx context.Context, entry *models.WebhookAuditEntry) {
+	log.Printf("[Webhooks] Refused %s webhook on %s (%s): %s", entry.Provider, entry.Endpoint, entry.Outcome, entry.Reason)
+
+	var payload interface{}
+	if len(entry.Payload) > 0 && json.Valid(entry.Payload) {
+		payload = entry.Payload
+	}
+	_, err := s.db.Pool.Exec(ctx, `
+		INSERT INTO webhook_audit_log (organization_id, provider, endpoint, outcome, reason, remote_addr, payload)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, entry.OrganizationID, entry.Provider, entry.Endpoint, entry.Outcome, entry.Reason, entry.RemoteAddr, payload)
+	if err != nil {
+		log.Printf("[Webhooks] Failed to record refused webhook: %v", err)
+	}
+}
+
+// ListWebhookRefusals returns the organization's most recent refused webhooks
+func (s *WhatsAppService) ListWebhookRefusals(ctx context.Context, orgID uuid.UUID, limit int) ([]*models.WebhookAuditEntry, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, organization_id, provider, endpoint, outcome, reason, remote_addr, payload, created_at
+		FROM webhook_audit_log
+		WHERE organization_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, orgID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook audit log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*models.WebhookAuditEntry{}
+	for rows.Next() {
+		var e models.WebhookAuditEntry
+		if err := rows.Scan(&e.ID, &e.OrganizationID, &e.Provider, &e.Endpoint, &e.Outcome, &e.Reason,
+			&e.RemoteAddr, &e.Payload, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook audit entry: %w", err)
+		}
+		entries = append(entries, &e)
+	}
+	return entries, nil
+}
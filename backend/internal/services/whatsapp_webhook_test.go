@@ -0,0 +1,50 @@
+package services
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestVerifyTwilioSignature(t *testing.T) {
+	const authToken = "12345"
+	const requestURL = "https://mycompany.com/myapp.php?foo=1&bar=2"
+	params := url.Values{
+		"CallSid": {"CA1234567890ABCDE"},
+		"Caller":  {"+12349013030"},
+		"Digits":  {"1234"},
+		"From":    {"+12349013030"},
+		"To":      {"+18005551212"},
+	}
+	// The example of Twilio's webhook security documentation
+	const signature = "0/KCTR6DLpKmkAf8muzZqo1nDgQ="
+
+	tampered := url.Values{}
+	for key, values := range params {
+		tampered[key] = values
+	}
+	tampered.Set("Digits", "9999")
+
+	tests := []struct {
+		name       string
+		authToken  string
+		requestURL string
+		params     url.Values
+		signature  string
+		wantErr    bool
+	}{
+		{"valid", authToken, requestURL, params, signature, false},
+		{"wrong auth token", "54321", requestURL, params, signature, true},
+		{"other URL", authToken, "https://mycompany.com/myapp.php", params, signature, true},
+		{"tampered parameter", authToken, requestURL, tampered, signature, true},
+		{"missing signature", authToken, requestURL, params, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyTwilioSignature(tt.authToken, tt.requestURL, tt.params, tt.signature)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("verifyTwilioSignature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
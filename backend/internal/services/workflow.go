@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/i18n"
 	"github.com/controlwise/backend/internal/models"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -210,6 +211,7 @@ func (s *WorkflowService) DuplicateWorkflow(ctx context.Context, id, orgID uuid.
 			ToStateID:            stateMap[trans.ToStateID],
 			Name:                 trans.Name,
 			RequiresConfirmation: trans.RequiresConfirmation,
+			Inputs:               trans.Inputs,
 		}
 		if err := s.CreateTransition(ctx, newTrans); err != nil {
 			return nil, err
@@ -238,12 +240,15 @@ func (s *WorkflowService) DuplicateWorkflow(ctx context.Context, id, orgID uuid.
 		// Copy actions
 		for _, action := range trigger.Actions {
 			newAction := &models.WorkflowAction{
-				TriggerID:    newTrigger.ID,
-				ActionType:   action.ActionType,
-				ActionOrder:  action.ActionOrder,
-				TemplateID:   action.TemplateID,
-				ActionConfig: action.ActionConfig,
-				IsActive:     action.IsActive,
+				TriggerID:           newTrigger.ID,
+				ActionType:          action.ActionType,
+				ActionOrder:         action.ActionOrder,
+				TemplateID:          action.TemplateID,
+				ActionConfig:        action.ActionConfig,
+				Urgency:             action.Urgency,
+				RetryMaxAttempts:    action.RetryMaxAttempts,
+				RetryBackoffSeconds: action.RetryBackoffSeconds,
+				IsActive:            action.IsActive,
 			}
 			if err := s.CreateAction(ctx, newAction); err != nil {
 				return nil, err
@@ -355,7 +360,7 @@ func (s *WorkflowService) ReorderStates(ctx context.Context, workflowID uuid.UUI
 // ListTransitions returns all transitions for a workflow
 func (s *WorkflowService) ListTransitions(ctx context.Context, workflowID uuid.UUID) ([]models.WorkflowTransition, error) {
 	rows, err := s.db.Pool.Query(ctx, `
-		SELECT id, workflow_id, from_state_id, to_state_id, name, requires_confirmation, created_at
+		SELECT id, workflow_id, from_state_id, to_state_id, name, requires_confirmation, inputs, created_at
 		FROM workflow_transitions
 		WHERE workflow_id = $1
 	`, workflowID)
@@ -369,7 +374,7 @@ func (s *WorkflowService) ListTransitions(ctx context.Context, workflowID uuid.U
 		var t models.WorkflowTransition
 		err := rows.Scan(
 			&t.ID, &t.WorkflowID, &t.FromStateID, &t.ToStateID,
-			&t.Name, &t.RequiresConfirmation, &t.CreatedAt,
+			&t.Name, &t.RequiresConfirmation, &t.Inputs, &t.CreatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan transition: %w", err)
@@ -383,12 +388,15 @@ func (s *WorkflowService) ListTransitions(ctx context.Context, workflowID uuid.U
 // CreateTransition creates a new transition
 func (s *WorkflowService) CreateTransition(ctx context.Context, transition *models.WorkflowTransition) error {
 	transition.ID = uuid.New()
+	if transition.Inputs == nil {
+		transition.Inputs = []models.TransitionInput{}
+	}
 
 	_, err := s.db.Pool.Exec(ctx, `
-		INSERT INTO workflow_transitions (id, workflow_id, from_state_id, to_state_id, name, requires_confirmation)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO workflow_transitions (id, workflow_id, from_state_id, to_state_id, name, requires_confirmation, inputs)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`, transition.ID, transition.WorkflowID, transition.FromStateID,
-		transition.ToStateID, transition.Name, transition.RequiresConfirmation)
+		transition.ToStateID, transition.Name, transition.RequiresConfirmation, transition.Inputs)
 
 	if err != nil {
 		return fmt.Errorf("failed to create transition: %w", err)
@@ -414,7 +422,8 @@ func (s *WorkflowService) DeleteTransition(ctx context.Context, id uuid.UUID) er
 func (s *WorkflowService) ListTriggers(ctx context.Context, workflowID uuid.UUID) ([]models.WorkflowTrigger, error) {
 	rows, err := s.db.Pool.Query(ctx, `
 		SELECT id, workflow_id, state_id, transition_id, trigger_type,
-		       time_offset_minutes, time_field, recurring_cron, conditions, is_active, created_at
+		       time_offset_minutes, time_field, recurring_cron, recurring_skip, recurring_last_run_at,
+		       conditions, is_active, created_at
 		FROM workflow_triggers
 		WHERE workflow_id = $1
 	`, workflowID)
@@ -428,7 +437,8 @@ func (s *WorkflowService) ListTriggers(ctx context.Context, workflowID uuid.UUID
 		var t models.WorkflowTrigger
 		err := rows.Scan(
 			&t.ID, &t.WorkflowID, &t.StateID, &t.TransitionID, &t.TriggerType,
-			&t.TimeOffsetMinutes, &t.TimeField, &t.RecurringCron, &t.Conditions, &t.IsActive, &t.CreatedAt,
+			&t.TimeOffsetMinutes, &t.TimeField, &t.RecurringCron, &t.RecurringSkip, &t.RecurringLastRunAt,
+			&t.Conditions, &t.IsActive, &t.CreatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan trigger: %w", err)
@@ -453,12 +463,14 @@ func (s *WorkflowService) GetTriggerByID(ctx context.Context, id uuid.UUID) (*mo
 	var t models.WorkflowTrigger
 	err := s.db.Pool.QueryRow(ctx, `
 		SELECT id, workflow_id, state_id, transition_id, trigger_type,
-		       time_offset_minutes, time_field, recurring_cron, conditions, is_active, created_at
+		       time_offset_minutes, time_field, recurring_cron, recurring_skip, recurring_last_run_at,
+		       conditions, is_active, created_at
 		FROM workflow_triggers
 		WHERE id = $1
 	`, id).Scan(
 		&t.ID, &t.WorkflowID, &t.StateID, &t.TransitionID, &t.TriggerType,
-		&t.TimeOffsetMinutes, &t.TimeField, &t.RecurringCron, &t.Conditions, &t.IsActive, &t.CreatedAt,
+		&t.TimeOffsetMinutes, &t.TimeField, &t.RecurringCron, &t.RecurringSkip, &t.RecurringLastRunAt,
+		&t.Conditions, &t.IsActive, &t.CreatedAt,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -479,15 +491,22 @@ func (s *WorkflowService) GetTriggerByID(ctx context.Context, id uuid.UUID) (*mo
 
 // CreateTrigger creates a new trigger
 func (s *WorkflowService) CreateTrigger(ctx context.Context, trigger *models.WorkflowTrigger) error {
+	if err := validateRecurringTrigger(trigger); err != nil {
+		return err
+	}
+	if _, err := models.ParseTriggerConditions(trigger.Conditions); err != nil {
+		return err
+	}
+
 	trigger.ID = uuid.New()
 	trigger.IsActive = true
 
 	_, err := s.db.Pool.Exec(ctx, `
 		INSERT INTO workflow_triggers (id, workflow_id, state_id, transition_id, trigger_type,
-		                               time_offset_minutes, time_field, recurring_cron, conditions, is_active)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		                               time_offset_minutes, time_field, recurring_cron, recurring_skip, conditions, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`, trigger.ID, trigger.WorkflowID, trigger.StateID, trigger.TransitionID, trigger.TriggerType,
-		trigger.TimeOffsetMinutes, trigger.TimeField, trigger.RecurringCron, trigger.Conditions, trigger.IsActive)
+		trigger.TimeOffsetMinutes, trigger.TimeField, trigger.RecurringCron, trigger.RecurringSkip, trigger.Conditions, trigger.IsActive)
 
 	if err != nil {
 		return fmt.Errorf("failed to create trigger: %w", err)
@@ -497,13 +516,20 @@ func (s *WorkflowService) CreateTrigger(ctx context.Context, trigger *models.Wor
 
 // UpdateTrigger updates an existing trigger
 func (s *WorkflowService) UpdateTrigger(ctx context.Context, id uuid.UUID, trigger *models.WorkflowTrigger) error {
+	if err := validateRecurringTrigger(trigger); err != nil {
+		return err
+	}
+	if _, err := models.ParseTriggerConditions(trigger.Conditions); err != nil {
+		return err
+	}
+
 	result, err := s.db.Pool.Exec(ctx, `
 		UPDATE workflow_triggers
 		SET state_id = $1, transition_id = $2, trigger_type = $3, time_offset_minutes = $4,
-		    time_field = $5, recurring_cron = $6, conditions = $7, is_active = $8
-		WHERE id = $9
+		    time_field = $5, recurring_cron = $6, recurring_skip = $7, conditions = $8, is_active = $9
+		WHERE id = $10
 	`, trigger.StateID, trigger.TransitionID, trigger.TriggerType, trigger.TimeOffsetMinutes,
-		trigger.TimeField, trigger.RecurringCron, trigger.Conditions, trigger.IsActive, id)
+		trigger.TimeField, trigger.RecurringCron, trigger.RecurringSkip, trigger.Conditions, trigger.IsActive, id)
 
 	if err != nil {
 		return fmt.Errorf("failed to update trigger: %w", err)
@@ -531,7 +557,8 @@ func (s *WorkflowService) DeleteTrigger(ctx context.Context, id uuid.UUID) error
 // ListActions returns all actions for a trigger
 func (s *WorkflowService) ListActions(ctx context.Context, triggerID uuid.UUID) ([]models.WorkflowAction, error) {
 	rows, err := s.db.Pool.Query(ctx, `
-		SELECT id, trigger_id, action_type, action_order, template_id, action_config, is_active, created_at
+		SELECT id, trigger_id, action_type, action_order, template_id, action_config, urgency,
+		       retry_max_attempts, retry_backoff_seconds, is_active, created_at
 		FROM workflow_actions
 		WHERE trigger_id = $1
 		ORDER BY action_order ASC
@@ -546,7 +573,8 @@ func (s *WorkflowService) ListActions(ctx context.Context, triggerID uuid.UUID)
 		var a models.WorkflowAction
 		err := rows.Scan(
 			&a.ID, &a.TriggerID, &a.ActionType, &a.ActionOrder,
-			&a.TemplateID, &a.ActionConfig, &a.IsActive, &a.CreatedAt,
+			&a.TemplateID, &a.ActionConfig, &a.Urgency, &a.RetryMaxAttempts, &a.RetryBackoffSeconds,
+			&a.IsActive, &a.CreatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan action: %w", err)
@@ -565,12 +593,19 @@ func (s *WorkflowService) CreateAction(ctx context.Context, action *models.Workf
 	if action.ActionConfig == nil {
 		action.ActionConfig = json.RawMessage("{}")
 	}
+	if err := normalizeActionUrgency(action); err != nil {
+		return err
+	}
+	if err := normalizeActionRetry(action); err != nil {
+		return err
+	}
 
 	_, err := s.db.Pool.Exec(ctx, `
-		INSERT INTO workflow_actions (id, trigger_id, action_type, action_order, template_id, action_config, is_active)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO workflow_actions (id, trigger_id, action_type, action_order, template_id, action_config, urgency,
+		                              retry_max_attempts, retry_backoff_seconds, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`, action.ID, action.TriggerID, action.ActionType, action.ActionOrder,
-		action.TemplateID, action.ActionConfig, action.IsActive)
+		action.TemplateID, action.ActionConfig, action.Urgency, action.RetryMaxAttempts, action.RetryBackoffSeconds, action.IsActive)
 
 	if err != nil {
 		return fmt.Errorf("failed to create action: %w", err)
@@ -580,11 +615,20 @@ func (s *WorkflowService) CreateAction(ctx context.Context, action *models.Workf
 
 // UpdateAction updates an existing action
 func (s *WorkflowService) UpdateAction(ctx context.Context, id uuid.UUID, action *models.WorkflowAction) error {
+	if err := normalizeActionUrgency(action); err != nil {
+		return err
+	}
+	if err := normalizeActionRetry(action); err != nil {
+		return err
+	}
+
 	result, err := s.db.Pool.Exec(ctx, `
 		UPDATE workflow_actions
-		SET action_type = $1, action_order = $2, template_id = $3, action_config = $4, is_active = $5
-		WHERE id = $6
-	`, action.ActionType, action.ActionOrder, action.TemplateID, action.ActionConfig, action.IsActive, id)
+		SET action_type = $1, action_order = $2, template_id = $3, action_config = $4, urgency = $5,
+		    retry_max_attempts = $6, retry_backoff_seconds = $7, is_active = $8
+		WHERE id = $9
+	`, action.ActionType, action.ActionOrder, action.TemplateID, action.ActionConfig, action.Urgency,
+		action.RetryMaxAttempts, action.RetryBackoffSeconds, action.IsActive, id)
 
 	if err != nil {
 		return fmt.Errorf("failed to update action: %w", err)
@@ -595,6 +639,35 @@ func (s *WorkflowService) UpdateAction(ctx context.Context, id uuid.UUID, action
 	return nil
 }
 
+// normalizeActionUrgency defaults the action's urgency to normal and rejects unknown levels
+func normalizeActionUrgency(action *models.WorkflowAction) error {
+	switch action.Urgency {
+	case "":
+		action.Urgency = models.ActionUrgencyNormal
+	case models.ActionUrgencyNormal, models.ActionUrgencyUrgent:
+	default:
+		return fmt.Errorf("invalid urgency '%s'", action.Urgency)
+	}
+	return nil
+}
+
+// normalizeActionRetry defaults the action's retry policy to a single attempt with a one minute backoff
+func normalizeActionRetry(action *models.WorkflowAction) error {
+	if action.RetryMaxAttempts == 0 {
+		action.RetryMaxAttempts = 1
+	}
+	if action.RetryBackoffSeconds == 0 {
+		action.RetryBackoffSeconds = 60
+	}
+	if action.RetryMaxAttempts < 1 || action.RetryMaxAttempts > 10 {
+		return errors.New("retry_max_attempts must be between 1 and 10")
+	}
+	if action.RetryBackoffSeconds < 1 || action.RetryBackoffSeconds > 86400 {
+		return errors.New("retry_backoff_seconds must be between 1 and 86400")
+	}
+	return nil
+}
+
 // DeleteAction deletes an action
 func (s *WorkflowService) DeleteAction(ctx context.Context, id uuid.UUID) error {
 	result, err := s.db.Pool.Exec(ctx, `DELETE FROM workflow_actions WHERE id = $1`, id)
@@ -612,7 +685,7 @@ func (s *WorkflowService) DeleteAction(ctx context.Context, id uuid.UUID) error
 // ListTemplates returns all message templates for an organization
 func (s *WorkflowService) ListTemplates(ctx context.Context, orgID uuid.UUID, channel string) ([]*models.MessageTemplate, error) {
 	query := `
-		SELECT id, organization_id, name, channel, subject, body, variables, is_active, created_at, updated_at
+		SELECT id, organization_id, name, channel, subject, body, variables, whatsapp_content_sid, is_active, created_at, updated_at
 		FROM message_templates
 		WHERE organization_id = $1`
 
@@ -634,7 +707,7 @@ func (s *WorkflowService) ListTemplates(ctx context.Context, orgID uuid.UUID, ch
 		var t models.MessageTemplate
 		err := rows.Scan(
 			&t.ID, &t.OrganizationID, &t.Name, &t.Channel, &t.Subject,
-			&t.Body, &t.Variables, &t.IsActive, &t.CreatedAt, &t.UpdatedAt,
+			&t.Body, &t.Variables, &t.WhatsAppContentSID, &t.IsActive, &t.CreatedAt, &t.UpdatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan template: %w", err)
@@ -649,12 +722,12 @@ func (s *WorkflowService) ListTemplates(ctx context.Context, orgID uuid.UUID, ch
 func (s *WorkflowService) GetTemplateByID(ctx context.Context, id, orgID uuid.UUID) (*models.MessageTemplate, error) {
 	var t models.MessageTemplate
 	err := s.db.Pool.QueryRow(ctx, `
-		SELECT id, organization_id, name, channel, subject, body, variables, is_active, created_at, updated_at
+		SELECT id, organization_id, name, channel, subject, body, variables, whatsapp_content_sid, is_active, created_at, updated_at
 		FROM message_templates
 		WHERE id = $1 AND organization_id = $2
 	`, id, orgID).Scan(
 		&t.ID, &t.OrganizationID, &t.Name, &t.Channel, &t.Subject,
-		&t.Body, &t.Variables, &t.IsActive, &t.CreatedAt, &t.UpdatedAt,
+		&t.Body, &t.Variables, &t.WhatsAppContentSID, &t.IsActive, &t.CreatedAt, &t.UpdatedAt,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -675,10 +748,10 @@ func (s *WorkflowService) CreateTemplate(ctx context.Context, template *models.M
 	}
 
 	_, err := s.db.Pool.Exec(ctx, `
-		INSERT INTO message_templates (id, organization_id, name, channel, subject, body, variables, is_active)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO message_templates (id, organization_id, name, channel, subject, body, variables, whatsapp_content_sid, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`, template.ID, template.OrganizationID, template.Name, template.Channel,
-		template.Subject, template.Body, template.Variables, template.IsActive)
+		template.Subject, template.Body, template.Variables, template.WhatsAppContentSID, template.IsActive)
 
 	if err != nil {
 		return fmt.Errorf("failed to create template: %w", err)
@@ -690,10 +763,11 @@ func (s *WorkflowService) CreateTemplate(ctx context.Context, template *models.M
 func (s *WorkflowService) UpdateTemplate(ctx context.Context, id, orgID uuid.UUID, template *models.MessageTemplate) error {
 	result, err := s.db.Pool.Exec(ctx, `
 		UPDATE message_templates
-		SET name = $1, channel = $2, subject = $3, body = $4, variables = $5, is_active = $6, updated_at = NOW()
-		WHERE id = $7 AND organization_id = $8
+		SET name = $1, channel = $2, subject = $3, body = $4, variables = $5, whatsapp_content_sid = $6,
+			is_active = $7, updated_at = NOW()
+		WHERE id = $8 AND organization_id = $9
 	`, template.Name, template.Channel, template.Subject, template.Body,
-		template.Variables, template.IsActive, id, orgID)
+		template.Variables, template.WhatsAppContentSID, template.IsActive, id, orgID)
 
 	if err != nil {
 		return fmt.Errorf("failed to update template: %w", err)
@@ -837,8 +911,7 @@ func (s *WorkflowService) OnSessionStateChange(ctx context.Context, orgID uuid.U
 		case models.TriggerTypeTimeBefore:
 			// Schedule for time before scheduled_at
 			if trigger.TimeOffsetMinutes != nil {
-				offset := time.Duration(*trigger.TimeOffsetMinutes) * time.Minute
-				executeAt := scheduledAt.Add(-offset)
+				executeAt := s.triggerTime(ctx, orgID, scheduledAt, -*trigger.TimeOffsetMinutes)
 				if executeAt.After(time.Now()) {
 					if err := s.scheduleJob(ctx, orgID, trigger.ID, "session", sessionID, executeAt); err != nil {
 						return fmt.Errorf("failed to schedule time_before trigger: %w", err)
@@ -848,8 +921,7 @@ func (s *WorkflowService) OnSessionStateChange(ctx context.Context, orgID uuid.U
 		case models.TriggerTypeTimeAfter:
 			// Schedule for time after scheduled_at
 			if trigger.TimeOffsetMinutes != nil {
-				offset := time.Duration(*trigger.TimeOffsetMinutes) * time.Minute
-				executeAt := scheduledAt.Add(offset)
+				executeAt := s.triggerTime(ctx, orgID, scheduledAt, *trigger.TimeOffsetMinutes)
 				if err := s.scheduleJob(ctx, orgID, trigger.ID, "session", sessionID, executeAt); err != nil {
 					return fmt.Errorf("failed to schedule time_after trigger: %w", err)
 				}
@@ -955,8 +1027,7 @@ func (s *WorkflowService) OnBudgetStateChange(ctx context.Context, orgID uuid.UU
 		case models.TriggerTypeTimeAfter:
 			// Schedule for time after the state change
 			if trigger.TimeOffsetMinutes != nil {
-				offset := time.Duration(*trigger.TimeOffsetMinutes) * time.Minute
-				executeAt := time.Now().Add(offset)
+				executeAt := s.triggerTime(ctx, orgID, time.Now(), *trigger.TimeOffsetMinutes)
 				if err := s.scheduleJob(ctx, orgID, trigger.ID, "budget", budgetID, executeAt); err != nil {
 					return fmt.Errorf("failed to schedule time_after trigger: %w", err)
 				}
@@ -1018,8 +1089,7 @@ func (s *WorkflowService) OnProjectStateChange(ctx context.Context, orgID uuid.U
 		case models.TriggerTypeTimeAfter:
 			// Schedule for time after the state change
 			if trigger.TimeOffsetMinutes != nil {
-				offset := time.Duration(*trigger.TimeOffsetMinutes) * time.Minute
-				executeAt := time.Now().Add(offset)
+				executeAt := s.triggerTime(ctx, orgID, time.Now(), *trigger.TimeOffsetMinutes)
 				if err := s.scheduleJob(ctx, orgID, trigger.ID, "project", projectID, executeAt); err != nil {
 					return fmt.Errorf("failed to schedule time_after trigger: %w", err)
 				}
@@ -1030,10 +1100,85 @@ func (s *WorkflowService) OnProjectStateChange(ctx context.Context, orgID uuid.U
 	return nil
 }
 
+// OnTaskEvent schedules the project workflow's triggers of the given type (task_assigned, task_due)
+// attached to the project's current state. The jobs run against the task, so templates can use
+// both the task and its project.
+func (s *WorkflowService) OnTaskEvent(ctx context.Context, orgID, projectID, taskID uuid.UUID, projectStatus string, triggerType models.TriggerType) error {
+	workflow, err := s.GetDefaultWorkflow(ctx, orgID, models.WorkflowModuleConstruction, models.WorkflowEntityProject)
+	if err != nil {
+		return fmt.Errorf("failed to get default workflow: %w", err)
+	}
+	if workflow == nil || !workflow.IsActive {
+		return nil
+	}
+
+	var stateID *uuid.UUID
+	for i := range workflow.States {
+		if workflow.States[i].Name == projectStatus {
+			stateID = &workflow.States[i].ID
+			break
+		}
+	}
+	if stateID == nil {
+		return nil
+	}
+
+	for _, trigger := range workflow.Triggers {
+		if trigger.StateID == nil || *trigger.StateID != *stateID || !trigger.IsActive || trigger.TriggerType != triggerType {
+			continue
+		}
+		if err := s.scheduleJob(ctx, orgID, trigger.ID, "task", taskID, time.Now()); err != nil {
+			return fmt.Errorf("failed to schedule %s trigger: %w", triggerType, err)
+		}
+	}
+
+	return nil
+}
+
+// OnEmailBounced schedules the email_bounced triggers of a session's or budget's workflow attached
+// to its current status, so staff can correct the contact's email address
+func (s *WorkflowService) OnEmailBounced(ctx context.Context, orgID uuid.UUID, entityType models.WorkflowEntityType, entityID uuid.UUID, status string) error {
+	module := models.WorkflowModuleConstruction
+	if entityType == models.WorkflowEntitySession {
+		module = models.WorkflowModuleAppointments
+	}
+	workflow, err := s.GetDefaultWorkflow(ctx, orgID, module, entityType)
+	if err != nil {
+		return fmt.Errorf("failed to get default workflow: %w", err)
+	}
+	if workflow == nil || !workflow.IsActive {
+		return nil
+	}
+
+	var stateID *uuid.UUID
+	for i := range workflow.States {
+		if workflow.States[i].Name == status {
+			stateID = &workflow.States[i].ID
+			break
+		}
+	}
+	if stateID == nil {
+		return nil
+	}
+
+	for _, trigger := range workflow.Triggers {
+		if trigger.StateID == nil || *trigger.StateID != *stateID || !trigger.IsActive || trigger.TriggerType != models.TriggerTypeEmailBounced {
+			continue
+		}
+		if err := s.scheduleJob(ctx, orgID, trigger.ID, string(entityType), entityID, time.Now()); err != nil {
+			return fmt.Errorf("failed to schedule email_bounced trigger: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // ============ Default Workflow Creation ============
 
-// CreateDefaultBudgetWorkflow creates the default workflow for budget lifecycle
+// CreateDefaultBudgetWorkflow creates the default workflow for budget lifecycle in the context's locale
 func (s *WorkflowService) CreateDefaultBudgetWorkflow(ctx context.Context, orgID uuid.UUID) (*models.Workflow, error) {
+	locale := i18n.FromContext(ctx)
+
 	// Check if default workflow already exists
 	existing, err := s.GetDefaultWorkflow(ctx, orgID, models.WorkflowModuleConstruction, models.WorkflowEntityBudget)
 	if err != nil {
@@ -1046,8 +1191,8 @@ func (s *WorkflowService) CreateDefaultBudgetWorkflow(ctx context.Context, orgID
 	// Create the workflow
 	workflow := &models.Workflow{
 		OrganizationID: orgID,
-		Name:           "Ciclo de Vida do Orçamento",
-		Description:    stringPtr("Workflow padrão para gestão de orçamentos de construção"),
+		Name:           i18n.T(locale, "Budget Lifecycle"),
+		Description:    stringPtr(i18n.T(locale, "Default workflow for managing construction budgets")),
 		Module:         models.WorkflowModuleConstruction,
 		EntityType:     models.WorkflowEntityBudget,
 		IsActive:       true,
@@ -1066,11 +1211,12 @@ func (s *WorkflowService) CreateDefaultBudgetWorkflow(ctx context.Context, orgID
 		color       string
 		position    int
 	}{
-		{"draft", "Rascunho", "Orçamento em preparação", models.StateTypeInitial, "#6B7280", 0},
-		{"sent", "Enviado", "Orçamento enviado ao cliente", models.StateTypeIntermediate, "#3B82F6", 1},
-		{"approved", "Aprovado", "Orçamento aprovado pelo cliente", models.StateTypeFinal, "#10B981", 2},
-		{"rejected", "Rejeitado", "Orçamento rejeitado pelo cliente", models.StateTypeFinal, "#EF4444", 3},
-		{"expired", "Expirado", "Orçamento expirou sem resposta", models.StateTypeFinal, "#F59E0B", 4},
+		{"draft", "Draft", "Budget being prepared", models.StateTypeInitial, "#6B7280", 0},
+		{"pending_internal_approval", "Internal Approval", "Budget awaits internal approval before being sent", models.StateTypeIntermediate, "#8B5CF6", 1},
+		{"sent", "Sent", "Budget sent to the client", models.StateTypeIntermediate, "#3B82F6", 2},
+		{"approved", "Approved", "Budget approved by the client", models.StateTypeFinal, "#10B981", 3},
+		{"rejected", "Rejected", "Budget rejected by the client", models.StateTypeFinal, "#EF4444", 4},
+		{"expired", "Expired", "Budget expired without a reply", models.StateTypeFinal, "#F59E0B", 5},
 	}
 
 	stateMap := make(map[string]uuid.UUID)
@@ -1078,8 +1224,8 @@ func (s *WorkflowService) CreateDefaultBudgetWorkflow(ctx context.Context, orgID
 		state := &models.WorkflowState{
 			WorkflowID:  workflow.ID,
 			Name:        st.name,
-			DisplayName: st.displayName,
-			Description: stringPtr(st.description),
+			DisplayName: i18n.T(locale, st.displayName),
+			Description: stringPtr(i18n.T(locale, st.description)),
 			StateType:   st.stateType,
 			Color:       stringPtr(st.color),
 			Position:    st.position,
@@ -1095,11 +1241,14 @@ func (s *WorkflowService) CreateDefaultBudgetWorkflow(ctx context.Context, orgID
 		from, to, name       string
 		requiresConfirmation bool
 	}{
-		{"draft", "sent", "Enviar ao Cliente", false},
-		{"sent", "approved", "Cliente Aprova", false},
-		{"sent", "rejected", "Cliente Rejeita", false},
-		{"sent", "expired", "Expirar", false},
-		{"rejected", "draft", "Voltar a Rascunho", false},
+		{"draft", "sent", "Send to Client", false},
+		{"draft", "pending_internal_approval", "Request Internal Approval", false},
+		{"pending_internal_approval", "sent", "Internal Approval Completed", false},
+		{"pending_internal_approval", "draft", "Internal Approval Rejected", false},
+		{"sent", "approved", "Client Approves", false},
+		{"sent", "rejected", "Client Rejects", false},
+		{"sent", "expired", "Expire", false},
+		{"rejected", "draft", "Back to Draft", false},
 	}
 
 	for _, tr := range transitions {
@@ -1107,7 +1256,7 @@ func (s *WorkflowService) CreateDefaultBudgetWorkflow(ctx context.Context, orgID
 			WorkflowID:           workflow.ID,
 			FromStateID:          stateMap[tr.from],
 			ToStateID:            stateMap[tr.to],
-			Name:                 tr.name,
+			Name:                 i18n.T(locale, tr.name),
 			RequiresConfirmation: tr.requiresConfirmation,
 		}
 		if err := s.CreateTransition(ctx, transition); err != nil {
@@ -1115,6 +1264,39 @@ func (s *WorkflowService) CreateDefaultBudgetWorkflow(ctx context.Context, orgID
 		}
 	}
 
+	// Create trigger for when budget needs internal sign-off (on_enter "pending_internal_approval" state)
+	pendingApprovalStateID := stateMap["pending_internal_approval"]
+	triggerPendingApproval := &models.WorkflowTrigger{
+		WorkflowID:  workflow.ID,
+		StateID:     &pendingApprovalStateID,
+		TriggerType: models.TriggerTypeOnEnter,
+		IsActive:    true,
+	}
+	if err := s.CreateTrigger(ctx, triggerPendingApproval); err != nil {
+		return nil, fmt.Errorf("failed to create pending approval trigger: %w", err)
+	}
+
+	// Create notify_user action asking the required roles for sign-off
+	pendingApprovalConfig, err := json.Marshal(map[string]string{
+		"kind":       "approval_request",
+		"recipients": "internal_approvers",
+		"title":      i18n.T(locale, "Internal approval required - {{budget_number}}"),
+		"message":    i18n.T(locale, "workflow.budget.approval_request.message"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal pending approval config: %w", err)
+	}
+	actionPendingApproval := &models.WorkflowAction{
+		TriggerID:    triggerPendingApproval.ID,
+		ActionType:   models.ActionTypeNotifyUser,
+		ActionOrder:  0,
+		IsActive:     true,
+		ActionConfig: pendingApprovalConfig,
+	}
+	if err := s.CreateAction(ctx, actionPendingApproval); err != nil {
+		return nil, fmt.Errorf("failed to create pending approval action: %w", err)
+	}
+
 	// Create trigger for when budget is sent (on_enter "sent" state)
 	sentStateID := stateMap["sent"]
 	triggerSent := &models.WorkflowTrigger{
@@ -1128,20 +1310,68 @@ func (s *WorkflowService) CreateDefaultBudgetWorkflow(ctx context.Context, orgID
 	}
 
 	// Create send_email action for the sent trigger
+	sentConfig, err := json.Marshal(map[string]string{
+		"subject":  i18n.T(locale, "New budget available - {{budget_number}}"),
+		"to_field": "client_email",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal sent config: %w", err)
+	}
 	actionSent := &models.WorkflowAction{
-		TriggerID:   triggerSent.ID,
-		ActionType:  models.ActionTypeSendEmail,
-		ActionOrder: 0,
-		IsActive:    true,
-		ActionConfig: json.RawMessage(`{
-			"subject": "Novo orçamento disponível - {{budget_number}}",
-			"to_field": "client_email"
-		}`),
+		TriggerID:    triggerSent.ID,
+		ActionType:   models.ActionTypeSendEmail,
+		ActionOrder:  0,
+		IsActive:     true,
+		ActionConfig: sentConfig,
 	}
 	if err := s.CreateAction(ctx, actionSent); err != nil {
 		return nil, fmt.Errorf("failed to create sent action: %w", err)
 	}
 
+	// Create follow-up reminders for unanswered budgets (time_after "sent" state).
+	// They stop automatically when the budget is approved, rejected or expires.
+	followUps := []struct {
+		days    int
+		subject string
+		body    string
+	}{
+		{3, "Reminder: budget {{budget_number}}", "workflow.budget.follow_up_3.body"},
+		{7, "Still interested? Budget {{budget_number}}", "workflow.budget.follow_up_7.body"},
+		{14, "Last reminder: budget {{budget_number}}", "workflow.budget.follow_up_14.body"},
+	}
+	for _, fu := range followUps {
+		offset := fu.days * 24 * 60
+		triggerFollowUp := &models.WorkflowTrigger{
+			WorkflowID:        workflow.ID,
+			StateID:           &sentStateID,
+			TriggerType:       models.TriggerTypeTimeAfter,
+			TimeOffsetMinutes: &offset,
+			IsActive:          true,
+		}
+		if err := s.CreateTrigger(ctx, triggerFollowUp); err != nil {
+			return nil, fmt.Errorf("failed to create follow-up trigger: %w", err)
+		}
+
+		config, err := json.Marshal(map[string]string{
+			"subject":  i18n.T(locale, fu.subject),
+			"body":     i18n.T(locale, fu.body),
+			"to_field": "client_email",
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal follow-up config: %w", err)
+		}
+		actionFollowUp := &models.WorkflowAction{
+			TriggerID:    triggerFollowUp.ID,
+			ActionType:   models.ActionTypeSendEmail,
+			ActionOrder:  0,
+			IsActive:     true,
+			ActionConfig: config,
+		}
+		if err := s.CreateAction(ctx, actionFollowUp); err != nil {
+			return nil, fmt.Errorf("failed to create follow-up action: %w", err)
+		}
+	}
+
 	// Create trigger for when budget is approved (on_enter "approved" state)
 	approvedStateID := stateMap["approved"]
 	triggerApproved := &models.WorkflowTrigger{
@@ -1155,15 +1385,19 @@ func (s *WorkflowService) CreateDefaultBudgetWorkflow(ctx context.Context, orgID
 	}
 
 	// Create send_email action for the approved trigger (notify organization)
+	approvedConfig, err := json.Marshal(map[string]string{
+		"subject":  i18n.T(locale, "Budget {{budget_number}} has been approved!"),
+		"to_field": "organization_email",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal approved config: %w", err)
+	}
 	actionApproved := &models.WorkflowAction{
-		TriggerID:   triggerApproved.ID,
-		ActionType:  models.ActionTypeSendEmail,
-		ActionOrder: 0,
-		IsActive:    true,
-		ActionConfig: json.RawMessage(`{
-			"subject": "Orçamento {{budget_number}} foi aprovado!",
-			"to_field": "organization_email"
-		}`),
+		TriggerID:    triggerApproved.ID,
+		ActionType:   models.ActionTypeSendEmail,
+		ActionOrder:  0,
+		IsActive:     true,
+		ActionConfig: approvedConfig,
 	}
 	if err := s.CreateAction(ctx, actionApproved); err != nil {
 		return nil, fmt.Errorf("failed to create approved action: %w", err)
@@ -1172,8 +1406,10 @@ func (s *WorkflowService) CreateDefaultBudgetWorkflow(ctx context.Context, orgID
 	return s.GetWorkflowByID(ctx, workflow.ID, orgID)
 }
 
-// CreateDefaultProjectWorkflow creates the default workflow for project lifecycle
+// CreateDefaultProjectWorkflow creates the default workflow for project lifecycle in the context's locale
 func (s *WorkflowService) CreateDefaultProjectWorkflow(ctx context.Context, orgID uuid.UUID) (*models.Workflow, error) {
+	locale := i18n.FromContext(ctx)
+
 	// Check if default workflow already exists
 	existing, err := s.GetDefaultWorkflow(ctx, orgID, models.WorkflowModuleConstruction, models.WorkflowEntityProject)
 	if err != nil {
@@ -1186,8 +1422,8 @@ func (s *WorkflowService) CreateDefaultProjectWorkflow(ctx context.Context, orgI
 	// Create the workflow
 	workflow := &models.Workflow{
 		OrganizationID: orgID,
-		Name:           "Ciclo de Vida do Projeto",
-		Description:    stringPtr("Workflow padrão para gestão de projetos de construção"),
+		Name:           i18n.T(locale, "Project Lifecycle"),
+		Description:    stringPtr(i18n.T(locale, "Default workflow for managing construction projects")),
 		Module:         models.WorkflowModuleConstruction,
 		EntityType:     models.WorkflowEntityProject,
 		IsActive:       true,
@@ -1206,10 +1442,10 @@ func (s *WorkflowService) CreateDefaultProjectWorkflow(ctx context.Context, orgI
 		color       string
 		position    int
 	}{
-		{"in_progress", "Em Progresso", "Projeto em execução", models.StateTypeInitial, "#3B82F6", 0},
-		{"on_hold", "Em Espera", "Projeto pausado", models.StateTypeIntermediate, "#F59E0B", 1},
-		{"completed", "Concluído", "Projeto finalizado", models.StateTypeFinal, "#10B981", 2},
-		{"cancelled", "Cancelado", "Projeto cancelado", models.StateTypeFinal, "#EF4444", 3},
+		{"in_progress", "In Progress", "Project in execution", models.StateTypeInitial, "#3B82F6", 0},
+		{"on_hold", "On Hold", "Project paused", models.StateTypeIntermediate, "#F59E0B", 1},
+		{"completed", "Completed", "Project finished", models.StateTypeFinal, "#10B981", 2},
+		{"cancelled", "Cancelled", "Project cancelled", models.StateTypeFinal, "#EF4444", 3},
 	}
 
 	stateMap := make(map[string]uuid.UUID)
@@ -1217,8 +1453,8 @@ func (s *WorkflowService) CreateDefaultProjectWorkflow(ctx context.Context, orgI
 		state := &models.WorkflowState{
 			WorkflowID:  workflow.ID,
 			Name:        st.name,
-			DisplayName: st.displayName,
-			Description: stringPtr(st.description),
+			DisplayName: i18n.T(locale, st.displayName),
+			Description: stringPtr(i18n.T(locale, st.description)),
 			StateType:   st.stateType,
 			Color:       stringPtr(st.color),
 			Position:    st.position,
@@ -1234,11 +1470,11 @@ func (s *WorkflowService) CreateDefaultProjectWorkflow(ctx context.Context, orgI
 		from, to, name       string
 		requiresConfirmation bool
 	}{
-		{"in_progress", "on_hold", "Pausar Projeto", false},
-		{"in_progress", "completed", "Concluir Projeto", true},
-		{"in_progress", "cancelled", "Cancelar Projeto", true},
-		{"on_hold", "in_progress", "Retomar Projeto", false},
-		{"on_hold", "cancelled", "Cancelar Projeto", true},
+		{"in_progress", "on_hold", "Pause Project", false},
+		{"in_progress", "completed", "Complete Project", true},
+		{"in_progress", "cancelled", "Cancel Project", true},
+		{"on_hold", "in_progress", "Resume Project", false},
+		{"on_hold", "cancelled", "Cancel Project", true},
 	}
 
 	for _, tr := range transitions {
@@ -1246,7 +1482,7 @@ func (s *WorkflowService) CreateDefaultProjectWorkflow(ctx context.Context, orgI
 			WorkflowID:           workflow.ID,
 			FromStateID:          stateMap[tr.from],
 			ToStateID:            stateMap[tr.to],
-			Name:                 tr.name,
+			Name:                 i18n.T(locale, tr.name),
 			RequiresConfirmation: tr.requiresConfirmation,
 		}
 		if err := s.CreateTransition(ctx, transition); err != nil {
@@ -1267,15 +1503,19 @@ func (s *WorkflowService) CreateDefaultProjectWorkflow(ctx context.Context, orgI
 	}
 
 	// Create send_email action for the completed trigger
+	completedConfig, err := json.Marshal(map[string]string{
+		"subject":  i18n.T(locale, "Project {{project_name}} has been completed!"),
+		"to_field": "client_email",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal completed config: %w", err)
+	}
 	actionCompleted := &models.WorkflowAction{
-		TriggerID:   triggerCompleted.ID,
-		ActionType:  models.ActionTypeSendEmail,
-		ActionOrder: 0,
-		IsActive:    true,
-		ActionConfig: json.RawMessage(`{
-			"subject": "Projeto {{project_name}} foi concluído!",
-			"to_field": "client_email"
-		}`),
+		TriggerID:    triggerCompleted.ID,
+		ActionType:   models.ActionTypeSendEmail,
+		ActionOrder:  0,
+		IsActive:     true,
+		ActionConfig: completedConfig,
 	}
 	if err := s.CreateAction(ctx, actionCompleted); err != nil {
 		return nil, fmt.Errorf("failed to create completed action: %w", err)
@@ -1284,8 +1524,101 @@ func (s *WorkflowService) CreateDefaultProjectWorkflow(ctx context.Context, orgI
 	return s.GetWorkflowByID(ctx, workflow.ID, orgID)
 }
 
-// CreateDefaultTemplates creates default message templates for a module
+// CreateDefaultSessionWorkflow creates the default workflow for session lifecycle in the context's locale.
+// It has no triggers; reminders and other messages are added to it.
+func (s *WorkflowService) CreateDefaultSessionWorkflow(ctx context.Context, orgID uuid.UUID) (*models.Workflow, error) {
+	locale := i18n.FromContext(ctx)
+
+	// Check if default workflow already exists
+	existing, err := s.GetDefaultWorkflow(ctx, orgID, models.WorkflowModuleAppointments, models.WorkflowEntitySession)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	workflow := &models.Workflow{
+		OrganizationID: orgID,
+		Name:           i18n.T(locale, "Session Lifecycle"),
+		Description:    stringPtr(i18n.T(locale, "Default workflow for managing sessions")),
+		Module:         models.WorkflowModuleAppointments,
+		EntityType:     models.WorkflowEntitySession,
+		IsActive:       true,
+		IsDefault:      true,
+	}
+	if err := s.CreateWorkflow(ctx, workflow); err != nil {
+		return nil, fmt.Errorf("failed to create workflow: %w", err)
+	}
+
+	// Create states matching Session status enum
+	states := []struct {
+		name        string
+		displayName string
+		description string
+		stateType   models.StateType
+		color       string
+		position    int
+	}{
+		{"pending", "Pending", "Session awaiting confirmation", models.StateTypeInitial, "#F59E0B", 0},
+		{"confirmed", "Confirmed", "Session confirmed by the patient", models.StateTypeIntermediate, "#3B82F6", 1},
+		{"completed", "Completed", "Session took place", models.StateTypeFinal, "#10B981", 2},
+		{"cancelled", "Cancelled", "Session cancelled", models.StateTypeFinal, "#EF4444", 3},
+		{"no_show", "No Show", "Patient did not attend", models.StateTypeFinal, "#6B7280", 4},
+	}
+
+	stateMap := make(map[string]uuid.UUID)
+	for _, st := range states {
+		state := &models.WorkflowState{
+			WorkflowID:  workflow.ID,
+			Name:        st.name,
+			DisplayName: i18n.T(locale, st.displayName),
+			Description: stringPtr(i18n.T(locale, st.description)),
+			StateType:   st.stateType,
+			Color:       stringPtr(st.color),
+			Position:    st.position,
+		}
+		if err := s.CreateState(ctx, state); err != nil {
+			return nil, fmt.Errorf("failed to create state %s: %w", st.name, err)
+		}
+		stateMap[st.name] = state.ID
+	}
+
+	// Create transitions
+	transitions := []struct {
+		from, to, name       string
+		requiresConfirmation bool
+	}{
+		{"pending", "confirmed", "Confirm Session", false},
+		{"pending", "completed", "Complete Session", false},
+		{"pending", "cancelled", "Cancel Session", true},
+		{"pending", "no_show", "Mark No Show", true},
+		{"confirmed", "completed", "Complete Session", false},
+		{"confirmed", "cancelled", "Cancel Session", true},
+		{"confirmed", "no_show", "Mark No Show", true},
+	}
+
+	for _, tr := range transitions {
+		transition := &models.WorkflowTransition{
+			WorkflowID:           workflow.ID,
+			FromStateID:          stateMap[tr.from],
+			ToStateID:            stateMap[tr.to],
+			Name:                 i18n.T(locale, tr.name),
+			RequiresConfirmation: tr.requiresConfirmation,
+		}
+		if err := s.CreateTransition(ctx, transition); err != nil {
+			return nil, fmt.Errorf("failed to create transition %s: %w", tr.name, err)
+		}
+	}
+
+	return s.GetWorkflowByID(ctx, workflow.ID, orgID)
+}
+
+// CreateDefaultTemplates creates default message templates for a module in the context's locale.
+// Bodies are catalog keys, names, subjects and variable descriptions are English message IDs.
 func (s *WorkflowService) CreateDefaultTemplates(ctx context.Context, orgID uuid.UUID, module string) error {
+	locale := i18n.FromContext(ctx)
+
 	var templates []struct {
 		name    string
 		channel models.MessageChannel
@@ -1304,87 +1637,64 @@ func (s *WorkflowService) CreateDefaultTemplates(ctx context.Context, orgID uuid
 			vars    []models.TemplateVariable
 		}{
 			{
-				name:    "Orçamento Enviado",
+				name:    "Budget Sent",
 				channel: models.MessageChannelEmail,
-				subject: "Novo Orçamento - {{budget_number}}",
-				body: `Caro(a) {{client_name}},
-
-Enviamos em anexo o orçamento {{budget_number}} para o projeto "{{project_name}}".
-
-Valor Total: {{budget_total}}€
-
-Para visualizar ou aprovar o orçamento, aceda ao seguinte link:
-{{budget_link}}
-
-Ficamos ao dispor para qualquer esclarecimento.
-
-Com os melhores cumprimentos,
-{{organization_name}}`,
+				subject: "New Budget - {{budget_number}}",
+				body:    "template.budget_sent.body",
 				vars: []models.TemplateVariable{
-					{Name: "client_name", Description: "Nome do cliente"},
-					{Name: "budget_number", Description: "Número do orçamento"},
-					{Name: "project_name", Description: "Nome do projeto"},
-					{Name: "budget_total", Description: "Valor total do orçamento"},
-					{Name: "budget_link", Description: "Link para visualizar o orçamento"},
-					{Name: "organization_name", Description: "Nome da organização"},
+					{Name: "client_name", Description: "Client name"},
+					{Name: "budget_number", Description: "Budget number"},
+					{Name: "project_name", Description: "Project name"},
+					{Name: "budget_total", Description: "Budget total"},
+					{Name: "budget_link", Description: "Link to view the budget"},
+					{Name: "organization_name", Description: "Organization name"},
 				},
 			},
 			{
-				name:    "Orçamento Aprovado",
+				name:    "Budget Approved",
 				channel: models.MessageChannelEmail,
-				subject: "Orçamento {{budget_number}} Aprovado!",
-				body: `O orçamento {{budget_number}} para o cliente {{client_name}} foi aprovado!
-
-Projeto: {{project_name}}
-Valor: {{budget_total}}€
-
-O projeto pode agora ser iniciado.
-
-{{organization_name}}`,
+				subject: "Budget {{budget_number}} Approved!",
+				body:    "template.budget_approved.body",
 				vars: []models.TemplateVariable{
-					{Name: "client_name", Description: "Nome do cliente"},
-					{Name: "budget_number", Description: "Número do orçamento"},
-					{Name: "project_name", Description: "Nome do projeto"},
-					{Name: "budget_total", Description: "Valor total do orçamento"},
-					{Name: "organization_name", Description: "Nome da organização"},
+					{Name: "client_name", Description: "Client name"},
+					{Name: "budget_number", Description: "Budget number"},
+					{Name: "project_name", Description: "Project name"},
+					{Name: "budget_total", Description: "Budget total"},
+					{Name: "organization_name", Description: "Organization name"},
 				},
 			},
 			{
-				name:    "Orçamento Rejeitado",
+				name:    "Budget Rejected",
 				channel: models.MessageChannelEmail,
-				subject: "Orçamento {{budget_number}} Rejeitado",
-				body: `O orçamento {{budget_number}} para o cliente {{client_name}} foi rejeitado.
-
-Projeto: {{project_name}}
-Valor: {{budget_total}}€
-
-Poderá ser necessário rever o orçamento e reenviar ao cliente.
-
-{{organization_name}}`,
+				subject: "Budget {{budget_number}} Rejected",
+				body:    "template.budget_rejected.body",
+				vars: []models.TemplateVariable{
+					{Name: "client_name", Description: "Client name"},
+					{Name: "budget_number", Description: "Budget number"},
+					{Name: "project_name", Description: "Project name"},
+					{Name: "budget_total", Description: "Budget total"},
+					{Name: "organization_name", Description: "Organization name"},
+				},
+			},
+			{
+				name:    "Budget Reminder",
+				channel: models.MessageChannelWhatsApp,
+				body:    "template.budget_reminder.body",
 				vars: []models.TemplateVariable{
-					{Name: "client_name", Description: "Nome do cliente"},
-					{Name: "budget_number", Description: "Número do orçamento"},
-					{Name: "project_name", Description: "Nome do projeto"},
-					{Name: "budget_total", Description: "Valor total do orçamento"},
-					{Name: "organization_name", Description: "Nome da organização"},
+					{Name: "client_name", Description: "Client name"},
+					{Name: "budget_number", Description: "Budget number"},
+					{Name: "budget_total", Description: "Budget total"},
 				},
 			},
 			{
-				name:    "Projeto Concluído",
+				name:    "Project Completed",
 				channel: models.MessageChannelEmail,
-				subject: "Projeto {{project_name}} Concluído",
-				body: `Caro(a) {{client_name}},
-
-Temos o prazer de informar que o projeto "{{project_name}}" foi concluído com sucesso!
-
-Agradecemos a sua confiança e estamos ao dispor para futuros projetos.
-
-Com os melhores cumprimentos,
-{{organization_name}}`,
+				subject: "Project {{project_name}} Completed",
+				body:    "template.project_completed.body",
 				vars: []models.TemplateVariable{
-					{Name: "client_name", Description: "Nome do cliente"},
-					{Name: "project_name", Description: "Nome do projeto"},
-					{Name: "organization_name", Description: "Nome da organização"},
+					{Name: "client_name", Description: "Client name"},
+					{Name: "project_name", Description: "Project name"},
+					{Name: "organization_name", Description: "Organization name"},
 				},
 			},
 		}
@@ -1398,105 +1708,60 @@ Com os melhores cumprimentos,
 			vars    []models.TemplateVariable
 		}{
 			{
-				name:    "Lembrete 24h",
+				name:    "24h Reminder",
 				channel: models.MessageChannelWhatsApp,
-				subject: "",
-				body: `Olá {{patient_name}}! 👋
-
-Lembramos que tem uma consulta agendada para amanhã:
-
-📅 Data: {{session_date}}
-🕐 Hora: {{session_time}}
-👤 Terapeuta: {{therapist_name}}
-
-Por favor, confirme a sua presença respondendo a esta mensagem.
-
-{{organization_name}}`,
+				body:    "template.reminder_24h.body",
 				vars: []models.TemplateVariable{
-					{Name: "patient_name", Description: "Nome do paciente"},
-					{Name: "session_date", Description: "Data da sessão"},
-					{Name: "session_time", Description: "Hora da sessão"},
-					{Name: "therapist_name", Description: "Nome do terapeuta"},
-					{Name: "organization_name", Description: "Nome da organização"},
+					{Name: "patient_name", Description: "Patient name"},
+					{Name: "session_date", Description: "Session date"},
+					{Name: "session_time", Description: "Session time"},
+					{Name: "therapist_name", Description: "Therapist name"},
+					{Name: "organization_name", Description: "Organization name"},
 				},
 			},
 			{
-				name:    "Lembrete 2h",
+				name:    "2h Reminder",
 				channel: models.MessageChannelWhatsApp,
-				subject: "",
-				body: `Olá {{patient_name}}! 👋
-
-A sua consulta é daqui a 2 horas:
-
-🕐 {{session_time}}
-👤 {{therapist_name}}
-
-Esperamos por si!
-
-{{organization_name}}`,
+				body:    "template.reminder_2h.body",
 				vars: []models.TemplateVariable{
-					{Name: "patient_name", Description: "Nome do paciente"},
-					{Name: "session_time", Description: "Hora da sessão"},
-					{Name: "therapist_name", Description: "Nome do terapeuta"},
-					{Name: "organization_name", Description: "Nome da organização"},
+					{Name: "patient_name", Description: "Patient name"},
+					{Name: "session_time", Description: "Session time"},
+					{Name: "therapist_name", Description: "Therapist name"},
+					{Name: "organization_name", Description: "Organization name"},
 				},
 			},
 			{
-				name:    "Sessão Confirmada",
+				name:    "Session Confirmed",
 				channel: models.MessageChannelWhatsApp,
-				subject: "",
-				body: `Olá {{patient_name}}! ✅
-
-A sua consulta está confirmada:
-
-📅 Data: {{session_date}}
-🕐 Hora: {{session_time}}
-👤 Terapeuta: {{therapist_name}}
-
-Até breve!
-{{organization_name}}`,
+				body:    "template.session_confirmed.body",
 				vars: []models.TemplateVariable{
-					{Name: "patient_name", Description: "Nome do paciente"},
-					{Name: "session_date", Description: "Data da sessão"},
-					{Name: "session_time", Description: "Hora da sessão"},
-					{Name: "therapist_name", Description: "Nome do terapeuta"},
-					{Name: "organization_name", Description: "Nome da organização"},
+					{Name: "patient_name", Description: "Patient name"},
+					{Name: "session_date", Description: "Session date"},
+					{Name: "session_time", Description: "Session time"},
+					{Name: "therapist_name", Description: "Therapist name"},
+					{Name: "organization_name", Description: "Organization name"},
 				},
 			},
 			{
-				name:    "Lembrete Pagamento",
+				name:    "Payment Reminder",
 				channel: models.MessageChannelWhatsApp,
-				subject: "",
-				body: `Olá {{patient_name}}! 👋
-
-Gostaríamos de lembrar que tem sessões pendentes de pagamento no valor de {{amount}}€.
-
-Por favor, regularize o pagamento na próxima consulta ou contacte-nos para mais informações.
-
-Obrigado,
-{{organization_name}}`,
+				body:    "template.payment_reminder.body",
 				vars: []models.TemplateVariable{
-					{Name: "patient_name", Description: "Nome do paciente"},
-					{Name: "amount", Description: "Valor em dívida"},
-					{Name: "organization_name", Description: "Nome da organização"},
+					{Name: "patient_name", Description: "Patient name"},
+					{Name: "amount", Description: "Amount due"},
+					{Name: "organization_name", Description: "Organization name"},
 				},
 			},
 			{
-				name:    "Sessão Cancelada",
+				name:    "Session Cancelled",
 				channel: models.MessageChannelWhatsApp,
-				subject: "",
-				body: `Olá {{patient_name}},
-
-A sua consulta do dia {{session_date}} às {{session_time}} foi cancelada.
-
-Para reagendar, por favor contacte-nos.
-
-{{organization_name}}`,
+				body:    "template.session_cancelled.body",
 				vars: []models.TemplateVariable{
-					{Name: "patient_name", Description: "Nome do paciente"},
-					{Name: "session_date", Description: "Data da sessão"},
-					{Name: "session_time", Description: "Hora da sessão"},
-					{Name: "organization_name", Description: "Nome da organização"},
+					{Name: "patient_name", Description: "Patient name"},
+					{Name: "session_date", Description: "Session date"},
+					{Name: "session_time", Description: "Session time"},
+					{Name: "cancellation_policy", Description: "Cancellation policy outcome"},
+					{Name: "organization_name", Description: "Organization name"},
 				},
 			},
 		}
@@ -1507,6 +1772,15 @@ Para reagendar, por favor contacte-nos.
 
 	// Create templates (skip if already exists)
 	for _, t := range templates {
+		t.name = i18n.T(locale, t.name)
+		t.body = i18n.T(locale, t.body)
+		if t.subject != "" {
+			t.subject = i18n.T(locale, t.subject)
+		}
+		for i := range t.vars {
+			t.vars[i].Description = i18n.T(locale, t.vars[i].Description)
+		}
+
 		// Check if template already exists
 		var exists bool
 		err := s.db.Pool.QueryRow(ctx, `
@@ -1792,79 +2066,122 @@ func (s *WorkflowService) TestWorkflowTrigger(ctx context.Context, orgID, workfl
 
 	// Process each action
 	for i := range fullTrigger.Actions {
-		action := &fullTrigger.Actions[i]
-		actionResult := &ActionTestResult{
-			Action:     action,
-			ActionType: string(action.ActionType),
-		}
-
-		switch action.ActionType {
-		case models.ActionTypeSendWhatsApp, models.ActionTypeSendEmail:
-			// Get template if specified
-			if action.TemplateID != nil {
-				template, err := s.GetTemplateByID(ctx, *action.TemplateID, orgID)
-				if err == nil {
-					actionResult.Template = template
-					// Render with sample data
-					actionResult.RenderedBody = renderTemplateString(template.Body, sampleData)
-					if template.Subject != nil {
-						actionResult.RenderedSubject = renderTemplateString(*template.Subject, sampleData)
-					}
-				}
-			} else if action.ActionConfig != nil {
-				// Use inline config
-				config := parseActionConfigJSON(action.ActionConfig)
-				if subject, ok := config["subject"].(string); ok {
-					actionResult.RenderedSubject = renderTemplateString(subject, sampleData)
-				}
-				if body, ok := config["body"].(string); ok {
-					actionResult.RenderedBody = renderTemplateString(body, sampleData)
+		result.Actions = append(result.Actions, s.previewAction(ctx, orgID, &fullTrigger.Actions[i], sampleData))
+	}
+
+	return result, nil
+}
+
+// previewAction renders what an action would send or change for the given entity data
+func (s *WorkflowService) previewAction(ctx context.Context, orgID uuid.UUID, action *models.WorkflowAction, sampleData map[string]interface{}) *ActionTestResult {
+	actionResult := &ActionTestResult{
+		Action:     action,
+		ActionType: string(action.ActionType),
+	}
+
+	switch action.ActionType {
+	case models.ActionTypeSendWhatsApp, models.ActionTypeSendEmail, models.ActionTypeOfferSlot:
+		// Get template if specified
+		if action.TemplateID != nil {
+			template, err := s.GetTemplateByID(ctx, *action.TemplateID, orgID)
+			if err == nil {
+				actionResult.Template = template
+				// Render with sample data
+				actionResult.RenderedBody = renderTemplateString(template.Body, sampleData)
+				if template.Subject != nil {
+					actionResult.RenderedSubject = renderTemplateString(*template.Subject, sampleData)
 				}
 			}
+		} else if action.ActionConfig != nil {
+			// Use inline config
+			config := parseActionConfigJSON(action.ActionConfig)
+			if subject, ok := config["subject"].(string); ok {
+				actionResult.RenderedSubject = renderTemplateString(subject, sampleData)
+			}
+			if body, ok := config["body"].(string); ok {
+				actionResult.RenderedBody = renderTemplateString(body, sampleData)
+			}
+		}
 
-			// Determine recipient
-			if action.ActionType == models.ActionTypeSendEmail {
-				if config := parseActionConfigJSON(action.ActionConfig); config != nil {
-					if toField, ok := config["to_field"].(string); ok {
-						if email, ok := sampleData[toField].(string); ok {
-							actionResult.Recipient = email
-						} else {
-							actionResult.Recipient = toField + " (campo não encontrado)"
-						}
+		// Determine recipient
+		if action.ActionType == models.ActionTypeSendEmail {
+			if config := parseActionConfigJSON(action.ActionConfig); config != nil {
+				if toField, ok := config["to_field"].(string); ok {
+					if email, ok := sampleData[toField].(string); ok {
+						actionResult.Recipient = email
+					} else {
+						actionResult.Recipient = toField + " (campo não encontrado)"
 					}
 				}
-			} else {
-				// WhatsApp - use patient_phone or client_phone
-				if phone, ok := sampleData["patient_phone"].(string); ok {
-					actionResult.Recipient = phone
-				} else if phone, ok := sampleData["client_phone"].(string); ok {
-					actionResult.Recipient = phone
-				}
 			}
+		} else if action.ActionType == models.ActionTypeOfferSlot {
+			actionResult.Recipient = "Próximo paciente da lista de espera"
+		} else {
+			// WhatsApp - use patient_phone or client_phone
+			if phone, ok := sampleData["patient_phone"].(string); ok {
+				actionResult.Recipient = phone
+			} else if phone, ok := sampleData["client_phone"].(string); ok {
+				actionResult.Recipient = phone
+			}
+		}
 
-		case models.ActionTypeUpdateField:
-			if action.ActionConfig != nil {
-				config := parseActionConfigJSON(action.ActionConfig)
-				if field, ok := config["field"].(string); ok {
-					if value, ok := config["value"].(string); ok {
-						actionResult.RenderedBody = fmt.Sprintf("Campo '%s' será atualizado para '%s'", field, value)
-					}
+	case models.ActionTypeUpdateField:
+		if action.ActionConfig != nil {
+			config := parseActionConfigJSON(action.ActionConfig)
+			if field, ok := config["field"].(string); ok {
+				if value, ok := config["value"].(string); ok {
+					actionResult.RenderedBody = fmt.Sprintf("Campo '%s' será atualizado para '%s'", field, value)
 				}
 			}
+		}
+
+	case models.ActionTypeCreateTask:
+		if action.ActionConfig != nil {
+			config := parseActionConfigJSON(action.ActionConfig)
+			if title, ok := config["title"].(string); ok {
+				actionResult.RenderedBody = renderTemplateString(title, sampleData)
+			}
+		}
 
-		case models.ActionTypeCreateTask:
-			if action.ActionConfig != nil {
-				config := parseActionConfigJSON(action.ActionConfig)
-				if title, ok := config["title"].(string); ok {
-					actionResult.RenderedBody = renderTemplateString(title, sampleData)
+	case models.ActionTypeTransitionEntity:
+		if action.ActionConfig != nil {
+			config := parseActionConfigJSON(action.ActionConfig)
+			switch config["target"] {
+			case models.ChainTargetProject:
+				actionResult.RenderedBody = "Projeto do orçamento será criado e iniciado"
+				if status, ok := config["to_status"].(string); ok && status != "" {
+					actionResult.RenderedBody = fmt.Sprintf("Projeto do orçamento passará para '%s'", status)
+				}
+			case models.ChainTargetSessionPayment:
+				actionResult.RenderedBody = "Pagamento da sessão será criado"
+				if days, ok := config["dunning_after_days"].(float64); ok && days > 0 {
+					actionResult.RenderedBody += fmt.Sprintf(", com cobrança após %d dias", int(days))
 				}
 			}
 		}
 
-		result.Actions = append(result.Actions, actionResult)
-	}
+	case models.ActionTypeCreateProject:
+		actionResult.RenderedBody = "Projeto do orçamento será criado com uma tarefa por item"
+		if action.ActionConfig != nil {
+			config := parseActionConfigJSON(action.ActionConfig)
+			switch config["items_as"] {
+			case BudgetItemsAsMilestones:
+				actionResult.RenderedBody = "Projeto do orçamento será criado com um marco por item"
+			case BudgetItemsAsNone:
+				actionResult.RenderedBody = "Projeto do orçamento será criado"
+			}
+		}
 
-	return result, nil
+	case models.ActionTypeSendChat:
+		if action.ActionConfig != nil {
+			config := parseActionConfigJSON(action.ActionConfig)
+			if message, ok := config["message"].(string); ok {
+				actionResult.RenderedBody = renderTemplateString(message, sampleData)
+			}
+			actionResult.Recipient = "Canais da equipa (Slack/Teams)"
+		}
+	}
+	return actionResult
 }
 
 // GetSampleDataForEntityType returns sample data for testing
@@ -1880,6 +2197,12 @@ func GetSampleDataForEntityType(entityType string) map[string]interface{} {
 			"session_time":      "14:30",
 			"session_type":      "Consulta Regular",
 			"amount":            "50.00",
+			"cancellation_fee":  "25.00",
+			"cancellation_policy": "Cancelamento com menos de 24h de antecedência: taxa de 50% (25.00€).",
+			"payment_link":      "https://checkout.stripe.com/c/pay/cs_test_123",
+			"reschedule_link":   "https://app.controlwise.pt/reschedule/abc123",
+			"offer_link":        "https://app.controlwise.pt/waiting-list/offers/abc123",
+			"offer_expires_at":  "15/01/2025 12:30",
 			"organization_name": "Clínica Exemplo",
 			"organization_email": "clinica@exemplo.com",
 		}
@@ -1893,6 +2216,7 @@ func GetSampleDataForEntityType(entityType string) map[string]interface{} {
 			"budget_total":       "15000.00",
 			"budget_link":        "https://app.controlwise.pt/budgets/123",
 			"approval_link":      "https://app.controlwise.pt/budgets/123/approve",
+			"payment_link":       "https://checkout.stripe.com/c/pay/cs_test_123",
 			"organization_name":  "Construções ABC",
 			"organization_email": "info@construcoes-abc.pt",
 		}
@@ -1907,6 +2231,20 @@ func GetSampleDataForEntityType(entityType string) map[string]interface{} {
 			"organization_name":  "Construções ABC",
 			"organization_email": "info@construcoes-abc.pt",
 		}
+	case "invoice":
+		return map[string]interface{}{
+			"client_name":        "Ana Ferreira",
+			"client_email":       "ana.ferreira@email.com",
+			"client_phone":       "+351934567890",
+			"invoice_number":     "FT 2025/12",
+			"invoice_type":       "Fatura",
+			"invoice_status":     "issued",
+			"invoice_total":      "1230.00",
+			"invoice_issue_date": "15/01/2025",
+			"invoice_due_date":   "14/02/2025",
+			"organization_name":  "Construções ABC",
+			"organization_email": "info@construcoes-abc.pt",
+		}
 	default:
 		return map[string]interface{}{
 			"name":  "Cliente Exemplo",
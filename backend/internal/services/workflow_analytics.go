@@ -0,0 +1,316 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// WorkflowStateAnalytics is how entities moved through one state of a workflow
+type WorkflowStateAnalytics struct {
+	State           string   `json:"state"`
+	DisplayName     string   `json:"display_name"`
+	Entered         int      `json:"entered"`
+	Exited          int      `json:"exited"`
+	AvgDwellSeconds *float64 `json:"avg_dwell_seconds"` // over the entries that have left the state
+}
+
+// WorkflowTriggerAnalytics counts how often a trigger ran
+type WorkflowTriggerAnalytics struct {
+	TriggerID   uuid.UUID          `json:"trigger_id"`
+	TriggerType models.TriggerType `json:"trigger_type"`
+	State       *string            `json:"state"`
+	Fired       int                `json:"fired"`
+	Skipped     int                `json:"skipped"` // conditions did not match
+}
+
+// WorkflowActionAnalytics counts the outcomes of an action. Every retry attempt is counted.
+type WorkflowActionAnalytics struct {
+	ActionID    uuid.UUID         `json:"action_id"`
+	ActionType  models.ActionType `json:"action_type"`
+	TriggerID   *uuid.UUID        `json:"trigger_id"`
+	Succeeded   int               `json:"succeeded"`
+	Failed      int               `json:"failed"`
+	Held        int               `json:"held"` // messages held by do-not-disturb
+	SuccessRate *float64          `json:"success_rate"`
+}
+
+// WorkflowMessageAnalytics is the provider delivery status of the messages sent to a channel
+type WorkflowMessageAnalytics struct {
+	Channel      string   `json:"channel"`
+	Total        int      `json:"total"`
+	Delivered    int      `json:"delivered"` // delivered or read
+	Read         int      `json:"read"`
+	Failed       int      `json:"failed"`
+	Pending      int      `json:"pending"` // queued or sent, no delivery report yet
+	DeliveryRate *float64 `json:"delivery_rate"`
+}
+
+// WorkflowAnalytics summarises a workflow's execution log over a period
+type WorkflowAnalytics struct {
+	WorkflowID uuid.UUID                  `json:"workflow_id"`
+	From       time.Time                  `json:"from"`
+	To         time.Time                  `json:"to"`
+	States     []WorkflowStateAnalytics   `json:"states"`
+	Triggers   []WorkflowTriggerAnalytics `json:"triggers"`
+	Actions    []WorkflowActionAnalytics  `json:"actions"`
+	Messages   []WorkflowMessageAnalytics `json:"messages"` // session workflows only
+}
+
+// GetWorkflowAnalytics computes state entries and dwell times, trigger runs, action outcomes and
+// message delivery for a workflow between from (inclusive) and to (exclusive)
+func (s *WorkflowService) GetWorkflowAnalytics(ctx context.Context, orgID, workflowID uuid.UUID, from, to time.Time) (*WorkflowAnalytics, error) {
+	if !to.After(from) {
+		return nil, errors.New("end date must be after start date")
+	}
+
+	workflow, err := s.GetWorkflowByID(ctx, workflowID, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	analytics := &WorkflowAnalytics{
+		WorkflowID: workflowID,
+		From:       from,
+		To:         to,
+		States:     []WorkflowStateAnalytics{},
+		Triggers:   []WorkflowTriggerAnalytics{},
+		Actions:    []WorkflowActionAnalytics{},
+		Messages:   []WorkflowMessageAnalytics{},
+	}
+
+	if err := s.stateAnalytics(ctx, orgID, workflow, from, to, analytics); err != nil {
+		return nil, err
+	}
+	if err := s.triggerAnalytics(ctx, orgID, workflow, from, to, analytics); err != nil {
+		return nil, err
+	}
+	if err := s.actionAnalytics(ctx, orgID, workflow, from, to, analytics); err != nil {
+		return nil, err
+	}
+	if workflow.EntityType == models.WorkflowEntitySession {
+		if err := s.messageAnalytics(ctx, orgID, workflowID, from, to, analytics); err != nil {
+			return nil, err
+		}
+	}
+
+	return analytics, nil
+}
+
+// stateAnalytics counts entries into each state in the period. The dwell time of an entry runs
+// until the entity's next state change, which may fall after the period.
+func (s *WorkflowService) stateAnalytics(ctx context.Context, orgID uuid.UUID, workflow *models.Workflow, from, to time.Time, analytics *WorkflowAnalytics) error {
+	rows, err := s.db.Pool.Query(ctx, `
+		WITH changes AS (
+			SELECT to_state, created_at,
+			       LEAD(created_at) OVER (PARTITION BY entity_type, entity_id ORDER BY created_at) AS left_at
+			FROM workflow_execution_log
+			WHERE organization_id = $1 AND workflow_id = $2 AND event_type = 'state_change'
+			AND created_at >= $3
+		)
+		SELECT to_state, COUNT(*), COUNT(left_at),
+		       AVG(EXTRACT(EPOCH FROM left_at - created_at))::float8
+		FROM changes
+		WHERE created_at < $4 AND to_state IS NOT NULL
+		GROUP BY to_state
+	`, orgID, workflow.ID, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to query state analytics: %w", err)
+	}
+	defer rows.Close()
+
+	byState := map[string]WorkflowStateAnalytics{}
+	for rows.Next() {
+		var st WorkflowStateAnalytics
+		if err := rows.Scan(&st.State, &st.Entered, &st.Exited, &st.AvgDwellSeconds); err != nil {
+			return fmt.Errorf("failed to scan state analytics: %w", err)
+		}
+		byState[st.State] = st
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read state analytics: %w", err)
+	}
+
+	// Every state in workflow order, then states that were removed but still have log entries
+	for _, state := range workflow.States {
+		st, ok := byState[state.Name]
+		if !ok {
+			st = WorkflowStateAnalytics{State: state.Name}
+		}
+		st.DisplayName = state.DisplayName
+		analytics.States = append(analytics.States, st)
+		delete(byState, state.Name)
+	}
+	for _, st := range byState {
+		st.DisplayName = st.State
+		analytics.States = append(analytics.States, st)
+	}
+
+	return nil
+}
+
+// triggerAnalytics counts fired and skipped runs of each trigger in the period
+func (s *WorkflowService) triggerAnalytics(ctx context.Context, orgID uuid.UUID, workflow *models.Workflow, from, to time.Time, analytics *WorkflowAnalytics) error {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT details->>'trigger_id',
+		       COUNT(*) FILTER (WHERE event_type = 'trigger_fired'),
+		       COUNT(*) FILTER (WHERE event_type = 'trigger_skipped')
+		FROM workflow_execution_log
+		WHERE organization_id = $1 AND workflow_id = $2
+		AND event_type IN ('trigger_fired', 'trigger_skipped')
+		AND created_at >= $3 AND created_at < $4
+		AND details ? 'trigger_id'
+		GROUP BY details->>'trigger_id'
+	`, orgID, workflow.ID, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to query trigger analytics: %w", err)
+	}
+	defer rows.Close()
+
+	counts := map[uuid.UUID][2]int{}
+	for rows.Next() {
+		var rawID string
+		var fired, skipped int
+		if err := rows.Scan(&rawID, &fired, &skipped); err != nil {
+			return fmt.Errorf("failed to scan trigger analytics: %w", err)
+		}
+		if id, err := uuid.Parse(rawID); err == nil {
+			counts[id] = [2]int{fired, skipped}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read trigger analytics: %w", err)
+	}
+
+	stateNames := map[uuid.UUID]string{}
+	for _, state := range workflow.States {
+		stateNames[state.ID] = state.Name
+	}
+	for _, trigger := range workflow.Triggers {
+		t := WorkflowTriggerAnalytics{
+			TriggerID:   trigger.ID,
+			TriggerType: trigger.TriggerType,
+			Fired:       counts[trigger.ID][0],
+			Skipped:     counts[trigger.ID][1],
+		}
+		if trigger.StateID != nil {
+			if name, ok := stateNames[*trigger.StateID]; ok {
+				t.State = &name
+			}
+		}
+		analytics.Triggers = append(analytics.Triggers, t)
+	}
+
+	return nil
+}
+
+// actionAnalytics counts successful and failed attempts of each action in the period
+func (s *WorkflowService) actionAnalytics(ctx context.Context, orgID uuid.UUID, workflow *models.Workflow, from, to time.Time, analytics *WorkflowAnalytics) error {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT details->>'action_id',
+		       COUNT(*) FILTER (WHERE event_type = 'action_executed'),
+		       COUNT(*) FILTER (WHERE event_type = 'action_failed'),
+		       COUNT(*) FILTER (WHERE details->'delivery'->>'outcome' = 'held')
+		FROM workflow_execution_log
+		WHERE organization_id = $1 AND workflow_id = $2
+		AND event_type IN ('action_executed', 'action_failed')
+		AND created_at >= $3 AND created_at < $4
+		AND details ? 'action_id'
+		GROUP BY details->>'action_id'
+	`, orgID, workflow.ID, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to query action analytics: %w", err)
+	}
+	defer rows.Close()
+
+	counts := map[uuid.UUID][3]int{}
+	for rows.Next() {
+		var rawID string
+		var succeeded, failed, held int
+		if err := rows.Scan(&rawID, &succeeded, &failed, &held); err != nil {
+			return fmt.Errorf("failed to scan action analytics: %w", err)
+		}
+		if id, err := uuid.Parse(rawID); err == nil {
+			counts[id] = [3]int{succeeded, failed, held}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read action analytics: %w", err)
+	}
+
+	for _, trigger := range workflow.Triggers {
+		triggerID := trigger.ID
+		for _, action := range trigger.Actions {
+			c := counts[action.ID]
+			a := WorkflowActionAnalytics{
+				ActionID:   action.ID,
+				ActionType: action.ActionType,
+				TriggerID:  &triggerID,
+				Succeeded:  c[0],
+				Failed:     c[1],
+				Held:       c[2],
+			}
+			if attempts := a.Succeeded + a.Failed; attempts > 0 {
+				rate := float64(a.Succeeded) / float64(attempts)
+				a.SuccessRate = &rate
+			}
+			analytics.Actions = append(analytics.Actions, a)
+		}
+	}
+
+	return nil
+}
+
+// messageAnalytics reports the delivery status of the WhatsApp messages and emails sent in the
+// period to sessions the workflow sent a message for
+func (s *WorkflowService) messageAnalytics(ctx context.Context, orgID, workflowID uuid.UUID, from, to time.Time, analytics *WorkflowAnalytics) error {
+	channels := []struct {
+		name       string
+		actionType models.ActionType
+		query      string
+	}{
+		{"whatsapp", models.ActionTypeSendWhatsApp, `
+			SELECT COUNT(*),
+			       COUNT(*) FILTER (WHERE m.status IN ('delivered', 'read')),
+			       COUNT(*) FILTER (WHERE m.status = 'read'),
+			       COUNT(*) FILTER (WHERE m.status IN ('failed', 'undelivered'))
+			FROM whatsapp_messages m
+			WHERE m.organization_id = $1 AND m.direction = 'outbound'
+			AND m.created_at >= $3 AND m.created_at < $4`},
+		{"email", models.ActionTypeSendEmail, `
+			SELECT COUNT(*),
+			       COUNT(*) FILTER (WHERE m.status = 'delivered'),
+			       0,
+			       COUNT(*) FILTER (WHERE m.status = 'failed')
+			FROM email_messages m
+			WHERE m.organization_id = $1
+			AND m.created_at >= $3 AND m.created_at < $4`},
+	}
+
+	for _, channel := range channels {
+		m := WorkflowMessageAnalytics{Channel: channel.name}
+		err := s.db.Pool.QueryRow(ctx, channel.query+`
+			AND m.session_id IN (
+				SELECT entity_id FROM workflow_execution_log
+				WHERE organization_id = $1 AND workflow_id = $2 AND entity_type = 'session'
+				AND event_type = 'action_executed' AND details->>'action_type' = $5
+				AND created_at >= $3 AND created_at < $4
+			)
+		`, orgID, workflowID, from, to, channel.actionType).Scan(&m.Total, &m.Delivered, &m.Read, &m.Failed)
+		if err != nil {
+			return fmt.Errorf("failed to query %s delivery: %w", channel.name, err)
+		}
+		m.Pending = m.Total - m.Delivered - m.Failed
+		if m.Total > 0 {
+			rate := float64(m.Delivered) / float64(m.Total)
+			m.DeliveryRate = &rate
+		}
+		analytics.Messages = append(analytics.Messages, m)
+	}
+
+	return nil
+}
@@ -0,0 +1,378 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// WorkflowChainService runs transition_entity actions, moving the entities related to the one a
+// workflow runs for. Every move is recorded as a chain link so loops between entities are stopped.
+type WorkflowChainService struct {
+	db              *database.DB
+	projects        *ProjectService
+	tasks           *TaskService
+	sessionPayments *SessionPaymentService
+	workflow        *WorkflowService
+}
+
+// NewWorkflowChainService creates a new WorkflowChainService
+func NewWorkflowChainService(db *database.DB, projects *ProjectService, tasks *TaskService, sessionPayments *SessionPaymentService, workflow *WorkflowService) *WorkflowChainService {
+	return &WorkflowChainService{
+		db:              db,
+		projects:        projects,
+		tasks:           tasks,
+		sessionPayments: sessionPayments,
+		workflow:        workflow,
+	}
+}
+
+// TransitionRelated moves the related entity named by the action config's target:
+//   - project (from a budget): creates the budget's project, or moves it to to_status
+//   - session_payment (from a session): creates the session's payment and, with
+//     dunning_after_days, schedules the session workflow's payment_overdue triggers
+func (s *WorkflowChainService) TransitionRelated(ctx context.Context, orgID, actionID uuid.UUID, entityType string, entityID uuid.UUID, config map[string]interface{}) error {
+	depth, err := s.chainDepth(ctx, orgID, entityType, entityID)
+	if err != nil {
+		return err
+	}
+	if depth+1 > models.MaxWorkflowChainDepth {
+		return fmt.Errorf("workflow chain is deeper than %d actions", models.MaxWorkflowChainDepth)
+	}
+
+	target, _ := config["target"].(string)
+	switch target {
+	case models.ChainTargetProject:
+		if entityType != "budget" {
+			return fmt.Errorf("%s target requires a budget, got %s", target, entityType)
+		}
+		return s.transitionProject(ctx, orgID, actionID, entityID, depth+1, config)
+	case models.ChainTargetSessionPayment:
+		if entityType != "session" {
+			return fmt.Errorf("%s target requires a session, got %s", target, entityType)
+		}
+		return s.createSessionPayment(ctx, orgID, actionID, entityID, depth+1, config)
+	default:
+		return fmt.Errorf("invalid transition target: %q", target)
+	}
+}
+
+// transitionProject creates the project of an approved budget, or moves its existing project
+func (s *WorkflowChainService) transitionProject(ctx context.Context, orgID, actionID, budgetID uuid.UUID, depth int, config map[string]interface{}) error {
+	toStatus, _ := config["to_status"].(string)
+
+	var projectID uuid.UUID
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT id FROM projects
+		WHERE budget_id = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`, budgetID, orgID).Scan(&projectID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("failed to get budget project: %w", err)
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		var createdBy uuid.UUID
+		var title string
+		err := s.db.Pool.QueryRow(ctx, `
+			SELECT b.created_by, COALESCE(w.title, b.budget_number)
+			FROM budgets b
+			LEFT JOIN worksheets w ON w.id = b.worksheet_id
+			WHERE b.id = $1 AND b.organization_id = $2 AND b.deleted_at IS NULL
+		`, budgetID, orgID).Scan(&createdBy, &title)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return errors.New("budget not found")
+			}
+			return fmt.Errorf("failed to get budget: %w", err)
+		}
+
+		durationDays := 30
+		if days, ok := config["duration_days"].(float64); ok && days > 0 {
+			durationDays = int(days)
+		}
+		project, err := s.projects.CreateFromBudget(ctx, orgID, createdBy, CreateProjectRequest{
+			BudgetID:        budgetID,
+			Title:           title,
+			ExpectedEndDate: time.Now().AddDate(0, 0, durationDays),
+		})
+		if err != nil {
+			return err
+		}
+		projectID = project.ID
+		if toStatus == "" || toStatus == string(project.Status) {
+			return s.recordLink(ctx, orgID, actionID, "budget", budgetID, "project", projectID, depth)
+		}
+	} else {
+		if toStatus == "" {
+			return nil
+		}
+		linked, err := s.linkExists(ctx, actionID, "budget", budgetID, "project", projectID)
+		if err != nil || linked {
+			return err
+		}
+	}
+
+	if err := s.checkCycle(ctx, orgID, "budget", budgetID, "project", projectID); err != nil {
+		return err
+	}
+	if err := s.projects.UpdateStatus(ctx, projectID, orgID, models.ProjectStatus(toStatus), nil, nil); err != nil {
+		return err
+	}
+	return s.recordLink(ctx, orgID, actionID, "budget", budgetID, "project", projectID, depth)
+}
+
+// CreateProject runs a create_project action: it creates the project of an approved budget with
+// the action config's start_offset_days, duration_days, number_prefix and items_as (tasks,
+// milestones or none). A budget that already has a project is left as it is.
+func (s *WorkflowChainService) CreateProject(ctx context.Context, orgID, actionID uuid.UUID, entityType string, entityID uuid.UUID, config map[string]interface{}) error {
+	if entityType != "budget" {
+		return fmt.Errorf("create_project requires a budget, got %s", entityType)
+	}
+
+	depth, err := s.chainDepth(ctx, orgID, entityType, entityID)
+	if err != nil {
+		return err
+	}
+	if depth+1 > models.MaxWorkflowChainDepth {
+		return fmt.Errorf("workflow chain is deeper than %d actions", models.MaxWorkflowChainDepth)
+	}
+
+	var hasProject bool
+	var createdBy uuid.UUID
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM projects WHERE budget_id = b.id AND deleted_at IS NULL), b.created_by
+		FROM budgets b
+		WHERE b.id = $1 AND b.organization_id = $2 AND b.deleted_at IS NULL
+	`, entityID, orgID).Scan(&hasProject, &createdBy)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("budget not found")
+		}
+		return fmt.Errorf("failed to get budget: %w", err)
+	}
+	if hasProject {
+		return nil
+	}
+
+	startDate := time.Now()
+	if days, ok := config["start_offset_days"].(float64); ok {
+		startDate = startDate.AddDate(0, 0, int(days))
+	}
+	durationDays := 30
+	if days, ok := config["duration_days"].(float64); ok && days > 0 {
+		durationDays = int(days)
+	}
+	req := CreateProjectRequest{
+		BudgetID:        entityID,
+		StartDate:       &startDate,
+		ExpectedEndDate: startDate.AddDate(0, 0, durationDays),
+		ItemsAs:         BudgetItemsAsTasks,
+	}
+	req.NumberPrefix, _ = config["number_prefix"].(string)
+	if itemsAs, ok := config["items_as"].(string); ok && itemsAs != "" {
+		req.ItemsAs = itemsAs
+	}
+
+	project, err := s.projects.CreateFromBudget(ctx, orgID, createdBy, req)
+	if errors.Is(err, ErrBudgetHasProject) {
+		// A project was created from the budget meanwhile
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.recordLink(ctx, orgID, actionID, "budget", entityID, models.ChainTargetProject, project.ID, depth+1)
+}
+
+// CreateTask creates the task of a create_task action on the project of the entity the workflow
+// runs for: the project itself, a task's project or a budget's project. The config sets the
+// 'title', 'description' and 'assignee_id'; the task goes to the assignee's delegate while they
+// are out of office. Tasks are linked to the entity like chained transitions, so task_assigned
+// workflows creating tasks stop at the chain depth limit.
+func (s *WorkflowChainService) CreateTask(ctx context.Context, orgID, actionID uuid.UUID, entityType string, entityID uuid.UUID, config map[string]interface{}) error {
+	depth, err := s.chainDepth(ctx, orgID, entityType, entityID)
+	if err != nil {
+		return err
+	}
+	if depth+1 > models.MaxWorkflowChainDepth {
+		return fmt.Errorf("workflow chain is deeper than %d actions", models.MaxWorkflowChainDepth)
+	}
+
+	var query string
+	switch entityType {
+	case "project":
+		query = `SELECT id, created_by FROM projects WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL`
+	case "task":
+		query = `
+			SELECT p.id, p.created_by FROM tasks t JOIN projects p ON p.id = t.project_id
+			WHERE t.id = $1 AND p.organization_id = $2 AND p.deleted_at IS NULL`
+	case "budget":
+		query = `SELECT id, created_by FROM projects WHERE budget_id = $1 AND organization_id = $2 AND deleted_at IS NULL`
+	default:
+		return fmt.Errorf("create_task requires a project, task or budget, got %s", entityType)
+	}
+	var projectID, createdBy uuid.UUID
+	if err := s.db.Pool.QueryRow(ctx, query, entityID, orgID).Scan(&projectID, &createdBy); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("no project found for %s %s", entityType, entityID)
+		}
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	req := CreateTaskRequest{ProjectID: projectID}
+	req.Title, _ = config["title"].(string)
+	if req.Title == "" {
+		req.Title = fmt.Sprintf("Task for %s %s", entityType, entityID)
+	}
+	if description, ok := config["description"].(string); ok && description != "" {
+		req.Description = &description
+	}
+	if assignee, ok := config["assignee_id"].(string); ok && assignee != "" {
+		assigneeID, err := uuid.Parse(assignee)
+		if err != nil {
+			return errors.New("create_task action has an invalid 'assignee_id' in config")
+		}
+		req.AssignedTo = &assigneeID
+	}
+
+	task, err := s.tasks.Create(ctx, orgID, createdBy, req)
+	if err != nil {
+		return err
+	}
+	return s.recordLink(ctx, orgID, actionID, entityType, entityID, "task", task.ID, depth+1)
+}
+
+// createSessionPayment creates the payment of a session and schedules its dunning triggers
+func (s *WorkflowChainService) createSessionPayment(ctx context.Context, orgID, actionID, sessionID uuid.UUID, depth int, config map[string]interface{}) error {
+	if err := s.sessionPayments.CreatePaymentForSession(ctx, sessionID, orgID); err != nil {
+		return err
+	}
+	payment, err := s.sessionPayments.GetBySessionID(ctx, sessionID, orgID)
+	if err != nil {
+		return err
+	}
+	if payment == nil {
+		return errors.New("payment record not found")
+	}
+
+	linked, err := s.linkExists(ctx, actionID, "session", sessionID, models.ChainTargetSessionPayment, payment.ID)
+	if err != nil || linked {
+		return err
+	}
+
+	if days, ok := config["dunning_after_days"].(float64); ok && days > 0 && payment.PaymentStatus != models.SessionPaymentStatusPaid {
+		if err := s.scheduleDunning(ctx, orgID, sessionID, time.Now().Add(time.Duration(days*24)*time.Hour)); err != nil {
+			return err
+		}
+	}
+
+	return s.recordLink(ctx, orgID, actionID, "session", sessionID, models.ChainTargetSessionPayment, payment.ID, depth)
+}
+
+// scheduleDunning schedules the session workflow's payment_overdue triggers attached to the
+// session's current state. They are cancelled once the payment is marked as paid.
+func (s *WorkflowChainService) scheduleDunning(ctx context.Context, orgID, sessionID uuid.UUID, at time.Time) error {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT t.id
+		FROM workflow_triggers t
+		JOIN workflows w ON w.id = t.workflow_id
+		JOIN workflow_states st ON st.id = t.state_id
+		JOIN sessions se ON se.id = $2 AND se.organization_id = w.organization_id
+		WHERE w.organization_id = $1 AND w.entity_type = 'session' AND w.is_default = true AND w.is_active = true
+		AND t.trigger_type = $3 AND t.is_active = true AND st.name = se.status
+	`, orgID, sessionID, models.TriggerTypePaymentOverdue)
+	if err != nil {
+		return fmt.Errorf("failed to get dunning triggers: %w", err)
+	}
+	var triggerIDs []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan trigger: %w", err)
+		}
+		triggerIDs = append(triggerIDs, id)
+	}
+	rows.Close()
+
+	for _, triggerID := range triggerIDs {
+		if err := s.workflow.scheduleJob(ctx, orgID, triggerID, "session", sessionID, at); err != nil {
+			return fmt.Errorf("failed to schedule dunning trigger: %w", err)
+		}
+	}
+	return nil
+}
+
+// chainDepth returns how many transition_entity actions led to the entity, 0 when it was
+// changed directly
+func (s *WorkflowChainService) chainDepth(ctx context.Context, orgID uuid.UUID, entityType string, entityID uuid.UUID) (int, error) {
+	var depth int
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT COALESCE(MAX(depth), 0) FROM workflow_chain_links
+		WHERE organization_id = $1 AND target_entity_type = $2 AND target_entity_id = $3
+	`, orgID, entityType, entityID).Scan(&depth)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get workflow chain depth: %w", err)
+	}
+	return depth, nil
+}
+
+// checkCycle refuses to move a target that is already one of the source's ancestors in a chain
+func (s *WorkflowChainService) checkCycle(ctx context.Context, orgID uuid.UUID, sourceType string, sourceID uuid.UUID, targetType string, targetID uuid.UUID) error {
+	var cycle bool
+	err := s.db.Pool.QueryRow(ctx, `
+		WITH RECURSIVE ancestors AS (
+			SELECT source_entity_type, source_entity_id, 1 AS level
+			FROM workflow_chain_links
+			WHERE organization_id = $1 AND target_entity_type = $2 AND target_entity_id = $3
+			UNION
+			SELECT l.source_entity_type, l.source_entity_id, a.level + 1
+			FROM workflow_chain_links l
+			JOIN ancestors a ON l.target_entity_type = a.source_entity_type AND l.target_entity_id = a.source_entity_id
+			WHERE l.organization_id = $1 AND a.level < $6
+		)
+		SELECT EXISTS(SELECT 1 FROM ancestors WHERE source_entity_type = $4 AND source_entity_id = $5)
+	`, orgID, sourceType, sourceID, targetType, targetID, models.MaxWorkflowChainDepth).Scan(&cycle)
+	if err != nil {
+		return fmt.Errorf("failed to check workflow chain: %w", err)
+	}
+	if cycle {
+		return fmt.Errorf("workflow chain loop: %s %s already led to %s %s", targetType, targetID, sourceType, sourceID)
+	}
+	return nil
+}
+
+// linkExists reports whether the action already moved the target for the source
+func (s *WorkflowChainService) linkExists(ctx context.Context, actionID uuid.UUID, sourceType string, sourceID uuid.UUID, targetType string, targetID uuid.UUID) (bool, error) {
+	var exists bool
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM workflow_chain_links
+			WHERE action_id = $1 AND source_entity_type = $2 AND source_entity_id = $3
+			AND target_entity_type = $4 AND target_entity_id = $5
+		)
+	`, actionID, sourceType, sourceID, targetType, targetID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check workflow chain link: %w", err)
+	}
+	return exists, nil
+}
+
+// recordLink stores a chain link between the source and the target it moved
+func (s *WorkflowChainService) recordLink(ctx context.Context, orgID, actionID uuid.UUID, sourceType string, sourceID uuid.UUID, targetType string, targetID uuid.UUID, depth int) error {
+	_, err := s.db.Pool.Exec(ctx, `
+		INSERT INTO workflow_chain_links
+		(id, organization_id, action_id, source_entity_type, source_entity_id, target_entity_type, target_entity_id, depth)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, uuid.New(), orgID, actionID, sourceType, sourceID, targetType, targetID, depth)
+	if err != nil {
+		return fmt.Errorf("failed to record workflow chain link: %w", err)
+	}
+	return nil
+}
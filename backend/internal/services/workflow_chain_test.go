@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/workflow"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// insertTestAction creates a project workflow with one state whose trigger runs an action of the type
+func insertTestAction(t *testing.T, ctx context.Context, tx pgx.Tx, orgID uuid.UUID, actionType models.ActionType, config json.RawMessage) uuid.UUID {
+	t.Helper()
+	workflowID, stateID, triggerID, actionID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	steps := []struct {
+		sql  string
+		args []interface{}
+	}{
+		{`INSERT INTO workflows (id, organization_id, name, module, entity_type) VALUES ($1, $2, 'Projects', 'construction', 'project')`,
+			[]interface{}{workflowID, orgID}},
+		{`INSERT INTO workflow_states (id, workflow_id, name, display_name, state_type, position) VALUES ($1, $2, 'in_progress', 'In progress', 'initial', 0)`,
+			[]interface{}{stateID, workflowID}},
+		{`INSERT INTO workflow_triggers (id, workflow_id, state_id, trigger_type) VALUES ($1, $2, $3, 'on_enter')`,
+			[]interface{}{triggerID, workflowID, stateID}},
+		{`INSERT INTO workflow_actions (id, trigger_id, action_type, action_config) VALUES ($1, $2, $3, $4)`,
+			[]interface{}{actionID, triggerID, actionType, config}},
+	}
+	for _, step := range steps {
+		if _, err := tx.Exec(ctx, step.sql, step.args...); err != nil {
+			t.Fatalf("failed to create workflow action: %v", err)
+		}
+	}
+	return actionID
+}
+
+// TestExecutorCreateTask runs a create_task action through the executor and checks the task was
+// created on the project, assigned to the delegate of the out-of-office assignee
+func TestExecutorCreateTask(t *testing.T) {
+	pool := openTestDB(t)
+	ctx := context.Background()
+
+	orgID, adminID := seedTestOrganization(t, pool, "Workflow tasks")
+	var awayID, delegateID, projectID uuid.UUID
+	action := &models.WorkflowAction{ActionType: models.ActionTypeCreateTask}
+	seedTestData(t, pool, func(tx pgx.Tx) {
+		awayID = insertTestUser(t, ctx, tx, orgID, models.RoleEmployee)
+		delegateID = insertTestUser(t, ctx, tx, orgID, models.RoleEmployee)
+		projectID = insertTestProject(t, ctx, tx, orgID, adminID)
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO user_out_of_office (organization_id, user_id, delegate_user_id, starts_at, ends_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`, orgID, awayID, delegateID, time.Now().Add(-time.Hour), time.Now().Add(24*time.Hour)); err != nil {
+			t.Fatalf("failed to create out-of-office: %v", err)
+		}
+		action.ActionConfig, _ = json.Marshal(map[string]string{
+			"title":       "Order materials",
+			"description": "Before the works start",
+			"assignee_id": awayID.String(),
+		})
+		action.ID = insertTestAction(t, ctx, tx, orgID, action.ActionType, action.ActionConfig)
+	})
+
+	db := &database.DB{Pool: pool}
+	executor := workflow.NewExecutor(db)
+	executor.SetTaskCreator(NewWorkflowChainService(db, nil, NewTaskService(db, nil), nil, nil))
+
+	if _, err := executor.ExecuteAction(ctx, orgID, action, "project", projectID, nil); err != nil {
+		t.Fatalf("ExecuteAction: %v", err)
+	}
+
+	var taskID uuid.UUID
+	var description *string
+	var assignedTo *uuid.UUID
+	err := pool.QueryRow(ctx, `
+		SELECT id, description, assigned_to FROM tasks
+		WHERE project_id = $1 AND title = 'Order materials' AND deleted_at IS NULL
+	`, projectID).Scan(&taskID, &description, &assignedTo)
+	if err != nil {
+		t.Fatalf("failed to get created task: %v", err)
+	}
+	if description == nil || *description != "Before the works start" {
+		t.Errorf("task description = %v, want the action's", description)
+	}
+	if assignedTo == nil || *assignedTo != delegateID {
+		t.Errorf("task assigned to %v, want delegate %s", assignedTo, delegateID)
+	}
+
+	var linked bool
+	if err := pool.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM workflow_chain_links
+			WHERE action_id = $1 AND source_entity_type = 'project' AND source_entity_id = $2
+				AND target_entity_type = 'task' AND target_entity_id = $3 AND depth = 1
+		)
+	`, action.ID, projectID, taskID).Scan(&linked); err != nil {
+		t.Fatalf("failed to get chain link: %v", err)
+	}
+	if !linked {
+		t.Error("created task is not linked to the project it was created for")
+	}
+}
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// workflowEntityTables maps workflow-managed entity types to the tables holding their status
+var workflowEntityTables = map[models.WorkflowEntityType]string{
+	models.WorkflowEntitySession: "sessions",
+	models.WorkflowEntityBudget:  "budgets",
+	models.WorkflowEntityProject: "projects",
+	models.WorkflowEntityInvoice: "invoices",
+}
+
+// maxMismatchSamples is the number of entity IDs kept as examples of a mismatch
+const maxMismatchSamples = 5
+
+// ============ Status Consistency ============
+
+// CheckAllStatusConsistency runs the consistency check for every organization with a default workflow
+func (s *WorkflowService) CheckAllStatusConsistency(ctx context.Context) (int, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT DISTINCT organization_id FROM workflows WHERE is_default = true AND is_active = true
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query organizations: %w", err)
+	}
+	var orgIDs []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan organization: %w", err)
+		}
+		orgIDs = append(orgIDs, id)
+	}
+	rows.Close()
+
+	open := 0
+	for _, orgID := range orgIDs {
+		mismatches, err := s.CheckStatusConsistency(ctx, orgID)
+		if err != nil {
+			fmt.Printf("Failed to check status consistency for organization %s: %v\n", orgID, err)
+			continue
+		}
+		open += len(mismatches)
+	}
+
+	return open, nil
+}
+
+// CheckStatusConsistency compares entity statuses against the states of each default workflow.
+// Auto-apply remaps run first; remaining statuses without a matching state are recorded as
+// mismatches and previously recorded ones that disappeared are marked resolved.
+func (s *WorkflowService) CheckStatusConsistency(ctx context.Context, orgID uuid.UUID) ([]*models.WorkflowStatusMismatch, error) {
+	for entityType, table := range workflowEntityTables {
+		if err := s.checkEntityStatuses(ctx, orgID, entityType, table); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.ListStatusMismatches(ctx, orgID, false)
+}
+
+// checkEntityStatuses checks the statuses of one entity type
+func (s *WorkflowService) checkEntityStatuses(ctx context.Context, orgID uuid.UUID, entityType models.WorkflowEntityType, table string) error {
+	var workflowID uuid.UUID
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT id FROM workflows
+		WHERE organization_id = $1 AND entity_type = $2 AND is_default = true AND is_active = true
+		LIMIT 1
+	`, orgID, entityType).Scan(&workflowID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("failed to get default workflow: %w", err)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Not workflow-managed anymore: nothing can be out of sync
+		return s.resolveMismatches(ctx, orgID, entityType, nil)
+	}
+
+	remaps, err := s.ListStatusRemaps(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	remapErrors := make(map[string]string)
+	for _, remap := range remaps {
+		if remap.EntityType != entityType || !remap.AutoApply {
+			continue
+		}
+		if _, err := s.applyStatusRemap(ctx, orgID, remap); err != nil {
+			remapErrors[remap.FromStatus] = err.Error()
+		}
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT e.status, COUNT(*), (array_agg(e.id ORDER BY e.updated_at DESC))[1:`+fmt.Sprint(maxMismatchSamples)+`]
+		FROM `+table+` e
+		WHERE e.organization_id = $1 AND e.deleted_at IS NULL AND e.status IS NOT NULL
+		AND NOT EXISTS (SELECT 1 FROM workflow_states ws WHERE ws.workflow_id = $2 AND ws.name = e.status)
+		GROUP BY e.status
+	`, orgID, workflowID)
+	if err != nil {
+		return fmt.Errorf("failed to query %s statuses: %w", entityType, err)
+	}
+	var mismatches []*models.WorkflowStatusMismatch
+	for rows.Next() {
+		m := &models.WorkflowStatusMismatch{OrganizationID: orgID, WorkflowID: workflowID, EntityType: entityType}
+		if err := rows.Scan(&m.Status, &m.EntityCount, &m.SampleEntityIDs); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan status: %w", err)
+		}
+		if msg, ok := remapErrors[m.Status]; ok {
+			m.RemapError = &msg
+		}
+		mismatches = append(mismatches, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read %s statuses: %w", entityType, err)
+	}
+
+	statuses := make([]string, 0, len(mismatches))
+	for _, m := range mismatches {
+		statuses = append(statuses, m.Status)
+		_, err := s.db.Pool.Exec(ctx, `
+			INSERT INTO workflow_status_mismatches (
+				organization_id, workflow_id, entity_type, status, entity_count, sample_entity_ids, remap_error
+			) VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (organization_id, entity_type, status) DO UPDATE
+			SET workflow_id = EXCLUDED.workflow_id, entity_count = EXCLUDED.entity_count,
+				sample_entity_ids = EXCLUDED.sample_entity_ids, remap_error = EXCLUDED.remap_error,
+				last_checked_at = NOW(), resolved_at = NULL,
+				first_detected_at = CASE WHEN workflow_status_mismatches.resolved_at IS NULL
+					THEN workflow_status_mismatches.first_detected_at ELSE NOW() END
+		`, orgID, workflowID, entityType, m.Status, m.EntityCount, m.SampleEntityIDs, m.RemapError)
+		if err != nil {
+			return fmt.Errorf("failed to record status mismatch: %w", err)
+		}
+	}
+
+	return s.resolveMismatches(ctx, orgID, entityType, statuses)
+}
+
+// resolveMismatches marks open mismatches of an entity type resolved, except the given statuses
+func (s *WorkflowService) resolveMismatches(ctx context.Context, orgID uuid.UUID, entityType models.WorkflowEntityType, keep []string) error {
+	if keep == nil {
+		keep = []string{}
+	}
+	_, err := s.db.Pool.Exec(ctx, `
+		UPDATE workflow_status_mismatches
+		SET resolved_at = NOW(), last_checked_at = NOW()
+		WHERE organization_id = $1 AND entity_type = $2 AND resolved_at IS NULL
+		AND NOT (status = ANY($3))
+	`, orgID, entityType, keep)
+	if err != nil {
+		return fmt.Errorf("failed to resolve status mismatches: %w", err)
+	}
+	return nil
+}
+
+// ListStatusMismatches returns the recorded status mismatches of an organization
+func (s *WorkflowService) ListStatusMismatches(ctx context.Context, orgID uuid.UUID, includeResolved bool) ([]*models.WorkflowStatusMismatch, error) {
+	query := `
+		SELECT id, organization_id, workflow_id, entity_type, status, entity_count, sample_entity_ids,
+			remap_error, first_detected_at, last_checked_at, resolved_at
+		FROM workflow_status_mismatches
+		WHERE organization_id = $1
+	`
+	if !includeResolved {
+		query += " AND resolved_at IS NULL"
+	}
+	query += " ORDER BY entity_type, entity_count DESC"
+
+	rows, err := s.db.Pool.Query(ctx, query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query status mismatches: %w", err)
+	}
+	defer rows.Close()
+
+	mismatches := []*models.WorkflowStatusMismatch{}
+	for rows.Next() {
+		var m models.WorkflowStatusMismatch
+		if err := rows.Scan(
+			&m.ID, &m.OrganizationID, &m.WorkflowID, &m.EntityType, &m.Status, &m.EntityCount, &m.SampleEntityIDs,
+			&m.RemapError, &m.FirstDetectedAt, &m.LastCheckedAt, &m.ResolvedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan status mismatch: %w", err)
+		}
+		mismatches = append(mismatches, &m)
+	}
+
+	return mismatches, nil
+}
+
+// ============ Status Remaps ============
+
+// ListStatusRemaps returns the status remaps of an organization with their target state names
+func (s *WorkflowService) ListStatusRemaps(ctx context.Context, orgID uuid.UUID) ([]*models.WorkflowStatusRemap, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT r.id, r.organization_id, r.entity_type, r.from_status, r.to_state_id, ws.name,
+			r.auto_apply, r.created_by, r.created_at, r.updated_at
+		FROM workflow_status_remaps r
+		JOIN workflow_states ws ON ws.id = r.to_state_id
+		WHERE r.organization_id = $1
+		ORDER BY r.entity_type, r.from_status
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query status remaps: %w", err)
+	}
+	defer rows.Close()
+
+	remaps := []*models.WorkflowStatusRemap{}
+	for rows.Next() {
+		var r models.WorkflowStatusRemap
+		if err := rows.Scan(
+			&r.ID, &r.OrganizationID, &r.EntityType, &r.FromStatus, &r.ToStateID, &r.ToStatus,
+			&r.AutoApply, &r.CreatedBy, &r.CreatedAt, &r.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan status remap: %w", err)
+		}
+		remaps = append(remaps, &r)
+	}
+
+	return remaps, nil
+}
+
+// SaveStatusRemap creates or replaces the remap of an old status. The target state must
+// belong to a workflow of the organization for the same entity type.
+func (s *WorkflowService) SaveStatusRemap(ctx context.Context, remap *models.WorkflowStatusRemap) error {
+	if _, ok := workflowEntityTables[remap.EntityType]; !ok {
+		return fmt.Errorf("invalid entity type: %s", remap.EntityType)
+	}
+	if remap.FromStatus == "" {
+		return errors.New("from_status is required")
+	}
+
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT ws.name FROM workflow_states ws
+		JOIN workflows w ON w.id = ws.workflow_id
+		WHERE ws.id = $1 AND w.organization_id = $2 AND w.entity_type = $3
+	`, remap.ToStateID, remap.OrganizationID, remap.EntityType).Scan(&remap.ToStatus)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("state not found")
+		}
+		return fmt.Errorf("failed to get state: %w", err)
+	}
+	if remap.ToStatus == remap.FromStatus {
+		return errors.New("status is already mapped to this state")
+	}
+
+	err = s.db.Pool.QueryRow(ctx, `
+		INSERT INTO workflow_status_remaps (organization_id, entity_type, from_status, to_state_id, auto_apply, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (organization_id, entity_type, from_status) DO UPDATE
+		SET to_state_id = EXCLUDED.to_state_id, auto_apply = EXCLUDED.auto_apply
+		RETURNING id, created_by, created_at, updated_at
+	`, remap.OrganizationID, remap.EntityType, remap.FromStatus, remap.ToStateID, remap.AutoApply, remap.CreatedBy).Scan(
+		&remap.ID, &remap.CreatedBy, &remap.CreatedAt, &remap.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save status remap: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteStatusRemap deletes a status remap
+func (s *WorkflowService) DeleteStatusRemap(ctx context.Context, id, orgID uuid.UUID) error {
+	result, err := s.db.Pool.Exec(ctx, `
+		DELETE FROM workflow_status_remaps WHERE id = $1 AND organization_id = $2
+	`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to delete status remap: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("status remap not found")
+	}
+	return nil
+}
+
+// ApplyStatusRemap moves every entity with the old status to the mapped state and
+// returns the number of entities updated
+func (s *WorkflowService) ApplyStatusRemap(ctx context.Context, id, orgID uuid.UUID) (int64, error) {
+	remaps, err := s.ListStatusRemaps(ctx, orgID)
+	if err != nil {
+		return 0, err
+	}
+	for _, remap := range remaps {
+		if remap.ID != id {
+			continue
+		}
+		updated, err := s.applyStatusRemap(ctx, orgID, remap)
+		if err != nil {
+			return 0, err
+		}
+		if err := s.checkEntityStatuses(ctx, orgID, remap.EntityType, workflowEntityTables[remap.EntityType]); err != nil {
+			return updated, err
+		}
+		return updated, nil
+	}
+	return 0, errors.New("status remap not found")
+}
+
+// applyStatusRemap rewrites the status column directly: workflow triggers do not fire,
+// since the entities are already in the state under its old name
+func (s *WorkflowService) applyStatusRemap(ctx context.Context, orgID uuid.UUID, remap *models.WorkflowStatusRemap) (int64, error) {
+	table := workflowEntityTables[remap.EntityType]
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE `+table+` SET status = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE organization_id = $2 AND status = $3 AND deleted_at IS NULL
+	`, remap.ToStatus, orgID, remap.FromStatus)
+	if err != nil {
+		return 0, fmt.Errorf("failed to remap %s status %s to %s: %w", remap.EntityType, remap.FromStatus, remap.ToStatus, err)
+	}
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const deadLetterColumns = `
+	d.id, d.organization_id, d.workflow_id, d.trigger_id, d.action_id, d.entity_type, d.entity_id,
+	d.attempts, d.last_error, d.status, d.requeued_by, d.requeued_at, d.resolved_at, d.created_at, d.updated_at,
+	w.name, a.action_type
+`
+
+func scanDeadLetter(row pgx.Row) (*models.WorkflowDeadLetter, error) {
+	var d models.WorkflowDeadLetter
+	err := row.Scan(
+		&d.ID, &d.OrganizationID, &d.WorkflowID, &d.TriggerID, &d.ActionID, &d.EntityType, &d.EntityID,
+		&d.Attempts, &d.LastError, &d.Status, &d.RequeuedBy, &d.RequeuedAt, &d.ResolvedAt, &d.CreatedAt, &d.UpdatedAt,
+		&d.WorkflowName, &d.ActionType,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// ListDeadLetters returns the organization's failed actions, most recent first.
+// Without a status filter only the ones still needing attention (failed or requeued) are returned.
+func (s *WorkflowService) ListDeadLetters(ctx context.Context, orgID uuid.UUID, status string, limit, offset int) ([]*models.WorkflowDeadLetter, int, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	where := `d.organization_id = $1 AND (($2 = '' AND d.status IN ('failed', 'requeued')) OR d.status = $2)`
+
+	var total int
+	err := s.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM workflow_dead_letters d WHERE `+where, orgID, status).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count dead letters: %w", err)
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT `+deadLetterColumns+`
+		FROM workflow_dead_letters d
+		JOIN workflows w ON w.id = d.workflow_id
+		JOIN workflow_actions a ON a.id = d.action_id
+		WHERE `+where+`
+		ORDER BY d.created_at DESC
+		LIMIT $3 OFFSET $4
+	`, orgID, status, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	letters := []*models.WorkflowDeadLetter{}
+	for rows.Next() {
+		d, err := scanDeadLetter(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan dead letter: %w", err)
+		}
+		letters = append(letters, d)
+	}
+
+	return letters, total, nil
+}
+
+// GetDeadLetter returns a failed action of the organization
+func (s *WorkflowService) GetDeadLetter(ctx context.Context, id, orgID uuid.UUID) (*models.WorkflowDeadLetter, error) {
+	d, err := scanDeadLetter(s.db.Pool.QueryRow(ctx, `
+		SELECT `+deadLetterColumns+`
+		FROM workflow_dead_letters d
+		JOIN workflows w ON w.id = d.workflow_id
+		JOIN workflow_actions a ON a.id = d.action_id
+		WHERE d.id = $1 AND d.organization_id = $2
+	`, id, orgID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("dead letter not found")
+		}
+		return nil, fmt.Errorf("failed to get dead letter: %w", err)
+	}
+	return d, nil
+}
+
+// RequeueDeadLetter marks a failed action to be run again; the worker picks it up within a minute
+func (s *WorkflowService) RequeueDeadLetter(ctx context.Context, id, orgID, userID uuid.UUID) (*models.WorkflowDeadLetter, error) {
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE workflow_dead_letters
+		SET status = 'requeued', requeued_by = $1, requeued_at = NOW(), updated_at = NOW()
+		WHERE id = $2 AND organization_id = $3 AND status = 'failed'
+	`, userID, id, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to requeue dead letter: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, errors.New("dead letter not found or already requeued")
+	}
+	return s.GetDeadLetter(ctx, id, orgID)
+}
+
+// DiscardDeadLetter dismisses a failed action that should not be run again
+func (s *WorkflowService) DiscardDeadLetter(ctx context.Context, id, orgID uuid.UUID) error {
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE workflow_dead_letters SET status = 'discarded', updated_at = NOW()
+		WHERE id = $1 AND organization_id = $2 AND status = 'failed'
+	`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to discard dead letter: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("dead letter not found or not failed")
+	}
+	return nil
+}
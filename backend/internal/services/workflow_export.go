@@ -0,0 +1,334 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ExportWorkflow serializes a workflow with its states, transitions, triggers, actions and the
+// message templates the actions use
+func (s *WorkflowService) ExportWorkflow(ctx context.Context, id, orgID uuid.UUID) (*models.WorkflowExport, error) {
+	w, err := s.GetWorkflowByID(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &models.WorkflowExport{
+		Version:    models.WorkflowExportVersion,
+		ExportedAt: time.Now(),
+		Workflow: models.WorkflowExportDefinition{
+			Name:        w.Name,
+			Description: w.Description,
+			Module:      w.Module,
+			EntityType:  w.EntityType,
+			States:      []models.WorkflowExportState{},
+			Transitions: []models.WorkflowExportTransition{},
+			Triggers:    []models.WorkflowExportTrigger{},
+		},
+		Templates: []models.WorkflowExportTemplate{},
+	}
+
+	for _, st := range w.States {
+		export.Workflow.States = append(export.Workflow.States, models.WorkflowExportState{
+			ID:          st.ID,
+			Name:        st.Name,
+			DisplayName: st.DisplayName,
+			Description: st.Description,
+			StateType:   st.StateType,
+			Color:       st.Color,
+			Position:    st.Position,
+		})
+	}
+	for _, tr := range w.Transitions {
+		export.Workflow.Transitions = append(export.Workflow.Transitions, models.WorkflowExportTransition{
+			ID:                   tr.ID,
+			FromStateID:          tr.FromStateID,
+			ToStateID:            tr.ToStateID,
+			Name:                 tr.Name,
+			RequiresConfirmation: tr.RequiresConfirmation,
+			Inputs:               tr.Inputs,
+		})
+	}
+
+	templates := map[uuid.UUID]bool{}
+	for _, t := range w.Triggers {
+		trigger := models.WorkflowExportTrigger{
+			StateID:           t.StateID,
+			TransitionID:      t.TransitionID,
+			TriggerType:       t.TriggerType,
+			TimeOffsetMinutes: t.TimeOffsetMinutes,
+			TimeField:         t.TimeField,
+			RecurringCron:     t.RecurringCron,
+			RecurringSkip:     t.RecurringSkip,
+			Conditions:        t.Conditions,
+			IsActive:          t.IsActive,
+			Actions:           []models.WorkflowExportAction{},
+		}
+		for _, a := range t.Actions {
+			trigger.Actions = append(trigger.Actions, models.WorkflowExportAction{
+				ActionType:          a.ActionType,
+				ActionOrder:         a.ActionOrder,
+				TemplateID:          a.TemplateID,
+				ActionConfig:        a.ActionConfig,
+				Urgency:             a.Urgency,
+				RetryMaxAttempts:    a.RetryMaxAttempts,
+				RetryBackoffSeconds: a.RetryBackoffSeconds,
+				IsActive:            a.IsActive,
+			})
+			if a.TemplateID == nil || templates[*a.TemplateID] {
+				continue
+			}
+			templates[*a.TemplateID] = true
+
+			tpl, err := s.GetTemplateByID(ctx, *a.TemplateID, orgID)
+			if err != nil {
+				return nil, err
+			}
+			export.Templates = append(export.Templates, models.WorkflowExportTemplate{
+				ID:                 tpl.ID,
+				Name:               tpl.Name,
+				Channel:            tpl.Channel,
+				Subject:            tpl.Subject,
+				Body:               tpl.Body,
+				Variables:          tpl.Variables,
+				WhatsAppContentSID: tpl.WhatsAppContentSID,
+			})
+		}
+		export.Workflow.Triggers = append(export.Workflow.Triggers, trigger)
+	}
+
+	return export, nil
+}
+
+// ImportWorkflow creates a workflow from an export, with new IDs. The workflow starts inactive
+// and is named after the export unless a name is given. Templates are matched by name and
+// channel: existing ones are reused, missing ones are created.
+func (s *WorkflowService) ImportWorkflow(ctx context.Context, orgID uuid.UUID, export *models.WorkflowExport, name string) (*models.WorkflowImportResult, error) {
+	if export.Version != models.WorkflowExportVersion {
+		return nil, fmt.Errorf("unsupported workflow export version %d", export.Version)
+	}
+	def := export.Workflow
+	if name == "" {
+		name = def.Name
+	}
+	if name == "" {
+		return nil, errors.New("workflow name is required")
+	}
+	switch def.Module {
+	case models.WorkflowModuleAppointments, models.WorkflowModuleConstruction, models.WorkflowModuleInvoices:
+	default:
+		return nil, fmt.Errorf("invalid module '%s'", def.Module)
+	}
+	switch def.EntityType {
+	case models.WorkflowEntitySession, models.WorkflowEntityBudget, models.WorkflowEntityProject, models.WorkflowEntityInvoice:
+	default:
+		return nil, fmt.Errorf("invalid entity type '%s'", def.EntityType)
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM workflows WHERE organization_id = $1 AND name = $2)
+	`, orgID, name).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check workflow name: %w", err)
+	}
+	if exists {
+		return nil, errors.New("a workflow with this name already exists")
+	}
+
+	result := &models.WorkflowImportResult{TemplatesCreated: []string{}, TemplatesReused: []string{}}
+	templateMap, err := importWorkflowTemplates(ctx, tx, orgID, export.Templates, result)
+	if err != nil {
+		return nil, err
+	}
+
+	workflowID := uuid.New()
+	_, err = tx.Exec(ctx, `
+		INSERT INTO workflows (id, organization_id, name, description, module, entity_type, is_active, is_default)
+		VALUES ($1, $2, $3, $4, $5, $6, false, false)
+	`, workflowID, orgID, name, def.Description, def.Module, def.EntityType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workflow: %w", err)
+	}
+
+	stateMap := make(map[uuid.UUID]uuid.UUID, len(def.States))
+	for _, st := range def.States {
+		newID := uuid.New()
+		_, err := tx.Exec(ctx, `
+			INSERT INTO workflow_states (id, workflow_id, name, display_name, description, state_type, color, position)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, newID, workflowID, st.Name, st.DisplayName, st.Description, st.StateType, st.Color, st.Position)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create state '%s': %w", st.Name, err)
+		}
+		stateMap[st.ID] = newID
+	}
+
+	transitionMap := make(map[uuid.UUID]uuid.UUID, len(def.Transitions))
+	for _, tr := range def.Transitions {
+		from, okFrom := stateMap[tr.FromStateID]
+		to, okTo := stateMap[tr.ToStateID]
+		if !okFrom || !okTo {
+			return nil, fmt.Errorf("transition '%s' references an unknown state", tr.Name)
+		}
+		newID := uuid.New()
+		inputs := tr.Inputs
+		if inputs == nil {
+			inputs = []models.TransitionInput{}
+		}
+		_, err := tx.Exec(ctx, `
+			INSERT INTO workflow_transitions (id, workflow_id, from_state_id, to_state_id, name, requires_confirmation, inputs)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, newID, workflowID, from, to, tr.Name, tr.RequiresConfirmation, inputs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create transition '%s': %w", tr.Name, err)
+		}
+		transitionMap[tr.ID] = newID
+	}
+
+	for _, t := range def.Triggers {
+		trigger := &models.WorkflowTrigger{
+			WorkflowID:        workflowID,
+			TriggerType:       t.TriggerType,
+			TimeOffsetMinutes: t.TimeOffsetMinutes,
+			TimeField:         t.TimeField,
+			RecurringCron:     t.RecurringCron,
+			RecurringSkip:     t.RecurringSkip,
+			Conditions:        t.Conditions,
+			IsActive:          t.IsActive,
+		}
+		if t.StateID != nil {
+			id, ok := stateMap[*t.StateID]
+			if !ok {
+				return nil, errors.New("trigger references an unknown state")
+			}
+			trigger.StateID = &id
+		}
+		if t.TransitionID != nil {
+			id, ok := transitionMap[*t.TransitionID]
+			if !ok {
+				return nil, errors.New("trigger references an unknown transition")
+			}
+			trigger.TransitionID = &id
+		}
+		if err := validateRecurringTrigger(trigger); err != nil {
+			return nil, err
+		}
+		if _, err := models.ParseTriggerConditions(trigger.Conditions); err != nil {
+			return nil, err
+		}
+
+		triggerID := uuid.New()
+		_, err := tx.Exec(ctx, `
+			INSERT INTO workflow_triggers (id, workflow_id, state_id, transition_id, trigger_type,
+			                               time_offset_minutes, time_field, recurring_cron, recurring_skip, conditions, is_active)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		`, triggerID, workflowID, trigger.StateID, trigger.TransitionID, trigger.TriggerType,
+			trigger.TimeOffsetMinutes, trigger.TimeField, trigger.RecurringCron, trigger.RecurringSkip, trigger.Conditions, trigger.IsActive)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create trigger: %w", err)
+		}
+
+		for _, a := range t.Actions {
+			action := &models.WorkflowAction{
+				ActionType:          a.ActionType,
+				ActionConfig:        a.ActionConfig,
+				Urgency:             a.Urgency,
+				RetryMaxAttempts:    a.RetryMaxAttempts,
+				RetryBackoffSeconds: a.RetryBackoffSeconds,
+			}
+			if action.ActionConfig == nil {
+				action.ActionConfig = json.RawMessage("{}")
+			}
+			if err := normalizeActionUrgency(action); err != nil {
+				return nil, err
+			}
+			if err := normalizeActionRetry(action); err != nil {
+				return nil, err
+			}
+			if a.TemplateID != nil {
+				id, ok := templateMap[*a.TemplateID]
+				if !ok {
+					return nil, errors.New("action references a template missing from the export")
+				}
+				action.TemplateID = &id
+			}
+
+			_, err := tx.Exec(ctx, `
+				INSERT INTO workflow_actions (id, trigger_id, action_type, action_order, template_id, action_config, urgency,
+				                              retry_max_attempts, retry_backoff_seconds, is_active)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			`, uuid.New(), triggerID, action.ActionType, a.ActionOrder, action.TemplateID, action.ActionConfig,
+				action.Urgency, action.RetryMaxAttempts, action.RetryBackoffSeconds, a.IsActive)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create action: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	result.Workflow, err = s.GetWorkflowByID(ctx, workflowID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// importWorkflowTemplates maps the export's template IDs to templates of the organization,
+// creating the ones it doesn't have yet
+func importWorkflowTemplates(ctx context.Context, tx pgx.Tx, orgID uuid.UUID, templates []models.WorkflowExportTemplate, result *models.WorkflowImportResult) (map[uuid.UUID]uuid.UUID, error) {
+	templateMap := make(map[uuid.UUID]uuid.UUID, len(templates))
+	for _, t := range templates {
+		if t.Name == "" || t.Body == "" {
+			return nil, errors.New("exported templates need a name and a body")
+		}
+		if t.Channel != models.MessageChannelWhatsApp && t.Channel != models.MessageChannelEmail {
+			return nil, fmt.Errorf("invalid channel '%s' for template '%s'", t.Channel, t.Name)
+		}
+
+		var id uuid.UUID
+		err := tx.QueryRow(ctx, `
+			SELECT id FROM message_templates WHERE organization_id = $1 AND name = $2 AND channel = $3
+		`, orgID, t.Name, t.Channel).Scan(&id)
+		if err == nil {
+			templateMap[t.ID] = id
+			result.TemplatesReused = append(result.TemplatesReused, t.Name)
+			continue
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("failed to look up template '%s': %w", t.Name, err)
+		}
+
+		variables := t.Variables
+		if variables == nil {
+			variables = json.RawMessage("[]")
+		}
+		id = uuid.New()
+		_, err = tx.Exec(ctx, `
+			INSERT INTO message_templates (id, organization_id, name, channel, subject, body, variables, whatsapp_content_sid, is_active)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, true)
+		`, id, orgID, t.Name, t.Channel, t.Subject, t.Body, variables, t.WhatsAppContentSID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create template '%s': %w", t.Name, err)
+		}
+		templateMap[t.ID] = id
+		result.TemplatesCreated = append(result.TemplatesCreated, t.Name)
+	}
+	return templateMap, nil
+}
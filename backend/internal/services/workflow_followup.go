@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// maxFollowUpDelayDays bounds how far after entering a state a follow-up can be scheduled
+const maxFollowUpDelayDays = 365
+
+// FollowUpStep is one reminder of a follow-up sequence. Each step is stored as a
+// time_after trigger on the state with a single send_email or send_whatsapp action.
+type FollowUpStep struct {
+	TriggerID  *uuid.UUID            `json:"trigger_id,omitempty"`
+	DelayDays  int                   `json:"delay_days"`
+	Channel    models.MessageChannel `json:"channel"`
+	TemplateID *uuid.UUID            `json:"template_id,omitempty"`
+	Subject    string                `json:"subject,omitempty"`
+	Body       string                `json:"body,omitempty"`
+	IsActive   bool                  `json:"is_active"`
+}
+
+// GetFollowUpSequence returns the follow-up reminders configured for a workflow state,
+// ordered by delay. Pending reminders stop as soon as the entity leaves the state.
+func (s *WorkflowService) GetFollowUpSequence(ctx context.Context, workflowID, orgID, stateID uuid.UUID) ([]FollowUpStep, error) {
+	workflow, err := s.GetWorkflowByID(ctx, workflowID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !workflowHasState(workflow, stateID) {
+		return nil, errors.New("state not found")
+	}
+
+	steps := []FollowUpStep{}
+	for _, trigger := range workflow.Triggers {
+		if trigger.StateID == nil || *trigger.StateID != stateID || trigger.TriggerType != models.TriggerTypeTimeAfter {
+			continue
+		}
+		delayDays := 0
+		if trigger.TimeOffsetMinutes != nil {
+			delayDays = *trigger.TimeOffsetMinutes / (24 * 60)
+		}
+
+		for _, action := range trigger.Actions {
+			triggerID := trigger.ID
+			step := FollowUpStep{
+				TriggerID:  &triggerID,
+				DelayDays:  delayDays,
+				TemplateID: action.TemplateID,
+				IsActive:   trigger.IsActive && action.IsActive,
+			}
+			switch action.ActionType {
+			case models.ActionTypeSendEmail:
+				step.Channel = models.MessageChannelEmail
+				config := parseActionConfigJSON(action.ActionConfig)
+				step.Subject, _ = config["subject"].(string)
+				step.Body, _ = config["body"].(string)
+			case models.ActionTypeSendWhatsApp:
+				step.Channel = models.MessageChannelWhatsApp
+			default:
+				continue
+			}
+			steps = append(steps, step)
+		}
+	}
+
+	sort.SliceStable(steps, func(i, j int) bool { return steps[i].DelayDays < steps[j].DelayDays })
+	return steps, nil
+}
+
+// SetFollowUpSequence replaces the follow-up reminders of a workflow state.
+// Existing time_after triggers on the state are removed and one trigger is created per step.
+// Reminders already scheduled from the old triggers are dropped with them; the new
+// sequence applies to entities entering the state from now on.
+func (s *WorkflowService) SetFollowUpSequence(ctx context.Context, workflowID, orgID, stateID uuid.UUID, steps []FollowUpStep) ([]FollowUpStep, error) {
+	workflow, err := s.GetWorkflowByID(ctx, workflowID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !workflowHasState(workflow, stateID) {
+		return nil, errors.New("state not found")
+	}
+
+	for i := range steps {
+		if err := s.validateFollowUpStep(ctx, orgID, &steps[i]); err != nil {
+			return nil, fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM workflow_triggers
+		WHERE workflow_id = $1 AND state_id = $2 AND trigger_type = $3
+	`, workflowID, stateID, models.TriggerTypeTimeAfter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove follow-up triggers: %w", err)
+	}
+
+	for _, step := range steps {
+		offset := step.DelayDays * 24 * 60
+		triggerID := uuid.New()
+		_, err := tx.Exec(ctx, `
+			INSERT INTO workflow_triggers (id, workflow_id, state_id, trigger_type, time_offset_minutes, is_active)
+			VALUES ($1, $2, $3, $4, $5, true)
+		`, triggerID, workflowID, stateID, models.TriggerTypeTimeAfter, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create follow-up trigger: %w", err)
+		}
+
+		actionType := models.ActionTypeSendWhatsApp
+		var config json.RawMessage
+		if step.Channel == models.MessageChannelEmail {
+			actionType = models.ActionTypeSendEmail
+			config, err = json.Marshal(map[string]string{
+				"subject":  step.Subject,
+				"body":     step.Body,
+				"to_field": "client_email",
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal action config: %w", err)
+			}
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO workflow_actions (id, trigger_id, action_type, action_order, template_id, action_config, is_active)
+			VALUES ($1, $2, $3, 0, $4, $5, true)
+		`, uuid.New(), triggerID, actionType, step.TemplateID, config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create follow-up action: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return s.GetFollowUpSequence(ctx, workflowID, orgID, stateID)
+}
+
+// validateFollowUpStep checks a follow-up step and its template
+func (s *WorkflowService) validateFollowUpStep(ctx context.Context, orgID uuid.UUID, step *FollowUpStep) error {
+	if step.DelayDays < 1 || step.DelayDays > maxFollowUpDelayDays {
+		return fmt.Errorf("delay must be between 1 and %d days", maxFollowUpDelayDays)
+	}
+
+	switch step.Channel {
+	case models.MessageChannelEmail:
+		if step.TemplateID == nil && step.Body == "" {
+			return errors.New("email follow-ups need a template or a body")
+		}
+	case models.MessageChannelWhatsApp:
+		if step.TemplateID == nil {
+			return errors.New("WhatsApp follow-ups need a template")
+		}
+	default:
+		return fmt.Errorf("invalid channel: %s", step.Channel)
+	}
+
+	if step.TemplateID != nil {
+		template, err := s.GetTemplateByID(ctx, *step.TemplateID, orgID)
+		if err != nil {
+			return err
+		}
+		if template.Channel != step.Channel {
+			return fmt.Errorf("template is not a %s template", step.Channel)
+		}
+	}
+
+	return nil
+}
+
+// workflowHasState returns true if the state belongs to the workflow
+func workflowHasState(workflow *models.Workflow, stateID uuid.UUID) bool {
+	for _, state := range workflow.States {
+		if state.ID == stateID {
+			return true
+		}
+	}
+	return false
+}
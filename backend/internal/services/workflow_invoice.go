@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/controlwise/backend/internal/i18n"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// ============ Invoices ============
+
+// OnInvoiceStateChange triggers workflow actions when an invoice or credit note changes state
+func (s *WorkflowService) OnInvoiceStateChange(ctx context.Context, orgID uuid.UUID, invoiceID uuid.UUID, fromStatus, toStatus string) error {
+	workflow, err := s.GetDefaultWorkflow(ctx, orgID, models.WorkflowModuleInvoices, models.WorkflowEntityInvoice)
+	if err != nil {
+		return fmt.Errorf("failed to get default workflow: %w", err)
+	}
+	if workflow == nil {
+		// No default workflow configured, nothing to do
+		return nil
+	}
+
+	var targetState *models.WorkflowState
+	for i := range workflow.States {
+		if workflow.States[i].Name == toStatus {
+			targetState = &workflow.States[i]
+			break
+		}
+	}
+	if targetState == nil {
+		return nil
+	}
+
+	// Jobs scheduled for the previous state, such as payment reminders, no longer apply
+	if fromStatus != "" {
+		if err := s.cancelPendingJobsForEntity(ctx, "invoice", invoiceID); err != nil {
+			fmt.Printf("Failed to cancel pending jobs: %v\n", err)
+		}
+	}
+
+	for _, trigger := range workflow.Triggers {
+		if trigger.StateID == nil || *trigger.StateID != targetState.ID || !trigger.IsActive {
+			continue
+		}
+
+		switch trigger.TriggerType {
+		case models.TriggerTypeOnEnter:
+			if err := s.scheduleJob(ctx, orgID, trigger.ID, "invoice", invoiceID, time.Now()); err != nil {
+				return fmt.Errorf("failed to schedule on_enter trigger: %w", err)
+			}
+		case models.TriggerTypeTimeAfter:
+			if trigger.TimeOffsetMinutes != nil {
+				executeAt := time.Now().Add(time.Duration(*trigger.TimeOffsetMinutes) * time.Minute)
+				if err := s.scheduleJob(ctx, orgID, trigger.ID, "invoice", invoiceID, executeAt); err != nil {
+					return fmt.Errorf("failed to schedule time_after trigger: %w", err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// CreateDefaultInvoiceWorkflow creates the default workflow for the invoice lifecycle in the
+// context's locale. Issued invoices are emailed to the customer.
+func (s *WorkflowService) CreateDefaultInvoiceWorkflow(ctx context.Context, orgID uuid.UUID) (*models.Workflow, error) {
+	locale := i18n.FromContext(ctx)
+
+	existing, err := s.GetDefaultWorkflow(ctx, orgID, models.WorkflowModuleInvoices, models.WorkflowEntityInvoice)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	workflow := &models.Workflow{
+		OrganizationID: orgID,
+		Name:           i18n.T(locale, "Invoice Lifecycle"),
+		Description:    stringPtr(i18n.T(locale, "Default workflow for managing invoices")),
+		Module:         models.WorkflowModuleInvoices,
+		EntityType:     models.WorkflowEntityInvoice,
+		IsActive:       true,
+		IsDefault:      true,
+	}
+	if err := s.CreateWorkflow(ctx, workflow); err != nil {
+		return nil, fmt.Errorf("failed to create workflow: %w", err)
+	}
+
+	// Create states matching Invoice status enum
+	states := []struct {
+		name        string
+		displayName string
+		description string
+		stateType   models.StateType
+		color       string
+		position    int
+	}{
+		{"draft", "Draft", "Invoice being prepared", models.StateTypeInitial, "#6B7280", 0},
+		{"issued", "Issued", "Invoice issued to the customer", models.StateTypeIntermediate, "#3B82F6", 1},
+		{"paid", "Paid", "Invoice paid", models.StateTypeFinal, "#10B981", 2},
+		{"void", "Void", "Invoice voided", models.StateTypeFinal, "#EF4444", 3},
+	}
+
+	stateMap := make(map[string]uuid.UUID)
+	for _, st := range states {
+		state := &models.WorkflowState{
+			WorkflowID:  workflow.ID,
+			Name:        st.name,
+			DisplayName: i18n.T(locale, st.displayName),
+			Description: stringPtr(i18n.T(locale, st.description)),
+			StateType:   st.stateType,
+			Color:       stringPtr(st.color),
+			Position:    st.position,
+		}
+		if err := s.CreateState(ctx, state); err != nil {
+			return nil, fmt.Errorf("failed to create state %s: %w", st.name, err)
+		}
+		stateMap[st.name] = state.ID
+	}
+
+	transitions := []struct {
+		from, to, name       string
+		requiresConfirmation bool
+	}{
+		{"draft", "issued", "Issue Invoice", true},
+		{"issued", "paid", "Mark as Paid", false},
+		{"issued", "void", "Void Invoice", true},
+	}
+	for _, tr := range transitions {
+		transition := &models.WorkflowTransition{
+			WorkflowID:           workflow.ID,
+			FromStateID:          stateMap[tr.from],
+			ToStateID:            stateMap[tr.to],
+			Name:                 i18n.T(locale, tr.name),
+			RequiresConfirmation: tr.requiresConfirmation,
+		}
+		if err := s.CreateTransition(ctx, transition); err != nil {
+			return nil, fmt.Errorf("failed to create transition %s: %w", tr.name, err)
+		}
+	}
+
+	// Email issued invoices to the customer (on_enter "issued" state)
+	issuedStateID := stateMap["issued"]
+	triggerIssued := &models.WorkflowTrigger{
+		WorkflowID:  workflow.ID,
+		StateID:     &issuedStateID,
+		TriggerType: models.TriggerTypeOnEnter,
+		IsActive:    true,
+	}
+	if err := s.CreateTrigger(ctx, triggerIssued); err != nil {
+		return nil, fmt.Errorf("failed to create issued trigger: %w", err)
+	}
+
+	issuedConfig, err := json.Marshal(map[string]string{
+		"subject":  i18n.T(locale, "Invoice {{invoice_number}}"),
+		"to_field": "client_email",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal issued config: %w", err)
+	}
+	actionIssued := &models.WorkflowAction{
+		TriggerID:    triggerIssued.ID,
+		ActionType:   models.ActionTypeSendEmail,
+		ActionOrder:  0,
+		IsActive:     true,
+		ActionConfig: issuedConfig,
+	}
+	if err := s.CreateAction(ctx, actionIssued); err != nil {
+		return nil, fmt.Errorf("failed to create issued action: %w", err)
+	}
+
+	return s.GetWorkflowByID(ctx, workflow.ID, orgID)
+}
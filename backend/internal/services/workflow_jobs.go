@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ScheduledJobChange is who changes a scheduled job by hand and why
+type ScheduledJobChange struct {
+	UserID uuid.UUID
+	Reason string
+}
+
+// CancelScheduledJob cancels a pending job so its trigger does not run
+func (s *WorkflowService) CancelScheduledJob(ctx context.Context, orgID, jobID uuid.UUID, change ScheduledJobChange) (*models.ScheduledJob, error) {
+	return s.changeScheduledJob(ctx, orgID, jobID, change, models.EventTypeJobCancelled,
+		func(job *models.ScheduledJob) (string, []interface{}, error) {
+			if job.Status != models.JobStatusPending {
+				return "", nil, errors.New("only pending jobs can be cancelled")
+			}
+			return `status = 'cancelled', processed_at = NOW()`, nil, nil
+		})
+}
+
+// RunScheduledJobNow makes a pending job due immediately, or retries a failed one. The worker
+// picks it up on its next pass, within a minute.
+func (s *WorkflowService) RunScheduledJobNow(ctx context.Context, orgID, jobID uuid.UUID, change ScheduledJobChange) (*models.ScheduledJob, error) {
+	return s.changeScheduledJob(ctx, orgID, jobID, change, models.EventTypeJobRunNow,
+		func(job *models.ScheduledJob) (string, []interface{}, error) {
+			if job.Status != models.JobStatusPending && job.Status != models.JobStatusFailed {
+				return "", nil, errors.New("only pending or failed jobs can be run")
+			}
+			return `status = 'pending', scheduled_for = NOW(), last_error = NULL, processed_at = NULL`, nil, nil
+		})
+}
+
+// RescheduleScheduledJob moves a pending or failed job to a new time
+func (s *WorkflowService) RescheduleScheduledJob(ctx context.Context, orgID, jobID uuid.UUID, at time.Time, change ScheduledJobChange) (*models.ScheduledJob, error) {
+	if !at.After(time.Now()) {
+		return nil, errors.New("new time must be in the future")
+	}
+	return s.changeScheduledJob(ctx, orgID, jobID, change, models.EventTypeJobRescheduled,
+		func(job *models.ScheduledJob) (string, []interface{}, error) {
+			if job.Status != models.JobStatusPending && job.Status != models.JobStatusFailed {
+				return "", nil, errors.New("only pending or failed jobs can be rescheduled")
+			}
+			return `status = 'pending', scheduled_for = $3, last_error = NULL, processed_at = NULL`, []interface{}{at}, nil
+		})
+}
+
+// changeScheduledJob locks a job, applies the SET clause returned by apply and records the change
+// in the execution log of the job's workflow
+func (s *WorkflowService) changeScheduledJob(ctx context.Context, orgID, jobID uuid.UUID, change ScheduledJobChange, eventType models.EventType,
+	apply func(job *models.ScheduledJob) (string, []interface{}, error)) (*models.ScheduledJob, error) {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var job models.ScheduledJob
+	var workflowID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		SELECT j.id, j.organization_id, j.trigger_id, j.entity_type, j.entity_id,
+		       j.scheduled_for, j.status, j.attempts, j.last_error, j.created_at, j.processed_at,
+		       t.workflow_id
+		FROM scheduled_jobs j
+		JOIN workflow_triggers t ON t.id = j.trigger_id
+		WHERE j.id = $1 AND j.organization_id = $2
+		FOR UPDATE OF j
+	`, jobID, orgID).Scan(
+		&job.ID, &job.OrganizationID, &job.TriggerID, &job.EntityType, &job.EntityID,
+		&job.ScheduledFor, &job.Status, &job.Attempts, &job.LastError, &job.CreatedAt, &job.ProcessedAt,
+		&workflowID,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("scheduled job not found")
+		}
+		return nil, fmt.Errorf("failed to get scheduled job: %w", err)
+	}
+
+	set, args, err := apply(&job)
+	if err != nil {
+		return nil, err
+	}
+	previousStatus, previousTime := job.Status, job.ScheduledFor
+
+	err = tx.QueryRow(ctx, `
+		UPDATE scheduled_jobs SET `+set+`
+		WHERE id = $1 AND organization_id = $2
+		RETURNING scheduled_for, status, last_error, processed_at
+	`, append([]interface{}{jobID, orgID}, args...)...).Scan(&job.ScheduledFor, &job.Status, &job.LastError, &job.ProcessedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update scheduled job: %w", err)
+	}
+
+	details := map[string]interface{}{
+		"job_id":                 job.ID,
+		"trigger_id":             job.TriggerID,
+		"changed_by":             change.UserID,
+		"previous_status":        previousStatus,
+		"previous_scheduled_for": previousTime,
+		"scheduled_for":          job.ScheduledFor,
+	}
+	if reason := strings.TrimSpace(change.Reason); reason != "" {
+		details["reason"] = reason
+	}
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job change: %w", err)
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO workflow_execution_log
+		(id, organization_id, workflow_id, entity_type, entity_id, trigger_id, event_type, details)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, uuid.New(), orgID, workflowID, job.EntityType, job.EntityID, job.TriggerID, eventType, detailsJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record job change: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return &job, nil
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+)
+
+// validateRecurringTrigger checks the cron expression and skip rule of a recurring trigger.
+// An empty skip rule defaults to none.
+func validateRecurringTrigger(trigger *models.WorkflowTrigger) error {
+	if trigger.RecurringSkip == "" {
+		trigger.RecurringSkip = models.RecurringSkipNone
+	}
+	if !trigger.RecurringSkip.IsValid() {
+		return errors.New("recurring_skip must be one of none, weekends, holidays, business_days")
+	}
+
+	if trigger.TriggerType != models.TriggerTypeRecurring {
+		return nil
+	}
+	if trigger.RecurringCron == nil || *trigger.RecurringCron == "" {
+		return errors.New("recurring trigger requires recurring_cron")
+	}
+	if _, err := cron.ParseStandard(*trigger.RecurringCron); err != nil {
+		return fmt.Errorf("invalid recurring_cron: %w", err)
+	}
+
+	return nil
+}
+
+// ============ Organization Holidays ============
+
+// ListHolidays returns the organization's holidays within an optional year
+func (s *WorkflowService) ListHolidays(ctx context.Context, orgID uuid.UUID, year int) ([]*models.OrganizationHoliday, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, organization_id, date, name, created_at
+		FROM organization_holidays
+		WHERE organization_id = $1 AND ($2 = 0 OR EXTRACT(YEAR FROM date) = $2)
+		ORDER BY date ASC
+	`, orgID, year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list holidays: %w", err)
+	}
+	defer rows.Close()
+
+	holidays := []*models.OrganizationHoliday{}
+	for rows.Next() {
+		var h models.OrganizationHoliday
+		if err := rows.Scan(&h.ID, &h.OrganizationID, &h.Date, &h.Name, &h.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan holiday: %w", err)
+		}
+		holidays = append(holidays, &h)
+	}
+
+	return holidays, nil
+}
+
+// CreateHoliday adds a day on which holiday-aware recurring triggers are skipped
+func (s *WorkflowService) CreateHoliday(ctx context.Context, holiday *models.OrganizationHoliday) error {
+	if holiday.Name == "" {
+		return errors.New("name is required")
+	}
+	if holiday.Date.IsZero() {
+		return errors.New("date is required")
+	}
+
+	var exists bool
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM organization_holidays WHERE organization_id = $1 AND date = $2)
+	`, holiday.OrganizationID, holiday.Date).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check holiday: %w", err)
+	}
+	if exists {
+		return errors.New("a holiday already exists on this date")
+	}
+
+	holiday.ID = uuid.New()
+	err = s.db.Pool.QueryRow(ctx, `
+		INSERT INTO organization_holidays (id, organization_id, date, name)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at
+	`, holiday.ID, holiday.OrganizationID, holiday.Date, holiday.Name).Scan(&holiday.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create holiday: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteHoliday removes an organization holiday
+func (s *WorkflowService) DeleteHoliday(ctx context.Context, id, orgID uuid.UUID) error {
+	result, err := s.db.Pool.Exec(ctx, `
+		DELETE FROM organization_holidays WHERE id = $1 AND organization_id = $2
+	`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to delete holiday: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("holiday not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,420 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/workflow"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// replayExecutionWindow is how long after a trigger fired its actions and messages are looked for
+const replayExecutionWindow = 5 * time.Minute
+
+// ExecutionReplayRequest identifies the execution to replay: a logged trigger or action event,
+// or a trigger and entity when the trigger left no trace (e.g. its job never ran)
+type ExecutionReplayRequest struct {
+	LogID      *uuid.UUID `json:"log_id"`
+	TriggerID  *uuid.UUID `json:"trigger_id"`
+	EntityType string     `json:"entity_type"`
+	EntityID   *uuid.UUID `json:"entity_id"`
+}
+
+// ReplayedAction is what an action of the original execution did
+type ReplayedAction struct {
+	ActionID   uuid.UUID         `json:"action_id"`
+	ActionType models.ActionType `json:"action_type"`
+	Outcome    string            `json:"outcome"` // executed, held, failed or not_run
+	Error      string            `json:"error,omitempty"`
+	At         *time.Time        `json:"at,omitempty"`
+}
+
+// ReplayedMessage is a message sent to the entity around the original execution
+type ReplayedMessage struct {
+	Channel   string    `json:"channel"` // whatsapp or email
+	Recipient string    `json:"recipient"`
+	Subject   string    `json:"subject,omitempty"`
+	Body      string    `json:"body"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ExecutionReplayOriginal is what the execution did at the time, as recorded in the log
+type ExecutionReplayOriginal struct {
+	LogID    *uuid.UUID        `json:"log_id,omitempty"`
+	At       *time.Time        `json:"at,omitempty"`
+	Outcome  string            `json:"outcome"` // fired, skipped or not_logged
+	Reason   string            `json:"reason,omitempty"`
+	Actions  []ReplayedAction  `json:"actions"`
+	Messages []ReplayedMessage `json:"messages"`
+}
+
+// ExecutionReplayNow is what the trigger would do against the entity's current data.
+// Nothing is sent or changed.
+type ExecutionReplayNow struct {
+	TriggerExists     bool                       `json:"trigger_exists"`
+	TriggerActive     bool                       `json:"trigger_active"`
+	ConditionsMatched bool                       `json:"conditions_matched"`
+	Conditions        []workflow.ConditionResult `json:"conditions"`
+	Outcome           string                     `json:"outcome"` // would_fire, would_skip or would_not_run
+	EntityData        map[string]interface{}     `json:"entity_data"`
+	Actions           []*ActionTestResult        `json:"actions"`
+}
+
+// ExecutionReplayDifference is something that differs between the original execution and now
+type ExecutionReplayDifference struct {
+	Field    string     `json:"field"` // e.g. outcome, action, recipient, body
+	ActionID *uuid.UUID `json:"action_id,omitempty"`
+	Then     string     `json:"then"`
+	Now      string     `json:"now"`
+}
+
+// ExecutionReplay compares a past trigger execution with a dry run against current data
+type ExecutionReplay struct {
+	WorkflowID  uuid.UUID                   `json:"workflow_id"`
+	TriggerID   uuid.UUID                   `json:"trigger_id"`
+	TriggerType models.TriggerType          `json:"trigger_type,omitempty"`
+	EntityType  string                      `json:"entity_type"`
+	EntityID    uuid.UUID                   `json:"entity_id"`
+	Original    ExecutionReplayOriginal     `json:"original"`
+	Now         ExecutionReplayNow          `json:"now"`
+	Differences []ExecutionReplayDifference `json:"differences"`
+}
+
+// ReplayExecution re-runs a past trigger execution against the entity's current data in
+// dry-run mode and reports what would differ now: condition results, rendered content and
+// recipients. It answers questions such as why a patient did not get last week's reminder.
+func (s *WorkflowService) ReplayExecution(ctx context.Context, orgID uuid.UUID, req ExecutionReplayRequest) (*ExecutionReplay, error) {
+	replay := &ExecutionReplay{
+		Original:    ExecutionReplayOriginal{Outcome: "not_logged", Actions: []ReplayedAction{}, Messages: []ReplayedMessage{}},
+		Differences: []ExecutionReplayDifference{},
+	}
+
+	var anchor *models.WorkflowExecutionLog
+	switch {
+	case req.LogID != nil:
+		entry, triggerID, err := s.replayAnchor(ctx, orgID, *req.LogID)
+		if err != nil {
+			return nil, err
+		}
+		anchor = entry
+		replay.WorkflowID, replay.TriggerID = entry.WorkflowID, triggerID
+		replay.EntityType, replay.EntityID = entry.EntityType, entry.EntityID
+	case req.TriggerID != nil && req.EntityID != nil && req.EntityType != "":
+		err := s.db.Pool.QueryRow(ctx, `
+			SELECT t.workflow_id FROM workflow_triggers t
+			JOIN workflows w ON w.id = t.workflow_id
+			WHERE t.id = $1 AND w.organization_id = $2
+		`, *req.TriggerID, orgID).Scan(&replay.WorkflowID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("trigger not found")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get trigger: %w", err)
+		}
+		replay.TriggerID = *req.TriggerID
+		replay.EntityType, replay.EntityID = req.EntityType, *req.EntityID
+	default:
+		return nil, errors.New("log_id, or trigger_id with entity_type and entity_id, is required")
+	}
+
+	wf, err := s.GetWorkflowByID(ctx, replay.WorkflowID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	var trigger *models.WorkflowTrigger
+	for i := range wf.Triggers {
+		if wf.Triggers[i].ID == replay.TriggerID {
+			trigger = &wf.Triggers[i]
+			replay.TriggerType = trigger.TriggerType
+		}
+	}
+
+	if anchor != nil {
+		if err := s.replayOriginal(ctx, orgID, replay, anchor, trigger); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := workflow.NewExecutor(s.db).EntityData(ctx, orgID, replay.EntityType, replay.EntityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load entity data: %w", err)
+	}
+	replay.Now = ExecutionReplayNow{
+		EntityData: data,
+		Conditions: []workflow.ConditionResult{},
+		Actions:    []*ActionTestResult{},
+		Outcome:    "would_not_run",
+	}
+	if trigger != nil {
+		replay.Now.TriggerExists = true
+		replay.Now.TriggerActive = trigger.IsActive
+		matched, results, err := workflow.ExplainConditions(trigger.Conditions, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate trigger conditions: %w", err)
+		}
+		replay.Now.ConditionsMatched, replay.Now.Conditions = matched, results
+
+		switch {
+		case !trigger.IsActive:
+		case !matched:
+			replay.Now.Outcome = "would_skip"
+		default:
+			replay.Now.Outcome = "would_fire"
+			for i := range trigger.Actions {
+				if trigger.Actions[i].IsActive {
+					replay.Now.Actions = append(replay.Now.Actions, s.previewAction(ctx, orgID, &trigger.Actions[i], data))
+				}
+			}
+		}
+	}
+
+	replay.Differences = replayDifferences(replay, trigger)
+	return replay, nil
+}
+
+// replayAnchor loads a logged trigger or action event and the trigger it belongs to
+func (s *WorkflowService) replayAnchor(ctx context.Context, orgID, logID uuid.UUID) (*models.WorkflowExecutionLog, uuid.UUID, error) {
+	entry := &models.WorkflowExecutionLog{}
+	var triggerID *uuid.UUID
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT l.id, l.organization_id, l.workflow_id, l.entity_type, l.entity_id,
+		       COALESCE(l.trigger_id, (l.details->>'trigger_id')::uuid, a.trigger_id),
+		       COALESCE(l.action_id, (l.details->>'action_id')::uuid),
+		       l.event_type, l.details, l.created_at
+		FROM workflow_execution_log l
+		LEFT JOIN workflow_actions a ON a.id = COALESCE(l.action_id, (l.details->>'action_id')::uuid)
+		WHERE l.id = $1 AND l.organization_id = $2
+	`, logID, orgID).Scan(&entry.ID, &entry.OrganizationID, &entry.WorkflowID, &entry.EntityType, &entry.EntityID,
+		&triggerID, &entry.ActionID, &entry.EventType, &entry.Details, &entry.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, uuid.Nil, errors.New("execution log not found")
+	}
+	if err != nil {
+		return nil, uuid.Nil, fmt.Errorf("failed to get execution log: %w", err)
+	}
+	if triggerID == nil {
+		return nil, uuid.Nil, errors.New("execution log is not a trigger or action event")
+	}
+	entry.TriggerID = triggerID
+	return entry, *triggerID, nil
+}
+
+// replayOriginal rebuilds the original execution from the log: the trigger outcome, what each
+// action did and the messages the entity was sent
+func (s *WorkflowService) replayOriginal(ctx context.Context, orgID uuid.UUID, replay *ExecutionReplay, anchor *models.WorkflowExecutionLog, trigger *models.WorkflowTrigger) error {
+	original := &replay.Original
+	original.LogID = &anchor.ID
+
+	// Action events point back to the trigger event that preceded them
+	start := anchor.CreatedAt
+	switch anchor.EventType {
+	case models.EventTypeTriggerFired:
+		original.Outcome = "fired"
+	case models.EventTypeTriggerSkipped:
+		original.Outcome = "skipped"
+		var details struct {
+			Reason string `json:"reason"`
+		}
+		_ = json.Unmarshal(anchor.Details, &details)
+		original.Reason = details.Reason
+	default:
+		original.Outcome = "fired"
+		var firedAt time.Time
+		err := s.db.Pool.QueryRow(ctx, `
+			SELECT created_at FROM workflow_execution_log
+			WHERE organization_id = $1 AND workflow_id = $2 AND entity_type = $3 AND entity_id = $4
+			AND event_type = $5 AND details->>'trigger_id' = $6
+			AND created_at <= $7 AND created_at > $8
+			ORDER BY created_at DESC LIMIT 1
+		`, orgID, anchor.WorkflowID, anchor.EntityType, anchor.EntityID, models.EventTypeTriggerFired,
+			replay.TriggerID.String(), anchor.CreatedAt, anchor.CreatedAt.Add(-replayExecutionWindow)).Scan(&firedAt)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("failed to find trigger event: %w", err)
+		}
+		if err == nil {
+			start = firedAt
+		}
+	}
+	original.At = &start
+	end := start.Add(replayExecutionWindow)
+
+	if original.Outcome == "fired" && trigger != nil {
+		actions := map[uuid.UUID]*ReplayedAction{}
+		for i := range trigger.Actions {
+			a := &trigger.Actions[i]
+			original.Actions = append(original.Actions, ReplayedAction{ActionID: a.ID, ActionType: a.ActionType, Outcome: "not_run"})
+		}
+		for i := range original.Actions {
+			actions[original.Actions[i].ActionID] = &original.Actions[i]
+		}
+
+		rows, err := s.db.Pool.Query(ctx, `
+			SELECT (details->>'action_id')::uuid, event_type, details, created_at
+			FROM workflow_execution_log
+			WHERE organization_id = $1 AND workflow_id = $2 AND entity_type = $3 AND entity_id = $4
+			AND event_type IN ($5, $6) AND details ? 'action_id'
+			AND created_at >= $7 AND created_at < $8
+			ORDER BY created_at
+		`, orgID, anchor.WorkflowID, anchor.EntityType, anchor.EntityID,
+			models.EventTypeActionExecuted, models.EventTypeActionFailed, start, end)
+		if err != nil {
+			return fmt.Errorf("failed to query action events: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var actionID uuid.UUID
+			var eventType models.EventType
+			var raw json.RawMessage
+			var at time.Time
+			if err := rows.Scan(&actionID, &eventType, &raw, &at); err != nil {
+				return fmt.Errorf("failed to scan action event: %w", err)
+			}
+			a, ok := actions[actionID]
+			if !ok || a.At != nil {
+				continue
+			}
+			var details struct {
+				Error    string                     `json:"error"`
+				Delivery *workflow.DeliveryDecision `json:"delivery"`
+			}
+			_ = json.Unmarshal(raw, &details)
+			a.At = &at
+			a.Outcome = "executed"
+			if eventType == models.EventTypeActionFailed {
+				a.Outcome, a.Error = "failed", details.Error
+			} else if details.Delivery != nil && details.Delivery.Outcome == "held" {
+				a.Outcome = "held"
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to read action events: %w", err)
+		}
+	}
+
+	// Messages are linked to sessions only
+	if replay.EntityType != string(models.WorkflowEntitySession) {
+		return nil
+	}
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT 'whatsapp', phone_number, '', COALESCE(message_content, ''), COALESCE(status, ''), created_at
+		FROM whatsapp_messages
+		WHERE organization_id = $1 AND session_id = $2 AND direction = 'outbound'
+		AND created_at >= $3 AND created_at < $4
+		UNION ALL
+		SELECT 'email', to_address, COALESCE(subject, ''), COALESCE(message_content, ''), status, created_at
+		FROM email_messages
+		WHERE organization_id = $1 AND session_id = $2
+		AND created_at >= $3 AND created_at < $4
+		ORDER BY 6
+	`, orgID, replay.EntityID, start, end)
+	if err != nil {
+		return fmt.Errorf("failed to query messages: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m ReplayedMessage
+		if err := rows.Scan(&m.Channel, &m.Recipient, &m.Subject, &m.Body, &m.Status, &m.CreatedAt); err != nil {
+			return fmt.Errorf("failed to scan message: %w", err)
+		}
+		original.Messages = append(original.Messages, m)
+	}
+	return rows.Err()
+}
+
+// replayDifferences lists what changed between the original execution and the dry run
+func replayDifferences(replay *ExecutionReplay, trigger *models.WorkflowTrigger) []ExecutionReplayDifference {
+	diffs := []ExecutionReplayDifference{}
+	original, now := &replay.Original, &replay.Now
+
+	thenOutcome := original.Outcome
+	if original.Reason != "" {
+		thenOutcome += " (" + original.Reason + ")"
+	}
+	nowOutcome := now.Outcome
+	switch {
+	case !now.TriggerExists:
+		nowOutcome += " (trigger deleted)"
+	case !now.TriggerActive:
+		nowOutcome += " (trigger inactive)"
+	}
+	outcomes := map[string]string{"fired": "would_fire", "skipped": "would_skip"}
+	if outcomes[original.Outcome] != now.Outcome {
+		diffs = append(diffs, ExecutionReplayDifference{Field: "outcome", Then: thenOutcome, Now: nowOutcome})
+	}
+
+	// Actions that ran then but would not now, and the other way round
+	previews := map[uuid.UUID]*ActionTestResult{}
+	for _, p := range now.Actions {
+		previews[p.Action.ID] = p
+	}
+	for i := range original.Actions {
+		a := &original.Actions[i]
+		actionID := a.ActionID
+		_, wouldRun := previews[actionID]
+		if a.Outcome != "not_run" && !wouldRun && now.Outcome == "would_fire" {
+			diffs = append(diffs, ExecutionReplayDifference{Field: "action", ActionID: &actionID, Then: a.Outcome, Now: "inactive or removed"})
+		}
+		if a.Outcome != "executed" && wouldRun {
+			then := a.Outcome
+			if a.Error != "" {
+				then += ": " + a.Error
+			}
+			diffs = append(diffs, ExecutionReplayDifference{Field: "action", ActionID: &actionID, Then: then, Now: "would run"})
+		}
+	}
+	if trigger != nil && original.Outcome == "fired" {
+		for _, p := range now.Actions {
+			found := false
+			for _, a := range original.Actions {
+				found = found || a.ActionID == p.Action.ID
+			}
+			if !found {
+				actionID := p.Action.ID
+				diffs = append(diffs, ExecutionReplayDifference{Field: "action", ActionID: &actionID, Then: "did not exist", Now: "would run"})
+			}
+		}
+	}
+
+	// Messages sent then against the messages the actions would render now, per channel in order
+	sent := map[string][]ReplayedMessage{}
+	for _, m := range original.Messages {
+		sent[m.Channel] = append(sent[m.Channel], m)
+	}
+	for _, p := range now.Actions {
+		channel := ""
+		switch p.Action.ActionType {
+		case models.ActionTypeSendWhatsApp:
+			channel = "whatsapp"
+		case models.ActionTypeSendEmail:
+			channel = "email"
+		default:
+			continue
+		}
+		actionID := p.Action.ID
+		if len(sent[channel]) == 0 {
+			if original.Outcome != "not_logged" {
+				diffs = append(diffs, ExecutionReplayDifference{Field: "message", ActionID: &actionID, Then: "no " + channel + " message sent", Now: "would send to " + p.Recipient})
+			}
+			continue
+		}
+		m := sent[channel][0]
+		sent[channel] = sent[channel][1:]
+		if m.Recipient != p.Recipient {
+			diffs = append(diffs, ExecutionReplayDifference{Field: "recipient", ActionID: &actionID, Then: m.Recipient, Now: p.Recipient})
+		}
+		if channel == "email" && m.Subject != p.RenderedSubject {
+			diffs = append(diffs, ExecutionReplayDifference{Field: "subject", ActionID: &actionID, Then: m.Subject, Now: p.RenderedSubject})
+		}
+		if strings.TrimSpace(m.Body) != strings.TrimSpace(p.RenderedBody) {
+			diffs = append(diffs, ExecutionReplayDifference{Field: "body", ActionID: &actionID, Then: m.Body, Now: p.RenderedBody})
+		}
+	}
+	return diffs
+}
@@ -0,0 +1,278 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/controlwise/backend/internal/workflow"
+	"github.com/google/uuid"
+)
+
+// Events of a workflow simulation timeline
+const (
+	SimulationStateEntered   = "state_entered"
+	SimulationJobScheduled   = "job_scheduled"
+	SimulationJobCancelled   = "job_cancelled"
+	SimulationTriggerFired   = "trigger_fired"
+	SimulationTriggerSkipped = "trigger_skipped"
+)
+
+// WorkflowSimulationStep is a state the hypothetical entity moves to
+type WorkflowSimulationStep struct {
+	State string     `json:"state"`
+	At    *time.Time `json:"at"` // defaults to the previous step's time
+}
+
+// WorkflowSimulationRequest describes a hypothetical entity and the states it goes through
+type WorkflowSimulationRequest struct {
+	Entity      map[string]interface{}   `json:"entity"`       // overrides the sample data
+	ScheduledAt *time.Time               `json:"scheduled_at"` // session time, defaults to a week after the start
+	StartAt     *time.Time               `json:"start_at"`     // defaults to now
+	Steps       []WorkflowSimulationStep `json:"steps"`
+}
+
+// WorkflowSimulationEvent is an entry of the simulated timeline
+type WorkflowSimulationEvent struct {
+	At          time.Time           `json:"at"`
+	Event       string              `json:"event"`
+	State       string              `json:"state,omitempty"`
+	TriggerID   *uuid.UUID          `json:"trigger_id,omitempty"`
+	TriggerType models.TriggerType  `json:"trigger_type,omitempty"`
+	Reason      string              `json:"reason,omitempty"`
+	Actions     []*ActionTestResult `json:"actions,omitempty"`
+}
+
+// WorkflowSimulationJob is a job the simulation would have scheduled
+type WorkflowSimulationJob struct {
+	TriggerID    uuid.UUID          `json:"trigger_id"`
+	TriggerType  models.TriggerType `json:"trigger_type"`
+	State        string             `json:"state"`
+	ScheduledFor time.Time          `json:"scheduled_for"`
+	Status       string             `json:"status"` // executed, cancelled or pending
+}
+
+// WorkflowSimulationResult is the full timeline of a simulated entity lifecycle
+type WorkflowSimulationResult struct {
+	WorkflowID uuid.UUID                 `json:"workflow_id"`
+	EntityType string                    `json:"entity_type"`
+	EntityData map[string]interface{}    `json:"entity_data"`
+	Timeline   []WorkflowSimulationEvent `json:"timeline"`
+	Jobs       []*WorkflowSimulationJob  `json:"jobs"`
+}
+
+// SimulateWorkflow runs a hypothetical entity through a sequence of states and returns the
+// triggers that would fire, the jobs that would be scheduled and the messages that would be sent.
+// Nothing is written: jobs are scheduled in memory the same way state changes schedule them.
+func (s *WorkflowService) SimulateWorkflow(ctx context.Context, orgID, workflowID uuid.UUID, req WorkflowSimulationRequest) (*WorkflowSimulationResult, error) {
+	if len(req.Steps) == 0 {
+		return nil, errors.New("at least one step is required")
+	}
+
+	wf, err := s.GetWorkflowByID(ctx, workflowID, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	if req.StartAt != nil {
+		start = *req.StartAt
+	}
+	scheduledAt := start.Add(7 * 24 * time.Hour)
+	if req.ScheduledAt != nil {
+		scheduledAt = *req.ScheduledAt
+	}
+
+	entityType := string(wf.EntityType)
+	data := GetSampleDataForEntityType(entityType)
+	if wf.EntityType == models.WorkflowEntitySession {
+		loc := orgLocation(ctx, s.db.Pool, orgID)
+		data["scheduled_at"] = scheduledAt.In(loc)
+		data["session_date"], data["session_time"] = workflow.SessionTimeVars(scheduledAt, loc)
+	}
+	for key, value := range req.Entity {
+		data[key] = value
+	}
+
+	states := make(map[string]*models.WorkflowState, len(wf.States))
+	stateNames := make(map[uuid.UUID]string, len(wf.States))
+	for i := range wf.States {
+		states[wf.States[i].Name] = &wf.States[i]
+		stateNames[wf.States[i].ID] = wf.States[i].Name
+	}
+	triggers := make(map[uuid.UUID]*models.WorkflowTrigger, len(wf.Triggers))
+	for i := range wf.Triggers {
+		triggers[wf.Triggers[i].ID] = &wf.Triggers[i]
+	}
+
+	result := &WorkflowSimulationResult{
+		WorkflowID: wf.ID,
+		EntityType: entityType,
+		EntityData: data,
+		Timeline:   []WorkflowSimulationEvent{},
+		Jobs:       []*WorkflowSimulationJob{},
+	}
+
+	// runDue fires the pending jobs due by the given time, in time order
+	runDue := func(until *time.Time) error {
+		sort.SliceStable(result.Jobs, func(i, j int) bool {
+			return result.Jobs[i].ScheduledFor.Before(result.Jobs[j].ScheduledFor)
+		})
+		for _, job := range result.Jobs {
+			if job.Status != "pending" || (until != nil && job.ScheduledFor.After(*until)) {
+				continue
+			}
+			job.Status = "executed"
+			event, err := s.simulateTrigger(ctx, orgID, triggers[job.TriggerID], job, data)
+			if err != nil {
+				return err
+			}
+			result.Timeline = append(result.Timeline, event)
+		}
+		return nil
+	}
+
+	at := start
+	previous := ""
+	for i, step := range req.Steps {
+		if step.At != nil {
+			if step.At.Before(at) {
+				return nil, fmt.Errorf("step %d is before the previous step", i+1)
+			}
+			at = *step.At
+		}
+		stepAt := at
+		if err := runDue(&stepAt); err != nil {
+			return nil, err
+		}
+
+		// Leaving a state cancels the entity's pending jobs
+		if i > 0 {
+			for _, job := range result.Jobs {
+				if job.Status != "pending" {
+					continue
+				}
+				job.Status = "cancelled"
+				triggerID := job.TriggerID
+				result.Timeline = append(result.Timeline, WorkflowSimulationEvent{
+					At: at, Event: SimulationJobCancelled, State: job.State,
+					TriggerID: &triggerID, TriggerType: job.TriggerType,
+					Reason: fmt.Sprintf("left state %s", previous),
+				})
+			}
+		}
+
+		data["status"] = step.State
+		entered := WorkflowSimulationEvent{At: at, Event: SimulationStateEntered, State: step.State}
+		state, ok := states[step.State]
+		switch {
+		case !ok:
+			entered.Reason = "state is not part of the workflow, no triggers run"
+		case previous != "" && !hasTransition(wf, stateNames, previous, step.State):
+			entered.Reason = fmt.Sprintf("the workflow has no transition from %s", previous)
+		}
+		result.Timeline = append(result.Timeline, entered)
+		previous = step.State
+		if !ok {
+			continue
+		}
+
+		for j := range wf.Triggers {
+			trigger := &wf.Triggers[j]
+			if trigger.StateID == nil || *trigger.StateID != state.ID || !trigger.IsActive {
+				continue
+			}
+			runAt, scheduled := simulatedJobTime(wf.EntityType, trigger, at, scheduledAt)
+			if !scheduled {
+				continue
+			}
+			job := &WorkflowSimulationJob{
+				TriggerID: trigger.ID, TriggerType: trigger.TriggerType,
+				State: step.State, ScheduledFor: runAt, Status: "pending",
+			}
+			result.Jobs = append(result.Jobs, job)
+			triggerID := trigger.ID
+			result.Timeline = append(result.Timeline, WorkflowSimulationEvent{
+				At: at, Event: SimulationJobScheduled, State: step.State,
+				TriggerID: &triggerID, TriggerType: trigger.TriggerType,
+				Reason: fmt.Sprintf("runs at %s", runAt.Format(time.RFC3339)),
+			})
+		}
+	}
+
+	// Jobs still pending after the last step run when they are due
+	if err := runDue(nil); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(result.Timeline, func(i, j int) bool {
+		return result.Timeline[i].At.Before(result.Timeline[j].At)
+	})
+	return result, nil
+}
+
+// simulatedJobTime computes when a state trigger's job would run, following the scheduling of
+// session, budget and project state changes
+func simulatedJobTime(entityType models.WorkflowEntityType, trigger *models.WorkflowTrigger, enteredAt, scheduledAt time.Time) (time.Time, bool) {
+	switch trigger.TriggerType {
+	case models.TriggerTypeOnEnter:
+		return enteredAt, true
+	case models.TriggerTypeTimeBefore:
+		if entityType != models.WorkflowEntitySession || trigger.TimeOffsetMinutes == nil {
+			return time.Time{}, false
+		}
+		runAt := scheduledAt.Add(-time.Duration(*trigger.TimeOffsetMinutes) * time.Minute)
+		return runAt, runAt.After(enteredAt)
+	case models.TriggerTypeTimeAfter:
+		if trigger.TimeOffsetMinutes == nil {
+			return time.Time{}, false
+		}
+		offset := time.Duration(*trigger.TimeOffsetMinutes) * time.Minute
+		if entityType == models.WorkflowEntitySession {
+			return scheduledAt.Add(offset), true
+		}
+		return enteredAt.Add(offset), true
+	}
+	return time.Time{}, false
+}
+
+// simulateTrigger evaluates a due job's trigger against the entity data and renders its actions
+func (s *WorkflowService) simulateTrigger(ctx context.Context, orgID uuid.UUID, trigger *models.WorkflowTrigger, job *WorkflowSimulationJob, data map[string]interface{}) (WorkflowSimulationEvent, error) {
+	triggerID := trigger.ID
+	event := WorkflowSimulationEvent{
+		At: job.ScheduledFor, Event: SimulationTriggerFired, State: job.State,
+		TriggerID: &triggerID, TriggerType: trigger.TriggerType,
+	}
+
+	if len(trigger.Conditions) > 0 {
+		matched, err := workflow.EvaluateConditions(trigger.Conditions, data)
+		if err != nil {
+			return event, fmt.Errorf("failed to evaluate trigger conditions: %w", err)
+		}
+		if !matched {
+			event.Event = SimulationTriggerSkipped
+			event.Reason = "conditions not met"
+			return event, nil
+		}
+	}
+
+	for i := range trigger.Actions {
+		if trigger.Actions[i].IsActive {
+			event.Actions = append(event.Actions, s.previewAction(ctx, orgID, &trigger.Actions[i], data))
+		}
+	}
+	return event, nil
+}
+
+// hasTransition reports whether the workflow declares a transition between two states
+func hasTransition(wf *models.Workflow, stateNames map[uuid.UUID]string, from, to string) bool {
+	for _, t := range wf.Transitions {
+		if stateNames[t.FromStateID] == from && stateNames[t.ToStateID] == to {
+			return true
+		}
+	}
+	return false
+}
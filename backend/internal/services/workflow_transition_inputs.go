@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	apperrors "github.com/controlwise/backend/internal/errors"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// TransitionInputs is a status change through a default workflow transition, with the inputs
+// validated against the transition's declaration
+type TransitionInputs struct {
+	WorkflowID uuid.UUID
+	Transition *models.WorkflowTransition // nil when no transition connects the two statuses
+	FromStatus string
+	ToStatus   string
+	Values     map[string]interface{}
+}
+
+// PrepareTransition validates the inputs of a status change against the transition between the
+// two statuses in the default workflow. A nil inputs map marks a change made by the system, whose
+// inputs are not required.
+func (s *WorkflowService) PrepareTransition(ctx context.Context, orgID uuid.UUID, module models.WorkflowModule, entityType models.WorkflowEntityType, fromStatus, toStatus string, inputs map[string]interface{}) (*TransitionInputs, error) {
+	workflow, err := s.GetDefaultWorkflow(ctx, orgID, module, entityType)
+	if err != nil {
+		return nil, err
+	}
+	if workflow == nil {
+		return nil, nil
+	}
+
+	prepared := &TransitionInputs{WorkflowID: workflow.ID, FromStatus: fromStatus, ToStatus: toStatus}
+	stateNames := make(map[uuid.UUID]string, len(workflow.States))
+	for _, state := range workflow.States {
+		stateNames[state.ID] = state.Name
+	}
+	for i := range workflow.Transitions {
+		t := &workflow.Transitions[i]
+		if stateNames[t.FromStateID] == fromStatus && stateNames[t.ToStateID] == toStatus {
+			prepared.Transition = t
+			break
+		}
+	}
+	if prepared.Transition == nil {
+		return prepared, nil
+	}
+
+	values, err := validateTransitionInputs(prepared.Transition.Inputs, inputs)
+	if err != nil {
+		return nil, err
+	}
+	prepared.Values = values
+	return prepared, nil
+}
+
+// validateTransitionInputs checks submitted values against the declared inputs, keeping only
+// declared ones
+func validateTransitionInputs(declared []models.TransitionInput, inputs map[string]interface{}) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	var fieldErrors []apperrors.ValidationError
+
+	for _, input := range declared {
+		label := input.Label
+		if label == "" {
+			label = input.Name
+		}
+
+		value, ok := inputs[input.Name]
+		if str, isString := value.(string); isString && strings.TrimSpace(str) == "" {
+			ok = false
+		}
+		if !ok || value == nil {
+			if input.Required && inputs != nil {
+				fieldErrors = append(fieldErrors, apperrors.ValidationError{Field: input.Name, Message: fmt.Sprintf("%s is required", label)})
+			}
+			continue
+		}
+
+		switch input.Type {
+		case models.TransitionInputNumber:
+			if _, isNumber := value.(float64); !isNumber {
+				fieldErrors = append(fieldErrors, apperrors.ValidationError{Field: input.Name, Message: fmt.Sprintf("%s must be a number", label)})
+				continue
+			}
+		case models.TransitionInputSelect:
+			str, _ := value.(string)
+			allowed := false
+			for _, option := range input.Options {
+				if option == str {
+					allowed = true
+					break
+				}
+			}
+			if !allowed {
+				fieldErrors = append(fieldErrors, apperrors.ValidationError{Field: input.Name, Message: fmt.Sprintf("%s must be one of: %s", label, strings.Join(input.Options, ", "))})
+				continue
+			}
+		default:
+			str, isString := value.(string)
+			if !isString {
+				fieldErrors = append(fieldErrors, apperrors.ValidationError{Field: input.Name, Message: fmt.Sprintf("%s must be text", label)})
+				continue
+			}
+			value = strings.TrimSpace(str)
+		}
+		values[input.Name] = value
+	}
+
+	if len(fieldErrors) > 0 {
+		return nil, apperrors.NewValidationErrors(fieldErrors)
+	}
+	return values, nil
+}
+
+// RecordTransition stores a status change with its transition inputs in the execution log
+func (s *WorkflowService) RecordTransition(ctx context.Context, orgID uuid.UUID, entityType string, entityID uuid.UUID, prepared *TransitionInputs, changedBy *uuid.UUID) error {
+	if prepared == nil {
+		return nil
+	}
+
+	details := map[string]interface{}{}
+	if prepared.Transition != nil {
+		details["transition_id"] = prepared.Transition.ID
+		details["transition_name"] = prepared.Transition.Name
+	}
+	if len(prepared.Values) > 0 {
+		details["inputs"] = prepared.Values
+	}
+	if changedBy != nil {
+		details["changed_by"] = *changedBy
+	}
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("failed to encode transition details: %w", err)
+	}
+
+	fromState := &prepared.FromStatus
+	if prepared.FromStatus == "" {
+		fromState = nil
+	}
+	_, err = s.db.Pool.Exec(ctx, `
+		INSERT INTO workflow_execution_log
+		(id, organization_id, workflow_id, entity_type, entity_id, event_type, from_state, to_state, details)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, uuid.New(), orgID, prepared.WorkflowID, entityType, entityID, models.EventTypeStateChange,
+		fromState, prepared.ToStatus, detailsJSON)
+	if err != nil {
+		return fmt.Errorf("failed to record transition: %w", err)
+	}
+	return nil
+}
+
+// SetTransitionInputs replaces the inputs declared on a transition
+func (s *WorkflowService) SetTransitionInputs(ctx context.Context, workflowID, transitionID, orgID uuid.UUID, inputs []models.TransitionInput) (*models.WorkflowTransition, error) {
+	seen := make(map[string]bool)
+	for _, input := range inputs {
+		if input.Name == "" {
+			return nil, errors.New("input name is required")
+		}
+		if seen[input.Name] {
+			return nil, fmt.Errorf("duplicate input name: %s", input.Name)
+		}
+		seen[input.Name] = true
+
+		switch input.Type {
+		case models.TransitionInputText, models.TransitionInputNumber:
+		case models.TransitionInputSelect:
+			if len(input.Options) == 0 {
+				return nil, fmt.Errorf("select input %s has no options", input.Name)
+			}
+		default:
+			return nil, fmt.Errorf("invalid input type: %s", input.Type)
+		}
+	}
+	if inputs == nil {
+		inputs = []models.TransitionInput{}
+	}
+
+	var t models.WorkflowTransition
+	err := s.db.Pool.QueryRow(ctx, `
+		UPDATE workflow_transitions wt
+		SET inputs = $1
+		FROM workflows w
+		WHERE wt.id = $2 AND wt.workflow_id = $3 AND w.id = wt.workflow_id AND w.organization_id = $4
+		RETURNING wt.id, wt.workflow_id, wt.from_state_id, wt.to_state_id, wt.name,
+			wt.requires_confirmation, wt.inputs, wt.created_at
+	`, inputs, transitionID, workflowID, orgID).Scan(
+		&t.ID, &t.WorkflowID, &t.FromStateID, &t.ToStateID, &t.Name,
+		&t.RequiresConfirmation, &t.Inputs, &t.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("transition not found")
+		}
+		return nil, fmt.Errorf("failed to update transition inputs: %w", err)
+	}
+	return &t, nil
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	apperrors "github.com/controlwise/backend/internal/errors"
+	"github.com/controlwise/backend/internal/models"
+)
+
+func TestValidateTransitionInputs(t *testing.T) {
+	declared := []models.TransitionInput{
+		{Name: "reason_category", Label: "Motivo", Type: models.TransitionInputSelect, Options: []string{"illness", "schedule"}, Required: true},
+		{Name: "notes", Type: models.TransitionInputText},
+		{Name: "minutes", Type: models.TransitionInputNumber},
+	}
+
+	tests := []struct {
+		name          string
+		inputs        map[string]interface{}
+		invalidFields []string
+		values        int
+	}{
+		{
+			name:   "valid inputs keep only declared fields",
+			inputs: map[string]interface{}{"reason_category": "illness", "notes": " febre ", "other": "x"},
+			values: 2,
+		},
+		{
+			name:          "missing required input",
+			inputs:        map[string]interface{}{"notes": "sem motivo"},
+			invalidFields: []string{"reason_category"},
+		},
+		{
+			name:          "option and type mismatches",
+			inputs:        map[string]interface{}{"reason_category": "other", "minutes": "ten"},
+			invalidFields: []string{"reason_category", "minutes"},
+		},
+		{
+			name:   "system changes skip required inputs",
+			inputs: nil,
+			values: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := validateTransitionInputs(declared, tt.inputs)
+			if len(tt.invalidFields) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(values) != tt.values {
+					t.Errorf("got %d values, want %d", len(values), tt.values)
+				}
+				return
+			}
+
+			var validationErrs *apperrors.ValidationErrors
+			if !errors.As(err, &validationErrs) {
+				t.Fatalf("expected validation errors, got %v", err)
+			}
+			if len(validationErrs.Errors) != len(tt.invalidFields) {
+				t.Fatalf("got %d errors, want %d", len(validationErrs.Errors), len(tt.invalidFields))
+			}
+			for i, field := range tt.invalidFields {
+				if validationErrs.Errors[i].Field != field {
+					t.Errorf("error %d on field %s, want %s", i, validationErrs.Errors[i].Field, field)
+				}
+			}
+		})
+	}
+}
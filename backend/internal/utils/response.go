@@ -7,6 +7,7 @@ import (
 	"net/http"
 
 	apperrors "github.com/controlwise/backend/internal/errors"
+	"github.com/controlwise/backend/internal/i18n"
 )
 
 // Default max request body size (1MB)
@@ -27,14 +28,14 @@ type SuccessResponseBody struct {
 	Message string      `json:"message,omitempty"`
 }
 
-// ErrorResponse sends an error response with a message
+// ErrorResponse sends an error response with a message, translated to the request locale
 func ErrorResponse(w http.ResponseWriter, statusCode int, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 	json.NewEncoder(w).Encode(ErrorResponseBody{
 		Error:   http.StatusText(statusCode),
 		Code:    http.StatusText(statusCode),
-		Message: message,
+		Message: i18n.Translate(w, message),
 	})
 }
 
@@ -49,7 +50,7 @@ func AppErrorResponse(w http.ResponseWriter, err error) {
 		json.NewEncoder(w).Encode(ErrorResponseBody{
 			Error:   "Validation Error",
 			Code:    "VALIDATION_ERROR",
-			Message: "Invalid input data",
+			Message: i18n.Translate(w, "Invalid input data"),
 			Details: validationErrs.Errors,
 		})
 		return
@@ -61,7 +62,7 @@ func AppErrorResponse(w http.ResponseWriter, err error) {
 		json.NewEncoder(w).Encode(ErrorResponseBody{
 			Error:   http.StatusText(appErr.StatusCode),
 			Code:    appErr.Code,
-			Message: appErr.Message,
+			Message: i18n.Translate(w, appErr.Message),
 		})
 		return
 	}
@@ -72,7 +73,7 @@ func AppErrorResponse(w http.ResponseWriter, err error) {
 	json.NewEncoder(w).Encode(ErrorResponseBody{
 		Error:   "Internal Server Error",
 		Code:    "INTERNAL_ERROR",
-		Message: "An internal error occurred",
+		Message: i18n.Translate(w, "An internal error occurred"),
 	})
 }
 
@@ -91,7 +92,7 @@ func SuccessMessageResponse(w http.ResponseWriter, statusCode int, message strin
 	w.WriteHeader(statusCode)
 	json.NewEncoder(w).Encode(SuccessResponseBody{
 		Data:    data,
-		Message: message,
+		Message: i18n.Translate(w, message),
 	})
 }
 
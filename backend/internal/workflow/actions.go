@@ -0,0 +1,197 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// Headers sent with custom action webhook requests
+const (
+	CustomActionSignatureHeader = "X-ControlWise-Signature" // hex HMAC-SHA256 of "<timestamp>.<body>"
+	CustomActionTimestampHeader = "X-ControlWise-Timestamp" // unix seconds
+)
+
+// ActionRequest is what a custom action handler receives when its action runs
+type ActionRequest struct {
+	OrganizationID uuid.UUID
+	Action         *models.WorkflowAction
+	EntityType     string
+	EntityID       uuid.UUID
+	EntityData     map[string]interface{}
+	Config         map[string]interface{} // parsed action_config
+}
+
+// ActionHandler executes a custom action type. Returning an error fails the action,
+// which is then retried according to the action's retry policy.
+type ActionHandler interface {
+	Execute(ctx context.Context, req ActionRequest) error
+}
+
+// ActionHandlerFunc adapts a function to an ActionHandler
+type ActionHandlerFunc func(ctx context.Context, req ActionRequest) error
+
+// Execute calls f(ctx, req)
+func (f ActionHandlerFunc) Execute(ctx context.Context, req ActionRequest) error {
+	return f(ctx, req)
+}
+
+// builtinActionTypes are handled by the executor itself and cannot be overridden
+var builtinActionTypes = map[models.ActionType]bool{
+	models.ActionTypeSendWhatsApp:     true,
+	models.ActionTypeSendEmail:        true,
+	models.ActionTypeUpdateField:      true,
+	models.ActionTypeCreateTask:       true,
+	models.ActionTypeNotifyUser:       true,
+	models.ActionTypeTransitionEntity: true,
+	models.ActionTypeCreateProject:    true,
+	models.ActionTypeSendChat:         true,
+	models.ActionTypeOfferSlot:        true,
+}
+
+// ActionRegistry holds the handlers of custom action types
+type ActionRegistry struct {
+	mu       sync.RWMutex
+	handlers map[models.ActionType]ActionHandler
+}
+
+// NewActionRegistry creates an empty action registry
+func NewActionRegistry() *ActionRegistry {
+	return &ActionRegistry{handlers: make(map[models.ActionType]ActionHandler)}
+}
+
+// Register adds the handler of a custom action type
+func (r *ActionRegistry) Register(actionType models.ActionType, handler ActionHandler) error {
+	if actionType == "" {
+		return fmt.Errorf("action type is required")
+	}
+	if handler == nil {
+		return fmt.Errorf("handler for action type %s is nil", actionType)
+	}
+	if builtinActionTypes[actionType] {
+		return fmt.Errorf("action type %s is built in and cannot be overridden", actionType)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.handlers[actionType]; exists {
+		return fmt.Errorf("action type %s is already registered", actionType)
+	}
+	r.handlers[actionType] = handler
+	return nil
+}
+
+// Lookup returns the handler of a custom action type
+func (r *ActionRegistry) Lookup(actionType models.ActionType) (ActionHandler, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	handler, ok := r.handlers[actionType]
+	return handler, ok
+}
+
+// Types returns the registered custom action types, sorted
+func (r *ActionRegistry) Types() []models.ActionType {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	types := make([]models.ActionType, 0, len(r.handlers))
+	for t := range r.handlers {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+// WebhookActionPayload is the JSON body posted to a custom action webhook
+type WebhookActionPayload struct {
+	ActionType     models.ActionType      `json:"action_type"`
+	ActionID       uuid.UUID              `json:"action_id"`
+	TriggerID      uuid.UUID              `json:"trigger_id"`
+	OrganizationID uuid.UUID              `json:"organization_id"`
+	EntityType     string                 `json:"entity_type"`
+	EntityID       uuid.UUID              `json:"entity_id"`
+	EntityData     map[string]interface{} `json:"entity_data"`
+	Config         map[string]interface{} `json:"config"`
+	SentAt         time.Time              `json:"sent_at"`
+}
+
+// WebhookActionHandler runs a custom action by posting it to an external endpoint.
+// Any non-2xx response fails the action.
+type WebhookActionHandler struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// NewWebhookActionHandler creates a handler posting to url. When secret is set, requests are
+// signed so the receiver can verify they come from this deployment.
+func NewWebhookActionHandler(url, secret string) *WebhookActionHandler {
+	return &WebhookActionHandler{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Execute posts the action to the webhook
+func (h *WebhookActionHandler) Execute(ctx context.Context, req ActionRequest) error {
+	now := time.Now().UTC()
+	body, err := json.Marshal(WebhookActionPayload{
+		ActionType:     req.Action.ActionType,
+		ActionID:       req.Action.ID,
+		TriggerID:      req.Action.TriggerID,
+		OrganizationID: req.OrganizationID,
+		EntityType:     req.EntityType,
+		EntityID:       req.EntityID,
+		EntityData:     req.EntityData,
+		Config:         req.Config,
+		SentAt:         now,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode custom action payload: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create custom action request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if h.secret != "" {
+		timestamp := strconv.FormatInt(now.Unix(), 10)
+		httpReq.Header.Set(CustomActionTimestampHeader, timestamp)
+		httpReq.Header.Set(CustomActionSignatureHeader, SignCustomAction(h.secret, timestamp, body))
+	}
+
+	resp, err := h.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("custom action webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("custom action webhook returned status %d: %s", resp.StatusCode, truncateString(string(detail), 200))
+	}
+	return nil
+}
+
+// SignCustomAction computes the signature of a custom action webhook body
+func SignCustomAction(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,236 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+)
+
+// Bulk run job types (matching jobs package)
+const (
+	TypeExecuteBulkRun     = "workflow:execute_bulk_run"
+	TypeExecuteBulkRunItem = "workflow:execute_bulk_run_item"
+)
+
+// bulkRunItemMaxRetry is how many times a recipient is retried before it counts as failed
+const bulkRunItemMaxRetry = 3
+
+// ExecuteBulkRunPayload matches jobs.ExecuteBulkRunPayload
+type ExecuteBulkRunPayload struct {
+	RunID uuid.UUID `json:"run_id"`
+}
+
+// ExecuteBulkRunItemPayload matches jobs.ExecuteBulkRunItemPayload
+type ExecuteBulkRunItemPayload struct {
+	OrganizationID uuid.UUID `json:"organization_id"`
+	RunID          uuid.UUID `json:"run_id"`
+	ItemID         uuid.UUID `json:"item_id"`
+	TriggerID      uuid.UUID `json:"trigger_id"`
+	EntityType     string    `json:"entity_type"`
+	EntityID       uuid.UUID `json:"entity_id"`
+}
+
+// StartBulkRun records a run of a trigger over every entity returned by entityQuery
+// (which selects entity ids and takes $1 = organization and $2 = entityArg), then
+// enqueues the parent job that fans it out. Runs without entities are not recorded.
+func (s *Scheduler) StartBulkRun(ctx context.Context, orgID, triggerID uuid.UUID, source, entityType, entityQuery string, entityArg interface{}) (*models.TriggerRun, error) {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	run := &models.TriggerRun{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		TriggerID:      triggerID,
+		Source:         source,
+		Status:         models.TriggerRunStatusPending,
+	}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO trigger_runs (id, organization_id, trigger_id, source, status)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at
+	`, run.ID, run.OrganizationID, run.TriggerID, run.Source, run.Status).Scan(&run.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trigger run: %w", err)
+	}
+
+	result, err := tx.Exec(ctx, `
+		INSERT INTO trigger_run_items (id, run_id, entity_type, entity_id)
+		SELECT gen_random_uuid(), $3, $4, e.id
+		FROM (`+entityQuery+`) e
+	`, orgID, entityArg, run.ID, entityType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trigger run items: %w", err)
+	}
+	run.TotalCount = int(result.RowsAffected())
+	if run.TotalCount == 0 {
+		return nil, nil
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE trigger_runs SET total_count = $1 WHERE id = $2`, run.TotalCount, run.ID); err != nil {
+		return nil, fmt.Errorf("failed to update trigger run: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if s.client != nil {
+		data, _ := json.Marshal(ExecuteBulkRunPayload{RunID: run.ID})
+		if _, err := s.client.Enqueue(asynq.NewTask(TypeExecuteBulkRun, data), asynq.Queue("default")); err != nil {
+			return run, fmt.Errorf("failed to enqueue trigger run: %w", err)
+		}
+	}
+
+	log.Printf("[Scheduler] Started bulk run %s of trigger %s for %d %s entities", run.ID, triggerID, run.TotalCount, entityType)
+	return run, nil
+}
+
+// bulkRunDispatchDelay returns when the recipient at index of a run is dispatched: runs are sent
+// in batches of a minute's worth of messages at the organization's rate, one batch a minute.
+// A rate of zero dispatches every recipient at once.
+func bulkRunDispatchDelay(index int, rate float64) time.Duration {
+	if rate <= 0 {
+		return 0
+	}
+	batch := int(math.Max(1, math.Floor(rate*60)))
+	return time.Duration(index/batch) * time.Minute
+}
+
+// FanOutBulkRun enqueues one task per pending recipient of a run, spread over time at the
+// organization's rate limit when the scheduler has one.
+// Recipients go to the low priority queue so immediate notifications are not delayed.
+func (s *Scheduler) FanOutBulkRun(ctx context.Context, runID uuid.UUID) error {
+	var orgID, triggerID uuid.UUID
+	err := s.db.Pool.QueryRow(ctx, `
+		UPDATE trigger_runs SET status = 'running', started_at = COALESCE(started_at, NOW())
+		WHERE id = $1
+		RETURNING organization_id, trigger_id
+	`, runID).Scan(&orgID, &triggerID)
+	if err != nil {
+		return fmt.Errorf("failed to start trigger run: %w", err)
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, entity_type, entity_id FROM trigger_run_items
+		WHERE run_id = $1 AND status = 'pending'
+	`, runID)
+	if err != nil {
+		return fmt.Errorf("failed to query trigger run items: %w", err)
+	}
+	var items []ExecuteBulkRunItemPayload
+	for rows.Next() {
+		item := ExecuteBulkRunItemPayload{OrganizationID: orgID, RunID: runID, TriggerID: triggerID}
+		if err := rows.Scan(&item.ItemID, &item.EntityType, &item.EntityID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan trigger run item: %w", err)
+		}
+		items = append(items, item)
+	}
+	rows.Close()
+
+	if s.client == nil {
+		return nil
+	}
+
+	var rate float64
+	if s.limiter != nil {
+		if rate, err = s.limiter.DispatchRate(ctx, orgID); err != nil {
+			log.Printf("[Scheduler] Failed to get dispatch rate of organization %s, not spreading run %s: %v", orgID, runID, err)
+		}
+	}
+
+	var spread time.Duration
+	for i, item := range items {
+		data, _ := json.Marshal(item)
+		delay := bulkRunDispatchDelay(i, rate)
+		spread = delay
+		// The item ID as task ID keeps a re-run of the parent job from enqueuing a recipient twice
+		_, err := s.client.Enqueue(asynq.NewTask(TypeExecuteBulkRunItem, data),
+			asynq.Queue("low"), asynq.MaxRetry(bulkRunItemMaxRetry), asynq.TaskID(item.ItemID.String()),
+			asynq.ProcessIn(delay))
+		if err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+			return fmt.Errorf("failed to enqueue trigger run item %s: %w", item.ItemID, err)
+		}
+	}
+
+	log.Printf("[Scheduler] Fanned out bulk run %s into %d tasks over %v", runID, len(items), spread)
+	return nil
+}
+
+// RecordBulkRunItem stores the outcome of a recipient. A failure is only final once the task
+// is out of retries; earlier failures just record the attempt. The run is finished when every
+// recipient has a final outcome.
+func (s *Scheduler) RecordBulkRunItem(ctx context.Context, runID, itemID uuid.UUID, execErr error, final bool) error {
+	if execErr != nil && !final {
+		_, err := s.db.Pool.Exec(ctx, `
+			UPDATE trigger_run_items SET attempts = attempts + 1, last_error = $1
+			WHERE id = $2 AND status = 'pending'
+		`, execErr.Error(), itemID)
+		if err != nil {
+			return fmt.Errorf("failed to record trigger run attempt: %w", err)
+		}
+		return nil
+	}
+
+	status := models.TriggerRunItemStatusCompleted
+	var lastError *string
+	if execErr != nil {
+		status = models.TriggerRunItemStatusFailed
+		msg := execErr.Error()
+		lastError = &msg
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE trigger_run_items
+		SET status = $1, attempts = attempts + 1, last_error = COALESCE($2, last_error), processed_at = NOW()
+		WHERE id = $3 AND status = 'pending'
+	`, status, lastError, itemID)
+	if err != nil {
+		return fmt.Errorf("failed to record trigger run item: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		// Already recorded by an earlier delivery of the task
+		return nil
+	}
+
+	completed, failed := 1, 0
+	if execErr != nil {
+		completed, failed = 0, 1
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE trigger_runs
+		SET completed_count = completed_count + $1, failed_count = failed_count + $2,
+		    status = CASE
+		        WHEN completed_count + failed_count + 1 < total_count THEN status
+		        WHEN failed_count + $2 > 0 THEN 'completed_with_errors'
+		        ELSE 'completed'
+		    END,
+		    finished_at = CASE WHEN completed_count + failed_count + 1 >= total_count THEN NOW() ELSE finished_at END
+		WHERE id = $3
+	`, completed, failed, runID)
+	if err != nil {
+		return fmt.Errorf("failed to update trigger run: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package workflow
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBulkRunDispatchDelay(t *testing.T) {
+	tests := []struct {
+		index int
+		rate  float64
+		want  time.Duration
+	}{
+		{5000, 0, 0},
+		{299, 5, 0},
+		{300, 5, time.Minute},
+		{1000, 5, 3 * time.Minute},
+		{1, 0.01, time.Minute}, // slower than a message a minute still sends one a minute
+	}
+	for _, tt := range tests {
+		if got := bulkRunDispatchDelay(tt.index, tt.rate); got != tt.want {
+			t.Errorf("bulkRunDispatchDelay(%d, %v) = %v, want %v", tt.index, tt.rate, got, tt.want)
+		}
+	}
+}
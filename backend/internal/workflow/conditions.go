@@ -0,0 +1,260 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+// EvaluateConditions reports whether the entity data matches a trigger's conditions.
+// Triggers without conditions always match.
+func EvaluateConditions(raw json.RawMessage, data map[string]interface{}) (bool, error) {
+	condition, err := models.ParseTriggerConditions(raw)
+	if err != nil {
+		return false, err
+	}
+	if condition == nil {
+		return true, nil
+	}
+	return evaluateCondition(condition, data), nil
+}
+
+// ConditionResult is the outcome of one comparison of a trigger's conditions
+type ConditionResult struct {
+	Field    string                   `json:"field"`
+	Operator models.ConditionOperator `json:"operator"`
+	Expected interface{}              `json:"expected,omitempty"`
+	Actual   interface{}              `json:"actual"`
+	Found    bool                     `json:"found"`
+	Matched  bool                     `json:"matched"`
+}
+
+// ExplainConditions evaluates a trigger's conditions like EvaluateConditions and also returns
+// the outcome of every comparison, in order, to show why a trigger did or did not match
+func ExplainConditions(raw json.RawMessage, data map[string]interface{}) (bool, []ConditionResult, error) {
+	condition, err := models.ParseTriggerConditions(raw)
+	if err != nil {
+		return false, nil, err
+	}
+	if condition == nil {
+		return true, []ConditionResult{}, nil
+	}
+
+	results := []ConditionResult{}
+	var collect func(c *models.TriggerCondition)
+	collect = func(c *models.TriggerCondition) {
+		for i := range c.All {
+			collect(&c.All[i])
+		}
+		for i := range c.Any {
+			collect(&c.Any[i])
+		}
+		if c.IsGroup() {
+			return
+		}
+		actual, found := lookupField(data, c.Field)
+		results = append(results, ConditionResult{
+			Field: c.Field, Operator: c.Operator, Expected: c.Value,
+			Actual: actual, Found: found, Matched: evaluateCondition(c, data),
+		})
+	}
+	collect(condition)
+
+	return evaluateCondition(condition, data), results, nil
+}
+
+// evaluateCondition evaluates a comparison or, recursively, an all/any group
+func evaluateCondition(c *models.TriggerCondition, data map[string]interface{}) bool {
+	if c.All != nil {
+		for i := range c.All {
+			if !evaluateCondition(&c.All[i], data) {
+				return false
+			}
+		}
+		return true
+	}
+	if c.Any != nil {
+		for i := range c.Any {
+			if evaluateCondition(&c.Any[i], data) {
+				return true
+			}
+		}
+		return false
+	}
+
+	actual, found := lookupField(data, c.Field)
+	switch c.Operator {
+	case models.ConditionExists:
+		return found && actual != nil && actual != ""
+	case models.ConditionNotExists:
+		return !found || actual == nil || actual == ""
+	case models.ConditionNotEquals:
+		return !found || !valuesEqual(actual, c.Value)
+	case models.ConditionNotIn:
+		return !found || !valueInList(actual, c.Value)
+	}
+	if !found {
+		return false
+	}
+
+	switch c.Operator {
+	case models.ConditionEquals:
+		return valuesEqual(actual, c.Value)
+	case models.ConditionIn:
+		return valueInList(actual, c.Value)
+	case models.ConditionContains:
+		return valueContains(actual, c.Value)
+	case models.ConditionGreaterThan:
+		cmp, ok := compareValues(actual, c.Value)
+		return ok && cmp > 0
+	case models.ConditionGreaterOrEqual:
+		cmp, ok := compareValues(actual, c.Value)
+		return ok && cmp >= 0
+	case models.ConditionLessThan:
+		cmp, ok := compareValues(actual, c.Value)
+		return ok && cmp < 0
+	case models.ConditionLessOrEqual:
+		cmp, ok := compareValues(actual, c.Value)
+		return ok && cmp <= 0
+	}
+	return false
+}
+
+// lookupField resolves a dotted field path in the entity data
+func lookupField(data map[string]interface{}, field string) (interface{}, bool) {
+	var current interface{} = data
+	for _, part := range strings.Split(field, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = m[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// valuesEqual compares numerically or chronologically when both sides are numbers or dates,
+// otherwise as text (case-insensitive)
+func valuesEqual(actual, expected interface{}) bool {
+	if a, ok := toNumber(actual); ok {
+		if b, ok := toNumber(expected); ok {
+			return a.Equal(b)
+		}
+	}
+	if a, ok := toTime(actual); ok {
+		if b, ok := toTime(expected); ok {
+			return a.Equal(b)
+		}
+	}
+	return strings.EqualFold(toText(actual), toText(expected))
+}
+
+// valueInList reports whether the value equals one of the list's values
+func valueInList(actual, list interface{}) bool {
+	values, ok := list.([]interface{})
+	if !ok {
+		return false
+	}
+	for _, v := range values {
+		if valuesEqual(actual, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// valueContains checks a list for the value, or a text for the substring (case-insensitive)
+func valueContains(actual, expected interface{}) bool {
+	switch a := actual.(type) {
+	case []interface{}:
+		return valueInList(expected, a)
+	case []string:
+		for _, v := range a {
+			if valuesEqual(v, expected) {
+				return true
+			}
+		}
+		return false
+	}
+	return strings.Contains(strings.ToLower(toText(actual)), strings.ToLower(toText(expected)))
+}
+
+// compareValues orders numbers numerically and dates chronologically. ok is false when the
+// values cannot be ordered.
+func compareValues(actual, expected interface{}) (int, bool) {
+	if a, ok := toNumber(actual); ok {
+		if b, ok := toNumber(expected); ok {
+			return a.Cmp(b), true
+		}
+	}
+	if a, ok := toTime(actual); ok {
+		if b, ok := toTime(expected); ok {
+			return a.Compare(b), true
+		}
+	}
+	return 0, false
+}
+
+// toNumber reads numbers and numeric text (entity data keeps amounts as formatted text)
+func toNumber(v interface{}) (decimal.Decimal, bool) {
+	switch n := v.(type) {
+	case float64:
+		return decimal.NewFromFloat(n), true
+	case float32:
+		return decimal.NewFromFloat32(n), true
+	case int:
+		return decimal.NewFromInt(int64(n)), true
+	case int64:
+		return decimal.NewFromInt(n), true
+	case int32:
+		return decimal.NewFromInt32(n), true
+	case decimal.Decimal:
+		return n, true
+	case string:
+		d, err := decimal.NewFromString(strings.TrimSpace(n))
+		return d, err == nil
+	}
+	return decimal.Decimal{}, false
+}
+
+// toTime reads times and RFC 3339, YYYY-MM-DD or DD/MM/YYYY text
+func toTime(v interface{}) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case *time.Time:
+		if t != nil {
+			return *t, true
+		}
+	case string:
+		for _, layout := range []string{time.RFC3339, "2006-01-02", "02/01/2006"} {
+			if parsed, err := time.Parse(layout, t); err == nil {
+				return parsed, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// toText formats a value for text comparison
+func toText(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case bool:
+		return strconv.FormatBool(t)
+	case time.Time:
+		return t.Format(time.RFC3339)
+	}
+	return fmt.Sprint(v)
+}
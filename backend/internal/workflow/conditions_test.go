@@ -0,0 +1,178 @@
+package workflow
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestEvaluateConditions(t *testing.T) {
+	data := map[string]interface{}{
+		"session_type":     "online",
+		"status":           "confirmed",
+		"cancellation_fee": "25.00",
+		"scheduled_at":     time.Date(2025, 3, 10, 14, 30, 0, 0, time.UTC),
+		"patient_email":    "maria@example.com",
+		"client": map[string]interface{}{
+			"city": "Lisboa",
+		},
+	}
+
+	tests := []struct {
+		name       string
+		conditions string
+		expected   bool
+	}{
+		{
+			name:       "no conditions",
+			conditions: ``,
+			expected:   true,
+		},
+		{
+			name:       "empty object",
+			conditions: `{}`,
+			expected:   true,
+		},
+		{
+			name:       "equals is case-insensitive",
+			conditions: `{"field": "session_type", "operator": "eq", "value": "Online"}`,
+			expected:   true,
+		},
+		{
+			name:       "not equals",
+			conditions: `{"field": "status", "operator": "neq", "value": "confirmed"}`,
+			expected:   false,
+		},
+		{
+			name:       "numeric text compared as number",
+			conditions: `{"field": "cancellation_fee", "operator": "gt", "value": 9.5}`,
+			expected:   true,
+		},
+		{
+			name:       "date comparison",
+			conditions: `{"field": "scheduled_at", "operator": "lt", "value": "2025-03-01"}`,
+			expected:   false,
+		},
+		{
+			name:       "nested field",
+			conditions: `{"field": "client.city", "operator": "in", "value": ["Porto", "Lisboa"]}`,
+			expected:   true,
+		},
+		{
+			name:       "contains",
+			conditions: `{"field": "patient_email", "operator": "contains", "value": "@example.com"}`,
+			expected:   true,
+		},
+		{
+			name:       "missing field does not exist",
+			conditions: `{"field": "patient_phone", "operator": "not_exists"}`,
+			expected:   true,
+		},
+		{
+			name:       "missing field does not equal",
+			conditions: `{"field": "patient_phone", "operator": "eq", "value": "+351912345678"}`,
+			expected:   false,
+		},
+		{
+			name: "all group",
+			conditions: `{"all": [
+				{"field": "session_type", "operator": "eq", "value": "online"},
+				{"field": "status", "operator": "eq", "value": "cancelled"}
+			]}`,
+			expected: false,
+		},
+		{
+			name: "any group nested in all",
+			conditions: `{"all": [
+				{"field": "session_type", "operator": "eq", "value": "online"},
+				{"any": [
+					{"field": "status", "operator": "eq", "value": "cancelled"},
+					{"field": "patient_email", "operator": "exists"}
+				]}
+			]}`,
+			expected: true,
+		},
+		{
+			name:       "list is an all group",
+			conditions: `[{"field": "session_type", "operator": "eq", "value": "online"}, {"field": "status", "operator": "eq", "value": "confirmed"}]`,
+			expected:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := EvaluateConditions(json.RawMessage(tt.conditions), data)
+			if err != nil {
+				t.Fatalf("EvaluateConditions() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("EvaluateConditions() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEvaluateConditionsInvalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions string
+	}{
+		{"malformed JSON", `{"field": "status"`},
+		{"missing field", `{"operator": "eq", "value": "x"}`},
+		{"unknown operator", `{"field": "status", "operator": "like", "value": "x"}`},
+		{"missing value", `{"field": "status", "operator": "eq"}`},
+		{"in without list", `{"field": "status", "operator": "in", "value": "x"}`},
+		{"empty group", `{"any": []}`},
+		{"both all and any", `{"all": [{"field": "a", "operator": "exists"}], "any": [{"field": "b", "operator": "exists"}]}`},
+		{"comparison and group", `{"field": "a", "operator": "exists", "all": [{"field": "b", "operator": "exists"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := EvaluateConditions(json.RawMessage(tt.conditions), nil); err == nil {
+				t.Error("EvaluateConditions() expected an error")
+			}
+		})
+	}
+}
+
+func TestExplainConditions(t *testing.T) {
+	data := map[string]interface{}{
+		"session_type": "online",
+		"status":       "confirmed",
+	}
+	conditions := `{"any": [
+		{"field": "session_type", "operator": "eq", "value": "presencial"},
+		{"all": [
+			{"field": "status", "operator": "eq", "value": "confirmed"},
+			{"field": "patient_email", "operator": "exists"}
+		]}
+	]}`
+
+	matched, results, err := ExplainConditions(json.RawMessage(conditions), data)
+	if err != nil {
+		t.Fatalf("ExplainConditions() error = %v", err)
+	}
+	if matched {
+		t.Error("ExplainConditions() matched, want no match")
+	}
+
+	want := []struct {
+		field   string
+		found   bool
+		matched bool
+	}{
+		{"session_type", true, false},
+		{"status", true, true},
+		{"patient_email", false, false},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("ExplainConditions() returned %d results, want %d", len(results), len(want))
+	}
+	for i, w := range want {
+		r := results[i]
+		if r.Field != w.field || r.Found != w.found || r.Matched != w.matched {
+			t.Errorf("result %d = %+v, want field %s found %v matched %v", i, r, w.field, w.found, w.matched)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxDelegationDepth limits how far a delegation chain is followed
+const maxDelegationDepth = 5
+
+// resolveDelegate returns the user that should receive work addressed to userID from a workflow
+func (e *Executor) resolveDelegate(ctx context.Context, orgID, userID uuid.UUID, delegationType models.DelegationType, entityType string, entityID uuid.UUID) uuid.UUID {
+	return ResolveDelegate(ctx, e.db, orgID, userID, delegationType, entityType, entityID, "workflow")
+}
+
+// ResolveDelegate returns the user that should receive work addressed to userID, following
+// out-of-office delegations and recording any rerouting in the audit log with its source
+func ResolveDelegate(ctx context.Context, db *database.DB, orgID, userID uuid.UUID, delegationType models.DelegationType, entityType string, entityID uuid.UUID, source string) uuid.UUID {
+	now := time.Now()
+	current := userID
+	visited := map[uuid.UUID]bool{userID: true}
+	var firstEntryID *uuid.UUID
+
+	for i := 0; i < maxDelegationDepth; i++ {
+		var entry models.OutOfOffice
+		err := db.Pool.QueryRow(ctx, `
+			SELECT id, delegate_user_id, starts_at, ends_at,
+				delegate_approvals, delegate_tasks, delegate_escalations, is_active
+			FROM user_out_of_office
+			WHERE organization_id = $1 AND user_id = $2 AND is_active = true
+			AND starts_at <= $3 AND ends_at > $3
+			ORDER BY starts_at DESC
+			LIMIT 1
+		`, orgID, current, now).Scan(
+			&entry.ID, &entry.DelegateUserID, &entry.StartsAt, &entry.EndsAt,
+			&entry.DelegateApprovals, &entry.DelegateTasks, &entry.DelegateEscalations, &entry.IsActive,
+		)
+		if err != nil {
+			if !errors.Is(err, pgx.ErrNoRows) {
+				log.Printf("[Delegation] Failed to check out-of-office for user %s: %v", current, err)
+			}
+			break
+		}
+		if !entry.Covers(delegationType, now) || visited[entry.DelegateUserID] {
+			break
+		}
+		if firstEntryID == nil {
+			firstEntryID = &entry.ID
+		}
+		current = entry.DelegateUserID
+		visited[current] = true
+	}
+
+	if current == userID {
+		return userID
+	}
+
+	log.Printf("[Delegation] Rerouting %s for user %s to delegate %s", delegationType, userID, current)
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"rerouted_at": now,
+		"source":      source,
+	})
+	_, err := db.Pool.Exec(ctx, `
+		INSERT INTO delegation_audit_log (
+			organization_id, out_of_office_id, original_user_id, delegate_user_id,
+			delegation_type, entity_type, entity_id, details
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, orgID, firstEntryID, userID, current, delegationType, entityType, entityID, details)
+	if err != nil {
+		log.Printf("[Delegation] Failed to record delegation: %v", err)
+	}
+
+	return current
+}
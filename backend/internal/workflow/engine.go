@@ -3,14 +3,17 @@ package workflow
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"time"
 
 	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/events"
 	"github.com/controlwise/backend/internal/models"
 	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
 )
 
 // Engine handles workflow execution
@@ -19,6 +22,7 @@ type Engine struct {
 	client    *asynq.Client
 	scheduler *Scheduler
 	executor  *Executor
+	events    *events.Publisher
 }
 
 // NewEngine creates a new workflow engine
@@ -29,9 +33,20 @@ func NewEngine(db *database.DB, client *asynq.Client) *Engine {
 	}
 	e.scheduler = NewScheduler(db, client)
 	e.executor = NewExecutor(db)
+	e.executor.client = client
 	return e
 }
 
+// SetFrontendURL sets the base of the links put in messages
+func (e *Engine) SetFrontendURL(url string) {
+	e.executor.SetFrontendURL(url)
+}
+
+// SetEventPublisher sets the publisher failed actions are streamed to dashboards with
+func (e *Engine) SetEventPublisher(p *events.Publisher) {
+	e.events = p
+}
+
 // OnStateEnter is called when an entity enters a state
 // It fires on_enter triggers and schedules time-based triggers
 func (e *Engine) OnStateEnter(ctx context.Context, orgID uuid.UUID, workflow *models.Workflow, stateName string, entityType string, entityID uuid.UUID, entityData map[string]interface{}) error {
@@ -152,6 +167,31 @@ func (e *Engine) TransitionEntity(ctx context.Context, orgID uuid.UUID, workflow
 
 // executeTrigger executes a trigger and all its actions
 func (e *Engine) executeTrigger(ctx context.Context, orgID uuid.UUID, workflow *models.Workflow, trigger *models.WorkflowTrigger, entityType string, entityID uuid.UUID, entityData map[string]interface{}) error {
+	// Triggers with conditions only fire when the entity data matches them
+	if len(trigger.Conditions) > 0 {
+		if entityData == nil {
+			data, err := e.getEntityData(ctx, orgID, entityType, entityID)
+			if err != nil {
+				log.Printf("[WorkflowEngine] Failed to get entity data: %v", err)
+				data = make(map[string]interface{})
+			}
+			entityData = data
+		}
+		matched, err := EvaluateConditions(trigger.Conditions, entityData)
+		if err != nil {
+			return fmt.Errorf("failed to evaluate trigger conditions: %w", err)
+		}
+		if !matched {
+			log.Printf("[WorkflowEngine] Skipping trigger %s: conditions not met for %s/%s", trigger.ID, entityType, entityID)
+			e.logEvent(ctx, orgID, workflow.ID, entityType, entityID, models.EventTypeTriggerSkipped, nil, nil, map[string]interface{}{
+				"trigger_id":   trigger.ID,
+				"trigger_type": trigger.TriggerType,
+				"reason":       "conditions_not_met",
+			})
+			return nil
+		}
+	}
+
 	log.Printf("[WorkflowEngine] Executing trigger %s (type=%s)", trigger.ID, trigger.TriggerType)
 
 	// Log trigger fired
@@ -168,23 +208,27 @@ func (e *Engine) executeTrigger(ctx context.Context, orgID uuid.UUID, workflow *
 			continue
 		}
 
-		if err := e.executor.ExecuteAction(ctx, orgID, &action, entityType, entityID, entityData); err != nil {
-			// Log failure
-			e.logEvent(ctx, orgID, workflow.ID, entityType, entityID, models.EventTypeActionFailed, nil, nil, map[string]interface{}{
-				"action_id":   action.ID,
-				"action_type": action.ActionType,
-				"error":       err.Error(),
-			})
+		decision, err := e.executor.ExecuteAction(ctx, orgID, &action, entityType, entityID, entityData)
+		details := map[string]interface{}{
+			"action_id":   action.ID,
+			"action_type": action.ActionType,
+		}
+		if decision != nil {
+			details["delivery"] = decision
+		}
+
+		if err != nil {
+			// Retry in the background per the action's policy, or dead-letter it
+			details["error"] = err.Error()
+			details["retry_scheduled"] = e.handleActionFailure(ctx, orgID, workflow.ID, trigger.ID, &action, entityType, entityID, err)
+			e.logEvent(ctx, orgID, workflow.ID, entityType, entityID, models.EventTypeActionFailed, nil, nil, details)
 			log.Printf("[WorkflowEngine] Action %s failed: %v", action.ID, err)
 			// Continue with other actions
 			continue
 		}
 
 		// Log success
-		e.logEvent(ctx, orgID, workflow.ID, entityType, entityID, models.EventTypeActionExecuted, nil, nil, map[string]interface{}{
-			"action_id":   action.ID,
-			"action_type": action.ActionType,
-		})
+		e.logEvent(ctx, orgID, workflow.ID, entityType, entityID, models.EventTypeActionExecuted, nil, nil, details)
 	}
 
 	return nil
@@ -198,6 +242,20 @@ func (e *Engine) ExecuteTriggerByID(ctx context.Context, orgID, triggerID uuid.U
 		return fmt.Errorf("failed to get trigger: %w", err)
 	}
 
+	// Scheduled triggers only run while the entity is still in the trigger's state,
+	// so follow-ups stop once a budget is answered or expires
+	if trigger.StateID != nil && (trigger.TriggerType == models.TriggerTypeTimeAfter || trigger.TriggerType == models.TriggerTypeTimeBefore ||
+		trigger.TriggerType == models.TriggerTypeRecurring) {
+		inState, err := e.isEntityInState(ctx, orgID, *trigger.StateID, entityType, entityID)
+		if err != nil {
+			return fmt.Errorf("failed to check entity state: %w", err)
+		}
+		if !inState {
+			log.Printf("[WorkflowEngine] Skipping trigger %s: %s/%s is no longer in the trigger's state", triggerID, entityType, entityID)
+			return nil
+		}
+	}
+
 	// Get entity data for template rendering
 	entityData, err := e.getEntityData(ctx, orgID, entityType, entityID)
 	if err != nil {
@@ -208,6 +266,112 @@ func (e *Engine) ExecuteTriggerByID(ctx context.Context, orgID, triggerID uuid.U
 	return e.executeTrigger(ctx, orgID, workflow, trigger, entityType, entityID, entityData)
 }
 
+// FireEventTriggers executes triggers of the given type (e.g. compliance_overdue) from the default workflow
+// for the entity type, limited to triggers attached to the entity's current state.
+// It is used by background jobs that change or inspect entities outside the services.
+// extraData is merged into the entity data used for template rendering.
+func (e *Engine) FireEventTriggers(ctx context.Context, orgID uuid.UUID, triggerType models.TriggerType, entityType string, entityID uuid.UUID, currentState string, extraData map[string]interface{}) (int, error) {
+	return e.fireEventTriggers(ctx, orgID, triggerType, entityType, entityType, entityID, currentState, extraData)
+}
+
+// FireProjectTriggers executes triggers of the given type (e.g. task_due, payment_overdue) from the default
+// project workflow, limited to triggers attached to the project's current state, against an entity
+// of the project such as a task or a payment.
+func (e *Engine) FireProjectTriggers(ctx context.Context, orgID uuid.UUID, triggerType models.TriggerType, entityType string, entityID uuid.UUID, projectStatus string, extraData map[string]interface{}) (int, error) {
+	return e.fireEventTriggers(ctx, orgID, triggerType, "project", entityType, entityID, projectStatus, extraData)
+}
+
+// fireEventTriggers executes the workflowEntityType's default workflow triggers of the given type
+// attached to the currentState, against the entityType/entityID
+func (e *Engine) fireEventTriggers(ctx context.Context, orgID uuid.UUID, triggerType models.TriggerType, workflowEntityType, entityType string, entityID uuid.UUID, currentState string, extraData map[string]interface{}) (int, error) {
+	rows, err := e.db.Pool.Query(ctx, `
+		SELECT t.id
+		FROM workflow_triggers t
+		JOIN workflows w ON w.id = t.workflow_id
+		JOIN workflow_states s ON s.id = t.state_id
+		WHERE w.organization_id = $1 AND w.entity_type = $2 AND w.is_default = true AND w.is_active = true
+		AND t.trigger_type = $3 AND t.is_active = true AND s.name = $4
+	`, orgID, workflowEntityType, triggerType, currentState)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query event triggers: %w", err)
+	}
+	var triggerIDs []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan trigger: %w", err)
+		}
+		triggerIDs = append(triggerIDs, id)
+	}
+	rows.Close()
+
+	if len(triggerIDs) == 0 {
+		return 0, nil
+	}
+
+	entityData, err := e.getEntityData(ctx, orgID, entityType, entityID)
+	if err != nil {
+		log.Printf("[WorkflowEngine] Failed to get entity data: %v", err)
+		entityData = make(map[string]interface{})
+	}
+	for k, v := range extraData {
+		entityData[k] = v
+	}
+
+	fired := 0
+	for _, triggerID := range triggerIDs {
+		trigger, workflow, err := e.getTriggerWithWorkflow(ctx, triggerID, orgID)
+		if err != nil {
+			log.Printf("[WorkflowEngine] Failed to load trigger %s: %v", triggerID, err)
+			continue
+		}
+		if err := e.executeTrigger(ctx, orgID, workflow, trigger, entityType, entityID, entityData); err != nil {
+			log.Printf("[WorkflowEngine] Failed to execute %s trigger %s: %v", triggerType, triggerID, err)
+			continue
+		}
+		fired++
+	}
+
+	return fired, nil
+}
+
+// isEntityInState checks whether an entity's current status matches a workflow state.
+// Sent budgets past their validity date count as expired.
+func (e *Engine) isEntityInState(ctx context.Context, orgID, stateID uuid.UUID, entityType string, entityID uuid.UUID) (bool, error) {
+	var stateName string
+	if err := e.db.Pool.QueryRow(ctx, `SELECT name FROM workflow_states WHERE id = $1`, stateID).Scan(&stateName); err != nil {
+		return false, err
+	}
+
+	var query string
+	switch entityType {
+	case "session":
+		query = `SELECT status FROM sessions WHERE id = $1 AND organization_id = $2`
+	case "budget":
+		query = `
+			SELECT CASE WHEN status = 'sent' AND valid_until < CURRENT_DATE THEN 'expired' ELSE status END
+			FROM budgets WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+		`
+	case "project":
+		query = `SELECT status FROM projects WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL`
+	case "invoice":
+		query = `SELECT status FROM invoices WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL`
+	default:
+		return true, nil
+	}
+
+	var status string
+	if err := e.db.Pool.QueryRow(ctx, query, entityID, orgID).Scan(&status); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return status == stateName, nil
+}
+
 // getTriggerWithWorkflow gets a trigger and its parent workflow
 func (e *Engine) getTriggerWithWorkflow(ctx context.Context, triggerID, orgID uuid.UUID) (*models.WorkflowTrigger, *models.Workflow, error) {
 	var trigger models.WorkflowTrigger
@@ -215,14 +379,15 @@ func (e *Engine) getTriggerWithWorkflow(ctx context.Context, triggerID, orgID uu
 
 	err := e.db.Pool.QueryRow(ctx, `
 		SELECT t.id, t.workflow_id, t.state_id, t.transition_id, t.trigger_type,
-		       t.time_offset_minutes, t.time_field, t.recurring_cron, t.conditions, t.is_active, t.created_at
+		       t.time_offset_minutes, t.time_field, t.recurring_cron, t.recurring_skip, t.recurring_last_run_at,
+		       t.conditions, t.is_active, t.created_at
 		FROM workflow_triggers t
 		JOIN workflows w ON w.id = t.workflow_id
 		WHERE t.id = $1 AND w.organization_id = $2
 	`, triggerID, orgID).Scan(
 		&trigger.ID, &workflowID, &trigger.StateID, &trigger.TransitionID, &trigger.TriggerType,
-		&trigger.TimeOffsetMinutes, &trigger.TimeField, &trigger.RecurringCron, &trigger.Conditions,
-		&trigger.IsActive, &trigger.CreatedAt,
+		&trigger.TimeOffsetMinutes, &trigger.TimeField, &trigger.RecurringCron, &trigger.RecurringSkip,
+		&trigger.RecurringLastRunAt, &trigger.Conditions, &trigger.IsActive, &trigger.CreatedAt,
 	)
 	if err != nil {
 		return nil, nil, err
@@ -230,7 +395,8 @@ func (e *Engine) getTriggerWithWorkflow(ctx context.Context, triggerID, orgID uu
 
 	// Load actions
 	rows, err := e.db.Pool.Query(ctx, `
-		SELECT id, trigger_id, action_type, action_order, template_id, action_config, is_active, created_at
+		SELECT id, trigger_id, action_type, action_order, template_id, action_config, urgency,
+		       retry_max_attempts, retry_backoff_seconds, is_active, created_at
 		FROM workflow_actions
 		WHERE trigger_id = $1
 		ORDER BY action_order ASC
@@ -244,7 +410,8 @@ func (e *Engine) getTriggerWithWorkflow(ctx context.Context, triggerID, orgID uu
 		var action models.WorkflowAction
 		if err := rows.Scan(
 			&action.ID, &action.TriggerID, &action.ActionType, &action.ActionOrder,
-			&action.TemplateID, &action.ActionConfig, &action.IsActive, &action.CreatedAt,
+			&action.TemplateID, &action.ActionConfig, &action.Urgency, &action.RetryMaxAttempts, &action.RetryBackoffSeconds,
+			&action.IsActive, &action.CreatedAt,
 		); err != nil {
 			return nil, nil, err
 		}
@@ -280,6 +447,12 @@ func (e *Engine) getEntityData(ctx context.Context, orgID uuid.UUID, entityType
 		return e.getBudgetData(ctx, orgID, entityID)
 	case "project":
 		return e.getProjectData(ctx, orgID, entityID)
+	case "task":
+		return e.executor.getTaskData(ctx, orgID, entityID)
+	case "payment":
+		return e.executor.getPaymentData(ctx, orgID, entityID)
+	case "invoice":
+		return e.executor.getInvoiceData(ctx, orgID, entityID)
 	}
 
 	return data, nil
@@ -289,9 +462,10 @@ func (e *Engine) getEntityData(ctx context.Context, orgID uuid.UUID, entityType
 func (e *Engine) getSessionData(ctx context.Context, orgID uuid.UUID, sessionID uuid.UUID) (map[string]interface{}, error) {
 	data := make(map[string]interface{})
 
-	var patientName, therapistName, sessionType, status string
+	var patientName, therapistName, sessionType, status, cancellationNote string
 	var scheduledAt time.Time
 	var patientPhone, patientEmail *string
+	var cancellationFeeCents int
 
 	err := e.db.Pool.QueryRow(ctx, `
 		SELECT
@@ -301,28 +475,34 @@ func (e *Engine) getSessionData(ctx context.Context, orgID uuid.UUID, sessionID
 			COALESCE(c.name, '') as patient_name,
 			c.phone as patient_phone,
 			c.email as patient_email,
-			COALESCE(u.name, '') as therapist_name
+			COALESCE(t.name, '') as therapist_name,
+			COALESCE(s.cancellation_fee_cents, 0),
+			COALESCE(s.cancellation_policy_note, '')
 		FROM sessions s
 		LEFT JOIN patients p ON p.id = s.patient_id
 		LEFT JOIN clients c ON c.id = p.client_id
-		LEFT JOIN users u ON u.id = s.therapist_id
+		LEFT JOIN therapists t ON t.id = s.therapist_id
 		WHERE s.id = $1 AND s.organization_id = $2
 	`, sessionID, orgID).Scan(
 		&scheduledAt, &sessionType, &status,
 		&patientName, &patientPhone, &patientEmail, &therapistName,
+		&cancellationFeeCents, &cancellationNote,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get session data: %w", err)
 	}
 
+	// Dates and times in messages are on the organization's wall clock
+	loc := OrganizationLocation(ctx, e.db.Pool, orgID)
 	data["session_id"] = sessionID.String()
-	data["scheduled_at"] = scheduledAt
-	data["session_date"] = scheduledAt.Format("02/01/2006")
-	data["session_time"] = scheduledAt.Format("15:04")
+	data["scheduled_at"] = scheduledAt.In(loc)
+	data["session_date"], data["session_time"] = SessionTimeVars(scheduledAt, loc)
 	data["session_type"] = sessionType
 	data["status"] = status
 	data["patient_name"] = patientName
 	data["therapist_name"] = therapistName
+	data["cancellation_fee"] = fmt.Sprintf("%.2f", float64(cancellationFeeCents)/100)
+	data["cancellation_policy"] = cancellationNote
 
 	if patientPhone != nil {
 		data["patient_phone"] = *patientPhone
@@ -340,7 +520,7 @@ func (e *Engine) getBudgetData(ctx context.Context, orgID uuid.UUID, budgetID uu
 
 	var clientName, status, budgetNumber, worksheetTitle string
 	var total float64
-	var clientEmail, clientPhone *string
+	var clientEmail, clientPhone, portalToken *string
 
 	err := e.db.Pool.QueryRow(ctx, `
 		SELECT
@@ -350,13 +530,14 @@ func (e *Engine) getBudgetData(ctx context.Context, orgID uuid.UUID, budgetID uu
 			w.title as worksheet_title,
 			COALESCE(c.name, '') as client_name,
 			c.email as client_email,
-			c.phone as client_phone
+			c.phone as client_phone,
+			b.portal_token
 		FROM budgets b
 		LEFT JOIN worksheets w ON w.id = b.worksheet_id
 		LEFT JOIN clients c ON c.id = w.client_id
 		WHERE b.id = $1 AND b.organization_id = $2
 	`, budgetID, orgID).Scan(
-		&status, &budgetNumber, &total, &worksheetTitle, &clientName, &clientEmail, &clientPhone,
+		&status, &budgetNumber, &total, &worksheetTitle, &clientName, &clientEmail, &clientPhone, &portalToken,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get budget data: %w", err)
@@ -375,6 +556,7 @@ func (e *Engine) getBudgetData(ctx context.Context, orgID uuid.UUID, budgetID uu
 	if clientPhone != nil {
 		data["client_phone"] = *clientPhone
 	}
+	e.executor.addBudgetLinks(data, portalToken)
 
 	return data, nil
 }
@@ -438,8 +620,26 @@ func (e *Engine) logEvent(ctx context.Context, orgID, workflowID uuid.UUID, enti
 		(id, organization_id, workflow_id, entity_type, entity_id, event_type, from_state, to_state, details)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`, uuid.New(), orgID, workflowID, entityType, entityID, eventType, fromState, toState, detailsJSON)
+	if err != nil {
+		return err
+	}
+
+	if eventType == models.EventTypeActionFailed {
+		data := map[string]interface{}{"workflow_id": workflowID}
+		for _, key := range []string{"action_id", "action_type", "error", "retry_scheduled"} {
+			if v, ok := details[key]; ok {
+				data[key] = v
+			}
+		}
+		e.events.Publish(ctx, orgID, models.DashboardEvent{
+			Type:       models.DashboardEventActionFailed,
+			EntityType: entityType,
+			EntityID:   entityID,
+			Data:       data,
+		})
+	}
 
-	return err
+	return nil
 }
 
 // GetScheduler returns the scheduler instance
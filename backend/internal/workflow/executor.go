@@ -3,18 +3,97 @@ package workflow
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/controlwise/backend/internal/database"
 	"github.com/controlwise/backend/internal/models"
 	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
 )
 
+// ErrMessageCapReached is returned for non-critical messages once the monthly message cap is reached
+var ErrMessageCapReached = errors.New("monthly message cap reached, non-critical message not sent")
+
+// ErrRecipientOptedOut is returned for messages to addresses that opted out of the channel
+var ErrRecipientOptedOut = errors.New("recipient has opted out of messages on this channel")
+
+// ErrRecipientSuppressed is returned for emails to addresses that hard bounced or complained
+var ErrRecipientSuppressed = errors.New("recipient email address is suppressed")
+
 // NotificationSender interface for sending notifications
 type NotificationSender interface {
 	SendWhatsApp(ctx context.Context, phone, message string) error
-	SendEmail(ctx context.Context, to, subject, body string) error
+	SendWhatsAppTemplate(ctx context.Context, phone, contentSID string, variables map[string]string) error
+}
+
+// EmailSender sends emails through the organization's configured email provider
+type EmailSender interface {
+	SendEmail(ctx context.Context, orgID uuid.UUID, to, subject, body string) error
+}
+
+// EntityTransitioner moves the entities related to the one a transition_entity action runs for
+type EntityTransitioner interface {
+	TransitionRelated(ctx context.Context, orgID uuid.UUID, actionID uuid.UUID, entityType string, entityID uuid.UUID, config map[string]interface{}) error
+}
+
+// ProjectCreator creates the project of the approved budget a create_project action runs for
+type ProjectCreator interface {
+	CreateProject(ctx context.Context, orgID uuid.UUID, actionID uuid.UUID, entityType string, entityID uuid.UUID, config map[string]interface{}) error
+}
+
+// TaskCreator creates the task of a create_task action, rerouted to the assignee's delegate
+// while they are out of office
+type TaskCreator interface {
+	CreateTask(ctx context.Context, orgID uuid.UUID, actionID uuid.UUID, entityType string, entityID uuid.UUID, config map[string]interface{}) error
+}
+
+// PaymentLinker returns the online payment link of an entity for the {{payment_link}} template
+// variable, or "" when there is nothing to pay online
+type PaymentLinker interface {
+	LinkFor(ctx context.Context, orgID uuid.UUID, entityType string, entityID uuid.UUID) (string, error)
+}
+
+// PaymentReferencer returns the Multibanco and SEPA payment references of an entity for the
+// {{mb_entity}}, {{mb_reference}}, {{sepa_reference}} and {{iban}} template variables, or nil
+// when there is nothing to pay
+type PaymentReferencer interface {
+	ReferencesFor(ctx context.Context, orgID uuid.UUID, entityType string, entityID uuid.UUID) (map[string]string, error)
+}
+
+// RescheduleLinker returns a link with which the patient moves a session to another free slot,
+// for the {{reschedule_link}} template variable, or "" when the session can no longer be moved
+type RescheduleLinker interface {
+	LinkFor(ctx context.Context, orgID uuid.UUID, entityType string, entityID uuid.UUID) (string, error)
+}
+
+// SlotOfferer offers the slot of a cancelled session to the next suitable patient on the waiting
+// list, valid until expiresAt. It returns nil when there is nothing to offer or nobody to offer it to.
+type SlotOfferer interface {
+	OfferSlot(ctx context.Context, orgID, actionID, sessionID uuid.UUID, expiresAt time.Time) (*models.WaitingListOffer, error)
+	// WithdrawOffer takes back an offer whose message could not be sent
+	WithdrawOffer(ctx context.Context, offerID uuid.UUID) error
+}
+
+// ChatSender posts messages to an organization's Slack and Teams channels
+type ChatSender interface {
+	// SendChat posts to one chat webhook, or to all active ones when webhookID is nil
+	SendChat(ctx context.Context, orgID uuid.UUID, webhookID *uuid.UUID, text string) error
+	// SendAlert posts to the chat webhooks that asked to hear about failed workflow actions
+	SendAlert(ctx context.Context, orgID uuid.UUID, text string) error
+}
+
+// ApprovedTemplate is a pre-approved WhatsApp template, sent instead of free-form text
+// when the recipient's customer service window is closed
+type ApprovedTemplate struct {
+	ContentSID string
+	Variables  map[string]string // {"1": "...", "2": "..."}
 }
 
 // Executor handles workflow action execution
@@ -22,6 +101,20 @@ type Executor struct {
 	db             *database.DB
 	templates      *TemplateRenderer
 	notifySender   NotificationSender
+	emailSender    EmailSender
+	limiter        *RateLimiter
+	client         *asynq.Client
+	actions        *ActionRegistry
+	transitioner   EntityTransitioner
+	projects       ProjectCreator
+	tasks          TaskCreator
+	paymentLinks   PaymentLinker
+	paymentRefs    PaymentReferencer
+	chatSender     ChatSender
+	rescheduleLinks RescheduleLinker
+	slotOffers     SlotOfferer
+	frontendURL    string // base of the links put in messages, e.g. the budget portal
+	syntheticOrg   uuid.UUID // organization of the synthetic traffic, whose messages are not sent
 }
 
 // NewExecutor creates a new action executor
@@ -29,6 +122,7 @@ func NewExecutor(db *database.DB) *Executor {
 	return &Executor{
 		db:        db,
 		templates: NewTemplateRenderer(db),
+		actions:   NewActionRegistry(),
 	}
 }
 
@@ -37,26 +131,236 @@ func (e *Executor) SetNotificationSender(sender NotificationSender) {
 	e.notifySender = sender
 }
 
-// ExecuteAction executes a single workflow action
-func (e *Executor) ExecuteAction(ctx context.Context, orgID uuid.UUID, action *models.WorkflowAction, entityType string, entityID uuid.UUID, entityData map[string]interface{}) error {
+// SetFrontendURL sets the base of the links put in messages
+func (e *Executor) SetFrontendURL(url string) {
+	e.frontendURL = strings.TrimRight(url, "/")
+}
+
+// SetSyntheticOrganization makes sending the messages of the organization synthetic traffic is
+// generated in a no-op, everything up to the provider call still running
+func (e *Executor) SetSyntheticOrganization(orgID uuid.UUID) {
+	e.syntheticOrg = orgID
+}
+
+// SetEmailSender sets the email sender implementation
+func (e *Executor) SetEmailSender(sender EmailSender) {
+	e.emailSender = sender
+}
+
+// SetEntityTransitioner sets the implementation of transition_entity actions
+func (e *Executor) SetEntityTransitioner(transitioner EntityTransitioner) {
+	e.transitioner = transitioner
+}
+
+// SetProjectCreator sets the implementation of create_project actions
+func (e *Executor) SetProjectCreator(creator ProjectCreator) {
+	e.projects = creator
+}
+
+// SetTaskCreator sets the implementation of create_task actions
+func (e *Executor) SetTaskCreator(creator TaskCreator) {
+	e.tasks = creator
+}
+
+// SetPaymentLinker sets the provider of {{payment_link}} template variables
+func (e *Executor) SetPaymentLinker(linker PaymentLinker) {
+	e.paymentLinks = linker
+}
+
+// SetPaymentReferencer sets the provider of Multibanco and SEPA reference template variables
+func (e *Executor) SetPaymentReferencer(referencer PaymentReferencer) {
+	e.paymentRefs = referencer
+}
+
+// SetRescheduleLinker sets the provider of {{reschedule_link}} template variables
+func (e *Executor) SetRescheduleLinker(linker RescheduleLinker) {
+	e.rescheduleLinks = linker
+}
+
+// SetSlotOfferer sets the implementation of offer_slot actions
+func (e *Executor) SetSlotOfferer(offerer SlotOfferer) {
+	e.slotOffers = offerer
+}
+
+// SetChatSender sets the implementation of send_chat actions and failed action alerts
+func (e *Executor) SetChatSender(sender ChatSender) {
+	e.chatSender = sender
+}
+
+// SetRateLimiter sets the limiter that spreads out messages over provider and organization rate limits
+func (e *Executor) SetRateLimiter(limiter *RateLimiter) {
+	e.limiter = limiter
+}
+
+// RegisterAction registers the handler of a custom action type, used for actions whose type
+// is not one of the built-in ones
+func (e *Executor) RegisterAction(actionType models.ActionType, handler ActionHandler) error {
+	return e.actions.Register(actionType, handler)
+}
+
+// Delivery outcomes of message actions, recorded in the execution log
+const (
+	DeliverySent                 = "sent"
+	DeliveryHeld                 = "held"                    // normal action during do-not-disturb, sent when it ends
+	DeliveryBypassedDoNotDisturb = "bypassed_do_not_disturb" // urgent action sent during do-not-disturb
+	DeliveryDeferred             = "deferred"                // normal action in quiet hours, sent when the send window opens
+	DeliveryBypassedQuietHours   = "bypassed_quiet_hours"    // urgent action sent in quiet hours
+)
+
+// DeliveryDecision records how a message action was handled with respect to do-not-disturb and
+// the organization's send window
+type DeliveryDecision struct {
+	Urgency           models.ActionUrgency `json:"urgency"`
+	Outcome           string               `json:"outcome"`
+	DoNotDisturbUntil *time.Time           `json:"do_not_disturb_until,omitempty"`
+	// When the send window next opens, if the message would otherwise go out in quiet hours
+	SendWindowOpensAt *time.Time `json:"send_window_opens_at,omitempty"`
+}
+
+// releaseAt is when a held or deferred message goes out: once do-not-disturb has ended and the
+// send window is open
+func (d *DeliveryDecision) releaseAt() *time.Time {
+	if d.SendWindowOpensAt != nil {
+		return d.SendWindowOpensAt
+	}
+	return d.DoNotDisturbUntil
+}
+
+// ExecuteAction executes a single workflow action. For message actions it also returns
+// the do-not-disturb decision taken.
+func (e *Executor) ExecuteAction(ctx context.Context, orgID uuid.UUID, action *models.WorkflowAction, entityType string, entityID uuid.UUID, entityData map[string]interface{}) (*DeliveryDecision, error) {
 	log.Printf("[Executor] Executing action %s (type=%s)", action.ID, action.ActionType)
 
 	switch action.ActionType {
 	case models.ActionTypeSendWhatsApp:
-		return e.executeSendWhatsApp(ctx, orgID, action, entityType, entityID, entityData)
+		decision := e.deliveryDecision(ctx, orgID, action)
+		return decision, e.executeSendWhatsApp(ctx, orgID, action, decision, entityType, entityID, entityData)
 	case models.ActionTypeSendEmail:
-		return e.executeSendEmail(ctx, orgID, action, entityType, entityID, entityData)
+		decision := e.deliveryDecision(ctx, orgID, action)
+		return decision, e.executeSendEmail(ctx, orgID, action, decision, entityType, entityID, entityData)
 	case models.ActionTypeUpdateField:
-		return e.executeUpdateField(ctx, orgID, action, entityType, entityID, entityData)
+		return nil, e.executeUpdateField(ctx, orgID, action, entityType, entityID, entityData)
 	case models.ActionTypeCreateTask:
-		return e.executeCreateTask(ctx, orgID, action, entityType, entityID, entityData)
+		return nil, e.executeCreateTask(ctx, orgID, action, entityType, entityID)
+	case models.ActionTypeNotifyUser:
+		return nil, e.executeNotifyUser(ctx, orgID, action, entityType, entityID, entityData)
+	case models.ActionTypeTransitionEntity:
+		return nil, e.executeTransitionEntity(ctx, orgID, action, entityType, entityID)
+	case models.ActionTypeCreateProject:
+		return nil, e.executeCreateProject(ctx, orgID, action, entityType, entityID)
+	case models.ActionTypeSendChat:
+		return nil, e.executeSendChat(ctx, orgID, action, entityType, entityID, entityData)
+	case models.ActionTypeOfferSlot:
+		decision := e.deliveryDecision(ctx, orgID, action)
+		return decision, e.executeOfferSlot(ctx, orgID, action, decision, entityType, entityID)
 	default:
-		return fmt.Errorf("unknown action type: %s", action.ActionType)
+		if handler, ok := e.actions.Lookup(action.ActionType); ok {
+			return nil, e.executeCustomAction(ctx, handler, orgID, action, entityType, entityID, entityData)
+		}
+		return nil, fmt.Errorf("unknown action type: %s", action.ActionType)
+	}
+}
+
+// executeTransitionEntity moves the related entity named in the action config
+func (e *Executor) executeTransitionEntity(ctx context.Context, orgID uuid.UUID, action *models.WorkflowAction, entityType string, entityID uuid.UUID) error {
+	if e.transitioner == nil {
+		return errors.New("entity transitions are not configured")
+	}
+
+	config, err := parseActionConfig(action.ActionConfig)
+	if err != nil {
+		return fmt.Errorf("failed to parse action config: %w", err)
 	}
+
+	return e.transitioner.TransitionRelated(ctx, orgID, action.ID, entityType, entityID, config)
+}
+
+// executeCreateProject creates the project of the budget the workflow runs for
+func (e *Executor) executeCreateProject(ctx context.Context, orgID uuid.UUID, action *models.WorkflowAction, entityType string, entityID uuid.UUID) error {
+	if e.projects == nil {
+		return errors.New("project creation is not configured")
+	}
+
+	config, err := parseActionConfig(action.ActionConfig)
+	if err != nil {
+		return fmt.Errorf("failed to parse action config: %w", err)
+	}
+
+	return e.projects.CreateProject(ctx, orgID, action.ID, entityType, entityID, config)
+}
+
+// executeCustomAction runs an action through its registered custom handler
+func (e *Executor) executeCustomAction(ctx context.Context, handler ActionHandler, orgID uuid.UUID, action *models.WorkflowAction, entityType string, entityID uuid.UUID, entityData map[string]interface{}) error {
+	config, err := parseActionConfig(action.ActionConfig)
+	if err != nil {
+		return fmt.Errorf("failed to parse action config: %w", err)
+	}
+
+	return handler.Execute(ctx, ActionRequest{
+		OrganizationID: orgID,
+		Action:         action,
+		EntityType:     entityType,
+		EntityID:       entityID,
+		EntityData:     entityData,
+		Config:         config,
+	})
+}
+
+// deliveryDecision decides whether a message action is sent now, held until the organization's
+// do-not-disturb period ends, or deferred out of quiet hours to when its send window next opens.
+// Urgent actions are always sent.
+func (e *Executor) deliveryDecision(ctx context.Context, orgID uuid.UUID, action *models.WorkflowAction) *DeliveryDecision {
+	decision := &DeliveryDecision{Urgency: action.Urgency, Outcome: DeliverySent}
+	if decision.Urgency == "" {
+		decision.Urgency = models.ActionUrgencyNormal
+	}
+
+	var until *time.Time
+	var window *models.SendWindow
+	err := e.db.Pool.QueryRow(ctx, `
+		SELECT CASE WHEN do_not_disturb_until > NOW() THEN do_not_disturb_until END, send_window
+		FROM notification_configs
+		WHERE organization_id = $1
+	`, orgID).Scan(&until, &window)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("[Executor] Failed to check do-not-disturb, sending: %v", err)
+		}
+		return decision
+	}
+
+	// A message held by do-not-disturb must also wait for the window once it ends
+	if window != nil {
+		from := time.Now()
+		if until != nil {
+			from = *until
+		}
+		if opens := window.NextAllowed(from); opens.After(from) {
+			decision.SendWindowOpensAt = &opens
+		}
+	}
+	if until == nil && decision.SendWindowOpensAt == nil {
+		return decision
+	}
+
+	decision.DoNotDisturbUntil = until
+	switch {
+	case decision.Urgency == models.ActionUrgencyUrgent && until != nil:
+		decision.Outcome = DeliveryBypassedDoNotDisturb
+	case decision.Urgency == models.ActionUrgencyUrgent:
+		decision.Outcome = DeliveryBypassedQuietHours
+	case e.client == nil:
+		log.Printf("[Executor] Job queue not configured, cannot hold message until %v", *decision.releaseAt())
+	case until != nil:
+		decision.Outcome = DeliveryHeld
+	default:
+		decision.Outcome = DeliveryDeferred
+	}
+	return decision
 }
 
 // executeSendWhatsApp sends a WhatsApp message using a template
-func (e *Executor) executeSendWhatsApp(ctx context.Context, orgID uuid.UUID, action *models.WorkflowAction, entityType string, entityID uuid.UUID, entityData map[string]interface{}) error {
+func (e *Executor) executeSendWhatsApp(ctx context.Context, orgID uuid.UUID, action *models.WorkflowAction, decision *DeliveryDecision, entityType string, entityID uuid.UUID, entityData map[string]interface{}) error {
 	if action.TemplateID == nil {
 		return fmt.Errorf("WhatsApp action requires a template")
 	}
@@ -79,11 +383,9 @@ func (e *Executor) executeSendWhatsApp(ctx context.Context, orgID uuid.UUID, act
 		}
 	}
 
-	// Render template
-	message, err := e.templates.RenderTemplate(template.Body, entityData)
-	if err != nil {
-		return fmt.Errorf("failed to render template: %w", err)
-	}
+	e.addPaymentLink(ctx, orgID, entityType, entityID, entityData, template.Body)
+	e.addPaymentReferences(ctx, orgID, entityType, entityID, entityData, template.Body)
+	e.addRescheduleLink(ctx, orgID, entityType, entityID, entityData, template.Body)
 
 	// Get recipient phone number
 	phone, ok := entityData["patient_phone"].(string)
@@ -94,22 +396,109 @@ func (e *Executor) executeSendWhatsApp(ctx context.Context, orgID uuid.UUID, act
 		}
 	}
 
-	log.Printf("[Executor] Sending WhatsApp to %s: %s", phone, truncateString(message, 50))
+	return e.sendWhatsAppTemplate(ctx, orgID, action, decision, template, phone, entityData)
+}
 
-	// Send notification
-	if e.notifySender != nil {
-		if err := e.notifySender.SendWhatsApp(ctx, phone, message); err != nil {
-			return fmt.Errorf("failed to send WhatsApp: %w", err)
+// sendWhatsAppTemplate renders a WhatsApp template with the data and sends it to the phone
+func (e *Executor) sendWhatsAppTemplate(ctx context.Context, orgID uuid.UUID, action *models.WorkflowAction, decision *DeliveryDecision, template *models.MessageTemplate, phone string, entityData map[string]interface{}) error {
+	optedOut, err := e.recipientOptedOut(ctx, orgID, models.MessageChannelWhatsApp, phone)
+	if err != nil {
+		return fmt.Errorf("failed to check WhatsApp opt-out: %w", err)
+	}
+	if optedOut {
+		log.Printf("[Executor] %s opted out of WhatsApp messages, skipping send", phone)
+		return nil
+	}
+
+	message, err := e.templates.RenderTemplate(template.Body, entityData)
+	if err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
+
+	// Free-form text is only delivered within 24h of the recipient's last message,
+	// outside it Twilio rejects it (63016) and the approved template is sent instead
+	var content *ApprovedTemplate
+	open, err := e.whatsappSessionOpen(ctx, orgID, phone)
+	if err != nil {
+		return fmt.Errorf("failed to check WhatsApp session window: %w", err)
+	}
+	if !open {
+		content, err = e.approvedTemplate(template, entityData)
+		if err != nil {
+			return err
 		}
+		log.Printf("[Executor] Session window closed for %s, sending approved template %s", phone, content.ContentSID)
 	} else {
-		log.Printf("[Executor] WhatsApp sender not configured, skipping send")
+		log.Printf("[Executor] Sending WhatsApp to %s: %s", phone, truncateString(message, 50))
 	}
 
-	return nil
+	// Send notification
+	return e.deliver(ctx, orgID, models.MessageChannelWhatsApp, phone, "", message, content, decision, isCriticalAction(action))
+}
+
+// recipientOptedOut reports whether the address opted out of the organization's messages on the channel
+func (e *Executor) recipientOptedOut(ctx context.Context, orgID uuid.UUID, channel models.MessageChannel, to string) (bool, error) {
+	consentChannel := models.ConsentChannel(channel)
+	var optedOut bool
+	err := e.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM contact_consents
+			WHERE organization_id = $1 AND channel = $2 AND address = $3 AND status = 'opted_out'
+		)
+	`, orgID, consentChannel, models.NormalizeConsentAddress(consentChannel, to)).Scan(&optedOut)
+	return optedOut, err
+}
+
+// emailSuppressed reports whether the address hard bounced or complained about the organization's emails
+func (e *Executor) emailSuppressed(ctx context.Context, orgID uuid.UUID, to string) (bool, error) {
+	var suppressed bool
+	err := e.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM email_suppressions WHERE organization_id = $1 AND address = $2)
+	`, orgID, models.NormalizeConsentAddress(models.ConsentChannelEmail, to)).Scan(&suppressed)
+	return suppressed, err
+}
+
+// whatsappSessionOpen reports whether the phone number messaged the organization within the
+// WhatsApp customer service window
+func (e *Executor) whatsappSessionOpen(ctx context.Context, orgID uuid.UUID, phone string) (bool, error) {
+	var lastInboundAt *time.Time
+	err := e.db.Pool.QueryRow(ctx, `
+		SELECT last_inbound_at FROM whatsapp_conversations
+		WHERE organization_id = $1 AND phone_number = $2
+	`, orgID, models.NormalizeWhatsAppPhone(phone)).Scan(&lastInboundAt)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return false, err
+	}
+	return models.NewWhatsAppSessionWindow(phone, lastInboundAt).IsOpen, nil
+}
+
+// approvedTemplate fills the template's approved WhatsApp version with the entity data.
+// Placeholder {{n}} takes the n-th template variable.
+func (e *Executor) approvedTemplate(template *models.MessageTemplate, entityData map[string]interface{}) (*ApprovedTemplate, error) {
+	if template.WhatsAppContentSID == nil || *template.WhatsAppContentSID == "" {
+		return nil, fmt.Errorf("WhatsApp session window is closed and template %q has no approved WhatsApp template", template.Name)
+	}
+
+	vars, err := template.GetVariables()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template variables: %w", err)
+	}
+	content := &ApprovedTemplate{
+		ContentSID: *template.WhatsAppContentSID,
+		Variables:  make(map[string]string, len(vars)),
+	}
+	for i, v := range vars {
+		value, err := e.templates.RenderTemplate("{{"+v.Name+"}}", entityData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render template variable %s: %w", v.Name, err)
+		}
+		content.Variables[fmt.Sprint(i+1)] = value
+	}
+	return content, nil
 }
 
 // executeSendEmail sends an email using a template or inline config
-func (e *Executor) executeSendEmail(ctx context.Context, orgID uuid.UUID, action *models.WorkflowAction, entityType string, entityID uuid.UUID, entityData map[string]interface{}) error {
+func (e *Executor) executeSendEmail(ctx context.Context, orgID uuid.UUID, action *models.WorkflowAction, decision *DeliveryDecision, entityType string, entityID uuid.UUID, entityData map[string]interface{}) error {
 	var subject, body string
 	var err error
 
@@ -119,6 +508,10 @@ func (e *Executor) executeSendEmail(ctx context.Context, orgID uuid.UUID, action
 		if err != nil {
 			return fmt.Errorf("failed to get entity data: %w", err)
 		}
+	} else if _, ok := entityData["organization_name"]; !ok {
+		if err := e.addOrganizationData(ctx, orgID, entityData); err != nil {
+			log.Printf("[Executor] Failed to add organization branding: %v", err)
+		}
 	}
 
 	if action.TemplateID != nil {
@@ -132,6 +525,14 @@ func (e *Executor) executeSendEmail(ctx context.Context, orgID uuid.UUID, action
 			return fmt.Errorf("template is not an email template")
 		}
 
+		subjectTemplate := ""
+		if template.Subject != nil {
+			subjectTemplate = *template.Subject
+		}
+		e.addPaymentLink(ctx, orgID, entityType, entityID, entityData, subjectTemplate, template.Body)
+		e.addPaymentReferences(ctx, orgID, entityType, entityID, entityData, subjectTemplate, template.Body)
+		e.addRescheduleLink(ctx, orgID, entityType, entityID, entityData, subjectTemplate, template.Body)
+
 		// Render template body
 		body, err = e.templates.RenderTemplate(template.Body, entityData)
 		if err != nil {
@@ -164,6 +565,10 @@ func (e *Executor) executeSendEmail(ctx context.Context, orgID uuid.UUID, action
 			bodyTemplate = "Olá {{client_name}},\n\nTem uma nova notificação.\n\nCumprimentos"
 		}
 
+		e.addPaymentLink(ctx, orgID, entityType, entityID, entityData, subjectTemplate, bodyTemplate)
+		e.addPaymentReferences(ctx, orgID, entityType, entityID, entityData, subjectTemplate, bodyTemplate)
+		e.addRescheduleLink(ctx, orgID, entityType, entityID, entityData, subjectTemplate, bodyTemplate)
+
 		subject, err = e.templates.RenderTemplate(subjectTemplate, entityData)
 		if err != nil {
 			return fmt.Errorf("failed to render subject: %w", err)
@@ -197,20 +602,220 @@ func (e *Executor) executeSendEmail(ctx context.Context, orgID uuid.UUID, action
 		return fmt.Errorf("no email address available for entity %s/%s", entityType, entityID)
 	}
 
+	optedOut, err := e.recipientOptedOut(ctx, orgID, models.MessageChannelEmail, email)
+	if err != nil {
+		return fmt.Errorf("failed to check email opt-out: %w", err)
+	}
+	if optedOut {
+		log.Printf("[Executor] %s opted out of email messages, skipping send", email)
+		return nil
+	}
+	suppressed, err := e.emailSuppressed(ctx, orgID, email)
+	if err != nil {
+		return fmt.Errorf("failed to check email suppression: %w", err)
+	}
+	if suppressed {
+		log.Printf("[Executor] %s bounced or complained, skipping send", email)
+		return nil
+	}
+
 	log.Printf("[Executor] Sending email to %s: subject=%s", email, subject)
+	body = brandEmailBody(body, entityData)
 
 	// Send notification
-	if e.notifySender != nil {
-		if err := e.notifySender.SendEmail(ctx, email, subject, body); err != nil {
+	return e.deliver(ctx, orgID, models.MessageChannelEmail, email, subject, body, nil, decision, isCriticalAction(action))
+}
+
+// deliver sends a message now or queues it for later: until the do-not-disturb period ends or the
+// send window opens when the decision holds or defers it, or, when the provider or organization rate limit is reached, until its
+// reserved slot frees up instead of letting the provider reject it
+func (e *Executor) deliver(ctx context.Context, orgID uuid.UUID, channel models.MessageChannel, to, subject, body string, content *ApprovedTemplate, decision *DeliveryDecision, critical bool) error {
+	payload := SendMessagePayload{
+		OrganizationID: orgID,
+		Channel:        string(channel),
+		To:             to,
+		Subject:        subject,
+		Body:           body,
+		Critical:       critical,
+	}
+	if content != nil {
+		payload.ContentSID = content.ContentSID
+		payload.ContentVariables = content.Variables
+	}
+
+	if decision != nil && (decision.Outcome == DeliveryHeld || decision.Outcome == DeliveryDeferred) {
+		releaseAt := *decision.releaseAt()
+		if err := e.enqueueMessage(payload, asynq.ProcessAt(releaseAt)); err != nil {
+			return fmt.Errorf("failed to queue held message: %w", err)
+		}
+		if decision.Outcome == DeliveryHeld {
+			log.Printf("[Executor] Do-not-disturb active, %s message to %s held until %v", channel, to, releaseAt)
+		} else {
+			log.Printf("[Executor] Quiet hours, %s message to %s deferred until %v", channel, to, releaseAt)
+		}
+		return nil
+	}
+
+	if e.limiter != nil && e.client != nil {
+		wait, err := e.limiter.Reserve(ctx, orgID, channel)
+		if err != nil {
+			log.Printf("[Executor] Rate limiter unavailable, sending without limit: %v", err)
+		} else if wait > 0 {
+			if err := e.enqueueMessage(payload, asynq.ProcessIn(wait)); err != nil {
+				return fmt.Errorf("failed to queue rate limited message: %w", err)
+			}
+			log.Printf("[Executor] Rate limit reached, %s message to %s queued for %v", channel, to, wait)
+			return nil
+		}
+	}
+
+	return e.SendMessage(ctx, orgID, channel, to, subject, body, content, critical)
+}
+
+// enqueueMessage queues a rendered message to be sent by the job worker
+func (e *Executor) enqueueMessage(payload SendMessagePayload, when asynq.Option) error {
+	data, _ := json.Marshal(payload)
+	_, err := e.client.Enqueue(asynq.NewTask(TypeSendMessage, data), when, asynq.Queue("default"))
+	return err
+}
+
+// SendMessage sends a rendered message through the notification sender and records its cost.
+// WhatsApp messages with an approved template are sent as that template instead of the body.
+// Non-critical messages are not sent once the organization's monthly message cap is reached, and
+// no message at all to addresses that opted out of the channel, or emails to addresses that were
+// suppressed, since it was queued.
+func (e *Executor) SendMessage(ctx context.Context, orgID uuid.UUID, channel models.MessageChannel, to, subject, body string, content *ApprovedTemplate, critical bool) error {
+	optedOut, err := e.recipientOptedOut(ctx, orgID, channel, to)
+	if err != nil {
+		return fmt.Errorf("failed to check opt-out: %w", err)
+	}
+	if optedOut {
+		return ErrRecipientOptedOut
+	}
+	if channel == models.MessageChannelEmail {
+		suppressed, err := e.emailSuppressed(ctx, orgID, to)
+		if err != nil {
+			return fmt.Errorf("failed to check email suppression: %w", err)
+		}
+		if suppressed {
+			return ErrRecipientSuppressed
+		}
+	}
+
+	if !critical {
+		reached, err := e.messageCapReached(ctx, orgID)
+		if err != nil {
+			log.Printf("[Executor] Failed to check monthly message cap: %v", err)
+		} else if reached {
+			return ErrMessageCapReached
+		}
+	}
+
+	if e.syntheticOrg != uuid.Nil && orgID == e.syntheticOrg {
+		log.Printf("[Executor] Synthetic traffic, %s message to %s not sent", channel, to)
+		return nil
+	}
+
+	switch channel {
+	case models.MessageChannelEmail:
+		if e.emailSender == nil {
+			log.Printf("[Executor] Email sender not configured, skipping send")
+			return nil
+		}
+		if err := e.emailSender.SendEmail(ctx, orgID, to, subject, body); err != nil {
 			return fmt.Errorf("failed to send email: %w", err)
 		}
-	} else {
-		log.Printf("[Executor] Email sender not configured, skipping send")
+	default:
+		if e.notifySender == nil {
+			log.Printf("[Executor] WhatsApp sender not configured, skipping send")
+			return nil
+		}
+		if content != nil {
+			err := e.notifySender.SendWhatsAppTemplate(ctx, to, content.ContentSID, content.Variables)
+			if err != nil {
+				return fmt.Errorf("failed to send WhatsApp template: %w", err)
+			}
+		} else if err := e.notifySender.SendWhatsApp(ctx, to, body); err != nil {
+			return fmt.Errorf("failed to send WhatsApp: %w", err)
+		}
+	}
+
+	provider, _, err := organizationProvider(ctx, e.db, orgID, channel)
+	if err != nil {
+		log.Printf("[Executor] Failed to record message cost: %v", err)
+		return nil
+	}
+	_, err = e.db.Pool.Exec(ctx, `
+		INSERT INTO message_costs (organization_id, channel, provider, cost, currency, source, critical)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, orgID, channel, provider, models.MessageRates[channel], models.MessageCostCurrency,
+		models.MessageCostSourceRateTable, critical)
+	if err != nil {
+		log.Printf("[Executor] Failed to record message cost: %v", err)
 	}
 
 	return nil
 }
 
+// isCriticalAction reports whether a send action is marked critical in its config
+// ({"critical": true}); critical messages keep being sent after the monthly cap is reached
+func isCriticalAction(action *models.WorkflowAction) bool {
+	config, err := parseActionConfig(action.ActionConfig)
+	if err != nil {
+		return false
+	}
+	critical, _ := config["critical"].(bool)
+	return critical
+}
+
+// messageCapReached reports whether the organization's message spend this month reached its cap.
+// Admins are alerted the first time it happens in a month.
+func (e *Executor) messageCapReached(ctx context.Context, orgID uuid.UUID) (bool, error) {
+	var reached bool
+	err := e.db.Pool.QueryRow(ctx, `
+		SELECT nc.monthly_message_cap IS NOT NULL AND COALESCE((
+			SELECT SUM(cost) FROM message_costs
+			WHERE organization_id = $1 AND created_at >= date_trunc('month', NOW())
+		), 0) >= nc.monthly_message_cap
+		FROM notification_configs nc
+		WHERE nc.organization_id = $1
+	`, orgID).Scan(&reached)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	if !reached {
+		return false, nil
+	}
+
+	// Only the first worker to see the cap this month alerts the admins
+	result, err := e.db.Pool.Exec(ctx, `
+		UPDATE notification_configs SET message_cap_alerted_at = NOW()
+		WHERE organization_id = $1
+		AND (message_cap_alerted_at IS NULL OR message_cap_alerted_at < date_trunc('month', NOW()))
+	`, orgID)
+	if err != nil {
+		log.Printf("[Executor] Failed to mark message cap alert: %v", err)
+		return true, nil
+	}
+	if result.RowsAffected() > 0 {
+		_, err = e.db.Pool.Exec(ctx, `
+			INSERT INTO notifications (user_id, type, title, message)
+			SELECT id, $2, 'Limite mensal de mensagens atingido',
+			       'O limite mensal de custos de mensagens foi atingido. Os envios não críticos estão em pausa até ao próximo mês ou até o limite ser aumentado.'
+			FROM users
+			WHERE organization_id = $1 AND role = 'admin' AND is_active = true AND deleted_at IS NULL
+		`, orgID, models.NotificationTypeMessageCapReached)
+		if err != nil {
+			log.Printf("[Executor] Failed to alert admins of message cap: %v", err)
+		}
+	}
+
+	return true, nil
+}
+
 // executeUpdateField updates a field on the entity
 func (e *Executor) executeUpdateField(ctx context.Context, orgID uuid.UUID, action *models.WorkflowAction, entityType string, entityID uuid.UUID, entityData map[string]interface{}) error {
 	// Parse action config
@@ -248,47 +853,341 @@ func (e *Executor) executeUpdateField(ctx context.Context, orgID uuid.UUID, acti
 	return nil
 }
 
-// executeCreateTask creates a task/reminder
-func (e *Executor) executeCreateTask(ctx context.Context, orgID uuid.UUID, action *models.WorkflowAction, entityType string, entityID uuid.UUID, entityData map[string]interface{}) error {
-	// Parse action config
+// executeCreateTask creates a task on the project of the entity the workflow runs for
+func (e *Executor) executeCreateTask(ctx context.Context, orgID uuid.UUID, action *models.WorkflowAction, entityType string, entityID uuid.UUID) error {
+	if e.tasks == nil {
+		return errors.New("task creation is not configured")
+	}
+
 	config, err := parseActionConfig(action.ActionConfig)
 	if err != nil {
 		return fmt.Errorf("failed to parse action config: %w", err)
 	}
 
-	title, _ := config["title"].(string)
-	description, _ := config["description"].(string)
-	assigneeID, _ := config["assignee_id"].(string)
+	return e.tasks.CreateTask(ctx, orgID, action.ID, entityType, entityID, config)
+}
 
-	if title == "" {
-		title = fmt.Sprintf("Task for %s %s", entityType, entityID)
+// executeNotifyUser sends an in-app notification to a user, such as an approval request or escalation
+func (e *Executor) executeNotifyUser(ctx context.Context, orgID uuid.UUID, action *models.WorkflowAction, entityType string, entityID uuid.UUID, entityData map[string]interface{}) error {
+	config, err := parseActionConfig(action.ActionConfig)
+	if err != nil {
+		return fmt.Errorf("failed to parse action config: %w", err)
+	}
+
+	recipients, err := e.notifyUserRecipients(ctx, orgID, config, entityType, entityID)
+	if err != nil {
+		return err
+	}
+	if len(recipients) == 0 {
+		log.Printf("[Executor] No recipients for notify_user action on %s/%s", entityType, entityID)
+		return nil
 	}
 
-	log.Printf("[Executor] Creating task: %s for entity %s/%s", title, entityType, entityID)
+	// kind is either 'approval_request' or 'escalation'
+	kind, _ := config["kind"].(string)
+	notificationType := models.NotificationTypeEscalation
+	delegationType := models.DelegationTypeEscalation
+	if kind == string(models.NotificationTypeApprovalRequest) {
+		notificationType = models.NotificationTypeApprovalRequest
+		delegationType = models.DelegationTypeApproval
+	}
 
-	// For now, just log the task creation
-	// In a full implementation, this would create a task in a tasks table
-	log.Printf("[Executor] Task details: title=%s, description=%s, assignee=%s", title, description, assigneeID)
+	if entityData == nil {
+		entityData, err = e.getEntityData(ctx, orgID, entityType, entityID)
+		if err != nil {
+			return fmt.Errorf("failed to get entity data: %w", err)
+		}
+	}
+
+	titleTemplate, _ := config["title"].(string)
+	messageTemplate, _ := config["message"].(string)
+	if titleTemplate == "" {
+		titleTemplate = "Ação necessária"
+	}
+
+	title, err := e.templates.RenderTemplate(titleTemplate, entityData)
+	if err != nil {
+		return fmt.Errorf("failed to render title: %w", err)
+	}
+	message, err := e.templates.RenderTemplate(messageTemplate, entityData)
+	if err != nil {
+		return fmt.Errorf("failed to render message: %w", err)
+	}
+
+	notified := make(map[uuid.UUID]bool)
+	for _, userID := range recipients {
+		// Reroute to the delegate if the recipient is out of office
+		recipientID := e.resolveDelegate(ctx, orgID, userID, delegationType, entityType, entityID)
+		if notified[recipientID] {
+			continue
+		}
+		notified[recipientID] = true
+
+		log.Printf("[Executor] Notifying user %s (%s): %s", recipientID, notificationType, title)
+
+		_, err = e.db.Pool.Exec(ctx, `
+			INSERT INTO notifications (user_id, type, title, message, entity_type, entity_id)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, recipientID, notificationType, title, message, entityType, entityID)
+		if err != nil {
+			return fmt.Errorf("failed to create notification: %w", err)
+		}
+	}
 
 	return nil
 }
 
+// executeOfferSlot offers the slot of the cancelled session the workflow runs for to the next
+// suitable patient on the waiting list, with the action's WhatsApp template. The offer lasts
+// 'expires_in_minutes' (120 by default), counted from the end of do-not-disturb when the message is held.
+func (e *Executor) executeOfferSlot(ctx context.Context, orgID uuid.UUID, action *models.WorkflowAction, decision *DeliveryDecision, entityType string, entityID uuid.UUID) error {
+	if e.slotOffers == nil {
+		return errors.New("waiting list offers are not configured")
+	}
+	if entityType != string(models.WorkflowEntitySession) {
+		return fmt.Errorf("offer_slot actions only run for sessions, not %s", entityType)
+	}
+	if action.TemplateID == nil {
+		return errors.New("offer_slot action requires a WhatsApp template")
+	}
+
+	template, err := e.templates.GetTemplate(ctx, *action.TemplateID, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to get template: %w", err)
+	}
+	if template.Channel != models.MessageChannelWhatsApp {
+		return fmt.Errorf("template is not a WhatsApp template")
+	}
+
+	config, err := parseActionConfig(action.ActionConfig)
+	if err != nil {
+		return fmt.Errorf("failed to parse action config: %w", err)
+	}
+	expiresIn := 120 * time.Minute
+	if minutes, ok := config["expires_in_minutes"].(float64); ok && minutes > 0 {
+		expiresIn = time.Duration(minutes) * time.Minute
+	}
+	from := time.Now()
+	if decision.Outcome == DeliveryHeld || decision.Outcome == DeliveryDeferred {
+		from = *decision.releaseAt()
+	}
+
+	offer, err := e.slotOffers.OfferSlot(ctx, orgID, action.ID, entityID, from.Add(expiresIn))
+	if err != nil {
+		return err
+	}
+	if offer == nil {
+		log.Printf("[Executor] No waiting list offer made for session %s", entityID)
+		return nil
+	}
+
+	loc := OrganizationLocation(ctx, e.db.Pool, orgID)
+	sessionDate, sessionTime := SessionTimeVars(offer.ScheduledAt, loc)
+	data := map[string]interface{}{
+		"patient_name":     offer.PatientName,
+		"patient_phone":    offer.PatientPhone,
+		"therapist_name":   offer.TherapistName,
+		"scheduled_at":     offer.ScheduledAt.In(loc),
+		"session_date":     sessionDate,
+		"session_time":     sessionTime,
+		"offer_link":       offer.AcceptLink,
+		"offer_expires_at": offer.ExpiresAt.In(loc).Format("02/01/2006 15:04"),
+	}
+	if offer.ServiceName != nil {
+		data["service_name"] = *offer.ServiceName
+	}
+	if err := e.addOrganizationData(ctx, orgID, data); err != nil {
+		log.Printf("[Executor] Failed to add organization data to offer %s: %v", offer.ID, err)
+	}
+
+	if err := e.sendWhatsAppTemplate(ctx, orgID, action, decision, template, offer.PatientPhone, data); err != nil {
+		// Give the patient back their place, so a retry offers the slot again
+		if withdrawErr := e.slotOffers.WithdrawOffer(ctx, offer.ID); withdrawErr != nil {
+			log.Printf("[Executor] Failed to withdraw offer %s: %v", offer.ID, withdrawErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// executeSendChat posts the rendered 'message' of the config to the team's chat channels:
+// the chat webhook in 'webhook_id', or every active one of the organization
+func (e *Executor) executeSendChat(ctx context.Context, orgID uuid.UUID, action *models.WorkflowAction, entityType string, entityID uuid.UUID, entityData map[string]interface{}) error {
+	if e.chatSender == nil {
+		return errors.New("chat notifications are not configured")
+	}
+
+	config, err := parseActionConfig(action.ActionConfig)
+	if err != nil {
+		return fmt.Errorf("failed to parse action config: %w", err)
+	}
+
+	messageTemplate, _ := config["message"].(string)
+	if messageTemplate == "" {
+		return errors.New("message is required")
+	}
+
+	var webhookID *uuid.UUID
+	if idStr, _ := config["webhook_id"].(string); idStr != "" {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			return fmt.Errorf("invalid webhook_id: %w", err)
+		}
+		webhookID = &id
+	}
+
+	if entityData == nil {
+		entityData, err = e.getEntityData(ctx, orgID, entityType, entityID)
+		if err != nil {
+			return fmt.Errorf("failed to get entity data: %w", err)
+		}
+	}
+
+	message, err := e.templates.RenderTemplate(messageTemplate, entityData)
+	if err != nil {
+		return fmt.Errorf("failed to render message: %w", err)
+	}
+
+	log.Printf("[Executor] Posting chat message for %s/%s", entityType, entityID)
+	return e.chatSender.SendChat(ctx, orgID, webhookID, message)
+}
+
+// notifyUserRecipients resolves the users targeted by a notify_user action.
+// The config sets either 'user_id' (an active user of the organization), a 'role' (every active user with it), or
+// 'recipients' = 'internal_approvers' for the roles whose sign-off a budget is waiting on,
+// or 'task_assignee' for the person a task is assigned to.
+func (e *Executor) notifyUserRecipients(ctx context.Context, orgID uuid.UUID, config map[string]interface{}, entityType string, entityID uuid.UUID) ([]uuid.UUID, error) {
+	if userIDStr, _ := config["user_id"].(string); userIDStr != "" {
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			return nil, fmt.Errorf("notify_user action has an invalid 'user_id' in config")
+		}
+		// Imported workflows can carry any user_id, so only members of the organization are notified
+		var isMember bool
+		err = e.db.Pool.QueryRow(ctx, `
+			SELECT EXISTS(
+				SELECT 1 FROM users
+				WHERE id = $1 AND organization_id = $2 AND is_active = true AND deleted_at IS NULL
+			)
+		`, userID, orgID).Scan(&isMember)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check recipient: %w", err)
+		}
+		if !isMember {
+			return nil, fmt.Errorf("notify_user recipient %s is not an active user of the organization", userID)
+		}
+		return []uuid.UUID{userID}, nil
+	}
+
+	var rows pgx.Rows
+	var err error
+	if role, _ := config["role"].(string); role != "" {
+		rows, err = e.db.Pool.Query(ctx, `
+			SELECT id FROM users
+			WHERE organization_id = $1 AND role = $2 AND is_active = true AND deleted_at IS NULL
+		`, orgID, role)
+	} else if recipients, _ := config["recipients"].(string); recipients == "task_assignee" && entityType == "task" {
+		rows, err = e.db.Pool.Query(ctx, `
+			SELECT u.id FROM tasks t
+			JOIN users u ON u.id = t.assigned_to
+			WHERE t.id = $1 AND u.organization_id = $2 AND u.is_active = true AND u.deleted_at IS NULL
+		`, entityID, orgID)
+	} else if recipients == "internal_approvers" && entityType == "budget" {
+		rows, err = e.db.Pool.Query(ctx, `
+			SELECT DISTINCT u.id FROM users u
+			JOIN budget_internal_approvals a ON a.required_role = u.role
+			WHERE a.budget_id = $1 AND a.status = 'pending'
+			AND u.organization_id = $2 AND u.is_active = true AND u.deleted_at IS NULL
+		`, entityID, orgID)
+	} else {
+		return nil, fmt.Errorf("notify_user action requires 'user_id', 'role' or 'recipients' in config")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recipients: %w", err)
+	}
+	defer rows.Close()
+
+	var userIDs []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan recipient: %w", err)
+		}
+		userIDs = append(userIDs, id)
+	}
+	return userIDs, nil
+}
+
+// EntityData returns the data templates are rendered with for an entity, as used when its
+// actions run
+func (e *Executor) EntityData(ctx context.Context, orgID uuid.UUID, entityType string, entityID uuid.UUID) (map[string]interface{}, error) {
+	return e.getEntityData(ctx, orgID, entityType, entityID)
+}
+
 // getEntityData retrieves entity data for template rendering
 func (e *Executor) getEntityData(ctx context.Context, orgID uuid.UUID, entityType string, entityID uuid.UUID) (map[string]interface{}, error) {
 	data := make(map[string]interface{})
+	var err error
 
 	switch entityType {
 	case "session":
-		return e.getSessionData(ctx, orgID, entityID)
+		data, err = e.getSessionData(ctx, orgID, entityID)
 	case "budget":
-		return e.getBudgetData(ctx, orgID, entityID)
+		data, err = e.getBudgetData(ctx, orgID, entityID)
 	case "project":
-		return e.getProjectData(ctx, orgID, entityID)
+		data, err = e.getProjectData(ctx, orgID, entityID)
+	case "task":
+		data, err = e.getTaskData(ctx, orgID, entityID)
+	case "payment":
+		data, err = e.getPaymentData(ctx, orgID, entityID)
+	case "invoice":
+		data, err = e.getInvoiceData(ctx, orgID, entityID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := e.addOrganizationData(ctx, orgID, data); err != nil {
+		return nil, err
 	}
 
 	return data, nil
 }
 
+// addOrganizationData adds the organization's branding (name, email and logo) to entity data
+func (e *Executor) addOrganizationData(ctx context.Context, orgID uuid.UUID, data map[string]interface{}) error {
+	var name, email string
+	var logo *string
+	err := e.db.Pool.QueryRow(ctx, `
+		SELECT name, email, logo FROM organizations WHERE id = $1
+	`, orgID).Scan(&name, &email, &logo)
+	if err != nil {
+		return fmt.Errorf("failed to get organization data: %w", err)
+	}
+
+	data["organization_name"] = name
+	data["organization_email"] = email
+	if logo != nil && *logo != "" {
+		data["organization_logo_url"] = *logo
+	}
+
+	return nil
+}
+
+// brandEmailBody turns a rendered plain text email into HTML headed by the organization logo
+func brandEmailBody(body string, entityData map[string]interface{}) string {
+	var b strings.Builder
+	b.WriteString("<html><body>")
+	if logo, _ := entityData["organization_logo_url"].(string); logo != "" {
+		name, _ := entityData["organization_name"].(string)
+		fmt.Fprintf(&b, `<p><img src="%s" alt="%s" style="max-width:200px;max-height:80px"></p>`,
+			html.EscapeString(logo), html.EscapeString(name))
+	}
+	b.WriteString(strings.ReplaceAll(html.EscapeString(body), "\n", "<br>\n"))
+	b.WriteString("</body></html>")
+	return b.String()
+}
+
 // getSessionData retrieves session data with patient and therapist info
 func (e *Executor) getSessionData(ctx context.Context, orgID uuid.UUID, sessionID uuid.UUID) (map[string]interface{}, error) {
 	data := make(map[string]interface{})
@@ -301,29 +1200,37 @@ func (e *Executor) getSessionData(ctx context.Context, orgID uuid.UUID, sessionI
 			COALESCE(c.name, '') as patient_name,
 			c.phone as patient_phone,
 			c.email as patient_email,
-			COALESCE(u.name, '') as therapist_name
+			COALESCE(t.name, '') as therapist_name,
+			COALESCE(s.cancellation_fee_cents, 0),
+			COALESCE(s.cancellation_policy_note, '')
 		FROM sessions s
 		LEFT JOIN patients p ON p.id = s.patient_id
 		LEFT JOIN clients c ON c.id = p.client_id
-		LEFT JOIN users u ON u.id = s.therapist_id
+		LEFT JOIN therapists t ON t.id = s.therapist_id
 		WHERE s.id = $1 AND s.organization_id = $2
 	`, sessionID, orgID)
 
-	var scheduledAt interface{}
-	var sessionType, status, patientName, therapistName string
+	var scheduledAt time.Time
+	var sessionType, status, patientName, therapistName, cancellationNote string
 	var patientPhone, patientEmail *string
+	var cancellationFeeCents int
 
-	err := row.Scan(&scheduledAt, &sessionType, &status, &patientName, &patientPhone, &patientEmail, &therapistName)
+	err := row.Scan(&scheduledAt, &sessionType, &status, &patientName, &patientPhone, &patientEmail, &therapistName,
+		&cancellationFeeCents, &cancellationNote)
 	if err != nil {
 		return nil, err
 	}
 
+	loc := OrganizationLocation(ctx, e.db.Pool, orgID)
 	data["session_id"] = sessionID.String()
-	data["scheduled_at"] = scheduledAt
+	data["scheduled_at"] = scheduledAt.In(loc)
+	data["session_date"], data["session_time"] = SessionTimeVars(scheduledAt, loc)
 	data["session_type"] = sessionType
 	data["status"] = status
 	data["patient_name"] = patientName
 	data["therapist_name"] = therapistName
+	data["cancellation_fee"] = fmt.Sprintf("%.2f", float64(cancellationFeeCents)/100)
+	data["cancellation_policy"] = cancellationNote
 
 	if patientPhone != nil {
 		data["patient_phone"] = *patientPhone
@@ -347,7 +1254,8 @@ func (e *Executor) getBudgetData(ctx context.Context, orgID uuid.UUID, budgetID
 			w.title as worksheet_title,
 			COALESCE(c.name, '') as client_name,
 			c.email as client_email,
-			c.phone as client_phone
+			c.phone as client_phone,
+			b.portal_token
 		FROM budgets b
 		LEFT JOIN worksheets w ON w.id = b.worksheet_id
 		LEFT JOIN clients c ON c.id = w.client_id
@@ -356,9 +1264,9 @@ func (e *Executor) getBudgetData(ctx context.Context, orgID uuid.UUID, budgetID
 
 	var status, budgetNumber, worksheetTitle, clientName string
 	var total float64
-	var clientEmail, clientPhone *string
+	var clientEmail, clientPhone, portalToken *string
 
-	err := row.Scan(&status, &budgetNumber, &total, &worksheetTitle, &clientName, &clientEmail, &clientPhone)
+	err := row.Scan(&status, &budgetNumber, &total, &worksheetTitle, &clientName, &clientEmail, &clientPhone, &portalToken)
 	if err != nil {
 		return nil, err
 	}
@@ -376,10 +1284,118 @@ func (e *Executor) getBudgetData(ctx context.Context, orgID uuid.UUID, budgetID
 	if clientPhone != nil {
 		data["client_phone"] = *clientPhone
 	}
+	e.addBudgetLinks(data, portalToken)
 
 	return data, nil
 }
 
+// addBudgetLinks adds the client portal links, where the budget is viewed and approved
+func (e *Executor) addBudgetLinks(data map[string]interface{}, portalToken *string) {
+	if portalToken == nil || e.frontendURL == "" {
+		return
+	}
+	link := fmt.Sprintf("%s/budget-portal/%s", e.frontendURL, *portalToken)
+	data["budget_link"] = link
+	data["approval_link"] = link + "?action=approve"
+}
+
+// addPaymentLink adds the {{payment_link}} variable when a message uses it. Links are created on
+// demand so entities are only registered with the payment provider when a client is asked to pay.
+func (e *Executor) addPaymentLink(ctx context.Context, orgID uuid.UUID, entityType string, entityID uuid.UUID, data map[string]interface{}, templates ...string) {
+	if e.paymentLinks == nil {
+		return
+	}
+	if _, ok := data["payment_link"]; ok {
+		return
+	}
+	used := false
+	for _, t := range templates {
+		if strings.Contains(t, "payment_link") {
+			used = true
+			break
+		}
+	}
+	if !used {
+		return
+	}
+
+	link, err := e.paymentLinks.LinkFor(ctx, orgID, entityType, entityID)
+	if err != nil {
+		// The message still goes out, without the link
+		log.Printf("[Executor] Failed to create payment link for %s %s: %v", entityType, entityID, err)
+		return
+	}
+	if link != "" {
+		data["payment_link"] = link
+	}
+}
+
+// addRescheduleLink adds the {{reschedule_link}} variable when a message uses it. Each message gets
+// its own single-use link.
+func (e *Executor) addRescheduleLink(ctx context.Context, orgID uuid.UUID, entityType string, entityID uuid.UUID, data map[string]interface{}, templates ...string) {
+	if e.rescheduleLinks == nil {
+		return
+	}
+	if _, ok := data["reschedule_link"]; ok {
+		return
+	}
+	used := false
+	for _, t := range templates {
+		if strings.Contains(t, "reschedule_link") {
+			used = true
+			break
+		}
+	}
+	if !used {
+		return
+	}
+
+	link, err := e.rescheduleLinks.LinkFor(ctx, orgID, entityType, entityID)
+	if err != nil {
+		// The message still goes out, without the link
+		log.Printf("[Executor] Failed to create reschedule link for %s %s: %v", entityType, entityID, err)
+		return
+	}
+	if link != "" {
+		data["reschedule_link"] = link
+	}
+}
+
+// paymentReferenceVariables are the template variables filled by addPaymentReferences
+var paymentReferenceVariables = []string{"mb_entity", "mb_reference", "sepa_reference", "iban"}
+
+// addPaymentReferences adds the Multibanco and SEPA reference variables when a message uses them.
+// As with payment links, references are only generated when a client is asked to pay.
+func (e *Executor) addPaymentReferences(ctx context.Context, orgID uuid.UUID, entityType string, entityID uuid.UUID, data map[string]interface{}, templates ...string) {
+	if e.paymentRefs == nil {
+		return
+	}
+	if _, ok := data["mb_reference"]; ok {
+		return
+	}
+	used := false
+	for _, t := range templates {
+		for _, name := range paymentReferenceVariables {
+			if strings.Contains(t, name) {
+				used = true
+			}
+		}
+	}
+	if !used {
+		return
+	}
+
+	refs, err := e.paymentRefs.ReferencesFor(ctx, orgID, entityType, entityID)
+	if err != nil {
+		// The message still goes out, without the references
+		log.Printf("[Executor] Failed to generate payment references for %s %s: %v", entityType, entityID, err)
+		return
+	}
+	for name, value := range refs {
+		data[name] = value
+	}
+}
+
 // getProjectData retrieves project data
 func (e *Executor) getProjectData(ctx context.Context, orgID uuid.UUID, projectID uuid.UUID) (map[string]interface{}, error) {
 	data := make(map[string]interface{})
@@ -423,6 +1439,139 @@ func (e *Executor) getProjectData(ctx context.Context, orgID uuid.UUID, projectI
 	return data, nil
 }
 
+// getTaskData retrieves task data with its assignee, merged over the data of its project
+func (e *Executor) getTaskData(ctx context.Context, orgID uuid.UUID, taskID uuid.UUID) (map[string]interface{}, error) {
+	var projectID uuid.UUID
+	var title, status, priority string
+	var description, assigneeName, assigneeEmail, assigneePhone *string
+	var dueDate *time.Time
+
+	err := e.db.Pool.QueryRow(ctx, `
+		SELECT
+			t.project_id, t.title, t.description, t.status, t.priority, t.due_date,
+			u.first_name || ' ' || u.last_name, u.email, u.phone
+		FROM tasks t
+		JOIN projects p ON p.id = t.project_id
+		LEFT JOIN users u ON u.id = t.assigned_to
+		WHERE t.id = $1 AND p.organization_id = $2
+	`, taskID, orgID).Scan(
+		&projectID, &title, &description, &status, &priority, &dueDate,
+		&assigneeName, &assigneeEmail, &assigneePhone,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := e.getProjectData(ctx, orgID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	data["task_id"] = taskID.String()
+	data["task_title"] = title
+	data["task_status"] = status
+	data["task_priority"] = priority
+	if description != nil {
+		data["task_description"] = *description
+	}
+	if dueDate != nil {
+		data["task_due_date"] = dueDate.Format("02/01/2006")
+	}
+	if assigneeName != nil {
+		data["task_assignee_name"] = *assigneeName
+	}
+	if assigneeEmail != nil {
+		data["task_assignee_email"] = *assigneeEmail
+	}
+	if assigneePhone != nil {
+		data["task_assignee_phone"] = *assigneePhone
+	}
+
+	return data, nil
+}
+
+// getPaymentData retrieves payment data merged over the data of its project
+func (e *Executor) getPaymentData(ctx context.Context, orgID uuid.UUID, paymentID uuid.UUID) (map[string]interface{}, error) {
+	var projectID uuid.UUID
+	var amount decimal.Decimal
+	var status string
+	var dueDate time.Time
+	var reference *string
+
+	err := e.db.Pool.QueryRow(ctx, `
+		SELECT project_id, amount, status, due_date, reference
+		FROM payments
+		WHERE id = $1 AND organization_id = $2
+	`, paymentID, orgID).Scan(&projectID, &amount, &status, &dueDate, &reference)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := e.getProjectData(ctx, orgID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	data["payment_id"] = paymentID.String()
+	data["payment_amount"] = amount.StringFixed(2)
+	data["payment_status"] = status
+	data["payment_due_date"] = dueDate.Format("02/01/2006")
+	data["payment_days_overdue"] = int(time.Since(dueDate).Hours() / 24)
+	if reference != nil {
+		data["payment_reference"] = *reference
+	}
+
+	return data, nil
+}
+
+// getInvoiceData retrieves invoice data with the customer it is addressed to
+func (e *Executor) getInvoiceData(ctx context.Context, orgID uuid.UUID, invoiceID uuid.UUID) (map[string]interface{}, error) {
+	var documentType, status, customerName string
+	var invoiceNumber, customerEmail, clientPhone *string
+	var total decimal.Decimal
+	var issueDate, dueDate *time.Time
+
+	err := e.db.Pool.QueryRow(ctx, `
+		SELECT i.document_type, i.status, i.invoice_number, i.customer_name, i.customer_email,
+			c.phone, i.total, i.issue_date, i.due_date
+		FROM invoices i
+		LEFT JOIN clients c ON c.id = i.client_id
+		WHERE i.id = $1 AND i.organization_id = $2
+	`, invoiceID, orgID).Scan(&documentType, &status, &invoiceNumber, &customerName, &customerEmail,
+		&clientPhone, &total, &issueDate, &dueDate)
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string]interface{}{
+		"invoice_id":     invoiceID.String(),
+		"invoice_type":   "Fatura",
+		"invoice_status": status,
+		"invoice_total":  total.StringFixed(2),
+		"client_name":    customerName,
+	}
+	if documentType == string(models.InvoiceDocumentCreditNote) {
+		data["invoice_type"] = "Nota de crédito"
+	}
+	if invoiceNumber != nil {
+		data["invoice_number"] = *invoiceNumber
+	}
+	if customerEmail != nil {
+		data["client_email"] = *customerEmail
+	}
+	if clientPhone != nil {
+		data["client_phone"] = *clientPhone
+	}
+	if issueDate != nil {
+		data["invoice_issue_date"] = issueDate.Format("02/01/2006")
+	}
+	if dueDate != nil {
+		data["invoice_due_date"] = dueDate.Format("02/01/2006")
+	}
+
+	return data, nil
+}
+
 // parseActionConfig parses the action_config JSON
 func parseActionConfig(config []byte) (map[string]interface{}, error) {
 	if config == nil {
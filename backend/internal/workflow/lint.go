@@ -0,0 +1,189 @@
+package workflow
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/controlwise/backend/internal/models"
+)
+
+// WhatsApp message limits checked by the template linter
+const (
+	WhatsAppMaxMessageLength  = 1600 // free-form messages
+	WhatsAppMaxTemplateLength = 1024 // body of approved templates
+	WhatsAppMaxEmoji          = 10
+)
+
+var placeholderRe = regexp.MustCompile(`\{\{([^{}]*)\}\}`)
+var variableNameRe = regexp.MustCompile(`^\w+$`)
+var adjacentPlaceholdersRe = regexp.MustCompile(`\}\}\s*\{\{`)
+
+// LintTemplate checks a template's subject and body for undefined variables, unbalanced
+// placeholders and channel constraints. known holds the variables available to the template and
+// rendered is the body after rendering, used for the length checks.
+func LintTemplate(channel models.MessageChannel, subject *string, body, rendered string, known map[string]bool) []models.TemplateIssue {
+	issues := make([]models.TemplateIssue, 0)
+	if subject != nil {
+		issues = append(issues, lintPlaceholders("subject", *subject, known)...)
+		issues = append(issues, lintEncoding("subject", *subject)...)
+	}
+	issues = append(issues, lintPlaceholders("body", body, known)...)
+	issues = append(issues, lintEncoding("body", body)...)
+
+	switch channel {
+	case models.MessageChannelWhatsApp:
+		if subject != nil && strings.TrimSpace(*subject) != "" {
+			issues = append(issues, models.TemplateIssue{
+				Severity: models.TemplateIssueWarning, Code: "subject_ignored", Field: "subject",
+				Message: "WhatsApp messages have no subject, it will not be sent",
+			})
+		}
+		if n := utf8.RuneCountInString(rendered); n > WhatsAppMaxMessageLength {
+			issues = append(issues, models.TemplateIssue{
+				Severity: models.TemplateIssueError, Code: "message_too_long", Field: "body",
+				Message: fmt.Sprintf("rendered message has %d characters, WhatsApp allows %d", n, WhatsAppMaxMessageLength),
+			})
+		}
+		if n := utf8.RuneCountInString(body); n > WhatsAppMaxTemplateLength {
+			issues = append(issues, models.TemplateIssue{
+				Severity: models.TemplateIssueWarning, Code: "template_too_long", Field: "body",
+				Message: fmt.Sprintf("body has %d characters, approved WhatsApp templates allow %d", n, WhatsAppMaxTemplateLength),
+			})
+		}
+		if n := countEmoji(body); n > WhatsAppMaxEmoji {
+			issues = append(issues, models.TemplateIssue{
+				Severity: models.TemplateIssueWarning, Code: "too_many_emoji", Field: "body",
+				Message: fmt.Sprintf("body has %d emoji, approved WhatsApp templates allow %d", n, WhatsAppMaxEmoji),
+			})
+		}
+		for _, m := range placeholderRe.FindAllStringIndex(body, -1) {
+			if m[0] == 0 || m[1] == len(body) {
+				issues = append(issues, models.TemplateIssue{
+					Severity: models.TemplateIssueWarning, Code: "edge_variable", Field: "body",
+					Message: "approved WhatsApp templates cannot start or end with a variable",
+				})
+				break
+			}
+		}
+		if adjacentPlaceholdersRe.MatchString(body) {
+			issues = append(issues, models.TemplateIssue{
+				Severity: models.TemplateIssueWarning, Code: "adjacent_variables", Field: "body",
+				Message: "approved WhatsApp templates need text between variables",
+			})
+		}
+	case models.MessageChannelEmail:
+		if subject == nil || strings.TrimSpace(*subject) == "" {
+			issues = append(issues, models.TemplateIssue{
+				Severity: models.TemplateIssueWarning, Code: "missing_subject", Field: "subject",
+				Message: "email template has no subject, a generic one will be used",
+			})
+		}
+	}
+
+	return issues
+}
+
+// lintPlaceholders reports malformed, unbalanced and undefined {{variable}} placeholders
+func lintPlaceholders(field, text string, known map[string]bool) []models.TemplateIssue {
+	var issues []models.TemplateIssue
+
+	if open, close := strings.Count(text, "{{"), strings.Count(text, "}}"); open != close {
+		issues = append(issues, models.TemplateIssue{
+			Severity: models.TemplateIssueError, Code: "unbalanced_placeholder", Field: field,
+			Message: fmt.Sprintf("%d opening and %d closing braces, placeholders must be written as {{variable}}", open, close),
+		})
+	}
+
+	seen := make(map[string]bool)
+	for _, match := range placeholderRe.FindAllStringSubmatch(text, -1) {
+		name := match[1]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		if !variableNameRe.MatchString(name) {
+			issues = append(issues, models.TemplateIssue{
+				Severity: models.TemplateIssueError, Code: "invalid_placeholder", Field: field, Variable: name,
+				Message: fmt.Sprintf("placeholder %s is not a variable name and will not be replaced", match[0]),
+			})
+			continue
+		}
+		if !known[name] {
+			issues = append(issues, models.TemplateIssue{
+				Severity: models.TemplateIssueError, Code: "undefined_variable", Field: field, Variable: name,
+				Message: fmt.Sprintf("variable %s is not available for this entity", name),
+			})
+		}
+	}
+
+	return issues
+}
+
+// UnusedVariables reports the variables a template declares but never uses
+func UnusedVariables(declared []models.TemplateVariable, subject *string, body string) []models.TemplateIssue {
+	used := make(map[string]bool)
+	text := body
+	if subject != nil {
+		text += " " + *subject
+	}
+	for _, match := range placeholderRe.FindAllStringSubmatch(text, -1) {
+		used[strings.TrimSpace(match[1])] = true
+	}
+
+	issues := make([]models.TemplateIssue, 0)
+	for _, v := range declared {
+		if v.Name != "" && !used[v.Name] {
+			issues = append(issues, models.TemplateIssue{
+				Severity: models.TemplateIssueWarning, Code: "unused_variable", Field: "variables", Variable: v.Name,
+				Message: fmt.Sprintf("variable %s is declared but not used", v.Name),
+			})
+		}
+	}
+	return issues
+}
+
+// lintEncoding reports text damaged by a wrong encoding and characters that are invisible to
+// the reader, which usually come from copying text out of documents
+func lintEncoding(field, text string) []models.TemplateIssue {
+	var issues []models.TemplateIssue
+
+	if !utf8.ValidString(text) || strings.ContainsRune(text, utf8.RuneError) {
+		issues = append(issues, models.TemplateIssue{
+			Severity: models.TemplateIssueError, Code: "invalid_encoding", Field: field,
+			Message: "text has characters damaged by a wrong encoding (shown as �), retype them",
+		})
+	}
+
+	for _, r := range text {
+		invisible := r == '\u200b' || r == '\u200c' || r == '\u2060' || r == '\ufeff' ||
+			(r < 0x20 && r != '\n' && r != '\r' && r != '\t') || r == 0x7f
+		if invisible {
+			issues = append(issues, models.TemplateIssue{
+				Severity: models.TemplateIssueWarning, Code: "invisible_character", Field: field,
+				Message: fmt.Sprintf("text has an invisible character (U+%04X) that may break the message", r),
+			})
+			break
+		}
+	}
+
+	return issues
+}
+
+// countEmoji counts pictographic runes, ignoring modifiers and joiners
+func countEmoji(text string) int {
+	count := 0
+	for _, r := range text {
+		switch {
+		case r >= 0x1F300 && r <= 0x1FAFF, // symbols, pictographs, emoticons, transport
+			r >= 0x2600 && r <= 0x27BF,   // miscellaneous symbols and dingbats
+			r >= 0x1F1E6 && r <= 0x1F1FF: // regional indicators
+			if r < 0x1F3FB || r > 0x1F3FF { // skin tone modifiers
+				count++
+			}
+		}
+	}
+	return count
+}
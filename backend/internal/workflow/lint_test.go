@@ -0,0 +1,108 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/controlwise/backend/internal/models"
+)
+
+func issueCodes(issues []models.TemplateIssue) []string {
+	codes := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		codes = append(codes, issue.Code)
+	}
+	return codes
+}
+
+func TestLintTemplate(t *testing.T) {
+	known := map[string]bool{"patient_name": true, "session_date": true}
+	subject := "Lembrete {{patient_name}}"
+
+	tests := []struct {
+		name     string
+		channel  models.MessageChannel
+		subject  *string
+		body     string
+		rendered string
+		expected []string
+	}{
+		{
+			name:     "valid whatsapp template",
+			channel:  models.MessageChannelWhatsApp,
+			body:     "Olá {{patient_name}}, até {{session_date}}.",
+			rendered: "Olá João, até 15/01/2025.",
+			expected: []string{},
+		},
+		{
+			name:     "undefined variable",
+			channel:  models.MessageChannelWhatsApp,
+			body:     "Olá {{client_name}}!",
+			rendered: "Olá {{client_name}}!",
+			expected: []string{"undefined_variable"},
+		},
+		{
+			name:     "unbalanced and malformed placeholders",
+			channel:  models.MessageChannelWhatsApp,
+			body:     "Olá {{patient_name}, dia {{ session_date }}.",
+			rendered: "Olá {{patient_name}, dia {{ session_date }}.",
+			expected: []string{"unbalanced_placeholder", "invalid_placeholder"},
+		},
+		{
+			name:     "whatsapp subject, edge variable and emoji",
+			channel:  models.MessageChannelWhatsApp,
+			subject:  &subject,
+			body:     "{{patient_name}} " + strings.Repeat("🎉", 11) + " ok",
+			rendered: "João " + strings.Repeat("🎉", 11) + " ok",
+			expected: []string{"subject_ignored", "too_many_emoji", "edge_variable"},
+		},
+		{
+			name:     "whatsapp message too long",
+			channel:  models.MessageChannelWhatsApp,
+			body:     "Olá " + strings.Repeat("a", 1100) + ".",
+			rendered: "Olá " + strings.Repeat("a", 1700) + ".",
+			expected: []string{"message_too_long", "template_too_long"},
+		},
+		{
+			name:     "whatsapp adjacent variables and invisible character",
+			channel:  models.MessageChannelWhatsApp,
+			body:     "Olá\u200b {{patient_name}}{{session_date}} ok",
+			rendered: "Olá\u200b João15/01/2025 ok",
+			expected: []string{"invisible_character", "adjacent_variables"},
+		},
+		{
+			name:     "damaged encoding",
+			channel:  models.MessageChannelEmail,
+			subject:  &subject,
+			body:     "Sess\ufffdo confirmada",
+			rendered: "Sess\ufffdo confirmada",
+			expected: []string{"invalid_encoding"},
+		},
+		{
+			name:     "email without subject",
+			channel:  models.MessageChannelEmail,
+			body:     "Olá {{patient_name}}",
+			rendered: "Olá João",
+			expected: []string{"missing_subject"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			codes := issueCodes(LintTemplate(tt.channel, tt.subject, tt.body, tt.rendered, known))
+			if strings.Join(codes, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("LintTemplate() = %v, want %v", codes, tt.expected)
+			}
+		})
+	}
+}
+
+func TestUnusedVariables(t *testing.T) {
+	subject := "Lembrete {{patient_name}}"
+	declared := []models.TemplateVariable{{Name: "patient_name"}, {Name: "session_date"}, {Name: "amount"}}
+
+	issues := UnusedVariables(declared, &subject, "Até {{ session_date }}.")
+	if len(issues) != 1 || issues[0].Variable != "amount" || issues[0].Code != "unused_variable" {
+		t.Errorf("UnusedVariables() = %+v, want only amount reported", issues)
+	}
+}
@@ -0,0 +1,238 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/controlwise/backend/internal/config"
+	"github.com/controlwise/backend/internal/database"
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+)
+
+// Message providers, each with its own rate limit
+const (
+	ProviderTwilio = "twilio"
+	ProviderMeta   = "meta"
+	ProviderSMTP   = "smtp"
+)
+
+// reserveScript takes a token from every bucket in KEYS (ARGV holds rate and burst per key).
+// Buckets may go negative: the caller gets the wait until its reserved token is available,
+// so queued messages are spread out instead of all retrying at once. The rate is kept in the
+// bucket so its backlog can be read.
+var reserveScript = redis.NewScript(`
+local now_raw = redis.call('TIME')
+local now = tonumber(now_raw[1]) * 1000 + math.floor(tonumber(now_raw[2]) / 1000)
+local wait = 0
+for i, key in ipairs(KEYS) do
+	local rate = tonumber(ARGV[i * 2 - 1])
+	local burst = tonumber(ARGV[i * 2])
+	local bucket = redis.call('HMGET', key, 'tokens', 'ts')
+	local tokens = tonumber(bucket[1]) or burst
+	local ts = tonumber(bucket[2]) or now
+	tokens = math.min(burst, tokens + (now - ts) * rate / 1000) - 1
+	if tokens < 0 then
+		wait = math.max(wait, math.ceil(-tokens * 1000 / rate))
+	end
+	redis.call('HSET', key, 'tokens', tostring(tokens), 'ts', tostring(now), 'rate', tostring(rate))
+	redis.call('PEXPIRE', key, math.ceil((burst - tokens) * 1000 / rate) + 1000)
+end
+return wait
+`)
+
+// RateLimiter enforces messages per second per provider and per organization with
+// token buckets stored in Redis, so the limits are shared by every worker
+type RateLimiter struct {
+	redis *redis.Client
+	db    *database.DB
+	cfg   config.RateLimitConfig
+}
+
+// NewRateLimiter creates a new message rate limiter
+func NewRateLimiter(redis *redis.Client, db *database.DB, cfg config.RateLimitConfig) *RateLimiter {
+	return &RateLimiter{
+		redis: redis,
+		db:    db,
+		cfg:   cfg,
+	}
+}
+
+// ProviderFor returns the provider that delivers a channel's messages for an organization
+// sending WhatsApp messages through whatsappProvider
+func ProviderFor(channel models.MessageChannel, whatsappProvider models.WhatsAppProvider) string {
+	switch {
+	case channel == models.MessageChannelEmail:
+		return ProviderSMTP
+	case whatsappProvider == models.WhatsAppProviderMeta:
+		return ProviderMeta
+	default:
+		return ProviderTwilio
+	}
+}
+
+// organizationProvider returns the provider that delivers the organization's messages on the
+// channel, and the organization's messages per second override for the channel, if any
+func organizationProvider(ctx context.Context, db *database.DB, orgID uuid.UUID, channel models.MessageChannel) (string, *float64, error) {
+	column := "whatsapp_messages_per_second"
+	if channel == models.MessageChannelEmail {
+		column = "email_messages_per_second"
+	}
+
+	var whatsappProvider models.WhatsAppProvider
+	var override *float64
+	err := db.Pool.QueryRow(ctx, `
+		SELECT whatsapp_provider, `+column+`::float8 FROM notification_configs WHERE organization_id = $1
+	`, orgID).Scan(&whatsappProvider, &override)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return "", nil, fmt.Errorf("failed to get organization message provider: %w", err)
+	}
+	return ProviderFor(channel, whatsappProvider), override, nil
+}
+
+// Reserve takes a send slot for a message of the organization on the channel and returns
+// how long to wait before sending it. Zero means it can be sent now.
+func (l *RateLimiter) Reserve(ctx context.Context, orgID uuid.UUID, channel models.MessageChannel) (time.Duration, error) {
+	provider, providerRate, orgRate, err := l.rates(ctx, orgID, channel)
+	if err != nil {
+		return 0, err
+	}
+
+	var keys []string
+	var args []interface{}
+	if providerRate > 0 {
+		keys = append(keys, fmt.Sprintf("ratelimit:provider:%s", provider))
+		args = append(args, providerRate, math.Max(1, math.Ceil(providerRate)))
+	}
+	if orgRate > 0 {
+		keys = append(keys, fmt.Sprintf("ratelimit:org:%s:%s", orgID, provider))
+		args = append(args, orgRate, math.Max(1, math.Ceil(orgRate)))
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	waitMs, err := reserveScript.Run(ctx, l.redis, keys, args...).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to reserve rate limit token: %w", err)
+	}
+
+	return time.Duration(waitMs) * time.Millisecond, nil
+}
+
+// DispatchRate returns the messages per second the organization's sends can go out at, the
+// slower of its channels since a trigger may message on either. Zero means unlimited.
+func (l *RateLimiter) DispatchRate(ctx context.Context, orgID uuid.UUID) (float64, error) {
+	var slowest float64
+	for _, channel := range []models.MessageChannel{models.MessageChannelWhatsApp, models.MessageChannelEmail} {
+		_, providerRate, orgRate, err := l.rates(ctx, orgID, channel)
+		if err != nil {
+			return 0, err
+		}
+		for _, rate := range []float64{providerRate, orgRate} {
+			if rate > 0 && (slowest == 0 || rate < slowest) {
+				slowest = rate
+			}
+		}
+	}
+	return slowest, nil
+}
+
+// bucketBacklog returns how many messages hold a future send slot in a bucket last reserved
+// from at ts, and how long until the bucket has caught up with them (times in milliseconds)
+func bucketBacklog(tokens float64, ts, now int64, rate float64) (int, time.Duration) {
+	if rate <= 0 {
+		return 0, 0
+	}
+	tokens += float64(now-ts) * rate / 1000
+	if tokens >= 0 {
+		return 0, 0
+	}
+	return int(math.Ceil(-tokens)), time.Duration(-tokens / rate * float64(time.Second))
+}
+
+// Backlogs returns the provider and organization buckets with messages waiting for a send slot,
+// longest wait first
+func (l *RateLimiter) Backlogs(ctx context.Context) ([]*models.NotificationSendBacklog, error) {
+	now, err := l.redis.Time(ctx).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get redis time: %w", err)
+	}
+
+	backlogs := []*models.NotificationSendBacklog{}
+	iter := l.redis.Scan(ctx, 0, "ratelimit:*", 100).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		values, err := l.redis.HMGet(ctx, key, "tokens", "ts", "rate").Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read rate limit bucket: %w", err)
+		}
+		var tokens, rate float64
+		var ts int64
+		if len(values) != 3 || values[0] == nil || values[1] == nil || values[2] == nil {
+			continue // reserved before buckets kept their rate
+		}
+		fmt.Sscan(values[0].(string), &tokens)
+		fmt.Sscan(values[1].(string), &ts)
+		fmt.Sscan(values[2].(string), &rate)
+
+		waiting, drain := bucketBacklog(tokens, ts, now.UnixMilli(), rate)
+		if waiting == 0 {
+			continue
+		}
+		backlog := &models.NotificationSendBacklog{Waiting: waiting, DrainSeconds: drain.Seconds()}
+		// ratelimit:provider:<provider> or ratelimit:org:<org id>:<provider>
+		parts := strings.Split(key, ":")
+		switch {
+		case len(parts) == 3 && parts[1] == "provider":
+			backlog.Provider = parts[2]
+		case len(parts) == 4 && parts[1] == "org":
+			orgID, err := uuid.Parse(parts[2])
+			if err != nil {
+				continue
+			}
+			backlog.OrganizationID = &orgID
+			backlog.Provider = parts[3]
+		default:
+			continue
+		}
+		backlogs = append(backlogs, backlog)
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan rate limit buckets: %w", err)
+	}
+
+	sort.Slice(backlogs, func(i, j int) bool { return backlogs[i].DrainSeconds > backlogs[j].DrainSeconds })
+	return backlogs, nil
+}
+
+// rates returns the provider delivering the organization's messages on the channel, its rate
+// and the organization rate, using the organization's notification config override when set
+func (l *RateLimiter) rates(ctx context.Context, orgID uuid.UUID, channel models.MessageChannel) (string, float64, float64, error) {
+	provider, override, err := organizationProvider(ctx, l.db, orgID, channel)
+	if err != nil {
+		return "", 0, 0, err
+	}
+
+	var providerRate, orgRate float64
+	switch provider {
+	case ProviderSMTP:
+		providerRate, orgRate = l.cfg.SMTPPerSecond, l.cfg.OrgEmailPerSecond
+	case ProviderMeta:
+		providerRate, orgRate = l.cfg.MetaPerSecond, l.cfg.OrgWhatsAppPerSecond
+	default:
+		providerRate, orgRate = l.cfg.TwilioPerSecond, l.cfg.OrgWhatsAppPerSecond
+	}
+	if override != nil {
+		orgRate = *override
+	}
+
+	return provider, providerRate, orgRate, nil
+}
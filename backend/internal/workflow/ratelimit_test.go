@@ -0,0 +1,49 @@
+package workflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+)
+
+func TestBucketBacklog(t *testing.T) {
+	tests := []struct {
+		name        string
+		tokens      float64
+		elapsedMs   int64
+		rate        float64
+		wantWaiting int
+		wantDrain   time.Duration
+	}{
+		{"tokens left", 3, 0, 5, 0, 0},
+		{"behind by ten", -10, 0, 5, 10, 2 * time.Second},
+		{"caught up since", -10, 2000, 5, 0, 0},
+		{"partly caught up", -10, 1000, 5, 5, time.Second},
+		{"unlimited", -10, 0, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		waiting, drain := bucketBacklog(tt.tokens, 1000, 1000+tt.elapsedMs, tt.rate)
+		if waiting != tt.wantWaiting || drain != tt.wantDrain {
+			t.Errorf("%s: bucketBacklog = %d, %v, want %d, %v", tt.name, waiting, drain, tt.wantWaiting, tt.wantDrain)
+		}
+	}
+}
+
+func TestProviderFor(t *testing.T) {
+	tests := []struct {
+		channel          models.MessageChannel
+		whatsappProvider models.WhatsAppProvider
+		want             string
+	}{
+		{models.MessageChannelWhatsApp, models.WhatsAppProviderTwilio, ProviderTwilio},
+		{models.MessageChannelWhatsApp, models.WhatsAppProviderMeta, ProviderMeta},
+		{models.MessageChannelWhatsApp, "", ProviderTwilio},
+		{models.MessageChannelEmail, models.WhatsAppProviderMeta, ProviderSMTP},
+	}
+	for _, tt := range tests {
+		if got := ProviderFor(tt.channel, tt.whatsappProvider); got != tt.want {
+			t.Errorf("ProviderFor(%s, %q) = %s, want %s", tt.channel, tt.whatsappProvider, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+)
+
+// TypeRetryAction retries a failed action (matching jobs package)
+const TypeRetryAction = "workflow:retry_action"
+
+// maxRetryBackoff caps the delay between two attempts of an action
+const maxRetryBackoff = 24 * time.Hour
+
+// RetryActionPayload matches jobs.RetryActionPayload
+type RetryActionPayload struct {
+	OrganizationID   uuid.UUID  `json:"organization_id"`
+	WorkflowID       uuid.UUID  `json:"workflow_id"`
+	TriggerID        uuid.UUID  `json:"trigger_id"`
+	ActionID         uuid.UUID  `json:"action_id"`
+	EntityType       string     `json:"entity_type"`
+	EntityID         uuid.UUID  `json:"entity_id"`
+	BackoffSeconds   int        `json:"backoff_seconds"`
+	PreviousAttempts int        `json:"previous_attempts"`
+	DeadLetterID     *uuid.UUID `json:"dead_letter_id,omitempty"` // set when an admin requeued a dead letter
+}
+
+// RetryDelay returns how long to wait before the n-th asynq retry of a retry task: the action's
+// backoff, doubled after every attempt. ok is false for other tasks.
+func RetryDelay(n int, t *asynq.Task) (time.Duration, bool) {
+	if t.Type() != TypeRetryAction {
+		return 0, false
+	}
+	var payload RetryActionPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil || payload.BackoffSeconds <= 0 {
+		return 0, false
+	}
+	return retryBackoff(payload.BackoffSeconds, payload.PreviousAttempts+n+1), true
+}
+
+// retryBackoff is the delay after the given number of failed attempts
+func retryBackoff(backoffSeconds, failedAttempts int) time.Duration {
+	delay := time.Duration(backoffSeconds) * time.Second
+	for i := 1; i < failedAttempts && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryBackoff)
+}
+
+// handleActionFailure schedules the remaining attempts of an action that failed its first attempt,
+// or dead-letters it when its policy has no retries. It reports whether a retry was scheduled.
+func (e *Engine) handleActionFailure(ctx context.Context, orgID, workflowID, triggerID uuid.UUID, action *models.WorkflowAction, entityType string, entityID uuid.UUID, execErr error) bool {
+	payload := RetryActionPayload{
+		OrganizationID:   orgID,
+		WorkflowID:       workflowID,
+		TriggerID:        triggerID,
+		ActionID:         action.ID,
+		EntityType:       entityType,
+		EntityID:         entityID,
+		BackoffSeconds:   action.RetryBackoffSeconds,
+		PreviousAttempts: 1,
+	}
+
+	if action.RetryMaxAttempts > 1 && e.client != nil {
+		data, _ := json.Marshal(payload)
+		_, err := e.client.Enqueue(asynq.NewTask(TypeRetryAction, data),
+			asynq.Queue("default"),
+			asynq.ProcessIn(retryBackoff(action.RetryBackoffSeconds, 1)),
+			asynq.MaxRetry(action.RetryMaxAttempts-2))
+		if err == nil {
+			return true
+		}
+		log.Printf("[WorkflowEngine] Failed to enqueue retry of action %s: %v", action.ID, err)
+	}
+
+	if err := e.recordDeadLetter(ctx, payload, 1, execErr); err != nil {
+		log.Printf("[WorkflowEngine] Failed to record dead letter for action %s: %v", action.ID, err)
+	}
+	return false
+}
+
+// RetryAction runs a failed action again. attempt counts every attempt so far including this one;
+// when final is set no further retry follows and a failure is dead-lettered.
+func (e *Engine) RetryAction(ctx context.Context, payload RetryActionPayload, attempt int, final bool) error {
+	action, err := e.getAction(ctx, payload.ActionID, payload.OrganizationID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("[WorkflowEngine] Skipping retry of action %s: it no longer exists", payload.ActionID)
+			return nil
+		}
+		return fmt.Errorf("failed to get action: %w", err)
+	}
+	if !action.IsActive {
+		log.Printf("[WorkflowEngine] Skipping retry of action %s: it was deactivated", payload.ActionID)
+		if payload.DeadLetterID != nil {
+			return e.recordDeadLetter(ctx, payload, payload.PreviousAttempts, errors.New("action is deactivated"))
+		}
+		return nil
+	}
+
+	entityData, err := e.getEntityData(ctx, payload.OrganizationID, payload.EntityType, payload.EntityID)
+	if err != nil {
+		log.Printf("[WorkflowEngine] Failed to get entity data: %v", err)
+	}
+
+	decision, execErr := e.executor.ExecuteAction(ctx, payload.OrganizationID, action, payload.EntityType, payload.EntityID, entityData)
+	details := map[string]interface{}{
+		"action_id":   action.ID,
+		"action_type": action.ActionType,
+		"attempt":     attempt,
+	}
+	if decision != nil {
+		details["delivery"] = decision
+	}
+
+	if execErr != nil {
+		details["error"] = execErr.Error()
+		e.logEvent(ctx, payload.OrganizationID, payload.WorkflowID, payload.EntityType, payload.EntityID, models.EventTypeActionFailed, nil, nil, details)
+		if final {
+			if err := e.recordDeadLetter(ctx, payload, attempt, execErr); err != nil {
+				log.Printf("[WorkflowEngine] Failed to record dead letter for action %s: %v", action.ID, err)
+			}
+		}
+		return execErr
+	}
+
+	e.logEvent(ctx, payload.OrganizationID, payload.WorkflowID, payload.EntityType, payload.EntityID, models.EventTypeActionExecuted, nil, nil, details)
+	if payload.DeadLetterID != nil {
+		_, err := e.db.Pool.Exec(ctx, `
+			UPDATE workflow_dead_letters SET status = 'resolved', attempts = $1, resolved_at = NOW(), updated_at = NOW()
+			WHERE id = $2
+		`, attempt, *payload.DeadLetterID)
+		if err != nil {
+			log.Printf("[WorkflowEngine] Failed to resolve dead letter %s: %v", *payload.DeadLetterID, err)
+		}
+	}
+	return nil
+}
+
+// recordDeadLetter stores an action that exhausted its attempts, or marks a requeued one failed again
+func (e *Engine) recordDeadLetter(ctx context.Context, payload RetryActionPayload, attempts int, execErr error) error {
+	if payload.DeadLetterID != nil {
+		_, err := e.db.Pool.Exec(ctx, `
+			UPDATE workflow_dead_letters SET status = 'failed', attempts = $1, last_error = $2, updated_at = NOW()
+			WHERE id = $3
+		`, attempts, execErr.Error(), *payload.DeadLetterID)
+		return err
+	}
+
+	_, err := e.db.Pool.Exec(ctx, `
+		INSERT INTO workflow_dead_letters (organization_id, workflow_id, trigger_id, action_id, entity_type, entity_id, attempts, last_error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, payload.OrganizationID, payload.WorkflowID, payload.TriggerID, payload.ActionID,
+		payload.EntityType, payload.EntityID, attempts, execErr.Error())
+	if err == nil {
+		log.Printf("[WorkflowEngine] Action %s for %s/%s dead-lettered after %d attempts", payload.ActionID, payload.EntityType, payload.EntityID, attempts)
+		e.alertActionFailure(ctx, payload, attempts, execErr)
+	}
+	return err
+}
+
+// alertActionFailure tells the team channels that asked for it about an action that was dead-lettered
+func (e *Engine) alertActionFailure(ctx context.Context, payload RetryActionPayload, attempts int, execErr error) {
+	if e.executor.chatSender == nil {
+		return
+	}
+
+	var workflowName string
+	if err := e.db.Pool.QueryRow(ctx, `SELECT name FROM workflows WHERE id = $1`, payload.WorkflowID).Scan(&workflowName); err != nil {
+		workflowName = payload.WorkflowID.String()
+	}
+
+	text := fmt.Sprintf("Falhou uma ação do workflow \"%s\" (%s %s) após %d tentativas: %s",
+		workflowName, payload.EntityType, payload.EntityID, attempts, execErr.Error())
+	if err := e.executor.chatSender.SendAlert(ctx, payload.OrganizationID, text); err != nil {
+		log.Printf("[WorkflowEngine] Failed to send chat alert for action %s: %v", payload.ActionID, err)
+	}
+}
+
+// ProcessRequeuedDeadLetters enqueues the dead letters admins asked to run again, with their
+// action's retry policy. The dead letter id is the task id, so a task still queued is not duplicated.
+func (e *Engine) ProcessRequeuedDeadLetters(ctx context.Context) error {
+	if e.client == nil {
+		return nil
+	}
+
+	rows, err := e.db.Pool.Query(ctx, `
+		SELECT d.id, d.organization_id, d.workflow_id, d.trigger_id, d.action_id, d.entity_type, d.entity_id,
+		       d.attempts, a.retry_max_attempts, a.retry_backoff_seconds
+		FROM workflow_dead_letters d
+		JOIN workflow_actions a ON a.id = d.action_id
+		WHERE d.status = 'requeued'
+		ORDER BY d.requeued_at ASC
+		LIMIT 100
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to query requeued dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	type requeued struct {
+		payload     RetryActionPayload
+		maxAttempts int
+	}
+	var letters []requeued
+	for rows.Next() {
+		var r requeued
+		var id uuid.UUID
+		if err := rows.Scan(&id, &r.payload.OrganizationID, &r.payload.WorkflowID, &r.payload.TriggerID, &r.payload.ActionID,
+			&r.payload.EntityType, &r.payload.EntityID, &r.payload.PreviousAttempts, &r.maxAttempts, &r.payload.BackoffSeconds); err != nil {
+			return fmt.Errorf("failed to scan dead letter: %w", err)
+		}
+		r.payload.DeadLetterID = &id
+		letters = append(letters, r)
+	}
+	rows.Close()
+
+	for _, r := range letters {
+		data, _ := json.Marshal(r.payload)
+		_, err := e.client.Enqueue(asynq.NewTask(TypeRetryAction, data),
+			asynq.Queue("default"),
+			asynq.TaskID("dead-letter:"+r.payload.DeadLetterID.String()),
+			asynq.MaxRetry(r.maxAttempts-1))
+		if err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+			log.Printf("[WorkflowEngine] Failed to enqueue dead letter %s: %v", *r.payload.DeadLetterID, err)
+		}
+	}
+
+	return nil
+}
+
+// getAction loads an action of the organization
+func (e *Engine) getAction(ctx context.Context, actionID, orgID uuid.UUID) (*models.WorkflowAction, error) {
+	var a models.WorkflowAction
+	err := e.db.Pool.QueryRow(ctx, `
+		SELECT a.id, a.trigger_id, a.action_type, a.action_order, a.template_id, a.action_config, a.urgency,
+		       a.retry_max_attempts, a.retry_backoff_seconds, a.is_active, a.created_at
+		FROM workflow_actions a
+		JOIN workflow_triggers t ON t.id = a.trigger_id
+		JOIN workflows w ON w.id = t.workflow_id
+		WHERE a.id = $1 AND w.organization_id = $2
+	`, actionID, orgID).Scan(
+		&a.ID, &a.TriggerID, &a.ActionType, &a.ActionOrder, &a.TemplateID, &a.ActionConfig, &a.Urgency,
+		&a.RetryMaxAttempts, &a.RetryBackoffSeconds, &a.IsActive, &a.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
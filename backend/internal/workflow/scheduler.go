@@ -11,11 +11,13 @@ import (
 	"github.com/controlwise/backend/internal/models"
 	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
+	"github.com/robfig/cron/v3"
 )
 
 // Job type constants (matching jobs package)
 const (
 	TypeExecuteTrigger = "workflow:execute_trigger"
+	TypeSendMessage    = "workflow:send_message"
 )
 
 // ExecuteTriggerPayload matches jobs.ExecuteTriggerPayload
@@ -26,10 +28,23 @@ type ExecuteTriggerPayload struct {
 	EntityID       uuid.UUID `json:"entity_id"`
 }
 
+// SendMessagePayload matches jobs.SendMessagePayload
+type SendMessagePayload struct {
+	OrganizationID   uuid.UUID         `json:"organization_id"`
+	Channel          string            `json:"channel"`
+	To               string            `json:"to"`
+	Subject          string            `json:"subject,omitempty"`
+	Body             string            `json:"body"`
+	Critical         bool              `json:"critical,omitempty"`
+	ContentSID       string            `json:"content_sid,omitempty"` // approved WhatsApp template sent instead of Body
+	ContentVariables map[string]string `json:"content_variables,omitempty"`
+}
+
 // Scheduler handles scheduling of workflow jobs
 type Scheduler struct {
-	db     *database.DB
-	client *asynq.Client
+	db      *database.DB
+	client  *asynq.Client
+	limiter *RateLimiter
 }
 
 // NewScheduler creates a new scheduler
@@ -40,6 +55,12 @@ func NewScheduler(db *database.DB, client *asynq.Client) *Scheduler {
 	}
 }
 
+// SetRateLimiter sets the limiter bulk runs are paced by, so large runs are spread over time
+// instead of reserving thousands of send slots at once
+func (s *Scheduler) SetRateLimiter(limiter *RateLimiter) {
+	s.limiter = limiter
+}
+
 // ScheduleTimeTrigger schedules a time-based trigger for later execution
 func (s *Scheduler) ScheduleTimeTrigger(ctx context.Context, orgID uuid.UUID, trigger *models.WorkflowTrigger, entityType string, entityID uuid.UUID, entityData map[string]interface{}) error {
 	if trigger.TimeOffsetMinutes == nil {
@@ -79,13 +100,11 @@ func (s *Scheduler) ScheduleTimeTrigger(ctx context.Context, orgID uuid.UUID, tr
 	}
 
 	// Calculate scheduled time
-	offset := time.Duration(*trigger.TimeOffsetMinutes) * time.Minute
-	var scheduledFor time.Time
+	offset := *trigger.TimeOffsetMinutes
 	if trigger.TriggerType == models.TriggerTypeTimeBefore {
-		scheduledFor = baseTime.Add(-offset)
-	} else {
-		scheduledFor = baseTime.Add(offset)
+		offset = -offset
 	}
+	scheduledFor := OffsetTime(baseTime, offset, OrganizationLocation(ctx, s.db.Pool, orgID))
 
 	// Don't schedule in the past
 	if scheduledFor.Before(time.Now()) {
@@ -107,18 +126,155 @@ func (s *Scheduler) ScheduleTimeTrigger(ctx context.Context, orgID uuid.UUID, tr
 	return nil
 }
 
-// ScheduleRecurringTrigger sets up a recurring trigger (placeholder - actual cron handling is done by Asynq scheduler)
+// ScheduleRecurringTrigger validates a recurring trigger when its state is entered.
+// Occurrences are fired by ProcessRecurringTriggers for every entity in the state.
 func (s *Scheduler) ScheduleRecurringTrigger(ctx context.Context, orgID uuid.UUID, trigger *models.WorkflowTrigger, entityType string, entityID uuid.UUID) error {
 	if trigger.RecurringCron == nil {
 		return fmt.Errorf("recurring trigger requires cron expression")
 	}
 
+	if _, err := cron.ParseStandard(*trigger.RecurringCron); err != nil {
+		return fmt.Errorf("invalid cron expression %q: %w", *trigger.RecurringCron, err)
+	}
+
 	log.Printf("[Scheduler] Recurring trigger %s registered with cron: %s", trigger.ID, *trigger.RecurringCron)
 	// Recurring triggers are handled by the CheckTimeTriggers job which runs every minute
 	// and checks for any due recurring triggers
 	return nil
 }
 
+// recurringEntityQueries select the entities of an organization currently in a state
+var recurringEntityQueries = map[string]string{
+	"session": `SELECT id FROM sessions WHERE organization_id = $1 AND status = $2 AND deleted_at IS NULL`,
+	"budget": `
+		SELECT id FROM budgets
+		WHERE organization_id = $1 AND deleted_at IS NULL
+		AND CASE WHEN status = 'sent' AND valid_until < CURRENT_DATE THEN 'expired' ELSE status END = $2
+	`,
+	"project": `SELECT id FROM projects WHERE organization_id = $1 AND status = $2 AND deleted_at IS NULL`,
+	"invoice": `SELECT id FROM invoices WHERE organization_id = $1 AND status = $2 AND deleted_at IS NULL`,
+}
+
+// ProcessRecurringTriggers starts a bulk run of each active recurring trigger whose occurrence is
+// due, over every entity currently in the trigger's state. Only the latest missed occurrence runs, so a worker
+// outage does not replay a backlog. Occurrences excluded by the trigger's skip rule (weekends,
+// organization holidays) are marked as evaluated without running.
+// This is called by the CheckTimeTriggers periodic job
+func (s *Scheduler) ProcessRecurringTriggers(ctx context.Context) error {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT t.id, t.recurring_cron, t.recurring_skip, t.recurring_last_run_at, t.created_at,
+		       w.organization_id, w.entity_type, st.name
+		FROM workflow_triggers t
+		JOIN workflows w ON w.id = t.workflow_id
+		JOIN workflow_states st ON st.id = t.state_id
+		WHERE t.trigger_type = 'recurring' AND t.is_active = true AND w.is_active = true
+		AND t.recurring_cron IS NOT NULL
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to query recurring triggers: %w", err)
+	}
+
+	type recurringTrigger struct {
+		id, orgID             uuid.UUID
+		cronExpr              string
+		skip                  models.RecurringSkipRule
+		lastRunAt             *time.Time
+		createdAt             time.Time
+		entityType, stateName string
+	}
+	var triggers []recurringTrigger
+	for rows.Next() {
+		var t recurringTrigger
+		if err := rows.Scan(&t.id, &t.cronExpr, &t.skip, &t.lastRunAt, &t.createdAt, &t.orgID, &t.entityType, &t.stateName); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan recurring trigger: %w", err)
+		}
+		triggers = append(triggers, t)
+	}
+	rows.Close()
+
+	now := time.Now()
+	for _, t := range triggers {
+		schedule, err := cron.ParseStandard(t.cronExpr)
+		if err != nil {
+			log.Printf("[Scheduler] Recurring trigger %s has an invalid cron %q: %v", t.id, t.cronExpr, err)
+			continue
+		}
+
+		// Cron fields are read on the organization's wall clock
+		from := t.createdAt
+		if t.lastRunAt != nil {
+			from = *t.lastRunAt
+		}
+		from = from.In(OrganizationLocation(ctx, s.db.Pool, t.orgID))
+		occurrence := schedule.Next(from)
+		if occurrence.IsZero() || occurrence.After(now) {
+			continue
+		}
+		for next := schedule.Next(occurrence); !next.IsZero() && !next.After(now); next = schedule.Next(occurrence) {
+			occurrence = next
+		}
+
+		// Claim the occurrence so concurrent workers don't fire it twice
+		result, err := s.db.Pool.Exec(ctx, `
+			UPDATE workflow_triggers SET recurring_last_run_at = $1
+			WHERE id = $2 AND recurring_last_run_at IS NOT DISTINCT FROM $3
+		`, occurrence, t.id, t.lastRunAt)
+		if err != nil {
+			log.Printf("[Scheduler] Failed to claim recurring trigger %s: %v", t.id, err)
+			continue
+		}
+		if result.RowsAffected() == 0 {
+			continue
+		}
+
+		reason, err := s.recurringSkipReason(ctx, t.orgID, t.skip, occurrence)
+		if err != nil {
+			log.Printf("[Scheduler] Failed to evaluate skip rule of recurring trigger %s: %v", t.id, err)
+			continue
+		}
+		if reason != "" {
+			log.Printf("[Scheduler] Skipping recurring trigger %s at %v (%s)", t.id, occurrence, reason)
+			continue
+		}
+
+		query, ok := recurringEntityQueries[t.entityType]
+		if !ok {
+			continue
+		}
+		// Each occurrence becomes a bulk run fanned out into one task per entity
+		if _, err := s.StartBulkRun(ctx, t.orgID, t.id, "recurring", t.entityType, query, t.stateName); err != nil {
+			log.Printf("[Scheduler] Failed to start recurring trigger %s: %v", t.id, err)
+		}
+	}
+
+	return nil
+}
+
+// recurringSkipReason returns why a recurring occurrence is skipped by the rule, or an empty string
+func (s *Scheduler) recurringSkipReason(ctx context.Context, orgID uuid.UUID, rule models.RecurringSkipRule, at time.Time) (string, error) {
+	if rule == models.RecurringSkipWeekends || rule == models.RecurringSkipBusinessDays {
+		if at.Weekday() == time.Saturday || at.Weekday() == time.Sunday {
+			return "weekend", nil
+		}
+	}
+
+	if rule == models.RecurringSkipHolidays || rule == models.RecurringSkipBusinessDays {
+		var name string
+		err := s.db.Pool.QueryRow(ctx, `
+			SELECT COALESCE((SELECT name FROM organization_holidays WHERE organization_id = $1 AND date = $2::date), '')
+		`, orgID, at.Format("2006-01-02")).Scan(&name)
+		if err != nil {
+			return "", fmt.Errorf("failed to check holidays: %w", err)
+		}
+		if name != "" {
+			return "holiday: " + name, nil
+		}
+	}
+
+	return "", nil
+}
+
 // CancelPendingJobs cancels all pending scheduled jobs for an entity
 func (s *Scheduler) CancelPendingJobs(ctx context.Context, entityType string, entityID uuid.UUID) error {
 	result, err := s.db.Pool.Exec(ctx, `
@@ -26,12 +26,12 @@ func NewTemplateRenderer(db *database.DB) *TemplateRenderer {
 func (r *TemplateRenderer) GetTemplate(ctx context.Context, id, orgID uuid.UUID) (*models.MessageTemplate, error) {
 	var t models.MessageTemplate
 	err := r.db.Pool.QueryRow(ctx, `
-		SELECT id, organization_id, name, channel, subject, body, variables, is_active, created_at, updated_at
+		SELECT id, organization_id, name, channel, subject, body, variables, whatsapp_content_sid, is_active, created_at, updated_at
 		FROM message_templates
 		WHERE id = $1 AND organization_id = $2
 	`, id, orgID).Scan(
 		&t.ID, &t.OrganizationID, &t.Name, &t.Channel, &t.Subject,
-		&t.Body, &t.Variables, &t.IsActive, &t.CreatedAt, &t.UpdatedAt,
+		&t.Body, &t.Variables, &t.WhatsAppContentSID, &t.IsActive, &t.CreatedAt, &t.UpdatedAt,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -105,6 +105,16 @@ func getSampleData(entityType string) map[string]interface{} {
 			"session_time":    "14:30",
 			"session_type":    "Consulta Regular",
 			"amount":          "50.00",
+			"cancellation_fee": "25.00",
+			"cancellation_policy": "Cancelamento com menos de 24h de antecedência: taxa de 50% (25.00€).",
+			"payment_link":    "https://checkout.stripe.com/c/pay/cs_test_123",
+			"reschedule_link": "https://app.controlwise.pt/reschedule/abc123",
+			"offer_link":      "https://app.controlwise.pt/waiting-list/offers/abc123",
+			"offer_expires_at": "15/01/2025 12:30",
+			"mb_entity":       "11604",
+			"mb_reference":    "001 000 119",
+			"sepa_reference":  "RF740000000001",
+			"iban":            "PT50000201231234567890154",
 			"organization_name": "Clínica Exemplo",
 		}
 	case "budget":
@@ -116,6 +126,7 @@ func getSampleData(entityType string) map[string]interface{} {
 			"budget_total":  "15000.00",
 			"budget_link":   "https://example.com/budgets/123",
 			"approval_link": "https://example.com/budgets/123/approve",
+			"payment_link":  "https://checkout.stripe.com/c/pay/cs_test_123",
 			"organization_name": "Construções ABC",
 		}
 	case "project":
@@ -127,6 +138,17 @@ func getSampleData(entityType string) map[string]interface{} {
 			"project_status": "Em Curso",
 			"organization_name": "Construções ABC",
 		}
+	case "invoice":
+		return map[string]interface{}{
+			"client_name":    "Ana Ferreira",
+			"client_email":   "ana.ferreira@email.com",
+			"client_phone":   "+351934567890",
+			"invoice_number": "FT 2025/12",
+			"invoice_type":   "Fatura",
+			"invoice_total":  "1230.00",
+			"invoice_due_date": "14/02/2025",
+			"organization_name": "Construções ABC",
+		}
 	default:
 		return map[string]interface{}{
 			"name":  "Cliente Exemplo",
@@ -149,6 +171,16 @@ func GetAvailableVariables(entityType string) []models.TemplateVariable {
 			{Name: "session_time", Description: "Hora da sessão (HH:MM)"},
 			{Name: "session_type", Description: "Tipo de sessão"},
 			{Name: "amount", Description: "Valor da sessão"},
+			{Name: "cancellation_fee", Description: "Taxa de cancelamento"},
+			{Name: "cancellation_policy", Description: "Resultado da política de cancelamento"},
+			{Name: "payment_link", Description: "Link para pagar a sessão online"},
+			{Name: "reschedule_link", Description: "Link para o paciente remarcar a sessão"},
+			{Name: "offer_link", Description: "Link para aceitar a vaga oferecida da lista de espera"},
+			{Name: "offer_expires_at", Description: "Data e hora até quando a vaga oferecida é válida"},
+			{Name: "mb_entity", Description: "Entidade Multibanco para pagar a sessão"},
+			{Name: "mb_reference", Description: "Referência Multibanco para pagar a sessão"},
+			{Name: "sepa_reference", Description: "Referência RF para pagar a sessão por transferência"},
+			{Name: "iban", Description: "IBAN para transferências"},
 			{Name: "organization_name", Description: "Nome da organização"},
 		}
 	case "budget":
@@ -160,6 +192,7 @@ func GetAvailableVariables(entityType string) []models.TemplateVariable {
 			{Name: "budget_total", Description: "Valor total do orçamento"},
 			{Name: "budget_link", Description: "Link para visualizar o orçamento"},
 			{Name: "approval_link", Description: "Link para aprovar o orçamento"},
+			{Name: "payment_link", Description: "Link para pagar o orçamento aprovado online"},
 			{Name: "organization_name", Description: "Nome da organização"},
 		}
 	case "project":
@@ -171,6 +204,19 @@ func GetAvailableVariables(entityType string) []models.TemplateVariable {
 			{Name: "project_status", Description: "Estado do projeto"},
 			{Name: "organization_name", Description: "Nome da organização"},
 		}
+	case "invoice":
+		return []models.TemplateVariable{
+			{Name: "client_name", Description: "Nome do cliente"},
+			{Name: "client_email", Description: "Email do cliente"},
+			{Name: "client_phone", Description: "Telefone do cliente"},
+			{Name: "invoice_number", Description: "Número da fatura"},
+			{Name: "invoice_type", Description: "Tipo de documento (fatura ou nota de crédito)"},
+			{Name: "invoice_status", Description: "Estado da fatura"},
+			{Name: "invoice_total", Description: "Valor total da fatura"},
+			{Name: "invoice_issue_date", Description: "Data de emissão (DD/MM/AAAA)"},
+			{Name: "invoice_due_date", Description: "Data de vencimento (DD/MM/AAAA)"},
+			{Name: "organization_name", Description: "Nome da organização"},
+		}
 	default:
 		return []models.TemplateVariable{}
 	}
@@ -0,0 +1,49 @@
+package workflow
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/controlwise/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// RowQuerier runs single-row queries, on the pool or in a transaction
+type RowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// OrganizationLocation returns the timezone an organization's dates and times are shown and
+// counted in, falling back to the default timezone
+func OrganizationLocation(ctx context.Context, q RowQuerier, orgID uuid.UUID) *time.Location {
+	name := models.DefaultTimezone
+	err := q.QueryRow(ctx, `SELECT timezone FROM organizations WHERE id = $1`, orgID).Scan(&name)
+	if err != nil {
+		log.Printf("[Workflow] Failed to get timezone of organization %s, using %s: %v", orgID, models.DefaultTimezone, err)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("[Workflow] Organization %s has an invalid timezone %q, using %s", orgID, name, models.DefaultTimezone)
+		if loc, err = time.LoadLocation(models.DefaultTimezone); err != nil {
+			loc = time.UTC
+		}
+	}
+	return loc
+}
+
+// OffsetTime moves base by a trigger offset in minutes, back when negative. Whole days are counted
+// as calendar days in loc, so a reminder one day before a 10:00 session goes out at 10:00 across a
+// daylight saving change.
+func OffsetTime(base time.Time, minutes int, loc *time.Location) time.Time {
+	days, rest := minutes/(24*60), minutes%(24*60)
+	return base.In(loc).AddDate(0, 0, days).Add(time.Duration(rest) * time.Minute)
+}
+
+// SessionTimeVars returns the {{session_date}} and {{session_time}} template variables of a
+// session, in the organization's timezone
+func SessionTimeVars(scheduledAt time.Time, loc *time.Location) (date, clock string) {
+	local := scheduledAt.In(loc)
+	return local.Format("02/01/2006"), local.Format("15:04")
+}
@@ -0,0 +1,42 @@
+package workflow
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOffsetTime(t *testing.T) {
+	lisbon, err := time.LoadLocation("Europe/Lisbon")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+	// Clocks go forward at 01:00 on 29 March 2026 in Lisbon
+	session := time.Date(2026, 3, 29, 10, 0, 0, 0, lisbon)
+
+	tests := []struct {
+		name    string
+		minutes int
+		want    time.Time
+	}{
+		{"day before keeps the wall clock", -24 * 60, time.Date(2026, 3, 28, 10, 0, 0, 0, lisbon)},
+		{"hours are elapsed time", -2 * 60, time.Date(2026, 3, 29, 8, 0, 0, 0, lisbon)},
+		{"day and a half before", -36 * 60, time.Date(2026, 3, 27, 22, 0, 0, 0, lisbon)},
+		{"day after", 24 * 60, time.Date(2026, 3, 30, 10, 0, 0, 0, lisbon)},
+	}
+	for _, tt := range tests {
+		if got := OffsetTime(session, tt.minutes, lisbon); !got.Equal(tt.want) {
+			t.Errorf("%s: OffsetTime(%d) = %v, want %v", tt.name, tt.minutes, got, tt.want)
+		}
+	}
+}
+
+func TestSessionTimeVars(t *testing.T) {
+	lisbon, err := time.LoadLocation("Europe/Lisbon")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+	date, clock := SessionTimeVars(time.Date(2026, 7, 1, 23, 30, 0, 0, time.UTC), lisbon)
+	if date != "02/07/2026" || clock != "00:30" {
+		t.Errorf("SessionTimeVars = %s %s, want 02/07/2026 00:30", date, clock)
+	}
+}
@@ -0,0 +1,73 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+)
+
+// ProcessSlotOfferFollowUps passes the slots of waiting list offers that expired or were declined
+// to the next suitable patient, by running the offer_slot action that made them again. The patient
+// of an expired offer goes back to waiting.
+func (e *Engine) ProcessSlotOfferFollowUps(ctx context.Context) error {
+	rows, err := e.db.Pool.Query(ctx, `
+		WITH due AS (
+			UPDATE waiting_list_offers
+			SET status = CASE WHEN status = 'pending' THEN 'expired' ELSE status END,
+				responded_at = COALESCE(responded_at, NOW()),
+				followed_up = true
+			WHERE id IN (
+				SELECT id FROM waiting_list_offers
+				WHERE followed_up = false
+				AND (status = 'declined' OR (status = 'pending' AND expires_at <= NOW()))
+				ORDER BY expires_at
+				LIMIT 100
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING organization_id, entry_id, source_session_id, action_id, status
+		), waiting AS (
+			UPDATE waiting_list_entries e SET status = 'waiting'
+			FROM due
+			WHERE e.id = due.entry_id AND due.status = 'expired' AND e.status = 'offered'
+		)
+		SELECT organization_id, source_session_id, action_id FROM due WHERE action_id IS NOT NULL
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to follow up slot offers: %w", err)
+	}
+
+	type followUp struct {
+		orgID, sessionID, actionID uuid.UUID
+	}
+	var followUps []followUp
+	for rows.Next() {
+		var f followUp
+		if err := rows.Scan(&f.orgID, &f.sessionID, &f.actionID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan slot offer: %w", err)
+		}
+		followUps = append(followUps, f)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to follow up slot offers: %w", err)
+	}
+
+	for _, f := range followUps {
+		action, err := e.getAction(ctx, f.actionID, f.orgID)
+		if err != nil {
+			log.Printf("[WorkflowEngine] Failed to get offer_slot action %s: %v", f.actionID, err)
+			continue
+		}
+		if !action.IsActive {
+			continue
+		}
+		if _, err := e.executor.ExecuteAction(ctx, f.orgID, action, "session", f.sessionID, nil); err != nil {
+			log.Printf("[WorkflowEngine] Failed to offer the slot of session %s to the next patient: %v", f.sessionID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,11 @@
+-- Reverse out-of-office migration
+
+DROP TRIGGER IF EXISTS update_user_out_of_office_updated_at ON user_out_of_office;
+
+DROP INDEX IF EXISTS idx_delegation_audit_entity;
+DROP INDEX IF EXISTS idx_delegation_audit_org;
+DROP INDEX IF EXISTS idx_out_of_office_org;
+DROP INDEX IF EXISTS idx_out_of_office_user;
+
+DROP TABLE IF EXISTS delegation_audit_log;
+DROP TABLE IF EXISTS user_out_of_office;
@@ -0,0 +1,43 @@
+-- Out-of-office and delegation
+-- Lets users hand over approvals, task assignments and escalations to a delegate during an absence
+
+CREATE TABLE user_out_of_office (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    delegate_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    reason TEXT,
+    delegate_approvals BOOLEAN DEFAULT true,
+    delegate_tasks BOOLEAN DEFAULT true,
+    delegate_escalations BOOLEAN DEFAULT true,
+    is_active BOOLEAN DEFAULT true,
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    CHECK (ends_at > starts_at),
+    CHECK (user_id <> delegate_user_id)
+);
+
+-- Audit trail of every item rerouted to a delegate
+CREATE TABLE delegation_audit_log (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    out_of_office_id UUID REFERENCES user_out_of_office(id) ON DELETE SET NULL,
+    original_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    delegate_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    delegation_type VARCHAR(30) NOT NULL, -- 'approval', 'task', 'escalation'
+    entity_type VARCHAR(50),
+    entity_id UUID,
+    details JSONB,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_out_of_office_user ON user_out_of_office(user_id, starts_at, ends_at) WHERE is_active = true;
+CREATE INDEX idx_out_of_office_org ON user_out_of_office(organization_id);
+CREATE INDEX idx_delegation_audit_org ON delegation_audit_log(organization_id, created_at DESC);
+CREATE INDEX idx_delegation_audit_entity ON delegation_audit_log(entity_type, entity_id);
+
+CREATE TRIGGER update_user_out_of_office_updated_at BEFORE UPDATE ON user_out_of_office
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
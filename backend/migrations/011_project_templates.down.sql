@@ -0,0 +1,21 @@
+-- Reverse project templates migration
+
+DROP TRIGGER IF EXISTS update_project_templates_updated_at ON project_templates;
+
+DROP INDEX IF EXISTS idx_tasks_milestone_id;
+DROP INDEX IF EXISTS idx_project_documents_project;
+DROP INDEX IF EXISTS idx_project_milestones_project;
+DROP INDEX IF EXISTS idx_project_template_documents_template;
+DROP INDEX IF EXISTS idx_project_template_tasks_template;
+DROP INDEX IF EXISTS idx_project_template_phases_template;
+DROP INDEX IF EXISTS idx_project_templates_org;
+
+ALTER TABLE projects DROP COLUMN IF EXISTS template_id;
+ALTER TABLE tasks DROP COLUMN IF EXISTS milestone_id;
+
+DROP TABLE IF EXISTS project_documents;
+DROP TABLE IF EXISTS project_milestones;
+DROP TABLE IF EXISTS project_template_documents;
+DROP TABLE IF EXISTS project_template_tasks;
+DROP TABLE IF EXISTS project_template_phases;
+DROP TABLE IF EXISTS project_templates;
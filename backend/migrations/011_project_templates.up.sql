@@ -0,0 +1,87 @@
+-- Project templates
+-- Reusable project structures (phases, tasks, required documents) instantiated from an approved budget
+
+CREATE TABLE project_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    category VARCHAR(50),
+    is_active BOOLEAN DEFAULT true,
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE(organization_id, name)
+);
+
+CREATE TABLE project_template_phases (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    template_id UUID NOT NULL REFERENCES project_templates(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    position INT NOT NULL,
+    start_offset_days INT NOT NULL DEFAULT 0, -- relative to project start
+    duration_days INT NOT NULL DEFAULT 1,
+    UNIQUE(template_id, position)
+);
+
+CREATE TABLE project_template_tasks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    template_id UUID NOT NULL REFERENCES project_templates(id) ON DELETE CASCADE,
+    phase_id UUID REFERENCES project_template_phases(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    priority VARCHAR(50) NOT NULL DEFAULT 'medium' CHECK (priority IN ('low', 'medium', 'high', 'urgent')),
+    position INT NOT NULL,
+    start_offset_days INT NOT NULL DEFAULT 0, -- relative to phase start (or project start without a phase)
+    duration_days INT NOT NULL DEFAULT 1
+);
+
+CREATE TABLE project_template_documents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    template_id UUID NOT NULL REFERENCES project_templates(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    is_required BOOLEAN DEFAULT true,
+    position INT NOT NULL
+);
+
+-- Milestones of an instantiated project (one per template phase)
+CREATE TABLE project_milestones (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    position INT NOT NULL,
+    start_date DATE NOT NULL,
+    due_date DATE NOT NULL,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Documents a project must collect
+CREATE TABLE project_documents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    is_required BOOLEAN DEFAULT true,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- 'pending', 'received'
+    file_url TEXT,
+    received_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+ALTER TABLE tasks ADD COLUMN milestone_id UUID REFERENCES project_milestones(id) ON DELETE SET NULL;
+ALTER TABLE projects ADD COLUMN template_id UUID REFERENCES project_templates(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_project_templates_org ON project_templates(organization_id);
+CREATE INDEX idx_project_template_phases_template ON project_template_phases(template_id);
+CREATE INDEX idx_project_template_tasks_template ON project_template_tasks(template_id);
+CREATE INDEX idx_project_template_documents_template ON project_template_documents(template_id);
+CREATE INDEX idx_project_milestones_project ON project_milestones(project_id);
+CREATE INDEX idx_project_documents_project ON project_documents(project_id);
+CREATE INDEX idx_tasks_milestone_id ON tasks(milestone_id);
+
+CREATE TRIGGER update_project_templates_updated_at BEFORE UPDATE ON project_templates
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
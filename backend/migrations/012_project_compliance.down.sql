@@ -0,0 +1,13 @@
+-- Reverse project compliance migration
+
+DROP TRIGGER IF EXISTS update_project_compliance_items_updated_at ON project_compliance_items;
+DROP TRIGGER IF EXISTS update_compliance_requirements_updated_at ON compliance_requirements;
+
+DROP INDEX IF EXISTS idx_project_compliance_items_overdue;
+DROP INDEX IF EXISTS idx_project_compliance_items_project;
+DROP INDEX IF EXISTS idx_compliance_requirements_org;
+
+DROP TABLE IF EXISTS project_compliance_items;
+DROP TABLE IF EXISTS compliance_requirements;
+
+ALTER TABLE projects DROP COLUMN IF EXISTS category;
@@ -0,0 +1,46 @@
+-- Project compliance checklists
+-- Requirements per project category (licenses, safety plans, insurance) with due dates relative to project start
+
+ALTER TABLE projects ADD COLUMN category VARCHAR(50);
+
+CREATE TABLE compliance_requirements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    category VARCHAR(50), -- project category, NULL applies to all projects
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    requirement_type VARCHAR(30) NOT NULL DEFAULT 'other', -- 'license', 'safety_plan', 'insurance', 'other'
+    due_offset_days INT NOT NULL DEFAULT 0, -- relative to project start (negative = before start)
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE project_compliance_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    requirement_id UUID REFERENCES compliance_requirements(id) ON DELETE SET NULL,
+    name VARCHAR(255) NOT NULL,
+    requirement_type VARCHAR(30) NOT NULL DEFAULT 'other',
+    due_date DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- 'pending', 'completed', 'waived'
+    document_url TEXT,
+    completed_at TIMESTAMPTZ,
+    completed_by UUID REFERENCES users(id),
+    waiver_reason TEXT,
+    waived_by UUID REFERENCES users(id),
+    waived_at TIMESTAMPTZ,
+    escalated_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE(project_id, requirement_id)
+);
+
+CREATE INDEX idx_compliance_requirements_org ON compliance_requirements(organization_id, category);
+CREATE INDEX idx_project_compliance_items_project ON project_compliance_items(project_id);
+CREATE INDEX idx_project_compliance_items_overdue ON project_compliance_items(due_date) WHERE status = 'pending' AND escalated_at IS NULL;
+
+CREATE TRIGGER update_compliance_requirements_updated_at BEFORE UPDATE ON compliance_requirements
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_project_compliance_items_updated_at BEFORE UPDATE ON project_compliance_items
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
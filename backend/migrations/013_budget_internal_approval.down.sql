@@ -0,0 +1,18 @@
+-- Reverse budget internal approval migration
+
+DROP TRIGGER IF EXISTS update_budget_internal_approvals_updated_at ON budget_internal_approvals;
+DROP TRIGGER IF EXISTS update_budget_approval_rules_updated_at ON budget_approval_rules;
+
+DROP INDEX IF EXISTS idx_budget_approval_audit_budget;
+DROP INDEX IF EXISTS idx_budget_internal_approvals_budget;
+DROP INDEX IF EXISTS idx_budget_approval_rules_org;
+
+DROP TABLE IF EXISTS budget_approval_audit;
+DROP TABLE IF EXISTS budget_internal_approvals;
+DROP TABLE IF EXISTS budget_approval_rules;
+
+UPDATE budgets SET status = 'draft' WHERE status = 'pending_internal_approval';
+
+ALTER TABLE budgets DROP CONSTRAINT IF EXISTS budgets_status_check;
+ALTER TABLE budgets ADD CONSTRAINT budgets_status_check
+    CHECK (status IN ('draft', 'sent', 'approved', 'rejected', 'expired'));
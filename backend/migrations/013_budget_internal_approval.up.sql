@@ -0,0 +1,53 @@
+-- Budget internal approval
+-- Budgets above configurable thresholds need internal sign-off from specific roles before being sent to the client
+
+ALTER TABLE budgets DROP CONSTRAINT IF EXISTS budgets_status_check;
+ALTER TABLE budgets ADD CONSTRAINT budgets_status_check
+    CHECK (status IN ('draft', 'pending_internal_approval', 'sent', 'approved', 'rejected', 'expired'));
+
+CREATE TABLE budget_approval_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    min_total DECIMAL(12, 2) NOT NULL, -- rule applies to budgets with total >= min_total
+    required_role VARCHAR(50) NOT NULL CHECK (required_role IN ('admin', 'manager', 'accountant')),
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    CONSTRAINT budget_approval_rules_min_total CHECK (min_total >= 0)
+);
+
+-- One sign-off request per required role each time a budget is submitted
+CREATE TABLE budget_internal_approvals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    budget_id UUID NOT NULL REFERENCES budgets(id) ON DELETE CASCADE,
+    rule_id UUID REFERENCES budget_approval_rules(id) ON DELETE SET NULL,
+    required_role VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- 'pending', 'approved', 'rejected', 'cancelled'
+    requested_by UUID NOT NULL REFERENCES users(id),
+    decided_by UUID REFERENCES users(id),
+    decided_at TIMESTAMPTZ,
+    comment TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Audit trail of every step in the internal approval process
+CREATE TABLE budget_approval_audit (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    budget_id UUID NOT NULL REFERENCES budgets(id) ON DELETE CASCADE,
+    approval_id UUID REFERENCES budget_internal_approvals(id) ON DELETE SET NULL,
+    user_id UUID REFERENCES users(id),
+    action VARCHAR(30) NOT NULL, -- 'submitted', 'approved', 'rejected', 'released'
+    comment TEXT,
+    details JSONB,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_budget_approval_rules_org ON budget_approval_rules(organization_id, is_active);
+CREATE INDEX idx_budget_internal_approvals_budget ON budget_internal_approvals(budget_id, status);
+CREATE INDEX idx_budget_approval_audit_budget ON budget_approval_audit(budget_id, created_at);
+
+CREATE TRIGGER update_budget_approval_rules_updated_at BEFORE UPDATE ON budget_approval_rules FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_budget_internal_approvals_updated_at BEFORE UPDATE ON budget_internal_approvals FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
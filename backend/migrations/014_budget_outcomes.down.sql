@@ -0,0 +1,11 @@
+-- Reverse budget win/loss tracking migration
+
+DROP INDEX IF EXISTS idx_clients_segment;
+DROP INDEX IF EXISTS idx_budgets_outcome;
+
+ALTER TABLE budgets DROP COLUMN IF EXISTS loss_recorded_at;
+ALTER TABLE budgets DROP COLUMN IF EXISTS loss_recorded_by;
+ALTER TABLE budgets DROP COLUMN IF EXISTS loss_notes;
+ALTER TABLE budgets DROP COLUMN IF EXISTS loss_reason;
+
+ALTER TABLE clients DROP COLUMN IF EXISTS segment;
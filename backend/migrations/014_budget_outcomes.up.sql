@@ -0,0 +1,13 @@
+-- Budget win/loss tracking
+-- Structured loss reasons on rejected/expired budgets and client segments for conversion reporting
+
+ALTER TABLE clients ADD COLUMN segment VARCHAR(50); -- e.g. 'residential', 'commercial', 'public'
+
+ALTER TABLE budgets ADD COLUMN loss_reason VARCHAR(30)
+    CHECK (loss_reason IN ('price', 'timing', 'competitor', 'scope', 'no_response', 'other'));
+ALTER TABLE budgets ADD COLUMN loss_notes TEXT;
+ALTER TABLE budgets ADD COLUMN loss_recorded_by UUID REFERENCES users(id);
+ALTER TABLE budgets ADD COLUMN loss_recorded_at TIMESTAMPTZ;
+
+CREATE INDEX idx_budgets_outcome ON budgets(organization_id, status, loss_reason);
+CREATE INDEX idx_clients_segment ON clients(organization_id, segment);
@@ -0,0 +1,10 @@
+-- Reverse supplier price lists migration
+
+DROP TRIGGER IF EXISTS update_materials_updated_at ON materials;
+
+DROP INDEX IF EXISTS idx_price_list_imports_org;
+DROP INDEX IF EXISTS idx_materials_name;
+DROP INDEX IF EXISTS idx_materials_org;
+
+DROP TABLE IF EXISTS materials;
+DROP TABLE IF EXISTS price_list_imports;
@@ -0,0 +1,47 @@
+-- Supplier price lists
+-- Materials catalogue and staged CSV/XLSX imports (preview before confirm)
+
+CREATE TABLE materials (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    supplier VARCHAR(255) NOT NULL DEFAULT '',
+    code VARCHAR(100) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    unit VARCHAR(50) NOT NULL,
+    unit_price DECIMAL(12, 4) NOT NULL,
+    is_active BOOLEAN DEFAULT true,
+    last_import_id UUID,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE(organization_id, supplier, code)
+);
+
+CREATE TABLE price_list_imports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    file_name VARCHAR(255) NOT NULL,
+    file_format VARCHAR(10) NOT NULL CHECK (file_format IN ('csv', 'xlsx')),
+    target VARCHAR(20) NOT NULL CHECK (target IN ('catalogue', 'budget')),
+    budget_id UUID REFERENCES budgets(id) ON DELETE CASCADE,
+    supplier VARCHAR(255) NOT NULL DEFAULT '',
+    options JSONB NOT NULL DEFAULT '{}', -- column mapping, unit conversions, tax rate
+    rows JSONB NOT NULL DEFAULT '[]',    -- parsed rows shown in the preview
+    row_count INT NOT NULL DEFAULT 0,
+    error_count INT NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'preview' CHECK (status IN ('preview', 'confirmed', 'cancelled')),
+    created_by UUID REFERENCES users(id),
+    confirmed_by UUID REFERENCES users(id),
+    confirmed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    CHECK (target <> 'budget' OR budget_id IS NOT NULL)
+);
+
+ALTER TABLE materials ADD CONSTRAINT fk_materials_last_import
+    FOREIGN KEY (last_import_id) REFERENCES price_list_imports(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_materials_org ON materials(organization_id, is_active);
+CREATE INDEX idx_materials_name ON materials(organization_id, name);
+CREATE INDEX idx_price_list_imports_org ON price_list_imports(organization_id, created_at DESC);
+
+CREATE TRIGGER update_materials_updated_at BEFORE UPDATE ON materials
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,9 @@
+-- Reverse price history migration
+
+DROP TRIGGER IF EXISTS update_cost_index_values_updated_at ON cost_index_values;
+
+DROP INDEX IF EXISTS idx_price_history_budget;
+DROP INDEX IF EXISTS idx_price_history_lookup;
+
+DROP TABLE IF EXISTS cost_index_values;
+DROP TABLE IF EXISTS price_history;
@@ -0,0 +1,40 @@
+-- Price history and construction cost indexes
+-- Unit prices recorded from sent budgets and catalogue imports, used to flag cost drift on new budgets
+
+CREATE TABLE price_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    item_key TEXT NOT NULL, -- normalized description
+    description TEXT NOT NULL,
+    unit VARCHAR(50) NOT NULL,
+    unit_price DECIMAL(12, 4) NOT NULL,
+    source VARCHAR(20) NOT NULL CHECK (source IN ('budget', 'catalogue')),
+    budget_id UUID REFERENCES budgets(id) ON DELETE CASCADE,
+    material_id UUID REFERENCES materials(id) ON DELETE CASCADE,
+    recorded_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Monthly construction cost index values maintained per organization (e.g. published by the statistics office)
+CREATE TABLE cost_index_values (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    period DATE NOT NULL CHECK (EXTRACT(DAY FROM period) = 1), -- first day of the month
+    value DECIMAL(10, 4) NOT NULL CHECK (value > 0),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE(organization_id, period)
+);
+
+CREATE INDEX idx_price_history_lookup ON price_history(organization_id, item_key, unit, recorded_at DESC);
+CREATE INDEX idx_price_history_budget ON price_history(budget_id);
+
+CREATE TRIGGER update_cost_index_values_updated_at BEFORE UPDATE ON cost_index_values
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Backfill from budgets already sent to clients
+INSERT INTO price_history (organization_id, item_key, description, unit, unit_price, source, budget_id, recorded_at)
+SELECT b.organization_id, lower(regexp_replace(trim(bi.description), '\s+', ' ', 'g')), bi.description,
+    bi.unit, bi.unit_price, 'budget', b.id, b.sent_at
+FROM budget_items bi
+JOIN budgets b ON b.id = bi.budget_id
+WHERE b.sent_at IS NOT NULL AND b.deleted_at IS NULL AND bi.deleted_at IS NULL;
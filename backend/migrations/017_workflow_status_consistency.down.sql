@@ -0,0 +1,8 @@
+-- Reverse workflow status consistency migration
+
+DROP TRIGGER IF EXISTS update_workflow_status_remaps_updated_at ON workflow_status_remaps;
+
+DROP INDEX IF EXISTS idx_workflow_status_mismatches_open;
+
+DROP TABLE IF EXISTS workflow_status_mismatches;
+DROP TABLE IF EXISTS workflow_status_remaps;
@@ -0,0 +1,35 @@
+-- Workflow status consistency
+-- Entity statuses that no longer match a state of the default workflow, and remaps to fix them
+
+CREATE TABLE workflow_status_remaps (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    entity_type VARCHAR(50) NOT NULL, -- 'session', 'budget', 'project'
+    from_status VARCHAR(50) NOT NULL,
+    to_state_id UUID NOT NULL REFERENCES workflow_states(id) ON DELETE CASCADE,
+    auto_apply BOOLEAN DEFAULT false, -- applied by the consistency check job
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE(organization_id, entity_type, from_status)
+);
+
+CREATE TABLE workflow_status_mismatches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    workflow_id UUID NOT NULL REFERENCES workflows(id) ON DELETE CASCADE,
+    entity_type VARCHAR(50) NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    entity_count INT NOT NULL DEFAULT 0,
+    sample_entity_ids UUID[] NOT NULL DEFAULT '{}',
+    remap_error TEXT, -- last failure applying a remap
+    first_detected_at TIMESTAMPTZ DEFAULT NOW(),
+    last_checked_at TIMESTAMPTZ DEFAULT NOW(),
+    resolved_at TIMESTAMPTZ,
+    UNIQUE(organization_id, entity_type, status)
+);
+
+CREATE INDEX idx_workflow_status_mismatches_open ON workflow_status_mismatches(organization_id) WHERE resolved_at IS NULL;
+
+CREATE TRIGGER update_workflow_status_remaps_updated_at BEFORE UPDATE ON workflow_status_remaps
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
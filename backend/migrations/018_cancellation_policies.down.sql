@@ -0,0 +1,14 @@
+-- Reverse session cancellation policies migration
+
+DROP TRIGGER IF EXISTS update_cancellation_policy_rules_updated_at ON cancellation_policy_rules;
+
+DROP INDEX IF EXISTS idx_cancellation_policy_rules_org;
+
+ALTER TABLE session_payments DROP COLUMN IF EXISTS kind;
+
+ALTER TABLE sessions DROP COLUMN IF EXISTS cancellation_policy_note;
+ALTER TABLE sessions DROP COLUMN IF EXISTS cancellation_fee_waived;
+ALTER TABLE sessions DROP COLUMN IF EXISTS cancellation_rule_id;
+ALTER TABLE sessions DROP COLUMN IF EXISTS cancellation_fee_cents;
+
+DROP TABLE IF EXISTS cancellation_policy_rules;
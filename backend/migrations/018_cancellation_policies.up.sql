@@ -0,0 +1,27 @@
+-- Session cancellation policies
+-- Fee windows applied when a session is cancelled close to its start
+
+CREATE TABLE cancellation_policy_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    window_hours INT NOT NULL CHECK (window_hours > 0), -- applies to cancellations less than this many hours before the session
+    fee_percent DECIMAL(5, 2) NOT NULL CHECK (fee_percent >= 0 AND fee_percent <= 100),
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE(organization_id, window_hours)
+);
+
+ALTER TABLE sessions ADD COLUMN cancellation_fee_cents INT;
+ALTER TABLE sessions ADD COLUMN cancellation_rule_id UUID REFERENCES cancellation_policy_rules(id) ON DELETE SET NULL;
+ALTER TABLE sessions ADD COLUMN cancellation_fee_waived BOOLEAN DEFAULT false;
+ALTER TABLE sessions ADD COLUMN cancellation_policy_note TEXT; -- policy outcome shown in the cancellation message
+
+ALTER TABLE session_payments ADD COLUMN kind VARCHAR(20) NOT NULL DEFAULT 'session'
+    CHECK (kind IN ('session', 'cancellation_fee'));
+
+CREATE INDEX idx_cancellation_policy_rules_org ON cancellation_policy_rules(organization_id, is_active);
+
+CREATE TRIGGER update_cancellation_policy_rules_updated_at BEFORE UPDATE ON cancellation_policy_rules
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
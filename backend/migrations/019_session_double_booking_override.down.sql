@@ -0,0 +1,9 @@
+-- Reverse double-booking override migration
+
+DROP INDEX IF EXISTS idx_sessions_override_pending;
+
+ALTER TABLE sessions DROP COLUMN IF EXISTS override_decided_at;
+ALTER TABLE sessions DROP COLUMN IF EXISTS override_decided_by;
+ALTER TABLE sessions DROP COLUMN IF EXISTS override_status;
+ALTER TABLE sessions DROP COLUMN IF EXISTS override_reason;
+ALTER TABLE sessions DROP COLUMN IF EXISTS conflict_override;
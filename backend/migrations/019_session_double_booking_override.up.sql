@@ -0,0 +1,13 @@
+-- Double-booking override
+-- Lets staff book intentionally overlapping sessions (e.g. couples therapy) with a reason,
+-- optionally subject to manager approval (appointments module config "double_booking_requires_approval")
+
+ALTER TABLE sessions ADD COLUMN conflict_override BOOLEAN DEFAULT false;
+ALTER TABLE sessions ADD COLUMN override_reason TEXT;
+ALTER TABLE sessions ADD COLUMN override_status VARCHAR(20)
+    CHECK (override_status IN ('pending', 'approved', 'rejected'));
+ALTER TABLE sessions ADD COLUMN override_decided_by UUID REFERENCES users(id);
+ALTER TABLE sessions ADD COLUMN override_decided_at TIMESTAMPTZ;
+
+CREATE INDEX idx_sessions_override_pending ON sessions(organization_id)
+    WHERE override_status = 'pending' AND deleted_at IS NULL;
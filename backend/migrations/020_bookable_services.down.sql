@@ -0,0 +1,11 @@
+-- Reverse service catalogue migration
+
+DROP TRIGGER IF EXISTS update_bookable_services_updated_at ON bookable_services;
+
+DROP INDEX IF EXISTS idx_bookable_service_therapists_therapist;
+DROP INDEX IF EXISTS idx_bookable_services_org;
+
+ALTER TABLE sessions DROP COLUMN IF EXISTS service_id;
+
+DROP TABLE IF EXISTS bookable_service_therapists;
+DROP TABLE IF EXISTS bookable_services;
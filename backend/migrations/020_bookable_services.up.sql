@@ -0,0 +1,33 @@
+-- Service catalogue for the appointments module
+-- Named services with duration and price, used by public booking and to pre-fill sessions
+
+CREATE TABLE bookable_services (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    duration_minutes INT NOT NULL CHECK (duration_minutes > 0),
+    price_cents INT NOT NULL DEFAULT 0 CHECK (price_cents >= 0),
+    session_type VARCHAR(50) NOT NULL DEFAULT 'regular',
+    is_public BOOLEAN DEFAULT false, -- listed in public booking
+    is_active BOOLEAN DEFAULT true,
+    position INT DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ
+);
+
+-- Therapists allowed to provide a service (no rows means every therapist)
+CREATE TABLE bookable_service_therapists (
+    service_id UUID NOT NULL REFERENCES bookable_services(id) ON DELETE CASCADE,
+    therapist_id UUID NOT NULL REFERENCES therapists(id) ON DELETE CASCADE,
+    PRIMARY KEY (service_id, therapist_id)
+);
+
+ALTER TABLE sessions ADD COLUMN service_id UUID REFERENCES bookable_services(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_bookable_services_org ON bookable_services(organization_id, is_active) WHERE deleted_at IS NULL;
+CREATE INDEX idx_bookable_service_therapists_therapist ON bookable_service_therapists(therapist_id);
+
+CREATE TRIGGER update_bookable_services_updated_at BEFORE UPDATE ON bookable_services
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_workflow_triggers_recurring;
+
+ALTER TABLE workflow_triggers DROP COLUMN IF EXISTS recurring_last_run_at;
+ALTER TABLE workflow_triggers DROP COLUMN IF EXISTS recurring_skip;
+
+DROP TABLE IF EXISTS organization_holidays;
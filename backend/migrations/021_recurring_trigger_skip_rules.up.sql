@@ -0,0 +1,18 @@
+-- Skip rules for recurring workflow triggers
+-- Organization holidays and weekend/business-day rules evaluated by the scheduler
+
+CREATE TABLE organization_holidays (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (organization_id, date)
+);
+
+-- none, weekends, holidays, business_days (weekends and holidays)
+ALTER TABLE workflow_triggers ADD COLUMN recurring_skip VARCHAR(20) NOT NULL DEFAULT 'none';
+-- Last cron occurrence evaluated by the scheduler, whether it ran or was skipped
+ALTER TABLE workflow_triggers ADD COLUMN recurring_last_run_at TIMESTAMPTZ;
+
+CREATE INDEX idx_workflow_triggers_recurring ON workflow_triggers(trigger_type) WHERE trigger_type = 'recurring' AND is_active = true;
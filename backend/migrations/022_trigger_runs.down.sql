@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_trigger_run_items_run;
+DROP INDEX IF EXISTS idx_trigger_runs_trigger;
+DROP INDEX IF EXISTS idx_trigger_runs_org;
+
+DROP TABLE IF EXISTS trigger_run_items;
+DROP TABLE IF EXISTS trigger_runs;
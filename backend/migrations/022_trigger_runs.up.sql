@@ -0,0 +1,31 @@
+-- Bulk trigger runs
+-- A trigger fired for many entities is fanned out into one task per recipient, tracked here
+
+CREATE TABLE trigger_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    trigger_id UUID NOT NULL REFERENCES workflow_triggers(id) ON DELETE CASCADE,
+    source VARCHAR(20) NOT NULL DEFAULT 'recurring',
+    status VARCHAR(30) NOT NULL DEFAULT 'pending', -- pending, running, completed, completed_with_errors
+    total_count INT NOT NULL DEFAULT 0,
+    completed_count INT NOT NULL DEFAULT 0,
+    failed_count INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ
+);
+
+CREATE TABLE trigger_run_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    run_id UUID NOT NULL REFERENCES trigger_runs(id) ON DELETE CASCADE,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id UUID NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, completed, failed
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    processed_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_trigger_runs_org ON trigger_runs(organization_id, created_at DESC);
+CREATE INDEX idx_trigger_runs_trigger ON trigger_runs(trigger_id);
+CREATE INDEX idx_trigger_run_items_run ON trigger_run_items(run_id, status);
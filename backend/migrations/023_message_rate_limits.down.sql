@@ -0,0 +1,2 @@
+ALTER TABLE notification_configs DROP COLUMN IF EXISTS email_messages_per_second;
+ALTER TABLE notification_configs DROP COLUMN IF EXISTS whatsapp_messages_per_second;
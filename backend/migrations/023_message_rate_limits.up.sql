@@ -0,0 +1,5 @@
+-- Per-organization message rate limits
+-- Messages per second for each provider; NULL uses the platform default
+
+ALTER TABLE notification_configs ADD COLUMN whatsapp_messages_per_second NUMERIC(6,2) CHECK (whatsapp_messages_per_second > 0);
+ALTER TABLE notification_configs ADD COLUMN email_messages_per_second NUMERIC(6,2) CHECK (email_messages_per_second > 0);
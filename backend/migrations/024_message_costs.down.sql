@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS update_message_costs_updated_at ON message_costs;
+
+DROP INDEX IF EXISTS idx_message_costs_sid;
+DROP INDEX IF EXISTS idx_message_costs_org_created;
+
+ALTER TABLE notification_configs DROP COLUMN IF EXISTS message_cap_alerted_at;
+ALTER TABLE notification_configs DROP COLUMN IF EXISTS monthly_message_cap;
+
+DROP TABLE IF EXISTS message_costs;
@@ -0,0 +1,27 @@
+-- Message cost tracking
+-- Provider cost of every outbound message, with a monthly spend cap per organization
+
+CREATE TABLE message_costs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    channel VARCHAR(20) NOT NULL,
+    provider VARCHAR(20) NOT NULL,
+    message_sid VARCHAR(100), -- provider message ID, used to apply the price from callbacks
+    cost NUMERIC(12,5) NOT NULL DEFAULT 0,
+    currency VARCHAR(3) NOT NULL DEFAULT 'EUR',
+    source VARCHAR(20) NOT NULL DEFAULT 'rate_table', -- rate_table (estimate) or provider (actual price)
+    critical BOOLEAN DEFAULT false,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Monthly spend cap; non-critical sends pause once it is reached
+ALTER TABLE notification_configs ADD COLUMN monthly_message_cap NUMERIC(10,2) CHECK (monthly_message_cap >= 0);
+ALTER TABLE notification_configs ADD COLUMN message_cap_alerted_at TIMESTAMPTZ;
+
+CREATE INDEX idx_message_costs_org_created ON message_costs(organization_id, created_at);
+CREATE UNIQUE INDEX idx_message_costs_sid ON message_costs(message_sid) WHERE message_sid IS NOT NULL;
+
+CREATE TRIGGER update_message_costs_updated_at
+    BEFORE UPDATE ON message_costs
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
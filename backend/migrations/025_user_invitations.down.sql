@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS update_user_invitations_updated_at ON user_invitations;
+
+DROP INDEX IF EXISTS idx_user_invitations_open_email;
+DROP INDEX IF EXISTS idx_user_invitations_org;
+DROP INDEX IF EXISTS idx_user_invitations_token;
+
+DROP TABLE IF EXISTS user_invitations;
@@ -0,0 +1,29 @@
+-- User invitations
+-- Admins invite colleagues by email with a role; accepting the invite creates the user
+
+CREATE TABLE user_invitations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(50) NOT NULL CHECK (role IN ('admin', 'manager', 'employee', 'accountant')),
+    token_hash VARCHAR(64) NOT NULL, -- SHA-256 of the token sent by email, the token itself is never stored
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    sent_count INTEGER NOT NULL DEFAULT 1,
+    last_sent_at TIMESTAMPTZ DEFAULT NOW(),
+    accepted_at TIMESTAMPTZ,
+    accepted_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_user_invitations_token ON user_invitations(token_hash);
+CREATE INDEX idx_user_invitations_org ON user_invitations(organization_id, created_at DESC);
+-- One open invitation per email and organization
+CREATE UNIQUE INDEX idx_user_invitations_open_email ON user_invitations(organization_id, LOWER(email))
+    WHERE accepted_at IS NULL AND revoked_at IS NULL;
+
+CREATE TRIGGER update_user_invitations_updated_at
+    BEFORE UPDATE ON user_invitations
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_tasks_overdue;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS due_notified_at;
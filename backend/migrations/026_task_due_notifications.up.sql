@@ -0,0 +1,7 @@
+-- Overdue task detection
+-- Open tasks past their due date are flagged once so the assignee is notified and task_due triggers fire
+
+ALTER TABLE tasks ADD COLUMN due_notified_at TIMESTAMPTZ;
+
+CREATE INDEX idx_tasks_overdue ON tasks(due_date)
+    WHERE due_notified_at IS NULL AND deleted_at IS NULL AND status IN ('todo', 'in_progress');
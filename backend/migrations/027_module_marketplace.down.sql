@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_module_changelog_module;
+DROP TABLE IF EXISTS module_changelog;
+
+UPDATE available_modules SET dependencies = '[]' WHERE name = 'notifications';
+
+ALTER TABLE organization_modules DROP COLUMN IF EXISTS changelog_seen_at;
+ALTER TABLE available_modules DROP COLUMN IF EXISTS version;
+ALTER TABLE available_modules DROP COLUMN IF EXISTS pricing_tier;
@@ -0,0 +1,27 @@
+-- Module marketplace metadata
+-- Pricing tier and version per module, "any of" dependencies ("a|b") and a per-module changelog
+
+ALTER TABLE available_modules ADD COLUMN pricing_tier VARCHAR(20) NOT NULL DEFAULT 'standard'
+    CHECK (pricing_tier IN ('free', 'standard', 'premium'));
+ALTER TABLE available_modules ADD COLUMN version VARCHAR(20) NOT NULL DEFAULT '1.0.0';
+
+-- When the organization's admins last opened the module's what's-new feed
+ALTER TABLE organization_modules ADD COLUMN changelog_seen_at TIMESTAMPTZ;
+
+-- Notifications are sent for appointments or construction entities, so one of them is required
+UPDATE available_modules SET
+    pricing_tier = 'premium',
+    dependencies = '["appointments|construction"]'
+WHERE name = 'notifications';
+
+CREATE TABLE module_changelog (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    module_name VARCHAR(50) NOT NULL REFERENCES available_modules(name) ON DELETE CASCADE,
+    version VARCHAR(20) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    body TEXT,
+    released_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_module_changelog_module ON module_changelog(module_name, released_at DESC);
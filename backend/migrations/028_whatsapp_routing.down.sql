@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_whatsapp_numbers_org;
+DROP TABLE IF EXISTS whatsapp_numbers;
+
+DROP INDEX IF EXISTS idx_notification_configs_webhook_token;
+ALTER TABLE notification_configs DROP COLUMN IF EXISTS webhook_token;
@@ -0,0 +1,25 @@
+-- Inbound WhatsApp routing
+-- Each organization gets a secret webhook token (/webhooks/whatsapp/{token}) and its sender numbers
+-- are mapped to it, so inbound messages on the shared endpoint reach the right tenant
+
+ALTER TABLE notification_configs ADD COLUMN webhook_token VARCHAR(64) NOT NULL
+    DEFAULT replace(gen_random_uuid()::text, '-', '') || replace(gen_random_uuid()::text, '-', '');
+
+CREATE UNIQUE INDEX idx_notification_configs_webhook_token ON notification_configs(webhook_token);
+
+-- Numbers are stored in E.164 without the whatsapp: prefix
+CREATE TABLE whatsapp_numbers (
+    phone_number VARCHAR(20) PRIMARY KEY,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_whatsapp_numbers_org ON whatsapp_numbers(organization_id);
+
+INSERT INTO whatsapp_numbers (phone_number, organization_id)
+SELECT DISTINCT ON (replace(twilio_whatsapp_number, 'whatsapp:', ''))
+    replace(twilio_whatsapp_number, 'whatsapp:', ''), organization_id
+FROM notification_configs
+WHERE twilio_whatsapp_number IS NOT NULL AND twilio_whatsapp_number <> ''
+ORDER BY replace(twilio_whatsapp_number, 'whatsapp:', ''), created_at
+ON CONFLICT DO NOTHING;
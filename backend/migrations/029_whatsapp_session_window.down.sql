@@ -0,0 +1,3 @@
+ALTER TABLE message_templates DROP COLUMN IF EXISTS whatsapp_content_sid;
+
+DROP TABLE IF EXISTS whatsapp_conversations;
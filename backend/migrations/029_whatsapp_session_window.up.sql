@@ -0,0 +1,18 @@
+-- WhatsApp customer service window
+-- Free-form WhatsApp messages are only delivered within 24 hours of the contact's last inbound message;
+-- outside it Twilio rejects them (error 63016) and an approved template (Content SID) must be used
+
+CREATE TABLE whatsapp_conversations (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    phone_number VARCHAR(20) NOT NULL, -- E.164, without the whatsapp: prefix
+    last_inbound_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (organization_id, phone_number)
+);
+
+INSERT INTO whatsapp_conversations (organization_id, phone_number, last_inbound_at)
+SELECT organization_id, replace(phone_number, 'whatsapp:', ''), MAX(created_at)
+FROM whatsapp_messages
+WHERE direction = 'inbound'
+GROUP BY organization_id, replace(phone_number, 'whatsapp:', '');
+
+ALTER TABLE message_templates ADD COLUMN whatsapp_content_sid VARCHAR(64);
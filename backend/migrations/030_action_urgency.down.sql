@@ -0,0 +1,3 @@
+ALTER TABLE notification_configs DROP COLUMN IF EXISTS do_not_disturb_until;
+
+ALTER TABLE workflow_actions DROP COLUMN IF EXISTS urgency;
@@ -0,0 +1,8 @@
+-- Urgent notifications
+-- Organizations can pause outgoing messages with a do-not-disturb period. Normal message actions are
+-- held until it ends, urgent ones (e.g. a session cancelled by the clinic shortly before) are sent anyway
+
+ALTER TABLE workflow_actions ADD COLUMN urgency VARCHAR(10) NOT NULL DEFAULT 'normal'
+    CHECK (urgency IN ('normal', 'urgent'));
+
+ALTER TABLE notification_configs ADD COLUMN do_not_disturb_until TIMESTAMPTZ;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_financial_period_events_period;
+DROP TABLE IF EXISTS financial_period_events;
+
+DROP INDEX IF EXISTS idx_financial_periods_closed;
+DROP TABLE IF EXISTS financial_periods;
@@ -0,0 +1,31 @@
+-- Monthly close
+-- Closing a month locks the payments that belong to it (paid in it, or due in it while open) and stores
+-- a snapshot of its figures. Reopening requires a reason; every close and reopen is kept in the history.
+
+CREATE TABLE financial_periods (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    period DATE NOT NULL, -- first day of the month
+    status VARCHAR(20) NOT NULL CHECK (status IN ('closed', 'reopened')),
+    snapshot JSONB,
+    closed_by UUID REFERENCES users(id),
+    closed_at TIMESTAMPTZ,
+    reopened_by UUID REFERENCES users(id),
+    reopened_at TIMESTAMPTZ,
+    reopen_reason TEXT,
+    UNIQUE (organization_id, period)
+);
+
+CREATE INDEX idx_financial_periods_closed ON financial_periods(organization_id, period) WHERE status = 'closed';
+
+CREATE TABLE financial_period_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    period DATE NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('closed', 'reopened')),
+    user_id UUID REFERENCES users(id),
+    reason TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_financial_period_events_period ON financial_period_events(organization_id, period);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_cash_register_entries_payment;
+DROP INDEX IF EXISTS idx_cash_register_entries_register;
+DROP TABLE IF EXISTS cash_register_entries;
+
+DROP INDEX IF EXISTS idx_cash_registers_opened_at;
+DROP INDEX IF EXISTS idx_cash_registers_open;
+DROP TABLE IF EXISTS cash_registers;
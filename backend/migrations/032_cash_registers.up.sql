@@ -0,0 +1,38 @@
+-- Cash register
+-- Clinics open a register at the start of the day, record the cash, MB Way and card takings (usually
+-- against a session payment) and close it with the counted amounts. The end-of-day reconciliation
+-- compares the counted amounts with the recorded takings.
+
+CREATE TABLE cash_registers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'closed')),
+    opening_float_cents INTEGER NOT NULL DEFAULT 0 CHECK (opening_float_cents >= 0),
+    opened_by UUID REFERENCES users(id),
+    opened_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    counted_cash_cents INTEGER,
+    counted_card_cents INTEGER,
+    counted_mbway_cents INTEGER,
+    closed_by UUID REFERENCES users(id),
+    closed_at TIMESTAMPTZ,
+    notes TEXT
+);
+
+-- Only one register can be open at a time
+CREATE UNIQUE INDEX idx_cash_registers_open ON cash_registers(organization_id) WHERE status = 'open';
+CREATE INDEX idx_cash_registers_opened_at ON cash_registers(organization_id, opened_at DESC);
+
+CREATE TABLE cash_register_entries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    cash_register_id UUID NOT NULL REFERENCES cash_registers(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    session_payment_id UUID REFERENCES session_payments(id) ON DELETE SET NULL,
+    method VARCHAR(20) NOT NULL CHECK (method IN ('cash', 'card', 'mbway')),
+    amount_cents INTEGER NOT NULL CHECK (amount_cents > 0),
+    description TEXT,
+    recorded_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_cash_register_entries_register ON cash_register_entries(cash_register_id);
+CREATE INDEX idx_cash_register_entries_payment ON cash_register_entries(session_payment_id) WHERE session_payment_id IS NOT NULL;
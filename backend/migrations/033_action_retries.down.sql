@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_workflow_dead_letters_requeued;
+DROP INDEX IF EXISTS idx_workflow_dead_letters_org;
+DROP TABLE IF EXISTS workflow_dead_letters;
+
+ALTER TABLE workflow_actions
+    DROP COLUMN IF EXISTS retry_backoff_seconds,
+    DROP COLUMN IF EXISTS retry_max_attempts;
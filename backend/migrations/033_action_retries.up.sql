@@ -0,0 +1,29 @@
+-- Action retry policies
+-- A failed action is retried up to retry_max_attempts times in total, waiting retry_backoff_seconds
+-- doubled after every attempt. Actions that still fail land in the dead-letter table, where admins
+-- can inspect them and requeue them once the cause is fixed.
+
+ALTER TABLE workflow_actions
+    ADD COLUMN retry_max_attempts INTEGER NOT NULL DEFAULT 1 CHECK (retry_max_attempts BETWEEN 1 AND 10),
+    ADD COLUMN retry_backoff_seconds INTEGER NOT NULL DEFAULT 60 CHECK (retry_backoff_seconds BETWEEN 1 AND 86400);
+
+CREATE TABLE workflow_dead_letters (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    workflow_id UUID NOT NULL REFERENCES workflows(id) ON DELETE CASCADE,
+    trigger_id UUID NOT NULL REFERENCES workflow_triggers(id) ON DELETE CASCADE,
+    action_id UUID NOT NULL REFERENCES workflow_actions(id) ON DELETE CASCADE,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id UUID NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 1,
+    last_error TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'failed' CHECK (status IN ('failed', 'requeued', 'resolved', 'discarded')),
+    requeued_by UUID REFERENCES users(id),
+    requeued_at TIMESTAMPTZ,
+    resolved_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_workflow_dead_letters_org ON workflow_dead_letters(organization_id, status, created_at DESC);
+CREATE INDEX idx_workflow_dead_letters_requeued ON workflow_dead_letters(requeued_at) WHERE status = 'requeued';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_expenses_drafts;
+DROP INDEX IF EXISTS idx_expenses_project;
+DROP INDEX IF EXISTS idx_expenses_org_date;
+DROP TABLE IF EXISTS expenses;
@@ -0,0 +1,36 @@
+-- Expenses
+-- Expenses are recorded from a photo or PDF of the receipt. The receipt is run through the configured
+-- OCR provider, which pre-fills a draft with the vendor, date, amount and VAT. A user reviews the
+-- draft and confirms it, which posts the expense to its month.
+
+CREATE TABLE expenses (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    -- Project expenses belong to a project, clinic expenses to the organization
+    scope VARCHAR(20) NOT NULL CHECK (scope IN ('project', 'clinic')),
+    project_id UUID REFERENCES projects(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'posted')),
+    vendor VARCHAR(255),
+    vendor_tax_id VARCHAR(50),
+    expense_date DATE,
+    amount DECIMAL(12, 2) CHECK (amount >= 0),
+    vat_amount DECIMAL(12, 2) CHECK (vat_amount >= 0),
+    vat_rate DECIMAL(5, 2) CHECK (vat_rate >= 0 AND vat_rate <= 100),
+    category VARCHAR(100),
+    description TEXT,
+    receipt_url TEXT,
+    receipt_file_name VARCHAR(255),
+    ocr_provider VARCHAR(50),
+    ocr_confidence DECIMAL(4, 3),
+    ocr_data JSONB,
+    created_by UUID REFERENCES users(id),
+    confirmed_by UUID REFERENCES users(id),
+    confirmed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_expenses_org_date ON expenses(organization_id, expense_date DESC) WHERE deleted_at IS NULL;
+CREATE INDEX idx_expenses_project ON expenses(project_id) WHERE project_id IS NOT NULL AND deleted_at IS NULL;
+CREATE INDEX idx_expenses_drafts ON expenses(organization_id, created_at DESC) WHERE status = 'draft' AND deleted_at IS NULL;
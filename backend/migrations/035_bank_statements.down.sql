@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_bank_statement_lines_session_payment;
+DROP INDEX IF EXISTS idx_bank_statement_lines_payment;
+DROP INDEX IF EXISTS idx_bank_statement_lines_unmatched;
+DROP INDEX IF EXISTS idx_bank_statement_lines_statement;
+DROP TABLE IF EXISTS bank_statement_lines;
+
+DROP INDEX IF EXISTS idx_bank_statements_org;
+DROP TABLE IF EXISTS bank_statements;
@@ -0,0 +1,50 @@
+-- Bank statements
+-- Statements are imported from CSV or CAMT.053 files or synced from a PSD2 aggregator. Each line
+-- can be matched to an open payment or session payment, which marks it paid. Lines keep an
+-- external id (the bank's, or a hash of the line) so overlapping imports don't duplicate them.
+
+CREATE TABLE bank_statements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    source VARCHAR(20) NOT NULL CHECK (source IN ('csv', 'camt', 'aggregator')),
+    account_iban VARCHAR(34),
+    file_name VARCHAR(255),
+    period_from DATE,
+    period_to DATE,
+    line_count INTEGER NOT NULL DEFAULT 0,
+    duplicate_count INTEGER NOT NULL DEFAULT 0,
+    imported_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_bank_statements_org ON bank_statements(organization_id, created_at DESC);
+
+CREATE TABLE bank_statement_lines (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    statement_id UUID NOT NULL REFERENCES bank_statements(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    external_id VARCHAR(100) NOT NULL,
+    booking_date DATE NOT NULL,
+    value_date DATE,
+    -- Credits are positive, debits negative
+    amount DECIMAL(12, 2) NOT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT 'EUR',
+    description TEXT,
+    reference VARCHAR(255),
+    counterparty_name VARCHAR(255),
+    counterparty_iban VARCHAR(34),
+    status VARCHAR(20) NOT NULL DEFAULT 'unmatched' CHECK (status IN ('unmatched', 'matched', 'ignored')),
+    matched_payment_id UUID REFERENCES payments(id) ON DELETE SET NULL,
+    matched_session_payment_id UUID REFERENCES session_payments(id) ON DELETE SET NULL,
+    match_score INTEGER,
+    auto_matched BOOLEAN NOT NULL DEFAULT false,
+    matched_by UUID REFERENCES users(id),
+    matched_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(organization_id, external_id)
+);
+
+CREATE INDEX idx_bank_statement_lines_statement ON bank_statement_lines(statement_id, booking_date);
+CREATE INDEX idx_bank_statement_lines_unmatched ON bank_statement_lines(organization_id, booking_date DESC) WHERE status = 'unmatched';
+CREATE INDEX idx_bank_statement_lines_payment ON bank_statement_lines(matched_payment_id) WHERE matched_payment_id IS NOT NULL;
+CREATE INDEX idx_bank_statement_lines_session_payment ON bank_statement_lines(matched_session_payment_id) WHERE matched_session_payment_id IS NOT NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_export_bundles_pending;
+DROP INDEX IF EXISTS idx_export_bundles_org;
+DROP TABLE IF EXISTS export_bundles;
@@ -0,0 +1,23 @@
+-- Accountant export bundles
+-- A bundle is a zip with a month's payments, session payments and posted expenses as CSV plus a
+-- SAF-T (PT) file. It is requested from the accountant workspace and built by the worker, which
+-- uploads it and optionally emails the requester a download link.
+
+CREATE TABLE export_bundles (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    period DATE NOT NULL, -- first day of the month
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'processing', 'ready', 'failed')),
+    send_email BOOLEAN NOT NULL DEFAULT false,
+    file_url TEXT,
+    file_name VARCHAR(255),
+    file_size BIGINT,
+    error TEXT,
+    requested_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_export_bundles_org ON export_bundles(organization_id, created_at DESC);
+CREATE INDEX idx_export_bundles_pending ON export_bundles(created_at) WHERE status = 'pending';
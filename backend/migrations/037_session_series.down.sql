@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_sessions_series;
+ALTER TABLE sessions DROP COLUMN IF EXISTS series_id;
+
+DROP INDEX IF EXISTS idx_session_series_org;
+DROP TABLE IF EXISTS session_series;
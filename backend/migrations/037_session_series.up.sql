@@ -0,0 +1,27 @@
+-- Recurring session series
+-- A series is created from a recurrence rule (weekly or biweekly, on one or more weekdays, for a
+-- number of occurrences or until a date). Its sessions are regular sessions linked by series_id,
+-- so upcoming ones can be edited or cancelled together.
+
+CREATE TABLE session_series (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    therapist_id UUID NOT NULL REFERENCES therapists(id),
+    patient_id UUID NOT NULL REFERENCES patients(id),
+    service_id UUID REFERENCES bookable_services(id) ON DELETE SET NULL,
+    frequency VARCHAR(20) NOT NULL CHECK (frequency IN ('weekly', 'biweekly')),
+    weekdays VARCHAR(2)[] NOT NULL,
+    occurrences INTEGER CHECK (occurrences > 0),
+    until_date DATE,
+    starts_at TIMESTAMPTZ NOT NULL,
+    duration_minutes INTEGER NOT NULL,
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (occurrences IS NOT NULL OR until_date IS NOT NULL)
+);
+
+CREATE INDEX idx_session_series_org ON session_series(organization_id);
+
+ALTER TABLE sessions ADD COLUMN series_id UUID REFERENCES session_series(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_sessions_series ON sessions(series_id, scheduled_at) WHERE series_id IS NOT NULL;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_organization_usage_storage;
+DROP TABLE IF EXISTS organization_usage;
+ALTER TABLE organizations DROP COLUMN IF EXISTS monthly_message_quota;
+ALTER TABLE organizations DROP COLUMN IF EXISTS storage_quota_bytes;
@@ -0,0 +1,22 @@
+-- Organization data usage and quotas
+-- A nightly job snapshots each organization's stored files, message volume and row counts.
+-- Quotas are set per organization by system admins; NULL means unlimited.
+
+ALTER TABLE organizations ADD COLUMN storage_quota_bytes BIGINT CHECK (storage_quota_bytes > 0);
+ALTER TABLE organizations ADD COLUMN monthly_message_quota INTEGER CHECK (monthly_message_quota > 0);
+
+CREATE TABLE organization_usage (
+    organization_id UUID PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+    photo_count INTEGER NOT NULL DEFAULT 0,
+    photo_bytes BIGINT NOT NULL DEFAULT 0,
+    document_count INTEGER NOT NULL DEFAULT 0, -- project and compliance documents (size not recorded)
+    receipt_count INTEGER NOT NULL DEFAULT 0,  -- expense receipts (size not recorded)
+    export_bytes BIGINT NOT NULL DEFAULT 0,
+    storage_bytes BIGINT NOT NULL DEFAULT 0,   -- files whose size is recorded
+    messages_this_month INTEGER NOT NULL DEFAULT 0,
+    messages_by_channel JSONB NOT NULL DEFAULT '{}',
+    row_counts JSONB NOT NULL DEFAULT '{}',
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_organization_usage_storage ON organization_usage(storage_bytes DESC);
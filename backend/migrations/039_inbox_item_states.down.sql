@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_inbox_item_states_assigned_to;
+DROP TABLE IF EXISTS inbox_item_states;
@@ -0,0 +1,20 @@
+-- Priority inbox
+-- Inbox items are computed from the modules on every read; this table only keeps what people
+-- did with them: who an item is assigned to, until when it is snoozed and whether it was dismissed.
+
+CREATE TABLE inbox_item_states (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    item_type VARCHAR(30) NOT NULL CHECK (item_type IN (
+        'session_unconfirmed', 'failed_reminder', 'budget_approval', 'overdue_task', 'unmatched_message'
+    )),
+    item_id UUID NOT NULL,
+    assigned_to UUID REFERENCES users(id) ON DELETE SET NULL,
+    snoozed_until TIMESTAMPTZ,
+    dismissed_at TIMESTAMPTZ,
+    dismissed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (organization_id, item_type, item_id)
+);
+
+CREATE INDEX idx_inbox_item_states_assigned_to ON inbox_item_states(assigned_to) WHERE assigned_to IS NOT NULL;
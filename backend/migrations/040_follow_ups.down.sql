@@ -0,0 +1,10 @@
+DELETE FROM inbox_item_states WHERE item_type = 'follow_up';
+ALTER TABLE inbox_item_states DROP CONSTRAINT inbox_item_states_item_type_check;
+ALTER TABLE inbox_item_states ADD CONSTRAINT inbox_item_states_item_type_check CHECK (item_type IN (
+    'session_unconfirmed', 'failed_reminder', 'budget_approval', 'overdue_task', 'unmatched_message'
+));
+
+DROP INDEX IF EXISTS idx_follow_ups_due;
+DROP INDEX IF EXISTS idx_follow_ups_entity;
+DROP INDEX IF EXISTS idx_follow_ups_user;
+DROP TABLE IF EXISTS follow_ups;
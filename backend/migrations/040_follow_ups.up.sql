@@ -0,0 +1,29 @@
+-- Personal follow-up reminders
+-- Any user can ask to be reminded about a budget, client, session, project or patient on a date.
+-- Once due, the follow-up shows up in the user's inbox and, if asked, is emailed by the worker.
+
+CREATE TABLE follow_ups (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    entity_type VARCHAR(20) NOT NULL CHECK (entity_type IN ('budget', 'client', 'session', 'project', 'patient')),
+    entity_id UUID NOT NULL,
+    remind_at TIMESTAMPTZ NOT NULL,
+    note TEXT,
+    send_email BOOLEAN NOT NULL DEFAULT FALSE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'notified', 'done', 'cancelled')),
+    notified_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_follow_ups_user ON follow_ups(organization_id, user_id, status);
+CREATE INDEX idx_follow_ups_entity ON follow_ups(entity_type, entity_id);
+CREATE INDEX idx_follow_ups_due ON follow_ups(remind_at) WHERE status = 'pending';
+
+-- Due follow-ups are inbox items
+ALTER TABLE inbox_item_states DROP CONSTRAINT inbox_item_states_item_type_check;
+ALTER TABLE inbox_item_states ADD CONSTRAINT inbox_item_states_item_type_check CHECK (item_type IN (
+    'session_unconfirmed', 'failed_reminder', 'budget_approval', 'overdue_task', 'unmatched_message', 'follow_up'
+));
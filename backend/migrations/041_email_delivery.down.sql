@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS idx_email_messages_provider_message_id;
+DROP INDEX IF EXISTS idx_email_messages_session_id;
+DROP INDEX IF EXISTS idx_email_messages_org_id;
+DROP TABLE IF EXISTS email_messages;
+
+ALTER TABLE notification_configs DROP COLUMN IF EXISTS sendgrid_api_key_encrypted;
+ALTER TABLE notification_configs DROP COLUMN IF EXISTS smtp_password_encrypted;
+ALTER TABLE notification_configs DROP COLUMN IF EXISTS smtp_username;
+ALTER TABLE notification_configs DROP COLUMN IF EXISTS smtp_port;
+ALTER TABLE notification_configs DROP COLUMN IF EXISTS smtp_host;
+ALTER TABLE notification_configs DROP COLUMN IF EXISTS email_from_name;
+ALTER TABLE notification_configs DROP COLUMN IF EXISTS email_from_address;
+ALTER TABLE notification_configs DROP COLUMN IF EXISTS email_provider;
+ALTER TABLE notification_configs DROP COLUMN IF EXISTS email_enabled;
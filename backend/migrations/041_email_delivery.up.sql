@@ -0,0 +1,32 @@
+-- Email delivery per organization
+-- Organizations send workflow emails through their own SMTP server or SendGrid account.
+-- Every email sent is logged in email_messages, as WhatsApp messages are in whatsapp_messages.
+
+ALTER TABLE notification_configs ADD COLUMN email_enabled BOOLEAN DEFAULT FALSE;
+ALTER TABLE notification_configs ADD COLUMN email_provider VARCHAR(20) CHECK (email_provider IN ('smtp', 'sendgrid'));
+ALTER TABLE notification_configs ADD COLUMN email_from_address VARCHAR(255);
+ALTER TABLE notification_configs ADD COLUMN email_from_name VARCHAR(100);
+ALTER TABLE notification_configs ADD COLUMN smtp_host VARCHAR(255);
+ALTER TABLE notification_configs ADD COLUMN smtp_port INTEGER CHECK (smtp_port > 0 AND smtp_port < 65536);
+ALTER TABLE notification_configs ADD COLUMN smtp_username VARCHAR(255);
+ALTER TABLE notification_configs ADD COLUMN smtp_password_encrypted VARCHAR(500);  -- Encrypted storage
+ALTER TABLE notification_configs ADD COLUMN sendgrid_api_key_encrypted VARCHAR(500); -- Encrypted storage
+
+CREATE TABLE email_messages (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    session_id UUID REFERENCES sessions(id) ON DELETE SET NULL,
+    to_address VARCHAR(255) NOT NULL,
+    subject TEXT,
+    message_content TEXT,
+    provider VARCHAR(20) NOT NULL CHECK (provider IN ('smtp', 'sendgrid', 'platform')),
+    provider_message_id VARCHAR(255),
+    status VARCHAR(20) NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'sent', 'delivered', 'failed')),
+    error_message TEXT,
+    sent_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_email_messages_org_id ON email_messages(organization_id, created_at DESC);
+CREATE INDEX idx_email_messages_session_id ON email_messages(session_id);
+CREATE INDEX idx_email_messages_provider_message_id ON email_messages(provider_message_id);
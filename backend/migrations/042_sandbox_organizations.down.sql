@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_organizations_sandbox_of;
+
+ALTER TABLE organizations DROP COLUMN IF EXISTS sandbox_of;
@@ -0,0 +1,8 @@
+-- Sandbox organizations
+-- A sandbox is a copy of an organization's workflows, templates and a sample of anonymized data,
+-- linked to the organization it was cloned from. Validated workflows are promoted back through
+-- the workflow import.
+
+ALTER TABLE organizations ADD COLUMN sandbox_of UUID REFERENCES organizations(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_organizations_sandbox_of ON organizations(sandbox_of) WHERE sandbox_of IS NOT NULL;
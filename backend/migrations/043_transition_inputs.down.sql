@@ -0,0 +1 @@
+ALTER TABLE workflow_transitions DROP COLUMN IF EXISTS inputs;
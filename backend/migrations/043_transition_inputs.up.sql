@@ -0,0 +1,5 @@
+-- Transition inputs
+-- Transitions can declare the fields a user must fill in to take them (e.g. a reason category
+-- when cancelling). The submitted values are stored in the execution log with the state change.
+
+ALTER TABLE workflow_transitions ADD COLUMN inputs JSONB NOT NULL DEFAULT '[]';
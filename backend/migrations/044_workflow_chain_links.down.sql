@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_workflow_chain_links_source;
+DROP INDEX IF EXISTS idx_workflow_chain_links_target;
+
+DROP TABLE IF EXISTS workflow_chain_links;
@@ -0,0 +1,19 @@
+-- Workflow chain links
+-- transition_entity actions move an entity related to the one a workflow runs for (e.g. an
+-- approved budget starts its project). Each link is recorded so chains can be traced and loops
+-- between entities stopped.
+
+CREATE TABLE workflow_chain_links (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    action_id UUID REFERENCES workflow_actions(id) ON DELETE SET NULL,
+    source_entity_type VARCHAR(20) NOT NULL,
+    source_entity_id UUID NOT NULL,
+    target_entity_type VARCHAR(20) NOT NULL,
+    target_entity_id UUID NOT NULL,
+    depth INT NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_workflow_chain_links_target ON workflow_chain_links(target_entity_type, target_entity_id);
+CREATE INDEX idx_workflow_chain_links_source ON workflow_chain_links(source_entity_type, source_entity_id);
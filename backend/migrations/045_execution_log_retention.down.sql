@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_workflow_log_org_created;
+DROP INDEX IF EXISTS idx_execution_log_archives_pending;
+DROP INDEX IF EXISTS idx_execution_log_archives_org;
+DROP TABLE IF EXISTS execution_log_archives;
+ALTER TABLE organizations DROP COLUMN IF EXISTS execution_log_retention_days;
@@ -0,0 +1,28 @@
+-- Workflow execution log retention
+-- Each organization keeps its execution log for a number of days (NULL keeps it forever).
+-- Older rows are archived nightly to a compressed JSONL file in object storage and deleted.
+-- System admins can also request an archive, which the worker builds the same way.
+
+ALTER TABLE organizations ADD COLUMN execution_log_retention_days INTEGER DEFAULT 365 CHECK (execution_log_retention_days >= 30);
+
+CREATE TABLE execution_log_archives (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, processing, ready, failed
+    cutoff TIMESTAMPTZ NOT NULL,                   -- rows created before it are archived
+    row_count INTEGER NOT NULL DEFAULT 0,
+    first_entry_at TIMESTAMPTZ,
+    last_entry_at TIMESTAMPTZ,
+    file_url TEXT,
+    file_name VARCHAR(255),
+    file_size BIGINT,
+    error TEXT,
+    requested_by UUID REFERENCES system_admins(id), -- NULL for the nightly archival
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_execution_log_archives_org ON execution_log_archives(organization_id, created_at DESC);
+CREATE INDEX idx_execution_log_archives_pending ON execution_log_archives(created_at) WHERE status IN ('pending', 'processing');
+CREATE INDEX idx_workflow_log_org_created ON workflow_execution_log(organization_id, created_at);
@@ -0,0 +1,17 @@
+DROP TRIGGER IF EXISTS clients_read_model ON clients;
+DROP TRIGGER IF EXISTS patients_read_model ON patients;
+DROP TRIGGER IF EXISTS therapists_read_model ON therapists;
+DROP TRIGGER IF EXISTS sessions_read_model ON sessions;
+
+DROP FUNCTION IF EXISTS clients_read_model_trigger();
+DROP FUNCTION IF EXISTS patients_read_model_trigger();
+DROP FUNCTION IF EXISTS therapists_read_model_trigger();
+DROP FUNCTION IF EXISTS sessions_read_model_trigger();
+DROP FUNCTION IF EXISTS refresh_session_read_model(UUID);
+
+DROP INDEX IF EXISTS idx_session_read_model_status;
+DROP INDEX IF EXISTS idx_session_read_model_patient;
+DROP INDEX IF EXISTS idx_session_read_model_therapist;
+DROP INDEX IF EXISTS idx_session_read_model_org_scheduled;
+
+DROP TABLE IF EXISTS session_read_model;
@@ -0,0 +1,135 @@
+-- Session read model
+-- The calendar and session list read a denormalized copy of each session with its therapist and
+-- patient details, so they no longer join four tables on every request. Patients take their name,
+-- phone and email from their client. The copy is kept up to date by triggers on sessions,
+-- therapists, patients and clients, in the same transaction as the write.
+
+CREATE TABLE session_read_model (
+    session_id UUID PRIMARY KEY REFERENCES sessions(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL,
+    therapist_id UUID NOT NULL,
+    patient_id UUID NOT NULL,
+    scheduled_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    duration_minutes INT NOT NULL,
+    price_cents INT,
+    status VARCHAR(20),
+    session_type VARCHAR(50),
+    notes TEXT,
+    cancel_reason TEXT,
+    cancelled_at TIMESTAMP,
+    cancelled_by UUID,
+    completed_at TIMESTAMP,
+    conflict_override BOOLEAN NOT NULL DEFAULT false,
+    override_reason TEXT,
+    override_status VARCHAR(20),
+    service_id UUID,
+    series_id UUID,
+    created_by UUID,
+    created_at TIMESTAMP,
+    updated_at TIMESTAMP,
+    therapist_name VARCHAR(200) NOT NULL,
+    patient_name VARCHAR(255) NOT NULL,
+    patient_phone VARCHAR(50),
+    patient_email VARCHAR(255),
+    search_text TEXT NOT NULL -- lowercased patient name, phone and email for list search
+);
+
+CREATE INDEX idx_session_read_model_org_scheduled ON session_read_model(organization_id, scheduled_at);
+CREATE INDEX idx_session_read_model_therapist ON session_read_model(organization_id, therapist_id, scheduled_at);
+CREATE INDEX idx_session_read_model_patient ON session_read_model(organization_id, patient_id, scheduled_at);
+CREATE INDEX idx_session_read_model_status ON session_read_model(organization_id, status, scheduled_at);
+
+-- Rebuilds the read model row of one session; deleted sessions are removed
+CREATE OR REPLACE FUNCTION refresh_session_read_model(p_session_id UUID)
+RETURNS void AS $$
+BEGIN
+    DELETE FROM session_read_model WHERE session_id = p_session_id;
+
+    INSERT INTO session_read_model (
+        session_id, organization_id, therapist_id, patient_id, scheduled_at, ends_at, duration_minutes,
+        price_cents, status, session_type, notes, cancel_reason, cancelled_at, cancelled_by, completed_at,
+        conflict_override, override_reason, override_status, service_id, series_id, created_by,
+        created_at, updated_at, therapist_name, patient_name, patient_phone, patient_email, search_text
+    )
+    SELECT s.id, s.organization_id, s.therapist_id, s.patient_id, s.scheduled_at,
+        s.scheduled_at + make_interval(mins => s.duration_minutes), s.duration_minutes,
+        s.price_cents, s.status, s.session_type, s.notes, s.cancel_reason, s.cancelled_at, s.cancelled_by, s.completed_at,
+        COALESCE(s.conflict_override, false), s.override_reason, s.override_status, s.service_id, s.series_id, s.created_by,
+        s.created_at, s.updated_at, t.name, c.name, c.phone, c.email,
+        lower(concat_ws(' ', c.name, c.phone, c.email))
+    FROM sessions s
+    JOIN therapists t ON t.id = s.therapist_id
+    JOIN patients p ON p.id = s.patient_id
+    JOIN clients c ON c.id = p.client_id
+    WHERE s.id = p_session_id AND s.deleted_at IS NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION sessions_read_model_trigger()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM refresh_session_read_model(NEW.id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION therapists_read_model_trigger()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE session_read_model SET therapist_name = NEW.name WHERE therapist_id = NEW.id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- A patient moved to another client takes that client's details
+CREATE OR REPLACE FUNCTION patients_read_model_trigger()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE session_read_model r
+    SET patient_name = c.name, patient_phone = c.phone, patient_email = c.email,
+        search_text = lower(concat_ws(' ', c.name, c.phone, c.email))
+    FROM clients c
+    WHERE r.patient_id = NEW.id AND c.id = NEW.client_id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION clients_read_model_trigger()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE session_read_model
+    SET patient_name = NEW.name, patient_phone = NEW.phone, patient_email = NEW.email,
+        search_text = lower(concat_ws(' ', NEW.name, NEW.phone, NEW.email))
+    WHERE patient_id IN (SELECT id FROM patients WHERE client_id = NEW.id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER sessions_read_model AFTER INSERT OR UPDATE ON sessions
+    FOR EACH ROW EXECUTE FUNCTION sessions_read_model_trigger();
+CREATE TRIGGER therapists_read_model AFTER UPDATE OF name ON therapists
+    FOR EACH ROW EXECUTE FUNCTION therapists_read_model_trigger();
+CREATE TRIGGER patients_read_model AFTER UPDATE OF client_id ON patients
+    FOR EACH ROW EXECUTE FUNCTION patients_read_model_trigger();
+CREATE TRIGGER clients_read_model AFTER UPDATE OF name, phone, email ON clients
+    FOR EACH ROW EXECUTE FUNCTION clients_read_model_trigger();
+
+-- Backfill existing sessions
+INSERT INTO session_read_model (
+    session_id, organization_id, therapist_id, patient_id, scheduled_at, ends_at, duration_minutes,
+    price_cents, status, session_type, notes, cancel_reason, cancelled_at, cancelled_by, completed_at,
+    conflict_override, override_reason, override_status, service_id, series_id, created_by,
+    created_at, updated_at, therapist_name, patient_name, patient_phone, patient_email, search_text
+)
+SELECT s.id, s.organization_id, s.therapist_id, s.patient_id, s.scheduled_at,
+    s.scheduled_at + make_interval(mins => s.duration_minutes), s.duration_minutes,
+    s.price_cents, s.status, s.session_type, s.notes, s.cancel_reason, s.cancelled_at, s.cancelled_by, s.completed_at,
+    COALESCE(s.conflict_override, false), s.override_reason, s.override_status, s.service_id, s.series_id, s.created_by,
+    s.created_at, s.updated_at, t.name, c.name, c.phone, c.email,
+    lower(concat_ws(' ', c.name, c.phone, c.email))
+FROM sessions s
+JOIN therapists t ON t.id = s.therapist_id
+JOIN patients p ON p.id = s.patient_id
+JOIN clients c ON c.id = p.client_id
+WHERE s.deleted_at IS NULL;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_organization_merges_pending;
+DROP INDEX IF EXISTS idx_organization_merges_target;
+DROP INDEX IF EXISTS idx_organization_merges_source;
+DROP TABLE IF EXISTS organization_merges;
@@ -0,0 +1,27 @@
+-- Organization merges
+-- A system admin merges a source organization into a target one (e.g. after a clinic is
+-- acquired). The worker moves users, clients, patients, therapists, workflows, budgets/projects
+-- and sessions step by step; each step commits with its report, so an interrupted merge resumes
+-- from the next step. Dry runs execute every step and roll back, keeping only the report.
+
+CREATE TABLE organization_merges (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    source_organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    target_organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    dry_run BOOLEAN NOT NULL DEFAULT false,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, processing, completed, failed
+    rules JSONB NOT NULL DEFAULT '{}',              -- conflict resolution rules
+    report JSONB NOT NULL DEFAULT '[]',             -- one entry per completed step
+    current_step VARCHAR(30),
+    error TEXT,
+    requested_by UUID REFERENCES system_admins(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    heartbeat_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    CHECK (source_organization_id <> target_organization_id)
+);
+
+CREATE INDEX idx_organization_merges_source ON organization_merges(source_organization_id, created_at DESC);
+CREATE INDEX idx_organization_merges_target ON organization_merges(target_organization_id, created_at DESC);
+CREATE INDEX idx_organization_merges_pending ON organization_merges(created_at) WHERE status IN ('pending', 'processing');
@@ -0,0 +1,6 @@
+-- Embeddable booking availability
+-- Organizations can show their public services and free slots on their own websites through a
+-- read-only, cross-origin endpoint. The listed origins are the websites allowed to embed it;
+-- an empty list allows any website.
+
+ALTER TABLE organizations ADD COLUMN booking_embed_origins TEXT[] NOT NULL DEFAULT '{}';
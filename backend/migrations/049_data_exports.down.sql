@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_data_exports_expires;
+DROP INDEX IF EXISTS idx_data_exports_pending;
+DROP INDEX IF EXISTS idx_data_exports_org;
+DROP TABLE IF EXISTS data_exports;
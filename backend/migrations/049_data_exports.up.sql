@@ -0,0 +1,27 @@
+-- Data exports
+-- Exports of a whole module (clients, patients, sessions, ...) are too large to build within a
+-- request for big organizations. They are queued here and built as CSV by the worker, which
+-- records its progress, uploads the file and removes it once the export expires.
+
+CREATE TABLE data_exports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    entity VARCHAR(30) NOT NULL CHECK (entity IN ('clients', 'projects', 'payments', 'expenses', 'patients', 'sessions', 'session_payments')),
+    filters JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'processing', 'ready', 'failed', 'expired')),
+    total_rows INT,
+    processed_rows INT NOT NULL DEFAULT 0,
+    file_url TEXT,
+    file_name VARCHAR(255),
+    file_size BIGINT,
+    error TEXT,
+    requested_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_data_exports_org ON data_exports(organization_id, created_at DESC);
+CREATE INDEX idx_data_exports_pending ON data_exports(created_at) WHERE status = 'pending';
+CREATE INDEX idx_data_exports_expires ON data_exports(expires_at) WHERE status = 'ready';
@@ -0,0 +1,4 @@
+ALTER TABLE budgets DROP COLUMN IF EXISTS pdf_generated_at;
+ALTER TABLE budgets DROP COLUMN IF EXISTS pdf_url;
+
+DROP TABLE IF EXISTS budget_pdf_templates;
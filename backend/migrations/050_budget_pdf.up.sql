@@ -0,0 +1,23 @@
+-- Budget PDFs
+-- Budgets are rendered to PDF with the organization's branding. Each organization has at most
+-- one template; without it the defaults apply. The last generated file is kept on the budget and
+-- regenerated when the budget or the template changes.
+
+CREATE TABLE budget_pdf_templates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL UNIQUE REFERENCES organizations(id) ON DELETE CASCADE,
+    layout VARCHAR(20) NOT NULL DEFAULT 'classic' CHECK (layout IN ('classic', 'compact')),
+    accent_color VARCHAR(7) NOT NULL DEFAULT '#1F4E79',
+    title VARCHAR(100),          -- replaces "Orçamento" in the header
+    header_text TEXT,            -- printed under the organization details
+    footer_text TEXT,            -- printed at the bottom of every page
+    terms TEXT,                  -- payment and execution conditions
+    show_logo BOOLEAN NOT NULL DEFAULT true,
+    show_item_tax BOOLEAN NOT NULL DEFAULT true,
+    show_signature BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE budgets ADD COLUMN pdf_url TEXT;
+ALTER TABLE budgets ADD COLUMN pdf_generated_at TIMESTAMPTZ;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_budget_portal_events_budget;
+DROP TABLE IF EXISTS budget_portal_events;
+
+DROP INDEX IF EXISTS idx_budgets_portal_token;
+ALTER TABLE budgets DROP COLUMN IF EXISTS portal_viewed_at;
+ALTER TABLE budgets DROP COLUMN IF EXISTS portal_token;
@@ -0,0 +1,22 @@
+-- Client budget portal
+-- Sent budgets get an unguessable portal token. The link built from it lets the client view the
+-- budget and its PDF and approve or reject it without an account. Portal activity is kept as an
+-- audit trail of the client's decision.
+
+ALTER TABLE budgets ADD COLUMN portal_token VARCHAR(64);
+ALTER TABLE budgets ADD COLUMN portal_viewed_at TIMESTAMPTZ; -- first time the client opened the link
+
+CREATE UNIQUE INDEX idx_budgets_portal_token ON budgets(portal_token) WHERE portal_token IS NOT NULL;
+
+CREATE TABLE budget_portal_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    budget_id UUID NOT NULL REFERENCES budgets(id) ON DELETE CASCADE,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('viewed', 'approved', 'rejected')),
+    comment TEXT,
+    ip_address VARCHAR(64),
+    user_agent TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_budget_portal_events_budget ON budget_portal_events(budget_id, created_at DESC);
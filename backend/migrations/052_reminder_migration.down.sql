@@ -0,0 +1,53 @@
+-- Restore the legacy reminders for every organization
+CREATE OR REPLACE FUNCTION create_session_reminders()
+RETURNS TRIGGER AS $$
+BEGIN
+    -- Only create reminders for pending or confirmed sessions
+    IF NEW.status IN ('pending', 'confirmed') AND NEW.scheduled_at > NOW() THEN
+        -- Check if organization has notifications enabled
+        IF EXISTS (
+            SELECT 1 FROM notification_configs nc
+            JOIN organization_modules om ON om.organization_id = nc.organization_id
+            WHERE nc.organization_id = NEW.organization_id
+                AND nc.whatsapp_enabled = TRUE
+                AND om.module_name = 'notifications'
+                AND om.is_enabled = TRUE
+        ) THEN
+            -- Create 24h reminder (only if session is more than 24h away)
+            IF NEW.scheduled_at > NOW() + interval '24 hours' THEN
+                INSERT INTO scheduled_reminders (session_id, type, scheduled_for)
+                VALUES (NEW.id, 'reminder_24h', NEW.scheduled_at - interval '24 hours')
+                ON CONFLICT (session_id, type) DO UPDATE
+                SET scheduled_for = NEW.scheduled_at - interval '24 hours',
+                    status = 'pending',
+                    processed_at = NULL,
+                    error_message = NULL;
+            END IF;
+
+            -- Create 2h reminder (only if session is more than 2h away)
+            IF NEW.scheduled_at > NOW() + interval '2 hours' THEN
+                INSERT INTO scheduled_reminders (session_id, type, scheduled_for)
+                VALUES (NEW.id, 'reminder_2h', NEW.scheduled_at - interval '2 hours')
+                ON CONFLICT (session_id, type) DO UPDATE
+                SET scheduled_for = NEW.scheduled_at - interval '2 hours',
+                    status = 'pending',
+                    processed_at = NULL,
+                    error_message = NULL;
+            END IF;
+        END IF;
+    ELSE
+        -- Cancel pending reminders for cancelled/completed sessions
+        UPDATE scheduled_reminders
+        SET status = 'skipped'
+        WHERE session_id = NEW.id AND status = 'pending';
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMENT ON TABLE scheduled_reminders IS NULL;
+
+ALTER TABLE notification_configs
+    DROP COLUMN IF EXISTS reminders_workflow_id,
+    DROP COLUMN IF EXISTS reminders_migrated_at;
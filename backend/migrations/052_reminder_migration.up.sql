@@ -0,0 +1,59 @@
+-- Legacy reminder migration
+-- The 24h/2h reminders of notification_configs are replaced by time_before triggers of the
+-- session workflow. Once an organization is migrated, sessions no longer get scheduled_reminders
+-- rows; the table is kept for organizations that were not migrated yet and will be dropped
+-- when none are left.
+
+ALTER TABLE notification_configs
+    ADD COLUMN reminders_migrated_at TIMESTAMPTZ,
+    ADD COLUMN reminders_workflow_id UUID REFERENCES workflows(id) ON DELETE SET NULL;
+
+COMMENT ON TABLE scheduled_reminders IS 'Deprecated: reminders of organizations that were not migrated to workflows yet';
+
+CREATE OR REPLACE FUNCTION create_session_reminders()
+RETURNS TRIGGER AS $$
+BEGIN
+    -- Only create reminders for pending or confirmed sessions
+    IF NEW.status IN ('pending', 'confirmed') AND NEW.scheduled_at > NOW() THEN
+        -- Check if organization has notifications enabled and still uses the legacy reminders
+        IF EXISTS (
+            SELECT 1 FROM notification_configs nc
+            JOIN organization_modules om ON om.organization_id = nc.organization_id
+            WHERE nc.organization_id = NEW.organization_id
+                AND nc.whatsapp_enabled = TRUE
+                AND nc.reminders_migrated_at IS NULL
+                AND om.module_name = 'notifications'
+                AND om.is_enabled = TRUE
+        ) THEN
+            -- Create 24h reminder (only if session is more than 24h away)
+            IF NEW.scheduled_at > NOW() + interval '24 hours' THEN
+                INSERT INTO scheduled_reminders (session_id, type, scheduled_for)
+                VALUES (NEW.id, 'reminder_24h', NEW.scheduled_at - interval '24 hours')
+                ON CONFLICT (session_id, type) DO UPDATE
+                SET scheduled_for = NEW.scheduled_at - interval '24 hours',
+                    status = 'pending',
+                    processed_at = NULL,
+                    error_message = NULL;
+            END IF;
+
+            -- Create 2h reminder (only if session is more than 2h away)
+            IF NEW.scheduled_at > NOW() + interval '2 hours' THEN
+                INSERT INTO scheduled_reminders (session_id, type, scheduled_for)
+                VALUES (NEW.id, 'reminder_2h', NEW.scheduled_at - interval '2 hours')
+                ON CONFLICT (session_id, type) DO UPDATE
+                SET scheduled_for = NEW.scheduled_at - interval '2 hours',
+                    status = 'pending',
+                    processed_at = NULL,
+                    error_message = NULL;
+            END IF;
+        END IF;
+    ELSE
+        -- Cancel pending reminders for cancelled/completed sessions
+        UPDATE scheduled_reminders
+        SET status = 'skipped'
+        WHERE session_id = NEW.id AND status = 'pending';
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,31 @@
+-- Session read model without the project link
+-- Rebuilds the read model row of one session; deleted sessions are removed
+CREATE OR REPLACE FUNCTION refresh_session_read_model(p_session_id UUID)
+RETURNS void AS $$
+BEGIN
+    DELETE FROM session_read_model WHERE session_id = p_session_id;
+
+    INSERT INTO session_read_model (
+        session_id, organization_id, therapist_id, patient_id, scheduled_at, ends_at, duration_minutes,
+        price_cents, status, session_type, notes, cancel_reason, cancelled_at, cancelled_by, completed_at,
+        conflict_override, override_reason, override_status, service_id, series_id, created_by,
+        created_at, updated_at, therapist_name, patient_name, patient_phone, patient_email, search_text
+    )
+    SELECT s.id, s.organization_id, s.therapist_id, s.patient_id, s.scheduled_at,
+        s.scheduled_at + make_interval(mins => s.duration_minutes), s.duration_minutes,
+        s.price_cents, s.status, s.session_type, s.notes, s.cancel_reason, s.cancelled_at, s.cancelled_by, s.completed_at,
+        COALESCE(s.conflict_override, false), s.override_reason, s.override_status, s.service_id, s.series_id, s.created_by,
+        s.created_at, s.updated_at, t.name, c.name, c.phone, c.email,
+        lower(concat_ws(' ', c.name, c.phone, c.email))
+    FROM sessions s
+    JOIN therapists t ON t.id = s.therapist_id
+    JOIN patients p ON p.id = s.patient_id
+    JOIN clients c ON c.id = p.client_id
+    WHERE s.id = p_session_id AND s.deleted_at IS NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE session_read_model DROP COLUMN IF EXISTS project_id;
+
+DROP INDEX IF EXISTS idx_sessions_project_id;
+ALTER TABLE sessions DROP COLUMN IF EXISTS project_id;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_budget_revisions_budget;
+DROP TABLE IF EXISTS budget_revisions;
+
+ALTER TABLE budgets DROP COLUMN IF EXISTS current_revision;
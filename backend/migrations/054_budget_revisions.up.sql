@@ -0,0 +1,24 @@
+-- Budget revisions
+-- Every time a budget is sent its items and totals are frozen as a revision. Sending a budget
+-- again after it was changed creates the next revision, so the versions the client received can
+-- be listed and compared.
+
+ALTER TABLE budgets ADD COLUMN current_revision INT NOT NULL DEFAULT 0;
+
+CREATE TABLE budget_revisions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    budget_id UUID NOT NULL REFERENCES budgets(id) ON DELETE CASCADE,
+    revision_number INT NOT NULL,
+    subtotal DECIMAL(12, 2) NOT NULL,
+    tax DECIMAL(12, 2) NOT NULL,
+    total DECIMAL(12, 2) NOT NULL,
+    valid_until DATE NOT NULL,
+    notes TEXT,
+    items JSONB NOT NULL DEFAULT '[]', -- budget items as they were sent
+    sent_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(budget_id, revision_number)
+);
+
+CREATE INDEX idx_budget_revisions_budget ON budget_revisions(budget_id, revision_number DESC);
@@ -0,0 +1,15 @@
+DROP TRIGGER IF EXISTS payments_state_history ON payments;
+DROP TRIGGER IF EXISTS session_payments_state_history ON session_payments;
+DROP TRIGGER IF EXISTS sessions_state_history ON sessions;
+
+DROP FUNCTION IF EXISTS payments_state_history_trigger();
+DROP FUNCTION IF EXISTS session_payments_state_history_trigger();
+DROP FUNCTION IF EXISTS sessions_state_history_trigger();
+DROP FUNCTION IF EXISTS payment_state(payments);
+DROP FUNCTION IF EXISTS session_payment_state(session_payments);
+DROP FUNCTION IF EXISTS session_state(sessions);
+DROP FUNCTION IF EXISTS record_entity_state(UUID, TEXT, UUID, JSONB);
+
+DROP INDEX IF EXISTS idx_entity_state_history_current;
+DROP INDEX IF EXISTS idx_entity_state_history_as_of;
+DROP TABLE IF EXISTS entity_state_history;
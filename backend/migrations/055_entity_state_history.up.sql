@@ -0,0 +1,138 @@
+-- Entity state history
+-- Reports can be run as of a past date ("what did receivables look like on March 31") even after
+-- later edits. Each row is the state of a session, session payment or project payment during
+-- [valid_from, valid_to). Triggers record every change in the same transaction as the write, so
+-- the history is complete whichever service changed the row.
+
+CREATE TABLE entity_state_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    entity_type VARCHAR(30) NOT NULL CHECK (entity_type IN ('session', 'session_payment', 'payment')),
+    entity_id UUID NOT NULL,
+    state JSONB NOT NULL, -- the reported columns of the row
+    valid_from TIMESTAMPTZ NOT NULL,
+    valid_to TIMESTAMPTZ -- NULL while current; set when the row changes or is deleted
+);
+
+CREATE INDEX idx_entity_state_history_as_of ON entity_state_history(organization_id, entity_type, valid_from);
+CREATE UNIQUE INDEX idx_entity_state_history_current ON entity_state_history(entity_type, entity_id) WHERE valid_to IS NULL;
+
+-- Closes the current state of an entity and opens p_state, unless it did not change. A NULL
+-- p_state (deleted row) only closes it.
+CREATE OR REPLACE FUNCTION record_entity_state(p_org UUID, p_type TEXT, p_id UUID, p_state JSONB)
+RETURNS void AS $$
+BEGIN
+    IF p_state IS NOT NULL AND EXISTS (
+        SELECT 1 FROM entity_state_history
+        WHERE entity_type = p_type AND entity_id = p_id AND valid_to IS NULL AND state = p_state
+    ) THEN
+        RETURN;
+    END IF;
+
+    UPDATE entity_state_history SET valid_to = NOW()
+    WHERE entity_type = p_type AND entity_id = p_id AND valid_to IS NULL;
+
+    IF p_state IS NOT NULL THEN
+        INSERT INTO entity_state_history (organization_id, entity_type, entity_id, state, valid_from)
+        VALUES (p_org, p_type, p_id, p_state, NOW());
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION session_state(s sessions)
+RETURNS JSONB AS $$
+    SELECT jsonb_build_object(
+        'status', s.status, 'scheduled_at', s.scheduled_at, 'therapist_id', s.therapist_id,
+        'patient_id', s.patient_id, 'price_cents', s.price_cents
+    );
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION session_payment_state(sp session_payments)
+RETURNS JSONB AS $$
+    SELECT jsonb_build_object(
+        'session_id', sp.session_id, 'payment_status', sp.payment_status,
+        'amount_cents', sp.amount_cents, 'due_date', sp.due_date
+    );
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION payment_state(p payments)
+RETURNS JSONB AS $$
+    SELECT jsonb_build_object(
+        'project_id', p.project_id, 'status', p.status, 'amount', p.amount, 'due_date', p.due_date
+    );
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION sessions_state_history_trigger()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM record_entity_state(NEW.organization_id, 'session', NEW.id,
+        CASE WHEN NEW.deleted_at IS NULL THEN session_state(NEW) END);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION session_payments_state_history_trigger()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_org UUID;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        UPDATE entity_state_history SET valid_to = NOW()
+        WHERE entity_type = 'session_payment' AND entity_id = OLD.id AND valid_to IS NULL;
+        RETURN NULL;
+    END IF;
+
+    SELECT organization_id INTO v_org FROM sessions WHERE id = NEW.session_id;
+    PERFORM record_entity_state(v_org, 'session_payment', NEW.id, session_payment_state(NEW));
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION payments_state_history_trigger()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM record_entity_state(NEW.organization_id, 'payment', NEW.id,
+        CASE WHEN NEW.deleted_at IS NULL THEN payment_state(NEW) END);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER sessions_state_history AFTER INSERT OR UPDATE ON sessions
+    FOR EACH ROW EXECUTE FUNCTION sessions_state_history_trigger();
+CREATE TRIGGER session_payments_state_history AFTER INSERT OR UPDATE OR DELETE ON session_payments
+    FOR EACH ROW EXECUTE FUNCTION session_payments_state_history_trigger();
+CREATE TRIGGER payments_state_history AFTER INSERT OR UPDATE ON payments
+    FOR EACH ROW EXECUTE FUNCTION payments_state_history_trigger();
+
+-- Backfill the current state from the row's creation. Paid payments are split at paid_at into
+-- their unpaid and paid states; other earlier changes were not recorded.
+INSERT INTO entity_state_history (organization_id, entity_type, entity_id, state, valid_from)
+SELECT s.organization_id, 'session', s.id, session_state(s), COALESCE(s.created_at, NOW())
+FROM sessions s
+WHERE s.deleted_at IS NULL;
+
+INSERT INTO entity_state_history (organization_id, entity_type, entity_id, state, valid_from, valid_to)
+SELECT s.organization_id, 'session_payment', sp.id,
+    session_payment_state(sp) || jsonb_build_object('payment_status', 'unpaid'),
+    COALESCE(sp.created_at, sp.paid_at), sp.paid_at
+FROM session_payments sp
+JOIN sessions s ON s.id = sp.session_id
+WHERE sp.payment_status = 'paid' AND sp.paid_at IS NOT NULL AND sp.paid_at > COALESCE(sp.created_at, sp.paid_at);
+
+INSERT INTO entity_state_history (organization_id, entity_type, entity_id, state, valid_from)
+SELECT s.organization_id, 'session_payment', sp.id, session_payment_state(sp),
+    CASE WHEN sp.payment_status = 'paid' AND sp.paid_at IS NOT NULL THEN sp.paid_at ELSE COALESCE(sp.created_at, NOW()) END
+FROM session_payments sp
+JOIN sessions s ON s.id = sp.session_id;
+
+INSERT INTO entity_state_history (organization_id, entity_type, entity_id, state, valid_from, valid_to)
+SELECT p.organization_id, 'payment', p.id, payment_state(p) || jsonb_build_object('status', 'pending'),
+    COALESCE(p.created_at, p.paid_at), p.paid_at
+FROM payments p
+WHERE p.deleted_at IS NULL AND p.status = 'paid' AND p.paid_at IS NOT NULL AND p.paid_at > COALESCE(p.created_at, p.paid_at);
+
+INSERT INTO entity_state_history (organization_id, entity_type, entity_id, state, valid_from)
+SELECT p.organization_id, 'payment', p.id, payment_state(p),
+    CASE WHEN p.status = 'paid' AND p.paid_at IS NOT NULL THEN p.paid_at ELSE COALESCE(p.created_at, NOW()) END
+FROM payments p
+WHERE p.deleted_at IS NULL;
@@ -0,0 +1,2 @@
+-- Client numbers stay in E.164, which the previous code also accepts
+ALTER TABLE organizations DROP COLUMN IF EXISTS default_country;
@@ -0,0 +1,18 @@
+-- Phone normalization
+-- Phone numbers are stored in E.164 (+351912345678). Numbers typed without a country code are
+-- read with the organization's default country. Existing client numbers in the usual Portuguese
+-- forms are converted; others are left as typed and converted the next time they are saved.
+
+ALTER TABLE organizations ADD COLUMN default_country VARCHAR(2) NOT NULL DEFAULT 'PT';
+
+UPDATE clients SET phone = '+351' || regexp_replace(phone, '[\s\-.]', '', 'g')
+WHERE regexp_replace(phone, '[\s\-.]', '', 'g') ~ '^[29][0-9]{8}$';
+
+UPDATE clients SET phone = '+' || regexp_replace(phone, '[\s\-.]', '', 'g')
+WHERE regexp_replace(phone, '[\s\-.]', '', 'g') ~ '^351[0-9]{9}$';
+
+UPDATE clients SET phone = '+' || substr(regexp_replace(phone, '[\s\-.]', '', 'g'), 3)
+WHERE regexp_replace(phone, '[\s\-.]', '', 'g') ~ '^00[1-9][0-9]{7,14}$';
+
+UPDATE clients SET phone = regexp_replace(phone, '[\s\-.]', '', 'g')
+WHERE phone ~ '^\+' AND regexp_replace(phone, '[\s\-.]', '', 'g') ~ '^\+[1-9][0-9]{7,14}$';
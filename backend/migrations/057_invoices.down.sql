@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_invoice_lines_invoice;
+DROP TABLE IF EXISTS invoice_lines;
+
+DROP TRIGGER IF EXISTS update_invoices_updated_at ON invoices;
+DROP INDEX IF EXISTS idx_invoices_credited;
+DROP INDEX IF EXISTS idx_invoices_source;
+DROP INDEX IF EXISTS idx_invoices_client;
+DROP INDEX IF EXISTS idx_invoices_org_status;
+DROP TABLE IF EXISTS invoices;
+DROP TABLE IF EXISTS invoice_sequences;
+
+DELETE FROM organization_modules WHERE module_name = 'invoices';
+DELETE FROM available_modules WHERE name = 'invoices';
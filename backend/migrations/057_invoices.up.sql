@@ -0,0 +1,82 @@
+-- Invoices
+-- Invoices and credit notes issued from session payments or project payments. Drafts get their
+-- legal number when they are issued, from a sequence per organization, document type and year,
+-- so issued documents are numbered without gaps (FT 2026/1, FT 2026/2, ..., NC 2026/1).
+
+INSERT INTO available_modules (name, display_name, description, icon, dependencies, pricing_tier) VALUES
+('invoices', 'Faturacao', 'Faturas e notas de credito com numeracao sequencial, IVA e PDF', 'Receipt', '["appointments|construction"]', 'standard');
+
+CREATE TABLE invoice_sequences (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    document_type VARCHAR(20) NOT NULL,
+    year INT NOT NULL,
+    last_number INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (organization_id, document_type, year)
+);
+
+CREATE TABLE invoices (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    document_type VARCHAR(20) NOT NULL DEFAULT 'invoice' CHECK (document_type IN ('invoice', 'credit_note')),
+    status VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'issued', 'paid', 'void')),
+    -- Legal number, set when issued
+    series VARCHAR(10) NOT NULL,
+    year INT,
+    number INT,
+    invoice_number VARCHAR(30),
+    -- What is invoiced; credit notes point to the invoice they credit
+    source_type VARCHAR(20) CHECK (source_type IN ('session_payment', 'payment')),
+    source_id UUID,
+    credited_invoice_id UUID REFERENCES invoices(id),
+    -- The customer as printed on the document
+    client_id UUID REFERENCES clients(id) ON DELETE SET NULL,
+    customer_name VARCHAR(255) NOT NULL,
+    customer_tax_id VARCHAR(50),
+    customer_address TEXT,
+    customer_email VARCHAR(255),
+    issue_date DATE,
+    due_date DATE,
+    subtotal DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    vat_total DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    total DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    notes TEXT,
+    void_reason TEXT,
+    issued_at TIMESTAMPTZ,
+    paid_at TIMESTAMPTZ,
+    voided_at TIMESTAMPTZ,
+    pdf_url TEXT,
+    pdf_generated_at TIMESTAMPTZ,
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ,
+    UNIQUE(organization_id, series, year, number)
+);
+
+CREATE INDEX idx_invoices_org_status ON invoices(organization_id, status) WHERE deleted_at IS NULL;
+CREATE INDEX idx_invoices_client ON invoices(client_id) WHERE deleted_at IS NULL;
+-- A payment is invoiced once, and an invoice credited once, unless the document was voided or deleted
+CREATE UNIQUE INDEX idx_invoices_source ON invoices(source_type, source_id)
+    WHERE document_type = 'invoice' AND status <> 'void' AND deleted_at IS NULL;
+CREATE UNIQUE INDEX idx_invoices_credited ON invoices(credited_invoice_id)
+    WHERE status <> 'void' AND deleted_at IS NULL;
+
+CREATE TRIGGER update_invoices_updated_at
+    BEFORE UPDATE ON invoices
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE invoice_lines (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    invoice_id UUID NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    description TEXT NOT NULL,
+    quantity DECIMAL(12, 2) NOT NULL DEFAULT 1,
+    unit_price DECIMAL(12, 2) NOT NULL, -- without VAT
+    vat_rate DECIMAL(5, 2) NOT NULL,    -- percentage, e.g. 23.00
+    vat_exemption_reason VARCHAR(255),  -- required when the rate is 0
+    net DECIMAL(12, 2) NOT NULL,
+    vat DECIMAL(12, 2) NOT NULL,
+    total DECIMAL(12, 2) NOT NULL
+);
+
+CREATE INDEX idx_invoice_lines_invoice ON invoice_lines(invoice_id, position);
@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS update_payment_links_updated_at ON payment_links;
+DROP INDEX IF EXISTS idx_payment_links_open;
+DROP INDEX IF EXISTS idx_payment_links_target;
+DROP INDEX IF EXISTS idx_payment_links_provider_reference;
+DROP TABLE IF EXISTS payment_links;
+
+DROP INDEX IF EXISTS idx_payment_provider_configs_webhook_token;
+DROP TABLE IF EXISTS payment_provider_configs;
@@ -0,0 +1,48 @@
+-- Online payment links
+-- Organizations connect their own Stripe account and send Checkout links for unpaid session
+-- payments, project payments and approved budgets. Stripe reports completed checkouts to
+-- /webhooks/stripe/{token}, which marks the payment as paid.
+
+CREATE TABLE payment_provider_configs (
+    organization_id UUID PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+    provider VARCHAR(20) NOT NULL DEFAULT 'stripe' CHECK (provider IN ('stripe')),
+    is_enabled BOOLEAN NOT NULL DEFAULT false,
+    secret_key_encrypted TEXT,
+    webhook_secret_encrypted TEXT,
+    currency VARCHAR(3) NOT NULL DEFAULT 'eur',
+    webhook_token VARCHAR(64) NOT NULL
+        DEFAULT replace(gen_random_uuid()::text, '-', '') || replace(gen_random_uuid()::text, '-', ''),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_payment_provider_configs_webhook_token ON payment_provider_configs(webhook_token);
+
+CREATE TABLE payment_links (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    provider VARCHAR(20) NOT NULL DEFAULT 'stripe',
+    target_type VARCHAR(20) NOT NULL CHECK (target_type IN ('session_payment', 'payment', 'budget')),
+    target_id UUID NOT NULL,
+    provider_reference VARCHAR(255) NOT NULL, -- Stripe Checkout Session ID
+    url TEXT NOT NULL,
+    amount DECIMAL(12, 2) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'paid', 'expired', 'cancelled')),
+    expires_at TIMESTAMPTZ NOT NULL,
+    paid_at TIMESTAMPTZ,
+    -- Payment recorded for a paid budget link, on the project built from the budget
+    payment_id UUID REFERENCES payments(id) ON DELETE SET NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_payment_links_provider_reference ON payment_links(provider, provider_reference);
+CREATE INDEX idx_payment_links_target ON payment_links(organization_id, target_type, target_id, status);
+-- A target has at most one open link, reused by every message that includes it
+CREATE UNIQUE INDEX idx_payment_links_open ON payment_links(target_type, target_id) WHERE status = 'open';
+
+CREATE TRIGGER update_payment_links_updated_at
+    BEFORE UPDATE ON payment_links
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
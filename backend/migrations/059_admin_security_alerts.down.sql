@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_admin_security_alerts_audit_logs;
+DROP INDEX IF EXISTS idx_admin_security_alerts_created;
+DROP INDEX IF EXISTS idx_admin_security_alerts_admin;
+DROP TABLE IF EXISTS admin_security_alerts;
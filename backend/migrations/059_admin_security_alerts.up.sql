@@ -0,0 +1,27 @@
+-- Admin security alerts
+-- A worker job scans the system admin audit log for unusual behavior: many exports or
+-- deletions in a short time, and impersonation outside business hours. Each finding is an
+-- alert sent to the other system admins; the flagged admin has to justify it with a note
+-- before performing further sensitive actions.
+
+CREATE TABLE admin_security_alerts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    admin_id UUID NOT NULL REFERENCES system_admins(id),
+    rule VARCHAR(40) NOT NULL CHECK (rule IN ('mass_export', 'off_hours_impersonation', 'bulk_deletion')),
+    severity VARCHAR(20) NOT NULL CHECK (severity IN ('medium', 'high')),
+    details JSONB NOT NULL DEFAULT '{}',
+    -- The flagged audit log entries; an entry is flagged at most once per rule
+    audit_log_ids UUID[] NOT NULL,
+    window_start TIMESTAMPTZ NOT NULL,
+    window_end TIMESTAMPTZ NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'justified', 'dismissed')),
+    justification TEXT,
+    justified_at TIMESTAMPTZ,
+    reviewed_by UUID REFERENCES system_admins(id),
+    reviewed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_admin_security_alerts_admin ON admin_security_alerts(admin_id, status);
+CREATE INDEX idx_admin_security_alerts_created ON admin_security_alerts(created_at DESC);
+CREATE INDEX idx_admin_security_alerts_audit_logs ON admin_security_alerts USING GIN (audit_log_ids);
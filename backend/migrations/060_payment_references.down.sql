@@ -0,0 +1,19 @@
+ALTER TABLE session_payments
+    DROP COLUMN IF EXISTS sepa_reference,
+    DROP COLUMN IF EXISTS mb_reference,
+    DROP COLUMN IF EXISTS mb_entity;
+
+ALTER TABLE payments
+    DROP COLUMN IF EXISTS sepa_reference,
+    DROP COLUMN IF EXISTS mb_reference,
+    DROP COLUMN IF EXISTS mb_entity;
+
+DROP TRIGGER IF EXISTS update_payment_references_updated_at ON payment_references;
+DROP INDEX IF EXISTS idx_payment_references_open;
+DROP INDEX IF EXISTS idx_payment_references_provider_reference;
+DROP INDEX IF EXISTS idx_payment_references_mb;
+DROP INDEX IF EXISTS idx_payment_references_target;
+DROP TABLE IF EXISTS payment_references;
+
+DROP INDEX IF EXISTS idx_payment_reference_configs_callback_token;
+DROP TABLE IF EXISTS payment_reference_configs;
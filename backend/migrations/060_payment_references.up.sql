@@ -0,0 +1,67 @@
+-- Multibanco and SEPA payment references
+-- Organizations connect an IfThenPay or Easypay account and generate Multibanco references
+-- (entity + reference) for unpaid session payments and project payments, together with an
+-- RF creditor reference for SEPA transfers to the organization's IBAN. The current references
+-- are stored on the payment; the provider reports payments to /webhooks/{provider}/{token},
+-- which marks the payment as paid. SEPA transfers are matched from bank statements.
+
+CREATE TABLE payment_reference_configs (
+    organization_id UUID PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+    provider VARCHAR(20) NOT NULL CHECK (provider IN ('ifthenpay', 'easypay')),
+    is_enabled BOOLEAN NOT NULL DEFAULT false,
+    -- IfThenPay: references are computed from the entity and sub-entity of the account
+    mb_entity VARCHAR(5),
+    mb_subentity VARCHAR(3),
+    -- Easypay: references are requested through the API
+    account_id VARCHAR(100),
+    api_key_encrypted TEXT,
+    -- IfThenPay anti-phishing key, sent back on every payment callback
+    callback_key_encrypted TEXT,
+    iban VARCHAR(34),
+    validity_days INT NOT NULL DEFAULT 30 CHECK (validity_days BETWEEN 1 AND 365),
+    next_order_id BIGINT NOT NULL DEFAULT 1,
+    callback_token VARCHAR(64) NOT NULL
+        DEFAULT replace(gen_random_uuid()::text, '-', '') || replace(gen_random_uuid()::text, '-', ''),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_payment_reference_configs_callback_token ON payment_reference_configs(callback_token);
+
+CREATE TABLE payment_references (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    provider VARCHAR(20) NOT NULL,
+    target_type VARCHAR(20) NOT NULL CHECK (target_type IN ('session_payment', 'payment')),
+    target_id UUID NOT NULL,
+    mb_entity VARCHAR(5),
+    mb_reference VARCHAR(9),
+    sepa_reference VARCHAR(25),
+    provider_reference VARCHAR(255), -- Easypay payment ID
+    amount DECIMAL(12, 2) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'paid', 'cancelled')),
+    expires_at TIMESTAMPTZ NOT NULL,
+    paid_at TIMESTAMPTZ,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_payment_references_target ON payment_references(target_type, target_id);
+CREATE INDEX idx_payment_references_mb ON payment_references(organization_id, mb_entity, mb_reference);
+CREATE INDEX idx_payment_references_provider_reference ON payment_references(provider, provider_reference);
+CREATE UNIQUE INDEX idx_payment_references_open ON payment_references(target_type, target_id) WHERE status = 'open';
+
+CREATE TRIGGER update_payment_references_updated_at
+    BEFORE UPDATE ON payment_references
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE payments
+    ADD COLUMN mb_entity VARCHAR(5),
+    ADD COLUMN mb_reference VARCHAR(9),
+    ADD COLUMN sepa_reference VARCHAR(25);
+
+ALTER TABLE session_payments
+    ADD COLUMN mb_entity VARCHAR(5),
+    ADD COLUMN mb_reference VARCHAR(9),
+    ADD COLUMN sepa_reference VARCHAR(25);
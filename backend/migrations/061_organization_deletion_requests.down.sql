@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS organization_deletion_events;
+DROP TRIGGER IF EXISTS update_organization_deletion_requests_updated_at ON organization_deletion_requests;
+DROP TABLE IF EXISTS organization_deletion_requests;
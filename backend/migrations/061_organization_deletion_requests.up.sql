@@ -0,0 +1,53 @@
+-- Organization deletion requests
+-- An organization admin requests the deletion of the organization. After a cooling-off period,
+-- during which the admins are reminded and can cancel, the worker exports the organization's
+-- data, suspends it and, once the export has been available for a while, purges it. Requests
+-- and their events have no foreign keys to the organization or its users so they outlive the
+-- purge as its audit trail.
+
+CREATE TABLE organization_deletion_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL,
+    organization_name VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, cancelled, exporting, exported, purging, completed, failed
+    reason TEXT,
+    requested_by UUID,                             -- organization user
+    requested_by_email VARCHAR(255) NOT NULL,
+    cooling_off_ends_at TIMESTAMPTZ NOT NULL,
+    reminders_sent INTEGER NOT NULL DEFAULT 0,
+    cancelled_by UUID,
+    cancelled_at TIMESTAMPTZ,
+    export_file_url TEXT,
+    export_file_name VARCHAR(255),
+    export_file_size BIGINT,
+    exported_at TIMESTAMPTZ,
+    purge_after TIMESTAMPTZ,
+    purge_report JSONB,                            -- rows deleted per table and files removed
+    error TEXT,
+    heartbeat_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_organization_deletion_requests_active ON organization_deletion_requests(organization_id)
+    WHERE status NOT IN ('cancelled', 'completed');
+CREATE INDEX idx_organization_deletion_requests_org ON organization_deletion_requests(organization_id, created_at DESC);
+CREATE INDEX idx_organization_deletion_requests_due ON organization_deletion_requests(status, cooling_off_ends_at)
+    WHERE status IN ('pending', 'exporting', 'exported', 'purging');
+
+CREATE TRIGGER update_organization_deletion_requests_updated_at BEFORE UPDATE ON organization_deletion_requests
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Every step of a deletion request, by the organization user, system admin or worker behind it
+CREATE TABLE organization_deletion_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    request_id UUID NOT NULL REFERENCES organization_deletion_requests(id) ON DELETE CASCADE,
+    event VARCHAR(30) NOT NULL, -- requested, reminder_sent, cancelled, export_started, exported, purge_started, purged, failed, retried
+    user_id UUID,
+    admin_id UUID,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_organization_deletion_events_request ON organization_deletion_events(request_id, created_at);
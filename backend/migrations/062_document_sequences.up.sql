@@ -0,0 +1,15 @@
+-- Document sequences
+-- Project and budget numbers (PRJ-2026-001, ORC-2026-001) were counted from the existing rows,
+-- so two documents created at the same time could get the same number. Each organization now
+-- has a counter per document type, prefix and year, locked while a number is being allocated.
+-- Counters are created on first use from the highest number already in use.
+
+CREATE TABLE document_sequences (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    document_type VARCHAR(20) NOT NULL CHECK (document_type IN ('project', 'budget')),
+    prefix VARCHAR(50) NOT NULL,
+    year INT NOT NULL,
+    last_number INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (organization_id, document_type, prefix, year)
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS patient_portal_login_tokens;
+DROP TRIGGER IF EXISTS update_patient_portal_settings_updated_at ON patient_portal_settings;
+DROP TABLE IF EXISTS patient_portal_settings;
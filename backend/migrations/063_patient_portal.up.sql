@@ -0,0 +1,34 @@
+-- Patient portal
+-- Patients sign in with a one-time link sent to their email and get a token for the patient
+-- portal only, with which they see their own sessions and outstanding payments, confirm or
+-- cancel upcoming sessions up to the organization's cutoff, and update their contact details.
+
+CREATE TABLE patient_portal_settings (
+    organization_id UUID PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+    is_enabled BOOLEAN NOT NULL DEFAULT false,
+    -- Patients can cancel up to this many hours before a session starts
+    cancellation_cutoff_hours INT NOT NULL DEFAULT 24 CHECK (cancellation_cutoff_hours BETWEEN 0 AND 720),
+    allow_confirmation BOOLEAN NOT NULL DEFAULT true,
+    allow_cancellation BOOLEAN NOT NULL DEFAULT true,
+    allow_contact_update BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER update_patient_portal_settings_updated_at
+    BEFORE UPDATE ON patient_portal_settings
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- One-time sign-in links; only the hash of the token is kept
+CREATE TABLE patient_portal_login_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    patient_id UUID NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    ip_address VARCHAR(45),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_patient_portal_login_tokens_patient ON patient_portal_login_tokens(patient_id, created_at);
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS update_chat_webhooks_updated_at ON chat_webhooks;
+DROP TABLE IF EXISTS chat_webhooks;
@@ -0,0 +1,26 @@
+-- Chat webhooks
+-- Slack and Microsoft Teams incoming webhooks of an organization's team channels. Workflows post
+-- to them with send_chat actions, and channels can also be alerted when a workflow action fails
+-- for good. Webhook URLs carry their own credentials, so they are stored encrypted.
+
+CREATE TABLE chat_webhooks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    provider VARCHAR(20) NOT NULL CHECK (provider IN ('slack', 'teams')),
+    name VARCHAR(100) NOT NULL,
+    webhook_url_encrypted TEXT NOT NULL,
+    webhook_url_hint VARCHAR(100) NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    notify_action_failures BOOLEAN NOT NULL DEFAULT false,
+    last_sent_at TIMESTAMP,
+    last_error TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_chat_webhooks_org ON chat_webhooks(organization_id) WHERE is_active = true;
+
+CREATE TRIGGER update_chat_webhooks_updated_at
+    BEFORE UPDATE ON chat_webhooks
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,19 @@
+-- Session reschedule links
+-- Reminder and cancellation messages can carry a link with which the patient picks another free
+-- slot of the same therapist for their session. Links are created when a message uses them, are
+-- single-use and stop working once the session starts; only the hash of the token is kept.
+
+CREATE TABLE session_reschedule_links (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    session_id UUID NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    -- Where the session was moved from and to, once used
+    previous_scheduled_at TIMESTAMP,
+    new_scheduled_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_session_reschedule_links_session ON session_reschedule_links(session_id);
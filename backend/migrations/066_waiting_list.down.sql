@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS waiting_list_offers;
+DROP TRIGGER IF EXISTS update_waiting_list_entries_updated_at ON waiting_list_entries;
+DROP TABLE IF EXISTS waiting_list_entries;
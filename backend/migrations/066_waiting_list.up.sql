@@ -0,0 +1,58 @@
+-- Waiting list
+-- Patients queue for a therapist (or any) and the days and times that suit them. When a session
+-- is cancelled, an offer_slot workflow action offers its slot to the first matching patient over
+-- WhatsApp with a link to accept it; offers that expire or are declined pass to the next patient.
+
+CREATE TABLE waiting_list_entries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    patient_id UUID NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+    -- Any therapist or service when not set
+    therapist_id UUID REFERENCES therapists(id) ON DELETE SET NULL,
+    service_id UUID REFERENCES bookable_services(id) ON DELETE SET NULL,
+    -- 0 = Sunday ... 6 = Saturday; any day when empty
+    preferred_weekdays SMALLINT[] NOT NULL DEFAULT '{}',
+    -- Window of session start times in the therapist's timezone; any time when not set
+    earliest_time TIME,
+    latest_time TIME,
+    status VARCHAR(20) NOT NULL DEFAULT 'waiting'
+        CHECK (status IN ('waiting', 'offered', 'booked', 'removed')),
+    notes TEXT,
+    booked_session_id UUID REFERENCES sessions(id) ON DELETE SET NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_waiting_list_entries_org_status ON waiting_list_entries(organization_id, status, created_at);
+
+CREATE TRIGGER update_waiting_list_entries_updated_at
+    BEFORE UPDATE ON waiting_list_entries
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Slots offered to waiting patients; only the hash of the accept link token is kept
+CREATE TABLE waiting_list_offers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    entry_id UUID NOT NULL REFERENCES waiting_list_entries(id) ON DELETE CASCADE,
+    -- The cancelled session whose slot is offered, and the action that offered it
+    source_session_id UUID NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    action_id UUID REFERENCES workflow_actions(id) ON DELETE SET NULL,
+    therapist_id UUID NOT NULL REFERENCES therapists(id) ON DELETE CASCADE,
+    service_id UUID REFERENCES bookable_services(id) ON DELETE SET NULL,
+    scheduled_at TIMESTAMP NOT NULL,
+    duration_minutes INT NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'accepted', 'declined', 'expired', 'taken', 'withdrawn')),
+    -- Set once an expired or declined offer has been passed to the next patient
+    followed_up BOOLEAN NOT NULL DEFAULT false,
+    booked_session_id UUID REFERENCES sessions(id) ON DELETE SET NULL,
+    responded_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_waiting_list_offers_source ON waiting_list_offers(source_session_id);
+CREATE INDEX idx_waiting_list_offers_entry ON waiting_list_offers(entry_id);
+CREATE INDEX idx_waiting_list_offers_follow_up ON waiting_list_offers(status, expires_at) WHERE followed_up = false;
@@ -0,0 +1,18 @@
+-- Budget line-item comments
+-- Clients ask questions about specific lines of a sent budget from the portal and staff answer in
+-- the same thread, so the negotiation is kept with the budget instead of in email.
+
+CREATE TABLE budget_item_comments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    budget_id UUID NOT NULL REFERENCES budgets(id) ON DELETE CASCADE,
+    budget_item_id UUID NOT NULL REFERENCES budget_items(id) ON DELETE CASCADE,
+    author_type VARCHAR(20) NOT NULL CHECK (author_type IN ('client', 'staff')),
+    -- Set for staff replies
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    body TEXT NOT NULL,
+    ip_address VARCHAR(64),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_budget_item_comments_budget ON budget_item_comments(budget_id, created_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_whatsapp_messages_thread;
+ALTER TABLE whatsapp_messages DROP COLUMN IF EXISTS sent_by;
+ALTER TABLE whatsapp_conversations DROP COLUMN IF EXISTS last_read_at;
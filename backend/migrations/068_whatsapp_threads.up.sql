@@ -0,0 +1,13 @@
+-- WhatsApp conversation threads
+-- Messages are grouped into one thread per phone number. The organization shares one read marker per
+-- thread for the unread counters, and replies typed in the app record who sent them.
+
+ALTER TABLE whatsapp_conversations ADD COLUMN last_read_at TIMESTAMPTZ;
+
+ALTER TABLE whatsapp_messages ADD COLUMN sent_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_whatsapp_messages_thread
+    ON whatsapp_messages(organization_id, ('+' || regexp_replace(phone_number, '\D', '', 'g')), created_at DESC);
+
+-- Conversations so far start out read
+UPDATE whatsapp_conversations SET last_read_at = NOW();
@@ -0,0 +1,4 @@
+ALTER TABLE notification_configs DROP COLUMN IF EXISTS meta_app_secret_encrypted;
+ALTER TABLE notification_configs DROP COLUMN IF EXISTS meta_access_token_encrypted;
+ALTER TABLE notification_configs DROP COLUMN IF EXISTS meta_phone_number_id;
+ALTER TABLE notification_configs DROP COLUMN IF EXISTS whatsapp_provider;
@@ -0,0 +1,10 @@
+-- WhatsApp Cloud API
+-- Organizations can send WhatsApp messages through Meta's Cloud API instead of Twilio. Meta
+-- identifies the sender by its phone number ID; the access token and the app secret that signs
+-- webhooks are stored encrypted. Inbound Meta messages keep their payload for media downloads.
+
+ALTER TABLE notification_configs ADD COLUMN whatsapp_provider VARCHAR(20) NOT NULL DEFAULT 'twilio'
+    CHECK (whatsapp_provider IN ('twilio', 'meta'));
+ALTER TABLE notification_configs ADD COLUMN meta_phone_number_id VARCHAR(64);
+ALTER TABLE notification_configs ADD COLUMN meta_access_token_encrypted TEXT;
+ALTER TABLE notification_configs ADD COLUMN meta_app_secret_encrypted TEXT;
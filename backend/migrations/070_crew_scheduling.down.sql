@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS crew_assignments;
+DROP TABLE IF EXISTS crew_members;
@@ -0,0 +1,51 @@
+-- Crew scheduling
+-- Workers and subcontractors are assigned to projects by day with the hours planned for that day.
+-- A member is double-booked when the hours planned across projects exceed the hours they work in a
+-- day. The hours actually worked are recorded on the assignment and, at the rate the member had
+-- when assigned, make up the planned and actual labour cost of the project.
+
+CREATE TABLE crew_members (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('worker', 'subcontractor')),
+    name VARCHAR(255) NOT NULL,
+    -- The app user, for workers who log in
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    trade VARCHAR(100),
+    phone VARCHAR(50),
+    hourly_rate DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    daily_hours DECIMAL(4, 2) NOT NULL DEFAULT 8 CHECK (daily_hours > 0 AND daily_hours <= 24),
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_crew_members_org ON crew_members(organization_id, name);
+
+CREATE TRIGGER update_crew_members_updated_at
+    BEFORE UPDATE ON crew_members
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE crew_assignments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    crew_member_id UUID NOT NULL REFERENCES crew_members(id) ON DELETE CASCADE,
+    work_date DATE NOT NULL,
+    planned_hours DECIMAL(4, 2) NOT NULL CHECK (planned_hours > 0 AND planned_hours <= 24),
+    -- Recorded once the day is worked
+    actual_hours DECIMAL(4, 2) CHECK (actual_hours >= 0 AND actual_hours <= 24),
+    hourly_rate DECIMAL(12, 2) NOT NULL,
+    notes TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (crew_member_id, project_id, work_date)
+);
+
+CREATE INDEX idx_crew_assignments_org_date ON crew_assignments(organization_id, work_date);
+CREATE INDEX idx_crew_assignments_project ON crew_assignments(project_id, work_date);
+
+CREATE TRIGGER update_crew_assignments_updated_at
+    BEFORE UPDATE ON crew_assignments
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,8 @@
+ALTER TABLE tasks DROP COLUMN IF EXISTS weight;
+
+ALTER TABLE projects
+    DROP COLUMN IF EXISTS progress_override_at,
+    DROP COLUMN IF EXISTS progress_override_by,
+    DROP COLUMN IF EXISTS progress_override,
+    DROP COLUMN IF EXISTS computed_progress,
+    DROP COLUMN IF EXISTS progress_mode;
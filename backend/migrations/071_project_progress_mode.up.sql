@@ -0,0 +1,15 @@
+-- Project progress computed from tasks
+-- In 'tasks' mode a project's progress is the weighted share of its tasks that are done, recalculated
+-- whenever a task changes. Cancelled tasks don't count, and tasks of a completed milestone count as
+-- done. A manual figure set on such a project is kept apart as an override, with who set it and when,
+-- so the computed progress is never lost and the override can be cleared. projects.progress remains
+-- the progress shown everywhere, clients included.
+
+ALTER TABLE projects
+    ADD COLUMN progress_mode VARCHAR(10) NOT NULL DEFAULT 'manual' CHECK (progress_mode IN ('manual', 'tasks')),
+    ADD COLUMN computed_progress INT CHECK (computed_progress BETWEEN 0 AND 100),
+    ADD COLUMN progress_override INT CHECK (progress_override BETWEEN 0 AND 100),
+    ADD COLUMN progress_override_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN progress_override_at TIMESTAMPTZ;
+
+ALTER TABLE tasks ADD COLUMN weight INT NOT NULL DEFAULT 1 CHECK (weight BETWEEN 1 AND 100);
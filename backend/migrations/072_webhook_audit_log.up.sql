@@ -0,0 +1,21 @@
+-- Webhook audit log
+-- Messaging provider webhooks that can't be verified or routed are refused and kept here, with what
+-- they carried, instead of being processed: requests whose signature doesn't match the
+-- organization's credentials and messages to numbers no organization uses. Rejected requests of a
+-- known organization are listed in its notification settings.
+
+CREATE TABLE webhook_audit_log (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    -- NULL when the request couldn't be tied to an organization
+    organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE,
+    provider VARCHAR(20) NOT NULL,
+    endpoint VARCHAR(255) NOT NULL,
+    outcome VARCHAR(20) NOT NULL CHECK (outcome IN ('rejected', 'unroutable')),
+    reason TEXT NOT NULL,
+    remote_addr VARCHAR(100),
+    payload JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhook_audit_log_org ON webhook_audit_log(organization_id, created_at DESC);
+CREATE INDEX idx_webhook_audit_log_created ON webhook_audit_log(created_at);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS whatsapp_opt_outs;
+
+ALTER TABLE whatsapp_messages DROP COLUMN IF EXISTS intent;
+
+ALTER TABLE notification_configs DROP COLUMN IF EXISTS inbound_intents;
@@ -0,0 +1,20 @@
+-- Inbound WhatsApp intents
+-- Inbound messages are matched to an intent (confirm, cancel, reschedule, opt-out, opt-in,
+-- balance) by keywords; anything else falls back to a notification for the staff. Organizations
+-- can switch intents off, add keywords and change the replies; their overrides are kept as JSON
+-- over the built-in defaults. Numbers that opted out get no further WhatsApp messages until they
+-- opt back in.
+
+ALTER TABLE notification_configs ADD COLUMN inbound_intents JSONB NOT NULL DEFAULT '{}';
+
+ALTER TABLE whatsapp_messages ADD COLUMN intent VARCHAR(20);
+
+CREATE TABLE whatsapp_opt_outs (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    -- E.164
+    phone_number VARCHAR(50) NOT NULL,
+    -- The message the number opted out with
+    message_id UUID REFERENCES whatsapp_messages(id) ON DELETE SET NULL,
+    opted_out_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (organization_id, phone_number)
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS session_outcomes;
+DROP TABLE IF EXISTS treatment_plan_goals;
+DROP TABLE IF EXISTS treatment_plans;
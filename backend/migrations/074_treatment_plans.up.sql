@@ -0,0 +1,60 @@
+-- Treatment plans and session outcomes
+-- A patient's treatment plan sets goals, how many sessions are planned and when the plan is to
+-- be reviewed. Completed sessions are coded with their outcome, against the plan and the goals
+-- they worked on, so the plan shows how treatment is progressing. Plans whose review date is
+-- within the appointments module's treatment_plan_review_days fire their review once, until
+-- the review date changes.
+
+CREATE TABLE treatment_plans (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    patient_id UUID NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+    therapist_id UUID REFERENCES therapists(id) ON DELETE SET NULL,
+    title VARCHAR(255) NOT NULL,
+    planned_sessions INT CHECK (planned_sessions > 0),
+    start_date DATE NOT NULL,
+    review_date DATE,
+    review_notified_at TIMESTAMPTZ,
+    status VARCHAR(20) NOT NULL DEFAULT 'active'
+        CHECK (status IN ('active', 'completed', 'discontinued')),
+    notes TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_treatment_plans_patient ON treatment_plans(patient_id, start_date DESC);
+CREATE INDEX idx_treatment_plans_review ON treatment_plans(review_date)
+    WHERE status = 'active' AND review_notified_at IS NULL;
+
+CREATE TRIGGER update_treatment_plans_updated_at
+    BEFORE UPDATE ON treatment_plans
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE treatment_plan_goals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    plan_id UUID NOT NULL REFERENCES treatment_plans(id) ON DELETE CASCADE,
+    description TEXT NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    achieved_at TIMESTAMPTZ,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_treatment_plan_goals_plan ON treatment_plan_goals(plan_id, position);
+
+-- One outcome per session
+CREATE TABLE session_outcomes (
+    session_id UUID PRIMARY KEY REFERENCES sessions(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    plan_id UUID REFERENCES treatment_plans(id) ON DELETE SET NULL,
+    outcome VARCHAR(20) NOT NULL CHECK (outcome IN ('improved', 'stable', 'worsened')),
+    -- Progress towards the plan's goals as the therapist rates it, 0 (none) to 10 (achieved)
+    progress_rating SMALLINT CHECK (progress_rating BETWEEN 0 AND 10),
+    -- Goals of the plan the session worked on
+    goal_ids UUID[] NOT NULL DEFAULT '{}',
+    notes TEXT,
+    recorded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    recorded_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_session_outcomes_plan ON session_outcomes(plan_id);
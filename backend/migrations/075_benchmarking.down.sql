@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS benchmark_metrics;
+DROP TABLE IF EXISTS benchmark_participants;
@@ -0,0 +1,25 @@
+-- Anonymous benchmarking
+-- Organizations that opt in share their aggregate metrics (no-show rate, budget conversion rate,
+-- WhatsApp response time) over a trailing window, computed every night. Each participant can
+-- compare itself to the percentile bands of all participants; no other organization's values
+-- are ever returned. Opting out deletes the organization's metrics.
+
+CREATE TABLE benchmark_participants (
+    organization_id UUID PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+    opted_in_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    opted_in_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE benchmark_metrics (
+    organization_id UUID NOT NULL REFERENCES benchmark_participants(organization_id) ON DELETE CASCADE,
+    metric VARCHAR(50) NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    -- Sessions, budgets or conversations the value was computed from
+    sample_size INT NOT NULL,
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (organization_id, metric)
+);
+
+CREATE INDEX idx_benchmark_metrics_metric ON benchmark_metrics(metric);
@@ -0,0 +1,15 @@
+CREATE TABLE whatsapp_opt_outs (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    phone_number VARCHAR(50) NOT NULL,
+    message_id UUID REFERENCES whatsapp_messages(id) ON DELETE SET NULL,
+    opted_out_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (organization_id, phone_number)
+);
+
+INSERT INTO whatsapp_opt_outs (organization_id, phone_number, opted_out_at)
+SELECT organization_id, address, changed_at
+FROM contact_consents
+WHERE channel = 'whatsapp' AND status = 'opted_out';
+
+DROP TABLE IF EXISTS contact_consent_history;
+DROP TABLE IF EXISTS contact_consents;
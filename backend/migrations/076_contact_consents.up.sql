@@ -0,0 +1,52 @@
+-- Contact consent
+-- Replaces the WhatsApp-only opt-out list with a consent record per contact address and channel
+-- (WhatsApp, email, SMS). An address with no record may be contacted; one that opted out gets no
+-- further messages on that channel until it opts back in. Every change is kept in a history with
+-- where it came from: an inbound keyword, the staff, or the client portal.
+
+CREATE TABLE contact_consents (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    channel VARCHAR(20) NOT NULL CHECK (channel IN ('whatsapp', 'email', 'sms')),
+    -- E.164 phone number or lowercase email address
+    address VARCHAR(255) NOT NULL,
+    client_id UUID REFERENCES clients(id) ON DELETE SET NULL,
+    patient_id UUID REFERENCES patients(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('opted_in', 'opted_out')),
+    source VARCHAR(20) NOT NULL CHECK (source IN ('inbound_keyword', 'staff', 'portal', 'import')),
+    note TEXT,
+    changed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (organization_id, channel, address)
+);
+
+CREATE INDEX idx_contact_consents_client ON contact_consents(client_id) WHERE client_id IS NOT NULL;
+CREATE INDEX idx_contact_consents_patient ON contact_consents(patient_id) WHERE patient_id IS NOT NULL;
+
+CREATE TABLE contact_consent_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    consent_id UUID NOT NULL REFERENCES contact_consents(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    note TEXT,
+    -- The inbound message the change came with, for keyword opt-outs
+    message_id UUID REFERENCES whatsapp_messages(id) ON DELETE SET NULL,
+    changed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_contact_consent_history_consent ON contact_consent_history(consent_id, changed_at);
+
+WITH moved AS (
+    INSERT INTO contact_consents (organization_id, channel, address, status, source, changed_at, created_at)
+    SELECT organization_id, 'whatsapp', phone_number, 'opted_out', 'inbound_keyword', opted_out_at, opted_out_at
+    FROM whatsapp_opt_outs
+    RETURNING id, organization_id, address, changed_at
+)
+INSERT INTO contact_consent_history (consent_id, status, source, message_id, changed_at)
+SELECT m.id, 'opted_out', 'inbound_keyword', o.message_id, m.changed_at
+FROM moved m
+JOIN whatsapp_opt_outs o ON o.organization_id = m.organization_id AND o.phone_number = m.address;
+
+DROP TABLE whatsapp_opt_outs;
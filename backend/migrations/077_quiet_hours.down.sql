@@ -0,0 +1,2 @@
+ALTER TABLE scheduled_reminders DROP COLUMN IF EXISTS deferred_until;
+ALTER TABLE notification_configs DROP COLUMN IF EXISTS send_window;
@@ -0,0 +1,9 @@
+-- Quiet hours
+-- Organizations can set the part of the day (and the weekdays) messages may go out, in a
+-- timezone. Workflow messages and session reminders due outside it are deferred to the start of
+-- the next window; urgent actions are still sent. Unset, messages go out at any time.
+
+ALTER TABLE notification_configs ADD COLUMN send_window JSONB;
+
+-- When a reminder due in quiet hours is sent instead; its scheduled_for is kept
+ALTER TABLE scheduled_reminders ADD COLUMN deferred_until TIMESTAMPTZ;
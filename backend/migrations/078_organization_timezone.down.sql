@@ -0,0 +1,15 @@
+ALTER TABLE session_reschedule_links
+    ALTER COLUMN previous_scheduled_at TYPE TIMESTAMP USING previous_scheduled_at AT TIME ZONE 'UTC',
+    ALTER COLUMN new_scheduled_at TYPE TIMESTAMP USING new_scheduled_at AT TIME ZONE 'UTC';
+
+ALTER TABLE waiting_list_offers ALTER COLUMN scheduled_at TYPE TIMESTAMP USING scheduled_at AT TIME ZONE 'UTC';
+
+ALTER TABLE scheduled_reminders ALTER COLUMN scheduled_for TYPE TIMESTAMP USING scheduled_for AT TIME ZONE 'UTC';
+
+ALTER TABLE session_read_model
+    ALTER COLUMN scheduled_at TYPE TIMESTAMP USING scheduled_at AT TIME ZONE 'UTC',
+    ALTER COLUMN ends_at TYPE TIMESTAMP USING ends_at AT TIME ZONE 'UTC';
+
+ALTER TABLE sessions ALTER COLUMN scheduled_at TYPE TIMESTAMP USING scheduled_at AT TIME ZONE 'UTC';
+
+ALTER TABLE organizations DROP COLUMN IF EXISTS timezone;
@@ -0,0 +1,20 @@
+-- Organization timezone
+-- Each organization has a timezone its dates and times are shown in: the calendar, the dates and
+-- times in messages, and the wall-clock days time triggers and recurring triggers count in. Session
+-- times are stored as instants; they were already written in UTC, so existing values are read as UTC.
+
+ALTER TABLE organizations ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'Europe/Lisbon';
+
+ALTER TABLE sessions ALTER COLUMN scheduled_at TYPE TIMESTAMPTZ USING scheduled_at AT TIME ZONE 'UTC';
+
+ALTER TABLE session_read_model
+    ALTER COLUMN scheduled_at TYPE TIMESTAMPTZ USING scheduled_at AT TIME ZONE 'UTC',
+    ALTER COLUMN ends_at TYPE TIMESTAMPTZ USING ends_at AT TIME ZONE 'UTC';
+
+ALTER TABLE scheduled_reminders ALTER COLUMN scheduled_for TYPE TIMESTAMPTZ USING scheduled_for AT TIME ZONE 'UTC';
+
+ALTER TABLE waiting_list_offers ALTER COLUMN scheduled_at TYPE TIMESTAMPTZ USING scheduled_at AT TIME ZONE 'UTC';
+
+ALTER TABLE session_reschedule_links
+    ALTER COLUMN previous_scheduled_at TYPE TIMESTAMPTZ USING previous_scheduled_at AT TIME ZONE 'UTC',
+    ALTER COLUMN new_scheduled_at TYPE TIMESTAMPTZ USING new_scheduled_at AT TIME ZONE 'UTC';
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS email_suppressions;
+
+UPDATE email_messages SET status = 'failed' WHERE status IN ('bounced', 'complained');
+ALTER TABLE email_messages DROP CONSTRAINT email_messages_status_check;
+ALTER TABLE email_messages ADD CONSTRAINT email_messages_status_check
+    CHECK (status IN ('queued', 'sent', 'delivered', 'failed'));
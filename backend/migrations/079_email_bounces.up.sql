@@ -0,0 +1,22 @@
+-- Email bounces and complaints
+-- SendGrid and Amazon SES report bounces and spam complaints to a webhook per organization. The
+-- email is marked bounced or complained, and hard-bounced or complaining addresses are suppressed:
+-- the organization's emails aren't sent to them until staff remove the suppression.
+
+ALTER TABLE email_messages DROP CONSTRAINT email_messages_status_check;
+ALTER TABLE email_messages ADD CONSTRAINT email_messages_status_check
+    CHECK (status IN ('queued', 'sent', 'delivered', 'failed', 'bounced', 'complained'));
+
+CREATE TABLE email_suppressions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    address VARCHAR(255) NOT NULL, -- lowercase
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('hard_bounce', 'complaint')),
+    provider VARCHAR(20) NOT NULL CHECK (provider IN ('sendgrid', 'ses')),
+    detail TEXT,
+    email_message_id UUID REFERENCES email_messages(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (organization_id, address)
+);
+
+CREATE INDEX idx_email_suppressions_org ON email_suppressions(organization_id, created_at DESC);
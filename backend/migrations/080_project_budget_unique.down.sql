@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_projects_budget_unique;
@@ -0,0 +1,5 @@
+-- One project per budget
+-- Project creation locks the budget row before checking for an existing project; the index keeps
+-- any path that skips the lock from creating a second project from the same budget.
+
+CREATE UNIQUE INDEX idx_projects_budget_unique ON projects(budget_id) WHERE deleted_at IS NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE notification_configs DROP COLUMN IF EXISTS ses_topic_arn;
+ALTER TABLE notification_configs DROP COLUMN IF EXISTS sendgrid_webhook_verification_key;
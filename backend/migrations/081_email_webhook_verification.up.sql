@@ -0,0 +1,7 @@
+-- Email bounce and complaint webhook verification
+-- SendGrid posts are checked against the organization's Signed Event Webhook public key, and SES
+-- notifications must be signed by Amazon SNS and come from the organization's topic. Until these
+-- are set, the organization's bounce and complaint webhooks are refused.
+
+ALTER TABLE notification_configs ADD COLUMN sendgrid_webhook_verification_key TEXT; -- base64 DER public key
+ALTER TABLE notification_configs ADD COLUMN ses_topic_arn VARCHAR(255);